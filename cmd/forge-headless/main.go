@@ -17,20 +17,31 @@ import (
 
 	"github.com/entrhq/forge/pkg/agent"
 	agentcontext "github.com/entrhq/forge/pkg/agent/context"
+	"github.com/entrhq/forge/pkg/agent/hooks"
 	"github.com/entrhq/forge/pkg/agent/longtermmemory"
 	"github.com/entrhq/forge/pkg/agent/longtermmemory/capture"
 	"github.com/entrhq/forge/pkg/agent/longtermmemory/retrieval"
 	"github.com/entrhq/forge/pkg/agent/memory/notes"
+	"github.com/entrhq/forge/pkg/agent/memory/vector"
+	"github.com/entrhq/forge/pkg/agent/projectmemory"
+	"github.com/entrhq/forge/pkg/agent/repocontext"
+	"github.com/entrhq/forge/pkg/agent/todo"
 	"github.com/entrhq/forge/pkg/agent/tools"
 	appconfig "github.com/entrhq/forge/pkg/config"
 	"github.com/entrhq/forge/pkg/executor/headless"
 	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/embedding"
 	"github.com/entrhq/forge/pkg/llm/openai"
 	"github.com/entrhq/forge/pkg/logging"
+	"github.com/entrhq/forge/pkg/security/offline"
+	"github.com/entrhq/forge/pkg/security/sandbox"
 	"github.com/entrhq/forge/pkg/security/workspace"
 	"github.com/entrhq/forge/pkg/tools/browser"
 	"github.com/entrhq/forge/pkg/tools/coding"
+	"github.com/entrhq/forge/pkg/tools/mock"
 	"github.com/entrhq/forge/pkg/tools/scratchpad"
+	todotools "github.com/entrhq/forge/pkg/tools/todo"
+	"github.com/entrhq/forge/pkg/tools/web"
 	"gopkg.in/yaml.v3"
 )
 
@@ -39,18 +50,8 @@ const (
 	defaultModel = "anthropic/claude-sonnet-4.5"
 
 	// Context management defaults for headless execution
-	defaultMaxTokens       = 100000 // Conservative limit with headroom for 128K context
-	defaultToolCallAge     = 20     // Tool calls must be 20+ messages old to enter buffer
-	defaultMinToolCalls    = 10     // Minimum 10 tool calls in buffer before summarizing
-	defaultMaxToolCallDist = 40     // Force summarization if any tool call is 40+ messages old
-
-	// Threshold summarization defaults (strategy 2: half-compaction when context is near full)
-	defaultThresholdTrigger = 80.0 // Fire when context usage reaches 80% of the token limit
-
-	// Goal-batch compaction defaults (strategy 3: compact old completed turns into goal-batch blocks)
-	defaultGoalBatchTurnsOld = 20 // Turns must be 20+ messages old to be eligible for compaction
-	defaultGoalBatchMinTurns = 3  // Minimum 3 complete turns before triggering compaction
-	defaultGoalBatchMaxTurns = 6  // Compact at most 6 turns per LLM call
+	// Summarization strategies come from the project config's context section.
+	defaultMaxTokens = 100000 // Conservative limit with headroom for 128K context
 )
 
 // CLIConfig holds command-line configuration
@@ -64,7 +65,17 @@ type CLIConfig struct {
 	Mode        string
 	Timeout     time.Duration
 	OutputFile  string
+	Offline     bool
+	DryRun      bool
+	RunID       string
+	Vars        headless.TaskVars // Values for the task_template's placeholders
+	VarsFile    string            // JSON object of task_template values, or - for stdin
 	ShowVersion bool
+
+	// Validate is a config file to check and exit, and Schema names a schema
+	// to print and exit
+	Validate string
+	Schema   string
 }
 
 func main() {
@@ -77,6 +88,26 @@ func main() {
 		return
 	}
 
+	if config.Schema != "" {
+		if err := printSchema(config.Schema); err != nil {
+			log.Printf("Error: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if config.Validate != "" {
+		valid, err := validateConfigFile(config.Validate)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			os.Exit(1)
+		}
+		if !valid {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create context with signal handling
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -111,7 +142,15 @@ func parseFlags() *CLIConfig {
 	flag.StringVar(&config.Mode, "mode", "write", "Execution mode: read-only or write")
 	flag.DurationVar(&config.Timeout, "timeout", 5*time.Minute, "Execution timeout")
 	flag.StringVar(&config.OutputFile, "output", "execution-summary.json", "Output file for execution summary")
+	flag.BoolVar(&config.Offline, "offline", false, "Run without network access: require a local model server and disable network-touching tools")
+	config.Vars = headless.TaskVars{}
+	flag.Var(config.Vars, "var", "Value for the task_template's {{name}} placeholder as name=value (repeatable)")
+	flag.StringVar(&config.VarsFile, "vars-file", "", "JSON object of task_template values, or - to read it from stdin")
+	flag.BoolVar(&config.DryRun, "dry-run", false, "Simulate file writes and commands in memory and write the would-be diff and gate predictions to the artifacts without touching the workspace")
+	flag.StringVar(&config.RunID, "run-id", "", "The run's ID for cancel requests and artifact uploads (default: generated)")
 	flag.BoolVar(&config.ShowVersion, "version", false, "Show version and exit")
+	flag.StringVar(&config.Validate, "validate", "", "Check a headless config file (or ~/.forge/config.json) for unknown keys, wrong types and deprecated fields, then exit")
+	flag.StringVar(&config.Schema, "schema", "", "Print the JSON Schema of the headless or global config and exit")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Forge Headless - Autonomous Coding Agent for CI/CD\n\n")
@@ -125,6 +164,12 @@ func parseFlags() *CLIConfig {
 		fmt.Fprintf(os.Stderr, "  forge-headless -config forge-headless.yaml\n\n")
 		fmt.Fprintf(os.Stderr, "  # Read-only mode\n")
 		fmt.Fprintf(os.Stderr, "  forge-headless -task \"Analyze code coverage\" -mode read-only\n\n")
+		fmt.Fprintf(os.Stderr, "  # Fill a task_template from the CI payload and a flag\n")
+		fmt.Fprintf(os.Stderr, "  forge-headless -config fix-issue.yaml -vars-file payload.json -var paths=internal/auth\n\n")
+		fmt.Fprintf(os.Stderr, "  # Preview a prompt's changes without touching the workspace\n")
+		fmt.Fprintf(os.Stderr, "  forge-headless -config forge-headless.yaml -dry-run\n\n")
+		fmt.Fprintf(os.Stderr, "  # Check a config file before scheduling a run\n")
+		fmt.Fprintf(os.Stderr, "  forge-headless -validate forge-headless.yaml\n\n")
 	}
 
 	flag.Parse()
@@ -140,17 +185,107 @@ func run(ctx context.Context, cliConfig *CLIConfig) error {
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	if cliConfig.DryRun {
+		execConfig.DryRun = true
+	}
+	if cliConfig.RunID != "" {
+		execConfig.RunID = cliConfig.RunID
+	}
+
+	// Fill the task template, flags taking precedence over the JSON payload
+	vars := headless.TaskVars{}
+	if cliConfig.VarsFile != "" {
+		if vars, err = headless.LoadTaskVars(cliConfig.VarsFile); err != nil {
+			return err
+		}
+	}
+	for name, value := range cliConfig.Vars {
+		vars[name] = value
+	}
+	if renderErr := execConfig.RenderTaskTemplate(vars); renderErr != nil {
+		return renderErr
+	}
 
 	// Validate configuration
 	if validationErr := execConfig.Validate(); validationErr != nil {
 		return fmt.Errorf("invalid configuration: %w", validationErr)
 	}
+	if cliConfig.Offline {
+		if offlineErr := execConfig.ValidateOffline(); offlineErr != nil {
+			return offlineErr
+		}
+	}
+
+	// Work in a throwaway worktree so the primary checkout is left untouched
+	if execConfig.Git.UseWorktree {
+		worktree, worktreeErr := headless.EnterWorktree(ctx, execConfig)
+		if worktreeErr != nil {
+			return worktreeErr
+		}
+		log.Printf("Working in worktree %s", worktree.Dir())
+		defer func() {
+			kept, closeErr := worktree.Close()
+			switch {
+			case closeErr != nil:
+				log.Printf("Failed to remove the worktree %s: %v", worktree.Dir(), closeErr)
+			case kept:
+				log.Printf("Kept the worktree, which has uncommitted changes: %s", worktree.Dir())
+			}
+		}()
+	}
 
 	// Initialize global configuration
 	if initErr := appconfig.Initialize(""); initErr != nil {
 		return fmt.Errorf("failed to initialize configuration: %w", initErr)
 	}
 
+	// Layer the workspace's shared .forge/config.yaml over the global config
+	projectConfig, err := appconfig.InitializeProject(execConfig.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+	if projectConfig != nil {
+		log.Printf("Loaded project configuration from %s", appconfig.ProjectConfigPath)
+	}
+
+	// Repository hooks run shell commands around tool calls and turns
+	hookConfig, err := hooks.LoadConfig(execConfig.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+	if hookConfig != nil {
+		log.Printf("Loaded shell hooks from %s", hooks.ConfigPath)
+	}
+
+	// Apply the administrator's approval policy, which user and project settings cannot loosen
+	policy, err := appconfig.InitializePolicy(appconfig.DefaultPolicyPath)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		log.Printf("Loaded approval policy from %s", policy.Path)
+	}
+
+	// Layer headless sampling parameters over the global per-role settings so the
+	// effective values are what gets applied and recorded in execution.json
+	execConfig.Sampling = execConfig.Sampling.WithDefaults(headless.SamplingConfig{
+		Agent:      llm.SamplingFromConfig(appconfig.SamplingRoleAgent),
+		Summarizer: llm.SamplingFromConfig(appconfig.SamplingRoleSummarizer),
+		Commit:     llm.SamplingFromConfig(appconfig.SamplingRoleCommit),
+	})
+	if execConfig.FallbackModel == "" {
+		execConfig.FallbackModel = llm.FallbackModelFromConfig()
+	}
+	execConfig.RateLimit = llm.RateLimitFromConfig().Merge(execConfig.RateLimit)
+	execConfig.ResponseCache = llm.ResponseCacheFromConfig().Merge(execConfig.ResponseCache)
+	if execConfig.ResponseCache.Enabled {
+		cache, err := llm.NewResponseCache(execConfig.ResponseCache)
+		if err != nil {
+			return fmt.Errorf("failed to open the LLM response cache: %w", err)
+		}
+		llm.SetSharedResponseCache(cache)
+	}
+
 	// Determine final LLM configuration (CLI args override config file)
 	finalModel := cliConfig.Model
 	finalBaseURL := cliConfig.BaseURL
@@ -179,8 +314,10 @@ func run(ctx context.Context, cliConfig *CLIConfig) error {
 	}
 
 	// Create LLM provider with final configuration
+	llm.SharedRateLimiter().SetLimits(execConfig.RateLimit)
 	providerOpts := []openai.ProviderOption{
 		openai.WithModel(finalModel),
+		openai.WithRateLimiter(llm.SharedRateLimiter()),
 	}
 
 	if finalBaseURL != "" {
@@ -192,40 +329,14 @@ func run(ctx context.Context, cliConfig *CLIConfig) error {
 		return fmt.Errorf("failed to create LLM provider: %w", err)
 	}
 
-	// Create context manager for long-running autonomous tasks
-	toolCallStrategy := agentcontext.NewToolCallSummarizationStrategy(
-		defaultToolCallAge,
-		defaultMinToolCalls,
-		defaultMaxToolCallDist,
-	)
-
-	// Strategy 2: Half-compaction when context crosses the token threshold.
-	thresholdStrategy := agentcontext.NewThresholdSummarizationStrategy(
-		defaultThresholdTrigger,
-	)
-
-	goalBatchStrategy := agentcontext.NewGoalBatchCompactionStrategy(
-		defaultGoalBatchTurnsOld,
-		defaultGoalBatchMinTurns,
-		defaultGoalBatchMaxTurns,
-	)
-
-	contextManager, err := agentcontext.NewManager(
-		provider,
-		defaultMaxTokens,
-		toolCallStrategy,
-		thresholdStrategy,
-		goalBatchStrategy,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create context manager: %w", err)
-	}
-
-	// Apply summarization model override from config (no-op if not configured)
-	if llmCfg := appconfig.GetLLM(); llmCfg != nil {
-		if summarizationModel := llmCfg.GetSummarizationModel(); summarizationModel != "" {
-			contextManager.SetSummarizationModel(summarizationModel)
+	// Offline mode requires a local model server and turns off network-touching capabilities
+	var offlineReport *offline.Report
+	if cliConfig.Offline {
+		if endpointErr := offline.CheckEndpoint("LLM provider", provider.GetBaseURL(), "-base-url, OPENAI_BASE_URL, or llm.base_url"); endpointErr != nil {
+			return endpointErr
 		}
+		offlineReport = &offline.Report{}
+		offlineReport.Disable("Web and browser tools", "they load remote pages")
 	}
 
 	// Initialize the embedding provider for long-term memory retrieval.
@@ -246,6 +357,10 @@ func run(ctx context.Context, cliConfig *CLIConfig) error {
 			embedder = nil
 		}
 	}
+	if embeddingProvider, ok := embedder.(*embedding.Provider); ok && offlineReport != nil && !offline.IsLocalURL(embeddingProvider.BaseURL()) {
+		offlineReport.Disable("Memory retrieval", fmt.Sprintf("embedding endpoint %s is not local", embeddingProvider.BaseURL()))
+		embedder = nil
+	}
 
 	// Initialize long-term memory capture pipeline and retrieval engine.
 	var capturePipeline *capture.Pipeline
@@ -289,87 +404,323 @@ func run(ctx context.Context, cliConfig *CLIConfig) error {
 		}
 	}
 
-	// Create workspace security guard
-	guard, err := workspace.NewGuard(execConfig.WorkspaceDir)
-	if err != nil {
-		return fmt.Errorf("failed to create workspace guard: %w", err)
+	// Vector memory indexes tool summaries, notes and task results whenever an
+	// embedding model is configured, and is searched with recall_memory.
+	var vectorMemory *vector.Memory
+	if embedder != nil {
+		if storePath, pathErr := vector.DefaultStorePath(); pathErr != nil {
+			log.Printf("memory: vector memory disabled: %v", pathErr)
+		} else if vectorStore, storeErr := vector.NewFileStore(storePath); storeErr != nil {
+			log.Printf("memory: vector memory disabled: %v", storeErr)
+		} else {
+			memLog, _ := logging.NewLogger("headless-vector")
+			vectorMemory = vector.New(vectorStore, embedder, execConfig.WorkspaceDir, memLog)
+			vectorMemory.Start(ctx)
+			log.Printf("memory: vector memory started (records=%d)", vectorStore.Len())
+		}
 	}
 
-	// Compose the headless system prompt with mode-specific guidance
-	systemPrompt := composeHeadlessSystemPrompt(execConfig.Mode)
+	// newGuard creates the workspace security guard and command sandbox for a
+	// workspace. Task matrix runs give each task its own worktree, so each
+	// task gets its own.
+	newGuard := func(workspaceDir string) (*workspace.Guard, *sandbox.Sandbox, error) {
+		guard, err := workspace.NewGuard(workspaceDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create workspace guard: %w", err)
+		}
+
+		// Run model-chosen commands isolated from the host when configured
+		commandSandbox, err := sandbox.New(execConfig.Sandbox, guard.WorkspaceDir())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to set up command sandbox: %w", err)
+		}
+
+		// Enforce the project's write-protection rules
+		if err := guard.SetPathRules(projectConfig.GuardPathRules()); err != nil {
+			return nil, nil, fmt.Errorf("failed to apply path rules: %w", err)
+		}
+
+		// Let tools work across the project's workspace roots
+		if err := guard.SetRoots(projectConfig.GuardRoots()); err != nil {
+			return nil, nil, fmt.Errorf("failed to apply workspace roots: %w", err)
+		}
+
+		// Let tools use the directories outside the workspace the project allows
+		if err := guard.SetAllowedDirs(projectConfig.GuardAllowedDirs()); err != nil {
+			return nil, nil, fmt.Errorf("failed to apply allowed directories: %w", err)
+		}
+
+		// Restrict a monorepo run to the configured workspace paths
+		if err := guard.SetScope(execConfig.Workspace.Paths); err != nil {
+			return nil, nil, fmt.Errorf("failed to apply workspace paths: %w", err)
+		}
+
+		// Commands may run in, and use, the roots and allowed directories too
+		if commandSandbox != nil {
+			for _, root := range guard.Roots() {
+				commandSandbox.Mount(root.Path, root.ReadOnly)
+			}
+			for _, dir := range guard.AllowedDirs() {
+				commandSandbox.Mount(dir.Path, dir.ReadOnly)
+			}
+		}
+		return guard, commandSandbox, nil
+	}
 
-	// Create notes manager for scratchpad
-	notesManager := notes.NewManager()
+	guard, commandSandbox, err := newGuard(execConfig.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+	if commandSandbox != nil {
+		log.Printf("Command sandbox: %s", commandSandbox.Describe())
+	}
 
-	// Create agent with headless system prompt, disabled interactive tools, context management, and shared notes manager
-	agentOpts := []agent.AgentOption{
-		agent.WithCustomInstructions(systemPrompt),
-		agent.WithDisabledTools("ask_question", "converse"),
-		agent.WithContextManager(contextManager),
-		agent.WithNotesManager(notesManager),
-		agent.WithEmbedder(embedder),
-		agent.WithRetrievalEngine(retrievalEngine),
+	// Compose the headless system prompt with mode-specific guidance
+	systemPrompt := appconfig.AppendExperimentalNotice(projectConfig.AppendInstructions(composeHeadlessSystemPrompt(execConfig.Mode)))
+	if offlineReport != nil {
+		systemPrompt += "\n\n" + offline.Instructions
 	}
-	if capturePipeline != nil {
-		agentOpts = append(agentOpts, agent.WithCapturePipeline(capturePipeline))
+	if rootsInstructions := guard.RootsInstructions(); rootsInstructions != "" {
+		systemPrompt += "\n\n" + rootsInstructions
 	}
-	ag := agent.NewDefaultAgent(provider, agentOpts...)
 
-	// Register coding tools with workspace guard, filtered by constraints
-	codingTools := []tools.Tool{
-		coding.NewReadFileTool(guard),
-		coding.NewWriteFileTool(guard),
-		coding.NewListFilesTool(guard),
-		coding.NewSearchFilesTool(guard),
-		coding.NewApplyDiffTool(guard),
-		coding.NewExecuteCommandTool(guard),
-		coding.NewAnalyzeDocumentTool(guard, provider),
+	// In a dry run, writes and commands go to an in-memory overlay
+	var overlay *mock.Overlay
+	if execConfig.DryRun {
+		overlay = mock.NewOverlay(guard.WorkspaceDir())
+		log.Printf("Dry run: file writes and commands are simulated")
 	}
 
-	for _, tool := range codingTools {
-		// Filter tools based on allowed_tools constraint
-		if !execConfig.Constraints.ShouldRegisterTool(tool.Name()) {
-			continue
+	// newAgent builds the agent for one execution. Fan-out runs build one per
+	// package so no conversation state carries over between packages.
+	newAgent := func(runConfig *headless.Config) (agent.Agent, error) {
+		// Matrix tasks run in their own worktrees
+		runGuard, runSandbox := guard, commandSandbox
+		if runConfig.WorkspaceDir != execConfig.WorkspaceDir {
+			var err error
+			if runGuard, runSandbox, err = newGuard(runConfig.WorkspaceDir); err != nil {
+				return nil, err
+			}
 		}
-		if regErr := ag.RegisterTool(tool); regErr != nil {
-			return fmt.Errorf("failed to register tool: %w", regErr)
+
+		// Create context manager for long-running autonomous tasks
+		contextStrategies, err := agentcontext.NewStrategies(projectConfig.GetContextStrategies())
+		if err != nil {
+			return nil, err
+		}
+		contextManager, err := agentcontext.NewManager(provider, defaultMaxTokens, contextStrategies...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create context manager: %w", err)
 		}
-	}
 
-	// Register scratchpad tools
-	scratchpadTools := []tools.Tool{
-		scratchpad.NewAddNoteTool(notesManager),
-		scratchpad.NewListNotesTool(notesManager),
-		scratchpad.NewSearchNotesTool(notesManager),
-		scratchpad.NewListTagsTool(notesManager),
-		scratchpad.NewScratchNoteTool(notesManager),
-		scratchpad.NewUpdateNoteTool(notesManager),
-	}
+		// Apply summarization model override from config (no-op if not configured)
+		if llmCfg := appconfig.GetLLM(); llmCfg != nil {
+			if summarizationModel := llmCfg.GetSummarizationModel(); summarizationModel != "" {
+				contextManager.SetSummarizationModel(summarizationModel)
+			}
+		}
+		contextManager.SetSummarizationSampling(runConfig.Sampling.Summarizer)
+
+		// Create notes manager for scratchpad
+		notesManager := notes.NewManager()
+
+		// Create agent with headless system prompt, disabled interactive tools, context management, and shared notes manager
+		agentOpts := []agent.AgentOption{
+			agent.WithCustomInstructions(systemPrompt),
+			agent.WithDisabledTools("ask_question", "converse"),
+			agent.WithDisabledTools(projectConfig.GetDisabledTools()...),
+			agent.WithDisabledTools(appconfig.DisabledExperimentalTools()...),
+			agent.WithWorkspaceDir(runConfig.WorkspaceDir),
+			agent.WithWorkspaceGuard(runGuard),
+			agent.WithHookConfig(hookConfig),
+			agent.WithToolCallMode(llm.ToolCallModeFromConfig()),
+			agent.WithMessageTokenLimit(
+				runConfig.Constraints.MessageTokenLimit(),
+				agent.OversizedMessagePolicy(runConfig.Constraints.OversizedMessages),
+			),
+			agent.WithToolLimits(projectConfig.GetToolLimits().Merge(runConfig.Constraints.ToolLimits)),
+			agent.WithToolSchemas(projectConfig.GetToolSchemas()),
+			agent.WithContextManager(contextManager),
+			agent.WithNotesManager(notesManager),
+			agent.WithEmbedder(embedder),
+			agent.WithRetrievalEngine(retrievalEngine),
+			agent.WithVectorMemory(vectorMemory),
+		}
+		if capturePipeline != nil {
+			agentOpts = append(agentOpts, agent.WithCapturePipeline(capturePipeline))
+		}
+		if settings := projectConfig.GetRepositoryContext(); settings.Enabled {
+			repositoryContext, loadErr := repocontext.NewLoader(runConfig.WorkspaceDir, repocontext.Options{
+				MaxTokens: settings.MaxTokens,
+				Nested:    settings.Nested,
+			})
+			if loadErr != nil {
+				log.Printf("repository context not loaded: %v", loadErr)
+			} else {
+				agentOpts = append(agentOpts, agent.WithRepositoryContextLoader(repositoryContext))
+			}
+		}
+		if settings := projectConfig.GetProjectMemory(); settings.Enabled {
+			// Learnings go to the primary workspace, not a run's worktree
+			mem, memErr := projectmemory.Open(execConfig.WorkspaceDir, projectmemory.Options{MaxTokens: settings.MaxTokens})
+			switch {
+			case memErr != nil:
+				log.Printf("project memory not loaded: %v", memErr)
+			case overlay != nil || runConfig.Mode == headless.ModeReadOnly:
+				agentOpts = append(agentOpts, agent.WithProjectMemory(mem, nil), agent.WithDisabledTools("remember"))
+			case settings.AutoExtract:
+				agentOpts = append(agentOpts, agent.WithProjectMemory(mem, projectmemory.NewExtractor(provider, mem)))
+			default:
+				agentOpts = append(agentOpts, agent.WithProjectMemory(mem, nil))
+			}
+		}
+		agentProvider := llm.WithFallback(llm.WithSampling(provider, runConfig.Sampling.Agent), runConfig.FallbackModel)
+		ag := agent.NewDefaultAgent(agentProvider, agentOpts...)
+		for _, strategy := range contextStrategies {
+			if toolCallStrategy, ok := strategy.(*agentcontext.ToolCallSummarizationStrategy); ok {
+				toolCallStrategy.SetVectorMemory(vectorMemory, ag.GetSessionID())
+			}
+		}
 
-	for _, tool := range scratchpadTools {
-		if regErr := ag.RegisterTool(tool); regErr != nil {
-			return fmt.Errorf("failed to register scratchpad tool: %w", regErr)
+		// Register coding tools with workspace guard, filtered by constraints
+		executeCommand := coding.NewExecuteCommandTool(runGuard)
+		executeCommand.SetSandbox(runSandbox)
+		runTests := coding.NewRunTestsTool(runGuard)
+		runTests.SetSandbox(runSandbox)
+		viewImage := coding.NewViewImageTool(runGuard)
+		viewImage.SetScreenshotDir(runConfig.ScreenshotDir())
+		codingTools := []tools.Tool{
+			coding.NewReadFileTool(runGuard),
+			coding.NewWriteFileTool(runGuard),
+			coding.NewListFilesTool(runGuard),
+			coding.NewSearchFilesTool(runGuard),
+			coding.NewFindFilesTool(runGuard),
+			coding.NewApplyDiffTool(runGuard),
+			coding.NewRenameSymbolTool(runGuard),
+			coding.NewDeleteFileTool(runGuard),
+			coding.NewMoveFileTool(runGuard),
+			coding.NewCreateDirectoryTool(runGuard),
+			executeCommand,
+			runTests,
+			coding.NewAnalyzeDocumentTool(runGuard, provider),
+			viewImage,
+		}
+
+		// In a dry run, route writes and commands through the overlay
+		if overlay != nil {
+			codingTools = mock.Wrap(codingTools, runGuard, overlay)
+		}
+
+		for _, tool := range codingTools {
+			// Filter tools based on allowed_tools constraint
+			if !runConfig.Constraints.ShouldRegisterTool(tool.Name()) {
+				continue
+			}
+			if regErr := ag.RegisterTool(tool); regErr != nil {
+				return nil, fmt.Errorf("failed to register tool: %w", regErr)
+			}
+		}
+
+		// Register scratchpad tools
+		scratchpadTools := []tools.Tool{
+			scratchpad.NewAddNoteTool(notesManager),
+			scratchpad.NewListNotesTool(notesManager),
+			scratchpad.NewSearchNotesTool(notesManager),
+			scratchpad.NewListTagsTool(notesManager),
+			scratchpad.NewScratchNoteTool(notesManager),
+			scratchpad.NewUpdateNoteTool(notesManager),
+		}
+
+		for _, tool := range scratchpadTools {
+			if regErr := ag.RegisterTool(tool); regErr != nil {
+				return nil, fmt.Errorf("failed to register scratchpad tool: %w", regErr)
+			}
 		}
-	}
 
-	// Register browser tools using the browser registry
-	browserManager := browser.NewSessionManager()
-	browserRegistry := browser.NewToolRegistry(browserManager)
-	browserRegistry.SetLLMProvider(provider) // Enable AI-powered browser tools
-	browserTools := browserRegistry.RegisterTools()
+		// Register todo list tools
+		todoList := todo.NewList()
+		todoTools := []tools.Tool{
+			todotools.NewCreateTodoListTool(todoList),
+			todotools.NewUpdateTodoTool(todoList),
+		}
 
-	for _, tool := range browserTools {
-		// Filter tools based on allowed_tools constraint
-		if !execConfig.Constraints.ShouldRegisterTool(tool.Name()) {
-			continue
+		for _, tool := range todoTools {
+			if regErr := ag.RegisterTool(tool); regErr != nil {
+				return nil, fmt.Errorf("failed to register todo tool: %w", regErr)
+			}
 		}
-		if regErr := ag.RegisterTool(tool); regErr != nil {
-			return fmt.Errorf("failed to register browser tool: %w", regErr)
+
+		// Register web and browser tools (they need the network). A dry run
+		// leaves them out, since requests and page interactions cannot be simulated
+		if offlineReport == nil && overlay == nil {
+			if fetchURL := web.NewFetchURLTool(); runConfig.Constraints.ShouldRegisterTool(fetchURL.Name()) {
+				if regErr := ag.RegisterTool(fetchURL); regErr != nil {
+					return nil, fmt.Errorf("failed to register web tool: %w", regErr)
+				}
+			}
+			if httpRequest := web.NewHTTPRequestTool(); runConfig.Constraints.ShouldRegisterTool(httpRequest.Name()) {
+				if regErr := ag.RegisterTool(httpRequest); regErr != nil {
+					return nil, fmt.Errorf("failed to register web tool: %w", regErr)
+				}
+			}
+			if searchProvider, searchErr := web.SearchProviderFromEnv(); searchErr != nil {
+				log.Printf("Web search unavailable: %v", searchErr)
+			} else if webSearch := web.NewWebSearchTool(searchProvider); searchProvider != nil && runConfig.Constraints.ShouldRegisterTool(webSearch.Name()) {
+				if regErr := ag.RegisterTool(webSearch); regErr != nil {
+					return nil, fmt.Errorf("failed to register web tool: %w", regErr)
+				}
+			}
+
+			browserManager := browser.NewSessionManager()
+			browserRegistry := browser.NewToolRegistry(browserManager)
+			browserRegistry.SetLLMProvider(provider) // Enable AI-powered browser tools
+			browserRegistry.SetScreenshotDir(runConfig.ScreenshotDir())
+			browserTools := browserRegistry.RegisterTools()
+
+			// Headless runs cannot install Playwright interactively; use fetch_url instead
+			if ui := appconfig.GetUI(); ui != nil && ui.IsBrowserEnabled() {
+				if err := browserManager.DetectInstallation(); err != nil {
+					log.Printf("Browser tools unavailable, using fetch_url instead: %v", err)
+				}
+			}
+
+			for _, tool := range browserTools {
+				// Filter tools based on allowed_tools constraint
+				if !runConfig.Constraints.ShouldRegisterTool(tool.Name()) {
+					continue
+				}
+				if regErr := ag.RegisterTool(tool); regErr != nil {
+					return nil, fmt.Errorf("failed to register browser tool: %w", regErr)
+				}
+			}
 		}
+
+		return ag, nil
 	}
 
-	// Create headless executor with configured agent
-	executor, err := headless.NewExecutor(ag, execConfig)
+	if offlineReport != nil {
+		log.Print(offlineReport.Banner())
+	}
+
+	// Create headless executor with configured agent, or a fan-out or matrix
+	// executor that builds an agent per package or task
+	var executor interface{ Run(context.Context) error }
+	switch {
+	case len(execConfig.Tasks) > 0:
+		executor, err = headless.NewMatrixExecutor(execConfig, newAgent)
+	case execConfig.FanOut.Enabled():
+		executor, err = headless.NewFanOutExecutor(execConfig, newAgent)
+	default:
+		var ag agent.Agent
+		if ag, err = newAgent(execConfig); err != nil {
+			return err
+		}
+		var single *headless.Executor
+		if single, err = headless.NewExecutor(ag, execConfig); err == nil {
+			single.SetOverlay(overlay)
+			executor = single
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create executor: %w", err)
 	}
@@ -383,7 +734,13 @@ func run(ctx context.Context, cliConfig *CLIConfig) error {
 
 	// Run execution
 	log.Printf("Starting headless execution...")
-	log.Printf("Task: %s", execConfig.Task)
+	if len(execConfig.Tasks) > 0 {
+		for _, task := range execConfig.Tasks {
+			log.Printf("Task %s: %s", task.Name, task.Task)
+		}
+	} else {
+		log.Printf("Task: %s", execConfig.Task)
+	}
 	log.Printf("Mode: %s", execConfig.Mode)
 	log.Printf("Workspace: %s", execConfig.WorkspaceDir)
 
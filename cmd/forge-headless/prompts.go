@@ -77,7 +77,7 @@ const ReadOnlyModeGuidance = `
 -   **Analysis and Observation**: Read files, search code, list directories to understand the codebase
 -   **Information Gathering**: Examine code structure, dependencies, configuration, and documentation
 -   **Reporting**: Provide detailed analysis, findings, recommendations, and observations
--   **Read-Only Tools**: Use read_file, list_files, search_files to gather information
+-   **Read-Only Tools**: Use read_file, list_files, search_files, find_files to gather information
 
 **What You Can Do:**
 -   Analyze code quality, architecture, and patterns
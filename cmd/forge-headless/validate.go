@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/config/jsonschema"
+	"github.com/entrhq/forge/pkg/executor/headless"
+	"gopkg.in/yaml.v3"
+)
+
+// printSchema writes the JSON Schema of the headless or global config to stdout.
+func printSchema(name string) error {
+	var schema *jsonschema.Schema
+	switch name {
+	case "headless":
+		schema = headless.Schema()
+	case "global":
+		schema = appconfig.GlobalSchema()
+	default:
+		return fmt.Errorf("unknown schema: %s (must be 'headless' or 'global')", name)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(schema)
+}
+
+// validateConfigFile checks a config file against its schema and prints the
+// issues found. A file with a top-level sections key is checked as the global
+// config, anything else as a headless config. It reports whether the file is
+// free of errors; warnings such as deprecated fields do not fail it.
+func validateConfigFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	kind, schema := "headless", headless.Schema()
+	if isGlobalConfig(data) {
+		kind, schema = "global", appconfig.GlobalSchema()
+	}
+
+	issues, err := jsonschema.Validate(schema, data)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	// Checks the schema cannot express, such as required fields and quality
+	// gate dependencies, once the file is known to load
+	if kind == "headless" && !jsonschema.HasErrors(issues) {
+		config, loadErr := loadConfigFromFile(path)
+		if loadErr == nil {
+			loadErr = config.Validate()
+		}
+		if loadErr != nil {
+			issues = append(issues, jsonschema.Issue{Message: loadErr.Error()})
+		}
+	}
+
+	errors, warnings := 0, 0
+	for _, issue := range issues {
+		fmt.Printf("%s: %s\n", path, issue)
+		if issue.Warning {
+			warnings++
+		} else {
+			errors++
+		}
+	}
+	if errors > 0 {
+		fmt.Printf("%s: invalid %s config: %d error(s), %d warning(s)\n", path, kind, errors, warnings)
+		return false, nil
+	}
+	fmt.Printf("%s: valid %s config (%d warning(s))\n", path, kind, warnings)
+	return true, nil
+}
+
+// isGlobalConfig reports whether data is in the format of ~/.forge/config.json.
+func isGlobalConfig(data []byte) bool {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	_, ok := doc["sections"]
+	return ok
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/entrhq/forge/pkg/executor/acp"
+)
+
+// runACP serves the agent to an editor plugin over JSON-RPC on stdin and
+// stdout. Stdout carries only protocol messages, so everything else goes to
+// stderr or the session log.
+func runACP(ctx context.Context, config *Config) error {
+	factory, offlineReport, err := newSessionFactory(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	cmdLog.Infof("Serving editor integration over stdio (workspace: %s)", config.WorkspaceDir)
+	if offlineReport != nil {
+		fmt.Fprint(os.Stderr, offlineReport.Banner())
+	}
+
+	srv := acp.NewServer(factory, acp.WithAgentInfo("forge", version))
+	return srv.Serve(ctx, os.Stdin, os.Stdout)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/config/secrets"
+)
+
+// maxSecretBytes caps a secret piped to 'forge auth login'
+const maxSecretBytes = 64 * 1024
+
+// authCredential is a credential 'forge auth' can keep in the system keyring.
+type authCredential struct {
+	name     string // Argument to login and logout, e.g. "github"
+	label    string // e.g. "GitHub token"
+	envVar   string // Environment variable that takes precedence over the keyring
+	provider string // Git hosting provider; empty for the LLM API key
+}
+
+// authCredentials lists the credentials in the order status shows them
+var authCredentials = []authCredential{
+	{name: "llm", label: "LLM API key", envVar: "OPENAI_API_KEY"},
+	{name: git.ProviderGitHub, label: "GitHub token", envVar: git.DefaultTokenEnv(git.ProviderGitHub), provider: git.ProviderGitHub},
+	{name: git.ProviderGitLab, label: "GitLab token", envVar: git.DefaultTokenEnv(git.ProviderGitLab), provider: git.ProviderGitLab},
+	{name: git.ProviderGitea, label: "Gitea token", envVar: git.DefaultTokenEnv(git.ProviderGitea), provider: git.ProviderGitea},
+	{name: git.ProviderBitbucket, label: "Bitbucket token", envVar: git.DefaultTokenEnv(git.ProviderBitbucket), provider: git.ProviderBitbucket},
+}
+
+// lookupAuthCredential finds a credential by name.
+func lookupAuthCredential(name string) (authCredential, error) {
+	names := make([]string, 0, len(authCredentials))
+	for _, cred := range authCredentials {
+		if cred.name == name {
+			return cred, nil
+		}
+		names = append(names, cred.name)
+	}
+	return authCredential{}, fmt.Errorf("unknown credential %q (must be one of %s)", name, strings.Join(names, ", "))
+}
+
+// runAuth stores, reports and removes credentials in the system keyring so
+// API keys and git tokens do not have to live in the environment or in a
+// config file.
+func runAuth(config *Config) error {
+	err := runAuthCommand(config)
+	if err != nil {
+		// Application errors otherwise only reach the session log
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	return err
+}
+
+// runAuthCommand dispatches the auth subcommand.
+func runAuthCommand(config *Config) error {
+	if err := appconfig.Initialize(""); err != nil {
+		return fmt.Errorf("failed to initialize configuration: %w", err)
+	}
+
+	args := config.AuthArgs
+	if len(args) == 0 {
+		return fmt.Errorf("auth needs a command: forge auth login <credential>, forge auth status or forge auth logout [credential]")
+	}
+
+	switch args[0] {
+	case "login":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: forge auth login <credential>")
+		}
+		cred, err := lookupAuthCredential(args[1])
+		if err != nil {
+			return err
+		}
+		return authLogin(cred)
+	case "status":
+		return authStatus(os.Stdout)
+	case "logout":
+		creds := authCredentials
+		if len(args) > 1 {
+			creds = nil
+			for _, name := range args[1:] {
+				cred, err := lookupAuthCredential(name)
+				if err != nil {
+					return err
+				}
+				creds = append(creds, cred)
+			}
+		}
+		return authLogout(creds, len(args) > 1)
+	default:
+		return fmt.Errorf("unknown auth command %q (must be login, status or logout)", args[0])
+	}
+}
+
+// authLogin reads a secret from the terminal, or from stdin when it is piped,
+// and stores it in the system keyring.
+func authLogin(cred authCredential) error {
+	secret, err := readSecret(cred.label)
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return fmt.Errorf("no %s given", cred.label)
+	}
+
+	if cred.provider == "" {
+		// The LLM section records that its key lives in the keyring, so the
+		// config file never holds it
+		llmConfig := appconfig.GetLLM()
+		if llmConfig == nil {
+			return fmt.Errorf("LLM configuration is not available")
+		}
+		if err := llmConfig.StoreAPIKey(secret); err != nil {
+			return fmt.Errorf("failed to store the %s in the system keyring: %w", cred.label, err)
+		}
+		if err := appconfig.Global().SaveAll(); err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+	} else if err := secrets.Set(secrets.GitTokenAccount(cred.provider), secret); err != nil {
+		return fmt.Errorf("failed to store the %s in the system keyring: %w", cred.label, err)
+	}
+
+	fmt.Printf("✓ Stored the %s in the system keyring\n", cred.label)
+	if os.Getenv(cred.envVar) != "" {
+		fmt.Printf("  %s is also set and takes precedence while it is\n", cred.envVar)
+	}
+	return nil
+}
+
+// readSecret prompts for a secret without echoing it, or reads it from stdin
+// when stdin is not a terminal, e.g. echo "$TOKEN" | forge auth login github.
+func readSecret(label string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprintf(os.Stderr, "%s: ", label)
+		secret, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the %s: %w", label, err)
+		}
+		return strings.TrimSpace(string(secret)), nil
+	}
+
+	secret, err := io.ReadAll(io.LimitReader(os.Stdin, maxSecretBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the %s from stdin: %w", label, err)
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
+
+// authStatus reports where each credential comes from, without showing it.
+func authStatus(out io.Writer) error {
+	unavailable := false
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, cred := range authCredentials {
+		inKeyring, err := credentialInKeyring(cred)
+		if errors.Is(err, secrets.ErrUnavailable) {
+			unavailable = true
+		} else if err != nil {
+			return err
+		}
+
+		var sources []string
+		if credentialInEnv(cred) {
+			sources = append(sources, "set in "+cred.envVar)
+		}
+		if inKeyring {
+			sources = append(sources, "stored in the system keyring")
+		}
+		if cred.provider == "" && credentialInConfigFile() {
+			sources = append(sources, "in the config file as plain text (move it with forge auth login llm)")
+		}
+		if len(sources) == 0 {
+			sources = append(sources, "not set")
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", cred.name, cred.label, strings.Join(sources, ", "))
+	}
+
+	fmt.Fprintln(out, "Credentials (the environment takes precedence over the keyring):")
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if unavailable {
+		fmt.Fprintln(out, "\nNo system keyring is available: on Linux install libsecret's secret-tool and run a Secret Service such as GNOME Keyring.")
+	}
+	return nil
+}
+
+// authLogout removes credentials from the system keyring. Naming a
+// credential that is not stored is reported; removing all of them is quiet
+// about the ones that were never stored.
+func authLogout(creds []authCredential, named bool) error {
+	removed := 0
+	for _, cred := range creds {
+		stored, err := credentialInKeyring(cred)
+		if err != nil {
+			return err
+		}
+		if cred.provider == "" && credentialInConfigFile() {
+			stored = true
+		}
+		if !stored {
+			if named {
+				fmt.Printf("No %s is stored\n", cred.label)
+			}
+			continue
+		}
+
+		if cred.provider == "" {
+			if err := appconfig.GetLLM().StoreAPIKey(""); err != nil {
+				return fmt.Errorf("failed to remove the %s: %w", cred.label, err)
+			}
+			if err := appconfig.Global().SaveAll(); err != nil {
+				return fmt.Errorf("failed to save configuration: %w", err)
+			}
+		} else if err := secrets.Delete(secrets.GitTokenAccount(cred.provider)); err != nil {
+			return fmt.Errorf("failed to remove the %s: %w", cred.label, err)
+		}
+		removed++
+
+		fmt.Printf("✓ Removed the %s\n", cred.label)
+		if credentialInEnv(cred) {
+			fmt.Printf("  %s is still set in the environment\n", cred.envVar)
+		}
+	}
+
+	if removed == 0 && !named {
+		fmt.Println("No credentials are stored")
+	}
+	return nil
+}
+
+// credentialInKeyring reports whether the keyring holds cred.
+func credentialInKeyring(cred authCredential) (bool, error) {
+	account := secrets.AccountLLMAPIKey
+	if cred.provider != "" {
+		account = secrets.GitTokenAccount(cred.provider)
+	}
+	_, err := secrets.Get(account)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, secrets.ErrNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// credentialInEnv reports whether the environment provides cred.
+func credentialInEnv(cred authCredential) bool {
+	if cred.provider != "" {
+		return git.TokenFromEnv(cred.provider, "") != ""
+	}
+	return os.Getenv(cred.envVar) != ""
+}
+
+// credentialInConfigFile reports whether the LLM API key is written to the
+// config file rather than kept in the keyring.
+func credentialInConfigFile() bool {
+	llmConfig := appconfig.GetLLM()
+	return llmConfig != nil && llmConfig.GetAPIKeyStorage() != appconfig.APIKeyStorageKeyring && llmConfig.GetAPIKey() != ""
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/bootstrap"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/executor/tui"
+)
+
+// setUpProject runs the project's setup before the session starts when its
+// bootstrap settings ask for it, and otherwise offers /setup when
+// dependencies look missing. Setup never runs in mock mode, where the
+// agent's commands are simulated.
+func setUpProject(ctx context.Context, config *Config, projectConfig *appconfig.ProjectConfig, executor *tui.Executor) {
+	settings := projectConfig.GetBootstrap()
+	if !settings.Enabled {
+		return
+	}
+	steps := bootstrap.Plan(config.WorkspaceDir, settings.Commands)
+
+	if !settings.AutoRun || config.MockTools {
+		if installs := bootstrap.Installs(steps); len(installs) > 0 {
+			executor.AddStartupWarning("Project setup available",
+				fmt.Sprintf("%s. Run /setup to run: %s", installs[0].Reason, bootstrap.Describe(steps)), false)
+		}
+		return
+	}
+
+	if len(steps) == 0 {
+		return
+	}
+	fmt.Printf("Setting up project...\n")
+	results := bootstrap.RunAll(ctx, config.WorkspaceDir, steps, settings.Timeout, func(result bootstrap.Result) {
+		if result.Failed() {
+			fmt.Printf("  ✗ %s: %s\n", result.Step.Command, result.Error)
+			return
+		}
+		fmt.Printf("  ✓ %s (%s)\n", result.Step.Command, result.Duration)
+	})
+	if failure, failed := bootstrap.FirstFailure(results); failed {
+		executor.AddStartupWarning("Project setup failed",
+			fmt.Sprintf("%s: %s. Fix it and run /setup again.", failure.Step.Command, failure.Error), true)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	agentcontext "github.com/entrhq/forge/pkg/agent/context"
+	"github.com/entrhq/forge/pkg/agent/longtermmemory/capture"
+	"github.com/entrhq/forge/pkg/agent/memory/vector"
+)
+
+// attachStrategyMemory connects the built-in summarization strategies to
+// long-term memory: tool call summaries are indexed in vector memory and
+// goal-batch compactions are sent to the capture observer. Either may be nil.
+func attachStrategyMemory(strategies []agentcontext.Strategy, observer *capture.Observer, vectorMemory *vector.Memory, sessionID string) {
+	for _, strategy := range strategies {
+		switch s := strategy.(type) {
+		case *agentcontext.ToolCallSummarizationStrategy:
+			s.SetVectorMemory(vectorMemory, sessionID)
+		case *agentcontext.GoalBatchCompactionStrategy:
+			if observer != nil {
+				s.SetCaptureObserver(observer, sessionID)
+			}
+		}
+	}
+}
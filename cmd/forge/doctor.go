@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/entrhq/forge/pkg/doctor"
+	"github.com/entrhq/forge/pkg/llm/openai"
+)
+
+// runDoctor checks the environment Forge needs and prints a fix for each
+// problem it finds. It fails when a check that blocks Forge from running
+// fails; warnings about optional features do not.
+func runDoctor(ctx context.Context, config *Config) error {
+	fmt.Printf("Forge v%s doctor\n\n", version)
+
+	results := []doctor.Result{doctor.CheckGlobalConfig("")}
+	results = append(results, doctor.CheckProjectConfig(config.WorkspaceDir))
+	if config.HeadlessConfig != "" {
+		results = append(results, checkHeadlessConfig(config))
+	}
+
+	var cliModel, cliBaseURL, cliAPIKey string
+	if config.Model != nil {
+		cliModel = *config.Model
+	}
+	if config.BaseURL != nil {
+		cliBaseURL = *config.BaseURL
+	}
+	if config.APIKey != nil {
+		cliAPIKey = *config.APIKey
+	}
+	provider, err := openai.BuildProvider(cliModel, cliBaseURL, cliAPIKey, defaultModel)
+	results = append(results, doctor.CheckProvider(ctx, provider, err, config.Offline)...)
+
+	model := defaultModel
+	if provider != nil {
+		model = provider.GetModel()
+	}
+	results = append(results, doctor.CheckTokenizer(model))
+	results = append(results, doctor.CheckGit(ctx, config.WorkspaceDir)...)
+	results = append(results, doctor.CheckBrowser(config.Offline))
+	results = append(results, doctor.CheckWorkspace(config.WorkspaceDir))
+
+	summary := doctor.Report(os.Stdout, results)
+	if summary.Failures > 0 {
+		return fmt.Errorf("doctor found %d problem(s)", summary.Failures)
+	}
+	return nil
+}
+
+// checkHeadlessConfig validates the file passed with -headless-config the way
+// -headless loads it.
+func checkHeadlessConfig(config *Config) doctor.Result {
+	const name = "Headless config"
+	execConfig, err := loadAndValidateConfig(config)
+	if err == nil && config.Offline {
+		err = execConfig.ValidateOffline()
+	}
+	if err != nil {
+		return doctor.Result{Name: name, Status: doctor.StatusFail, Detail: err.Error(), Fix: "Fix " + config.HeadlessConfig}
+	}
+	return doctor.Result{Name: name, Status: doctor.StatusOK, Detail: config.HeadlessConfig}
+}
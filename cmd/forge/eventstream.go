@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/entrhq/forge/pkg/executor/eventstream"
+)
+
+// eventStreamCloseTimeout bounds how long the session waits for stream
+// clients to receive its last events
+const eventStreamCloseTimeout = 5 * time.Second
+
+// startEventStream serves the session's events over WebSocket on
+// config.StreamAddr, for dashboards and observers that watch it live, and
+// returns the stream's URL. The stream is nil when no address is set.
+func startEventStream(config *Config) (*eventstream.Stream, string, error) {
+	if config.StreamAddr == "" {
+		return nil, "", nil
+	}
+
+	token := config.StreamToken
+	if token == "" {
+		token = os.Getenv("FORGE_STREAM_TOKEN")
+	}
+	if token == "" {
+		return nil, "", fmt.Errorf("-stream-addr requires a token; set -stream-token or FORGE_STREAM_TOKEN")
+	}
+
+	stream, err := eventstream.New(token)
+	if err != nil {
+		return nil, "", err
+	}
+	addr, err := stream.Listen(config.StreamAddr)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start the event stream: %w", err)
+	}
+	return stream, fmt.Sprintf("ws://%s/v1/events", addr), nil
+}
+
+// stopEventStream lets stream clients receive the session's last events,
+// then closes the stream.
+func stopEventStream(stream *eventstream.Stream) {
+	ctx, cancel := context.WithTimeout(context.Background(), eventStreamCloseTimeout)
+	defer cancel()
+	_ = stream.Close(ctx)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/coding/fileindex"
+)
+
+// startFileIndex starts indexing the guard's workspace for search_files and
+// find_files, and stops watching it when ctx is done. It returns nil, leaving
+// the tools to walk the workspace, when no file watcher can be created.
+func startFileIndex(ctx context.Context, guard *workspace.Guard) *fileindex.Index {
+	index, err := fileindex.New(guard)
+	if err != nil {
+		cmdLog.Warnf("file index disabled: %v", err)
+		return nil
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = index.Close()
+	}()
+	return index
+}
@@ -5,21 +5,30 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/entrhq/forge/pkg/agent"
 	agentcontext "github.com/entrhq/forge/pkg/agent/context"
+	"github.com/entrhq/forge/pkg/agent/hooks"
 	"github.com/entrhq/forge/pkg/agent/memory/notes"
+	"github.com/entrhq/forge/pkg/agent/recording"
+	"github.com/entrhq/forge/pkg/agent/todo"
 	"github.com/entrhq/forge/pkg/agent/tools"
 	appconfig "github.com/entrhq/forge/pkg/config"
 	"github.com/entrhq/forge/pkg/executor/headless"
 	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/llm/openai"
+	"github.com/entrhq/forge/pkg/security/offline"
+	"github.com/entrhq/forge/pkg/security/sandbox"
 	"github.com/entrhq/forge/pkg/security/workspace"
 	"github.com/entrhq/forge/pkg/tools/browser"
 	"github.com/entrhq/forge/pkg/tools/coding"
 	"github.com/entrhq/forge/pkg/tools/custom"
+	"github.com/entrhq/forge/pkg/tools/mock"
 	"github.com/entrhq/forge/pkg/tools/scratchpad"
+	todotools "github.com/entrhq/forge/pkg/tools/todo"
+	"github.com/entrhq/forge/pkg/tools/web"
 	"gopkg.in/yaml.v3"
 )
 
@@ -32,12 +41,87 @@ func runHeadless(ctx context.Context, config *Config) error {
 	if err != nil {
 		return err
 	}
+	if config.Offline {
+		if offlineErr := execConfig.ValidateOffline(); offlineErr != nil {
+			return offlineErr
+		}
+	}
+
+	// Work in a throwaway worktree so the primary checkout is left untouched
+	if execConfig.Git.UseWorktree {
+		worktree, worktreeErr := headless.EnterWorktree(ctx, execConfig)
+		if worktreeErr != nil {
+			return worktreeErr
+		}
+		cmdLog.Infof("Working in worktree %s", worktree.Dir())
+		defer func() {
+			kept, closeErr := worktree.Close()
+			switch {
+			case closeErr != nil:
+				cmdLog.Warnf("Failed to remove the worktree %s: %v", worktree.Dir(), closeErr)
+			case kept:
+				cmdLog.Warnf("Kept the worktree, which has uncommitted changes: %s", worktree.Dir())
+			}
+		}()
+	}
 
 	// Initialize global configuration
 	if initErr := appconfig.Initialize(""); initErr != nil {
 		return fmt.Errorf("failed to initialize configuration: %w", initErr)
 	}
 
+	// Layer the workspace's shared .forge/config.yaml over the global config
+	projectConfig, err := appconfig.InitializeProject(execConfig.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+	if projectConfig != nil {
+		cmdLog.Infof("Loaded project configuration from %s", appconfig.ProjectConfigPath)
+	}
+
+	// Repository hooks run shell commands around tool calls and turns
+	hookConfig, err := hooks.LoadConfig(execConfig.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+	if hookConfig != nil {
+		cmdLog.Infof("Loaded shell hooks from %s", hooks.ConfigPath)
+	}
+
+	// Apply the administrator's approval policy, which user and project settings cannot loosen
+	policy, err := appconfig.InitializePolicy(appconfig.DefaultPolicyPath)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		cmdLog.Infof("Loaded approval policy from %s", policy.Path)
+	}
+
+	// Run in the profile selected with -profile
+	profile, err := loadProfile(config, projectConfig)
+	if err != nil {
+		return err
+	}
+	if profile != nil {
+		cmdLog.Infof("Using profile %s", profile.Name)
+	}
+
+	// Layer headless sampling parameters over the global per-role settings so the
+	// effective values are what gets applied and recorded in execution.json
+	execConfig.Sampling = execConfig.Sampling.WithDefaults(headless.SamplingConfig{
+		Agent:      llm.SamplingFromConfig(appconfig.SamplingRoleAgent),
+		Summarizer: llm.SamplingFromConfig(appconfig.SamplingRoleSummarizer),
+		Commit:     llm.SamplingFromConfig(appconfig.SamplingRoleCommit),
+	})
+	if execConfig.FallbackModel == "" {
+		execConfig.FallbackModel = llm.FallbackModelFromConfig()
+	}
+	execConfig.RateLimit = llm.RateLimitFromConfig().Merge(execConfig.RateLimit)
+	execConfig.ResponseCache = llm.ResponseCacheFromConfig().Merge(execConfig.ResponseCache)
+	if err := startResponseCache(execConfig.ResponseCache); err != nil {
+		return err
+	}
+
 	// Resolve LLM configuration with proper precedence:
 	// CLI flags -> Environment variables -> Config file -> Defaults
 	var cliModel, cliBaseURL, cliAPIKey string
@@ -52,44 +136,18 @@ func runHeadless(ctx context.Context, config *Config) error {
 	}
 
 	// Build the LLM provider, respecting config file and CLI flag precedence
-	provider, err := openai.BuildProvider(cliModel, cliBaseURL, cliAPIKey, defaultModel)
+	provider, err := openai.BuildProvider(profileModel(cliModel, profile), cliBaseURL, cliAPIKey, defaultModel)
 	if err != nil {
 		return err
 	}
+	llm.SharedRateLimiter().SetLimits(execConfig.RateLimit)
 
-	// Create context manager for headless execution
-	toolCallStrategy := agentcontext.NewToolCallSummarizationStrategy(
-		defaultToolCallAge,
-		defaultMinToolCalls,
-		defaultMaxToolCallDist,
-	)
-
-	// Strategy 2: Half-compaction when context crosses the token threshold.
-	thresholdStrategy := agentcontext.NewThresholdSummarizationStrategy(
-		defaultThresholdTrigger,
-	)
-
-	goalBatchStrategy := agentcontext.NewGoalBatchCompactionStrategy(
-		defaultGoalBatchTurnsOld,
-		defaultGoalBatchMinTurns,
-		defaultGoalBatchMaxTurns,
-	)
-
-	contextManager, err := agentcontext.NewManager(
-		provider,
-		defaultMaxTokens,
-		toolCallStrategy,
-		thresholdStrategy,
-		goalBatchStrategy,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create context manager: %w", err)
-	}
-
-	// Apply summarization model override from config (no-op if not configured)
-	if llmCfg := appconfig.GetLLM(); llmCfg != nil {
-		if summarizationModel := llmCfg.GetSummarizationModel(); summarizationModel != "" {
-			contextManager.SetSummarizationModel(summarizationModel)
+	// Offline mode requires a local model server and turns off network-touching capabilities
+	var offlineReport *offline.Report
+	if config.Offline {
+		offlineReport, err = newOfflineReport(provider)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -115,12 +173,10 @@ func runHeadless(ctx context.Context, config *Config) error {
 			embedder = nil
 		}
 	}
-
-	// Create workspace security guard
-	guard, err := workspace.NewGuard(execConfig.WorkspaceDir)
-	if err != nil {
-		return fmt.Errorf("failed to create workspace guard: %w", err)
+	if offlineReport != nil {
+		embedder = offlineEmbedder(embedder, offlineReport)
 	}
+	vectorMemory := startVectorMemory(ctx, embedder, execConfig.WorkspaceDir)
 
 	// Whitelist custom tools directory for custom tool operations
 	homeDir, err := os.UserHomeDir()
@@ -128,8 +184,64 @@ func runHeadless(ctx context.Context, config *Config) error {
 		return fmt.Errorf("failed to get home directory: %w", err)
 	}
 	customToolsDir := filepath.Join(homeDir, ".forge", "tools")
-	if err := guard.AddWhitelist(customToolsDir); err != nil {
-		return fmt.Errorf("failed to whitelist custom tools directory: %w", err)
+
+	// newGuard creates the workspace security guard and command sandbox for a
+	// workspace. Task matrix runs give each task its own worktree, so each
+	// task gets its own.
+	newGuard := func(workspaceDir string) (*workspace.Guard, *sandbox.Sandbox, error) {
+		guard, err := workspace.NewGuard(workspaceDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create workspace guard: %w", err)
+		}
+
+		// Run model-chosen commands isolated from the host when configured
+		commandSandbox, err := sandbox.New(execConfig.Sandbox, guard.WorkspaceDir())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to set up command sandbox: %w", err)
+		}
+
+		if err := guard.AddWhitelist(customToolsDir); err != nil {
+			return nil, nil, fmt.Errorf("failed to whitelist custom tools directory: %w", err)
+		}
+
+		// Enforce the project's write-protection rules
+		if err := guard.SetPathRules(projectConfig.GuardPathRules()); err != nil {
+			return nil, nil, fmt.Errorf("failed to apply path rules: %w", err)
+		}
+
+		// Let tools work across the project's and command line's workspace roots
+		if err := guard.SetRoots(append(projectConfig.GuardRoots(), config.Roots...)); err != nil {
+			return nil, nil, fmt.Errorf("failed to apply workspace roots: %w", err)
+		}
+
+		// Let tools use the directories outside the workspace the project allows
+		if err := guard.SetAllowedDirs(projectConfig.GuardAllowedDirs()); err != nil {
+			return nil, nil, fmt.Errorf("failed to apply allowed directories: %w", err)
+		}
+
+		// Restrict a monorepo run to the configured workspace paths
+		if err := guard.SetScope(execConfig.Workspace.Paths); err != nil {
+			return nil, nil, fmt.Errorf("failed to apply workspace paths: %w", err)
+		}
+
+		// Commands may run in, and use, the roots and allowed directories too
+		if commandSandbox != nil {
+			for _, root := range guard.Roots() {
+				commandSandbox.Mount(root.Path, root.ReadOnly)
+			}
+			for _, dir := range guard.AllowedDirs() {
+				commandSandbox.Mount(dir.Path, dir.ReadOnly)
+			}
+		}
+		return guard, commandSandbox, nil
+	}
+
+	guard, commandSandbox, err := newGuard(execConfig.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+	if commandSandbox != nil {
+		cmdLog.Infof("Command sandbox: %s", commandSandbox.Describe())
 	}
 
 	// Compose the headless system prompt with mode-specific guidance
@@ -139,91 +251,269 @@ func runHeadless(ctx context.Context, config *Config) error {
 	if config.SystemPrompt != "" {
 		systemPrompt = config.SystemPrompt
 	}
-
-	// Load repository context from AGENTS.md if it exists
-	var repositoryContext string
-	agentsMdPath := filepath.Join(execConfig.WorkspaceDir, "AGENTS.md")
-	if agentsMdData, readErr := os.ReadFile(agentsMdPath); readErr == nil {
-		repositoryContext = string(agentsMdData)
+	systemPrompt = projectConfig.AppendInstructions(systemPrompt)
+	systemPrompt = appconfig.AppendExperimentalNotice(systemPrompt)
+	if offlineReport != nil {
+		systemPrompt += "\n\n" + offline.Instructions
+	}
+	if rootsInstructions := guard.RootsInstructions(); rootsInstructions != "" {
+		systemPrompt += "\n\n" + rootsInstructions
 	}
 
-	// Create notes manager for scratchpad
-	notesManager := notes.NewManager()
+	// Load repository context from AGENTS.md files
+	repositoryContext := newRepositoryContextLoader(execConfig.WorkspaceDir, projectConfig, config.NoAgentsMD)
 
-	// Create agent with headless system prompt, disabled interactive tools, and context management
-	agentOpts := []agent.AgentOption{
-		agent.WithCustomInstructions(systemPrompt),
-		agent.WithDisabledTools("ask_question", "converse"),
-		agent.WithContextManager(contextManager),
-		agent.WithEmbedder(embedder),
+	// In mock mode or a dry run, writes and commands go to an in-memory
+	// overlay shared by every agent
+	var overlay *mock.Overlay
+	if config.MockTools || execConfig.DryRun {
+		overlay = mock.NewOverlay(guard.WorkspaceDir())
+		cmdLog.Infof("Mock tools enabled: file writes and commands are simulated")
 	}
 
-	// Add repository context if available
-	if repositoryContext != "" {
-		agentOpts = append(agentOpts, agent.WithRepositoryContext(repositoryContext))
+	// Record the run for 'forge replay' when asked; a bundle holds one session
+	var recorder *recording.Recorder
+	if config.Record != "" {
+		if len(execConfig.Tasks) > 0 || execConfig.FanOut.Enabled() {
+			return fmt.Errorf("-record can only record a single headless run, not a task matrix or fan-out")
+		}
+		recorder, err = newSessionRecorder(config.Record, provider, execConfig.WorkspaceDir)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			cmdLog.Infof("%s", closeSessionRecorder(recorder, config.Record))
+		}()
 	}
 
-	ag := agent.NewDefaultAgent(provider, agentOpts...)
-
-	// Register coding tools with workspace guard
-	codingTools := []tools.Tool{
-		coding.NewReadFileTool(guard),
-		coding.NewWriteFileTool(guard),
-		coding.NewListFilesTool(guard),
-		coding.NewSearchFilesTool(guard),
-		coding.NewApplyDiffTool(guard),
-		coding.NewExecuteCommandTool(guard),
-		coding.NewAnalyzeDocumentTool(guard, provider),
+	// Stream every agent's events to external observers when asked
+	stream, streamURL, err := startEventStream(config)
+	if err != nil {
+		return err
 	}
+	if stream != nil {
+		defer stopEventStream(stream)
+		cmdLog.Infof("Streaming events on %s", streamURL)
+	}
+
+	// newAgent builds the agent for one execution. Fan-out runs build one per
+	// package so no conversation state carries over between packages.
+	newAgent := func(runConfig *headless.Config) (agent.Agent, error) {
+		// Matrix tasks run in their own worktrees
+		runGuard, runSandbox := guard, commandSandbox
+		if runConfig.WorkspaceDir != execConfig.WorkspaceDir {
+			var err error
+			if runGuard, runSandbox, err = newGuard(runConfig.WorkspaceDir); err != nil {
+				return nil, err
+			}
+		}
 
-	for _, tool := range codingTools {
-		if regErr := ag.RegisterTool(tool); regErr != nil {
-			return fmt.Errorf("failed to register tool: %w", regErr)
+		// Create context manager for headless execution
+		contextStrategies, err := agentcontext.NewStrategies(projectConfig.GetContextStrategies())
+		if err != nil {
+			return nil, err
+		}
+		contextManager, err := agentcontext.NewManager(provider, defaultMaxTokens, contextStrategies...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create context manager: %w", err)
 		}
-	}
 
-	// Register scratchpad tools
-	scratchpadTools := []tools.Tool{
-		scratchpad.NewAddNoteTool(notesManager),
-		scratchpad.NewListNotesTool(notesManager),
-		scratchpad.NewSearchNotesTool(notesManager),
-		scratchpad.NewListTagsTool(notesManager),
-		scratchpad.NewScratchNoteTool(notesManager),
-		scratchpad.NewUpdateNoteTool(notesManager),
-	}
+		// Apply summarization model override from config (no-op if not configured)
+		if llmCfg := appconfig.GetLLM(); llmCfg != nil {
+			if summarizationModel := llmCfg.GetSummarizationModel(); summarizationModel != "" {
+				contextManager.SetSummarizationModel(summarizationModel)
+			}
+		}
+		contextManager.SetSummarizationSampling(runConfig.Sampling.Summarizer)
+
+		// Create notes manager for scratchpad
+		notesManager := notes.NewManager()
+
+		// Create agent with headless system prompt, disabled interactive tools, and context management
+		agentOpts := []agent.AgentOption{
+			agent.WithCustomInstructions(systemPrompt),
+			agent.WithDisabledTools("ask_question", "converse"),
+			agent.WithDisabledTools(projectConfig.GetDisabledTools()...),
+			agent.WithDisabledTools(appconfig.DisabledExperimentalTools()...),
+			agent.WithWorkspaceDir(runConfig.WorkspaceDir),
+			agent.WithWorkspaceGuard(runGuard),
+			agent.WithHookConfig(hookConfig),
+			agent.WithToolCallMode(llm.ToolCallModeFromConfig()),
+			agent.WithMessageTokenLimit(
+				runConfig.Constraints.MessageTokenLimit(),
+				agent.OversizedMessagePolicy(runConfig.Constraints.OversizedMessages),
+			),
+			agent.WithToolLimits(projectConfig.GetToolLimits().Merge(runConfig.Constraints.ToolLimits)),
+			agent.WithToolSchemas(projectConfig.GetToolSchemas()),
+			agent.WithContextManager(contextManager),
+			agent.WithEmbedder(embedder),
+			agent.WithVectorMemory(vectorMemory),
+		}
 
-	for _, tool := range scratchpadTools {
-		if regErr := ag.RegisterTool(tool); regErr != nil {
-			return fmt.Errorf("failed to register scratchpad tool: %w", regErr)
+		// Add repository context if available
+		if repositoryContext != nil {
+			agentOpts = append(agentOpts, agent.WithRepositoryContextLoader(repositoryContext))
+		}
+		// Learnings go to the primary workspace, not a run's worktree
+		readOnlyMemory := overlay != nil || runConfig.Mode == headless.ModeReadOnly
+		agentOpts = append(agentOpts, projectMemoryOptions(execConfig.WorkspaceDir, projectConfig, provider, readOnlyMemory)...)
+		if recorder != nil {
+			agentOpts = append(agentOpts, agent.WithRecorder(recorder))
+		}
+		if stream != nil {
+			agentOpts = append(agentOpts, agent.WithEventObserver(stream))
 		}
-	}
 
-	// Register custom tool management tools
-	customTools := []tools.Tool{
-		custom.NewCreateCustomToolTool(),
-		custom.NewRunCustomToolTool(guard),
-	}
+		agentProvider := llm.WithFallback(llm.WithSampling(llm.WithRequestLog(provider, llm.SharedRequestLog()), runConfig.Sampling.Agent), runConfig.FallbackModel)
+		ag := agent.NewDefaultAgent(agentProvider, agentOpts...)
+		ag.SetProfile(profile)
+		attachStrategyMemory(contextStrategies, nil, vectorMemory, ag.GetSessionID())
+
+		// Register coding tools with workspace guard
+		executeCommand := coding.NewExecuteCommandTool(runGuard)
+		executeCommand.SetSandbox(runSandbox)
+		runTests := coding.NewRunTestsTool(runGuard)
+		runTests.SetSandbox(runSandbox)
+		viewImage := coding.NewViewImageTool(runGuard)
+		viewImage.SetScreenshotDir(runConfig.ScreenshotDir())
+		codingTools := []tools.Tool{
+			coding.NewReadFileTool(runGuard),
+			coding.NewWriteFileTool(runGuard),
+			coding.NewListFilesTool(runGuard),
+			coding.NewSearchFilesTool(runGuard),
+			coding.NewFindFilesTool(runGuard),
+			coding.NewApplyDiffTool(runGuard),
+			coding.NewRenameSymbolTool(runGuard),
+			coding.NewDeleteFileTool(runGuard),
+			coding.NewMoveFileTool(runGuard),
+			coding.NewCreateDirectoryTool(runGuard),
+			executeCommand,
+			runTests,
+			coding.NewAnalyzeDocumentTool(runGuard, provider),
+			viewImage,
+		}
 
-	for _, tool := range customTools {
-		if regErr := ag.RegisterTool(tool); regErr != nil {
-			return fmt.Errorf("failed to register custom tool: %w", regErr)
+		// In mock mode, route writes and commands through an in-memory overlay
+		if overlay != nil {
+			codingTools = mock.Wrap(codingTools, runGuard, overlay)
 		}
-	}
 
-	// Register browser tools using the browser registry
-	browserManager := browser.NewSessionManager()
-	browserRegistry := browser.NewToolRegistry(browserManager)
-	browserRegistry.SetLLMProvider(provider) // Enable AI-powered browser tools
-	browserTools := browserRegistry.RegisterTools()
+		for _, tool := range codingTools {
+			if regErr := ag.RegisterTool(tool); regErr != nil {
+				return nil, fmt.Errorf("failed to register tool: %w", regErr)
+			}
+		}
+
+		// Register scratchpad tools
+		scratchpadTools := []tools.Tool{
+			scratchpad.NewAddNoteTool(notesManager),
+			scratchpad.NewListNotesTool(notesManager),
+			scratchpad.NewSearchNotesTool(notesManager),
+			scratchpad.NewListTagsTool(notesManager),
+			scratchpad.NewScratchNoteTool(notesManager),
+			scratchpad.NewUpdateNoteTool(notesManager),
+		}
+
+		for _, tool := range scratchpadTools {
+			if regErr := ag.RegisterTool(tool); regErr != nil {
+				return nil, fmt.Errorf("failed to register scratchpad tool: %w", regErr)
+			}
+		}
+
+		// Register todo list tools
+		todoList := todo.NewList()
+		todoTools := []tools.Tool{
+			todotools.NewCreateTodoListTool(todoList),
+			todotools.NewUpdateTodoTool(todoList),
+		}
+
+		for _, tool := range todoTools {
+			if regErr := ag.RegisterTool(tool); regErr != nil {
+				return nil, fmt.Errorf("failed to register todo tool: %w", regErr)
+			}
+		}
 
-	for _, tool := range browserTools {
-		if regErr := ag.RegisterTool(tool); regErr != nil {
-			return fmt.Errorf("failed to register browser tool: %w", regErr)
+		// Register custom tool management tools, which mock mode and the
+		// sandbox cannot contain: custom tools run arbitrary scripts on the host
+		// and are saved in ~/.forge/tools
+		if overlay == nil && !execConfig.Sandbox.Enabled() {
+			customTools := []tools.Tool{
+				custom.NewCreateCustomToolTool(),
+				custom.NewRunCustomToolTool(runGuard),
+			}
+
+			for _, tool := range customTools {
+				if regErr := ag.RegisterTool(tool); regErr != nil {
+					return nil, fmt.Errorf("failed to register custom tool: %w", regErr)
+				}
+			}
 		}
+
+		// Register web and browser tools (they need the network). Mock mode
+		// leaves them out, since requests and page interactions cannot be simulated
+		if offlineReport == nil && overlay == nil {
+			if regErr := ag.RegisterTool(web.NewFetchURLTool()); regErr != nil {
+				return nil, fmt.Errorf("failed to register web tool: %w", regErr)
+			}
+			if regErr := ag.RegisterTool(web.NewHTTPRequestTool()); regErr != nil {
+				return nil, fmt.Errorf("failed to register web tool: %w", regErr)
+			}
+			if searchProvider, searchErr := web.SearchProviderFromEnv(); searchErr != nil {
+				cmdLog.Warnf("Web search unavailable: %v", searchErr)
+			} else if searchProvider != nil {
+				if regErr := ag.RegisterTool(web.NewWebSearchTool(searchProvider)); regErr != nil {
+					return nil, fmt.Errorf("failed to register web tool: %w", regErr)
+				}
+			}
+
+			browserManager := browser.NewSessionManager()
+			browserRegistry := browser.NewToolRegistry(browserManager)
+			browserRegistry.SetLLMProvider(provider) // Enable AI-powered browser tools
+			browserRegistry.SetScreenshotDir(runConfig.ScreenshotDir())
+			browserTools := browserRegistry.RegisterTools()
+
+			// Headless runs cannot install Playwright interactively; use fetch_url instead
+			if ui := appconfig.GetUI(); ui != nil && ui.IsBrowserEnabled() {
+				if err := browserManager.DetectInstallation(); err != nil {
+					cmdLog.Infof("Browser tools unavailable, using fetch_url instead: %v", err)
+				}
+			}
+
+			for _, tool := range browserTools {
+				if regErr := ag.RegisterTool(tool); regErr != nil {
+					return nil, fmt.Errorf("failed to register browser tool: %w", regErr)
+				}
+			}
+		}
+
+		return ag, nil
 	}
 
-	// Create and run executor
-	return runExecutor(ctx, ag, execConfig)
+	if offlineReport != nil {
+		for _, line := range strings.Split(strings.TrimSpace(offlineReport.Banner()), "\n") {
+			cmdLog.Infof("%s", line)
+		}
+	}
+
+	// Create and run executor, fanning the task out per package or running
+	// the task matrix when configured
+	var runErr error
+	switch {
+	case len(execConfig.Tasks) > 0:
+		runErr = runMatrix(ctx, newAgent, execConfig)
+	case execConfig.FanOut.Enabled():
+		runErr = runFanOut(ctx, newAgent, execConfig)
+	default:
+		ag, agentErr := newAgent(execConfig)
+		if agentErr != nil {
+			return agentErr
+		}
+		runErr = runExecutor(ctx, ag, execConfig, overlay)
+	}
+	if overlay != nil {
+		printMockSummary(overlay)
+	}
+	return runErr
 }
 
 // loadAndValidateConfig loads and validates headless configuration
@@ -246,6 +536,26 @@ func loadAndValidateConfig(config *Config) (*headless.Config, error) {
 	if config.WorkspaceDir != "." {
 		execConfig.WorkspaceDir = config.WorkspaceDir
 	}
+	if config.DryRun {
+		execConfig.DryRun = true
+	}
+	if config.RunID != "" {
+		execConfig.RunID = config.RunID
+	}
+
+	// Fill the task template, flags taking precedence over the JSON payload
+	vars := headless.TaskVars{}
+	if config.TaskVarsFile != "" {
+		if vars, err = headless.LoadTaskVars(config.TaskVarsFile); err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range config.TaskVars {
+		vars[name] = value
+	}
+	if renderErr := execConfig.RenderTaskTemplate(vars); renderErr != nil {
+		return nil, renderErr
+	}
 
 	// Validate configuration
 	if validationErr := execConfig.Validate(); validationErr != nil {
@@ -274,12 +584,14 @@ func loadHeadlessConfigFromFile(path string) (*headless.Config, error) {
 	return config, nil
 }
 
-// runExecutor creates and runs the headless executor
-func runExecutor(ctx context.Context, ag *agent.DefaultAgent, execConfig *headless.Config) error {
+// runExecutor creates and runs the headless executor. overlay is the
+// overlay the agent's tools write to in mock mode or a dry run, or nil.
+func runExecutor(ctx context.Context, ag agent.Agent, execConfig *headless.Config, overlay *mock.Overlay) error {
 	executor, err := headless.NewExecutor(ag, execConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create executor: %w", err)
 	}
+	executor.SetOverlay(overlay)
 
 	// Apply timeout if configured
 	if execConfig.Constraints.Timeout > 0 {
@@ -304,6 +616,54 @@ func runExecutor(ctx context.Context, ag *agent.DefaultAgent, execConfig *headle
 	return nil
 }
 
+// runFanOut creates and runs the fan-out executor, which builds an agent per
+// package with newAgent. The timeout applies to each package's execution.
+func runFanOut(ctx context.Context, newAgent headless.AgentFactory, execConfig *headless.Config) error {
+	executor, err := headless.NewFanOutExecutor(execConfig, newAgent)
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	cmdLog.Infof("Starting headless fan-out execution...")
+	cmdLog.Infof("Task: %s", execConfig.Task)
+	cmdLog.Infof("Mode: %s", execConfig.Mode)
+	cmdLog.Infof("Workspace: %s", execConfig.WorkspaceDir)
+
+	startTime := time.Now()
+	if runErr := executor.Run(ctx); runErr != nil {
+		return fmt.Errorf("execution failed: %w", runErr)
+	}
+
+	duration := time.Since(startTime)
+	cmdLog.Infof("Execution completed successfully in %s", duration)
+	return nil
+}
+
+// runMatrix creates and runs the matrix executor, which builds an agent per
+// task with newAgent. The timeout applies to each task's execution.
+func runMatrix(ctx context.Context, newAgent headless.AgentFactory, execConfig *headless.Config) error {
+	executor, err := headless.NewMatrixExecutor(execConfig, newAgent)
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	cmdLog.Infof("Starting headless task matrix...")
+	for _, task := range execConfig.Tasks {
+		cmdLog.Infof("Task %s: %s", task.Name, task.Task)
+	}
+	cmdLog.Infof("Mode: %s", execConfig.Mode)
+	cmdLog.Infof("Workspace: %s", execConfig.WorkspaceDir)
+
+	startTime := time.Now()
+	if runErr := executor.Run(ctx); runErr != nil {
+		return fmt.Errorf("execution failed: %w", runErr)
+	}
+
+	duration := time.Since(startTime)
+	cmdLog.Infof("Execution completed successfully in %s", duration)
+	return nil
+}
+
 // composeHeadlessSystemPrompt creates a system prompt for headless execution
 func composeHeadlessSystemPrompt(mode headless.ExecutionMode) string {
 	basePrompt := composeSystemPrompt()
@@ -320,7 +680,7 @@ You are operating in READ-ONLY headless mode. Your mission is to analyze and rep
 **CRITICAL CONSTRAINTS:**
 - You MUST NOT modify any files (no write_file, no apply_diff)
 - You MUST NOT execute commands that modify the workspace
-- You MUST only use read operations: read_file, list_files, search_files
+- You MUST only use read operations: read_file, list_files, search_files, find_files
 - Focus on analysis, documentation, and recommendations
 
 **Your Task:**
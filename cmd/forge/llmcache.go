@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// startResponseCache turns on caching of deterministic model responses
+// (summarization and commit message and PR generation) when cfg enables it.
+func startResponseCache(cfg appconfig.ResponseCache) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	cache, err := llm.NewResponseCache(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open the LLM response cache: %w", err)
+	}
+	llm.SetSharedResponseCache(cache)
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// startRequestLog turns on logging of the agent's model requests for
+// -llm-log. Providers built for the agent from then on write each request
+// and its response to the workspace's .forge/llm-logs.
+func startRequestLog(config *Config) error {
+	if !config.LLMLog {
+		return nil
+	}
+	dir := filepath.Join(config.WorkspaceDir, llm.RequestLogDir)
+	log, err := llm.NewRequestLog(dir)
+	if err != nil {
+		return err
+	}
+	llm.SetSharedRequestLog(log)
+	// stderr keeps the notice out of the ACP protocol on stdout
+	fmt.Fprintf(os.Stderr, "Logging LLM requests to %s\n", dir)
+	return nil
+}
@@ -11,26 +11,36 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/entrhq/forge/pkg/agent"
 	agentcontext "github.com/entrhq/forge/pkg/agent/context"
+	"github.com/entrhq/forge/pkg/agent/hooks"
 	"github.com/entrhq/forge/pkg/agent/longtermmemory"
 	"github.com/entrhq/forge/pkg/agent/longtermmemory/capture"
 	"github.com/entrhq/forge/pkg/agent/longtermmemory/retrieval"
 	"github.com/entrhq/forge/pkg/agent/memory/notes"
+	"github.com/entrhq/forge/pkg/agent/todo"
 	"github.com/entrhq/forge/pkg/agent/tools"
 	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/executor/headless"
 	"github.com/entrhq/forge/pkg/executor/tui"
 	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/llm/openai"
 	frameworkVersion "github.com/entrhq/forge/pkg/version"
 
+	"github.com/entrhq/forge/pkg/security/offline"
 	"github.com/entrhq/forge/pkg/security/workspace"
 	"github.com/entrhq/forge/pkg/tools/browser"
 	"github.com/entrhq/forge/pkg/tools/coding"
 	"github.com/entrhq/forge/pkg/tools/custom"
+	"github.com/entrhq/forge/pkg/tools/mock"
 	"github.com/entrhq/forge/pkg/tools/scratchpad"
+	"github.com/entrhq/forge/pkg/tools/terminal"
+	todotools "github.com/entrhq/forge/pkg/tools/todo"
+	"github.com/entrhq/forge/pkg/tools/web"
 )
 
 const (
@@ -39,31 +49,56 @@ const (
 
 	// Context management defaults for coding sessions
 	// These are tuned for long coding sessions with many file operations
-	defaultMaxTokens       = 100000 // Conservative limit with headroom for 128K context
-	defaultToolCallAge     = 20     // Tool calls must be 20+ messages old to enter buffer
-	defaultMinToolCalls    = 10     // Minimum 10 tool calls in buffer before summarizing
-	defaultMaxToolCallDist = 40     // Force summarization if any tool call is 40+ messages old
-
-	// Goal-batch compaction defaults (strategy 3: compact old completed turns into goal-batch blocks)
-	defaultGoalBatchTurnsOld = 20 // Turns must be 20+ messages old to be eligible for compaction
-	defaultGoalBatchMinTurns = 3  // Minimum 3 complete turns before triggering compaction
-	defaultGoalBatchMaxTurns = 6  // Compact at most 6 turns per LLM call
-
-	// Threshold summarization defaults (strategy 2: half-compaction when context is near full)
-	// Summarizes the older half of the conversation into a single summary, keeping the recent half verbatim.
-	defaultThresholdTrigger = 80.0 // Fire when context usage reaches 80% of the token limit
+	// Summarization strategies come from the project config's context section,
+	// defaulting to tool call summarization, threshold half-compaction and
+	// goal-batch compaction.
+	defaultMaxTokens = 100000 // Conservative limit with headroom for 128K context
+
+	// A single pasted log or tool result larger than this is chunked before it reaches the
+	// conversation, so one message cannot blow the context on its own.
+	defaultMaxMessageTokens = 25000
 )
 
 // Config holds the application configuration
 type Config struct {
-	APIKey         *string // Pointer to distinguish "not set" from "set to empty"
-	BaseURL        *string // Pointer to distinguish "not set" from "set to empty"
-	Model          *string // Pointer to distinguish "not set" from "set to default"
-	WorkspaceDir   string
-	SystemPrompt   string
-	ShowVersion    bool
-	Headless       bool
-	HeadlessConfig string
+	APIKey           *string // Pointer to distinguish "not set" from "set to empty"
+	BaseURL          *string // Pointer to distinguish "not set" from "set to empty"
+	Model            *string // Pointer to distinguish "not set" from "set to default"
+	WorkspaceDir     string
+	Roots            []workspace.Root // Additional workspace roots from -root and -root-ro
+	SystemPrompt     string
+	ShowVersion      bool
+	Headless         bool
+	HeadlessConfig   string
+	MockTools        bool
+	DryRun           bool              // Preview a headless run's changes in its artifacts
+	RunID            string            // Identifies a headless run in cancel requests and artifact uploads
+	TaskVars         headless.TaskVars // Values for the headless task_template's placeholders
+	TaskVarsFile     string            // JSON object of task_template values, or - for stdin
+	Offline          bool
+	MaxMessageTokens int
+	MaxTurnToolCalls int           // Tool calls in one turn before the agent is told to wrap up (0 disables)
+	MaxTurnDuration  time.Duration // Time one turn may run before the agent is told to wrap up (0 disables)
+	Serve            bool          // Set by the "serve" subcommand
+	ServeAddr        string
+	ServeToken       string
+	ACP              bool // Serve an editor plugin over JSON-RPC on stdio
+	Update           bool // Set by the "update" subcommand
+	UpdateChannel    string
+	UpdateCheckOnly  bool
+	Record           string // Record the session to this bundle for 'forge replay'
+	LLMLog           bool   // Write every LLM request and response to .forge/llm-logs
+	Replay           bool   // Set by the "replay" subcommand
+	ReplayBundle     string
+	ReplaySpeed      float64
+	MockProvider     bool     // Rerun the replay against the recorded model responses
+	Doctor           bool     // Set by the "doctor" subcommand
+	Auth             bool     // Set by the "auth" subcommand
+	AuthArgs         []string // login <credential>, status, or logout [credential]
+	Profile          string   // Named profile from ~/.forge/profiles.yaml or .forge/config.yaml
+	NoAgentsMD       bool     // Do not load AGENTS.md into the repository context
+	StreamAddr       string   // Stream the session's events over WebSocket on this address
+	StreamToken      string
 }
 
 func main() {
@@ -90,7 +125,12 @@ func main() {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigChan
-		fmt.Println("\n\nShutting down gracefully...")
+		// Stdout carries protocol messages in editor integration mode
+		if config.ACP {
+			fmt.Fprintln(os.Stderr, "Shutting down gracefully...")
+		} else {
+			fmt.Println("\n\nShutting down gracefully...")
+		}
 		cancel()
 	}()
 
@@ -104,7 +144,7 @@ func main() {
 
 // parseFlags parses command line flags and environment variables
 func parseFlags() *Config {
-	config := &Config{}
+	config := &Config{TaskVars: headless.TaskVars{}}
 
 	// Use temporary variables for flags
 	var apiKey, baseURL, model string
@@ -113,32 +153,112 @@ func parseFlags() *Config {
 	flag.StringVar(&baseURL, "base-url", "", "OpenAI API base URL (or set OPENAI_BASE_URL env var)")
 	flag.StringVar(&model, "model", "", "LLM model to use")
 	flag.StringVar(&config.WorkspaceDir, "workspace", ".", "Workspace directory (default: current directory)")
+	flag.Var(rootFlag{roots: &config.Roots}, "root", "Additional workspace root as name=path, referenced as @name/... (repeatable)")
+	flag.Var(rootFlag{roots: &config.Roots, readOnly: true}, "root-ro", "Additional read-only workspace root as name=path (repeatable)")
 	flag.StringVar(&config.SystemPrompt, "prompt", "", "Custom instructions for the agent (optional, overrides default)")
 	flag.BoolVar(&config.ShowVersion, "version", false, "Show version and exit")
 	flag.BoolVar(&config.Headless, "headless", false, "Run in headless mode (non-interactive)")
 	flag.StringVar(&config.HeadlessConfig, "headless-config", "", "Path to headless mode configuration file (YAML)")
+	flag.BoolVar(&config.MockTools, "mock-tools", false, "Simulate file writes and commands in memory without touching the workspace")
+	flag.BoolVar(&config.DryRun, "dry-run", false, "With -headless, simulate file writes and commands and write the would-be diff and gate predictions to the artifacts")
+	flag.StringVar(&config.RunID, "run-id", "", "With -headless, the run's ID for cancel requests and artifact uploads (default: generated)")
+	flag.Var(config.TaskVars, "var", "With -headless, a value for the task_template's {{name}} placeholder as name=value (repeatable)")
+	flag.StringVar(&config.TaskVarsFile, "vars-file", "", "With -headless, a JSON object of task_template values, or - to read it from stdin")
+	flag.BoolVar(&config.Offline, "offline", false, "Run without network access: require a local model server and disable network-touching tools")
+	flag.IntVar(&config.MaxMessageTokens, "max-message-tokens", defaultMaxMessageTokens, "Chunk any single message or tool result larger than this many tokens (0 disables)")
+	flag.IntVar(&config.MaxTurnToolCalls, "max-turn-tool-calls", 0, "Tell the agent to wrap up after this many tool calls in one turn, and stop it if it keeps going (0 disables)")
+	flag.DurationVar(&config.MaxTurnDuration, "max-turn-duration", 0, "Tell the agent to wrap up once a turn has run this long (e.g. 15m), and stop it if it keeps going (0 disables)")
+	flag.StringVar(&config.ServeAddr, "addr", defaultServeAddr, "Listen address for 'forge serve'")
+	flag.StringVar(&config.ServeToken, "serve-token", "", "Bearer token required by 'forge serve' (or set FORGE_SERVE_TOKEN env var)")
+	flag.BoolVar(&config.ACP, "acp", false, "Serve an editor plugin over JSON-RPC on stdin/stdout instead of starting the TUI")
+	flag.StringVar(&config.UpdateChannel, "channel", "", "Release channel for 'forge update': stable or beta (default: update.channel setting)")
+	flag.BoolVar(&config.UpdateCheckOnly, "check", false, "With 'forge update', only report whether an update is available")
+	flag.StringVar(&config.Record, "record", "", "Record the session to a bundle file that 'forge replay' can play back")
+	flag.BoolVar(&config.LLMLog, "llm-log", false, "Write every LLM request and streamed response, with secrets redacted, to .forge/llm-logs for prompt debugging")
+	flag.Float64Var(&config.ReplaySpeed, "replay-speed", 1, "With 'forge replay', playback speed relative to the recording (0 shows everything at once)")
+	flag.StringVar(&config.Profile, "profile", "", "Start in a named profile (instructions, tools, model and constraints) from ~/.forge/profiles.yaml or .forge/config.yaml")
+	flag.BoolVar(&config.NoAgentsMD, "no-agents-md", false, "Do not load AGENTS.md files into the agent's repository context")
+	flag.StringVar(&config.StreamAddr, "stream-addr", "", "Stream the session's events over WebSocket on this address (e.g. 127.0.0.1:7778) for external dashboards")
+	flag.StringVar(&config.StreamToken, "stream-token", "", "Token event stream clients must send (or set FORGE_STREAM_TOKEN env var)")
+	flag.BoolVar(&config.MockProvider, "mock-provider", false, "With 'forge replay', rerun the session through the current agent using the recorded model responses and report divergences")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Forge - A TUI coding agent\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: forge [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: forge [options]\n")
+		fmt.Fprintf(os.Stderr, "       forge serve [options]   Expose the agent over HTTP with SSE event streams\n")
+		fmt.Fprintf(os.Stderr, "       forge update [options]  Install the latest verified release\n")
+		fmt.Fprintf(os.Stderr, "       forge replay [options] <bundle>  Play back a session recorded with -record\n")
+		fmt.Fprintf(os.Stderr, "       forge doctor [options]  Check the environment and print fixes for problems\n")
+		fmt.Fprintf(os.Stderr, "       forge auth login|status|logout [credential]  Keep API keys and git tokens in the system keyring\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nEnvironment Variables:\n")
 		fmt.Fprintf(os.Stderr, "  OPENAI_API_KEY     OpenAI API key\n")
 		fmt.Fprintf(os.Stderr, "  OPENAI_BASE_URL    OpenAI API base URL (for compatible APIs)\n")
+		fmt.Fprintf(os.Stderr, "  FORGE_SERVE_TOKEN  Bearer token for forge serve\n")
+		fmt.Fprintf(os.Stderr, "  FORGE_STREAM_TOKEN Token for the -stream-addr event stream\n")
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  # TUI Mode (default)\n")
 		fmt.Fprintf(os.Stderr, "  forge                                    # Start in current directory\n")
 		fmt.Fprintf(os.Stderr, "  forge -workspace /path/to/project\n")
+		fmt.Fprintf(os.Stderr, "  forge -root proto=../shared-protos       # Work across two repositories\n")
 		fmt.Fprintf(os.Stderr, "  forge -model gpt-4-turbo\n")
 		fmt.Fprintf(os.Stderr, "  forge -base-url https://api.openrouter.ai/api/v1\n")
+		fmt.Fprintf(os.Stderr, "  forge -mock-tools                        # Dry-run edits and commands in memory\n")
+		fmt.Fprintf(os.Stderr, "  forge -offline -base-url http://localhost:11434/v1 -model qwen2.5-coder\n")
+		fmt.Fprintf(os.Stderr, "  forge -profile reviewer                  # Start in the reviewer profile\n")
 		fmt.Fprintf(os.Stderr, "\n  # Headless Mode (CI/CD)\n")
 		fmt.Fprintf(os.Stderr, "  forge -headless -headless-config config.yaml\n")
 		fmt.Fprintf(os.Stderr, "  forge -headless -headless-config config.yaml -workspace /path/to/project\n")
+		fmt.Fprintf(os.Stderr, "  forge -headless -headless-config config.yaml -dry-run  # Preview changes without touching the workspace\n")
+		fmt.Fprintf(os.Stderr, "  forge -headless -headless-config fix-issue.yaml -var issue_title=\"Login fails\"  # Fill the task_template\n")
+		fmt.Fprintf(os.Stderr, "\n  # API Server (web frontends, editor plugins)\n")
+		fmt.Fprintf(os.Stderr, "  forge serve -addr 127.0.0.1:7777 -serve-token secret\n")
+		fmt.Fprintf(os.Stderr, "\n  # Editor integration (JSON-RPC over stdio)\n")
+		fmt.Fprintf(os.Stderr, "  forge --acp -workspace /path/to/project\n")
+		fmt.Fprintf(os.Stderr, "\n  # Self-update\n")
+		fmt.Fprintf(os.Stderr, "  forge update                             # Install the latest stable release\n")
+		fmt.Fprintf(os.Stderr, "  forge update -channel beta -check        # Report whether a beta is available\n")
+		fmt.Fprintf(os.Stderr, "\n  # Environment check\n")
+		fmt.Fprintf(os.Stderr, "  forge doctor                             # Check config, API key, model, git and tools\n")
+		fmt.Fprintf(os.Stderr, "  forge doctor -headless-config ci.yaml    # Also validate a headless config\n")
+		fmt.Fprintf(os.Stderr, "\n  # Credentials\n")
+		fmt.Fprintf(os.Stderr, "  forge auth login llm                     # Store the LLM API key in the system keyring\n")
+		fmt.Fprintf(os.Stderr, "  echo \"$TOKEN\" | forge auth login github  # Store a git token from stdin\n")
+		fmt.Fprintf(os.Stderr, "  forge auth status                        # Show where each credential comes from\n")
+		fmt.Fprintf(os.Stderr, "\n  # Session recording and replay\n")
+		fmt.Fprintf(os.Stderr, "  forge -record session.jsonl              # Record the session\n")
+		fmt.Fprintf(os.Stderr, "  forge replay session.jsonl               # Re-render it in the TUI\n")
+		fmt.Fprintf(os.Stderr, "  forge replay -mock-provider session.jsonl  # Rerun it against the current agent\n")
+		fmt.Fprintf(os.Stderr, "\n  # Live event stream (dashboards, observers)\n")
+		fmt.Fprintf(os.Stderr, "  forge -stream-addr 127.0.0.1:7778 -stream-token secret\n")
+	}
+
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "serve" {
+		config.Serve = true
+		args = args[1:]
+	} else if len(args) > 0 && args[0] == "update" {
+		config.Update = true
+		args = args[1:]
+	} else if len(args) > 0 && args[0] == "replay" {
+		config.Replay = true
+		args = args[1:]
+	} else if len(args) > 0 && args[0] == "doctor" {
+		config.Doctor = true
+		args = args[1:]
+	} else if len(args) > 0 && args[0] == "auth" {
+		config.Auth = true
+		args = args[1:]
+	}
+	_ = flag.CommandLine.Parse(args) // ExitOnError: exits on parse failure
+	if config.Replay {
+		config.ReplayBundle = flag.Arg(0)
+	}
+	if config.Auth {
+		config.AuthArgs = flag.Args()
 	}
 
-	flag.Parse()
-
 	// Convert flag values to pointers only if they were explicitly set
 	// Check if flag was visited (explicitly set by user)
 	flagWasSet := make(map[string]bool)
@@ -169,6 +289,64 @@ func (c *Config) validate() error {
 		return fmt.Errorf("headless mode requires a configuration file (use -headless-config flag)")
 	}
 
+	if c.DryRun && !c.Headless {
+		return fmt.Errorf("-dry-run only applies to -headless; use -mock-tools to simulate changes in the TUI")
+	}
+
+	if c.RunID != "" && !c.Headless {
+		return fmt.Errorf("-run-id only applies to -headless")
+	}
+
+	if (len(c.TaskVars) > 0 || c.TaskVarsFile != "") && !c.Headless {
+		return fmt.Errorf("-var and -vars-file only apply to -headless")
+	}
+
+	if c.Serve && c.Headless {
+		return fmt.Errorf("serve and -headless cannot be combined")
+	}
+
+	if c.ACP && (c.Headless || c.Serve || c.Update) {
+		return fmt.Errorf("--acp cannot be combined with -headless, serve or update")
+	}
+
+	if c.Update && c.Offline {
+		return fmt.Errorf("update needs the network to download releases and cannot run with -offline")
+	}
+
+	if c.Replay && c.ReplayBundle == "" {
+		return fmt.Errorf("replay requires a recorded bundle (forge replay <bundle>)")
+	}
+
+	if c.MockProvider && !c.Replay {
+		return fmt.Errorf("-mock-provider only applies to 'forge replay'")
+	}
+
+	if c.Record != "" && (c.Serve || c.ACP || c.Update || c.Replay) {
+		return fmt.Errorf("-record only records TUI and -headless sessions")
+	}
+
+	if c.StreamAddr != "" && (c.Serve || c.ACP || c.Update || c.Replay || c.Doctor || c.Auth) {
+		return fmt.Errorf("-stream-addr only streams TUI and -headless sessions; forge serve streams its sessions itself")
+	}
+
+	if c.Profile != "" && (c.Serve || c.ACP || c.Update || c.Replay || c.Doctor || c.Auth) {
+		return fmt.Errorf("-profile only applies to TUI and -headless sessions")
+	}
+
+	if c.Doctor && (c.Headless || c.Serve || c.ACP || c.Update || c.Replay || c.Record != "") {
+		return fmt.Errorf("doctor cannot be combined with other modes; use -headless-config to check a headless configuration")
+	}
+
+	if c.Auth && (c.Headless || c.Serve || c.ACP || c.Update || c.Replay || c.Doctor || c.Record != "") {
+		return fmt.Errorf("auth cannot be combined with other modes")
+	}
+
+	// The doctor reports workspace problems itself, with a fix, and auth
+	// does not touch the workspace
+	if c.Doctor || c.Auth {
+		return nil
+	}
+
 	// Verify workspace directory exists (unless using headless config which will be validated later)
 	if !c.Headless || c.WorkspaceDir != "." {
 		info, err := os.Stat(c.WorkspaceDir)
@@ -185,11 +363,40 @@ func (c *Config) validate() error {
 
 // run executes the main application logic
 func run(ctx context.Context, config *Config) error {
+	if config.Update {
+		return runUpdate(ctx, config)
+	}
+
+	if config.Replay {
+		return runReplay(ctx, config)
+	}
+
+	if config.Doctor {
+		return runDoctor(ctx, config)
+	}
+
+	if config.Auth {
+		return runAuth(config)
+	}
+
+	// Log the agent's model requests when asked
+	if err := startRequestLog(config); err != nil {
+		return err
+	}
+
 	// Check if headless mode is requested
 	if config.Headless {
 		return runHeadless(ctx, config)
 	}
 
+	if config.Serve {
+		return runServe(ctx, config)
+	}
+
+	if config.ACP {
+		return runACP(ctx, config)
+	}
+
 	// Run TUI mode (default)
 	return runTUI(ctx, config)
 }
@@ -203,6 +410,45 @@ func runTUI(ctx context.Context, config *Config) error {
 		return fmt.Errorf("failed to initialize configuration: %w", err)
 	}
 
+	// Layer the workspace's shared .forge/config.yaml over the global config
+	projectConfig, err := appconfig.InitializeProject(config.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+	if projectConfig != nil {
+		fmt.Printf("Loaded project configuration from %s\n", appconfig.ProjectConfigPath)
+	}
+	if err := startResponseCache(llm.ResponseCacheFromConfig()); err != nil {
+		return err
+	}
+
+	// Repository hooks run shell commands around tool calls and turns
+	hookConfig, err := hooks.LoadConfig(config.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+	if hookConfig != nil {
+		fmt.Printf("Loaded shell hooks from %s\n", hooks.ConfigPath)
+	}
+
+	// Apply the administrator's approval policy, which user and project settings cannot loosen
+	policy, err := appconfig.InitializePolicy(appconfig.DefaultPolicyPath)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		fmt.Printf("Loaded approval policy from %s\n", policy.Path)
+	}
+
+	// Start in the profile selected with -profile
+	profile, err := loadProfile(config, projectConfig)
+	if err != nil {
+		return err
+	}
+	if profile != nil {
+		fmt.Printf("Using profile %s\n", profile.Name)
+	}
+
 	// Resolve LLM configuration with proper precedence:
 	// CLI flags -> Environment variables -> Config file -> Defaults
 	var cliModel, cliBaseURL, cliAPIKey string
@@ -216,41 +462,29 @@ func runTUI(ctx context.Context, config *Config) error {
 		cliAPIKey = *config.APIKey
 	}
 
-	provider, err := openai.BuildProvider(cliModel, cliBaseURL, cliAPIKey, defaultModel)
+	provider, err := openai.BuildProvider(profileModel(cliModel, profile), cliBaseURL, cliAPIKey, defaultModel)
 	if err != nil {
 		return err
 	}
 
-	// Create context summarization strategies for long coding sessions
-	// Strategy 1: Summarize old tool calls to compress historical operations (with buffering)
-	toolCallStrategy := agentcontext.NewToolCallSummarizationStrategy(
-		defaultToolCallAge,
-		defaultMinToolCalls,
-		defaultMaxToolCallDist,
-	)
-
-	// Strategy 2: Collapse the older half of the conversation into a single summary when
-	// context usage crosses the threshold. The recent half is always kept verbatim.
-	thresholdStrategy := agentcontext.NewThresholdSummarizationStrategy(
-		defaultThresholdTrigger,
-	)
+	// Offline mode requires a local model server and turns off network-touching capabilities
+	var offlineReport *offline.Report
+	if config.Offline {
+		offlineReport, err = newOfflineReport(provider)
+		if err != nil {
+			return err
+		}
+	}
 
-	// Strategy 3: Compact old completed turns (user message + summaries) into goal-batch blocks
-	goalBatchStrategy := agentcontext.NewGoalBatchCompactionStrategy(
-		defaultGoalBatchTurnsOld,
-		defaultGoalBatchMinTurns,
-		defaultGoalBatchMaxTurns,
-	)
+	// Create the context summarization strategies configured for the project
+	contextStrategies, err := agentcontext.NewStrategies(projectConfig.GetContextStrategies())
+	if err != nil {
+		return err
+	}
 
 	// Create context manager with active strategies
 	// Event channel will be set by the agent during initialization
-	contextManager, err := agentcontext.NewManager(
-		provider,
-		defaultMaxTokens,
-		toolCallStrategy,
-		thresholdStrategy,
-		goalBatchStrategy,
-	)
+	contextManager, err := agentcontext.NewManager(provider, defaultMaxTokens, contextStrategies...)
 	if err != nil {
 		return fmt.Errorf("failed to create context manager: %w", err)
 	}
@@ -261,6 +495,7 @@ func runTUI(ctx context.Context, config *Config) error {
 			contextManager.SetSummarizationModel(summarizationModel)
 		}
 	}
+	contextManager.SetSummarizationSampling(llm.SamplingFromConfig(appconfig.SamplingRoleSummarizer))
 
 	// Initialize the embedding provider for long-term memory retrieval.
 	// NewEmbedder returns (nil, nil) when embedding is unconfigured — the agent
@@ -316,6 +551,9 @@ func runTUI(ctx context.Context, config *Config) error {
 			embedder = nil
 		}
 	}
+	if offlineReport != nil {
+		embedder = offlineEmbedder(embedder, offlineReport)
+	}
 
 	// Initialize the async long-term memory capture pipeline and retrieval engine.
 	// Both are silently disabled when not configured.
@@ -375,6 +613,10 @@ func runTUI(ctx context.Context, config *Config) error {
 		cmdLog.Infof("memory: long-term capture disabled (memory section not configured or not enabled)")
 	}
 
+	// Vector memory indexes tool summaries, notes and task results whenever an
+	// embedding model is configured, and is searched with recall_memory.
+	vectorMemory := startVectorMemory(ctx, embedder, config.WorkspaceDir)
+
 	// Create workspace security guard
 	guard, err := workspace.NewGuard(config.WorkspaceDir)
 	if err != nil {
@@ -391,11 +633,27 @@ func runTUI(ctx context.Context, config *Config) error {
 		return fmt.Errorf("failed to whitelist custom tools directory: %w", err)
 	}
 
-	// Check for AGENTS.md in workspace root
-	agentsMdPath := filepath.Join(config.WorkspaceDir, "AGENTS.md")
-	var repositoryContext string
-	if content, err := os.ReadFile(agentsMdPath); err == nil {
-		repositoryContext = string(content)
+	// Enforce the project's write-protection rules
+	if err := guard.SetPathRules(projectConfig.GuardPathRules()); err != nil {
+		return fmt.Errorf("failed to apply path rules: %w", err)
+	}
+
+	// Let tools work across the project's and command line's workspace roots
+	if err := guard.SetRoots(append(projectConfig.GuardRoots(), config.Roots...)); err != nil {
+		return fmt.Errorf("failed to apply workspace roots: %w", err)
+	}
+
+	// Let tools use the directories outside the workspace the project allows
+	if err := guard.SetAllowedDirs(projectConfig.GuardAllowedDirs()); err != nil {
+		return fmt.Errorf("failed to apply allowed directories: %w", err)
+	}
+
+	// Index the workspace so search_files and find_files need not walk it
+	fileIndex := startFileIndex(ctx, guard)
+
+	// Load AGENTS.md from the workspace root, and later from the directories the agent works in
+	repositoryContext := newRepositoryContextLoader(config.WorkspaceDir, projectConfig, config.NoAgentsMD)
+	if repositoryContext != nil && len(repositoryContext.Files()) > 0 {
 		fmt.Printf("Loaded repository context from AGENTS.md\n")
 	}
 
@@ -404,6 +662,14 @@ func runTUI(ctx context.Context, config *Config) error {
 	if config.SystemPrompt != "" {
 		systemPrompt = config.SystemPrompt // Override with user-provided prompt
 	}
+	systemPrompt = projectConfig.AppendInstructions(systemPrompt)
+	systemPrompt = appconfig.AppendExperimentalNotice(systemPrompt)
+	if offlineReport != nil {
+		systemPrompt += "\n\n" + offline.Instructions
+	}
+	if rootsInstructions := guard.RootsInstructions(); rootsInstructions != "" {
+		systemPrompt += "\n\n" + rootsInstructions
+	}
 
 	// Create notes manager for scratchpad
 	notesManager := notes.NewManager()
@@ -411,14 +677,32 @@ func runTUI(ctx context.Context, config *Config) error {
 	// Create browser session manager
 	browserManager := browser.NewSessionManager()
 
+	// Create terminal session manager; the agent closes its sessions when it
+	// stops, and this covers quitting before it does
+	terminalManager := terminal.NewSessionManager()
+	defer func() { _ = terminalManager.CloseAll() }()
+
 	// Create agent with custom system prompt, repository context, context manager, and shared notes manager
 	agentOptions := []agent.AgentOption{
 		agent.WithCustomInstructions(systemPrompt),
 		agent.WithContextManager(contextManager),
 		agent.WithNotesManager(notesManager),
 		agent.WithBrowserManager(browserManager),
+		agent.WithTerminalManager(terminalManager),
 		agent.WithEmbedder(embedder),
 		agent.WithRetrievalEngine(retrievalEngine),
+		agent.WithVectorMemory(vectorMemory),
+		agent.WithDisabledTools(projectConfig.GetDisabledTools()...),
+		agent.WithDisabledTools(appconfig.DisabledExperimentalTools()...),
+		agent.WithWorkspaceDir(config.WorkspaceDir),
+		agent.WithWorkspaceGuard(guard),
+		agent.WithHookConfig(hookConfig),
+		agent.WithToolCallMode(llm.ToolCallModeFromConfig()),
+		agent.WithMessageTokenLimit(config.MaxMessageTokens, agent.OversizedMessageChunk),
+		agent.WithMaxToolCallsPerTurn(config.MaxTurnToolCalls),
+		agent.WithMaxTurnDuration(config.MaxTurnDuration),
+		agent.WithToolLimits(projectConfig.GetToolLimits()),
+		agent.WithToolSchemas(projectConfig.GetToolSchemas()),
 	}
 
 	// Attach the capture pipeline when it was successfully initialized
@@ -426,28 +710,75 @@ func runTUI(ctx context.Context, config *Config) error {
 		agentOptions = append(agentOptions, agent.WithCapturePipeline(capturePipeline))
 	}
 
-	// Add repository context if AGENTS.md was loaded
-	if repositoryContext != "" {
-		agentOptions = append(agentOptions, agent.WithRepositoryContext(repositoryContext))
+	// Add repository context unless AGENTS.md loading is turned off
+	if repositoryContext != nil {
+		agentOptions = append(agentOptions, agent.WithRepositoryContextLoader(repositoryContext))
 	}
 
-	ag := agent.NewDefaultAgent(provider, agentOptions...)
+	// Load the facts remembered in earlier sessions
+	agentOptions = append(agentOptions, projectMemoryOptions(config.WorkspaceDir, projectConfig, provider, config.MockTools)...)
+
+	// Stream the session's events to external observers when asked
+	stream, streamURL, err := startEventStream(config)
+	if err != nil {
+		return err
+	}
+	if stream != nil {
+		defer stopEventStream(stream)
+		fmt.Printf("Streaming events on %s\n", streamURL)
+		agentOptions = append(agentOptions, agent.WithEventObserver(stream))
+	}
 
-	// Wire the capture observer into the goal-batch compaction strategy so that
-	// compaction events also trigger long-term memory classification.
-	if obs := ag.GetCaptureObserver(); obs != nil {
-		goalBatchStrategy.SetCaptureObserver(obs, ag.GetSessionID())
+	// Record the session for 'forge replay' when asked
+	if config.Record != "" {
+		recorder, recordErr := newSessionRecorder(config.Record, provider, config.WorkspaceDir)
+		if recordErr != nil {
+			return recordErr
+		}
+		defer func() {
+			fmt.Println(closeSessionRecorder(recorder, config.Record))
+		}()
+		agentOptions = append(agentOptions, agent.WithRecorder(recorder))
 	}
 
+	agentProvider := llm.WithSampling(llm.WithRequestLog(provider, llm.SharedRequestLog()), llm.SamplingFromConfig(appconfig.SamplingRoleAgent))
+	agentProvider = llm.WithFallback(agentProvider, llm.FallbackModelFromConfig())
+	ag := agent.NewDefaultAgent(agentProvider, agentOptions...)
+	ag.SetProfile(profile)
+
+	// Wire the capture observer into goal-batch compaction so that compaction
+	// events also trigger long-term memory classification.
+	attachStrategyMemory(contextStrategies, ag.GetCaptureObserver(), vectorMemory, ag.GetSessionID())
+
 	// Register coding tools
+	searchFiles := coding.NewSearchFilesTool(guard)
+	searchFiles.SetIndex(fileIndex)
+	findFiles := coding.NewFindFilesTool(guard)
+	findFiles.SetIndex(fileIndex)
+	viewImage := coding.NewViewImageTool(guard)
+	viewImage.SetScreenshotDir(filepath.Join(config.WorkspaceDir, browser.DefaultScreenshotDir))
 	codingTools := []tools.Tool{
 		coding.NewReadFileTool(guard),
 		coding.NewWriteFileTool(guard),
 		coding.NewListFilesTool(guard),
-		coding.NewSearchFilesTool(guard),
+		searchFiles,
+		findFiles,
 		coding.NewApplyDiffTool(guard),
+		coding.NewRenameSymbolTool(guard),
+		coding.NewDeleteFileTool(guard),
+		coding.NewMoveFileTool(guard),
+		coding.NewCreateDirectoryTool(guard),
 		coding.NewExecuteCommandTool(guard),
+		coding.NewRunTestsTool(guard),
 		coding.NewAnalyzeDocumentTool(guard, provider),
+		viewImage,
+	}
+
+	// In mock mode, route writes and commands through an in-memory overlay
+	var overlay *mock.Overlay
+	if config.MockTools {
+		overlay = mock.NewOverlay(guard.WorkspaceDir())
+		codingTools = mock.Wrap(codingTools, guard, overlay)
 	}
 
 	for _, tool := range codingTools {
@@ -456,6 +787,15 @@ func runTUI(ctx context.Context, config *Config) error {
 		}
 	}
 
+	// Register interactive terminal tools, which mock mode cannot contain
+	if !config.MockTools {
+		for _, tool := range terminal.NewTools(terminalManager, guard) {
+			if err := ag.RegisterTool(tool); err != nil {
+				return fmt.Errorf("failed to register terminal tool: %w", err)
+			}
+		}
+	}
+
 	// Register scratchpad tools
 	scratchpadTools := []tools.Tool{
 		scratchpad.NewAddNoteTool(notesManager),
@@ -472,43 +812,111 @@ func runTUI(ctx context.Context, config *Config) error {
 		}
 	}
 
-	// Register custom tool management tools
-	customTools := []tools.Tool{
-		custom.NewCreateCustomToolTool(),
-		custom.NewRunCustomToolTool(guard),
+	// Register todo list tools
+	todoList := todo.NewList()
+	todoTools := []tools.Tool{
+		todotools.NewCreateTodoListTool(todoList),
+		todotools.NewUpdateTodoTool(todoList),
 	}
 
-	for _, tool := range customTools {
+	for _, tool := range todoTools {
 		if err := ag.RegisterTool(tool); err != nil {
-			return fmt.Errorf("failed to register custom tool: %w", err)
+			return fmt.Errorf("failed to register todo tool: %w", err)
 		}
 	}
 
-	// Register browser tools using the browser registry
-	browserRegistry := browser.NewToolRegistry(browserManager)
-	browserRegistry.SetLLMProvider(provider) // Enable AI-powered browser tools
-	browserTools := browserRegistry.RegisterTools()
+	// Register custom tool management tools, which mock mode cannot contain
+	// either: custom tools run arbitrary scripts and are saved in ~/.forge/tools
+	if !config.MockTools {
+		customTools := []tools.Tool{
+			custom.NewCreateCustomToolTool(),
+			custom.NewRunCustomToolTool(guard),
+		}
 
-	for _, tool := range browserTools {
-		if err := ag.RegisterTool(tool); err != nil {
-			return fmt.Errorf("failed to register browser tool: %w", err)
+		for _, tool := range customTools {
+			if err := ag.RegisterTool(tool); err != nil {
+				return fmt.Errorf("failed to register custom tool: %w", err)
+			}
+		}
+	}
+
+	// Register web and browser tools (they need the network). Mock mode leaves
+	// them out, since requests and page interactions cannot be simulated
+	var searchWarning error
+	if offlineReport == nil && !config.MockTools {
+		if err := ag.RegisterTool(web.NewFetchURLTool()); err != nil {
+			return fmt.Errorf("failed to register web tool: %w", err)
+		}
+		if err := ag.RegisterTool(web.NewHTTPRequestTool()); err != nil {
+			return fmt.Errorf("failed to register web tool: %w", err)
+		}
+		if searchProvider, err := web.SearchProviderFromEnv(); err != nil {
+			searchWarning = err
+		} else if searchProvider != nil {
+			if err := ag.RegisterTool(web.NewWebSearchTool(searchProvider)); err != nil {
+				return fmt.Errorf("failed to register web tool: %w", err)
+			}
+		}
+
+		browserRegistry := browser.NewToolRegistry(browserManager)
+		browserRegistry.SetLLMProvider(provider) // Enable AI-powered browser tools
+		browserRegistry.SetScreenshotDir(filepath.Join(config.WorkspaceDir, browser.DefaultScreenshotDir))
+		browserTools := browserRegistry.RegisterTools()
+
+		for _, tool := range browserTools {
+			if err := ag.RegisterTool(tool); err != nil {
+				return fmt.Errorf("failed to register browser tool: %w", err)
+			}
 		}
 	}
 
 	// Create TUI executor with provider and workspace for git operations
 	executor := tui.NewExecutor(ag, provider, config.WorkspaceDir, "forge")
+	executor.SetWorkspaceRoots(guard.Roots())
 
 	// Surface any memory misconfiguration warnings as TUI toasts shown once at startup.
 	for _, w := range tuiMemWarnings {
 		executor.AddStartupWarning(w.message, w.details, w.isError)
 	}
 
+	if searchWarning != nil {
+		executor.AddStartupWarning("Web search unavailable", searchWarning.Error(), false)
+	}
+
+	// Teams share workflows such as /review as commands in ~/.forge/commands
+	if err := tui.LoadUserCommands(filepath.Join(homeDir, ".forge", "commands")); err != nil {
+		executor.AddStartupWarning("Some custom commands were not loaded", err.Error(), false)
+	}
+
+	// Fall back to fetch_url rather than failing mid-task when Playwright is missing
+	if ui := appconfig.GetUI(); offlineReport == nil && !config.MockTools && ui != nil && ui.IsBrowserEnabled() {
+		if err := browserManager.DetectInstallation(); err != nil {
+			executor.AddStartupWarning(
+				"Browser tools unavailable",
+				"Playwright's Chromium is not installed, so fetch_url will read web pages instead. Run /browser install to install it.",
+				false,
+			)
+		}
+	}
+
+	// Install missing dependencies before the agent's first tool calls need them
+	setUpProject(ctx, config, projectConfig, executor)
+
 	// Display welcome message
 	fmt.Printf("Forge v%s - Coding Agent\n", version)
 	fmt.Printf("Workspace: %s\n", config.WorkspaceDir)
 	if config.Model != nil {
 		fmt.Printf("Model: %s\n", *config.Model)
 	}
+	if overlay != nil {
+		fmt.Println("Mock tools: enabled (file writes and commands are simulated)")
+	}
+	if offlineReport != nil {
+		fmt.Print(offlineReport.Banner())
+		executor.AddStartupWarning("Offline mode", "Unavailable: "+strings.Join(offlineReport.Disabled(), "; "), false)
+	} else {
+		startUpdateCheck(ctx, executor)
+	}
 	fmt.Println("\nStarting TUI...")
 	fmt.Println()
 
@@ -517,5 +925,9 @@ func runTUI(ctx context.Context, config *Config) error {
 		return fmt.Errorf("executor error: %w", err)
 	}
 
+	if overlay != nil {
+		printMockSummary(overlay)
+	}
+
 	return nil
 }
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+
+	"github.com/entrhq/forge/pkg/agent/memory/vector"
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// startVectorMemory opens the user's vector store and starts its indexing
+// goroutine. It returns nil, disabling vector memory, when no embedder is
+// configured or the store cannot be opened.
+func startVectorMemory(ctx context.Context, embedder llm.Embedder, workspaceDir string) *vector.Memory {
+	if embedder == nil {
+		return nil
+	}
+
+	path, err := vector.DefaultStorePath()
+	if err != nil {
+		cmdLog.Warnf("memory: vector memory disabled: %v", err)
+		return nil
+	}
+	store, err := vector.NewFileStore(path)
+	if err != nil {
+		cmdLog.Warnf("memory: vector memory disabled: %v", err)
+		return nil
+	}
+
+	mem := vector.New(store, embedder, workspaceDir, cmdLog)
+	mem.Start(ctx)
+	cmdLog.Infof("memory: vector memory started (store=%s records=%d)", path, store.Len())
+	return mem
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/tools/mock"
+)
+
+// printMockSummary reports what a -mock-tools session would have changed.
+func printMockSummary(overlay *mock.Overlay) {
+	changes := overlay.Changes()
+	commands := overlay.Commands()
+
+	fmt.Println("\nMock session summary (nothing was written to the workspace):")
+	if len(changes) == 0 && len(commands) == 0 {
+		fmt.Println("  No simulated changes.")
+		return
+	}
+
+	for _, change := range changes {
+		action := "modified"
+		switch {
+		case change.Deleted:
+			action = "deleted "
+		case change.Created:
+			action = "created "
+		}
+		fmt.Printf("  %s %s\n", action, change.Path)
+	}
+
+	for _, command := range commands {
+		fmt.Printf("  ran      %s\n", command)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/embedding"
+	"github.com/entrhq/forge/pkg/llm/openai"
+	"github.com/entrhq/forge/pkg/security/offline"
+)
+
+// newOfflineReport fails fast unless the LLM provider is served locally, and
+// records the capabilities offline mode turns off.
+func newOfflineReport(provider *openai.Provider) (*offline.Report, error) {
+	if err := offline.CheckEndpoint("LLM provider", provider.GetBaseURL(), "-base-url, OPENAI_BASE_URL, or llm.base_url"); err != nil {
+		return nil, err
+	}
+
+	report := &offline.Report{}
+	report.Disable("Web and browser tools", "they load remote pages")
+	return report, nil
+}
+
+// offlineEmbedder drops the embedder when its endpoint is not local, which
+// disables memory retrieval rather than failing the run.
+func offlineEmbedder(embedder llm.Embedder, report *offline.Report) llm.Embedder {
+	provider, ok := embedder.(*embedding.Provider)
+	if !ok || offline.IsLocalURL(provider.BaseURL()) {
+		return embedder
+	}
+	report.Disable("Memory retrieval", fmt.Sprintf("embedding endpoint %s is not local", provider.BaseURL()))
+	return nil
+}
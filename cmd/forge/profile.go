@@ -0,0 +1,27 @@
+package main
+
+import (
+	appconfig "github.com/entrhq/forge/pkg/config"
+)
+
+// loadProfile returns the profile selected with -profile, looked up in the
+// user's and the project's profiles, or nil when no profile was selected.
+func loadProfile(config *Config, projectConfig *appconfig.ProjectConfig) (*appconfig.Profile, error) {
+	if config.Profile == "" {
+		return nil, nil
+	}
+	profiles, err := appconfig.LoadProfiles("", projectConfig)
+	if err != nil {
+		return nil, err
+	}
+	return appconfig.LookupProfile(profiles, config.Profile)
+}
+
+// profileModel returns the model the CLI should request: -model when it was
+// given, otherwise the profile's model, if any.
+func profileModel(cliModel string, profile *appconfig.Profile) string {
+	if cliModel == "" && profile != nil {
+		return profile.Model
+	}
+	return cliModel
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/projectmemory"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// projectMemoryOptions returns the agent options that load the workspace's
+// .forge/memory.md and let the agent add to it, as the project's
+// project_memory settings direct. provider extracts the learnings of each
+// completed task. A readOnly session, such as a mock run, sees the memory but
+// cannot change it. It returns nil when the memory is turned off.
+func projectMemoryOptions(workspaceDir string, projectConfig *appconfig.ProjectConfig, provider llm.Provider, readOnly bool) []agent.AgentOption {
+	settings := projectConfig.GetProjectMemory()
+	if !settings.Enabled {
+		return nil
+	}
+
+	mem, err := projectmemory.Open(workspaceDir, projectmemory.Options{MaxTokens: settings.MaxTokens})
+	if err != nil {
+		cmdLog.Warnf("Project memory not loaded: %v", err)
+		return nil
+	}
+	if readOnly {
+		return []agent.AgentOption{agent.WithProjectMemory(mem, nil), agent.WithDisabledTools("remember")}
+	}
+
+	var extractor *projectmemory.Extractor
+	if settings.AutoExtract {
+		extractor = projectmemory.NewExtractor(provider, mem)
+	}
+	return []agent.AgentOption{agent.WithProjectMemory(mem, extractor)}
+}
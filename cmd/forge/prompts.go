@@ -41,6 +41,7 @@ const WorkflowGuidance = `
 -   **Plan Your Work**: Before writing code, think through the requirements and create a plan.
 -   **Incremental Changes**: Apply changes in small, logical increments. Use the "apply_diff" tool for targeted edits rather than rewriting an entire file.
 -   **Efficient File Handling**: Use "read_file" with line ranges for large files. Don't read a whole file just to see a small part of it.
+-   **Restructuring**: Use "delete_file", "move_file" and "create_directory" rather than rm, mv or mkdir in "execute_command", so the changes are checked and tracked.
 -   **Verify and Test**: After making changes, consider how to verify them. This may involve running tests, linting, or building the code.
 -   **Batch Operations**: When performing similar edits across multiple files, try to do so in a single tool call where possible.
 `
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/recording"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/executor/tui"
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// newSessionRecorder starts a recording of the session at path for
+// 'forge replay'.
+func newSessionRecorder(path string, provider llm.Provider, workspaceDir string) (*recording.Recorder, error) {
+	// Record the mode the agent will actually use, so a replay offers the
+	// recorded responses the same way
+	toolCallMode := llm.ToolCallModeXML
+	if llm.ToolCallModeFromConfig() == llm.ToolCallModeNative && llm.SupportsNativeToolCalls(provider) {
+		toolCallMode = llm.ToolCallModeNative
+	}
+
+	if absDir, err := filepath.Abs(workspaceDir); err == nil {
+		workspaceDir = absDir
+	}
+
+	return recording.NewRecorder(path, recording.Header{
+		ForgeVersion: version,
+		Model:        provider.GetModel(),
+		Workspace:    workspaceDir,
+		ToolCallMode: toolCallMode,
+	})
+}
+
+// closeSessionRecorder finishes a recording and describes the outcome.
+func closeSessionRecorder(recorder *recording.Recorder, path string) string {
+	if err := recorder.Close(); err != nil {
+		return fmt.Sprintf("Session recording %s may be incomplete: %v", path, err)
+	}
+	return fmt.Sprintf("Session recorded to %s (play it back with: forge replay %s)", path, path)
+}
+
+// runReplay plays back a recorded session: re-rendered in the TUI, or with
+// -mock-provider rerun against the current agent to check for regressions.
+func runReplay(ctx context.Context, config *Config) error {
+	bundle, err := recording.Load(config.ReplayBundle)
+	if err != nil {
+		return err
+	}
+
+	if config.MockProvider {
+		return runReplayCheck(ctx, config, bundle)
+	}
+
+	// Load the UI settings; nothing else of the config is used by a replay
+	if err := appconfig.Initialize(""); err != nil {
+		return fmt.Errorf("failed to initialize configuration: %w", err)
+	}
+
+	// No workspace: a replay neither offers nor writes session checkpoints
+	player := recording.NewPlayer(bundle, config.ReplaySpeed)
+	executor := tui.NewExecutor(player, recording.NewReplayProvider(bundle), "", "forge")
+	executor.SetReadOnly("This is a replay of " + config.ReplayBundle + ". Start forge without 'replay' to chat.")
+
+	fmt.Printf("Forge v%s - Replaying %s\n", version, config.ReplayBundle)
+	fmt.Printf("Recorded: %s with %s in %s\n", bundle.Header.Started.Format("2006-01-02 15:04"), bundle.Header.Model, bundle.Header.Workspace)
+	fmt.Println("\nStarting TUI...")
+	fmt.Println()
+
+	if err := executor.Run(ctx); err != nil {
+		return fmt.Errorf("executor error: %w", err)
+	}
+	return nil
+}
+
+// runReplayCheck reruns the recorded inputs through the current agent with
+// the recorded model responses and tool results, and fails when the agent's
+// requests or tool calls differ from the recording.
+func runReplayCheck(ctx context.Context, config *Config, bundle *recording.Bundle) error {
+	systemPrompt := composeSystemPrompt()
+	if config.SystemPrompt != "" {
+		systemPrompt = config.SystemPrompt
+	}
+
+	fmt.Printf("Replaying %s against the recorded model responses...\n", config.ReplayBundle)
+	report, err := recording.Rerun(ctx, bundle, agent.WithCustomInstructions(systemPrompt))
+	if err != nil {
+		return fmt.Errorf("replay failed: %w", err)
+	}
+
+	fmt.Printf("Replayed %d inputs and %d tool calls\n", report.Inputs, report.ToolCalls)
+	if !report.Diverged() {
+		fmt.Println("No divergences: the agent behaved as recorded")
+		return nil
+	}
+
+	fmt.Printf("\n%d divergences:\n", len(report.Divergences))
+	for _, d := range report.Divergences {
+		fmt.Printf("- %s\n", d)
+	}
+	return fmt.Errorf("the agent diverged from the recording")
+}
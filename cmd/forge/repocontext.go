@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/entrhq/forge/pkg/agent/repocontext"
+	appconfig "github.com/entrhq/forge/pkg/config"
+)
+
+// newRepositoryContextLoader loads the workspace's AGENTS.md files as the
+// project's repository_context settings direct. It returns nil when loading
+// is turned off, by the settings or by -no-agents-md, or cannot start.
+func newRepositoryContextLoader(workspaceDir string, projectConfig *appconfig.ProjectConfig, disabled bool) *repocontext.Loader {
+	settings := projectConfig.GetRepositoryContext()
+	if disabled || !settings.Enabled {
+		return nil
+	}
+
+	loader, err := repocontext.NewLoader(workspaceDir, repocontext.Options{
+		MaxTokens: settings.MaxTokens,
+		Nested:    settings.Nested,
+	})
+	if err != nil {
+		cmdLog.Warnf("Repository context not loaded: %v", err)
+		return nil
+	}
+	return loader
+}
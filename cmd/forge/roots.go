@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// rootFlag collects repeated -root and -root-ro flags of the form name=path.
+type rootFlag struct {
+	roots    *[]workspace.Root
+	readOnly bool
+}
+
+// String implements flag.Value.
+func (f rootFlag) String() string {
+	if f.roots == nil {
+		return ""
+	}
+	values := make([]string, 0, len(*f.roots))
+	for _, root := range *f.roots {
+		if root.ReadOnly == f.readOnly {
+			values = append(values, root.Name+"="+root.Path)
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+// Set implements flag.Value. Relative paths are resolved against the current
+// directory, like -workspace.
+func (f rootFlag) Set(value string) error {
+	name, path, ok := strings.Cut(value, "=")
+	if !ok || path == "" {
+		return fmt.Errorf("expected name=path, got %q", value)
+	}
+	if err := workspace.ValidateRootName(name); err != nil {
+		return err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	*f.roots = append(*f.roots, workspace.Root{Name: name, Path: absPath, ReadOnly: f.readOnly})
+	return nil
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/entrhq/forge/pkg/agent"
+	agentcontext "github.com/entrhq/forge/pkg/agent/context"
+	"github.com/entrhq/forge/pkg/agent/hooks"
+	"github.com/entrhq/forge/pkg/agent/memory/notes"
+	"github.com/entrhq/forge/pkg/agent/todo"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/executor/server"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/openai"
+	"github.com/entrhq/forge/pkg/security/offline"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/browser"
+	"github.com/entrhq/forge/pkg/tools/coding"
+	"github.com/entrhq/forge/pkg/tools/custom"
+	"github.com/entrhq/forge/pkg/tools/scratchpad"
+	"github.com/entrhq/forge/pkg/tools/terminal"
+	todotools "github.com/entrhq/forge/pkg/tools/todo"
+	"github.com/entrhq/forge/pkg/tools/web"
+)
+
+const defaultServeAddr = "127.0.0.1:7777"
+
+// runServe exposes the agent over HTTP+SSE. Every API session gets its own
+// agent, context manager and tool instances built the same way as the TUI's.
+func runServe(ctx context.Context, config *Config) error {
+	factory, offlineReport, err := newSessionFactory(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	token := config.ServeToken
+	if token == "" {
+		token = os.Getenv("FORGE_SERVE_TOKEN")
+	}
+	// The API runs tools on this machine, so it is never served without a token
+	generated := token == ""
+	if generated {
+		token, err = newServeToken()
+		if err != nil {
+			return err
+		}
+	}
+
+	srv := server.NewServer(config.ServeAddr, factory, server.WithToken(token))
+
+	fmt.Printf("Forge v%s - serving on http://%s\n", version, config.ServeAddr)
+	fmt.Printf("Workspace: %s\n", config.WorkspaceDir)
+	if generated {
+		fmt.Printf("Token: %s (generated; set -serve-token or FORGE_SERVE_TOKEN to choose one)\n", token)
+	}
+	if offlineReport != nil {
+		fmt.Print(offlineReport.Banner())
+	}
+
+	return srv.Run(ctx)
+}
+
+// newServeToken returns a random bearer token for a serve run started without one.
+func newServeToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate serve token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// newSessionFactory loads the workspace configuration and returns a factory
+// that builds a fresh agent per session, for the API server and editor
+// integration modes. The offline report is nil unless -offline is set.
+func newSessionFactory(ctx context.Context, config *Config) (server.SessionFactory, *offline.Report, error) {
+	// Initialize global configuration (for auto-approval and command whitelist)
+	if err := appconfig.Initialize(""); err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize configuration: %w", err)
+	}
+
+	// Layer the workspace's shared .forge/config.yaml over the global config
+	projectConfig, err := appconfig.InitializeProject(config.WorkspaceDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if projectConfig != nil {
+		cmdLog.Infof("Loaded project configuration from %s", appconfig.ProjectConfigPath)
+	}
+	if err := startResponseCache(llm.ResponseCacheFromConfig()); err != nil {
+		return nil, nil, err
+	}
+
+	// Repository hooks run shell commands around tool calls and turns
+	hookConfig, err := hooks.LoadConfig(config.WorkspaceDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hookConfig != nil {
+		cmdLog.Infof("Loaded shell hooks from %s", hooks.ConfigPath)
+	}
+
+	// Apply the administrator's approval policy, which user and project settings cannot loosen
+	policy, err := appconfig.InitializePolicy(appconfig.DefaultPolicyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if policy != nil {
+		cmdLog.Infof("Loaded approval policy from %s", policy.Path)
+	}
+
+	var cliModel, cliBaseURL, cliAPIKey string
+	if config.Model != nil {
+		cliModel = *config.Model
+	}
+	if config.BaseURL != nil {
+		cliBaseURL = *config.BaseURL
+	}
+	if config.APIKey != nil {
+		cliAPIKey = *config.APIKey
+	}
+
+	provider, err := openai.BuildProvider(cliModel, cliBaseURL, cliAPIKey, defaultModel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Offline mode requires a local model server and turns off network-touching capabilities
+	var offlineReport *offline.Report
+	if config.Offline {
+		offlineReport, err = newOfflineReport(provider)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Create workspace security guard
+	guard, err := workspace.NewGuard(config.WorkspaceDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create workspace guard: %w", err)
+	}
+
+	// Whitelist custom tools directory for custom tool operations
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	if err := guard.AddWhitelist(filepath.Join(homeDir, ".forge", "tools")); err != nil {
+		return nil, nil, fmt.Errorf("failed to whitelist custom tools directory: %w", err)
+	}
+
+	// Enforce the project's write-protection rules
+	if err := guard.SetPathRules(projectConfig.GuardPathRules()); err != nil {
+		return nil, nil, fmt.Errorf("failed to apply path rules: %w", err)
+	}
+
+	// Let tools work across the project's and command line's workspace roots
+	if err := guard.SetRoots(append(projectConfig.GuardRoots(), config.Roots...)); err != nil {
+		return nil, nil, fmt.Errorf("failed to apply workspace roots: %w", err)
+	}
+
+	// Let tools use the directories outside the workspace the project allows
+	if err := guard.SetAllowedDirs(projectConfig.GuardAllowedDirs()); err != nil {
+		return nil, nil, fmt.Errorf("failed to apply allowed directories: %w", err)
+	}
+
+	// Index the workspace so search_files and find_files need not walk it
+	fileIndex := startFileIndex(ctx, guard)
+
+	systemPrompt := composeSystemPrompt()
+	if config.SystemPrompt != "" {
+		systemPrompt = config.SystemPrompt
+	}
+	systemPrompt = projectConfig.AppendInstructions(systemPrompt)
+	systemPrompt = appconfig.AppendExperimentalNotice(systemPrompt)
+	if offlineReport != nil {
+		systemPrompt += "\n\n" + offline.Instructions
+	}
+	if rootsInstructions := guard.RootsInstructions(); rootsInstructions != "" {
+		systemPrompt += "\n\n" + rootsInstructions
+	}
+
+	factory := func(sessionCtx context.Context) (agent.Agent, error) {
+		contextStrategies, err := agentcontext.NewStrategies(projectConfig.GetContextStrategies())
+		if err != nil {
+			return nil, err
+		}
+		contextManager, err := agentcontext.NewManager(provider, defaultMaxTokens, contextStrategies...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create context manager: %w", err)
+		}
+		if llmCfg := appconfig.GetLLM(); llmCfg != nil {
+			if summarizationModel := llmCfg.GetSummarizationModel(); summarizationModel != "" {
+				contextManager.SetSummarizationModel(summarizationModel)
+			}
+		}
+		contextManager.SetSummarizationSampling(llm.SamplingFromConfig(appconfig.SamplingRoleSummarizer))
+
+		notesManager := notes.NewManager()
+		todoList := todo.NewList()
+		browserManager := browser.NewSessionManager()
+		terminalManager := terminal.NewSessionManager()
+
+		agentOptions := []agent.AgentOption{
+			agent.WithCustomInstructions(systemPrompt),
+			agent.WithContextManager(contextManager),
+			agent.WithNotesManager(notesManager),
+			agent.WithBrowserManager(browserManager),
+			agent.WithTerminalManager(terminalManager),
+			agent.WithDisabledTools(projectConfig.GetDisabledTools()...),
+			agent.WithDisabledTools(appconfig.DisabledExperimentalTools()...),
+			agent.WithWorkspaceDir(config.WorkspaceDir),
+			agent.WithWorkspaceGuard(guard),
+			agent.WithHookConfig(hookConfig),
+			agent.WithToolCallMode(llm.ToolCallModeFromConfig()),
+			agent.WithMessageTokenLimit(config.MaxMessageTokens, agent.OversizedMessageChunk),
+			agent.WithMaxToolCallsPerTurn(config.MaxTurnToolCalls),
+			agent.WithMaxTurnDuration(config.MaxTurnDuration),
+			agent.WithToolLimits(projectConfig.GetToolLimits()),
+			agent.WithToolSchemas(projectConfig.GetToolSchemas()),
+		}
+		// Each session finds nested AGENTS.md files as its own work reaches them
+		if repositoryContext := newRepositoryContextLoader(config.WorkspaceDir, projectConfig, config.NoAgentsMD); repositoryContext != nil {
+			agentOptions = append(agentOptions, agent.WithRepositoryContextLoader(repositoryContext))
+		}
+		agentOptions = append(agentOptions, projectMemoryOptions(config.WorkspaceDir, projectConfig, provider, false)...)
+
+		agentProvider := llm.WithSampling(llm.WithRequestLog(provider, llm.SharedRequestLog()), llm.SamplingFromConfig(appconfig.SamplingRoleAgent))
+		agentProvider = llm.WithFallback(agentProvider, llm.FallbackModelFromConfig())
+		ag := agent.NewDefaultAgent(agentProvider, agentOptions...)
+
+		searchFiles := coding.NewSearchFilesTool(guard)
+		searchFiles.SetIndex(fileIndex)
+		findFiles := coding.NewFindFilesTool(guard)
+		findFiles.SetIndex(fileIndex)
+		viewImage := coding.NewViewImageTool(guard)
+		viewImage.SetScreenshotDir(filepath.Join(config.WorkspaceDir, browser.DefaultScreenshotDir))
+		sessionTools := []tools.Tool{
+			coding.NewReadFileTool(guard),
+			coding.NewWriteFileTool(guard),
+			coding.NewListFilesTool(guard),
+			searchFiles,
+			findFiles,
+			coding.NewApplyDiffTool(guard),
+			coding.NewRenameSymbolTool(guard),
+			coding.NewDeleteFileTool(guard),
+			coding.NewMoveFileTool(guard),
+			coding.NewCreateDirectoryTool(guard),
+			coding.NewExecuteCommandTool(guard),
+			coding.NewRunTestsTool(guard),
+			coding.NewAnalyzeDocumentTool(guard, provider),
+			viewImage,
+			scratchpad.NewAddNoteTool(notesManager),
+			scratchpad.NewListNotesTool(notesManager),
+			scratchpad.NewSearchNotesTool(notesManager),
+			scratchpad.NewListTagsTool(notesManager),
+			scratchpad.NewScratchNoteTool(notesManager),
+			scratchpad.NewUpdateNoteTool(notesManager),
+			todotools.NewCreateTodoListTool(todoList),
+			todotools.NewUpdateTodoTool(todoList),
+			custom.NewCreateCustomToolTool(),
+			custom.NewRunCustomToolTool(guard),
+		}
+		sessionTools = append(sessionTools, terminal.NewTools(terminalManager, guard)...)
+
+		if offlineReport == nil {
+			sessionTools = append(sessionTools, web.NewFetchURLTool(), web.NewHTTPRequestTool())
+			if searchProvider, err := web.SearchProviderFromEnv(); err != nil {
+				cmdLog.Warnf("Web search unavailable: %v", err)
+			} else if searchProvider != nil {
+				sessionTools = append(sessionTools, web.NewWebSearchTool(searchProvider))
+			}
+
+			browserRegistry := browser.NewToolRegistry(browserManager)
+			browserRegistry.SetLLMProvider(provider)
+			browserRegistry.SetScreenshotDir(filepath.Join(config.WorkspaceDir, browser.DefaultScreenshotDir))
+			sessionTools = append(sessionTools, browserRegistry.RegisterTools()...)
+
+			if ui := appconfig.GetUI(); ui != nil && ui.IsBrowserEnabled() {
+				if err := browserManager.DetectInstallation(); err != nil {
+					cmdLog.Infof("Browser tools unavailable, using fetch_url instead: %v", err)
+				}
+			}
+		}
+
+		for _, tool := range sessionTools {
+			if err := ag.RegisterTool(tool); err != nil {
+				return nil, fmt.Errorf("failed to register tool %s: %w", tool.Name(), err)
+			}
+		}
+
+		return ag, nil
+	}
+
+	return factory, offlineReport, nil
+}
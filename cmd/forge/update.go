@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/executor/tui"
+	"github.com/entrhq/forge/pkg/update"
+)
+
+// runUpdate replaces this binary with the newest release on the selected
+// channel, after verifying the download's signature and checksum.
+func runUpdate(ctx context.Context, config *Config) error {
+	if err := appconfig.Initialize(""); err != nil {
+		return fmt.Errorf("failed to initialize configuration: %w", err)
+	}
+
+	// The -channel flag overrides the configured channel
+	channel := config.UpdateChannel
+	if channel == "" {
+		channel = update.ChannelStable
+		if updateCfg := appconfig.GetUpdate(); updateCfg != nil {
+			channel = updateCfg.GetChannel()
+		}
+	}
+	if err := update.ValidateChannel(channel); err != nil {
+		return err
+	}
+
+	client := update.NewClient()
+	fmt.Printf("Checking the %s channel for updates...\n", channel)
+	release, err := client.Latest(ctx, channel)
+	if err != nil {
+		return err
+	}
+
+	if !update.IsNewer(release.Version, version) {
+		fmt.Printf("Forge v%s is up to date\n", version)
+		return nil
+	}
+	fmt.Printf("Forge v%s is available (you have v%s): %s\n", release.Version, version, release.URL)
+	if config.UpdateCheckOnly {
+		return nil
+	}
+
+	key, err := update.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the forge binary: %w", err)
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return fmt.Errorf("failed to locate the forge binary: %w", err)
+	}
+
+	fmt.Println("Downloading and verifying...")
+	binary, err := client.Download(ctx, release, key)
+	if err != nil {
+		return err
+	}
+	if err := update.Install(exe, binary); err != nil {
+		return err
+	}
+
+	fmt.Printf("Updated %s to v%s\n", exe, release.Version)
+	return nil
+}
+
+// startUpdateCheck shows the update notice recorded by the last release check
+// and refreshes it in the background, so startup never waits on the network.
+func startUpdateCheck(ctx context.Context, executor *tui.Executor) {
+	updateCfg := appconfig.GetUpdate()
+	if updateCfg == nil || !updateCfg.IsCheckForUpdates() {
+		return
+	}
+
+	cachePath, err := update.CachePath()
+	if err != nil {
+		return
+	}
+
+	channel := updateCfg.GetChannel()
+	if notice := update.Notice(cachePath, version, channel); notice != "" {
+		executor.AddStartupWarning("Update available", notice, false)
+	}
+
+	go func() {
+		if err := update.NewClient().Refresh(ctx, cachePath, channel, time.Now()); err != nil {
+			cmdLog.Debugf("update check failed: %v", err)
+		}
+	}()
+}
@@ -77,6 +77,14 @@ type Agent interface {
 	SetProvider(provider llm.Provider) error
 }
 
+// ToolGroupInfo describes a group of tools with lazily sent schemas.
+type ToolGroupInfo struct {
+	Name      string
+	ToolCount int // Visible tools in the group
+	Tokens    int // Size of the group's schemas
+	Loaded    bool
+}
+
 // ContextInfo contains detailed agent context statistics
 type ContextInfo struct {
 	// System prompt
@@ -86,9 +94,15 @@ type ContextInfo struct {
 
 	// Tool system
 	ToolCount  int
-	ToolTokens int
+	ToolTokens int // Schemas and manifest sent with every call
 	ToolNames  []string
 
+	// Lazy tool schemas: the groups whose schemas are sent only once loaded
+	LoadedToolCount    int // Tools whose schemas are sent
+	ToolManifestTokens int
+	DeferredToolTokens int // Schemas left out until their groups are loaded
+	ToolGroups         []ToolGroupInfo
+
 	// Message history
 	MessageCount       int
 	ConversationTurns  int
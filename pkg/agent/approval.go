@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 
+	"github.com/entrhq/forge/pkg/agent/approval"
 	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/types"
 )
@@ -26,10 +27,14 @@ func (a *DefaultAgent) handleCommandCancellation(req *types.CancellationRequest)
 }
 
 // requestApproval sends an approval request and waits for user response
-// Returns (approved, timedOut) where:
-//   - approved: true if user approved, false if rejected
-//   - timedOut: true if the request timed out waiting for response
-func (a *DefaultAgent) requestApproval(ctx context.Context, toolCall tools.ToolCall, preview *tools.ToolPreview) (bool, bool) {
+func (a *DefaultAgent) requestApproval(ctx context.Context, toolCall tools.ToolCall, preview *tools.ToolPreview) approval.Outcome {
 	// Delegate all approval logic to the approval manager
 	return a.approvalManager.RequestApproval(ctx, toolCall, preview)
 }
+
+// SetAutoApproval controls whether approval rules and auto-approval settings
+// may decide tool approvals. When disabled, every approval request is emitted
+// as an event for the executor to answer.
+func (a *DefaultAgent) SetAutoApproval(enabled bool) {
+	a.approvalManager.SetAutoApproval(enabled)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
 	"github.com/entrhq/forge/pkg/types"
 	"github.com/google/uuid"
 )
@@ -19,6 +20,13 @@ type Manager struct {
 	pendingApprovals map[string]*pendingApproval
 	mu               sync.Mutex
 	emitEvent        EventEmitter
+
+	// manualOnly sends every request to the approval handler, bypassing
+	// approval rules and auto-approval settings
+	manualOnly bool
+
+	// guard makes the paths approval rules see workspace-relative (may be nil)
+	guard *workspace.Guard
 }
 
 // pendingApproval tracks an approval request that is waiting for user response
@@ -39,11 +47,36 @@ func NewManager(timeout time.Duration, emitEvent EventEmitter) *Manager {
 	}
 }
 
-// RequestApproval sends an approval request and waits for user response
-// Returns (approved, timedOut) where:
-//   - approved: true if user approved, false if rejected
-//   - timedOut: true if the request timed out waiting for response
-func (m *Manager) RequestApproval(ctx context.Context, toolCall tools.ToolCall, preview *tools.ToolPreview) (bool, bool) {
+// SetAutoApproval controls whether approval rules and auto-approval settings
+// may decide requests. When disabled, every request is sent to whoever
+// answers approval events, which then owns those decisions.
+func (m *Manager) SetAutoApproval(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.manualOnly = !enabled
+}
+
+// SetGuard sets the workspace guard that resolves the paths of tool calls
+// for the approval rules, so absolute and root-prefixed paths match them too.
+func (m *Manager) SetGuard(guard *workspace.Guard) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.guard = guard
+}
+
+// Outcome is the result of an approval request
+type Outcome struct {
+	Approved bool // The tool call may run
+	TimedOut bool // No response arrived before the timeout
+
+	// Reason explains a rejection made by an approval rule rather than the user
+	Reason string
+}
+
+// RequestApproval resolves a tool call with the approval rules and
+// auto-approval settings, asking the user and waiting for a response when
+// neither decides it.
+func (m *Manager) RequestApproval(ctx context.Context, toolCall tools.ToolCall, preview *tools.ToolPreview) Outcome {
 	// Generate unique approval ID
 	approvalID := uuid.New().String()
 
@@ -59,16 +92,17 @@ func (m *Manager) RequestApproval(ctx context.Context, toolCall tools.ToolCall,
 	// Parse tool input for event
 	argsMap := parseToolArguments(toolCall)
 
-	// Check for auto-approval
-	if approved, autoApproved := m.checkAutoApproval(approvalID, toolCall, argsMap); autoApproved {
-		return approved, false
+	// Approval rules and auto-approval settings may decide without asking
+	if outcome, decided := m.decide(approvalID, toolCall, argsMap, preview); decided {
+		return outcome
 	}
 
 	// Emit approval request event (tool requires manual approval)
 	m.emitEvent(types.NewToolApprovalRequestEvent(approvalID, toolCall.ToolName, argsMap, preview))
 
 	// Wait for response with timeout
-	return m.waitForResponse(ctx, approvalID, toolCall, responseChannel)
+	approved, timedOut := m.waitForResponse(ctx, approvalID, toolCall, responseChannel)
+	return Outcome{Approved: approved, TimedOut: timedOut}
 }
 
 // HandleResponse processes an approval response from the user
@@ -292,7 +292,8 @@ func TestManager_RequestApproval_GrantedResponse(t *testing.T) {
 		}
 	}()
 
-	approved, timedOut := manager.RequestApproval(ctx, toolCall, preview)
+	outcome := manager.RequestApproval(ctx, toolCall, preview)
+	approved, timedOut := outcome.Approved, outcome.TimedOut
 
 	if !approved {
 		t.Error("expected approval to be granted")
@@ -353,7 +354,8 @@ func TestManager_RequestApproval_RejectedResponse(t *testing.T) {
 		}
 	}()
 
-	approved, timedOut := manager.RequestApproval(ctx, toolCall, nil)
+	outcome := manager.RequestApproval(ctx, toolCall, nil)
+	approved, timedOut := outcome.Approved, outcome.TimedOut
 
 	if approved {
 		t.Error("expected approval to be rejected")
@@ -390,7 +392,8 @@ func TestManager_RequestApproval_Timeout(t *testing.T) {
 	}
 
 	// Don't send any response - let it timeout
-	approved, timedOut := manager.RequestApproval(ctx, toolCall, nil)
+	outcome := manager.RequestApproval(ctx, toolCall, nil)
+	approved, timedOut := outcome.Approved, outcome.TimedOut
 
 	if approved {
 		t.Error("expected approval to be rejected on timeout")
@@ -432,7 +435,8 @@ func TestManager_RequestApproval_ContextCancellation(t *testing.T) {
 		cancel()
 	}()
 
-	approved, timedOut := manager.RequestApproval(ctx, toolCall, nil)
+	outcome := manager.RequestApproval(ctx, toolCall, nil)
+	approved, timedOut := outcome.Approved, outcome.TimedOut
 
 	if approved {
 		t.Error("expected approval to be rejected on context cancellation")
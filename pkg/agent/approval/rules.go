@@ -0,0 +1,167 @@
+package approval
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// NewApprovalRequest describes a tool call for the approval rules. Its paths
+// are the path and destination arguments and the preview's file_path and
+// destination, made workspace-relative through guard, plus the files in a
+// directory the call deletes or moves. A nil guard leaves paths as given. The
+// diff size is counted from diff and new file previews.
+func NewApprovalRequest(toolName string, args map[string]any, preview *tools.ToolPreview, guard *workspace.Guard) config.ApprovalRequest {
+	req := config.ApprovalRequest{Tool: toolName}
+	req.Command, _ = args["command"].(string)
+
+	var targets []string
+	for _, key := range []string{"path", "destination"} {
+		if p, ok := args[key].(string); ok && p != "" {
+			targets = append(targets, p)
+		}
+	}
+	if preview != nil {
+		for _, key := range []string{"file_path", "destination"} {
+			if p, ok := preview.Metadata[key].(string); ok && p != "" {
+				targets = append(targets, p)
+			}
+		}
+	}
+	for _, target := range targets {
+		req.Paths = appendPath(req.Paths, relativePath(guard, target))
+	}
+	if toolName == "delete_file" || toolName == "move_file" {
+		req.Paths = appendDirectoryFiles(req.Paths, guard, args)
+	}
+
+	if preview == nil {
+		return req
+	}
+
+	switch preview.Type {
+	case tools.PreviewTypeDiff:
+		req.HasDiff = true
+		inHunk := false
+		for _, line := range strings.Split(preview.Content, "\n") {
+			// File headers (--- and +++) precede the first hunk
+			if strings.HasPrefix(line, "@@") {
+				inHunk = true
+				continue
+			}
+			if inHunk && (strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")) {
+				req.DiffLines++
+			}
+		}
+	case tools.PreviewTypeFileWrite:
+		req.HasDiff = true
+		if content := strings.TrimSuffix(preview.Content, "\n"); content != "" {
+			req.DiffLines = strings.Count(content, "\n") + 1
+		}
+	}
+	return req
+}
+
+// relativePath returns p relative to the workspace or the root containing it,
+// or as given when guard is nil or p is outside both.
+func relativePath(guard *workspace.Guard, p string) string {
+	if guard == nil {
+		return p
+	}
+	absPath, err := guard.ResolvePath(p)
+	if err != nil {
+		return p
+	}
+	relPath, err := guard.MakeRelative(absPath)
+	if err != nil {
+		return absPath
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// appendDirectoryFiles adds the files a delete or move of a directory
+// changes: every file in it and, for a move, where each would go.
+func appendDirectoryFiles(paths []string, guard *workspace.Guard, args map[string]any) []string {
+	source, _ := args["path"].(string)
+	if guard == nil || source == "" {
+		return paths
+	}
+	absSource, err := guard.ResolvePath(source)
+	if err != nil {
+		return paths
+	}
+	if info, statErr := os.Stat(absSource); statErr != nil || !info.IsDir() {
+		return paths
+	}
+	destination, _ := args["destination"].(string)
+	if destination != "" {
+		destination = relativePath(guard, destination)
+	}
+
+	_ = filepath.WalkDir(absSource, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil || d.IsDir() {
+			return nil //nolint:nilerr // unreadable entries are skipped
+		}
+		paths = appendPath(paths, relativePath(guard, p))
+		if destination != "" {
+			if rel, relErr := filepath.Rel(absSource, p); relErr == nil {
+				paths = appendPath(paths, destination+"/"+filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	return paths
+}
+
+// appendPath adds p to paths unless it is already there.
+func appendPath(paths []string, p string) []string {
+	if slices.Contains(paths, p) {
+		return paths
+	}
+	return append(paths, p)
+}
+
+// decide resolves a request without asking the user. The first matching
+// approval rule takes precedence over the auto-approval settings, and a rule
+// resolving to ask always prompts.
+func (m *Manager) decide(approvalID string, toolCall tools.ToolCall, argsMap map[string]any, preview *tools.ToolPreview) (Outcome, bool) {
+	m.mu.Lock()
+	manualOnly := m.manualOnly
+	guard := m.guard
+	m.mu.Unlock()
+	if manualOnly {
+		return Outcome{}, false
+	}
+
+	if rule, matched := config.EvaluateApprovalRules(NewApprovalRequest(toolCall.ToolName, argsMap, preview, guard)); matched {
+		return m.applyApprovalRule(approvalID, toolCall, rule)
+	}
+	if approved, autoApproved := m.checkAutoApproval(approvalID, toolCall, argsMap); autoApproved {
+		return Outcome{Approved: approved}, true
+	}
+	return Outcome{}, false
+}
+
+// applyApprovalRule grants or rejects a tool call as the matching rule
+// decided. It returns false for ask, which leaves the call to the user.
+func (m *Manager) applyApprovalRule(approvalID string, toolCall tools.ToolCall, rule config.ApprovalResult) (Outcome, bool) {
+	switch rule.Decision {
+	case config.ApprovalAllow:
+		m.emitEvent(types.NewToolApprovalGrantedEvent(approvalID, toolCall.ToolName))
+		return Outcome{Approved: true}, true
+	case config.ApprovalDeny:
+		event := types.NewToolApprovalRejectedEvent(approvalID, toolCall.ToolName)
+		event.Metadata["reason"] = rule.Reason()
+		m.emitEvent(event)
+		return Outcome{Reason: rule.Reason()}, true
+	default:
+		return Outcome{}, false
+	}
+}
@@ -0,0 +1,176 @@
+package approval
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestNewApprovalRequest(t *testing.T) {
+	diff := &tools.ToolPreview{
+		Type:     tools.PreviewTypeDiff,
+		Content:  "--- main.go\n+++ main.go\n@@ -1,3 +1,3 @@\n package main\n--- removed comment\n+// added comment\n-old\n",
+		Metadata: map[string]any{"file_path": "cmd/main.go"},
+	}
+	req := NewApprovalRequest("apply_diff", map[string]any{"path": "cmd/main.go"}, diff, nil)
+	if !slices.Equal(req.Paths, []string{"cmd/main.go"}) || !req.HasDiff || req.DiffLines != 3 {
+		t.Errorf("expected the preview's path and 3 changed lines, got %+v", req)
+	}
+
+	newFile := &tools.ToolPreview{Type: tools.PreviewTypeFileWrite, Content: "a\nb\n"}
+	req = NewApprovalRequest("write_file", map[string]any{"path": "notes.txt"}, newFile, nil)
+	if !slices.Equal(req.Paths, []string{"notes.txt"}) || !req.HasDiff || req.DiffLines != 2 {
+		t.Errorf("expected a new two-line file, got %+v", req)
+	}
+
+	req = NewApprovalRequest("execute_command", map[string]any{"command": "go test ./..."}, &tools.ToolPreview{Type: tools.PreviewTypeCommand}, nil)
+	if req.Command != "go test ./..." || req.HasDiff {
+		t.Errorf("expected a command without a diff, got %+v", req)
+	}
+}
+
+func TestNewApprovalRequest_Paths(t *testing.T) {
+	dir := t.TempDir()
+	guard, err := workspace.NewGuard(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "secrets", "prod"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secrets", "prod", "key.pem"), []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want []string
+	}{
+		{"absolute path", "write_file", map[string]any{"path": filepath.Join(dir, "secrets", "x")}, []string{"secrets/x"}},
+		{"move destination", "move_file", map[string]any{"path": "notes.txt", "destination": "secrets/notes.txt"}, []string{"notes.txt", "secrets/notes.txt"}},
+		{"directory delete", "delete_file", map[string]any{"path": "secrets", "recursive": true}, []string{"secrets", "secrets/prod/key.pem"}},
+		{"directory move", "move_file", map[string]any{"path": "secrets", "destination": "public"}, []string{"secrets", "public", "secrets/prod/key.pem", "public/prod/key.pem"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if req := NewApprovalRequest(tt.tool, tt.args, nil, guard); !slices.Equal(req.Paths, tt.want) {
+				t.Errorf("Paths = %v, want %v", req.Paths, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_RequestApproval_ApprovalRules(t *testing.T) {
+	if err := config.Initialize(filepath.Join(t.TempDir(), "config.json")); err != nil {
+		t.Fatalf("failed to initialize config: %v", err)
+	}
+	config.GetAutoApproval().SetToolAutoApproval("write_file", true)
+
+	config.SetProjectConfig(&config.ProjectConfig{ApprovalRules: []config.ApprovalRule{
+		{Name: "secrets", Paths: []string{"**/.env"}, Decision: config.ApprovalDeny},
+		{Tools: []string{"write_file"}, Paths: []string{"docs/**"}, Decision: config.ApprovalAsk},
+		{Tools: []string{"execute_command"}, Commands: []string{`^make lint$`}, Decision: config.ApprovalAllow},
+	}})
+	t.Cleanup(func() { config.SetProjectConfig(nil) })
+
+	writeCall := func(path string) tools.ToolCall {
+		return tools.ToolCall{ToolName: "write_file", Arguments: tools.ArgumentsBlock{InnerXML: []byte("<path>" + path + "</path><content>x</content>")}}
+	}
+
+	t.Run("deny", func(t *testing.T) {
+		emitter := &mockEventEmitter{}
+		manager := NewManager(time.Second, emitter.emit)
+
+		outcome := manager.RequestApproval(context.Background(), writeCall("app/.env"), nil)
+		if outcome.Approved || outcome.Reason != "approval rule 'secrets' in .forge/config.yaml" {
+			t.Errorf("expected a rejection by the secrets rule, got %+v", outcome)
+		}
+		events := emitter.getEvents()
+		if len(events) != 1 || events[0].Type != types.EventTypeToolApprovalRejected || events[0].Metadata["reason"] != outcome.Reason {
+			t.Errorf("expected a rejected event carrying the reason, got %+v", events)
+		}
+	})
+
+	t.Run("allow", func(t *testing.T) {
+		emitter := &mockEventEmitter{}
+		manager := NewManager(time.Second, emitter.emit)
+
+		call := tools.ToolCall{ToolName: "execute_command", Arguments: tools.ArgumentsBlock{InnerXML: []byte("<command>make lint</command>")}}
+		if outcome := manager.RequestApproval(context.Background(), call, nil); !outcome.Approved {
+			t.Errorf("expected the command to be allowed, got %+v", outcome)
+		}
+	})
+
+	t.Run("ask overrides auto-approval", func(t *testing.T) {
+		emitter := &mockEventEmitter{}
+		manager := NewManager(50*time.Millisecond, emitter.emit)
+
+		outcome := manager.RequestApproval(context.Background(), writeCall("docs/guide.md"), nil)
+		if !outcome.TimedOut {
+			t.Errorf("expected the user to be asked, got %+v", outcome)
+		}
+		if events := emitter.getEvents(); len(events) == 0 || events[0].Type != types.EventTypeToolApprovalRequest {
+			t.Errorf("expected an approval request event, got %+v", events)
+		}
+	})
+
+	t.Run("no rule falls back to auto-approval", func(t *testing.T) {
+		manager := NewManager(time.Second, (&mockEventEmitter{}).emit)
+		if outcome := manager.RequestApproval(context.Background(), writeCall("main.go"), nil); !outcome.Approved {
+			t.Errorf("expected the auto-approval setting to apply, got %+v", outcome)
+		}
+	})
+
+	t.Run("auto-approval disabled", func(t *testing.T) {
+		emitter := &mockEventEmitter{}
+		manager := NewManager(50*time.Millisecond, emitter.emit)
+		manager.SetAutoApproval(false)
+
+		outcome := manager.RequestApproval(context.Background(), writeCall("app/.env"), nil)
+		if !outcome.TimedOut || outcome.Reason != "" {
+			t.Errorf("expected the request to be left to the approval handler, got %+v", outcome)
+		}
+	})
+}
+
+func TestManager_RequestApproval_DenyRuleCoversEveryPath(t *testing.T) {
+	if err := config.Initialize(filepath.Join(t.TempDir(), "config.json")); err != nil {
+		t.Fatalf("failed to initialize config: %v", err)
+	}
+	config.GetAutoApproval().SetToolAutoApproval("write_file", true)
+	config.GetAutoApproval().SetToolAutoApproval("move_file", true)
+	config.SetProjectConfig(&config.ProjectConfig{ApprovalRules: []config.ApprovalRule{
+		{Name: "secrets", Paths: []string{"secrets/**"}, Decision: config.ApprovalDeny},
+	}})
+	t.Cleanup(func() { config.SetProjectConfig(nil) })
+
+	dir := t.TempDir()
+	guard, err := workspace.NewGuard(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := map[string]tools.ToolCall{
+		"move into a denied directory": {ToolName: "move_file", Arguments: tools.ArgumentsBlock{InnerXML: []byte("<path>notes.txt</path><destination>secrets/notes.txt</destination>")}},
+		"absolute path":                {ToolName: "write_file", Arguments: tools.ArgumentsBlock{InnerXML: []byte("<path>" + filepath.Join(dir, "secrets", "x") + "</path><content>x</content>")}},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			manager := NewManager(time.Second, (&mockEventEmitter{}).emit)
+			manager.SetGuard(guard)
+			if outcome := manager.RequestApproval(context.Background(), call, nil); outcome.Approved || outcome.Reason == "" {
+				t.Errorf("expected a rejection by the secrets rule, got %+v", outcome)
+			}
+		})
+	}
+}
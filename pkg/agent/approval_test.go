@@ -157,7 +157,8 @@ func TestApprovalSystem_RequestApproval(t *testing.T) {
 				}()
 			}
 
-			approved, timedOut := agent.requestApproval(ctx, toolCall, preview)
+			outcome := agent.requestApproval(ctx, toolCall, preview)
+			approved, timedOut := outcome.Approved, outcome.TimedOut
 
 			if approved != tt.expectApproved {
 				t.Errorf("approved = %v, want %v", approved, tt.expectApproved)
@@ -2,6 +2,8 @@ package agent
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/entrhq/forge/pkg/types"
 )
@@ -10,6 +12,7 @@ import (
 // The loop continues until a loop-breaking tool is used or circuit breaker triggers
 func (a *DefaultAgent) runAgentLoop(ctx context.Context) {
 	var errorContext string
+	budget := a.newTurnBudget()
 
 	for {
 		// Check if context was canceled (e.g., via /stop command)
@@ -32,6 +35,17 @@ func (a *DefaultAgent) runAgentLoop(ctx context.Context) {
 
 		// Update error context for next iteration
 		errorContext = nextErrorContext
+
+		// Tell the agent to wrap up once the turn has used up its budget, and
+		// stop it if it keeps going
+		wrapUp, withinBudget := a.checkTurnBudget(budget)
+		if !withinBudget {
+			a.memory.Add(types.NewUserMessage("Operation stopped: the turn used up its budget."))
+			return
+		}
+		if wrapUp != "" {
+			errorContext = strings.TrimSpace(errorContext + "\n\n" + wrapUp)
+		}
 	}
 }
 
@@ -61,6 +75,9 @@ func (a *DefaultAgent) executeIteration(ctx context.Context, errorContext string
 	a.recordResponse(pctx, resp)
 
 	// Step 5: Process the tool call (parse, validate, execute)
+	if a.nativeToolCalls() {
+		return a.processNativeToolCall(ctx, resp.nativeToolCalls)
+	}
 	return a.processToolCall(ctx, resp.toolCallContent)
 }
 
@@ -90,11 +107,26 @@ func (a *DefaultAgent) refreshCustomToolRegistry() {
 // emitEvent sends an event on the event channel.
 // This is a blocking send to ensure critical events like TurnEnd are not dropped.
 // It safely handles the case where the event channel may be closed during shutdown.
+// Events are stamped with the current time and turn ID unless already set.
 //
 //nolint:errcheck // Recover is intentionally ignored - panic during shutdown is expected
 func (a *DefaultAgent) emitEvent(event *types.AgentEvent) {
 	defer func() {
 		_ = recover() // Event channel was closed during shutdown - this is expected
 	}()
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.TurnID == "" {
+		a.cancelMu.Lock()
+		event.TurnID = a.currentTurnID
+		a.cancelMu.Unlock()
+	}
+	if a.recorder != nil {
+		a.recorder.RecordEvent(event)
+	}
+	for _, observer := range a.eventObservers {
+		observer.ObserveEvent(event)
+	}
 	a.channels.Event <- event
 }
@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"github.com/entrhq/forge/pkg/agent/memory/notes"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Checkpoint is a serializable copy of the state an agent accumulates during a
+// session: its conversation history and scratchpad notes.
+type Checkpoint struct {
+	Messages []*types.Message `json:"messages"`
+	Notes    []*notes.Note    `json:"notes,omitempty"`
+}
+
+// Checkpointer is implemented by agents whose session state can be saved and
+// later restored, e.g. to recover a conversation after a terminal crash.
+type Checkpointer interface {
+	// Checkpoint returns a copy of the agent's current session state.
+	Checkpoint() *Checkpoint
+
+	// RestoreCheckpoint replaces the agent's session state with cp. It must
+	// only be called while the agent is idle.
+	RestoreCheckpoint(cp *Checkpoint)
+}
+
+// Checkpoint returns the current conversation history and all notes,
+// including scratched ones.
+func (a *DefaultAgent) Checkpoint() *Checkpoint {
+	return &Checkpoint{
+		Messages: a.memory.GetAll(),
+		Notes: a.notesManager.List(notes.ListOptions{
+			IncludeScratched: true,
+			Limit:            a.notesManager.Count(),
+		}),
+	}
+}
+
+// RestoreCheckpoint replaces the conversation history and notes with those
+// saved in cp. The session ID is kept so long-term memory captures made after
+// the restore stay correlated with this agent lifecycle.
+func (a *DefaultAgent) RestoreCheckpoint(cp *Checkpoint) {
+	a.memory.Clear()
+	for _, msg := range cp.Messages {
+		if msg != nil {
+			a.memory.Add(msg)
+		}
+	}
+	a.notesManager.Restore(cp.Notes)
+}
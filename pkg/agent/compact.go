@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	agentcontext "github.com/entrhq/forge/pkg/agent/context"
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/agent/prompts"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Compact runs the context manager's summarization strategies immediately
+// instead of waiting for their thresholds. Token counts in the result
+// include the system prompt.
+func (a *DefaultAgent) Compact(ctx context.Context, opts agentcontext.CompactOptions) (*agentcontext.CompactResult, error) {
+	if a.contextManager == nil {
+		return nil, fmt.Errorf("context management is not enabled")
+	}
+	convMem, ok := a.memory.(*memory.ConversationMemory)
+	if !ok {
+		return nil, fmt.Errorf("memory type %T does not support compaction", a.memory)
+	}
+
+	overheadTokens := 0
+	if a.tokenizer != nil {
+		overheadTokens = a.tokenizer.CountMessagesTokens(prompts.BuildMessages(a.buildSystemPrompt(), nil, "", ""))
+	}
+
+	return a.contextManager.Compact(ctx, convMem, overheadTokens, opts)
+}
+
+// handleCompactRequest compacts the context on behalf of a /compact command.
+// The context manager reports progress through summarization events; the
+// compaction can be stopped like a turn.
+func (a *DefaultAgent) handleCompactRequest(ctx context.Context, input *types.Input) {
+	params, _ := input.Metadata["params"].(types.CompactRequestParams)
+
+	level, err := agentcontext.ParseCompactLevel(params.Level)
+	if err != nil {
+		a.emitEvent(types.NewErrorEvent(err))
+		return
+	}
+
+	compactCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	a.cancelMu.Lock()
+	a.cancelStream = cancel
+	a.cancelMu.Unlock()
+
+	defer func() {
+		a.cancelMu.Lock()
+		a.cancelStream = nil
+		a.cancelMu.Unlock()
+	}()
+
+	a.emitEvent(types.NewUpdateBusyEvent(true))
+	defer a.emitEvent(types.NewUpdateBusyEvent(false))
+
+	result, err := a.Compact(compactCtx, agentcontext.CompactOptions{
+		Level:        level,
+		TargetTokens: params.TargetTokens,
+	})
+	if err != nil {
+		agentDebugLog.Printf("Compaction failed: %v", err)
+		if result == nil {
+			// Failed before the context manager emitted its own events
+			a.emitEvent(types.NewErrorEvent(err))
+		}
+		return
+	}
+	agentDebugLog.Printf("Compacted context from %d to %d tokens", result.TokensBefore, result.TokensAfter)
+}
@@ -0,0 +1,132 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// CompactLevel controls how much of the conversation an on-demand
+// compaction summarizes.
+type CompactLevel string
+
+const (
+	// CompactLevelLight summarizes old tool calls and completed turns but
+	// leaves the recent conversation alone.
+	CompactLevelLight CompactLevel = "light"
+	// CompactLevelNormal also collapses the older half of the conversation
+	// into a summary, like the threshold strategy does at its limit.
+	CompactLevelNormal CompactLevel = "normal"
+	// CompactLevelAggressive repeats the strategies until nothing more can be
+	// summarized.
+	CompactLevelAggressive CompactLevel = "aggressive"
+)
+
+// CompactStrategyName identifies on-demand compaction in summarization events
+const CompactStrategyName = "Compact"
+
+// maxCompactPasses bounds how often a compaction runs the strategy chain
+const maxCompactPasses = 5
+
+// ParseCompactLevel parses an aggressiveness level. An empty string selects
+// CompactLevelNormal.
+func ParseCompactLevel(s string) (CompactLevel, error) {
+	switch level := CompactLevel(s); level {
+	case "":
+		return CompactLevelNormal, nil
+	case CompactLevelLight, CompactLevelNormal, CompactLevelAggressive:
+		return level, nil
+	default:
+		return "", fmt.Errorf("invalid compaction level %q (must be %q, %q or %q)", s, CompactLevelLight, CompactLevelNormal, CompactLevelAggressive)
+	}
+}
+
+// CompactOptions configures an on-demand compaction.
+type CompactOptions struct {
+	Level CompactLevel
+	// TargetTokens stops compaction once the context is at or below this
+	// size, repeating the strategies until it is reached. 0 means no target.
+	TargetTokens int
+}
+
+// CompactResult reports what an on-demand compaction did.
+type CompactResult struct {
+	Summarized   int // Messages or groups replaced by summaries
+	TokensBefore int
+	TokensAfter  int
+}
+
+// Compact runs the summarization strategies immediately, ignoring their
+// triggers. overheadTokens is the size of the prompt outside the conversation
+// (system prompt and tool definitions), so that token counts and
+// opts.TargetTokens refer to the whole context. It emits a single pair of
+// summarization events for the TUI.
+func (m *Manager) Compact(ctx context.Context, conv *memory.ConversationMemory, overheadTokens int, opts CompactOptions) (*CompactResult, error) {
+	level := opts.Level
+	if level == "" {
+		level = CompactLevelNormal
+	}
+
+	countTokens := func() int {
+		return m.CalibrateTokens(overheadTokens + m.tokenizer.CountMessagesTokens(conv.GetAll()))
+	}
+	current := countTokens()
+	result := &CompactResult{TokensBefore: current}
+	targetReached := func() bool {
+		return opts.TargetTokens > 0 && current <= opts.TargetTokens
+	}
+
+	if m.eventChannel != nil {
+		m.eventChannel <- types.NewContextSummarizationStartEvent(CompactStrategyName, current, m.maxTokens)
+	}
+	startTime := time.Now()
+
+	for pass := 0; pass < maxCompactPasses && !targetReached(); pass++ {
+		passSummarized := 0
+		for _, strategy := range m.strategies {
+			if targetReached() {
+				break
+			}
+			// Light compaction keeps the recent conversation verbatim
+			if _, ok := strategy.(*ThresholdSummarizationStrategy); ok && level == CompactLevelLight {
+				continue
+			}
+
+			debugLog.Printf("Compact: executing Summarize() for strategy %s", strategy.Name())
+			summarized, err := strategy.Summarize(ctx, conv, m.providerForSummarization())
+			if err != nil {
+				if m.eventChannel != nil {
+					m.eventChannel <- types.NewContextSummarizationErrorEvent(CompactStrategyName, err)
+				}
+				result.TokensAfter = countTokens()
+				return result, fmt.Errorf("strategy %s failed: %w", strategy.Name(), err)
+			}
+			passSummarized += summarized
+			current = countTokens()
+		}
+		result.Summarized += passSummarized
+
+		// Repeat only while making progress towards a target, or when aggressive
+		if passSummarized == 0 || (opts.TargetTokens == 0 && level != CompactLevelAggressive) {
+			break
+		}
+	}
+
+	result.TokensAfter = current
+	debugLog.Printf("Compact: summarized %d, tokens %d -> %d", result.Summarized, result.TokensBefore, result.TokensAfter)
+
+	if m.eventChannel != nil {
+		m.eventChannel <- types.NewContextSummarizationCompleteEvent(
+			CompactStrategyName,
+			result.TokensBefore-result.TokensAfter,
+			result.TokensAfter,
+			result.Summarized,
+			time.Since(startTime).String(),
+		)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,125 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCompactLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    CompactLevel
+		wantErr bool
+	}{
+		{"", CompactLevelNormal, false},
+		{"light", CompactLevelLight, false},
+		{"normal", CompactLevelNormal, false},
+		{"aggressive", CompactLevelAggressive, false},
+		{"extreme", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseCompactLevel(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// newCompactFixture returns a manager with only the threshold strategy, whose
+// trigger is never met, and a conversation of n messages.
+func newCompactFixture(t *testing.T, n int) (*Manager, *memory.ConversationMemory, *MockLLMProvider) {
+	t.Helper()
+
+	mockLLM := new(MockLLMProvider)
+	mockLLM.On("Complete", mock.Anything, mock.Anything).Return(types.NewAssistantMessage("summary"), nil)
+	mockLLM.On("GetModel").Return("gpt-4o").Maybe()
+
+	m, err := NewManager(mockLLM, 1_000_000, NewThresholdSummarizationStrategy(80))
+	if err != nil {
+		t.Skipf("tokenizer unavailable: %v", err)
+	}
+
+	conv := memory.NewConversationMemory()
+	for i := range n {
+		if i%2 == 0 {
+			conv.Add(types.NewUserMessage(fmt.Sprintf("user message %d", i)))
+		} else {
+			conv.Add(types.NewAssistantMessage(fmt.Sprintf("assistant message %d", i)))
+		}
+	}
+	return m, conv, mockLLM
+}
+
+func TestManager_Compact_Normal(t *testing.T) {
+	m, conv, mockLLM := newCompactFixture(t, 8)
+	events := make(chan *types.AgentEvent, 10)
+	m.SetEventChannel(events)
+
+	result, err := m.Compact(context.Background(), conv, 100, CompactOptions{Level: CompactLevelNormal})
+	require.NoError(t, err)
+
+	// Runs once even though the threshold is far away
+	mockLLM.AssertNumberOfCalls(t, "Complete", 1)
+	assert.Equal(t, 4, result.Summarized)
+	assert.Len(t, conv.GetAll(), 5)
+	assert.Less(t, result.TokensAfter, result.TokensBefore)
+	assert.Greater(t, result.TokensAfter, 100, "token counts include the overhead")
+
+	require.Len(t, events, 2)
+	assert.Equal(t, types.EventTypeContextSummarizationStart, (<-events).Type)
+	complete := <-events
+	assert.Equal(t, types.EventTypeContextSummarizationComplete, complete.Type)
+	assert.Equal(t, result.TokensAfter, complete.ContextSummarization.NewTokenCount)
+}
+
+func TestManager_Compact_LightKeepsRecentConversation(t *testing.T) {
+	m, conv, mockLLM := newCompactFixture(t, 8)
+
+	result, err := m.Compact(context.Background(), conv, 0, CompactOptions{Level: CompactLevelLight})
+	require.NoError(t, err)
+
+	mockLLM.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+	assert.Equal(t, 0, result.Summarized)
+	assert.Len(t, conv.GetAll(), 8)
+}
+
+func TestManager_Compact_AggressiveRepeats(t *testing.T) {
+	m, conv, mockLLM := newCompactFixture(t, 8)
+
+	result, err := m.Compact(context.Background(), conv, 0, CompactOptions{Level: CompactLevelAggressive})
+	require.NoError(t, err)
+
+	// 8 -> 5 -> 4 -> 3 messages, then too few to split
+	mockLLM.AssertNumberOfCalls(t, "Complete", 3)
+	assert.Equal(t, 8, result.Summarized)
+	assert.Len(t, conv.GetAll(), 3)
+}
+
+func TestManager_Compact_TargetTokens(t *testing.T) {
+	m, conv, mockLLM := newCompactFixture(t, 8)
+
+	// Already under the target: nothing to do
+	result, err := m.Compact(context.Background(), conv, 0, CompactOptions{TargetTokens: 1_000_000})
+	require.NoError(t, err)
+	mockLLM.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+	assert.Equal(t, result.TokensBefore, result.TokensAfter)
+
+	// An unreachable target repeats until nothing is left to summarize
+	result, err = m.Compact(context.Background(), conv, 0, CompactOptions{Level: CompactLevelNormal, TargetTokens: 1})
+	require.NoError(t, err)
+	mockLLM.AssertNumberOfCalls(t, "Complete", 3)
+	assert.Equal(t, 8, result.Summarized)
+}
@@ -190,7 +190,8 @@ func (s *GoalBatchCompactionStrategy) Summarize(ctx context.Context, conv *memor
 //
 // A complete turn is a user message followed by at least one regular [SUMMARIZED]
 // block (summarized=true, not a [GOAL BATCH]) before the next user message.
-// System messages and existing [GOAL BATCH] blocks are skipped transparently.
+// System messages, pinned messages and existing [GOAL BATCH] blocks are
+// skipped transparently, so a pinned user message never starts a turn.
 // Incomplete turns (user message with no following summary) are never returned.
 func (s *GoalBatchCompactionStrategy) collectCompleteTurns(messages []*types.Message) []completeTurn {
 	var turns []completeTurn
@@ -199,8 +200,9 @@ func (s *GoalBatchCompactionStrategy) collectCompleteTurns(messages []*types.Mes
 	for i < len(messages) {
 		msg := messages[i]
 
-		// Skip system messages and already-compacted [GOAL BATCH] blocks.
-		if msg.Role == types.RoleSystem || isGoalBatch(msg) {
+		// Skip system messages, pinned messages and already-compacted
+		// [GOAL BATCH] blocks.
+		if isPreserved(msg) || isGoalBatch(msg) {
 			i++
 			continue
 		}
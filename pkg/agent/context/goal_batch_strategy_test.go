@@ -131,6 +131,20 @@ func TestCollectCompleteTurns_SkipsSystemMessages(t *testing.T) {
 	assert.Equal(t, "goal 1", turns[0].userMessage.Content)
 }
 
+func TestCollectCompleteTurns_SkipsPinnedMessages(t *testing.T) {
+	s := NewGoalBatchCompactionStrategy(5, 2, 6)
+	messages := []*types.Message{
+		types.NewUserMessage("spec").WithMetadata(types.MetadataPinned, true),
+		newSummarizedMsg("[SUMMARIZED] read the spec"),
+		types.NewUserMessage("goal 1"),
+		newSummarizedMsg("[SUMMARIZED] did goal 1"),
+	}
+
+	turns := s.collectCompleteTurns(messages)
+	assert.Len(t, turns, 1, "a pinned user message never starts a turn")
+	assert.Equal(t, "goal 1", turns[0].userMessage.Content)
+}
+
 // --- ShouldRun ---
 
 func TestGoalBatchCompactionStrategy_ShouldRun_NotEnoughMessages(t *testing.T) {
@@ -13,3 +13,9 @@ func isSummarized(msg *types.Message) bool {
 	summarized, ok := msg.Metadata["summarized"].(bool)
 	return ok && summarized
 }
+
+// isPreserved reports whether a message must be kept verbatim by every
+// strategy: system messages, and messages pinned by the user or the agent.
+func isPreserved(msg *types.Message) bool {
+	return msg.Role == types.RoleSystem || msg.IsPinned()
+}
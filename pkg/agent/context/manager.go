@@ -3,10 +3,12 @@ package context
 import (
 	"context"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
 	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/config"
 	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/llm/tokenizer"
 	"github.com/entrhq/forge/pkg/logging"
@@ -27,21 +29,39 @@ func init() {
 // Manager orchestrates multiple context summarization strategies,
 // evaluating them in order and emitting events for TUI feedback.
 type Manager struct {
-	strategies         []Strategy
-	llm                llm.Provider
-	summarizationModel string // optional model override for summarization calls
-	tokenizer          *tokenizer.Tokenizer
-	maxTokens          int
-	eventChannel       chan<- *types.AgentEvent
-	mu                 sync.RWMutex // protects llm and summarizationModel
+	strategies            []Strategy
+	llm                   llm.Provider
+	summarizationModel    string                // optional model override for summarization calls
+	summarizationSampling config.SamplingParams // sampling parameters for summarization calls
+	tokenizer             *tokenizer.Tokenizer
+	maxTokens             int
+	eventChannel          chan<- *types.AgentEvent
+	mu                    sync.RWMutex // protects llm, summarizationModel and summarizationSampling
+
+	// usageRatio is the provider-reported prompt size per estimated token
+	// from the last reconciled call, or 0 before the first one
+	usageRatio float64
+	usageMu    sync.Mutex
 }
 
+// Bounds on the usage ratio. Reports outside them are more likely a
+// provider quirk, such as counting cached tokens separately, than tokenizer
+// drift, so they are clamped.
+const (
+	minUsageRatio = 0.5
+	maxUsageRatio = 2.0
+)
+
 // NewManager creates a new context manager with the given strategies.
 // Strategies are evaluated in the order provided.
 // The event channel should be set later via SetEventChannel() once the agent creates it.
 func NewManager(llm llm.Provider, maxTokens int, strategies ...Strategy) (*Manager, error) {
-	// Create tokenizer for accurate token counting
-	tok, err := tokenizer.New()
+	// Create tokenizer for accurate token counting, matched to the model
+	var model string
+	if llm != nil {
+		model = llm.GetModel()
+	}
+	tok, err := tokenizer.NewForModel(model)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tokenizer: %w", err)
 	}
@@ -76,8 +96,51 @@ func (m *Manager) SetEventChannel(eventChan chan<- *types.AgentEvent) {
 // This is called when the agent's provider is hot-reloaded.
 func (m *Manager) SetProvider(provider llm.Provider) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	previous := m.llm
 	m.llm = provider
+	m.mu.Unlock()
+
+	// A new model counts tokens differently, so earlier reconciliation no
+	// longer applies
+	if provider == nil || (previous != nil && previous.GetModel() == provider.GetModel()) {
+		return
+	}
+	if err := m.tokenizer.SetModel(provider.GetModel()); err != nil {
+		debugLog.Printf("Keeping %s tokenizer for %s: %v", m.tokenizer.Encoding(), provider.GetModel(), err)
+	}
+	m.usageMu.Lock()
+	m.usageRatio = 0
+	m.usageMu.Unlock()
+}
+
+// ReconcileUsage records the prompt tokens the provider reported for a call
+// whose prompt the tokenizer estimated at estimated tokens. Later estimates
+// passed to CalibrateTokens are scaled by the ratio between the two, so the
+// summarization threshold tracks billed usage rather than tokenizer drift.
+func (m *Manager) ReconcileUsage(estimated, reported int) {
+	if estimated <= 0 || reported <= 0 {
+		return
+	}
+	ratio := min(max(float64(reported)/float64(estimated), minUsageRatio), maxUsageRatio)
+
+	m.usageMu.Lock()
+	defer m.usageMu.Unlock()
+	m.usageRatio = ratio
+	debugLog.Printf("Reconciled token usage: estimated %d, reported %d (ratio %.3f)", estimated, reported, ratio)
+}
+
+// CalibrateTokens scales a tokenizer estimate by the ratio from the last
+// reconciled call. Estimates are returned unchanged until the provider has
+// reported usage.
+func (m *Manager) CalibrateTokens(estimated int) int {
+	m.usageMu.Lock()
+	ratio := m.usageRatio
+	m.usageMu.Unlock()
+
+	if ratio == 0 {
+		return estimated
+	}
+	return int(math.Round(float64(estimated) * ratio))
 }
 
 // SetSummarizationModel sets the model name to use for summarization LLM calls.
@@ -97,24 +160,34 @@ func (m *Manager) GetSummarizationModel() string {
 	return m.summarizationModel
 }
 
+// SetSummarizationSampling sets the sampling parameters used for summarization
+// calls. They replace whatever parameters the agent's provider carries, so the
+// zero value means provider defaults rather than the agent's settings.
+func (m *Manager) SetSummarizationSampling(params config.SamplingParams) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.summarizationSampling = params
+}
+
 // providerForSummarization returns the provider to use for summarization calls.
 // If a summarization model override is configured and the provider implements
 // llm.ModelCloner, returns a lightweight clone with the override model.
-// Otherwise returns m.llm unchanged.
+// Summarization sampling parameters are applied when the provider supports them,
+// and responses are answered from the shared response cache when it is on.
 // The caller must not hold m.mu.
 func (m *Manager) providerForSummarization() llm.Provider {
 	m.mu.RLock()
 	provider := m.llm
 	model := m.summarizationModel
+	sampling := m.summarizationSampling
 	m.mu.RUnlock()
 
-	if model == "" {
-		return provider
-	}
-	if cloner, ok := provider.(llm.ModelCloner); ok {
-		return cloner.CloneWithModel(model)
+	if model != "" {
+		if cloner, ok := provider.(llm.ModelCloner); ok {
+			provider = cloner.CloneWithModel(model)
+		}
 	}
-	return provider
+	return llm.WithSampling(llm.WithResponseCache(provider, llm.SharedResponseCache()), sampling)
 }
 
 // EvaluateAndSummarize evaluates all strategies and performs summarization if needed.
@@ -163,7 +236,7 @@ func (m *Manager) EvaluateAndSummarize(ctx context.Context, conv *memory.Convers
 
 		// Recalculate current tokens after summarization using accurate tokenizer
 		messages := conv.GetAll()
-		newTokenCount := m.tokenizer.CountMessagesTokens(messages)
+		newTokenCount := m.CalibrateTokens(m.tokenizer.CountMessagesTokens(messages))
 
 		// Calculate tokens saved
 		tokensSaved := currentTokens - newTokenCount
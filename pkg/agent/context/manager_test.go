@@ -0,0 +1,48 @@
+package context
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_ReconcileUsage(t *testing.T) {
+	m := &Manager{}
+
+	// Estimates pass through until the provider reports usage
+	assert.Equal(t, 1000, m.CalibrateTokens(1000))
+
+	m.ReconcileUsage(1000, 1150)
+	assert.Equal(t, 2300, m.CalibrateTokens(2000))
+
+	// Missing reports leave the ratio alone
+	m.ReconcileUsage(1000, 0)
+	m.ReconcileUsage(0, 500)
+	assert.Equal(t, 2300, m.CalibrateTokens(2000))
+
+	// Implausible reports are clamped
+	m.ReconcileUsage(100, 10_000)
+	assert.Equal(t, 2000, m.CalibrateTokens(1000))
+	m.ReconcileUsage(10_000, 100)
+	assert.Equal(t, 500, m.CalibrateTokens(1000))
+}
+
+func TestManager_SetProviderResetsUsage(t *testing.T) {
+	first := new(MockLLMProvider)
+	first.On("GetModel").Return("gpt-4o")
+	second := new(MockLLMProvider)
+	second.On("GetModel").Return("claude-sonnet-4")
+
+	m, err := NewManager(first, 100_000)
+	if err != nil {
+		t.Skipf("tokenizer unavailable: %v", err)
+	}
+	m.ReconcileUsage(1000, 1200)
+
+	// The same model keeps the calibration
+	m.SetProvider(first)
+	assert.Equal(t, 1200, m.CalibrateTokens(1000))
+
+	m.SetProvider(second)
+	assert.Equal(t, 1000, m.CalibrateTokens(1000))
+}
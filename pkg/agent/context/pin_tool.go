@@ -0,0 +1,98 @@
+package context
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// PinRequestFunc pins the user's most recent message and returns its
+// content, typically DefaultAgent.PinLastUserMessage.
+type PinRequestFunc func() (string, error)
+
+// PinContextTool lets the agent mark context that must survive
+// summarization verbatim, such as a spec or acceptance criteria. Pinned
+// content is kept as-is by every summarization strategy and never pruned.
+type PinContextTool struct {
+	pinRequest PinRequestFunc
+}
+
+// NewPinContextTool creates a new PinContextTool.
+func NewPinContextTool(pinRequest PinRequestFunc) *PinContextTool {
+	return &PinContextTool{
+		pinRequest: pinRequest,
+	}
+}
+
+// Name returns the tool name.
+func (t *PinContextTool) Name() string {
+	return "pin_context"
+}
+
+// Description returns the tool description.
+func (t *PinContextTool) Description() string {
+	return "Pin context so it is never summarized or dropped from the conversation: the user's request, or key content such as a spec, " +
+		"acceptance criteria or the relevant part of a file you have read. Without content, pins the user's most recent message. " +
+		"Pin sparingly: pinned content permanently uses context space."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *PinContextTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"content": map[string]any{
+				"type":        "string",
+				"description": "The exact text to pin, copied verbatim. Omit to pin the user's most recent message",
+			},
+			"label": map[string]any{
+				"type":        "string",
+				"description": "Short description of the pinned content, e.g. 'acceptance criteria' or the file it came from",
+			},
+		},
+		nil,
+	)
+}
+
+// Execute pins the content. Pinned content is returned as the tool result,
+// which the agent keeps pinned in its memory.
+func (t *PinContextTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Content string   `xml:"content"`
+		Label   string   `xml:"label"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	label := strings.TrimSpace(input.Label)
+
+	if strings.TrimSpace(input.Content) == "" {
+		content, err := t.pinRequest()
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("Pinned the user's most recent message (%d characters).", len(content)),
+			map[string]any{"target": "user_message"}, nil
+	}
+
+	if label == "" {
+		label = "pinned context"
+	}
+	// The result message carries the content and is pinned by the agent
+	metadata := map[string]any{
+		types.MetadataPinned: true,
+		"target":             "content",
+		"label":              label,
+	}
+	return fmt.Sprintf("Pinned %s:\n\n%s", label, input.Content), metadata, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *PinContextTool) IsLoopBreaking() bool {
+	return false
+}
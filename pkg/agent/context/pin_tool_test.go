@@ -0,0 +1,43 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinContextTool_PinsContent(t *testing.T) {
+	tool := NewPinContextTool(func() (string, error) {
+		t.Fatal("the user's message should not be pinned when content is given")
+		return "", nil
+	})
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(
+		"<arguments><label>acceptance criteria</label><content>- Returns 404 for unknown ids</content></arguments>"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result, "Pinned acceptance criteria:"))
+	assert.Contains(t, result, "- Returns 404 for unknown ids")
+	assert.Equal(t, true, metadata[types.MetadataPinned], "the result message must be pinned")
+}
+
+func TestPinContextTool_PinsUserMessage(t *testing.T) {
+	pinned := false
+	tool := NewPinContextTool(func() (string, error) {
+		pinned = true
+		return "Build the export endpoint", nil
+	})
+
+	result, metadata, err := tool.Execute(context.Background(), []byte("<arguments></arguments>"))
+	assert.NoError(t, err)
+	assert.True(t, pinned)
+	assert.Contains(t, result, "most recent message")
+	assert.Nil(t, metadata[types.MetadataPinned], "the result itself is not pinned")
+
+	tool = NewPinContextTool(func() (string, error) { return "", errors.New("nothing to pin") })
+	_, _, err = tool.Execute(context.Background(), []byte("<arguments></arguments>"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,165 @@
+package context
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// SummarizationStrategy is the interface custom strategies implement to plug
+// into the Manager. Register a factory for it with RegisterStrategy and list
+// its name under context.strategies in the project config.
+type SummarizationStrategy = Strategy
+
+// Names of the built-in strategies, in the order they run by default.
+const (
+	StrategyToolCalls = "tool_calls"
+	StrategyThreshold = "threshold"
+	StrategyGoalBatch = "goal_batch"
+)
+
+// DefaultStrategyNames are the strategies used when the project config does
+// not list any.
+var DefaultStrategyNames = []string{StrategyToolCalls, StrategyThreshold, StrategyGoalBatch}
+
+// StrategyOptions are the options configured for one strategy.
+type StrategyOptions map[string]any
+
+// Decode decodes the options into target, a pointer to a struct with yaml
+// tags. Options the struct does not declare are an error, so typos are
+// reported instead of silently ignored.
+func (o StrategyOptions) Decode(target any) error {
+	if len(o) == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(map[string]any(o))
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	return decoder.Decode(target)
+}
+
+// StrategyFactory builds a strategy from its configured options.
+type StrategyFactory func(options StrategyOptions) (Strategy, error)
+
+var (
+	strategyFactories   = map[string]StrategyFactory{}
+	strategyFactoriesMu sync.RWMutex
+)
+
+func init() {
+	RegisterStrategy(StrategyToolCalls, newToolCallStrategy)
+	RegisterStrategy(StrategyThreshold, newThresholdStrategy)
+	RegisterStrategy(StrategyGoalBatch, newGoalBatchStrategy)
+}
+
+// RegisterStrategy makes a strategy available by name to the project config.
+// It is meant to be called from an init function, and panics if name is empty
+// or already registered.
+func RegisterStrategy(name string, factory StrategyFactory) {
+	strategyFactoriesMu.Lock()
+	defer strategyFactoriesMu.Unlock()
+	if name == "" || factory == nil {
+		panic("context: RegisterStrategy needs a name and a factory")
+	}
+	if _, exists := strategyFactories[name]; exists {
+		panic(fmt.Sprintf("context: strategy %q is already registered", name))
+	}
+	strategyFactories[name] = factory
+}
+
+// RegisteredStrategies returns the names of the registered strategies, sorted.
+func RegisteredStrategies() []string {
+	strategyFactoriesMu.RLock()
+	defer strategyFactoriesMu.RUnlock()
+	names := make([]string, 0, len(strategyFactories))
+	for name := range strategyFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewStrategy builds the strategy registered under name.
+func NewStrategy(name string, options StrategyOptions) (Strategy, error) {
+	strategyFactoriesMu.RLock()
+	factory, ok := strategyFactories[name]
+	strategyFactoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown context strategy %q (registered: %v)", name, RegisteredStrategies())
+	}
+	strategy, err := factory(options)
+	if err != nil {
+		return nil, fmt.Errorf("context strategy %q: %w", name, err)
+	}
+	return strategy, nil
+}
+
+// NewStrategies builds the configured strategies in order, or the default
+// strategies when none are configured.
+func NewStrategies(configured []config.ContextStrategy) ([]Strategy, error) {
+	if len(configured) == 0 {
+		configured = make([]config.ContextStrategy, len(DefaultStrategyNames))
+		for i, name := range DefaultStrategyNames {
+			configured[i] = config.ContextStrategy{Name: name}
+		}
+	}
+	strategies := make([]Strategy, len(configured))
+	for i, c := range configured {
+		strategy, err := NewStrategy(c.Name, c.Options)
+		if err != nil {
+			return nil, err
+		}
+		strategies[i] = strategy
+	}
+	return strategies, nil
+}
+
+// newToolCallStrategy builds a ToolCallSummarizationStrategy. Options left
+// unset use the strategy's defaults.
+func newToolCallStrategy(options StrategyOptions) (Strategy, error) {
+	var o struct {
+		MessagesOld  int `yaml:"messages_old"`
+		MinToolCalls int `yaml:"min_tool_calls"`
+		MaxDistance  int `yaml:"max_distance"`
+	}
+	if err := options.Decode(&o); err != nil {
+		return nil, err
+	}
+	return NewToolCallSummarizationStrategy(o.MessagesOld, o.MinToolCalls, o.MaxDistance), nil
+}
+
+// newThresholdStrategy builds a ThresholdSummarizationStrategy that fires at
+// 80% of the token limit unless trigger_percent says otherwise.
+func newThresholdStrategy(options StrategyOptions) (Strategy, error) {
+	o := struct {
+		TriggerPercent float64 `yaml:"trigger_percent"`
+	}{TriggerPercent: 80}
+	if err := options.Decode(&o); err != nil {
+		return nil, err
+	}
+	if o.TriggerPercent <= 0 || o.TriggerPercent > 100 {
+		return nil, fmt.Errorf("trigger_percent must be between 0 and 100")
+	}
+	return NewThresholdSummarizationStrategy(o.TriggerPercent), nil
+}
+
+// newGoalBatchStrategy builds a GoalBatchCompactionStrategy. Options left
+// unset use the strategy's defaults.
+func newGoalBatchStrategy(options StrategyOptions) (Strategy, error) {
+	var o struct {
+		MessagesOld int `yaml:"messages_old"`
+		MinTurns    int `yaml:"min_turns"`
+		MaxTurns    int `yaml:"max_turns"`
+	}
+	if err := options.Decode(&o); err != nil {
+		return nil, err
+	}
+	return NewGoalBatchCompactionStrategy(o.MessagesOld, o.MinTurns, o.MaxTurns), nil
+}
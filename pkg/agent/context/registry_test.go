@@ -0,0 +1,81 @@
+package context
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+type keepAllStrategy struct{ limit int }
+
+func (s *keepAllStrategy) Name() string { return "KeepAll" }
+
+func (s *keepAllStrategy) ShouldRun(*memory.ConversationMemory, int, int) bool { return false }
+
+func (s *keepAllStrategy) Summarize(context.Context, *memory.ConversationMemory, llm.Provider) (int, error) {
+	return 0, nil
+}
+
+func TestNewStrategies_Defaults(t *testing.T) {
+	strategies, err := NewStrategies(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range strategies {
+		names = append(names, s.Name())
+	}
+	if got := strings.Join(names, ","); got != "ToolCallSummarization,ThresholdSummarization,GoalBatchCompaction" {
+		t.Errorf("unexpected default strategies %s", got)
+	}
+}
+
+func TestNewStrategies_Registered(t *testing.T) {
+	RegisterStrategy("test_keep_all", func(options StrategyOptions) (Strategy, error) {
+		var o struct {
+			Limit int `yaml:"limit"`
+		}
+		if err := options.Decode(&o); err != nil {
+			return nil, err
+		}
+		return &keepAllStrategy{limit: o.Limit}, nil
+	})
+
+	strategies, err := NewStrategies([]config.ContextStrategy{
+		{Name: "test_keep_all", Options: map[string]any{"limit": 3}},
+		{Name: StrategyThreshold, Options: map[string]any{"trigger_percent": 70}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keepAll, ok := strategies[0].(*keepAllStrategy); !ok || keepAll.limit != 3 {
+		t.Errorf("expected the registered strategy with its options, got %#v", strategies[0])
+	}
+	if threshold, ok := strategies[1].(*ThresholdSummarizationStrategy); !ok || threshold.thresholdPercent != 70 {
+		t.Errorf("expected a 70%% threshold strategy, got %#v", strategies[1])
+	}
+}
+
+func TestNewStrategies_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy config.ContextStrategy
+		wantErr  string
+	}{
+		{"unknown strategy", config.ContextStrategy{Name: "nope"}, `unknown context strategy "nope"`},
+		{"unknown option", config.ContextStrategy{Name: StrategyToolCalls, Options: map[string]any{"min_calls": 3}}, "min_calls"},
+		{"invalid option", config.ContextStrategy{Name: StrategyThreshold, Options: map[string]any{"trigger_percent": 120}}, "trigger_percent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewStrategies([]config.ContextStrategy{tt.strategy})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
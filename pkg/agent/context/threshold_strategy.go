@@ -14,14 +14,14 @@ import (
 // exceeds a configured percentage of the maximum context window.
 //
 // When triggered it collapses the older half of the conversation (excluding
-// system messages) into a single comprehensive summary, then keeps the more
-// recent half verbatim. This guarantees meaningful token reduction on every
+// system and pinned messages) into a single comprehensive summary, then keeps
+// the more recent half verbatim. This guarantees meaningful token reduction on every
 // run and avoids the "already-summarized loop" that plagued block-by-block
 // approaches.
 //
 // Layout after a run:
 //
-//	[system messages (unchanged)] [summary of older half] [recent half verbatim]
+//	[system messages (unchanged)] [summary of older half] [pinned messages from older half] [recent half verbatim]
 type ThresholdSummarizationStrategy struct {
 	// thresholdPercent is the percentage (0-100) of max tokens that triggers summarization.
 	thresholdPercent float64
@@ -67,19 +67,20 @@ func (s *ThresholdSummarizationStrategy) ShouldRun(conv *memory.ConversationMemo
 		return false
 	}
 
-	// Require enough non-system messages for a meaningful half-split.
+	// Require enough summarizable messages for a meaningful half-split.
 	messages := conv.GetAll()
-	nonSystemCount := 0
+	summarizableCount := 0
 	for _, msg := range messages {
-		if msg.Role != types.RoleSystem {
-			nonSystemCount++
+		if !isPreserved(msg) {
+			summarizableCount++
 		}
 	}
-	return nonSystemCount >= s.minMessages
+	return summarizableCount >= s.minMessages
 }
 
 // Summarize collapses the older half of the conversation into a single summary,
-// leaving the more recent half intact. System messages are always preserved.
+// leaving the more recent half intact. System messages are always preserved,
+// and pinned messages in the older half are kept verbatim after the summary.
 // Returns the number of messages replaced by the summary.
 func (s *ThresholdSummarizationStrategy) Summarize(ctx context.Context, conv *memory.ConversationMemory, provider llm.Provider) (int, error) {
 	messages := conv.GetAll()
@@ -87,23 +88,40 @@ func (s *ThresholdSummarizationStrategy) Summarize(ctx context.Context, conv *me
 	// Partition into system vs. conversation messages preserving original order.
 	var systemMessages []*types.Message
 	var conversationMessages []*types.Message
+	summarizable := 0
 	for _, msg := range messages {
 		if msg.Role == types.RoleSystem {
 			systemMessages = append(systemMessages, msg)
-		} else {
-			conversationMessages = append(conversationMessages, msg)
+			continue
+		}
+		conversationMessages = append(conversationMessages, msg)
+		if !msg.IsPinned() {
+			summarizable++
 		}
 	}
 
-	if len(conversationMessages) < s.minMessages {
+	if summarizable < s.minMessages {
 		return 0, nil
 	}
 
 	// Split: older half gets summarized, recent half stays verbatim.
 	// For odd counts we round down so the recent half is slightly larger —
-	// that is, we prefer to preserve more recent context.
-	splitAt := len(conversationMessages) / 2
-	olderHalf := conversationMessages[:splitAt]
+	// that is, we prefer to preserve more recent context. Pinned messages
+	// don't count towards the split.
+	splitAt := 0
+	for seen := 0; seen < summarizable/2; splitAt++ {
+		if !conversationMessages[splitAt].IsPinned() {
+			seen++
+		}
+	}
+	var olderHalf, pinned []*types.Message
+	for _, msg := range conversationMessages[:splitAt] {
+		if msg.IsPinned() {
+			pinned = append(pinned, msg)
+		} else {
+			olderHalf = append(olderHalf, msg)
+		}
+	}
 	recentHalf := conversationMessages[splitAt:]
 
 	// Single LLM call covers the entire older half.
@@ -112,10 +130,11 @@ func (s *ThresholdSummarizationStrategy) Summarize(ctx context.Context, conv *me
 		return 0, err
 	}
 
-	// Reassemble: system → summary → recent half.
-	newMessages := make([]*types.Message, 0, len(systemMessages)+1+len(recentHalf))
+	// Reassemble: system → summary → pinned → recent half.
+	newMessages := make([]*types.Message, 0, len(systemMessages)+1+len(pinned)+len(recentHalf))
 	newMessages = append(newMessages, systemMessages...)
 	newMessages = append(newMessages, summary)
+	newMessages = append(newMessages, pinned...)
 	newMessages = append(newMessages, recentHalf...)
 
 	conv.Clear()
@@ -190,6 +190,37 @@ func TestThresholdStrategy_Summarize_PreservesSystemMessages(t *testing.T) {
 	assert.True(t, result[1].Metadata["summarized"].(bool), "second message must be the summary")
 }
 
+// TestThresholdStrategy_Summarize_KeepsPinnedMessages verifies that pinned
+// messages in the older half are kept verbatim after the summary and do not
+// count towards the split.
+func TestThresholdStrategy_Summarize_KeepsPinnedMessages(t *testing.T) {
+	s := NewThresholdSummarizationStrategy(80)
+	conv := memory.NewConversationMemory()
+
+	spec := types.NewUserMessage("acceptance criteria").WithMetadata(types.MetadataPinned, true)
+	conv.Add(types.NewUserMessage("msg1"))
+	conv.Add(spec)
+	conv.Add(types.NewAssistantMessage("msg2"))
+	conv.Add(types.NewUserMessage("msg3"))
+	conv.Add(types.NewAssistantMessage("msg4"))
+
+	mockLLM := new(MockLLMProvider)
+	mockLLM.On("Complete", mock.Anything, mock.Anything).Return(
+		types.NewAssistantMessage("Summary"),
+		nil,
+	)
+
+	summarized, err := s.Summarize(context.Background(), conv, mockLLM)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, summarized, "msg1 and msg2 are summarized")
+
+	result := conv.GetAll()
+	assert.Len(t, result, 4)
+	assert.True(t, isSummarized(result[0]))
+	assert.Same(t, spec, result[1], "pinned message must follow the summary verbatim")
+	assert.Equal(t, "msg3", result[2].Content)
+}
+
 // TestThresholdStrategy_Summarize_RecentHalfKeptVerbatim verifies that existing
 // summaries inside the recent half are preserved as-is (not re-summarized).
 func TestThresholdStrategy_Summarize_RecentHalfKeptVerbatim(t *testing.T) {
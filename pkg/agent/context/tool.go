@@ -0,0 +1,105 @@
+package context
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// CompactFunc compacts the agent's context, typically DefaultAgent.Compact.
+type CompactFunc func(ctx context.Context, opts CompactOptions) (*CompactResult, error)
+
+// CompactContextTool lets the agent compact its own context before starting
+// work that will need room, such as reading several large files.
+type CompactContextTool struct {
+	compact CompactFunc
+}
+
+// NewCompactContextTool creates a new CompactContextTool.
+func NewCompactContextTool(compact CompactFunc) *CompactContextTool {
+	return &CompactContextTool{
+		compact: compact,
+	}
+}
+
+// Name returns the tool name.
+func (t *CompactContextTool) Name() string {
+	return "compact_context"
+}
+
+// Description returns the tool description.
+func (t *CompactContextTool) Description() string {
+	return "Summarize older parts of the conversation now to free context space, instead of waiting for automatic summarization. " +
+		"Use it before work that will add a lot of context. Summarized messages are replaced by shorter summaries and their full text is no longer available."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *CompactContextTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"level": map[string]any{
+				"type": "string",
+				"enum": []string{string(CompactLevelLight), string(CompactLevelNormal), string(CompactLevelAggressive)},
+				"description": "How much to summarize: light only summarizes old tool calls and completed turns, normal also the older half of the conversation, " +
+					"aggressive repeats until nothing more can be summarized (default: normal)",
+			},
+			"target_tokens": map[string]any{
+				"type":        "integer",
+				"description": "Keep compacting until the context is at or below this many tokens (optional)",
+			},
+		},
+		nil,
+	)
+}
+
+// Execute compacts the context.
+func (t *CompactContextTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName      xml.Name `xml:"arguments"`
+		Level        string   `xml:"level"`
+		TargetTokens int      `xml:"target_tokens"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	level, err := ParseCompactLevel(input.Level)
+	if err != nil {
+		return "", nil, err
+	}
+	if input.TargetTokens < 0 {
+		return "", nil, fmt.Errorf("target_tokens cannot be negative")
+	}
+
+	result, err := t.compact(ctx, CompactOptions{Level: level, TargetTokens: input.TargetTokens})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var message string
+	if result.Summarized == 0 {
+		message = fmt.Sprintf("Nothing to compact at level %s; the context is %d tokens.", level, result.TokensAfter)
+	} else {
+		message = fmt.Sprintf("Compacted the context from %d to %d tokens (%d summarized).", result.TokensBefore, result.TokensAfter, result.Summarized)
+	}
+	if input.TargetTokens > 0 && result.TokensAfter > input.TargetTokens {
+		message += fmt.Sprintf(" The target of %d tokens could not be reached.", input.TargetTokens)
+	}
+
+	metadata := map[string]any{
+		"level":         string(level),
+		"summarized":    result.Summarized,
+		"tokens_before": result.TokensBefore,
+		"tokens_after":  result.TokensAfter,
+	}
+
+	return message, metadata, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *CompactContextTool) IsLoopBreaking() bool {
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/agent/memory/vector"
 	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/types"
 )
@@ -28,6 +29,11 @@ type ToolCallSummarizationStrategy struct {
 
 	// eventChannel is used to emit progress events during parallel summarization
 	eventChannel chan<- *types.AgentEvent
+
+	// vectorMemory indexes each batch summary for recall in later sessions.
+	// May be nil — means vector memory is disabled.
+	vectorMemory    *vector.Memory
+	vectorSessionID string
 }
 
 // NewToolCallSummarizationStrategy creates a new tool call summarization strategy with buffering.
@@ -59,6 +65,14 @@ func (s *ToolCallSummarizationStrategy) SetEventChannel(eventChan chan<- *types.
 	s.eventChannel = eventChan
 }
 
+// SetVectorMemory attaches vector long-term memory to this strategy. Each
+// batch summary is indexed together with the user goal it served.
+// A nil memory disables indexing silently.
+func (s *ToolCallSummarizationStrategy) SetVectorMemory(mem *vector.Memory, sessionID string) {
+	s.vectorMemory = mem
+	s.vectorSessionID = sessionID
+}
+
 // Name returns the strategy's identifier.
 func (s *ToolCallSummarizationStrategy) Name() string {
 	return "ToolCallSummarization"
@@ -84,8 +98,8 @@ func (s *ToolCallSummarizationStrategy) ShouldRun(conv *memory.ConversationMemor
 	oldestToolCallPosition := -1
 
 	for i, msg := range oldMessages {
-		// Skip if already summarized
-		if isSummarized(msg) {
+		// Skip if already summarized or pinned
+		if isSummarized(msg) || msg.IsPinned() {
 			continue
 		}
 
@@ -154,11 +168,22 @@ func (s *ToolCallSummarizationStrategy) Summarize(ctx context.Context, conv *mem
 	}
 
 	// Single batched LLM call covers all groups.
-	summaries, err := s.summarizeBatch(ctx, groups, llm, findNearestUserGoal(oldMessages))
+	userGoal := findNearestUserGoal(oldMessages)
+	summaries, err := s.summarizeBatch(ctx, groups, llm, userGoal)
 	if err != nil {
 		return 0, err
 	}
 
+	if s.vectorMemory != nil {
+		for _, summary := range summaries {
+			content := strings.TrimPrefix(summary.Content, "[SUMMARIZED] ")
+			if userGoal != "" {
+				content = fmt.Sprintf("Goal: %s\n\n%s", userGoal, content)
+			}
+			s.vectorMemory.Remember(vector.KindToolBatch, content, s.vectorSessionID)
+		}
+	}
+
 	// Build a set of all message pointers belonging to the groups being replaced.
 	groupSet := make(map[*types.Message]bool, len(groups)*2)
 	for _, group := range groups {
@@ -319,7 +344,7 @@ func containsToolCallIndicators(content string) bool {
 
 // shouldSkipMessage returns true if the message should be skipped during grouping.
 func shouldSkipMessage(msg *types.Message) bool {
-	return isSummarized(msg) || msg.Role == types.RoleSystem
+	return isSummarized(msg) || isPreserved(msg)
 }
 
 // isToolRelatedMessage checks if a message is related to a tool call or result.
@@ -335,6 +360,11 @@ func groupToolCallsAndResults(messages []*types.Message) [][]*types.Message {
 	currentGroup := make([]*types.Message, 0)
 
 	for _, msg := range messages {
+		if msg.IsPinned() {
+			// Keep the call that produced a pinned result next to it
+			currentGroup = make([]*types.Message, 0)
+			continue
+		}
 		if shouldSkipMessage(msg) {
 			continue
 		}
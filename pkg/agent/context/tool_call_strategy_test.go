@@ -128,6 +128,22 @@ func TestGroupToolCallsAndResults_SkipsSystemMessages(t *testing.T) {
 	}
 }
 
+// TestGroupToolCallsAndResults_SkipsPinnedResults tests that a pinned tool
+// result and the call that produced it are never grouped.
+func TestGroupToolCallsAndResults_SkipsPinnedResults(t *testing.T) {
+	messages := []*types.Message{
+		toolCallMsg("pin_context"),
+		types.NewToolMessage("Pinned spec").WithMetadata(types.MetadataPinned, true),
+		toolCallMsg("read_file"),
+		types.NewToolMessage("File content"),
+	}
+
+	groups := groupToolCallsAndResults(messages)
+
+	assert.Len(t, groups, 1, "Only the unpinned call should be grouped")
+	assert.Contains(t, groups[0][0].Content, "read_file")
+}
+
 // TestNewToolCallSummarizationStrategy_Constructor tests constructor parameter defaults.
 func TestNewToolCallSummarizationStrategy_Constructor(t *testing.T) {
 	tests := []struct {
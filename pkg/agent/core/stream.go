@@ -24,15 +24,44 @@ type streamState struct {
 	toolNameDetected bool // tracks if we've detected and emitted the tool name
 	toolNameEmitted  bool // tracks if we've emitted buffered content after tool name
 	toolCallParser   *parser.ToolCallParser
+	nativeToolCalls  []types.ToolCall // tool calls made through native function calling
+	usage            *llm.UsageInfo   // token usage reported by the provider, if any
 }
 
 // ProcessStream processes a stream of chunks, emitting events and calling
 // the completion handler when done. This provides reusable stream processing
-// logic that any agent can use.
+// logic that any agent can use. usage is nil when the provider does not
+// report token usage.
 func ProcessStream(
 	stream <-chan *llm.StreamChunk,
 	emitEvent func(*types.AgentEvent),
-	onComplete func(assistantContent, thinkingContent, toolCallContent, role string),
+	onComplete func(assistantContent, thinkingContent, toolCallContent, role string, usage *llm.UsageInfo),
+) {
+	processStream(stream, emitEvent, func(state *streamState, role string) {
+		onComplete(state.assistantContent, state.thinkingContent, state.toolCallContent, role, state.usage)
+	})
+}
+
+// ProcessNativeToolCallStream processes a stream from a provider using native
+// function calling. Tool calls are reported to the completion handler as
+// structured calls rather than XML, and emitted as tool call events so the UI
+// shows them the same way as XML tool calls.
+func ProcessNativeToolCallStream(
+	stream <-chan *llm.StreamChunk,
+	emitEvent func(*types.AgentEvent),
+	onComplete func(assistantContent, thinkingContent, role string, toolCalls []types.ToolCall, usage *llm.UsageInfo),
+) {
+	processStream(stream, emitEvent, func(state *streamState, role string) {
+		onComplete(state.assistantContent, state.thinkingContent, role, state.nativeToolCalls, state.usage)
+	})
+}
+
+// processStream runs the shared stream loop, calling onComplete with the final
+// state unless the stream fails
+func processStream(
+	stream <-chan *llm.StreamChunk,
+	emitEvent func(*types.AgentEvent),
+	onComplete func(state *streamState, role string),
 ) {
 	state := &streamState{
 		toolCallParser: parser.NewToolCallParser(),
@@ -52,6 +81,11 @@ func ProcessStream(
 			handleContent(chunk, state, emitEvent)
 		}
 
+		state.nativeToolCalls = append(state.nativeToolCalls, chunk.ToolCalls...)
+		if chunk.Usage != nil {
+			state.usage = chunk.Usage
+		}
+
 		if chunk.IsLast() {
 			finalize(state, emitEvent, onComplete)
 			return
@@ -223,7 +257,7 @@ func handleToolCallContent(content string, state *streamState, emitEvent func(*t
 }
 
 // finalize ends the stream processing
-func finalize(state *streamState, emitEvent func(*types.AgentEvent), onComplete func(string, string, string, string)) {
+func finalize(state *streamState, emitEvent func(*types.AgentEvent), onComplete func(*streamState, string)) {
 	// Flush any remaining content from tool call parser
 	toolCallContent, regularContent := state.toolCallParser.Flush()
 	if toolCallContent != nil && toolCallContent.Content != "" {
@@ -253,11 +287,19 @@ func finalize(state *streamState, emitEvent func(*types.AgentEvent), onComplete
 		emitEvent(types.NewMessageEndEvent())
 	}
 
+	for _, call := range state.nativeToolCalls {
+		event := types.NewToolCallStartEvent()
+		event.Metadata["tool_name"] = call.Name
+		emitEvent(event)
+		emitEvent(types.NewToolCallContentEvent(call.Arguments))
+		emitEvent(types.NewToolCallEndEvent())
+	}
+
 	role := state.role
 	if role == "" {
 		role = string(types.RoleAssistant)
 	}
-	onComplete(state.assistantContent, state.thinkingContent, state.toolCallContent, role)
+	onComplete(state, role)
 }
 
 // extractToolNameFromPartial attempts to extract the tool name from partial XML content.
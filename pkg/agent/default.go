@@ -11,16 +11,23 @@ import (
 
 	"github.com/entrhq/forge/pkg/agent/approval"
 	agentcontext "github.com/entrhq/forge/pkg/agent/context"
+	"github.com/entrhq/forge/pkg/agent/hooks"
 	"github.com/entrhq/forge/pkg/agent/longtermmemory/capture"
 	"github.com/entrhq/forge/pkg/agent/longtermmemory/retrieval"
 	"github.com/entrhq/forge/pkg/agent/memory"
 	"github.com/entrhq/forge/pkg/agent/memory/notes"
+	"github.com/entrhq/forge/pkg/agent/memory/vector"
+	"github.com/entrhq/forge/pkg/agent/projectmemory"
 	"github.com/entrhq/forge/pkg/agent/prompts"
+	"github.com/entrhq/forge/pkg/agent/repocontext"
 	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/config"
 	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/llm/tokenizer"
 	"github.com/entrhq/forge/pkg/logging"
+	"github.com/entrhq/forge/pkg/security/workspace"
 	"github.com/entrhq/forge/pkg/tools/browser"
+	"github.com/entrhq/forge/pkg/tools/terminal"
 	"github.com/entrhq/forge/pkg/types"
 )
 
@@ -75,6 +82,10 @@ type DefaultAgent struct {
 	// Token usage tracking
 	tokenizer *tokenizer.Tokenizer
 
+	// Per-message size guard (0 disables)
+	messageTokenLimit int
+	oversizedPolicy   OversizedMessagePolicy
+
 	// Context management
 	contextManager *agentcontext.Manager
 
@@ -84,13 +95,17 @@ type DefaultAgent struct {
 	// Browser session management
 	browserManager *browser.SessionManager
 
+	// Interactive terminal sessions, closed when the agent stops
+	terminalManager *terminal.SessionManager
+
 	// Embedding provider for long-term memory retrieval (may be nil — means retrieval disabled)
 	embedder llm.Embedder
 
 	// Long-term memory retrieval engine (may be nil — means retrieval disabled)
 	retrievalEngine *retrieval.Engine
 
-	// currentTurnID identifies the active user turn for per-turn retrieval caching.
+	// currentTurnID identifies the active user turn for per-turn retrieval caching
+	// and event correlation. It is empty between turns.
 	// Protected by cancelMu since it is set before and read during the same turn.
 	currentTurnID string
 
@@ -98,6 +113,76 @@ type DefaultAgent struct {
 	capturePipeline *capture.Pipeline
 	captureObserver *capture.Observer
 	sessionID       string
+
+	// Embedding-backed memory of tool summaries, notes and task results (may be nil — means disabled)
+	vectorMemory *vector.Memory
+
+	// Live state appended to the system prompt on every LLM call (may be nil)
+	runtimeContext   func() string
+	runtimeContextMu sync.RWMutex
+
+	// Durable project facts from .forge/memory.md (may be nil — means disabled).
+	// The prompt section is read once so it stays stable for the session
+	projectMemory        *projectmemory.Memory
+	projectMemoryContext string
+	learningExtractor    *projectmemory.Extractor
+	learnings            sync.WaitGroup // Extractions still running
+
+	// Grows repositoryContext with nested AGENTS.md files as tools touch their
+	// directories (may be nil — means the context is fixed)
+	repositoryContextLoader *repocontext.Loader
+	repositoryContextMu     sync.RWMutex
+
+	// Working mode selected with -profile or /profile (may be nil)
+	profile   *config.Profile
+	profileMu sync.RWMutex
+
+	// Per-tool timeouts and result sizes enforced when tools are executed
+	toolLimits config.ToolLimits
+
+	// Per-turn budgets enforced by the agent loop (0 disables), and the tool
+	// calls made so far in the current turn
+	maxToolCallsPerTurn int
+	maxTurnDuration     time.Duration
+	turnToolCalls       int
+
+	// Groups of tools whose schemas are only sent once loaded (empty means
+	// every schema is always sent). The groups are fixed at creation.
+	toolGroups       []ToolGroup
+	loadedToolGroups map[string]bool
+	toolGroupsMu     sync.RWMutex
+
+	// Tool calling protocol: llm.ToolCallModeXML (default) or llm.ToolCallModeNative
+	toolCallMode string
+
+	// Workspace whose git state is reported in the environment facts (may be empty)
+	workspaceDir string
+
+	// Guard whose workspace the approval rules' paths are relative to (may be nil)
+	workspaceGuard *workspace.Guard
+
+	// Date, platform and git facts for the system prompt, refreshed each turn
+	environment   *prompts.EnvironmentFacts
+	environmentMu sync.RWMutex
+
+	// Hooks run around tool calls and turns, and the shell hooks configured
+	// for the workspace (may be nil)
+	hookRegistry *hooks.Registry
+	hookConfig   *hooks.Config
+
+	// Context from post-turn hooks, added to the next user message.
+	// Protected by cancelMu.
+	pendingHookContext string
+
+	// Records the session for replay (may be nil)
+	recorder Recorder
+
+	// Told about every event the agent emits, e.g. to stream it live
+	eventObservers []EventObserver
+
+	// The most recent message the user sent, pinned by /pin or pin_context
+	// without content. Only accessed from the input loop.
+	lastUserMessage *types.Message
 }
 
 // AgentOption is a function that configures an agent
@@ -141,6 +226,14 @@ func WithBrowserManager(manager *browser.SessionManager) AgentOption {
 	}
 }
 
+// WithTerminalManager sets the terminal session manager whose sessions the
+// agent closes when it stops
+func WithTerminalManager(manager *terminal.SessionManager) AgentOption {
+	return func(a *DefaultAgent) {
+		a.terminalManager = manager
+	}
+}
+
 // WithBufferSize sets the channel buffer size
 func WithBufferSize(size int) AgentOption {
 	return func(a *DefaultAgent) {
@@ -162,7 +255,9 @@ func WithApprovalTimeout(timeout time.Duration) AgentOption {
 	}
 }
 
-// WithContextManager sets a context manager for the agent to handle context summarization
+// WithContextManager sets a context manager for the agent to handle context
+// summarization. The compact_context and pin_context tools are registered
+// unless disabled.
 func WithContextManager(manager *agentcontext.Manager) AgentOption {
 	return func(a *DefaultAgent) {
 		a.contextManager = manager
@@ -198,8 +293,64 @@ func WithCapturePipeline(pipeline *capture.Pipeline) AgentOption {
 	}
 }
 
-// WithDisabledTools returns an option to disable specific built-in tools
-// This is useful for headless mode where interactive tools should be disabled
+// WithVectorMemory attaches vector long-term memory to the agent. Task
+// completions and scratchpad notes are indexed into it as the session runs,
+// and the recall_memory tool is registered unless disabled.
+// A nil memory is valid — it disables vector memory silently.
+func WithVectorMemory(mem *vector.Memory) AgentOption {
+	return func(a *DefaultAgent) {
+		a.vectorMemory = mem
+	}
+}
+
+// WithRuntimeContext sets a function whose output is appended to the system
+// prompt every time it is built, i.e. before each LLM call. Use it to report
+// live state the model should plan around, such as a remaining budget.
+func WithRuntimeContext(fn func() string) AgentOption {
+	return func(a *DefaultAgent) {
+		a.runtimeContext = fn
+	}
+}
+
+// WithWorkspaceDir sets the workspace directory whose git branch and dirty
+// state are reported in the system prompt's environment facts.
+func WithWorkspaceDir(dir string) AgentOption {
+	return func(a *DefaultAgent) {
+		a.workspaceDir = dir
+	}
+}
+
+// WithWorkspaceGuard sets the workspace guard the agent's tools use, so the
+// approval rules see the paths of tool calls relative to its workspace.
+func WithWorkspaceGuard(guard *workspace.Guard) AgentOption {
+	return func(a *DefaultAgent) {
+		a.workspaceGuard = guard
+	}
+}
+
+// WithHookConfig registers the shell hooks from a workspace's
+// .forge/hooks.yaml. Their commands run in the directory set by
+// WithWorkspaceDir.
+func WithHookConfig(cfg *hooks.Config) AgentOption {
+	return func(a *DefaultAgent) {
+		a.hookConfig = cfg
+	}
+}
+
+// WithToolCallMode sets how the model calls tools: llm.ToolCallModeXML embeds
+// tool calls in the response text, llm.ToolCallModeNative uses the provider's
+// function-calling API. Native mode falls back to XML when the provider does
+// not support it.
+func WithToolCallMode(mode string) AgentOption {
+	return func(a *DefaultAgent) {
+		a.toolCallMode = mode
+	}
+}
+
+// WithDisabledTools returns an option to disable specific tools by name.
+// Disabled built-ins are never registered, and RegisterTool silently ignores
+// disabled tools. This is useful for headless mode where interactive tools
+// should be disabled, and for project configs that opt out of tools.
 func WithDisabledTools(toolNames ...string) AgentOption {
 	return func(a *DefaultAgent) {
 		if a.disabledTools == nil {
@@ -213,19 +364,24 @@ func WithDisabledTools(toolNames ...string) AgentOption {
 
 // NewDefaultAgent creates a new DefaultAgent with the given provider and options.
 func NewDefaultAgent(provider llm.Provider, opts ...AgentOption) *DefaultAgent {
-	// Create tokenizer for client-side token counting
-	tok, err := tokenizer.New()
+	// Create tokenizer for client-side token counting, matched to the model
+	var model string
+	if provider != nil {
+		model = provider.GetModel()
+	}
+	tok, err := tokenizer.NewForModel(model)
 	if err != nil {
 		// Fall back to nil tokenizer if initialization fails
 		tok = nil
 	}
 
 	a := &DefaultAgent{
-		provider:   provider,
-		bufferSize: 10, // default buffer size
-		tools:      make(map[string]tools.Tool),
-		memory:     memory.NewConversationMemory(),
-		tokenizer:  tok,
+		provider:     provider,
+		bufferSize:   10, // default buffer size
+		tools:        make(map[string]tools.Tool),
+		memory:       memory.NewConversationMemory(),
+		tokenizer:    tok,
+		hookRegistry: hooks.NewRegistry(),
 	}
 
 	// Generate a per-session ID used to correlate long-term memory captures.
@@ -242,17 +398,37 @@ func NewDefaultAgent(provider llm.Provider, opts ...AgentOption) *DefaultAgent {
 		opt(a)
 	}
 
+	a.hookConfig.Register(a.hookRegistry, a.workspaceDir)
+
 	// Initialize notes manager if not provided via option
 	if a.notesManager == nil {
 		a.notesManager = notes.NewManager()
 	}
 
+	if a.vectorMemory != nil && !a.disabledTools["recall_memory"] {
+		a.tools["recall_memory"] = vector.NewRecallMemoryTool(a.vectorMemory)
+	}
+	if a.projectMemory != nil && !a.disabledTools["remember"] {
+		a.tools["remember"] = projectmemory.NewRememberTool(a.projectMemory)
+	}
+
+	if a.contextManager != nil && !a.disabledTools["compact_context"] {
+		a.tools["compact_context"] = agentcontext.NewCompactContextTool(a.Compact)
+	}
+	if a.contextManager != nil && !a.disabledTools["pin_context"] {
+		a.tools["pin_context"] = agentcontext.NewPinContextTool(a.PinLastUserMessage)
+	}
+	if len(a.toolGroups) > 0 && !a.disabledTools["load_tools"] {
+		a.tools["load_tools"] = tools.NewLoadToolsTool(a.LoadToolGroups)
+	}
+
 	// Create channels with configured buffer size
 	a.channels = types.NewAgentChannels(a.bufferSize)
 
 	// Initialize approval manager with default timeout
 	a.approvalTimeout = 5 * time.Minute
 	a.approvalManager = approval.NewManager(a.approvalTimeout, a.emitEvent)
+	a.approvalManager.SetGuard(a.workspaceGuard)
 
 	// If context manager was provided, set its event channel now that channels exist
 	if a.contextManager != nil {
@@ -299,10 +475,12 @@ func (a *DefaultAgent) Shutdown(ctx context.Context) error {
 	// Wait for completion or context cancellation
 	select {
 	case <-a.channels.Done:
-		return nil
 	case <-ctx.Done():
 		return ctx.Err()
 	}
+
+	// Let learnings from the last task reach the memory file
+	return a.waitForLearnings(ctx)
 }
 
 // GetChannels returns the communication channels for this agent.
@@ -313,6 +491,7 @@ func (a *DefaultAgent) GetChannels() *types.AgentChannels {
 // eventLoop is the main processing loop for the agent.
 func (a *DefaultAgent) eventLoop(ctx context.Context) {
 	defer a.channels.Close()
+	defer a.closeTerminals()
 	defer func() {
 		a.runMu.Lock()
 		a.running = false
@@ -392,6 +571,9 @@ func (a *DefaultAgent) processInput(ctx context.Context, input *types.Input) {
 
 	// Handle user input
 	if input.IsUserInput() {
+		if a.recorder != nil {
+			a.recorder.RecordInput(input.Content)
+		}
 		a.processUserInput(ctx, input.Content)
 		return
 	}
@@ -408,16 +590,31 @@ func (a *DefaultAgent) processInput(ctx context.Context, input *types.Input) {
 		a.handleNotesRequest(input)
 		return
 	}
+
+	// Handle on-demand context compaction
+	if input.IsCompactRequest() {
+		a.handleCompactRequest(ctx, input)
+		return
+	}
+
+	// Handle context pinning
+	if input.IsPinRequest() {
+		a.handlePinRequest(input)
+		return
+	}
+
+	// Handle retrying the last turn
+	if input.IsRetryRequest() {
+		a.handleRetryRequest(ctx, input)
+		return
+	}
 }
 
 // processUserInput processes a user text input using the agent loop.
 func (a *DefaultAgent) processUserInput(ctx context.Context, content string) {
-	// Add user message to memory
-	userMsg := types.NewUserMessage(content)
-	a.memory.Add(userMsg)
-
 	// Generate a unique ID for this turn so the retrieval engine can cache
 	// results across multiple sub-turns without repeating LLM/embed calls.
+	// Every event emitted during the turn carries it for correlation.
 	turnIDBytes := make([]byte, 8)
 	var turnID string
 	if _, err := rand.Read(turnIDBytes); err == nil {
@@ -429,6 +626,34 @@ func (a *DefaultAgent) processUserInput(ctx context.Context, content string) {
 	a.currentTurnID = turnID
 	a.cancelMu.Unlock()
 
+	defer func() {
+		a.cancelMu.Lock()
+		a.currentTurnID = ""
+		a.cancelMu.Unlock()
+	}()
+
+	// Reject or chunk inputs that would blow the context on their own
+	content, ok := a.guardUserInput(content)
+	if !ok {
+		a.emitEvent(types.NewTurnEndEvent())
+		return
+	}
+
+	// Let hooks rewrite or veto the message before the model sees it
+	content, ok = a.runPreTurnHooks(ctx, content)
+	if !ok {
+		a.emitEvent(types.NewTurnEndEvent())
+		return
+	}
+
+	// Refresh the date and git state the model sees for this turn
+	a.refreshEnvironment()
+
+	// Add user message to memory
+	userMsg := types.NewUserMessage(content)
+	a.memory.Add(userMsg)
+	a.lastUserMessage = userMsg
+
 	// Create cancellable context for this turn
 	turnCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -450,6 +675,9 @@ func (a *DefaultAgent) processUserInput(ctx context.Context, content string) {
 	// Run agent loop (now in assistant.go)
 	a.runAgentLoop(turnCtx)
 
+	// Post-turn hooks run even when the turn was stopped
+	a.runPostTurnHooks(ctx)
+
 	// Notify the long-term memory capture pipeline that a turn completed.
 	// This is non-blocking: the observer enqueues an event on a buffered
 	// channel; the pipeline goroutine processes it asynchronously.
@@ -457,6 +685,11 @@ func (a *DefaultAgent) processUserInput(ctx context.Context, content string) {
 		a.captureObserver.OnTurnComplete(a.memory.GetAll(), a.sessionID)
 	}
 
+	// Index notes written or edited during the turn; also non-blocking
+	if a.vectorMemory != nil {
+		a.vectorMemory.RememberNotes(a.notesManager.List(notes.ListOptions{Limit: a.notesManager.Count()}), a.sessionID)
+	}
+
 	// Emit turn end
 	a.emitEvent(types.NewTurnEndEvent())
 }
@@ -484,6 +717,12 @@ func (a *DefaultAgent) RegisterTool(tool tools.Tool) error {
 		return fmt.Errorf("cannot override built-in tool: %s", name)
 	}
 
+	// Disabled tools are dropped rather than rejected so callers can register
+	// their full tool set unconditionally
+	if a.disabledTools[name] {
+		return nil
+	}
+
 	a.toolsMu.Lock()
 	defer a.toolsMu.Unlock()
 
@@ -563,13 +802,14 @@ func classifyMessages(messages []*types.Message) (raw, summaries, goalBatches []
 
 // computeMessageTokens calculates token counts for message slices using the
 // tokenizer when available, or a character-based approximation otherwise.
+// The current total is calibrated against provider-reported usage.
 func (a *DefaultAgent) computeMessageTokens(
 	all, raw, summaries, goalBatches []*types.Message,
 	fullPrompt string,
 ) (convTokens, currentTokens, rawTokens, summaryTokens, goalBatchTokens int) {
 	if a.tokenizer != nil {
 		convTokens = a.tokenizer.CountMessagesTokens(all)
-		currentTokens = convTokens + a.tokenizer.CountTokens(fullPrompt)
+		currentTokens = a.calibrateTokens(convTokens + a.tokenizer.CountTokens(fullPrompt))
 		rawTokens = a.tokenizer.CountMessagesTokens(raw)
 		summaryTokens = a.tokenizer.CountMessagesTokens(summaries)
 		goalBatchTokens = a.tokenizer.CountMessagesTokens(goalBatches)
@@ -600,30 +840,41 @@ func (a *DefaultAgent) GetContextInfo() *ContextInfo {
 		WithCustomInstructions(a.customInstructions).
 		Build()
 
+	repositoryContext := a.getRepositoryContext()
 	repositorySection := ""
-	if a.repositoryContext != "" {
-		repositorySection = "<repository_context>\n" + a.repositoryContext + "\n</repository_context>\n\n"
+	if repositoryContext != "" {
+		repositorySection = "<repository_context>\n" + repositoryContext + "\n</repository_context>\n\n"
 	}
 
+	toolsList := a.getToolsList()
+	exposedTools, deferredGroups := a.splitDeferredTools(toolsList)
+	toolManifest := formatToolManifest(deferredGroups)
 	toolsSection := ""
-	if len(a.tools) > 0 {
+	if len(exposedTools) > 0 {
 		toolsSection = "<available_tools>\n" +
-			prompts.FormatToolSchemas(a.getToolsList()) +
+			prompts.FormatToolSchemas(exposedTools) +
 			"</available_tools>\n\n"
 	}
 
-	systemPromptTokens, repositoryTokens, toolTokens := 0, 0, 0
+	systemPromptTokens, repositoryTokens, toolTokens, manifestTokens := 0, 0, 0, 0
 	if a.tokenizer != nil {
 		systemPromptTokens = a.tokenizer.CountTokens(baseSystemPrompt)
 		repositoryTokens = a.tokenizer.CountTokens(repositorySection)
-		toolTokens = a.tokenizer.CountTokens(toolsSection)
+		manifestTokens = a.tokenizer.CountTokens(toolManifest)
+		toolTokens = a.tokenizer.CountTokens(toolsSection) + manifestTokens
 	}
+	toolGroups, deferredTokens := a.toolGroupInfo(toolsList)
 
 	builder := prompts.NewPromptBuilder().
-		WithTools(a.getToolsList()).
-		WithCustomInstructions(a.customInstructions)
-	if a.repositoryContext != "" {
-		builder = builder.WithRepositoryContext(a.repositoryContext)
+		WithTools(exposedTools).
+		WithToolManifest(toolManifest).
+		WithCustomInstructions(a.customInstructions).
+		WithEnvironment(a.getEnvironment())
+	if repositoryContext != "" {
+		builder = builder.WithRepositoryContext(repositoryContext)
+	}
+	if a.projectMemoryContext != "" {
+		builder = builder.WithProjectMemory(a.projectMemoryContext)
 	}
 	fullSystemPrompt := builder.Build()
 
@@ -660,6 +911,10 @@ func (a *DefaultAgent) GetContextInfo() *ContextInfo {
 		ToolCount:               len(a.tools),
 		ToolTokens:              toolTokens,
 		ToolNames:               toolNames,
+		LoadedToolCount:         len(exposedTools),
+		ToolManifestTokens:      manifestTokens,
+		DeferredToolTokens:      deferredTokens,
+		ToolGroups:              toolGroups,
 		MessageCount:            len(messages),
 		ConversationTurns:       conversationTurns,
 		ConversationTokens:      conversationTokens,
@@ -679,6 +934,11 @@ func (a *DefaultAgent) GetContextInfo() *ContextInfo {
 	}
 }
 
+// WorkspaceGuard returns the guard set with WithWorkspaceGuard, or nil.
+func (a *DefaultAgent) WorkspaceGuard() *workspace.Guard {
+	return a.workspaceGuard
+}
+
 // GetProvider returns the LLM provider used by this agent
 func (a *DefaultAgent) GetProvider() llm.Provider {
 	return a.provider
@@ -692,6 +952,13 @@ func (a *DefaultAgent) SetProvider(provider llm.Provider) error {
 		return fmt.Errorf("provider cannot be nil")
 	}
 
+	// Count tokens the way the new model does
+	if a.tokenizer != nil && (a.provider == nil || a.provider.GetModel() != provider.GetModel()) {
+		if err := a.tokenizer.SetModel(provider.GetModel()); err != nil {
+			agentDebugLog.Printf("Keeping %s tokenizer for %s: %v", a.tokenizer.Encoding(), provider.GetModel(), err)
+		}
+	}
+
 	// Update the agent's provider
 	a.provider = provider
 
@@ -712,6 +979,31 @@ func (a *DefaultAgent) SetSummarizationModel(model string) {
 	}
 }
 
+// GetSummarizationModel returns the model override used for context
+// summarization calls, or "" when summarization uses the main provider model.
+func (a *DefaultAgent) GetSummarizationModel() string {
+	if a.contextManager != nil {
+		return a.contextManager.GetSummarizationModel()
+	}
+	return ""
+}
+
+// GetBrowserManager returns the browser session manager, or nil when browser
+// automation is not configured.
+func (a *DefaultAgent) GetBrowserManager() *browser.SessionManager {
+	return a.browserManager
+}
+
+// closeTerminals kills the programs left running in terminal sessions.
+func (a *DefaultAgent) closeTerminals() {
+	if a.terminalManager == nil {
+		return
+	}
+	if err := a.terminalManager.CloseAll(); err != nil {
+		agentDebugLog.Warnf("Failed to close terminal sessions: %v", err)
+	}
+}
+
 // GetSessionID returns the per-session identifier used to correlate long-term
 // memory captures with a single agent lifecycle. The value is stable for the
 // duration of the agent's lifetime and is safe for concurrent reads.
@@ -2,8 +2,10 @@ package agent
 
 import (
 	"context"
+	"strings"
 	"testing"
 
+	agentcontext "github.com/entrhq/forge/pkg/agent/context"
 	"github.com/entrhq/forge/pkg/agent/memory"
 	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/llm/tokenizer"
@@ -216,3 +218,128 @@ func TestEmptyMemoryTokenCounting(t *testing.T) {
 		t.Errorf("Expected non-zero system prompt tokens, got 0")
 	}
 }
+
+// TestRecordResponseReconcilesUsage verifies that provider-reported usage is
+// emitted instead of the estimate and calibrates later estimates
+func TestRecordResponseReconcilesUsage(t *testing.T) {
+	manager := &agentcontext.Manager{}
+	agent := NewDefaultAgent(&mockProvider{}, WithContextManager(manager))
+
+	pctx := &promptContext{promptTokens: 1000, estimatedTokens: 1000}
+	agent.recordResponse(pctx, &llmResponse{
+		assistantContent: "done",
+		completionTokens: 5,
+		usage:            &llm.UsageInfo{PromptTokens: 1100, CompletionTokens: 6, TotalTokens: 1106},
+	})
+
+	event := <-agent.channels.Event
+	if event.Type != types.EventTypeTokenUsage || event.TokenUsage.PromptTokens != 1100 || event.TokenUsage.TotalTokens != 1106 {
+		t.Errorf("Expected reported usage in the token usage event, got %+v", event.TokenUsage)
+	}
+	if got := agent.calibrateTokens(2000); got != 2200 {
+		t.Errorf("Expected estimates to be calibrated to 2200, got %d", got)
+	}
+
+	// Without a report the estimate is emitted
+	agent.recordResponse(pctx, &llmResponse{assistantContent: "done", completionTokens: 5})
+	event = <-agent.channels.Event
+	if event.TokenUsage.PromptTokens != 1000 || event.TokenUsage.CompletionTokens != 5 {
+		t.Errorf("Expected estimated usage in the token usage event, got %+v", event.TokenUsage)
+	}
+}
+
+// TestRecordResponseAnswersExtraNativeToolCalls verifies that every native
+// tool call is recorded and the ones after the first get an error result
+func TestRecordResponseAnswersExtraNativeToolCalls(t *testing.T) {
+	agent := NewDefaultAgent(&mockProvider{})
+
+	calls := []types.ToolCall{
+		{ID: "call_1", Name: "read_file", Arguments: `{"path":"a.go"}`},
+		{ID: "call_2", Name: "read_file", Arguments: `{"path":"b.go"}`},
+		{ID: "call_3", Name: "list_files", Arguments: `{}`},
+	}
+	agent.recordResponse(&promptContext{}, &llmResponse{nativeToolCalls: calls})
+
+	messages := agent.memory.GetAll()
+	if len(messages) != 3 {
+		t.Fatalf("Expected the assistant message and two error results, got %d messages", len(messages))
+	}
+	if got := messages[0].ToolCalls; len(got) != 3 {
+		t.Errorf("Expected all three tool calls to be recorded, got %+v", got)
+	}
+	for i, msg := range messages[1:] {
+		call := calls[i+1]
+		if msg.Role != types.RoleTool || msg.ToolCallID != call.ID {
+			t.Errorf("Expected a tool result answering %s, got %+v", call.ID, msg)
+		}
+		if !strings.Contains(msg.Content, "only one tool call per turn is supported") || !strings.Contains(msg.Content, call.Name) {
+			t.Errorf("Expected an error naming %s, got %q", call.Name, msg.Content)
+		}
+	}
+}
+
+// TestHandlePinRequest verifies that /pin and /unpin requests pin the user's
+// latest message or new content, and remove pins again
+func TestHandlePinRequest(t *testing.T) {
+	agent := NewDefaultAgent(&mockProvider{}, WithBufferSize(10))
+
+	// Nothing to pin yet
+	agent.handlePinRequest(types.NewPinRequestInput(types.PinRequestParams{}))
+	if event := <-agent.channels.Event; event.Type != types.EventTypeError {
+		t.Errorf("Expected an error event without a user message, got %s", event.Type)
+	}
+
+	request := types.NewUserMessage("Add CSV export. It must stream rows.")
+	agent.memory.Add(request)
+	agent.lastUserMessage = request
+	agent.handlePinRequest(types.NewPinRequestInput(types.PinRequestParams{}))
+	if !request.IsPinned() {
+		t.Error("Expected the latest user message to be pinned")
+	}
+
+	agent.handlePinRequest(types.NewPinRequestInput(types.PinRequestParams{Label: "docs/spec.md", Content: "# Spec"}))
+	messages := agent.memory.GetAll()
+	if last := messages[len(messages)-1]; !last.IsPinned() || !strings.Contains(last.Content, "docs/spec.md") {
+		t.Errorf("Expected the content to be added as a pinned message, got %q", last.Content)
+	}
+
+	agent.handlePinRequest(types.NewPinRequestInput(types.PinRequestParams{Unpin: true}))
+	for _, msg := range agent.memory.GetAll() {
+		if msg.IsPinned() {
+			t.Errorf("Expected no pinned messages after unpinning, got %q", msg.Content)
+		}
+	}
+}
+
+// TestRewindLastTurn verifies that retrying removes the last turn from the
+// context, and is refused once that turn has been summarized away
+func TestRewindLastTurn(t *testing.T) {
+	agent := NewDefaultAgent(&mockProvider{}, WithBufferSize(10))
+
+	agent.handleRetryRequest(context.Background(), types.NewRetryRequestInput(types.RetryRequestParams{Content: "again"}))
+	if event := <-agent.channels.Event; event.Type != types.EventTypeError {
+		t.Errorf("Expected an error event without a message to retry, got %s", event.Type)
+	}
+	if event := <-agent.channels.Event; event.Type != types.EventTypeTurnEnd {
+		t.Errorf("Expected the refused retry to end the turn, got %s", event.Type)
+	}
+
+	agent.memory.Add(types.NewUserMessage("Earlier task"))
+	request := types.NewUserMessage("Add CSV export")
+	agent.memory.Add(request)
+	agent.lastUserMessage = request
+	agent.memory.Add(types.NewAssistantMessage("Sorry, I failed"))
+
+	if err := agent.RewindLastTurn(); err != nil {
+		t.Fatalf("RewindLastTurn() error = %v", err)
+	}
+	if messages := agent.memory.GetAll(); len(messages) != 1 || messages[0].Content != "Earlier task" {
+		t.Errorf("Expected only the earlier task to remain, got %v", messages)
+	}
+
+	summarized := types.NewUserMessage("Summarized away")
+	agent.lastUserMessage = summarized
+	if err := agent.RewindLastTurn(); err == nil || !strings.Contains(err.Error(), "summarized") {
+		t.Errorf("Expected an error for a summarized message, got %v", err)
+	}
+}
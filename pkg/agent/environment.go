@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/prompts"
+)
+
+// gitStatusTimeout bounds the git call made when refreshing environment facts
+const gitStatusTimeout = 2 * time.Second
+
+// refreshEnvironment gathers fresh environment facts for the system prompt.
+// It runs at the start of each turn, so the facts stay fixed while the turn's
+// LLM calls reuse the same prompt.
+func (a *DefaultAgent) refreshEnvironment() {
+	facts := prompts.EnvironmentFacts{
+		Now:  time.Now(),
+		OS:   runtime.GOOS,
+		Arch: runtime.GOARCH,
+		CPUs: runtime.NumCPU(),
+	}
+
+	if a.workspaceDir != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), gitStatusTimeout)
+		defer cancel()
+		if status, err := git.GetWorkingTreeStatus(ctx, a.workspaceDir); err == nil {
+			facts.GitBranch = status.Branch
+			facts.GitDirty = status.Dirty
+		}
+	}
+
+	a.environmentMu.Lock()
+	defer a.environmentMu.Unlock()
+	a.environment = &facts
+}
+
+// getEnvironment returns the facts gathered for the current turn, gathering
+// them first if no turn has started yet
+func (a *DefaultAgent) getEnvironment() prompts.EnvironmentFacts {
+	a.environmentMu.RLock()
+	facts := a.environment
+	a.environmentMu.RUnlock()
+
+	if facts == nil {
+		a.refreshEnvironment()
+		a.environmentMu.RLock()
+		facts = a.environment
+		a.environmentMu.RUnlock()
+	}
+	return *facts
+}
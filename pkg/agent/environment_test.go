@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSystemPromptEnvironment(t *testing.T) {
+	a := NewDefaultAgent(&mockProvider{})
+
+	prompt := a.GetSystemPrompt()
+	if !strings.Contains(prompt, "<environment>") {
+		t.Fatal("expected an environment section in the system prompt")
+	}
+	if !strings.Contains(prompt, runtime.GOOS+"/"+runtime.GOARCH) {
+		t.Errorf("expected the platform in the system prompt")
+	}
+	if strings.Contains(prompt, "Git branch") {
+		t.Error("expected no git facts without a workspace directory")
+	}
+
+	// Facts stay fixed until the next turn refreshes them
+	first := a.getEnvironment()
+	if second := a.getEnvironment(); !second.Now.Equal(first.Now) {
+		t.Error("expected environment facts to be reused within a turn")
+	}
+}
+
+func TestRefreshEnvironmentGitState(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "trunk"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	a := NewDefaultAgent(&mockProvider{}, WithWorkspaceDir(dir))
+	a.refreshEnvironment()
+
+	facts := a.getEnvironment()
+	if facts.GitBranch != "trunk" || facts.GitDirty {
+		t.Errorf("expected clean branch trunk, got %q (dirty %v)", facts.GitBranch, facts.GitDirty)
+	}
+}
@@ -0,0 +1,434 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config/secrets"
+)
+
+// Supported git hosting providers
+const (
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+	ProviderGitea     = "gitea"
+	ProviderBitbucket = "bitbucket"
+)
+
+// bitbucketAPIURL is the REST API root of Bitbucket Cloud
+const bitbucketAPIURL = "https://api.bitbucket.org/2.0"
+
+// PullRequest describes a pull request (a merge request on GitLab) to open
+type PullRequest struct {
+	Title string
+	Body  string
+	Base  string // Branch the changes merge into
+	Head  string // Branch holding the changes, already pushed to the remote
+	Draft bool
+}
+
+// GitHost opens pull requests on a git hosting platform
+type GitHost interface {
+	// Name returns the provider name, e.g. "github"
+	Name() string
+
+	// CreatePullRequest opens pr and returns its web URL
+	CreatePullRequest(ctx context.Context, pr PullRequest) (string, error)
+}
+
+// HostConfig selects and configures a GitHost
+type HostConfig struct {
+	Provider  string // github (default), gitlab, gitea, or bitbucket
+	RemoteURL string // URL of the remote the head branch was pushed to
+	APIURL    string // API base URL; derived from RemoteURL when empty
+	Token     string // API token; GitHub falls back to the gh CLI when empty, Bitbucket also accepts "username:app_password"
+	WorkDir   string // Working directory for the gh CLI
+}
+
+// DefaultTokenEnv returns the environment variable conventionally holding
+// the API token for provider.
+func DefaultTokenEnv(provider string) string {
+	switch provider {
+	case ProviderGitLab:
+		return "GITLAB_TOKEN"
+	case ProviderGitea:
+		return "GITEA_TOKEN"
+	case ProviderBitbucket:
+		return "BITBUCKET_TOKEN"
+	default:
+		return "GITHUB_TOKEN"
+	}
+}
+
+// TokenFromEnv reads the API token for provider from envVar, or from the
+// provider's default variable when envVar is empty. Without a Bitbucket token,
+// BITBUCKET_USERNAME and BITBUCKET_APP_PASSWORD are combined into one.
+func TokenFromEnv(provider, envVar string) string {
+	if envVar != "" {
+		return os.Getenv(envVar)
+	}
+	token := os.Getenv(DefaultTokenEnv(provider))
+	if token == "" && provider == ProviderBitbucket {
+		username, password := os.Getenv("BITBUCKET_USERNAME"), os.Getenv("BITBUCKET_APP_PASSWORD")
+		if username != "" && password != "" {
+			token = username + ":" + password
+		}
+	}
+	return token
+}
+
+// ResolveToken returns the API token for provider from the environment, as
+// TokenFromEnv does, falling back to the token stored in the system keyring
+// with "forge auth login".
+func ResolveToken(provider, envVar string) string {
+	if token := TokenFromEnv(provider, envVar); token != "" {
+		return token
+	}
+	if provider == "" {
+		provider = ProviderGitHub
+	}
+	token, err := secrets.Get(secrets.GitTokenAccount(provider))
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+// NewGitHost creates the GitHost for cfg.Provider.
+func NewGitHost(cfg HostConfig) (GitHost, error) {
+	remote, err := ParseRemoteURL(cfg.RemoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Provider {
+	case "", ProviderGitHub:
+		apiURL := cfg.APIURL
+		if apiURL == "" {
+			apiURL = remote.githubAPIURL()
+		}
+		return &GitHubHost{remote: remote, apiURL: apiURL, token: cfg.Token, workDir: cfg.WorkDir, client: newHTTPClient()}, nil
+	case ProviderGitLab:
+		if cfg.Token == "" {
+			return nil, fmt.Errorf("gitlab requires an API token")
+		}
+		apiURL := cfg.APIURL
+		if apiURL == "" {
+			apiURL = remote.webURL() + "/api/v4"
+		}
+		return &GitLabHost{remote: remote, apiURL: apiURL, token: cfg.Token, client: newHTTPClient()}, nil
+	case ProviderGitea:
+		if cfg.Token == "" {
+			return nil, fmt.Errorf("gitea requires an API token")
+		}
+		apiURL := cfg.APIURL
+		if apiURL == "" {
+			apiURL = remote.webURL() + "/api/v1"
+		}
+		return &GiteaHost{remote: remote, apiURL: apiURL, token: cfg.Token, client: newHTTPClient()}, nil
+	case ProviderBitbucket:
+		if cfg.Token == "" {
+			return nil, fmt.Errorf("bitbucket requires an access token or app password")
+		}
+		apiURL := cfg.APIURL
+		if apiURL == "" {
+			apiURL = bitbucketAPIURL
+		}
+		return &BitbucketHost{remote: remote, apiURL: apiURL, token: cfg.Token, client: newHTTPClient()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported git provider: %s (must be 'github', 'gitlab', 'gitea', or 'bitbucket')", cfg.Provider)
+	}
+}
+
+// Remote identifies a repository on a git host
+type Remote struct {
+	Host string // Host name, including a port if the remote has one
+	Path string // Repository path, e.g. "owner/repo" or "group/subgroup/repo"
+}
+
+// ParseRemoteURL parses an HTTPS, SSH, or scp-style (git@host:owner/repo.git)
+// remote URL.
+func ParseRemoteURL(rawURL string) (Remote, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	var remote Remote
+
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return Remote{}, fmt.Errorf("invalid remote URL %q: %w", rawURL, err)
+		}
+		remote.Host = u.Host
+		// SSH ports are not the web port
+		if u.Scheme == "ssh" {
+			remote.Host = u.Hostname()
+		}
+		remote.Path = u.Path
+	} else if at := strings.Index(rawURL, "@"); at >= 0 {
+		hostPath := strings.SplitN(rawURL[at+1:], ":", 2)
+		if len(hostPath) == 2 {
+			remote.Host = hostPath[0]
+			remote.Path = hostPath[1]
+		}
+	}
+
+	remote.Path = strings.TrimSuffix(strings.Trim(remote.Path, "/"), ".git")
+	if remote.Host == "" || !strings.Contains(remote.Path, "/") {
+		return Remote{}, fmt.Errorf("cannot determine repository from remote URL %q", rawURL)
+	}
+	return remote, nil
+}
+
+// webURL returns the HTTPS root of the remote's host.
+func (r Remote) webURL() string {
+	return "https://" + r.Host
+}
+
+// githubAPIURL returns the REST API root for github.com or a GitHub
+// Enterprise Server host.
+func (r Remote) githubAPIURL() string {
+	if r.Host == "github.com" {
+		return "https://api.github.com"
+	}
+	return r.webURL() + "/api/v3"
+}
+
+// GitHubHost opens pull requests on GitHub or GitHub Enterprise Server
+type GitHubHost struct {
+	remote  Remote
+	apiURL  string
+	token   string
+	workDir string
+	client  *http.Client
+}
+
+// Name returns the provider name
+func (h *GitHubHost) Name() string {
+	return ProviderGitHub
+}
+
+// CreatePullRequest opens a pull request through the REST API, or through the
+// gh CLI and its own authentication when no token is configured.
+func (h *GitHubHost) CreatePullRequest(ctx context.Context, pr PullRequest) (string, error) {
+	if h.token == "" {
+		return h.createWithCLI(ctx, pr)
+	}
+
+	req := map[string]any{
+		"title": pr.Title,
+		"body":  pr.Body,
+		"base":  pr.Base,
+		"head":  pr.Head,
+		"draft": pr.Draft,
+	}
+	var resp struct {
+		HTMLURL string `json:"html_url"`
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/pulls", strings.TrimSuffix(h.apiURL, "/"), h.remote.Path)
+	headers := map[string]string{
+		"Authorization": "Bearer " + h.token,
+		"Accept":        "application/vnd.github+json",
+	}
+	if err := postJSON(ctx, h.client, endpoint, headers, req, &resp); err != nil {
+		return "", fmt.Errorf("failed to create pull request: %w", err)
+	}
+	return resp.HTMLURL, nil
+}
+
+func (h *GitHubHost) createWithCLI(ctx context.Context, pr PullRequest) (string, error) {
+	args := []string{"pr", "create",
+		"--title", pr.Title,
+		"--body", pr.Body,
+		"--base", pr.Base,
+		"--head", pr.Head,
+	}
+	if pr.Draft {
+		args = append(args, "--draft")
+	}
+
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	cmd.Dir = h.workDir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to create PR: %w, stderr: %s", err, stderr.String())
+	}
+
+	// gh prints the PR URL
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// GitLabHost opens merge requests on GitLab.com or a self-managed instance
+type GitLabHost struct {
+	remote Remote
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+// Name returns the provider name
+func (h *GitLabHost) Name() string {
+	return ProviderGitLab
+}
+
+// CreatePullRequest opens a merge request
+func (h *GitLabHost) CreatePullRequest(ctx context.Context, pr PullRequest) (string, error) {
+	title := pr.Title
+	if pr.Draft {
+		// GitLab marks merge requests as drafts by title prefix
+		title = "Draft: " + title
+	}
+
+	req := map[string]any{
+		"title":         title,
+		"description":   pr.Body,
+		"source_branch": pr.Head,
+		"target_branch": pr.Base,
+	}
+	var resp struct {
+		WebURL string `json:"web_url"`
+	}
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests", strings.TrimSuffix(h.apiURL, "/"), url.PathEscape(h.remote.Path))
+	headers := map[string]string{"PRIVATE-TOKEN": h.token}
+	if err := postJSON(ctx, h.client, endpoint, headers, req, &resp); err != nil {
+		return "", fmt.Errorf("failed to create merge request: %w", err)
+	}
+	return resp.WebURL, nil
+}
+
+// GiteaHost opens pull requests on Gitea or Forgejo
+type GiteaHost struct {
+	remote Remote
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+// Name returns the provider name
+func (h *GiteaHost) Name() string {
+	return ProviderGitea
+}
+
+// CreatePullRequest opens a pull request
+func (h *GiteaHost) CreatePullRequest(ctx context.Context, pr PullRequest) (string, error) {
+	title := pr.Title
+	if pr.Draft {
+		// Gitea marks pull requests as work in progress by title prefix
+		title = "WIP: " + title
+	}
+
+	req := map[string]any{
+		"title": title,
+		"body":  pr.Body,
+		"base":  pr.Base,
+		"head":  pr.Head,
+	}
+	var resp struct {
+		HTMLURL string `json:"html_url"`
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/pulls", strings.TrimSuffix(h.apiURL, "/"), h.remote.Path)
+	headers := map[string]string{"Authorization": "token " + h.token}
+	if err := postJSON(ctx, h.client, endpoint, headers, req, &resp); err != nil {
+		return "", fmt.Errorf("failed to create pull request: %w", err)
+	}
+	return resp.HTMLURL, nil
+}
+
+// BitbucketHost opens pull requests on Bitbucket Cloud
+type BitbucketHost struct {
+	remote Remote
+	apiURL string
+	token  string // Access token, or "username:app_password"
+	client *http.Client
+}
+
+// Name returns the provider name
+func (h *BitbucketHost) Name() string {
+	return ProviderBitbucket
+}
+
+// CreatePullRequest opens a pull request through the pullrequests API
+func (h *BitbucketHost) CreatePullRequest(ctx context.Context, pr PullRequest) (string, error) {
+	req := map[string]any{
+		"title":       pr.Title,
+		"description": pr.Body,
+		"source":      map[string]any{"branch": map[string]string{"name": pr.Head}},
+		"destination": map[string]any{"branch": map[string]string{"name": pr.Base}},
+		"draft":       pr.Draft,
+	}
+	var resp struct {
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	}
+	endpoint := fmt.Sprintf("%s/repositories/%s/pullrequests", strings.TrimSuffix(h.apiURL, "/"), h.remote.Path)
+	headers := map[string]string{"Authorization": h.authorization()}
+	if err := postJSON(ctx, h.client, endpoint, headers, req, &resp); err != nil {
+		return "", fmt.Errorf("failed to create pull request: %w", err)
+	}
+	return resp.Links.HTML.Href, nil
+}
+
+// authorization returns the Authorization header for the token: basic auth
+// for an app password given as "username:app_password", otherwise a bearer
+// token for OAuth and repository or workspace access tokens.
+func (h *BitbucketHost) authorization() string {
+	if strings.Contains(h.token, ":") {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(h.token))
+	}
+	return "Bearer " + h.token
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// postJSON sends body as JSON to endpoint and decodes the response into out.
+func postJSON(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/config/secrets"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantHost string
+		wantPath string
+		wantErr  bool
+	}{
+		{url: "https://github.com/entrhq/forge.git", wantHost: "github.com", wantPath: "entrhq/forge"},
+		{url: "git@github.com:entrhq/forge.git", wantHost: "github.com", wantPath: "entrhq/forge"},
+		{url: "ssh://git@gitlab.example.com:2222/group/sub/repo.git", wantHost: "gitlab.example.com", wantPath: "group/sub/repo"},
+		{url: "https://gitea.example.com:3000/owner/repo", wantHost: "gitea.example.com:3000", wantPath: "owner/repo"},
+		{url: "/srv/git/repo.git", wantErr: true},
+		{url: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			remote, err := ParseRemoteURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRemoteURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if remote.Host != tt.wantHost || remote.Path != tt.wantPath {
+				t.Errorf("ParseRemoteURL() = %+v, want host %q path %q", remote, tt.wantHost, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestNewGitHost_Provider(t *testing.T) {
+	remote := "git@example.com:owner/repo.git"
+
+	if _, err := NewGitHost(HostConfig{Provider: "sourcehut", RemoteURL: remote}); err == nil {
+		t.Error("expected error for unsupported provider")
+	}
+	if _, err := NewGitHost(HostConfig{Provider: ProviderGitLab, RemoteURL: remote}); err == nil {
+		t.Error("expected error for gitlab without a token")
+	}
+	if _, err := NewGitHost(HostConfig{Provider: ProviderBitbucket, RemoteURL: remote}); err == nil {
+		t.Error("expected error for bitbucket without a token")
+	}
+
+	host, err := NewGitHost(HostConfig{RemoteURL: remote})
+	if err != nil {
+		t.Fatalf("NewGitHost() error = %v", err)
+	}
+	if host.Name() != ProviderGitHub {
+		t.Errorf("default provider = %q, want %q", host.Name(), ProviderGitHub)
+	}
+	if gh := host.(*GitHubHost); gh.apiURL != "https://example.com/api/v3" {
+		t.Errorf("GitHub Enterprise API URL = %q", gh.apiURL)
+	}
+}
+
+// recordedRequest is a request captured by newTestAPI
+type recordedRequest struct {
+	path   string
+	header http.Header
+	body   map[string]any
+}
+
+// newTestAPI starts a server that records one request and replies with response.
+func newTestAPI(t *testing.T, response string) (*httptest.Server, *recordedRequest) {
+	t.Helper()
+	rec := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.path = r.URL.EscapedPath()
+		rec.header = r.Header
+		if err := json.NewDecoder(r.Body).Decode(&rec.body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, rec
+}
+
+func TestGitHosts_CreatePullRequest(t *testing.T) {
+	pr := PullRequest{Title: "Add feature", Body: "Details", Base: "main", Head: "forge/feature", Draft: true}
+
+	tests := []struct {
+		provider   string
+		remote     string
+		response   string
+		wantPath   string
+		wantHeader [2]string
+		wantTitle  string
+		wantURL    string
+	}{
+		{
+			provider:   ProviderGitHub,
+			remote:     "https://github.com/owner/repo.git",
+			response:   `{"html_url": "https://github.com/owner/repo/pull/1"}`,
+			wantPath:   "/repos/owner/repo/pulls",
+			wantHeader: [2]string{"Authorization", "Bearer secret"},
+			wantTitle:  "Add feature",
+			wantURL:    "https://github.com/owner/repo/pull/1",
+		},
+		{
+			provider:   ProviderGitLab,
+			remote:     "git@gitlab.example.com:group/sub/repo.git",
+			response:   `{"web_url": "https://gitlab.example.com/group/sub/repo/-/merge_requests/1"}`,
+			wantPath:   "/projects/group%2Fsub%2Frepo/merge_requests",
+			wantHeader: [2]string{"PRIVATE-TOKEN", "secret"},
+			wantTitle:  "Draft: Add feature",
+			wantURL:    "https://gitlab.example.com/group/sub/repo/-/merge_requests/1",
+		},
+		{
+			provider:   ProviderGitea,
+			remote:     "https://gitea.example.com/owner/repo.git",
+			response:   `{"html_url": "https://gitea.example.com/owner/repo/pulls/1"}`,
+			wantPath:   "/repos/owner/repo/pulls",
+			wantHeader: [2]string{"Authorization", "token secret"},
+			wantTitle:  "WIP: Add feature",
+			wantURL:    "https://gitea.example.com/owner/repo/pulls/1",
+		},
+		{
+			provider:   ProviderBitbucket,
+			remote:     "https://user@bitbucket.org/workspace/repo.git",
+			response:   `{"links": {"html": {"href": "https://bitbucket.org/workspace/repo/pull-requests/1"}}}`,
+			wantPath:   "/repositories/workspace/repo/pullrequests",
+			wantHeader: [2]string{"Authorization", "Bearer secret"},
+			wantTitle:  "Add feature",
+			wantURL:    "https://bitbucket.org/workspace/repo/pull-requests/1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			server, rec := newTestAPI(t, tt.response)
+			host, err := NewGitHost(HostConfig{
+				Provider:  tt.provider,
+				RemoteURL: tt.remote,
+				APIURL:    server.URL,
+				Token:     "secret",
+			})
+			if err != nil {
+				t.Fatalf("NewGitHost() error = %v", err)
+			}
+
+			url, err := host.CreatePullRequest(context.Background(), pr)
+			if err != nil {
+				t.Fatalf("CreatePullRequest() error = %v", err)
+			}
+			if url != tt.wantURL {
+				t.Errorf("URL = %q, want %q", url, tt.wantURL)
+			}
+			if rec.path != tt.wantPath {
+				t.Errorf("path = %q, want %q", rec.path, tt.wantPath)
+			}
+			if got := rec.header.Get(tt.wantHeader[0]); got != tt.wantHeader[1] {
+				t.Errorf("%s header = %q, want %q", tt.wantHeader[0], got, tt.wantHeader[1])
+			}
+			if rec.body["title"] != tt.wantTitle {
+				t.Errorf("title = %v, want %q", rec.body["title"], tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestGitHost_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "branch not found"}`, http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	host, err := NewGitHost(HostConfig{
+		Provider:  ProviderGitea,
+		RemoteURL: "https://gitea.example.com/owner/repo.git",
+		APIURL:    server.URL,
+		Token:     "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewGitHost() error = %v", err)
+	}
+
+	if _, err := host.CreatePullRequest(context.Background(), PullRequest{Title: "x", Base: "main", Head: "feature"}); err == nil {
+		t.Error("expected error for non-2xx response")
+	}
+}
+
+func TestBitbucketHost_AppPassword(t *testing.T) {
+	server, rec := newTestAPI(t, `{"links": {"html": {"href": "https://bitbucket.org/workspace/repo/pull-requests/2"}}}`)
+	host, err := NewGitHost(HostConfig{
+		Provider:  ProviderBitbucket,
+		RemoteURL: "git@bitbucket.org:workspace/repo.git",
+		APIURL:    server.URL,
+		Token:     "user:app-password",
+	})
+	if err != nil {
+		t.Fatalf("NewGitHost() error = %v", err)
+	}
+
+	if _, err := host.CreatePullRequest(context.Background(), PullRequest{Title: "x", Base: "main", Head: "feature"}); err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if got := rec.header.Get("Authorization"); got != "Basic dXNlcjphcHAtcGFzc3dvcmQ=" {
+		t.Errorf("Authorization header = %q, want basic auth", got)
+	}
+	source, _ := rec.body["source"].(map[string]any)
+	destination, _ := rec.body["destination"].(map[string]any)
+	if fmt.Sprint(source["branch"]) != "map[name:feature]" || fmt.Sprint(destination["branch"]) != "map[name:main]" {
+		t.Errorf("branches = %v -> %v", source, destination)
+	}
+}
+
+func TestTokenFromEnv_BitbucketAppPassword(t *testing.T) {
+	t.Setenv("BITBUCKET_TOKEN", "")
+	t.Setenv("BITBUCKET_USERNAME", "user")
+	t.Setenv("BITBUCKET_APP_PASSWORD", "app-password")
+	if got := TokenFromEnv(ProviderBitbucket, ""); got != "user:app-password" {
+		t.Errorf("TokenFromEnv() = %q, want the app password credentials", got)
+	}
+
+	t.Setenv("BITBUCKET_TOKEN", "access-token")
+	if got := TokenFromEnv(ProviderBitbucket, ""); got != "access-token" {
+		t.Errorf("TokenFromEnv() = %q, want the access token", got)
+	}
+}
+
+// memoryStore is a secrets.Store backed by a map
+type memoryStore map[string]string
+
+func (s memoryStore) Get(account string) (string, error) {
+	if secret, ok := s[account]; ok {
+		return secret, nil
+	}
+	return "", secrets.ErrNotFound
+}
+
+func (s memoryStore) Set(account, secret string) error {
+	s[account] = secret
+	return nil
+}
+
+func (s memoryStore) Delete(account string) error {
+	delete(s, account)
+	return nil
+}
+
+func TestResolveToken_KeyringFallback(t *testing.T) {
+	secrets.SetStore(memoryStore{"github_token": "keyring-token", "gitlab_token": "gitlab-keyring"})
+	t.Cleanup(func() { secrets.SetStore(nil) })
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GITLAB_TOKEN", "env-token")
+
+	if got := ResolveToken("", ""); got != "keyring-token" {
+		t.Errorf("ResolveToken(github) = %q, want the keyring token", got)
+	}
+	if got := ResolveToken(ProviderGitLab, ""); got != "env-token" {
+		t.Errorf("ResolveToken(gitlab) = %q, want the environment to win", got)
+	}
+	if got := ResolveToken(ProviderGitea, ""); got != "" {
+		t.Errorf("ResolveToken(gitea) = %q, want no token", got)
+	}
+}
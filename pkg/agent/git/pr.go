@@ -244,22 +244,11 @@ func CreatePR(workingDir, title, body, base, head string) (string, error) {
 	}
 
 	// Create PR using gh CLI
-	prCmd := exec.Command("gh", "pr", "create",
-		"--title", title,
-		"--body", body,
-		"--base", base,
-		"--head", head,
-	)
-	prCmd.Dir = workingDir
-
-	var prStdout, prStderr bytes.Buffer
-	prCmd.Stdout = &prStdout
-	prCmd.Stderr = &prStderr
-
-	if err := prCmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to create PR: %w, stderr: %s", err, prStderr.String())
-	}
-
-	// Return the PR URL from gh output
-	return strings.TrimSpace(prStdout.String()), nil
+	host := &GitHubHost{workDir: workingDir}
+	return host.createWithCLI(context.Background(), PullRequest{
+		Title: title,
+		Body:  body,
+		Base:  base,
+		Head:  head,
+	})
 }
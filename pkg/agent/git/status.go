@@ -0,0 +1,62 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// WorkingTreeStatus summarizes the state of a git working tree
+type WorkingTreeStatus struct {
+	Branch string // Current branch, or "HEAD (detached)"
+	Dirty  bool   // Uncommitted or untracked changes
+}
+
+// GetWorkingTreeStatus reports the current branch and whether the working
+// tree has changes, using a single git status call.
+func GetWorkingTreeStatus(ctx context.Context, workingDir string) (*WorkingTreeStatus, error) {
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain=v1", "--branch")
+	cmd.Dir = workingDir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git status failed: %w, stderr: %s", err, stderr.String())
+	}
+
+	return parseWorkingTreeStatus(stdout.String()), nil
+}
+
+// parseWorkingTreeStatus parses `git status --porcelain=v1 --branch` output,
+// whose first line is a "## branch...upstream" header.
+func parseWorkingTreeStatus(output string) *WorkingTreeStatus {
+	status := &WorkingTreeStatus{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		header, ok := strings.CutPrefix(line, "## ")
+		if !ok {
+			if line != "" {
+				status.Dirty = true
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(header, "No commits yet on "):
+			status.Branch = strings.TrimPrefix(header, "No commits yet on ")
+		case strings.HasPrefix(header, "HEAD (no branch)"):
+			status.Branch = "HEAD (detached)"
+		default:
+			branch, _, _ := strings.Cut(header, "...")
+			branch, _, _ = strings.Cut(branch, " ")
+			status.Branch = branch
+		}
+	}
+	return status
+}
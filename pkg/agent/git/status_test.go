@@ -0,0 +1,27 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestParseWorkingTreeStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   WorkingTreeStatus
+	}{
+		{"clean with upstream", "## main...origin/main\n", WorkingTreeStatus{Branch: "main"}},
+		{"ahead of upstream", "## feature/x...origin/feature/x [ahead 2]\n", WorkingTreeStatus{Branch: "feature/x"}},
+		{"modified", "## main\n M pkg/a.go\n?? new.txt\n", WorkingTreeStatus{Branch: "main", Dirty: true}},
+		{"detached", "## HEAD (no branch)\n", WorkingTreeStatus{Branch: "HEAD (detached)"}},
+		{"no commits", "## No commits yet on main\n?? README.md\n", WorkingTreeStatus{Branch: "main", Dirty: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseWorkingTreeStatus(tt.output); *got != tt.want {
+				t.Errorf("parseWorkingTreeStatus() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
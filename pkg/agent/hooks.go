@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent/hooks"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// RegisterHook adds fn to the hooks run at point. Hooks run in registration
+// order, after those configured in .forge/hooks.yaml.
+func (a *DefaultAgent) RegisterHook(point hooks.Point, fn hooks.Func) {
+	a.hookRegistry.Register(point, fn)
+}
+
+// runHooks runs the hooks at event.Point, reporting hook errors without
+// interrupting the agent
+func (a *DefaultAgent) runHooks(ctx context.Context, event *hooks.Event) {
+	if err := a.hookRegistry.Run(ctx, event); err != nil {
+		a.emitEvent(types.NewErrorEvent(err))
+	}
+}
+
+// withHookContext appends hook context to text, or returns text unchanged
+// when there is none
+func withHookContext(text, hookContext string) string {
+	if hookContext == "" {
+		return text
+	}
+	return text + "\n\n<hook_context>\n" + hookContext + "\n</hook_context>"
+}
+
+// runPreToolCallHooks lets hooks rewrite the call's arguments or veto it,
+// returning their context for the result. A vetoed call is reported to the
+// model as a failed tool call; it returns false in that case.
+func (a *DefaultAgent) runPreToolCallHooks(ctx context.Context, toolCall *tools.ToolCall) (string, bool) {
+	if !a.hookRegistry.Has(hooks.PreToolCall) {
+		return "", true
+	}
+
+	event := &hooks.Event{
+		Point:        hooks.PreToolCall,
+		ToolName:     toolCall.ToolName,
+		ToolCallID:   toolCall.ID,
+		ArgumentsXML: toolCall.Arguments.InnerXML,
+	}
+	a.runHooks(ctx, event)
+
+	if !bytes.Equal(event.ArgumentsXML, toolCall.Arguments.InnerXML) {
+		toolCall.Arguments.InnerXML = event.ArgumentsXML
+	}
+
+	reason, vetoed := event.Vetoed()
+	if !vetoed {
+		return event.Context(), true
+	}
+
+	argsMap, err := tools.XMLToMap(toolCall.GetArgumentsXML())
+	if err != nil {
+		argsMap = make(map[string]any)
+	}
+	a.emitEvent(types.NewToolCallEvent(toolCall.ID, toolCall.ToolName, argsMap))
+	a.emitEvent(types.NewToolResultErrorEvent(toolCall.ID, toolCall.ToolName, errors.New(reason)))
+
+	msg := types.NewToolMessage(fmt.Sprintf("Tool '%s' was blocked by a hook and not executed:\n%s", toolCall.ToolName, reason))
+	if a.nativeToolCalls() {
+		msg.ToolCallID = toolCall.ID
+	}
+	a.memory.Add(msg)
+	return "", false
+}
+
+// runPostToolCallHooks runs hooks on a tool's outcome and folds their context,
+// after preContext from the pre-tool-call hooks, into the result, or into the
+// error when the tool failed
+func (a *DefaultAgent) runPostToolCallHooks(ctx context.Context, toolCall tools.ToolCall, preContext, result string, toolErr error) (string, error) {
+	event := &hooks.Event{
+		Point:        hooks.PostToolCall,
+		ToolName:     toolCall.ToolName,
+		ToolCallID:   toolCall.ID,
+		ArgumentsXML: toolCall.Arguments.InnerXML,
+		Result:       result,
+		Err:          toolErr,
+	}
+	event.AppendContext(preContext)
+	if a.hookRegistry.Has(hooks.PostToolCall) {
+		a.runHooks(ctx, event)
+	}
+
+	hookContext := event.Context()
+	if hookContext == "" {
+		return result, toolErr
+	}
+	if toolErr != nil {
+		return result, fmt.Errorf("%w%s", toolErr, withHookContext("", hookContext))
+	}
+	return withHookContext(result, hookContext), nil
+}
+
+// runPreTurnHooks lets hooks rewrite or veto the user's input, and adds any
+// context left by the previous turn's post-turn hooks. It returns false when
+// the turn was vetoed.
+func (a *DefaultAgent) runPreTurnHooks(ctx context.Context, content string) (string, bool) {
+	a.cancelMu.Lock()
+	pending := a.pendingHookContext
+	a.pendingHookContext = ""
+	a.cancelMu.Unlock()
+
+	if !a.hookRegistry.Has(hooks.PreTurn) {
+		return withHookContext(content, pending), true
+	}
+
+	event := &hooks.Event{Point: hooks.PreTurn, Input: content}
+	event.AppendContext(pending)
+	a.runHooks(ctx, event)
+
+	if reason, vetoed := event.Vetoed(); vetoed {
+		// Keep post-turn context for the message that does go through
+		a.cancelMu.Lock()
+		a.pendingHookContext = pending
+		a.cancelMu.Unlock()
+
+		a.emitEvent(types.NewErrorEvent(fmt.Errorf("message blocked by hook: %s", reason)))
+		return "", false
+	}
+	return withHookContext(event.Input, event.Context()), true
+}
+
+// runPostTurnHooks runs hooks once a turn ends, keeping their context for
+// the next user message
+func (a *DefaultAgent) runPostTurnHooks(ctx context.Context) {
+	if !a.hookRegistry.Has(hooks.PostTurn) {
+		return
+	}
+
+	event := &hooks.Event{Point: hooks.PostTurn}
+	a.runHooks(ctx, event)
+
+	if hookContext := event.Context(); hookContext != "" {
+		a.cancelMu.Lock()
+		a.pendingHookContext = hookContext
+		a.cancelMu.Unlock()
+	}
+}
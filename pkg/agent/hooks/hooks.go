@@ -0,0 +1,193 @@
+// Package hooks lets code and repositories run logic around the agent's tool
+// calls and turns. A hook can rewrite a tool call's arguments or the user's
+// input, veto a tool call or turn, and append context for the model to read.
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// Point is a place in the agent loop where hooks run.
+type Point string
+
+const (
+	// PreToolCall runs after a tool call is parsed and before it is approved
+	// and executed. Hooks may rewrite the arguments or veto the call.
+	PreToolCall Point = "pre_tool_call"
+	// PostToolCall runs after a tool executes, whether or not it failed.
+	// Appended context is added to the result the model sees.
+	PostToolCall Point = "post_tool_call"
+	// PreTurn runs when a user message arrives, before it is added to the
+	// conversation. Hooks may rewrite the input or veto the turn.
+	PreTurn Point = "pre_turn"
+	// PostTurn runs after the agent loop finishes a turn. Appended context is
+	// added to the next user message.
+	PostTurn Point = "post_turn"
+)
+
+// canVeto reports whether hooks at p run early enough to stop what follows
+func (p Point) canVeto() bool {
+	return p == PreToolCall || p == PreTurn
+}
+
+// Points lists every hook point in the order they occur.
+var Points = []Point{PreTurn, PreToolCall, PostToolCall, PostTurn}
+
+// ParsePoint parses a hook point name such as "pre_tool_call".
+func ParsePoint(s string) (Point, error) {
+	for _, point := range Points {
+		if string(point) == s {
+			return point, nil
+		}
+	}
+	names := make([]string, len(Points))
+	for i, point := range Points {
+		names[i] = string(point)
+	}
+	return "", fmt.Errorf("unknown hook point %q (must be one of %s)", s, strings.Join(names, ", "))
+}
+
+// Event describes what a hook is running for. Fields that do not apply to
+// the point are empty. Hooks change the outcome by assigning to
+// ArgumentsXML or Input, or by calling Veto and AppendContext.
+type Event struct {
+	Point Point
+
+	// Tool call fields, set for PreToolCall and PostToolCall
+	ToolName   string
+	ToolCallID string
+	// ArgumentsXML is the inner XML of the call's <arguments> element.
+	// PreToolCall hooks may replace it, e.g. with SetArguments.
+	ArgumentsXML []byte
+	// Result and Err are the tool's outcome, set for PostToolCall
+	Result string
+	Err    error
+
+	// Input is the user's message, set for PreTurn. Hooks may replace it.
+	Input string
+
+	vetoReason string
+	context    []string
+}
+
+// Arguments parses ArgumentsXML into a map for inspection.
+func (e *Event) Arguments() (map[string]any, error) {
+	return tools.XMLToMap([]byte("<arguments>" + string(e.ArgumentsXML) + "</arguments>"))
+}
+
+// SetArguments replaces the tool call's arguments with args, encoded the same
+// way as native function-calling arguments.
+func (e *Event) SetArguments(args map[string]any) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	innerXML, err := tools.ArgumentsFromJSON(string(data))
+	if err != nil {
+		return err
+	}
+	e.ArgumentsXML = innerXML
+	return nil
+}
+
+// Veto stops the tool call or turn, telling the model reason. It has no
+// effect at PostToolCall and PostTurn, which run after the fact.
+func (e *Event) Veto(reason string) {
+	if e.vetoReason == "" {
+		e.vetoReason = strings.TrimSpace(reason)
+		if e.vetoReason == "" {
+			e.vetoReason = "vetoed by hook"
+		}
+	}
+}
+
+// Vetoed reports whether a hook vetoed the event, and why.
+func (e *Event) Vetoed() (string, bool) {
+	return e.vetoReason, e.vetoReason != ""
+}
+
+// AppendContext adds text for the model to read alongside the event's
+// outcome: the tool result, or the user's input.
+func (e *Event) AppendContext(text string) {
+	if text = strings.TrimSpace(text); text != "" {
+		e.context = append(e.context, text)
+	}
+}
+
+// Context returns the appended context, one block per call to AppendContext,
+// or "" when there is none.
+func (e *Event) Context() string {
+	return strings.Join(e.context, "\n\n")
+}
+
+// Func is a hook. Returning an error vetoes a PreToolCall or PreTurn event
+// with the error as the reason; at other points the error is reported and
+// the agent carries on.
+type Func func(ctx context.Context, event *Event) error
+
+// Registry holds the hooks registered at each point. It is safe for
+// concurrent use. A nil Registry has no hooks.
+type Registry struct {
+	mu    sync.RWMutex
+	hooks map[Point][]Func
+}
+
+// NewRegistry creates an empty hook registry.
+func NewRegistry() *Registry {
+	return &Registry{hooks: make(map[Point][]Func)}
+}
+
+// Register adds fn to the hooks run at point, after those already
+// registered.
+func (r *Registry) Register(point Point, fn Func) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks[point] = append(r.hooks[point], fn)
+}
+
+// Has reports whether any hooks are registered at point.
+func (r *Registry) Has(point Point) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.hooks[point]) > 0
+}
+
+// Run runs the hooks registered at event.Point in order, stopping once one
+// vetoes. Errors from hooks that do not veto are joined and returned; the
+// event still reflects every hook that ran.
+func (r *Registry) Run(ctx context.Context, event *Event) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	hooks := append([]Func(nil), r.hooks[event.Point]...)
+	r.mu.RUnlock()
+
+	var errs []string
+	for _, fn := range hooks {
+		if err := fn(ctx, event); err != nil {
+			if !event.Point.canVeto() {
+				errs = append(errs, err.Error())
+				continue
+			}
+			event.Veto(err.Error())
+		}
+		if _, vetoed := event.Vetoed(); vetoed && event.Point.canVeto() {
+			break
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s hook failed: %s", event.Point, strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegistryRunOrderAndVeto(t *testing.T) {
+	r := NewRegistry()
+	var ran []string
+	r.Register(PreToolCall, func(ctx context.Context, e *Event) error {
+		ran = append(ran, "first")
+		e.AppendContext("checked")
+		return nil
+	})
+	r.Register(PreToolCall, func(ctx context.Context, e *Event) error {
+		ran = append(ran, "second")
+		return errors.New("denied")
+	})
+	r.Register(PreToolCall, func(ctx context.Context, e *Event) error {
+		ran = append(ran, "third")
+		return nil
+	})
+
+	event := &Event{Point: PreToolCall, ToolName: "write_file"}
+	if err := r.Run(context.Background(), event); err != nil {
+		t.Fatalf("expected errors at pre points to veto, got %v", err)
+	}
+
+	if strings.Join(ran, ",") != "first,second" {
+		t.Errorf("expected hooks to stop at the veto, ran %v", ran)
+	}
+	if reason, vetoed := event.Vetoed(); !vetoed || reason != "denied" {
+		t.Errorf("expected veto %q, got %q (%v)", "denied", reason, vetoed)
+	}
+	if event.Context() != "checked" {
+		t.Errorf("expected context from the first hook, got %q", event.Context())
+	}
+}
+
+func TestRegistryPostErrorsDoNotVeto(t *testing.T) {
+	r := NewRegistry()
+	r.Register(PostToolCall, func(ctx context.Context, e *Event) error {
+		return errors.New("lint crashed")
+	})
+	r.Register(PostToolCall, func(ctx context.Context, e *Event) error {
+		e.AppendContext("still ran")
+		return nil
+	})
+
+	event := &Event{Point: PostToolCall}
+	err := r.Run(context.Background(), event)
+	if err == nil || !strings.Contains(err.Error(), "lint crashed") {
+		t.Errorf("expected the hook error to be returned, got %v", err)
+	}
+	if _, vetoed := event.Vetoed(); vetoed {
+		t.Error("expected post hooks not to veto")
+	}
+	if event.Context() != "still ran" {
+		t.Errorf("expected later hooks to run, got %q", event.Context())
+	}
+}
+
+func TestNilRegistry(t *testing.T) {
+	var r *Registry
+	if r.Has(PreTurn) {
+		t.Error("expected a nil registry to have no hooks")
+	}
+	if err := r.Run(context.Background(), &Event{Point: PreTurn}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestEventArguments(t *testing.T) {
+	event := &Event{ArgumentsXML: []byte("<path>main.go</path>")}
+	args, err := event.Arguments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args["path"] != "main.go" {
+		t.Errorf("expected path main.go, got %v", args["path"])
+	}
+
+	if err := event.SetArguments(map[string]any{"path": "other.go", "content": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(event.ArgumentsXML); got != "<content>x</content><path>other.go</path>" {
+		t.Errorf("unexpected arguments XML %q", got)
+	}
+}
+
+func TestParsePoint(t *testing.T) {
+	for _, point := range Points {
+		if got, err := ParsePoint(string(point)); err != nil || got != point {
+			t.Errorf("ParsePoint(%q) = %q, %v", point, got, err)
+		}
+	}
+	if _, err := ParsePoint("after_write"); err == nil {
+		t.Error("expected an unknown point to be rejected")
+	}
+}
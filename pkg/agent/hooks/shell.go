@@ -0,0 +1,209 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigPath is the workspace-relative location of the shell hook config.
+const ConfigPath = ".forge/hooks.yaml"
+
+const (
+	// defaultShellTimeout bounds a shell hook without its own timeout
+	defaultShellTimeout = 60 * time.Second
+	// maxShellOutput caps how much hook output is passed to the model
+	maxShellOutput = 8 * 1024
+)
+
+// Config is a repository's shell hooks, read from .forge/hooks.yaml. It lets
+// a repo enforce invariants, such as formatting every file the agent writes,
+// without relying on the model to remember them.
+//
+// Example .forge/hooks.yaml:
+//
+//	hooks:
+//	  - on: post_tool_call
+//	    tools: [write_file, apply_diff]
+//	    command: gofmt -w "$FORGE_TOOL_PATH"
+//	  - on: pre_tool_call
+//	    tools: [execute_command]
+//	    command: ./scripts/check-command.sh
+//	    timeout: 10s
+type Config struct {
+	Hooks []ShellHook `yaml:"hooks"`
+
+	Path string `yaml:"-"` // Absolute path the config was loaded from
+}
+
+// ShellHook runs a shell command at a hook point. The command runs with sh in
+// the workspace and receives the event as JSON on stdin, plus FORGE_HOOK,
+// FORGE_TOOL_NAME, FORGE_TOOL_PATH and FORGE_WORKSPACE in its environment.
+//
+// A failing command (non-zero exit or timeout) vetoes a pre_tool_call or
+// pre_turn event with its output as the reason; at other points the failure
+// and output are appended as context. Output from a successful command is
+// appended as context.
+type ShellHook struct {
+	On      Point         `yaml:"on"`
+	Tools   []string      `yaml:"tools"`   // Tool names to run for; empty means every tool
+	Command string        `yaml:"command"` // Run with sh -c
+	Timeout time.Duration `yaml:"timeout"` // 0 means defaultShellTimeout
+}
+
+// LoadConfig reads .forge/hooks.yaml from workspaceDir.
+// It returns (nil, nil) when the file does not exist.
+func LoadConfig(workspaceDir string) (*Config, error) {
+	path := filepath.Join(workspaceDir, ConfigPath)
+	data, err := os.ReadFile(path) //nolint:gosec // path is fixed relative to the workspace
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read hooks config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ConfigPath, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ConfigPath, err)
+	}
+
+	if absPath, absErr := filepath.Abs(path); absErr == nil {
+		path = absPath
+	}
+	cfg.Path = path
+
+	return &cfg, nil
+}
+
+// Validate checks the config for hooks that cannot run.
+func (c *Config) Validate() error {
+	for i, hook := range c.Hooks {
+		if _, err := ParsePoint(string(hook.On)); err != nil {
+			return fmt.Errorf("hooks[%d].on: %w", i, err)
+		}
+		if strings.TrimSpace(hook.Command) == "" {
+			return fmt.Errorf("hooks[%d].command: command is empty", i)
+		}
+		if len(hook.Tools) > 0 && (hook.On == PreTurn || hook.On == PostTurn) {
+			return fmt.Errorf("hooks[%d].tools: only applies to pre_tool_call and post_tool_call hooks", i)
+		}
+		if hook.Timeout < 0 {
+			return fmt.Errorf("hooks[%d].timeout: must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// Register adds each configured hook to r, running its command in
+// workspaceDir. It is safe to call on a nil config.
+func (c *Config) Register(r *Registry, workspaceDir string) {
+	if c == nil {
+		return
+	}
+	for _, hook := range c.Hooks {
+		r.Register(hook.On, hook.Func(workspaceDir))
+	}
+}
+
+// shellPayload is the event as passed to a shell hook on stdin
+type shellPayload struct {
+	Hook      Point          `json:"hook"`
+	Tool      string         `json:"tool,omitempty"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	Result    string         `json:"result,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Input     string         `json:"input,omitempty"`
+}
+
+// Func returns the hook function that runs the command in workspaceDir.
+func (h ShellHook) Func(workspaceDir string) Func {
+	return func(ctx context.Context, event *Event) error {
+		if len(h.Tools) > 0 && !slices.Contains(h.Tools, event.ToolName) {
+			return nil
+		}
+
+		payload := shellPayload{Hook: event.Point, Tool: event.ToolName, Result: event.Result, Input: event.Input}
+		if event.ToolName != "" {
+			payload.Arguments, _ = event.Arguments()
+		}
+		if event.Err != nil {
+			payload.Error = event.Err.Error()
+		}
+		stdin, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode hook input: %w", err)
+		}
+
+		timeout := h.Timeout
+		if timeout == 0 {
+			timeout = defaultShellTimeout
+		}
+		runCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(runCtx, "sh", "-c", h.Command) //nolint:gosec // commands come from the repository's hook config
+		cmd.Dir = workspaceDir
+		// Stop waiting on output held open by background children once killed
+		cmd.WaitDelay = time.Second
+		cmd.Stdin = bytes.NewReader(stdin)
+		cmd.Env = append(os.Environ(),
+			"FORGE_HOOK="+string(event.Point),
+			"FORGE_TOOL_NAME="+event.ToolName,
+			"FORGE_TOOL_PATH="+toolPath(payload.Arguments),
+			"FORGE_WORKSPACE="+workspaceDir,
+		)
+		output, runErr := cmd.CombinedOutput()
+		out := truncateOutput(strings.TrimSpace(string(output)))
+
+		if runErr != nil {
+			if runCtx.Err() == context.DeadlineExceeded {
+				runErr = fmt.Errorf("timed out after %s", timeout)
+			}
+			message := fmt.Sprintf("Hook `%s` failed (%v)", h.Command, runErr)
+			if out != "" {
+				message += ":\n" + out
+			}
+			if event.Point.canVeto() {
+				event.Veto(message)
+			} else {
+				event.AppendContext(message)
+			}
+			return nil
+		}
+
+		if out != "" {
+			event.AppendContext(fmt.Sprintf("Hook `%s`:\n%s", h.Command, out))
+		}
+		return nil
+	}
+}
+
+// toolPath returns the path argument of a tool call, or "" if it has none
+func toolPath(args map[string]any) string {
+	if path, ok := args["path"].(string); ok {
+		return path
+	}
+	return ""
+}
+
+// truncateOutput keeps the start of long hook output
+func truncateOutput(out string) string {
+	if len(out) <= maxShellOutput {
+		return out
+	}
+	return out[:maxShellOutput] + fmt.Sprintf("\n[output truncated: %d of %d bytes shown]", maxShellOutput, len(out))
+}
@@ -0,0 +1,131 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeHooksConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, ".forge"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ConfigPath), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := LoadConfig(dir)
+	if err != nil || cfg != nil {
+		t.Fatalf("expected no config without a file, got %v, %v", cfg, err)
+	}
+
+	writeHooksConfig(t, dir, `hooks:
+  - on: post_tool_call
+    tools: [write_file]
+    command: gofmt -w "$FORGE_TOOL_PATH"
+    timeout: 5s
+`)
+	cfg, err = LoadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Hooks) != 1 || cfg.Hooks[0].On != PostToolCall || cfg.Hooks[0].Timeout != 5*time.Second {
+		t.Errorf("unexpected config %+v", cfg.Hooks)
+	}
+	if !filepath.IsAbs(cfg.Path) {
+		t.Errorf("expected an absolute path, got %q", cfg.Path)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		hook ShellHook
+		want string
+	}{
+		{"unknown point", ShellHook{On: "after_write", Command: "true"}, "unknown hook point"},
+		{"empty command", ShellHook{On: PreTurn, Command: " "}, "command is empty"},
+		{"tools on turn hook", ShellHook{On: PostTurn, Command: "true", Tools: []string{"write_file"}}, "only applies"},
+		{"negative timeout", ShellHook{On: PreToolCall, Command: "true", Timeout: -time.Second}, "negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{Hooks: []ShellHook{tt.hook}}).Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestShellHook(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	t.Run("output becomes context", func(t *testing.T) {
+		hook := ShellHook{On: PostToolCall, Command: `echo "$FORGE_HOOK $FORGE_TOOL_NAME $FORGE_TOOL_PATH"; grep -o '"tool":"write_file"'`}
+		event := &Event{Point: PostToolCall, ToolName: "write_file", ArgumentsXML: []byte("<path>main.go</path>")}
+		if err := hook.Func(dir)(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		if want := "post_tool_call write_file main.go\n\"tool\":\"write_file\""; !strings.Contains(event.Context(), want) {
+			t.Errorf("expected context containing %q, got %q", want, event.Context())
+		}
+	})
+
+	t.Run("other tools are skipped", func(t *testing.T) {
+		hook := ShellHook{On: PreToolCall, Tools: []string{"write_file"}, Command: "exit 1"}
+		event := &Event{Point: PreToolCall, ToolName: "read_file"}
+		if err := hook.Func(dir)(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		if _, vetoed := event.Vetoed(); vetoed {
+			t.Error("expected the hook not to run for read_file")
+		}
+	})
+
+	t.Run("failure vetoes pre hooks", func(t *testing.T) {
+		hook := ShellHook{On: PreToolCall, Command: "echo 'not on main'; exit 3"}
+		event := &Event{Point: PreToolCall, ToolName: "execute_command"}
+		if err := hook.Func(dir)(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		reason, vetoed := event.Vetoed()
+		if !vetoed || !strings.Contains(reason, "exit status 3") || !strings.Contains(reason, "not on main") {
+			t.Errorf("expected a veto with the exit status and output, got %q", reason)
+		}
+	})
+
+	t.Run("failure is context for post hooks", func(t *testing.T) {
+		hook := ShellHook{On: PostTurn, Command: "echo broken >&2; exit 1"}
+		event := &Event{Point: PostTurn}
+		if err := hook.Func(dir)(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(event.Context(), "failed (exit status 1)") || !strings.Contains(event.Context(), "broken") {
+			t.Errorf("unexpected context %q", event.Context())
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		hook := ShellHook{On: PreTurn, Command: "sleep 5", Timeout: 50 * time.Millisecond}
+		event := &Event{Point: PreTurn}
+		if err := hook.Func(dir)(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		if reason, _ := event.Vetoed(); !strings.Contains(reason, "timed out") {
+			t.Errorf("expected a timeout veto, got %q", reason)
+		}
+	})
+}
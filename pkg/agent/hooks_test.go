@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/hooks"
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// recordingTool returns the arguments it was executed with
+type recordingTool struct {
+	calls int
+}
+
+func (r *recordingTool) Name() string        { return "record" }
+func (r *recordingTool) Description() string { return "records its arguments" }
+func (r *recordingTool) Schema() map[string]any {
+	return map[string]any{"type": "object"}
+}
+func (r *recordingTool) Execute(ctx context.Context, args []byte) (string, map[string]any, error) {
+	r.calls++
+	return "ran with " + string(args), nil, nil
+}
+func (r *recordingTool) IsLoopBreaking() bool { return false }
+
+func lastMessageContent(a *DefaultAgent) string {
+	messages := a.memory.GetAll()
+	if len(messages) == 0 {
+		return ""
+	}
+	return messages[len(messages)-1].Content
+}
+
+func TestToolCallHooks(t *testing.T) {
+	a := NewDefaultAgent(&mockProvider{}, WithBufferSize(100))
+	tool := &recordingTool{}
+	if err := a.RegisterTool(tool); err != nil {
+		t.Fatal(err)
+	}
+
+	a.RegisterHook(hooks.PreToolCall, func(ctx context.Context, e *hooks.Event) error {
+		return e.SetArguments(map[string]any{"path": "rewritten.go"})
+	})
+	a.RegisterHook(hooks.PostToolCall, func(ctx context.Context, e *hooks.Event) error {
+		e.AppendContext("formatted " + e.ToolName)
+		return nil
+	})
+
+	call := tools.ToolCall{ToolName: "record", Arguments: tools.ArgumentsBlock{InnerXML: []byte("<path>original.go</path>")}}
+	if shouldContinue, errCtx := a.executeTool(context.Background(), call); !shouldContinue || errCtx != "" {
+		t.Fatalf("unexpected result: continue=%v errCtx=%q", shouldContinue, errCtx)
+	}
+
+	result := lastMessageContent(a)
+	if !strings.Contains(result, "<path>rewritten.go</path>") {
+		t.Errorf("expected rewritten arguments, got %q", result)
+	}
+	if !strings.Contains(result, "<hook_context>\nformatted record\n</hook_context>") {
+		t.Errorf("expected post hook context in the result, got %q", result)
+	}
+}
+
+func TestToolCallHookVeto(t *testing.T) {
+	a := NewDefaultAgent(&mockProvider{}, WithBufferSize(100))
+	tool := &recordingTool{}
+	if err := a.RegisterTool(tool); err != nil {
+		t.Fatal(err)
+	}
+	a.RegisterHook(hooks.PreToolCall, func(ctx context.Context, e *hooks.Event) error {
+		return errors.New("generated files are read-only")
+	})
+
+	call := tools.ToolCall{ToolName: "record"}
+	if shouldContinue, _ := a.executeTool(context.Background(), call); !shouldContinue {
+		t.Fatal("expected the loop to continue after a veto")
+	}
+
+	if tool.calls != 0 {
+		t.Error("expected a vetoed tool not to run")
+	}
+	if got := lastMessageContent(a); !strings.Contains(got, "blocked by a hook") || !strings.Contains(got, "generated files are read-only") {
+		t.Errorf("expected the veto reason in memory, got %q", got)
+	}
+}
+
+func TestTurnHooks(t *testing.T) {
+	a := NewDefaultAgent(&mockProvider{}, WithBufferSize(100))
+	a.RegisterHook(hooks.PostTurn, func(ctx context.Context, e *hooks.Event) error {
+		e.AppendContext("2 tests failed")
+		return nil
+	})
+	a.RegisterHook(hooks.PreTurn, func(ctx context.Context, e *hooks.Event) error {
+		if strings.Contains(e.Input, "forbidden") {
+			e.Veto("not allowed")
+		}
+		e.Input = strings.ToUpper(e.Input)
+		return nil
+	})
+
+	a.runPostTurnHooks(context.Background())
+	content, ok := a.runPreTurnHooks(context.Background(), "fix it")
+	if !ok {
+		t.Fatal("expected the turn to proceed")
+	}
+	if want := "FIX IT\n\n<hook_context>\n2 tests failed\n</hook_context>"; content != want {
+		t.Errorf("expected %q, got %q", want, content)
+	}
+
+	// Post-turn context is only delivered once
+	if content, _ = a.runPreTurnHooks(context.Background(), "again"); content != "AGAIN" {
+		t.Errorf("expected no stale hook context, got %q", content)
+	}
+
+	if _, ok := a.runPreTurnHooks(context.Background(), "forbidden"); ok {
+		t.Error("expected the turn to be vetoed")
+	}
+}
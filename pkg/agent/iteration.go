@@ -3,25 +3,30 @@ package agent
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/entrhq/forge/pkg/agent/core"
 	"github.com/entrhq/forge/pkg/agent/memory"
 	"github.com/entrhq/forge/pkg/agent/prompts"
+	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/types"
 )
 
 // promptContext holds the prepared prompt and related metadata
 type promptContext struct {
-	systemPrompt string
-	messages     []*types.Message
-	promptTokens int
+	systemPrompt    string
+	messages        []*types.Message
+	promptTokens    int // estimatedTokens calibrated against reported usage
+	estimatedTokens int // tokenizer count of messages
 }
 
 // llmResponse holds the response from the LLM
 type llmResponse struct {
 	assistantContent string
 	toolCallContent  string
+	nativeToolCalls  []types.ToolCall // set instead of toolCallContent in native tool calling mode
 	completionTokens int
+	usage            *llm.UsageInfo // token usage reported by the provider, if any
 }
 
 // attemptSummarization tries to summarize the conversation if context manager is available
@@ -88,10 +93,11 @@ func (a *DefaultAgent) preparePrompt(ctx context.Context, errorContext string) *
 	messages := prompts.BuildMessages(systemPrompt, history, "", errorContext)
 
 	// Track prompt tokens before sending to LLM
-	var promptTokens int
+	var promptTokens, estimatedTokens int
 	if a.tokenizer != nil {
-		promptTokens = a.tokenizer.CountMessagesTokens(messages)
-		agentDebugLog.Printf("Prompt tokens before send: %d", promptTokens)
+		estimatedTokens = a.tokenizer.CountMessagesTokens(messages)
+		promptTokens = a.calibrateTokens(estimatedTokens)
+		agentDebugLog.Printf("Prompt tokens before send: %d (estimated %d)", promptTokens, estimatedTokens)
 	}
 
 	// Check if we need to summarize conversation history
@@ -102,18 +108,29 @@ func (a *DefaultAgent) preparePrompt(ctx context.Context, errorContext string) *
 
 		// Recalculate tokens with updated messages
 		if a.tokenizer != nil {
-			promptTokens = a.tokenizer.CountMessagesTokens(messages)
+			estimatedTokens = a.tokenizer.CountMessagesTokens(messages)
+			promptTokens = a.calibrateTokens(estimatedTokens)
 			agentDebugLog.Printf("Tokens after summarization: %d", promptTokens)
 		}
 	}
 
 	return &promptContext{
-		systemPrompt: systemPrompt,
-		messages:     messages,
-		promptTokens: promptTokens,
+		systemPrompt:    systemPrompt,
+		messages:        messages,
+		promptTokens:    promptTokens,
+		estimatedTokens: estimatedTokens,
 	}
 }
 
+// calibrateTokens corrects a tokenizer estimate with the usage the provider
+// reported for earlier calls, when the context manager has any
+func (a *DefaultAgent) calibrateTokens(estimated int) int {
+	if a.contextManager == nil {
+		return estimated
+	}
+	return a.contextManager.CalibrateTokens(estimated)
+}
+
 // callLLM sends the request to the LLM and processes the streaming response
 func (a *DefaultAgent) callLLM(ctx context.Context, pctx *promptContext) (*llmResponse, error) {
 	// Emit API call start event with context information
@@ -123,8 +140,17 @@ func (a *DefaultAgent) callLLM(ctx context.Context, pctx *promptContext) (*llmRe
 	}
 	a.emitEvent(types.NewAPICallStartEvent("llm", pctx.promptTokens, maxTokens))
 
-	// Get response from LLM
-	stream, err := a.provider.StreamCompletion(ctx, pctx.messages)
+	// Get response from LLM, reporting any move to the fallback model
+	ctx = llm.ContextWithFallbackHandler(ctx, a.emitModelFallback)
+	native := a.nativeToolCalls()
+	var stream <-chan *llm.StreamChunk
+	var err error
+	if native {
+		provider := a.provider.(llm.ToolCallingProvider) //nolint:errcheck // checked by nativeToolCalls
+		stream, err = provider.StreamCompletionWithTools(ctx, pctx.messages, a.getToolDefinitions())
+	} else {
+		stream, err = a.provider.StreamCompletion(ctx, pctx.messages)
+	}
 	if err != nil {
 		// Check if this is a context cancellation (user stopped the agent)
 		if ctx.Err() != nil {
@@ -134,14 +160,26 @@ func (a *DefaultAgent) callLLM(ctx context.Context, pctx *promptContext) (*llmRe
 		a.emitEvent(types.NewErrorEvent(fmt.Errorf("failed to start completion: %w", err)))
 		return nil, err
 	}
+	stream = a.recordStream(pctx.messages, stream)
 
 	// Process stream and collect response
 	var assistantContent string
 	var toolCallContent string
-	core.ProcessStream(stream, a.emitEvent, func(content, thinking, toolCall, role string) {
-		assistantContent = content
-		toolCallContent = toolCall
-	})
+	var nativeToolCalls []types.ToolCall
+	var usage *llm.UsageInfo
+	if native {
+		core.ProcessNativeToolCallStream(stream, a.emitEvent, func(content, thinking, role string, toolCalls []types.ToolCall, reported *llm.UsageInfo) {
+			assistantContent = content
+			nativeToolCalls = toolCalls
+			usage = reported
+		})
+	} else {
+		core.ProcessStream(stream, a.emitEvent, func(content, thinking, toolCall, role string, reported *llm.UsageInfo) {
+			assistantContent = content
+			toolCallContent = toolCall
+			usage = reported
+		})
+	}
 
 	// Count completion tokens if tokenizer is available
 	var completionTokens int
@@ -150,24 +188,69 @@ func (a *DefaultAgent) callLLM(ctx context.Context, pctx *promptContext) (*llmRe
 		if toolCallContent != "" {
 			fullResponse += toolCallContent
 		}
+		for _, call := range nativeToolCalls {
+			fullResponse += call.Name + call.Arguments
+		}
 		completionTokens = a.tokenizer.CountTokens(fullResponse)
 	}
 
 	return &llmResponse{
 		assistantContent: assistantContent,
 		toolCallContent:  toolCallContent,
+		nativeToolCalls:  nativeToolCalls,
 		completionTokens: completionTokens,
+		usage:            usage,
 	}, nil
 }
 
+// emitModelFallback reports that the model was rate limited and requests
+// moved to the fallback model
+func (a *DefaultAgent) emitModelFallback(fallback llm.ModelFallback) {
+	agentDebugLog.Printf("Model %s rate limited, falling back to %s until %s: %s",
+		fallback.From, fallback.To, fallback.Until.Format(time.RFC3339), fallback.Reason)
+	a.emitEvent(types.NewModelFallbackEvent(types.ModelFallback{
+		From:   fallback.From,
+		To:     fallback.To,
+		Reason: fallback.Reason,
+		Until:  fallback.Until,
+	}))
+}
+
 // recordResponse handles token usage events and adds the response to memory
 func (a *DefaultAgent) recordResponse(pctx *promptContext, resp *llmResponse) {
-	// Emit token usage event if we have token counts
-	if pctx.promptTokens > 0 || resp.completionTokens > 0 {
+	// Prefer the provider's billed usage to our estimates, and correct later
+	// estimates with it
+	if usage := resp.usage; usage != nil && usage.PromptTokens > 0 {
+		if a.contextManager != nil {
+			a.contextManager.ReconcileUsage(pctx.estimatedTokens, usage.PromptTokens)
+		}
+		totalTokens := usage.TotalTokens
+		if totalTokens == 0 {
+			totalTokens = usage.PromptTokens + usage.CompletionTokens
+		}
+		a.emitEvent(types.NewTokenUsageEvent(usage.PromptTokens, usage.CompletionTokens, totalTokens))
+	} else if pctx.promptTokens > 0 || resp.completionTokens > 0 {
 		totalTokens := pctx.promptTokens + resp.completionTokens
 		a.emitEvent(types.NewTokenUsageEvent(pctx.promptTokens, resp.completionTokens, totalTokens))
 	}
 
+	// Native tool calls are kept structured so the provider can pair them with
+	// their results. Only the first call is executed; the others are answered
+	// with an error so every call has a result and the model can retry them.
+	if len(resp.nativeToolCalls) > 0 {
+		a.memory.Add(&types.Message{
+			Role:      types.RoleAssistant,
+			Content:   resp.assistantContent,
+			ToolCalls: resp.nativeToolCalls,
+		})
+		for _, call := range resp.nativeToolCalls[1:] {
+			msg := types.NewToolMessage(fmt.Sprintf("Tool '%s' was not executed: only one tool call per turn is supported. Call it again in your next response if it is still needed.", call.Name))
+			msg.ToolCallID = call.ID
+			a.memory.Add(msg)
+		}
+		return
+	}
+
 	// Add assistant's response to memory
 	fullResponse := resp.assistantContent
 	if resp.toolCallContent != "" {
@@ -82,10 +82,11 @@ func (cm *ConversationMemory) Count() int {
 }
 
 // Prune reduces the conversation history to fit within a token limit
-// while preserving important context (system messages, recent messages).
+// while preserving important context (system messages, pinned messages,
+// recent messages).
 //
 // Strategy:
-// 1. Always keep system messages
+// 1. Always keep system messages and pinned messages
 // 2. Keep the most recent messages
 // 3. Remove messages from the middle of the conversation
 func (cm *ConversationMemory) Prune(maxTokens int) error {
@@ -126,37 +127,104 @@ func (cm *ConversationMemory) Prune(maxTokens int) error {
 	remainingTokens := maxTokens - systemTokens
 	if remainingTokens <= 0 {
 		// System messages alone exceed the limit
-		// Keep only the most recent system message
+		// Keep only the most recent system message and pinned messages
+		cm.messages = make([]*types.Message, 0, 1)
 		if len(systemMessages) > 0 {
-			cm.messages = []*types.Message{systemMessages[len(systemMessages)-1]}
+			cm.messages = append(cm.messages, systemMessages[len(systemMessages)-1])
+		}
+		for _, msg := range conversationMessages {
+			if msg.IsPinned() {
+				cm.messages = append(cm.messages, msg)
+			}
 		}
 		return nil
 	}
 
-	// Keep as many recent conversation messages as possible
-	keptMessages := make([]*types.Message, 0)
+	// Pinned messages are kept regardless of age and use up budget first
+	kept := make(map[*types.Message]bool)
 	currentTokens := 0
+	for _, msg := range conversationMessages {
+		if msg.IsPinned() {
+			kept[msg] = true
+			currentTokens += estimateTokens(msg)
+		}
+	}
 
-	// Add messages from newest to oldest
+	// Keep as many recent conversation messages as possible,
+	// adding messages from newest to oldest
 	for i := len(conversationMessages) - 1; i >= 0; i-- {
-		msgTokens := estimateTokens(conversationMessages[i])
-		if currentTokens+msgTokens <= remainingTokens {
-			keptMessages = append([]*types.Message{conversationMessages[i]}, keptMessages...)
-			currentTokens += msgTokens
-		} else {
+		msg := conversationMessages[i]
+		if kept[msg] {
+			continue
+		}
+		msgTokens := estimateTokens(msg)
+		if currentTokens+msgTokens > remainingTokens {
 			// Can't fit any more messages
 			break
 		}
+		kept[msg] = true
+		currentTokens += msgTokens
 	}
 
-	// Rebuild messages: system messages + kept conversation messages
-	cm.messages = make([]*types.Message, 0, len(systemMessages)+len(keptMessages))
+	// Rebuild messages: system messages + kept conversation messages in order
+	cm.messages = make([]*types.Message, 0, len(systemMessages)+len(kept))
 	cm.messages = append(cm.messages, systemMessages...)
-	cm.messages = append(cm.messages, keptMessages...)
+	for _, msg := range conversationMessages {
+		if kept[msg] {
+			cm.messages = append(cm.messages, msg)
+		}
+	}
 
 	return nil
 }
 
+// Pinned returns the pinned messages in conversation order
+func (cm *ConversationMemory) Pinned() []*types.Message {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	result := make([]*types.Message, 0)
+	for _, msg := range cm.messages {
+		if msg.IsPinned() {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// Unpin removes the pin from every pinned message, making them eligible for
+// summarization and pruning again. Returns the number of messages unpinned.
+func (cm *ConversationMemory) Unpin() int {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	count := 0
+	for _, msg := range cm.messages {
+		if msg.IsPinned() {
+			delete(msg.Metadata, types.MetadataPinned)
+			count++
+		}
+	}
+	return count
+}
+
+// TruncateFrom removes msg and every message after it, such as a turn being
+// retried. It returns the number of messages removed, or 0 when msg is no
+// longer in the history, e.g. because it was summarized.
+func (cm *ConversationMemory) TruncateFrom(msg *types.Message) int {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	for i, m := range cm.messages {
+		if m == msg {
+			removed := len(cm.messages) - i
+			cm.messages = cm.messages[:i:i]
+			return removed
+		}
+	}
+	return 0
+}
+
 // AddMultiple adds multiple messages at once (thread-safe)
 func (cm *ConversationMemory) AddMultiple(messages []*types.Message) {
 	cm.mu.Lock()
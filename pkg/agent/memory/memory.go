@@ -20,7 +20,7 @@ type Memory interface {
 	Clear()
 
 	// Prune reduces the conversation history to fit within a token limit
-	// while preserving important context (system messages, recent messages)
+	// while preserving important context (system, pinned and recent messages)
 	Prune(maxTokens int) error
 
 	// Count returns the number of messages in the conversation history
@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"strings"
 	"sync"
 	"testing"
 
@@ -169,6 +170,29 @@ func TestConversationMemory_Prune(t *testing.T) {
 		}
 	})
 
+	t.Run("PruneKeepsPinnedMessages", func(t *testing.T) {
+		mem := NewConversationMemory()
+
+		spec := types.NewUserMessage(strings.Repeat("Acceptance criteria. ", 20)).WithMetadata(types.MetadataPinned, true)
+		mem.Add(types.NewSystemMessage("You are helpful"))
+		mem.Add(spec)
+		for i := 0; i < 10; i++ {
+			mem.Add(types.NewAssistantMessage(strings.Repeat("Working on it. ", 10)))
+		}
+
+		if err := mem.Prune(150); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		all := mem.GetAll()
+		if len(all) >= 12 {
+			t.Fatalf("expected messages to be pruned, got %d", len(all))
+		}
+		if all[0].Role != types.RoleSystem || all[1] != spec {
+			t.Error("expected the pinned message to be kept in place after the system message")
+		}
+	})
+
 	t.Run("PruneInvalidTokens", func(t *testing.T) {
 		mem := NewConversationMemory()
 		mem.Add(types.NewUserMessage("Test"))
@@ -294,3 +318,40 @@ func TestConversationMemory_GetAllReturnsACopy(t *testing.T) {
 		t.Errorf("expected 1 message in internal storage, got %d", len(original))
 	}
 }
+
+func TestConversationMemory_PinnedAndUnpin(t *testing.T) {
+	mem := NewConversationMemory()
+
+	mem.Add(types.NewUserMessage("Spec").WithMetadata(types.MetadataPinned, true))
+	mem.Add(types.NewAssistantMessage("Reply"))
+	mem.Add(types.NewToolMessage("Criteria").WithMetadata(types.MetadataPinned, true))
+
+	pinned := mem.Pinned()
+	if len(pinned) != 2 || pinned[0].Content != "Spec" || pinned[1].Content != "Criteria" {
+		t.Fatalf("expected both pinned messages in order, got %v", pinned)
+	}
+
+	if n := mem.Unpin(); n != 2 {
+		t.Errorf("expected 2 messages unpinned, got %d", n)
+	}
+	if len(mem.Pinned()) != 0 {
+		t.Error("expected no pinned messages after Unpin")
+	}
+}
+
+func TestConversationMemory_TruncateFrom(t *testing.T) {
+	mem := NewConversationMemory()
+	first := types.NewUserMessage("First")
+	retried := types.NewUserMessage("Retried")
+	mem.AddMultiple([]*types.Message{first, types.NewAssistantMessage("Reply"), retried, types.NewAssistantMessage("Failed")})
+
+	if n := mem.TruncateFrom(retried); n != 2 {
+		t.Errorf("expected 2 messages removed, got %d", n)
+	}
+	if all := mem.GetAll(); len(all) != 2 || all[1].Content != "Reply" {
+		t.Errorf("expected the history before the retried message, got %v", all)
+	}
+	if n := mem.TruncateFrom(retried); n != 0 {
+		t.Errorf("expected nothing removed for a message no longer in the history, got %d", n)
+	}
+}
@@ -0,0 +1,36 @@
+package notes
+
+import "slices"
+
+// Category tags mark the notes the agent records on its own as work
+// progresses, so the user can review them by kind. A note's category is the
+// first of its tags that is one of these.
+const (
+	// CategoryDecision marks a choice between approaches and why it was made
+	CategoryDecision = "decision"
+
+	// CategoryBlocker marks a constraint discovered during the task that
+	// stops or limits the work
+	CategoryBlocker = "blocker"
+
+	// CategoryFollowup marks work deferred for later
+	CategoryFollowup = "followup"
+)
+
+// Categories lists the category tags in display order.
+var Categories = []string{CategoryDecision, CategoryBlocker, CategoryFollowup}
+
+// IsCategory reports whether tag is a category tag (case-insensitive).
+func IsCategory(tag string) bool {
+	return slices.Contains(Categories, normalizeTag(tag))
+}
+
+// Category returns the note's category tag, or "" when it has none.
+func (n *Note) Category() string {
+	for _, tag := range n.Tags {
+		if IsCategory(tag) {
+			return tag
+		}
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+package notes
+
+import "testing"
+
+func TestNoteCategory(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want string
+	}{
+		{name: "no category", tags: []string{"auth", "api"}, want: ""},
+		{name: "category tag", tags: []string{"auth", "Decision"}, want: CategoryDecision},
+		{name: "first category wins", tags: []string{"followup", "blocker"}, want: CategoryFollowup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note, err := NewNote("content", tt.tags)
+			if err != nil {
+				t.Fatalf("NewNote() error = %v", err)
+			}
+			if got := note.Category(); got != tt.want {
+				t.Errorf("Category() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCategory(t *testing.T) {
+	for _, tag := range []string{"decision", " BLOCKER ", "followup"} {
+		if !IsCategory(tag) {
+			t.Errorf("IsCategory(%q) = false, want true", tag)
+		}
+	}
+	for _, tag := range []string{"", "todo", "decisions"} {
+		if IsCategory(tag) {
+			t.Errorf("IsCategory(%q) = true, want false", tag)
+		}
+	}
+}
@@ -243,3 +243,18 @@ func (m *Manager) Clear() {
 
 	m.notes = make(map[string]*Note)
 }
+
+// Restore replaces all notes with the given set, preserving their IDs and
+// timestamps. It is used to resume a saved session.
+func (m *Manager) Restore(notes []*Note) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.notes = make(map[string]*Note, len(notes))
+	for _, note := range notes {
+		if note == nil || note.ID == "" {
+			continue
+		}
+		m.notes[note.ID] = note
+	}
+}
@@ -467,3 +467,24 @@ func TestManagerConcurrency(t *testing.T) {
 		t.Errorf("expected 10 notes after concurrent adds, got %d", m.Count())
 	}
 }
+
+func TestManagerRestore(t *testing.T) {
+	m := NewManager()
+	if _, err := m.Add("discarded", []string{"old"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	saved := &Note{ID: "note_42", Content: "restored", Tags: []string{"test"}, Scratched: true}
+	m.Restore([]*Note{saved, nil})
+
+	if m.Count() != 1 {
+		t.Fatalf("expected 1 note, got %d", m.Count())
+	}
+	got, err := m.Get("note_42")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Content != "restored" || !got.Scratched {
+		t.Errorf("unexpected note %+v", got)
+	}
+}
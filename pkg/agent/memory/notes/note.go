@@ -117,11 +117,16 @@ func GenerateID() string {
 func normalizeTags(tags []string) []string {
 	normalized := make([]string, len(tags))
 	for i, tag := range tags {
-		normalized[i] = strings.ToLower(strings.TrimSpace(tag))
+		normalized[i] = normalizeTag(tag)
 	}
 	return normalized
 }
 
+// normalizeTag trims whitespace from tag and lowercases it
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
 // Update modifies the note's content and/or tags.
 // At least one parameter must be non-nil.
 func (n *Note) Update(content *string, tags []string) error {
@@ -170,8 +175,7 @@ func (n *Note) Unscratched() {
 
 // HasTag checks if the note has a specific tag (case-insensitive)
 func (n *Note) HasTag(tag string) bool {
-	normalized := strings.ToLower(strings.TrimSpace(tag))
-	return slices.Contains(n.Tags, normalized)
+	return slices.Contains(n.Tags, normalizeTag(tag))
 }
 
 // MatchesAllTags checks if the note has all specified tags (case-insensitive, AND logic)
@@ -0,0 +1,206 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/memory/notes"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/logging"
+	"github.com/google/uuid"
+)
+
+const (
+	queueSize = 64
+
+	// maxEmbedBatch caps how many queued records are embedded in one request.
+	maxEmbedBatch = 16
+
+	// maxContentChars keeps each embedded text well inside embedding model
+	// input limits. Longer content is truncated before embedding and storage.
+	maxContentChars = 8000
+)
+
+// Memory embeds records in the background and answers recall queries.
+//
+// Remember and RememberNotes never block the agent loop: records are queued
+// and embedded by a single goroutine. If the queue is full the record is
+// dropped and a debug log is emitted.
+type Memory struct {
+	store     Store
+	embedder  llm.Embedder
+	workspace string
+	queue     chan Record
+	log       *logging.Logger
+
+	notesMu      sync.Mutex
+	indexedNotes map[string]time.Time // Record ID -> UpdatedAt of the last indexed note version
+}
+
+// New creates a Memory that tags new records with workspace. A single Memory
+// may be shared by several agent sessions. A nil logger falls back to the
+// "memory" component logger.
+func New(store Store, embedder llm.Embedder, workspace string, log *logging.Logger) *Memory {
+	if log == nil {
+		log, _ = logging.NewLogger("memory")
+	}
+	return &Memory{
+		store:        store,
+		embedder:     embedder,
+		workspace:    workspace,
+		queue:        make(chan Record, queueSize),
+		log:          log,
+		indexedNotes: make(map[string]time.Time),
+	}
+}
+
+// Start launches the embedding goroutine. It runs until ctx is canceled.
+// Start must be called exactly once before any records are remembered.
+func (m *Memory) Start(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case r := <-m.queue:
+				batch := []Record{r}
+			drain:
+				for len(batch) < maxEmbedBatch {
+					select {
+					case next := <-m.queue:
+						batch = append(batch, next)
+					default:
+						break drain
+					}
+				}
+				m.index(ctx, batch)
+			}
+		}
+	}()
+}
+
+// index embeds and stores a batch of records. Failures are logged and the
+// batch is dropped; memory is best-effort and must not disturb the session.
+func (m *Memory) index(ctx context.Context, batch []Record) {
+	inputs := make([]string, len(batch))
+	for i, r := range batch {
+		inputs[i] = r.Content
+	}
+
+	vectors, err := m.embedder.Embed(ctx, inputs)
+	if err != nil {
+		m.log.Warnf("memory: vector indexing failed, dropping %d record(s): %v", len(batch), err)
+		return
+	}
+	if len(vectors) != len(batch) {
+		m.log.Warnf("memory: embedder returned %d vectors for %d inputs, dropping batch", len(vectors), len(batch))
+		return
+	}
+
+	for i := range batch {
+		batch[i].Vector = vectors[i]
+		batch[i].Model = m.embedder.Model()
+	}
+	if err := m.store.Add(ctx, batch...); err != nil {
+		m.log.Warnf("memory: vector store write failed: %v", err)
+		return
+	}
+	m.log.Debugf("memory: indexed %d vector record(s)", len(batch))
+}
+
+// Remember queues content produced by sessionID for embedding under a new
+// record ID.
+func (m *Memory) Remember(kind Kind, content, sessionID string) {
+	m.enqueue(string(kind)+"_"+uuid.NewString(), kind, content, sessionID)
+}
+
+// RememberNotes queues every note that is new or changed since it was last
+// indexed. Scratched notes are skipped: they record work that is finished or
+// no longer relevant.
+func (m *Memory) RememberNotes(all []*notes.Note, sessionID string) {
+	m.notesMu.Lock()
+	defer m.notesMu.Unlock()
+
+	for _, note := range all {
+		if note.Scratched {
+			continue
+		}
+		// Note IDs are only unique within a session, so scope the record ID to it
+		id := fmt.Sprintf("%s_%s_%s", KindNote, sessionID, note.ID)
+		if indexed, ok := m.indexedNotes[id]; ok && !note.UpdatedAt.After(indexed) {
+			continue
+		}
+		m.indexedNotes[id] = note.UpdatedAt
+
+		content := note.Content
+		if len(note.Tags) > 0 {
+			content = fmt.Sprintf("%s\n[tags: %s]", content, strings.Join(note.Tags, ", "))
+		}
+		m.enqueue(id, KindNote, content, sessionID)
+	}
+}
+
+func (m *Memory) enqueue(id string, kind Kind, content, sessionID string) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return
+	}
+	if len(content) > maxContentChars {
+		content = strings.ToValidUTF8(content[:maxContentChars], "")
+	}
+
+	r := Record{
+		ID:        id,
+		Kind:      kind,
+		Content:   content,
+		Workspace: m.workspace,
+		SessionID: sessionID,
+		CreatedAt: time.Now().UTC(),
+	}
+	select {
+	case m.queue <- r:
+	default:
+		m.log.Debugf("memory: vector record dropped — queue full (kind=%s)", kind)
+	}
+}
+
+// RecallOptions controls a recall query.
+type RecallOptions struct {
+	Limit         int
+	Kind          Kind // Empty means any kind
+	AllWorkspaces bool // Search memories from every workspace, not just this one
+}
+
+// Recall embeds query and returns the most similar records. Only records
+// embedded with the current embedding model are compared, since vectors from
+// different models are not comparable.
+func (m *Memory) Recall(ctx context.Context, query string, opts RecallOptions) ([]Match, error) {
+	vectors, err := m.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("vector: embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("vector: embedder returned %d vectors for 1 query", len(vectors))
+	}
+
+	filter := Filter{Kind: opts.Kind, Model: m.embedder.Model()}
+	if !opts.AllWorkspaces {
+		filter.Workspace = m.workspace
+	}
+	return m.store.Search(ctx, vectors[0], opts.Limit, filter)
+}
+
+// DefaultStorePath returns the location of the user's vector store,
+// ~/.forge/memories/vectors.jsonl.
+func DefaultStorePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("vector: cannot determine home dir: %w", err)
+	}
+	return filepath.Join(homeDir, ".forge", "memories", "vectors.jsonl"), nil
+}
@@ -0,0 +1,60 @@
+// Package vector provides embedding-backed long-term memory for knowledge the
+// agent produces while working: summarized tool batches, scratchpad notes and
+// task completions. Records are embedded asynchronously, persisted to a local
+// store shared by every session and workspace, and searched by similarity
+// through the recall_memory tool.
+package vector
+
+import "time"
+
+// Kind classifies the source of a record.
+type Kind string
+
+const (
+	// KindToolBatch is an LLM summary of a batch of tool calls and results.
+	KindToolBatch Kind = "tool_batch"
+	// KindNote is a scratchpad note.
+	KindNote Kind = "note"
+	// KindTaskCompletion is the result the agent reported when finishing a task.
+	KindTaskCompletion Kind = "task_completion"
+)
+
+// Record is a single embedded memory.
+type Record struct {
+	// ID identifies the record. Adding a record with an existing ID replaces it,
+	// which lets edited notes overwrite their previous version.
+	ID        string    `json:"id"`
+	Kind      Kind      `json:"kind"`
+	Content   string    `json:"content"`
+	Workspace string    `json:"workspace"`
+	SessionID string    `json:"session_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Model     string    `json:"model"` // Embedding model that produced Vector
+	Vector    []float32 `json:"vector"`
+}
+
+// Match is a record returned by a similarity search.
+type Match struct {
+	Record Record
+	Score  float64 // Cosine similarity to the query, in [-1, 1]
+}
+
+// Filter narrows a search. Zero values match everything.
+type Filter struct {
+	Kind      Kind
+	Workspace string
+	Model     string
+}
+
+func (f Filter) matches(r *Record) bool {
+	if f.Kind != "" && r.Kind != f.Kind {
+		return false
+	}
+	if f.Workspace != "" && r.Workspace != f.Workspace {
+		return false
+	}
+	if f.Model != "" && r.Model != f.Model {
+		return false
+	}
+	return true
+}
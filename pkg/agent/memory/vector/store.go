@@ -0,0 +1,148 @@
+package vector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store persists embedded records and answers similarity queries.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	Add(ctx context.Context, records ...Record) error
+	Search(ctx context.Context, query []float32, k int, filter Filter) ([]Match, error)
+	Len() int
+}
+
+// FileStore is a Store backed by an append-only JSON Lines file. The whole
+// index is held in memory and searched linearly, which is fast enough for the
+// tens of thousands of records a single user accumulates.
+type FileStore struct {
+	path string
+
+	mu      sync.RWMutex
+	records []Record
+	byID    map[string]int // Index into records
+}
+
+// NewFileStore opens the store at path, creating it if needed. Lines that
+// cannot be parsed are skipped so a torn final write does not lose the index.
+func NewFileStore(path string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("vector: init directory: %w", err)
+	}
+
+	s := &FileStore{path: path, byID: make(map[string]int)}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vector: open store: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil || r.ID == "" {
+			continue
+		}
+		s.upsert(r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("vector: read store: %w", err)
+	}
+	return s, nil
+}
+
+// upsert adds r to the in-memory index, replacing any record with the same ID.
+// Callers must hold mu for writing.
+func (s *FileStore) upsert(r Record) {
+	if i, ok := s.byID[r.ID]; ok {
+		s.records[i] = r
+		return
+	}
+	s.byID[r.ID] = len(s.records)
+	s.records = append(s.records, r)
+}
+
+// Add appends records to the file and the in-memory index.
+func (s *FileStore) Add(_ context.Context, records ...Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("vector: open store: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("vector: encode record %s: %w", r.ID, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("vector: write store: %w", err)
+	}
+
+	for _, r := range records {
+		s.upsert(r)
+	}
+	return nil
+}
+
+// Search returns up to k records matching filter, ordered by descending
+// similarity to query. Vectors are unit length, so similarity is a dot product.
+func (s *FileStore) Search(_ context.Context, query []float32, k int, filter Filter) ([]Match, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []Match
+	for i := range s.records {
+		r := &s.records[i]
+		if !filter.matches(r) {
+			continue
+		}
+		matches = append(matches, Match{Record: *r, Score: dotProduct(query, r.Vector)})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if k > 0 && len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches, nil
+}
+
+// Len returns the number of records in the store.
+func (s *FileStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.records)
+}
+
+func dotProduct(a, b []float32) float64 {
+	n := min(len(a), len(b))
+	var sum float64
+	for i := 0; i < n; i++ {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
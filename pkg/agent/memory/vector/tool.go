@@ -0,0 +1,146 @@
+package vector
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+const (
+	defaultRecallLimit = 5
+	maxRecallLimit     = 20
+)
+
+// RecallMemoryTool searches long-term vector memory from earlier sessions.
+type RecallMemoryTool struct {
+	memory *Memory
+}
+
+// NewRecallMemoryTool creates a new RecallMemoryTool.
+func NewRecallMemoryTool(memory *Memory) *RecallMemoryTool {
+	return &RecallMemoryTool{
+		memory: memory,
+	}
+}
+
+// Name returns the tool name.
+func (t *RecallMemoryTool) Name() string {
+	return "recall_memory"
+}
+
+// Description returns the tool description.
+func (t *RecallMemoryTool) Description() string {
+	return "Search long-term memory for knowledge from earlier sessions: summaries of past tool work, scratchpad notes and completed task results. " +
+		"Use it before investigating something you may have already worked on. Results are ranked by semantic similarity to the query."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *RecallMemoryTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"query": map[string]any{
+				"type":        "string",
+				"description": "Natural-language description of what you want to remember",
+			},
+			"kind": map[string]any{
+				"type":        "string",
+				"enum":        []string{string(KindToolBatch), string(KindNote), string(KindTaskCompletion)},
+				"description": "Only return memories of this kind (optional)",
+			},
+			"all_workspaces": map[string]any{
+				"type":        "boolean",
+				"description": "Also search memories from other workspaces (default: false)",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of results (default: %d, max: %d)", defaultRecallLimit, maxRecallLimit),
+			},
+		},
+		[]string{"query"},
+	)
+}
+
+// Execute searches vector memory.
+func (t *RecallMemoryTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName       xml.Name `xml:"arguments"`
+		Query         string   `xml:"query"`
+		Kind          string   `xml:"kind"`
+		AllWorkspaces bool     `xml:"all_workspaces"`
+		Limit         int      `xml:"limit"`
+	}
+
+	if err := xml.Unmarshal(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	query := strings.TrimSpace(input.Query)
+	if query == "" {
+		return "", nil, fmt.Errorf("query is required")
+	}
+
+	kind := Kind(strings.TrimSpace(input.Kind))
+	switch kind {
+	case "", KindToolBatch, KindNote, KindTaskCompletion:
+	default:
+		return "", nil, fmt.Errorf("invalid kind: %s (must be '%s', '%s' or '%s')", kind, KindToolBatch, KindNote, KindTaskCompletion)
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultRecallLimit
+	}
+	limit = min(limit, maxRecallLimit)
+
+	matches, err := t.memory.Recall(ctx, query, RecallOptions{
+		Limit:         limit,
+		Kind:          kind,
+		AllWorkspaces: input.AllWorkspaces,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var message strings.Builder
+	if len(matches) == 0 {
+		message.WriteString("No memories found.")
+	} else {
+		fmt.Fprintf(&message, "Found %d memor%s:\n\n", len(matches), pluralY(len(matches)))
+		for i, match := range matches {
+			r := match.Record
+			fmt.Fprintf(&message, "%d. [%s] %s (similarity %.2f)", i+1, r.Kind, r.CreatedAt.Format("2006-01-02"), match.Score)
+			if input.AllWorkspaces {
+				fmt.Fprintf(&message, " — %s", r.Workspace)
+			}
+			message.WriteString("\n")
+			for _, line := range strings.Split(r.Content, "\n") {
+				fmt.Fprintf(&message, "   %s\n", line)
+			}
+			message.WriteString("\n")
+		}
+	}
+
+	metadata := map[string]any{
+		"result_count":   len(matches),
+		"query":          query,
+		"kind":           string(kind),
+		"all_workspaces": input.AllWorkspaces,
+	}
+
+	return message.String(), metadata, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *RecallMemoryTool) IsLoopBreaking() bool {
+	return false
+}
+
+func pluralY(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
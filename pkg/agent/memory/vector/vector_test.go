@@ -0,0 +1,221 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/memory/notes"
+)
+
+// keywordEmbedder embeds text as a unit vector over a fixed keyword vocabulary,
+// so similarity is driven by shared keywords.
+type keywordEmbedder struct {
+	vocab []string
+	fail  bool
+}
+
+func (e *keywordEmbedder) Embed(_ context.Context, inputs []string) ([][]float32, error) {
+	if e.fail {
+		return nil, errors.New("embedding unavailable")
+	}
+	out := make([][]float32, len(inputs))
+	for i, input := range inputs {
+		v := make([]float32, len(e.vocab))
+		var norm float64
+		for j, word := range e.vocab {
+			if strings.Contains(strings.ToLower(input), word) {
+				v[j] = 1
+				norm++
+			}
+		}
+		if norm > 0 {
+			for j := range v {
+				v[j] /= float32(math.Sqrt(norm))
+			}
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func (e *keywordEmbedder) Model() string { return "keyword" }
+
+func newTestEmbedder() *keywordEmbedder {
+	return &keywordEmbedder{vocab: []string{"database", "migration", "auth", "token", "css"}}
+}
+
+// waitForRecords polls until the store holds n records.
+func waitForRecords(t *testing.T, store Store, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for store.Len() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d records, have %d", n, store.Len())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFileStore_PersistsAndUpserts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.jsonl")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := store.Add(ctx,
+		Record{ID: "a", Kind: KindNote, Content: "first", Workspace: "/repo", Vector: []float32{1, 0}},
+		Record{ID: "b", Kind: KindTaskCompletion, Content: "second", Workspace: "/other", Vector: []float32{0, 1}},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(ctx, Record{ID: "a", Kind: KindNote, Content: "edited", Workspace: "/repo", Vector: []float32{1, 0}}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != 2 {
+		t.Fatalf("expected 2 records after reopen, got %d", reopened.Len())
+	}
+
+	matches, err := reopened.Search(ctx, []float32{1, 0}, 5, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matches[0].Record.Content != "edited" {
+		t.Errorf("expected replaced record to rank first, got %q", matches[0].Record.Content)
+	}
+
+	matches, _ = reopened.Search(ctx, []float32{1, 0}, 5, Filter{Workspace: "/other"})
+	if len(matches) != 1 || matches[0].Record.ID != "b" {
+		t.Errorf("expected workspace filter to return only b, got %+v", matches)
+	}
+}
+
+func TestMemory_RememberAndRecall(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "vectors.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem := New(store, newTestEmbedder(), "/repo", nil)
+	mem.Start(ctx)
+
+	mem.Remember(KindToolBatch, "Ran the database migration and fixed a failing column", "s1")
+	mem.Remember(KindTaskCompletion, "Rotated the auth token signing key", "s1")
+	mem.Remember(KindNote, "   ", "s1") // Blank content is ignored
+	waitForRecords(t, store, 2)
+
+	matches, err := mem.Recall(ctx, "how did the migration go", RecallOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Record.Kind != KindToolBatch {
+		t.Fatalf("expected the migration summary, got %+v", matches)
+	}
+	if matches[0].Record.SessionID != "s1" || matches[0].Record.Model != "keyword" {
+		t.Errorf("expected provenance to be recorded, got %+v", matches[0].Record)
+	}
+
+	matches, _ = mem.Recall(ctx, "auth", RecallOptions{Kind: KindTaskCompletion})
+	if len(matches) != 1 {
+		t.Errorf("expected kind filter to return one record, got %d", len(matches))
+	}
+}
+
+func TestMemory_RememberNotesSkipsUnchanged(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "vectors.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem := New(store, newTestEmbedder(), "/repo", nil)
+	mem.Start(ctx)
+
+	manager := notes.NewManager()
+	note, err := manager.Add("CSS build uses the legacy pipeline", []string{"build"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Add("Auth middleware is deprecated", []string{"auth"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Scratch(note.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	all := manager.List(notes.ListOptions{IncludeScratched: true, Limit: 10})
+	mem.RememberNotes(all, "s1")
+	mem.RememberNotes(all, "s1")
+	waitForRecords(t, store, 1)
+
+	time.Sleep(50 * time.Millisecond)
+	if store.Len() != 1 {
+		t.Errorf("expected only the active note to be indexed once, got %d records", store.Len())
+	}
+}
+
+func TestMemory_RecallEmbedError(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "vectors.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem := New(store, &keywordEmbedder{fail: true}, "/repo", nil)
+
+	if _, err := mem.Recall(context.Background(), "anything", RecallOptions{}); err == nil {
+		t.Error("expected embedding failure to be returned")
+	}
+}
+
+func TestRecallMemoryTool_Execute(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "vectors.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedder := newTestEmbedder()
+	vectors, _ := embedder.Embed(ctx, []string{"database migration", "css"})
+	if err := store.Add(ctx,
+		Record{ID: "1", Kind: KindToolBatch, Content: "Migrated the database", Workspace: "/repo", Model: "keyword", Vector: vectors[0]},
+		Record{ID: "2", Kind: KindNote, Content: "CSS is generated", Workspace: "/elsewhere", Model: "keyword", Vector: vectors[1]},
+	); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewRecallMemoryTool(New(store, embedder, "/repo", nil))
+
+	result, metadata, err := tool.Execute(ctx, []byte(`<arguments><query>database</query></arguments>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, "Migrated the database") {
+		t.Errorf("expected migration memory in result, got %q", result)
+	}
+	if metadata["result_count"] != 1 {
+		t.Errorf("expected other workspaces to be excluded by default, got %v", metadata["result_count"])
+	}
+
+	result, _, err = tool.Execute(ctx, []byte(`<arguments><query>css</query><all_workspaces>true</all_workspaces><limit>1</limit></arguments>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, "/elsewhere") {
+		t.Errorf("expected cross-workspace result with its workspace, got %q", result)
+	}
+
+	if _, _, err := tool.Execute(ctx, []byte(`<arguments><query>x</query><kind>bogus</kind></arguments>`)); err == nil {
+		t.Error("expected invalid kind to be rejected")
+	}
+}
@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// OversizedMessagePolicy controls what the agent does with a single user input
+// or tool result that exceeds the per-message token ceiling.
+type OversizedMessagePolicy string
+
+const (
+	// OversizedMessageReject drops the message. Oversized user inputs end the
+	// turn without calling the LLM; oversized tool results are replaced with a
+	// note asking the agent to narrow its request.
+	OversizedMessageReject OversizedMessagePolicy = "reject"
+
+	// OversizedMessageChunk keeps the leading and trailing chunks of the message
+	// that fit within the ceiling and elides the middle.
+	OversizedMessageChunk OversizedMessagePolicy = "chunk"
+)
+
+// chunkBudgetRatio leaves headroom for the elision marker and for the
+// imprecision of mapping a token budget onto a byte offset.
+const chunkBudgetRatio = 0.9
+
+// WithMessageTokenLimit caps the size of any single user input or tool result
+// added to the conversation. A limit of 0 disables the guard. An unrecognized
+// policy is treated as OversizedMessageChunk.
+func WithMessageTokenLimit(limit int, policy OversizedMessagePolicy) AgentOption {
+	return func(a *DefaultAgent) {
+		if limit < 0 {
+			limit = 0
+		}
+		if policy != OversizedMessageReject {
+			policy = OversizedMessageChunk
+		}
+		a.messageTokenLimit = limit
+		a.oversizedPolicy = policy
+	}
+}
+
+// countTokens counts tokens in text using the tokenizer when available, or a
+// character-based approximation otherwise.
+func (a *DefaultAgent) countTokens(text string) int {
+	if a.tokenizer != nil {
+		return a.tokenizer.CountTokens(text)
+	}
+	return len(text) / 4
+}
+
+// guardUserInput applies the per-message ceiling to user input. It returns the
+// content to add to memory and false when the input was rejected.
+func (a *DefaultAgent) guardUserInput(content string) (string, bool) {
+	if a.messageTokenLimit <= 0 {
+		return content, true
+	}
+	tokens := a.countTokens(content)
+	if tokens <= a.messageTokenLimit {
+		return content, true
+	}
+
+	info := types.OversizedMessage{
+		Source: types.OversizedSourceUserInput,
+		Tokens: tokens,
+		Limit:  a.messageTokenLimit,
+	}
+
+	if a.oversizedPolicy == OversizedMessageReject {
+		info.Action = types.OversizedActionRejected
+		a.emitEvent(types.NewOversizedMessageEvent(info))
+		a.emitEvent(types.NewErrorEvent(fmt.Errorf(
+			"message not sent: it is about %d tokens, over the %d-token per-message limit. "+
+				"Trim it, or save it to a file in the workspace and ask the agent to read the relevant parts",
+			tokens, a.messageTokenLimit)))
+		return "", false
+	}
+
+	chunked := a.chunkContent(content, tokens)
+	info.Action = types.OversizedActionChunked
+	info.KeptTokens = a.countTokens(chunked)
+	a.emitEvent(types.NewOversizedMessageEvent(info))
+	return chunked, true
+}
+
+// guardToolResult applies the per-message ceiling to a tool result before it is
+// added to memory. Rejected results are replaced with guidance for the agent.
+func (a *DefaultAgent) guardToolResult(toolName, result string) string {
+	if a.messageTokenLimit <= 0 {
+		return result
+	}
+	tokens := a.countTokens(result)
+	if tokens <= a.messageTokenLimit {
+		return result
+	}
+
+	info := types.OversizedMessage{
+		Source:   types.OversizedSourceToolResult,
+		ToolName: toolName,
+		Tokens:   tokens,
+		Limit:    a.messageTokenLimit,
+	}
+
+	var guarded string
+	if a.oversizedPolicy == OversizedMessageReject {
+		info.Action = types.OversizedActionRejected
+		guarded = fmt.Sprintf(
+			"The result was discarded because it is about %d tokens, over the %d-token per-message limit. "+
+				"Narrow the request instead: read specific line ranges, search for the relevant lines, "+
+				"or filter command output (e.g. with grep, head or tail).",
+			tokens, a.messageTokenLimit)
+	} else {
+		info.Action = types.OversizedActionChunked
+		guarded = a.chunkContent(result, tokens)
+	}
+
+	info.KeptTokens = a.countTokens(guarded)
+	a.emitEvent(types.NewOversizedMessageEvent(info))
+	return guarded
+}
+
+// chunkContent keeps the head and tail of content that fit within the token
+// ceiling, cutting on line boundaries where possible, and replaces the middle
+// with a marker saying how much was omitted.
+func (a *DefaultAgent) chunkContent(content string, tokens int) string {
+	budget := int(float64(len(content)) * float64(a.messageTokenLimit) * chunkBudgetRatio / float64(tokens))
+	half := budget / 2
+
+	head := content[:snapHeadCut(content, half)]
+	tail := content[snapTailCut(content, len(content)-half):]
+
+	omitted := tokens - a.countTokens(head) - a.countTokens(tail)
+	if omitted < 0 {
+		omitted = 0
+	}
+
+	return fmt.Sprintf("%s\n\n[... about %d tokens omitted: the message exceeded the %d-token per-message limit ...]\n\n%s",
+		strings.TrimRight(head, "\n"), omitted, a.messageTokenLimit, strings.TrimLeft(tail, "\n"))
+}
+
+// snapHeadCut moves a cut offset back to the end of the last complete line
+// before it, or to a rune boundary when that would discard more than half of
+// the head.
+func snapHeadCut(content string, cut int) int {
+	if cut <= 0 {
+		return 0
+	}
+	if idx := strings.LastIndexByte(content[:cut], '\n'); idx >= cut/2 {
+		return idx + 1
+	}
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return cut
+}
+
+// snapTailCut moves a cut offset forward to the start of the next complete
+// line, or to a rune boundary when that would discard more than half of the
+// tail.
+func snapTailCut(content string, cut int) int {
+	if cut >= len(content) {
+		return len(content)
+	}
+	if idx := strings.IndexByte(content[cut:], '\n'); idx >= 0 && idx < (len(content)-cut)/2 {
+		return cut + idx + 1
+	}
+	for cut < len(content) && !utf8.RuneStart(content[cut]) {
+		cut++
+	}
+	return cut
+}
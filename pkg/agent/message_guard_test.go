@@ -0,0 +1,195 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func newGuardedAgent(limit int, policy OversizedMessagePolicy) *DefaultAgent {
+	a := &DefaultAgent{channels: types.NewAgentChannels(10)}
+	WithMessageTokenLimit(limit, policy)(a)
+	return a
+}
+
+func nextOversizedEvent(t *testing.T, a *DefaultAgent) *types.OversizedMessage {
+	t.Helper()
+	select {
+	case event := <-a.channels.Event:
+		if event.Type != types.EventTypeOversizedMessage || event.OversizedMessage == nil {
+			t.Fatalf("expected oversized message event, got %s", event.Type)
+		}
+		return event.OversizedMessage
+	default:
+		t.Fatal("expected an oversized message event")
+		return nil
+	}
+}
+
+func largeLog(lines int) string {
+	var b strings.Builder
+	for i := range lines {
+		fmt.Fprintf(&b, "line %05d: héllo wörld, something happened here\n", i)
+	}
+	return b.String()
+}
+
+func TestGuardToolResult_UnderLimit(t *testing.T) {
+	a := newGuardedAgent(1000, OversizedMessageChunk)
+	result := a.guardToolResult("read_file", "small result")
+	if result != "small result" {
+		t.Errorf("expected result unchanged, got %q", result)
+	}
+	if len(a.channels.Event) != 0 {
+		t.Error("expected no event for a result under the limit")
+	}
+}
+
+func TestGuardToolResult_Disabled(t *testing.T) {
+	a := newGuardedAgent(0, OversizedMessageReject)
+	content := largeLog(2000)
+	if got := a.guardToolResult("read_file", content); got != content {
+		t.Error("expected result unchanged when the guard is disabled")
+	}
+}
+
+func TestGuardToolResult_Chunk(t *testing.T) {
+	a := newGuardedAgent(1000, OversizedMessageChunk)
+	content := largeLog(2000)
+
+	result := a.guardToolResult("execute_command", content)
+
+	info := nextOversizedEvent(t, a)
+	if info.Action != types.OversizedActionChunked || info.Source != types.OversizedSourceToolResult {
+		t.Errorf("unexpected event: %+v", info)
+	}
+	if info.ToolName != "execute_command" {
+		t.Errorf("expected tool name in event, got %q", info.ToolName)
+	}
+	if info.KeptTokens > info.Limit {
+		t.Errorf("kept %d tokens, over the %d limit", info.KeptTokens, info.Limit)
+	}
+
+	if !strings.HasPrefix(result, "line 00000:") {
+		t.Error("expected the head of the result to be kept")
+	}
+	if !strings.HasSuffix(result, "line 01999: héllo wörld, something happened here\n") {
+		t.Error("expected the tail of the result to be kept on a line boundary")
+	}
+	if !strings.Contains(result, "tokens omitted") {
+		t.Error("expected an omission marker")
+	}
+	if !utf8.ValidString(result) {
+		t.Error("chunked result is not valid UTF-8")
+	}
+}
+
+func TestGuardToolResult_ChunkSingleLine(t *testing.T) {
+	a := newGuardedAgent(100, OversizedMessageChunk)
+	content := strings.Repeat("ü", 5000)
+
+	result := a.guardToolResult("read_file", content)
+	nextOversizedEvent(t, a)
+
+	if !utf8.ValidString(result) {
+		t.Error("chunked result is not valid UTF-8")
+	}
+	if len(result) >= len(content) {
+		t.Error("expected the result to be shortened")
+	}
+}
+
+func TestGuardToolResult_Reject(t *testing.T) {
+	a := newGuardedAgent(1000, OversizedMessageReject)
+
+	result := a.guardToolResult("read_file", largeLog(2000))
+
+	info := nextOversizedEvent(t, a)
+	if info.Action != types.OversizedActionRejected {
+		t.Errorf("expected rejected action, got %q", info.Action)
+	}
+	if strings.Contains(result, "line 00000") {
+		t.Error("expected the original result to be discarded")
+	}
+	if !strings.Contains(result, "Narrow the request") {
+		t.Errorf("expected guidance for the agent, got %q", result)
+	}
+}
+
+func TestGuardUserInput(t *testing.T) {
+	t.Run("Reject", func(t *testing.T) {
+		a := newGuardedAgent(1000, OversizedMessageReject)
+
+		_, ok := a.guardUserInput(largeLog(2000))
+		if ok {
+			t.Fatal("expected oversized input to be rejected")
+		}
+
+		info := nextOversizedEvent(t, a)
+		if info.Source != types.OversizedSourceUserInput || info.Action != types.OversizedActionRejected {
+			t.Errorf("unexpected event: %+v", info)
+		}
+		if event := <-a.channels.Event; event.Type != types.EventTypeError {
+			t.Errorf("expected a follow-up error event explaining the rejection, got %s", event.Type)
+		}
+	})
+
+	t.Run("Chunk", func(t *testing.T) {
+		a := newGuardedAgent(1000, OversizedMessageChunk)
+		content := largeLog(2000)
+
+		chunked, ok := a.guardUserInput(content)
+		if !ok {
+			t.Fatal("expected oversized input to be chunked, not rejected")
+		}
+		if len(chunked) >= len(content) {
+			t.Error("expected the input to be shortened")
+		}
+		nextOversizedEvent(t, a)
+	})
+
+	t.Run("UnknownPolicyChunks", func(t *testing.T) {
+		a := newGuardedAgent(1000, OversizedMessagePolicy("truncate"))
+		if _, ok := a.guardUserInput(largeLog(2000)); !ok {
+			t.Error("expected unknown policy to fall back to chunking")
+		}
+	})
+}
+
+func TestProcessUserInput_RejectedEventsShareTurnID(t *testing.T) {
+	a := newGuardedAgent(1000, OversizedMessageReject)
+
+	a.processUserInput(context.Background(), largeLog(2000))
+
+	var events []*types.AgentEvent
+	for len(a.channels.Event) > 0 {
+		events = append(events, <-a.channels.Event)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected oversized, error and turn end events, got %d", len(events))
+	}
+	if events[2].Type != types.EventTypeTurnEnd {
+		t.Errorf("expected the last event to be turn end, got %s", events[2].Type)
+	}
+
+	turnID := events[0].TurnID
+	if turnID == "" {
+		t.Fatal("expected events to carry a turn ID")
+	}
+	for _, event := range events {
+		if event.TurnID != turnID {
+			t.Errorf("%s event has turn ID %q, want %q", event.Type, event.TurnID, turnID)
+		}
+		if event.Timestamp.IsZero() {
+			t.Errorf("%s event has no timestamp", event.Type)
+		}
+	}
+
+	if a.currentTurnID != "" {
+		t.Error("expected the turn ID to be cleared after the turn")
+	}
+}
@@ -37,6 +37,7 @@ func (a *DefaultAgent) handleNotesRequest(input *types.Input) {
 			ID:        note.ID,
 			Content:   note.Content,
 			Tags:      note.Tags,
+			Category:  note.Category(),
 			Scratched: note.Scratched,
 			CreatedAt: note.CreatedAt.Format("2006-01-02 15:04:05"),
 			UpdatedAt: note.UpdatedAt.Format("2006-01-02 15:04:05"),
@@ -0,0 +1,19 @@
+package agent
+
+import "github.com/entrhq/forge/pkg/types"
+
+// EventObserver is told about every event the agent emits, before it is
+// delivered on the event channel, so events can be streamed elsewhere as
+// they happen. ObserveEvent must not block and must be safe for concurrent
+// use; the event must not be modified.
+type EventObserver interface {
+	ObserveEvent(event *types.AgentEvent)
+}
+
+// WithEventObserver adds an observer of the agent's events. An agent may
+// have several.
+func WithEventObserver(observer EventObserver) AgentOption {
+	return func(a *DefaultAgent) {
+		a.eventObservers = append(a.eventObservers, observer)
+	}
+}
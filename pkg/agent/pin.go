@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// PinLastUserMessage pins the most recent message the user sent, typically
+// the task and its acceptance criteria, so summarization keeps it verbatim.
+// It returns the pinned content.
+func (a *DefaultAgent) PinLastUserMessage() (string, error) {
+	if a.lastUserMessage == nil || !slices.Contains(a.memory.GetAll(), a.lastUserMessage) {
+		return "", fmt.Errorf("there is no user message in the context to pin")
+	}
+	a.lastUserMessage.WithMetadata(types.MetadataPinned, true)
+	return a.lastUserMessage.Content, nil
+}
+
+// PinContent adds content, such as a file or a spec, to the conversation as a
+// pinned message that summarization keeps verbatim. Call it between turns.
+func (a *DefaultAgent) PinContent(label, content string) {
+	text := content
+	if label != "" {
+		text = fmt.Sprintf("Pinned context (%s):\n\n%s", label, content)
+	}
+	a.memory.Add(types.NewUserMessage(text).WithMetadata(types.MetadataPinned, true))
+}
+
+// Unpin removes every pin, letting summarization reduce the pinned messages
+// again. It returns the number of messages unpinned.
+func (a *DefaultAgent) Unpin() (int, error) {
+	convMem, ok := a.memory.(*memory.ConversationMemory)
+	if !ok {
+		return 0, fmt.Errorf("memory type %T does not support pinning", a.memory)
+	}
+	return convMem.Unpin(), nil
+}
+
+// handlePinRequest pins or unpins context on behalf of the /pin and /unpin
+// commands.
+func (a *DefaultAgent) handlePinRequest(input *types.Input) {
+	params, _ := input.Metadata["params"].(types.PinRequestParams)
+
+	switch {
+	case params.Unpin:
+		n, err := a.Unpin()
+		if err != nil {
+			a.emitEvent(types.NewErrorEvent(err))
+			return
+		}
+		agentDebugLog.Printf("Unpinned %d message(s)", n)
+	case params.Content != "":
+		a.PinContent(params.Label, params.Content)
+		agentDebugLog.Printf("Pinned %s", params.Label)
+	default:
+		if _, err := a.PinLastUserMessage(); err != nil {
+			a.emitEvent(types.NewErrorEvent(err))
+		}
+	}
+}
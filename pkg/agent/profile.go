@@ -0,0 +1,21 @@
+package agent
+
+import "github.com/entrhq/forge/pkg/config"
+
+// SetProfile switches the agent to a working mode: from the next LLM call
+// the profile's instructions are added to the system prompt and the tools it
+// hides are neither offered nor run. Pass nil to return to the default mode.
+// The profile's model is applied separately, with SetProvider. It is safe to
+// call while the agent is running.
+func (a *DefaultAgent) SetProfile(profile *config.Profile) {
+	a.profileMu.Lock()
+	defer a.profileMu.Unlock()
+	a.profile = profile
+}
+
+// GetProfile returns the active profile, or nil in the default mode.
+func (a *DefaultAgent) GetProfile() *config.Profile {
+	a.profileMu.RLock()
+	defer a.profileMu.RUnlock()
+	return a.profile
+}
@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/config"
+)
+
+func TestSetProfile_FiltersToolsAndAddsInstructions(t *testing.T) {
+	a := &DefaultAgent{tools: make(map[string]tools.Tool), customInstructions: "base"}
+	for _, name := range []string{"read_file", "write_file", "execute_command", "task_completion"} {
+		a.tools[name] = &mockRegularTool{name: name}
+	}
+
+	a.SetProfile(&config.Profile{
+		Name:               "reviewer",
+		Constraints:        []string{"read-only"},
+		DisabledTools:      []string{"execute_command"},
+		CustomInstructions: "Report bugs first.",
+	})
+
+	var names []string
+	for _, tool := range a.getToolsList() {
+		names = append(names, tool.Name())
+	}
+	if len(names) != 2 || strings.Contains(strings.Join(names, ","), "write_file") {
+		t.Errorf("visible tools = %v, want read_file and task_completion", names)
+	}
+	if _, ok := a.getTool("write_file"); ok {
+		t.Error("expected a tool hidden by the profile not to be found")
+	}
+
+	prompt := a.buildSystemPrompt()
+	if !strings.Contains(prompt, "base\n\n# Profile: reviewer") || !strings.Contains(prompt, "Report bugs first.") {
+		t.Errorf("expected the profile instructions after the custom instructions, got:\n%s", prompt)
+	}
+
+	a.SetProfile(nil)
+	if _, ok := a.getTool("write_file"); !ok {
+		t.Error("expected clearing the profile to restore its tools")
+	}
+	if strings.Contains(a.buildSystemPrompt(), "# Profile") {
+		t.Error("expected clearing the profile to remove its instructions")
+	}
+}
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/projectmemory"
+)
+
+// learningExtractionTimeout bounds the model call that extracts the
+// learnings of a completed task
+const learningExtractionTimeout = 2 * time.Minute
+
+// WithProjectMemory loads the facts in mem into the system prompt and
+// registers the remember tool. With an extractor, the conversation is also
+// mined for new facts in the background whenever the agent completes a task.
+// Facts added during a session reach the system prompt in the next one, so
+// the prompt stays stable within a session.
+func WithProjectMemory(mem *projectmemory.Memory, extractor *projectmemory.Extractor) AgentOption {
+	return func(a *DefaultAgent) {
+		a.projectMemory = mem
+		a.projectMemoryContext = mem.Context()
+		a.learningExtractor = extractor
+	}
+}
+
+// extractLearnings starts extracting the learnings of the task just
+// completed, if an extractor is configured. Shutdown waits for it.
+func (a *DefaultAgent) extractLearnings() {
+	if a.learningExtractor == nil {
+		return
+	}
+
+	messages := a.memory.GetAll()
+	a.learnings.Add(1)
+	go func() {
+		defer a.learnings.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), learningExtractionTimeout)
+		defer cancel()
+
+		added, err := a.learningExtractor.Extract(ctx, messages)
+		if err != nil {
+			agentDebugLog.Printf("Learning extraction failed: %v", err)
+			return
+		}
+		if len(added) > 0 {
+			agentDebugLog.Printf("Recorded %d learning(s) in %s: %v", len(added), projectmemory.FileName, added)
+		}
+	}()
+}
+
+// waitForLearnings waits for running extractions until ctx is done.
+func (a *DefaultAgent) waitForLearnings(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.learnings.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,155 @@
+package projectmemory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+const (
+	// maxMessageChars keeps long tool output from crowding out the rest of
+	// the conversation in the extraction prompt
+	maxMessageChars = 2000
+
+	// maxTranscriptChars bounds the conversation sent for extraction; the
+	// end of the task is kept
+	maxTranscriptChars = 40000
+)
+
+// extractionSystemPrompt instructs the model that extracts learnings
+const extractionSystemPrompt = `You maintain the project memory of an AI coding assistant: a short list of durable facts about one code repository that is loaded into every future session.
+
+Read the conversation of a task that was just completed and pick out facts a future session would otherwise have to rediscover, for example:
+- build: build or test quirks, required environment, setup steps that turned out to be needed
+- commands: exact command incantations that worked, especially after ones that did not
+- architecture: where important things live and how the pieces fit together
+- conventions: patterns the code follows that are not obvious from a single file
+
+Only record a fact when all of these hold:
+- It was established in the conversation, not guessed
+- It will still be true for future tasks, not just this one
+- It is not already in the existing memory, even in other words
+- It contains no secrets, credentials, tokens or personal data
+
+Most tasks teach nothing worth recording; then return []. Never return more than 5 facts.
+
+Return a JSON array of objects with "category" (build, commands, architecture, conventions or general) and "fact" (one self-contained sentence). Example:
+[{"category": "commands", "fact": "Run the web tests with ` + "`pnpm --filter web test`" + `; ` + "`npm test`" + ` at the root runs nothing."}]`
+
+// maxExtractedFacts bounds the facts recorded for one task
+const maxExtractedFacts = 5
+
+// extractedFact is one learning returned by the extraction model
+type extractedFact struct {
+	Category string `json:"category"`
+	Fact     string `json:"fact"`
+}
+
+// Extractor asks a model for the learnings of a completed task and adds
+// them to the memory file.
+type Extractor struct {
+	provider llm.Provider
+	memory   *Memory
+}
+
+// NewExtractor creates an Extractor that calls provider and records what it
+// finds in memory.
+func NewExtractor(provider llm.Provider, memory *Memory) *Extractor {
+	return &Extractor{provider: provider, memory: memory}
+}
+
+// Extract sends the conversation and the current memory to the model and
+// adds the new facts it returns, reporting the ones added. A response that
+// is not a JSON array is treated as having no facts.
+func (e *Extractor) Extract(ctx context.Context, messages []*types.Message) ([]string, error) {
+	transcript := formatTranscript(messages)
+	if transcript == "" {
+		return nil, nil
+	}
+
+	existing, err := e.memory.Facts()
+	if err != nil {
+		return nil, err
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("EXISTING MEMORY\n")
+	if len(existing) == 0 {
+		prompt.WriteString("(empty)\n")
+	}
+	for _, fact := range existing {
+		fmt.Fprintf(&prompt, "- %s\n", fact)
+	}
+	prompt.WriteString("\nCONVERSATION\n")
+	prompt.WriteString(transcript)
+	prompt.WriteString("\n\nReturn the JSON array of new facts, or [] if there are none.")
+
+	response, err := e.provider.Complete(ctx, []*types.Message{
+		types.NewSystemMessage(extractionSystemPrompt),
+		types.NewUserMessage(prompt.String()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("learning extraction failed: %w", err)
+	}
+
+	var facts []extractedFact
+	if err := json.Unmarshal([]byte(stripCodeFences(response.Content)), &facts); err != nil {
+		return nil, nil
+	}
+
+	var added []string
+	for _, fact := range facts[:min(len(facts), maxExtractedFacts)] {
+		category, err := ParseCategory(fact.Category)
+		if err != nil {
+			category = CategoryGeneral
+		}
+		ok, err := e.memory.Add(category, fact.Fact)
+		if err != nil || !ok {
+			continue
+		}
+		added = append(added, normalizeFact(fact.Fact))
+	}
+	return added, nil
+}
+
+// formatTranscript renders the conversation for the extraction prompt, each
+// message cut to maxMessageChars and the whole to its last maxTranscriptChars.
+// Tool results are kept, since that is where build quirks and working
+// commands show up.
+func formatTranscript(messages []*types.Message) string {
+	var parts []string
+	for _, msg := range messages {
+		if msg.Role == types.RoleSystem {
+			continue
+		}
+		content := strings.TrimSpace(msg.Content)
+		if content == "" {
+			continue
+		}
+		if len(content) > maxMessageChars {
+			content = content[:maxMessageChars] + " […]"
+		}
+		parts = append(parts, fmt.Sprintf("[%s]: %s", msg.Role, content))
+	}
+
+	transcript := strings.Join(parts, "\n\n")
+	if len(transcript) > maxTranscriptChars {
+		transcript = "[…]\n" + transcript[len(transcript)-maxTranscriptChars:]
+	}
+	return transcript
+}
+
+// stripCodeFences removes a Markdown code fence wrapped around a response
+func stripCodeFences(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "```") {
+		if i := strings.Index(s, "\n"); i != -1 {
+			s = s[i+1:]
+		}
+	}
+	return strings.TrimSpace(strings.TrimSuffix(s, "```"))
+}
@@ -0,0 +1,269 @@
+// Package projectmemory keeps durable facts about a project, such as build
+// quirks, command incantations and architecture notes, in .forge/memory.md.
+// The agent adds to the file with the remember tool and when it completes a
+// task, and the file is loaded into the system prompt of later sessions.
+// It is plain Markdown, meant to be read, edited and committed like AGENTS.md.
+package projectmemory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileName is the workspace-relative path of the memory file
+const FileName = ".forge/memory.md"
+
+// DefaultMaxTokens is the memory budget in the system prompt when none is configured
+const DefaultMaxTokens = 2000
+
+// maxFactLength bounds a single fact, which should be a sentence or two
+const maxFactLength = 500
+
+// Category groups facts under a heading in the memory file.
+type Category string
+
+const (
+	CategoryBuild        Category = "build"        // Building, testing and environment quirks
+	CategoryCommands     Category = "commands"     // Command incantations that work
+	CategoryArchitecture Category = "architecture" // Where things live and how they fit together
+	CategoryConventions  Category = "conventions"  // Patterns the code follows
+	CategoryGeneral      Category = "general"      // Anything else
+)
+
+// Categories lists the categories in the order their sections are added.
+var Categories = []Category{CategoryBuild, CategoryCommands, CategoryArchitecture, CategoryConventions, CategoryGeneral}
+
+// heading returns the section heading of the category.
+func (c Category) heading() string {
+	return "## " + strings.ToUpper(string(c[:1])) + string(c[1:])
+}
+
+// ParseCategory returns the category named s, case-insensitively. An empty
+// name is CategoryGeneral.
+func ParseCategory(s string) (Category, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return CategoryGeneral, nil
+	}
+	for _, category := range Categories {
+		if string(category) == s {
+			return category, nil
+		}
+	}
+	return "", fmt.Errorf("invalid category: %s (must be 'build', 'commands', 'architecture', 'conventions' or 'general')", s)
+}
+
+// fileHeader starts a new memory file
+const fileHeader = "# Project Memory\n\n" +
+	"Durable facts about this project, recorded by Forge and loaded into every session. Edit or remove entries freely.\n"
+
+// Options configures a Memory.
+type Options struct {
+	// MaxTokens bounds the memory in the system prompt. 0 uses DefaultMaxTokens.
+	MaxTokens int
+	// CountTokens counts the tokens in a string. nil estimates four bytes
+	// per token.
+	CountTokens func(string) int
+}
+
+// Memory reads and appends to a workspace's memory file. It is safe for
+// concurrent use.
+type Memory struct {
+	mu   sync.Mutex
+	path string
+	opts Options
+}
+
+// Open returns the memory of workspaceDir. The file need not exist yet; it
+// is created by the first fact added.
+func Open(workspaceDir string, opts Options) (*Memory, error) {
+	root, err := filepath.Abs(workspaceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace directory: %w", err)
+	}
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = DefaultMaxTokens
+	}
+	if opts.CountTokens == nil {
+		opts.CountTokens = estimateTokens
+	}
+	return &Memory{path: filepath.Join(root, filepath.FromSlash(FileName)), opts: opts}, nil
+}
+
+// Path returns the absolute path of the memory file.
+func (m *Memory) Path() string {
+	return m.path
+}
+
+// Facts returns the facts in the file, in file order. A missing file has none.
+func (m *Memory) Facts() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	content, err := m.read()
+	if err != nil {
+		return nil, err
+	}
+
+	var facts []string
+	for line := range strings.SplitSeq(content, "\n") {
+		if fact, ok := strings.CutPrefix(strings.TrimSpace(line), "- "); ok && strings.TrimSpace(fact) != "" {
+			facts = append(facts, strings.TrimSpace(fact))
+		}
+	}
+	return facts, nil
+}
+
+// Add appends fact to the category's section, creating the file and the
+// section as needed. It reports false without writing when the file already
+// has the fact.
+func (m *Memory) Add(category Category, fact string) (bool, error) {
+	fact = normalizeFact(fact)
+	if fact == "" {
+		return false, fmt.Errorf("fact is empty")
+	}
+	if len(fact) > maxFactLength {
+		return false, fmt.Errorf("fact is %d characters; keep it under %d, one or two sentences", len(fact), maxFactLength)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	content, err := m.read()
+	if err != nil {
+		return false, err
+	}
+	if content == "" {
+		content = fileHeader
+	}
+
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	key := strings.ToLower(fact)
+	for _, line := range lines {
+		if existing, ok := strings.CutPrefix(strings.TrimSpace(line), "- "); ok && strings.ToLower(normalizeFact(existing)) == key {
+			return false, nil
+		}
+	}
+
+	lines = insertFact(lines, category.heading(), "- "+fact)
+	return true, m.write(strings.Join(lines, "\n") + "\n")
+}
+
+// insertFact adds bullet after the last entry of the section under heading,
+// or in a new section at the end of the file.
+func insertFact(lines []string, heading, bullet string) []string {
+	start := -1
+	for i, line := range lines {
+		if strings.EqualFold(strings.TrimSpace(line), heading) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return append(lines, "", heading, "", bullet)
+	}
+
+	// The section ends at the next heading; insert after its last non-blank line
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "#") {
+			end = i
+			break
+		}
+	}
+	at := start + 1
+	for i := end - 1; i > start; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			at = i + 1
+			break
+		}
+	}
+	insert := []string{bullet}
+	if at == start+1 {
+		insert = []string{"", bullet}
+	}
+	return append(lines[:at], append(insert, lines[at:]...)...)
+}
+
+// Context renders the memory file for the system prompt, cut down to the
+// token budget on a line boundary. It returns "" when there is no file or it
+// is empty.
+func (m *Memory) Context() string {
+	m.mu.Lock()
+	content, err := m.read()
+	m.mu.Unlock()
+	content = strings.TrimSpace(content)
+	if err != nil || content == "" {
+		return ""
+	}
+
+	tokens := m.opts.CountTokens(content)
+	if tokens <= m.opts.MaxTokens {
+		return content
+	}
+	cut := len(content) * m.opts.MaxTokens / tokens
+	if i := strings.LastIndexByte(content[:cut], '\n'); i > 0 {
+		cut = i
+	}
+	return content[:cut] + fmt.Sprintf("\n\n[%s truncated to fit the %d-token memory budget; read the file for the rest]", FileName, m.opts.MaxTokens)
+}
+
+// read returns the file's content, or "" when it does not exist.
+func (m *Memory) read() (string, error) {
+	data, err := os.ReadFile(m.path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	return string(data), nil
+}
+
+// write replaces the file's content through a temporary file, so a crash
+// never leaves it half written. An edited file keeps its permissions.
+func (m *Memory) write(content string) error {
+	perm := os.FileMode(0600)
+	if info, err := os.Stat(m.path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(m.path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, ".memory-*.md")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	if _, err := tmp.WriteString(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	if err := os.Rename(tmp.Name(), m.path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	return nil
+}
+
+// normalizeFact puts a fact on one line without a leading bullet.
+func normalizeFact(fact string) string {
+	fact = strings.Join(strings.Fields(fact), " ")
+	fact = strings.TrimPrefix(fact, "- ")
+	return strings.TrimSpace(fact)
+}
+
+// estimateTokens approximates a token count at four bytes per token
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
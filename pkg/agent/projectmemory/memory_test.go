@@ -0,0 +1,212 @@
+package projectmemory
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func openMemory(t *testing.T, opts Options) *Memory {
+	t.Helper()
+	mem, err := Open(t.TempDir(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mem
+}
+
+func TestMemory_Add(t *testing.T) {
+	mem := openMemory(t, Options{})
+
+	if got := mem.Context(); got != "" {
+		t.Errorf("Context() = %q, want empty before the file exists", got)
+	}
+
+	for _, add := range []struct {
+		category Category
+		fact     string
+	}{
+		{CategoryBuild, "Integration tests need `make db-up` first."},
+		{CategoryCommands, "Run the linter with `make lint`."},
+		{CategoryBuild, "CGO must be disabled for release builds."},
+	} {
+		added, err := mem.Add(add.category, add.fact)
+		if err != nil || !added {
+			t.Fatalf("Add(%q) = %v, %v", add.fact, added, err)
+		}
+	}
+
+	added, err := mem.Add(CategoryGeneral, "- integration tests need `make db-up`   first.")
+	if err != nil || added {
+		t.Errorf("Add() of a duplicate = %v, %v, want false", added, err)
+	}
+
+	data, err := os.ReadFile(mem.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fileHeader + `
+## Build
+
+- Integration tests need ` + "`make db-up`" + ` first.
+- CGO must be disabled for release builds.
+
+## Commands
+
+- Run the linter with ` + "`make lint`" + `.
+`
+	if string(data) != want {
+		t.Errorf("file =\n%s\nwant\n%s", data, want)
+	}
+
+	facts, err := mem.Facts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facts) != 3 || facts[1] != "CGO must be disabled for release builds." {
+		t.Errorf("Facts() = %q", facts)
+	}
+}
+
+func TestMemory_AddRejectsBadFacts(t *testing.T) {
+	mem := openMemory(t, Options{})
+
+	if _, err := mem.Add(CategoryGeneral, "  \n "); err == nil {
+		t.Error("Add() of an empty fact should fail")
+	}
+	if _, err := mem.Add(CategoryGeneral, strings.Repeat("x", maxFactLength+1)); err == nil {
+		t.Error("Add() of an overlong fact should fail")
+	}
+	if _, err := os.Stat(mem.Path()); !os.IsNotExist(err) {
+		t.Error("rejected facts should not create the file")
+	}
+}
+
+func TestMemory_ContextBudget(t *testing.T) {
+	mem := openMemory(t, Options{MaxTokens: 60})
+	for i := range 20 {
+		if _, err := mem.Add(CategoryGeneral, strings.Repeat("word ", 5)+string(rune('a'+i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := mem.Context()
+	if !strings.Contains(got, "truncated to fit the 60-token memory budget") {
+		t.Errorf("Context() should note the truncation, got:\n%s", got)
+	}
+	if body, _, _ := strings.Cut(got, "\n\n["); len(body) > 60*4 {
+		t.Errorf("Context() kept %d bytes, want at most %d", len(body), 60*4)
+	}
+}
+
+func TestParseCategory(t *testing.T) {
+	if got, err := ParseCategory(""); err != nil || got != CategoryGeneral {
+		t.Errorf("ParseCategory(\"\") = %q, %v", got, err)
+	}
+	if got, err := ParseCategory(" Build "); err != nil || got != CategoryBuild {
+		t.Errorf("ParseCategory(\" Build \") = %q, %v", got, err)
+	}
+	if _, err := ParseCategory("secrets"); err == nil {
+		t.Error("ParseCategory() should reject unknown categories")
+	}
+}
+
+func TestRememberTool_Execute(t *testing.T) {
+	mem := openMemory(t, Options{})
+	tool := NewRememberTool(mem)
+
+	args := []byte(`<arguments><fact>Migrations live in db/migrations.</fact><category>architecture</category></arguments>`)
+	_, metadata, err := tool.Execute(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata["added"] != true || metadata["category"] != "architecture" {
+		t.Errorf("metadata = %v", metadata)
+	}
+
+	result, metadata, err := tool.Execute(context.Background(), args)
+	if err != nil || metadata["added"] != false || !strings.Contains(result, "already") {
+		t.Errorf("second Execute() = %q, %v, %v", result, metadata, err)
+	}
+
+	if _, _, err := tool.Execute(context.Background(), []byte(`<arguments><fact>x</fact><category>nope</category></arguments>`)); err == nil {
+		t.Error("Execute() should reject an unknown category")
+	}
+}
+
+func TestExtractor_Extract(t *testing.T) {
+	mem := openMemory(t, Options{})
+	if _, err := mem.Add(CategoryCommands, "Run the tests with `go test ./...`."); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &fakeProvider{response: "```json\n[" +
+		`{"category": "build", "fact": "The proto package is generated by make proto."},` +
+		`{"category": "commands", "fact": "run the tests with ` + "`go test ./...`" + `."},` +
+		`{"category": "unknown", "fact": "The API server listens on port 8081."}` +
+		"]\n```"}
+	messages := []*types.Message{
+		types.NewSystemMessage("system prompt"),
+		types.NewUserMessage("Fix the build"),
+		types.NewAssistantMessage("Ran make proto and the build passed."),
+	}
+
+	added, err := NewExtractor(provider, mem).Extract(context.Background(), messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 2 {
+		t.Fatalf("Extract() added %q, want the two new facts", added)
+	}
+
+	prompt := provider.received[len(provider.received)-1].Content
+	if !strings.Contains(prompt, "- Run the tests with") || !strings.Contains(prompt, "[user]: Fix the build") {
+		t.Errorf("prompt should contain the existing memory and the conversation, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "system prompt") {
+		t.Error("prompt should leave out system messages")
+	}
+
+	facts, err := mem.Facts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facts) != 3 {
+		t.Errorf("Facts() = %q, want 3", facts)
+	}
+
+	provider.response = "Nothing worth remembering."
+	if added, err := NewExtractor(provider, mem).Extract(context.Background(), messages); err != nil || len(added) != 0 {
+		t.Errorf("Extract() of a non-JSON response = %q, %v, want nothing", added, err)
+	}
+}
+
+// fakeProvider returns a canned response and records the last request
+type fakeProvider struct {
+	response string
+	received []*types.Message
+}
+
+func (f *fakeProvider) Complete(_ context.Context, messages []*types.Message) (*types.Message, error) {
+	f.received = messages
+	return types.NewAssistantMessage(f.response), nil
+}
+
+func (f *fakeProvider) StreamCompletion(_ context.Context, _ []*types.Message) (<-chan *llm.StreamChunk, error) {
+	ch := make(chan *llm.StreamChunk)
+	close(ch)
+	return ch, nil
+}
+
+func (f *fakeProvider) AnalyzeDocument(_ context.Context, _ []byte, _ string, _ string) (string, error) {
+	return f.response, nil
+}
+
+func (f *fakeProvider) GetModelInfo() *types.ModelInfo { return &types.ModelInfo{Name: "fake"} }
+func (f *fakeProvider) GetModel() string               { return "fake" }
+func (f *fakeProvider) GetBaseURL() string             { return "" }
+func (f *fakeProvider) GetAPIKey() string              { return "" }
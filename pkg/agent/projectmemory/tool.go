@@ -0,0 +1,92 @@
+package projectmemory
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// RememberTool records a durable fact about the project in the memory file.
+type RememberTool struct {
+	memory *Memory
+}
+
+// NewRememberTool creates a new RememberTool.
+func NewRememberTool(memory *Memory) *RememberTool {
+	return &RememberTool{
+		memory: memory,
+	}
+}
+
+// Name returns the tool name.
+func (t *RememberTool) Name() string {
+	return "remember"
+}
+
+// Description returns the tool description.
+func (t *RememberTool) Description() string {
+	return "Record a durable fact about this project in " + FileName + " so future sessions start with it: a build or test quirk, a command incantation that works, " +
+		"where something lives, or a convention the code follows. Use it when you learn something that took effort to find out and will hold beyond the current task. " +
+		"State each fact in one self-contained sentence. Never record secrets, or details that only matter for the current task."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *RememberTool) Schema() map[string]any {
+	categories := make([]string, len(Categories))
+	for i, category := range Categories {
+		categories[i] = string(category)
+	}
+	return tools.BaseToolSchema(
+		map[string]any{
+			"fact": map[string]any{
+				"type":        "string",
+				"description": "The fact, in one self-contained sentence, e.g. \"Integration tests need `make db-up` first; they use the postgres container on port 5433.\"",
+			},
+			"category": map[string]any{
+				"type":        "string",
+				"enum":        categories,
+				"description": "Section of the memory file the fact belongs in (default: general)",
+			},
+		},
+		[]string{"fact"},
+	)
+}
+
+// Execute adds the fact to the memory file.
+func (t *RememberTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName  xml.Name `xml:"arguments"`
+		Fact     string   `xml:"fact"`
+		Category string   `xml:"category"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	category, err := ParseCategory(input.Category)
+	if err != nil {
+		return "", nil, err
+	}
+
+	added, err := t.memory.Add(category, input.Fact)
+	if err != nil {
+		return "", nil, err
+	}
+
+	metadata := map[string]any{
+		"category": string(category),
+		"added":    added,
+	}
+	if !added {
+		return fmt.Sprintf("%s already has this fact.", FileName), metadata, nil
+	}
+	return fmt.Sprintf("Remembered in %s under %s. It will be in the system prompt of future sessions.", FileName, category), metadata, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *RememberTool) IsLoopBreaking() bool {
+	return false
+}
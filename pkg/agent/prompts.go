@@ -1,6 +1,8 @@
 package agent
 
 import (
+	"strings"
+
 	"github.com/entrhq/forge/pkg/agent/prompts"
 	"github.com/entrhq/forge/pkg/config"
 	customtools "github.com/entrhq/forge/pkg/tools/custom"
@@ -8,17 +10,33 @@ import (
 
 // buildSystemPrompt constructs the system prompt with tool schemas and custom instructions
 func (a *DefaultAgent) buildSystemPrompt() string {
+	exposedTools, toolManifest := a.getExposedTools()
 	builder := prompts.NewPromptBuilder().
-		WithTools(a.getToolsList())
+		WithTools(exposedTools).
+		WithToolManifest(toolManifest)
 
-	// Add user's custom instructions if provided
-	if a.customInstructions != "" {
-		builder.WithCustomInstructions(a.customInstructions)
+	// Tool schemas are sent as function definitions in native mode
+	if a.nativeToolCalls() {
+		builder.WithNativeToolCalls()
+	}
+
+	// Add user's custom instructions, followed by the active profile's
+	customInstructions := a.customInstructions
+	if profileInstructions := a.GetProfile().Instructions(); profileInstructions != "" {
+		customInstructions = strings.TrimSpace(customInstructions + "\n\n" + profileInstructions)
+	}
+	if customInstructions != "" {
+		builder.WithCustomInstructions(customInstructions)
 	}
 
 	// Add repository context if provided
-	if a.repositoryContext != "" {
-		builder.WithRepositoryContext(a.repositoryContext)
+	if repositoryContext := a.getRepositoryContext(); repositoryContext != "" {
+		builder.WithRepositoryContext(repositoryContext)
+	}
+
+	// Add facts remembered in earlier sessions
+	if a.projectMemoryContext != "" {
+		builder.WithProjectMemory(a.projectMemoryContext)
 	}
 
 	// Add available custom tools list
@@ -33,16 +51,42 @@ func (a *DefaultAgent) buildSystemPrompt() string {
 		builder.WithBrowserGuidance(browserGuidance)
 	}
 
+	// Add date, platform and git facts, refreshed each turn
+	builder.WithEnvironment(a.getEnvironment())
+
+	// Add live runtime state, refreshed on every build
+	if runtimeContext := a.getRuntimeContext(); runtimeContext != "" {
+		builder.WithRuntimeContext(runtimeContext)
+	}
+
 	return builder.Build()
 }
 
+// SetRuntimeContext replaces the function that reports live state in the
+// system prompt. It is safe to call while the agent is running; pass nil to
+// remove the section.
+func (a *DefaultAgent) SetRuntimeContext(fn func() string) {
+	a.runtimeContextMu.Lock()
+	defer a.runtimeContextMu.Unlock()
+	a.runtimeContext = fn
+}
+
+// getRuntimeContext returns the current runtime context section, if any
+func (a *DefaultAgent) getRuntimeContext() string {
+	a.runtimeContextMu.RLock()
+	fn := a.runtimeContext
+	a.runtimeContextMu.RUnlock()
+
+	if fn == nil {
+		return ""
+	}
+	return fn()
+}
+
 // getCustomToolsList builds a formatted list of available custom tools
 func (a *DefaultAgent) getCustomToolsList() string {
 	// Get the run_custom_tool instance
-	a.toolsMu.RLock()
-	tool, exists := a.tools["run_custom_tool"]
-	a.toolsMu.RUnlock()
-
+	tool, exists := a.getTool("run_custom_tool")
 	if !exists {
 		return ""
 	}
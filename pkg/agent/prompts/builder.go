@@ -12,8 +12,13 @@ type PromptBuilder struct {
 	tools              []tools.Tool
 	customInstructions string
 	repositoryContext  string
+	projectMemory      string
+	toolManifest       string
 	customToolsList    string
 	browserGuidance    string
+	environment        *EnvironmentFacts
+	runtimeContext     string
+	nativeToolCalls    bool
 }
 
 // NewPromptBuilder creates a new prompt builder with default settings
@@ -29,6 +34,13 @@ func (pb *PromptBuilder) WithTools(toolsList []tools.Tool) *PromptBuilder {
 	return pb
 }
 
+// WithToolManifest adds the formatted list of tool groups whose schemas are
+// not loaded
+func (pb *PromptBuilder) WithToolManifest(manifest string) *PromptBuilder {
+	pb.toolManifest = manifest
+	return pb
+}
+
 // WithCustomInstructions adds custom user-provided instructions
 // These are instructions from the end user, not the base system prompt
 func (pb *PromptBuilder) WithCustomInstructions(instructions string) *PromptBuilder {
@@ -43,6 +55,13 @@ func (pb *PromptBuilder) WithRepositoryContext(context string) *PromptBuilder {
 	return pb
 }
 
+// WithProjectMemory adds the facts about the project remembered in earlier
+// sessions, from .forge/memory.md
+func (pb *PromptBuilder) WithProjectMemory(memory string) *PromptBuilder {
+	pb.projectMemory = memory
+	return pb
+}
+
 // WithCustomToolsList adds the formatted list of available custom tools
 func (pb *PromptBuilder) WithCustomToolsList(customTools string) *PromptBuilder {
 	pb.customToolsList = customTools
@@ -55,6 +74,28 @@ func (pb *PromptBuilder) WithBrowserGuidance(guidance string) *PromptBuilder {
 	return pb
 }
 
+// WithEnvironment adds the current date, platform and git state. The facts
+// are refreshed once per turn, so they sit just before the runtime context.
+func (pb *PromptBuilder) WithEnvironment(facts EnvironmentFacts) *PromptBuilder {
+	pb.environment = &facts
+	return pb
+}
+
+// WithRuntimeContext adds live state, such as a remaining execution budget,
+// that changes between LLM calls
+func (pb *PromptBuilder) WithRuntimeContext(context string) *PromptBuilder {
+	pb.runtimeContext = context
+	return pb
+}
+
+// WithNativeToolCalls switches the tool calling instructions to the provider's
+// native function-calling API. Tool schemas are then sent as function
+// definitions, so they are left out of the prompt.
+func (pb *PromptBuilder) WithNativeToolCalls() *PromptBuilder {
+	pb.nativeToolCalls = true
+	return pb
+}
+
 // Build constructs the complete system prompt by assembling all sections
 func (pb *PromptBuilder) Build() string {
 	var builder strings.Builder
@@ -73,6 +114,15 @@ func (pb *PromptBuilder) Build() string {
 		builder.WriteString("\n</repository_context>\n\n")
 	}
 
+	// Add project memory if provided (from .forge/memory.md)
+	if pb.projectMemory != "" {
+		builder.WriteString("<project_memory>\n")
+		builder.WriteString(ProjectMemoryPreamble)
+		builder.WriteString("\n\n")
+		builder.WriteString(pb.projectMemory)
+		builder.WriteString("\n</project_memory>\n\n")
+	}
+
 	// Add system capabilities
 	builder.WriteString(SystemCapabilitiesPrompt)
 	builder.WriteString("\n\n")
@@ -86,16 +136,26 @@ func (pb *PromptBuilder) Build() string {
 	builder.WriteString("\n\n")
 
 	// Add tool calling instructions
-	builder.WriteString(ToolCallingPrompt)
+	if pb.nativeToolCalls {
+		builder.WriteString(NativeToolCallingPrompt)
+	} else {
+		builder.WriteString(ToolCallingPrompt)
+	}
 	builder.WriteString("\n\n")
 
 	// Add available tools section
-	if len(pb.tools) > 0 {
+	if len(pb.tools) > 0 && !pb.nativeToolCalls {
 		builder.WriteString("<available_tools>\n")
 		builder.WriteString(FormatToolSchemas(pb.tools))
 		builder.WriteString("</available_tools>\n\n")
 	}
 
+	// Add the tool groups that can be loaded on demand
+	if pb.toolManifest != "" {
+		builder.WriteString(pb.toolManifest)
+		builder.WriteString("\n\n")
+	}
+
 	// Add tool use rules
 	builder.WriteString(ToolUseRulesPrompt)
 	builder.WriteString("\n\n")
@@ -119,6 +179,21 @@ func (pb *PromptBuilder) Build() string {
 		builder.WriteString(pb.browserGuidance)
 	}
 
+	// Add environment facts, which change once per turn
+	if pb.environment != nil {
+		builder.WriteString("\n\n<environment>\n")
+		builder.WriteString(pb.environment.Format())
+		builder.WriteString("\n</environment>")
+	}
+
+	// Add runtime context last: it changes on every call, so keeping it at the
+	// end leaves the rest of the prompt stable
+	if pb.runtimeContext != "" {
+		builder.WriteString("\n\n<runtime_context>\n")
+		builder.WriteString(pb.runtimeContext)
+		builder.WriteString("\n</runtime_context>")
+	}
+
 	return builder.String()
 }
 
@@ -129,7 +204,9 @@ func (pb *PromptBuilder) Build() string {
 // boundary — just before the payload is sent to the provider.
 // The original pointer is reused when no copy is needed, avoiding allocations.
 func normalizeRoleForLLM(msg *types.Message) *types.Message {
-	if msg.Role != types.RoleTool {
+	// Results of native tool calls keep their role so the provider can pair
+	// them with the call they answer
+	if msg.Role != types.RoleTool || msg.ToolCallID != "" {
 		return msg
 	}
 	// Shallow-copy the message, remapping only the role.
@@ -0,0 +1,35 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EnvironmentFacts describes when and where the agent is running, so the
+// model does not have to guess the date or platform
+type EnvironmentFacts struct {
+	Now       time.Time
+	OS        string // runtime.GOOS
+	Arch      string // runtime.GOARCH
+	CPUs      int
+	GitBranch string // Empty outside a git repository
+	GitDirty  bool
+}
+
+// Format renders the facts as the body of the <environment> section
+func (f EnvironmentFacts) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "- Current date: %s\n", f.Now.Format("Monday, 2 January 2006"))
+	fmt.Fprintf(&b, "- Current time: %s\n", f.Now.Format("15:04 MST (UTC-07:00)"))
+	fmt.Fprintf(&b, "- Operating system: %s/%s\n", f.OS, f.Arch)
+	fmt.Fprintf(&b, "- CPU cores: %d", f.CPUs)
+	if f.GitBranch != "" {
+		state := "clean"
+		if f.GitDirty {
+			state = "uncommitted changes"
+		}
+		fmt.Fprintf(&b, "\n- Git branch: %s (%s)", f.GitBranch, state)
+	}
+	return b.String()
+}
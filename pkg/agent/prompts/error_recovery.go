@@ -16,6 +16,10 @@ const (
 	ErrorTypeMissingToolName ErrorRecoveryType = "missing_tool_name"
 	ErrorTypeUnknownTool     ErrorRecoveryType = "unknown_tool"
 	ErrorTypeToolExecution   ErrorRecoveryType = "tool_execution"
+
+	// Native function-calling counterparts of ErrorTypeNoToolCall and ErrorTypeInvalidXML
+	ErrorTypeNoFunctionCall   ErrorRecoveryType = "no_function_call"
+	ErrorTypeInvalidArguments ErrorRecoveryType = "invalid_arguments"
 )
 
 // ErrorRecoveryContext contains data needed to build error recovery messages
@@ -41,6 +45,10 @@ func BuildErrorRecoveryMessage(ctx ErrorRecoveryContext) string {
 		return buildUnknownToolError(ctx.ToolName, ctx.AvailableTools)
 	case ErrorTypeToolExecution:
 		return buildToolExecutionError(ctx.ToolName, ctx.Error)
+	case ErrorTypeNoFunctionCall:
+		return buildNoFunctionCallError()
+	case ErrorTypeInvalidArguments:
+		return buildInvalidArgumentsError(ctx.ToolName, ctx.Error, ctx.Content)
 	default:
 		return fmt.Sprintf("ERROR: An unknown error occurred: %v\n\nPlease try again.", ctx.Error)
 	}
@@ -73,6 +81,35 @@ Example:
 Please try again with a valid tool call.`
 }
 
+// buildNoFunctionCallError creates an error message for a response without a
+// function call when tools are called natively
+func buildNoFunctionCallError() string {
+	return `ERROR: No tool call found in your response.
+
+You MUST call exactly one tool in every response, using the function-calling interface. Do not write tool calls as text.
+
+Use task_completion to finish the task, ask_question to ask the user for information, or converse for a conversational reply.
+
+Please try again with a tool call.`
+}
+
+// buildInvalidArgumentsError creates an error message for function call
+// arguments that could not be converted into tool arguments
+func buildInvalidArgumentsError(toolName string, err error, arguments string) string {
+	snippet := arguments
+	if len(snippet) > 300 {
+		snippet = snippet[:300] + "..."
+	}
+
+	return fmt.Sprintf(`ERROR: Invalid arguments in call to %s.
+
+Error: %v
+
+Your arguments: %s
+
+Arguments must be a single JSON object whose keys are the parameter names in the tool's schema. Please try again with valid arguments.`, toolName, err, snippet)
+}
+
 // buildParseError creates an error message with recovery instructions for XML parsing errors
 func buildParseError(err error, content string) string {
 	snippet := content
@@ -3,6 +3,7 @@ package prompts
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/types"
@@ -118,6 +119,67 @@ func TestPromptBuilder(t *testing.T) {
 			t.Error("should contain custom instructions header")
 		}
 	})
+
+	t.Run("WithNativeToolCalls", func(t *testing.T) {
+		prompt := NewPromptBuilder().
+			WithTools([]tools.Tool{tools.NewTaskCompletionTool()}).
+			WithNativeToolCalls().
+			Build()
+
+		if !strings.Contains(prompt, "function calling") {
+			t.Error("should describe native function calling")
+		}
+		if strings.Contains(prompt, "<available_tools>") {
+			t.Error("should leave tool schemas to the function definitions")
+		}
+	})
+
+	t.Run("WithEnvironment", func(t *testing.T) {
+		prompt := NewPromptBuilder().
+			WithTools([]tools.Tool{}).
+			WithEnvironment(EnvironmentFacts{
+				Now:       time.Date(2026, time.March, 6, 9, 30, 0, 0, time.UTC),
+				OS:        "linux",
+				Arch:      "amd64",
+				CPUs:      8,
+				GitBranch: "main",
+				GitDirty:  true,
+			}).
+			WithRuntimeContext("- Tokens used: 10 of 100").
+			Build()
+
+		want := "<environment>\n" +
+			"- Current date: Friday, 6 March 2026\n" +
+			"- Current time: 09:30 UTC (UTC+00:00)\n" +
+			"- Operating system: linux/amd64\n" +
+			"- CPU cores: 8\n" +
+			"- Git branch: main (uncommitted changes)\n" +
+			"</environment>\n\n<runtime_context>"
+		if !strings.Contains(prompt, want) {
+			t.Errorf("should contain the environment section before the runtime context, got:\n%s", prompt)
+		}
+	})
+
+	t.Run("WithEnvironmentOutsideGit", func(t *testing.T) {
+		prompt := NewPromptBuilder().
+			WithEnvironment(EnvironmentFacts{Now: time.Now(), OS: "darwin", Arch: "arm64", CPUs: 10}).
+			Build()
+
+		if strings.Contains(prompt, "Git branch") {
+			t.Error("should leave out git facts outside a repository")
+		}
+	})
+
+	t.Run("WithRuntimeContext", func(t *testing.T) {
+		prompt := NewPromptBuilder().
+			WithTools([]tools.Tool{}).
+			WithRuntimeContext("- Tokens used: 10 of 100").
+			Build()
+
+		if !strings.HasSuffix(prompt, "<runtime_context>\n- Tokens used: 10 of 100\n</runtime_context>") {
+			t.Error("should end with the runtime context section")
+		}
+	})
 }
 
 func TestBuildMessages(t *testing.T) {
@@ -191,6 +253,15 @@ func TestNormalizeRoleForLLM(t *testing.T) {
 		}
 	})
 
+	t.Run("NativeToolResultKeepsRole", func(t *testing.T) {
+		original := types.NewToolMessage("Tool result content")
+		original.ToolCallID = "call_1"
+
+		if result := normalizeRoleForLLM(original); result.Role != types.RoleTool {
+			t.Errorf("expected RoleTool for a native tool result, got %s", result.Role)
+		}
+	})
+
 	t.Run("OtherRolesPassThrough", func(t *testing.T) {
 		msgs := []*types.Message{
 			types.NewUserMessage("user msg"),
@@ -141,6 +141,29 @@ func example() *Config {
 Failure to include a tool call is an operational error.
 </tool_calling>`
 
+// NativeToolCallingPrompt replaces ToolCallingPrompt when tools are offered
+// through the provider's native function-calling API.
+const NativeToolCallingPrompt = `<tool_calling>
+You have access to a set of tools that you can execute through function calling. You use one tool per message, and will receive the result of that tool use in the next message. You use tools step-by-step to accomplish tasks, with each tool use informed by the result of the previous tool use.
+
+The available tools and their parameters are provided as function definitions. Call them with the function-calling interface only; do not write tool calls as text, XML or JSON in your message.
+
+**CRITICAL RULES:**
+1. ALWAYS follow each function's parameter schema exactly
+2. The conversation may reference tools that are no longer available. NEVER call tools that are not explicitly provided
+3. **NEVER refer to tool names when speaking to the USER.** Instead of "I'll use task_completion", say "I'll complete this task"
+4. Before calling each tool, explain to the USER why you are taking this action (in your thinking)
+5. Call exactly ONE tool per message
+
+**CRITICAL INSTRUCTION:** Every single one of your responses MUST end with a tool call. There are no exceptions.
+- If a task is complete, use 'task_completion'
+- If you need information from the user, use 'ask_question'
+- If you are just conversing, use 'converse'
+- If you are performing an action, use the appropriate operational tool
+
+Failure to include a tool call is an operational error.
+</tool_calling>`
+
 // ToolUseRulesPrompt outlines the rules for using tools.
 const ToolUseRulesPrompt = `<tool_use_rules>
 **CRITICAL:** You MUST use a tool call in EVERY response. No exceptions.
@@ -170,7 +193,6 @@ You have access to a scratchpad for maintaining working memory during task execu
 - Cross-component patterns and relationships discovered during exploration
 - Root cause analysis and fix rationale for bugs
 - Important dependencies and interaction flows between systems
-- Progress tracking for multi-step implementations
 - Workarounds and their limitations
 
 **DON'T use scratchpad for:**
@@ -182,7 +204,7 @@ You have access to a scratchpad for maintaining working memory during task execu
 
 ## Available Tools
 
--   **add_note**: Create notes capturing insights, decisions, or patterns (800 char limit, 1-5 tags required)
+-   **add_note**: Create notes capturing insights, decisions, or patterns (800 char limit, 1-5 tags required, optional category)
 -   **search_notes**: Find notes by content keywords or tag filtering
 -   **list_notes**: View recent notes, optionally filtered by tags (default: 10 most recent)
 -   **update_note**: Modify note content as understanding evolves
@@ -190,16 +212,30 @@ You have access to a scratchpad for maintaining working memory during task execu
 -   **scratch_note**: Mark notes as addressed/completed (keeps for reference, filters from active lists)
 -   **list_tags**: See all tags in use to maintain consistent taxonomy
 
+## Plans Belong on the Todo List
+
+Track the steps of a multi-step task with **create_todo_list** and **update_todo**, not with notes: the user sees the todo list as a live checklist. Create the list once the plan is clear, mark each step in_progress when you start it and completed as soon as it is done, and cancel steps that turn out to be unnecessary.
+
+## Record Decisions, Blockers and Follow-ups As You Go
+
+Do not wait to be asked. The user reviews these notes with /notes, filtered by category, so record each one with add_note and its **category** the moment it happens:
+
+- **decision**: You chose between approaches. Record the choice and the reason, e.g. "Kept the v1 endpoint alongside v2 because the CLI still calls it"
+- **blocker**: You found a constraint that stops or limits the work, such as a failing dependency, a missing permission or an API that cannot do what the task needs
+- **followup**: You noticed work that is out of scope or deferred, such as a TODO, a flaky test or a cleanup left for later
+
+Add domain tags alongside the category. Scratch a blocker once it is resolved and a followup once it is done. Routine steps are not decisions: skip choices with only one reasonable option.
+
 ## Effective Tagging Strategy
 
 Organize notes by **type**, **domain**, and **status**:
 
-**Type tags**: decision, pattern, bug, dependency, workaround, progress, architecture
+**Type tags**: decision, blocker, followup, pattern, bug, dependency, workaround, architecture
 **Domain tags**: auth, api, database, ui, config, test, build, security
-**Status tags**: active, investigating, resolved, blocked, future
+**Status tags**: active, investigating, resolved, future
 
 Examples:
-- "Decision to use JWT with refresh tokens for auth scaling" → tags: ["decision", "auth", "security"]
+- "Decision to use JWT with refresh tokens for auth scaling" → category: decision, tags: ["auth", "security"]
 - "Payment service depends on user service for auth context" → tags: ["dependency", "api", "auth"]
 - "Test suite requires DB migration before running" → tags: ["pattern", "test", "database"]
 
@@ -209,7 +245,6 @@ Examples:
 - You discover a non-obvious relationship between components
 - You make an architectural decision and want to maintain consistency
 - You identify a pattern that should be applied across multiple files
-- You need to track progress through a multi-step implementation
 - Context compression might lose important reasoning or trade-offs
 
 **Skip the note when:**
@@ -226,6 +261,11 @@ Examples:
 - **Limit quantity** - quality over quantity keeps context manageable (aim for 5-10 focused notes per session)
 </scratchpad_guidance>`
 
+// ProjectMemoryPreamble introduces the facts from .forge/memory.md in the
+// <project_memory> section.
+const ProjectMemoryPreamble = `Facts about this project recorded in earlier sessions, from .forge/memory.md. Rely on them, but trust the code when it disagrees and fix the entry. ` +
+	`When you learn something durable that took effort to find out, such as a build quirk or a command that works, record it with the remember tool.`
+
 // CustomToolsGuidancePrompt explains when and why to create custom tools.
 const CustomToolsGuidancePrompt = `<custom_tools_guidance>
 # Building Custom Tools
@@ -244,9 +284,9 @@ Consider creating a custom tool when:
 
 ## How to Create Tools
 
-Use the **create_custom_tool** tool to scaffold a new custom tool. This generates the initial structure and returns detailed workflow instructions for:
+Use the **create_custom_tool** tool to scaffold a new custom tool in Go, Python or shell. Prefer Python or shell when Go is not installed or the tool is a small script. This generates the initial structure and returns detailed workflow instructions for:
 1. Implementing the tool logic
-2. Compiling the tool
+2. Compiling the tool (Go only)
 3. Updating metadata
 4. Verifying auto-discovery
 
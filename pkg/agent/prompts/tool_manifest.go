@@ -0,0 +1,32 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToolManifestEntry is a group of tools whose schemas are left out of the
+// prompt until the agent loads them.
+type ToolManifestEntry struct {
+	Group       string
+	Description string
+	Tools       []string
+}
+
+// FormatToolManifest lists the tool groups that are available but not
+// loaded, for inclusion in the system prompt.
+func FormatToolManifest(entries []ToolManifestEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("<tool_manifest>\n")
+	builder.WriteString("These tools are available but their schemas are not loaded. Before using one, call load_tools with its group to load the group's schemas.\n\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&builder, "- **%s**: %s (%s)\n", entry.Group, entry.Description, strings.Join(entry.Tools, ", "))
+	}
+	builder.WriteString("</tool_manifest>")
+
+	return builder.String()
+}
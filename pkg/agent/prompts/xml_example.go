@@ -3,6 +3,8 @@ package prompts
 import (
 	"fmt"
 	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
 )
 
 // XMLExampleProvider is an optional interface that tools can implement
@@ -130,10 +132,7 @@ func generateArrayExample(name string, propSchema map[string]any, indent string)
 		fmt.Fprintf(&builder, "%s<%s>\n", indent, name)
 
 		// Generate example item (singular form if possible)
-		singularName := name
-		if strings.HasSuffix(name, "s") {
-			singularName = name[:len(name)-1]
-		}
+		singularName := tools.ArrayItemElementName(name)
 
 		fmt.Fprintf(&builder, "%s  <%s>\n", indent, singularName)
 
@@ -159,10 +158,7 @@ func generateArrayExample(name string, propSchema map[string]any, indent string)
 	fmt.Fprintf(&builder, "%s<%s>\n", indent, name)
 
 	// Generate 2 example items with singular form
-	singularName := name
-	if strings.HasSuffix(name, "s") {
-		singularName = name[:len(name)-1]
-	}
+	singularName := tools.ArrayItemElementName(name)
 
 	fmt.Fprintf(&builder, "%s  <%s>item1</%s>\n", indent, singularName, singularName)
 	fmt.Fprintf(&builder, "%s  <%s>item2</%s>\n", indent, singularName, singularName)
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Recorder captures what is needed to replay a session: the user's inputs,
+// every event the agent emits, and the raw responses of the agent's LLM calls.
+// Implementations must be safe for concurrent use.
+type Recorder interface {
+	RecordInput(content string)
+	RecordEvent(event *types.AgentEvent)
+	RecordLLMCall(messages []*types.Message, chunks []*llm.StreamChunk)
+}
+
+// WithRecorder records the session with rec. Only the main agent loop's LLM
+// calls are recorded; summarization and other auxiliary calls are not.
+func WithRecorder(rec Recorder) AgentOption {
+	return func(a *DefaultAgent) {
+		a.recorder = rec
+	}
+}
+
+// recordStream passes stream through unchanged, recording the request and
+// its chunks once the response is complete. The call is recorded before the
+// last chunk is passed on, since stream processing stops reading at that
+// chunk and the turn may end right after.
+func (a *DefaultAgent) recordStream(messages []*types.Message, stream <-chan *llm.StreamChunk) <-chan *llm.StreamChunk {
+	if a.recorder == nil {
+		return stream
+	}
+
+	out := make(chan *llm.StreamChunk)
+	go func() {
+		defer close(out)
+		var chunks []*llm.StreamChunk
+		for chunk := range stream {
+			chunks = append(chunks, chunk)
+			if chunk.IsError() || chunk.IsLast() {
+				a.recorder.RecordLLMCall(messages, chunks)
+				out <- chunk
+				return
+			}
+			out <- chunk
+		}
+		a.recorder.RecordLLMCall(messages, chunks)
+	}()
+	return out
+}
@@ -0,0 +1,328 @@
+package recording
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// FormatVersion is the bundle format version written by Recorder.
+const FormatVersion = 1
+
+// EntryKind identifies what a bundle entry holds.
+type EntryKind string
+
+const (
+	// EntryHeader describes the session; it is always the first entry.
+	EntryHeader EntryKind = "header"
+	// EntryInput is a message the user sent to the agent.
+	EntryInput EntryKind = "input"
+	// EntryEvent is an event the agent emitted.
+	EntryEvent EntryKind = "event"
+	// EntryLLMCall is a request the agent loop sent to the model and the
+	// streamed response.
+	EntryLLMCall EntryKind = "llm_call"
+)
+
+// Header describes a recorded session.
+type Header struct {
+	Version      int       `json:"version"`
+	ForgeVersion string    `json:"forge_version,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	Workspace    string    `json:"workspace,omitempty"`
+	ToolCallMode string    `json:"tool_call_mode,omitempty"`
+	Started      time.Time `json:"started"`
+}
+
+// Entry is one line of a bundle. Exactly one of Header, Input, Event and
+// LLMCall is set, according to Kind.
+type Entry struct {
+	Seq     int64     `json:"seq"`
+	Time    time.Time `json:"time"`
+	Kind    EntryKind `json:"kind"`
+	Header  *Header   `json:"header,omitempty"`
+	Input   string    `json:"input,omitempty"`
+	Event   *Event    `json:"event,omitempty"`
+	LLMCall *LLMCall  `json:"llm_call,omitempty"`
+}
+
+// Event is the recorded form of a types.AgentEvent. Errors are kept as their
+// message and previews only when they are tool previews.
+type Event struct {
+	Type       types.AgentEventType `json:"type"`
+	Timestamp  time.Time            `json:"timestamp,omitzero"`
+	TurnID     string               `json:"turn_id,omitempty"`
+	Content    string               `json:"content,omitempty"`
+	ToolName   string               `json:"tool_name,omitempty"`
+	ToolCallID string               `json:"tool_call_id,omitempty"`
+	ToolInput  map[string]any       `json:"tool_input,omitempty"`
+	ToolOutput any                  `json:"tool_output,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	IsBusy     bool                 `json:"is_busy,omitempty"`
+	ApprovalID string               `json:"approval_id,omitempty"`
+	Preview    *tools.ToolPreview   `json:"preview,omitempty"`
+	Metadata   map[string]any       `json:"metadata,omitempty"`
+
+	TokenUsage           *types.TokenUsage           `json:"token_usage,omitempty"`
+	CommandExecution     *types.CommandExecution     `json:"command_execution,omitempty"`
+	ContextSummarization *types.ContextSummarization `json:"context_summarization,omitempty"`
+	APICallInfo          *types.APICallInfo          `json:"api_call_info,omitempty"`
+	NotesData            *types.NotesData            `json:"notes_data,omitempty"`
+	OversizedMessage     *types.OversizedMessage     `json:"oversized_message,omitempty"`
+	ModelFallback        *types.ModelFallback        `json:"model_fallback,omitempty"`
+	TurnBudget           *types.TurnBudget           `json:"turn_budget,omitempty"`
+}
+
+// LLMCall is a recorded model request and its streamed response.
+type LLMCall struct {
+	Messages []Message `json:"messages"`
+	Chunks   []Chunk   `json:"chunks"`
+}
+
+// Message is the recorded form of a types.Message.
+type Message struct {
+	Role       types.MessageRole `json:"role"`
+	Content    string            `json:"content"`
+	ToolCalls  []types.ToolCall  `json:"tool_calls,omitempty"`
+	ToolCallID string            `json:"tool_call_id,omitempty"`
+}
+
+// Chunk is the recorded form of an llm.StreamChunk.
+type Chunk struct {
+	Content   string           `json:"content,omitempty"`
+	Role      string           `json:"role,omitempty"`
+	Type      llm.ContentType  `json:"type,omitempty"`
+	Finished  bool             `json:"finished,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	Usage     *llm.UsageInfo   `json:"usage,omitempty"`
+	ToolCalls []types.ToolCall `json:"tool_calls,omitempty"`
+}
+
+// Bundle is a loaded recording.
+type Bundle struct {
+	Header  Header
+	Entries []Entry
+}
+
+// Load reads the bundle at path.
+func Load(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer f.Close()
+
+	bundle, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording %s: %w", path, err)
+	}
+	return bundle, nil
+}
+
+// Read decodes a bundle. A truncated final line, left by a session that was
+// killed mid-write, is ignored.
+func Read(r io.Reader) (*Bundle, error) {
+	dec := json.NewDecoder(r)
+	bundle := &Bundle{}
+	for i := 0; ; i++ {
+		var entry Entry
+		err := dec.Decode(&entry)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+
+		if i == 0 {
+			if entry.Kind != EntryHeader || entry.Header == nil {
+				return nil, fmt.Errorf("missing header")
+			}
+			if entry.Header.Version > FormatVersion {
+				return nil, fmt.Errorf("unsupported format version %d (this build reads up to %d)", entry.Header.Version, FormatVersion)
+			}
+			bundle.Header = *entry.Header
+			continue
+		}
+		bundle.Entries = append(bundle.Entries, entry)
+	}
+
+	if bundle.Header.Version == 0 {
+		return nil, fmt.Errorf("missing header")
+	}
+	return bundle, nil
+}
+
+// Inputs returns the recorded user inputs in order.
+func (b *Bundle) Inputs() []string {
+	var inputs []string
+	for _, entry := range b.Entries {
+		if entry.Kind == EntryInput {
+			inputs = append(inputs, entry.Input)
+		}
+	}
+	return inputs
+}
+
+// Events returns the recorded events in order.
+func (b *Bundle) Events() []*Event {
+	var events []*Event
+	for _, entry := range b.Entries {
+		if entry.Kind == EntryEvent && entry.Event != nil {
+			events = append(events, entry.Event)
+		}
+	}
+	return events
+}
+
+// LLMCalls returns the recorded LLM calls in order.
+func (b *Bundle) LLMCalls() []*LLMCall {
+	var calls []*LLMCall
+	for _, entry := range b.Entries {
+		if entry.Kind == EntryLLMCall && entry.LLMCall != nil {
+			calls = append(calls, entry.LLMCall)
+		}
+	}
+	return calls
+}
+
+func newEvent(event *types.AgentEvent) *Event {
+	recorded := &Event{
+		Type:                 event.Type,
+		Timestamp:            event.Timestamp,
+		TurnID:               event.TurnID,
+		Content:              event.Content,
+		ToolName:             event.ToolName,
+		ToolCallID:           event.ToolCallID,
+		ToolInput:            event.ToolInput,
+		ToolOutput:           event.ToolOutput,
+		IsBusy:               event.IsBusy,
+		ApprovalID:           event.ApprovalID,
+		Metadata:             event.Metadata,
+		TokenUsage:           event.TokenUsage,
+		CommandExecution:     event.CommandExecution,
+		ContextSummarization: event.ContextSummarization,
+		APICallInfo:          event.APICallInfo,
+		NotesData:            event.NotesData,
+		OversizedMessage:     event.OversizedMessage,
+		ModelFallback:        event.ModelFallback,
+		TurnBudget:           event.TurnBudget,
+	}
+	if event.Error != nil {
+		recorded.Error = event.Error.Error()
+	}
+	if preview, ok := event.Preview.(*tools.ToolPreview); ok {
+		recorded.Preview = preview
+	}
+	return recorded
+}
+
+// AgentEvent converts the recorded event back to the event the agent emitted.
+func (e *Event) AgentEvent() *types.AgentEvent {
+	event := &types.AgentEvent{
+		Type:                 e.Type,
+		Timestamp:            e.Timestamp,
+		TurnID:               e.TurnID,
+		Content:              e.Content,
+		ToolName:             e.ToolName,
+		ToolCallID:           e.ToolCallID,
+		ToolInput:            e.ToolInput,
+		ToolOutput:           e.ToolOutput,
+		IsBusy:               e.IsBusy,
+		ApprovalID:           e.ApprovalID,
+		Metadata:             restoreNumbers(e.Metadata),
+		TokenUsage:           e.TokenUsage,
+		CommandExecution:     e.CommandExecution,
+		ContextSummarization: e.ContextSummarization,
+		APICallInfo:          e.APICallInfo,
+		NotesData:            e.NotesData,
+		OversizedMessage:     e.OversizedMessage,
+		ModelFallback:        e.ModelFallback,
+		TurnBudget:           e.TurnBudget,
+	}
+	if event.Metadata == nil {
+		event.Metadata = make(map[string]any)
+	}
+	if e.Error != "" {
+		event.Error = errors.New(e.Error)
+	}
+	if e.Preview != nil {
+		event.Preview = e.Preview
+	}
+	return event
+}
+
+// restoreNumbers turns whole numbers, which JSON decodes as float64, back
+// into ints so consumers asserting the types the agent emitted still match.
+func restoreNumbers(metadata map[string]any) map[string]any {
+	for key, value := range metadata {
+		if f, ok := value.(float64); ok && f == math.Trunc(f) && math.Abs(f) < math.MaxInt32 {
+			metadata[key] = int(f)
+		}
+	}
+	return metadata
+}
+
+func newMessages(messages []*types.Message) []Message {
+	recorded := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		recorded = append(recorded, Message{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+		})
+	}
+	return recorded
+}
+
+// Message converts the recorded message back to a types.Message.
+func (m Message) Message() *types.Message {
+	msg := types.NewMessage(m.Role, m.Content)
+	msg.ToolCalls = m.ToolCalls
+	msg.ToolCallID = m.ToolCallID
+	return msg
+}
+
+func newChunks(chunks []*llm.StreamChunk) []Chunk {
+	recorded := make([]Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		c := Chunk{
+			Content:   chunk.Content,
+			Role:      chunk.Role,
+			Type:      chunk.Type,
+			Finished:  chunk.Finished,
+			Usage:     chunk.Usage,
+			ToolCalls: chunk.ToolCalls,
+		}
+		if chunk.Error != nil {
+			c.Error = chunk.Error.Error()
+		}
+		recorded = append(recorded, c)
+	}
+	return recorded
+}
+
+// StreamChunk converts the recorded chunk back to an llm.StreamChunk.
+func (c Chunk) StreamChunk() *llm.StreamChunk {
+	chunk := &llm.StreamChunk{
+		Content:   c.Content,
+		Role:      c.Role,
+		Type:      c.Type,
+		Finished:  c.Finished,
+		Usage:     c.Usage,
+		ToolCalls: c.ToolCalls,
+	}
+	if c.Error != "" {
+		chunk.Error = errors.New(c.Error)
+	}
+	return chunk
+}
@@ -0,0 +1,21 @@
+// Package recording captures agent sessions to replayable bundles and plays
+// them back.
+//
+// A Recorder, attached with agent.WithRecorder, appends the user's inputs,
+// every event the agent emits and the raw responses of its LLM calls to a
+// JSON Lines bundle as the session runs, so a bundle is usable even when the
+// session ends abruptly.
+//
+// Bundles are played back in two ways. A Player is an agent.Agent that
+// re-emits the recorded inputs and events with their original timing, which
+// lets the TUI re-render a session exactly as it was shown. Rerun instead
+// drives a fresh agent against a ReplayProvider that answers with the
+// recorded LLM responses and stub tools that return the recorded results,
+// then reports where the new run's requests and tool calls diverge from the
+// recording. That makes a bundle a regression test for changes to the agent
+// loop, prompts and tool handling that needs neither a model nor the
+// original workspace.
+//
+// Bundles contain the full conversation, including file contents and command
+// output the agent saw, and are written readable only by their owner.
+package recording
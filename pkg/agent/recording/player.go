@@ -0,0 +1,206 @@
+package recording
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// EventTypeReplayInput is emitted by a Player for each recorded user input,
+// in the place the user sent it, so executors can show it as the user's
+// message.
+const EventTypeReplayInput types.AgentEventType = "replay_input"
+
+// maxReplayGap caps the pause between two replayed entries, so time the user
+// spent reading or typing does not stall the replay.
+const maxReplayGap = 2 * time.Second
+
+// Player is an agent.Agent that re-emits a bundle's inputs and events with
+// their original timing. It runs no model and no tools; inputs, approvals and
+// cancellations sent to it are discarded.
+type Player struct {
+	bundle   *Bundle
+	speed    float64
+	channels *types.AgentChannels
+
+	startOnce sync.Once
+}
+
+// NewPlayer creates a player for bundle. speed scales the recorded pauses
+// between entries: 2 plays twice as fast, and 0 or less emits everything at
+// once.
+func NewPlayer(bundle *Bundle, speed float64) *Player {
+	return &Player{
+		bundle:   bundle,
+		speed:    speed,
+		channels: types.NewAgentChannels(10),
+	}
+}
+
+// Start begins replaying in a goroutine.
+func (p *Player) Start(ctx context.Context) error {
+	started := false
+	p.startOnce.Do(func() {
+		started = true
+		go p.play(ctx)
+	})
+	if !started {
+		return fmt.Errorf("player is already running")
+	}
+	return nil
+}
+
+// Shutdown stops the replay.
+func (p *Player) Shutdown(ctx context.Context) error {
+	close(p.channels.Shutdown)
+
+	select {
+	case <-p.channels.Done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetChannels returns the player's channels.
+func (p *Player) GetChannels() *types.AgentChannels {
+	return p.channels
+}
+
+// GetTool returns nil; a replay has no tools.
+func (p *Player) GetTool(name string) any {
+	return nil
+}
+
+// GetTools returns no tools; a replay has no tools.
+func (p *Player) GetTools() []any {
+	return nil
+}
+
+// GetContextInfo describes the conversation as of the last recorded LLM call.
+func (p *Player) GetContextInfo() *agent.ContextInfo {
+	messages := p.GetMessages()
+	info := &agent.ContextInfo{MessageCount: len(messages)}
+	for _, msg := range messages {
+		if msg.Role == types.RoleUser {
+			info.ConversationTurns++
+		}
+	}
+	return info
+}
+
+// GetMessages returns the conversation history sent with the last recorded
+// LLM call, without the system prompt.
+func (p *Player) GetMessages() []*types.Message {
+	calls := p.bundle.LLMCalls()
+	if len(calls) == 0 {
+		return nil
+	}
+
+	var messages []*types.Message
+	for _, msg := range calls[len(calls)-1].Messages {
+		if msg.Role != types.RoleSystem {
+			messages = append(messages, msg.Message())
+		}
+	}
+	return messages
+}
+
+// GetSystemPrompt returns the system prompt of the last recorded LLM call.
+func (p *Player) GetSystemPrompt() string {
+	calls := p.bundle.LLMCalls()
+	if len(calls) == 0 {
+		return ""
+	}
+	for _, msg := range calls[len(calls)-1].Messages {
+		if msg.Role == types.RoleSystem {
+			return msg.Content
+		}
+	}
+	return ""
+}
+
+// SetProvider always fails; a replay cannot switch models.
+func (p *Player) SetProvider(provider llm.Provider) error {
+	return fmt.Errorf("cannot change the provider of a replayed session")
+}
+
+// play emits the recorded entries, then idles discarding input until it is
+// shut down.
+func (p *Player) play(ctx context.Context) {
+	defer p.channels.Close()
+
+	var last time.Time
+	for _, entry := range p.bundle.Entries {
+		var event *types.AgentEvent
+		switch entry.Kind {
+		case EntryInput:
+			event = &types.AgentEvent{
+				Type:      EventTypeReplayInput,
+				Content:   entry.Input,
+				Timestamp: entry.Time,
+				Metadata:  make(map[string]any),
+			}
+		case EntryEvent:
+			if entry.Event == nil {
+				continue
+			}
+			event = entry.Event.AgentEvent()
+		default:
+			continue
+		}
+
+		if delay := p.delay(last, entry.Time); delay > 0 {
+			timer := time.NewTimer(delay)
+			ok := p.discardUntil(ctx, timer.C)
+			timer.Stop()
+			if !ok {
+				return
+			}
+		}
+		last = entry.Time
+
+		select {
+		case p.channels.Event <- event:
+		case <-ctx.Done():
+			return
+		case <-p.channels.Shutdown:
+			return
+		}
+	}
+
+	p.discardUntil(ctx, nil)
+}
+
+// delay returns how long to pause between entries recorded at prev and next:
+// the recorded gap, capped and scaled by the replay speed.
+func (p *Player) delay(prev, next time.Time) time.Duration {
+	if p.speed <= 0 || prev.IsZero() {
+		return 0
+	}
+	gap := min(next.Sub(prev), maxReplayGap)
+	return time.Duration(float64(gap) / p.speed)
+}
+
+// discardUntil discards anything sent to the player until done fires, or
+// forever when done is nil. It reports false once the replay should stop.
+func (p *Player) discardUntil(ctx context.Context, done <-chan time.Time) bool {
+	for {
+		select {
+		case <-done:
+			return true
+		case <-ctx.Done():
+			return false
+		case <-p.channels.Shutdown:
+			return false
+		case <-p.channels.Input:
+		case <-p.channels.Approval:
+		case <-p.channels.Cancel:
+		}
+	}
+}
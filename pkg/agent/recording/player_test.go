@@ -0,0 +1,84 @@
+package recording
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestPlayerReplaysInputsAndEvents(t *testing.T) {
+	bundle := recordSession(t)
+
+	player := NewPlayer(bundle, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := player.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := player.Start(ctx); err == nil {
+		t.Error("second Start should fail")
+	}
+
+	channels := player.GetChannels()
+	var replayed []*types.AgentEvent
+	timeout := time.After(5 * time.Second)
+	for len(replayed) < len(bundle.Entries) {
+		select {
+		case event := <-channels.Event:
+			replayed = append(replayed, event)
+		case <-timeout:
+			t.Fatalf("replayed %d events before timing out", len(replayed))
+		}
+		if last := replayed[len(replayed)-1]; last.Type == types.EventTypeTurnEnd {
+			break
+		}
+	}
+
+	if replayed[0].Type != EventTypeReplayInput || replayed[0].Content != "what is the answer?" {
+		t.Errorf("first event = %s %q, want the replayed input", replayed[0].Type, replayed[0].Content)
+	}
+	if last := replayed[len(replayed)-1]; last.Type != types.EventTypeTurnEnd {
+		t.Errorf("last event = %s, want turn_end", last.Type)
+	}
+
+	// Input sent during or after the replay is discarded
+	channels.Input <- types.NewUserInput("ignored")
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second)
+	defer shutdownCancel()
+	if err := player.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if msgs := player.GetMessages(); len(msgs) == 0 || msgs[0].Content != "what is the answer?" {
+		t.Errorf("GetMessages = %v, want the recorded conversation", msgs)
+	}
+	if player.GetSystemPrompt() == "" {
+		t.Error("GetSystemPrompt should return the recorded system prompt")
+	}
+}
+
+func TestPlayerDelay(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name  string
+		speed float64
+		gap   time.Duration
+		want  time.Duration
+	}{
+		{"recorded gap", 1, 500 * time.Millisecond, 500 * time.Millisecond},
+		{"faster", 2, 500 * time.Millisecond, 250 * time.Millisecond},
+		{"long gaps are capped", 1, time.Minute, maxReplayGap},
+		{"instant", 0, time.Second, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Player{speed: tt.speed}
+			if got := p.delay(start, start.Add(tt.gap)); got != tt.want {
+				t.Errorf("delay = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
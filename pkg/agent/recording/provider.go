@@ -0,0 +1,149 @@
+package recording
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// ReplayProvider is an llm.Provider that answers the agent loop's requests
+// with a bundle's recorded responses, in order. It compares each request with
+// the recorded one and keeps the differences as divergences.
+type ReplayProvider struct {
+	model  string
+	native bool
+
+	mu          sync.Mutex
+	calls       []*LLMCall
+	next        int
+	divergences []Divergence
+}
+
+// NewReplayProvider creates a provider that replays the LLM calls in bundle.
+// It offers native tool calling when the session was recorded with it.
+func NewReplayProvider(bundle *Bundle) *ReplayProvider {
+	return &ReplayProvider{
+		model:  bundle.Header.Model,
+		native: bundle.Header.ToolCallMode == llm.ToolCallModeNative,
+		calls:  bundle.LLMCalls(),
+	}
+}
+
+// StreamCompletion streams the next recorded response.
+func (p *ReplayProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	call, err := p.nextCall(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := make(chan *llm.StreamChunk, len(call.Chunks))
+	for _, chunk := range call.Chunks {
+		stream <- chunk.StreamChunk()
+	}
+	close(stream)
+	return stream, nil
+}
+
+// StreamCompletionWithTools streams the next recorded response. The offered
+// tools are not compared; the recorded tool calls are in the response.
+func (p *ReplayProvider) StreamCompletionWithTools(ctx context.Context, messages []*types.Message, _ []llm.ToolDefinition) (<-chan *llm.StreamChunk, error) {
+	return p.StreamCompletion(ctx, messages)
+}
+
+// Complete is not supported: only the agent loop's streamed calls are recorded.
+func (p *ReplayProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	return nil, fmt.Errorf("replay provider: non-streaming completions are not recorded")
+}
+
+// AnalyzeDocument is not supported: document analysis calls are not recorded.
+func (p *ReplayProvider) AnalyzeDocument(ctx context.Context, fileData []byte, mediaType string, prompt string) (string, error) {
+	return "", fmt.Errorf("replay provider: document analysis is not recorded")
+}
+
+// GetModelInfo returns the recorded model.
+func (p *ReplayProvider) GetModelInfo() *types.ModelInfo {
+	return &types.ModelInfo{
+		Name:                p.model,
+		Provider:            "replay",
+		SupportsStreaming:   true,
+		SupportsToolCalling: p.native,
+	}
+}
+
+// GetModel returns the recorded model name.
+func (p *ReplayProvider) GetModel() string {
+	return p.model
+}
+
+// GetBaseURL returns an empty string; replays make no requests.
+func (p *ReplayProvider) GetBaseURL() string {
+	return ""
+}
+
+// GetAPIKey returns an empty string; replays make no requests.
+func (p *ReplayProvider) GetAPIKey() string {
+	return ""
+}
+
+// Divergences returns the differences found so far between the requests
+// made and the recorded ones, including recorded calls not yet replayed.
+func (p *ReplayProvider) Divergences() []Divergence {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	divergences := append([]Divergence(nil), p.divergences...)
+	if p.next < len(p.calls) {
+		divergences = append(divergences, Divergence{
+			Kind:  DivergenceLLMCall,
+			Index: p.next,
+			Want:  fmt.Sprintf("%d LLM calls", len(p.calls)),
+			Got:   fmt.Sprintf("%d LLM calls", p.next),
+		})
+	}
+	return divergences
+}
+
+// nextCall returns the next recorded call, noting when the request differs
+// from the recorded one.
+func (p *ReplayProvider) nextCall(messages []*types.Message) (*LLMCall, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	index := p.next
+	if index >= len(p.calls) {
+		p.divergences = append(p.divergences, Divergence{
+			Kind:  DivergenceLLMCall,
+			Index: index,
+			Want:  fmt.Sprintf("%d LLM calls", len(p.calls)),
+			Got:   fmt.Sprintf("LLM call %d", index+1),
+		})
+		return nil, fmt.Errorf("replay provider: no recorded response for LLM call %d", index+1)
+	}
+	p.next++
+
+	call := p.calls[index]
+	if want, got := lastMessage(call.Messages), lastMessage(newMessages(messages)); want != got {
+		p.divergences = append(p.divergences, Divergence{
+			Kind:  DivergenceRequest,
+			Index: index,
+			Want:  want,
+			Got:   got,
+		})
+	}
+	return call, nil
+}
+
+// lastMessage describes the newest message of a request: what the agent
+// added since the previous call. The system prompt and earlier history are
+// not compared, since they legitimately change between runs (the date,
+// summarization).
+func lastMessage(messages []Message) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	last := messages[len(messages)-1]
+	return fmt.Sprintf("%s: %s", last.Role, last.Content)
+}
@@ -0,0 +1,110 @@
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Recorder writes a session to a bundle file. It implements agent.Recorder.
+// Each entry is written as soon as it is recorded. Errors are kept and
+// reported by Close rather than interrupting the session: an entry that
+// cannot be encoded is skipped, and a failed write stops the recording.
+type Recorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	seq     int64
+	err     error
+	stopped bool
+}
+
+// NewRecorder creates the bundle at path, replacing any existing file, and
+// writes its header. Version and Started are filled in when unset.
+func NewRecorder(path string, header Header) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	if header.Version == 0 {
+		header.Version = FormatVersion
+	}
+	if header.Started.IsZero() {
+		header.Started = time.Now()
+	}
+
+	r := &Recorder{file: file}
+	r.write(Entry{Kind: EntryHeader, Header: &header})
+	if r.stopped {
+		file.Close()
+		return nil, r.err
+	}
+	return r, nil
+}
+
+// RecordInput records a message the user sent to the agent.
+func (r *Recorder) RecordInput(content string) {
+	r.write(Entry{Kind: EntryInput, Input: content})
+}
+
+// RecordEvent records an event the agent emitted.
+func (r *Recorder) RecordEvent(event *types.AgentEvent) {
+	r.write(Entry{Kind: EntryEvent, Event: newEvent(event)})
+}
+
+// RecordLLMCall records a model request and its streamed response.
+func (r *Recorder) RecordLLMCall(messages []*types.Message, chunks []*llm.StreamChunk) {
+	r.write(Entry{Kind: EntryLLMCall, LLMCall: &LLMCall{
+		Messages: newMessages(messages),
+		Chunks:   newChunks(chunks),
+	}})
+}
+
+// Close closes the bundle file and returns the first error that occurred
+// while recording, if any.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return r.err
+	}
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = fmt.Errorf("failed to close recording: %w", err)
+	}
+	r.file = nil
+	return r.err
+}
+
+func (r *Recorder) write(entry Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil || r.stopped {
+		return
+	}
+	entry.Seq = r.seq
+	entry.Time = time.Now()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		if r.err == nil {
+			r.err = fmt.Errorf("failed to encode recording entry %d: %w", entry.Seq, err)
+		}
+		return
+	}
+	r.seq++
+	if _, err := r.file.Write(append(data, '\n')); err != nil {
+		r.err = fmt.Errorf("failed to write recording: %w", err)
+		r.stopped = true
+	}
+}
@@ -0,0 +1,330 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// DivergenceKind identifies what differs between a rerun and its recording.
+type DivergenceKind string
+
+const (
+	// DivergenceRequest is an LLM request whose newest message differs from
+	// the recorded request.
+	DivergenceRequest DivergenceKind = "request"
+	// DivergenceLLMCall is a difference in the number of LLM calls.
+	DivergenceLLMCall DivergenceKind = "llm_call"
+	// DivergenceToolCall is a tool call that differs from the recorded one,
+	// or a difference in the number of tool calls.
+	DivergenceToolCall DivergenceKind = "tool_call"
+)
+
+// Divergence is a difference between a rerun and its recording.
+type Divergence struct {
+	Kind  DivergenceKind
+	Index int // Zero-based position of the LLM or tool call
+	Want  string
+	Got   string
+}
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("%s %d:\n  recorded: %s\n  replayed: %s", d.Kind, d.Index+1, d.Want, d.Got)
+}
+
+// Report is the result of a Rerun.
+type Report struct {
+	Inputs      int
+	ToolCalls   int
+	Divergences []Divergence
+}
+
+// Diverged reports whether the rerun differed from the recording.
+func (r *Report) Diverged() bool {
+	return len(r.Divergences) > 0
+}
+
+// rerunTurnTimeout bounds a single replayed turn. Replayed turns make no
+// network requests and run no real tools, so a turn that takes this long is
+// stuck.
+const rerunTurnTimeout = 2 * time.Minute
+
+// Rerun replays bundle's inputs through a fresh agent that gets the recorded
+// LLM responses from a ReplayProvider, and reports where the rerun diverges
+// from the recording. opts configure the agent under test, for example with
+// the current system prompt.
+//
+// Tools other than the built-in ones are replaced with stubs that return the
+// recorded results in order and never touch the workspace. Approval requests
+// are answered as they were recorded: a call the user rejected is rejected
+// again, everything else is approved. Hooks, context summarization and
+// long-term memory are not part of the rerun unless opts add them.
+func Rerun(ctx context.Context, bundle *Bundle, opts ...agent.AgentOption) (*Report, error) {
+	provider := NewReplayProvider(bundle)
+	agentOpts := append([]agent.AgentOption{agent.WithToolCallMode(bundle.Header.ToolCallMode)}, opts...)
+	ag := agent.NewDefaultAgent(provider, agentOpts...)
+
+	results := newRecordedResults(bundle.Events())
+	for _, name := range results.toolNames() {
+		if ag.GetTool(name) != nil {
+			continue // Built-in tools run for real
+		}
+		if err := ag.RegisterTool(&stubTool{name: name, results: results}); err != nil {
+			return nil, fmt.Errorf("failed to register stub for %s: %w", name, err)
+		}
+	}
+
+	// Approvals are answered from the recording, not by configured rules
+	ag.SetAutoApproval(false)
+
+	if err := ag.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start agent: %w", err)
+	}
+	channels := ag.GetChannels()
+
+	var toolCalls []*types.AgentEvent
+	for _, input := range bundle.Inputs() {
+		channels.Input <- types.NewUserInput(input)
+		calls, err := runTurn(ctx, channels, results)
+		toolCalls = append(toolCalls, calls...)
+		if err != nil {
+			_ = ag.Shutdown(context.Background())
+			return nil, err
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = ag.Shutdown(shutdownCtx)
+
+	report := &Report{
+		Inputs:      len(bundle.Inputs()),
+		ToolCalls:   len(toolCalls),
+		Divergences: provider.Divergences(),
+	}
+	report.Divergences = append(report.Divergences, compareToolCalls(recordedToolCalls(bundle.Events()), toolCalls)...)
+	return report, nil
+}
+
+// runTurn reads events until the turn ends, answering approval requests from
+// the recording, and returns the turn's tool call events. A turn that made
+// the agent busy has ended once it is idle again, which the agent reports
+// after the turn end event.
+func runTurn(ctx context.Context, channels *types.AgentChannels, results *recordedResults) ([]*types.AgentEvent, error) {
+	timeout := time.NewTimer(rerunTurnTimeout)
+	defer timeout.Stop()
+
+	var toolCalls []*types.AgentEvent
+	busy, ended := false, false
+	for {
+		select {
+		case <-ctx.Done():
+			return toolCalls, ctx.Err()
+		case <-timeout.C:
+			return toolCalls, fmt.Errorf("replayed turn did not finish within %s", rerunTurnTimeout)
+		case event, ok := <-channels.Event:
+			if !ok {
+				return toolCalls, fmt.Errorf("agent stopped during replay")
+			}
+			switch event.Type {
+			case types.EventTypeToolCall:
+				toolCalls = append(toolCalls, event)
+			case types.EventTypeToolApprovalRequest:
+				decision := types.ApprovalGranted
+				if results.takeRejection(event.ToolName) {
+					decision = types.ApprovalRejected
+				}
+				channels.Approval <- types.NewApprovalResponse(event.ApprovalID, decision)
+			case types.EventTypeUpdateBusy:
+				busy = event.IsBusy
+				if !busy && ended {
+					return toolCalls, nil
+				}
+			case types.EventTypeTurnEnd:
+				ended = true
+				if !busy {
+					return toolCalls, nil
+				}
+			}
+		}
+	}
+}
+
+// recordedToolCalls returns the tool call events of a recording.
+func recordedToolCalls(events []*Event) []*Event {
+	var calls []*Event
+	for _, event := range events {
+		if event.Type == types.EventTypeToolCall {
+			calls = append(calls, event)
+		}
+	}
+	return calls
+}
+
+// compareToolCalls reports the replayed tool calls whose tool or arguments
+// differ from the recorded calls at the same position.
+func compareToolCalls(recorded []*Event, replayed []*types.AgentEvent) []Divergence {
+	var divergences []Divergence
+	for i := 0; i < len(recorded) && i < len(replayed); i++ {
+		want := describeToolCall(recorded[i].ToolName, recorded[i].ToolInput)
+		got := describeToolCall(replayed[i].ToolName, replayed[i].ToolInput)
+		if want != got {
+			divergences = append(divergences, Divergence{Kind: DivergenceToolCall, Index: i, Want: want, Got: got})
+		}
+	}
+	if len(recorded) != len(replayed) {
+		divergences = append(divergences, Divergence{
+			Kind:  DivergenceToolCall,
+			Index: min(len(recorded), len(replayed)),
+			Want:  fmt.Sprintf("%d tool calls", len(recorded)),
+			Got:   fmt.Sprintf("%d tool calls", len(replayed)),
+		})
+	}
+	return divergences
+}
+
+// describeToolCall renders a call with its arguments as sorted JSON, so the
+// same call compares equal before and after a round trip through a bundle.
+func describeToolCall(name string, input map[string]any) string {
+	var normalized any
+	if data, err := json.Marshal(input); err == nil {
+		_ = json.Unmarshal(data, &normalized)
+	}
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return name
+	}
+	return fmt.Sprintf("%s %s", name, data)
+}
+
+// recordedResult is how one recorded call of a tool ended.
+type recordedResult struct {
+	rejected bool
+	output   string
+	metadata map[string]any
+	err      error
+}
+
+// recordedResults holds each tool's recorded results in call order.
+type recordedResults struct {
+	mu     sync.Mutex
+	byTool map[string][]recordedResult
+	order  []string
+}
+
+func newRecordedResults(events []*Event) *recordedResults {
+	results := &recordedResults{byTool: make(map[string][]recordedResult)}
+
+	// Results follow their call; a rejected call has no call event
+	pending := make(map[string]int) // Tool call ID -> index in byTool
+	for _, event := range events {
+		switch event.Type {
+		case types.EventTypeToolCall:
+			results.add(event.ToolName, recordedResult{err: errors.New("replay: no result was recorded for this call")})
+			pending[event.ToolCallID] = len(results.byTool[event.ToolName]) - 1
+		case types.EventTypeToolResult, types.EventTypeToolResultError:
+			index, ok := pending[event.ToolCallID]
+			if !ok {
+				continue
+			}
+			delete(pending, event.ToolCallID)
+			result := &results.byTool[event.ToolName][index]
+			result.err = nil
+			if event.Type == types.EventTypeToolResultError {
+				result.err = errors.New(event.Error)
+			} else {
+				result.output = fmt.Sprint(event.ToolOutput)
+				result.metadata = restoreNumbers(event.Metadata)
+			}
+		case types.EventTypeToolApprovalRejected, types.EventTypeToolApprovalTimeout:
+			results.add(event.ToolName, recordedResult{rejected: true})
+		}
+	}
+	return results
+}
+
+func (r *recordedResults) add(tool string, result recordedResult) {
+	if _, ok := r.byTool[tool]; !ok {
+		r.order = append(r.order, tool)
+	}
+	r.byTool[tool] = append(r.byTool[tool], result)
+}
+
+// toolNames returns the recorded tools in order of first use.
+func (r *recordedResults) toolNames() []string {
+	return r.order
+}
+
+// takeRejection consumes the tool's next result if the call was rejected.
+func (r *recordedResults) takeRejection(tool string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	queue := r.byTool[tool]
+	if len(queue) == 0 || !queue[0].rejected {
+		return false
+	}
+	r.byTool[tool] = queue[1:]
+	return true
+}
+
+// take consumes the tool's next executed result, skipping rejections the
+// rerun did not ask about.
+func (r *recordedResults) take(tool string) (recordedResult, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	queue := r.byTool[tool]
+	for len(queue) > 0 && queue[0].rejected {
+		queue = queue[1:]
+	}
+	if len(queue) == 0 {
+		r.byTool[tool] = nil
+		return recordedResult{}, false
+	}
+	r.byTool[tool] = queue[1:]
+	return queue[0], true
+}
+
+// stubTool stands in for a recorded tool, returning its recorded results. It
+// is previewable so the agent asks for approval as the real tool would.
+type stubTool struct {
+	name    string
+	results *recordedResults
+}
+
+func (t *stubTool) Name() string { return t.name }
+
+func (t *stubTool) Description() string {
+	return fmt.Sprintf("Replays the recorded results of %s.", t.name)
+}
+
+func (t *stubTool) Schema() map[string]any {
+	return map[string]any{"type": "object"}
+}
+
+func (t *stubTool) Execute(ctx context.Context, argumentsXML []byte) (string, map[string]any, error) {
+	result, ok := t.results.take(t.name)
+	if !ok {
+		return "", nil, fmt.Errorf("replay: the recording has no further results for %s", t.name)
+	}
+	return result.output, result.metadata, result.err
+}
+
+func (t *stubTool) IsLoopBreaking() bool { return false }
+
+func (t *stubTool) GeneratePreview(ctx context.Context, argumentsXML []byte) (*tools.ToolPreview, error) {
+	return &tools.ToolPreview{
+		Type:    tools.PreviewTypeCommand,
+		Title:   "Replay " + t.name,
+		Content: strings.TrimSpace(string(argumentsXML)),
+	}, nil
+}
@@ -0,0 +1,253 @@
+package recording
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// scriptedProvider streams one scripted response per call.
+type scriptedProvider struct {
+	mu        sync.Mutex
+	responses []string
+}
+
+func (p *scriptedProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch := make(chan *llm.StreamChunk, 2)
+	if len(p.responses) > 0 {
+		ch <- &llm.StreamChunk{Role: "assistant", Content: p.responses[0]}
+		p.responses = p.responses[1:]
+	}
+	ch <- &llm.StreamChunk{Finished: true, Usage: &llm.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}}
+	close(ch)
+	return ch, nil
+}
+
+func (p *scriptedProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	return types.NewMessage(types.RoleAssistant, ""), nil
+}
+
+func (p *scriptedProvider) AnalyzeDocument(ctx context.Context, fileData []byte, mediaType string, prompt string) (string, error) {
+	return "", nil
+}
+
+func (p *scriptedProvider) GetModelInfo() *types.ModelInfo { return &types.ModelInfo{Name: "scripted"} }
+func (p *scriptedProvider) GetModel() string               { return "scripted" }
+func (p *scriptedProvider) GetBaseURL() string             { return "" }
+func (p *scriptedProvider) GetAPIKey() string              { return "" }
+
+// lookupTool is a tool whose result the rerun must take from the recording.
+type lookupTool struct{ calls int }
+
+func (t *lookupTool) Name() string           { return "lookup" }
+func (t *lookupTool) Description() string    { return "Looks up a key" }
+func (t *lookupTool) Schema() map[string]any { return map[string]any{"type": "object"} }
+func (t *lookupTool) IsLoopBreaking() bool   { return false }
+
+func (t *lookupTool) Execute(ctx context.Context, argumentsXML []byte) (string, map[string]any, error) {
+	t.calls++
+	return "value-42", map[string]any{"hits": 1}, nil
+}
+
+func toolCallXML(tool, args string) string {
+	return "<tool>\n<server_name>local</server_name>\n<tool_name>" + tool + "</tool_name>\n<arguments>\n" + args + "\n</arguments>\n</tool>"
+}
+
+// recordSession runs one turn that calls lookup and then completes, and
+// returns the recorded bundle.
+func recordSession(t *testing.T) *Bundle {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	rec, err := NewRecorder(path, Header{Model: "scripted", ToolCallMode: llm.ToolCallModeXML})
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+
+	provider := &scriptedProvider{responses: []string{
+		"Looking it up.\n" + toolCallXML("lookup", "<key>answer</key>"),
+		toolCallXML("task_completion", "<result>The answer is value-42</result>"),
+	}}
+	ag := agent.NewDefaultAgent(provider, agent.WithRecorder(rec))
+	tool := &lookupTool{}
+	if err := ag.RegisterTool(tool); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := ag.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	channels := ag.GetChannels()
+	channels.Input <- types.NewUserInput("what is the answer?")
+	waitForIdle(t, channels)
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	_ = ag.Shutdown(shutdownCtx)
+	for range channels.Event {
+	}
+
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if tool.calls != 1 {
+		t.Fatalf("lookup ran %d times while recording, want 1", tool.calls)
+	}
+
+	bundle, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return bundle
+}
+
+// waitForIdle waits for the agent to report it is no longer busy, which it
+// does after the turn end event.
+func waitForIdle(t *testing.T, channels *types.AgentChannels) {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-channels.Event:
+			if event.Type == types.EventTypeUpdateBusy && !event.IsBusy {
+				return
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the turn to end")
+		}
+	}
+}
+
+func TestRecorderCapturesSession(t *testing.T) {
+	bundle := recordSession(t)
+
+	if bundle.Header.Version != FormatVersion || bundle.Header.Model != "scripted" {
+		t.Errorf("unexpected header: %+v", bundle.Header)
+	}
+	if inputs := bundle.Inputs(); len(inputs) != 1 || inputs[0] != "what is the answer?" {
+		t.Errorf("inputs = %q", inputs)
+	}
+	if calls := bundle.LLMCalls(); len(calls) != 2 {
+		t.Fatalf("recorded %d LLM calls, want 2", len(calls))
+	}
+
+	var result *types.AgentEvent
+	for _, event := range bundle.Events() {
+		if event.Type == types.EventTypeToolResult && event.ToolName == "lookup" {
+			result = event.AgentEvent()
+		}
+	}
+	if result == nil {
+		t.Fatal("lookup result event was not recorded")
+	}
+	if result.ToolOutput != "value-42" {
+		t.Errorf("ToolOutput = %v, want value-42", result.ToolOutput)
+	}
+	if hits, ok := result.Metadata["hits"].(int); !ok || hits != 1 {
+		t.Errorf("metadata hits = %#v, want int 1", result.Metadata["hits"])
+	}
+}
+
+func TestRerunMatchesRecording(t *testing.T) {
+	bundle := recordSession(t)
+
+	report, err := Rerun(context.Background(), bundle)
+	if err != nil {
+		t.Fatalf("Rerun failed: %v", err)
+	}
+	if report.Diverged() {
+		t.Fatalf("unexpected divergences: %v", report.Divergences)
+	}
+	if report.Inputs != 1 || report.ToolCalls != 2 {
+		t.Errorf("report = %+v, want 1 input and 2 tool calls", report)
+	}
+}
+
+func TestRerunReportsDivergence(t *testing.T) {
+	bundle := recordSession(t)
+
+	// An agent without the lookup tool answers the model's call with an
+	// error, so the next request and the tool calls no longer match
+	report, err := Rerun(context.Background(), bundle, agent.WithDisabledTools("lookup"))
+	if err != nil {
+		t.Fatalf("Rerun failed: %v", err)
+	}
+	if !report.Diverged() {
+		t.Fatal("expected divergences")
+	}
+
+	kinds := make(map[DivergenceKind]bool)
+	for _, d := range report.Divergences {
+		kinds[d.Kind] = true
+	}
+	if !kinds[DivergenceRequest] || !kinds[DivergenceToolCall] {
+		t.Errorf("divergences = %v, want request and tool_call", report.Divergences)
+	}
+}
+
+func TestRerunReplaysRejections(t *testing.T) {
+	bundle := recordSession(t)
+
+	// Turn the recorded lookup call into one the user rejected
+	var entries []Entry
+	for _, entry := range bundle.Entries {
+		if entry.Kind == EntryEvent && entry.Event.ToolName == "lookup" {
+			switch entry.Event.Type {
+			case types.EventTypeToolCall:
+				entry.Event = &Event{Type: types.EventTypeToolApprovalRejected, ToolName: "lookup", ApprovalID: "a1"}
+			case types.EventTypeToolResult:
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	bundle.Entries = entries
+
+	report, err := Rerun(context.Background(), bundle)
+	if err != nil {
+		t.Fatalf("Rerun failed: %v", err)
+	}
+
+	// The rejection changes what the model is told next
+	var request *Divergence
+	for i := range report.Divergences {
+		if report.Divergences[i].Kind == DivergenceRequest {
+			request = &report.Divergences[i]
+		}
+	}
+	if request == nil || !strings.Contains(request.Got, "rejected by user") {
+		t.Errorf("divergences = %v, want the rejection sent to the model", report.Divergences)
+	}
+}
+
+func TestReadRejectsMissingHeader(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"seq":0,"kind":"input","input":"hi"}` + "\n"))
+	if err == nil || !strings.Contains(err.Error(), "missing header") {
+		t.Errorf("Read error = %v, want missing header", err)
+	}
+}
+
+func TestReadIgnoresTruncatedEntry(t *testing.T) {
+	data := `{"seq":0,"kind":"header","header":{"version":1}}` + "\n" +
+		`{"seq":1,"kind":"input","input":"hi"}` + "\n" +
+		`{"seq":2,"kind":"event","event":{"type":"mess`
+	bundle, err := Read(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if inputs := bundle.Inputs(); len(inputs) != 1 {
+		t.Errorf("inputs = %q, want one", inputs)
+	}
+}
@@ -0,0 +1,229 @@
+// Package repocontext loads AGENTS.md files into the agent's repository
+// context. The workspace root's AGENTS.md is loaded up front; in a monorepo,
+// the AGENTS.md of each directory the agent works in is added as it gets
+// there, so package-specific conventions arrive when they become relevant
+// instead of all at once.
+package repocontext
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileName is the file repository context is read from
+const FileName = "AGENTS.md"
+
+// DefaultMaxTokens is the repository context budget when none is configured
+const DefaultMaxTokens = 8000
+
+// Options configures a Loader.
+type Options struct {
+	// MaxTokens bounds the whole repository context. 0 uses DefaultMaxTokens.
+	MaxTokens int
+	// Nested loads the AGENTS.md of directories the agent touches, not just
+	// the workspace root's.
+	Nested bool
+	// CountTokens counts the tokens in a string. nil estimates four bytes
+	// per token.
+	CountTokens func(string) int
+}
+
+// file is a loaded AGENTS.md
+type file struct {
+	rel     string // Workspace-relative path, e.g. "packages/web/AGENTS.md"
+	content string
+}
+
+// Loader assembles the repository context from the workspace's AGENTS.md
+// files. It is safe for concurrent use.
+type Loader struct {
+	mu      sync.Mutex
+	root    string
+	opts    Options
+	files   []file          // Root first, then nested files in the order they were found
+	checked map[string]bool // Directories already looked at
+}
+
+// NewLoader creates a loader for workspaceDir and loads the root AGENTS.md.
+func NewLoader(workspaceDir string, opts Options) (*Loader, error) {
+	root, err := filepath.Abs(workspaceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace directory: %w", err)
+	}
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = DefaultMaxTokens
+	}
+	if opts.CountTokens == nil {
+		opts.CountTokens = estimateTokens
+	}
+
+	l := &Loader{root: root, opts: opts, checked: make(map[string]bool)}
+	l.load(root)
+	return l, nil
+}
+
+// Touch records that the agent worked on path, a file or directory given
+// relative to the workspace or absolute, and loads the AGENTS.md of every
+// directory between the workspace root and path that has not been checked
+// yet. It reports whether the repository context changed. Paths outside the
+// workspace, including "@root/..." paths, are ignored.
+func (l *Loader) Touch(path string) bool {
+	if !l.opts.Nested || path == "" || strings.HasPrefix(path, "@") {
+		return false
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.root, path)
+	}
+	path = filepath.Clean(path)
+
+	rel, err := filepath.Rel(l.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	// A file's own directory is the deepest that applies
+	dir := path
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	rel, _ = filepath.Rel(l.root, dir)
+	if rel == "." {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	changed := false
+	current := l.root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		if l.checked[current] {
+			continue
+		}
+		if l.loadLocked(current) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Files returns the workspace-relative paths of the loaded AGENTS.md files.
+func (l *Loader) Files() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	paths := make([]string, len(l.files))
+	for i, f := range l.files {
+		paths[i] = f.rel
+	}
+	return paths
+}
+
+// Context renders the loaded files as the repository context, within the
+// token budget. The root file comes first; each nested file is introduced
+// with the directory it applies to and takes precedence over the ones before
+// it. When the files do not all fit, the most recently found nested files
+// are kept and the rest are named so the agent can read them itself. It
+// returns "" when no AGENTS.md was found.
+func (l *Loader) Context() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.files) == 0 {
+		return ""
+	}
+
+	sections := make([]string, len(l.files))
+	for i, f := range l.files {
+		sections[i] = l.section(f, i == 0 && f.rel == FileName)
+	}
+
+	budget := l.opts.MaxTokens
+	keep := make([]bool, len(sections))
+	// The root file is kept whatever its size, cut down if need be
+	start := 0
+	if l.files[0].rel == FileName {
+		sections[0] = l.truncate(sections[0], budget)
+		budget -= l.opts.CountTokens(sections[0])
+		keep[0] = true
+		start = 1
+	}
+	for i := len(sections) - 1; i >= start; i-- {
+		if tokens := l.opts.CountTokens(sections[i]); tokens <= budget {
+			keep[i] = true
+			budget -= tokens
+		}
+	}
+
+	var kept, omitted []string
+	for i, section := range sections {
+		if keep[i] {
+			kept = append(kept, section)
+		} else {
+			omitted = append(omitted, l.files[i].rel)
+		}
+	}
+	if len(omitted) > 0 {
+		kept = append(kept, fmt.Sprintf("Not loaded to stay within the %d-token repository context budget; read them with read_file before working in their directories: %s",
+			l.opts.MaxTokens, strings.Join(omitted, ", ")))
+	}
+	return strings.Join(kept, "\n\n")
+}
+
+// section renders one file. The root file is rendered as is, as it always
+// has been.
+func (l *Loader) section(f file, isRoot bool) string {
+	if isRoot {
+		return f.content
+	}
+	dir := filepath.ToSlash(filepath.Dir(f.rel))
+	return fmt.Sprintf("---\nFrom %s, for files under %s/ (takes precedence over the instructions above):\n\n%s", f.rel, dir, f.content)
+}
+
+// truncate cuts section down to about budget tokens on a line boundary,
+// noting the cut.
+func (l *Loader) truncate(section string, budget int) string {
+	tokens := l.opts.CountTokens(section)
+	if tokens <= budget || tokens == 0 {
+		return section
+	}
+	cut := len(section) * budget / tokens
+	if i := strings.LastIndexByte(section[:cut], '\n'); i > 0 {
+		cut = i
+	}
+	return section[:cut] + fmt.Sprintf("\n\n[%s truncated to fit the %d-token repository context budget; read the file for the rest]", FileName, l.opts.MaxTokens)
+}
+
+// load checks dir for an AGENTS.md, taking the lock.
+func (l *Loader) load(dir string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.loadLocked(dir)
+}
+
+// loadLocked checks dir for an AGENTS.md and adds it when it has content.
+func (l *Loader) loadLocked(dir string) bool {
+	l.checked[dir] = true
+	path := filepath.Join(dir, FileName)
+	data, err := os.ReadFile(path) //nolint:gosec // path is inside the workspace
+	if err != nil {
+		return false
+	}
+	content := strings.TrimSpace(string(data))
+	if content == "" {
+		return false
+	}
+
+	rel, err := filepath.Rel(l.root, path)
+	if err != nil {
+		return false
+	}
+	l.files = append(l.files, file{rel: filepath.ToSlash(rel), content: content})
+	return true
+}
+
+// estimateTokens approximates a token count at four bytes per token
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
@@ -0,0 +1,98 @@
+package repocontext
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeAgentsMD writes content to dir/AGENTS.md under root, creating dir.
+func writeAgentsMD(t *testing.T, root, dir, content string) {
+	t.Helper()
+	full := filepath.Join(root, dir)
+	if err := os.MkdirAll(full, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(full, FileName), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoader_Nested(t *testing.T) {
+	root := t.TempDir()
+	writeAgentsMD(t, root, ".", "Run make test.")
+	writeAgentsMD(t, root, "packages/web", "Use pnpm.")
+	writeAgentsMD(t, root, "packages/api", "Use go test.")
+
+	loader, err := NewLoader(root, Options{Nested: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loader.Context(); got != "Run make test." {
+		t.Errorf("Context() = %q, want only the root file", got)
+	}
+
+	if !loader.Touch("packages/web/src/app.ts") {
+		t.Fatal("Touch() should report the nested AGENTS.md")
+	}
+	if loader.Touch("packages/web/src/other.ts") {
+		t.Error("Touch() should not reload a directory already checked")
+	}
+	if loader.Touch(filepath.Join(root, "..", "elsewhere", "file.go")) || loader.Touch("@proto/api.proto") {
+		t.Error("Touch() should ignore paths outside the workspace")
+	}
+
+	got := loader.Context()
+	if !strings.HasPrefix(got, "Run make test.\n\n---\nFrom packages/web/AGENTS.md, for files under packages/web/") ||
+		!strings.HasSuffix(got, "Use pnpm.") {
+		t.Errorf("Context() = %q", got)
+	}
+	if files := loader.Files(); len(files) != 2 || files[1] != "packages/web/AGENTS.md" {
+		t.Errorf("Files() = %v", files)
+	}
+}
+
+func TestLoader_NestedDisabled(t *testing.T) {
+	root := t.TempDir()
+	writeAgentsMD(t, root, "pkg", "Nested.")
+
+	loader, err := NewLoader(root, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loader.Touch("pkg/file.go") || loader.Context() != "" {
+		t.Errorf("nested files should not load, got %q", loader.Context())
+	}
+}
+
+func TestLoader_Budget(t *testing.T) {
+	root := t.TempDir()
+	writeAgentsMD(t, root, ".", strings.Repeat("root line\n", 20))
+	writeAgentsMD(t, root, "a", strings.Repeat("a", 200))
+	writeAgentsMD(t, root, "b", "Short.")
+
+	loader, err := NewLoader(root, Options{Nested: true, MaxTokens: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader.Touch("a/x.go")
+	loader.Touch("b/y.go")
+
+	got := loader.Context()
+	if !strings.Contains(got, "Short.") {
+		t.Errorf("the most recently found file should be kept: %q", got)
+	}
+	if strings.Contains(got, strings.Repeat("a", 200)) || !strings.Contains(got, "read_file before working in their directories: a/AGENTS.md") {
+		t.Errorf("the file over budget should be named, not loaded: %q", got)
+	}
+
+	// A root file over the whole budget is cut down
+	loader, err = NewLoader(root, Options{MaxTokens: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loader.Context(); !strings.Contains(got, "AGENTS.md truncated to fit the 10-token") || len(got) > 200 {
+		t.Errorf("Context() = %q, want the root file truncated", got)
+	}
+}
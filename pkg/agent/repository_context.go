@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"github.com/entrhq/forge/pkg/agent/repocontext"
+)
+
+// repositoryPathArgs are the tool arguments that name a file or directory the
+// agent is working in
+var repositoryPathArgs = []string{"path", "working_dir"}
+
+// WithRepositoryContextLoader sets the repository context from loader and
+// keeps it up to date: when a tool works in a directory with its own AGENTS.md,
+// that file is added to the context from the next LLM call. It replaces any
+// context set with WithRepositoryContext.
+func WithRepositoryContextLoader(loader *repocontext.Loader) AgentOption {
+	return func(a *DefaultAgent) {
+		a.repositoryContextLoader = loader
+		a.repositoryContext = loader.Context()
+	}
+}
+
+// getRepositoryContext returns the current repository context, if any
+func (a *DefaultAgent) getRepositoryContext() string {
+	a.repositoryContextMu.RLock()
+	defer a.repositoryContextMu.RUnlock()
+	return a.repositoryContext
+}
+
+// touchRepositoryContext passes the paths a tool call worked on to the
+// repository context loader, refreshing the context when it found another
+// AGENTS.md.
+func (a *DefaultAgent) touchRepositoryContext(args map[string]any) {
+	if a.repositoryContextLoader == nil {
+		return
+	}
+
+	changed := false
+	for _, key := range repositoryPathArgs {
+		if path, ok := args[key].(string); ok && a.repositoryContextLoader.Touch(path) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	updated := a.repositoryContextLoader.Context()
+	a.repositoryContextMu.Lock()
+	a.repositoryContext = updated
+	a.repositoryContextMu.Unlock()
+	agentDebugLog.Infof("Repository context now includes %v", a.repositoryContextLoader.Files())
+}
@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/repocontext"
+)
+
+func TestTouchRepositoryContext(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, repocontext.FileName), []byte("Root rules."), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "svc"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "svc", repocontext.FileName), []byte("Service rules."), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loader, err := repocontext.NewLoader(root, repocontext.Options{Nested: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &DefaultAgent{}
+	WithRepositoryContextLoader(loader)(a)
+	if got := a.getRepositoryContext(); got != "Root rules." {
+		t.Fatalf("repository context = %q, want the root file", got)
+	}
+
+	a.touchRepositoryContext(map[string]any{"path": "svc/main.go"})
+	if got := a.getRepositoryContext(); !strings.Contains(got, "Service rules.") {
+		t.Errorf("repository context = %q, want the nested file after touching svc/", got)
+	}
+}
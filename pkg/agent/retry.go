@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent/memory"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// RewindLastTurn removes the most recent message the user sent, and
+// everything the agent added after it, from the context. Call it between
+// turns. It fails when that message has already been summarized, since the
+// turn can then no longer be separated from the rest of the history.
+func (a *DefaultAgent) RewindLastTurn() error {
+	convMem, ok := a.memory.(*memory.ConversationMemory)
+	if !ok {
+		return fmt.Errorf("memory type %T does not support rewinding", a.memory)
+	}
+	if a.lastUserMessage == nil {
+		return fmt.Errorf("there is no previous message to retry")
+	}
+	if convMem.TruncateFrom(a.lastUserMessage) == 0 {
+		return fmt.Errorf("the last message has been summarized and can no longer be retried; send it again instead")
+	}
+	a.lastUserMessage = nil
+	return nil
+}
+
+// handleRetryRequest redoes the last turn on behalf of /retry and message
+// editing: the turn is removed from the context so the model never sees the
+// failed attempt, then the message is processed as if sent for the first time.
+func (a *DefaultAgent) handleRetryRequest(ctx context.Context, input *types.Input) {
+	params, _ := input.Metadata["params"].(types.RetryRequestParams)
+
+	if err := a.RewindLastTurn(); err != nil {
+		a.emitEvent(types.NewErrorEvent(err))
+		a.emitEvent(types.NewTurnEndEvent())
+		return
+	}
+	agentDebugLog.Printf("Rewound the last turn for a retry")
+
+	if a.recorder != nil {
+		a.recorder.RecordInput(params.Content)
+	}
+	a.processUserInput(ctx, params.Content)
+}
@@ -0,0 +1,178 @@
+// Package todo holds the agent's plan for the current task: an ordered list of
+// steps, each with a status. Unlike scratchpad notes, the list is meant to be
+// shown to the user as a live checklist of the agent's progress.
+package todo
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// MaxItems is the largest number of steps a list can hold
+	MaxItems = 30
+
+	// MaxContentLength is the maximum number of characters in a step
+	MaxContentLength = 200
+
+	// MetadataKey is the tool result metadata key holding a snapshot of the
+	// list ([]Item) after the todo tools change it
+	MetadataKey = "todo_list"
+)
+
+// Status is the state of a step
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+	StatusCancelled  Status = "cancelled"
+)
+
+// ParseStatus validates a status name.
+func ParseStatus(s string) (Status, error) {
+	switch status := Status(strings.TrimSpace(s)); status {
+	case StatusPending, StatusInProgress, StatusCompleted, StatusCancelled:
+		return status, nil
+	default:
+		return "", fmt.Errorf("invalid status: %s (must be '%s', '%s', '%s', or '%s')", s, StatusPending, StatusInProgress, StatusCompleted, StatusCancelled)
+	}
+}
+
+// Done reports whether no work remains on a step with this status.
+func (s Status) Done() bool {
+	return s == StatusCompleted || s == StatusCancelled
+}
+
+// Icon returns the checklist marker for the status.
+func (s Status) Icon() string {
+	switch s {
+	case StatusInProgress:
+		return "▸"
+	case StatusCompleted:
+		return "✓"
+	case StatusCancelled:
+		return "✗"
+	default:
+		return "○"
+	}
+}
+
+// Item is one step of the plan. Steps are numbered from 1 in list order.
+type Item struct {
+	Step    int    `json:"step"`
+	Content string `json:"content"`
+	Status  Status `json:"status"`
+}
+
+// List is the agent's plan. All operations are thread-safe and session-scoped
+// (in-memory only).
+type List struct {
+	mu    sync.RWMutex
+	items []Item
+}
+
+// NewList creates an empty list
+func NewList() *List {
+	return &List{}
+}
+
+// Set replaces the list with steps, all pending.
+func (l *List) Set(steps []string) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("a todo list needs at least one step")
+	}
+	if len(steps) > MaxItems {
+		return fmt.Errorf("a todo list can have at most %d steps, got %d", MaxItems, len(steps))
+	}
+
+	items := make([]Item, len(steps))
+	for i, step := range steps {
+		content, err := validateContent(step)
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+		items[i] = Item{Step: i + 1, Content: content, Status: StatusPending}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.items = items
+	return nil
+}
+
+// Update sets the status of step, and its content when content is not empty.
+func (l *List) Update(step int, status Status, content string) (Item, error) {
+	if content != "" {
+		var err error
+		if content, err = validateContent(content); err != nil {
+			return Item{}, err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.items) == 0 {
+		return Item{}, fmt.Errorf("no todo list exists; create one with create_todo_list first")
+	}
+	if step < 1 || step > len(l.items) {
+		return Item{}, fmt.Errorf("step %d does not exist (the list has steps 1-%d)", step, len(l.items))
+	}
+
+	item := &l.items[step-1]
+	item.Status = status
+	if content != "" {
+		item.Content = content
+	}
+	return *item, nil
+}
+
+// Items returns a copy of the steps in order.
+func (l *List) Items() []Item {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]Item(nil), l.items...)
+}
+
+// Format renders items as a plain-text checklist with a progress line.
+func Format(items []Item) string {
+	if len(items) == 0 {
+		return "The todo list is empty."
+	}
+
+	var b strings.Builder
+	done, _ := Progress(items)
+	fmt.Fprintf(&b, "Todo list (%d/%d done):\n", done, len(items))
+	for _, item := range items {
+		fmt.Fprintf(&b, "%s %d. %s", item.Status.Icon(), item.Step, item.Content)
+		if item.Status == StatusInProgress || item.Status == StatusCancelled {
+			fmt.Fprintf(&b, " (%s)", item.Status)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Progress returns how many of items are done, and whether all of them are.
+func Progress(items []Item) (done int, complete bool) {
+	for _, item := range items {
+		if item.Status.Done() {
+			done++
+		}
+	}
+	return done, len(items) > 0 && done == len(items)
+}
+
+func validateContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", fmt.Errorf("step content cannot be empty")
+	}
+	if len(content) > MaxContentLength {
+		return "", fmt.Errorf("step content exceeds %d characters", MaxContentLength)
+	}
+	return content, nil
+}
@@ -0,0 +1,84 @@
+package todo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestList_SetAndUpdate(t *testing.T) {
+	list := NewList()
+	if err := list.Set([]string{" Read the code ", "Write the fix", "Run the tests"}); err != nil {
+		t.Fatal(err)
+	}
+
+	items := list.Items()
+	if len(items) != 3 || items[0].Content != "Read the code" || items[2].Step != 3 || items[1].Status != StatusPending {
+		t.Fatalf("items = %+v", items)
+	}
+
+	if _, err := list.Update(1, StatusCompleted, ""); err != nil {
+		t.Fatal(err)
+	}
+	item, err := list.Update(2, StatusInProgress, "Write the fix and a test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Content != "Write the fix and a test" || item.Status != StatusInProgress {
+		t.Errorf("updated item = %+v", item)
+	}
+
+	// Items returns a copy
+	items = list.Items()
+	items[0].Content = "changed"
+	if list.Items()[0].Content != "Read the code" {
+		t.Error("expected Items to return a copy")
+	}
+
+	if done, complete := Progress(list.Items()); done != 1 || complete {
+		t.Errorf("progress = %d, %v", done, complete)
+	}
+}
+
+func TestList_Errors(t *testing.T) {
+	list := NewList()
+	if _, err := list.Update(1, StatusCompleted, ""); err == nil {
+		t.Error("expected updating an empty list to fail")
+	}
+	if err := list.Set(nil); err == nil {
+		t.Error("expected an empty plan to be rejected")
+	}
+	if err := list.Set([]string{"ok", "  "}); err == nil || !strings.Contains(err.Error(), "step 2") {
+		t.Errorf("expected the blank step to be reported, got %v", err)
+	}
+	if err := list.Set(make([]string, MaxItems+1)); err == nil {
+		t.Error("expected too many steps to be rejected")
+	}
+
+	if err := list.Set([]string{"only step"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := list.Update(2, StatusCompleted, ""); err == nil {
+		t.Error("expected an unknown step to be rejected")
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	if status, err := ParseStatus("in_progress"); err != nil || status != StatusInProgress {
+		t.Errorf("ParseStatus = %v, %v", status, err)
+	}
+	if _, err := ParseStatus("done"); err == nil {
+		t.Error("expected an unknown status to be rejected")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	items := []Item{
+		{Step: 1, Content: "Read", Status: StatusCompleted},
+		{Step: 2, Content: "Write", Status: StatusInProgress},
+		{Step: 3, Content: "Ship", Status: StatusCancelled},
+	}
+	want := "Todo list (2/3 done):\n✓ 1. Read\n▸ 2. Write (in_progress)\n✗ 3. Ship (cancelled)"
+	if got := Format(items); got != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"maps"
 
+	"github.com/entrhq/forge/pkg/agent/memory/vector"
 	"github.com/entrhq/forge/pkg/agent/prompts"
 	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/tools/coding"
 	"github.com/entrhq/forge/pkg/types"
 )
 
-// executeToolCall emits events, executes the tool, and handles execution errors
+// executeToolCall emits events, executes the tool, and handles execution errors.
+// hookContext is output from pre-tool-call hooks to include with the result.
 // Returns (result, metadata, shouldContinue, errorContext)
-func (a *DefaultAgent) executeToolCall(ctx context.Context, tool tools.Tool, toolCall tools.ToolCall) (string, map[string]any, bool, string) {
+func (a *DefaultAgent) executeToolCall(ctx context.Context, tool tools.Tool, toolCall tools.ToolCall, hookContext string) (string, map[string]any, bool, string) {
 	// Emit tool call event - parse arguments to map for event emission
 	argsMap, err := tools.XMLToMap(toolCall.GetArgumentsXML())
 	if err != nil {
@@ -27,7 +30,12 @@ func (a *DefaultAgent) executeToolCall(ctx context.Context, tool tools.Tool, too
 	ctxWithRegistry := context.WithValue(ctxWithEmitter, coding.CommandRegistryKey, &a.activeCommands)
 
 	// Execute the tool
-	result, metadata, toolErr := tool.Execute(ctxWithRegistry, toolCall.GetArgumentsXML())
+	result, metadata, toolErr := a.runTool(ctxWithRegistry, tool, toolCall.GetArgumentsXML())
+	if toolErr == nil && !tool.IsLoopBreaking() {
+		result = a.limitToolResult(toolCall.ToolName, result)
+	}
+	a.touchRepositoryContext(argsMap)
+	result, toolErr = a.runPostToolCallHooks(ctx, toolCall, hookContext, result, toolErr)
 
 	if toolErr != nil {
 		a.emitEvent(types.NewToolResultErrorEvent(toolCall.ID, toolCall.ToolName, toolErr))
@@ -58,11 +66,21 @@ func (a *DefaultAgent) processToolResult(tool tools.Tool, toolCall tools.ToolCal
 	if len(metadata) > 0 {
 		maps.Copy(event.Metadata, metadata)
 	}
+	// The image goes to the model, not to the executors
+	image, hasImage := metadata[types.MetadataImage].(types.Image)
+	delete(event.Metadata, types.MetadataImage)
 	a.emitEvent(event)
 
 	// Success! Reset error tracking
 	a.resetErrorTracking()
 
+	if a.vectorMemory != nil && toolCall.ToolName == "task_completion" {
+		a.vectorMemory.Remember(vector.KindTaskCompletion, result, a.sessionID)
+	}
+	if toolCall.ToolName == "task_completion" {
+		a.extractLearnings()
+	}
+
 	// Check if this is a loop-breaking tool
 	if tool.IsLoopBreaking() {
 		return false, ""
@@ -73,7 +91,24 @@ func (a *DefaultAgent) processToolResult(tool tools.Tool, toolCall tools.ToolCal
 	// identify and group tool call / result pairs. BuildMessages remaps
 	// RoleTool -> RoleUser before sending to the LLM (XML-mode providers
 	// don't have a native tool role).
-	a.memory.Add(types.NewToolMessage(fmt.Sprintf("Tool '%s' result:\n%s", toolCall.ToolName, result)))
+	result = a.guardToolResult(toolCall.ToolName, result)
+	if hasImage && !llm.AcceptsImages(a.provider) {
+		result += "\n\nThe image was not attached because the current model does not accept image input. Use analyze_document to have it described instead."
+		hasImage = false
+	}
+	msg := types.NewToolMessage(fmt.Sprintf("Tool '%s' result:\n%s", toolCall.ToolName, result))
+	if hasImage {
+		msg.Images = []types.Image{image}
+	}
+	if a.nativeToolCalls() {
+		// Native tool results answer their call by ID
+		msg.ToolCallID = toolCall.ID
+	}
+	if pinned, _ := metadata[types.MetadataPinned].(bool); pinned {
+		// Tools such as pin_context ask for their result to survive summarization
+		msg.WithMetadata(types.MetadataPinned, true)
+	}
+	a.memory.Add(msg)
 	return true, ""
 }
 
@@ -97,18 +132,21 @@ func (a *DefaultAgent) handleToolApproval(ctx context.Context, tool tools.Tool,
 	}
 
 	// Request approval from user
-	approved, timedOut := a.requestApproval(ctx, toolCall, preview)
+	outcome := a.requestApproval(ctx, toolCall, preview)
 
-	if timedOut {
+	if outcome.TimedOut {
 		// Timeout - treat as rejection and continue loop without executing
 		errMsg := fmt.Sprintf("Tool approval request timed out after %v. The tool was not executed.", a.approvalTimeout)
 		a.memory.Add(types.NewUserMessage(errMsg))
 		return false
 	}
 
-	if !approved {
-		// User rejected - continue loop without executing
+	if !outcome.Approved {
+		// Rejected - continue loop without executing
 		errMsg := fmt.Sprintf("Tool '%s' execution was rejected by user.", toolCall.ToolName)
+		if outcome.Reason != "" {
+			errMsg = fmt.Sprintf("Tool '%s' execution was rejected by %s. Do not retry it; find another approach or ask the user.", toolCall.ToolName, outcome.Reason)
+		}
 		a.memory.Add(types.NewUserMessage(errMsg))
 		return false
 	}
@@ -138,18 +176,30 @@ func (a *DefaultAgent) lookupTool(toolName string) (tools.Tool, bool, string) {
 		return nil, true, errMsg
 	}
 
+	// A tool called before its group was loaded has its schema sent from now on
+	a.loadToolGroupOf(toolName)
+
 	return tool, true, ""
 }
 
 // executeTool handles tool lookup, execution, and result processing
 // Returns (shouldContinue, errorContext) following the same pattern as executeIteration
 func (a *DefaultAgent) executeTool(ctx context.Context, toolCall tools.ToolCall) (bool, string) {
+	// Every call counts against the turn's budget, whether or not it succeeds
+	a.turnToolCalls++
+
 	// Look up the tool
 	tool, shouldContinue, errCtx := a.lookupTool(toolCall.ToolName)
 	if !shouldContinue || errCtx != "" {
 		return shouldContinue, errCtx
 	}
 
+	// Let hooks rewrite or veto the call before it is approved
+	hookContext, ok := a.runPreToolCallHooks(ctx, &toolCall)
+	if !ok {
+		return true, ""
+	}
+
 	// Handle tool approval if needed
 	if !a.handleToolApproval(ctx, tool, toolCall) {
 		// Tool approval was rejected or timed out - continue loop without executing
@@ -157,7 +207,7 @@ func (a *DefaultAgent) executeTool(ctx context.Context, toolCall tools.ToolCall)
 	}
 
 	// Execute the tool call
-	result, metadata, shouldContinue, errCtx := a.executeToolCall(ctx, tool, toolCall)
+	result, metadata, shouldContinue, errCtx := a.executeToolCall(ctx, tool, toolCall, hookContext)
 	if !shouldContinue || errCtx != "" {
 		return shouldContinue, errCtx
 	}
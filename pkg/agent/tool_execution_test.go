@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// visionProvider is a mockProvider whose model accepts images
+type visionProvider struct {
+	mockProvider
+}
+
+func (v *visionProvider) GetModel() string {
+	return "gpt-4o"
+}
+
+func TestProcessToolResult_Image(t *testing.T) {
+	image := types.Image{MediaType: "image/png", Data: []byte("png")}
+	metadata := map[string]any{"path": "bug.png", types.MetadataImage: image}
+	call := tools.ToolCall{ID: "call_1", ToolName: "view_image"}
+
+	t.Run("attached for vision models", func(t *testing.T) {
+		a := NewDefaultAgent(&visionProvider{}, WithBufferSize(100))
+		a.processToolResult(&recordingTool{}, call, "Image: bug.png", metadata)
+
+		messages := a.memory.GetAll()
+		if len(messages) != 1 || len(messages[0].Images) != 1 || messages[0].Images[0].MediaType != "image/png" {
+			t.Fatalf("expected the tool result to carry the image, got %+v", messages)
+		}
+
+		// Executors see the result without the image
+		event := <-a.GetChannels().Event
+		if _, ok := event.Metadata[types.MetadataImage]; ok {
+			t.Errorf("expected the image to be left out of the tool result event")
+		}
+		if event.Metadata["path"] != "bug.png" {
+			t.Errorf("expected the rest of the metadata on the event, got %+v", event.Metadata)
+		}
+	})
+
+	t.Run("described for other models", func(t *testing.T) {
+		a := NewDefaultAgent(&mockProvider{}, WithBufferSize(100))
+		a.processToolResult(&recordingTool{}, call, "Image: bug.png", metadata)
+
+		messages := a.memory.GetAll()
+		if len(messages) != 1 || len(messages[0].Images) != 0 {
+			t.Fatalf("expected a tool result without images, got %+v", messages)
+		}
+		if !strings.Contains(messages[0].Content, "analyze_document") {
+			t.Errorf("expected a pointer to analyze_document, got %q", messages[0].Content)
+		}
+	})
+}
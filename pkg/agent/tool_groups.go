@@ -0,0 +1,272 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/prompts"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/config"
+)
+
+// ToolGroup is a set of related tools whose schemas can be left out of the
+// prompt until the agent loads them, leaving a one-line manifest entry.
+type ToolGroup struct {
+	Name        string
+	Description string   // What the tools are for, shown in the manifest
+	Tools       []string // Tool names; tools that are not registered are skipped
+}
+
+// DefaultToolGroups returns the groups of built-in tools used for occasional
+// tasks. Tools outside every group, such as the file and command tools, always
+// have their schemas in the prompt.
+func DefaultToolGroups() []ToolGroup {
+	return []ToolGroup{
+		{
+			Name:        "browser",
+			Description: "Drive a browser to test, inspect or interact with web pages",
+			Tools: []string{
+				"start_browser_session", "close_browser_session", "list_browser_sessions",
+				"browser_navigate", "browser_click", "browser_fill_form", "browser_search",
+				"browser_extract_content", "analyze_page", "browser_wait", "browser_screenshot",
+				"browser_evaluate",
+			},
+		},
+		{
+			Name:        "web",
+			Description: "Fetch web pages, call HTTP APIs and search the web",
+			Tools:       []string{"fetch_url", "http_request", "web_search"},
+		},
+		{
+			Name:        "terminal",
+			Description: "Interactive terminal sessions for REPLs, debuggers, TUIs and long-running processes",
+			Tools:       []string{"start_terminal", "terminal_send_keys", "terminal_read_screen", "list_terminals", "close_terminal"},
+		},
+		{
+			Name:        "scratchpad",
+			Description: "Notes that outlive context summarization: add, search, update and delete them",
+			Tools:       []string{"add_note", "list_notes", "search_notes", "list_tags", "scratch_note", "update_note", "delete_note"},
+		},
+		{
+			Name:        "custom_tools",
+			Description: "Create and run the user's own scripted tools",
+			Tools:       []string{"create_custom_tool", "run_custom_tool"},
+		},
+		{
+			Name:        "documents",
+			Description: "Read images, PDFs and other documents",
+			Tools:       []string{"view_image", "analyze_document"},
+		},
+	}
+}
+
+// WithToolSchemas applies a project's tool schema settings. With lazy
+// schemas, the tools of DefaultToolGroups are listed in a manifest instead of
+// having their schemas sent, and the load_tools tool loads them on demand.
+func WithToolSchemas(settings config.ToolSchemasConfig) AgentOption {
+	return func(a *DefaultAgent) {
+		if !settings.Lazy {
+			return
+		}
+		WithLazyTools(DefaultToolGroups(), settings.AlwaysLoaded...)(a)
+	}
+}
+
+// WithLazyTools leaves the schemas of groups' tools out of the prompt until
+// the agent loads them with load_tools or calls one of them. Groups named in
+// loaded are sent from the start.
+func WithLazyTools(groups []ToolGroup, loaded ...string) AgentOption {
+	return func(a *DefaultAgent) {
+		a.toolGroups = groups
+		a.loadedToolGroups = make(map[string]bool)
+		for _, name := range loaded {
+			if _, ok := a.findToolGroup(name); !ok {
+				agentDebugLog.Warnf("Ignoring unknown tool group %q", name)
+				continue
+			}
+			a.loadedToolGroups[name] = true
+		}
+	}
+}
+
+// findToolGroup returns the group with the given name. Groups are fixed once
+// the agent is created, so no lock is needed.
+func (a *DefaultAgent) findToolGroup(name string) (ToolGroup, bool) {
+	for _, group := range a.toolGroups {
+		if group.Name == name {
+			return group, true
+		}
+	}
+	return ToolGroup{}, false
+}
+
+// toolGroupOf returns the name of the group toolName belongs to, if any.
+func (a *DefaultAgent) toolGroupOf(toolName string) (string, bool) {
+	for _, group := range a.toolGroups {
+		if slices.Contains(group.Tools, toolName) {
+			return group.Name, true
+		}
+	}
+	return "", false
+}
+
+// toolGroupLoaded reports whether the group's schemas are sent.
+func (a *DefaultAgent) toolGroupLoaded(name string) bool {
+	a.toolGroupsMu.RLock()
+	defer a.toolGroupsMu.RUnlock()
+	return a.loadedToolGroups[name]
+}
+
+// LoadToolGroups loads the schemas of the named groups for the rest of the
+// session and describes the tools they make available.
+func (a *DefaultAgent) LoadToolGroups(names []string) (string, error) {
+	available := make(map[string]bool)
+	for _, tool := range a.getToolsList() {
+		available[tool.Name()] = true
+	}
+
+	var loaded []ToolGroup
+	for _, name := range names {
+		group, ok := a.findToolGroup(name)
+		if !ok {
+			return "", fmt.Errorf("unknown tool group %q; the tool manifest lists the groups that can be loaded", name)
+		}
+		loaded = append(loaded, group)
+	}
+
+	a.toolGroupsMu.Lock()
+	for _, group := range loaded {
+		a.loadedToolGroups[group.Name] = true
+	}
+	a.toolGroupsMu.Unlock()
+
+	var builder strings.Builder
+	for _, group := range loaded {
+		var toolNames []string
+		for _, name := range group.Tools {
+			if available[name] {
+				toolNames = append(toolNames, name)
+			}
+		}
+		if len(toolNames) == 0 {
+			fmt.Fprintf(&builder, "Loaded %s, but none of its tools are available right now.\n", group.Name)
+			continue
+		}
+		fmt.Fprintf(&builder, "Loaded %s: %s\n", group.Name, strings.Join(toolNames, ", "))
+	}
+	builder.WriteString("Their full schemas are available from your next step.")
+	return builder.String(), nil
+}
+
+// loadToolGroupOf loads the group of a tool the agent called before loading
+// it, so the tool's schema is sent from the next call on.
+func (a *DefaultAgent) loadToolGroupOf(toolName string) {
+	name, ok := a.toolGroupOf(toolName)
+	if !ok {
+		return
+	}
+	a.toolGroupsMu.Lock()
+	defer a.toolGroupsMu.Unlock()
+	a.loadedToolGroups[name] = true
+}
+
+// deferredToolGroup is a group whose schemas are not sent, with its
+// currently visible tools.
+type deferredToolGroup struct {
+	group ToolGroup
+	tools []tools.Tool
+}
+
+// splitDeferredTools separates toolsList into the tools whose schemas are
+// sent and the groups whose schemas are deferred until loaded. Groups without
+// visible tools are left out.
+func (a *DefaultAgent) splitDeferredTools(toolsList []tools.Tool) ([]tools.Tool, []deferredToolGroup) {
+	if len(a.toolGroups) == 0 {
+		return toolsList, nil
+	}
+
+	exposed := make([]tools.Tool, 0, len(toolsList))
+	byGroup := make(map[string][]tools.Tool)
+	for _, tool := range toolsList {
+		name, ok := a.toolGroupOf(tool.Name())
+		if !ok || a.toolGroupLoaded(name) {
+			exposed = append(exposed, tool)
+			continue
+		}
+		byGroup[name] = append(byGroup[name], tool)
+	}
+
+	var deferred []deferredToolGroup
+	for _, group := range a.toolGroups {
+		if groupTools := byGroup[group.Name]; len(groupTools) > 0 {
+			slices.SortFunc(groupTools, func(x, y tools.Tool) int {
+				return strings.Compare(x.Name(), y.Name())
+			})
+			deferred = append(deferred, deferredToolGroup{group: group, tools: groupTools})
+		}
+	}
+	return exposed, deferred
+}
+
+// getExposedTools returns the visible tools whose schemas are sent, and the
+// manifest of the groups that are not loaded.
+func (a *DefaultAgent) getExposedTools() ([]tools.Tool, string) {
+	exposed, deferred := a.splitDeferredTools(a.getToolsList())
+	return exposed, formatToolManifest(deferred)
+}
+
+// formatToolManifest formats the deferred groups for the system prompt.
+func formatToolManifest(deferred []deferredToolGroup) string {
+	entries := make([]prompts.ToolManifestEntry, 0, len(deferred))
+	for _, d := range deferred {
+		names := make([]string, len(d.tools))
+		for i, tool := range d.tools {
+			names[i] = tool.Name()
+		}
+		entries = append(entries, prompts.ToolManifestEntry{
+			Group:       d.group.Name,
+			Description: d.group.Description,
+			Tools:       names,
+		})
+	}
+	return prompts.FormatToolManifest(entries)
+}
+
+// toolGroupInfo measures the schemas of each group's visible tools, and the
+// schemas currently left out of the prompt. Sizes are only counted with a
+// tokenizer.
+func (a *DefaultAgent) toolGroupInfo(toolsList []tools.Tool) ([]ToolGroupInfo, int) {
+	if len(a.toolGroups) == 0 {
+		return nil, 0
+	}
+
+	byGroup := make(map[string][]tools.Tool)
+	for _, tool := range toolsList {
+		if name, ok := a.toolGroupOf(tool.Name()); ok {
+			byGroup[name] = append(byGroup[name], tool)
+		}
+	}
+
+	var infos []ToolGroupInfo
+	deferredTokens := 0
+	for _, group := range a.toolGroups {
+		groupTools := byGroup[group.Name]
+		if len(groupTools) == 0 {
+			continue
+		}
+		info := ToolGroupInfo{
+			Name:      group.Name,
+			ToolCount: len(groupTools),
+			Loaded:    a.toolGroupLoaded(group.Name),
+		}
+		if a.tokenizer != nil {
+			info.Tokens = a.tokenizer.CountTokens(prompts.FormatToolSchemas(groupTools))
+		}
+		if !info.Loaded {
+			deferredTokens += info.Tokens
+		}
+		infos = append(infos, info)
+	}
+	return infos, deferredTokens
+}
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/config"
+)
+
+func newLazyToolsAgent(t *testing.T, loaded ...string) *DefaultAgent {
+	t.Helper()
+	groups := []ToolGroup{
+		{Name: "web", Description: "Fetch web pages", Tools: []string{"fetch_url", "http_request"}},
+		{Name: "notes", Description: "Take notes", Tools: []string{"add_note"}},
+		{Name: "empty", Description: "Nothing registered", Tools: []string{"missing_tool"}},
+	}
+	ag := NewDefaultAgent(nil, WithLazyTools(groups, loaded...))
+	for _, name := range []string{"read_file", "fetch_url", "http_request", "add_note"} {
+		if err := ag.RegisterTool(&mockRegularTool{name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ag
+}
+
+func TestLazyTools_ManifestUntilLoaded(t *testing.T) {
+	ag := newLazyToolsAgent(t, "notes")
+
+	prompt := ag.GetSystemPrompt()
+	for _, want := range []string{"## read_file", "## add_note", "## load_tools", "<tool_manifest>", "- **web**: Fetch web pages (fetch_url, http_request)"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt should contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"## fetch_url", "## http_request", "**notes**", "**empty**"} {
+		if strings.Contains(prompt, unwanted) {
+			t.Errorf("prompt should not contain %q", unwanted)
+		}
+	}
+
+	loadTools, ok := ag.getTool("load_tools")
+	if !ok {
+		t.Fatal("load_tools should be registered with lazy tool groups")
+	}
+	if _, _, err := loadTools.Execute(context.Background(), []byte(`<arguments><groups><group>unknown</group></groups></arguments>`)); err == nil {
+		t.Error("loading an unknown group should fail")
+	}
+	result, _, err := loadTools.Execute(context.Background(), []byte(`<arguments><groups><group>web</group><group>empty</group></groups></arguments>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, "Loaded web: fetch_url, http_request") || !strings.Contains(result, "none of its tools are available") {
+		t.Errorf("unexpected load_tools result: %s", result)
+	}
+
+	prompt = ag.GetSystemPrompt()
+	if !strings.Contains(prompt, "## fetch_url") || strings.Contains(prompt, "<tool_manifest>") {
+		t.Errorf("loaded schemas should be sent and the manifest dropped, got:\n%s", prompt)
+	}
+}
+
+func TestLazyTools_CallingToolLoadsItsGroup(t *testing.T) {
+	ag := newLazyToolsAgent(t)
+
+	if _, ok, _ := ag.lookupTool("add_note"); !ok {
+		t.Fatal("tools of groups that are not loaded should still be callable")
+	}
+
+	exposed, manifest := ag.getExposedTools()
+	names := make(map[string]bool)
+	for _, tool := range exposed {
+		names[tool.Name()] = true
+	}
+	if !names["add_note"] || names["fetch_url"] {
+		t.Errorf("only the called tool's group should be loaded, exposed = %v", names)
+	}
+	if strings.Contains(manifest, "notes") || !strings.Contains(manifest, "web") {
+		t.Errorf("unexpected manifest:\n%s", manifest)
+	}
+}
+
+func TestLazyTools_ContextInfo(t *testing.T) {
+	ag := newLazyToolsAgent(t, "notes")
+
+	info := ag.GetContextInfo()
+	// The three built-ins, read_file, add_note and load_tools
+	if info.LoadedToolCount != 6 {
+		t.Errorf("LoadedToolCount = %d, want 6", info.LoadedToolCount)
+	}
+	if len(info.ToolGroups) != 2 {
+		t.Fatalf("ToolGroups = %+v, want the two groups with registered tools", info.ToolGroups)
+	}
+	if web := info.ToolGroups[0]; web.Name != "web" || web.ToolCount != 2 || web.Loaded {
+		t.Errorf("web group = %+v", web)
+	}
+	if notes := info.ToolGroups[1]; notes.Name != "notes" || !notes.Loaded {
+		t.Errorf("notes group = %+v", notes)
+	}
+}
+
+func TestWithToolSchemas_Disabled(t *testing.T) {
+	ag := NewDefaultAgent(nil, WithToolSchemas(config.ToolSchemasConfig{}))
+	if err := ag.RegisterTool(&mockRegularTool{name: "fetch_url"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := ag.getTool("load_tools"); ok {
+		t.Error("load_tools should only be registered with lazy schemas")
+	}
+	prompt := ag.GetSystemPrompt()
+	if !strings.Contains(prompt, "## fetch_url") || strings.Contains(prompt, "<tool_manifest>") {
+		t.Error("every schema should be sent without lazy schemas")
+	}
+	if info := ag.GetContextInfo(); len(info.ToolGroups) != 0 {
+		t.Errorf("ToolGroups = %+v, want none", info.ToolGroups)
+	}
+}
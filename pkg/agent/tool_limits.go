@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/config"
+)
+
+// WithToolLimits bounds how long each tool call may run and how large a result
+// it may return. Without this option tool calls are unbounded.
+func WithToolLimits(limits config.ToolLimits) AgentOption {
+	return func(a *DefaultAgent) {
+		a.toolLimits = limits
+	}
+}
+
+// toolOutcome carries the return values of a tool call across goroutines.
+type toolOutcome struct {
+	result   string
+	metadata map[string]any
+	err      error
+}
+
+// runTool executes the tool within its timeout. A call that outlives the
+// timeout has its context canceled and is abandoned, so a tool that ignores
+// cancellation cannot stall the loop. Loop-breaking tools wait for the user
+// and are never timed out.
+func (a *DefaultAgent) runTool(ctx context.Context, tool tools.Tool, args []byte) (string, map[string]any, error) {
+	timeout := a.toolLimits.For(tool.Name()).Timeout
+	if timeout <= 0 || tool.IsLoopBreaking() {
+		return tool.Execute(ctx, args)
+	}
+
+	toolCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan toolOutcome, 1)
+	go func() {
+		result, metadata, err := tool.Execute(toolCtx, args)
+		done <- toolOutcome{result: result, metadata: metadata, err: err}
+	}()
+
+	select {
+	case outcome := <-done:
+		if outcome.err != nil && ctx.Err() == nil && errors.Is(toolCtx.Err(), context.DeadlineExceeded) {
+			return "", nil, toolTimeoutError(tool.Name(), timeout)
+		}
+		return outcome.result, outcome.metadata, outcome.err
+	case <-toolCtx.Done():
+		if ctx.Err() != nil {
+			// Canceled by the caller, e.g. /stop: let the tool wind down as before
+			outcome := <-done
+			return outcome.result, outcome.metadata, outcome.err
+		}
+		return "", nil, toolTimeoutError(tool.Name(), timeout)
+	}
+}
+
+// toolTimeoutError explains a timed-out call in terms the agent can act on.
+func toolTimeoutError(toolName string, timeout time.Duration) error {
+	return fmt.Errorf("%s timed out after %s and was stopped. Narrow the request, e.g. limit it to a subdirectory "+
+		"or a file pattern, or ask the user to raise tool_limits for %s", toolName, timeout, toolName)
+}
+
+// limitToolResult keeps the head and tail of a result larger than the tool's
+// size limit, cutting on line boundaries where possible, and notes how much of
+// the middle was dropped.
+func (a *DefaultAgent) limitToolResult(toolName, result string) string {
+	limit := a.toolLimits.For(toolName).MaxResultBytes
+	if limit <= 0 || len(result) <= limit {
+		return result
+	}
+
+	half := limit / 2
+	head := result[:snapHeadCut(result, half)]
+	tail := result[snapTailCut(result, len(result)-half):]
+	omitted := len(result) - len(head) - len(tail)
+
+	return fmt.Sprintf("%s\n\n[... %d bytes omitted: the %s result exceeded its %d-byte limit. "+
+		"Narrow the request to see the rest ...]\n\n%s",
+		strings.TrimRight(head, "\n"), omitted, toolName, limit, strings.TrimLeft(tail, "\n"))
+}
@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config"
+)
+
+// blockingTool runs until release is closed, ignoring cancellation unless
+// honorContext is set.
+type blockingTool struct {
+	name         string
+	loopBreaking bool
+	honorContext bool
+	release      chan struct{}
+}
+
+func (b *blockingTool) Name() string        { return b.name }
+func (b *blockingTool) Description() string { return "blocks" }
+func (b *blockingTool) Schema() map[string]any {
+	return map[string]any{"type": "object"}
+}
+func (b *blockingTool) Execute(ctx context.Context, args []byte) (string, map[string]any, error) {
+	if b.honorContext {
+		select {
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		case <-b.release:
+			return "released", nil, nil
+		}
+	}
+	<-b.release
+	return "released", nil, nil
+}
+func (b *blockingTool) IsLoopBreaking() bool { return b.loopBreaking }
+
+func newBlockingTool(t *testing.T, name string) *blockingTool {
+	t.Helper()
+	tool := &blockingTool{name: name, release: make(chan struct{})}
+	t.Cleanup(func() { close(tool.release) })
+	return tool
+}
+
+func TestRunTool_Timeout(t *testing.T) {
+	a := &DefaultAgent{}
+	WithToolLimits(config.ToolLimits{
+		Timeout: time.Hour,
+		Tools:   map[string]config.ToolLimit{"search_files": {Timeout: 20 * time.Millisecond}},
+	})(a)
+
+	for _, honor := range []bool{false, true} {
+		tool := newBlockingTool(t, "search_files")
+		tool.honorContext = honor
+
+		start := time.Now()
+		_, _, err := a.runTool(context.Background(), tool, nil)
+		if err == nil || !strings.Contains(err.Error(), "search_files timed out after 20ms") {
+			t.Errorf("honorContext=%v: expected a timeout error, got %v", honor, err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("honorContext=%v: runTool took %v", honor, elapsed)
+		}
+	}
+}
+
+func TestRunTool_NoTimeout(t *testing.T) {
+	a := &DefaultAgent{}
+	WithToolLimits(config.ToolLimits{Timeout: 20 * time.Millisecond})(a)
+
+	// Loop-breaking tools wait for the user and are never timed out
+	tool := &blockingTool{name: "ask_question", loopBreaking: true, release: make(chan struct{})}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		close(tool.release)
+	}()
+	result, _, err := a.runTool(context.Background(), tool, nil)
+	if err != nil || result != "released" {
+		t.Errorf("expected the loop-breaking tool to finish, got %q, %v", result, err)
+	}
+
+	// Canceling the turn is not reported as a timeout
+	a = &DefaultAgent{}
+	WithToolLimits(config.ToolLimits{Timeout: time.Hour})(a)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	honoring := newBlockingTool(t, "read_file")
+	honoring.honorContext = true
+	if _, _, err := a.runTool(ctx, honoring, nil); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLimitToolResult(t *testing.T) {
+	a := &DefaultAgent{}
+	WithToolLimits(config.ToolLimits{Tools: map[string]config.ToolLimit{"search_files": {MaxResultBytes: 2000}}})(a)
+	content := largeLog(200)
+
+	if got := a.limitToolResult("read_file", content); got != content {
+		t.Error("expected an unlimited tool's result unchanged")
+	}
+	if got := a.limitToolResult("search_files", "short"); got != "short" {
+		t.Errorf("expected a small result unchanged, got %q", got)
+	}
+
+	result := a.limitToolResult("search_files", content)
+	if !strings.HasPrefix(result, "line 00000:") {
+		t.Error("expected the head of the result to be kept")
+	}
+	if !strings.HasSuffix(result, "line 00199: héllo wörld, something happened here\n") {
+		t.Error("expected the tail of the result to be kept")
+	}
+	if !strings.Contains(result, "bytes omitted: the search_files result exceeded its 2000-byte limit") {
+		t.Errorf("expected an omission note, got %q", result)
+	}
+	if len(result) > 2000+200 {
+		t.Errorf("kept %d bytes, far over the 2000-byte limit", len(result))
+	}
+	for _, line := range strings.Split(result, "\n") {
+		if line != "" && !strings.HasPrefix(line, "line ") && !strings.HasPrefix(line, "[... ") {
+			t.Errorf("expected cuts on line boundaries, got line %q", line)
+		}
+	}
+}
@@ -121,3 +121,53 @@ func (a *DefaultAgent) processToolCall(ctx context.Context, toolCallContent stri
 	// Execute the tool
 	return a.executeTool(ctx, toolCall)
 }
+
+// processNativeToolCall handles validation and execution of the first tool call
+// made through native function calling
+// Returns (shouldContinue, errorContext) following the same pattern as executeIteration
+func (a *DefaultAgent) processNativeToolCall(ctx context.Context, calls []types.ToolCall) (bool, string) {
+	// Check if context was canceled before processing
+	if ctx.Err() != nil {
+		return false, "" // Stop silently - user requested cancellation
+	}
+
+	if len(calls) == 0 {
+		a.emitEvent(types.NewNoToolCallEvent())
+		errMsg := prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+			Type: prompts.ErrorTypeNoFunctionCall,
+		})
+
+		if a.trackError(errMsg) {
+			a.emitEvent(types.NewErrorEvent(fmt.Errorf("circuit breaker triggered: 5 consecutive no tool call errors")))
+			return false, ""
+		}
+
+		a.emitEvent(types.NewErrorEvent(fmt.Errorf("no tool call found in response")))
+		return true, errMsg
+	}
+
+	toolCall, err := tools.NewNativeToolCall(calls[0])
+	if err != nil {
+		errMsg := prompts.BuildErrorRecoveryMessage(prompts.ErrorRecoveryContext{
+			Type:     prompts.ErrorTypeInvalidArguments,
+			ToolName: calls[0].Name,
+			Error:    err,
+			Content:  calls[0].Arguments,
+		})
+
+		if a.trackError(errMsg) {
+			a.emitEvent(types.NewErrorEvent(fmt.Errorf("circuit breaker triggered: 5 consecutive parse errors")))
+			return false, ""
+		}
+
+		a.emitEvent(types.NewErrorEvent(fmt.Errorf("failed to parse tool call: %w", err)))
+		return true, errMsg
+	}
+
+	shouldContinue, errCtx := a.validateToolCallFields(toolCall)
+	if !shouldContinue || errCtx != "" {
+		return shouldContinue, errCtx
+	}
+
+	return a.executeTool(ctx, *toolCall)
+}
@@ -1,7 +1,10 @@
 package agent
 
 import (
+	"sort"
+
 	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/llm"
 )
 
 // getToolsList returns tools as []tools.Tool for internal use
@@ -10,8 +13,12 @@ func (a *DefaultAgent) getToolsList() []tools.Tool {
 	a.toolsMu.RLock()
 	defer a.toolsMu.RUnlock()
 
+	profile := a.GetProfile()
 	toolsList := make([]tools.Tool, 0, len(a.tools))
 	for _, tool := range a.tools {
+		if profile.HidesTool(tool.Name()) {
+			continue
+		}
 		// Check if tool implements ConditionallyVisible
 		if cv, ok := tool.(tools.ConditionallyVisible); ok {
 			// Only include if ShouldShow returns true
@@ -24,11 +31,55 @@ func (a *DefaultAgent) getToolsList() []tools.Tool {
 	return toolsList
 }
 
-// getTool retrieves a tool by name (thread-safe)
+// getTool retrieves a tool by name (thread-safe). Tools hidden by the active
+// profile are not found.
 func (a *DefaultAgent) getTool(name string) (tools.Tool, bool) {
+	if a.GetProfile().HidesTool(name) {
+		return nil, false
+	}
+
 	a.toolsMu.RLock()
 	defer a.toolsMu.RUnlock()
 
 	tool, exists := a.tools[name]
 	return tool, exists
 }
+
+// nativeToolCalls reports whether tool calls go through the provider's native
+// function-calling API rather than XML in the response text
+func (a *DefaultAgent) nativeToolCalls() bool {
+	return a.toolCallMode == llm.ToolCallModeNative && llm.SupportsNativeToolCalls(a.provider)
+}
+
+// getToolDefinitions returns the visible tools whose schemas are sent as
+// function definitions for native tool calling, sorted by name so requests
+// are stable
+func (a *DefaultAgent) getToolDefinitions() []llm.ToolDefinition {
+	toolsList, _ := a.getExposedTools()
+	definitions := make([]llm.ToolDefinition, 0, len(toolsList))
+	for _, tool := range toolsList {
+		definitions = append(definitions, llm.ToolDefinition{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+			Parameters:  functionParameters(tool.Schema()),
+		})
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+	return definitions
+}
+
+// functionParameters makes a tool schema acceptable as function parameters,
+// which must be an object schema with properties
+func functionParameters(schema map[string]any) map[string]any {
+	params := make(map[string]any, len(schema)+2)
+	for key, value := range schema {
+		params[key] = value
+	}
+	params["type"] = "object"
+	if _, ok := params["properties"]; !ok {
+		params["properties"] = map[string]any{}
+	}
+	return params
+}
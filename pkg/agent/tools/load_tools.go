@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// ToolGroupLoader loads the schemas of the named tool groups and describes
+// the tools they make available, typically DefaultAgent.LoadToolGroups.
+type ToolGroupLoader func(groups []string) (string, error)
+
+// LoadToolsTool lets the agent load the schemas of tool groups listed in the
+// tool manifest. Groups stay loaded for the rest of the session.
+type LoadToolsTool struct {
+	load ToolGroupLoader
+}
+
+// NewLoadToolsTool creates a new LoadToolsTool.
+func NewLoadToolsTool(load ToolGroupLoader) *LoadToolsTool {
+	return &LoadToolsTool{
+		load: load,
+	}
+}
+
+// Name returns the tool name.
+func (t *LoadToolsTool) Name() string {
+	return "load_tools"
+}
+
+// Description returns the tool description.
+func (t *LoadToolsTool) Description() string {
+	return "Load the tools of groups listed in the tool manifest so they can be called. " +
+		"Their full schemas are available from your next step and stay loaded for the rest of the session. " +
+		"Load every group a task needs in one call."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *LoadToolsTool) Schema() map[string]any {
+	return BaseToolSchema(
+		map[string]any{
+			"groups": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "string",
+				},
+				"description": "Names of the tool groups to load, as listed in the tool manifest",
+				"minItems":    1,
+			},
+		},
+		[]string{"groups"},
+	)
+}
+
+// Execute loads the requested groups.
+func (t *LoadToolsTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Groups  []string `xml:"groups>group"`
+	}
+
+	if err := UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	var groups []string
+	for _, group := range input.Groups {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	if len(groups) == 0 {
+		return "", nil, fmt.Errorf("missing required parameter: groups")
+	}
+
+	result, err := t.load(groups)
+	if err != nil {
+		return "", nil, err
+	}
+	return result, map[string]any{"groups": groups}, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *LoadToolsTool) IsLoopBreaking() bool {
+	return false
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// elementNameRegex matches argument names that can be used as XML element names
+var elementNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// ArrayItemElementName returns the element name used for each item of an
+// array argument, e.g. "edit" for "edits". Tools unmarshal arrays with tags
+// like `xml:"edits>edit"`, and the XML examples in the system prompt follow
+// the same convention.
+func ArrayItemElementName(name string) string {
+	if strings.HasSuffix(name, "s") {
+		return name[:len(name)-1]
+	}
+	return name
+}
+
+// NewNativeToolCall converts a tool call made through a provider's native
+// function-calling API into a ToolCall with XML arguments, so tools execute the
+// same way regardless of the protocol the model used.
+func NewNativeToolCall(call types.ToolCall) (*ToolCall, error) {
+	if call.Name == "" {
+		return nil, fmt.Errorf("tool_name is required in tool call")
+	}
+
+	innerXML, err := ArgumentsFromJSON(call.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid arguments for %s: %w", call.Name, err)
+	}
+
+	return &ToolCall{
+		ID:         call.ID,
+		ServerName: defaultServerName,
+		ToolName:   call.Name,
+		Arguments:  ArgumentsBlock{InnerXML: innerXML},
+	}, nil
+}
+
+// ArgumentsFromJSON converts a JSON argument object into the inner XML of an
+// <arguments> element. Objects become nested elements, arrays become a wrapper
+// element holding one element per item (see ArrayItemElementName), and null
+// values are omitted.
+func ArgumentsFromJSON(arguments string) ([]byte, error) {
+	arguments = strings.TrimSpace(arguments)
+	if arguments == "" {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(arguments))
+	decoder.UseNumber()
+	var args map[string]any
+	if err := decoder.Decode(&args); err != nil {
+		return nil, fmt.Errorf("arguments must be a JSON object: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeXMLFields(&buf, args); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeXMLFields writes each field of an object as an element, in name order
+// so the output is deterministic.
+func writeXMLFields(buf *bytes.Buffer, fields map[string]any) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := writeXMLValue(buf, name, fields[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeXMLValue writes a single JSON value as an element named name.
+func writeXMLValue(buf *bytes.Buffer, name string, value any) error {
+	if value == nil {
+		return nil
+	}
+	if !elementNameRegex.MatchString(name) {
+		return fmt.Errorf("argument name %q is not a valid identifier", name)
+	}
+
+	fmt.Fprintf(buf, "<%s>", name)
+	switch v := value.(type) {
+	case map[string]any:
+		if err := writeXMLFields(buf, v); err != nil {
+			return err
+		}
+	case []any:
+		itemName := ArrayItemElementName(name)
+		for _, item := range v {
+			if err := writeXMLValue(buf, itemName, item); err != nil {
+				return err
+			}
+		}
+	case string:
+		if err := xml.EscapeText(buf, []byte(v)); err != nil {
+			return err
+		}
+	default:
+		// json.Number and bool
+		fmt.Fprint(buf, v)
+	}
+	fmt.Fprintf(buf, "</%s>", name)
+	return nil
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestArgumentsFromJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		expected  string
+		wantErr   bool
+	}{
+		{
+			name:      "scalars in name order",
+			arguments: `{"path":"main.go","line":12,"recursive":true}`,
+			expected:  "<line>12</line><path>main.go</path><recursive>true</recursive>",
+		},
+		{
+			name:      "special characters are escaped",
+			arguments: `{"content":"if a < b && c > d {}"}`,
+			expected:  "<content>if a &lt; b &amp;&amp; c &gt; d {}</content>",
+		},
+		{
+			name:      "arrays use singular item elements",
+			arguments: `{"edits":[{"search":"a","replace":"b"}],"tags":["x","y"]}`,
+			expected:  "<edits><edit><replace>b</replace><search>a</search></edit></edits><tags><tag>x</tag><tag>y</tag></tags>",
+		},
+		{
+			name:      "null values are omitted",
+			arguments: `{"path":"main.go","limit":null}`,
+			expected:  "<path>main.go</path>",
+		},
+		{
+			name:      "large integers keep their digits",
+			arguments: `{"timeout":12345678901234}`,
+			expected:  "<timeout>12345678901234</timeout>",
+		},
+		{
+			name:      "empty arguments",
+			arguments: "",
+			expected:  "",
+		},
+		{
+			name:      "not an object",
+			arguments: `["main.go"]`,
+			wantErr:   true,
+		},
+		{
+			name:      "invalid element name",
+			arguments: `{"bad name":"x"}`,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ArgumentsFromJSON(tt.arguments)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewNativeToolCall_UnmarshalsLikeXML(t *testing.T) {
+	call, err := NewNativeToolCall(types.ToolCall{
+		ID:        "call_1",
+		Name:      "apply_diff",
+		Arguments: `{"path":"main.go","edits":[{"search":"old","replace":"new & improved"}]}`,
+	})
+	if err != nil {
+		t.Fatalf("NewNativeToolCall failed: %v", err)
+	}
+	if call.ID != "call_1" || call.ServerName != "local" || call.ToolName != "apply_diff" {
+		t.Errorf("unexpected tool call %+v", call)
+	}
+
+	var args struct {
+		Path  string `xml:"path"`
+		Edits []struct {
+			Search  string `xml:"search"`
+			Replace string `xml:"replace"`
+		} `xml:"edits>edit"`
+	}
+	if err := UnmarshalXMLWithFallback(call.GetArgumentsXML(), &args); err != nil {
+		t.Fatalf("failed to unmarshal arguments: %v", err)
+	}
+	if args.Path != "main.go" || len(args.Edits) != 1 || args.Edits[0].Replace != "new & improved" {
+		t.Errorf("unexpected arguments %+v", args)
+	}
+
+	if _, err := NewNativeToolCall(types.ToolCall{Arguments: "{}"}); err == nil {
+		t.Error("expected an error for a call without a name")
+	}
+}
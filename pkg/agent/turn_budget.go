@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// turnBudgetGrace is the number of iterations the agent gets to wrap up after
+// a turn budget runs out, before the turn is stopped.
+const turnBudgetGrace = 2
+
+// WithMaxToolCallsPerTurn caps the number of tool calls the agent makes in
+// one turn. Once the cap is reached the agent is told to wrap up, and the turn
+// is stopped if it keeps going. A limit of 0 disables the cap.
+func WithMaxToolCallsPerTurn(limit int) AgentOption {
+	return func(a *DefaultAgent) {
+		if limit < 0 {
+			limit = 0
+		}
+		a.maxToolCallsPerTurn = limit
+	}
+}
+
+// WithMaxTurnDuration caps how long the agent loop runs for one turn. Once
+// the time is up the agent is told to wrap up, and the turn is stopped if it
+// keeps going. A duration of 0 disables the cap.
+func WithMaxTurnDuration(d time.Duration) AgentOption {
+	return func(a *DefaultAgent) {
+		if d < 0 {
+			d = 0
+		}
+		a.maxTurnDuration = d
+	}
+}
+
+// turnBudget tracks one turn against the configured budgets.
+type turnBudget struct {
+	start    time.Time
+	exceeded bool // The agent has been told to wrap up
+	grace    int  // Iterations left to wrap up in
+}
+
+// newTurnBudget starts tracking a turn.
+func (a *DefaultAgent) newTurnBudget() *turnBudget {
+	a.turnToolCalls = 0
+	return &turnBudget{start: time.Now()}
+}
+
+// checkTurnBudget is called after each iteration of the agent loop. It
+// returns the wrap-up instruction to inject into the next iteration once a
+// budget is used up, and false when the agent has had its grace iterations
+// and the turn must stop.
+func (a *DefaultAgent) checkTurnBudget(budget *turnBudget) (string, bool) {
+	if budget.exceeded {
+		if budget.grace == 0 {
+			info := a.turnBudgetInfo(budget)
+			info.Stopped = true
+			a.emitEvent(types.NewTurnBudgetExceededEvent(info))
+			return "", false
+		}
+		budget.grace--
+		return turnBudgetWrapUpMessage(a.turnBudgetInfo(budget)), true
+	}
+
+	info := a.turnBudgetInfo(budget)
+	if info.Limit == "" {
+		return "", true
+	}
+	budget.exceeded = true
+	budget.grace = turnBudgetGrace - 1
+	a.emitEvent(types.NewTurnBudgetExceededEvent(info))
+	return turnBudgetWrapUpMessage(info), true
+}
+
+// turnBudgetInfo describes the turn's use of its budgets. Limit is empty
+// while the turn is within them.
+func (a *DefaultAgent) turnBudgetInfo(budget *turnBudget) types.TurnBudget {
+	info := types.TurnBudget{
+		ToolCalls:    a.turnToolCalls,
+		MaxToolCalls: a.maxToolCallsPerTurn,
+		Elapsed:      time.Since(budget.start).Round(time.Second),
+		MaxDuration:  a.maxTurnDuration,
+	}
+	switch {
+	case a.maxToolCallsPerTurn > 0 && a.turnToolCalls >= a.maxToolCallsPerTurn:
+		info.Limit = types.TurnBudgetToolCalls
+	case a.maxTurnDuration > 0 && time.Since(budget.start) >= a.maxTurnDuration:
+		info.Limit = types.TurnBudgetDuration
+	}
+	return info
+}
+
+// turnBudgetWrapUpMessage tells the agent its budget is used up and how to end
+// the turn.
+func turnBudgetWrapUpMessage(info types.TurnBudget) string {
+	spent := fmt.Sprintf("you have made %d tool calls, the limit for one turn", info.ToolCalls)
+	if info.Limit == types.TurnBudgetDuration {
+		spent = fmt.Sprintf("this turn has been running for %s, the limit for one turn", info.Elapsed)
+	}
+	return fmt.Sprintf(`BUDGET EXCEEDED: %s.
+
+Wrap up now. Do not start new work or call any more tools to investigate or make changes. Instead, use task_completion (or converse if you need input from the user) to:
+- Summarize what you have done so far
+- List what remains to be done and any problems you ran into
+
+The turn will be stopped if you keep going.`, spent)
+}
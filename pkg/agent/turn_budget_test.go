@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func nextTurnBudgetEvent(t *testing.T, a *DefaultAgent) *types.TurnBudget {
+	t.Helper()
+	select {
+	case event := <-a.channels.Event:
+		if event.Type != types.EventTypeTurnBudgetExceeded || event.TurnBudget == nil {
+			t.Fatalf("expected turn budget exceeded event, got %s", event.Type)
+		}
+		return event.TurnBudget
+	default:
+		t.Fatal("expected a turn budget exceeded event")
+		return nil
+	}
+}
+
+func TestCheckTurnBudget_ToolCalls(t *testing.T) {
+	a := &DefaultAgent{channels: types.NewAgentChannels(10)}
+	WithMaxToolCallsPerTurn(3)(a)
+	budget := a.newTurnBudget()
+
+	a.turnToolCalls = 2
+	if wrapUp, ok := a.checkTurnBudget(budget); wrapUp != "" || !ok {
+		t.Fatalf("expected no wrap-up within budget, got %q, %v", wrapUp, ok)
+	}
+	if len(a.channels.Event) != 0 {
+		t.Fatal("expected no event within budget")
+	}
+
+	a.turnToolCalls = 3
+	wrapUp, ok := a.checkTurnBudget(budget)
+	if !ok || !strings.Contains(wrapUp, "3 tool calls") || !strings.Contains(wrapUp, "task_completion") {
+		t.Fatalf("expected a wrap-up instruction, got %q, %v", wrapUp, ok)
+	}
+	info := nextTurnBudgetEvent(t, a)
+	if info.Limit != types.TurnBudgetToolCalls || info.ToolCalls != 3 || info.MaxToolCalls != 3 || info.Stopped {
+		t.Errorf("unexpected event: %+v", info)
+	}
+
+	// The agent keeps being told to wrap up during its grace iterations,
+	// without another event, then the turn is stopped
+	for range turnBudgetGrace - 1 {
+		if wrapUp, ok := a.checkTurnBudget(budget); wrapUp == "" || !ok {
+			t.Fatalf("expected another wrap-up instruction, got %q, %v", wrapUp, ok)
+		}
+	}
+	if len(a.channels.Event) != 0 {
+		t.Fatal("expected no event during the grace iterations")
+	}
+	if _, ok := a.checkTurnBudget(budget); ok {
+		t.Fatal("expected the turn to be stopped after the grace iterations")
+	}
+	if info := nextTurnBudgetEvent(t, a); !info.Stopped {
+		t.Errorf("expected the final event to report the turn stopped: %+v", info)
+	}
+}
+
+func TestCheckTurnBudget_Duration(t *testing.T) {
+	a := &DefaultAgent{channels: types.NewAgentChannels(10)}
+	WithMaxTurnDuration(time.Minute)(a)
+	budget := a.newTurnBudget()
+
+	if wrapUp, ok := a.checkTurnBudget(budget); wrapUp != "" || !ok {
+		t.Fatalf("expected no wrap-up within budget, got %q, %v", wrapUp, ok)
+	}
+
+	budget.start = time.Now().Add(-2 * time.Minute)
+	wrapUp, ok := a.checkTurnBudget(budget)
+	if !ok || !strings.Contains(wrapUp, "running for 2m0s") {
+		t.Fatalf("expected a wrap-up instruction, got %q, %v", wrapUp, ok)
+	}
+	if info := nextTurnBudgetEvent(t, a); info.Limit != types.TurnBudgetDuration || info.MaxDuration != time.Minute {
+		t.Errorf("unexpected event: %+v", info)
+	}
+}
+
+func TestCheckTurnBudget_Disabled(t *testing.T) {
+	a := &DefaultAgent{channels: types.NewAgentChannels(10)}
+	WithMaxToolCallsPerTurn(-1)(a)
+	budget := a.newTurnBudget()
+	budget.start = time.Now().Add(-time.Hour)
+	a.turnToolCalls = 1000
+
+	if wrapUp, ok := a.checkTurnBudget(budget); wrapUp != "" || !ok {
+		t.Errorf("expected no budget when disabled, got %q, %v", wrapUp, ok)
+	}
+}
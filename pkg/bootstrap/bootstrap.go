@@ -0,0 +1,316 @@
+// Package bootstrap detects the commands that prepare a project for work,
+// such as installing its dependencies and warming the build cache, and runs
+// them before an agent starts, so early tool calls do not fail on missing
+// dependencies.
+//
+// Detection looks at the workspace root:
+//
+//	go.mod             go mod download, when go.sum lists modules missing from
+//	                   the module cache, and go build as a cache warmup
+//	package.json       npm ci, pnpm install or yarn install, chosen by lock
+//	                   file, when node_modules is missing
+//	requirements.txt   pip install -r requirements.txt, when there is no
+//	                   .venv or venv virtual environment
+//
+// Steps whose package manager is not installed are left out.
+package bootstrap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a single step when no timeout is configured.
+const DefaultTimeout = 10 * time.Minute
+
+// maxOutputBytes is how much of the end of a step's output is kept.
+const maxOutputBytes = 4096
+
+// waitDelay is how long a timed-out step's output pipes may stay open, held
+// by processes it started, before they are closed
+const waitDelay = time.Second
+
+// Kinds of step
+const (
+	KindInstall = "install" // Installs missing dependencies
+	KindWarmup  = "warmup"  // Fills a build cache; never required
+	KindCustom  = "custom"  // A command from the configuration
+)
+
+// Step is one setup command.
+type Step struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Command string `json:"command"`          // Run with sh -c in the workspace
+	Reason  string `json:"reason,omitempty"` // Why the step is needed
+}
+
+// Result is the outcome of running a step.
+type Result struct {
+	Step     Step          `json:"step"`
+	Duration time.Duration `json:"duration"`
+	Output   string        `json:"output,omitempty"` // The end of the combined output
+	Error    string        `json:"error,omitempty"`
+}
+
+// Failed reports whether the step failed.
+func (r Result) Failed() bool {
+	return r.Error != ""
+}
+
+// lookPath reports whether a command is installed; replaced in tests.
+var lookPath = func(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// Detect returns the setup steps the project in workspaceDir needs, installs
+// before warmups.
+func Detect(workspaceDir string) []Step {
+	var installs, warmups []Step
+
+	if exists(workspaceDir, "go.mod") && lookPath("go") {
+		if missing := missingGoModules(workspaceDir); missing > 0 {
+			installs = append(installs, Step{
+				Name:    "Go modules",
+				Kind:    KindInstall,
+				Command: "go mod download",
+				Reason:  fmt.Sprintf("%d module(s) in go.sum are not in the module cache", missing),
+			})
+		}
+		warmups = append(warmups, Step{
+			Name:    "Go build cache",
+			Kind:    KindWarmup,
+			Command: "go build -o /dev/null ./...",
+			Reason:  "compile the packages once so later builds and tests start warm",
+		})
+	}
+
+	if exists(workspaceDir, "package.json") && !exists(workspaceDir, "node_modules") {
+		if step, ok := nodeInstall(workspaceDir); ok {
+			installs = append(installs, step)
+		}
+	}
+
+	if exists(workspaceDir, "requirements.txt") && !exists(workspaceDir, ".venv") && !exists(workspaceDir, "venv") && lookPath("python3") {
+		installs = append(installs, Step{
+			Name:    "Python requirements",
+			Kind:    KindInstall,
+			Command: "python3 -m pip install -r requirements.txt",
+			Reason:  "no .venv or venv virtual environment",
+		})
+	}
+
+	return append(installs, warmups...)
+}
+
+// Plan returns the steps to run in workspaceDir: commands when the
+// configuration sets them, otherwise the detected steps.
+func Plan(workspaceDir string, commands []string) []Step {
+	if len(commands) > 0 {
+		return CustomSteps(commands)
+	}
+	return Detect(workspaceDir)
+}
+
+// CustomSteps returns steps for commands set in the configuration.
+func CustomSteps(commands []string) []Step {
+	steps := make([]Step, 0, len(commands))
+	for _, command := range commands {
+		if command = strings.TrimSpace(command); command != "" {
+			steps = append(steps, Step{Name: command, Kind: KindCustom, Command: command})
+		}
+	}
+	return steps
+}
+
+// Installs returns the detected steps that install missing dependencies, the
+// ones worth offering to a user; warmups only save time, and configured
+// commands may have nothing to do.
+func Installs(steps []Step) []Step {
+	var installs []Step
+	for _, step := range steps {
+		if step.Kind == KindInstall {
+			installs = append(installs, step)
+		}
+	}
+	return installs
+}
+
+// Describe lists the steps' commands on one line.
+func Describe(steps []Step) string {
+	commands := make([]string, len(steps))
+	for i, step := range steps {
+		commands[i] = step.Command
+	}
+	return strings.Join(commands, "; ")
+}
+
+// Run runs step in workspaceDir, stopping it after timeout (DefaultTimeout
+// when zero).
+func Run(ctx context.Context, workspaceDir string, step Step, timeout time.Duration) Result {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	cmd := exec.CommandContext(runCtx, "sh", "-c", step.Command) //nolint:gosec // detected or configured by the project
+	cmd.Dir = workspaceDir
+	cmd.WaitDelay = waitDelay
+	// Package managers skip prompts and progress bars in CI
+	cmd.Env = append(os.Environ(), "CI=true")
+	output, err := cmd.CombinedOutput()
+
+	result := Result{
+		Step:     step,
+		Duration: time.Since(start).Round(time.Millisecond),
+		Output:   tail(output),
+	}
+	switch {
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		result.Error = fmt.Sprintf("timed out after %s", timeout)
+	case err != nil:
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// RunAll runs steps in order, calling report after each. Warmups that fail
+// do not stop the run; the first other failure does. It returns the results
+// so far.
+func RunAll(ctx context.Context, workspaceDir string, steps []Step, timeout time.Duration, report func(Result)) []Result {
+	results := make([]Result, 0, len(steps))
+	for _, step := range steps {
+		if ctx.Err() != nil {
+			break
+		}
+		result := Run(ctx, workspaceDir, step, timeout)
+		results = append(results, result)
+		if report != nil {
+			report(result)
+		}
+		if result.Failed() && step.Kind != KindWarmup {
+			break
+		}
+	}
+	return results
+}
+
+// FirstFailure returns the first failed result that is not a warmup.
+func FirstFailure(results []Result) (Result, bool) {
+	for _, result := range results {
+		if result.Failed() && result.Step.Kind != KindWarmup {
+			return result, true
+		}
+	}
+	return Result{}, false
+}
+
+// nodeInstall returns the install step of the package manager whose lock file
+// the project has.
+func nodeInstall(dir string) (Step, bool) {
+	managers := []struct {
+		lockFile string
+		tool     string
+		command  string
+	}{
+		{"pnpm-lock.yaml", "pnpm", "pnpm install --frozen-lockfile"},
+		{"yarn.lock", "yarn", "yarn install --frozen-lockfile"},
+		{"package-lock.json", "npm", "npm ci"},
+	}
+	tool, command := "npm", "npm install"
+	for _, manager := range managers {
+		if exists(dir, manager.lockFile) {
+			tool, command = manager.tool, manager.command
+			break
+		}
+	}
+	if !lookPath(tool) {
+		return Step{}, false
+	}
+	return Step{
+		Name:    "Node packages",
+		Kind:    KindInstall,
+		Command: command,
+		Reason:  "node_modules is missing",
+	}, true
+}
+
+// missingGoModules counts the modules whose source go.sum records that are
+// not in the module cache.
+func missingGoModules(dir string) int {
+	data, err := os.ReadFile(filepath.Join(dir, "go.sum"))
+	if err != nil {
+		return 0
+	}
+	cache := goModCache()
+	if cache == "" {
+		return 0
+	}
+
+	missing := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Lines for a module's go.mod alone do not need its source
+		if len(fields) < 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		zip := filepath.Join(cache, "cache", "download", escapeModule(fields[0]), "@v", escapeModule(fields[1])+".zip")
+		if !exists(zip) {
+			missing++
+		}
+	}
+	return missing
+}
+
+// goModCache returns the Go module cache directory.
+func goModCache() string {
+	if cache := os.Getenv("GOMODCACHE"); cache != "" {
+		return cache
+	}
+	output, err := exec.Command("go", "env", "GOMODCACHE").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// escapeModule escapes a module path or version the way the module cache
+// does, writing each upper-case letter as '!' and its lower-case form.
+func escapeModule(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// tail returns the end of output, at most maxOutputBytes long.
+func tail(output []byte) string {
+	output = bytes.TrimSpace(output)
+	if len(output) > maxOutputBytes {
+		output = output[len(output)-maxOutputBytes:]
+	}
+	return string(output)
+}
+
+// exists reports whether the path made of elems exists.
+func exists(elems ...string) bool {
+	_, err := os.Stat(filepath.Join(elems...))
+	return err == nil
+}
@@ -0,0 +1,167 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFiles creates files with content in dir; names ending in "/" are
+// created as directories
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// stubLookPath makes only the named commands look installed
+func stubLookPath(t *testing.T, installed ...string) {
+	t.Helper()
+	original := lookPath
+	lookPath = func(name string) bool {
+		for _, tool := range installed {
+			if tool == name {
+				return true
+			}
+		}
+		return false
+	}
+	t.Cleanup(func() { lookPath = original })
+}
+
+func commands(steps []Step) []string {
+	var out []string
+	for _, step := range steps {
+		out = append(out, step.Command)
+	}
+	return out
+}
+
+func TestDetect_Go(t *testing.T) {
+	stubLookPath(t, "go")
+	cache := t.TempDir()
+	t.Setenv("GOMODCACHE", cache)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/app\n",
+		"go.sum": "github.com/BurntSushi/toml v1.4.0 h1:abc=\n" +
+			"github.com/BurntSushi/toml v1.4.0/go.mod h1:def=\n" +
+			"golang.org/x/mod v0.20.0/go.mod h1:ghi=\n",
+	})
+
+	steps := Detect(dir)
+	if got := commands(steps); strings.Join(got, "|") != "go mod download|go build -o /dev/null ./..." {
+		t.Fatalf("unexpected steps %v", got)
+	}
+	if !strings.Contains(steps[0].Reason, "1 module(s)") {
+		t.Errorf("expected only the module with source to count, got %q", steps[0].Reason)
+	}
+
+	// Once the module is downloaded, only the warmup is left
+	writeFiles(t, cache, map[string]string{"cache/download/github.com/!burnt!sushi/toml/@v/v1.4.0.zip": ""})
+	if got := commands(Detect(dir)); strings.Join(got, "|") != "go build -o /dev/null ./..." {
+		t.Errorf("expected only the warmup with modules cached, got %v", got)
+	}
+	if installs := Installs(Detect(dir)); len(installs) != 0 {
+		t.Errorf("expected no installs to offer, got %v", installs)
+	}
+}
+
+func TestDetect_Node(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		installed []string
+		want      string
+	}{
+		{"npm lock file", map[string]string{"package.json": "{}", "package-lock.json": "{}"}, []string{"npm"}, "npm ci"},
+		{"pnpm lock file", map[string]string{"package.json": "{}", "pnpm-lock.yaml": ""}, []string{"npm", "pnpm"}, "pnpm install --frozen-lockfile"},
+		{"yarn lock file", map[string]string{"package.json": "{}", "yarn.lock": ""}, []string{"yarn"}, "yarn install --frozen-lockfile"},
+		{"no lock file", map[string]string{"package.json": "{}"}, []string{"npm"}, "npm install"},
+		{"already installed", map[string]string{"package.json": "{}", "node_modules/": ""}, []string{"npm"}, ""},
+		{"package manager missing", map[string]string{"package.json": "{}", "yarn.lock": ""}, []string{"npm"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubLookPath(t, tt.installed...)
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			if got := strings.Join(commands(Detect(dir)), "|"); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDetect_Python(t *testing.T) {
+	stubLookPath(t, "python3")
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"requirements.txt": "requests\n"})
+	if got := commands(Detect(dir)); len(got) != 1 || got[0] != "python3 -m pip install -r requirements.txt" {
+		t.Errorf("unexpected steps %v", got)
+	}
+
+	writeFiles(t, dir, map[string]string{".venv/": ""})
+	if steps := Detect(dir); len(steps) != 0 {
+		t.Errorf("expected nothing to do with a virtual environment, got %v", steps)
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	dir := t.TempDir()
+	steps := []Step{
+		{Name: "warm", Kind: KindWarmup, Command: "echo warming; exit 1"},
+		{Name: "ok", Kind: KindCustom, Command: "echo done > done.txt"},
+		{Name: "broken", Kind: KindInstall, Command: "echo oops >&2; exit 3"},
+		{Name: "never", Kind: KindCustom, Command: "touch never.txt"},
+	}
+
+	var reported []string
+	results := RunAll(context.Background(), dir, steps, 0, func(r Result) { reported = append(reported, r.Step.Name) })
+
+	if strings.Join(reported, ",") != "warm,ok,broken" {
+		t.Fatalf("expected a failed warmup not to stop the run and a failed install to, got %v", reported)
+	}
+	if results[0].Output != "warming" || !results[0].Failed() || results[1].Failed() {
+		t.Errorf("unexpected results %+v", results)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "done.txt")); err != nil {
+		t.Errorf("expected the step to run in the workspace: %v", err)
+	}
+	failure, ok := FirstFailure(results)
+	if !ok || failure.Step.Name != "broken" || failure.Output != "oops" {
+		t.Errorf("expected the install to be the first failure, got %+v", failure)
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	result := Run(context.Background(), t.TempDir(), Step{Command: "sleep 5"}, 50*time.Millisecond)
+	if !strings.Contains(result.Error, "timed out") {
+		t.Errorf("expected a timeout, got %+v", result)
+	}
+}
+
+func TestCustomSteps(t *testing.T) {
+	steps := CustomSteps([]string{"make deps", "  ", "make warm"})
+	if len(steps) != 2 || steps[0].Kind != KindCustom || Describe(steps) != "make deps; make warm" {
+		t.Errorf("unexpected steps %+v", steps)
+	}
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// ApprovalDecision is what an approval rule resolves a tool call to.
+type ApprovalDecision string
+
+const (
+	// ApprovalAllow runs the tool call without asking.
+	ApprovalAllow ApprovalDecision = "allow"
+	// ApprovalDeny rejects the tool call without asking.
+	ApprovalDeny ApprovalDecision = "deny"
+	// ApprovalAsk asks the user. Headless runs, which have no one to ask,
+	// treat it as allow within their constraints.
+	ApprovalAsk ApprovalDecision = "ask"
+)
+
+// ApprovalRule matches tool calls and resolves them to a decision. Every
+// condition that is set must match; a list condition matches when any of its
+// entries does. A rule without conditions matches every call, which makes it
+// useful as a final catch-all.
+//
+// Example:
+//
+//	approval_rules:
+//	  - name: protect-secrets
+//	    tools: [write_file, apply_diff]
+//	    paths: ["**/.env", "secrets/**"]
+//	    decision: deny
+//	  - tools: [execute_command]
+//	    commands: ['^go (test|vet|build)\b']
+//	    decision: allow
+//	  - tools: [apply_diff]
+//	    diff_lines: "<= 20"
+//	    decision: allow
+type ApprovalRule struct {
+	Name string `yaml:"name"`
+
+	// Tools are tool names; shell-style wildcards such as "mcp_*" are allowed.
+	Tools []string `yaml:"tools"`
+
+	// Paths are workspace-relative globs matched against the paths a tool
+	// call targets. "**" matches any number of directories and a pattern
+	// without a slash matches at any depth. A call touching several paths,
+	// such as a move, matches a deny or ask rule when any of them matches,
+	// and an allow rule only when all of them do.
+	Paths []string `yaml:"paths"`
+
+	// Commands are regular expressions matched against execute_command's command.
+	Commands []string `yaml:"commands"`
+
+	// DiffLines compares the number of lines a call adds or removes with a
+	// limit, e.g. "<= 50" or "> 500". It only matches calls that change a file.
+	DiffLines string `yaml:"diff_lines"`
+
+	Decision ApprovalDecision `yaml:"decision"`
+}
+
+// ApprovalRequest describes a tool call for approval rules.
+type ApprovalRequest struct {
+	Tool string
+	// Paths are the workspace-relative paths the call writes or reads: its
+	// file, a move's destination, and the files in a directory it deletes or
+	// moves
+	Paths   []string
+	Command string // Command execute_command would run, if any
+
+	// DiffLines is the number of lines the call adds or removes; HasDiff
+	// reports whether the call changes a file at all.
+	DiffLines int
+	HasDiff   bool
+}
+
+// ApprovalResult is the decision of the first rule matching a tool call.
+type ApprovalResult struct {
+	Decision ApprovalDecision
+	Rule     string // Rule name, or its position when unnamed
+	Source   string // File the rule came from
+}
+
+// Reason describes the result for users and the model.
+func (r ApprovalResult) Reason() string {
+	return fmt.Sprintf("approval rule '%s' in %s", r.Rule, r.Source)
+}
+
+// Validate checks the rule for values that cannot be applied.
+func (r *ApprovalRule) Validate() error {
+	switch r.Decision {
+	case ApprovalAllow, ApprovalDeny, ApprovalAsk:
+	case "":
+		return fmt.Errorf("decision is required")
+	default:
+		return fmt.Errorf("invalid decision %q (must be %q, %q or %q)", r.Decision, ApprovalAllow, ApprovalDeny, ApprovalAsk)
+	}
+
+	for i, tool := range r.Tools {
+		if strings.TrimSpace(tool) == "" {
+			return fmt.Errorf("tools[%d]: tool name is empty", i)
+		}
+		if _, err := path.Match(tool, ""); err != nil {
+			return fmt.Errorf("tools[%d]: invalid pattern '%s': %w", i, tool, err)
+		}
+	}
+	for i, pattern := range r.Paths {
+		if err := workspace.ValidatePathPattern(pattern); err != nil {
+			return fmt.Errorf("paths[%d]: %w", i, err)
+		}
+	}
+	for i, expr := range r.Commands {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("commands[%d]: invalid expression '%s': %w", i, expr, err)
+		}
+	}
+	if r.DiffLines != "" {
+		if _, _, err := parseDiffLines(r.DiffLines); err != nil {
+			return fmt.Errorf("diff_lines: %w", err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to req.
+func (r *ApprovalRule) Matches(req ApprovalRequest) bool {
+	if len(r.Tools) > 0 && !matchesAny(r.Tools, func(pattern string) bool {
+		matched, _ := path.Match(pattern, req.Tool)
+		return matched
+	}) {
+		return false
+	}
+
+	if len(r.Paths) > 0 {
+		if len(req.Paths) == 0 {
+			return false
+		}
+		// An allow must cover every path so one of them cannot smuggle a
+		// protected one through; a deny or ask needs just one
+		matched := 0
+		for _, p := range req.Paths {
+			relPath := strings.TrimPrefix(path.Clean(filepath.ToSlash(p)), "./")
+			if matchesAny(r.Paths, func(pattern string) bool {
+				return workspace.MatchPathPattern(pattern, relPath)
+			}) {
+				matched++
+			}
+		}
+		if matched == 0 || (r.Decision == ApprovalAllow && matched < len(req.Paths)) {
+			return false
+		}
+	}
+
+	if len(r.Commands) > 0 {
+		command := strings.TrimSpace(req.Command)
+		if command == "" || !matchesAny(r.Commands, func(expr string) bool {
+			matched, err := regexp.MatchString(expr, command)
+			return err == nil && matched
+		}) {
+			return false
+		}
+	}
+
+	if r.DiffLines != "" {
+		op, limit, err := parseDiffLines(r.DiffLines)
+		if err != nil || !req.HasDiff || !compareLines(req.DiffLines, op, limit) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesAny(patterns []string, match func(string) bool) bool {
+	for _, pattern := range patterns {
+		if match(strings.TrimSpace(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDiffLines splits a diff_lines expression such as "<= 50" into its
+// operator and limit.
+func parseDiffLines(expr string) (string, int, error) {
+	expr = strings.TrimSpace(expr)
+	for _, op := range []string{"<=", ">=", "==", "<", ">"} {
+		if rest, ok := strings.CutPrefix(expr, op); ok {
+			limit, err := strconv.Atoi(strings.TrimSpace(rest))
+			if err != nil || limit < 0 {
+				return "", 0, fmt.Errorf("invalid line count in '%s'", expr)
+			}
+			return op, limit, nil
+		}
+	}
+	return "", 0, fmt.Errorf("invalid expression '%s' (expected an operator <, <=, >, >= or == followed by a line count)", expr)
+}
+
+func compareLines(lines int, op string, limit int) bool {
+	switch op {
+	case "<":
+		return lines < limit
+	case "<=":
+		return lines <= limit
+	case ">":
+		return lines > limit
+	case ">=":
+		return lines >= limit
+	default:
+		return lines == limit
+	}
+}
+
+// validateApprovalRules checks each rule, reporting errors by position.
+func validateApprovalRules(rules []ApprovalRule) error {
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return fmt.Errorf("approval_rules[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// firstMatchingRule returns the result of the first rule matching req.
+func firstMatchingRule(rules []ApprovalRule, source string, req ApprovalRequest) (ApprovalResult, bool) {
+	for i := range rules {
+		if !rules[i].Matches(req) {
+			continue
+		}
+		name := rules[i].Name
+		if name == "" {
+			name = fmt.Sprintf("approval_rules[%d]", i)
+		}
+		return ApprovalResult{Decision: rules[i].Decision, Rule: name, Source: source}, true
+	}
+	return ApprovalResult{}, false
+}
+
+// EvaluateApprovalRules resolves req with the approval rules of the admin
+// policy and then those of the project config; the first matching rule
+// decides. It reports false when no rule matches, in which case the
+// auto-approval settings and command whitelist apply.
+//
+// A project rule cannot allow what the admin policy withholds: an allow for a
+// tool the policy requires approval for, or for a command outside an
+// exclusive policy whitelist, becomes ask.
+func EvaluateApprovalRules(req ApprovalRequest) (ApprovalResult, bool) {
+	if policy := GetPolicy(); policy != nil {
+		if result, ok := firstMatchingRule(policy.ApprovalRules, policy.Path, req); ok {
+			return result, true
+		}
+	}
+
+	project := GetProjectConfig()
+	if project == nil {
+		return ApprovalResult{}, false
+	}
+	result, ok := firstMatchingRule(project.ApprovalRules, ProjectConfigPath, req)
+	if !ok {
+		return ApprovalResult{}, false
+	}
+
+	if result.Decision == ApprovalAllow && policyWithholdsApproval(req) {
+		result.Decision = ApprovalAsk
+	}
+	return result, true
+}
+
+// policyWithholdsApproval reports whether the admin policy requires req to be
+// approved by the user.
+func policyWithholdsApproval(req ApprovalRequest) bool {
+	if req.Tool == "execute_command" {
+		whitelisted, exclusive := policyCommandWhitelisted(req.Command)
+		return exclusive && !whitelisted
+	}
+	approved, ok := PolicyAutoApproval(req.Tool)
+	return ok && !approved
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovalRule_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    ApprovalRule
+		wantErr string
+	}{
+		{"valid", ApprovalRule{Tools: []string{"mcp_*"}, Paths: []string{"src/**"}, Commands: []string{`^go test\b`}, DiffLines: "<= 50", Decision: ApprovalAllow}, ""},
+		{"catch-all", ApprovalRule{Decision: ApprovalAsk}, ""},
+		{"missing decision", ApprovalRule{Tools: []string{"write_file"}}, "decision is required"},
+		{"unknown decision", ApprovalRule{Decision: "maybe"}, "invalid decision"},
+		{"bad command", ApprovalRule{Commands: []string{"("}, Decision: ApprovalDeny}, "commands[0]"},
+		{"bad path", ApprovalRule{Paths: []string{"[a"}, Decision: ApprovalDeny}, "paths[0]"},
+		{"bad diff size", ApprovalRule{DiffLines: "about 50", Decision: ApprovalDeny}, "diff_lines"},
+		{"negative diff size", ApprovalRule{DiffLines: "> -1", Decision: ApprovalDeny}, "diff_lines"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestApprovalRule_Matches(t *testing.T) {
+	tests := []struct {
+		name string
+		rule ApprovalRule
+		req  ApprovalRequest
+		want bool
+	}{
+		{"tool glob", ApprovalRule{Tools: []string{"mcp_*"}}, ApprovalRequest{Tool: "mcp_github_search"}, true},
+		{"other tool", ApprovalRule{Tools: []string{"write_file"}}, ApprovalRequest{Tool: "apply_diff"}, false},
+		{"path at any depth", ApprovalRule{Paths: []string{".env"}}, ApprovalRequest{Tool: "write_file", Paths: []string{"services/api/.env"}}, true},
+		{"path subtree", ApprovalRule{Paths: []string{"secrets/**"}}, ApprovalRequest{Tool: "write_file", Paths: []string{"./secrets/prod/key.pem"}}, true},
+		{"path outside subtree", ApprovalRule{Paths: []string{"secrets/**"}}, ApprovalRequest{Tool: "write_file", Paths: []string{"src/secrets.go"}}, false},
+		{"path condition without a path", ApprovalRule{Paths: []string{"**"}}, ApprovalRequest{Tool: "execute_command"}, false},
+		{"command expression", ApprovalRule{Commands: []string{`^go (test|vet)\b`}}, ApprovalRequest{Tool: "execute_command", Command: "  go test ./..."}, true},
+		{"command mismatch", ApprovalRule{Commands: []string{`^go (test|vet)\b`}}, ApprovalRequest{Tool: "execute_command", Command: "go testify"}, false},
+		{"small diff", ApprovalRule{DiffLines: "<= 20"}, ApprovalRequest{Tool: "apply_diff", DiffLines: 20, HasDiff: true}, true},
+		{"large diff", ApprovalRule{DiffLines: "<= 20"}, ApprovalRequest{Tool: "apply_diff", DiffLines: 21, HasDiff: true}, false},
+		{"diff condition without a diff", ApprovalRule{DiffLines: "< 10"}, ApprovalRequest{Tool: "execute_command"}, false},
+		{"all conditions", ApprovalRule{Tools: []string{"apply_diff"}, Paths: []string{"*.md"}, DiffLines: "> 100"}, ApprovalRequest{Tool: "apply_diff", Paths: []string{"docs/guide.md"}, DiffLines: 150, HasDiff: true}, true},
+		{"one condition fails", ApprovalRule{Tools: []string{"apply_diff"}, Paths: []string{"*.md"}, DiffLines: "> 100"}, ApprovalRequest{Tool: "apply_diff", Paths: []string{"docs/guide.md"}, DiffLines: 5, HasDiff: true}, false},
+		{"deny matching any path", ApprovalRule{Paths: []string{"secrets/**"}, Decision: ApprovalDeny}, ApprovalRequest{Tool: "move_file", Paths: []string{"notes.txt", "secrets/notes.txt"}}, true},
+		{"allow matching some paths", ApprovalRule{Paths: []string{"docs/**"}, Decision: ApprovalAllow}, ApprovalRequest{Tool: "move_file", Paths: []string{"docs/a.md", "secrets/a.md"}}, false},
+		{"allow matching every path", ApprovalRule{Paths: []string{"docs/**"}, Decision: ApprovalAllow}, ApprovalRequest{Tool: "move_file", Paths: []string{"docs/a.md", "docs/old/a.md"}}, true},
+		{"no conditions", ApprovalRule{}, ApprovalRequest{Tool: "anything"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rule.Matches(tt.req))
+		})
+	}
+}
+
+func TestEvaluateApprovalRules_FirstMatchWins(t *testing.T) {
+	SetProjectConfig(&ProjectConfig{ApprovalRules: []ApprovalRule{
+		{Name: "secrets", Paths: []string{"**/.env"}, Decision: ApprovalDeny},
+		{Tools: []string{"write_file"}, Decision: ApprovalAllow},
+	}})
+	t.Cleanup(func() { SetProjectConfig(nil) })
+
+	result, ok := EvaluateApprovalRules(ApprovalRequest{Tool: "write_file", Paths: []string{"app/.env"}})
+	require.True(t, ok)
+	assert.Equal(t, ApprovalResult{Decision: ApprovalDeny, Rule: "secrets", Source: ProjectConfigPath}, result)
+
+	result, ok = EvaluateApprovalRules(ApprovalRequest{Tool: "write_file", Paths: []string{"main.go"}})
+	require.True(t, ok)
+	assert.Equal(t, ApprovalAllow, result.Decision)
+	assert.Equal(t, "approval_rules[1]", result.Rule)
+
+	_, ok = EvaluateApprovalRules(ApprovalRequest{Tool: "read_file", Paths: []string{"main.go"}})
+	assert.False(t, ok, "no rule matches, so the auto-approval settings apply")
+}
+
+func TestEvaluateApprovalRules_PolicyPrecedence(t *testing.T) {
+	SetPolicy(&PolicyConfig{
+		Path:                      DefaultPolicyPath,
+		AutoApproval:              map[string]bool{"write_file": false},
+		CommandWhitelist:          []WhitelistPattern{{Pattern: "go test", Type: MatchTypePrefix}},
+		ExclusiveCommandWhitelist: true,
+		ApprovalRules: []ApprovalRule{
+			{Name: "no-network", Commands: []string{`^curl\b`}, Decision: ApprovalDeny},
+		},
+	})
+	SetProjectConfig(&ProjectConfig{ApprovalRules: []ApprovalRule{
+		{Name: "trust-everything", Decision: ApprovalAllow},
+	}})
+	t.Cleanup(func() {
+		SetPolicy(nil)
+		SetProjectConfig(nil)
+	})
+
+	// Policy rules are evaluated before the project's
+	result, ok := EvaluateApprovalRules(ApprovalRequest{Tool: "execute_command", Command: "curl example.com"})
+	require.True(t, ok)
+	assert.Equal(t, ApprovalDeny, result.Decision)
+	assert.Equal(t, DefaultPolicyPath, result.Source)
+
+	// A project allow cannot approve what the policy withholds
+	result, _ = EvaluateApprovalRules(ApprovalRequest{Tool: "write_file", Paths: []string{"main.go"}})
+	assert.Equal(t, ApprovalAsk, result.Decision)
+	result, _ = EvaluateApprovalRules(ApprovalRequest{Tool: "execute_command", Command: "make deploy"})
+	assert.Equal(t, ApprovalAsk, result.Decision)
+
+	result, _ = EvaluateApprovalRules(ApprovalRequest{Tool: "execute_command", Command: "go test ./..."})
+	assert.Equal(t, ApprovalAllow, result.Decision)
+	result, _ = EvaluateApprovalRules(ApprovalRequest{Tool: "read_file", Paths: []string{"main.go"}})
+	assert.Equal(t, ApprovalAllow, result.Decision)
+}
+
+func TestLoadProjectConfig_InvalidApprovalRule(t *testing.T) {
+	dir := writeProjectConfig(t, `
+approval_rules:
+  - tools: [write_file]
+    decision: sometimes
+`)
+
+	_, err := LoadProjectConfig(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "approval_rules[0]")
+}
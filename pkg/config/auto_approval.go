@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"maps"
 	"sync"
+
+	"github.com/entrhq/forge/pkg/config/jsonschema"
 )
 
 const (
@@ -41,6 +43,11 @@ func (s *AutoApprovalSection) Description() string {
 	return "Configure which tools are automatically approved without prompts. Note: execute_command always requires approval or whitelist."
 }
 
+// Schema describes the section's data: whether each tool runs without approval.
+func (s *AutoApprovalSection) Schema() *jsonschema.Schema {
+	return jsonschema.Map(jsonschema.Boolean())
+}
+
 // Data returns the current configuration data.
 func (s *AutoApprovalSection) Data() map[string]any {
 	s.mu.RLock()
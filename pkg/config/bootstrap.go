@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BootstrapConfig controls project setup, the commands that install the
+// project's dependencies and warm its build cache before a session starts.
+// By default the setup Forge detects from go.mod, package.json and
+// requirements.txt is offered with /setup when dependencies look missing.
+//
+// Example:
+//
+//	bootstrap:
+//	  auto_run: true                 # Run setup before the session starts
+//	  commands: ["make deps"]        # Run these instead of the detected setup
+//	  timeout: 5m
+type BootstrapConfig struct {
+	Enabled  *bool         `yaml:"enabled"`  // Detect and offer setup (default true)
+	AutoRun  bool          `yaml:"auto_run"` // Run setup at startup instead of offering it
+	Commands []string      `yaml:"commands"` // Shell commands run in the workspace instead of the detected ones
+	Timeout  time.Duration `yaml:"timeout"`  // Limit for each command (default 10m)
+}
+
+// BootstrapSettings are the project setup settings in effect.
+type BootstrapSettings struct {
+	Enabled  bool
+	AutoRun  bool
+	Commands []string
+	Timeout  time.Duration // 0 means the default
+}
+
+// Validate checks the config for values that cannot be applied. It is safe to
+// call on a nil config.
+func (c *BootstrapConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout: must not be negative")
+	}
+	for i, command := range c.Commands {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("commands[%d]: command is empty", i)
+		}
+	}
+	return nil
+}
+
+// GetBootstrap returns the project's setup settings, with defaults for what
+// it leaves unset. It is safe to call on a nil config.
+func (p *ProjectConfig) GetBootstrap() BootstrapSettings {
+	settings := BootstrapSettings{Enabled: true}
+	if p == nil || p.Bootstrap == nil {
+		return settings
+	}
+	c := p.Bootstrap
+	if c.Enabled != nil {
+		settings.Enabled = *c.Enabled
+	}
+	settings.AutoRun = c.AutoRun
+	settings.Commands = c.Commands
+	settings.Timeout = c.Timeout
+	return settings
+}
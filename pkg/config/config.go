@@ -26,28 +26,10 @@ func Initialize(configPath string) error {
 	manager := NewManager(store)
 
 	// Register default sections
-	if err := manager.RegisterSection(NewAutoApprovalSection()); err != nil {
-		return err
-	}
-
-	if err := manager.RegisterSection(NewCommandWhitelistSection()); err != nil {
-		return err
-	}
-
-	if err := manager.RegisterSection(NewLLMSection()); err != nil {
-		return err
-	}
-
-	if err := manager.RegisterSection(NewUISection()); err != nil {
-		return err
-	}
-
-	if err := manager.RegisterSection(NewMemorySection()); err != nil {
-		return err
-	}
-
-	if err := manager.RegisterSection(NewMultimodalSection()); err != nil {
-		return err
+	for _, section := range defaultSections() {
+		if err := manager.RegisterSection(section); err != nil {
+			return err
+		}
 	}
 
 	// Load configuration
@@ -120,8 +102,17 @@ func GetCommandWhitelist() *CommandWhitelistSection {
 }
 
 // IsToolAutoApproved checks if a tool is configured for auto-approval.
-// Returns false if config is not initialized.
+// The admin policy takes precedence over the project config, which takes
+// precedence over the global one. Returns false if config is not initialized
+// and neither the policy nor the project sets anything.
 func IsToolAutoApproved(toolName string) bool {
+	if approved, ok := PolicyAutoApproval(toolName); ok {
+		return approved
+	}
+	if approved, ok := projectAutoApproval(toolName); ok {
+		return approved
+	}
+
 	autoApproval := GetAutoApproval()
 	if autoApproval == nil {
 		return false
@@ -150,8 +141,17 @@ func GetLLM() *LLMSection {
 }
 
 // IsCommandWhitelisted checks if a command is whitelisted for auto-approval.
-// Returns false if config is not initialized.
+// Patterns from the admin policy and the project config are checked in
+// addition to the global ones, unless the policy's whitelist is exclusive.
 func IsCommandWhitelisted(command string) bool {
+	whitelisted, exclusive := policyCommandWhitelisted(command)
+	if whitelisted || exclusive {
+		return whitelisted
+	}
+	if projectCommandWhitelisted(command) {
+		return true
+	}
+
 	whitelist := GetCommandWhitelist()
 	if whitelist == nil {
 		return false
@@ -218,3 +218,43 @@ func GetMultimodal() *MultimodalSection {
 
 	return multimodal
 }
+
+// GetUpdate returns the update settings section from global config.
+// Returns nil if config is not initialized.
+func GetUpdate() *UpdateSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection(SectionIDUpdate)
+	if !ok {
+		return nil
+	}
+
+	update, ok := section.(*UpdateSection)
+	if !ok {
+		return nil
+	}
+
+	return update
+}
+
+// GetSecurity returns the security settings section from global config.
+// Returns nil if config is not initialized.
+func GetSecurity() *SecuritySection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection(SectionIDSecurity)
+	if !ok {
+		return nil
+	}
+
+	security, ok := section.(*SecuritySection)
+	if !ok {
+		return nil
+	}
+
+	return security
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContextConfig selects the strategies that keep the conversation within the
+// model's context window. Strategies run in the order listed; when none are
+// listed the built-in tool_calls, threshold and goal_batch strategies run.
+// Binaries embedding Forge may register their own strategies by name.
+//
+// Example:
+//
+//	context:
+//	  strategies:
+//	    - name: tool_calls
+//	      options:
+//	        min_tool_calls: 5
+//	    - name: threshold
+//	      options:
+//	        trigger_percent: 70
+type ContextConfig struct {
+	Strategies []ContextStrategy `yaml:"strategies"`
+}
+
+// ContextStrategy is one configured summarization strategy.
+type ContextStrategy struct {
+	Name    string         `yaml:"name"`
+	Options map[string]any `yaml:"options"` // Strategy-specific settings
+}
+
+// Validate checks the config for values that cannot be applied. Strategy
+// names are resolved when the session starts, since strategies may be
+// registered by the binary. It is safe to call on a nil config.
+func (c *ContextConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(c.Strategies))
+	for i, strategy := range c.Strategies {
+		if strings.TrimSpace(strategy.Name) == "" {
+			return fmt.Errorf("strategies[%d]: name is empty", i)
+		}
+		if seen[strategy.Name] {
+			return fmt.Errorf("strategies[%d]: %q is listed twice", i, strategy.Name)
+		}
+		seen[strategy.Name] = true
+	}
+	return nil
+}
+
+// GetContextStrategies returns the project's summarization strategies, or nil
+// to use the defaults. It is safe to call on a nil config.
+func (p *ProjectConfig) GetContextStrategies() []ContextStrategy {
+	if p == nil || p.Context == nil {
+		return nil
+	}
+	return p.Context.Strategies
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/config/jsonschema"
+)
+
+const (
+	// SectionIDExperimental is the identifier for the experimental features section
+	SectionIDExperimental = "experimental"
+
+	// ExperimentalASTTools gates tools that edit code through a language
+	// server's syntax tree instead of text
+	ExperimentalASTTools = "ast_tools"
+)
+
+// ExperimentalFeature is a not-yet-stable capability that stays off until a
+// user or project opts in.
+type ExperimentalFeature struct {
+	Name        string
+	Description string
+	Tools       []string // Tools that are only registered while the feature is enabled
+}
+
+// experimentalFeatures lists every feature behind an opt-in, in display order.
+// Features graduate by being removed from this list.
+var experimentalFeatures = []ExperimentalFeature{
+	{
+		Name:        ExperimentalASTTools,
+		Description: "Semantic code edits through gopls, such as renaming a Go symbol across the workspace",
+		Tools:       []string{"rename_symbol"},
+	},
+}
+
+// ExperimentalFeatures returns the features that can be opted into.
+func ExperimentalFeatures() []ExperimentalFeature {
+	features := make([]ExperimentalFeature, len(experimentalFeatures))
+	copy(features, experimentalFeatures)
+	return features
+}
+
+// lookupExperimentalFeature returns the feature with the given name.
+func lookupExperimentalFeature(name string) (ExperimentalFeature, bool) {
+	for _, feature := range experimentalFeatures {
+		if feature.Name == name {
+			return feature, true
+		}
+	}
+	return ExperimentalFeature{}, false
+}
+
+// ExperimentalSection manages the user's opt-ins to experimental features.
+type ExperimentalSection struct {
+	// features maps feature names to whether they are enabled
+	features map[string]bool
+	mu       sync.RWMutex
+}
+
+// NewExperimentalSection creates a new experimental section with every feature disabled.
+func NewExperimentalSection() *ExperimentalSection {
+	return &ExperimentalSection{
+		features: make(map[string]bool),
+	}
+}
+
+// ID returns the section identifier.
+func (s *ExperimentalSection) ID() string {
+	return SectionIDExperimental
+}
+
+// Title returns the section title.
+func (s *ExperimentalSection) Title() string {
+	return "Experimental Features"
+}
+
+// Description returns the section description.
+func (s *ExperimentalSection) Description() string {
+	return "Opt in to features that are not yet stable. They may change or be removed between releases. Changes apply the next time Forge starts."
+}
+
+// Schema describes the section's data: whether each feature is enabled.
+func (s *ExperimentalSection) Schema() *jsonschema.Schema {
+	return jsonschema.Map(jsonschema.Boolean())
+}
+
+// Data returns the current configuration data, with an entry for every known feature.
+func (s *ExperimentalSection) Data() map[string]any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data := make(map[string]any, len(experimentalFeatures))
+	for _, feature := range experimentalFeatures {
+		data[feature.Name] = s.features[feature.Name]
+	}
+	return data
+}
+
+// SetData updates the configuration from the provided data.
+func (s *ExperimentalSection) SetData(data map[string]any) error {
+	if data == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, value := range data {
+		enabled, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("invalid value type for experimental feature '%s': expected bool, got %T", name, value)
+		}
+		// Keep unknown features so a config shared with newer versions survives a save
+		s.features[name] = enabled
+	}
+
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *ExperimentalSection) Validate() error {
+	// Unknown features are ignored rather than rejected, for forward compatibility
+	return nil
+}
+
+// Reset resets the section to default configuration (all disabled).
+func (s *ExperimentalSection) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.features = make(map[string]bool)
+}
+
+// IsEnabled returns true if the user opted in to the feature.
+func (s *ExperimentalSection) IsEnabled(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.features[name]
+}
+
+// SetEnabled sets whether the user opted in to the feature.
+func (s *ExperimentalSection) SetEnabled(name string, enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.features[name] = enabled
+}
+
+// GetExperimental returns the experimental features section from global config.
+// Returns nil if config is not initialized.
+func GetExperimental() *ExperimentalSection {
+	if !IsInitialized() {
+		return nil
+	}
+
+	section, ok := Global().GetSection(SectionIDExperimental)
+	if !ok {
+		return nil
+	}
+
+	experimental, ok := section.(*ExperimentalSection)
+	if !ok {
+		return nil
+	}
+
+	return experimental
+}
+
+// IsExperimentalEnabled reports whether an experimental feature is enabled.
+// The project config takes precedence over the global one. Features are
+// disabled unless one of them opts in.
+func IsExperimentalEnabled(name string) bool {
+	if cfg := GetProjectConfig(); cfg != nil {
+		if enabled, ok := cfg.Experimental[name]; ok {
+			return enabled
+		}
+	}
+
+	experimental := GetExperimental()
+	if experimental == nil {
+		return false
+	}
+	return experimental.IsEnabled(name)
+}
+
+// EnabledExperimentalFeatures returns the experimental features that are enabled.
+func EnabledExperimentalFeatures() []ExperimentalFeature {
+	var enabled []ExperimentalFeature
+	for _, feature := range experimentalFeatures {
+		if IsExperimentalEnabled(feature.Name) {
+			enabled = append(enabled, feature)
+		}
+	}
+	return enabled
+}
+
+// DisabledExperimentalTools returns the tools of every experimental feature
+// that is not enabled, for exclusion from the agent's tool set.
+func DisabledExperimentalTools() []string {
+	var tools []string
+	for _, feature := range experimentalFeatures {
+		if !IsExperimentalEnabled(feature.Name) {
+			tools = append(tools, feature.Tools...)
+		}
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// AppendExperimentalNotice returns systemPrompt with a section naming the
+// enabled experimental features, so the agent treats them with care. It
+// returns systemPrompt unchanged when none are enabled.
+func AppendExperimentalNotice(systemPrompt string) string {
+	enabled := EnabledExperimentalFeatures()
+	if len(enabled) == 0 {
+		return systemPrompt
+	}
+
+	var notice strings.Builder
+	notice.WriteString("\n\n# Experimental Features\n\n")
+	notice.WriteString("The user opted in to these experimental features. They are not yet stable: prefer the stable tools when both would work, ")
+	notice.WriteString("and if an experimental tool fails or behaves unexpectedly, fall back to the stable tools and mention it in your summary.\n")
+	for _, feature := range enabled {
+		fmt.Fprintf(&notice, "\n- %s: %s", feature.Name, feature.Description)
+		if len(feature.Tools) > 0 {
+			fmt.Fprintf(&notice, " (tools: %s)", strings.Join(feature.Tools, ", "))
+		}
+	}
+	return systemPrompt + notice.String()
+}
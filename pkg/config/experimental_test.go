@@ -0,0 +1,60 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExperimentalSection(t *testing.T) {
+	section := NewExperimentalSection()
+	assert.Equal(t, SectionIDExperimental, section.ID())
+	assert.Equal(t, false, section.Data()[ExperimentalASTTools], "every known feature should be listed and off by default")
+
+	require.NoError(t, section.SetData(map[string]any{ExperimentalASTTools: true, "future_feature": true}))
+	assert.True(t, section.IsEnabled(ExperimentalASTTools))
+	assert.True(t, section.IsEnabled("future_feature"), "unknown features should survive for newer versions")
+
+	assert.Error(t, section.SetData(map[string]any{ExperimentalASTTools: "yes"}))
+
+	section.Reset()
+	assert.False(t, section.IsEnabled(ExperimentalASTTools))
+}
+
+func TestExperimental_Layering(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, Initialize(tmpFile))
+	t.Cleanup(func() { SetProjectConfig(nil) })
+
+	assert.False(t, IsExperimentalEnabled(ExperimentalASTTools), "features should be off by default")
+	assert.Contains(t, DisabledExperimentalTools(), "rename_symbol")
+	assert.Equal(t, "base", AppendExperimentalNotice("base"))
+
+	GetExperimental().SetEnabled(ExperimentalASTTools, true)
+	assert.True(t, IsExperimentalEnabled(ExperimentalASTTools))
+	assert.NotContains(t, DisabledExperimentalTools(), "rename_symbol")
+
+	prompt := AppendExperimentalNotice("base")
+	assert.True(t, strings.HasPrefix(prompt, "base\n\n# Experimental Features"))
+	assert.Contains(t, prompt, "- ast_tools: ")
+	assert.Contains(t, prompt, "(tools: rename_symbol)")
+
+	SetProjectConfig(&ProjectConfig{Experimental: map[string]bool{ExperimentalASTTools: false}})
+	assert.False(t, IsExperimentalEnabled(ExperimentalASTTools), "project setting should override global")
+	assert.Contains(t, DisabledExperimentalTools(), "rename_symbol")
+}
+
+func TestLoadProjectConfig_Experimental(t *testing.T) {
+	dir := writeProjectConfig(t, "experimental:\n  ast_tools: true\n")
+	cfg, err := LoadProjectConfig(dir)
+	require.NoError(t, err)
+	assert.True(t, cfg.Experimental[ExperimentalASTTools])
+
+	dir = writeProjectConfig(t, "experimental:\n  ast_tool: true\n")
+	_, err = LoadProjectConfig(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown feature "ast_tool"`)
+}
@@ -0,0 +1,246 @@
+// Package jsonschema describes Forge's configuration files as JSON Schema and
+// checks YAML and JSON documents against those descriptions, so a mistyped
+// key or a value of the wrong type is reported before a run starts instead of
+// being silently ignored.
+//
+// Only the subset of JSON Schema the configuration needs is supported:
+// types, properties, additionalProperties, items, enum, pattern, oneOf and
+// deprecated.
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect the schemas declare.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the durations time.ParseDuration accepts, e.g. 30s or 1h30m.
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// Type names
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeArray   = "array"
+	TypeObject  = "object"
+)
+
+// Schema is a JSON Schema. An object schema with Properties and no
+// AdditionalProperties is closed: keys it does not list are unknown.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	OneOf       []*Schema          `json:"oneOf,omitempty"`
+	Deprecated  bool               `json:"deprecated,omitempty"`
+
+	// AdditionalProperties is the schema of keys not in Properties, such as
+	// the values of a map
+	AdditionalProperties *Schema `json:"-"`
+}
+
+// MarshalJSON writes additionalProperties as false for closed objects.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	out := struct {
+		*plain
+		AdditionalProperties any `json:"additionalProperties,omitempty"`
+	}{plain: (*plain)(s)}
+	switch {
+	case s.AdditionalProperties != nil:
+		out.AdditionalProperties = s.AdditionalProperties
+	case s.closed():
+		out.AdditionalProperties = false
+	}
+	return json.Marshal(out)
+}
+
+// closed reports whether keys outside Properties are unknown.
+func (s *Schema) closed() bool {
+	return s.Type == TypeObject && s.Properties != nil && s.AdditionalProperties == nil
+}
+
+// String returns a string schema.
+func String() *Schema {
+	return &Schema{Type: TypeString}
+}
+
+// Enum returns a string schema allowing only values.
+func Enum(values ...string) *Schema {
+	s := String()
+	for _, value := range values {
+		s.Enum = append(s.Enum, value)
+	}
+	return s
+}
+
+// Integer returns an integer schema.
+func Integer() *Schema {
+	return &Schema{Type: TypeInteger}
+}
+
+// Number returns a number schema.
+func Number() *Schema {
+	return &Schema{Type: TypeNumber}
+}
+
+// Boolean returns a boolean schema.
+func Boolean() *Schema {
+	return &Schema{Type: TypeBoolean}
+}
+
+// Duration returns the schema of a time.Duration, written like 30s or 5m.
+func Duration() *Schema {
+	return &Schema{Type: TypeString, Format: "duration", Pattern: durationPattern}
+}
+
+// Array returns an array schema whose elements match items.
+func Array(items *Schema) *Schema {
+	return &Schema{Type: TypeArray, Items: items}
+}
+
+// Object returns a closed object schema with the given properties.
+func Object(properties map[string]*Schema) *Schema {
+	if properties == nil {
+		properties = map[string]*Schema{}
+	}
+	return &Schema{Type: TypeObject, Properties: properties}
+}
+
+// Map returns the schema of an object with arbitrary keys whose values match
+// values.
+func Map(values *Schema) *Schema {
+	return &Schema{Type: TypeObject, AdditionalProperties: values}
+}
+
+// OneOf returns a schema matching any of alternatives.
+func OneOf(alternatives ...*Schema) *Schema {
+	return &Schema{OneOf: alternatives}
+}
+
+// Describe sets the description and returns s.
+func (s *Schema) Describe(description string) *Schema {
+	s.Description = description
+	return s
+}
+
+// Deprecate marks s as deprecated, with a description of what replaces it,
+// and returns s. Validation warns wherever a document uses s.
+func (s *Schema) Deprecate(replacement string) *Schema {
+	s.Deprecated = true
+	s.Description = replacement
+	return s
+}
+
+// Lookup returns the schema at path, following properties by name and "[]"
+// into array items and map values. It returns nil when there is none.
+func (s *Schema) Lookup(path ...string) *Schema {
+	current := s
+	for _, name := range path {
+		if current == nil {
+			return nil
+		}
+		switch {
+		case name == "[]" && current.Items != nil:
+			current = current.Items
+		case name == "[]":
+			current = current.AdditionalProperties
+		default:
+			current = current.Properties[name]
+		}
+	}
+	return current
+}
+
+// durationType is the reflect.Type of time.Duration
+var durationType = reflect.TypeFor[time.Duration]()
+
+// FromType returns the schema of the YAML documents that decode into a value
+// of type t, naming fields the way gopkg.in/yaml.v3 does: by their yaml tag,
+// or their lowercased name when untagged. Structs become closed objects and
+// time.Duration a duration string. Enums and deprecations are not visible
+// through reflection; set them on the result with Lookup.
+func FromType(t reflect.Type) *Schema {
+	return fromType(t, map[reflect.Type]bool{})
+}
+
+// fromType builds the schema of t. seen holds the struct types being built,
+// so recursive types end in an unconstrained schema instead of looping.
+func fromType(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return Duration()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return String()
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Integer()
+	case reflect.Float32, reflect.Float64:
+		return Number()
+	case reflect.Slice, reflect.Array:
+		return Array(fromType(t.Elem(), seen))
+	case reflect.Map:
+		return Map(fromType(t.Elem(), seen))
+	case reflect.Struct:
+		if seen[t] {
+			return &Schema{}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		s := Object(nil)
+		addFields(s, t, seen)
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+// addFields adds the properties of struct type t to s, flattening fields
+// tagged inline.
+func addFields(s *Schema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if strings.Contains(options, "inline") {
+			inline := field.Type
+			for inline.Kind() == reflect.Pointer {
+				inline = inline.Elem()
+			}
+			if inline.Kind() == reflect.Struct {
+				addFields(s, inline, seen)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		s.Properties[name] = fromType(field.Type, seen)
+	}
+}
@@ -0,0 +1,91 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFromType(t *testing.T) {
+	schema := testSchema()
+
+	tests := []struct {
+		path     []string
+		wantType string
+	}{
+		{[]string{"task"}, TypeString},
+		{[]string{"retries"}, TypeInteger},
+		{[]string{"ratio"}, TypeNumber},
+		{[]string{"enabled"}, TypeBoolean},
+		{[]string{"gates"}, TypeArray},
+		{[]string{"gates", "[]", "timeout"}, TypeString},
+		{[]string{"labels", "[]"}, TypeString},
+		{[]string{"nested"}, ""}, // Recursive types are left unconstrained
+	}
+	for _, tt := range tests {
+		field := schema.Lookup(tt.path...)
+		if field == nil {
+			t.Errorf("Lookup(%v) = nil", tt.path)
+			continue
+		}
+		if field.Type != tt.wantType {
+			t.Errorf("Lookup(%v).Type = %q, want %q", tt.path, field.Type, tt.wantType)
+		}
+	}
+
+	if schema.Lookup("internal") != nil {
+		t.Error("fields tagged yaml:\"-\" should be left out")
+	}
+	if schema.Lookup("gates", "[]", "timeout").Format != "duration" {
+		t.Error("time.Duration should have the duration format")
+	}
+}
+
+func TestFromType_Untagged(t *testing.T) {
+	type inner struct {
+		Port int
+	}
+	type untagged struct {
+		BaseURL string
+		Inner   inner `yaml:",inline"`
+	}
+	schema := FromType(reflect.TypeFor[untagged]())
+
+	if schema.Lookup("baseurl") == nil {
+		t.Error("untagged fields should be named like yaml.v3 names them")
+	}
+	if schema.Lookup("port") == nil {
+		t.Error("inline fields should be flattened")
+	}
+	if len(schema.Properties) != 2 {
+		t.Errorf("expected 2 properties, got %d", len(schema.Properties))
+	}
+}
+
+func TestSchema_MarshalJSON(t *testing.T) {
+	schema := Object(map[string]*Schema{
+		"labels": Map(String()),
+		"any":    {Type: TypeObject},
+	})
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if decoded["additionalProperties"] != false {
+		t.Errorf("closed object additionalProperties = %v, want false", decoded["additionalProperties"])
+	}
+	properties := decoded["properties"].(map[string]any)
+	labels := properties["labels"].(map[string]any)
+	if labels["additionalProperties"].(map[string]any)["type"] != TypeString {
+		t.Errorf("map additionalProperties = %v, want a string schema", labels["additionalProperties"])
+	}
+	if _, ok := properties["any"].(map[string]any)["additionalProperties"]; ok {
+		t.Error("an object without properties should accept any keys")
+	}
+}
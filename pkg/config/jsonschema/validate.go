@@ -0,0 +1,317 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Issue is a problem found in a document.
+type Issue struct {
+	Line    int    // 1-based line of the offending key or value, 0 when unknown
+	Path    string // Location in the document, e.g. quality_gates[0].timeout
+	Message string
+
+	// Warning is set for problems that do not stop the document from
+	// loading, such as a deprecated key
+	Warning bool
+}
+
+// String formats the issue for display.
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", i.Line)
+	}
+	if i.Warning {
+		b.WriteString("warning: ")
+	} else {
+		b.WriteString("error: ")
+	}
+	if i.Path != "" {
+		b.WriteString(i.Path + ": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// HasErrors reports whether any issue is an error rather than a warning.
+func HasErrors(issues []Issue) bool {
+	return slices.ContainsFunc(issues, func(issue Issue) bool {
+		return !issue.Warning
+	})
+}
+
+// Validate checks a YAML or JSON document against s. It returns an error only
+// when the document cannot be parsed; problems with its contents are
+// returned as issues, in document order.
+func Validate(s *Schema, data []byte) ([]Issue, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	v := &validator{}
+	v.validate(s, doc.Content[0], "")
+	return v.issues, nil
+}
+
+// validator collects the issues found while walking a document
+type validator struct {
+	issues []Issue
+}
+
+// report records an issue at node.
+func (v *validator) report(node *yaml.Node, path string, warning bool, format string, args ...any) {
+	v.issues = append(v.issues, Issue{
+		Line:    node.Line,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+		Warning: warning,
+	})
+}
+
+// validate checks node, found at path, against s.
+func (v *validator) validate(s *Schema, node *yaml.Node, path string) {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	// An empty value decodes to the zero value whatever the type
+	if s == nil || node.ShortTag() == "!!null" {
+		return
+	}
+
+	if len(s.OneOf) > 0 {
+		v.validateOneOf(s, node, path)
+		return
+	}
+
+	if !matchesType(s, node) {
+		v.report(node, path, false, "expected %s, got %s", describe(s), describeNode(node))
+		return
+	}
+	if s.Deprecated {
+		message := "deprecated"
+		if s.Description != "" {
+			message += ": " + s.Description
+		}
+		v.report(node, path, true, "%s", message)
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		v.validateMapping(s, node, path)
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case yaml.ScalarNode:
+		v.validateScalar(s, node, path)
+	}
+}
+
+// validateOneOf checks that node matches one of s's alternatives, reporting
+// the issues of the closest alternative when it matches none.
+func (v *validator) validateOneOf(s *Schema, node *yaml.Node, path string) {
+	var closest []Issue
+	for _, alternative := range s.OneOf {
+		if !matchesType(alternative, node) {
+			continue
+		}
+		inner := &validator{}
+		inner.validate(alternative, node, path)
+		if !HasErrors(inner.issues) {
+			v.issues = append(v.issues, inner.issues...)
+			return
+		}
+		if closest == nil {
+			closest = inner.issues
+		}
+	}
+	if closest != nil {
+		v.issues = append(v.issues, closest...)
+		return
+	}
+
+	expected := make([]string, 0, len(s.OneOf))
+	for _, alternative := range s.OneOf {
+		expected = append(expected, describe(alternative))
+	}
+	v.report(node, path, false, "expected %s, got %s", strings.Join(expected, " or "), describeNode(node))
+}
+
+// validateMapping checks the keys and values of a mapping node.
+func (v *validator) validateMapping(s *Schema, node *yaml.Node, path string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		// A merge key pulls in the keys of another mapping
+		if key.ShortTag() == "!!merge" {
+			v.validate(s, value, path)
+			continue
+		}
+
+		keyPath := joinPath(path, key.Value)
+		property, known := s.Properties[key.Value]
+		switch {
+		case known:
+			v.validate(property, value, keyPath)
+		case s.AdditionalProperties != nil:
+			v.validate(s.AdditionalProperties, value, keyPath)
+		case s.closed():
+			v.report(key, keyPath, false, "unknown key%s", suggest(key.Value, s.Properties))
+		}
+	}
+}
+
+// validateScalar checks a scalar's value against s's enum and pattern.
+func (v *validator) validateScalar(s *Schema, node *yaml.Node, path string) {
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(value any) bool {
+		return fmt.Sprint(value) == node.Value
+	}) {
+		allowed := make([]string, 0, len(s.Enum))
+		for _, value := range s.Enum {
+			allowed = append(allowed, fmt.Sprintf("%q", value))
+		}
+		v.report(node, path, false, "invalid value %q (must be one of %s)", node.Value, strings.Join(allowed, ", "))
+		return
+	}
+
+	if s.Format == "duration" {
+		if _, err := time.ParseDuration(node.Value); err != nil {
+			v.report(node, path, false, "invalid duration %q (use a unit, e.g. 30s or 5m)", node.Value)
+		}
+		return
+	}
+	if s.Pattern != "" {
+		if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(node.Value) {
+			v.report(node, path, false, "%q does not match %s", node.Value, s.Pattern)
+		}
+	}
+}
+
+// matchesType reports whether node's kind and resolved tag fit s's type, as
+// gopkg.in/yaml.v3 would decode them. A string accepts any scalar, since
+// YAML scalars such as 42 or true decode into strings as written.
+func matchesType(s *Schema, node *yaml.Node) bool {
+	switch s.Type {
+	case "":
+		return true
+	case TypeObject:
+		return node.Kind == yaml.MappingNode
+	case TypeArray:
+		return node.Kind == yaml.SequenceNode
+	}
+	if node.Kind != yaml.ScalarNode {
+		return false
+	}
+
+	tag := node.ShortTag()
+	switch s.Type {
+	case TypeString:
+		// A duration given as a bare number would be read as nanoseconds
+		return s.Format != "duration" || (tag != "!!int" && tag != "!!float")
+	case TypeBoolean:
+		return tag == "!!bool"
+	case TypeNumber:
+		return tag == "!!int" || tag == "!!float"
+	case TypeInteger:
+		if tag == "!!int" {
+			return true
+		}
+		if tag == "!!float" {
+			f, err := strconv.ParseFloat(node.Value, 64)
+			return err == nil && f == math.Trunc(f)
+		}
+	}
+	return false
+}
+
+// describe names the values s accepts.
+func describe(s *Schema) string {
+	switch {
+	case s.Format == "duration":
+		return "a duration such as 30s or 5m"
+	case s.Type == TypeObject:
+		return "a mapping"
+	case s.Type == TypeArray:
+		return "a list"
+	case s.Type == TypeInteger:
+		return "an integer"
+	case s.Type == "":
+		return "a value"
+	default:
+		return "a " + s.Type
+	}
+}
+
+// describeNode names the kind of value node holds.
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch node.ShortTag() {
+	case "!!bool":
+		return fmt.Sprintf("boolean %s", node.Value)
+	case "!!int", "!!float":
+		return fmt.Sprintf("number %s", node.Value)
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+// joinPath appends key to path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// suggest returns a "did you mean" hint naming the known key closest to key,
+// or "" when none is close.
+func suggest(key string, properties map[string]*Schema) string {
+	best, bestDistance := "", 3
+	for name := range properties {
+		if d := editDistance(key, name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
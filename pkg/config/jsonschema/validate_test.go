@@ -0,0 +1,174 @@
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testGate struct {
+	Name    string        `yaml:"name"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type testConfig struct {
+	Task     string            `yaml:"task"`
+	Mode     string            `yaml:"mode"`
+	Retries  int               `yaml:"retries"`
+	Ratio    float64           `yaml:"ratio"`
+	Enabled  bool              `yaml:"enabled"`
+	Gates    []testGate        `yaml:"gates"`
+	Labels   map[string]string `yaml:"labels"`
+	Legacy   string            `yaml:"legacy"`
+	Internal string            `yaml:"-"`
+	Nested   *testConfig       `yaml:"nested"`
+}
+
+func testSchema() *Schema {
+	schema := FromType(reflect.TypeFor[testConfig]())
+	schema.Lookup("mode").Enum = Enum("read-only", "write").Enum
+	schema.Lookup("legacy").Deprecate("use task instead")
+	return schema
+}
+
+func TestValidate_Valid(t *testing.T) {
+	doc := `
+task: fix it
+mode: write
+retries: 3
+ratio: 0.5
+enabled: true
+gates:
+  - name: test
+    timeout: 2m
+labels:
+  team: core
+nested:
+  anything: goes
+`
+	issues, err := Validate(testSchema(), []byte(doc))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+}
+
+func TestValidate_Issues(t *testing.T) {
+	doc := `task: 42
+mode: readonly
+retries: many
+enabled: yes
+gates:
+  - name: test
+    timout: 2m
+  - timeout: 300
+legacy: old
+tsk: typo
+`
+	issues, err := Validate(testSchema(), []byte(doc))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	want := []string{
+		`line 2: error: mode: invalid value "readonly" (must be one of "read-only", "write")`,
+		`line 3: error: retries: expected an integer, got "many"`,
+		`line 4: error: enabled: expected a boolean, got "yes"`,
+		`line 7: error: gates[0].timout: unknown key (did you mean "timeout"?)`,
+		`line 8: error: gates[1].timeout: expected a duration such as 30s or 5m, got number 300`,
+		`line 9: warning: legacy: deprecated: use task instead`,
+		`line 10: error: tsk: unknown key (did you mean "task"?)`,
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %d: %v", len(want), len(issues), issues)
+	}
+	for i, issue := range issues {
+		if issue.String() != want[i] {
+			t.Errorf("issue %d = %q, want %q", i, issue.String(), want[i])
+		}
+	}
+	if !HasErrors(issues) {
+		t.Error("expected HasErrors to be true")
+	}
+}
+
+func TestValidate_WarningsOnly(t *testing.T) {
+	issues, err := Validate(testSchema(), []byte("legacy: old\n"))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(issues) != 1 || !issues[0].Warning {
+		t.Fatalf("expected one warning, got %v", issues)
+	}
+	if HasErrors(issues) {
+		t.Error("a deprecation should not count as an error")
+	}
+}
+
+func TestValidate_OneOf(t *testing.T) {
+	schema := Object(map[string]*Schema{
+		"delay": OneOf(Duration(), Integer().Deprecate("write a duration")),
+		"hosts": OneOf(Array(String()), String()),
+	})
+
+	tests := []struct {
+		doc  string
+		want []string
+	}{
+		{"delay: 1s\nhosts: [a, b]\n", nil},
+		{"delay: 1000\nhosts: a,b\n", []string{"line 1: warning: delay: deprecated: write a duration"}},
+		{"delay: soon\n", []string{`line 1: error: delay: invalid duration "soon" (use a unit, e.g. 30s or 5m)`}},
+		{"hosts: {a: b}\n", []string{"line 1: error: hosts: expected a list or a string, got a mapping"}},
+	}
+	for _, tt := range tests {
+		issues, err := Validate(schema, []byte(tt.doc))
+		if err != nil {
+			t.Fatalf("Validate(%q): %v", tt.doc, err)
+		}
+		var got []string
+		for _, issue := range issues {
+			got = append(got, issue.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("Validate(%q) = %v, want %v", tt.doc, got, tt.want)
+		}
+	}
+}
+
+func TestValidate_JSON(t *testing.T) {
+	issues, err := Validate(testSchema(), []byte(`{"task": "x", "retries": 2.0, "ratio": 1, "gates": "none"}`))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Path != "gates" {
+		t.Fatalf("expected one issue at gates, got %v", issues)
+	}
+}
+
+func TestValidate_AliasesAndMerges(t *testing.T) {
+	doc := `
+gates:
+  - &gate
+    name: test
+    timeout: 1m
+  - <<: *gate
+    name: lint
+    timout: 1m
+`
+	issues, err := Validate(testSchema(), []byte(doc))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Path != "gates[1].timout" {
+		t.Fatalf("expected one issue at gates[1].timout, got %v", issues)
+	}
+}
+
+func TestValidate_ParseError(t *testing.T) {
+	if _, err := Validate(testSchema(), []byte("task: [unclosed\n")); err == nil {
+		t.Error("expected a parse error")
+	}
+}
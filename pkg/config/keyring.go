@@ -0,0 +1,28 @@
+package config
+
+import "github.com/entrhq/forge/pkg/config/secrets"
+
+// Keyring accounts used by the config sections
+const (
+	keyringAccountLLMAPIKey = secrets.AccountLLMAPIKey
+)
+
+var (
+	// ErrKeyringUnavailable is returned when the system has no usable keyring.
+	ErrKeyringUnavailable = secrets.ErrUnavailable
+	// ErrSecretNotFound is returned when the keyring has no secret for an account.
+	ErrSecretNotFound = secrets.ErrNotFound
+)
+
+// Keyring stores secrets outside the config file.
+type Keyring = secrets.Store
+
+// SetKeyring replaces the keyring used to store secrets. A nil keyring
+// restores the operating system's.
+func SetKeyring(k Keyring) {
+	secrets.SetStore(k)
+}
+
+func getKeyring() Keyring {
+	return secrets.Current()
+}
@@ -1,21 +1,178 @@
 package config
 
 import (
+	"fmt"
 	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config/jsonschema"
 )
 
 const (
 	// SectionIDLLM is the identifier for the LLM settings section
 	SectionIDLLM = "llm"
+
+	// SamplingRoleAgent selects sampling parameters for the main agent loop
+	SamplingRoleAgent = "agent"
+	// SamplingRoleSummarizer selects sampling parameters for context summarization
+	SamplingRoleSummarizer = "summarizer"
+	// SamplingRoleCommit selects sampling parameters for commit message and PR generation
+	SamplingRoleCommit = "commit"
+
+	// ToolCallingXML has the model write tool calls as XML in its response text
+	ToolCallingXML = "xml"
+	// ToolCallingNative uses the provider's native function-calling API
+	ToolCallingNative = "native"
+
+	// APIKeyStorageKeyring keeps the API key in the system keyring instead of
+	// the config file
+	APIKeyStorageKeyring = "keyring"
 )
 
+// SamplingParams holds optional generation parameters for one role.
+// Nil fields are omitted from requests so the provider default applies.
+type SamplingParams struct {
+	Temperature *float64 `yaml:"temperature,omitempty" json:"temperature,omitempty"`
+	TopP        *float64 `yaml:"top_p,omitempty" json:"top_p,omitempty"`
+	Seed        *int64   `yaml:"seed,omitempty" json:"seed,omitempty"`
+}
+
+// IsZero reports whether no parameter is set.
+func (p SamplingParams) IsZero() bool {
+	return p.Temperature == nil && p.TopP == nil && p.Seed == nil
+}
+
+// Merge returns a copy of p with every field that is set in override replaced.
+func (p SamplingParams) Merge(override SamplingParams) SamplingParams {
+	if override.Temperature != nil {
+		p.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		p.TopP = override.TopP
+	}
+	if override.Seed != nil {
+		p.Seed = override.Seed
+	}
+	return p
+}
+
+// Validate checks that the parameters fall within the ranges accepted by
+// OpenAI-compatible APIs.
+func (p SamplingParams) Validate() error {
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %g", *p.Temperature)
+	}
+	if p.TopP != nil && (*p.TopP <= 0 || *p.TopP > 1) {
+		return fmt.Errorf("top_p must be greater than 0 and at most 1, got %g", *p.TopP)
+	}
+	return nil
+}
+
+// ModelPricing is the price of a model in USD per million tokens.
+type ModelPricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// Cost returns the estimated cost in USD of a call with the given token counts.
+func (p ModelPricing) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.InputPerMillion + float64(completionTokens)*p.OutputPerMillion) / 1_000_000
+}
+
+// RateLimit bounds the requests Forge sends to the provider, across the
+// agent, the summarizer and the commit and PR generators. Zero fields are
+// unlimited.
+type RateLimit struct {
+	RequestsPerMinute     int `yaml:"requests_per_minute,omitempty" json:"requests_per_minute,omitempty"`
+	TokensPerMinute       int `yaml:"tokens_per_minute,omitempty" json:"tokens_per_minute,omitempty"` // Prompt and completion tokens
+	MaxConcurrentRequests int `yaml:"max_concurrent_requests,omitempty" json:"max_concurrent_requests,omitempty"`
+}
+
+// IsZero reports whether no limit is set.
+func (l RateLimit) IsZero() bool {
+	return l.RequestsPerMinute <= 0 && l.TokensPerMinute <= 0 && l.MaxConcurrentRequests <= 0
+}
+
+// Validate checks that no limit is negative.
+func (l RateLimit) Validate() error {
+	if l.RequestsPerMinute < 0 || l.TokensPerMinute < 0 || l.MaxConcurrentRequests < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	return nil
+}
+
+// Merge returns a copy of l with every limit that is set in override replaced.
+func (l RateLimit) Merge(override RateLimit) RateLimit {
+	if override.RequestsPerMinute > 0 {
+		l.RequestsPerMinute = override.RequestsPerMinute
+	}
+	if override.TokensPerMinute > 0 {
+		l.TokensPerMinute = override.TokensPerMinute
+	}
+	if override.MaxConcurrentRequests > 0 {
+		l.MaxConcurrentRequests = override.MaxConcurrentRequests
+	}
+	return l
+}
+
+// ResponseCache configures the on-disk cache of responses to deterministic
+// calls: summarization and commit message and PR generation. The cache is off
+// unless Enabled; zero fields use the defaults.
+type ResponseCache struct {
+	Enabled   bool          `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	Dir       string        `yaml:"dir,omitempty" json:"dir,omitempty"`                 // optional; default ~/.forge/cache/llm
+	TTL       time.Duration `yaml:"ttl,omitempty" json:"ttl,omitempty"`                 // optional; how long a response is reused
+	MaxSizeMB int           `yaml:"max_size_mb,omitempty" json:"max_size_mb,omitempty"` // optional; size the cache is trimmed to
+}
+
+// Validate checks that no limit is negative.
+func (c ResponseCache) Validate() error {
+	if c.TTL < 0 || c.MaxSizeMB < 0 {
+		return fmt.Errorf("ttl and max_size_mb must not be negative")
+	}
+	return nil
+}
+
+// Merge returns a copy of c with every field that is set in override
+// replaced.
+func (c ResponseCache) Merge(override ResponseCache) ResponseCache {
+	if override.Enabled {
+		c.Enabled = true
+	}
+	if override.Dir != "" {
+		c.Dir = override.Dir
+	}
+	if override.TTL > 0 {
+		c.TTL = override.TTL
+	}
+	if override.MaxSizeMB > 0 {
+		c.MaxSizeMB = override.MaxSizeMB
+	}
+	return c
+}
+
+// ModelOption is a model offered by the /model switcher.
+type ModelOption struct {
+	Name          string
+	ContextTokens int  // optional context window size; 0 uses the built-in table
+	Images        bool // optional; the model accepts image input, for models the built-in table does not know
+}
+
 // LLMSection manages LLM provider configuration settings.
 type LLMSection struct {
 	Model                string
 	BaseURL              string
 	APIKey               string
-	SummarizationModel   string // optional; if empty, summarization uses Model
-	BrowserAnalysisModel string // optional; if empty, browser page analysis uses Model
+	SummarizationModel   string                    // optional; if empty, summarization uses Model
+	BrowserAnalysisModel string                    // optional; if empty, browser page analysis uses Model
+	FallbackModel        string                    // optional; model requests move to while Model is rate limited
+	Sampling             map[string]SamplingParams // optional per-role sampling, keyed by SamplingRole*
+	Pricing              map[string]ModelPricing   // optional per-model prices, overriding the built-in table
+	Models               []ModelOption             // optional models to offer in the /model switcher
+	RateLimit            RateLimit                 // optional client-side limits on requests to the provider
+	ResponseCache        ResponseCache             // optional cache of deterministic responses
+	ToolCalling          string                    // optional; ToolCallingXML (default) or ToolCallingNative
+	APIKeyStorage        string                    // "" (config file) or APIKeyStorageKeyring
 	mu                   sync.RWMutex
 }
 
@@ -27,6 +184,9 @@ func NewLLMSection() *LLMSection {
 		APIKey:               "",
 		SummarizationModel:   "",
 		BrowserAnalysisModel: "",
+		FallbackModel:        "",
+		Sampling:             make(map[string]SamplingParams),
+		Pricing:              make(map[string]ModelPricing),
 	}
 }
 
@@ -42,20 +202,195 @@ func (s *LLMSection) Title() string {
 
 // Description returns the section description.
 func (s *LLMSection) Description() string {
-	return "Configure LLM provider settings. summarization_model and browser_analysis_model are optional — if set, those operations use the specified model instead of the main model."
+	return "Configure LLM provider settings. summarization_model and browser_analysis_model are optional — if set, those operations use the specified model instead of the main model. fallback_model is optional — if set, requests the main model rejects with a rate limit or overload error are retried on it. tool_calling selects how the model calls tools: xml (default, works everywhere) or native (the provider's function-calling API, for models with weak XML discipline). sampling optionally pins temperature, top_p and seed per role (agent, summarizer, commit). pricing optionally sets input_per_mtok and output_per_mtok (USD per million tokens) per model for cost estimates. models optionally lists extra models (name, context_tokens, and images when the model accepts image input) to switch between with /model. rate_limit optionally caps requests_per_minute, tokens_per_minute and max_concurrent_requests across everything Forge sends to the provider, so parallel work waits its turn instead of being throttled. response_cache optionally caches the responses to summarization and commit and PR generation on disk (enabled, dir, ttl, max_size_mb), so repeated runs over the same input skip the model. api_key_storage is keyring when the API key is kept in the system keyring instead of this file."
+}
+
+// Schema describes the section's data.
+func (s *LLMSection) Schema() *jsonschema.Schema {
+	sampling := jsonschema.Object(map[string]*jsonschema.Schema{
+		"temperature": jsonschema.Number(),
+		"top_p":       jsonschema.Number(),
+		"seed":        jsonschema.Integer(),
+	})
+	return jsonschema.Object(map[string]*jsonschema.Schema{
+		"model":                  jsonschema.String(),
+		"base_url":               jsonschema.String(),
+		"api_key":                jsonschema.String(),
+		"api_key_storage":        jsonschema.Enum("", APIKeyStorageKeyring),
+		"summarization_model":    jsonschema.String(),
+		"browser_analysis_model": jsonschema.String(),
+		"fallback_model":         jsonschema.String(),
+		"tool_calling":           jsonschema.Enum(ToolCallingXML, ToolCallingNative),
+		"sampling": jsonschema.Object(map[string]*jsonschema.Schema{
+			SamplingRoleAgent:      sampling,
+			SamplingRoleSummarizer: sampling,
+			SamplingRoleCommit:     sampling,
+		}),
+		"pricing": jsonschema.Map(jsonschema.Object(map[string]*jsonschema.Schema{
+			"input_per_mtok":  jsonschema.Number(),
+			"output_per_mtok": jsonschema.Number(),
+		})),
+		"rate_limit": jsonschema.Object(map[string]*jsonschema.Schema{
+			"requests_per_minute":     jsonschema.Integer(),
+			"tokens_per_minute":       jsonschema.Integer(),
+			"max_concurrent_requests": jsonschema.Integer(),
+		}),
+		"response_cache": jsonschema.Object(map[string]*jsonschema.Schema{
+			"enabled":     jsonschema.Boolean(),
+			"dir":         jsonschema.String(),
+			"ttl":         jsonschema.Duration(),
+			"max_size_mb": jsonschema.Integer(),
+		}),
+		"models": jsonschema.Array(jsonschema.OneOf(
+			jsonschema.String(),
+			jsonschema.Object(map[string]*jsonschema.Schema{
+				"name":           jsonschema.String(),
+				"context_tokens": jsonschema.Integer(),
+				"images":         jsonschema.Boolean(),
+			}),
+		)),
+	})
 }
 
 // Data returns the current configuration data.
 func (s *LLMSection) Data() map[string]any {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return map[string]any{
+	data := map[string]any{
 		"model":                  s.Model,
 		"base_url":               s.BaseURL,
 		"api_key":                s.APIKey,
 		"summarization_model":    s.SummarizationModel,
 		"browser_analysis_model": s.BrowserAnalysisModel,
+		"fallback_model":         s.FallbackModel,
+	}
+
+	// A key kept in the keyring never reaches the config file
+	if s.APIKeyStorage == APIKeyStorageKeyring {
+		data["api_key"] = ""
+		data["api_key_storage"] = s.APIKeyStorage
+	}
+
+	if s.ToolCalling != "" {
+		data["tool_calling"] = s.ToolCalling
+	}
+
+	if len(s.Sampling) > 0 {
+		sampling := make(map[string]any, len(s.Sampling))
+		for role, params := range s.Sampling {
+			sampling[role] = samplingToMap(params)
+		}
+		data["sampling"] = sampling
+	}
+
+	if len(s.Pricing) > 0 {
+		pricing := make(map[string]any, len(s.Pricing))
+		for model, price := range s.Pricing {
+			pricing[model] = map[string]any{
+				"input_per_mtok":  price.InputPerMillion,
+				"output_per_mtok": price.OutputPerMillion,
+			}
+		}
+		data["pricing"] = pricing
+	}
+
+	if len(s.Models) > 0 {
+		models := make([]any, 0, len(s.Models))
+		for _, option := range s.Models {
+			entry := map[string]any{"name": option.Name}
+			if option.ContextTokens > 0 {
+				entry["context_tokens"] = option.ContextTokens
+			}
+			if option.Images {
+				entry["images"] = true
+			}
+			models = append(models, entry)
+		}
+		data["models"] = models
+	}
+
+	if limit := rateLimitToMap(s.RateLimit); len(limit) > 0 {
+		data["rate_limit"] = limit
+	}
+	if cache := responseCacheToMap(s.ResponseCache); len(cache) > 0 {
+		data["response_cache"] = cache
+	}
+
+	return data
+}
+
+// rateLimitToMap converts rate limits to their stored representation,
+// omitting the ones that are not set.
+func rateLimitToMap(limit RateLimit) map[string]any {
+	m := make(map[string]any)
+	if limit.RequestsPerMinute > 0 {
+		m["requests_per_minute"] = limit.RequestsPerMinute
+	}
+	if limit.TokensPerMinute > 0 {
+		m["tokens_per_minute"] = limit.TokensPerMinute
 	}
+	if limit.MaxConcurrentRequests > 0 {
+		m["max_concurrent_requests"] = limit.MaxConcurrentRequests
+	}
+	return m
+}
+
+// responseCacheToMap converts response cache settings to their stored
+// representation, omitting the ones that are not set.
+func responseCacheToMap(cache ResponseCache) map[string]any {
+	m := make(map[string]any)
+	if cache.Enabled {
+		m["enabled"] = true
+	}
+	if cache.Dir != "" {
+		m["dir"] = cache.Dir
+	}
+	if cache.TTL > 0 {
+		m["ttl"] = cache.TTL.String()
+	}
+	if cache.MaxSizeMB > 0 {
+		m["max_size_mb"] = cache.MaxSizeMB
+	}
+	return m
+}
+
+// samplingToMap converts sampling parameters to their stored representation,
+// omitting fields that are not set.
+func samplingToMap(params SamplingParams) map[string]any {
+	m := make(map[string]any)
+	if params.Temperature != nil {
+		m["temperature"] = *params.Temperature
+	}
+	if params.TopP != nil {
+		m["top_p"] = *params.TopP
+	}
+	if params.Seed != nil {
+		m["seed"] = *params.Seed
+	}
+	return m
+}
+
+// samplingFromMap parses stored sampling parameters. JSON decoding yields
+// float64 for every number, so seeds are accepted as any numeric type.
+func samplingFromMap(m map[string]any) SamplingParams {
+	var params SamplingParams
+	if v, ok := m["temperature"].(float64); ok {
+		params.Temperature = &v
+	}
+	if v, ok := m["top_p"].(float64); ok {
+		params.TopP = &v
+	}
+	switch v := m["seed"].(type) {
+	case float64:
+		seed := int64(v)
+		params.Seed = &seed
+	case int:
+		seed := int64(v)
+		params.Seed = &seed
+	case int64:
+		params.Seed = &v
+	}
+	return params
 }
 
 // SetData updates the configuration from the provided data.
@@ -79,6 +414,10 @@ func (s *LLMSection) SetData(data map[string]any) error {
 		s.APIKey = apiKey
 	}
 
+	if storage, ok := data["api_key_storage"].(string); ok {
+		s.APIKeyStorage = storage
+	}
+
 	if summarizationModel, ok := data["summarization_model"].(string); ok {
 		s.SummarizationModel = summarizationModel
 	}
@@ -87,6 +426,70 @@ func (s *LLMSection) SetData(data map[string]any) error {
 		s.BrowserAnalysisModel = browserAnalysisModel
 	}
 
+	if fallbackModel, ok := data["fallback_model"].(string); ok {
+		s.FallbackModel = fallbackModel
+	}
+
+	if toolCalling, ok := data["tool_calling"].(string); ok {
+		s.ToolCalling = toolCalling
+	}
+
+	if sampling, ok := data["sampling"].(map[string]any); ok {
+		s.Sampling = make(map[string]SamplingParams, len(sampling))
+		for role, raw := range sampling {
+			if m, ok := raw.(map[string]any); ok {
+				s.Sampling[role] = samplingFromMap(m)
+			}
+		}
+	}
+
+	if pricing, ok := data["pricing"].(map[string]any); ok {
+		s.Pricing = make(map[string]ModelPricing, len(pricing))
+		for model, raw := range pricing {
+			if m, ok := raw.(map[string]any); ok {
+				input, _ := m["input_per_mtok"].(float64)
+				output, _ := m["output_per_mtok"].(float64)
+				s.Pricing[model] = ModelPricing{InputPerMillion: input, OutputPerMillion: output}
+			}
+		}
+	}
+
+	if limit, ok := data["rate_limit"].(map[string]any); ok {
+		s.RateLimit = RateLimit{}
+		s.RateLimit.RequestsPerMinute, _ = intFromAny(limit["requests_per_minute"])
+		s.RateLimit.TokensPerMinute, _ = intFromAny(limit["tokens_per_minute"])
+		s.RateLimit.MaxConcurrentRequests, _ = intFromAny(limit["max_concurrent_requests"])
+	}
+
+	if cache, ok := data["response_cache"].(map[string]any); ok {
+		s.ResponseCache = ResponseCache{}
+		s.ResponseCache.Enabled, _ = cache["enabled"].(bool)
+		s.ResponseCache.Dir, _ = cache["dir"].(string)
+		if ttl, ok := cache["ttl"].(string); ok && ttl != "" {
+			parsed, err := time.ParseDuration(ttl)
+			if err != nil {
+				return fmt.Errorf("invalid response_cache.ttl: %w", err)
+			}
+			s.ResponseCache.TTL = parsed
+		}
+		s.ResponseCache.MaxSizeMB, _ = intFromAny(cache["max_size_mb"])
+	}
+
+	if models, ok := data["models"].([]any); ok {
+		s.Models = make([]ModelOption, 0, len(models))
+		for _, raw := range models {
+			switch v := raw.(type) {
+			case string:
+				s.Models = append(s.Models, ModelOption{Name: v})
+			case map[string]any:
+				name, _ := v["name"].(string)
+				contextTokens, _ := intFromAny(v["context_tokens"])
+				images, _ := v["images"].(bool)
+				s.Models = append(s.Models, ModelOption{Name: name, ContextTokens: contextTokens, Images: images})
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -95,8 +498,46 @@ func (s *LLMSection) Validate() error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	// LLM configuration is optional - validation always passes
-	// Actual validation happens at runtime when LLM is used
+	// LLM configuration is optional - connection settings are validated at
+	// runtime when the LLM is used. Sampling values are checked here so a bad
+	// config file fails fast rather than on the first request.
+	switch s.ToolCalling {
+	case "", ToolCallingXML, ToolCallingNative:
+	default:
+		return fmt.Errorf("unknown tool_calling mode %q (must be %q or %q)", s.ToolCalling, ToolCallingXML, ToolCallingNative)
+	}
+	if s.APIKeyStorage != "" && s.APIKeyStorage != APIKeyStorageKeyring {
+		return fmt.Errorf("unknown api_key_storage %q (must be %q or empty)", s.APIKeyStorage, APIKeyStorageKeyring)
+	}
+	for role, params := range s.Sampling {
+		switch role {
+		case SamplingRoleAgent, SamplingRoleSummarizer, SamplingRoleCommit:
+		default:
+			return fmt.Errorf("unknown sampling role %q (must be %q, %q or %q)", role, SamplingRoleAgent, SamplingRoleSummarizer, SamplingRoleCommit)
+		}
+		if err := params.Validate(); err != nil {
+			return fmt.Errorf("sampling.%s: %w", role, err)
+		}
+	}
+	for model, price := range s.Pricing {
+		if price.InputPerMillion < 0 || price.OutputPerMillion < 0 {
+			return fmt.Errorf("pricing.%s must not be negative", model)
+		}
+	}
+	if err := s.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("rate_limit: %w", err)
+	}
+	if err := s.ResponseCache.Validate(); err != nil {
+		return fmt.Errorf("response_cache: %w", err)
+	}
+	for i, option := range s.Models {
+		if option.Name == "" {
+			return fmt.Errorf("models[%d] must have a name", i)
+		}
+		if option.ContextTokens < 0 {
+			return fmt.Errorf("models[%d].context_tokens must not be negative", i)
+		}
+	}
 	return nil
 }
 
@@ -109,6 +550,14 @@ func (s *LLMSection) Reset() {
 	s.APIKey = ""
 	s.SummarizationModel = ""
 	s.BrowserAnalysisModel = ""
+	s.FallbackModel = ""
+	s.Sampling = make(map[string]SamplingParams)
+	s.Pricing = make(map[string]ModelPricing)
+	s.Models = nil
+	s.RateLimit = RateLimit{}
+	s.ResponseCache = ResponseCache{}
+	s.ToolCalling = ""
+	s.APIKeyStorage = ""
 }
 
 // GetModel returns the configured model name.
@@ -139,13 +588,65 @@ func (s *LLMSection) SetBaseURL(baseURL string) {
 	s.BaseURL = baseURL
 }
 
-// GetAPIKey returns the configured API key.
+// GetAPIKey returns the configured API key. A key kept in the keyring is
+// read from it on first use.
 func (s *LLMSection) GetAPIKey() string {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
+	apiKey, storage := s.APIKey, s.APIKeyStorage
+	s.mu.RUnlock()
+	if apiKey != "" || storage != APIKeyStorageKeyring {
+		return apiKey
+	}
+
+	apiKey, err := getKeyring().Get(keyringAccountLLMAPIKey)
+	if err != nil {
+		return ""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.APIKey == "" {
+		s.APIKey = apiKey
+	}
 	return s.APIKey
 }
 
+// StoreAPIKey sets the API key and keeps it in the system keyring so it is
+// not written to the config file. When the keyring cannot be used the key is
+// kept in the config file instead and the keyring error is returned; the key
+// is set either way. An empty key removes any stored key.
+func (s *LLMSection) StoreAPIKey(apiKey string) error {
+	keyring := getKeyring()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if apiKey == "" {
+		s.APIKey = ""
+		if s.APIKeyStorage == APIKeyStorageKeyring {
+			s.APIKeyStorage = ""
+			return keyring.Delete(keyringAccountLLMAPIKey)
+		}
+		return nil
+	}
+
+	s.APIKey = apiKey
+	if err := keyring.Set(keyringAccountLLMAPIKey, apiKey); err != nil {
+		s.APIKeyStorage = ""
+		return err
+	}
+	s.APIKeyStorage = APIKeyStorageKeyring
+	return nil
+}
+
+// GetAPIKeyStorage returns where the API key is kept: "" for the config file
+// or APIKeyStorageKeyring.
+func (s *LLMSection) GetAPIKeyStorage() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.APIKeyStorage
+}
+
 // SetAPIKey sets the API key.
 func (s *LLMSection) SetAPIKey(apiKey string) {
 	s.mu.Lock()
@@ -184,3 +685,114 @@ func (s *LLMSection) SetBrowserAnalysisModel(model string) {
 	defer s.mu.Unlock()
 	s.BrowserAnalysisModel = model
 }
+
+// GetFallbackModel returns the model to use while the main model is rate
+// limited. An empty string means rate limit errors are returned as usual.
+func (s *LLMSection) GetFallbackModel() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.FallbackModel
+}
+
+// SetFallbackModel sets the fallback model name.
+// Pass an empty string to turn the fallback off.
+func (s *LLMSection) SetFallbackModel(model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.FallbackModel = model
+}
+
+// GetRateLimit returns the client-side rate limits. The zero value means
+// requests are not limited.
+func (s *LLMSection) GetRateLimit() RateLimit {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.RateLimit
+}
+
+// SetRateLimit sets the client-side rate limits.
+func (s *LLMSection) SetRateLimit(limit RateLimit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.RateLimit = limit
+}
+
+// GetResponseCache returns the response cache settings. The zero value
+// means responses are not cached.
+func (s *LLMSection) GetResponseCache() ResponseCache {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ResponseCache
+}
+
+// SetResponseCache sets the response cache settings.
+func (s *LLMSection) SetResponseCache(cache ResponseCache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ResponseCache = cache
+}
+
+// GetSampling returns the sampling parameters configured for role.
+// The zero value means no parameters are pinned.
+func (s *LLMSection) GetSampling(role string) SamplingParams {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Sampling[role]
+}
+
+// SetSampling sets the sampling parameters for role.
+// Passing the zero value removes any pinned parameters.
+func (s *LLMSection) SetSampling(role string, params SamplingParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Sampling == nil {
+		s.Sampling = make(map[string]SamplingParams)
+	}
+	if params.IsZero() {
+		delete(s.Sampling, role)
+		return
+	}
+	s.Sampling[role] = params
+}
+
+// GetPricing returns the configured price for model, if any.
+func (s *LLMSection) GetPricing(model string) (ModelPricing, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	price, ok := s.Pricing[model]
+	return price, ok
+}
+
+// GetModels returns the extra models configured for the /model switcher.
+func (s *LLMSection) GetModels() []ModelOption {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ModelOption(nil), s.Models...)
+}
+
+// GetModelOption returns the configured /model switcher entry for model, if any.
+func (s *LLMSection) GetModelOption(model string) (ModelOption, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, option := range s.Models {
+		if option.Name == model {
+			return option, true
+		}
+	}
+	return ModelOption{}, false
+}
+
+// GetToolCalling returns the configured tool calling mode. An empty string
+// means the default, ToolCallingXML.
+func (s *LLMSection) GetToolCalling() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ToolCalling
+}
+
+// SetToolCalling sets the tool calling mode.
+func (s *LLMSection) SetToolCalling(mode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ToolCalling = mode
+}
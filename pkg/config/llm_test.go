@@ -3,6 +3,7 @@ package config
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -189,6 +190,21 @@ func TestLLMSection_Validate(t *testing.T) {
 	}
 }
 
+func TestLLMSection_ValidateToolCalling(t *testing.T) {
+	section := NewLLMSection()
+	for _, mode := range []string{"", ToolCallingXML, ToolCallingNative} {
+		section.ToolCalling = mode
+		assert.NoError(t, section.Validate(), "mode %q", mode)
+	}
+
+	section.ToolCalling = "json"
+	assert.Error(t, section.Validate())
+
+	require.NoError(t, section.SetData(map[string]any{"tool_calling": ToolCallingNative}))
+	assert.Equal(t, ToolCallingNative, section.GetToolCalling())
+	assert.Equal(t, ToolCallingNative, section.Data()["tool_calling"])
+}
+
 func TestLLMSection_Reset(t *testing.T) {
 	section := NewLLMSection()
 	section.Model = "custom-model"
@@ -218,6 +234,123 @@ func TestLLMSection_GettersSetters(t *testing.T) {
 	assert.Equal(t, "sk-test123", section.GetAPIKey())
 }
 
+func TestLLMSection_FallbackModel(t *testing.T) {
+	section := NewLLMSection()
+	require.NoError(t, section.SetData(map[string]any{"fallback_model": "gpt-4o-mini"}))
+	assert.Equal(t, "gpt-4o-mini", section.GetFallbackModel())
+	assert.Equal(t, "gpt-4o-mini", section.Data()["fallback_model"])
+
+	section.Reset()
+	assert.Equal(t, "", section.GetFallbackModel())
+}
+
+func TestLLMSection_RateLimit(t *testing.T) {
+	section := NewLLMSection()
+	assert.NotContains(t, section.Data(), "rate_limit")
+
+	// JSON decoding yields float64 for numbers
+	require.NoError(t, section.SetData(map[string]any{
+		"rate_limit": map[string]any{"requests_per_minute": float64(50), "max_concurrent_requests": 2},
+	}))
+	assert.Equal(t, RateLimit{RequestsPerMinute: 50, MaxConcurrentRequests: 2}, section.GetRateLimit())
+	assert.Equal(t, map[string]any{"requests_per_minute": 50, "max_concurrent_requests": 2}, section.Data()["rate_limit"])
+	require.NoError(t, section.Validate())
+
+	section.SetRateLimit(RateLimit{TokensPerMinute: -1})
+	assert.Error(t, section.Validate())
+
+	section.Reset()
+	assert.Equal(t, RateLimit{}, section.GetRateLimit())
+}
+
+func TestLLMSection_ResponseCache(t *testing.T) {
+	section := NewLLMSection()
+	assert.NotContains(t, section.Data(), "response_cache")
+
+	require.NoError(t, section.SetData(map[string]any{
+		"response_cache": map[string]any{"enabled": true, "ttl": "24h", "max_size_mb": float64(64)},
+	}))
+	assert.Equal(t, ResponseCache{Enabled: true, TTL: 24 * time.Hour, MaxSizeMB: 64}, section.GetResponseCache())
+	assert.Equal(t, map[string]any{"enabled": true, "ttl": "24h0m0s", "max_size_mb": 64}, section.Data()["response_cache"])
+	require.NoError(t, section.Validate())
+
+	assert.Error(t, section.SetData(map[string]any{"response_cache": map[string]any{"ttl": "a week"}}))
+
+	section.SetResponseCache(ResponseCache{MaxSizeMB: -1})
+	assert.Error(t, section.Validate())
+
+	section.Reset()
+	assert.Equal(t, ResponseCache{}, section.GetResponseCache())
+}
+
+// memoryKeyring is a Keyring backed by a map
+type memoryKeyring struct {
+	secrets map[string]string
+	err     error
+}
+
+func (k *memoryKeyring) Get(account string) (string, error) {
+	if k.err != nil {
+		return "", k.err
+	}
+	secret, ok := k.secrets[account]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+func (k *memoryKeyring) Set(account, secret string) error {
+	if k.err != nil {
+		return k.err
+	}
+	k.secrets[account] = secret
+	return nil
+}
+
+func (k *memoryKeyring) Delete(account string) error {
+	delete(k.secrets, account)
+	return nil
+}
+
+func TestLLMSection_StoreAPIKey(t *testing.T) {
+	keyring := &memoryKeyring{secrets: make(map[string]string)}
+	SetKeyring(keyring)
+	t.Cleanup(func() { SetKeyring(nil) })
+
+	section := NewLLMSection()
+	require.NoError(t, section.StoreAPIKey("sk-secret"))
+	assert.Equal(t, APIKeyStorageKeyring, section.GetAPIKeyStorage())
+	assert.Equal(t, "sk-secret", keyring.secrets[keyringAccountLLMAPIKey])
+
+	// The key stays out of the saved data
+	data := section.Data()
+	assert.Equal(t, "", data["api_key"])
+	assert.Equal(t, APIKeyStorageKeyring, data["api_key_storage"])
+
+	// A section loaded from that data reads the key back from the keyring
+	loaded := NewLLMSection()
+	require.NoError(t, loaded.SetData(data))
+	assert.Equal(t, "sk-secret", loaded.GetAPIKey())
+
+	// Clearing the key removes it from the keyring
+	require.NoError(t, loaded.StoreAPIKey(""))
+	assert.Empty(t, keyring.secrets)
+	assert.Equal(t, "", loaded.GetAPIKeyStorage())
+}
+
+func TestLLMSection_StoreAPIKeyFallsBackToConfigFile(t *testing.T) {
+	SetKeyring(&memoryKeyring{err: ErrKeyringUnavailable})
+	t.Cleanup(func() { SetKeyring(nil) })
+
+	section := NewLLMSection()
+	err := section.StoreAPIKey("sk-secret")
+	assert.ErrorIs(t, err, ErrKeyringUnavailable)
+	assert.Equal(t, "sk-secret", section.GetAPIKey())
+	assert.Equal(t, "sk-secret", section.Data()["api_key"])
+	assert.NotContains(t, section.Data(), "api_key_storage")
+}
+
 func TestLLMSection_ThreadSafety(t *testing.T) {
 	section := NewLLMSection()
 
@@ -281,3 +414,125 @@ func TestLLMSection_IntegrationWithManager(t *testing.T) {
 		assert.Equal(t, "sk-test", newSection.GetAPIKey())
 	})
 }
+
+func TestLLMSection_Sampling(t *testing.T) {
+	temp := 0.2
+	seed := int64(42)
+
+	t.Run("set and get per role", func(t *testing.T) {
+		section := NewLLMSection()
+		section.SetSampling(SamplingRoleAgent, SamplingParams{Temperature: &temp, Seed: &seed})
+
+		agent := section.GetSampling(SamplingRoleAgent)
+		require.NotNil(t, agent.Temperature)
+		assert.InDelta(t, 0.2, *agent.Temperature, 1e-9)
+		require.NotNil(t, agent.Seed)
+		assert.Equal(t, int64(42), *agent.Seed)
+		assert.Nil(t, agent.TopP)
+
+		assert.Equal(t, SamplingParams{}, section.GetSampling(SamplingRoleSummarizer))
+
+		section.SetSampling(SamplingRoleAgent, SamplingParams{})
+		assert.Empty(t, section.Sampling)
+	})
+
+	t.Run("persists through file store", func(t *testing.T) {
+		tmpFile := filepath.Join(t.TempDir(), "config.json")
+		store, err := NewFileStore(tmpFile)
+		require.NoError(t, err)
+		manager := NewManager(store)
+		section := NewLLMSection()
+		require.NoError(t, manager.RegisterSection(section))
+
+		section.SetSampling(SamplingRoleCommit, SamplingParams{Temperature: &temp, Seed: &seed})
+		require.NoError(t, manager.SaveAll())
+
+		newStore, err := NewFileStore(tmpFile)
+		require.NoError(t, err)
+		newManager := NewManager(newStore)
+		newSection := NewLLMSection()
+		require.NoError(t, newManager.RegisterSection(newSection))
+		require.NoError(t, newManager.LoadAll())
+
+		commit := newSection.GetSampling(SamplingRoleCommit)
+		require.NotNil(t, commit.Temperature)
+		assert.InDelta(t, 0.2, *commit.Temperature, 1e-9)
+		require.NotNil(t, commit.Seed)
+		assert.Equal(t, int64(42), *commit.Seed)
+	})
+
+	t.Run("validate rejects bad values", func(t *testing.T) {
+		section := NewLLMSection()
+		bad := 3.0
+		section.SetSampling(SamplingRoleAgent, SamplingParams{Temperature: &bad})
+		assert.Error(t, section.Validate())
+
+		section.Reset()
+		section.SetSampling("reviewer", SamplingParams{Temperature: &temp})
+		assert.Error(t, section.Validate())
+	})
+}
+
+func TestLLMSection_Pricing(t *testing.T) {
+	section := NewLLMSection()
+	require.NoError(t, section.SetData(map[string]any{
+		"pricing": map[string]any{
+			"my-model": map[string]any{"input_per_mtok": 1.5, "output_per_mtok": 6.0},
+		},
+	}))
+
+	price, ok := section.GetPricing("my-model")
+	require.True(t, ok)
+	assert.InDelta(t, 1.5, price.InputPerMillion, 1e-9)
+	assert.InDelta(t, 6.0, price.OutputPerMillion, 1e-9)
+
+	_, ok = section.GetPricing("other-model")
+	assert.False(t, ok)
+
+	data := section.Data()
+	assert.Contains(t, data, "pricing")
+
+	section.Pricing["my-model"] = ModelPricing{InputPerMillion: -1}
+	assert.Error(t, section.Validate())
+}
+
+func TestModelPricing_Cost(t *testing.T) {
+	price := ModelPricing{InputPerMillion: 3, OutputPerMillion: 15}
+	assert.InDelta(t, 0.06, price.Cost(10_000, 2_000), 1e-9)
+}
+
+func TestRateLimit_Merge(t *testing.T) {
+	base := RateLimit{RequestsPerMinute: 60, TokensPerMinute: 100000}
+	got := base.Merge(RateLimit{TokensPerMinute: 20000, MaxConcurrentRequests: 2})
+	assert.Equal(t, RateLimit{RequestsPerMinute: 60, TokensPerMinute: 20000, MaxConcurrentRequests: 2}, got)
+}
+
+func TestLLMSection_Models(t *testing.T) {
+	section := NewLLMSection()
+	require.NoError(t, section.SetData(map[string]any{
+		"models": []any{
+			"gpt-4.1-mini",
+			map[string]any{"name": "local-coder", "context_tokens": 32768.0, "images": true},
+		},
+	}))
+
+	models := section.GetModels()
+	require.Len(t, models, 2)
+	assert.Equal(t, ModelOption{Name: "gpt-4.1-mini"}, models[0])
+	assert.Equal(t, ModelOption{Name: "local-coder", ContextTokens: 32768, Images: true}, models[1])
+
+	option, ok := section.GetModelOption("local-coder")
+	require.True(t, ok)
+	assert.Equal(t, 32768, option.ContextTokens)
+
+	// Data round-trips through SetData
+	restored := NewLLMSection()
+	require.NoError(t, restored.SetData(section.Data()))
+	assert.Equal(t, models, restored.GetModels())
+
+	section.Models = append(section.Models, ModelOption{})
+	assert.Error(t, section.Validate())
+
+	section.Reset()
+	assert.Empty(t, section.GetModels())
+}
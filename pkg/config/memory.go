@@ -2,6 +2,8 @@ package config
 
 import (
 	"sync"
+
+	"github.com/entrhq/forge/pkg/config/jsonschema"
 )
 
 const (
@@ -60,6 +62,22 @@ func (s *MemorySection) Description() string {
 	return "Configure long-term memory capabilities including embedding and retrieval models."
 }
 
+// Schema describes the section's data.
+func (s *MemorySection) Schema() *jsonschema.Schema {
+	return jsonschema.Object(map[string]*jsonschema.Schema{
+		"enabled":                    jsonschema.Boolean(),
+		"classifier_model":           jsonschema.String(),
+		"hypothesis_model":           jsonschema.String(),
+		"embedding_model":            jsonschema.String(),
+		"embedding_base_url":         jsonschema.String(),
+		"embedding_api_key":          jsonschema.String(),
+		"retrieval_top_k":            jsonschema.Integer(),
+		"retrieval_hop_depth":        jsonschema.Integer(),
+		"retrieval_hypothesis_count": jsonschema.Integer(),
+		"injection_token_budget":     jsonschema.Integer(),
+	})
+}
+
 // Data returns the current configuration data.
 func (s *MemorySection) Data() map[string]any {
 	s.mu.RLock()
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"sync"
+
+	"github.com/entrhq/forge/pkg/config/jsonschema"
 )
 
 const (
@@ -40,6 +42,14 @@ func (s *MultimodalSection) Description() string {
 	return "Configure multimodal document analysis. Set model to override which LLM is used for image/PDF analysis. Set pdf_page_limit to control truncation (0 = all pages)."
 }
 
+// Schema describes the section's data.
+func (s *MultimodalSection) Schema() *jsonschema.Schema {
+	return jsonschema.Object(map[string]*jsonschema.Schema{
+		"model":          jsonschema.String(),
+		"pdf_page_limit": jsonschema.Integer(),
+	})
+}
+
 // Data returns the current configuration data.
 func (s *MultimodalSection) Data() map[string]any {
 	s.mu.RLock()
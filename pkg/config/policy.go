@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPolicyPath is the admin-controlled location of the approval policy.
+const DefaultPolicyPath = "/etc/forge/policy.yaml"
+
+// PolicyConfig holds approval guardrails managed centrally by an administrator.
+// It takes precedence over both the global and the project config, and is
+// never written back, so neither /settings nor a repository can loosen it.
+//
+// Example /etc/forge/policy.yaml:
+//
+//	auto_approval:
+//	  read_file: true
+//	  execute_command: false
+//	command_whitelist:
+//	  - pattern: go test
+//	    description: Run Go tests
+//	exclusive_auto_approval: true
+//	exclusive_command_whitelist: true
+//	approval_rules:
+//	  - commands: ['^(curl|wget)\b']
+//	    decision: deny
+type PolicyConfig struct {
+	AutoApproval     map[string]bool    `yaml:"auto_approval"`
+	CommandWhitelist []WhitelistPattern `yaml:"command_whitelist"`
+
+	// ApprovalRules are evaluated before the project's, so their decisions
+	// cannot be overridden by a repository.
+	ApprovalRules []ApprovalRule `yaml:"approval_rules"`
+
+	// ExclusiveAutoApproval makes tools the policy does not list always
+	// require approval, ignoring user and project settings.
+	ExclusiveAutoApproval bool `yaml:"exclusive_auto_approval"`
+
+	// ExclusiveCommandWhitelist makes the policy's patterns the only ones
+	// honored, ignoring user and project whitelists.
+	ExclusiveCommandWhitelist bool `yaml:"exclusive_command_whitelist"`
+
+	// Path is the path the policy was loaded from.
+	Path string `yaml:"-"`
+}
+
+var (
+	policyConfig   *PolicyConfig
+	policyConfigMu sync.RWMutex
+)
+
+// LoadPolicy reads the approval policy at path. It returns (nil, nil) when the
+// file does not exist. A policy file that users other than its owner can
+// modify is rejected, since it could not be trusted as a guardrail.
+func LoadPolicy(path string) (*PolicyConfig, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o022 != 0 {
+		return nil, fmt.Errorf("policy %s must not be writable by group or others (mode %s)", path, info.Mode().Perm())
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is chosen by the administrator
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	var cfg PolicyConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for i := range cfg.CommandWhitelist {
+		if cfg.CommandWhitelist[i].Type == "" {
+			cfg.CommandWhitelist[i].Type = MatchTypePrefix
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	cfg.Path = path
+
+	return &cfg, nil
+}
+
+// Validate checks the policy for values that cannot be applied.
+func (p *PolicyConfig) Validate() error {
+	for i, pattern := range p.CommandWhitelist {
+		if strings.TrimSpace(pattern.Pattern) == "" {
+			return fmt.Errorf("command_whitelist[%d]: pattern is empty", i)
+		}
+		if pattern.Type != MatchTypePrefix && pattern.Type != MatchTypeExact {
+			return fmt.Errorf("command_whitelist[%d]: invalid type %q (must be %q or %q)", i, pattern.Type, MatchTypePrefix, MatchTypeExact)
+		}
+	}
+	return validateApprovalRules(p.ApprovalRules)
+}
+
+// InitializePolicy loads the policy at path and makes it the active policy
+// layer. It returns the loaded policy, or nil if there is none.
+func InitializePolicy(path string) (*PolicyConfig, error) {
+	cfg, err := LoadPolicy(path)
+	if err != nil {
+		return nil, err
+	}
+	SetPolicy(cfg)
+	return cfg, nil
+}
+
+// SetPolicy replaces the active policy layer. Pass nil to clear it.
+func SetPolicy(cfg *PolicyConfig) {
+	policyConfigMu.Lock()
+	defer policyConfigMu.Unlock()
+	policyConfig = cfg
+}
+
+// GetPolicy returns the active policy layer, or nil if none is loaded.
+func GetPolicy() *PolicyConfig {
+	policyConfigMu.RLock()
+	defer policyConfigMu.RUnlock()
+	return policyConfig
+}
+
+// PolicyAutoApproval returns the policy's auto-approval decision for toolName
+// and whether the policy decides it at all. When it does, user and project
+// settings for the tool have no effect.
+func PolicyAutoApproval(toolName string) (approved, ok bool) {
+	cfg := GetPolicy()
+	if cfg == nil {
+		return false, false
+	}
+	if approved, ok = cfg.AutoApproval[toolName]; ok {
+		return approved, true
+	}
+	if cfg.ExclusiveAutoApproval {
+		return false, true
+	}
+	return false, false
+}
+
+// policyCommandWhitelisted reports whether command matches a policy whitelist
+// pattern, and whether the policy's whitelist is the only one honored.
+func policyCommandWhitelisted(command string) (whitelisted, exclusive bool) {
+	cfg := GetPolicy()
+	if cfg == nil {
+		return false, false
+	}
+
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return false, cfg.ExclusiveCommandWhitelist
+	}
+
+	for _, pattern := range cfg.CommandWhitelist {
+		if matchesPattern(command, pattern.Pattern, pattern.Type) {
+			return true, cfg.ExclusiveCommandWhitelist
+		}
+	}
+	return false, cfg.ExclusiveCommandWhitelist
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePolicy(t *testing.T, content string, perm os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	require.NoError(t, os.Chmod(path, perm))
+	return path
+}
+
+func TestLoadPolicy_Missing(t *testing.T) {
+	cfg, err := LoadPolicy(filepath.Join(t.TempDir(), "policy.yaml"))
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestLoadPolicy_Parses(t *testing.T) {
+	path := writePolicy(t, `
+auto_approval:
+  read_file: true
+command_whitelist:
+  - pattern: go test
+exclusive_command_whitelist: true
+`, 0644)
+
+	cfg, err := LoadPolicy(path)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, map[string]bool{"read_file": true}, cfg.AutoApproval)
+	require.Len(t, cfg.CommandWhitelist, 1)
+	assert.Equal(t, MatchTypePrefix, cfg.CommandWhitelist[0].Type, "type should default to prefix")
+	assert.True(t, cfg.ExclusiveCommandWhitelist)
+	assert.False(t, cfg.ExclusiveAutoApproval)
+	assert.Equal(t, path, cfg.Path)
+}
+
+func TestLoadPolicy_Invalid(t *testing.T) {
+	path := writePolicy(t, `
+command_whitelist:
+  - pattern: ""
+`, 0644)
+	_, err := LoadPolicy(path)
+	assert.Error(t, err)
+
+	if runtime.GOOS != "windows" {
+		path = writePolicy(t, "auto_approval: {}", 0666)
+		_, err = LoadPolicy(path)
+		assert.Error(t, err, "a world-writable policy should be rejected")
+	}
+}
+
+func TestPolicy_OverridesUserAndProject(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, Initialize(tmpFile))
+	t.Cleanup(func() {
+		SetPolicy(nil)
+		SetProjectConfig(nil)
+	})
+
+	GetAutoApproval().SetToolAutoApproval("write_file", true)
+	GetAutoApproval().SetToolAutoApproval("apply_diff", true)
+	SetProjectConfig(&ProjectConfig{
+		AutoApproval:     map[string]bool{"read_file": false},
+		CommandWhitelist: []WhitelistPattern{{Pattern: "make", Type: MatchTypePrefix}},
+	})
+
+	SetPolicy(&PolicyConfig{
+		AutoApproval:     map[string]bool{"write_file": false, "read_file": true},
+		CommandWhitelist: []WhitelistPattern{{Pattern: "go test", Type: MatchTypePrefix}},
+	})
+
+	assert.False(t, IsToolAutoApproved("write_file"), "policy should override global")
+	assert.True(t, IsToolAutoApproved("read_file"), "policy should override project")
+	assert.True(t, IsToolAutoApproved("apply_diff"), "tools the policy does not list keep user settings")
+	assert.True(t, IsCommandWhitelisted("go test ./..."))
+	assert.True(t, IsCommandWhitelisted("make build"), "non-exclusive policy keeps project patterns")
+
+	SetPolicy(&PolicyConfig{
+		AutoApproval:              map[string]bool{"read_file": true},
+		CommandWhitelist:          []WhitelistPattern{{Pattern: "go test", Type: MatchTypePrefix}},
+		ExclusiveAutoApproval:     true,
+		ExclusiveCommandWhitelist: true,
+	})
+
+	assert.True(t, IsToolAutoApproved("read_file"))
+	assert.False(t, IsToolAutoApproved("apply_diff"), "exclusive policy should ignore user settings")
+	assert.True(t, IsCommandWhitelisted("go test ./..."))
+	assert.False(t, IsCommandWhitelisted("make build"), "exclusive policy should ignore project patterns")
+	assert.False(t, IsCommandWhitelisted("git status"), "exclusive policy should ignore global patterns")
+}
@@ -0,0 +1,275 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UserProfilesPath is the home-relative location of the profiles a user shares
+// across all of their projects.
+const UserProfilesPath = ".forge/profiles.yaml"
+
+// Profile is a named working mode, such as "reviewer" or "docs", that bundles
+// instructions, a toolset, a model and constraints so switching modes does not
+// mean rewriting the prompt. Profiles are defined under "profiles:" in
+// ~/.forge/profiles.yaml or .forge/config.yaml and selected with -profile or
+// /profile.
+//
+// Example:
+//
+//	profiles:
+//	  reviewer:
+//	    description: Review changes without editing them
+//	    model: anthropic/claude-opus-4.1
+//	    constraints: [read-only]
+//	    custom_instructions: |
+//	      Review the diff on the current branch. Report bugs before style.
+//	  docs:
+//	    enabled_tools: [read_file, write_file, list_files, search_files, find_files]
+//	    custom_instructions: Only edit Markdown files under docs/.
+type Profile struct {
+	Description        string   `yaml:"description"`
+	Model              string   `yaml:"model"`               // Main model while the profile is active; empty keeps the current one
+	CustomInstructions string   `yaml:"custom_instructions"` // Added to the system prompt under the profile's name
+	EnabledTools       []string `yaml:"enabled_tools"`       // When set, the only tools offered besides the built-ins
+	DisabledTools      []string `yaml:"disabled_tools"`      // Tools hidden while the profile is active
+	Constraints        []string `yaml:"constraints"`         // Names of ProfileConstraints to apply
+
+	// Name is the key the profile is defined under.
+	Name string `yaml:"-"`
+	// Source is the path of the file that defines the profile.
+	Source string `yaml:"-"`
+}
+
+// ProfileConstraint is a preset restriction a profile can apply by name.
+type ProfileConstraint struct {
+	Name        string
+	Description string
+	Tools       []string // Tools hidden by the constraint
+	Instruction string   // Tells the agent what it cannot do while the constraint applies
+}
+
+// profileConstraints lists the presets profiles can name in "constraints".
+var profileConstraints = []ProfileConstraint{
+	{
+		Name:        "read-only",
+		Description: "Read and search files but do not modify them",
+		Tools:       []string{"write_file", "apply_diff", "rename_symbol", "delete_file", "move_file", "create_directory"},
+		Instruction: "You cannot modify files in this mode. Describe the changes you would make instead of making them.",
+	},
+	{
+		Name:        "no-commands",
+		Description: "Do not run shell commands, tests, custom tools or interactive programs",
+		Tools: []string{
+			"execute_command", "run_tests", "run_custom_tool",
+			"start_terminal", "terminal_send_keys", "terminal_read_screen", "list_terminals", "close_terminal",
+		},
+		Instruction: "You cannot run shell commands in this mode. Ask the user to run any command you need.",
+	},
+	{
+		Name:        "no-network",
+		Description: "Do not fetch URLs, call HTTP APIs or drive a browser",
+		Tools: []string{
+			"fetch_url", "http_request", "web_search", "analyze_page",
+			"start_browser_session", "close_browser_session", "list_browser_sessions",
+			"browser_navigate", "browser_click", "browser_fill_form", "browser_wait",
+			"browser_search", "browser_evaluate", "browser_screenshot", "browser_extract_content",
+		},
+		Instruction: "You cannot access the network in this mode. Work only with what is in the workspace.",
+	},
+}
+
+// ProfileConstraints returns the presets profiles can apply.
+func ProfileConstraints() []ProfileConstraint {
+	constraints := make([]ProfileConstraint, len(profileConstraints))
+	copy(constraints, profileConstraints)
+	return constraints
+}
+
+// lookupProfileConstraint returns the preset with the given name.
+func lookupProfileConstraint(name string) (ProfileConstraint, bool) {
+	for _, constraint := range profileConstraints {
+		if constraint.Name == name {
+			return constraint, true
+		}
+	}
+	return ProfileConstraint{}, false
+}
+
+// profileNamePattern restricts profile names to what is easy to type after
+// -profile and /profile.
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Validate checks the profile for values that cannot be applied.
+func (p *Profile) Validate() error {
+	for i, name := range p.EnabledTools {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("enabled_tools[%d]: tool name is empty", i)
+		}
+	}
+	for i, name := range p.DisabledTools {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("disabled_tools[%d]: tool name is empty", i)
+		}
+	}
+	for i, name := range p.Constraints {
+		if _, ok := lookupProfileConstraint(name); !ok {
+			return fmt.Errorf("constraints[%d]: unknown constraint %q", i, name)
+		}
+	}
+	return nil
+}
+
+// validateProfiles checks the names and contents of a profiles map.
+func validateProfiles(profiles map[string]*Profile) error {
+	for name, profile := range profiles {
+		if !profileNamePattern.MatchString(name) {
+			return fmt.Errorf("profiles: invalid name %q (use lowercase letters, digits, '-' and '_')", name)
+		}
+		if profile == nil {
+			return fmt.Errorf("profiles.%s: profile is empty", name)
+		}
+		if err := profile.Validate(); err != nil {
+			return fmt.Errorf("profiles.%s.%w", name, err)
+		}
+	}
+	return nil
+}
+
+// HidesTool reports whether the profile keeps toolName from the agent. The
+// built-in tools that end a turn are never hidden, so the agent can always
+// finish or ask for help.
+func (p *Profile) HidesTool(toolName string) bool {
+	if p == nil {
+		return false
+	}
+	switch toolName {
+	case "task_completion", "ask_question", "converse":
+		return false
+	}
+	if len(p.EnabledTools) > 0 && !slices.Contains(p.EnabledTools, toolName) {
+		return true
+	}
+	if slices.Contains(p.DisabledTools, toolName) {
+		return true
+	}
+	for _, name := range p.Constraints {
+		if constraint, ok := lookupProfileConstraint(name); ok && slices.Contains(constraint.Tools, toolName) {
+			return true
+		}
+	}
+	return false
+}
+
+// Instructions returns the system prompt section for the profile: its custom
+// instructions followed by what its constraints rule out. It is safe to call
+// on a nil profile, in which case it returns "".
+func (p *Profile) Instructions() string {
+	if p == nil {
+		return ""
+	}
+
+	var lines []string
+	if text := strings.TrimSpace(p.CustomInstructions); text != "" {
+		lines = append(lines, text)
+	}
+	for _, name := range p.Constraints {
+		if constraint, ok := lookupProfileConstraint(name); ok {
+			lines = append(lines, constraint.Instruction)
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("# Profile: %s\n\nThe user selected the %q working mode. Follow these instructions until they switch modes:\n\n%s",
+		p.Name, p.Name, strings.Join(lines, "\n\n"))
+}
+
+// profilesFile is the layout of ~/.forge/profiles.yaml.
+type profilesFile struct {
+	Profiles map[string]*Profile `yaml:"profiles"`
+}
+
+// LoadUserProfiles reads the profiles defined in the file at path, or in
+// ~/.forge/profiles.yaml when path is empty. It returns (nil, nil) when the
+// file does not exist.
+func LoadUserProfiles(path string) (map[string]*Profile, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, UserProfilesPath)
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is the user's own profiles file
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var file profilesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := validateProfiles(file.Profiles); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	for name, profile := range file.Profiles {
+		profile.Name = name
+		profile.Source = path
+	}
+	return file.Profiles, nil
+}
+
+// LoadProfiles returns the profiles available in the workspace: the user's
+// from ~/.forge/profiles.yaml (or userPath when set), overridden by any of the
+// same name in the project config. project may be nil.
+func LoadProfiles(userPath string, project *ProjectConfig) (map[string]*Profile, error) {
+	profiles, err := LoadUserProfiles(userPath)
+	if err != nil {
+		return nil, err
+	}
+	if profiles == nil {
+		profiles = make(map[string]*Profile)
+	}
+	if project != nil {
+		for name, profile := range project.Profiles {
+			profile.Name = name
+			profile.Source = project.Path
+			profiles[name] = profile
+		}
+	}
+	return profiles, nil
+}
+
+// ProfileNames returns the names of profiles in sorted order.
+func ProfileNames(profiles map[string]*Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LookupProfile returns the profile called name, or an error listing the
+// profiles that exist.
+func LookupProfile(profiles map[string]*Profile, name string) (*Profile, error) {
+	if profile, ok := profiles[name]; ok {
+		return profile, nil
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("unknown profile %q: no profiles are defined in ~/%s or %s", name, UserProfilesPath, ProjectConfigPath)
+	}
+	return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(ProfileNames(profiles), ", "))
+}
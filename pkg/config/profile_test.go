@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile_HidesTool(t *testing.T) {
+	var none *Profile
+	assert.False(t, none.HidesTool("write_file"), "a nil profile hides nothing")
+
+	reviewer := &Profile{Constraints: []string{"read-only", "no-network"}, DisabledTools: []string{"run_tests"}}
+	assert.True(t, reviewer.HidesTool("write_file"))
+	assert.True(t, reviewer.HidesTool("browser_click"))
+	assert.True(t, reviewer.HidesTool("run_tests"))
+	assert.False(t, reviewer.HidesTool("read_file"))
+
+	docs := &Profile{EnabledTools: []string{"read_file", "write_file"}}
+	assert.False(t, docs.HidesTool("write_file"))
+	assert.True(t, docs.HidesTool("execute_command"))
+	assert.False(t, docs.HidesTool("task_completion"), "built-ins that end a turn are never hidden")
+}
+
+func TestProfile_Instructions(t *testing.T) {
+	var none *Profile
+	assert.Empty(t, none.Instructions())
+	assert.Empty(t, (&Profile{Name: "plain"}).Instructions())
+
+	text := (&Profile{Name: "reviewer", CustomInstructions: "Report bugs first.\n", Constraints: []string{"read-only"}}).Instructions()
+	assert.Contains(t, text, "# Profile: reviewer")
+	assert.Contains(t, text, "Report bugs first.\n\nYou cannot modify files")
+}
+
+func TestLoadProfiles_ProjectOverridesUser(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "profiles.yaml")
+	require.NoError(t, os.WriteFile(userPath, []byte(`
+profiles:
+  reviewer:
+    description: Personal reviewer
+  docs:
+    model: team/small
+`), 0600))
+
+	dir := writeProjectConfig(t, `
+profiles:
+  reviewer:
+    description: Team reviewer
+    constraints: [read-only]
+`)
+	project, err := LoadProjectConfig(dir)
+	require.NoError(t, err)
+
+	profiles, err := LoadProfiles(userPath, project)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs", "reviewer"}, ProfileNames(profiles))
+	assert.Equal(t, "Team reviewer", profiles["reviewer"].Description)
+	assert.Equal(t, project.Path, profiles["reviewer"].Source)
+	assert.Equal(t, "docs", profiles["docs"].Name)
+	assert.Equal(t, userPath, profiles["docs"].Source)
+
+	_, err = LookupProfile(profiles, "tester")
+	assert.ErrorContains(t, err, "available: docs, reviewer")
+
+	missing, err := LoadProfiles(filepath.Join(t.TempDir(), "missing.yaml"), nil)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestLoadProjectConfig_InvalidProfiles(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown constraint": "profiles:\n  reviewer:\n    constraints: [no-writes]\n",
+		"invalid name":       "profiles:\n  Code Review:\n    model: x\n",
+		"empty tool":         "profiles:\n  docs:\n    enabled_tools: [\"\"]\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadProjectConfig(writeProjectConfig(t, content))
+			assert.ErrorContains(t, err, "profiles")
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigPath is the workspace-relative location of the per-project config file.
+const ProjectConfigPath = ".forge/config.yaml"
+
+// ProjectConfig is shared agent policy committed to a repository. It is layered
+// over the global config at runtime and is never written back to it, so edits
+// made through /settings remain personal.
+//
+// Example .forge/config.yaml:
+//
+//	llm:
+//	  model: anthropic/claude-sonnet-4.5
+//	  fallback_model: anthropic/claude-haiku-4.5
+//	auto_approval:
+//	  read_file: true
+//	  execute_command: false
+//	command_whitelist:
+//	  - pattern: go test
+//	    description: Run Go tests
+//	approval_rules:
+//	  - tools: [write_file, apply_diff]
+//	    paths: ["**/.env"]
+//	    decision: deny
+//	custom_instructions: |
+//	  Run `make lint` before declaring a task complete.
+//	disabled_tools:
+//	  - fetch_url
+//	path_rules:
+//	  deny_write: ["vendor/**", "*.lock"]
+//	  read_only: [docs/adr]
+//	  max_file_size: 1048576
+//	roots:
+//	  - name: proto
+//	    path: ../shared-protos
+//	  - name: design
+//	    path: ../design-system
+//	    read_only: true
+//	allowed_dirs:
+//	  - path: ~/go/pkg/mod
+//	  - path: ../schemas
+//	    write: true
+//	experimental:
+//	  ast_tools: true
+//	tool_limits:
+//	  tools:
+//	    search_files:
+//	      timeout: 30s
+//	repository_context:
+//	  max_tokens: 4000
+//	project_memory:
+//	  auto_extract: false
+//	tool_schemas:
+//	  lazy: true
+//	bootstrap:
+//	  auto_run: true
+//	context:
+//	  strategies:
+//	    - name: tool_calls
+//	    - name: threshold
+//	profiles:
+//	  reviewer:
+//	    constraints: [read-only]
+//	    custom_instructions: Review the current branch and report bugs first.
+type ProjectConfig struct {
+	LLM                ProjectLLMConfig         `yaml:"llm"`
+	AutoApproval       map[string]bool          `yaml:"auto_approval"`
+	CommandWhitelist   []WhitelistPattern       `yaml:"command_whitelist"`
+	ApprovalRules      []ApprovalRule           `yaml:"approval_rules"`
+	CustomInstructions string                   `yaml:"custom_instructions"`
+	DisabledTools      []string                 `yaml:"disabled_tools"`
+	PathRules          ProjectPathRules         `yaml:"path_rules"`
+	Roots              []ProjectRoot            `yaml:"roots"`
+	AllowedDirs        []ProjectAllowedDir      `yaml:"allowed_dirs"`
+	Experimental       map[string]bool          `yaml:"experimental"`
+	Profiles           map[string]*Profile      `yaml:"profiles"`
+	ToolLimits         *ToolLimits              `yaml:"tool_limits"`
+	RepositoryContext  *RepositoryContextConfig `yaml:"repository_context"`
+	ProjectMemory      *ProjectMemoryConfig     `yaml:"project_memory"`
+	ToolSchemas        *ToolSchemasConfig       `yaml:"tool_schemas"`
+	Bootstrap          *BootstrapConfig         `yaml:"bootstrap"`
+	Context            *ContextConfig           `yaml:"context"`
+
+	// Path is the absolute path the config was loaded from.
+	Path string `yaml:"-"`
+}
+
+// ProjectLLMConfig holds the LLM settings a project may pin.
+type ProjectLLMConfig struct {
+	Model         string `yaml:"model"`
+	FallbackModel string `yaml:"fallback_model"` // Model to retry on when Model is rate limited
+}
+
+// ProjectPathRules restricts which paths the agent's tools may modify. They are
+// enforced by the workspace guard for write_file, apply_diff and
+// execute_command working directories.
+type ProjectPathRules struct {
+	DenyWrite   []string `yaml:"deny_write"`    // Workspace-relative globs; "**" matches any number of directories
+	ReadOnly    []string `yaml:"read_only"`     // Directories that may be read but not written
+	MaxFileSize int64    `yaml:"max_file_size"` // Largest file in bytes a tool may write; 0 means no limit
+}
+
+// ProjectRoot is a directory outside the workspace the agent's tools may work
+// in, such as a shared repository changed together with this one. Tools refer
+// to it as "@<name>/<path>".
+type ProjectRoot struct {
+	Name     string `yaml:"name"`
+	Path     string `yaml:"path"`      // Relative paths are resolved against the workspace
+	ReadOnly bool   `yaml:"read_only"` // Allow reads only
+}
+
+// ProjectAllowedDir is a directory outside the workspace the agent's tools may
+// use by its absolute path, such as the Go module cache. A project config is
+// checked in with the repository, so its directories are read-only unless
+// Write is set.
+type ProjectAllowedDir struct {
+	Path  string `yaml:"path"`  // "~" is expanded; relative paths are resolved against the workspace
+	Write bool   `yaml:"write"` // Allow writes and commands as well as reads
+}
+
+var (
+	projectConfig   *ProjectConfig
+	projectConfigMu sync.RWMutex
+)
+
+// LoadProjectConfig reads .forge/config.yaml from workspaceDir.
+// It returns (nil, nil) when the file does not exist.
+func LoadProjectConfig(workspaceDir string) (*ProjectConfig, error) {
+	path := filepath.Join(workspaceDir, ProjectConfigPath)
+	data, err := os.ReadFile(path) //nolint:gosec // path is fixed relative to the workspace
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read project config: %w", err)
+	}
+
+	// Unknown keys are errors, so a misspelled setting, such as a typo in
+	// deny_write, does not silently leave the workspace unprotected
+	var cfg ProjectConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", ProjectConfigPath, err)
+	}
+
+	for i := range cfg.CommandWhitelist {
+		if cfg.CommandWhitelist[i].Type == "" {
+			cfg.CommandWhitelist[i].Type = MatchTypePrefix
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ProjectConfigPath, err)
+	}
+
+	if absPath, absErr := filepath.Abs(path); absErr == nil {
+		path = absPath
+	}
+	cfg.Path = path
+
+	return &cfg, nil
+}
+
+// Validate checks the project config for values that cannot be applied.
+func (p *ProjectConfig) Validate() error {
+	for i, pattern := range p.CommandWhitelist {
+		if strings.TrimSpace(pattern.Pattern) == "" {
+			return fmt.Errorf("command_whitelist[%d]: pattern is empty", i)
+		}
+		if pattern.Type != MatchTypePrefix && pattern.Type != MatchTypeExact {
+			return fmt.Errorf("command_whitelist[%d]: invalid type %q (must be %q or %q)", i, pattern.Type, MatchTypePrefix, MatchTypeExact)
+		}
+	}
+	if err := validateApprovalRules(p.ApprovalRules); err != nil {
+		return err
+	}
+	for i, name := range p.DisabledTools {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("disabled_tools[%d]: tool name is empty", i)
+		}
+	}
+	for i, pattern := range p.PathRules.DenyWrite {
+		if err := workspace.ValidatePathPattern(pattern); err != nil {
+			return fmt.Errorf("path_rules.deny_write[%d]: %w", i, err)
+		}
+	}
+	for i, dir := range p.PathRules.ReadOnly {
+		if strings.TrimSpace(dir) == "" {
+			return fmt.Errorf("path_rules.read_only[%d]: directory is empty", i)
+		}
+	}
+	if p.PathRules.MaxFileSize < 0 {
+		return fmt.Errorf("path_rules.max_file_size: must not be negative")
+	}
+	for i, root := range p.Roots {
+		if err := workspace.ValidateRootName(root.Name); err != nil {
+			return fmt.Errorf("roots[%d]: %w", i, err)
+		}
+		if strings.TrimSpace(root.Path) == "" {
+			return fmt.Errorf("roots[%d]: path is empty", i)
+		}
+	}
+	for i, dir := range p.AllowedDirs {
+		if strings.TrimSpace(dir.Path) == "" {
+			return fmt.Errorf("allowed_dirs[%d]: path is empty", i)
+		}
+	}
+	for name := range p.Experimental {
+		if _, ok := lookupExperimentalFeature(name); !ok {
+			return fmt.Errorf("experimental: unknown feature %q", name)
+		}
+	}
+	if err := p.ToolLimits.Validate(); err != nil {
+		return fmt.Errorf("tool_limits.%w", err)
+	}
+	if err := p.RepositoryContext.Validate(); err != nil {
+		return fmt.Errorf("repository_context.%w", err)
+	}
+	if err := p.ProjectMemory.Validate(); err != nil {
+		return fmt.Errorf("project_memory.%w", err)
+	}
+	if err := p.ToolSchemas.Validate(); err != nil {
+		return fmt.Errorf("tool_schemas.%w", err)
+	}
+	if err := p.Bootstrap.Validate(); err != nil {
+		return fmt.Errorf("bootstrap.%w", err)
+	}
+	if err := p.Context.Validate(); err != nil {
+		return fmt.Errorf("context.%w", err)
+	}
+	return validateProfiles(p.Profiles)
+}
+
+// AppendInstructions returns systemPrompt with the project's custom
+// instructions appended under their own heading. It is safe to call on a nil
+// config, in which case systemPrompt is returned unchanged.
+func (p *ProjectConfig) AppendInstructions(systemPrompt string) string {
+	if p == nil || strings.TrimSpace(p.CustomInstructions) == "" {
+		return systemPrompt
+	}
+	return systemPrompt + "\n\n# Project Instructions\n\nThe following instructions come from this repository's " +
+		ProjectConfigPath + " and apply to all work in it:\n\n" + strings.TrimSpace(p.CustomInstructions)
+}
+
+// GetDisabledTools returns the tools the project opts out of. It is safe to
+// call on a nil config.
+func (p *ProjectConfig) GetDisabledTools() []string {
+	if p == nil {
+		return nil
+	}
+	return p.DisabledTools
+}
+
+// GetToolLimits returns the default tool limits overridden by the project's.
+// It is safe to call on a nil config.
+func (p *ProjectConfig) GetToolLimits() ToolLimits {
+	if p == nil {
+		return DefaultToolLimits()
+	}
+	return DefaultToolLimits().Merge(p.ToolLimits)
+}
+
+// GuardPathRules returns the project's path rules in the form enforced by the
+// workspace guard. It is safe to call on a nil config.
+func (p *ProjectConfig) GuardPathRules() workspace.PathRules {
+	if p == nil {
+		return workspace.PathRules{}
+	}
+	return workspace.PathRules{
+		DenyWrite:   p.PathRules.DenyWrite,
+		ReadOnly:    p.PathRules.ReadOnly,
+		MaxFileSize: p.PathRules.MaxFileSize,
+	}
+}
+
+// GuardRoots returns the project's additional workspace roots in the form
+// enforced by the workspace guard. Relative paths are resolved against the
+// workspace the config was loaded from, so they stay correct for guards of
+// task worktrees. It is safe to call on a nil config.
+func (p *ProjectConfig) GuardRoots() []workspace.Root {
+	if p == nil {
+		return nil
+	}
+	roots := make([]workspace.Root, len(p.Roots))
+	for i, root := range p.Roots {
+		path := root.Path
+		if p.Path != "" && !filepath.IsAbs(path) && !strings.HasPrefix(path, "~") {
+			path = filepath.Join(filepath.Dir(filepath.Dir(p.Path)), path)
+		}
+		roots[i] = workspace.Root{Name: root.Name, Path: path, ReadOnly: root.ReadOnly}
+	}
+	return roots
+}
+
+// GuardAllowedDirs returns the project's allowed directories in the form
+// enforced by the workspace guard, with relative paths resolved like
+// GuardRoots and every directory read-only unless it sets write. It is safe
+// to call on a nil config.
+func (p *ProjectConfig) GuardAllowedDirs() []workspace.AllowedDir {
+	if p == nil {
+		return nil
+	}
+	dirs := make([]workspace.AllowedDir, len(p.AllowedDirs))
+	for i, dir := range p.AllowedDirs {
+		path := dir.Path
+		if p.Path != "" && !filepath.IsAbs(path) && !strings.HasPrefix(path, "~") {
+			path = filepath.Join(filepath.Dir(filepath.Dir(p.Path)), path)
+		}
+		dirs[i] = workspace.AllowedDir{Path: path, ReadOnly: !dir.Write}
+	}
+	return dirs
+}
+
+// InitializeProject loads the project config for workspaceDir and makes it the
+// active project layer. It returns the loaded config, or nil if the workspace
+// has none.
+func InitializeProject(workspaceDir string) (*ProjectConfig, error) {
+	cfg, err := LoadProjectConfig(workspaceDir)
+	if err != nil {
+		return nil, err
+	}
+	SetProjectConfig(cfg)
+	return cfg, nil
+}
+
+// SetProjectConfig replaces the active project layer. Pass nil to clear it.
+func SetProjectConfig(cfg *ProjectConfig) {
+	projectConfigMu.Lock()
+	defer projectConfigMu.Unlock()
+	projectConfig = cfg
+}
+
+// GetProjectConfig returns the active project layer, or nil if none is loaded.
+func GetProjectConfig() *ProjectConfig {
+	projectConfigMu.RLock()
+	defer projectConfigMu.RUnlock()
+	return projectConfig
+}
+
+// projectAutoApproval returns the project's auto-approval setting for toolName
+// and whether the project sets one at all.
+func projectAutoApproval(toolName string) (approved, ok bool) {
+	cfg := GetProjectConfig()
+	if cfg == nil {
+		return false, false
+	}
+	approved, ok = cfg.AutoApproval[toolName]
+	return approved, ok
+}
+
+// projectCommandWhitelisted reports whether command matches a project whitelist pattern.
+func projectCommandWhitelisted(command string) bool {
+	cfg := GetProjectConfig()
+	if cfg == nil {
+		return false
+	}
+
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return false
+	}
+
+	for _, pattern := range cfg.CommandWhitelist {
+		if matchesPattern(command, pattern.Pattern, pattern.Type) {
+			return true
+		}
+	}
+	return false
+}
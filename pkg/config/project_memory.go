@@ -0,0 +1,55 @@
+package config
+
+import "fmt"
+
+// ProjectMemoryConfig controls the project memory file, .forge/memory.md,
+// where durable facts about the project are recorded and loaded into later
+// sessions. Unset fields keep their defaults.
+//
+// Example:
+//
+//	project_memory:
+//	  auto_extract: false   # Only record facts the agent saves with remember
+//	  max_tokens: 1000
+type ProjectMemoryConfig struct {
+	Enabled     *bool `yaml:"enabled"`      // Load the file and register the remember tool (default true)
+	AutoExtract *bool `yaml:"auto_extract"` // Extract learnings from the conversation when a task completes (default true)
+	MaxTokens   int   `yaml:"max_tokens"`   // Budget for the file in the system prompt (default 2000)
+}
+
+// ProjectMemorySettings are the project memory settings in effect.
+type ProjectMemorySettings struct {
+	Enabled     bool
+	AutoExtract bool
+	MaxTokens   int // 0 means the default
+}
+
+// Validate checks the config for values that cannot be applied. It is safe to
+// call on a nil config.
+func (c *ProjectMemoryConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens: must not be negative")
+	}
+	return nil
+}
+
+// GetProjectMemory returns the project's memory file settings, with defaults
+// for what it leaves unset. It is safe to call on a nil config.
+func (p *ProjectConfig) GetProjectMemory() ProjectMemorySettings {
+	settings := ProjectMemorySettings{Enabled: true, AutoExtract: true}
+	if p == nil || p.ProjectMemory == nil {
+		return settings
+	}
+	c := p.ProjectMemory
+	if c.Enabled != nil {
+		settings.Enabled = *c.Enabled
+	}
+	if c.AutoExtract != nil {
+		settings.AutoExtract = *c.AutoExtract
+	}
+	settings.MaxTokens = c.MaxTokens
+	return settings
+}
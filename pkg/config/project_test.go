@@ -0,0 +1,333 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProjectConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".forge"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ProjectConfigPath), []byte(content), 0600))
+	return dir
+}
+
+func TestLoadProjectConfig_Missing(t *testing.T) {
+	cfg, err := LoadProjectConfig(t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestLoadProjectConfig_Parses(t *testing.T) {
+	dir := writeProjectConfig(t, `
+llm:
+  model: team/model
+auto_approval:
+  read_file: true
+  execute_command: false
+command_whitelist:
+  - pattern: go test
+  - pattern: make lint
+    type: exact
+custom_instructions: |
+  Always run make lint.
+disabled_tools:
+  - execute_command
+`)
+
+	cfg, err := LoadProjectConfig(dir)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, "team/model", cfg.LLM.Model)
+	assert.Equal(t, map[string]bool{"read_file": true, "execute_command": false}, cfg.AutoApproval)
+	require.Len(t, cfg.CommandWhitelist, 2)
+	assert.Equal(t, MatchTypePrefix, cfg.CommandWhitelist[0].Type, "type should default to prefix")
+	assert.Equal(t, MatchTypeExact, cfg.CommandWhitelist[1].Type)
+	assert.Equal(t, []string{"execute_command"}, cfg.GetDisabledTools())
+	assert.True(t, filepath.IsAbs(cfg.Path))
+
+	prompt := cfg.AppendInstructions("base")
+	assert.True(t, strings.HasPrefix(prompt, "base\n\n# Project Instructions"))
+	assert.Contains(t, prompt, "Always run make lint.")
+}
+
+func TestLoadProjectConfig_Invalid(t *testing.T) {
+	dir := writeProjectConfig(t, `
+command_whitelist:
+  - pattern: go test
+    type: glob
+`)
+	_, err := LoadProjectConfig(dir)
+	assert.Error(t, err)
+
+	dir = writeProjectConfig(t, "llm: [unterminated")
+	_, err = LoadProjectConfig(dir)
+	assert.Error(t, err)
+
+	// Misspelled keys are reported rather than ignored
+	dir = writeProjectConfig(t, `
+path_rules:
+  deny_writes: ["vendor/**"]
+`)
+	_, err = LoadProjectConfig(dir)
+	assert.ErrorContains(t, err, "deny_writes")
+}
+
+func TestLoadProjectConfig_Empty(t *testing.T) {
+	cfg, err := LoadProjectConfig(writeProjectConfig(t, ""))
+	require.NoError(t, err)
+	assert.NotNil(t, cfg)
+}
+
+func TestProjectConfig_NilSafe(t *testing.T) {
+	var cfg *ProjectConfig
+	assert.Equal(t, "base", cfg.AppendInstructions("base"))
+	assert.Nil(t, cfg.GetDisabledTools())
+	assert.Zero(t, cfg.GuardPathRules())
+	assert.Nil(t, cfg.GuardRoots())
+	assert.Nil(t, cfg.GuardAllowedDirs())
+}
+
+func TestProjectConfig_LayersOverGlobal(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, Initialize(tmpFile))
+	t.Cleanup(func() { SetProjectConfig(nil) })
+
+	GetAutoApproval().SetToolAutoApproval("write_file", true)
+	assert.True(t, IsToolAutoApproved("write_file"))
+	assert.False(t, IsCommandWhitelisted("go test ./..."))
+
+	SetProjectConfig(&ProjectConfig{
+		AutoApproval: map[string]bool{"write_file": false, "read_file": true},
+		CommandWhitelist: []WhitelistPattern{
+			{Pattern: "go test", Type: MatchTypePrefix},
+		},
+	})
+
+	assert.False(t, IsToolAutoApproved("write_file"), "project setting should override global")
+	assert.True(t, IsToolAutoApproved("read_file"))
+	assert.True(t, IsCommandWhitelisted("go test ./..."))
+	assert.True(t, IsCommandWhitelisted("git status"), "global patterns should still apply")
+
+	// The project layer must never leak into the persisted global config
+	require.NoError(t, Global().SaveAll())
+	data, err := os.ReadFile(tmpFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "go test")
+}
+
+func TestLoadProjectConfig_PathRules(t *testing.T) {
+	dir := writeProjectConfig(t, `
+path_rules:
+  deny_write:
+    - vendor/**
+    - "*.lock"
+  read_only:
+    - docs/adr
+  max_file_size: 1048576
+`)
+
+	cfg, err := LoadProjectConfig(dir)
+	require.NoError(t, err)
+
+	rules := cfg.GuardPathRules()
+	assert.Equal(t, []string{"vendor/**", "*.lock"}, rules.DenyWrite)
+	assert.Equal(t, []string{"docs/adr"}, rules.ReadOnly)
+	assert.Equal(t, int64(1048576), rules.MaxFileSize)
+
+	dir = writeProjectConfig(t, `
+path_rules:
+  deny_write:
+    - "src/[a-"
+`)
+	_, err = LoadProjectConfig(dir)
+	assert.ErrorContains(t, err, "path_rules.deny_write[0]")
+
+	dir = writeProjectConfig(t, `
+path_rules:
+  max_file_size: -1
+`)
+	_, err = LoadProjectConfig(dir)
+	assert.ErrorContains(t, err, "path_rules.max_file_size")
+}
+
+func TestLoadProjectConfig_Roots(t *testing.T) {
+	dir := writeProjectConfig(t, `
+roots:
+  - name: proto
+    path: ../shared-protos
+  - name: design
+    path: ../design-system
+    read_only: true
+`)
+
+	cfg, err := LoadProjectConfig(dir)
+	require.NoError(t, err)
+	parent := filepath.Dir(filepath.Dir(filepath.Dir(cfg.Path)))
+	assert.Equal(t, []workspace.Root{
+		{Name: "proto", Path: filepath.Join(parent, "shared-protos")},
+		{Name: "design", Path: filepath.Join(parent, "design-system"), ReadOnly: true},
+	}, cfg.GuardRoots())
+
+	dir = writeProjectConfig(t, `
+roots:
+  - name: "shared protos"
+    path: ../shared-protos
+`)
+	_, err = LoadProjectConfig(dir)
+	assert.ErrorContains(t, err, "roots[0]")
+
+	dir = writeProjectConfig(t, `
+roots:
+  - name: proto
+`)
+	_, err = LoadProjectConfig(dir)
+	assert.ErrorContains(t, err, "roots[0]: path is empty")
+}
+
+func TestLoadProjectConfig_AllowedDirs(t *testing.T) {
+	dir := writeProjectConfig(t, `
+allowed_dirs:
+  - path: ~/go/pkg/mod
+  - path: ../schemas
+    write: true
+`)
+
+	cfg, err := LoadProjectConfig(dir)
+	require.NoError(t, err)
+	parent := filepath.Dir(filepath.Dir(filepath.Dir(cfg.Path)))
+	assert.Equal(t, []workspace.AllowedDir{
+		{Path: "~/go/pkg/mod", ReadOnly: true},
+		{Path: filepath.Join(parent, "schemas")},
+	}, cfg.GuardAllowedDirs())
+
+	dir = writeProjectConfig(t, `
+allowed_dirs:
+  - write: true
+`)
+	_, err = LoadProjectConfig(dir)
+	assert.ErrorContains(t, err, "allowed_dirs[0]: path is empty")
+}
+
+func TestLoadProjectConfig_RepositoryContext(t *testing.T) {
+	var cfg *ProjectConfig
+	assert.Equal(t, RepositoryContextSettings{Enabled: true, Nested: true}, cfg.GetRepositoryContext())
+
+	dir := writeProjectConfig(t, `
+repository_context:
+  nested: false
+  max_tokens: 4000
+`)
+	cfg, err := LoadProjectConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, RepositoryContextSettings{Enabled: true, Nested: false, MaxTokens: 4000}, cfg.GetRepositoryContext())
+
+	dir = writeProjectConfig(t, `
+repository_context:
+  max_tokens: -1
+`)
+	_, err = LoadProjectConfig(dir)
+	assert.ErrorContains(t, err, "repository_context.max_tokens")
+}
+
+func TestLoadProjectConfig_ProjectMemory(t *testing.T) {
+	var cfg *ProjectConfig
+	assert.Equal(t, ProjectMemorySettings{Enabled: true, AutoExtract: true}, cfg.GetProjectMemory())
+
+	dir := writeProjectConfig(t, `
+project_memory:
+  auto_extract: false
+  max_tokens: 1000
+`)
+	cfg, err := LoadProjectConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, ProjectMemorySettings{Enabled: true, AutoExtract: false, MaxTokens: 1000}, cfg.GetProjectMemory())
+
+	dir = writeProjectConfig(t, `
+project_memory:
+  max_tokens: -1
+`)
+	_, err = LoadProjectConfig(dir)
+	assert.ErrorContains(t, err, "project_memory.max_tokens")
+}
+
+func TestLoadProjectConfig_ToolSchemas(t *testing.T) {
+	var cfg *ProjectConfig
+	assert.Equal(t, ToolSchemasConfig{}, cfg.GetToolSchemas())
+
+	dir := writeProjectConfig(t, `
+tool_schemas:
+  lazy: true
+  always_loaded: [web]
+`)
+	cfg, err := LoadProjectConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, ToolSchemasConfig{Lazy: true, AlwaysLoaded: []string{"web"}}, cfg.GetToolSchemas())
+
+	dir = writeProjectConfig(t, `
+tool_schemas:
+  always_loaded: [""]
+`)
+	_, err = LoadProjectConfig(dir)
+	assert.ErrorContains(t, err, "tool_schemas.always_loaded[0]")
+}
+
+func TestLoadProjectConfig_Bootstrap(t *testing.T) {
+	var cfg *ProjectConfig
+	assert.Equal(t, BootstrapSettings{Enabled: true}, cfg.GetBootstrap())
+
+	dir := writeProjectConfig(t, `
+bootstrap:
+  auto_run: true
+  commands: ["make deps"]
+  timeout: 5m
+`)
+	cfg, err := LoadProjectConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, BootstrapSettings{Enabled: true, AutoRun: true, Commands: []string{"make deps"}, Timeout: 5 * time.Minute}, cfg.GetBootstrap())
+
+	dir = writeProjectConfig(t, `
+bootstrap:
+  commands: ["  "]
+`)
+	_, err = LoadProjectConfig(dir)
+	assert.ErrorContains(t, err, "bootstrap.commands[0]")
+}
+
+func TestLoadProjectConfig_Context(t *testing.T) {
+	var cfg *ProjectConfig
+	assert.Nil(t, cfg.GetContextStrategies())
+
+	dir := writeProjectConfig(t, `
+context:
+  strategies:
+    - name: threshold
+      options:
+        trigger_percent: 70
+    - name: keep_user_messages
+`)
+	cfg, err := LoadProjectConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []ContextStrategy{
+		{Name: "threshold", Options: map[string]any{"trigger_percent": 70}},
+		{Name: "keep_user_messages"},
+	}, cfg.GetContextStrategies())
+
+	dir = writeProjectConfig(t, `
+context:
+  strategies:
+    - name: threshold
+    - name: threshold
+`)
+	_, err = LoadProjectConfig(dir)
+	assert.ErrorContains(t, err, "context.strategies[1]")
+}
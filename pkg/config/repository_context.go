@@ -0,0 +1,54 @@
+package config
+
+import "fmt"
+
+// RepositoryContextConfig controls how AGENTS.md files are loaded into the
+// agent's repository context. Unset fields keep their defaults.
+//
+// Example:
+//
+//	repository_context:
+//	  nested: false     # Only the workspace root's AGENTS.md
+//	  max_tokens: 4000
+type RepositoryContextConfig struct {
+	Enabled   *bool `yaml:"enabled"`    // Load AGENTS.md at all (default true)
+	Nested    *bool `yaml:"nested"`     // Also load the AGENTS.md of directories the agent works in (default true)
+	MaxTokens int   `yaml:"max_tokens"` // Budget for all loaded files together (default 8000)
+}
+
+// RepositoryContextSettings are the repository context settings in effect.
+type RepositoryContextSettings struct {
+	Enabled   bool
+	Nested    bool
+	MaxTokens int // 0 means the loader's default
+}
+
+// Validate checks the config for values that cannot be applied. It is safe to
+// call on a nil config.
+func (c *RepositoryContextConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens: must not be negative")
+	}
+	return nil
+}
+
+// GetRepositoryContext returns the project's repository context settings,
+// with defaults for what it leaves unset. It is safe to call on a nil config.
+func (p *ProjectConfig) GetRepositoryContext() RepositoryContextSettings {
+	settings := RepositoryContextSettings{Enabled: true, Nested: true}
+	if p == nil || p.RepositoryContext == nil {
+		return settings
+	}
+	c := p.RepositoryContext
+	if c.Enabled != nil {
+		settings.Enabled = *c.Enabled
+	}
+	if c.Nested != nil {
+		settings.Nested = *c.Nested
+	}
+	settings.MaxTokens = c.MaxTokens
+	return settings
+}
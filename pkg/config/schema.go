@@ -0,0 +1,52 @@
+package config
+
+import (
+	"github.com/entrhq/forge/pkg/config/jsonschema"
+)
+
+// SchemaSection is a section that describes the data it stores as JSON
+// Schema, so the config file can be checked without loading it.
+type SchemaSection interface {
+	Section
+
+	// Schema returns the schema of the data SetData accepts
+	Schema() *jsonschema.Schema
+}
+
+// defaultSections returns the sections the global configuration is made of.
+func defaultSections() []Section {
+	return []Section{
+		NewAutoApprovalSection(),
+		NewCommandWhitelistSection(),
+		NewLLMSection(),
+		NewUISection(),
+		NewMemorySection(),
+		NewMultimodalSection(),
+		NewUpdateSection(),
+		NewExperimentalSection(),
+		NewSecuritySection(),
+	}
+}
+
+// GlobalSchema returns the JSON Schema of the global config file,
+// ~/.forge/config.json.
+func GlobalSchema() *jsonschema.Schema {
+	sections := make(map[string]*jsonschema.Schema)
+	for _, section := range defaultSections() {
+		schema := &jsonschema.Schema{}
+		if described, ok := section.(SchemaSection); ok {
+			schema = described.Schema()
+		}
+		schema.Title = section.Title()
+		schema.Description = section.Description()
+		sections[section.ID()] = schema
+	}
+
+	schema := jsonschema.Object(map[string]*jsonschema.Schema{
+		"version":  jsonschema.String(),
+		"sections": jsonschema.Object(sections),
+	})
+	schema.Schema = jsonschema.Draft
+	schema.Title = "Forge global configuration"
+	return schema
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/config/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultSectionsDescribeTheirData(t *testing.T) {
+	for _, section := range defaultSections() {
+		_, ok := section.(SchemaSection)
+		assert.True(t, ok, "section %s has no schema", section.ID())
+	}
+}
+
+func TestGlobalSchema_AcceptsSavedConfig(t *testing.T) {
+	llm := NewLLMSection()
+	temperature := 0.2
+	llm.Sampling = map[string]SamplingParams{SamplingRoleAgent: {Temperature: &temperature}}
+	llm.Pricing = map[string]ModelPricing{"gpt-4o": {InputPerMillion: 2.5, OutputPerMillion: 10}}
+	llm.Models = []ModelOption{{Name: "gpt-4o", ContextTokens: 128000}}
+	llm.RateLimit = RateLimit{RequestsPerMinute: 50}
+	llm.ToolCalling = ToolCallingNative
+
+	// Every section's data, as FileStore saves it, should match its schema
+	sections := map[string]map[string]any{}
+	for _, section := range defaultSections() {
+		sections[section.ID()] = section.Data()
+	}
+	sections[llm.ID()] = llm.Data()
+	data, err := json.Marshal(map[string]any{"version": "1.0", "sections": sections})
+	require.NoError(t, err)
+
+	issues, err := jsonschema.Validate(GlobalSchema(), data)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestGlobalSchema_ReportsIssues(t *testing.T) {
+	data := []byte(`{
+  "version": "1.0",
+  "sections": {
+    "llm": {"model": "gpt-4o", "modle": "typo"},
+    "update": {"channel": "nightly"},
+    "ui": {"auto_close_delay": 1000000000},
+    "unknown": {}
+  }
+}`)
+
+	issues, err := jsonschema.Validate(GlobalSchema(), data)
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		paths = append(paths, issue.Path)
+	}
+	assert.Equal(t, []string{"sections.llm.modle", "sections.update.channel", "sections.ui.auto_close_delay", "sections.unknown"}, paths)
+	assert.True(t, issues[2].Warning, "numeric auto_close_delay should be reported as deprecated")
+}
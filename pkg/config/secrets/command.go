@@ -0,0 +1,58 @@
+//go:build !windows
+
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runCommand runs a credential store client, reporting a missing client as
+// ErrUnavailable
+func runCommand(cmd *exec.Cmd, stdin string) (string, error) {
+	if _, err := exec.LookPath(cmd.Path); err != nil {
+		return "", ErrUnavailable
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// lookupOutput turns a client's lookup result into a secret. Both clients
+// exit non-zero when nothing is stored for the account.
+func lookupOutput(out string, err error) (string, error) {
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	secret := strings.TrimRight(out, "\r\n")
+	if secret == "" {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+// deleteOutput treats a client's failure to find the account as success
+func deleteOutput(err error) error {
+	var exitErr *exec.ExitError
+	if err == nil || errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
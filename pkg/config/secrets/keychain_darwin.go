@@ -0,0 +1,32 @@
+package secrets
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// keychainStore uses the macOS login keychain through the security client.
+type keychainStore struct{}
+
+func newSystemStore() Store {
+	return keychainStore{}
+}
+
+func (keychainStore) Get(account string) (string, error) {
+	return lookupOutput(runCommand(exec.Command("security", "find-generic-password", "-s", Service, "-a", account, "-w"), ""))
+}
+
+func (keychainStore) Set(account, secret string) error {
+	// security only reads the password from its arguments; -U updates an
+	// existing item in place
+	cmd := exec.Command("security", "add-generic-password", "-U", "-s", Service, "-a", account, "-w", secret)
+	if _, err := runCommand(cmd, ""); err != nil {
+		return fmt.Errorf("failed to store secret in keychain: %w", err)
+	}
+	return nil
+}
+
+func (keychainStore) Delete(account string) error {
+	_, err := runCommand(exec.Command("security", "delete-generic-password", "-s", Service, "-a", account), "")
+	return deleteOutput(err)
+}
@@ -0,0 +1,78 @@
+// Package secrets keeps credentials such as API keys and git tokens in the
+// operating system's credential store (the macOS keychain, the Secret Service
+// on Linux and the BSDs, or the Windows Credential Manager) so they never have
+// to sit in a config file or shell profile.
+package secrets
+
+import (
+	"errors"
+	"sync"
+)
+
+// Service is the service name every secret is stored under
+const Service = "forge"
+
+// AccountLLMAPIKey is the account holding the LLM provider's API key
+const AccountLLMAPIKey = "llm_api_key"
+
+var (
+	// ErrUnavailable is returned when the system has no usable credential store.
+	ErrUnavailable = errors.New("no system keyring available")
+	// ErrNotFound is returned when the store has no secret for an account.
+	ErrNotFound = errors.New("secret not found in keyring")
+)
+
+// Store keeps secrets by account name.
+type Store interface {
+	// Get returns the secret for account, or ErrNotFound.
+	Get(account string) (string, error)
+	// Set stores secret for account, replacing any previous one.
+	Set(account, secret string) error
+	// Delete removes the secret for account. Deleting a missing secret is not
+	// an error.
+	Delete(account string) error
+}
+
+var (
+	storeMu     sync.RWMutex
+	activeStore = newSystemStore()
+)
+
+// SetStore replaces the store used for secrets, e.g. with an in-memory one in
+// tests. A nil store restores the operating system's.
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	if s == nil {
+		s = newSystemStore()
+	}
+	activeStore = s
+}
+
+// Current returns the store in use.
+func Current() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return activeStore
+}
+
+// Get returns the secret for account from the current store.
+func Get(account string) (string, error) {
+	return Current().Get(account)
+}
+
+// Set stores secret for account in the current store.
+func Set(account, secret string) error {
+	return Current().Set(account, secret)
+}
+
+// Delete removes the secret for account from the current store.
+func Delete(account string) error {
+	return Current().Delete(account)
+}
+
+// GitTokenAccount returns the account a git hosting provider's API token is
+// stored under, e.g. "github_token".
+func GitTokenAccount(provider string) string {
+	return provider + "_token"
+}
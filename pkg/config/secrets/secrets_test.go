@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+)
+
+// memoryStore is a Store backed by a map
+type memoryStore map[string]string
+
+func (s memoryStore) Get(account string) (string, error) {
+	if secret, ok := s[account]; ok {
+		return secret, nil
+	}
+	return "", ErrNotFound
+}
+
+func (s memoryStore) Set(account, secret string) error {
+	s[account] = secret
+	return nil
+}
+
+func (s memoryStore) Delete(account string) error {
+	delete(s, account)
+	return nil
+}
+
+func TestSetStore(t *testing.T) {
+	store := memoryStore{}
+	SetStore(store)
+	t.Cleanup(func() { SetStore(nil) })
+
+	if err := Set(GitTokenAccount("github"), "ghp_secret"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if store["github_token"] != "ghp_secret" {
+		t.Errorf("store = %v, want the token under github_token", store)
+	}
+	if got, err := Get("github_token"); err != nil || got != "ghp_secret" {
+		t.Errorf("Get() = %q, %v, want the stored token", got, err)
+	}
+
+	if err := Delete("github_token"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := Get("github_token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+
+	SetStore(nil)
+	if _, ok := Current().(memoryStore); ok {
+		t.Error("SetStore(nil) should restore the system store")
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !darwin && !windows
+
+package secrets
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// secretServiceStore uses the freedesktop Secret Service (GNOME Keyring,
+// KWallet) through libsecret's secret-tool client.
+type secretServiceStore struct{}
+
+func newSystemStore() Store {
+	return secretServiceStore{}
+}
+
+func (secretServiceStore) Get(account string) (string, error) {
+	return lookupOutput(runCommand(exec.Command("secret-tool", "lookup", "service", Service, "account", account), ""))
+}
+
+func (secretServiceStore) Set(account, secret string) error {
+	// secret-tool reads the secret from stdin, keeping it out of the process list
+	cmd := exec.Command("secret-tool", "store", "--label", "Forge "+account, "service", Service, "account", account)
+	if _, err := runCommand(cmd, secret); err != nil {
+		return fmt.Errorf("failed to store secret in keyring: %w", err)
+	}
+	return nil
+}
+
+func (secretServiceStore) Delete(account string) error {
+	_, err := runCommand(exec.Command("secret-tool", "clear", "service", Service, "account", account), "")
+	return deleteOutput(err)
+}
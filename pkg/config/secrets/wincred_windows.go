@@ -0,0 +1,118 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	// maxCredentialBlobSize is CRED_MAX_CREDENTIAL_BLOB_SIZE
+	maxCredentialBlobSize = 5 * 512
+)
+
+var (
+	advapi32        = windows.NewLazySystemDLL("advapi32.dll")
+	procCredReadW   = advapi32.NewProc("CredReadW")
+	procCredWriteW  = advapi32.NewProc("CredWriteW")
+	procCredDeleteW = advapi32.NewProc("CredDeleteW")
+	procCredFree    = advapi32.NewProc("CredFree")
+)
+
+// credential mirrors the Win32 CREDENTIALW structure
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// wincredStore uses the Windows Credential Manager, storing each secret as a
+// generic credential named "forge:<account>".
+type wincredStore struct{}
+
+func newSystemStore() Store {
+	return wincredStore{}
+}
+
+// credentialTarget returns the Credential Manager target name for account
+func credentialTarget(account string) (*uint16, error) {
+	return windows.UTF16PtrFromString(Service + ":" + account)
+}
+
+func (wincredStore) Get(account string) (string, error) {
+	target, err := credentialTarget(account)
+	if err != nil {
+		return "", err
+	}
+
+	var cred *credential
+	if r, _, callErr := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred))); r == 0 {
+		if errors.Is(callErr, windows.ERROR_NOT_FOUND) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to read secret from Credential Manager: %w", callErr)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred))) //nolint:errcheck
+
+	if cred.CredentialBlobSize == 0 {
+		return "", ErrNotFound
+	}
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func (wincredStore) Set(account, secret string) error {
+	if len(secret) > maxCredentialBlobSize {
+		return fmt.Errorf("secret is %d bytes; Credential Manager holds at most %d", len(secret), maxCredentialBlobSize)
+	}
+	target, err := credentialTarget(account)
+	if err != nil {
+		return err
+	}
+	userName, err := windows.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           userName,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if r, _, callErr := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return fmt.Errorf("failed to store secret in Credential Manager: %w", callErr)
+	}
+	return nil
+}
+
+func (wincredStore) Delete(account string) error {
+	target, err := credentialTarget(account)
+	if err != nil {
+		return err
+	}
+	if r, _, callErr := procCredDeleteW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); r == 0 {
+		if errors.Is(callErr, windows.ERROR_NOT_FOUND) {
+			return nil // Nothing was stored
+		}
+		return fmt.Errorf("failed to delete secret from Credential Manager: %w", callErr)
+	}
+	return nil
+}
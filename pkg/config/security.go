@@ -0,0 +1,219 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/config/jsonschema"
+)
+
+const (
+	// SectionIDSecurity is the identifier for the security settings section
+	SectionIDSecurity = "security"
+)
+
+// defaultHTTPAllowedHosts lets http_request reach servers on this machine,
+// where the API being worked on usually runs, and nothing else.
+var defaultHTTPAllowedHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// SecuritySection manages network access granted to the agent's tools.
+type SecuritySection struct {
+	// HTTPAllowedHosts lists the hosts http_request may send requests to.
+	// See MatchHostPattern for the pattern syntax.
+	HTTPAllowedHosts []string
+	mu               sync.RWMutex
+}
+
+// NewSecuritySection creates a new security section with default settings.
+func NewSecuritySection() *SecuritySection {
+	return &SecuritySection{
+		HTTPAllowedHosts: slices.Clone(defaultHTTPAllowedHosts),
+	}
+}
+
+// ID returns the section identifier.
+func (s *SecuritySection) ID() string {
+	return SectionIDSecurity
+}
+
+// Title returns the section title.
+func (s *SecuritySection) Title() string {
+	return "Security Settings"
+}
+
+// Description returns the section description.
+func (s *SecuritySection) Description() string {
+	return "Configure network access for tools. http_allowed_hosts lists the hosts http_request may call: host names or IPs, optionally with :port, '*.example.com' for subdomains, or '*' for any host."
+}
+
+// Schema describes the section's data. http_allowed_hosts may also be a
+// comma-separated string.
+func (s *SecuritySection) Schema() *jsonschema.Schema {
+	return jsonschema.Object(map[string]*jsonschema.Schema{
+		"http_allowed_hosts": jsonschema.OneOf(
+			jsonschema.Array(jsonschema.String()),
+			jsonschema.String(),
+		),
+	})
+}
+
+// Data returns the current configuration data.
+func (s *SecuritySection) Data() map[string]any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hosts := make([]any, len(s.HTTPAllowedHosts))
+	for i, host := range s.HTTPAllowedHosts {
+		hosts[i] = host
+	}
+	return map[string]any{
+		"http_allowed_hosts": hosts,
+	}
+}
+
+// SetData updates the configuration from the provided data. Hosts may be a
+// list or, as edited in the settings overlay, a comma-separated string.
+func (s *SecuritySection) SetData(data map[string]any) error {
+	if data == nil {
+		return nil
+	}
+
+	raw, ok := data["http_allowed_hosts"]
+	if !ok {
+		return nil
+	}
+
+	var hosts []string
+	switch v := raw.(type) {
+	case string:
+		for _, host := range strings.Split(v, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+	case []string:
+		hosts = v
+	case []any:
+		for _, item := range v {
+			host, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("invalid http_allowed_hosts entry: expected string, got %T", item)
+			}
+			hosts = append(hosts, host)
+		}
+	default:
+		return fmt.Errorf("invalid http_allowed_hosts: expected list of strings, got %T", raw)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.HTTPAllowedHosts = hosts
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *SecuritySection) Validate() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, pattern := range s.HTTPAllowedHosts {
+		if err := ValidateHostPattern(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset resets the section to default configuration.
+func (s *SecuritySection) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.HTTPAllowedHosts = slices.Clone(defaultHTTPAllowedHosts)
+}
+
+// GetHTTPAllowedHosts returns a copy of the hosts http_request may call.
+func (s *SecuritySection) GetHTTPAllowedHosts() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.HTTPAllowedHosts)
+}
+
+// IsHTTPHostAllowed reports whether http_request may send a request to u.
+// The default allow-list applies when config is not initialized.
+func IsHTTPHostAllowed(u *url.URL) bool {
+	hosts := defaultHTTPAllowedHosts
+	if security := GetSecurity(); security != nil {
+		hosts = security.GetHTTPAllowedHosts()
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	for _, pattern := range hosts {
+		if MatchHostPattern(pattern, u.Hostname(), port) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateHostPattern reports whether pattern is a usable allowed host.
+func ValidateHostPattern(pattern string) error {
+	host, port := splitHostPattern(pattern)
+	if host == "" {
+		return fmt.Errorf("invalid allowed host %q: host is empty", pattern)
+	}
+	if strings.Contains(strings.TrimPrefix(host, "*."), "*") && host != "*" {
+		return fmt.Errorf("invalid allowed host %q: '*' is only allowed as '*' or a leading '*.'", pattern)
+	}
+	if strings.ContainsAny(host, "/ ") {
+		return fmt.Errorf("invalid allowed host %q: expected a host name, not a URL", pattern)
+	}
+	if port != "" && strings.Trim(port, "0123456789") != "" {
+		return fmt.Errorf("invalid allowed host %q: port must be a number", pattern)
+	}
+	return nil
+}
+
+// MatchHostPattern reports whether host and port match an allowed host
+// pattern. A pattern is a host name or IP address, optionally followed by
+// :port (IPv6 addresses need brackets to carry a port). Without a port, any
+// port matches. "*.example.com" matches subdomains of example.com but not
+// example.com itself, and "*" matches every host.
+func MatchHostPattern(pattern, host, port string) bool {
+	patternHost, patternPort := splitHostPattern(pattern)
+	if patternPort != "" && patternPort != port {
+		return false
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	switch {
+	case patternHost == "*":
+		return true
+	case strings.HasPrefix(patternHost, "*."):
+		return strings.HasSuffix(host, patternHost[1:])
+	default:
+		return host == patternHost
+	}
+}
+
+// splitHostPattern splits an allowed host pattern into its lower-cased host
+// and optional port.
+func splitHostPattern(pattern string) (host, port string) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	// A bare IPv6 address has several colons and no port
+	if strings.HasPrefix(pattern, "[") || strings.Count(pattern, ":") == 1 {
+		if h, p, err := net.SplitHostPort(pattern); err == nil {
+			return strings.TrimSuffix(h, "."), p
+		}
+	}
+	return strings.TrimSuffix(strings.Trim(pattern, "[]"), "."), ""
+}
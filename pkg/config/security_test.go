@@ -0,0 +1,76 @@
+package config
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecuritySection(t *testing.T) {
+	section := NewSecuritySection()
+	assert.Equal(t, []string{"localhost", "127.0.0.1", "::1"}, section.GetHTTPAllowedHosts())
+	assert.NoError(t, section.Validate())
+
+	assert.NoError(t, section.SetData(map[string]any{"http_allowed_hosts": []any{"api.internal:8080", "*.example.com"}}))
+	assert.Equal(t, []string{"api.internal:8080", "*.example.com"}, section.GetHTTPAllowedHosts())
+
+	// The settings overlay edits the list as comma-separated text
+	assert.NoError(t, section.SetData(map[string]any{"http_allowed_hosts": "localhost, staging.internal ,"}))
+	assert.Equal(t, []string{"localhost", "staging.internal"}, section.GetHTTPAllowedHosts())
+
+	assert.Error(t, section.SetData(map[string]any{"http_allowed_hosts": []any{1}}))
+
+	assert.NoError(t, section.SetData(map[string]any{"http_allowed_hosts": []any{"https://example.com/api"}}))
+	assert.Error(t, section.Validate())
+
+	section.Reset()
+	assert.Equal(t, []string{"localhost", "127.0.0.1", "::1"}, section.GetHTTPAllowedHosts())
+}
+
+func TestValidateHostPattern(t *testing.T) {
+	for _, pattern := range []string{"localhost", "localhost:8080", "*", "*.example.com", "::1", "[::1]:3000", "10.0.0.5"} {
+		assert.NoError(t, ValidateHostPattern(pattern), pattern)
+	}
+	for _, pattern := range []string{"", "api.*.com", "example.com/path", "localhost:http"} {
+		assert.Error(t, ValidateHostPattern(pattern), pattern)
+	}
+}
+
+func TestMatchHostPattern(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		host, port string
+		want       bool
+	}{
+		{"localhost", "localhost", "8080", true},
+		{"localhost", "LOCALHOST", "80", true},
+		{"localhost:8080", "localhost", "8080", true},
+		{"localhost:8080", "localhost", "9090", false},
+		{"*.example.com", "api.example.com", "443", true},
+		{"*.example.com", "example.com", "443", false},
+		{"*.example.com", "badexample.com", "443", false},
+		{"*", "anything.test", "1", true},
+		{"::1", "::1", "3000", true},
+		{"[::1]:3000", "::1", "3000", true},
+		{"[::1]:3000", "::1", "4000", false},
+		{"127.0.0.1", "127.0.0.2", "80", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchHostPattern(tt.pattern, tt.host, tt.port), "%s vs %s:%s", tt.pattern, tt.host, tt.port)
+	}
+}
+
+func TestIsHTTPHostAllowed_Defaults(t *testing.T) {
+	for raw, want := range map[string]bool{
+		"http://localhost:8080/api":   true,
+		"http://127.0.0.1/health":     true,
+		"http://[::1]:3000/":          true,
+		"https://example.com/":        false,
+		"http://localhost.evil.test/": false,
+	} {
+		u, err := url.Parse(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, want, IsHTTPHostAllowed(u), raw)
+	}
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+)
+
+// ToolLimit bounds a single call to a tool. A zero field means no limit.
+type ToolLimit struct {
+	Timeout        time.Duration `yaml:"timeout" json:"timeout"`                   // How long a call may run before it is stopped
+	MaxResultBytes int           `yaml:"max_result_bytes" json:"max_result_bytes"` // Largest result kept whole; longer results keep their head and tail
+}
+
+// ToolLimits bounds every tool call so one runaway call, such as a search over
+// node_modules, cannot stall the agent loop or flood the context. Timeout and
+// MaxResultBytes apply to all tools; Tools overrides them per tool, field by
+// field.
+//
+// Example:
+//
+//	tool_limits:
+//	  timeout: 10m
+//	  max_result_bytes: 100000
+//	  tools:
+//	    search_files:
+//	      timeout: 30s
+//	      max_result_bytes: 20000
+type ToolLimits struct {
+	Timeout        time.Duration        `yaml:"timeout" json:"timeout"`
+	MaxResultBytes int                  `yaml:"max_result_bytes" json:"max_result_bytes"`
+	Tools          map[string]ToolLimit `yaml:"tools" json:"tools"`
+}
+
+// DefaultToolLimits returns the limits used when none are configured: a
+// catch-all timeout, a shorter one for the tools that walk the workspace, a
+// longer one for the tools that run commands with their own timeouts, and a
+// result size in line with the default per-message token ceiling.
+func DefaultToolLimits() ToolLimits {
+	return ToolLimits{
+		Timeout:        10 * time.Minute,
+		MaxResultBytes: 100000,
+		Tools: map[string]ToolLimit{
+			"search_files":    {Timeout: time.Minute},
+			"find_files":      {Timeout: time.Minute},
+			"list_files":      {Timeout: time.Minute},
+			"execute_command": {Timeout: 30 * time.Minute},
+			"run_tests":       {Timeout: 30 * time.Minute},
+			"run_custom_tool": {Timeout: 30 * time.Minute},
+		},
+	}
+}
+
+// For returns the limits that apply to toolName.
+func (l ToolLimits) For(toolName string) ToolLimit {
+	limit := ToolLimit{Timeout: l.Timeout, MaxResultBytes: l.MaxResultBytes}
+	if override, ok := l.Tools[toolName]; ok {
+		if override.Timeout != 0 {
+			limit.Timeout = override.Timeout
+		}
+		if override.MaxResultBytes != 0 {
+			limit.MaxResultBytes = override.MaxResultBytes
+		}
+	}
+	return limit
+}
+
+// Merge returns l with the fields set in override replacing its own. Per-tool
+// limits are merged field by field too. override may be nil.
+func (l ToolLimits) Merge(override *ToolLimits) ToolLimits {
+	merged := ToolLimits{
+		Timeout:        l.Timeout,
+		MaxResultBytes: l.MaxResultBytes,
+		Tools:          maps.Clone(l.Tools),
+	}
+	if override == nil {
+		return merged
+	}
+	if override.Timeout != 0 {
+		merged.Timeout = override.Timeout
+	}
+	if override.MaxResultBytes != 0 {
+		merged.MaxResultBytes = override.MaxResultBytes
+	}
+	if len(override.Tools) > 0 && merged.Tools == nil {
+		merged.Tools = make(map[string]ToolLimit, len(override.Tools))
+	}
+	for name, limit := range override.Tools {
+		current := merged.Tools[name]
+		if limit.Timeout != 0 {
+			current.Timeout = limit.Timeout
+		}
+		if limit.MaxResultBytes != 0 {
+			current.MaxResultBytes = limit.MaxResultBytes
+		}
+		merged.Tools[name] = current
+	}
+	return merged
+}
+
+// Validate checks the limits for values that cannot be applied. It is safe to
+// call on nil limits.
+func (l *ToolLimits) Validate() error {
+	if l == nil {
+		return nil
+	}
+	if err := validateToolLimit("", ToolLimit{Timeout: l.Timeout, MaxResultBytes: l.MaxResultBytes}); err != nil {
+		return err
+	}
+	for name, limit := range l.Tools {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("tools: tool name is empty")
+		}
+		if err := validateToolLimit("tools."+name+".", limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateToolLimit checks a single limit, naming fields after prefix.
+func validateToolLimit(prefix string, limit ToolLimit) error {
+	if limit.Timeout < 0 {
+		return fmt.Errorf("%stimeout: must not be negative", prefix)
+	}
+	if limit.MaxResultBytes < 0 {
+		return fmt.Errorf("%smax_result_bytes: must not be negative", prefix)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToolLimits_For(t *testing.T) {
+	limits := ToolLimits{
+		Timeout:        time.Minute,
+		MaxResultBytes: 1000,
+		Tools: map[string]ToolLimit{
+			"search_files": {Timeout: 10 * time.Second},
+		},
+	}
+
+	if got := limits.For("search_files"); got != (ToolLimit{Timeout: 10 * time.Second, MaxResultBytes: 1000}) {
+		t.Errorf("search_files limit = %+v", got)
+	}
+	if got := limits.For("read_file"); got != (ToolLimit{Timeout: time.Minute, MaxResultBytes: 1000}) {
+		t.Errorf("read_file limit = %+v", got)
+	}
+	if got := (ToolLimits{}).For("read_file"); got != (ToolLimit{}) {
+		t.Errorf("expected no limit, got %+v", got)
+	}
+}
+
+func TestToolLimits_Merge(t *testing.T) {
+	defaults := DefaultToolLimits()
+	merged := defaults.Merge(&ToolLimits{
+		MaxResultBytes: 5000,
+		Tools: map[string]ToolLimit{
+			"search_files": {MaxResultBytes: 2000},
+			"fetch_url":    {Timeout: 20 * time.Second},
+		},
+	})
+
+	if merged.Timeout != defaults.Timeout || merged.MaxResultBytes != 5000 {
+		t.Errorf("merged = %+v", merged)
+	}
+	if got := merged.For("search_files"); got != (ToolLimit{Timeout: time.Minute, MaxResultBytes: 2000}) {
+		t.Errorf("search_files limit = %+v", got)
+	}
+	if got := merged.For("fetch_url"); got.Timeout != 20*time.Second {
+		t.Errorf("fetch_url limit = %+v", got)
+	}
+	if _, ok := defaults.Tools["fetch_url"]; ok {
+		t.Error("Merge modified the receiver's per-tool limits")
+	}
+	if got := defaults.Merge(nil); got.For("search_files") != defaults.For("search_files") {
+		t.Errorf("merging nil changed the limits: %+v", got)
+	}
+}
+
+func TestToolLimits_Validate(t *testing.T) {
+	var nilLimits *ToolLimits
+	if err := nilLimits.Validate(); err != nil {
+		t.Errorf("nil limits: %v", err)
+	}
+	if err := (&ToolLimits{MaxResultBytes: -1}).Validate(); err == nil || !strings.Contains(err.Error(), "max_result_bytes") {
+		t.Errorf("expected max_result_bytes error, got %v", err)
+	}
+	err := (&ToolLimits{Tools: map[string]ToolLimit{"search_files": {Timeout: -time.Second}}}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "tools.search_files.timeout") {
+		t.Errorf("expected per-tool timeout error, got %v", err)
+	}
+}
+
+func TestLoadProjectConfig_ToolLimits(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".forge"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := "tool_limits:\n  max_result_bytes: 20000\n  tools:\n    search_files:\n      timeout: 30s\n"
+	if err := os.WriteFile(filepath.Join(dir, ProjectConfigPath), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadProjectConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := cfg.GetToolLimits()
+	if got := limits.For("search_files"); got != (ToolLimit{Timeout: 30 * time.Second, MaxResultBytes: 20000}) {
+		t.Errorf("search_files limit = %+v", got)
+	}
+	if got := limits.For("read_file"); got.Timeout != DefaultToolLimits().Timeout {
+		t.Errorf("expected the default timeout for read_file, got %+v", got)
+	}
+
+	var nilConfig *ProjectConfig
+	if got := nilConfig.GetToolLimits(); got.For("search_files") != DefaultToolLimits().For("search_files") {
+		t.Errorf("nil config limits = %+v", got)
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToolSchemasConfig controls which tool schemas are sent with every LLM call.
+// With lazy schemas, the tools used for occasional tasks, such as the browser
+// and web tools, are listed in a short manifest by group, and the agent loads
+// a group's schemas with load_tools when a task needs them.
+//
+// Example:
+//
+//	tool_schemas:
+//	  lazy: true
+//	  always_loaded: [web]   # Groups whose schemas are sent from the start
+type ToolSchemasConfig struct {
+	Lazy         bool     `yaml:"lazy"`          // List occasional tool groups in a manifest instead of sending their schemas (default false)
+	AlwaysLoaded []string `yaml:"always_loaded"` // Groups loaded from the start of the session
+}
+
+// Validate checks the config for values that cannot be applied. It is safe to
+// call on a nil config.
+func (c *ToolSchemasConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	for i, group := range c.AlwaysLoaded {
+		if strings.TrimSpace(group) == "" {
+			return fmt.Errorf("always_loaded[%d]: group name is empty", i)
+		}
+	}
+	return nil
+}
+
+// GetToolSchemas returns the project's tool schema settings. It is safe to
+// call on a nil config.
+func (p *ProjectConfig) GetToolSchemas() ToolSchemasConfig {
+	if p == nil || p.ToolSchemas == nil {
+		return ToolSchemasConfig{}
+	}
+	return *p.ToolSchemas
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/entrhq/forge/pkg/config/jsonschema"
 )
 
 const (
@@ -17,6 +19,7 @@ const (
 	defaultBrowserEnabled          = false
 	defaultBrowserHeadless         = true
 	defaultShowThinking            = true
+	defaultSyntaxHighlighting      = true
 )
 
 // UISection manages user interface configuration settings.
@@ -27,6 +30,7 @@ type UISection struct {
 	BrowserEnabled          bool          `json:"browser_enabled"`
 	BrowserHeadless         bool          `json:"browser_headless"`
 	ShowThinking            bool          `json:"show_thinking"`
+	SyntaxHighlighting      bool          `json:"syntax_highlighting"`
 	mu                      sync.RWMutex
 }
 
@@ -39,6 +43,7 @@ func NewUISection() *UISection {
 		BrowserEnabled:          defaultBrowserEnabled,
 		BrowserHeadless:         defaultBrowserHeadless,
 		ShowThinking:            defaultShowThinking,
+		SyntaxHighlighting:      defaultSyntaxHighlighting,
 	}
 }
 
@@ -57,6 +62,22 @@ func (s *UISection) Description() string {
 	return "Configure user interface behavior including command overlay auto-close and browser automation settings."
 }
 
+// Schema describes the section's data.
+func (s *UISection) Schema() *jsonschema.Schema {
+	return jsonschema.Object(map[string]*jsonschema.Schema{
+		"auto_close_command_overlay": jsonschema.Boolean(),
+		"keep_open_on_error":         jsonschema.Boolean(),
+		"auto_close_delay": jsonschema.OneOf(
+			jsonschema.Duration(),
+			jsonschema.Integer().Deprecate("nanoseconds; write a duration such as 1s instead"),
+		),
+		"browser_enabled":     jsonschema.Boolean(),
+		"browser_headless":    jsonschema.Boolean(),
+		"show_thinking":       jsonschema.Boolean(),
+		"syntax_highlighting": jsonschema.Boolean(),
+	})
+}
+
 // Data returns the current configuration data.
 func (s *UISection) Data() map[string]any {
 	s.mu.RLock()
@@ -69,6 +90,7 @@ func (s *UISection) Data() map[string]any {
 		"browser_enabled":            s.BrowserEnabled,
 		"browser_headless":           s.BrowserHeadless,
 		"show_thinking":              s.ShowThinking,
+		"syntax_highlighting":        s.SyntaxHighlighting,
 	}
 }
 
@@ -111,6 +133,9 @@ func (s *UISection) setField(key string, value any) error {
 	case "show_thinking":
 		return s.setBoolField(&s.ShowThinking, value, key)
 
+	case "syntax_highlighting":
+		return s.setBoolField(&s.SyntaxHighlighting, value, key)
+
 	default:
 		// Ignore unknown keys for forward compatibility
 		return nil
@@ -172,6 +197,7 @@ func (s *UISection) Reset() {
 	s.BrowserEnabled = defaultBrowserEnabled
 	s.BrowserHeadless = defaultBrowserHeadless
 	s.ShowThinking = defaultShowThinking
+	s.SyntaxHighlighting = defaultSyntaxHighlighting
 }
 
 // GetAutoCloseSettings returns the current auto-close configuration.
@@ -253,6 +279,22 @@ func (s *UISection) SetShowThinking(show bool) {
 	s.ShowThinking = show
 }
 
+// IsSyntaxHighlighting returns whether code blocks in messages are
+// syntax highlighted in the TUI.
+func (s *UISection) IsSyntaxHighlighting() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.SyntaxHighlighting
+}
+
+// SetSyntaxHighlighting sets whether code blocks in messages are syntax
+// highlighted in the TUI.
+func (s *UISection) SetSyntaxHighlighting(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.SyntaxHighlighting = enabled
+}
+
 // IsBrowserHeadless returns whether browser runs in headless mode by default.
 func (s *UISection) IsBrowserHeadless() bool {
 	s.mu.RLock()
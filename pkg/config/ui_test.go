@@ -367,3 +367,25 @@ func TestUISection_BrowserReset(t *testing.T) {
 		t.Error("Expected browser headless to be true after reset")
 	}
 }
+
+func TestUISection_SyntaxHighlighting(t *testing.T) {
+	ui := NewUISection()
+	if !ui.IsSyntaxHighlighting() {
+		t.Error("Expected syntax highlighting to be enabled by default")
+	}
+
+	if err := ui.SetData(map[string]any{"syntax_highlighting": false}); err != nil {
+		t.Fatalf("Unexpected error setting data: %v", err)
+	}
+	if ui.IsSyntaxHighlighting() {
+		t.Error("Expected syntax highlighting to be disabled after SetData")
+	}
+	if enabled, ok := ui.Data()["syntax_highlighting"].(bool); !ok || enabled {
+		t.Error("Expected syntax_highlighting to be false in data")
+	}
+
+	ui.Reset()
+	if !ui.IsSyntaxHighlighting() {
+		t.Error("Expected syntax highlighting to be enabled after reset")
+	}
+}
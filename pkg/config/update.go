@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/config/jsonschema"
+)
+
+const (
+	// SectionIDUpdate is the identifier for the update settings section
+	SectionIDUpdate = "update"
+
+	defaultUpdateChannel   = "stable"
+	defaultCheckForUpdates = true
+)
+
+// UpdateSection manages self-update settings.
+type UpdateSection struct {
+	Channel         string // stable (full releases) or beta (also pre-releases)
+	CheckForUpdates bool   // show an update-available notice when the TUI starts
+	mu              sync.RWMutex
+}
+
+// NewUpdateSection creates a new update section with default settings.
+func NewUpdateSection() *UpdateSection {
+	return &UpdateSection{
+		Channel:         defaultUpdateChannel,
+		CheckForUpdates: defaultCheckForUpdates,
+	}
+}
+
+// ID returns the section identifier.
+func (s *UpdateSection) ID() string {
+	return SectionIDUpdate
+}
+
+// Title returns the section title.
+func (s *UpdateSection) Title() string {
+	return "Update Settings"
+}
+
+// Description returns the section description.
+func (s *UpdateSection) Description() string {
+	return "Configure self-update. channel selects which releases 'forge update' installs: stable or beta (also pre-releases). check_for_updates shows a notice in the TUI when a newer release is available."
+}
+
+// Schema describes the section's data.
+func (s *UpdateSection) Schema() *jsonschema.Schema {
+	return jsonschema.Object(map[string]*jsonschema.Schema{
+		"channel":           jsonschema.Enum("stable", "beta"),
+		"check_for_updates": jsonschema.Boolean(),
+	})
+}
+
+// Data returns the current configuration data.
+func (s *UpdateSection) Data() map[string]any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return map[string]any{
+		"channel":           s.Channel,
+		"check_for_updates": s.CheckForUpdates,
+	}
+}
+
+// SetData updates the configuration from the provided data.
+func (s *UpdateSection) SetData(data map[string]any) error {
+	if data == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if channel, ok := data["channel"].(string); ok {
+		s.Channel = channel
+	}
+
+	if check, ok := data["check_for_updates"].(bool); ok {
+		s.CheckForUpdates = check
+	}
+
+	return nil
+}
+
+// Validate validates the current configuration.
+func (s *UpdateSection) Validate() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.Channel != "stable" && s.Channel != "beta" {
+		return fmt.Errorf("channel must be 'stable' or 'beta', got %q", s.Channel)
+	}
+
+	return nil
+}
+
+// Reset resets the section to default configuration.
+func (s *UpdateSection) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Channel = defaultUpdateChannel
+	s.CheckForUpdates = defaultCheckForUpdates
+}
+
+// GetChannel returns the configured release channel.
+func (s *UpdateSection) GetChannel() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Channel
+}
+
+// IsCheckForUpdates returns whether the TUI shows update-available notices.
+func (s *UpdateSection) IsCheckForUpdates() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.CheckForUpdates
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateSection(t *testing.T) {
+	section := NewUpdateSection()
+	assert.Equal(t, "stable", section.GetChannel())
+	assert.True(t, section.IsCheckForUpdates())
+	assert.NoError(t, section.Validate())
+
+	assert.NoError(t, section.SetData(map[string]any{"channel": "beta", "check_for_updates": false}))
+	assert.Equal(t, "beta", section.GetChannel())
+	assert.False(t, section.IsCheckForUpdates())
+
+	assert.NoError(t, section.SetData(map[string]any{"channel": "nightly"}))
+	assert.Error(t, section.Validate())
+
+	section.Reset()
+	assert.Equal(t, "stable", section.GetChannel())
+	assert.True(t, section.IsCheckForUpdates())
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"strings"
+
+	"github.com/entrhq/forge/pkg/config/jsonschema"
 )
 
 const (
@@ -16,9 +18,9 @@ const (
 
 // WhitelistPattern represents a command pattern that can be auto-approved.
 type WhitelistPattern struct {
-	Pattern     string `json:"pattern"`
-	Description string `json:"description"`
-	Type        string `json:"type"` // "prefix" or "exact"
+	Pattern     string `json:"pattern" yaml:"pattern"`
+	Description string `json:"description" yaml:"description"`
+	Type        string `json:"type" yaml:"type"` // "prefix" or "exact"
 }
 
 // CommandWhitelistSection manages the whitelist of commands that can be auto-approved.
@@ -64,6 +66,17 @@ func (s *CommandWhitelistSection) Description() string {
 	return "Commands matching these patterns will auto-approve for execute_command tool"
 }
 
+// Schema describes the section's data.
+func (s *CommandWhitelistSection) Schema() *jsonschema.Schema {
+	return jsonschema.Object(map[string]*jsonschema.Schema{
+		"patterns": jsonschema.Array(jsonschema.Object(map[string]*jsonschema.Schema{
+			"pattern":     jsonschema.String(),
+			"description": jsonschema.String(),
+			"type":        jsonschema.Enum(MatchTypePrefix, MatchTypeExact),
+		})),
+	})
+}
+
 // Data returns the current configuration data.
 func (s *CommandWhitelistSection) Data() map[string]any {
 	// Convert patterns to interface{} slice
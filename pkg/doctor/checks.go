@@ -0,0 +1,313 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm/openai"
+	"github.com/entrhq/forge/pkg/llm/tokenizer"
+	"github.com/entrhq/forge/pkg/security/offline"
+	"github.com/entrhq/forge/pkg/tools/browser"
+)
+
+// networkTimeout bounds each request the provider checks make.
+const networkTimeout = 20 * time.Second
+
+// maxModelSuggestions caps the similar models suggested for an unknown model.
+const maxModelSuggestions = 3
+
+// baseURLHint says where the provider's base URL is configured.
+const baseURLHint = "-base-url, OPENAI_BASE_URL, or llm.base_url in /settings"
+
+// CheckGlobalConfig loads the user's configuration file (the default path when
+// path is empty) and validates every section, the way the TUI does at startup.
+func CheckGlobalConfig(path string) Result {
+	const name = "Configuration"
+	if err := config.Initialize(path); err != nil {
+		return fail(name, err.Error(), "Fix the file named above, or move it aside to start over from the defaults")
+	}
+
+	manager := config.Global()
+	if store, isFile := manager.Store().(*config.FileStore); isFile {
+		path = store.Path()
+	}
+	for _, section := range manager.GetSections() {
+		if err := section.Validate(); err != nil {
+			return fail(name, fmt.Sprintf("%s: section %q is invalid: %v", path, section.ID(), err),
+				"Correct the setting in /settings, or edit "+path)
+		}
+	}
+
+	if err := checkWritable(filepath.Dir(path)); err != nil {
+		return warn(name, fmt.Sprintf("%s cannot be saved: %v", path, err),
+			"Make "+filepath.Dir(path)+" writable so settings and sessions can be saved")
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return ok(name, path+" (not created yet, using defaults)")
+	}
+	return ok(name, path)
+}
+
+// CheckProjectConfig validates the workspace's shared .forge/config.yaml, if
+// it has one, and makes it the active project layer so later checks see the
+// model it pins.
+func CheckProjectConfig(workspaceDir string) Result {
+	const name = "Project config"
+	cfg, err := config.InitializeProject(workspaceDir)
+	if err != nil {
+		return fail(name, err.Error(), "Fix "+filepath.Join(workspaceDir, config.ProjectConfigPath))
+	}
+	if cfg == nil {
+		return skip(name, "no "+config.ProjectConfigPath)
+	}
+	return ok(name, cfg.Path)
+}
+
+// CheckProvider checks that an API key is configured and accepted, that the
+// endpoint is reachable, and that it serves the configured model. buildErr is
+// the error from building the provider, which leaves provider nil. With
+// offlineMode the endpoint must be local, as `forge -offline` requires.
+func CheckProvider(ctx context.Context, provider *openai.Provider, buildErr error, offlineMode bool) []Result {
+	const keyName, endpointName, modelName = "API key", "API endpoint", "Model"
+	if buildErr != nil {
+		return []Result{
+			fail(keyName, buildErr.Error(), "Set OPENAI_API_KEY, pass -api-key, or store a key with forge auth login llm or /settings"),
+			skip(endpointName, "needs an API key"),
+			skip(modelName, "needs an API key"),
+		}
+	}
+
+	baseURL, model := provider.GetBaseURL(), provider.GetModel()
+	if offlineMode {
+		if err := offline.CheckEndpoint("LLM provider", baseURL, baseURLHint); err != nil {
+			return []Result{
+				skip(keyName, "not checked"),
+				fail(endpointName, baseURL+" is not local", err.Error()),
+				skip(modelName, model+" (not checked)"),
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, networkTimeout)
+	defer cancel()
+	models, err := provider.ListModels(ctx)
+
+	var statusErr *openai.StatusError
+	switch {
+	case err == nil:
+	case errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden):
+		return []Result{
+			fail(keyName, fmt.Sprintf("rejected by %s (status %d)", baseURL, statusErr.StatusCode),
+				"Check that the key is valid for this endpoint and has not been revoked"),
+			ok(endpointName, baseURL),
+			skip(modelName, model+" (needs a valid API key)"),
+		}
+	case errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusMethodNotAllowed):
+		// Some OpenAI-compatible servers only implement chat completions
+		return []Result{
+			skip(keyName, "not verified: the endpoint does not list models"),
+			warn(endpointName, baseURL+" does not list models, so the key and model could not be verified",
+				"If requests fail, check the base URL ("+baseURLHint+") ends in the API version, e.g. /v1"),
+			skip(modelName, model+" (not verified)"),
+		}
+	case statusErr != nil:
+		return []Result{
+			skip(keyName, "not verified"),
+			fail(endpointName, fmt.Sprintf("%s answered with status %d", baseURL, statusErr.StatusCode),
+				"Check the provider's status page, or the base URL ("+baseURLHint+")"),
+			skip(modelName, model+" (not verified)"),
+		}
+	default:
+		return []Result{
+			skip(keyName, "not verified"),
+			fail(endpointName, fmt.Sprintf("cannot reach %s: %v", baseURL, err),
+				"Check your network connection and proxy settings, or the base URL ("+baseURLHint+")"),
+			skip(modelName, model+" (not verified)"),
+		}
+	}
+
+	results := []Result{
+		ok(keyName, "accepted by "+baseURL),
+		ok(endpointName, fmt.Sprintf("%s (%d models)", baseURL, len(models))),
+	}
+	if slices.ContainsFunc(models, func(m string) bool { return strings.EqualFold(m, model) }) {
+		return append(results, ok(modelName, model))
+	}
+	fix := "Choose a model this endpoint serves with -model or /model"
+	if similar := similarModels(model, models); len(similar) > 0 {
+		fix += "\nSimilar models: " + strings.Join(similar, ", ")
+	}
+	return append(results, fail(modelName, model+" is not served by "+baseURL, fix))
+}
+
+// similarModels returns up to maxModelSuggestions served models sharing the
+// family of model, e.g. other "claude" models for "anthropic/claude-opus-4".
+func similarModels(model string, models []string) []string {
+	family := strings.ToLower(model)
+	if i := strings.LastIndex(family, "/"); i >= 0 {
+		family = family[i+1:]
+	}
+	if i := strings.IndexAny(family, "-.:"); i > 0 {
+		family = family[:i]
+	}
+	if family == "" {
+		return nil
+	}
+
+	var similar []string
+	for _, m := range models {
+		if strings.Contains(strings.ToLower(m), family) {
+			similar = append(similar, m)
+		}
+	}
+	slices.Sort(similar)
+	return similar[:min(len(similar), maxModelSuggestions)]
+}
+
+// CheckGit checks that git is installed, that the workspace is a healthy
+// repository, and that commits can be attributed.
+func CheckGit(ctx context.Context, workspaceDir string) []Result {
+	const gitName, repoName, identityName = "Git", "Git repository", "Git identity"
+	if _, err := exec.LookPath("git"); err != nil {
+		return []Result{
+			fail(gitName, "git not found on PATH", "Install git from https://git-scm.com/downloads"),
+			skip(repoName, "needs git"),
+			skip(identityName, "needs git"),
+		}
+	}
+	version, err := runGit(ctx, "", "--version")
+	if err != nil {
+		return []Result{
+			fail(gitName, err.Error(), "Reinstall git from https://git-scm.com/downloads"),
+			skip(repoName, "needs git"),
+			skip(identityName, "needs git"),
+		}
+	}
+	results := []Result{ok(gitName, strings.TrimPrefix(version, "git version "))}
+
+	if _, err := os.Stat(workspaceDir); err != nil {
+		return append(results, skip(repoName, "workspace not found"), skip(identityName, "workspace not found"))
+	}
+	toplevel, err := runGit(ctx, workspaceDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		results = append(results, warn(repoName, workspaceDir+" is not a git repository",
+			"Run `git init` to use /commit, /pr and the secret_scan gate, and to review the agent's changes with git"))
+	} else if _, err := runGit(ctx, workspaceDir, "status", "--porcelain"); err != nil {
+		results = append(results, fail(repoName, "git status failed: "+err.Error(),
+			"Repair the repository, starting with `git fsck`"))
+	} else {
+		results = append(results, ok(repoName, toplevel))
+	}
+
+	userName, _ := runGit(ctx, workspaceDir, "config", "user.name")
+	userEmail, _ := runGit(ctx, workspaceDir, "config", "user.email")
+	if userName == "" || userEmail == "" {
+		return append(results, warn(identityName, "user.name or user.email is not set, so /commit cannot commit",
+			"git config --global user.name \"Your Name\"\ngit config --global user.email you@example.com"))
+	}
+	return append(results, ok(identityName, fmt.Sprintf("%s <%s>", userName, userEmail)))
+}
+
+// runGit runs git in dir and returns its trimmed output, or an error carrying
+// its stderr.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CheckBrowser checks that Playwright and Chromium are installed for the
+// browser tools. They are optional, so a missing install is a warning.
+func CheckBrowser(offlineMode bool) Result {
+	const name = "Browser tools"
+	if offlineMode {
+		return skip(name, "disabled by -offline")
+	}
+	if err := browser.CheckInstallation(); err != nil {
+		return warn(name, err.Error(), browser.InstallHint)
+	}
+	return ok(name, "Playwright and Chromium installed")
+}
+
+// CheckTokenizer checks that the token encoding for model can be loaded.
+// tiktoken downloads its data on first use and caches it; without it token
+// counts, and so context management, fall back to estimates.
+func CheckTokenizer(model string) Result {
+	const name = "Tokenizer"
+	tok, err := tokenizer.NewForModel(model)
+	if err != nil {
+		return warn(name, fmt.Sprintf("cannot load %s, token counts will be estimates: %v", tokenizer.EncodingForModel(model), err),
+			"Allow access to openaipublic.blob.core.windows.net once so the data is cached,\n"+
+				"or copy the encoding files into the directory named by TIKTOKEN_CACHE_DIR")
+	}
+	return ok(name, tok.Encoding())
+}
+
+// CheckWorkspace checks that the workspace is a directory the agent can read
+// and write.
+func CheckWorkspace(workspaceDir string) Result {
+	const name = "Workspace"
+	const fix = "Fix the directory's permissions, or choose another with -workspace"
+	if abs, err := filepath.Abs(workspaceDir); err == nil {
+		workspaceDir = abs
+	}
+
+	info, err := os.Stat(workspaceDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fail(name, workspaceDir+" does not exist", "Create it, or choose another with -workspace")
+	}
+	if err != nil {
+		return fail(name, err.Error(), fix)
+	}
+	if !info.IsDir() {
+		return fail(name, workspaceDir+" is not a directory", fix)
+	}
+	if _, err := os.ReadDir(workspaceDir); err != nil {
+		return fail(name, "cannot read "+workspaceDir+": "+err.Error(), fix)
+	}
+	if err := checkWritable(workspaceDir); err != nil {
+		return fail(name, workspaceDir+" is not writable: "+err.Error(), fix)
+	}
+	return ok(name, workspaceDir)
+}
+
+// checkWritable creates and removes a file in dir, or in its nearest existing
+// ancestor when dir has not been created yet.
+func checkWritable(dir string) error {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	f, err := os.CreateTemp(dir, ".forge-doctor-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,117 @@
+// Package doctor diagnoses the environment Forge runs in: configuration, the
+// LLM provider, git, optional tool dependencies and workspace permissions.
+//
+// Each check returns a Result that says what it found and, when something is
+// wrong, the fix to apply. `forge doctor` runs them all and prints a report,
+// so onboarding problems surface with an actionable message instead of as a
+// runtime error deep into a session.
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Status is the outcome of a check.
+type Status int
+
+const (
+	// StatusOK means the check passed.
+	StatusOK Status = iota
+	// StatusWarn means Forge runs, but a feature is degraded or unavailable.
+	StatusWarn
+	// StatusFail means Forge cannot run until the problem is fixed.
+	StatusFail
+	// StatusSkip means the check did not apply or depended on a failed check.
+	StatusSkip
+)
+
+// Icon returns the symbol shown for the status in the report.
+func (s Status) Icon() string {
+	switch s {
+	case StatusOK:
+		return "✓"
+	case StatusWarn:
+		return "!"
+	case StatusFail:
+		return "✗"
+	default:
+		return "-"
+	}
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name   string // What was checked, e.g. "Git"
+	Status Status
+	Detail string // What the check found
+	Fix    string // How to fix a warning or failure
+}
+
+// ok, warn, fail and skip build a Result with the given status.
+func ok(name, detail string) Result {
+	return Result{Name: name, Status: StatusOK, Detail: detail}
+}
+
+func warn(name, detail, fix string) Result {
+	return Result{Name: name, Status: StatusWarn, Detail: detail, Fix: fix}
+}
+
+func fail(name, detail, fix string) Result {
+	return Result{Name: name, Status: StatusFail, Detail: detail, Fix: fix}
+}
+
+func skip(name, detail string) Result {
+	return Result{Name: name, Status: StatusSkip, Detail: detail}
+}
+
+// Summary counts the results that need attention.
+type Summary struct {
+	Failures int
+	Warnings int
+}
+
+// Report writes one line per result, with the fix indented below any
+// warning or failure, followed by a summary line.
+func Report(w io.Writer, results []Result) Summary {
+	width := 0
+	for _, r := range results {
+		width = max(width, len(r.Name))
+	}
+
+	var summary Summary
+	for _, r := range results {
+		switch r.Status {
+		case StatusFail:
+			summary.Failures++
+		case StatusWarn:
+			summary.Warnings++
+		}
+		fmt.Fprintf(w, "  %s %-*s  %s\n", r.Status.Icon(), width, r.Name, r.Detail)
+		if r.Fix != "" && (r.Status == StatusFail || r.Status == StatusWarn) {
+			for _, line := range strings.Split(r.Fix, "\n") {
+				fmt.Fprintf(w, "    %*s  → %s\n", width, "", line)
+			}
+		}
+	}
+
+	fmt.Fprintln(w)
+	switch {
+	case summary.Failures > 0:
+		fmt.Fprintf(w, "%s, %s\n", plural(summary.Failures, "problem"), plural(summary.Warnings, "warning"))
+	case summary.Warnings > 0:
+		fmt.Fprintf(w, "No problems, %s\n", plural(summary.Warnings, "warning"))
+	default:
+		fmt.Fprintln(w, "Everything looks good")
+	}
+	return summary
+}
+
+// plural formats n with noun, pluralized unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
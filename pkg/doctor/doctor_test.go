@@ -0,0 +1,212 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/llm/openai"
+)
+
+func TestReport(t *testing.T) {
+	var buf bytes.Buffer
+	summary := Report(&buf, []Result{
+		ok("Git", "2.43.0"),
+		warn("Browser tools", "playwright is not installed", "Run /browser install"),
+		fail("API key", "no API key", "Set OPENAI_API_KEY\nor pass -api-key"),
+		skip("Model", "needs an API key"),
+	})
+
+	if summary.Failures != 1 || summary.Warnings != 1 {
+		t.Errorf("summary = %+v", summary)
+	}
+	want := strings.Join([]string{
+		"  ✓ Git            2.43.0",
+		"  ! Browser tools  playwright is not installed",
+		"                   → Run /browser install",
+		"  ✗ API key        no API key",
+		"                   → Set OPENAI_API_KEY",
+		"                   → or pass -api-key",
+		"  - Model          needs an API key",
+		"",
+		"1 problem, 1 warning",
+		"",
+	}, "\n")
+	if buf.String() != want {
+		t.Errorf("report =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	Report(&buf, []Result{ok("Git", "2.43.0")})
+	if !strings.HasSuffix(buf.String(), "Everything looks good\n") {
+		t.Errorf("report = %q", buf.String())
+	}
+}
+
+func newModelServer(t *testing.T, status int, models ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		ids := make([]string, len(models))
+		for i, m := range models {
+			ids[i] = fmt.Sprintf(`{"id":%q}`, m)
+		}
+		fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(ids, ","))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestProvider(t *testing.T, baseURL, model string) *openai.Provider {
+	t.Helper()
+	provider, err := openai.NewProvider("test-key", openai.WithBaseURL(baseURL), openai.WithModel(model))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func statuses(results []Result) []Status {
+	out := make([]Status, len(results))
+	for i, r := range results {
+		out[i] = r.Status
+	}
+	return out
+}
+
+func TestCheckProvider(t *testing.T) {
+	models := []string{"anthropic/claude-sonnet-4.5", "anthropic/claude-opus-4.1", "openai/gpt-5"}
+
+	t.Run("model served", func(t *testing.T) {
+		server := newModelServer(t, http.StatusOK, models...)
+		results := CheckProvider(context.Background(), newTestProvider(t, server.URL, "Anthropic/Claude-Sonnet-4.5"), nil, false)
+		if fmt.Sprint(statuses(results)) != fmt.Sprint([]Status{StatusOK, StatusOK, StatusOK}) {
+			t.Errorf("results = %+v", results)
+		}
+	})
+
+	t.Run("unknown model", func(t *testing.T) {
+		server := newModelServer(t, http.StatusOK, models...)
+		results := CheckProvider(context.Background(), newTestProvider(t, server.URL, "anthropic/claude-sonet-4"), nil, false)
+		if results[2].Status != StatusFail {
+			t.Fatalf("expected the model check to fail, got %+v", results[2])
+		}
+		if !strings.Contains(results[2].Fix, "Similar models: anthropic/claude-opus-4.1, anthropic/claude-sonnet-4.5") {
+			t.Errorf("fix = %q", results[2].Fix)
+		}
+	})
+
+	t.Run("key rejected", func(t *testing.T) {
+		server := newModelServer(t, http.StatusUnauthorized)
+		results := CheckProvider(context.Background(), newTestProvider(t, server.URL, "gpt-5"), nil, false)
+		if results[0].Status != StatusFail || !strings.Contains(results[0].Detail, "status 401") {
+			t.Errorf("results = %+v", results)
+		}
+	})
+
+	t.Run("models not listed", func(t *testing.T) {
+		server := newModelServer(t, http.StatusNotFound)
+		results := CheckProvider(context.Background(), newTestProvider(t, server.URL, "gpt-5"), nil, false)
+		if fmt.Sprint(statuses(results)) != fmt.Sprint([]Status{StatusSkip, StatusWarn, StatusSkip}) {
+			t.Errorf("results = %+v", results)
+		}
+	})
+
+	t.Run("no API key", func(t *testing.T) {
+		results := CheckProvider(context.Background(), nil, errors.New("API key is required"), false)
+		if results[0].Status != StatusFail || results[1].Status != StatusSkip {
+			t.Errorf("results = %+v", results)
+		}
+	})
+
+	t.Run("offline requires a local endpoint", func(t *testing.T) {
+		results := CheckProvider(context.Background(), newTestProvider(t, "https://api.example.com/v1", "gpt-5"), nil, true)
+		if results[1].Status != StatusFail || !strings.Contains(results[1].Fix, "offline mode") {
+			t.Errorf("results = %+v", results)
+		}
+	})
+}
+
+func TestCheckGit(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	dir := t.TempDir()
+	results := CheckGit(context.Background(), dir)
+	if fmt.Sprint(statuses(results)) != fmt.Sprint([]Status{StatusOK, StatusWarn, StatusWarn}) {
+		t.Fatalf("results = %+v", results)
+	}
+
+	for _, args := range [][]string{{"init"}, {"config", "user.name", "Test"}, {"config", "user.email", "test@example.com"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	results = CheckGit(context.Background(), dir)
+	if fmt.Sprint(statuses(results)) != fmt.Sprint([]Status{StatusOK, StatusOK, StatusOK}) {
+		t.Fatalf("results = %+v", results)
+	}
+	if results[2].Detail != "Test <test@example.com>" {
+		t.Errorf("identity = %q", results[2].Detail)
+	}
+}
+
+func TestCheckWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	if r := CheckWorkspace(dir); r.Status != StatusOK {
+		t.Errorf("result = %+v", r)
+	}
+
+	if r := CheckWorkspace(filepath.Join(dir, "missing")); r.Status != StatusFail || !strings.Contains(r.Detail, "does not exist") {
+		t.Errorf("result = %+v", r)
+	}
+
+	file := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if r := CheckWorkspace(file); r.Status != StatusFail || !strings.Contains(r.Detail, "not a directory") {
+		t.Errorf("result = %+v", r)
+	}
+}
+
+func TestCheckConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if r := CheckGlobalConfig(path); r.Status != StatusOK || !strings.Contains(r.Detail, "not created yet") {
+		t.Errorf("result = %+v", r)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if r := CheckGlobalConfig(path); r.Status != StatusFail || !strings.Contains(r.Detail, path) {
+		t.Errorf("result = %+v", r)
+	}
+
+	if r := CheckProjectConfig(dir); r.Status != StatusSkip {
+		t.Errorf("result = %+v", r)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".forge"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".forge", "config.yaml"), []byte("llm: [unclosed"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if r := CheckProjectConfig(dir); r.Status != StatusFail {
+		t.Errorf("result = %+v", r)
+	}
+}
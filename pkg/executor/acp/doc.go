@@ -0,0 +1,36 @@
+// Package acp implements an editor integration executor that speaks JSON-RPC
+// 2.0 over stdin and stdout, in the style of the Agent Client Protocol.
+//
+// Editor plugins (Neovim, VS Code, Zed) launch "forge --acp" as a child
+// process in the project directory and drive it over its standard streams,
+// so they embed exactly the same agent core as the TUI without scraping it.
+// Each message is one JSON object on its own line. Logs never go to stdout.
+//
+// Methods the client calls:
+//
+//	initialize            {"protocol_version": 1}  -> {"protocol_version", "agent_info", "capabilities"}
+//	session/new           {}                       -> {"session_id"}
+//	session/list          {}                       -> {"sessions": [{"id", "created_at", "busy"}]}
+//	session/prompt        {"session_id", "content"} -> {"stop_reason": "end_turn" | "cancelled"}
+//	session/cancel        {"session_id"}           -> {}
+//	session/close         {"session_id"}           -> {}
+//
+// session/prompt replies when the agent's turn ends, so a client sends one
+// prompt per session at a time. session/cancel may also be sent as a
+// notification.
+//
+// Messages Forge sends:
+//
+//	session/update              notification  {"session_id", "event"}
+//	session/request_permission  request       {"session_id", "approval_id", "tool_name", "tool_input", "preview"} -> {"approved": bool}
+//
+// Events in session/update have the same shape as the HTTP API's SSE events
+// (see package server). Tool calls that need approval arrive as
+// session/request_permission requests instead, with a preview whose type is
+// "diff", "file_write" or "command"; diffs and file writes name the file in
+// "path" and carry the change in "content".
+//
+// Errors use the standard JSON-RPC codes, plus CodeSessionNotFound and
+// CodeSessionBusy. Forge shuts every session down and exits when stdin is
+// closed.
+package acp
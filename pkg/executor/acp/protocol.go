@@ -0,0 +1,185 @@
+package acp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/executor/server"
+)
+
+// ProtocolVersion is the protocol version this package speaks.
+const ProtocolVersion = 1
+
+// Method names.
+const (
+	MethodInitialize        = "initialize"
+	MethodNewSession        = "session/new"
+	MethodListSessions      = "session/list"
+	MethodPrompt            = "session/prompt"
+	MethodCancel            = "session/cancel"
+	MethodCloseSession      = "session/close"
+	MethodUpdate            = "session/update"
+	MethodRequestPermission = "session/request_permission"
+)
+
+// Error codes. The first five are defined by JSON-RPC 2.0.
+const (
+	CodeParseError      = -32700
+	CodeInvalidRequest  = -32600
+	CodeMethodNotFound  = -32601
+	CodeInvalidParams   = -32602
+	CodeInternalError   = -32603
+	CodeSessionNotFound = -32001
+	CodeSessionBusy     = -32002
+)
+
+// Stop reasons reported when a prompt's turn ends.
+const (
+	StopReasonEndTurn   = "end_turn"
+	StopReasonCancelled = "cancelled"
+)
+
+// message is any JSON-RPC message: a request has a method and an ID, a
+// notification a method only, and a response an ID with a result or error.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// outgoing is a JSON-RPC message written to the client.
+type outgoing struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+func newError(code int, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Info identifies a client or agent implementation.
+type Info struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Capabilities describes the optional protocol features Forge supports.
+type Capabilities struct {
+	Approvals bool `json:"approvals"` // Tool calls may be sent for approval with session/request_permission
+	Diffs     bool `json:"diffs"`     // Approval previews of file edits carry a unified diff
+	Cancel    bool `json:"cancel"`    // session/cancel stops the running turn
+}
+
+// InitializeParams are the parameters of initialize.
+type InitializeParams struct {
+	ProtocolVersion int   `json:"protocol_version"`
+	ClientInfo      *Info `json:"client_info,omitempty"`
+}
+
+// InitializeResult is the result of initialize.
+type InitializeResult struct {
+	ProtocolVersion int          `json:"protocol_version"`
+	AgentInfo       Info         `json:"agent_info"`
+	Capabilities    Capabilities `json:"capabilities"`
+}
+
+// NewSessionResult is the result of session/new.
+type NewSessionResult struct {
+	SessionID string `json:"session_id"`
+}
+
+// SessionInfo describes a session in session/list.
+type SessionInfo struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Busy      bool      `json:"busy"`
+}
+
+// ListSessionsResult is the result of session/list.
+type ListSessionsResult struct {
+	Sessions []SessionInfo `json:"sessions"`
+}
+
+// SessionParams are the parameters of session/cancel and session/close.
+type SessionParams struct {
+	SessionID string `json:"session_id"`
+}
+
+// PromptParams are the parameters of session/prompt.
+type PromptParams struct {
+	SessionID string `json:"session_id"`
+	Content   string `json:"content"`
+}
+
+// PromptResult is the result of session/prompt.
+type PromptResult struct {
+	StopReason string `json:"stop_reason"`
+}
+
+// UpdateParams are the parameters of the session/update notification.
+type UpdateParams struct {
+	SessionID string       `json:"session_id"`
+	Event     server.Event `json:"event"`
+}
+
+// Preview shows what an approved tool call will do.
+type Preview struct {
+	Type        string         `json:"type"`
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Path        string         `json:"path,omitempty"`
+	Content     string         `json:"content,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+}
+
+// newPreview converts a tool preview to its wire form.
+func newPreview(preview any) *Preview {
+	p, ok := preview.(*tools.ToolPreview)
+	if !ok || p == nil {
+		return nil
+	}
+	wire := &Preview{
+		Type:        string(p.Type),
+		Title:       p.Title,
+		Description: p.Description,
+		Content:     p.Content,
+		Metadata:    p.Metadata,
+	}
+	if path, ok := p.Metadata["file_path"].(string); ok {
+		wire.Path = path
+	}
+	return wire
+}
+
+// PermissionParams are the parameters of session/request_permission.
+type PermissionParams struct {
+	SessionID  string         `json:"session_id"`
+	ApprovalID string         `json:"approval_id"`
+	ToolName   string         `json:"tool_name"`
+	ToolInput  map[string]any `json:"tool_input,omitempty"`
+	Preview    *Preview       `json:"preview,omitempty"`
+}
+
+// PermissionResult is the client's answer to session/request_permission.
+type PermissionResult struct {
+	Approved bool `json:"approved"`
+}
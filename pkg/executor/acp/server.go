@@ -0,0 +1,410 @@
+package acp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/executor/server"
+)
+
+const (
+	defaultMaxSessions = 16
+	shutdownTimeout    = 10 * time.Second
+)
+
+// Server is an executor that serves agent sessions to an editor over a
+// JSON-RPC connection on a pair of streams, usually stdin and stdout.
+type Server struct {
+	factory     server.SessionFactory
+	agentInfo   Info
+	maxSessions int
+
+	writeMu sync.Mutex
+	enc     *json.Encoder
+
+	callMu  sync.Mutex
+	lastID  int64
+	pending map[string]chan *message
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	baseCtx  context.Context
+	handlers sync.WaitGroup
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithAgentInfo sets the name and version reported by initialize.
+func WithAgentInfo(name, version string) Option {
+	return func(s *Server) {
+		s.agentInfo = Info{Name: name, Version: version}
+	}
+}
+
+// WithMaxSessions limits the number of concurrent sessions (default 16).
+func WithMaxSessions(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.maxSessions = n
+		}
+	}
+}
+
+// NewServer creates a server that creates one agent per session using factory.
+func NewServer(factory server.SessionFactory, opts ...Option) *Server {
+	s := &Server{
+		factory:     factory,
+		agentInfo:   Info{Name: "forge"},
+		maxSessions: defaultMaxSessions,
+		pending:     make(map[string]chan *message),
+		sessions:    make(map[string]*session),
+		baseCtx:     context.Background(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Serve reads messages from in and writes messages to out until in is closed
+// or ctx is canceled, then shuts down every session.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.mu.Lock()
+	s.baseCtx = ctx
+	s.mu.Unlock()
+	s.writeMu.Lock()
+	s.enc = json.NewEncoder(out)
+	s.writeMu.Unlock()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	var err error
+loop:
+	for {
+		select {
+		case line := <-lines:
+			s.handle(line)
+		case err = <-readErr:
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			break loop
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	stopErr := s.Stop(stopCtx)
+	s.handlers.Wait()
+	return errors.Join(err, stopErr)
+}
+
+// Stop shuts down every session.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	sessions := make([]*session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.sessions = make(map[string]*session)
+	s.mu.Unlock()
+
+	var errs []error
+	for _, sess := range sessions {
+		if err := sess.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("session %s: %w", sess.ID(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// handle routes one incoming message. Requests run concurrently, since
+// session/prompt only replies when the agent's turn ends.
+func (s *Server) handle(line []byte) {
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		_ = s.write(outgoing{ID: json.RawMessage("null"), Error: newError(CodeParseError, "invalid JSON: %v", err)})
+		return
+	}
+	if msg.JSONRPC != "2.0" {
+		if msg.ID != nil {
+			_ = s.write(outgoing{ID: msg.ID, Error: newError(CodeInvalidRequest, "jsonrpc must be \"2.0\"")})
+		}
+		return
+	}
+
+	// A message without a method answers one of our requests
+	if msg.Method == "" {
+		s.resolve(&msg)
+		return
+	}
+
+	s.handlers.Add(1)
+	go func() {
+		defer s.handlers.Done()
+		result, rpcErr := s.dispatch(msg.Method, msg.Params)
+		if msg.ID == nil {
+			return // Notifications get no response
+		}
+		if rpcErr != nil {
+			_ = s.write(outgoing{ID: msg.ID, Error: rpcErr})
+			return
+		}
+		_ = s.write(outgoing{ID: msg.ID, Result: result})
+	}()
+}
+
+func (s *Server) dispatch(method string, params json.RawMessage) (any, *Error) {
+	switch method {
+	case MethodInitialize:
+		var req InitializeParams
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			AgentInfo:       s.agentInfo,
+			Capabilities:    Capabilities{Approvals: true, Diffs: true, Cancel: true},
+		}, nil
+
+	case MethodNewSession:
+		return s.newSession()
+
+	case MethodListSessions:
+		s.mu.Lock()
+		infos := make([]SessionInfo, 0, len(s.sessions))
+		for _, sess := range s.sessions {
+			infos = append(infos, sess.info())
+		}
+		s.mu.Unlock()
+		sort.Slice(infos, func(i, j int) bool { return infos[i].CreatedAt.Before(infos[j].CreatedAt) })
+		return ListSessionsResult{Sessions: infos}, nil
+
+	case MethodPrompt:
+		var req PromptParams
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(req.Content) == "" {
+			return nil, newError(CodeInvalidParams, "content is required")
+		}
+		sess, err := s.lookup(req.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		reason, promptErr := sess.prompt(s.context(), req.Content)
+		if promptErr != nil {
+			return nil, sessionError(promptErr)
+		}
+		return PromptResult{StopReason: reason}, nil
+
+	case MethodCancel:
+		var req SessionParams
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		sess, err := s.lookup(req.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if cancelErr := sess.cancelTurn(); cancelErr != nil {
+			return nil, sessionError(cancelErr)
+		}
+		return struct{}{}, nil
+
+	case MethodCloseSession:
+		var req SessionParams
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		sess, ok := s.sessions[req.SessionID]
+		delete(s.sessions, req.SessionID)
+		s.mu.Unlock()
+		if !ok {
+			return nil, newError(CodeSessionNotFound, "session not found: %s", req.SessionID)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := sess.Shutdown(ctx); err != nil {
+			return nil, newError(CodeInternalError, "failed to shut down session: %v", err)
+		}
+		return struct{}{}, nil
+
+	default:
+		return nil, newError(CodeMethodNotFound, "method not found: %s", method)
+	}
+}
+
+func (s *Server) newSession() (any, *Error) {
+	s.mu.Lock()
+	if len(s.sessions) >= s.maxSessions {
+		s.mu.Unlock()
+		return nil, newError(CodeInternalError, "session limit of %d reached", s.maxSessions)
+	}
+	baseCtx := s.baseCtx
+	s.mu.Unlock()
+
+	id, err := newSessionID()
+	if err != nil {
+		return nil, newError(CodeInternalError, "%v", err)
+	}
+
+	// Only the session stops its agent (see server.AgentSession), so the
+	// server's context is not passed on as is
+	sessCtx, cancel := context.WithCancel(context.WithoutCancel(baseCtx))
+	ag, err := s.factory(sessCtx)
+	if err != nil {
+		cancel()
+		return nil, newError(CodeInternalError, "failed to create agent: %v", err)
+	}
+	if err := ag.Start(sessCtx); err != nil {
+		cancel()
+		return nil, newError(CodeInternalError, "failed to start agent: %v", err)
+	}
+
+	sess := newSession(sessCtx, id, ag, cancel, s)
+	go sess.pump()
+
+	s.mu.Lock()
+	s.sessions[id] = sess
+	s.mu.Unlock()
+
+	return NewSessionResult{SessionID: id}, nil
+}
+
+// lookup resolves a session by ID.
+func (s *Server) lookup(id string) (*session, *Error) {
+	s.mu.Lock()
+	sess, ok := s.sessions[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, newError(CodeSessionNotFound, "session not found: %s", id)
+	}
+	return sess, nil
+}
+
+func (s *Server) context() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.baseCtx
+}
+
+// call sends a request to the client and decodes its result into result.
+func (s *Server) call(ctx context.Context, method string, params, result any) error {
+	s.callMu.Lock()
+	s.lastID++
+	id := strconv.FormatInt(s.lastID, 10)
+	response := make(chan *message, 1)
+	s.pending[id] = response
+	s.callMu.Unlock()
+
+	defer func() {
+		s.callMu.Lock()
+		delete(s.pending, id)
+		s.callMu.Unlock()
+	}()
+
+	if err := s.write(outgoing{ID: json.RawMessage(id), Method: method, Params: params}); err != nil {
+		return err
+	}
+
+	select {
+	case msg := <-response:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		return json.Unmarshal(msg.Result, result)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resolve delivers a response to the call waiting for it.
+func (s *Server) resolve(msg *message) {
+	s.callMu.Lock()
+	response, ok := s.pending[string(bytes.TrimSpace(msg.ID))]
+	s.callMu.Unlock()
+	if ok {
+		response <- msg
+	}
+}
+
+// notify sends a notification to the client.
+func (s *Server) notify(method string, params any) error {
+	return s.write(outgoing{Method: method, Params: params})
+}
+
+func (s *Server) write(msg outgoing) error {
+	msg.JSONRPC = "2.0"
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if s.enc == nil {
+		return errors.New("connection is not open")
+	}
+	return s.enc.Encode(msg)
+}
+
+func decodeParams(params json.RawMessage, v any) *Error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return newError(CodeInvalidParams, "invalid params: %v", err)
+	}
+	return nil
+}
+
+func sessionError(err error) *Error {
+	switch {
+	case errors.Is(err, server.ErrSessionBusy):
+		return newError(CodeSessionBusy, "%v", err)
+	case errors.Is(err, server.ErrSessionClosed):
+		return newError(CodeSessionNotFound, "%v", err)
+	default:
+		return newError(CodeInternalError, "%v", err)
+	}
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
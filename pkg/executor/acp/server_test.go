@@ -0,0 +1,351 @@
+package acp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// echoAgent replies to each message with its content. A message of "edit"
+// asks for approval of a file edit first and reports the decision, and a
+// message of "wait" holds the turn open until it is cancelled.
+type echoAgent struct {
+	channels *types.AgentChannels
+}
+
+func newEchoAgent() *echoAgent {
+	return &echoAgent{channels: types.NewAgentChannels(16)}
+}
+
+func (a *echoAgent) Start(ctx context.Context) error {
+	go func() {
+		defer a.channels.Close()
+		waiting := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-a.channels.Shutdown:
+				return
+			case input := <-a.channels.Input:
+				if input.IsCancel() && waiting {
+					waiting = false
+					a.channels.Event <- types.NewTurnEndEvent()
+					continue
+				}
+				if !input.IsUserInput() {
+					continue
+				}
+				switch input.Content {
+				case "edit":
+					a.channels.Event <- types.NewToolApprovalRequestEvent("approval-1", "apply_diff", map[string]any{"path": "main.go"}, &tools.ToolPreview{
+						Type:     tools.PreviewTypeDiff,
+						Title:    "Edit main.go",
+						Content:  "-old\n+new",
+						Metadata: map[string]any{"file_path": "main.go"},
+					})
+				case "wait":
+					waiting = true
+				default:
+					a.channels.Event <- types.NewMessageContentEvent("echo: " + input.Content)
+					a.channels.Event <- types.NewTurnEndEvent()
+				}
+			case approval := <-a.channels.Approval:
+				a.channels.Event <- types.NewMessageContentEvent(approval.ApprovalID + ":" + string(approval.Decision))
+				a.channels.Event <- types.NewTurnEndEvent()
+			}
+		}
+	}()
+	return nil
+}
+
+func (a *echoAgent) Shutdown(ctx context.Context) error {
+	close(a.channels.Shutdown)
+	select {
+	case <-a.channels.Done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *echoAgent) GetChannels() *types.AgentChannels       { return a.channels }
+func (a *echoAgent) GetTool(string) any                      { return nil }
+func (a *echoAgent) GetTools() []any                         { return nil }
+func (a *echoAgent) GetContextInfo() *agent.ContextInfo      { return &agent.ContextInfo{} }
+func (a *echoAgent) GetMessages() []*types.Message           { return nil }
+func (a *echoAgent) GetSystemPrompt() string                 { return "" }
+func (a *echoAgent) SetProvider(provider llm.Provider) error { return nil }
+
+func echoFactory(context.Context) (agent.Agent, error) {
+	return newEchoAgent(), nil
+}
+
+// testClient drives a Server over in-memory pipes.
+type testClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *bufio.Scanner
+	nextID int
+	done   chan error
+}
+
+func newTestClient(t *testing.T, opts ...Option) *testClient {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	c := &testClient{t: t, in: inW, out: bufio.NewScanner(outR), done: make(chan error, 1)}
+	go func() {
+		c.done <- NewServer(echoFactory, opts...).Serve(context.Background(), inR, outW)
+		outW.Close()
+	}()
+	t.Cleanup(func() {
+		inW.Close()
+		go func() { _, _ = io.Copy(io.Discard, outR) }()
+		select {
+		case err := <-c.done:
+			if err != nil {
+				t.Errorf("Serve returned %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("Serve did not return after stdin closed")
+		}
+	})
+	return c
+}
+
+func (c *testClient) send(v any) {
+	c.t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := c.in.Write(append(data, '\n')); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// request sends a request and returns its ID.
+func (c *testClient) request(method string, params any) int {
+	c.t.Helper()
+	c.nextID++
+	c.send(map[string]any{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params})
+	return c.nextID
+}
+
+// read returns the next message from the server.
+func (c *testClient) read() message {
+	c.t.Helper()
+	lines := make(chan bool, 1)
+	go func() { lines <- c.out.Scan() }()
+	select {
+	case ok := <-lines:
+		if !ok {
+			c.t.Fatalf("connection closed: %v", c.out.Err())
+		}
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timed out waiting for a message")
+	}
+
+	var msg message
+	if err := json.Unmarshal(c.out.Bytes(), &msg); err != nil {
+		c.t.Fatalf("invalid message %s: %v", c.out.Bytes(), err)
+	}
+	return msg
+}
+
+// result reads messages until the response to id arrives, returning the
+// notifications read on the way, and decodes its result into v.
+func (c *testClient) result(id int, v any) []message {
+	c.t.Helper()
+	var notifications []message
+	for {
+		msg := c.read()
+		if msg.Method != "" {
+			notifications = append(notifications, msg)
+			continue
+		}
+		if string(msg.ID) != fmt.Sprint(id) {
+			c.t.Fatalf("unexpected response %s while waiting for %d", msg.ID, id)
+		}
+		if msg.Error != nil {
+			c.t.Fatalf("request %d failed: %v", id, msg.Error)
+		}
+		if v != nil {
+			if err := json.Unmarshal(msg.Result, v); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+		return notifications
+	}
+}
+
+func (c *testClient) newSession() string {
+	c.t.Helper()
+	var result NewSessionResult
+	c.result(c.request(MethodNewSession, nil), &result)
+	return result.SessionID
+}
+
+func TestServer_Initialize(t *testing.T) {
+	c := newTestClient(t, WithAgentInfo("forge", "1.2.3"))
+
+	var result InitializeResult
+	c.result(c.request(MethodInitialize, InitializeParams{ProtocolVersion: ProtocolVersion}), &result)
+	if result.ProtocolVersion != ProtocolVersion || result.AgentInfo.Version != "1.2.3" || !result.Capabilities.Approvals {
+		t.Errorf("unexpected initialize result: %+v", result)
+	}
+}
+
+func TestServer_PromptStreamsUpdates(t *testing.T) {
+	c := newTestClient(t)
+	id := c.newSession()
+
+	var result PromptResult
+	notifications := c.result(c.request(MethodPrompt, PromptParams{SessionID: id, Content: "hello"}), &result)
+	if result.StopReason != StopReasonEndTurn {
+		t.Errorf("expected end_turn, got %q", result.StopReason)
+	}
+
+	var contents []string
+	for _, n := range notifications {
+		var update UpdateParams
+		if err := json.Unmarshal(n.Params, &update); err != nil {
+			t.Fatal(err)
+		}
+		if n.Method != MethodUpdate || update.SessionID != id {
+			t.Errorf("unexpected notification %s for %s", n.Method, update.SessionID)
+		}
+		contents = append(contents, string(update.Event.Type)+":"+update.Event.Content)
+	}
+	if want := "message_content:echo: hello,turn_end:"; strings.Join(contents, ",") != want {
+		t.Errorf("expected updates %s, got %v", want, contents)
+	}
+}
+
+func TestServer_RequestPermission(t *testing.T) {
+	for _, approved := range []bool{true, false} {
+		t.Run(fmt.Sprint(approved), func(t *testing.T) {
+			c := newTestClient(t)
+			id := c.newSession()
+			promptID := c.request(MethodPrompt, PromptParams{SessionID: id, Content: "edit"})
+
+			msg := c.read()
+			if msg.Method != MethodRequestPermission {
+				t.Fatalf("expected a permission request, got %+v", msg)
+			}
+			var params PermissionParams
+			if err := json.Unmarshal(msg.Params, &params); err != nil {
+				t.Fatal(err)
+			}
+			if params.ApprovalID != "approval-1" || params.Preview == nil || params.Preview.Type != "diff" || params.Preview.Path != "main.go" || params.Preview.Content != "-old\n+new" {
+				t.Errorf("unexpected permission request: %+v (preview %+v)", params, params.Preview)
+			}
+			c.send(map[string]any{"jsonrpc": "2.0", "id": json.RawMessage(msg.ID), "result": PermissionResult{Approved: approved}})
+
+			notifications := c.result(promptID, nil)
+			var update UpdateParams
+			if err := json.Unmarshal(notifications[0].Params, &update); err != nil {
+				t.Fatal(err)
+			}
+			want := "approval-1:" + string(types.ApprovalRejected)
+			if approved {
+				want = "approval-1:" + string(types.ApprovalGranted)
+			}
+			if update.Event.Content != want {
+				t.Errorf("expected the agent to receive %s, got %s", want, update.Event.Content)
+			}
+		})
+	}
+}
+
+func TestServer_CancelAndBusy(t *testing.T) {
+	c := newTestClient(t)
+	id := c.newSession()
+	promptID := c.request(MethodPrompt, PromptParams{SessionID: id, Content: "wait"})
+
+	// Wait for the session to report busy so the second prompt is rejected
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var list ListSessionsResult
+		c.result(c.request(MethodListSessions, nil), &list)
+		if len(list.Sessions) == 1 && list.Sessions[0].Busy {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("session never became busy")
+		}
+	}
+
+	busyID := c.request(MethodPrompt, PromptParams{SessionID: id, Content: "again"})
+	if msg := c.read(); string(msg.ID) != fmt.Sprint(busyID) || msg.Error == nil || msg.Error.Code != CodeSessionBusy {
+		t.Fatalf("expected a session busy error, got %+v", msg)
+	}
+
+	// Cancel as a notification
+	c.send(map[string]any{"jsonrpc": "2.0", "method": MethodCancel, "params": SessionParams{SessionID: id}})
+	var result PromptResult
+	c.result(promptID, &result)
+	if result.StopReason != StopReasonCancelled {
+		t.Errorf("expected cancelled, got %q", result.StopReason)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	c := newTestClient(t)
+
+	tests := []struct {
+		name   string
+		send   func() int
+		wantID string
+		code   int
+	}{
+		{"unknown method", func() int { return c.request("session/unknown", nil) }, "", CodeMethodNotFound},
+		{"unknown session", func() int { return c.request(MethodPrompt, PromptParams{SessionID: "nope", Content: "hi"}) }, "", CodeSessionNotFound},
+		{"empty prompt", func() int { return c.request(MethodPrompt, PromptParams{SessionID: "nope"}) }, "", CodeInvalidParams},
+		{"bad params", func() int { return c.request(MethodPrompt, "text") }, "", CodeInvalidParams},
+		{"parse error", func() int { _, _ = c.in.Write([]byte("{not json\n")); return 0 }, "null", CodeParseError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := tt.send()
+			msg := c.read()
+			wantID := tt.wantID
+			if wantID == "" {
+				wantID = fmt.Sprint(id)
+			}
+			if string(msg.ID) != wantID || msg.Error == nil || msg.Error.Code != tt.code {
+				t.Errorf("expected error %d for request %s, got id %s error %+v", tt.code, wantID, msg.ID, msg.Error)
+			}
+		})
+	}
+}
+
+func TestServer_CloseSession(t *testing.T) {
+	c := newTestClient(t, WithMaxSessions(1))
+	id := c.newSession()
+
+	limitID := c.request(MethodNewSession, nil)
+	if msg := c.read(); string(msg.ID) != fmt.Sprint(limitID) || msg.Error == nil {
+		t.Fatalf("expected the session limit to be enforced, got %+v", msg)
+	}
+
+	c.result(c.request(MethodCloseSession, SessionParams{SessionID: id}), nil)
+	var list ListSessionsResult
+	c.result(c.request(MethodListSessions, nil), &list)
+	if len(list.Sessions) != 0 {
+		t.Errorf("expected no sessions after close, got %+v", list.Sessions)
+	}
+}
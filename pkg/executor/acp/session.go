@@ -0,0 +1,125 @@
+package acp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/executor/server"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// session adds prompt tracking and client notifications to an agent session.
+type session struct {
+	*server.AgentSession
+	ctx  context.Context
+	conn *Server
+
+	mu        sync.Mutex
+	cancelled bool
+	seq       int64
+	turn      chan string // receives the stop reason of the running prompt
+}
+
+func newSession(ctx context.Context, id string, ag agent.Agent, cancel context.CancelFunc, conn *Server) *session {
+	return &session{
+		AgentSession: server.NewAgentSession(id, ag, cancel),
+		ctx:          ctx,
+		conn:         conn,
+	}
+}
+
+// pump sends each of the agent's events to the client and ends prompts when
+// their turn ends, until the agent shuts down.
+func (s *session) pump() {
+	s.Run(s.forward, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.endTurn(StopReasonCancelled)
+	})
+}
+
+func (s *session) forward(event *types.AgentEvent) {
+	// Approvals are requests the client answers rather than updates
+	if event.Type == types.EventTypeToolApprovalRequest {
+		go s.requestPermission(event)
+		return
+	}
+
+	s.mu.Lock()
+	s.seq++
+	seq := s.seq
+	s.mu.Unlock()
+	_ = s.conn.notify(MethodUpdate, UpdateParams{SessionID: s.ID(), Event: server.NewEvent(seq, event)})
+
+	if event.Type == types.EventTypeTurnEnd {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.cancelled {
+			s.endTurn(StopReasonCancelled)
+		} else {
+			s.endTurn(StopReasonEndTurn)
+		}
+	}
+}
+
+// endTurn ends the running prompt with reason. The caller holds s.mu.
+func (s *session) endTurn(reason string) {
+	s.EndTurn()
+	if s.turn != nil {
+		s.turn <- reason
+		s.turn = nil
+	}
+}
+
+// requestPermission asks the client to approve a tool call and relays its
+// answer. Failed requests reject the call.
+func (s *session) requestPermission(event *types.AgentEvent) {
+	var result PermissionResult
+	err := s.conn.call(s.ctx, MethodRequestPermission, PermissionParams{
+		SessionID:  s.ID(),
+		ApprovalID: event.ApprovalID,
+		ToolName:   event.ToolName,
+		ToolInput:  event.ToolInput,
+		Preview:    newPreview(event.Preview),
+	}, &result)
+	_ = s.RespondApproval(event.ApprovalID, err == nil && result.Approved)
+}
+
+// prompt sends content to the agent and waits for its turn to end. Only one
+// prompt may run at a time because the agent processes inputs concurrently.
+func (s *session) prompt(ctx context.Context, content string) (string, error) {
+	turn := make(chan string, 1)
+
+	// Hold s.mu so the turn cannot end before it is tracked
+	s.mu.Lock()
+	if err := s.Send(content); err != nil {
+		s.mu.Unlock()
+		return "", err
+	}
+	s.cancelled = false
+	s.turn = turn
+	s.mu.Unlock()
+
+	select {
+	case reason := <-turn:
+		return reason, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (s *session) cancelTurn() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.CancelTurn(); err != nil {
+		return err
+	}
+	s.cancelled = true
+	return nil
+}
+
+func (s *session) info() SessionInfo {
+	return SessionInfo{ID: s.ID(), CreatedAt: s.CreatedAt(), Busy: s.Busy()}
+}
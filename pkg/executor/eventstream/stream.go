@@ -0,0 +1,299 @@
+// Package eventstream streams a running agent's events to external clients
+// over WebSocket, so dashboards, pair-programming observers and recording
+// services can watch a TUI or headless session live.
+//
+// A Stream is attached to agents with agent.WithEventObserver and served on
+// its own address. Clients connect to
+//
+//	GET /v1/events    WebSocket; one JSON message per event
+//	GET /healthz      Liveness check
+//
+// Each message is a server.Event, the same form 'forge serve' sends over SSE.
+// Clients must send "Authorization: Bearer <token>"; browsers' WebSocket
+// cannot set headers, so the token is also accepted as a "token" query
+// parameter. A client that connects late, or reconnects with ?after=<seq>,
+// first receives the buffered events after that sequence number.
+package eventstream
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/executor/server"
+	"github.com/entrhq/forge/pkg/types"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	defaultHistory = 1000
+
+	// subscriberBuffer is the number of events a slow client may fall
+	// behind before it is disconnected. It can reconnect with ?after= to
+	// catch up.
+	subscriberBuffer = 256
+
+	// writeTimeout bounds sending one event to a client
+	writeTimeout = 10 * time.Second
+)
+
+// Stream fans an agent's events out to WebSocket clients. It implements
+// agent.EventObserver and never blocks the agent: clients that cannot keep
+// up are disconnected.
+type Stream struct {
+	token      string
+	maxHistory int
+
+	mu          sync.Mutex
+	seq         int64
+	history     []server.Event
+	subscribers map[chan server.Event]struct{}
+	closed      bool
+	httpSrv     *http.Server
+	clients     sync.WaitGroup
+}
+
+// Option configures a Stream.
+type Option func(*Stream)
+
+// WithHistory sets how many events are kept for clients that connect late
+// or reconnect (default 1000).
+func WithHistory(n int) Option {
+	return func(s *Stream) {
+		if n > 0 {
+			s.maxHistory = n
+		}
+	}
+}
+
+// New creates a stream whose clients must authenticate with token.
+func New(token string, opts ...Option) (*Stream, error) {
+	if token == "" {
+		return nil, errors.New("an event stream requires a token")
+	}
+	s := &Stream{
+		token:       token,
+		maxHistory:  defaultHistory,
+		subscribers: make(map[chan server.Event]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// ObserveEvent publishes an agent event to every connected client.
+func (s *Stream) ObserveEvent(event *types.AgentEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	s.seq++
+	wire := server.NewEvent(s.seq, event)
+	if !event.Timestamp.IsZero() {
+		wire.Time = event.Timestamp
+	}
+	s.history = append(s.history, wire)
+	if len(s.history) > s.maxHistory {
+		s.history = s.history[len(s.history)-s.maxHistory:]
+	}
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- wire:
+		default:
+			// Drop clients that cannot keep up rather than stalling the agent
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Listen starts serving the stream on addr in the background and returns
+// the address it listens on, which tells the port when addr's is 0.
+func (s *Stream) Listen(addr string) (net.Addr, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	httpSrv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.mu.Lock()
+	s.httpSrv = httpSrv
+	s.mu.Unlock()
+
+	go func() {
+		_ = httpSrv.Serve(listener)
+	}()
+	return listener.Addr(), nil
+}
+
+// Close ends every client's stream once it has been sent the events already
+// published, then stops the server. It waits for clients until ctx is done.
+func (s *Stream) Close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	for ch := range s.subscribers {
+		close(ch)
+	}
+	s.subscribers = nil
+	httpSrv := s.httpSrv
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.clients.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if httpSrv != nil {
+		// WebSocket connections are hijacked, so Shutdown does not wait for them
+		if shutdownErr := httpSrv.Shutdown(ctx); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+	return err
+}
+
+// Handler returns the HTTP handler serving the stream.
+func (s *Stream) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok"}` + "\n"))
+	})
+	// Any origin may connect; the token is what authorizes a client
+	mux.Handle("GET /v1/events", websocket.Server{Handler: s.serveClient})
+	return s.authenticate(mux)
+}
+
+// authenticate enforces the token on every route except the health check.
+func (s *Stream) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if provided == "" {
+			provided = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(s.token)) != 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "missing or invalid token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveClient sends a client the buffered events it asked for, then each
+// new event until the stream closes or the client falls behind or leaves.
+func (s *Stream) serveClient(conn *websocket.Conn) {
+	var afterSeq int64
+	if after := conn.Request().URL.Query().Get("after"); after != "" {
+		if seq, err := strconv.ParseInt(after, 10, 64); err == nil {
+			afterSeq = seq
+		}
+	}
+
+	backlog, events, ok := s.subscribe(afterSeq)
+	if !ok {
+		_ = conn.Close()
+		return
+	}
+	defer func() {
+		s.unsubscribe(events)
+		_ = conn.Close()
+		s.clients.Done()
+	}()
+
+	// Clients only listen; a read ending means the client went away
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		var discard []byte
+		for websocket.Message.Receive(conn, &discard) == nil {
+		}
+	}()
+
+	for _, event := range backlog {
+		if !send(conn, event) {
+			return
+		}
+	}
+	for {
+		select {
+		case <-gone:
+			return
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			if !send(conn, event) {
+				return
+			}
+		}
+	}
+}
+
+// send writes one event to conn, reporting whether it succeeded
+func send(conn *websocket.Conn, event server.Event) bool {
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return false
+	}
+	return websocket.JSON.Send(conn, event) == nil
+}
+
+// subscribe returns the buffered events after afterSeq and a channel of new
+// events, and counts the client for Close. It reports false once the
+// stream is closed.
+func (s *Stream) subscribe(afterSeq int64) ([]server.Event, chan server.Event, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, nil, false
+	}
+
+	var backlog []server.Event
+	for _, event := range s.history {
+		if event.Seq > afterSeq {
+			backlog = append(backlog, event)
+		}
+	}
+
+	ch := make(chan server.Event, subscriberBuffer)
+	s.subscribers[ch] = struct{}{}
+	s.clients.Add(1)
+	return backlog, ch, true
+}
+
+func (s *Stream) unsubscribe(ch chan server.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[ch]; ok {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
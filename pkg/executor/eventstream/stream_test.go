@@ -0,0 +1,138 @@
+package eventstream
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/executor/server"
+	"github.com/entrhq/forge/pkg/types"
+	"golang.org/x/net/websocket"
+)
+
+func newTestStream(t *testing.T, opts ...Option) (*Stream, *httptest.Server) {
+	t.Helper()
+	stream, err := New("secret", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(stream.Handler())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = stream.Close(ctx)
+		ts.Close()
+	})
+	return stream, ts
+}
+
+// dial connects to the stream's event endpoint with query, e.g. "token=secret"
+func dial(t *testing.T, ts *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/v1/events?" + query
+	conn, err := websocket.Dial(url, "", ts.URL)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func receive(t *testing.T, conn *websocket.Conn) server.Event {
+	t.Helper()
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var event server.Event
+	if err := websocket.JSON.Receive(conn, &event); err != nil {
+		t.Fatalf("receive failed: %v", err)
+	}
+	return event
+}
+
+func TestNew_RequiresToken(t *testing.T) {
+	if _, err := New(""); err == nil {
+		t.Error("New() without a token should fail")
+	}
+}
+
+func TestStream_RejectsBadToken(t *testing.T) {
+	_, ts := newTestStream(t)
+
+	for _, query := range []string{"", "token=wrong"} {
+		resp, err := http.Get(ts.URL + "/v1/events?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("query %q: status = %d, want 401", query, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("healthz status = %d, want 200 without a token", resp.StatusCode)
+	}
+}
+
+func TestStream_BacklogAndLiveEvents(t *testing.T) {
+	stream, ts := newTestStream(t)
+
+	stream.ObserveEvent(types.NewMessageContentEvent("before"))
+	stream.ObserveEvent(types.NewToolCallEvent("call-1", "read_file", map[string]any{"path": "a.go"}))
+
+	conn := dial(t, ts, "token=secret")
+	if event := receive(t, conn); event.Seq != 1 || event.Content != "before" {
+		t.Errorf("first event = %+v, want the buffered message", event)
+	}
+	if event := receive(t, conn); event.Seq != 2 || event.ToolName != "read_file" {
+		t.Errorf("second event = %+v, want the buffered tool call", event)
+	}
+
+	stream.ObserveEvent(types.NewTurnEndEvent())
+	if event := receive(t, conn); event.Seq != 3 || event.Type != types.EventTypeTurnEnd {
+		t.Errorf("live event = %+v, want the turn end", event)
+	}
+
+	// A reconnecting client only gets what it missed
+	resumed := dial(t, ts, "token=secret&after=2")
+	if event := receive(t, resumed); event.Seq != 3 {
+		t.Errorf("resumed client got seq %d, want 3", event.Seq)
+	}
+}
+
+func TestStream_CloseEndsClients(t *testing.T) {
+	stream, ts := newTestStream(t, WithHistory(1))
+
+	stream.ObserveEvent(types.NewMessageContentEvent("dropped"))
+	stream.ObserveEvent(types.NewMessageContentEvent("kept"))
+
+	conn := dial(t, ts, "token=secret")
+	if event := receive(t, conn); event.Content != "kept" {
+		t.Errorf("expected only the last event buffered, got %+v", event)
+	}
+
+	stream.ObserveEvent(types.NewMessageContentEvent("last"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := stream.Close(ctx); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	// Events published before Close are still delivered, then the stream ends
+	if event := receive(t, conn); event.Content != "last" {
+		t.Errorf("expected the last event before the stream ended, got %+v", event)
+	}
+	var event server.Event
+	if err := websocket.JSON.Receive(conn, &event); err == nil {
+		t.Errorf("expected the stream to end, got %+v", event)
+	}
+}
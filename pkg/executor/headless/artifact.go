@@ -7,6 +7,9 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/entrhq/forge/pkg/agent/todo"
+	"github.com/entrhq/forge/pkg/bootstrap"
 )
 
 const (
@@ -14,6 +17,12 @@ const (
 	statusIconFail = "❌"
 )
 
+// changesPatchName is the artifact holding the run's changes as a unified diff
+const changesPatchName = "changes.patch"
+
+// gateOutputDirName is the artifacts subdirectory quality gate output is saved to
+const gateOutputDirName = "quality-gates"
+
 // ArtifactWriter handles writing execution artifacts
 type ArtifactWriter struct {
 	outputDir string
@@ -63,6 +72,79 @@ func (w *ArtifactWriter) WriteAll(summary *ExecutionSummary) error {
 	return nil
 }
 
+// Screenshots returns the browser screenshots saved during the execution,
+// relative to the output directory and sorted by name
+func (w *ArtifactWriter) Screenshots() []string {
+	matches, err := filepath.Glob(filepath.Join(w.outputDir, screenshotsDirName, "*.png"))
+	if err != nil {
+		return nil
+	}
+
+	screenshots := make([]string, 0, len(matches))
+	for _, match := range matches {
+		relPath, relErr := filepath.Rel(w.outputDir, match)
+		if relErr != nil {
+			continue
+		}
+		screenshots = append(screenshots, filepath.ToSlash(relPath))
+	}
+	return screenshots
+}
+
+// WriteChangesPatch writes the run's changes as a unified diff to
+// changes.patch and returns the artifact's name
+func (w *ArtifactWriter) WriteChangesPatch(patch string) (string, error) {
+	if err := os.MkdirAll(w.outputDir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	path := filepath.Join(w.outputDir, changesPatchName)
+	if err := os.WriteFile(path, []byte(patch), 0600); err != nil {
+		return "", fmt.Errorf("failed to write changes patch: %w", err)
+	}
+
+	return changesPatchName, nil
+}
+
+// WriteGateOutput writes one stream of a quality gate's output, from the
+// given RunAll attempt, under quality-gates/ and returns the artifact's path
+// relative to the output directory
+func (w *ArtifactWriter) WriteGateOutput(attempt, gateIndex int, gateName, stream, content string) (string, error) {
+	dir := filepath.Join(gateOutputDirName, fmt.Sprintf("attempt-%d", attempt))
+	if err := os.MkdirAll(filepath.Join(w.outputDir, dir), 0750); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	name := filepath.Join(dir, fmt.Sprintf("%02d-%s.%s.log", gateIndex+1, gateFileName(gateName), stream))
+	if err := os.WriteFile(filepath.Join(w.outputDir, name), []byte(content), 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s of quality gate '%s': %w", stream, gateName, err)
+	}
+
+	return filepath.ToSlash(name), nil
+}
+
+// gateFileName returns a gate name reduced to lowercase letters, digits and
+// dashes, for artifact file names
+func gateFileName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "gate"
+	}
+	return b.String()
+}
+
 // WriteExecutionJSON writes the full execution summary as JSON
 func (w *ArtifactWriter) WriteExecutionJSON(summary *ExecutionSummary) error {
 	path := filepath.Join(w.outputDir, "execution.json")
@@ -101,6 +183,19 @@ func (w *ArtifactWriter) WriteSummaryMarkdown(summary *ExecutionSummary) error {
 		md.WriteString("✅ **Success**\n\n")
 	}
 
+	// Project setup run before the agent
+	if len(summary.Bootstrap) > 0 {
+		md.WriteString("## Project Setup\n\n")
+		for _, result := range summary.Bootstrap {
+			if result.Failed() {
+				fmt.Fprintf(&md, "%s `%s`: %s\n", statusIconFail, result.Step.Command, result.Error)
+				continue
+			}
+			fmt.Fprintf(&md, "%s `%s` (%s)\n", statusIconPass, result.Step.Command, result.Duration)
+		}
+		md.WriteString("\n")
+	}
+
 	// Files Modified
 	if len(summary.FilesModified) > 0 {
 		md.WriteString("## Files Modified\n\n")
@@ -110,6 +205,25 @@ func (w *ArtifactWriter) WriteSummaryMarkdown(summary *ExecutionSummary) error {
 		md.WriteString("\n")
 	}
 
+	// Changes, as git sees them
+	if summary.Changes != nil {
+		w.writeChanges(&md, summary.Changes)
+	}
+
+	// What a dry run would have done
+	if summary.DryRun != nil {
+		w.writeDryRun(&md, summary.DryRun)
+	}
+
+	// Constraint Violations
+	if len(summary.Violations) > 0 {
+		md.WriteString("## Constraint Violations\n\n")
+		for _, violation := range summary.Violations {
+			fmt.Fprintf(&md, "- `%s` (%s): %s\n", violation.Tool, violation.Type, violation.Message)
+		}
+		md.WriteString("\n")
+	}
+
 	// Quality Gates
 	if summary.QualityGateResults != nil && len(summary.QualityGateResults.Results) > 0 {
 		md.WriteString("## Quality Gates\n\n")
@@ -123,18 +237,41 @@ func (w *ArtifactWriter) WriteSummaryMarkdown(summary *ExecutionSummary) error {
 		}
 	}
 
+	// Behavior Verification
+	if summary.BehaviorVerification != nil {
+		w.writeBehaviorVerification(&md, summary.BehaviorVerification)
+	}
+
 	// Pull Request
-	if summary.PRURL != "" {
+	if len(summary.StackedPRs) > 0 {
+		w.writeStackedPRs(&md, summary.StackedPRs)
+	} else if summary.PRURL != "" {
 		md.WriteString("## Pull Request\n\n")
 		fmt.Fprintf(&md, "✅ **Created:** %s\n\n", summary.PRURL)
 	}
 
+	// Plan
+	if len(summary.TodoList) > 0 {
+		w.writeTodoList(&md, summary.TodoList)
+	}
+
+	// Screenshots
+	if len(summary.Screenshots) > 0 {
+		md.WriteString("## Screenshots\n\n")
+		for _, screenshot := range summary.Screenshots {
+			fmt.Fprintf(&md, "![%s](%s)\n\n", screenshot, screenshot)
+		}
+	}
+
 	// Metrics
 	md.WriteString("## Metrics\n\n")
 	fmt.Fprintf(&md, "- **Files Modified:** %d\n", summary.Metrics.FilesModified)
 	fmt.Fprintf(&md, "- **Total Lines Added:** %d\n", summary.Metrics.TotalLinesAdded)
 	fmt.Fprintf(&md, "- **Total Lines Removed:** %d\n", summary.Metrics.TotalLinesRemoved)
 	fmt.Fprintf(&md, "- **Tokens Used:** %d\n", summary.Metrics.TokensUsed)
+	if summary.Metrics.CostUSD > 0 {
+		fmt.Fprintf(&md, "- **Estimated Cost:** $%.2f\n", summary.Metrics.CostUSD)
+	}
 	fmt.Fprintf(&md, "- **Iterations:** %d\n", summary.Metrics.Iterations)
 
 	// Write file
@@ -163,27 +300,68 @@ func (w *ArtifactWriter) WriteMetricsJSON(summary *ExecutionSummary) error {
 
 // ExecutionSummary contains a complete summary of headless execution
 type ExecutionSummary struct {
-	Task               string              `json:"task"`
-	Status             string              `json:"status"`
-	Error              string              `json:"error,omitempty"`
-	StartTime          time.Time           `json:"start_time"`
-	EndTime            time.Time           `json:"end_time"`
-	Duration           time.Duration       `json:"duration"`
-	FilesModified      []FileModification  `json:"files_modified"`
-	QualityGateResults *QualityGateResults `json:"quality_gate_results,omitempty"`
-	Metrics            ExecutionMetrics    `json:"metrics"`
-	GitInfo            *GitInfo            `json:"git_info,omitempty"`
-	PRURL              string              `json:"pr_url,omitempty"`
-	ToolCallCount      int                 `json:"tool_call_count"`
+	RunID                string                `json:"run_id,omitempty"`
+	Task                 string                `json:"task"`
+	Status               string                `json:"status"`
+	Error                string                `json:"error,omitempty"`
+	StartTime            time.Time             `json:"start_time"`
+	EndTime              time.Time             `json:"end_time"`
+	Duration             time.Duration         `json:"duration"`
+	FilesModified        []FileModification    `json:"files_modified"`
+	QualityGateResults   *QualityGateResults   `json:"quality_gate_results,omitempty"`
+	BehaviorVerification *BehaviorVerification `json:"behavior_verification,omitempty"`
+	Bootstrap            []bootstrap.Result    `json:"bootstrap,omitempty"`
+	Violations           []ViolationRecord     `json:"violations,omitempty"`
+	Metrics              ExecutionMetrics      `json:"metrics"`
+	GitInfo              *GitInfo              `json:"git_info,omitempty"`
+	PRURL                string                `json:"pr_url,omitempty"`
+	StackedPRs           []StackedPR           `json:"stacked_prs,omitempty"`
+	Screenshots          []string              `json:"screenshots,omitempty"`
+	TodoList             []todo.Item           `json:"todo_list,omitempty"`
+	ToolCallCount        int                   `json:"tool_call_count"`
+	Sampling             *SamplingConfig       `json:"sampling,omitempty"`
+	Changes              *ChangeSummary        `json:"changes,omitempty"`
+	DryRun               *DryRunSummary        `json:"dry_run,omitempty"`
+	ModelFallbacks       int                   `json:"model_fallbacks,omitempty"` // Times requests moved to the fallback model on a rate limit
+}
+
+// ChangeSummary describes everything the run changed in the workspace,
+// captured before it was committed
+type ChangeSummary struct {
+	Patch        string       `json:"patch"` // Unified diff artifact, relative to the artifacts directory
+	Files        []FileChange `json:"files"`
+	LinesAdded   int          `json:"lines_added"`
+	LinesRemoved int          `json:"lines_removed"`
+}
+
+// newChangeSummary totals the line counts of files
+func newChangeSummary(patchName string, files []FileChange) *ChangeSummary {
+	changes := &ChangeSummary{Patch: patchName, Files: files}
+	for _, file := range files {
+		changes.LinesAdded += file.LinesAdded
+		changes.LinesRemoved += file.LinesRemoved
+	}
+	return changes
 }
 
 // ExecutionMetrics contains execution metrics
 type ExecutionMetrics struct {
-	FilesModified     int `json:"files_modified"`
-	TotalLinesAdded   int `json:"total_lines_added"`
-	TotalLinesRemoved int `json:"total_lines_removed"`
-	TokensUsed        int `json:"tokens_used"`
-	Iterations        int `json:"iterations"`
+	FilesModified     int     `json:"files_modified"`
+	TotalLinesAdded   int     `json:"total_lines_added"`
+	TotalLinesRemoved int     `json:"total_lines_removed"`
+	TokensUsed        int     `json:"tokens_used"`
+	Iterations        int     `json:"iterations"`
+	CostUSD           float64 `json:"estimated_cost_usd,omitempty"` // From the model's price; unset when it is unknown
+}
+
+// add adds other's counts to the metrics, for runs made of several executions
+func (m *ExecutionMetrics) add(other ExecutionMetrics) {
+	m.FilesModified += other.FilesModified
+	m.TotalLinesAdded += other.TotalLinesAdded
+	m.TotalLinesRemoved += other.TotalLinesRemoved
+	m.TokensUsed += other.TokensUsed
+	m.Iterations += other.Iterations
+	m.CostUSD += other.CostUSD
 }
 
 // GitInfo contains git-related information
@@ -205,6 +383,129 @@ func (w *ArtifactWriter) writeQualityGateAttempts(md *strings.Builder, attempts
 	}
 }
 
+// writeStackedPRs writes the parts of a stacked change to markdown
+func (w *ArtifactWriter) writeStackedPRs(md *strings.Builder, parts []StackedPR) {
+	md.WriteString("## Stacked Pull Requests\n\n")
+	md.WriteString("| # | Group | Branch | Files | Lines | Pull request |\n")
+	md.WriteString("|---|-------|--------|-------|-------|--------------|\n")
+	for i, part := range parts {
+		prURL := part.PRURL
+		if prURL == "" {
+			prURL = "-"
+		}
+		fmt.Fprintf(md, "| %d | `%s` | `%s` | %d | %d | %s |\n", i+1, part.Group, part.Branch, len(part.Files), part.Lines, prURL)
+	}
+	md.WriteString("\n")
+
+	// Logical units declared by the agent carry a description
+	described := false
+	for _, part := range parts {
+		if part.Description != "" {
+			fmt.Fprintf(md, "- `%s`: %s\n", part.Group, part.Description)
+			described = true
+		}
+	}
+	if described {
+		md.WriteString("\n")
+	}
+}
+
+// writeChanges writes the files the run changed, with a link to the patch
+func (w *ArtifactWriter) writeChanges(md *strings.Builder, changes *ChangeSummary) {
+	md.WriteString("## Changes\n\n")
+	if len(changes.Files) == 0 {
+		md.WriteString("No changes\n\n")
+		return
+	}
+	fmt.Fprintf(md, "%d file(s), +%d/-%d lines. Full diff: [%s](%s)\n\n",
+		len(changes.Files), changes.LinesAdded, changes.LinesRemoved, changes.Patch, changes.Patch)
+	for _, file := range changes.Files {
+		if file.Binary {
+			fmt.Fprintf(md, "- `%s` (%s, binary)\n", file.Path, file.Status)
+			continue
+		}
+		fmt.Fprintf(md, "- `%s` (%s, +%d/-%d lines)\n", file.Path, file.Status, file.LinesAdded, file.LinesRemoved)
+	}
+	md.WriteString("\n")
+}
+
+// writeDryRun writes a dry run's gate predictions and commands to markdown
+func (w *ArtifactWriter) writeDryRun(md *strings.Builder, dryRun *DryRunSummary) {
+	md.WriteString("## Dry Run\n\n")
+	md.WriteString("Nothing was written to the workspace: the changes above are what the run would have made.\n\n")
+
+	if len(dryRun.Gates) > 0 {
+		md.WriteString("### Quality Gates\n\n")
+		for _, gate := range dryRun.Gates {
+			action := "would run"
+			if !gate.WouldRun {
+				action = "would be skipped"
+			}
+			fmt.Fprintf(md, "- **%s**", gate.Name)
+			if gate.Required {
+				md.WriteString(" (required)")
+			}
+			fmt.Fprintf(md, " %s: %s\n", action, gate.Reason)
+		}
+		md.WriteString("\n")
+	}
+
+	if len(dryRun.Commands) > 0 {
+		md.WriteString("### Commands\n\n")
+		for _, command := range dryRun.Commands {
+			fmt.Fprintf(md, "- `%s`\n", command)
+		}
+		md.WriteString("\n")
+	}
+
+	if len(dryRun.Refused) > 0 {
+		md.WriteString("### Refused Writes\n\n")
+		md.WriteString("A real run would refuse these writes too, so they are not in the changes above.\n\n")
+		for _, refusal := range dryRun.Refused {
+			fmt.Fprintf(md, "- %s\n", refusal)
+		}
+		md.WriteString("\n")
+	}
+}
+
+// writeTodoList writes the agent's plan as a markdown task list
+func (w *ArtifactWriter) writeTodoList(md *strings.Builder, items []todo.Item) {
+	done, _ := todo.Progress(items)
+	fmt.Fprintf(md, "## Plan\n\n%d of %d steps done\n\n", done, len(items))
+	for _, item := range items {
+		switch item.Status {
+		case todo.StatusCompleted:
+			fmt.Fprintf(md, "- [x] %s\n", item.Content)
+		case todo.StatusCancelled:
+			fmt.Fprintf(md, "- [ ] ~~%s~~ (cancelled)\n", item.Content)
+		case todo.StatusInProgress:
+			fmt.Fprintf(md, "- [ ] %s (in progress)\n", item.Content)
+		default:
+			fmt.Fprintf(md, "- [ ] %s\n", item.Content)
+		}
+	}
+	md.WriteString("\n")
+}
+
+// writeBehaviorVerification writes the no-behavior-change comparison to markdown
+func (w *ArtifactWriter) writeBehaviorVerification(md *strings.Builder, verification *BehaviorVerification) {
+	md.WriteString("## Behavior Verification\n\n")
+	if verification.Drifted {
+		md.WriteString("❌ **Behavior drift detected:** gate output differs from the baseline recorded before the task\n\n")
+	} else {
+		md.WriteString("✅ **No behavior change:** gate output matches the baseline recorded before the task\n\n")
+	}
+
+	for _, check := range verification.Checks {
+		if !check.Drifted {
+			fmt.Fprintf(md, "%s **%s** unchanged\n", statusIconPass, check.Gate)
+			continue
+		}
+		fmt.Fprintf(md, "%s **%s** drifted\n\n```diff\n%s\n```\n", statusIconFail, check.Gate, check.Diff)
+	}
+	md.WriteString("\n")
+}
+
 // writeQualityGateResults writes quality gate results to markdown
 func (w *ArtifactWriter) writeQualityGateResults(md *strings.Builder, results []QualityGateResult) {
 	for _, result := range results {
@@ -216,10 +517,29 @@ func (w *ArtifactWriter) writeQualityGateResults(md *strings.Builder, results []
 		if result.Required {
 			md.WriteString(" (required)")
 		}
+		if result.Skipped {
+			md.WriteString(" (skipped)")
+		} else if result.FailureKind == GateFailureInfrastructure {
+			md.WriteString(" (infrastructure error)")
+		}
+		if result.Runs > 1 {
+			fmt.Fprintf(md, " (%d runs", result.Runs)
+			if result.Flaky {
+				md.WriteString(", flaky")
+			}
+			md.WriteString(")")
+		}
 		md.WriteString("\n")
 		if result.Error != "" {
 			fmt.Fprintf(md, "   Error: %s\n", result.Error)
 		}
+		if !result.Passed {
+			for _, path := range []string{result.StdoutArtifact, result.StderrArtifact} {
+				if path != "" {
+					fmt.Fprintf(md, "   Output: [%s](%s)\n", path, path)
+				}
+			}
+		}
 	}
 	md.WriteString("\n")
 }
@@ -0,0 +1,75 @@
+package headless
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/bootstrap"
+)
+
+// BootstrapConfig runs project setup, such as installing dependencies and
+// warming the build cache, before the agent starts, so its first tool calls
+// do not fail on missing dependencies
+type BootstrapConfig struct {
+	Enabled  bool          `yaml:"enabled" json:"enabled"`
+	Commands []string      `yaml:"commands" json:"commands"` // Shell commands run instead of the setup detected from go.mod, package.json and requirements.txt
+	Timeout  time.Duration `yaml:"timeout" json:"timeout"`   // Limit for each command (default: 10m)
+	Required bool          `yaml:"required" json:"required"` // Fail the run when setup fails or a command is not allowed
+}
+
+// validate checks the timeout and commands.
+func (c BootstrapConfig) validate() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("bootstrap timeout must be non-negative")
+	}
+	for i, command := range c.Commands {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("bootstrap command %d is empty", i+1)
+		}
+	}
+	return nil
+}
+
+// runBootstrap runs the project's setup steps, each subject to the command
+// policy, and records them in the summary. Failures only fail the run with
+// bootstrap.required; otherwise the agent starts anyway and may fix the setup
+// itself.
+func (e *Executor) runBootstrap(ctx context.Context) error {
+	steps := bootstrap.Plan(e.config.WorkspaceDir, e.config.Bootstrap.Commands)
+	if len(steps) == 0 {
+		e.logger.Infof("▶ Project setup: nothing to do")
+		return nil
+	}
+
+	e.logger.Infof("▶ Running project setup: %s", bootstrap.Describe(steps))
+	for _, step := range steps {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var result bootstrap.Result
+		if err := e.constraintMgr.ValidateCommand(map[string]any{"command": step.Command}); err != nil {
+			result = bootstrap.Result{Step: step, Error: err.Error()}
+		} else {
+			result = bootstrap.Run(ctx, e.config.WorkspaceDir, step, e.config.Bootstrap.Timeout)
+		}
+		e.summary.Bootstrap = append(e.summary.Bootstrap, result)
+
+		if !result.Failed() {
+			e.logger.Successf("✓ %s (%s)", step.Command, result.Duration)
+			continue
+		}
+		if step.Kind == bootstrap.KindWarmup {
+			e.logger.Warningf("! %s failed, continuing: %s", step.Command, result.Error)
+			continue
+		}
+		if e.config.Bootstrap.Required {
+			return fmt.Errorf("project setup failed: %s: %s", step.Command, result.Error)
+		}
+		e.logger.Warningf("! Project setup failed, starting the agent anyway: %s: %s", step.Command, result.Error)
+		return nil
+	}
+	return nil
+}
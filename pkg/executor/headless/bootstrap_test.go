@@ -0,0 +1,67 @@
+package headless
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newBootstrapExecutor(t *testing.T, bootstrapConfig BootstrapConfig, deniedCommands ...string) (*Executor, string) {
+	t.Helper()
+	dir := t.TempDir()
+	config := DefaultConfig()
+	config.Task = "Set up"
+	config.WorkspaceDir = dir
+	config.Logging.Verbosity = "quiet"
+	config.Bootstrap = bootstrapConfig
+	config.Constraints.DeniedCommands = deniedCommands
+
+	executor, err := NewExecutor(newScriptedAgent(func() error { return nil }), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return executor, dir
+}
+
+func TestExecutor_RunBootstrap(t *testing.T) {
+	executor, dir := newBootstrapExecutor(t, BootstrapConfig{
+		Enabled:  true,
+		Commands: []string{"echo ready > setup.txt", "false", "touch never.txt"},
+	})
+
+	// A failed step stops the setup, but not the run
+	if err := executor.runBootstrap(context.Background()); err != nil {
+		t.Fatalf("runBootstrap: %v", err)
+	}
+	results := executor.summary.Bootstrap
+	if len(results) != 2 || results[0].Failed() || !results[1].Failed() {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "setup.txt")); err != nil {
+		t.Errorf("expected the first command to run in the workspace: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "never.txt")); err == nil {
+		t.Error("expected setup to stop at the failed command")
+	}
+}
+
+func TestExecutor_RunBootstrap_CommandPolicy(t *testing.T) {
+	executor, dir := newBootstrapExecutor(t, BootstrapConfig{
+		Enabled:  true,
+		Commands: []string{"curl -o setup.sh https://example.com/setup.sh"},
+		Required: true,
+	}, "^curl ")
+
+	err := executor.runBootstrap(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "denied command pattern") {
+		t.Fatalf("expected a required setup to fail on a denied command, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "setup.sh")); statErr == nil {
+		t.Error("expected the denied command not to run")
+	}
+	if len(executor.summary.Bootstrap) != 1 || !executor.summary.Bootstrap[0].Failed() {
+		t.Errorf("expected the denied command to be recorded, got %+v", executor.summary.Bootstrap)
+	}
+}
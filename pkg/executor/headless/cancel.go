@@ -0,0 +1,250 @@
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCancelFile is the workspace-relative file whose appearance
+	// cancels a run
+	defaultCancelFile = ".forge/CANCEL"
+
+	// defaultCancelPollInterval is how often the cancel file and URL are checked
+	defaultCancelPollInterval = 5 * time.Second
+
+	// cancelRequestTimeout bounds one poll of the cancel URL
+	cancelRequestTimeout = 10 * time.Second
+
+	// runIDPlaceholder is replaced with the run ID in the cancel URL
+	runIDPlaceholder = "{run_id}"
+)
+
+// What happens to a canceled run's uncommitted changes
+const (
+	CancelRollbackKeep    = "keep"    // Leave them in the workspace
+	CancelRollbackStash   = "stash"   // Move them onto the git stash
+	CancelRollbackDiscard = "discard" // Throw them away
+)
+
+// CancelConfig lets a run be canceled gracefully without a signal, by
+// writing a file into the workspace or from an HTTP endpoint the run polls.
+// A canceled run stops the agent, records its changes in the artifacts,
+// applies Rollback and ends with status "canceled".
+type CancelConfig struct {
+	File         string        `yaml:"file" json:"file"`                   // Writing this file cancels the run (default: .forge/CANCEL, relative to the workspace)
+	URL          string        `yaml:"url" json:"url"`                     // Polled with GET; {run_id} is replaced with the run ID
+	TokenEnv     string        `yaml:"token_env" json:"token_env"`         // Environment variable holding a bearer token for URL
+	PollInterval time.Duration `yaml:"poll_interval" json:"poll_interval"` // How often the file and URL are checked (default: 5s)
+	Rollback     string        `yaml:"rollback" json:"rollback"`           // keep, stash, or discard uncommitted changes (default: keep)
+}
+
+// validate checks the cancel settings.
+func (c CancelConfig) validate() error {
+	if c.PollInterval < 0 {
+		return fmt.Errorf("cancel poll_interval cannot be negative")
+	}
+	if c.URL != "" {
+		parsed, err := url.Parse(strings.ReplaceAll(c.URL, runIDPlaceholder, "run"))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid cancel url: %s (must be an http or https URL)", c.URL)
+		}
+	}
+	switch c.Rollback {
+	case "", CancelRollbackKeep, CancelRollbackStash, CancelRollbackDiscard:
+	default:
+		return fmt.Errorf("invalid cancel rollback: %s (must be '%s', '%s', or '%s')", c.Rollback, CancelRollbackKeep, CancelRollbackStash, CancelRollbackDiscard)
+	}
+	return nil
+}
+
+// cancelFile returns the cancel file, relative to the workspace unless absolute.
+func (c *Config) cancelFile() string {
+	file := c.Cancel.File
+	if file == "" {
+		file = defaultCancelFile
+	}
+	return c.workspacePath(file)
+}
+
+// cancelResponse is the body the cancel URL answers with. Any other answer,
+// and any failed request, leaves the run going.
+type cancelResponse struct {
+	Cancel bool   `json:"cancel"`
+	Reason string `json:"reason"`
+}
+
+// cancelWatcher polls for a cancel request. C delivers the request's reason
+// once.
+type cancelWatcher struct {
+	file     string
+	url      string
+	token    string
+	interval time.Duration
+	since    time.Time // A cancel file older than this is left over from an earlier run
+	client   *http.Client
+	logger   *Logger
+
+	c        chan string
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// newCancelWatcher starts polling for a cancel request for the run with
+// runID, until stop is called.
+func newCancelWatcher(config *Config, runID string, logger *Logger) *cancelWatcher {
+	interval := config.Cancel.PollInterval
+	if interval == 0 {
+		interval = defaultCancelPollInterval
+	}
+	w := &cancelWatcher{
+		file:     config.cancelFile(),
+		interval: interval,
+		since:    time.Now().Truncate(time.Second), // File systems may keep whole seconds
+		client:   &http.Client{Timeout: cancelRequestTimeout},
+		logger:   logger,
+		c:        make(chan string, 1),
+		done:     make(chan struct{}),
+	}
+	if config.Cancel.URL != "" {
+		w.url = strings.ReplaceAll(config.Cancel.URL, runIDPlaceholder, url.PathEscape(runID))
+		if config.Cancel.TokenEnv != "" {
+			w.token = os.Getenv(config.Cancel.TokenEnv)
+		}
+	}
+	go w.poll()
+	return w
+}
+
+// C returns the channel a cancel request's reason is delivered on.
+func (w *cancelWatcher) C() <-chan string {
+	return w.c
+}
+
+// stop ends polling.
+func (w *cancelWatcher) stop() {
+	w.stopOnce.Do(func() { close(w.done) })
+}
+
+func (w *cancelWatcher) poll() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		if reason, ok := w.check(); ok {
+			w.c <- reason
+			return
+		}
+	}
+}
+
+// check reports whether a cancel was requested, and why.
+func (w *cancelWatcher) check() (string, bool) {
+	if info, err := os.Stat(w.file); err == nil && !info.ModTime().Before(w.since) {
+		reason := "cancel file " + w.file + " was written"
+		if data, err := os.ReadFile(w.file); err == nil && strings.TrimSpace(string(data)) != "" {
+			reason = strings.TrimSpace(string(data))
+		}
+		return reason, true
+	}
+	if w.url == "" {
+		return "", false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cancelRequestTimeout)
+	defer cancel()
+	response, err := w.request(ctx)
+	if err != nil {
+		w.logger.Debugf("Cancel check failed: %v", err)
+		return "", false
+	}
+	if !response.Cancel {
+		return "", false
+	}
+	if response.Reason == "" {
+		response.Reason = "cancel requested from " + w.url
+	}
+	return response.Reason, true
+}
+
+// request asks the cancel URL whether the run is to be canceled.
+func (w *cancelWatcher) request(ctx context.Context) (cancelResponse, error) {
+	var response cancelResponse
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.url, nil)
+	if err != nil {
+		return response, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.token)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return response, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return response, fmt.Errorf("cancel url returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&response); err != nil {
+		return response, fmt.Errorf("invalid cancel response: %w", err)
+	}
+	return response, nil
+}
+
+// cancel ends an execution canceled by a signal or a cancel request. Its
+// changes are recorded in the artifacts first, then kept, stashed or
+// discarded as cancel.rollback says.
+func (e *Executor) cancel(err error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	e.captureChanges(ctx)
+	if !e.config.DryRun {
+		e.rollbackCanceled(ctx)
+	}
+	e.logger.Warningf("■ Execution canceled: %v", err)
+	return e.failWithStatus(statusCanceled, err)
+}
+
+// rollbackCanceled applies cancel.rollback to a canceled run's changes.
+// Checkpoint commits are undone first, as for any run that is not committed.
+func (e *Executor) rollbackCanceled(ctx context.Context) {
+	if e.checkpointCommit != "" {
+		if err := e.gitManager.Uncommit(ctx, e.baseCommit); err != nil {
+			e.logger.Warningf("! Failed to undo checkpoint commits: %v", err)
+			return
+		}
+	}
+
+	switch e.config.Cancel.Rollback {
+	case CancelRollbackStash:
+		stashed, err := e.gitManager.Stash(ctx, "forge: canceled run "+e.config.RunID)
+		switch {
+		case err != nil:
+			e.logger.Warningf("! Failed to stash the canceled run's changes: %v", err)
+		case stashed:
+			e.logger.Infof("± Stashed the canceled run's changes")
+		}
+	case CancelRollbackDiscard:
+		discarded, err := e.gitManager.Rollback(ctx)
+		switch {
+		case err != nil:
+			e.logger.Warningf("! Failed to discard the canceled run's changes: %v", err)
+		case discarded:
+			e.logger.Infof("± Discarded the canceled run's changes")
+		}
+	}
+}
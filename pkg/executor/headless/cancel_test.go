@@ -0,0 +1,170 @@
+package headless
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func cancelTestConfig(t *testing.T, workspaceDir string) *Config {
+	config := stallTestConfig(t)
+	config.WorkspaceDir = workspaceDir
+	config.Constraints.StallTimeout = 0
+	config.Cancel.PollInterval = 10 * time.Millisecond
+	return config
+}
+
+// writeCancelFile writes the cancel file once the run is underway
+func writeCancelFile(t *testing.T, workspaceDir, reason string) {
+	t.Helper()
+	time.AfterFunc(50*time.Millisecond, func() {
+		path := filepath.Join(workspaceDir, defaultCancelFile)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		_ = os.WriteFile(path, []byte(reason), 0644)
+	})
+}
+
+func TestExecutor_CancelFile(t *testing.T) {
+	ag := newStallingAgent()
+	config := cancelTestConfig(t, t.TempDir())
+	executor, err := NewExecutor(ag, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCancelFile(t, config.WorkspaceDir, "release freeze")
+
+	err = executor.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "release freeze") {
+		t.Fatalf("expected the run to be canceled with the file's reason, got %v", err)
+	}
+	if executor.summary.Status != statusCanceled {
+		t.Errorf("expected status %q, got %q", statusCanceled, executor.summary.Status)
+	}
+	inputs := ag.received()
+	if len(inputs) != 2 || !inputs[1].IsCancel() {
+		t.Errorf("expected the task and a cancellation, got %+v", inputs)
+	}
+}
+
+func TestExecutor_CancelURL(t *testing.T) {
+	t.Setenv("FORGE_TEST_CANCEL_TOKEN", "secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/runs/run-42/cancel" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"cancel": true, "reason": "canceled from the dashboard"}`))
+	}))
+	defer server.Close()
+
+	config := cancelTestConfig(t, t.TempDir())
+	config.RunID = "run-42"
+	config.Cancel.URL = server.URL + "/runs/{run_id}/cancel"
+	config.Cancel.TokenEnv = "FORGE_TEST_CANCEL_TOKEN"
+	executor, err := NewExecutor(newStallingAgent(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = executor.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "canceled from the dashboard") {
+		t.Fatalf("expected the run to be canceled from the URL, got %v", err)
+	}
+	if executor.summary.Status != statusCanceled || executor.summary.RunID != "run-42" {
+		t.Errorf("expected a canceled summary for run-42, got %q for %q", executor.summary.Status, executor.summary.RunID)
+	}
+}
+
+func TestExecutor_CancelRollbackDiscard(t *testing.T) {
+	workspaceDir := setupGitRepo(t)
+	config := cancelTestConfig(t, workspaceDir)
+	config.Cancel.Rollback = CancelRollbackDiscard
+	executor, err := NewExecutor(newStallingAgent(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes the agent made before the cancel
+	if err := os.WriteFile(filepath.Join(workspaceDir, "README.md"), []byte("# Changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspaceDir, "new.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeCancelFile(t, workspaceDir, "")
+
+	if err := executor.Run(context.Background()); err == nil {
+		t.Fatal("expected the canceled run to return an error")
+	}
+	if data, _ := os.ReadFile(filepath.Join(workspaceDir, "README.md")); string(data) != "# Test" {
+		t.Errorf("expected the change to README.md to be discarded, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(workspaceDir, "new.go")); err == nil {
+		t.Error("expected the new file to be discarded")
+	}
+	if _, err := os.Stat(filepath.Join(workspaceDir, defaultCancelFile)); err != nil {
+		t.Errorf("expected the cancel file to be left alone: %v", err)
+	}
+}
+
+func TestCancelWatcher_IgnoresStaleFile(t *testing.T) {
+	config := cancelTestConfig(t, t.TempDir())
+	path := filepath.Join(config.WorkspaceDir, defaultCancelFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher := newCancelWatcher(config, "run", NewLogger(LogLevelQuiet))
+	defer watcher.stop()
+	if _, ok := watcher.check(); ok {
+		t.Error("expected a cancel file left over from an earlier run to be ignored")
+	}
+
+	now := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, now, now); err != nil {
+		t.Fatal(err)
+	}
+	if reason, ok := watcher.check(); !ok || !strings.Contains(reason, defaultCancelFile) {
+		t.Errorf("expected a newly written cancel file to cancel the run, got %q, %v", reason, ok)
+	}
+}
+
+func TestCancelConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CancelConfig
+		wantErr string
+	}{
+		{"defaults", CancelConfig{}, ""},
+		{"url with run id", CancelConfig{URL: "https://ci.example.com/runs/{run_id}/cancel", Rollback: CancelRollbackStash}, ""},
+		{"not http", CancelConfig{URL: "file:///tmp/cancel"}, "invalid cancel url"},
+		{"unknown rollback", CancelConfig{Rollback: "revert"}, "invalid cancel rollback"},
+		{"negative interval", CancelConfig{PollInterval: -time.Second}, "poll_interval"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package headless
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/gobwas/glob"
+)
+
+const (
+	// StackGroupByDirectory splits a stack by the changed files' directories
+	StackGroupByDirectory = "directory"
+	// StackGroupByUnit splits a stack by the logical units the agent declares
+	// with group_changes, falling back to directories for other files
+	StackGroupByUnit = "unit"
+
+	groupChangesToolName = "group_changes"
+)
+
+// ChangeUnit is a logical part of the agent's change, such as a refactoring
+// the feature builds on, that becomes one part of a stack
+type ChangeUnit struct {
+	Name        string
+	Description string
+	Paths       []string // Workspace-relative files, directories or globs
+
+	globs []glob.Glob
+}
+
+// matches reports whether a workspace-relative file belongs to the unit
+func (u *ChangeUnit) matches(file string) bool {
+	for i, p := range u.Paths {
+		if file == p || strings.HasPrefix(file, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+		if u.globs[i] != nil && u.globs[i].Match(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeUnits holds the units the agent declared, in the order the stack
+// should be reviewed and merged
+type ChangeUnits struct {
+	units []*ChangeUnit
+	mu    sync.Mutex
+}
+
+// Declare adds a unit, or replaces the description and paths of one declared
+// before under the same name, keeping its position
+func (c *ChangeUnits) Declare(name, description string, paths []string) error {
+	unit := &ChangeUnit{Name: name, Description: description}
+	for _, p := range paths {
+		p = path.Clean(strings.TrimPrefix(p, "./"))
+		if p == "." || path.IsAbs(p) || strings.HasPrefix(p, "../") {
+			return fmt.Errorf("path %q must be relative to the workspace", p)
+		}
+		var g glob.Glob
+		if strings.ContainsAny(p, "*?[{") {
+			compiled, err := glob.Compile(p, '/')
+			if err != nil {
+				return fmt.Errorf("invalid glob %q: %w", p, err)
+			}
+			g = compiled
+		}
+		unit.Paths = append(unit.Paths, p)
+		unit.globs = append(unit.globs, g)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, existing := range c.units {
+		if existing.Name == name {
+			c.units[i] = unit
+			return nil
+		}
+	}
+	c.units = append(c.units, unit)
+	return nil
+}
+
+// Units returns the declared units in order
+func (c *ChangeUnits) Units() []*ChangeUnit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*ChangeUnit(nil), c.units...)
+}
+
+// GroupChangesTool lets the agent describe the logical units of its change,
+// so an oversized diff is split into a stack along them rather than by
+// directory
+type GroupChangesTool struct {
+	units    *ChangeUnits
+	maxLines int
+}
+
+// NewGroupChangesTool creates a tool recording units into units, for runs
+// that split diffs changing more than maxLines lines
+func NewGroupChangesTool(units *ChangeUnits, maxLines int) *GroupChangesTool {
+	return &GroupChangesTool{
+		units:    units,
+		maxLines: maxLines,
+	}
+}
+
+// Name returns the tool name.
+func (t *GroupChangesTool) Name() string {
+	return groupChangesToolName
+}
+
+// Description returns the tool description.
+func (t *GroupChangesTool) Description() string {
+	return fmt.Sprintf("Declare a logical unit of your change, such as a refactoring, a new API or its callers. "+
+		"If the final diff changes more than %d lines, it is split into a stack of dependent pull requests, one per unit, "+
+		"reviewed and merged in the order the units were declared, so declare units a later one depends on first. "+
+		"Call it once per unit before completing the task; declaring a name again replaces that unit. "+
+		"Changed files in no unit are grouped by directory after the units.", t.maxLines)
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *GroupChangesTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Short name for the unit, used in pull request titles and commit subjects, e.g. 'extract-client'",
+			},
+			"description": map[string]any{
+				"type":        "string",
+				"description": "One or two sentences on what the unit changes and why, used in its commit and pull request",
+			},
+			"paths": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "string",
+				},
+				"description": "Workspace-relative files, directories or globs (e.g. 'pkg/client/**') in the unit. A file in several units belongs to the first declared",
+				"minItems":    1,
+			},
+		},
+		[]string{"name", "description", "paths"},
+	)
+}
+
+// Execute records the unit.
+func (t *GroupChangesTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName     xml.Name `xml:"arguments"`
+		Name        string   `xml:"name"`
+		Description string   `xml:"description"`
+		Paths       []string `xml:"paths>path"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return "", nil, fmt.Errorf("missing required parameter: name")
+	}
+	description := strings.TrimSpace(input.Description)
+	if description == "" {
+		return "", nil, fmt.Errorf("missing required parameter: description")
+	}
+	var paths []string
+	for _, p := range input.Paths {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		return "", nil, fmt.Errorf("missing required parameter: paths")
+	}
+
+	if err := t.units.Declare(name, description, paths); err != nil {
+		return "", nil, err
+	}
+
+	units := t.units.Units()
+	names := make([]string, len(units))
+	for i, unit := range units {
+		names[i] = unit.Name
+	}
+	return fmt.Sprintf("Recorded unit %q. Stack order: %s", name, strings.Join(names, ", ")),
+		map[string]any{"name": name, "paths": paths, "units": len(units)}, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *GroupChangesTool) IsLoopBreaking() bool {
+	return false
+}
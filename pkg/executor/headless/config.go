@@ -2,8 +2,15 @@ package headless
 
 import (
 	"fmt"
+	"path/filepath"
 	"slices"
+	"strings"
 	"time"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/security/sandbox"
+	"github.com/entrhq/forge/pkg/security/workspace"
 )
 
 // Config represents the configuration for headless mode execution
@@ -11,6 +18,12 @@ type Config struct {
 	// Task description
 	Task string `yaml:"task" json:"task"`
 
+	// TaskTemplate is a file, relative to the workspace, whose contents
+	// become Task once its {{name}} placeholders are filled. TaskVars holds
+	// default values; see RenderTaskTemplate for the other sources.
+	TaskTemplate string            `yaml:"task_template" json:"task_template"`
+	TaskVars     map[string]string `yaml:"task_vars" json:"task_vars"`
+
 	// Execution mode
 	Mode ExecutionMode `yaml:"mode" json:"mode"`
 
@@ -22,22 +35,81 @@ type Config struct {
 	QualityGateMaxRetries   int                 `yaml:"quality_gate_max_retries" json:"quality_gate_max_retries"`     // Global max retries for quality gates (default: 3)
 	QualityGateRetryTimeout time.Duration       `yaml:"quality_gate_retry_timeout" json:"quality_gate_retry_timeout"` // Timeout for each quality gate retry attempt (default: same as main timeout)
 
+	// Behavior verification for pure-refactor tasks
+	Verification VerificationConfig `yaml:"verification" json:"verification"`
+
+	// Knowledge base of gate failures and their fixes, shared across runs
+	Knowledge KnowledgeConfig `yaml:"knowledge" json:"knowledge"`
+
+	// Fan-out of the task into a sub-execution per package
+	FanOut FanOutConfig `yaml:"fan_out" json:"fan_out"`
+
+	// Task matrix: independent tasks run in their own git worktrees instead
+	// of Task
+	Tasks  []MatrixTask `yaml:"tasks" json:"tasks"`
+	Matrix MatrixConfig `yaml:"matrix" json:"matrix"`
+
+	// Project setup run before the agent starts
+	Bootstrap BootstrapConfig `yaml:"bootstrap" json:"bootstrap"`
+
+	// Graceful cancellation through a file or an HTTP endpoint
+	Cancel CancelConfig `yaml:"cancel" json:"cancel"`
+
+	// RunID identifies the run in cancel requests, artifact upload paths and
+	// execution.json (default: generated from the start time)
+	RunID string `yaml:"run_id" json:"run_id"`
+
 	// Git configuration
 	Git GitConfig `yaml:"git" json:"git"`
 
 	// Artifacts configuration
 	Artifacts ArtifactConfig `yaml:"artifacts" json:"artifacts"`
 
+	// Notifications posted with the run's outcome once it finishes
+	Notifications []NotificationConfig `yaml:"notifications" json:"notifications"`
+
 	// Workspace directory
 	WorkspaceDir string `yaml:"workspace_dir" json:"workspace_dir"`
 
+	// Subtrees of the workspace the run is restricted to
+	Workspace WorkspaceConfig `yaml:"workspace" json:"workspace"`
+
+	// Sandbox isolates execute_command from the host (default: none)
+	Sandbox sandbox.Config `yaml:"sandbox" json:"sandbox"`
+
 	// Logging configuration
 	Logging LoggingConfig `yaml:"logging" json:"logging"`
 
+	// Sampling pins generation parameters per role for reproducible runs
+	Sampling SamplingConfig `yaml:"sampling" json:"sampling"`
+
+	// FallbackModel is the model requests are retried on when the main model
+	// is rate limited or overloaded, so a long run degrades instead of failing.
+	// Empty uses llm.fallback_model from the project or global config.
+	FallbackModel string `yaml:"fallback_model" json:"fallback_model"`
+
+	// RateLimit caps the requests the run sends to the provider. Limits left
+	// at 0 use llm.rate_limit from the global config.
+	RateLimit appconfig.RateLimit `yaml:"rate_limit" json:"rate_limit"`
+
+	// ResponseCache caches the responses to summarization and commit and PR
+	// generation across runs, so CI jobs over near-identical inputs skip the
+	// model. Fields left unset use llm.response_cache from the global config.
+	ResponseCache appconfig.ResponseCache `yaml:"response_cache" json:"response_cache"`
+
+	// DryRun runs the full agent loop with file writes and commands
+	// simulated in an overlay. The would-be diff and the quality gates the
+	// changes would run are written to the artifacts; the workspace, branch
+	// and remote are left untouched.
+	DryRun bool `yaml:"dry_run" json:"dry_run"`
+
 	// ConfigFilePath is the path to the config file used to start this run (if any)
 	// This file will be automatically excluded from commits to prevent temporary
 	// config files from being committed in PR workflows
 	ConfigFilePath string `yaml:"-" json:"-"`
+
+	// taskRendered is set once Task has been rendered from TaskTemplate
+	taskRendered bool
 }
 
 // ExecutionMode defines the execution mode for headless runs
@@ -61,18 +133,213 @@ type ConstraintConfig struct {
 	// Tool restrictions
 	AllowedTools []string `yaml:"allowed_tools" json:"allowed_tools"`
 
+	// Command policies for execute_command: regular expressions matched
+	// against the whole command. A command matching a denied expression is
+	// rejected; when allowed expressions are set, a command must match one.
+	AllowedCommands []string `yaml:"allowed_commands" json:"allowed_commands"`
+	DeniedCommands  []string `yaml:"denied_commands" json:"denied_commands"`
+
 	// Resource limits
 	MaxTokens int           `yaml:"max_tokens" json:"max_tokens"`
 	Timeout   time.Duration `yaml:"timeout" json:"timeout"`
+
+	// Stall detection: when the agent emits no event for StallTimeout, the
+	// stalled turn is canceled and, with RetryOnStall, retried once before
+	// the execution fails as stalled. Zero disables the watchdog.
+	StallTimeout time.Duration `yaml:"stall_timeout" json:"stall_timeout"`
+	RetryOnStall bool          `yaml:"retry_on_stall" json:"retry_on_stall"`
+
+	// Per-message limits: a single user input or tool result larger than
+	// MaxMessageTokens is handled according to OversizedMessages ("chunk" or "reject").
+	MaxMessageTokens  int    `yaml:"max_message_tokens" json:"max_message_tokens"` // Default: DefaultMaxMessageTokens; -1 disables the limit
+	OversizedMessages string `yaml:"oversized_messages" json:"oversized_messages"` // Default: chunk
+
+	// Per-tool timeouts and result sizes, layered over the project's
+	// tool_limits and the built-in defaults.
+	ToolLimits *appconfig.ToolLimits `yaml:"tool_limits" json:"tool_limits"`
+}
+
+// DefaultMaxMessageTokens is the per-message token ceiling used when
+// max_message_tokens is not set.
+const DefaultMaxMessageTokens = 25000
+
+// MessageTokenLimit returns the configured per-message token ceiling, or
+// DefaultMaxMessageTokens when unset. It returns 0, which disables the
+// guard, when max_message_tokens is -1.
+func (c ConstraintConfig) MessageTokenLimit() int {
+	switch {
+	case c.MaxMessageTokens == 0:
+		return DefaultMaxMessageTokens
+	case c.MaxMessageTokens < 0:
+		return 0
+	}
+	return c.MaxMessageTokens
+}
+
+// validate checks the constraint limits
+func (c ConstraintConfig) validate() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+
+	if c.StallTimeout < 0 {
+		return fmt.Errorf("stall_timeout cannot be negative")
+	}
+
+	if c.MaxFiles < 0 {
+		return fmt.Errorf("max_files cannot be negative")
+	}
+
+	if c.MaxLinesChanged < 0 {
+		return fmt.Errorf("max_lines_changed cannot be negative")
+	}
+
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens cannot be negative")
+	}
+
+	if c.MaxMessageTokens < -1 {
+		return fmt.Errorf("max_message_tokens must be -1 (no limit) or at least 0")
+	}
+
+	switch c.OversizedMessages {
+	case "", "chunk", "reject":
+	default:
+		return fmt.Errorf("invalid oversized_messages: %s (must be 'chunk' or 'reject')", c.OversizedMessages)
+	}
+
+	if err := c.ToolLimits.Validate(); err != nil {
+		return fmt.Errorf("tool_limits.%w", err)
+	}
+
+	if _, err := NewCommandPolicy(c.AllowedCommands, c.DeniedCommands); err != nil {
+		return err
+	}
+	return nil
 }
 
 // QualityGateConfig defines a quality gate to run before committing changes
 type QualityGateConfig struct {
 	Name       string        `yaml:"name" json:"name"`
-	Command    string        `yaml:"command" json:"command"`
+	Type       string        `yaml:"type" json:"type"`       // "command" (default), "lsp" or "secret_scan"
+	Command    string        `yaml:"command" json:"command"` // Shell command, or the language server command for lsp gates (default: gopls)
 	Required   bool          `yaml:"required" json:"required"`
-	MaxRetries int           `yaml:"max_retries" json:"max_retries"` // Maximum number of retry attempts (default: 3)
+	MaxRetries int           `yaml:"max_retries" json:"max_retries"` // Re-runs before a failure is reported (default: 0, or 2 when flaky)
+	Backoff    time.Duration `yaml:"backoff" json:"backoff"`         // Delay before the first re-run, doubled for each one after up to 5m (default: 2s)
+	Flaky      bool          `yaml:"flaky" json:"flaky"`             // Also re-run on assertion failures, not just infrastructure errors
 	Timeout    time.Duration `yaml:"timeout" json:"timeout"`         // Timeout for this quality gate (default: 3m)
+
+	// Command gates only
+	WorkDir string            `yaml:"work_dir" json:"work_dir"` // Directory to run the command in, relative to the workspace (or to each scoped path)
+	Env     map[string]string `yaml:"env" json:"env"`           // Environment variables added to the executor's own
+
+	// Scheduling
+	DependsOn []string `yaml:"depends_on" json:"depends_on"` // Gates that must pass first; the gate is skipped when one does not
+	RunIf     []string `yaml:"run_if" json:"run_if"`         // Workspace-relative globs; the gate only runs when a modified file matches one
+	Group     string   `yaml:"group" json:"group"`           // Gates in the same group run concurrently
+
+	// LSP diagnostics gates only
+	Severities []string `yaml:"severities" json:"severities"` // Diagnostic severities that fail the gate: error, warning, information, hint (default: error)
+	Extensions []string `yaml:"extensions" json:"extensions"` // File extensions to check, e.g. ".go" (default: .go with gopls, otherwise all modified files)
+}
+
+// validate checks the gate's type and type-specific settings.
+func (g QualityGateConfig) validate() error {
+	if g.Timeout < 0 {
+		return fmt.Errorf("quality gate '%s': timeout must be non-negative", g.Name)
+	}
+
+	isCommand := g.Type == "" || g.Type == QualityGateTypeCommand
+	if !isCommand && (g.WorkDir != "" || len(g.Env) > 0) {
+		return fmt.Errorf("quality gate '%s': work_dir and env only apply to command gates", g.Name)
+	}
+
+	switch g.Type {
+	case "", QualityGateTypeCommand:
+		if strings.TrimSpace(g.Command) == "" {
+			return fmt.Errorf("quality gate '%s': command is required", g.Name)
+		}
+		if g.WorkDir != "" && (filepath.IsAbs(g.WorkDir) || !filepath.IsLocal(g.WorkDir)) {
+			return fmt.Errorf("quality gate '%s': work_dir must be a relative path inside the workspace: %s", g.Name, g.WorkDir)
+		}
+		for name := range g.Env {
+			if name == "" || strings.ContainsAny(name, "=\x00") {
+				return fmt.Errorf("quality gate '%s': invalid env variable name: %q", g.Name, name)
+			}
+		}
+	case QualityGateTypeLSP:
+		for _, severity := range g.Severities {
+			if _, ok := lspSeverities[strings.ToLower(severity)]; !ok {
+				return fmt.Errorf("quality gate '%s': invalid severity: %s (must be 'error', 'warning', 'information' or 'hint')", g.Name, severity)
+			}
+		}
+	case QualityGateTypeSecretScan:
+		if g.Command != "" {
+			return fmt.Errorf("quality gate '%s': secret_scan gates take no command", g.Name)
+		}
+	default:
+		return fmt.Errorf("quality gate '%s': invalid type: %s (must be 'command', 'lsp' or 'secret_scan')", g.Name, g.Type)
+	}
+	return nil
+}
+
+// validateGateSchedules checks that run_if globs are valid and that
+// depends_on names other gates, unambiguously and without cycles.
+func validateGateSchedules(gates []QualityGateConfig) error {
+	counts := make(map[string]int, len(gates))
+	deps := make(map[string][]string, len(gates))
+	for _, gate := range gates {
+		counts[gate.Name]++
+		deps[gate.Name] = append(deps[gate.Name], gate.DependsOn...)
+	}
+
+	for _, gate := range gates {
+		for _, pattern := range gate.RunIf {
+			if err := workspace.ValidatePathPattern(pattern); err != nil {
+				return fmt.Errorf("quality gate '%s': run_if: %w", gate.Name, err)
+			}
+		}
+		for _, dep := range gate.DependsOn {
+			switch {
+			case dep == gate.Name:
+				return fmt.Errorf("quality gate '%s': depends_on names the gate itself", gate.Name)
+			case counts[dep] == 0:
+				return fmt.Errorf("quality gate '%s': depends_on names unknown gate '%s'", gate.Name, dep)
+			case counts[dep] > 1:
+				return fmt.Errorf("quality gate '%s': depends_on names '%s', which is the name of more than one gate", gate.Name, dep)
+			}
+		}
+	}
+
+	// Depth-first search for a dependency cycle
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(gates))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("quality gate dependencies form a cycle: %s", strings.Join(append(path, name), " → "))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range deps[name] {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for _, gate := range gates {
+		if err := visit(gate.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // GitConfig defines git operation configuration
@@ -85,6 +352,19 @@ type GitConfig struct {
 	AuthorName          string `yaml:"author_name" json:"author_name"`
 	AuthorEmail         string `yaml:"author_email" json:"author_email"`
 
+	// UseWorktree runs in a detached worktree of HEAD created for the run,
+	// so edits, gates and commits never touch the primary checkout. The
+	// worktree is removed afterwards unless it holds uncommitted changes.
+	UseWorktree bool `yaml:"use_worktree" json:"use_worktree"`
+
+	// Run history. CheckpointCommits commits the agent's work each time the
+	// quality gates run, the first time with the commit message and then as
+	// fixup commits for it, and autosquashes the fixups when the run commits.
+	// SquashCommits folds every commit made during the run, the agent's own
+	// included, into a single commit before it is pushed.
+	CheckpointCommits bool `yaml:"checkpoint_commits" json:"checkpoint_commits"`
+	SquashCommits     bool `yaml:"squash_commits" json:"squash_commits"`
+
 	// PR creation configuration (ADR-0031)
 	CreatePR  bool   `yaml:"create_pr" json:"create_pr"`   // If true, create PR instead of direct push
 	PRTitle   string `yaml:"pr_title" json:"pr_title"`     // PR title (optional, auto-generated if empty)
@@ -92,12 +372,72 @@ type GitConfig struct {
 	PRBase    string `yaml:"pr_base" json:"pr_base"`       // Target branch (default: auto-detected)
 	PRDraft   bool   `yaml:"pr_draft" json:"pr_draft"`     // Create as draft PR
 	RequirePR bool   `yaml:"require_pr" json:"require_pr"` // Fail if PR creation is not possible (no fallback)
+
+	// Stacked PRs for oversized changes
+	StackMaxLines   int    `yaml:"stack_max_lines" json:"stack_max_lines"`     // Split a diff changing more lines than this into a stack of dependent PRs (0 disables)
+	StackGroupDepth int    `yaml:"stack_group_depth" json:"stack_group_depth"` // Directory depth files are grouped by, one PR per group (default: 1)
+	StackGroupBy    string `yaml:"stack_group_by" json:"stack_group_by"`       // directory (default) or unit, for logical units the agent declares
+
+	// Git host used for PR creation
+	Provider string `yaml:"provider" json:"provider"`   // github (default), gitlab, gitea, or bitbucket
+	APIURL   string `yaml:"api_url" json:"api_url"`     // API base URL for self-hosted instances (default: derived from the origin remote)
+	TokenEnv string `yaml:"token_env" json:"token_env"` // Environment variable holding the API token (default: GITHUB_TOKEN, GITLAB_TOKEN, GITEA_TOKEN, or BITBUCKET_TOKEN)
+}
+
+// WorkspaceConfig scopes a run to part of a large workspace such as a monorepo
+type WorkspaceConfig struct {
+	// Paths lists workspace-relative subtrees, e.g. services/auth. When set,
+	// the workspace guard, search tools and file pattern constraints are
+	// restricted to them, and command quality gates run in each of them that
+	// has modified files instead of at the workspace root.
+	Paths []string `yaml:"paths" json:"paths"`
+}
+
+// Scoped reports whether the run is restricted to workspace paths
+func (c WorkspaceConfig) Scoped() bool {
+	return len(c.Paths) > 0
+}
+
+// validate checks that every path is a subdirectory of the workspace
+func (c WorkspaceConfig) validate() error {
+	for _, path := range c.Paths {
+		if err := workspace.ValidateScopePath(path); err != nil {
+			return fmt.Errorf("invalid workspace path: %w", err)
+		}
+	}
+	return nil
+}
+
+// SamplingConfig defines generation parameters for each LLM role.
+// Unset fields fall back to the global config, then to provider defaults.
+type SamplingConfig struct {
+	Agent      appconfig.SamplingParams `yaml:"agent" json:"agent"`
+	Summarizer appconfig.SamplingParams `yaml:"summarizer" json:"summarizer"`
+	Commit     appconfig.SamplingParams `yaml:"commit" json:"commit"` // Commit message and PR content generation
+}
+
+// IsZero reports whether no role has sampling parameters set.
+func (s SamplingConfig) IsZero() bool {
+	return s.Agent.IsZero() && s.Summarizer.IsZero() && s.Commit.IsZero()
+}
+
+// WithDefaults returns s with any unset field filled from defaults, so values in
+// the headless YAML take precedence over the global config.
+func (s SamplingConfig) WithDefaults(defaults SamplingConfig) SamplingConfig {
+	return SamplingConfig{
+		Agent:      defaults.Agent.Merge(s.Agent),
+		Summarizer: defaults.Summarizer.Merge(s.Summarizer),
+		Commit:     defaults.Commit.Merge(s.Commit),
+	}
 }
 
 // LoggingConfig defines logging configuration
 type LoggingConfig struct {
 	// Verbosity controls logging level: quiet, normal, verbose, debug
 	Verbosity string `yaml:"verbosity" json:"verbosity"`
+
+	// Format controls output rendering: text (default) or json (one JSON object per line)
+	Format string `yaml:"format" json:"format"`
 }
 
 // ArtifactConfig defines artifact generation configuration
@@ -109,13 +449,54 @@ type ArtifactConfig struct {
 	JSON     bool `yaml:"json" json:"json"`
 	Markdown bool `yaml:"markdown" json:"markdown"`
 	Metrics  bool `yaml:"metrics" json:"metrics"`
+	Patch    bool `yaml:"patch" json:"patch"` // changes.patch: the run's changes as a unified diff
+
+	// Upload pushes the artifacts to object storage after the run
+	Upload ArtifactUploadConfig `yaml:"upload" json:"upload"`
+}
+
+// screenshotsDirName is the artifacts subdirectory browser screenshots are saved to
+const screenshotsDirName = "screenshots"
+
+// ArtifactDir returns the directory artifacts are written to: output_dir,
+// relative to the workspace unless absolute.
+func (c *Config) ArtifactDir() string {
+	return c.workspacePath(c.Artifacts.OutputDir)
+}
+
+// ScreenshotDir returns the directory browser screenshots are saved to, inside
+// the artifacts directory so they are kept with the execution's other
+// artifacts and stay out of commits.
+func (c *Config) ScreenshotDir() string {
+	return filepath.Join(c.ArtifactDir(), screenshotsDirName)
+}
+
+// knowledgeDir returns the knowledge base directory, relative to the
+// workspace unless absolute.
+func (c *Config) knowledgeDir() string {
+	dir := c.Knowledge.Dir
+	if dir == "" {
+		dir = defaultKnowledgeDir
+	}
+	return c.workspacePath(dir)
+}
+
+// workspacePath resolves path against the workspace unless it is absolute.
+func (c *Config) workspacePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.WorkspaceDir, path)
 }
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	if c.Task == "" {
+	if c.Task == "" && len(c.Tasks) == 0 && c.TaskTemplate == "" {
 		return fmt.Errorf("task description is required")
 	}
+	if c.TaskTemplate != "" && (len(c.Tasks) > 0 || (c.Task != "" && !c.taskRendered)) {
+		return fmt.Errorf("task_template cannot be combined with task or tasks")
+	}
 
 	if c.Mode != ModeReadOnly && c.Mode != ModeWrite {
 		return fmt.Errorf("invalid mode: %s (must be 'read-only' or 'write')", c.Mode)
@@ -126,32 +507,124 @@ func (c *Config) Validate() error {
 	}
 
 	// Validate constraints
-	if c.Constraints.Timeout < 0 {
-		return fmt.Errorf("timeout cannot be negative")
+	if err := c.Constraints.validate(); err != nil {
+		return err
 	}
 
-	if c.Constraints.MaxFiles < 0 {
-		return fmt.Errorf("max_files cannot be negative")
+	// Validate sampling parameters
+	if err := c.Sampling.Agent.Validate(); err != nil {
+		return fmt.Errorf("invalid sampling.agent: %w", err)
+	}
+	if err := c.Sampling.Summarizer.Validate(); err != nil {
+		return fmt.Errorf("invalid sampling.summarizer: %w", err)
+	}
+	if err := c.Sampling.Commit.Validate(); err != nil {
+		return fmt.Errorf("invalid sampling.commit: %w", err)
+	}
+	if err := c.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("invalid rate_limit: %w", err)
+	}
+	if err := c.ResponseCache.Validate(); err != nil {
+		return fmt.Errorf("invalid response_cache: %w", err)
 	}
 
-	if c.Constraints.MaxLinesChanged < 0 {
-		return fmt.Errorf("max_lines_changed cannot be negative")
+	for _, gate := range c.QualityGates {
+		if err := gate.validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateGateSchedules(c.QualityGates); err != nil {
+		return err
 	}
 
-	if c.Constraints.MaxTokens < 0 {
-		return fmt.Errorf("max_tokens cannot be negative")
+	if err := c.Bootstrap.validate(); err != nil {
+		return err
+	}
+	if err := c.Cancel.validate(); err != nil {
+		return err
+	}
+	if err := c.Verification.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Workspace.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Artifacts.Upload.validate(); err != nil {
+		return err
+	}
+
+	for i, notification := range c.Notifications {
+		if err := notification.validate(); err != nil {
+			return fmt.Errorf("notifications[%d]: %w", i, err)
+		}
 	}
 
-	// Validate PR configuration
+	if err := c.FanOut.validate(c.Git); err != nil {
+		return err
+	}
+
+	if err := c.validateMatrix(); err != nil {
+		return err
+	}
+
+	// Validate PR configuration (matrix tasks each name their own branch)
 	if c.Git.CreatePR {
 		if !c.Git.AutoCommit {
 			return fmt.Errorf("create_pr requires auto_commit to be enabled")
 		}
-		if c.Git.Branch == "" {
+		if c.Git.Branch == "" && len(c.Tasks) == 0 {
 			return fmt.Errorf("create_pr requires a branch to be specified")
 		}
 	}
 
+	if c.Git.UseWorktree {
+		if len(c.Tasks) > 0 {
+			return fmt.Errorf("use_worktree cannot be combined with tasks, which run in worktrees of their own")
+		}
+		// Commits on the detached worktree would be lost when it is removed
+		if c.Git.AutoCommit && c.Git.Branch == "" {
+			return fmt.Errorf("use_worktree with auto_commit requires a branch to be specified")
+		}
+	}
+
+	if c.DryRun && (len(c.Tasks) > 0 || c.FanOut.Enabled()) {
+		return fmt.Errorf("dry_run cannot be combined with tasks or fan_out")
+	}
+
+	if c.Git.StackMaxLines < 0 || c.Git.StackGroupDepth < 0 {
+		return fmt.Errorf("stack_max_lines and stack_group_depth cannot be negative")
+	}
+	if c.Git.StackMaxLines > 0 && !c.Git.CreatePR {
+		return fmt.Errorf("stack_max_lines requires create_pr to be enabled")
+	}
+	switch c.Git.StackGroupBy {
+	case "", StackGroupByDirectory, StackGroupByUnit:
+	default:
+		return fmt.Errorf("invalid stack_group_by: %s (must be 'directory' or 'unit')", c.Git.StackGroupBy)
+	}
+
+	if c.Git.CheckpointCommits || c.Git.SquashCommits {
+		if !c.Git.AutoCommit {
+			return fmt.Errorf("checkpoint_commits and squash_commits require auto_commit to be enabled")
+		}
+		// A stack is split from the uncommitted diff alone
+		if c.Git.StackMaxLines > 0 {
+			return fmt.Errorf("checkpoint_commits and squash_commits cannot be combined with stack_max_lines")
+		}
+	}
+
+	switch c.Git.Provider {
+	case "", git.ProviderGitHub, git.ProviderGitLab, git.ProviderGitea, git.ProviderBitbucket:
+	default:
+		return fmt.Errorf("invalid git provider: %s (must be 'github', 'gitlab', 'gitea', or 'bitbucket')", c.Git.Provider)
+	}
+
+	if err := c.Sandbox.Validate(); err != nil {
+		return err
+	}
+
 	// Set default verbosity if not specified
 	if c.Logging.Verbosity == "" {
 		c.Logging.Verbosity = "normal"
@@ -168,6 +641,34 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid logging verbosity: %s (must be 'quiet', 'normal', 'verbose', or 'debug')", c.Logging.Verbosity)
 	}
 
+	if c.Logging.Format == "" {
+		c.Logging.Format = string(LogFormatText)
+	}
+	if c.Logging.Format != string(LogFormatText) && c.Logging.Format != string(LogFormatJSON) {
+		return fmt.Errorf("invalid logging format: %s (must be 'text' or 'json')", c.Logging.Format)
+	}
+
+	return nil
+}
+
+// ValidateOffline rejects settings that need the network, so a run started
+// with -offline fails before it starts rather than when it tries to push.
+func (c *Config) ValidateOffline() error {
+	if c.Git.CreatePR {
+		return fmt.Errorf("offline mode: git.create_pr needs the network to push and open a pull request; set it to false or run without -offline")
+	}
+	if c.Git.AutoPush {
+		return fmt.Errorf("offline mode: git.auto_push needs the network; set it to false to keep commits local, or run without -offline")
+	}
+	if c.Artifacts.Upload.Enabled() {
+		return fmt.Errorf("offline mode: artifacts.upload needs the network; remove it to keep artifacts local, or run without -offline")
+	}
+	if c.Cancel.URL != "" {
+		return fmt.Errorf("offline mode: cancel.url needs the network; use the cancel file instead, or run without -offline")
+	}
+	if len(c.Notifications) > 0 {
+		return fmt.Errorf("offline mode: notifications need the network; remove them, or run without -offline")
+	}
 	return nil
 }
 
@@ -196,9 +697,16 @@ func DefaultConfig() *Config {
 				"read_file",
 				"write_file",
 				"apply_diff",
+				"rename_symbol",
+				"delete_file",
+				"move_file",
+				"create_directory",
 				"search_files",
 				"list_files",
+				"find_files",
 				"execute_command",
+				"run_tests",
+				"view_image",
 			},
 		},
 		Git: GitConfig{
@@ -213,6 +721,10 @@ func DefaultConfig() *Config {
 			JSON:      true,
 			Markdown:  true,
 			Metrics:   true,
+			Patch:     true,
+		},
+		Knowledge: KnowledgeConfig{
+			Dir: defaultKnowledgeDir,
 		},
 	}
 }
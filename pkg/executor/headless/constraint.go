@@ -1,9 +1,12 @@
 package headless
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -22,6 +25,11 @@ type ConstraintManager struct {
 
 	// Pattern matching
 	patternMatcher *PatternMatcher
+	commandPolicy  *CommandPolicy
+	scope          []string // Workspace-relative subtrees files must be in
+
+	// Rejected tool calls, reported in the execution summary
+	violations []ViolationRecord
 
 	mu sync.RWMutex
 }
@@ -55,8 +63,17 @@ const (
 	ViolationTokenLimit      ViolationType = "token_limit"
 	ViolationTimeout         ViolationType = "timeout"
 	ViolationReadOnlyMode    ViolationType = "read_only_mode"
+	ViolationCommandPolicy   ViolationType = "command_policy"
+	ViolationApprovalRule    ViolationType = "approval_rule"
 )
 
+// ViolationRecord is a tool call rejected by a constraint
+type ViolationRecord struct {
+	Tool    string        `json:"tool"`
+	Type    ViolationType `json:"type"`
+	Message string        `json:"message"`
+}
+
 // NewConstraintManager creates a new constraint manager
 func NewConstraintManager(config ConstraintConfig, mode ExecutionMode) (*ConstraintManager, error) {
 	// Create pattern matcher
@@ -65,12 +82,18 @@ func NewConstraintManager(config ConstraintConfig, mode ExecutionMode) (*Constra
 		return nil, fmt.Errorf("failed to create pattern matcher: %w", err)
 	}
 
+	commandPolicy, err := NewCommandPolicy(config.AllowedCommands, config.DeniedCommands)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create command policy: %w", err)
+	}
+
 	return &ConstraintManager{
 		config:         &config,
 		mode:           mode,
 		filesModified:  make(map[string]*FileModification),
 		startTime:      time.Now(),
 		patternMatcher: patternMatcher,
+		commandPolicy:  commandPolicy,
 	}, nil
 }
 
@@ -84,6 +107,17 @@ func (cm *ConstraintManager) ValidateToolCall(toolName string, args any) error {
 		return nil
 	}
 
+	// load_tools only sends the schemas of tools already registered, so it
+	// grants nothing by itself
+	if toolName == "load_tools" {
+		return nil
+	}
+
+	// group_changes only records how the run's own diff is split
+	if toolName == groupChangesToolName {
+		return nil
+	}
+
 	// Enforce read-only mode: reject all file-modifying tools
 	if cm.mode == ModeReadOnly && isFileModifyingTool(toolName) {
 		return &ConstraintViolation{
@@ -111,10 +145,38 @@ func (cm *ConstraintManager) ValidateToolCall(toolName string, args any) error {
 		}
 	}
 
-	// For file-modifying tools, check file patterns
+	// Check shell commands against the command policy
+	if toolName == "execute_command" {
+		if command, ok := extractCommand(args); ok {
+			if err := cm.validateCommand(command); err != nil {
+				return err
+			}
+		}
+	}
+
+	// For file-modifying tools, check file patterns, including the
+	// destination of a move
 	if isFileModifyingTool(toolName) {
-		filePath, err := extractFilePath(args)
-		if err == nil && filePath != "" {
+		filePaths := make([]string, 0, 2)
+		if filePath, err := extractFilePath(args); err == nil {
+			filePaths = append(filePaths, filePath)
+		}
+		if argsMap, ok := args.(map[string]any); ok {
+			if destination, ok := argsMap["destination"].(string); ok && destination != "" {
+				filePaths = append(filePaths, destination)
+			}
+		}
+		for _, filePath := range filePaths {
+			if !cm.inScope(filePath) {
+				return &ConstraintViolation{
+					Type:    ViolationFilePattern,
+					Message: fmt.Sprintf("file '%s' is outside the workspace paths", filePath),
+					Details: map[string]any{
+						"file":            filePath,
+						"workspace_paths": cm.scope,
+					},
+				}
+			}
 			if !cm.patternMatcher.IsAllowed(filePath) {
 				return &ConstraintViolation{
 					Type:    ViolationFilePattern,
@@ -132,6 +194,95 @@ func (cm *ConstraintManager) ValidateToolCall(toolName string, args any) error {
 	return nil
 }
 
+// ValidateCommand validates an execute_command call against the command
+// policy. Unlike ValidateToolCall it checks nothing else, so it can run on
+// every command before approval without rejecting tools registered outside
+// the allowed tools list.
+func (cm *ConstraintManager) ValidateCommand(args any) error {
+	command, ok := extractCommand(args)
+	if !ok {
+		return nil
+	}
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.validateCommand(command)
+}
+
+// validateCommand checks command against the command policy.
+// Must be called with lock held
+func (cm *ConstraintManager) validateCommand(command string) error {
+	denied, allowed := cm.commandPolicy.Check(command)
+	if allowed {
+		return nil
+	}
+
+	message := fmt.Sprintf("command '%s' does not match allowed command patterns", command)
+	if denied != "" {
+		message = fmt.Sprintf("command '%s' matches denied command pattern '%s'", command, denied)
+	}
+	return &ConstraintViolation{
+		Type:    ViolationCommandPolicy,
+		Message: message,
+		Details: map[string]any{
+			"command":          command,
+			"allowed_commands": cm.config.AllowedCommands,
+			"denied_commands":  cm.config.DeniedCommands,
+		},
+	}
+}
+
+// RecordViolation records a tool call rejected with err for the execution
+// summary. Errors other than constraint violations are ignored.
+func (cm *ConstraintManager) RecordViolation(toolName string, err error) {
+	var violation *ConstraintViolation
+	if !errors.As(err, &violation) {
+		return
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.violations = append(cm.violations, ViolationRecord{
+		Tool:    toolName,
+		Type:    violation.Type,
+		Message: violation.Message,
+	})
+}
+
+// Violations returns the rejected tool calls in the order they were recorded.
+func (cm *ConstraintManager) Violations() []ViolationRecord {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return slices.Clone(cm.violations)
+}
+
+// SetScope restricts file-modifying tools to the given workspace-relative
+// subtrees, on top of the allowed and denied patterns.
+func (cm *ConstraintManager) SetScope(paths []string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cm.scope = make([]string, 0, len(paths))
+	for _, path := range paths {
+		cm.scope = append(cm.scope, filepath.ToSlash(filepath.Clean(path)))
+	}
+}
+
+// inScope reports whether path is inside one of the scoped subtrees.
+// Must be called with lock held
+func (cm *ConstraintManager) inScope(path string) bool {
+	if len(cm.scope) == 0 {
+		return true
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+	for _, dir := range cm.scope {
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // RecordFileModification records a file modification and validates against limits
 func (cm *ConstraintManager) RecordFileModification(path string, linesAdded, linesRemoved int) error {
 	cm.mu.Lock()
@@ -252,6 +403,74 @@ func (cm *ConstraintManager) GetCurrentState() *ConstraintState {
 	}
 }
 
+// ModifiedFiles returns the paths of the files modified so far, sorted.
+func (cm *ConstraintManager) ModifiedFiles() []string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	files := make([]string, 0, len(cm.filesModified))
+	for path := range cm.filesModified {
+		files = append(files, path)
+	}
+	slices.Sort(files)
+	return files
+}
+
+// PromptSection describes the run's limits and the budget left under each of
+// them, for injection into the agent's system prompt before every LLM call.
+// It returns an empty string when no limits are configured.
+func (cm *ConstraintManager) PromptSection() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	var lines []string
+	if cm.mode == ModeReadOnly {
+		lines = append(lines, "- Mode: read-only; write_file, apply_diff, rename_symbol, delete_file, move_file and create_directory will be rejected")
+	}
+	if cm.config.MaxFiles > 0 {
+		used := len(cm.filesModified)
+		lines = append(lines, fmt.Sprintf("- Files modified: %d of %d (%d remaining)", used, cm.config.MaxFiles, max(cm.config.MaxFiles-used, 0)))
+	}
+	if cm.config.MaxLinesChanged > 0 {
+		used := cm.calculateTotalLinesAdded() + cm.calculateTotalLinesRemoved()
+		lines = append(lines, fmt.Sprintf("- Lines changed: %d of %d (%d remaining)", used, cm.config.MaxLinesChanged, max(cm.config.MaxLinesChanged-used, 0)))
+	}
+	if cm.config.MaxTokens > 0 {
+		lines = append(lines, fmt.Sprintf("- Tokens used: %d of %d (%d remaining)", cm.tokensUsed, cm.config.MaxTokens, max(cm.config.MaxTokens-cm.tokensUsed, 0)))
+	}
+	if cm.config.Timeout > 0 {
+		elapsed := time.Since(cm.startTime).Round(time.Second)
+		remaining := max(cm.config.Timeout-elapsed, 0)
+		lines = append(lines, fmt.Sprintf("- Time elapsed: %s of %s (%s remaining)", elapsed, cm.config.Timeout, remaining))
+	}
+	if len(cm.scope) > 0 {
+		lines = append(lines, "- Workspace paths: "+strings.Join(cm.scope, ", ")+"; files outside them cannot be read or modified")
+	}
+	if len(cm.config.AllowedPatterns) > 0 {
+		lines = append(lines, "- Files you may modify: "+strings.Join(cm.config.AllowedPatterns, ", "))
+	}
+	if len(cm.config.DeniedPatterns) > 0 {
+		lines = append(lines, "- Files you must not modify: "+strings.Join(cm.config.DeniedPatterns, ", "))
+	}
+	if len(cm.config.AllowedTools) > 0 {
+		lines = append(lines, "- Tools you may use: "+strings.Join(cm.config.AllowedTools, ", "))
+	}
+	if len(cm.config.AllowedCommands) > 0 {
+		lines = append(lines, "- Commands you may run must match one of these regular expressions: "+strings.Join(cm.config.AllowedCommands, ", "))
+	}
+	if len(cm.config.DeniedCommands) > 0 {
+		lines = append(lines, "- Commands matching these regular expressions will be rejected: "+strings.Join(cm.config.DeniedCommands, ", "))
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return "# Execution Constraints\n\n" +
+		"This is an unattended run with hard limits. Breaking a limit rejects the tool call or aborts the run, " +
+		"so plan your changes to fit the remaining budget and finish with task_completion before it runs out.\n\n" +
+		strings.Join(lines, "\n")
+}
+
 // ConstraintState represents the current state of constraint tracking
 type ConstraintState struct {
 	FilesModified     []FileModification
@@ -286,7 +505,7 @@ func (cm *ConstraintManager) calculateTotalLinesRemoved() int {
 // Note: execute_command is allowed in read-only mode for inspection purposes
 func isFileModifyingTool(toolName string) bool {
 	switch toolName {
-	case "write_file", "apply_diff":
+	case "write_file", "apply_diff", "rename_symbol", "delete_file", "move_file", "create_directory":
 		return true
 	default:
 		return false
@@ -334,6 +553,19 @@ func extractFilePath(args any) (string, error) {
 	return path, nil
 }
 
+// extractCommand extracts the shell command from execute_command arguments
+func extractCommand(args any) (string, bool) {
+	argsMap, ok := args.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	command, ok := argsMap["command"].(string)
+	if !ok || strings.TrimSpace(command) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(command), true
+}
+
 // PatternMatcher handles glob pattern matching for file access control
 type PatternMatcher struct {
 	allowedPatterns []glob.Glob
@@ -391,3 +623,58 @@ func (pm *PatternMatcher) IsAllowed(path string) bool {
 
 	return false
 }
+
+// CommandPolicy matches shell commands against allowed and denied regular
+// expressions. Expressions are unanchored, so `git\s+push` matches the push
+// in `git add . && git push`.
+type CommandPolicy struct {
+	allowed []*regexp.Regexp
+	denied  []*regexp.Regexp
+}
+
+// NewCommandPolicy compiles the allowed and denied expressions
+func NewCommandPolicy(allowed, denied []string) (*CommandPolicy, error) {
+	cp := &CommandPolicy{}
+
+	for _, expr := range allowed {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed command pattern '%s': %w", expr, err)
+		}
+		cp.allowed = append(cp.allowed, re)
+	}
+
+	for _, expr := range denied {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid denied command pattern '%s': %w", expr, err)
+		}
+		cp.denied = append(cp.denied, re)
+	}
+
+	return cp, nil
+}
+
+// Check reports whether command may run. When a denied expression matches,
+// it is returned as well.
+func (cp *CommandPolicy) Check(command string) (denied string, allowed bool) {
+	// Denied expressions take precedence
+	for _, re := range cp.denied {
+		if re.MatchString(command) {
+			return re.String(), false
+		}
+	}
+
+	// If no allowed expressions specified, allow all (except denied)
+	if len(cp.allowed) == 0 {
+		return "", true
+	}
+
+	for _, re := range cp.allowed {
+		if re.MatchString(command) {
+			return "", true
+		}
+	}
+
+	return "", false
+}
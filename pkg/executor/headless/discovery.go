@@ -0,0 +1,276 @@
+package headless
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// DiscoveryGo lists packages with go list in every module of the workspace
+	DiscoveryGo = "go"
+	// DiscoveryCommand runs a command that prints one package directory per line
+	DiscoveryCommand = "command"
+)
+
+// Package is one unit of a fan-out run, a directory the task is applied to
+// on its own.
+type Package struct {
+	Name    string   `json:"name"`              // Import path for Go packages, otherwise the directory
+	Dir     string   `json:"dir"`               // Workspace-relative, slash-separated; "." for the root
+	Imports []string `json:"imports,omitempty"` // Names of the discovered packages it depends on
+}
+
+// slug returns a name for the package that is safe in branch and file names
+func (p Package) slug() string {
+	if p.Dir == "." {
+		return "root"
+	}
+	return strings.ReplaceAll(p.Dir, "/", "-")
+}
+
+// DiscoverPackages lists the workspace's packages using the configured
+// discovery, keeps those matching include and exclude, and orders them so
+// each package comes after the packages it imports.
+func DiscoverPackages(ctx context.Context, workspaceDir string, config FanOutConfig, scope []string) ([]Package, error) {
+	var packages []Package
+	var err error
+	switch config.Discovery {
+	case "", DiscoveryGo:
+		packages, err = discoverGoPackages(ctx, workspaceDir)
+	case DiscoveryCommand:
+		packages, err = discoverCommandPackages(ctx, workspaceDir, config.Command)
+	default:
+		err = fmt.Errorf("invalid fan_out discovery: %s", config.Discovery)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	packages, err = filterPackages(packages, config.Include, config.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	packages = scopePackages(packages, scope)
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("fan-out discovered no packages")
+	}
+	if config.MaxPackages > 0 && len(packages) > config.MaxPackages {
+		return nil, fmt.Errorf("fan-out discovered %d packages, more than max_packages (%d); narrow them with include or exclude", len(packages), config.MaxPackages)
+	}
+
+	return orderPackages(packages), nil
+}
+
+// discoverGoPackages runs go list in every Go module under workspaceDir,
+// recording each package's imports of other workspace packages
+func discoverGoPackages(ctx context.Context, workspaceDir string) ([]Package, error) {
+	root, err := filepath.EvalSymlinks(workspaceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace: %w", err)
+	}
+
+	modules, err := findGoModules(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no go.mod found in %s", workspaceDir)
+	}
+
+	seen := make(map[string]bool)
+	var packages []Package
+	for _, moduleDir := range modules {
+		cmd := exec.CommandContext(ctx, "go", "list", "-e", "-f", "{{.ImportPath}}\t{{.Dir}}\t{{join .Imports \" \"}}", "./...")
+		cmd.Dir = moduleDir
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("go list failed in %s: %w", moduleDir, err)
+		}
+
+		scanner := bufio.NewScanner(strings.NewReader(string(output)))
+		for scanner.Scan() {
+			fields := strings.Split(scanner.Text(), "\t")
+			if len(fields) < 2 || fields[1] == "" {
+				continue
+			}
+			dir, err := relativeDir(root, fields[1])
+			if err != nil || seen[dir] {
+				continue
+			}
+			seen[dir] = true
+
+			pkg := Package{Name: fields[0], Dir: dir}
+			if len(fields) > 2 {
+				pkg.Imports = strings.Fields(fields[2])
+			}
+			packages = append(packages, pkg)
+		}
+	}
+
+	// Only imports of other discovered packages matter for ordering
+	names := make(map[string]bool, len(packages))
+	for _, pkg := range packages {
+		names[pkg.Name] = true
+	}
+	for i := range packages {
+		packages[i].Imports = slices.DeleteFunc(packages[i].Imports, func(name string) bool { return !names[name] })
+	}
+
+	return packages, nil
+}
+
+// findGoModules returns the directories under root holding a go.mod, skipping
+// hidden, vendor, node_modules and testdata directories
+func findGoModules(root string) ([]string, error) {
+	var modules []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == "go.mod" {
+			modules = append(modules, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Go modules: %w", err)
+	}
+	return modules, nil
+}
+
+// discoverCommandPackages runs command in workspaceDir and treats each
+// non-empty output line as a package directory
+func discoverCommandPackages(ctx context.Context, workspaceDir, command string) ([]Package, error) {
+	root, err := filepath.EvalSymlinks(workspaceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command) //nolint:gosec // the command comes from the headless config
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("package discovery command failed: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var packages []Package
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(root, line)
+		}
+		dir, err := relativeDir(root, line)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			packages = append(packages, Package{Name: dir, Dir: dir})
+		}
+	}
+	return packages, nil
+}
+
+// relativeDir returns dir relative to root in slash form, rejecting
+// directories outside root
+func relativeDir(root, dir string) (string, error) {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("package directory %s is outside the workspace", dir)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// filterPackages keeps the packages whose directory matches an include
+// pattern (all when there are none) and no exclude pattern
+// scopePackages keeps the packages inside one of the workspace paths in scope.
+// An empty scope keeps every package.
+func scopePackages(packages []Package, scope []string) []Package {
+	if len(scope) == 0 {
+		return packages
+	}
+	return slices.DeleteFunc(packages, func(pkg Package) bool {
+		for _, dir := range scope {
+			dir = filepath.ToSlash(filepath.Clean(dir))
+			if pkg.Dir == dir || strings.HasPrefix(pkg.Dir, dir+"/") {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func filterPackages(packages []Package, include, exclude []string) ([]Package, error) {
+	matcher, err := NewPatternMatcher(include, exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fan_out pattern: %w", err)
+	}
+	return slices.DeleteFunc(packages, func(pkg Package) bool { return !matcher.IsAllowed(pkg.Dir) }), nil
+}
+
+// orderPackages sorts packages by directory, then moves each after the
+// packages it imports so dependents see their dependencies' changes
+func orderPackages(packages []Package) []Package {
+	slices.SortFunc(packages, func(a, b Package) int { return strings.Compare(a.Dir, b.Dir) })
+
+	byName := make(map[string]Package, len(packages))
+	for _, pkg := range packages {
+		byName[pkg.Name] = pkg
+	}
+
+	ordered := make([]Package, 0, len(packages))
+	visited := make(map[string]bool, len(packages))
+	var visit func(pkg Package)
+	visit = func(pkg Package) {
+		if visited[pkg.Name] {
+			return
+		}
+		// Marked before its imports so an import cycle cannot recurse forever
+		visited[pkg.Name] = true
+		for _, name := range pkg.Imports {
+			if dep, ok := byName[name]; ok {
+				visit(dep)
+			}
+		}
+		ordered = append(ordered, pkg)
+	}
+	for _, pkg := range packages {
+		visit(pkg)
+	}
+	return ordered
+}
+
+// nestedPackageDirs returns the directories of the packages nested inside
+// pkg's directory, which belong to their own sub-executions
+func nestedPackageDirs(pkg Package, packages []Package) []string {
+	var dirs []string
+	for _, other := range packages {
+		if other.Dir == pkg.Dir {
+			continue
+		}
+		if pkg.Dir == "." || strings.HasPrefix(other.Dir, pkg.Dir+"/") {
+			dirs = append(dirs, other.Dir)
+		}
+	}
+	return dirs
+}
@@ -0,0 +1,116 @@
+package headless
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func packageDirs(packages []Package) []string {
+	dirs := make([]string, len(packages))
+	for i, pkg := range packages {
+		dirs[i] = pkg.Dir
+	}
+	return dirs
+}
+
+func TestDiscoverPackages_Go(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	dir := writeWorkspaceFiles(t, map[string]string{
+		"go.mod":         "module example.com/m\n\ngo 1.21\n",
+		"main.go":        "package main\n\nimport _ \"example.com/m/api\"\n\nfunc main() {}\n",
+		"api/api.go":     "package api\n\nimport _ \"example.com/m/store\"\n",
+		"store/store.go": "package store\n",
+		"tools/go.mod":   "module example.com/tools\n\ngo 1.21\n",
+		"tools/gen.go":   "package tools\n",
+		"vendor/x/x.go":  "package x\n",
+	})
+
+	packages, err := DiscoverPackages(context.Background(), dir, FanOutConfig{By: FanOutByPackage}, nil)
+	if err != nil {
+		t.Fatalf("DiscoverPackages: %v", err)
+	}
+
+	// Dependencies come before the packages that import them
+	got := packageDirs(packages)
+	want := []string{"store", "api", ".", "tools"}
+	if len(got) != len(want) {
+		t.Fatalf("expected packages %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected packages %v, got %v", want, got)
+		}
+	}
+	if packages[0].Name != "example.com/m/store" {
+		t.Errorf("expected the import path as the name, got %q", packages[0].Name)
+	}
+}
+
+func TestDiscoverPackages_Command(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"services/auth", "services/billing", "libs/log"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := FanOutConfig{
+		By:        FanOutByPackage,
+		Discovery: DiscoveryCommand,
+		Command:   "printf 'services/billing\\nlibs/log\\n\\nservices/auth\\nservices/auth\\n'",
+		Include:   []string{"services/**"},
+		Exclude:   []string{"services/billing"},
+	}
+	packages, err := DiscoverPackages(context.Background(), dir, config, nil)
+	if err != nil {
+		t.Fatalf("DiscoverPackages: %v", err)
+	}
+	if got := packageDirs(packages); len(got) != 1 || got[0] != "services/auth" {
+		t.Errorf("expected only services/auth, got %v", got)
+	}
+
+	config.Include = nil
+	config.Exclude = nil
+	packages, err = DiscoverPackages(context.Background(), dir, config, []string{"services"})
+	if err != nil {
+		t.Fatalf("DiscoverPackages: %v", err)
+	}
+	if got := packageDirs(packages); len(got) != 2 || got[0] != "services/auth" || got[1] != "services/billing" {
+		t.Errorf("expected only the packages in the workspace paths, got %v", got)
+	}
+
+	config.MaxPackages = 2
+	if _, err := DiscoverPackages(context.Background(), dir, config, nil); err == nil {
+		t.Error("expected an error when discovery finds more than max_packages")
+	}
+
+	config.Command = "echo ../outside"
+	config.MaxPackages = 0
+	if _, err := DiscoverPackages(context.Background(), dir, config, nil); err == nil {
+		t.Error("expected an error for a directory outside the workspace")
+	}
+}
+
+func TestOrderPackages(t *testing.T) {
+	packages := orderPackages([]Package{
+		{Name: "c", Dir: "c"},
+		{Name: "a", Dir: "a", Imports: []string{"c", "b"}},
+		{Name: "b", Dir: "b", Imports: []string{"a"}}, // cycle with a
+	})
+
+	got := packageDirs(packages)
+	want := []string{"c", "b", "a"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, got)
+		}
+	}
+}
@@ -48,6 +48,7 @@
 // - Maximum number of files that can be modified
 // - Maximum total lines changed
 // - File pattern allowlists/denylists
+// - Workspace paths that scope a monorepo run to a few subtrees
 // - Tool restrictions
 // - Token usage limits
 // - Execution timeout
@@ -65,8 +66,26 @@
 // - Workspace validation
 // - Automatic commits with proper attribution
 // - Branch creation (for future PR workflows)
+// - Stacked PRs that split oversized diffs by directory
 // - Rollback on failures
 //
+// Fan-Out:
+//
+// The fan-out executor splits one task into a sub-execution per package,
+// discovered with go list or a configured command:
+// - Packages run in dependency order, each with a fresh agent
+// - Constraints and command gates are scoped to the package
+// - Packages share one branch and PR, or get stacked branches and PRs
+// - fanout.json and fanout.md aggregate the per-package results
+//
+// Task Matrix:
+//
+// The matrix executor runs a list of independent tasks in parallel:
+// - Each task runs in its own git worktree with a fresh agent
+// - Tasks override the top-level constraints and get their own branch and PR
+// - Worktrees with uncommitted changes are kept for inspection
+// - matrix.json and matrix.md aggregate the per-task results
+//
 // Artifacts:
 //
 // The artifact writer generates execution reports:
@@ -74,6 +93,9 @@
 // - summary.md: Human-readable markdown summary
 // - metrics.json: Execution metrics
 //
+// With artifacts.upload set, the artifacts directory is pushed to S3, GCS or
+// Azure Blob Storage after the run and signed URLs are logged.
+//
 // For more details, see:
 // - docs/adr/0026-headless-mode-architecture.md
 // - docs/adr/0027-safety-constraint-system.md
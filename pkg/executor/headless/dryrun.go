@@ -0,0 +1,159 @@
+package headless
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/tools/mock"
+)
+
+// DryRunSummary records what a dry run would have done. The changes it would
+// have made are in the summary's Changes.
+type DryRunSummary struct {
+	Commands []string         `json:"commands,omitempty"` // Commands the agent would have run, in order
+	Refused  []string         `json:"refused,omitempty"`  // Writes a real run would refuse, by path rule or write scope
+	Gates    []GatePrediction `json:"gates,omitempty"`
+}
+
+// GatePrediction tells whether a quality gate would run on a dry run's
+// changes. Whether it would pass cannot be known without running it.
+type GatePrediction struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	WouldRun bool   `json:"would_run"`
+	Reason   string `json:"reason"`
+}
+
+// Predict reports which gates would run on changedFiles, by their run_if
+// conditions, without running any. Gates with dependencies run only if those
+// pass, which the prediction notes.
+func (r *QualityGateRunner) Predict(changedFiles []string) []GatePrediction {
+	modifiedFiles := func() []string { return changedFiles }
+
+	predictions := make([]GatePrediction, 0, len(r.gates))
+	for _, gate := range r.gates {
+		schedule := r.schedules[gate.Name()]
+		prediction := GatePrediction{
+			Name:     gate.Name(),
+			Required: gate.Required(),
+			WouldRun: true,
+			Reason:   "always runs",
+		}
+		if len(schedule.RunIf) > 0 {
+			if anyFileMatches(schedule.RunIf, modifiedFiles) {
+				prediction.Reason = "a changed file matches run_if"
+			} else {
+				prediction.WouldRun = false
+				prediction.Reason = "no changed file matches run_if"
+			}
+		}
+		if prediction.WouldRun && len(schedule.DependsOn) > 0 {
+			prediction.Reason += fmt.Sprintf("; needs %s to pass", strings.Join(schedule.DependsOn, ", "))
+		}
+		predictions = append(predictions, prediction)
+	}
+	return predictions
+}
+
+// overlayPatch returns the unified diff of the changes held in a dry run's
+// overlay and the files it touches. Each file is diffed with git diff
+// --no-index in a temporary directory, so neither the workspace nor its
+// repository is touched and the workspace need not be a repository.
+func overlayPatch(ctx context.Context, changes []mock.Change) (string, []FileChange, error) {
+	dir, err := os.MkdirTemp("", "forge-dry-run-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var patch strings.Builder
+	files := make([]FileChange, 0, len(changes))
+	for _, change := range changes {
+		path := filepath.ToSlash(change.Path)
+
+		// With empty prefixes git names the files a/<path> and b/<path>,
+		// as in a diff of the repository
+		before, after, status := "/dev/null", "b/"+path, fileChangeAdded
+		if !change.Created {
+			before, status = "a/"+path, fileChangeModified
+			if err := writeDiffFile(dir, before, change.Original); err != nil {
+				return "", nil, err
+			}
+		}
+		if change.Deleted {
+			after, status = "/dev/null", fileChangeDeleted
+		} else if err := writeDiffFile(dir, after, change.Content); err != nil {
+			return "", nil, err
+		}
+
+		diff, err := diffNoIndex(ctx, dir, before, after)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to diff %s: %w", path, err)
+		}
+		patch.WriteString(diff)
+		files = append(files, countDiffLines(FileChange{Path: path, Status: status}, diff))
+	}
+	return patch.String(), files, nil
+}
+
+// writeDiffFile writes content to the slash-separated path under dir
+func writeDiffFile(dir, path, content string) error {
+	path = filepath.Join(dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	return nil
+}
+
+// diffNoIndex returns git's diff of the files before and after, relative to
+// dir. git diff --no-index exits with 1 when the files differ.
+func diffNoIndex(ctx context.Context, dir, before, after string) (string, error) {
+	execCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(execCtx, "git", "diff", "--no-index", "--binary", "--no-color", "--no-ext-diff",
+		"--src-prefix=", "--dst-prefix=", "--", before, after)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return "", fmt.Errorf("git command failed: %w\nOutput: %s", err, stderr.String())
+		}
+	}
+	return stdout.String(), nil
+}
+
+// countDiffLines sets file's line counts from its diff, or marks it binary
+func countDiffLines(file FileChange, diff string) FileChange {
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "GIT binary patch"), strings.HasPrefix(line, "Binary files "):
+			file.Binary = true
+			file.LinesAdded, file.LinesRemoved = 0, 0
+			return file
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			file.LinesAdded++
+		case strings.HasPrefix(line, "-"):
+			file.LinesRemoved++
+		}
+	}
+	return file
+}
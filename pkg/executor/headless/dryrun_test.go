@@ -0,0 +1,206 @@
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/mock"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestQualityGateRunner_Predict(t *testing.T) {
+	configs := []QualityGateConfig{
+		{Name: "build", Command: "go build ./...", Required: true},
+		{Name: "test", Command: "go test ./...", DependsOn: []string{"build"}},
+		{Name: "go-lint", Command: "golangci-lint run", RunIf: []string{"**/*.go"}},
+		{Name: "docs", Command: "make docs", RunIf: []string{"docs/**"}},
+	}
+	runner := NewQualityGateRunner(CreateQualityGates(configs, nil, nil)).WithSchedules(GateSchedules(configs), nil)
+
+	got := runner.Predict([]string{"pkg/api/handler.go"})
+	want := []GatePrediction{
+		{Name: "build", Required: true, WouldRun: true, Reason: "always runs"},
+		{Name: "test", WouldRun: true, Reason: "always runs; needs build to pass"},
+		{Name: "go-lint", WouldRun: true, Reason: "a changed file matches run_if"},
+		{Name: "docs", WouldRun: false, Reason: "no changed file matches run_if"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d predictions, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("prediction %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestOverlayPatch(t *testing.T) {
+	patch, files, err := overlayPatch(context.Background(), []mock.Change{
+		{Path: "docs/new.md", Created: true, Content: "# New\n"},
+		{Path: "main.go", Original: "package main\n\nfunc main() {}\n", Content: "package main\n\nfunc main() {\n\trun()\n}\n"},
+		{Path: "old.go", Original: "package main\n\nvar old = 1\n", Deleted: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"--- /dev/null\n+++ b/docs/new.md\n",
+		"--- a/main.go\n+++ b/main.go\n",
+		"-func main() {}\n+func main() {\n+\trun()\n+}\n",
+		"--- a/old.go\n+++ /dev/null\n",
+	} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("expected the patch to contain %q, got:\n%s", want, patch)
+		}
+	}
+	wantFiles := []FileChange{
+		{Path: "docs/new.md", Status: fileChangeAdded, LinesAdded: 1},
+		{Path: "main.go", Status: fileChangeModified, LinesAdded: 3, LinesRemoved: 1},
+		{Path: "old.go", Status: fileChangeDeleted, LinesRemoved: 3},
+	}
+	if len(files) != len(wantFiles) || files[0] != wantFiles[0] || files[1] != wantFiles[1] || files[2] != wantFiles[2] {
+		t.Errorf("files = %+v, want %+v", files, wantFiles)
+	}
+}
+
+func TestExecutor_DryRun(t *testing.T) {
+	dir := setupFanOutRepo(t)
+	config := DefaultConfig()
+	config.Task = "Write change.txt"
+	config.WorkspaceDir = dir
+	config.Logging.Verbosity = "quiet"
+	config.DryRun = true
+	config.Git.AutoCommit = true
+	config.Git.Branch = "forge/dry-run"
+	config.QualityGates = []QualityGateConfig{
+		{Name: "fails", Command: "false", Required: true},
+		{Name: "docs", Command: "false", RunIf: []string{"docs/**"}},
+	}
+
+	overlay := mock.NewOverlay(dir)
+	ag := newScriptedAgent(func() error {
+		overlay.WriteFile(filepath.Join(dir, "a", "change.txt"), []byte("change\n"))
+		overlay.RecordCommand("go test ./...")
+		return nil
+	})
+	executor, err := NewExecutor(ag, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor.SetOverlay(overlay)
+	if err := executor.Run(context.Background()); err != nil {
+		t.Fatalf("expected the dry run to succeed without running gates, got %v", err)
+	}
+
+	// Nothing reached the workspace, its branch or its history
+	if _, err := os.Stat(filepath.Join(dir, "a", "change.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected change.txt not to be written, got %v", err)
+	}
+	if branch := gitOutput(t, dir, "branch", "--show-current"); branch != "main" {
+		t.Errorf("expected to stay on main, got %s", branch)
+	}
+	if count := gitOutput(t, dir, "rev-list", "--count", "HEAD"); count != "2" {
+		t.Errorf("expected no new commit, got %s commits", count)
+	}
+
+	artifactDir := config.ArtifactDir()
+	patch, err := os.ReadFile(filepath.Join(artifactDir, changesPatchName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(patch), "+++ b/a/change.txt\n@@ -0,0 +1 @@\n+change\n") {
+		t.Errorf("expected the would-be diff in the patch, got:\n%s", patch)
+	}
+
+	data, err := os.ReadFile(filepath.Join(artifactDir, "execution.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary ExecutionSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Status != statusSuccess || summary.QualityGateResults != nil {
+		t.Errorf("expected success with no gate results, got %s and %+v", summary.Status, summary.QualityGateResults)
+	}
+	if summary.DryRun == nil || len(summary.DryRun.Commands) != 1 || len(summary.DryRun.Gates) != 2 {
+		t.Fatalf("expected the dry run summary, got %+v", summary.DryRun)
+	}
+	if !summary.DryRun.Gates[0].WouldRun || summary.DryRun.Gates[1].WouldRun {
+		t.Errorf("expected fails to run and docs to be skipped, got %+v", summary.DryRun.Gates)
+	}
+	if summary.Changes == nil || len(summary.Changes.Files) != 1 || summary.Changes.Files[0].Status != fileChangeAdded {
+		t.Errorf("expected a/change.txt as an added file, got %+v", summary.Changes)
+	}
+}
+
+func TestExecutor_DryRunReportsRefusedWrites(t *testing.T) {
+	dir := setupFanOutRepo(t)
+	config := DefaultConfig()
+	config.Task = "Write the keys"
+	config.WorkspaceDir = dir
+	config.Logging.Verbosity = "quiet"
+	config.DryRun = true
+
+	guard, err := workspace.NewGuard(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.SetPathRules(workspace.PathRules{DenyWrite: []string{"secrets/**"}}); err != nil {
+		t.Fatal(err)
+	}
+	overlay := mock.NewOverlay(dir)
+	writeFile := mock.NewWriteFileTool(guard, overlay)
+
+	// The agent reports a refused tool call as a tool result error
+	var ag *scriptedAgent
+	ag = newScriptedAgent(func() error {
+		_, _, toolErr := writeFile.Execute(context.Background(), []byte(`<arguments><path>secrets/key.txt</path><content>key</content></arguments>`))
+		if toolErr == nil {
+			return errors.New("expected the write to be refused")
+		}
+		ag.channels.Event <- types.NewToolResultErrorEvent("call-1", writeFile.Name(), toolErr)
+		return nil
+	})
+	executor, err := NewExecutor(ag, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor.SetOverlay(overlay)
+	if err := executor.Run(context.Background()); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(config.ArtifactDir(), "execution.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary ExecutionSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.DryRun == nil || len(summary.DryRun.Refused) != 1 || !strings.Contains(summary.DryRun.Refused[0], "secrets/key.txt") {
+		t.Fatalf("expected the refused write in the dry run summary, got %+v", summary.DryRun)
+	}
+	if summary.Changes != nil && len(summary.Changes.Files) > 0 {
+		t.Errorf("expected no would-be changes, got %+v", summary.Changes.Files)
+	}
+	if patch, err := os.ReadFile(filepath.Join(config.ArtifactDir(), changesPatchName)); err == nil && strings.Contains(string(patch), "secrets/key.txt") {
+		t.Errorf("expected the refused write to be missing from the patch, got:\n%s", patch)
+	}
+}
+
+func TestConfig_Validate_DryRun(t *testing.T) {
+	config := fanOutTestConfig(t.TempDir())
+	config.DryRun = true
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "dry_run cannot be combined") {
+		t.Errorf("expected dry_run with fan_out to be rejected, got %v", err)
+	}
+}
@@ -2,13 +2,19 @@ package headless
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/approval"
+	"github.com/entrhq/forge/pkg/agent/hooks"
+	"github.com/entrhq/forge/pkg/agent/todo"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	appconfig "github.com/entrhq/forge/pkg/config"
 	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/mock"
 	"github.com/entrhq/forge/pkg/types"
 )
 
@@ -19,18 +25,32 @@ const (
 	statusSuccess        = "success"
 	statusFailed         = "failed"
 	statusPartialSuccess = "partial_success"
+	statusStalled        = "stalled"
+	statusCanceled       = "canceled"
 )
 
+// failedStatus reports whether an execution with status failed outright
+func failedStatus(status string) bool {
+	return status == statusFailed || status == statusStalled || status == statusCanceled
+}
+
 // Executor implements the headless mode executor
 type Executor struct {
 	agent          agent.Agent
 	config         *Config
 	constraintMgr  *ConstraintManager
 	qualityGates   *QualityGateRunner
+	verifier       *BehaviorVerifier // Set in no_behavior_change verification mode
+	knowledge      *KnowledgeBase    // Set when the knowledge base is enabled
+	fixes          *FixRecorder      // Records gate fixes into knowledge
 	artifactWriter *ArtifactWriter
 	gitManager     *GitManager
-	llmProvider    llm.Provider // LLM provider for PR generation
-	logger         *Logger      // Logger for structured output
+	changeUnits    *ChangeUnits     // Logical units declared by the agent for stacked PRs
+	llmProvider    llm.Provider     // LLM provider for PR generation
+	model          string           // The agent's model, for estimating the run's cost
+	logger         *Logger          // Logger for structured output
+	overlay        *mock.Overlay    // Holds a dry run's simulated writes and commands
+	guard          *workspace.Guard // The agent's workspace guard, for approval rule paths (may be nil)
 
 	// Execution state
 	startTime             time.Time
@@ -38,6 +58,12 @@ type Executor struct {
 	qualityGateRetryCount int
 	sourceBranch          string // The branch we started from before creating a new one
 	retryPhaseActive      bool   // True when in quality gate retry phase with extended timeout
+	baseCommit            string // HEAD when the run started, set for checkpoint and squash commits
+	checkpointCommit      string // The first checkpoint commit, which later checkpoints fix up
+	lastCheckpoint        string // The latest checkpoint commit
+	promptTokens          int    // Token usage reported by the agent, for the cost estimate
+	completionTokens      int
+	refusedWrites         []string // Writes a dry run's path rules refused, reported in its summary
 }
 
 // NewExecutor creates a new headless executor with a pre-configured agent
@@ -62,44 +88,127 @@ func NewExecutor(ag agent.Agent, config *Config) (*Executor, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create constraint manager: %w", err)
 	}
+	constraintMgr.SetScope(config.Workspace.Paths)
 
 	// Create quality gate runner
-	gates := CreateQualityGates(config.QualityGates)
-	qualityGateRunner := NewQualityGateRunner(gates)
+	gates := CreateQualityGates(config.QualityGates, config.Workspace.Paths, constraintMgr.ModifiedFiles)
+
+	// Compare gate output with a pre-run baseline after all other gates
+	var verifier *BehaviorVerifier
+	if config.Verification.Enabled() {
+		verifier, err = NewBehaviorVerifier(gates, config.Verification)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verification configuration: %w", err)
+		}
+		gates = append(gates, verifier)
+	}
+	qualityGateRunner := NewQualityGateRunner(gates).WithSchedules(GateSchedules(config.QualityGates), constraintMgr.ModifiedFiles)
 
 	// Create artifact writer with workspace-relative path
-	artifactOutputDir := filepath.Join(config.WorkspaceDir, config.Artifacts.OutputDir)
+	artifactOutputDir := config.ArtifactDir()
 	artifactWriter := NewArtifactWriter(artifactOutputDir, config.Artifacts)
+	if config.Artifacts.Enabled {
+		qualityGateRunner.WithArtifacts(artifactWriter)
+	}
 
 	// Create git manager
 	gitManager := NewGitManager(config.WorkspaceDir, config.Git, config.ConfigFilePath)
-
-	// Extract LLM provider from agent (for PR generation)
+	// Files deleted to the trash or replaced with a backup are not part of
+	// the change, and neither are the artifacts, such as gate output written
+	// before a checkpoint commit, or the learnings recorded along the way
+	gitManager.ExcludeFromCommits(generatedPaths(config)...)
+
+	// Extract LLM provider from agent (for PR generation), swapping the agent's
+	// sampling parameters for the commit generator's and answering repeated
+	// requests from the response cache when it is on
 	var llmProvider llm.Provider
+	var model string
 	if defaultAgent, ok := ag.(*agent.DefaultAgent); ok {
-		llmProvider = defaultAgent.GetProvider()
+		llmProvider = llm.WithSampling(llm.WithResponseCache(defaultAgent.GetProvider(), llm.SharedResponseCache()), config.Sampling.Commit)
+		model = defaultAgent.GetProvider().GetModel()
+
+		// Keep the model aware of its remaining budget on every turn instead of
+		// letting it discover limits through rejected tool calls
+		defaultAgent.SetRuntimeContext(constraintMgr.PromptSection)
 	}
 
 	// Create logger based on logging configuration
 	// The config.Validate() method ensures Logging.Verbosity is set
 	logLevel := parseLogLevel(config.Logging.Verbosity)
-	logger := NewLogger(logLevel)
+	logger := NewLoggerWithFormat(logLevel, LogFormat(config.Logging.Format))
+
+	// Load fixes for recurring gate failures recorded by previous runs. A
+	// damaged knowledge base only costs the hints, so it never fails the run
+	var knowledge *KnowledgeBase
+	var fixes *FixRecorder
+	if config.Knowledge.Enabled {
+		knowledge, err = LoadKnowledgeBase(config.knowledgeDir())
+		if err != nil {
+			logger.Warningf("! Ignoring knowledge base: %v", err)
+			knowledge = &KnowledgeBase{path: knowledge.path}
+		}
+		fixes = NewFixRecorder(knowledge, config.WorkspaceDir)
+	}
+
+	if config.RunID == "" {
+		config.RunID = runID(time.Now())
+	}
 
-	return &Executor{
+	e := &Executor{
 		agent:                 ag,
 		config:                config,
 		constraintMgr:         constraintMgr,
 		qualityGates:          qualityGateRunner,
+		verifier:              verifier,
+		knowledge:             knowledge,
+		fixes:                 fixes,
 		artifactWriter:        artifactWriter,
 		gitManager:            gitManager,
+		changeUnits:           &ChangeUnits{},
 		llmProvider:           llmProvider,
+		model:                 model,
 		logger:                logger,
 		qualityGateRetryCount: 0,
 		summary: &ExecutionSummary{
-			Task:   config.Task,
-			Status: "running",
+			RunID:    config.RunID,
+			Task:     config.Task,
+			Status:   "running",
+			Sampling: samplingSummary(config.Sampling),
 		},
-	}, nil
+	}
+
+	// Check commands before they reach the approval manager, which would
+	// otherwise auto-approve commands on the user's whitelist unseen, and
+	// answer every approval here so constraints see each tool call
+	if defaultAgent, ok := ag.(*agent.DefaultAgent); ok {
+		defaultAgent.RegisterHook(hooks.PreToolCall, e.checkCommand)
+		defaultAgent.SetAutoApproval(false)
+		e.guard = defaultAgent.WorkspaceGuard()
+
+		// Let the agent say how an oversized diff should be split
+		if config.Git.StackMaxLines > 0 && config.Git.StackGroupBy == StackGroupByUnit {
+			if err := defaultAgent.RegisterTool(NewGroupChangesTool(e.changeUnits, config.Git.StackMaxLines)); err != nil {
+				return nil, fmt.Errorf("failed to register %s: %w", groupChangesToolName, err)
+			}
+		}
+	}
+
+	return e, nil
+}
+
+// SetOverlay sets the overlay the agent's tools write to in a dry run. It
+// must be the overlay passed to mock.Wrap for the agent's tools.
+func (e *Executor) SetOverlay(overlay *mock.Overlay) {
+	e.overlay = overlay
+}
+
+// samplingSummary returns the sampling configuration to record in execution.json,
+// or nil when no parameters were pinned.
+func samplingSummary(sampling SamplingConfig) *SamplingConfig {
+	if sampling.IsZero() {
+		return nil
+	}
+	return &sampling
 }
 
 // Run executes the headless task
@@ -110,10 +219,35 @@ func (e *Executor) Run(ctx context.Context) error {
 	e.summary.StartTime = e.startTime
 
 	e.logger.Infof("▶ Starting execution: %s", e.config.Task)
+	e.logger.Debugf("Run ID: %s", e.config.RunID)
+
+	if e.config.DryRun {
+		if e.overlay == nil {
+			return e.fail(fmt.Errorf("dry run requires the agent's tools to write to an overlay"))
+		}
+		e.logger.Infof("▶ Dry run: file writes and commands are simulated; nothing is committed or pushed")
+	}
 
 	// Validate workspace state
 	e.validateWorkspace()
 
+	// Install dependencies before the baseline and the agent need them
+	if e.config.Bootstrap.Enabled {
+		if e.config.DryRun {
+			e.logger.Infof("▶ Dry run: skipping project setup")
+		} else if err := e.runBootstrap(ctx); err != nil {
+			return e.fail(err)
+		}
+	}
+
+	// Record gate output on the untouched workspace before the agent runs
+	if e.verifier != nil && !e.config.DryRun {
+		e.logger.Infof("▶ Recording behavior baseline")
+		if err := e.verifier.RecordBaseline(ctx, e.config.WorkspaceDir, e.logger); err != nil {
+			return e.fail(err)
+		}
+	}
+
 	// Start agent
 	if err := e.agent.Start(ctx); err != nil {
 		return e.fail(fmt.Errorf("failed to start agent: %w", err))
@@ -134,6 +268,18 @@ func (e *Executor) Run(ctx context.Context) error {
 	// Get agent channels
 	channels := e.agent.GetChannels()
 
+	// Every agent event is a heartbeat for the stall watchdog
+	retries := 0
+	if e.config.Constraints.RetryOnStall {
+		retries = 1
+	}
+	watchdog := newStallWatchdog(e.config.Constraints.StallTimeout, retries)
+	defer watchdog.stop()
+
+	// Watch for a cancel file or a cancel from the cancel URL
+	cancelRequests := newCancelWatcher(e.config, e.config.RunID, e.logger)
+	defer cancelRequests.stop()
+
 	// Start event consumer in goroutine
 	eventDone := make(chan struct{})
 	turnEndReceived := false
@@ -141,24 +287,13 @@ func (e *Executor) Run(ctx context.Context) error {
 	go func() {
 		defer close(eventDone)
 		for event := range channels.Event {
+			watchdog.heartbeat()
+
 			// Log all events
 			e.logger.Debugf("Event received: Type=%s", event.Type)
 
 			// Log user-facing events
-			switch event.Type {
-			case types.EventTypeThinkingStart:
-				e.logger.Infof("● Thinking...")
-			case types.EventTypeAPICallStart:
-				e.logger.Infof("~ API call...")
-			case types.EventTypeToolCall:
-				e.logger.Infof("> tool: %s", e.formatToolCall(event))
-			case types.EventTypeToolResult:
-				e.logger.Infof("✓ tool: %s", e.formatToolCall(event))
-			case types.EventTypeToolResultError:
-				e.logger.Infof("✗ tool: %s", e.formatToolCall(event))
-			case types.EventTypeContextSummarizationStart:
-				e.logger.Infof("~ Summarizing context...")
-			}
+			e.logger.AgentEvent(event)
 
 			// Handle approval requests - validate against constraints and auto-approve
 			if event.Type == types.EventTypeToolApprovalRequest {
@@ -175,6 +310,13 @@ func (e *Executor) Run(ctx context.Context) error {
 				if err := fileTracker.TrackToolCall(event); err != nil {
 					e.logger.Debugf("Error tracking file modification: %v", err)
 				}
+
+				// The completion summary describes the fix if the next gate run passes
+				if e.fixes != nil && event.ToolName == "task_completion" {
+					if result, ok := event.ToolInput["result"].(string); ok {
+						e.fixes.TrackCompletion(result)
+					}
+				}
 			}
 
 			// Confirm successful file modifications
@@ -182,6 +324,11 @@ func (e *Executor) Run(ctx context.Context) error {
 				e.logger.Debugf("Tool result event - ToolName: %s", event.ToolName)
 				fileTracker.ConfirmModification(event)
 
+				// Keep the latest plan for the summary
+				if items, ok := event.Metadata[todo.MetadataKey].([]todo.Item); ok {
+					e.summary.TodoList = items
+				}
+
 				// Sync file modification to constraint manager for metrics tracking
 				if event.Metadata != nil {
 					if path, ok := event.Metadata["file_path"].(string); ok {
@@ -198,6 +345,21 @@ func (e *Executor) Run(ctx context.Context) error {
 							e.logger.Warningf("Constraint violation: %v", err)
 							// Don't fail execution, just log the violation
 						}
+						if e.fixes != nil {
+							e.fixes.TrackFile(path)
+						}
+					}
+
+					// Multi-file tools such as rename_symbol report every file they touched
+					if paths, ok := event.Metadata["modified_files"].([]string); ok {
+						for _, path := range paths {
+							if err := e.constraintMgr.RecordFileModification(path, 0, 0); err != nil {
+								e.logger.Warningf("Constraint violation: %v", err)
+							}
+							if e.fixes != nil {
+								e.fixes.TrackFile(path)
+							}
+						}
 					}
 				}
 			}
@@ -205,10 +367,25 @@ func (e *Executor) Run(ctx context.Context) error {
 			// Cancel failed file modifications
 			if event.Type == types.EventTypeToolResultError {
 				fileTracker.CancelModification(event)
+
+				// A dry run reports the writes a real run would refuse, since
+				// they are missing from its changes
+				var ruleErr *workspace.PathRuleError
+				if e.overlay != nil && errors.As(event.Error, &ruleErr) && ruleErr.Op == workspace.OpWrite {
+					e.refusedWrites = append(e.refusedWrites, ruleErr.Error())
+				}
+			}
+
+			// Count moves to the fallback model so the summary shows the run
+			// was partly done on it
+			if event.Type == types.EventTypeModelFallback {
+				e.summary.ModelFallbacks++
 			}
 
 			// Track token usage
 			if event.Type == types.EventTypeTokenUsage && event.TokenUsage != nil {
+				e.promptTokens += event.TokenUsage.PromptTokens
+				e.completionTokens += event.TokenUsage.CompletionTokens
 				if err := e.constraintMgr.RecordTokenUsage(event.TokenUsage.TotalTokens); err != nil {
 					e.logger.Errorf("Token limit exceeded: %v", err)
 					// Set execution to failed state
@@ -226,14 +403,33 @@ func (e *Executor) Run(ctx context.Context) error {
 				}
 			}
 
+			// A turn canceled by the watchdog did not complete the task
+			if event.Type == types.EventTypeTurnEnd {
+				if interrupted, retry := watchdog.turnEnded(); interrupted {
+					if retry {
+						e.retryStalledTurn()
+					}
+					continue
+				}
+			}
+
 			// Track turn end - this signals task completion
 			if event.Type == types.EventTypeTurnEnd {
 				turnEndReceived = true
-				e.logger.Infof("? Running quality gates...")
 
-				// Run quality gates before shutdown
-				if len(e.qualityGates.gates) > 0 {
+				// Run quality gates before shutdown. A dry run's changes are
+				// not on disk, so its gates are only predicted in finalize
+				if len(e.qualityGates.gates) > 0 && !e.config.DryRun {
+					e.logger.Infof("? Running quality gates...")
+					watchdog.pause()
 					results := e.qualityGates.RunAll(ctx, e.config.WorkspaceDir, e.logger)
+					watchdog.resume()
+					if e.fixes != nil {
+						if recorded := e.fixes.Observe(results); recorded > 0 {
+							e.logger.Infof("→ Recorded %d gate fix(es) in the knowledge base", recorded)
+						}
+					}
+					e.checkpoint(ctx, results)
 
 					if !results.AllPassed {
 						e.qualityGateRetryCount++
@@ -252,19 +448,33 @@ func (e *Executor) Run(ctx context.Context) error {
 						e.summary.QualityGateResults.AllPassed = results.AllPassed
 						e.summary.QualityGateResults.Results = results.Results
 
+						// Infrastructure failures are dead-lettered: the agent cannot fix them,
+						// so they must not consume one of its feedback loops
+						infraOnly := results.HasOnlyInfrastructureFailures()
+
 						// Check if we've exceeded max retries
-						if e.qualityGateRetryCount >= e.config.QualityGateMaxRetries {
-							e.logger.Errorf("✗ Max quality gate retries exceeded")
+						if infraOnly || e.qualityGateRetryCount >= e.config.QualityGateMaxRetries {
+							reason := fmt.Sprintf("Quality gates failed after %d attempts", e.qualityGateRetryCount)
+							if infraOnly {
+								e.logger.Errorf("✗ Quality gates could not run (infrastructure error); not asking the agent to fix them")
+								reason = "Quality gates could not run because of infrastructure errors"
+							} else {
+								e.logger.Errorf("✗ Max quality gate retries exceeded")
+							}
 
 							// Set status based on commit_on_quality_fail configuration
-							if e.config.Git.CommitOnQualityFail {
+							if results.HasSecretFindings() {
+								e.logger.Errorf("✗ Secrets found in the changes; failing execution without commit")
+								e.summary.Status = statusFailed
+								e.summary.Error = fmt.Sprintf("%s with secrets in the changes:\n%s", reason, results.FormatErrorMessage())
+							} else if e.config.Git.CommitOnQualityFail {
 								e.logger.Warningf("! Will commit partial work despite quality gate failures")
 								e.summary.Status = statusPartialSuccess
-								e.summary.Error = fmt.Sprintf("Quality gates failed after %d attempts, but changes were committed:\n%s", e.qualityGateRetryCount, results.FormatErrorMessage())
+								e.summary.Error = fmt.Sprintf("%s, but changes were committed:\n%s", reason, results.FormatErrorMessage())
 							} else {
 								e.logger.Errorf("✗ Failing execution without commit")
 								e.summary.Status = statusFailed
-								e.summary.Error = fmt.Sprintf("Quality gates failed after %d attempts:\n%s", e.qualityGateRetryCount, results.FormatErrorMessage())
+								e.summary.Error = fmt.Sprintf("%s:\n%s", reason, results.FormatErrorMessage())
 							}
 
 							// Signal shutdown after max retries
@@ -277,6 +487,12 @@ func (e *Executor) Run(ctx context.Context) error {
 						} else {
 							// Send feedback to agent for retry
 							feedbackMsg := results.FormatFeedbackMessage(e.qualityGateRetryCount, e.config.QualityGateMaxRetries)
+							if e.fixes != nil {
+								if known := e.fixes.KnownFixes(results); known != "" {
+									e.logger.Infof("→ Including known fixes from previous runs")
+									feedbackMsg += "\n\n" + known
+								}
+							}
 							e.logger.Infof("→ Sending quality gate feedback to agent for retry")
 
 							// On first retry, switch to retry timeout context
@@ -331,8 +547,8 @@ func (e *Executor) Run(ctx context.Context) error {
 						}
 					}
 				} else {
-					// No quality gates configured, proceed with normal shutdown
-					e.logger.Debugf("No quality gates configured, proceeding with shutdown")
+					// No quality gates to run, proceed with normal shutdown
+					e.logger.Debugf("No quality gates to run, proceeding with shutdown")
 					select {
 					case e.agent.GetChannels().Shutdown <- struct{}{}:
 						e.logger.Debugf("Shutdown signal sent to agent on turn end")
@@ -353,18 +569,32 @@ func (e *Executor) Run(ctx context.Context) error {
 	// Send task to agent
 	channels.Input <- types.NewUserInput(e.config.Task)
 
-	// Wait for completion or timeout
-	timedOut := false
-	select {
-	case <-channels.Done:
-		e.logger.Debugf("Agent completed - Done channel closed")
-	case <-execCtx.Done():
-		if execCtx.Err() == context.DeadlineExceeded {
-			e.logger.Warningf("! Execution timeout exceeded - will attempt to preserve completed work")
-			timedOut = true
-			// Don't return early - let finalize() handle git operations
-		} else {
-			return e.fail(fmt.Errorf("execution canceled: %w", execCtx.Err()))
+	// Wait for completion, timeout, a cancel request or a stall that is not
+	// retried
+	timedOut, stalled, canceled := false, false, ""
+wait:
+	for {
+		select {
+		case <-channels.Done:
+			e.logger.Debugf("Agent completed - Done channel closed")
+			break wait
+		case <-execCtx.Done():
+			if execCtx.Err() == context.DeadlineExceeded {
+				e.logger.Warningf("! Execution timeout exceeded - will attempt to preserve completed work")
+				timedOut = true
+				// Don't return early - let finalize() handle git operations
+				break wait
+			}
+			return e.cancel(fmt.Errorf("execution canceled: %w", execCtx.Err()))
+		case canceled = <-cancelRequests.C():
+			e.stopForCancel(canceled)
+			break wait
+		case <-watchdog.C():
+			if e.cancelStalledTurn(watchdog) {
+				continue
+			}
+			stalled = true
+			break wait
 		}
 	}
 
@@ -373,6 +603,14 @@ func (e *Executor) Run(ctx context.Context) error {
 	<-eventDone
 	e.logger.Debugf("Event consumer finished")
 
+	if canceled != "" {
+		return e.cancel(fmt.Errorf("execution canceled: %s", canceled))
+	}
+
+	if stalled {
+		return e.failWithStatus(statusStalled, fmt.Errorf("no agent activity for %s (%d stall(s)); the execution stalled", e.config.Constraints.StallTimeout, watchdog.stallCount()))
+	}
+
 	// Handle timeout after events are processed
 	if timedOut {
 		e.summary.Status = statusPartialSuccess
@@ -390,6 +628,66 @@ func (e *Executor) Run(ctx context.Context) error {
 	return e.finalize(ctx)
 }
 
+// cancelStalledTurn cancels a turn that stopped emitting events. It reports
+// whether the turn will be retried; otherwise the agent is shut down.
+func (e *Executor) cancelStalledTurn(watchdog *stallWatchdog) bool {
+	retry := watchdog.stall()
+	e.logger.Warningf("! No agent activity for %s - canceling the stalled turn", e.config.Constraints.StallTimeout)
+
+	select {
+	case e.agent.GetChannels().Input <- types.NewCancelInput():
+		e.logger.Debugf("Cancel sent to the stalled turn")
+	default:
+		e.logger.Debugf("Failed to cancel the stalled turn, input channel blocked")
+	}
+
+	if retry {
+		e.logger.Infof("→ Retrying once the stalled turn ends")
+		return true
+	}
+
+	// The agent may still be handling the cancellation, so wait for it to
+	// take the shutdown signal rather than dropping it
+	e.logger.Errorf("✗ Execution stalled")
+	select {
+	case e.agent.GetChannels().Shutdown <- struct{}{}:
+		e.logger.Debugf("Shutdown signal sent after stall")
+	case <-e.agent.GetChannels().Done:
+		e.logger.Debugf("Agent already shut down")
+	}
+	return false
+}
+
+// stopForCancel cancels the agent's current turn and shuts the agent down
+// after a cancel request
+func (e *Executor) stopForCancel(reason string) {
+	e.logger.Warningf("! Cancel requested: %s", reason)
+
+	select {
+	case e.agent.GetChannels().Input <- types.NewCancelInput():
+		e.logger.Debugf("Cancel sent to the current turn")
+	default:
+		e.logger.Debugf("Failed to cancel the current turn, input channel blocked")
+	}
+	select {
+	case e.agent.GetChannels().Shutdown <- struct{}{}:
+		e.logger.Debugf("Shutdown signal sent after cancel request")
+	case <-e.agent.GetChannels().Done:
+		e.logger.Debugf("Agent already shut down")
+	}
+}
+
+// retryStalledTurn asks the agent to continue after its stalled turn was
+// canceled
+func (e *Executor) retryStalledTurn() {
+	select {
+	case e.agent.GetChannels().Input <- types.NewUserInput(stallRetryMessage):
+		e.logger.Debugf("Stall retry sent to agent")
+	default:
+		e.logger.Errorf("Failed to send stall retry, input channel blocked")
+	}
+}
+
 // handleApprovalRequest handles tool approval requests by validating against constraints
 // and auto-approving (or rejecting) the tool call
 func (e *Executor) handleApprovalRequest(approvalChan chan<- *types.ApprovalResponse, event *types.AgentEvent) {
@@ -407,16 +705,55 @@ func (e *Executor) handleApprovalRequest(approvalChan chan<- *types.ApprovalResp
 	// Validate against constraints
 	if err := e.constraintMgr.ValidateToolCall(toolName, toolInput); err != nil {
 		e.logger.Warningf("Tool call rejected due to constraint violation: %v", err)
+		e.constraintMgr.RecordViolation(toolName, err)
 		// Send rejection response
 		approvalChan <- types.NewApprovalResponse(approvalID, types.ApprovalRejected)
 		return
 	}
 
+	// Approval rules shared with the TUI can deny calls the constraints
+	// allow. There is no one to ask, so ask is treated as allow.
+	preview, _ := event.Preview.(*tools.ToolPreview)
+	if rule, matched := appconfig.EvaluateApprovalRules(approval.NewApprovalRequest(toolName, toolInput, preview, e.guard)); matched && rule.Decision == appconfig.ApprovalDeny {
+		err := &ConstraintViolation{
+			Type:    ViolationApprovalRule,
+			Message: fmt.Sprintf("tool '%s' is denied by %s", toolName, rule.Reason()),
+			Details: map[string]any{
+				"rule":   rule.Rule,
+				"source": rule.Source,
+			},
+		}
+		e.logger.Warningf("Tool call rejected: %v", err)
+		e.constraintMgr.RecordViolation(toolName, err)
+		approvalChan <- types.NewApprovalResponse(approvalID, types.ApprovalRejected)
+		return
+	}
+
 	e.logger.Debugf("Tool call approved: %s", toolName)
 	// Send approval response
 	approvalChan <- types.NewApprovalResponse(approvalID, types.ApprovalGranted)
 }
 
+// checkCommand is a pre-tool-call hook that vetoes execute_command calls
+// violating the command policy, so the model is told why its command was
+// blocked
+func (e *Executor) checkCommand(_ context.Context, event *hooks.Event) error {
+	if event.ToolName != "execute_command" {
+		return nil
+	}
+	args, err := event.Arguments()
+	if err != nil {
+		return nil // The tool reports malformed arguments itself
+	}
+
+	if err := e.constraintMgr.ValidateCommand(args); err != nil {
+		e.logger.Warningf("Tool call rejected due to constraint violation: %v", err)
+		e.constraintMgr.RecordViolation(event.ToolName, err)
+		return err
+	}
+	return nil
+}
+
 // Stop gracefully stops the executor
 func (e *Executor) Stop(ctx context.Context) error {
 	return e.agent.Shutdown(ctx)
@@ -427,8 +764,8 @@ func (e *Executor) validateWorkspace() {
 	// Check if workspace directory exists
 	// TODO: Add directory existence check
 
-	// Check git status if git operations are enabled
-	if e.config.Git.AutoCommit {
+	// Check git status if git operations are enabled; a dry run makes none
+	if e.config.Git.AutoCommit && !e.config.DryRun {
 		ctx := context.Background()
 
 		// Check if workspace is clean
@@ -439,14 +776,18 @@ func (e *Executor) validateWorkspace() {
 
 		// Get current branch
 		currentBranch, err := e.gitManager.GetCurrentBranch(ctx)
-		if err != nil {
+		switch {
+		case err != nil:
 			e.logger.Warningf("! Could not determine current branch: %v", err)
-		} else {
+		case currentBranch == "":
+			e.logger.Infof("± Current branch: none (detached HEAD)")
+		default:
 			e.logger.Infof("± Current branch: %s", currentBranch)
 		}
 
-		// Create and checkout the specified branch if configured
-		if e.config.Git.Branch != "" && currentBranch != "" {
+		// Create and checkout the specified branch if configured. A detached
+		// HEAD, as in a use_worktree run, gets the branch too.
+		if e.config.Git.Branch != "" && err == nil {
 			if currentBranch != e.config.Git.Branch {
 				e.sourceBranch = currentBranch
 				e.logger.Infof("± Creating and checking out branch: %s", e.config.Git.Branch)
@@ -462,17 +803,65 @@ func (e *Executor) validateWorkspace() {
 			}
 		}
 
+		// Checkpoint and squash commits are counted from where the run starts
+		if e.config.Git.CheckpointCommits || e.config.Git.SquashCommits {
+			if head, err := e.gitManager.HeadCommit(ctx); err != nil {
+				e.logger.Warningf("! No base commit to checkpoint or squash the run's commits on: %v", err)
+			} else {
+				e.baseCommit = head
+			}
+		}
+
 		e.logger.Debugf("Git auto-commit enabled")
 	}
 }
 
+// checkpoint commits the agent's work after a quality gate run, so each
+// attempt is a commit of its own: the first with the run's commit message,
+// the rest as fixups for it. Changes with secrets in them are not committed.
+func (e *Executor) checkpoint(ctx context.Context, results *QualityGateResults) {
+	if !e.config.Git.CheckpointCommits || e.baseCommit == "" || results.HasSecretFindings() {
+		return
+	}
+
+	var committed bool
+	var err error
+	if e.checkpointCommit == "" {
+		committed, err = e.gitManager.Checkpoint(ctx, e.gitManager.GenerateCommitMessage(ctx, e.config.Task))
+	} else {
+		committed, err = e.gitManager.CommitFixup(ctx, e.checkpointCommit)
+	}
+	if err != nil {
+		e.logger.Warningf("! Failed to create checkpoint commit: %v", err)
+		return
+	}
+	if !committed {
+		return
+	}
+
+	head, err := e.gitManager.HeadCommit(ctx)
+	if err != nil {
+		e.logger.Warningf("! %v", err)
+		return
+	}
+	if e.checkpointCommit == "" {
+		e.checkpointCommit = head
+	}
+	e.lastCheckpoint = head
+	e.logger.Infof("± Checkpoint commit %s for gate attempt %d", shortHash(head), e.qualityGateRetryCount+1)
+}
+
 // finalize completes the execution and generates artifacts
 func (e *Executor) finalize(ctx context.Context) error {
 	e.summary.EndTime = time.Now()
 	e.summary.Duration = e.summary.EndTime.Sub(e.summary.StartTime)
+	if e.verifier != nil {
+		e.summary.BehaviorVerification = e.verifier.Report()
+	}
 
 	// Get constraint state
 	state := e.constraintMgr.GetCurrentState()
+	e.summary.Violations = e.constraintMgr.Violations()
 
 	// Calculate total lines from FilesModified
 	totalLinesAdded := 0
@@ -490,6 +879,7 @@ func (e *Executor) finalize(ctx context.Context) error {
 		TotalLinesRemoved: totalLinesRemoved,
 		TokensUsed:        state.TokensUsed,
 		Iterations:        e.summary.ToolCallCount, // Each tool call represents one iteration of the agent loop
+		CostUSD:           e.estimatedCost(),
 	}
 
 	// Quality gates have already been run during the event loop at turn end
@@ -508,30 +898,50 @@ func (e *Executor) finalize(ctx context.Context) error {
 		e.summary.Status = statusSuccess
 	}
 
+	// Record the run's changes before they are committed
+	e.captureChanges(ctx)
+
 	// Commit changes if configured and status allows it
 	// Commit on: statusSuccess or partial_success (when commit_on_quality_fail is true)
-	if e.config.Git.AutoCommit && (e.summary.Status == statusSuccess || e.summary.Status == statusPartialSuccess) {
+	if e.config.Git.AutoCommit && !e.config.DryRun && (e.summary.Status == statusSuccess || e.summary.Status == statusPartialSuccess) {
 		if err := e.commitChanges(ctx); err != nil {
 			e.logger.Warningf("! Failed to commit changes: %v", err)
 			// Don't fail the execution, just log the warning
 		}
+	} else if e.checkpointCommit != "" {
+		// A run that is not committed leaves its changes uncommitted
+		if err := e.gitManager.Uncommit(ctx, e.baseCommit); err != nil {
+			e.logger.Warningf("! Failed to undo checkpoint commits: %v", err)
+		} else {
+			e.logger.Infof("± Undid checkpoint commits; changes left uncommitted")
+		}
+	}
+
+	// Save the knowledge base after committing so it stays out of the commit
+	if e.knowledge != nil && !e.config.DryRun {
+		if err := e.knowledge.Save(); err != nil {
+			e.logger.Warningf("! Failed to save knowledge base: %v", err)
+		}
 	}
 
 	// Generate artifacts if enabled
 	if e.config.Artifacts.Enabled {
+		e.summary.Screenshots = e.artifactWriter.Screenshots()
 		if err := e.artifactWriter.WriteAll(e.summary); err != nil {
 			e.logger.Warningf("! Failed to write artifacts: %v", err)
 		} else {
 			e.logger.Successf("Artifacts written to %s", e.config.Artifacts.OutputDir)
 		}
+		uploadArtifacts(e.config, e.summary.StartTime, e.logger)
 	}
+	sendNotifications(e.config, newRunOutcome(e.summary), e.logger)
 
 	// Log final status
 	statusIcon := "■"
 	switch e.summary.Status {
 	case statusSuccess:
 		statusIcon = "✓"
-	case statusFailed:
+	case statusFailed, statusStalled, statusCanceled:
 		statusIcon = "✗"
 	case statusPartialSuccess:
 		statusIcon = "!"
@@ -554,18 +964,28 @@ func (e *Executor) commitChanges(ctx context.Context) error {
 		return fmt.Errorf("failed to check for changes: %w", err)
 	}
 
-	if len(changedFiles) == 0 {
+	if len(changedFiles) == 0 && e.checkpointCommit == "" {
 		e.logger.Infof("± No changes to commit")
 		return nil
 	}
 
-	e.logger.Infof("± Staging %d changed file(s)", len(changedFiles))
+	if len(changedFiles) > 0 {
+		e.logger.Infof("± Staging %d changed file(s)", len(changedFiles))
+	}
 
 	// Generate commit message
 	message := e.gitManager.GenerateCommitMessage(ctx, e.config.Task)
 
+	// Split an oversized diff into a stack of dependent PRs
+	if e.config.Git.StackMaxLines > 0 {
+		split, err := e.commitStack(ctx, message)
+		if err != nil || split {
+			return err
+		}
+	}
+
 	// Create commit (this will exclude the config file if set)
-	if err := e.gitManager.Commit(ctx, message); err != nil {
+	if err := e.commitRun(ctx, message); err != nil {
 		return fmt.Errorf("failed to create commit: %w", err)
 	}
 
@@ -591,43 +1011,162 @@ func (e *Executor) commitChanges(ctx context.Context) error {
 	return nil
 }
 
+// commitRun commits the run's remaining changes. With squash_commits, every
+// commit made since the run started is folded into the one commit; with
+// checkpoint commits, the changes are folded into the last checkpoint and
+// the checkpoints are autosquashed into the first.
+func (e *Executor) commitRun(ctx context.Context, message string) error {
+	switch {
+	case e.config.Git.SquashCommits && e.baseCommit != "":
+		count, err := e.gitManager.CommitsSince(ctx, e.baseCommit)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			e.logger.Infof("± Squashing %d commit(s) made during the run", count)
+		}
+		return e.gitManager.Squash(ctx, e.baseCommit, message)
+
+	case e.checkpointCommit != "":
+		head, err := e.gitManager.HeadCommit(ctx)
+		if err != nil {
+			return err
+		}
+		// The agent may have committed since the last checkpoint
+		if head == e.lastCheckpoint {
+			_, err = e.gitManager.Amend(ctx)
+		} else {
+			_, err = e.gitManager.CommitFixup(ctx, e.checkpointCommit)
+		}
+		if err != nil {
+			return err
+		}
+		if err := e.gitManager.Autosquash(ctx, e.baseCommit); err != nil {
+			e.logger.Warningf("! Keeping checkpoint commits unsquashed: %v", err)
+		}
+		if e.config.Git.AutoPush {
+			if err := e.gitManager.Push(ctx); err != nil {
+				return fmt.Errorf("failed to auto-push: %w", err)
+			}
+		}
+		return nil
+	}
+
+	return e.gitManager.Commit(ctx, message)
+}
+
+// captureChanges writes the workspace's uncommitted changes to the
+// changes.patch artifact and records their per-file stats in the summary, so
+// reviewers can inspect a run without checking out its branch
+func (e *Executor) captureChanges(ctx context.Context) {
+	if e.config.DryRun {
+		e.captureDryRun(ctx)
+		return
+	}
+	if !e.config.Artifacts.Enabled || !e.config.Artifacts.Patch || e.summary.Changes != nil {
+		return
+	}
+	if !e.gitManager.IsRepository(ctx) {
+		e.logger.Debugf("Workspace is not a git repository; not writing %s", changesPatchName)
+		return
+	}
+
+	// Checkpoint commits are part of the run's changes
+	patch, files, err := e.gitManager.ChangesSince(ctx, e.baseCommit, generatedPaths(e.config)...)
+	if err != nil {
+		e.logger.Warningf("! Failed to capture changes: %v", err)
+		return
+	}
+	patchName, err := e.artifactWriter.WriteChangesPatch(patch)
+	if err != nil {
+		e.logger.Warningf("! %v", err)
+		return
+	}
+	e.summary.Changes = newChangeSummary(patchName, files)
+}
+
+// captureDryRun records the commands a dry run would have run and the gates
+// its changes would run, and writes its changes to the changes.patch
+// artifact
+func (e *Executor) captureDryRun(ctx context.Context) {
+	if e.overlay == nil || e.summary.DryRun != nil {
+		return
+	}
+
+	changes := e.overlay.Changes()
+	changedFiles := make([]string, len(changes))
+	for i, change := range changes {
+		changedFiles[i] = change.Path
+	}
+	e.summary.DryRun = &DryRunSummary{
+		Commands: e.overlay.Commands(),
+		Refused:  e.refusedWrites,
+		Gates:    e.qualityGates.Predict(changedFiles),
+	}
+	for _, refusal := range e.refusedWrites {
+		e.logger.Infof("  ✗ Refused: %s", refusal)
+	}
+	for _, gate := range e.summary.DryRun.Gates {
+		if gate.WouldRun {
+			e.logger.Infof("  → Quality gate %s would run: %s", gate.Name, gate.Reason)
+		} else {
+			e.logger.Infof("  ↷ Quality gate %s would be skipped: %s", gate.Name, gate.Reason)
+		}
+	}
+
+	if !e.config.Artifacts.Enabled {
+		return
+	}
+	patch, files, err := overlayPatch(ctx, changes)
+	if err != nil {
+		e.logger.Warningf("! Failed to capture dry run changes: %v", err)
+		return
+	}
+	patchName, err := e.artifactWriter.WriteChangesPatch(patch)
+	if err != nil {
+		e.logger.Warningf("! %v", err)
+		return
+	}
+	e.summary.Changes = newChangeSummary(patchName, files)
+}
+
 // fail marks the execution as failed and returns an error
 func (e *Executor) fail(err error) error {
-	e.summary.Status = statusFailed
+	return e.failWithStatus(statusFailed, err)
+}
+
+// failWithStatus ends the execution with a failure status such as
+// statusStalled and returns err
+func (e *Executor) failWithStatus(status string, err error) error {
+	e.summary.Status = status
 	e.summary.Error = err.Error()
 	e.summary.EndTime = time.Now()
 	e.summary.Duration = e.summary.EndTime.Sub(e.startTime)
+	e.summary.Violations = e.constraintMgr.Violations()
+	e.summary.Metrics.TokensUsed = e.constraintMgr.GetCurrentState().TokensUsed
+	e.summary.Metrics.CostUSD = e.estimatedCost()
 
 	// Try to generate artifacts even on failure
 	if e.config.Artifacts.Enabled {
+		e.captureChanges(context.Background())
+		e.summary.Screenshots = e.artifactWriter.Screenshots()
 		if artifactErr := e.artifactWriter.WriteAll(e.summary); artifactErr != nil {
 			e.logger.Warningf("! Failed to write failure artifacts: %v", artifactErr)
 		}
+		uploadArtifacts(e.config, e.startTime, e.logger)
 	}
+	sendNotifications(e.config, newRunOutcome(e.summary), e.logger)
 
 	return err
 }
 
-// formatToolCall formats a tool call for display, showing parameters when available
-func (e *Executor) formatToolCall(event *types.AgentEvent) string {
-	if len(event.ToolInput) == 0 {
-		return event.ToolName
+// estimatedCost returns the cost in USD of the tokens the agent used at its
+// model's price, or 0 when the price is unknown. Calls made on the fallback
+// model are priced as the main model's.
+func (e *Executor) estimatedCost() float64 {
+	price, ok := llm.PricingForModel(e.model)
+	if !ok {
+		return 0
 	}
-
-	// Build a compact parameter summary
-	var params strings.Builder
-	count := 0
-	for key := range event.ToolInput {
-		if count > 0 {
-			params.WriteString(", ")
-		}
-		params.WriteString(key)
-		count++
-		if count >= 3 {
-			params.WriteString(", ...")
-			break
-		}
-	}
-
-	return fmt.Sprintf("%s(%s)", event.ToolName, params.String())
+	return price.Cost(e.promptTokens, e.completionTokens)
 }
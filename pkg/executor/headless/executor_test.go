@@ -5,7 +5,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/types"
 )
 
 // setupGitRepo creates a temporary git repository for testing
@@ -153,3 +158,101 @@ func TestGitManager_BranchAlreadyExists(t *testing.T) {
 		t.Errorf("expected branch %q, got %q", config.Branch, currentBranch)
 	}
 }
+
+func TestExecutor_HandleApprovalRequest_ApprovalRules(t *testing.T) {
+	appconfig.SetProjectConfig(&appconfig.ProjectConfig{ApprovalRules: []appconfig.ApprovalRule{
+		{Name: "large-diffs", Tools: []string{"apply_diff"}, DiffLines: "> 2", Decision: appconfig.ApprovalDeny},
+		{Tools: []string{"apply_diff"}, Decision: appconfig.ApprovalAsk},
+	}})
+	t.Cleanup(func() { appconfig.SetProjectConfig(nil) })
+
+	cm, err := NewConstraintManager(ConstraintConfig{}, ModeWrite)
+	if err != nil {
+		t.Fatalf("Failed to create constraint manager: %v", err)
+	}
+	e := &Executor{constraintMgr: cm, logger: NewLogger(LogLevelQuiet)}
+
+	request := func(id, diff string) types.ApprovalDecision {
+		approvals := make(chan *types.ApprovalResponse, 1)
+		preview := &tools.ToolPreview{Type: tools.PreviewTypeDiff, Content: diff, Metadata: map[string]any{"file_path": "main.go"}}
+		e.handleApprovalRequest(approvals, types.NewToolApprovalRequestEvent(id, "apply_diff", map[string]any{"path": "main.go"}, preview))
+		return (<-approvals).Decision
+	}
+
+	// Ask has no one to answer it, so it is granted within the constraints
+	if decision := request("small", "@@ -1 +1 @@\n-a\n+b\n"); decision != types.ApprovalGranted {
+		t.Errorf("expected a small diff to be granted, got %s", decision)
+	}
+
+	if decision := request("large", "@@ -1 +1,3 @@\n-a\n+b\n+c\n"); decision != types.ApprovalRejected {
+		t.Errorf("expected a large diff to be rejected, got %s", decision)
+	}
+	violations := cm.Violations()
+	if len(violations) != 1 || violations[0].Type != ViolationApprovalRule {
+		t.Errorf("expected an approval rule violation, got %+v", violations)
+	}
+}
+
+// runHistoryTestRun runs an agent that commits a.txt itself and leaves b.txt
+// uncommitted, with one quality gate running gate
+func runHistoryTestRun(t *testing.T, gate string, configure func(*Config)) string {
+	t.Helper()
+	dir := setupFanOutRepo(t)
+	config := DefaultConfig()
+	config.Task = "Add files"
+	config.WorkspaceDir = dir
+	config.Logging.Verbosity = "quiet"
+	config.Git.AutoCommit = true
+	config.Git.Branch = "forge/history"
+	config.Git.CommitMessage = "feat: add files"
+	config.QualityGates = []QualityGateConfig{{Name: "gate", Command: gate, Required: true}}
+	config.QualityGateMaxRetries = 1
+	configure(config)
+
+	ag := newScriptedAgent(func() error {
+		if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o644); err != nil {
+			return err
+		}
+		if err := exec.Command("git", "-C", dir, "add", "a.txt").Run(); err != nil {
+			return err
+		}
+		if err := exec.Command("git", "-C", dir, "commit", "-q", "-m", "wip").Run(); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b\n"), 0o644)
+	})
+	executor, err := NewExecutor(ag, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = executor.Run(context.Background())
+	return dir
+}
+
+func TestExecutor_SquashCommits(t *testing.T) {
+	dir := runHistoryTestRun(t, "true", func(config *Config) {
+		config.Git.SquashCommits = true
+		config.Git.CheckpointCommits = true
+	})
+
+	if got := gitOutput(t, dir, "log", "--format=%s", "main..forge/history"); got != "feat: add files" {
+		t.Errorf("expected the run squashed into one commit, got %q", got)
+	}
+	if got := gitOutput(t, dir, "show", "--name-only", "--format=", "forge/history"); got != "a.txt\nb.txt" {
+		t.Errorf("expected the commit to hold both files, got %q", got)
+	}
+}
+
+func TestExecutor_CheckpointCommitsUndoneOnFailure(t *testing.T) {
+	dir := runHistoryTestRun(t, "false", func(config *Config) {
+		config.Git.CheckpointCommits = true
+	})
+
+	if got := gitOutput(t, dir, "rev-parse", "HEAD"); got != gitOutput(t, dir, "rev-parse", "main") {
+		t.Errorf("expected the failed run's commits to be undone, HEAD is %s", got)
+	}
+	status := gitOutput(t, dir, "status", "--porcelain", "--", "a.txt", "b.txt")
+	if !strings.Contains(status, "a.txt") || !strings.Contains(status, "b.txt") {
+		t.Errorf("expected the changes left uncommitted, got:\n%s", status)
+	}
+}
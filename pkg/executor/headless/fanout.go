@@ -0,0 +1,699 @@
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/agent/projectmemory"
+	"github.com/entrhq/forge/pkg/tools/coding"
+)
+
+const (
+	// FanOutByPackage runs the task once per package
+	FanOutByPackage = "package"
+
+	// FanOutPRSingle commits every package to one branch and opens one PR
+	FanOutPRSingle = "single"
+	// FanOutPRStacked gives each package a branch stacked on the previous
+	// package's, with a PR per package
+	FanOutPRStacked = "stacked"
+
+	statusSkipped = "skipped"
+)
+
+// FanOutConfig splits one logical task into a sub-execution per package.
+//
+// Example:
+//
+//	fan_out:
+//	  by: package
+//	  include: ["services/**"]
+//	  pr: stacked
+type FanOutConfig struct {
+	By            string   `yaml:"by" json:"by"`                           // "package", or empty to disable fan-out
+	Discovery     string   `yaml:"discovery" json:"discovery"`             // go (default) or command
+	Command       string   `yaml:"command" json:"command"`                 // Discovery command printing one package directory per line
+	Include       []string `yaml:"include" json:"include"`                 // Package directory globs to run (default: all)
+	Exclude       []string `yaml:"exclude" json:"exclude"`                 // Package directory globs to skip
+	MaxPackages   int      `yaml:"max_packages" json:"max_packages"`       // Fail when discovery finds more packages (default: no limit)
+	PR            string   `yaml:"pr" json:"pr"`                           // single (default) or stacked
+	StopOnFailure bool     `yaml:"stop_on_failure" json:"stop_on_failure"` // Skip the remaining packages once one fails
+}
+
+// Enabled reports whether the task fans out.
+func (c FanOutConfig) Enabled() bool {
+	return c.By != ""
+}
+
+// stacked reports whether each package gets its own stacked branch.
+func (c FanOutConfig) stacked() bool {
+	return c.PR == FanOutPRStacked
+}
+
+// validate checks the fan-out settings against the git settings they need.
+func (c FanOutConfig) validate(gitConfig GitConfig) error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.By != FanOutByPackage {
+		return fmt.Errorf("invalid fan_out by: %s (must be '%s')", c.By, FanOutByPackage)
+	}
+
+	switch c.Discovery {
+	case "", DiscoveryGo:
+	case DiscoveryCommand:
+		if strings.TrimSpace(c.Command) == "" {
+			return fmt.Errorf("fan_out discovery 'command' requires a command")
+		}
+	default:
+		return fmt.Errorf("invalid fan_out discovery: %s (must be '%s' or '%s')", c.Discovery, DiscoveryGo, DiscoveryCommand)
+	}
+
+	if _, err := NewPatternMatcher(c.Include, c.Exclude); err != nil {
+		return fmt.Errorf("invalid fan_out pattern: %w", err)
+	}
+	if c.MaxPackages < 0 {
+		return fmt.Errorf("fan_out max_packages cannot be negative")
+	}
+
+	switch c.PR {
+	case "", FanOutPRSingle:
+	case FanOutPRStacked:
+		if !gitConfig.AutoCommit || gitConfig.Branch == "" {
+			return fmt.Errorf("fan_out pr 'stacked' requires auto_commit and a branch to name the stacked branches after")
+		}
+	default:
+		return fmt.Errorf("invalid fan_out pr: %s (must be '%s' or '%s')", c.PR, FanOutPRSingle, FanOutPRStacked)
+	}
+	return nil
+}
+
+// AgentFactory builds a fresh agent for one package's sub-execution, so no
+// conversation state carries over between packages.
+type AgentFactory func(config *Config) (agent.Agent, error)
+
+// PackageResult is the outcome of one package's sub-execution
+type PackageResult struct {
+	Package Package           `json:"package"`
+	Status  string            `json:"status"`
+	Error   string            `json:"error,omitempty"`
+	Branch  string            `json:"branch,omitempty"`
+	Commit  string            `json:"commit,omitempty"`
+	PRURL   string            `json:"pr_url,omitempty"`
+	Stashed bool              `json:"stashed,omitempty"` // Uncommitted changes were moved to the git stash
+	Summary *ExecutionSummary `json:"summary,omitempty"`
+}
+
+// FanOutSummary aggregates the sub-executions of a fan-out run
+type FanOutSummary struct {
+	Task      string           `json:"task"`
+	Status    string           `json:"status"`
+	Error     string           `json:"error,omitempty"`
+	StartTime time.Time        `json:"start_time"`
+	EndTime   time.Time        `json:"end_time"`
+	Duration  time.Duration    `json:"duration"`
+	PRMode    string           `json:"pr_mode,omitempty"`
+	Branch    string           `json:"branch,omitempty"`
+	PRURL     string           `json:"pr_url,omitempty"`
+	Packages  []PackageResult  `json:"packages"`
+	Metrics   ExecutionMetrics `json:"metrics"`
+}
+
+// count returns how many packages finished with status
+func (s *FanOutSummary) count(status string) int {
+	n := 0
+	for _, result := range s.Packages {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// FanOutExecutor runs one logical task as a sequence of per-package
+// sub-executions on the same working tree, each with constraints and gates
+// scoped to its package, and aggregates them into one report.
+type FanOutExecutor struct {
+	config     *Config
+	newAgent   AgentFactory
+	gitManager *GitManager
+	logger     *Logger
+
+	summary      *FanOutSummary
+	sourceBranch string // The branch we started from before creating a new one
+}
+
+// NewFanOutExecutor creates a fan-out executor that builds an agent per
+// package with newAgent
+func NewFanOutExecutor(config *Config, newAgent AgentFactory) (*FanOutExecutor, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if !config.FanOut.Enabled() {
+		return nil, fmt.Errorf("fan_out is not configured")
+	}
+
+	gitManager := NewGitManager(config.WorkspaceDir, config.Git, config.ConfigFilePath)
+	gitManager.ExcludeFromCommits(generatedPaths(config)...)
+
+	prMode := ""
+	if config.Git.AutoCommit {
+		prMode = FanOutPRSingle
+		if config.FanOut.stacked() {
+			prMode = FanOutPRStacked
+		}
+	}
+
+	return &FanOutExecutor{
+		config:     config,
+		newAgent:   newAgent,
+		gitManager: gitManager,
+		logger:     NewLoggerWithFormat(parseLogLevel(config.Logging.Verbosity), LogFormat(config.Logging.Format)),
+		summary: &FanOutSummary{
+			Task:   config.Task,
+			Status: "running",
+			PRMode: prMode,
+		},
+	}, nil
+}
+
+// generatedPaths returns the workspace paths Forge writes during a run,
+// which must stay out of package commits and stashes
+func generatedPaths(config *Config) []string {
+	cancelFile := config.Cancel.File
+	if cancelFile == "" {
+		cancelFile = defaultCancelFile
+	}
+	paths := []string{config.Artifacts.OutputDir, coding.BackupDir, coding.TrashDir, projectmemory.FileName, cancelFile}
+	if config.Knowledge.Enabled {
+		knowledgeDir := config.Knowledge.Dir
+		if knowledgeDir == "" {
+			knowledgeDir = defaultKnowledgeDir
+		}
+		paths = append(paths, knowledgeDir)
+	}
+	return paths
+}
+
+// Run discovers the packages and runs the task in each, in dependency order
+func (f *FanOutExecutor) Run(ctx context.Context) error {
+	f.summary.StartTime = time.Now()
+	f.logger.Infof("▶ Starting fan-out execution: %s", f.config.Task)
+
+	packages, err := DiscoverPackages(ctx, f.config.WorkspaceDir, f.config.FanOut, f.config.Workspace.Paths)
+	if err != nil {
+		return f.fail(err)
+	}
+	f.logger.Infof("→ Discovered %d package(s)", len(packages))
+
+	if f.config.Git.AutoCommit {
+		if err := f.prepareWorkspace(ctx); err != nil {
+			return f.fail(err)
+		}
+	}
+
+	// Stacked PRs target the previous package's branch, starting from the base
+	base := f.config.Git.PRBase
+	if base == "" {
+		base = f.sourceBranch
+	}
+
+	stopped := false
+	for i, pkg := range packages {
+		if stopped || ctx.Err() != nil {
+			f.summary.Packages = append(f.summary.Packages, PackageResult{Package: pkg, Status: statusSkipped})
+			continue
+		}
+
+		f.logger.Infof("▶ Package %d/%d: %s", i+1, len(packages), pkg.Name)
+		result, stashErr := f.runPackage(ctx, pkg, packages, base)
+		if result.Commit != "" && result.Branch != "" {
+			base = result.Branch
+		}
+		f.summary.Packages = append(f.summary.Packages, result)
+
+		if stashErr != nil {
+			// The next package's commit would include this package's changes
+			f.logger.Errorf("✗ Skipping the remaining packages: %v", stashErr)
+			stopped = true
+		} else if result.Status == statusCanceled {
+			f.logger.Warningf("! Skipping the remaining packages after a cancel request")
+			stopped = true
+		} else if failedStatus(result.Status) && f.config.FanOut.StopOnFailure {
+			f.logger.Warningf("! Skipping the remaining packages after a failure")
+			stopped = true
+		}
+	}
+
+	return f.finalize(ctx)
+}
+
+// prepareWorkspace requires a clean tree, so each package's commit holds only
+// its own changes, and switches to the shared branch in single-PR mode
+func (f *FanOutExecutor) prepareWorkspace(ctx context.Context) error {
+	dirty, err := f.gitManager.HasUncommittedChanges(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("fan-out with auto_commit requires a clean workspace; commit or stash your changes first")
+	}
+
+	currentBranch, err := f.gitManager.GetCurrentBranch(ctx)
+	if err != nil {
+		return err
+	}
+	f.logger.Infof("± Current branch: %s", currentBranch)
+
+	branch := f.config.Git.Branch
+	if branch == "" || branch == currentBranch {
+		return nil
+	}
+	f.sourceBranch = currentBranch
+	if f.config.FanOut.stacked() {
+		// Each package creates its own branch from here
+		return nil
+	}
+
+	f.logger.Infof("± Creating and checking out branch: %s", branch)
+	if err := f.gitManager.CreateBranch(ctx, branch); err != nil {
+		return err
+	}
+	f.summary.Branch = branch
+	return nil
+}
+
+// runPackage runs the task for one package and records what it left behind.
+// Changes that were not committed are stashed so the next package starts
+// from a clean tree; it returns an error when they could not be.
+func (f *FanOutExecutor) runPackage(ctx context.Context, pkg Package, packages []Package, base string) (PackageResult, error) {
+	result := PackageResult{Package: pkg}
+	config := packageConfig(f.config, pkg, packages)
+	if f.config.FanOut.stacked() {
+		config.Git.Branch = f.config.Git.Branch + "-" + pkg.slug()
+		config.Git.PRBase = base
+		if f.config.Git.PRTitle != "" {
+			config.Git.PRTitle = fmt.Sprintf("%s (%s)", f.config.Git.PRTitle, pkg.Name)
+		}
+	}
+
+	headBefore, _ := f.gitManager.HeadCommit(ctx)
+
+	if err := f.runSubExecution(ctx, config, &result); err != nil {
+		result.Status = statusFailed
+		result.Error = err.Error()
+	}
+
+	if f.config.Git.AutoCommit {
+		if head, err := f.gitManager.HeadCommit(ctx); err == nil && head != headBefore {
+			result.Commit = head
+			result.Branch = config.Git.Branch
+			if result.Branch == "" {
+				result.Branch = f.summary.Branch
+			}
+		}
+
+		stashed, err := f.gitManager.Stash(ctx, fmt.Sprintf("forge fan-out: %s (%s)", pkg.Name, result.Status))
+		if err != nil {
+			return result, err
+		}
+		if stashed {
+			result.Stashed = true
+			f.logger.Warningf("! Stashed uncommitted changes from %s", pkg.Name)
+		}
+	}
+
+	return result, nil
+}
+
+// runSubExecution runs config through a fresh agent and executor, recording
+// the execution summary in result
+func (f *FanOutExecutor) runSubExecution(ctx context.Context, config *Config, result *PackageResult) error {
+	summary, err := runSubExecution(ctx, f.newAgent, config, generatedPaths(f.config))
+	if summary != nil {
+		result.Summary = summary
+		result.Status = summary.Status
+		result.Error = summary.Error
+		result.PRURL = summary.PRURL
+	}
+	return err
+}
+
+// runSubExecution runs config through a fresh agent from newAgent and a new
+// executor that keeps excludePaths out of its commits. It returns the
+// execution summary once the executor ran, and an error when the execution
+// could not be set up or ended with an error it did not record as a failure.
+func runSubExecution(ctx context.Context, newAgent AgentFactory, config *Config, excludePaths []string) (*ExecutionSummary, error) {
+	ag, err := newAgent(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent: %w", err)
+	}
+
+	executor, err := NewExecutor(ag, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create executor: %w", err)
+	}
+	executor.gitManager.ExcludeFromCommits(excludePaths...)
+
+	runErr := executor.Run(ctx)
+	if runErr != nil && !failedStatus(executor.summary.Status) {
+		return executor.summary, runErr
+	}
+	return executor.summary, nil
+}
+
+// packageConfig derives the configuration for one package's sub-execution:
+// the task and command gates have {package} and {dir} expanded, file
+// patterns are limited to the package's directory, and artifacts, pull
+// requests and notifications are left to the fan-out executor.
+func packageConfig(parent *Config, pkg Package, packages []Package) *Config {
+	config := *parent
+	config.FanOut = FanOutConfig{}
+	config.Task = expandPackage(parent.Task, pkg) + packageScopeNote(pkg)
+
+	// Discovery kept only packages inside the workspace paths, and the package
+	// directory is narrower, so gates run from the root as the parent's would
+	config.Workspace = WorkspaceConfig{}
+
+	// Nested packages have their own sub-executions
+	config.Constraints.AllowedPatterns = nil
+	if pkg.Dir != "." {
+		config.Constraints.AllowedPatterns = []string{pkg.Dir + "/**"}
+	}
+	config.Constraints.DeniedPatterns = append([]string(nil), parent.Constraints.DeniedPatterns...)
+	for _, dir := range nestedPackageDirs(pkg, packages) {
+		config.Constraints.DeniedPatterns = append(config.Constraints.DeniedPatterns, dir+"/**")
+	}
+
+	config.QualityGates = make([]QualityGateConfig, len(parent.QualityGates))
+	for i, gate := range parent.QualityGates {
+		gate.Command = expandPackage(gate.Command, pkg)
+		config.QualityGates[i] = gate
+	}
+
+	config.Artifacts.Enabled = false
+	config.Notifications = nil
+
+	if !parent.FanOut.stacked() {
+		// Packages share one branch, pushed and opened as a PR at the end
+		config.Git.Branch = ""
+		config.Git.CreatePR = false
+		config.Git.AutoPush = false
+	}
+	config.Git.CommitMessage = packageCommitMessage(parent, pkg)
+
+	// The change is already split by package
+	config.Git.StackMaxLines = 0
+
+	return &config
+}
+
+// expandPackage replaces the {package} and {dir} placeholders in s
+func expandPackage(s string, pkg Package) string {
+	return strings.NewReplacer("{package}", pkg.Name, "{dir}", pkg.Dir).Replace(s)
+}
+
+// packageScopeNote tells the agent which package this sub-execution covers
+func packageScopeNote(pkg Package) string {
+	return fmt.Sprintf("\n\nScope: this run covers only the package %s in %s. Other packages are handled in separate runs, so only change files in this package.", pkg.Name, pkg.Dir)
+}
+
+// packageCommitMessage names the package on the first line of the
+// configured commit message
+func packageCommitMessage(config *Config, pkg Package) string {
+	message := config.Git.CommitMessage
+	if message == "" {
+		message = "chore: " + firstLine(config.Task)
+	}
+	subject, rest, _ := strings.Cut(message, "\n")
+	message = fmt.Sprintf("%s (%s)", subject, pkg.Name)
+	if rest != "" {
+		message += "\n" + rest
+	}
+	return message
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+// finalize sets the overall status, publishes the shared branch in single-PR
+// mode and writes the fan-out report
+func (f *FanOutExecutor) finalize(ctx context.Context) error {
+	f.summary.EndTime = time.Now()
+	f.summary.Duration = f.summary.EndTime.Sub(f.summary.StartTime)
+
+	committed := 0
+	for _, result := range f.summary.Packages {
+		if result.Commit != "" {
+			committed++
+		}
+		if result.Summary != nil {
+			f.summary.Metrics.add(result.Summary.Metrics)
+		}
+	}
+
+	succeeded := f.summary.count(statusSuccess)
+	switch {
+	case succeeded == len(f.summary.Packages):
+		f.summary.Status = statusSuccess
+	case succeeded == 0 && f.summary.count(statusPartialSuccess) == 0:
+		f.summary.Status = statusFailed
+		f.summary.Error = "no package completed successfully"
+	default:
+		f.summary.Status = statusPartialSuccess
+		f.summary.Error = fmt.Sprintf("%d of %d packages completed successfully", succeeded, len(f.summary.Packages))
+	}
+
+	if f.config.Git.AutoCommit && !f.config.FanOut.stacked() && committed > 0 {
+		if err := f.publishBranch(ctx); err != nil {
+			f.logger.Errorf("✗ %v", err)
+			f.summary.Status = statusFailed
+			f.summary.Error = err.Error()
+		}
+	}
+
+	if f.config.Artifacts.Enabled {
+		if err := f.writeArtifacts(); err != nil {
+			f.logger.Warningf("! Failed to write artifacts: %v", err)
+		} else {
+			f.logger.Successf("Artifacts written to %s", f.config.Artifacts.OutputDir)
+		}
+		uploadArtifacts(f.config, f.summary.StartTime, f.logger)
+	}
+	sendNotifications(f.config, f.outcome(), f.logger)
+
+	f.logger.Infof("■ Fan-out completed: %s (%d/%d packages succeeded, duration: %s)", f.summary.Status, succeeded, len(f.summary.Packages), f.summary.Duration)
+
+	if f.summary.Status == statusFailed {
+		return fmt.Errorf("fan-out failed: %s", f.summary.Error)
+	}
+	return nil
+}
+
+// publishBranch opens one pull request for the shared branch, or pushes it
+func (f *FanOutExecutor) publishBranch(ctx context.Context) error {
+	if !f.config.Git.CreatePR {
+		if f.config.Git.AutoPush {
+			if err := f.gitManager.Push(ctx); err != nil {
+				return err
+			}
+			f.logger.Successf("↑ Pushed to remote")
+		}
+		return nil
+	}
+
+	head, err := f.gitManager.GetCurrentBranch(ctx)
+	if err != nil {
+		return err
+	}
+	base := f.config.Git.PRBase
+	if base == "" {
+		base = f.sourceBranch
+	}
+	if base == "" {
+		base = defaultBaseBranch
+	}
+
+	title := f.config.Git.PRTitle
+	if title == "" {
+		title = "chore: " + firstLine(f.config.Task)
+	}
+	body := f.config.Git.PRBody
+	if body == "" {
+		body = f.markdownReport()
+	}
+
+	f.logger.Infof("↑ Pushing to origin/%s...", head)
+	prURL, err := f.gitManager.CreatePullRequest(ctx, git.PullRequest{
+		Title: title,
+		Body:  body,
+		Base:  base,
+		Head:  head,
+		Draft: f.config.Git.PRDraft,
+	})
+	if err != nil {
+		if f.config.Git.RequirePR {
+			return fmt.Errorf("failed to create pull request: %w", err)
+		}
+		f.logger.Warningf("! Failed to create PR: %v", err)
+		if f.config.Git.AutoPush {
+			if pushErr := f.gitManager.Push(ctx); pushErr != nil {
+				return fmt.Errorf("failed to push after PR creation failure: %w", pushErr)
+			}
+			f.logger.Successf("↑ Pushed to remote")
+		}
+		return nil
+	}
+
+	f.logger.Successf("⇄ Created pull request: %s", prURL)
+	f.summary.PRURL = prURL
+	return nil
+}
+
+// writeArtifacts writes fanout.json and fanout.md, and each package's own
+// artifacts under packages/<package>
+func (f *FanOutExecutor) writeArtifacts() error {
+	outputDir := f.config.ArtifactDir()
+	if err := os.MkdirAll(outputDir, 0750); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if f.config.Artifacts.JSON {
+		data, err := json.MarshalIndent(f.summary, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal fan-out summary: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, "fanout.json"), data, 0600); err != nil {
+			return fmt.Errorf("failed to write fan-out JSON: %w", err)
+		}
+	}
+
+	if f.config.Artifacts.Markdown {
+		if err := os.WriteFile(filepath.Join(outputDir, "fanout.md"), []byte(f.markdownReport()), 0600); err != nil {
+			return fmt.Errorf("failed to write fan-out markdown: %w", err)
+		}
+	}
+
+	for _, result := range f.summary.Packages {
+		if result.Summary == nil {
+			continue
+		}
+		writer := NewArtifactWriter(filepath.Join(outputDir, "packages", result.Package.slug()), f.config.Artifacts)
+		if err := writer.WriteAll(result.Summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownReport renders the fan-out summary, also used as the PR body
+func (f *FanOutExecutor) markdownReport() string {
+	s := f.summary
+	var md strings.Builder
+
+	md.WriteString("# Forge Fan-Out Summary\n\n")
+	fmt.Fprintf(&md, "**Task:** %s\n\n", s.Task)
+	fmt.Fprintf(&md, "**Status:** %s\n\n", s.Status)
+	fmt.Fprintf(&md, "**Packages:** %d succeeded, %d partial, %d failed, %d skipped\n\n",
+		s.count(statusSuccess), s.count(statusPartialSuccess), s.count(statusFailed)+s.count(statusStalled)+s.count(statusCanceled), s.count(statusSkipped))
+	if !s.EndTime.IsZero() {
+		fmt.Fprintf(&md, "**Duration:** %s\n\n", s.Duration)
+	}
+
+	md.WriteString("## Packages\n\n")
+	md.WriteString("| Package | Status | Files | Details |\n")
+	md.WriteString("|---------|--------|-------|---------|\n")
+	for _, result := range s.Packages {
+		icon := statusIconFail
+		if result.Status == statusSuccess {
+			icon = statusIconPass
+		}
+		files := 0
+		if result.Summary != nil {
+			files = result.Summary.Metrics.FilesModified
+		}
+
+		var details []string
+		if result.PRURL != "" {
+			details = append(details, result.PRURL)
+		} else if result.Commit != "" {
+			details = append(details, "commit "+shortHash(result.Commit))
+		}
+		if result.Stashed {
+			details = append(details, "changes stashed")
+		}
+		if result.Error != "" {
+			details = append(details, strings.ReplaceAll(firstLine(result.Error), "|", "\\|"))
+		}
+		fmt.Fprintf(&md, "| `%s` | %s %s | %d | %s |\n", result.Package.Name, icon, result.Status, files, strings.Join(details, "; "))
+	}
+	md.WriteString("\n")
+
+	md.WriteString("## Metrics\n\n")
+	fmt.Fprintf(&md, "- **Files Modified:** %d\n", s.Metrics.FilesModified)
+	fmt.Fprintf(&md, "- **Total Lines Added:** %d\n", s.Metrics.TotalLinesAdded)
+	fmt.Fprintf(&md, "- **Total Lines Removed:** %d\n", s.Metrics.TotalLinesRemoved)
+	fmt.Fprintf(&md, "- **Tokens Used:** %d\n", s.Metrics.TokensUsed)
+	if s.Metrics.CostUSD > 0 {
+		fmt.Fprintf(&md, "- **Estimated Cost:** $%.2f\n", s.Metrics.CostUSD)
+	}
+
+	return md.String()
+}
+
+// shortHash abbreviates a commit hash
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// fail marks the fan-out as failed before any package ran
+func (f *FanOutExecutor) fail(err error) error {
+	f.summary.Status = statusFailed
+	f.summary.Error = err.Error()
+	f.summary.EndTime = time.Now()
+	f.summary.Duration = f.summary.EndTime.Sub(f.summary.StartTime)
+
+	if f.config.Artifacts.Enabled {
+		if artifactErr := f.writeArtifacts(); artifactErr != nil {
+			f.logger.Warningf("! Failed to write failure artifacts: %v", artifactErr)
+		}
+		uploadArtifacts(f.config, f.summary.StartTime, f.logger)
+	}
+	sendNotifications(f.config, f.outcome(), f.logger)
+	return err
+}
+
+// outcome describes the fan-out for notifications, with a line per package
+func (f *FanOutExecutor) outcome() runOutcome {
+	outcome := runOutcome{
+		RunID:        f.config.RunID,
+		Task:         f.summary.Task,
+		Status:       f.summary.Status,
+		Error:        f.summary.Error,
+		Branch:       f.summary.Branch,
+		PRURL:        f.summary.PRURL,
+		Duration:     f.summary.Duration.Round(time.Second).String(),
+		FilesChanged: f.summary.Metrics.FilesModified,
+		LinesAdded:   f.summary.Metrics.TotalLinesAdded,
+		LinesRemoved: f.summary.Metrics.TotalLinesRemoved,
+		TokensUsed:   f.summary.Metrics.TokensUsed,
+		CostUSD:      f.summary.Metrics.CostUSD,
+	}
+	for _, result := range f.summary.Packages {
+		outcome.Tasks = append(outcome.Tasks, taskOutcome{Name: result.Package.Name, Status: result.Status, PRURL: result.PRURL})
+	}
+	return outcome
+}
@@ -0,0 +1,272 @@
+package headless
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// scriptedAgent runs work on the first input, then ends its turn and stops
+type scriptedAgent struct {
+	channels *types.AgentChannels
+	work     func() error
+}
+
+func newScriptedAgent(work func() error) *scriptedAgent {
+	return &scriptedAgent{channels: types.NewAgentChannels(10), work: work}
+}
+
+func (a *scriptedAgent) Start(ctx context.Context) error {
+	go func() {
+		defer a.channels.Close()
+		select {
+		case <-a.channels.Input:
+			if err := a.work(); err != nil {
+				a.channels.Event <- types.NewErrorEvent(err)
+			}
+			a.channels.Event <- types.NewTurnEndEvent()
+		case <-ctx.Done():
+		}
+	}()
+	return nil
+}
+
+func (a *scriptedAgent) Shutdown(ctx context.Context) error      { return nil }
+func (a *scriptedAgent) GetChannels() *types.AgentChannels       { return a.channels }
+func (a *scriptedAgent) GetTool(name string) any                 { return nil }
+func (a *scriptedAgent) GetTools() []any                         { return nil }
+func (a *scriptedAgent) GetContextInfo() *agent.ContextInfo      { return nil }
+func (a *scriptedAgent) GetMessages() []*types.Message           { return nil }
+func (a *scriptedAgent) GetSystemPrompt() string                 { return "" }
+func (a *scriptedAgent) SetProvider(provider llm.Provider) error { return nil }
+
+// gitOutput runs git in dir and returns its trimmed output
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// fanOutTestConfig returns a config that fans out over the packages a and b
+func fanOutTestConfig(workspaceDir string) *Config {
+	config := DefaultConfig()
+	config.Task = "Add a change file to {dir}"
+	config.WorkspaceDir = workspaceDir
+	config.Logging.Verbosity = "quiet"
+	config.Git.AutoCommit = true
+	config.Git.Branch = "forge/fanout"
+	config.Git.CommitMessage = "chore: add change files"
+	config.FanOut = FanOutConfig{
+		By:        FanOutByPackage,
+		Discovery: DiscoveryCommand,
+		Command:   "printf 'a\\nb\\n'",
+	}
+	return config
+}
+
+// packageDirAgents returns a factory whose agents write change.txt into
+// their package directory, plus the ok marker for the packages in ok
+func packageDirAgents(t *testing.T, workspaceDir string, ok ...string) AgentFactory {
+	return func(config *Config) (agent.Agent, error) {
+		dir := strings.TrimSuffix(config.Constraints.AllowedPatterns[0], "/**")
+		return newScriptedAgent(func() error {
+			files := []string{"change.txt"}
+			for _, okDir := range ok {
+				if okDir == dir {
+					files = append(files, "ok")
+				}
+			}
+			for _, name := range files {
+				if err := os.WriteFile(filepath.Join(workspaceDir, dir, name), []byte(config.Task), 0o644); err != nil {
+					t.Error(err)
+				}
+			}
+			return nil
+		}), nil
+	}
+}
+
+func setupFanOutRepo(t *testing.T) string {
+	t.Helper()
+	dir := setupGitRepo(t)
+	for _, pkg := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(dir, pkg), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, pkg, "keep"), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitOutput(t, dir, "add", ".")
+	gitOutput(t, dir, "commit", "-m", "Add packages")
+	gitOutput(t, dir, "branch", "-M", "main")
+	return dir
+}
+
+func TestFanOutExecutor_SingleBranch(t *testing.T) {
+	dir := setupFanOutRepo(t)
+	config := fanOutTestConfig(dir)
+	config.QualityGates = []QualityGateConfig{{Name: "marker", Command: "test -f {dir}/ok", Required: true}}
+	config.QualityGateMaxRetries = 1
+
+	executor, err := NewFanOutExecutor(config, packageDirAgents(t, dir, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.Run(context.Background()); err != nil {
+		t.Fatalf("expected partial success without an error, got %v", err)
+	}
+
+	summary := executor.summary
+	if summary.Status != statusPartialSuccess || summary.Branch != "forge/fanout" {
+		t.Errorf("unexpected summary status %q on branch %q", summary.Status, summary.Branch)
+	}
+	a, b := summary.Packages[0], summary.Packages[1]
+	if a.Status != statusSuccess || a.Commit == "" || a.Stashed {
+		t.Errorf("expected package a to be committed, got %+v", a)
+	}
+	if b.Status != statusFailed || b.Commit != "" || !b.Stashed {
+		t.Errorf("expected package b to fail with its changes stashed, got %+v", b)
+	}
+
+	if branch := gitOutput(t, dir, "branch", "--show-current"); branch != "forge/fanout" {
+		t.Errorf("expected to be on forge/fanout, got %s", branch)
+	}
+	if subject := gitOutput(t, dir, "log", "-1", "--format=%s"); subject != "chore: add change files (a)" {
+		t.Errorf("unexpected commit subject %q", subject)
+	}
+	if files := gitOutput(t, dir, "show", "--name-only", "--format=", "HEAD"); files != "a/change.txt\na/ok" {
+		t.Errorf("expected only package a's files in its commit, got %q", files)
+	}
+	if stash := gitOutput(t, dir, "stash", "list"); !strings.Contains(stash, "forge fan-out: b (failed)") {
+		t.Errorf("expected b's changes on the stash, got %q", stash)
+	}
+
+	for _, name := range []string{"fanout.json", "fanout.md", "packages/a/execution.json"} {
+		if _, err := os.Stat(filepath.Join(dir, config.Artifacts.OutputDir, name)); err != nil {
+			t.Errorf("expected artifact %s: %v", name, err)
+		}
+	}
+}
+
+func TestFanOutExecutor_StackedBranches(t *testing.T) {
+	dir := setupFanOutRepo(t)
+	config := fanOutTestConfig(dir)
+	config.FanOut.PR = FanOutPRStacked
+
+	executor, err := NewFanOutExecutor(config, packageDirAgents(t, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if executor.summary.Status != statusSuccess {
+		t.Fatalf("expected success, got %q: %s", executor.summary.Status, executor.summary.Error)
+	}
+	a, b := executor.summary.Packages[0], executor.summary.Packages[1]
+	if a.Branch != "forge/fanout-a" || b.Branch != "forge/fanout-b" {
+		t.Fatalf("unexpected branches %q and %q", a.Branch, b.Branch)
+	}
+	if parent := gitOutput(t, dir, "rev-parse", "forge/fanout-b~1"); parent != a.Commit {
+		t.Errorf("expected b's branch to be stacked on a's commit %s, got %s", a.Commit, parent)
+	}
+}
+
+func TestFanOutExecutor_RequiresCleanWorkspace(t *testing.T) {
+	dir := setupFanOutRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "a", "dirty"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	executor, err := NewFanOutExecutor(fanOutTestConfig(dir), packageDirAgents(t, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "clean workspace") {
+		t.Errorf("expected a clean workspace error, got %v", err)
+	}
+}
+
+func TestPackageConfig(t *testing.T) {
+	parent := DefaultConfig()
+	parent.Task = "Add context to the handlers in {package}"
+	parent.Constraints.AllowedPatterns = []string{"**/*.go"}
+	parent.Constraints.DeniedPatterns = []string{"**/*_gen.go"}
+	parent.QualityGates = []QualityGateConfig{{Name: "test", Command: "go test {package}"}}
+	parent.Git.CreatePR = true
+	parent.Git.Branch = "forge/context"
+	parent.FanOut = FanOutConfig{By: FanOutByPackage}
+	parent.Workspace = WorkspaceConfig{Paths: []string{"api"}}
+
+	pkg := Package{Name: "example.com/m/api", Dir: "api"}
+	packages := []Package{pkg, {Name: "example.com/m/api/v2", Dir: "api/v2"}, {Name: "example.com/m/store", Dir: "store"}}
+	config := packageConfig(parent, pkg, packages)
+
+	if !strings.HasPrefix(config.Task, "Add context to the handlers in example.com/m/api\n\nScope:") {
+		t.Errorf("unexpected task %q", config.Task)
+	}
+	if got := config.Constraints.AllowedPatterns; len(got) != 1 || got[0] != "api/**" {
+		t.Errorf("expected the package directory as the only allowed pattern, got %v", got)
+	}
+	if got := config.Constraints.DeniedPatterns; len(got) != 2 || got[1] != "api/v2/**" {
+		t.Errorf("expected nested packages to be denied, got %v", got)
+	}
+	if config.QualityGates[0].Command != "go test example.com/m/api" || parent.QualityGates[0].Command != "go test {package}" {
+		t.Errorf("expected only the package's gate to be expanded, got %q", config.QualityGates[0].Command)
+	}
+	if config.Git.CreatePR || config.Git.Branch != "" || config.Artifacts.Enabled || config.FanOut.Enabled() {
+		t.Error("expected branches, PRs, artifacts and fan-out to be left to the fan-out executor")
+	}
+	if config.Workspace.Scoped() {
+		t.Error("expected the package directory to replace the workspace paths")
+	}
+	if config.Git.CommitMessage != "chore: automated changes via Forge (example.com/m/api)" {
+		t.Errorf("unexpected commit message %q", config.Git.CommitMessage)
+	}
+}
+
+func TestFanOutConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		fanOut FanOutConfig
+		git    GitConfig
+		want   string
+	}{
+		{"disabled", FanOutConfig{}, GitConfig{}, ""},
+		{"unknown unit", FanOutConfig{By: "module"}, GitConfig{}, "invalid fan_out by"},
+		{"command without command", FanOutConfig{By: FanOutByPackage, Discovery: DiscoveryCommand}, GitConfig{}, "requires a command"},
+		{"unknown discovery", FanOutConfig{By: FanOutByPackage, Discovery: "bazel"}, GitConfig{}, "invalid fan_out discovery"},
+		{"bad pattern", FanOutConfig{By: FanOutByPackage, Include: []string{"[a"}}, GitConfig{}, "invalid fan_out pattern"},
+		{"stacked without branch", FanOutConfig{By: FanOutByPackage, PR: FanOutPRStacked}, GitConfig{AutoCommit: true}, "requires auto_commit and a branch"},
+		{"stacked", FanOutConfig{By: FanOutByPackage, PR: FanOutPRStacked}, GitConfig{AutoCommit: true, Branch: "forge/x"}, ""},
+		{"unknown pr mode", FanOutConfig{By: FanOutByPackage, PR: "many"}, GitConfig{}, "invalid fan_out pr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fanOut.validate(tt.git)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -1,18 +1,27 @@
 package headless
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/entrhq/forge/pkg/agent/git"
 )
 
 // GitManager handles git operations for headless mode
 type GitManager struct {
 	workspaceDir   string
 	config         GitConfig
-	configFilePath string // Path to config file to exclude from commits
+	configFilePath string   // Path to config file to exclude from commits
+	excludePaths   []string // Workspace-relative paths kept out of commits and stashes
 }
 
 // NewGitManager creates a new git manager
@@ -54,10 +63,9 @@ func (g *GitManager) GetCurrentBranch(ctx context.Context) (string, error) {
 // If the branch already exists, it just switches to it
 func (g *GitManager) CreateBranch(ctx context.Context, branchName string) error {
 	// Check if branch exists first
-	_, err := g.execGit(ctx, "show-ref", "--verify", "--quiet", fmt.Sprintf("refs/heads/%s", branchName))
-	if err == nil {
+	if g.branchExists(ctx, branchName) {
 		// Branch exists, just checkout
-		_, err = g.execGit(ctx, "checkout", branchName)
+		_, err := g.execGit(ctx, "checkout", branchName)
 		if err != nil {
 			return fmt.Errorf("failed to checkout existing branch '%s': %w", branchName, err)
 		}
@@ -65,7 +73,7 @@ func (g *GitManager) CreateBranch(ctx context.Context, branchName string) error
 	}
 
 	// Branch doesn't exist, create it
-	_, err = g.execGit(ctx, "checkout", "-b", branchName)
+	_, err := g.execGit(ctx, "checkout", "-b", branchName)
 	if err != nil {
 		return fmt.Errorf("failed to create branch '%s': %w", branchName, err)
 	}
@@ -73,13 +81,302 @@ func (g *GitManager) CreateBranch(ctx context.Context, branchName string) error
 	return nil
 }
 
+// ExcludeFromCommits keeps workspace-relative paths, such as the artifacts
+// directory, out of commits and stashes
+func (g *GitManager) ExcludeFromCommits(paths ...string) {
+	g.excludePaths = append(g.excludePaths, paths...)
+}
+
+// excludePathspecs returns the pathspecs that leave the config file and
+// excluded paths out of a git command
+func (g *GitManager) excludePathspecs() []string {
+	var specs []string
+	if g.configFilePath != "" && g.inWorkspace(g.configFilePath) {
+		specs = append(specs, fmt.Sprintf(":(exclude)%s", g.configFilePath))
+	}
+	for _, path := range g.excludePaths {
+		// An absolute path elsewhere, such as a worktree run's artifacts
+		// directory, needs no excluding
+		if filepath.IsAbs(path) && !g.inWorkspace(path) {
+			continue
+		}
+		specs = append(specs, fmt.Sprintf(":(exclude)%s", path))
+	}
+	return specs
+}
+
+// inWorkspace reports whether path, relative to the workspace unless
+// absolute, is inside it; git rejects pathspecs outside the repository
+func (g *GitManager) inWorkspace(path string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(g.workspaceDir, path)
+	}
+	workspaceDir, err := filepath.Abs(g.workspaceDir)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(workspaceDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // Commit creates a git commit with the configured author
 func (g *GitManager) Commit(ctx context.Context, message string) error {
-	// Stage all changes, excluding the config file
-	if g.configFilePath != "" {
+	committed, err := g.commitAll(ctx, "-m", message)
+	if err != nil || !committed {
+		return err
+	}
+
+	// Auto-push if configured
+	if g.config.AutoPush {
+		if err := g.Push(ctx); err != nil {
+			return fmt.Errorf("failed to auto-push: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Checkpoint commits all changes, excluding the config file and excluded
+// paths, without pushing. It reports whether there was anything to commit.
+func (g *GitManager) Checkpoint(ctx context.Context, message string) (bool, error) {
+	return g.commitAll(ctx, "-m", message)
+}
+
+// CommitFixup commits all changes as a fixup! commit for target, to be
+// folded into it by Autosquash. It does not push, and reports whether there
+// was anything to commit.
+func (g *GitManager) CommitFixup(ctx context.Context, target string) (bool, error) {
+	return g.commitAll(ctx, "--fixup="+target)
+}
+
+// Amend folds all changes into the last commit, keeping its message. It does
+// not push, and reports whether there was anything to fold in.
+func (g *GitManager) Amend(ctx context.Context) (bool, error) {
+	return g.commitAll(ctx, "--amend", "--no-edit")
+}
+
+// Autosquash folds the fixup! commits after base into the commits they fix
+// up. Changes outside the commits, such as excluded paths, are stashed for
+// the rebase. A rebase that fails is aborted, leaving the commits as they
+// were.
+func (g *GitManager) Autosquash(ctx context.Context, base string) error {
+	// Accept the rebase todo list and commit messages as generated
+	env := []string{"GIT_SEQUENCE_EDITOR=true", "GIT_EDITOR=true"}
+	if _, err := g.execGitEnv(ctx, env, "rebase", "--interactive", "--autosquash", "--autostash", base); err != nil {
+		_, _ = g.execGit(ctx, "rebase", "--abort")
+		return fmt.Errorf("failed to autosquash commits: %w", err)
+	}
+	return nil
+}
+
+// Squash replaces the commits after base with a single commit of their
+// changes and any uncommitted ones, excluding the config file and excluded
+// paths. Like Commit, it pushes when auto-push is configured.
+func (g *GitManager) Squash(ctx context.Context, base, message string) error {
+	if _, err := g.execGit(ctx, "reset", "--soft", base); err != nil {
+		return fmt.Errorf("failed to squash commits: %w", err)
+	}
+	return g.Commit(ctx, message)
+}
+
+// Uncommit moves the current branch back to base, leaving the changes of the
+// commits after it uncommitted in the working tree
+func (g *GitManager) Uncommit(ctx context.Context, base string) error {
+	if _, err := g.execGit(ctx, "reset", "-q", base); err != nil {
+		return fmt.Errorf("failed to reset to %s: %w", base, err)
+	}
+	return nil
+}
+
+// CommitsSince returns the number of commits after base on the current branch
+func (g *GitManager) CommitsSince(ctx context.Context, base string) (int, error) {
+	output, err := g.execGit(ctx, "rev-list", "--count", base+"..HEAD")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits: %w", err)
+	}
+	return strconv.Atoi(strings.TrimSpace(output))
+}
+
+// CommitPaths commits only the changes to the given workspace-relative paths,
+// leaving the rest of the working tree uncommitted. It does not auto-push.
+func (g *GitManager) CommitPaths(ctx context.Context, message string, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no paths to commit")
+	}
+
+	// Unstage everything, then stage only the requested paths
+	if _, err := g.execGit(ctx, "reset", "-q"); err != nil {
+		return fmt.Errorf("failed to reset staged changes: %w", err)
+	}
+	if _, err := g.execGit(ctx, append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+		return fmt.Errorf("failed to stage changes: %w", err)
+	}
+
+	return g.commitStaged(ctx, "-m", message)
+}
+
+// StagedLineCounts stages all changes, excluding the config file and
+// excluded paths, and returns the number of lines added plus removed in each
+// changed file, keyed by workspace-relative path. Binary files count as zero.
+func (g *GitManager) StagedLineCounts(ctx context.Context) (map[string]int, error) {
+	if err := g.stageAll(ctx); err != nil {
+		return nil, err
+	}
+
+	output, err := g.execGit(ctx, "diff", "--cached", "--numstat", "--no-renames", "--relative", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged changes: %w", err)
+	}
+
+	counts := make(map[string]int)
+	for _, entry := range strings.Split(output, "\x00") {
+		fields := strings.SplitN(strings.TrimSpace(entry), "\t", 3)
+		if len(fields) != 3 || fields[2] == "" {
+			continue
+		}
+		added, _ := strconv.Atoi(fields[0])   // "-" for binary files
+		removed, _ := strconv.Atoi(fields[1]) // "-" for binary files
+		counts[fields[2]] = added + removed
+	}
+
+	return counts, nil
+}
+
+// File change statuses reported by Changes
+const (
+	fileChangeAdded    = "added"
+	fileChangeModified = "modified"
+	fileChangeDeleted  = "deleted"
+)
+
+// FileChange is a file changed in the workspace, with its line counts
+type FileChange struct {
+	Path         string `json:"path"`   // Relative to the repository root
+	Status       string `json:"status"` // added, modified or deleted
+	LinesAdded   int    `json:"lines_added"`
+	LinesRemoved int    `json:"lines_removed"`
+	Binary       bool   `json:"binary,omitempty"`
+}
+
+// Changes returns the unified diff of every uncommitted change in the
+// workspace against HEAD, untracked files included, and the files it
+// touches. The changes are staged into a temporary copy of the index, so the
+// real index is left as it was. The config file, excluded paths and exclude
+// are left out.
+func (g *GitManager) Changes(ctx context.Context, exclude ...string) (string, []FileChange, error) {
+	return g.ChangesSince(ctx, "", exclude...)
+}
+
+// ChangesSince is Changes against the base commit instead of HEAD, so the
+// changes committed after base are included. An empty base is HEAD.
+func (g *GitManager) ChangesSince(ctx context.Context, base string, exclude ...string) (string, []FileChange, error) {
+	indexPath, err := g.execGit(ctx, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to locate the git index: %w", err)
+	}
+	indexPath = strings.TrimSpace(indexPath)
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(g.workspaceDir, indexPath)
+	}
+
+	tempIndex, err := os.CreateTemp("", "forge-index-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer os.Remove(tempIndex.Name())
+	// Starting from a copy keeps git's stat cache, so unchanged files are not
+	// hashed again; an unborn branch has no index yet
+	if index, openErr := os.Open(indexPath); openErr == nil { //nolint:gosec // path comes from git
+		_, err = io.Copy(tempIndex, index)
+		index.Close()
+	}
+	if closeErr := tempIndex.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to copy the git index: %w", err)
+	}
+	if info, statErr := os.Stat(tempIndex.Name()); statErr == nil && info.Size() == 0 {
+		os.Remove(tempIndex.Name()) // git rejects an empty index file but creates a missing one
+	}
+	env := []string{"GIT_INDEX_FILE=" + tempIndex.Name()}
+
+	excludes := g.excludePathspecs()
+	for _, path := range exclude {
+		excludes = append(excludes, fmt.Sprintf(":(exclude)%s", path))
+	}
+	if _, err := g.execGitEnv(ctx, env, append([]string{"add", "-A", "--", "."}, excludes...)...); err != nil {
+		return "", nil, fmt.Errorf("failed to stage changes: %w", err)
+	}
+
+	diff := []string{"diff", "--cached"}
+	if base != "" {
+		diff = append(diff, base)
+	}
+	diff = slices.Clip(diff) // Each command appends its own arguments
+	patch, err := g.execGitEnv(ctx, env, append(diff, "--binary", "--no-color", "--no-ext-diff", "--no-renames", "--", ".")...)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to diff changes: %w", err)
+	}
+	statuses, err := g.execGitEnv(ctx, env, append(diff, "--name-status", "--no-renames", "-z", "--", ".")...)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	numstat, err := g.execGitEnv(ctx, env, append(diff, "--numstat", "--no-renames", "-z", "--", ".")...)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to count changed lines: %w", err)
+	}
+
+	return patch, parseFileChanges(statuses, numstat), nil
+}
+
+// parseFileChanges combines the -z output of git diff --name-status and
+// --numstat into file changes, in git's order
+func parseFileChanges(statuses, numstat string) []FileChange {
+	var files []FileChange
+	index := make(map[string]int)
+	fields := strings.Split(statuses, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		status := fileChangeModified
+		switch fields[i] {
+		case "A":
+			status = fileChangeAdded
+		case "D":
+			status = fileChangeDeleted
+		}
+		index[fields[i+1]] = len(files)
+		files = append(files, FileChange{Path: fields[i+1], Status: status})
+	}
+
+	for _, entry := range strings.Split(numstat, "\x00") {
+		parts := strings.SplitN(entry, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		i, ok := index[parts[2]]
+		if !ok {
+			continue
+		}
+		if parts[0] == "-" && parts[1] == "-" {
+			files[i].Binary = true
+			continue
+		}
+		files[i].LinesAdded, _ = strconv.Atoi(parts[0])
+		files[i].LinesRemoved, _ = strconv.Atoi(parts[1])
+	}
+	return files
+}
+
+// stageAll stages all changes, excluding the config file and excluded paths
+func (g *GitManager) stageAll(ctx context.Context) error {
+	if excludes := g.excludePathspecs(); len(excludes) > 0 {
 		// Try to use pathspec magic to exclude the config file from staging
 		// Note: Don't use -A with pathspecs as it ignores them
-		_, err := g.execGit(ctx, "add", ".", fmt.Sprintf(":(exclude)%s", g.configFilePath))
+		_, err := g.execGit(ctx, append([]string{"add", "."}, excludes...)...)
 		if err != nil {
 			// If pathspec exclusion fails (e.g., config file is outside workspace),
 			// fall back to staging everything
@@ -88,31 +385,46 @@ func (g *GitManager) Commit(ctx context.Context, message string) error {
 				return fmt.Errorf("failed to stage changes: %w", fallbackErr)
 			}
 		}
-	} else {
-		// No config file to exclude, stage everything
-		_, err := g.execGit(ctx, "add", "-A")
-		if err != nil {
-			return fmt.Errorf("failed to stage changes: %w", err)
-		}
+		return nil
+	}
+
+	// No config file to exclude, stage everything
+	if _, err := g.execGit(ctx, "add", "-A"); err != nil {
+		return fmt.Errorf("failed to stage changes: %w", err)
+	}
+	return nil
+}
+
+// commitAll stages all changes, excluding the config file and excluded
+// paths, and commits them with the given git commit arguments. It reports
+// false without committing when nothing is staged.
+func (g *GitManager) commitAll(ctx context.Context, args ...string) (bool, error) {
+	if err := g.stageAll(ctx); err != nil {
+		return false, err
 	}
 
 	// Check if there are any staged changes to commit
 	// This prevents empty commits when the only change was the config file
 	hasChanges, err := g.hasChangesToCommit(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to verify staged changes: %w", err)
+		return false, fmt.Errorf("failed to verify staged changes: %w", err)
 	}
 
 	if !hasChanges {
 		// No changes to commit, skip the commit
-		return nil
+		return false, nil
 	}
 
-	// Create commit with configured author
-	args := []string{
-		"commit",
-		"-m", message,
+	if err := g.commitStaged(ctx, args...); err != nil {
+		return false, err
 	}
+	return true, nil
+}
+
+// commitStaged commits the staged changes with the configured author and the
+// given git commit arguments, such as -m and the message
+func (g *GitManager) commitStaged(ctx context.Context, commitArgs ...string) error {
+	args := append([]string{"commit"}, commitArgs...)
 
 	if g.config.AuthorName != "" && g.config.AuthorEmail != "" {
 		args = append(args,
@@ -120,18 +432,10 @@ func (g *GitManager) Commit(ctx context.Context, message string) error {
 		)
 	}
 
-	_, err = g.execGit(ctx, args...)
-	if err != nil {
+	if _, err := g.execGit(ctx, args...); err != nil {
 		return fmt.Errorf("failed to create commit: %w", err)
 	}
 
-	// Auto-push if configured
-	if g.config.AutoPush {
-		if err := g.Push(ctx); err != nil {
-			return fmt.Errorf("failed to auto-push: %w", err)
-		}
-	}
-
 	return nil
 }
 
@@ -146,6 +450,95 @@ func (g *GitManager) hasChangesToCommit(ctx context.Context) (bool, error) {
 	return strings.TrimSpace(output) != "", nil
 }
 
+// HasUncommittedChanges reports whether the workspace has changes outside
+// the config file and excluded paths
+func (g *GitManager) HasUncommittedChanges(ctx context.Context) (bool, error) {
+	output, err := g.execGit(ctx, append([]string{"status", "--porcelain", "--", "."}, g.excludePathspecs()...)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to check git status: %w", err)
+	}
+
+	return strings.TrimSpace(output) != "", nil
+}
+
+// Stash moves uncommitted changes, including untracked files but not the
+// config file or excluded paths, onto the stash. It reports whether there
+// was anything to stash.
+func (g *GitManager) Stash(ctx context.Context, message string) (bool, error) {
+	hasChanges, err := g.HasUncommittedChanges(ctx)
+	if err != nil || !hasChanges {
+		return false, err
+	}
+
+	args := append([]string{"stash", "push", "--include-untracked", "-m", message, "--", "."}, g.excludePathspecs()...)
+	if _, err := g.execGit(ctx, args...); err != nil {
+		return false, fmt.Errorf("failed to stash changes: %w", err)
+	}
+
+	return true, nil
+}
+
+// IsRepository reports whether the workspace is inside a git work tree
+func (g *GitManager) IsRepository(ctx context.Context) bool {
+	output, err := g.execGit(ctx, "rev-parse", "--is-inside-work-tree")
+	return err == nil && strings.TrimSpace(output) == "true"
+}
+
+// HeadCommit returns the hash of the commit HEAD points to
+func (g *GitManager) HeadCommit(ctx context.Context) (string, error) {
+	output, err := g.execGit(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// RepoPrefix returns the workspace's path relative to the repository root,
+// with a trailing slash, or "" when the workspace is the root
+func (g *GitManager) RepoPrefix(ctx context.Context) (string, error) {
+	output, err := g.execGit(ctx, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve workspace path in repository: %w", err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// AddWorktree checks out a new worktree of the repository at dir. With a
+// branch, the worktree is on that branch, created at commit if it does not
+// exist yet; without one, it is a detached checkout of commit.
+func (g *GitManager) AddWorktree(ctx context.Context, dir, branch, commit string) error {
+	args := []string{"worktree", "add"}
+	switch {
+	case branch == "":
+		args = append(args, "--detach", dir, commit)
+	case g.branchExists(ctx, branch):
+		args = append(args, dir, branch)
+	default:
+		args = append(args, "-b", branch, dir, commit)
+	}
+
+	if _, err := g.execGit(ctx, args...); err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+	return nil
+}
+
+// RemoveWorktree removes the worktree at dir, discarding any changes in it
+func (g *GitManager) RemoveWorktree(ctx context.Context, dir string) error {
+	if _, err := g.execGit(ctx, "worktree", "remove", "--force", dir); err != nil {
+		return fmt.Errorf("failed to remove worktree: %w", err)
+	}
+	return nil
+}
+
+// branchExists reports whether a local branch exists
+func (g *GitManager) branchExists(ctx context.Context, branch string) bool {
+	_, err := g.execGit(ctx, "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	return err == nil
+}
+
 // GetChangedFiles returns a list of files that have been modified or are untracked
 func (g *GitManager) GetChangedFiles(ctx context.Context) ([]string, error) {
 	// Use git status --porcelain to get both modified and untracked files
@@ -190,8 +583,12 @@ func (g *GitManager) Push(ctx context.Context) error {
 		return fmt.Errorf("failed to get current branch: %w", err)
 	}
 
-	// Push to remote
-	_, err = g.execGit(ctx, "push", "origin", branch)
+	return g.PushBranch(ctx, branch)
+}
+
+// PushBranch pushes the given local branch to the remote
+func (g *GitManager) PushBranch(ctx context.Context, branch string) error {
+	_, err := g.execGit(ctx, "push", "origin", branch)
 	if err != nil {
 		return fmt.Errorf("failed to push branch '%s': %w", branch, err)
 	}
@@ -199,21 +596,59 @@ func (g *GitManager) Push(ctx context.Context) error {
 	return nil
 }
 
-// Rollback rolls back all uncommitted changes
-func (g *GitManager) Rollback(ctx context.Context) error {
-	// Reset all changes
-	_, err := g.execGit(ctx, "reset", "--hard", "HEAD")
+// RemoteURL returns the URL of the origin remote
+func (g *GitManager) RemoteURL(ctx context.Context) (string, error) {
+	output, err := g.execGit(ctx, "remote", "get-url", "origin")
 	if err != nil {
-		return fmt.Errorf("failed to rollback changes: %w", err)
+		return "", fmt.Errorf("failed to get origin remote: %w", err)
 	}
+	return strings.TrimSpace(output), nil
+}
 
-	// Clean untracked files
-	_, err = g.execGit(ctx, "clean", "-fd")
+// Host returns the git host that pull requests are opened on, selected by
+// the provider setting and pointed at the origin remote's repository.
+func (g *GitManager) Host(ctx context.Context) (git.GitHost, error) {
+	remoteURL, err := g.RemoteURL(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to clean untracked files: %w", err)
+		return nil, err
 	}
 
-	return nil
+	return git.NewGitHost(git.HostConfig{
+		Provider:  g.config.Provider,
+		RemoteURL: remoteURL,
+		APIURL:    g.config.APIURL,
+		Token:     git.ResolveToken(g.config.Provider, g.config.TokenEnv),
+		WorkDir:   g.workspaceDir,
+	})
+}
+
+// CreatePullRequest pushes the pull request's head branch and opens a pull
+// request for it on the configured git host, returning the pull request's URL.
+func (g *GitManager) CreatePullRequest(ctx context.Context, pr git.PullRequest) (string, error) {
+	host, err := g.Host(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if err := g.PushBranch(ctx, pr.Head); err != nil {
+		return "", fmt.Errorf("failed to push branch: %w", err)
+	}
+
+	return host.CreatePullRequest(ctx, pr)
+}
+
+// Rollback discards uncommitted changes, including untracked files but not
+// the config file or excluded paths. It reports whether there was anything
+// to discard.
+func (g *GitManager) Rollback(ctx context.Context) (bool, error) {
+	stashed, err := g.Stash(ctx, "forge: rollback")
+	if err != nil || !stashed {
+		return false, err
+	}
+	if _, err := g.execGit(ctx, "stash", "drop"); err != nil {
+		return false, fmt.Errorf("failed to rollback changes: %w", err)
+	}
+	return true, nil
 }
 
 // execGit executes a git command and returns its output
@@ -235,6 +670,26 @@ func (g *GitManager) execGit(ctx context.Context, args ...string) (string, error
 	return string(output), nil
 }
 
+// execGitEnv executes a git command with extra environment variables and
+// returns its standard output alone, so warnings cannot corrupt it
+func (g *GitManager) execGitEnv(ctx context.Context, env []string, args ...string) (string, error) {
+	execCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(execCtx, "git", args...)
+	cmd.Dir = g.workspaceDir
+	cmd.Env = append(os.Environ(), env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git command failed: %w\nOutput: %s", err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
 // GenerateCommitMessage generates a commit message based on changes
 func (g *GitManager) GenerateCommitMessage(ctx context.Context, taskDescription string) string {
 	if g.config.CommitMessage != "" {
@@ -296,3 +296,178 @@ func TestGitManager_CommitWithNoChanges(t *testing.T) {
 		t.Errorf("expected 1 commit, got %s", strings.TrimSpace(output))
 	}
 }
+
+func TestGitManager_Changes(t *testing.T) {
+	testDir := setupTestRepo(t)
+	ctx := context.Background()
+	gm := NewGitManager(testDir, GitConfig{}, "")
+
+	if err := os.WriteFile(filepath.Join(testDir, "README.md"), []byte("# Test Repository\n\nUpdated.\n"), 0644); err != nil {
+		t.Fatalf("failed to update README: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(testDir, "new.txt"), []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("failed to write new file: %v", err)
+	}
+	artifactsDir := filepath.Join(testDir, ".forge", "artifacts")
+	if err := os.MkdirAll(artifactsDir, 0755); err != nil {
+		t.Fatalf("failed to create artifacts dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(artifactsDir, "execution.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write artifact: %v", err)
+	}
+
+	patch, files, err := gm.Changes(ctx, ".forge/artifacts")
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+
+	if !strings.Contains(patch, "+Updated.") || !strings.Contains(patch, "+++ b/new.txt") {
+		t.Errorf("expected the patch to hold both changes, got:\n%s", patch)
+	}
+	if strings.Contains(patch, "execution.json") {
+		t.Errorf("expected the artifacts directory to be left out, got:\n%s", patch)
+	}
+
+	want := []FileChange{
+		{Path: "README.md", Status: fileChangeModified, LinesAdded: 2},
+		{Path: "new.txt", Status: fileChangeAdded, LinesAdded: 2},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("expected %d files, got %+v", len(want), files)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("file %d: expected %+v, got %+v", i, want[i], files[i])
+		}
+	}
+
+	// The real index is untouched: nothing was staged
+	staged, err := gm.hasChangesToCommit(ctx)
+	if err != nil {
+		t.Fatalf("failed to check staged changes: %v", err)
+	}
+	if staged {
+		t.Error("expected Changes to leave the index unchanged")
+	}
+
+	// The patch applies to a clean checkout
+	if err := execCommand(testDir, "git", "stash", "--include-untracked"); err != nil {
+		t.Fatalf("failed to stash changes: %v", err)
+	}
+	patchFile := filepath.Join(t.TempDir(), "changes.patch")
+	if err := os.WriteFile(patchFile, []byte(patch), 0644); err != nil {
+		t.Fatalf("failed to write patch: %v", err)
+	}
+	if err := execCommand(testDir, "git", "apply", "--check", patchFile); err != nil {
+		t.Errorf("expected the patch to apply: %v", err)
+	}
+}
+
+func TestGitManager_CheckpointAndAutosquash(t *testing.T) {
+	testDir := setupTestRepo(t)
+	ctx := context.Background()
+	gm := NewGitManager(testDir, GitConfig{}, "")
+	gm.ExcludeFromCommits(".forge/artifacts")
+
+	base, err := gm.HeadCommit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(testDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("a.txt", "attempt 1\n")
+	if committed, err := gm.Checkpoint(ctx, "feat: add a"); err != nil || !committed {
+		t.Fatalf("Checkpoint() = %v, %v", committed, err)
+	}
+	target, _ := gm.HeadCommit(ctx)
+
+	write("a.txt", "attempt 2\n")
+	if committed, err := gm.CommitFixup(ctx, target); err != nil || !committed {
+		t.Fatalf("CommitFixup() = %v, %v", committed, err)
+	}
+	if committed, err := gm.CommitFixup(ctx, target); err != nil || committed {
+		t.Errorf("CommitFixup() without changes = %v, %v, want false", committed, err)
+	}
+
+	write("b.txt", "final\n")
+	if committed, err := gm.Amend(ctx); err != nil || !committed {
+		t.Fatalf("Amend() = %v, %v", committed, err)
+	}
+	if got := gitOutput(t, testDir, "log", "-1", "--format=%s"); got != "fixup! feat: add a" {
+		t.Errorf("Amend() changed the message to %q", got)
+	}
+
+	// Excluded paths stay out of the commits and survive the rebase
+	if err := os.MkdirAll(filepath.Join(testDir, ".forge", "artifacts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(".forge/artifacts/summary.md", "summary\n")
+
+	if count, err := gm.CommitsSince(ctx, base); err != nil || count != 2 {
+		t.Errorf("CommitsSince() = %d, %v, want 2", count, err)
+	}
+	if err := gm.Autosquash(ctx, base); err != nil {
+		t.Fatalf("Autosquash() failed: %v", err)
+	}
+
+	if got := gitOutput(t, testDir, "log", "--format=%s", base+"..HEAD"); got != "feat: add a" {
+		t.Errorf("expected a single commit after autosquash, got %q", got)
+	}
+	if got := gitOutput(t, testDir, "show", "HEAD:a.txt"); got != "attempt 2" {
+		t.Errorf("expected the fixup folded in, got a.txt = %q", got)
+	}
+	if got := gitOutput(t, testDir, "show", "--name-only", "--format=", "HEAD"); got != "a.txt\nb.txt" {
+		t.Errorf("expected the commit to hold a.txt and b.txt, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(testDir, ".forge", "artifacts", "summary.md")); err != nil {
+		t.Errorf("expected excluded files to survive the rebase: %v", err)
+	}
+}
+
+func TestGitManager_SquashAndUncommit(t *testing.T) {
+	testDir := setupTestRepo(t)
+	ctx := context.Background()
+	gm := NewGitManager(testDir, GitConfig{}, "")
+
+	base, err := gm.HeadCommit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"one.txt", "two.txt"} {
+		if err := os.WriteFile(filepath.Join(testDir, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := gm.Checkpoint(ctx, "add "+name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(testDir, "three.txt"), []byte("three\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gm.Squash(ctx, base, "feat: add files"); err != nil {
+		t.Fatalf("Squash() failed: %v", err)
+	}
+	if got := gitOutput(t, testDir, "log", "--format=%s", base+"..HEAD"); got != "feat: add files" {
+		t.Errorf("expected a single squashed commit, got %q", got)
+	}
+	if got := gitOutput(t, testDir, "show", "--name-only", "--format=", "HEAD"); got != "one.txt\nthree.txt\ntwo.txt" {
+		t.Errorf("expected the squashed commit to hold every file, got %q", got)
+	}
+
+	if err := gm.Uncommit(ctx, base); err != nil {
+		t.Fatalf("Uncommit() failed: %v", err)
+	}
+	if head, _ := gm.HeadCommit(ctx); head != base {
+		t.Errorf("expected HEAD back at the base commit, got %s", head)
+	}
+	if dirty, err := gm.HasUncommittedChanges(ctx); err != nil || !dirty {
+		t.Errorf("expected the changes left uncommitted, got %v, %v", dirty, err)
+	}
+}
@@ -2,10 +2,19 @@ package headless
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/entrhq/forge/pkg/agent/todo"
+	appconfig "github.com/entrhq/forge/pkg/config"
+	"gopkg.in/yaml.v3"
 )
 
+func ptrFloat(v float64) *float64 { return &v }
+
 func TestConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -51,6 +60,18 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid denied command pattern",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Constraints: ConstraintConfig{
+					DeniedCommands: []string{"rm -rf ("},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "negative timeout",
 			config: &Config{
@@ -63,6 +84,145 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "gitlab provider",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Git:          GitConfig{Provider: "gitlab"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "bitbucket provider",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Git:          GitConfig{Provider: "bitbucket"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown git provider",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Git:          GitConfig{Provider: "sourcehut"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "out of range sampling temperature",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Sampling: SamplingConfig{
+					Agent: appconfig.SamplingParams{Temperature: ptrFloat(2.5)},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "message token limit disabled",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Constraints:  ConstraintConfig{MaxMessageTokens: -1},
+			},
+			wantErr: false,
+		},
+		{
+			name: "message token limit below -1",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Constraints:  ConstraintConfig{MaxMessageTokens: -2},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid oversized message policy",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Constraints: ConstraintConfig{
+					MaxMessageTokens:  1000,
+					OversizedMessages: "truncate",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative tool timeout",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Constraints: ConstraintConfig{
+					ToolLimits: &appconfig.ToolLimits{
+						Tools: map[string]appconfig.ToolLimit{"search_files": {Timeout: -time.Second}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "stacked PRs without create_pr",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Git:          GitConfig{StackMaxLines: 500},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty bootstrap command",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Bootstrap:    BootstrapConfig{Enabled: true, Commands: []string{"make deps", " "}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown stack grouping",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Git:          GitConfig{CreatePR: true, StackMaxLines: 500, StackGroupBy: "package"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "workspace paths",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Workspace:    WorkspaceConfig{Paths: []string{"services/auth", "libs/shared"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "workspace path outside the workspace",
+			config: &Config{
+				Task:         "test",
+				Mode:         ModeWrite,
+				WorkspaceDir: "/tmp/test",
+				Workspace:    WorkspaceConfig{Paths: []string{"../other"}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -75,6 +235,46 @@ func TestConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestSamplingConfig_YAMLAndDefaults(t *testing.T) {
+	var config Config
+	data := []byte(`
+sampling:
+  agent:
+    temperature: 0
+    seed: 1234
+  commit:
+    top_p: 0.9
+`)
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+
+	globalSeed := int64(1)
+	merged := config.Sampling.WithDefaults(SamplingConfig{
+		Agent:      appconfig.SamplingParams{Temperature: ptrFloat(0.7), TopP: ptrFloat(0.5), Seed: &globalSeed},
+		Summarizer: appconfig.SamplingParams{Temperature: ptrFloat(0.3)},
+	})
+
+	if merged.Agent.Temperature == nil || *merged.Agent.Temperature != 0 {
+		t.Errorf("expected YAML temperature 0 to override global, got %v", merged.Agent.Temperature)
+	}
+	if merged.Agent.Seed == nil || *merged.Agent.Seed != 1234 {
+		t.Errorf("expected YAML seed to override global, got %v", merged.Agent.Seed)
+	}
+	if merged.Agent.TopP == nil || *merged.Agent.TopP != 0.5 {
+		t.Errorf("expected global top_p to fill unset field, got %v", merged.Agent.TopP)
+	}
+	if merged.Summarizer.Temperature == nil || *merged.Summarizer.Temperature != 0.3 {
+		t.Errorf("expected global summarizer temperature, got %v", merged.Summarizer.Temperature)
+	}
+	if merged.Commit.TopP == nil || *merged.Commit.TopP != 0.9 {
+		t.Errorf("expected commit top_p 0.9, got %v", merged.Commit.TopP)
+	}
+	if samplingSummary(SamplingConfig{}) != nil {
+		t.Errorf("expected no sampling summary when nothing is pinned")
+	}
+}
+
 func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
 
@@ -95,6 +295,23 @@ func TestDefaultConfig(t *testing.T) {
 	}
 }
 
+func TestConstraintConfig_MessageTokenLimit(t *testing.T) {
+	tests := []struct {
+		configured int
+		want       int
+	}{
+		{0, DefaultMaxMessageTokens},
+		{1000, 1000},
+		{-1, 0},
+	}
+	for _, tt := range tests {
+		c := ConstraintConfig{MaxMessageTokens: tt.configured}
+		if got := c.MessageTokenLimit(); got != tt.want {
+			t.Errorf("MessageTokenLimit() with max_message_tokens %d = %d, want %d", tt.configured, got, tt.want)
+		}
+	}
+}
+
 func TestConstraintManager_ValidateToolCall(t *testing.T) {
 	config := ConstraintConfig{
 		AllowedTools: []string{"read_file", "write_file"},
@@ -341,3 +558,86 @@ func (m *mockQualityGate) Required() bool {
 func (m *mockQualityGate) Execute(ctx context.Context, workspaceDir string) error {
 	return m.passFunc()
 }
+
+func TestConfig_ValidateOffline(t *testing.T) {
+	config := &Config{Task: "test", Mode: ModeWrite, WorkspaceDir: "/tmp/test"}
+	config.Git.AutoCommit = true
+	if err := config.ValidateOffline(); err != nil {
+		t.Errorf("local commits should be allowed offline: %v", err)
+	}
+
+	config.Git.AutoPush = true
+	if err := config.ValidateOffline(); err == nil {
+		t.Error("expected error for auto_push offline")
+	}
+
+	config.Git.AutoPush = false
+	config.Git.CreatePR = true
+	if err := config.ValidateOffline(); err == nil {
+		t.Error("expected error for create_pr offline")
+	}
+
+	config.Git.CreatePR = false
+	config.Artifacts.Upload = ArtifactUploadConfig{Provider: "s3", Bucket: "audit"}
+	if err := config.ValidateOffline(); err == nil {
+		t.Error("expected error for artifacts upload offline")
+	}
+}
+
+func TestArtifactWriter_Screenshots(t *testing.T) {
+	config := DefaultConfig()
+	config.WorkspaceDir = t.TempDir()
+	outputDir := filepath.Join(config.WorkspaceDir, config.Artifacts.OutputDir)
+	writer := NewArtifactWriter(outputDir, config.Artifacts)
+
+	if screenshots := writer.Screenshots(); len(screenshots) != 0 {
+		t.Fatalf("expected no screenshots, got %v", screenshots)
+	}
+
+	if err := os.MkdirAll(config.ScreenshotDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"b.png", "a.png", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(config.ScreenshotDir(), name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	summary := &ExecutionSummary{Task: "Check the page", Status: statusSuccess, Screenshots: writer.Screenshots()}
+	if len(summary.Screenshots) != 2 || summary.Screenshots[0] != "screenshots/a.png" || summary.Screenshots[1] != "screenshots/b.png" {
+		t.Fatalf("unexpected screenshots %v", summary.Screenshots)
+	}
+
+	if err := writer.WriteSummaryMarkdown(summary); err != nil {
+		t.Fatal(err)
+	}
+	md, err := os.ReadFile(filepath.Join(outputDir, "summary.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(md), "## Screenshots\n\n![screenshots/a.png](screenshots/a.png)") {
+		t.Errorf("expected screenshots in summary, got:\n%s", md)
+	}
+}
+
+func TestArtifactWriter_TodoList(t *testing.T) {
+	outputDir := t.TempDir()
+	writer := NewArtifactWriter(outputDir, DefaultConfig().Artifacts)
+
+	summary := &ExecutionSummary{Task: "Fix the bug", Status: statusSuccess, TodoList: []todo.Item{
+		{Step: 1, Content: "Reproduce the bug", Status: todo.StatusCompleted},
+		{Step: 2, Content: "Fix the handler", Status: todo.StatusInProgress},
+		{Step: 3, Content: "Update the docs", Status: todo.StatusCancelled},
+	}}
+	if err := writer.WriteSummaryMarkdown(summary); err != nil {
+		t.Fatal(err)
+	}
+	md, err := os.ReadFile(filepath.Join(outputDir, "summary.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "## Plan\n\n2 of 3 steps done\n\n- [x] Reproduce the bug\n- [ ] Fix the handler (in progress)\n- [ ] ~~Update the docs~~ (cancelled)\n"
+	if !strings.Contains(string(md), want) {
+		t.Errorf("expected the plan in summary, got:\n%s", md)
+	}
+}
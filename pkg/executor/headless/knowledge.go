@@ -0,0 +1,331 @@
+package headless
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultKnowledgeDir is where the gate failure knowledge base lives, relative to the workspace
+	defaultKnowledgeDir = ".forge/knowledge"
+
+	// knowledgeFileName is the knowledge base file inside the knowledge directory
+	knowledgeFileName = "gate_failures.json"
+
+	// maxKnowledgeEntries bounds the knowledge base; the least recently seen entries are dropped first
+	maxKnowledgeEntries = 200
+
+	// maxSignatureLines is how many significant failure lines make up a signature
+	maxSignatureLines = 20
+
+	// maxFailureExcerpt and maxFixSummary cap the text stored per entry
+	maxFailureExcerpt = 600
+	maxFixSummary     = 1000
+)
+
+var (
+	hexPattern     = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	digitsPattern  = regexp.MustCompile(`\d+`)
+	spacesPattern  = regexp.MustCompile(`\s+`)
+	failurePattern = regexp.MustCompile(`(?i)error|fail|panic|undefined|cannot|expected|not found|mismatch`)
+)
+
+// KnowledgeConfig configures the cross-run knowledge base of gate failures
+// and the fixes that resolved them.
+type KnowledgeConfig struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Dir     string `yaml:"dir" json:"dir"` // Relative to the workspace (default: .forge/knowledge)
+}
+
+// KnowledgeEntry records how a recurring gate failure was fixed.
+type KnowledgeEntry struct {
+	Gate        string    `json:"gate"`
+	Signature   string    `json:"signature"`
+	Failure     string    `json:"failure"`         // Excerpt of the failure output
+	Fix         string    `json:"fix"`             // The agent's summary of the fix
+	Files       []string  `json:"files,omitempty"` // Files changed by the fix
+	Occurrences int       `json:"occurrences"`     // Times this failure was fixed
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// KnowledgeBase is the set of known gate fixes persisted in the workspace.
+type KnowledgeBase struct {
+	path    string
+	entries []KnowledgeEntry
+	dirty   bool
+}
+
+// LoadKnowledgeBase reads the knowledge base from dir. A missing file yields
+// an empty knowledge base.
+func LoadKnowledgeBase(dir string) (*KnowledgeBase, error) {
+	kb := &KnowledgeBase{path: filepath.Join(dir, knowledgeFileName)}
+
+	data, err := os.ReadFile(kb.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return kb, nil
+	}
+	if err != nil {
+		return kb, fmt.Errorf("failed to read knowledge base: %w", err)
+	}
+	if err := json.Unmarshal(data, &kb.entries); err != nil {
+		return kb, fmt.Errorf("failed to parse knowledge base %s: %w", kb.path, err)
+	}
+	return kb, nil
+}
+
+// Lookup returns the known fixes for a gate failure signature.
+func (kb *KnowledgeBase) Lookup(gate, signature string) []KnowledgeEntry {
+	var matches []KnowledgeEntry
+	for _, entry := range kb.entries {
+		if entry.Gate == gate && entry.Signature == signature {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// Record adds a fix, or refreshes the entry for the same gate and signature.
+func (kb *KnowledgeBase) Record(entry KnowledgeEntry) {
+	entry.Failure = truncateText(entry.Failure, maxFailureExcerpt)
+	entry.Fix = truncateText(entry.Fix, maxFixSummary)
+	kb.dirty = true
+
+	for i, existing := range kb.entries {
+		if existing.Gate == entry.Gate && existing.Signature == entry.Signature {
+			entry.FirstSeen = existing.FirstSeen
+			entry.Occurrences = existing.Occurrences + 1
+			kb.entries[i] = entry
+			return
+		}
+	}
+
+	entry.FirstSeen = entry.LastSeen
+	entry.Occurrences = 1
+	kb.entries = append(kb.entries, entry)
+
+	if len(kb.entries) > maxKnowledgeEntries {
+		sort.SliceStable(kb.entries, func(i, j int) bool {
+			return kb.entries[i].LastSeen.After(kb.entries[j].LastSeen)
+		})
+		kb.entries = kb.entries[:maxKnowledgeEntries]
+	}
+}
+
+// Len returns the number of known fixes.
+func (kb *KnowledgeBase) Len() int {
+	return len(kb.entries)
+}
+
+// Save writes the knowledge base if it changed since it was loaded.
+func (kb *KnowledgeBase) Save() error {
+	if !kb.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(kb.path), 0750); err != nil {
+		return fmt.Errorf("failed to create knowledge directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(kb.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode knowledge base: %w", err)
+	}
+
+	// Write to a temporary file first so an interrupted run never leaves a
+	// truncated knowledge base behind
+	tmpPath := kb.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write knowledge base: %w", err)
+	}
+	if err := os.Rename(tmpPath, kb.path); err != nil {
+		return fmt.Errorf("failed to write knowledge base: %w", err)
+	}
+	kb.dirty = false
+	return nil
+}
+
+// FailureSignature identifies a gate failure across runs. Numbers, addresses
+// and the workspace path are masked, and when the output has lines that look
+// like errors only those count, so the same failure in a different checkout
+// or with different timings has the same signature.
+func FailureSignature(gate, output, workspaceDir string) string {
+	if workspaceDir != "" {
+		output = strings.ReplaceAll(output, workspaceDir, "<workspace>")
+	}
+
+	var lines, failureLines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = hexPattern.ReplaceAllString(line, "0x#")
+		line = digitsPattern.ReplaceAllString(line, "#")
+		line = strings.TrimSpace(spacesPattern.ReplaceAllString(line, " "))
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if failurePattern.MatchString(line) {
+			failureLines = append(failureLines, line)
+		}
+	}
+	if len(failureLines) > 0 {
+		lines = failureLines
+	}
+	if len(lines) > maxSignatureLines {
+		lines = lines[:maxSignatureLines]
+	}
+
+	sum := sha256.Sum256([]byte(gate + "\n" + strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// FormatKnownFixes renders known fixes for inclusion in gate feedback.
+func FormatKnownFixes(entries []KnowledgeEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var msg strings.Builder
+	msg.WriteString("Known fixes from previous runs for these same failures:\n\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&msg, "- %s (fixed %d time(s), last %s): %s\n", entry.Gate, entry.Occurrences, entry.LastSeen.Format("2006-01-02"), entry.Fix)
+		if len(entry.Files) > 0 {
+			fmt.Fprintf(&msg, "  Files changed: %s\n", strings.Join(entry.Files, ", "))
+		}
+	}
+	msg.WriteString("\nCheck whether the same fix applies before investigating from scratch.\n")
+	return msg.String()
+}
+
+// truncateText shortens s to at most n bytes, marking the cut.
+func truncateText(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return strings.TrimSpace(s[:n]) + "…"
+}
+
+// openFailure is a gate failure from this run that no later attempt has fixed yet.
+type openFailure struct {
+	signature string
+	excerpt   string
+}
+
+// FixRecorder follows the quality gate attempts of one run. When a gate that
+// failed in one attempt passes in a later one, the agent's completion summary
+// and the files it changed in between are recorded as the fix.
+type FixRecorder struct {
+	kb           *KnowledgeBase
+	workspaceDir string
+	open         map[string]openFailure // Keyed by gate name
+	summary      string                 // Latest task_completion result
+	files        map[string]struct{}    // Files modified since the last gate run
+	now          func() time.Time
+}
+
+// NewFixRecorder creates a recorder that stores fixes in kb.
+func NewFixRecorder(kb *KnowledgeBase, workspaceDir string) *FixRecorder {
+	return &FixRecorder{
+		kb:           kb,
+		workspaceDir: workspaceDir,
+		open:         make(map[string]openFailure),
+		files:        make(map[string]struct{}),
+		now:          time.Now,
+	}
+}
+
+// TrackCompletion remembers the agent's latest task_completion summary.
+func (r *FixRecorder) TrackCompletion(summary string) {
+	r.summary = summary
+}
+
+// TrackFile remembers a file modified by the agent.
+func (r *FixRecorder) TrackFile(path string) {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(r.workspaceDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	r.files[filepath.ToSlash(path)] = struct{}{}
+}
+
+// Observe processes the results of a gate run and returns the number of fixes
+// recorded. Only assertion failures of required gates are tracked; a gate
+// keeps the signature of its first failure in the run, since that is the
+// failure a later run is most likely to start from.
+func (r *FixRecorder) Observe(results *QualityGateResults) int {
+	recorded := 0
+	for _, result := range results.Results {
+		if !result.Required || result.Skipped {
+			continue
+		}
+		failure, wasOpen := r.open[result.Name]
+		switch {
+		case result.Passed && wasOpen:
+			if r.record(result.Name, failure) {
+				recorded++
+			}
+			delete(r.open, result.Name)
+		case !result.Passed && !wasOpen && result.FailureKind != GateFailureInfrastructure:
+			r.open[result.Name] = openFailure{
+				signature: FailureSignature(result.Name, result.Error, r.workspaceDir),
+				excerpt:   result.Error,
+			}
+		}
+	}
+
+	// The next attempt's changes are the candidate fix for what is still open
+	r.summary = ""
+	r.files = make(map[string]struct{})
+	return recorded
+}
+
+// KnownFixes returns the known fixes for the failed gates in results, formatted
+// for the agent, or an empty string when none are known.
+func (r *FixRecorder) KnownFixes(results *QualityGateResults) string {
+	var entries []KnowledgeEntry
+	for _, result := range results.GetFailedGates() {
+		if result.FailureKind == GateFailureInfrastructure {
+			continue
+		}
+		signature := FailureSignature(result.Name, result.Error, r.workspaceDir)
+		entries = append(entries, r.kb.Lookup(result.Name, signature)...)
+	}
+	return FormatKnownFixes(entries)
+}
+
+// record stores the fix for a failure, skipping it when the agent left no
+// summary and changed no files, since there is nothing to learn from.
+func (r *FixRecorder) record(gate string, failure openFailure) bool {
+	files := make([]string, 0, len(r.files))
+	for path := range r.files {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+
+	fix := r.summary
+	if fix == "" {
+		if len(files) == 0 {
+			return false
+		}
+		fix = "Changed " + strings.Join(files, ", ")
+	}
+
+	r.kb.Record(KnowledgeEntry{
+		Gate:      gate,
+		Signature: failure.signature,
+		Failure:   failure.excerpt,
+		Fix:       fix,
+		Files:     files,
+		LastSeen:  r.now().UTC(),
+	})
+	return true
+}
@@ -0,0 +1,144 @@
+package headless
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func failedResults(gate, output string) *QualityGateResults {
+	return &QualityGateResults{Results: []QualityGateResult{
+		{Name: gate, Required: true, Passed: false, Error: output, FailureKind: GateFailureAssertion},
+	}}
+}
+
+func passedResults(gate string) *QualityGateResults {
+	return &QualityGateResults{AllPassed: true, Results: []QualityGateResult{
+		{Name: gate, Required: true, Passed: true},
+	}}
+}
+
+func TestFailureSignature_IgnoresVolatileDetails(t *testing.T) {
+	first := FailureSignature("tests",
+		"quality gate 'tests' failed: exit status 1\nOutput:\nok  \texample.com/a\t0.12s\n/ci/run-41/pkg/b_test.go:12: expected 3, got 4\nFAIL\texample.com/b\t0.30s",
+		"/ci/run-41")
+	second := FailureSignature("tests",
+		"quality gate 'tests' failed: exit status 1\nOutput:\nok  \texample.com/a\t(cached)\n/ci/run-97/pkg/b_test.go:12: expected 3, got 4\nFAIL\texample.com/b\t1.02s",
+		"/ci/run-97")
+	if first != second {
+		t.Errorf("expected the same signature, got %s and %s", first, second)
+	}
+
+	other := FailureSignature("tests", "pkg/b_test.go:12: undefined: parseConfig", "")
+	if other == first {
+		t.Error("expected a different failure to have a different signature")
+	}
+	if FailureSignature("lint", "pkg/b_test.go:12: undefined: parseConfig", "") == other {
+		t.Error("expected the gate name to be part of the signature")
+	}
+}
+
+func TestKnowledgeBase_SaveAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "knowledge")
+	kb, err := LoadKnowledgeBase(dir)
+	if err != nil {
+		t.Fatalf("LoadKnowledgeBase on missing dir: %v", err)
+	}
+
+	// Nothing to save until an entry is recorded
+	if err := kb.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected no knowledge directory for an unchanged knowledge base")
+	}
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	kb.Record(KnowledgeEntry{Gate: "tests", Signature: "abc", Fix: "Regenerated mocks", LastSeen: now})
+	kb.Record(KnowledgeEntry{Gate: "tests", Signature: "abc", Fix: "Ran go generate ./...", LastSeen: now.Add(time.Hour)})
+	if err := kb.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadKnowledgeBase(dir)
+	if err != nil {
+		t.Fatalf("LoadKnowledgeBase: %v", err)
+	}
+	entries := loaded.Lookup("tests", "abc")
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Fix != "Ran go generate ./..." || entry.Occurrences != 2 || !entry.FirstSeen.Equal(now) {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if len(loaded.Lookup("lint", "abc")) != 0 {
+		t.Error("expected no match for another gate")
+	}
+}
+
+func TestKnowledgeBase_LoadCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, knowledgeFileName), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKnowledgeBase(dir); err == nil {
+		t.Error("expected an error for a corrupt knowledge base")
+	}
+}
+
+func TestFixRecorder_RecordsFixAfterRetry(t *testing.T) {
+	workspace := t.TempDir()
+	kb, _ := LoadKnowledgeBase(filepath.Join(workspace, defaultKnowledgeDir))
+	recorder := NewFixRecorder(kb, workspace)
+
+	output := "pkg/api/client_test.go:40: expected status 200, got 404"
+	if recorded := recorder.Observe(failedResults("tests", output)); recorded != 0 {
+		t.Fatalf("expected nothing recorded on failure, got %d", recorded)
+	}
+
+	recorder.TrackFile(filepath.Join(workspace, "pkg/api/routes.go"))
+	recorder.TrackCompletion("Registered the /v2/status route")
+	if recorded := recorder.Observe(passedResults("tests")); recorded != 1 {
+		t.Fatalf("expected 1 fix recorded, got %d", recorded)
+	}
+
+	// A later run hitting the same failure gets the fix in its feedback
+	next := NewFixRecorder(kb, workspace)
+	known := next.KnownFixes(failedResults("tests", output))
+	if !strings.Contains(known, "Registered the /v2/status route") || !strings.Contains(known, "pkg/api/routes.go") {
+		t.Errorf("expected the recorded fix, got:\n%s", known)
+	}
+	if next.KnownFixes(failedResults("tests", "pkg/db/store_test.go:9: unexpected EOF")) != "" {
+		t.Error("expected no known fixes for an unrelated failure")
+	}
+}
+
+func TestFixRecorder_SkipsUnfixableFailures(t *testing.T) {
+	kb, _ := LoadKnowledgeBase(t.TempDir())
+	recorder := NewFixRecorder(kb, "/workspace")
+
+	infra := &QualityGateResults{Results: []QualityGateResult{
+		{Name: "tests", Required: true, Error: "go: command not found", FailureKind: GateFailureInfrastructure},
+		{Name: "lint", Required: false, Error: "line too long", FailureKind: GateFailureAssertion},
+	}}
+	recorder.Observe(infra)
+	recorder.TrackCompletion("Done")
+	if recorded := recorder.Observe(&QualityGateResults{AllPassed: true, Results: []QualityGateResult{
+		{Name: "tests", Required: true, Passed: true},
+		{Name: "lint", Required: false, Passed: true},
+	}}); recorded != 0 {
+		t.Errorf("expected infrastructure and optional gate failures to be skipped, got %d recorded", recorded)
+	}
+
+	// A retry that changed nothing and left no summary teaches nothing
+	recorder.Observe(failedResults("tests", "expected 1, got 2"))
+	if recorded := recorder.Observe(passedResults("tests")); recorded != 0 {
+		t.Errorf("expected no fix without a summary or changed files, got %d", recorded)
+	}
+	if kb.Len() != 0 {
+		t.Errorf("expected an empty knowledge base, got %d entries", kb.Len())
+	}
+}
@@ -1,11 +1,15 @@
 package headless
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/entrhq/forge/pkg/types"
 )
 
 // LogLevel represents the logging verbosity level
@@ -22,10 +26,22 @@ const (
 	LogLevelDebug
 )
 
+// LogFormat selects how the logger renders output
+type LogFormat string
+
+const (
+	// LogFormatText renders human-oriented, colored output (default)
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON renders one JSON object per line (NDJSON) for machine consumption
+	LogFormatJSON LogFormat = "json"
+)
+
 // Logger provides structured, beautiful logging for headless execution
 type Logger struct {
 	level  LogLevel
+	format LogFormat
 	writer io.Writer
+	mu     sync.Mutex // Serializes JSON records written from concurrent goroutines
 
 	// ANSI color codes
 	colorReset     string
@@ -41,14 +57,21 @@ type Logger struct {
 	colorBoldWhite string
 
 	// Execution state
-	startTime time.Time
-	stepCount int
+	startTime  time.Time
+	stepCount  int
+	toolStarts map[string]time.Time // Tool call ID -> tool call event time, for JSON durations
 }
 
-// NewLogger creates a new logger with the specified level
+// NewLogger creates a new text logger with the specified level
 func NewLogger(level LogLevel) *Logger {
+	return NewLoggerWithFormat(level, LogFormatText)
+}
+
+// NewLoggerWithFormat creates a new logger with the specified level and output format
+func NewLoggerWithFormat(level LogLevel, format LogFormat) *Logger {
 	return &Logger{
 		level:          level,
+		format:         format,
 		writer:         os.Stdout,
 		colorReset:     "\033[0m",
 		colorGreen:     "\033[32m",
@@ -62,11 +85,16 @@ func NewLogger(level LogLevel) *Logger {
 		colorBoldRed:   "\033[1;31m",
 		colorBoldWhite: "\033[1;37m",
 		startTime:      time.Now(),
+		toolStarts:     make(map[string]time.Time),
 	}
 }
 
 // Header prints a prominent header message
 func (l *Logger) Header(message string) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelNormal, "info", logRecord{Event: "header", Message: message})
+		return
+	}
 	if l.level >= LogLevelNormal {
 		fmt.Fprintf(l.writer, "\n%s%s%s\n", l.colorBoldWhite, strings.Repeat("=", 70), l.colorReset)
 		fmt.Fprintf(l.writer, "%s  %s%s\n", l.colorBoldWhite, message, l.colorReset)
@@ -76,6 +104,10 @@ func (l *Logger) Header(message string) {
 
 // Section prints a section divider
 func (l *Logger) Section(title string) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelNormal, "info", logRecord{Event: "section", Message: title})
+		return
+	}
 	if l.level >= LogLevelNormal {
 		fmt.Fprintln(l.writer)
 		fmt.Fprintf(l.writer, "%s▶ %s%s\n", l.colorCyan, title, l.colorReset)
@@ -85,14 +117,23 @@ func (l *Logger) Section(title string) {
 
 // Step prints a numbered step in the execution
 func (l *Logger) Step(message string) {
-	if l.level >= LogLevelNormal {
-		l.stepCount++
-		fmt.Fprintf(l.writer, "\n%s[%d] %s%s\n", l.colorCyan, l.stepCount, message, l.colorReset)
+	if l.level < LogLevelNormal {
+		return
 	}
+	l.stepCount++
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelNormal, "info", logRecord{Event: "step", Message: message, Step: l.stepCount})
+		return
+	}
+	fmt.Fprintf(l.writer, "\n%s[%d] %s%s\n", l.colorCyan, l.stepCount, message, l.colorReset)
 }
 
 // Successf prints a success message with checkmark
 func (l *Logger) Successf(format string, args ...any) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelNormal, "info", logRecord{Event: "success", Message: fmt.Sprintf(format, args...)})
+		return
+	}
 	if l.level >= LogLevelNormal {
 		msg := fmt.Sprintf(format, args...)
 		fmt.Fprintf(l.writer, "%s✓ %s%s\n", l.colorBoldGreen, msg, l.colorReset)
@@ -101,6 +142,10 @@ func (l *Logger) Successf(format string, args ...any) {
 
 // Infof prints an informational message
 func (l *Logger) Infof(format string, args ...any) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelNormal, "info", logRecord{Event: "info", Message: fmt.Sprintf(format, args...)})
+		return
+	}
 	if l.level >= LogLevelNormal {
 		msg := fmt.Sprintf(format, args...)
 		fmt.Fprintf(l.writer, "%s%s%s\n", l.colorSalmon, msg, l.colorReset)
@@ -109,6 +154,10 @@ func (l *Logger) Infof(format string, args ...any) {
 
 // Warningf prints a warning message
 func (l *Logger) Warningf(format string, args ...any) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelQuiet, "warn", logRecord{Event: "warning", Message: fmt.Sprintf(format, args...)})
+		return
+	}
 	if l.level >= LogLevelQuiet {
 		msg := fmt.Sprintf(format, args...)
 		fmt.Fprintf(l.writer, "%s⚠ Warning: %s%s\n", l.colorYellow, msg, l.colorReset)
@@ -117,6 +166,10 @@ func (l *Logger) Warningf(format string, args ...any) {
 
 // Errorf prints an error message
 func (l *Logger) Errorf(format string, args ...any) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelQuiet, "error", logRecord{Event: "error", Message: fmt.Sprintf(format, args...)})
+		return
+	}
 	if l.level >= LogLevelQuiet {
 		msg := fmt.Sprintf(format, args...)
 		fmt.Fprintf(l.writer, "%s✗ Error: %s%s\n", l.colorBoldRed, msg, l.colorReset)
@@ -125,6 +178,10 @@ func (l *Logger) Errorf(format string, args ...any) {
 
 // Verbosef prints detailed information (only in verbose mode)
 func (l *Logger) Verbosef(format string, args ...any) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelVerbose, "verbose", logRecord{Event: "verbose", Message: fmt.Sprintf(format, args...)})
+		return
+	}
 	if l.level >= LogLevelVerbose {
 		msg := fmt.Sprintf(format, args...)
 		fmt.Fprintf(l.writer, "%s→ %s%s\n", l.colorGray, msg, l.colorReset)
@@ -133,6 +190,10 @@ func (l *Logger) Verbosef(format string, args ...any) {
 
 // Debugf prints debug information (only in debug mode)
 func (l *Logger) Debugf(format string, args ...any) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelDebug, "debug", logRecord{Event: "debug", Message: fmt.Sprintf(format, args...)})
+		return
+	}
 	if l.level >= LogLevelDebug {
 		msg := fmt.Sprintf(format, args...)
 		fmt.Fprintf(l.writer, "%s[DEBUG] %s%s\n", l.colorGray, msg, l.colorReset)
@@ -141,6 +202,10 @@ func (l *Logger) Debugf(format string, args ...any) {
 
 // ToolCall logs a tool execution with formatting based on verbosity
 func (l *Logger) ToolCall(toolName string, count int) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelNormal, "info", logRecord{Event: "tool_call_count", Tool: toolName, Count: count})
+		return
+	}
 	switch l.level {
 	case LogLevelQuiet:
 		// Don't log individual tool calls in quiet mode
@@ -155,6 +220,10 @@ func (l *Logger) ToolCall(toolName string, count int) {
 
 // FileModified logs a file modification
 func (l *Logger) FileModified(path string, linesAdded, linesRemoved int) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelNormal, "info", logRecord{Event: "file_modified", Path: path, LinesAdded: &linesAdded, LinesRemoved: &linesRemoved})
+		return
+	}
 	if l.level >= LogLevelNormal {
 		change := ""
 		if linesAdded > 0 || linesRemoved > 0 {
@@ -166,6 +235,14 @@ func (l *Logger) FileModified(path string, linesAdded, linesRemoved int) {
 
 // QualityGate logs quality gate execution
 func (l *Logger) QualityGate(name string, passed bool, message string) {
+	if l.format == LogFormatJSON {
+		level := "info"
+		if !passed {
+			level = "error"
+		}
+		l.emit(LogLevelNormal, level, logRecord{Event: "quality_gate", Gate: name, Passed: &passed, Message: message})
+		return
+	}
 	if l.level >= LogLevelNormal {
 		if passed {
 			fmt.Fprintf(l.writer, "%s  ✓ %s: passed%s\n", l.colorBoldGreen, name, l.colorReset)
@@ -180,6 +257,10 @@ func (l *Logger) QualityGate(name string, passed bool, message string) {
 
 // QualityGateRetry logs a quality gate retry attempt
 func (l *Logger) QualityGateRetry(attempt, maxRetries int) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelNormal, "warn", logRecord{Event: "quality_gate_retry", Attempt: attempt, MaxAttempts: maxRetries})
+		return
+	}
 	if l.level >= LogLevelNormal {
 		fmt.Fprintf(l.writer, "%s  Retrying quality gates (attempt %d/%d)%s\n", l.colorYellow, attempt, maxRetries, l.colorReset)
 	}
@@ -187,6 +268,14 @@ func (l *Logger) QualityGateRetry(attempt, maxRetries int) {
 
 // GitOperation logs a git operation
 func (l *Logger) GitOperation(operation, details string) {
+	if l.format == LogFormatJSON {
+		rec := logRecord{Event: "git", Message: operation}
+		if l.level >= LogLevelVerbose {
+			rec.Details = details
+		}
+		l.emit(LogLevelNormal, "info", rec)
+		return
+	}
 	if l.level >= LogLevelNormal {
 		fmt.Fprintf(l.writer, "%s  🔀 Git: %s%s\n", l.colorCyan, operation, l.colorReset)
 		if details != "" && l.level >= LogLevelVerbose {
@@ -197,6 +286,10 @@ func (l *Logger) GitOperation(operation, details string) {
 
 // Progress shows a progress update (dots, spinner, etc.)
 func (l *Logger) Progress(message string) {
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelNormal, "info", logRecord{Event: "progress", Message: message})
+		return
+	}
 	if l.level >= LogLevelNormal {
 		fmt.Fprintf(l.writer, "%s  %s%s", l.colorGray, message, l.colorReset)
 	}
@@ -207,6 +300,10 @@ func (l *Logger) Summary(status string, summary *ExecutionSummary) {
 	if l.level < LogLevelQuiet {
 		return
 	}
+	if l.format == LogFormatJSON {
+		l.emit(LogLevelQuiet, "info", logRecord{Event: "summary", Message: status, Summary: summary})
+		return
+	}
 
 	l.printSummaryHeader()
 	l.printStatus(status)
@@ -263,6 +360,9 @@ func (l *Logger) printMetrics(summary *ExecutionSummary) {
 	if summary.Metrics.TokensUsed > 0 {
 		fmt.Fprintf(l.writer, "    Tokens used: %s\n", formatNumber(summary.Metrics.TokensUsed))
 	}
+	if summary.Metrics.CostUSD > 0 {
+		fmt.Fprintf(l.writer, "    Estimated cost: $%.2f\n", summary.Metrics.CostUSD)
+	}
 }
 
 func (l *Logger) printModifiedFiles(summary *ExecutionSummary) {
@@ -287,7 +387,9 @@ func (l *Logger) printQualityGates(summary *ExecutionSummary) {
 
 	fmt.Fprintf(l.writer, "\n  🎯 Quality Gates:\n")
 	for _, result := range summary.QualityGateResults.Results {
-		if result.Passed {
+		if result.Passed && result.Skipped {
+			fmt.Fprintf(l.writer, "%s    ↷ %s (skipped)%s\n", l.colorGray, result.Name, l.colorReset)
+		} else if result.Passed {
 			fmt.Fprintf(l.writer, "%s    ✓ %s%s\n", l.colorBoldGreen, result.Name, l.colorReset)
 		} else {
 			fmt.Fprintf(l.writer, "%s    ✗ %s%s\n", l.colorBoldRed, result.Name, l.colorReset)
@@ -327,11 +429,248 @@ func (l *Logger) printSummaryFooter() {
 
 // Newline adds a blank line (respects log level)
 func (l *Logger) Newline() {
-	if l.level >= LogLevelNormal {
+	if l.level >= LogLevelNormal && l.format != LogFormatJSON {
 		fmt.Fprintln(l.writer)
 	}
 }
 
+// AgentEvent logs a user-facing agent event: thinking, API calls, tool calls
+// and results, token usage and context management. Streaming content events
+// are not logged.
+func (l *Logger) AgentEvent(event *types.AgentEvent) {
+	if l.format == LogFormatJSON {
+		l.agentEventJSON(event)
+		return
+	}
+
+	switch event.Type {
+	case types.EventTypeThinkingStart:
+		l.Infof("● Thinking...")
+	case types.EventTypeAPICallStart:
+		l.Infof("~ API call...")
+	case types.EventTypeToolCall:
+		l.Infof("> tool: %s", formatToolCall(event))
+	case types.EventTypeToolResult:
+		l.Infof("✓ tool: %s", formatToolCall(event))
+	case types.EventTypeToolResultError:
+		l.Infof("✗ tool: %s", formatToolCall(event))
+	case types.EventTypeContextSummarizationStart:
+		l.Infof("~ Summarizing context...")
+	case types.EventTypeOversizedMessage:
+		if info := event.OversizedMessage; info != nil {
+			l.Warningf("! %s %s: %d tokens exceeds the %d-token per-message limit",
+				info.Action, oversizedSource(info), info.Tokens, info.Limit)
+		}
+	case types.EventTypeModelFallback:
+		if info := event.ModelFallback; info != nil {
+			l.Warningf("! %s", modelFallbackMessage(info))
+		}
+	case types.EventTypeTurnBudgetExceeded:
+		if info := event.TurnBudget; info != nil {
+			l.Warningf("! %s", turnBudgetMessage(info))
+		}
+	}
+}
+
+// agentEventJSON writes the JSON record for an agent event
+func (l *Logger) agentEventJSON(event *types.AgentEvent) {
+	rec := logRecord{
+		Event:      string(event.Type),
+		TurnID:     event.TurnID,
+		Tool:       event.ToolName,
+		ToolCallID: event.ToolCallID,
+	}
+	if !event.Timestamp.IsZero() {
+		rec.Time = formatLogTime(event.Timestamp)
+	}
+	level := "info"
+
+	switch event.Type {
+	case types.EventTypeToolCall:
+		if event.ToolCallID != "" && !event.Timestamp.IsZero() {
+			l.mu.Lock()
+			l.toolStarts[event.ToolCallID] = event.Timestamp
+			l.mu.Unlock()
+		}
+	case types.EventTypeToolResult, types.EventTypeToolResultError:
+		rec.DurationMs = l.toolDuration(event)
+		if event.Type == types.EventTypeToolResultError {
+			level = "error"
+			if event.Error != nil {
+				rec.Message = event.Error.Error()
+			}
+		}
+	case types.EventTypeThinkingStart, types.EventTypeAPICallStart, types.EventTypeTurnEnd,
+		types.EventTypeContextSummarizationStart, types.EventTypeContextSummarizationComplete:
+	case types.EventTypeContextSummarizationError, types.EventTypeError:
+		level = "error"
+		if event.Error != nil {
+			rec.Message = event.Error.Error()
+		}
+	case types.EventTypeTokenUsage:
+		if event.TokenUsage == nil {
+			return
+		}
+		rec.Tokens = &logTokens{
+			Prompt:     event.TokenUsage.PromptTokens,
+			Completion: event.TokenUsage.CompletionTokens,
+			Total:      event.TokenUsage.TotalTokens,
+		}
+	case types.EventTypeOversizedMessage:
+		info := event.OversizedMessage
+		if info == nil {
+			return
+		}
+		level = "warn"
+		rec.Tool = info.ToolName
+		rec.Message = fmt.Sprintf("%s %s: %d tokens exceeds the %d-token per-message limit",
+			info.Action, oversizedSource(info), info.Tokens, info.Limit)
+		rec.Tokens = &logTokens{Total: info.Tokens}
+	case types.EventTypeModelFallback:
+		info := event.ModelFallback
+		if info == nil {
+			return
+		}
+		level = "warn"
+		rec.Message = modelFallbackMessage(info)
+		rec.Details = info.Reason
+	case types.EventTypeTurnBudgetExceeded:
+		info := event.TurnBudget
+		if info == nil {
+			return
+		}
+		level = "warn"
+		rec.Message = turnBudgetMessage(info)
+	default:
+		return
+	}
+
+	l.emit(LogLevelNormal, level, rec)
+}
+
+// toolDuration returns the milliseconds between a tool result event and the
+// matching tool call event, or nil if the call was not seen.
+func (l *Logger) toolDuration(event *types.AgentEvent) *int64 {
+	l.mu.Lock()
+	start, ok := l.toolStarts[event.ToolCallID]
+	delete(l.toolStarts, event.ToolCallID)
+	l.mu.Unlock()
+
+	if !ok || event.Timestamp.IsZero() {
+		return nil
+	}
+	ms := event.Timestamp.Sub(start).Milliseconds()
+	return &ms
+}
+
+// logRecord is a single NDJSON log line. Field order is stable so records
+// diff and grep predictably.
+type logRecord struct {
+	Time         string            `json:"time"`
+	Level        string            `json:"level"`
+	Event        string            `json:"event"`
+	TurnID       string            `json:"turn_id,omitempty"`
+	Message      string            `json:"message,omitempty"`
+	Details      string            `json:"details,omitempty"`
+	Tool         string            `json:"tool,omitempty"`
+	ToolCallID   string            `json:"tool_call_id,omitempty"`
+	DurationMs   *int64            `json:"duration_ms,omitempty"`
+	Count        int               `json:"count,omitempty"`
+	Step         int               `json:"step,omitempty"`
+	Path         string            `json:"path,omitempty"`
+	LinesAdded   *int              `json:"lines_added,omitempty"`
+	LinesRemoved *int              `json:"lines_removed,omitempty"`
+	Tokens       *logTokens        `json:"tokens,omitempty"`
+	Gate         string            `json:"gate,omitempty"`
+	Passed       *bool             `json:"passed,omitempty"`
+	Attempt      int               `json:"attempt,omitempty"`
+	MaxAttempts  int               `json:"max_attempts,omitempty"`
+	Summary      *ExecutionSummary `json:"summary,omitempty"`
+}
+
+// logTokens reports token counts in a JSON log record
+type logTokens struct {
+	Prompt     int `json:"prompt,omitempty"`
+	Completion int `json:"completion,omitempty"`
+	Total      int `json:"total"`
+}
+
+// emit writes rec as one JSON line if the logger's level is at least minLevel
+func (l *Logger) emit(minLevel LogLevel, level string, rec logRecord) {
+	if l.level < minLevel {
+		return
+	}
+	if rec.Time == "" {
+		rec.Time = formatLogTime(time.Now())
+	}
+	rec.Level = level
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.writer.Write(data)
+}
+
+// formatLogTime formats t as a UTC RFC 3339 timestamp with nanoseconds
+func formatLogTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// formatToolCall formats a tool call for display, showing parameters when available
+func formatToolCall(event *types.AgentEvent) string {
+	if len(event.ToolInput) == 0 {
+		return event.ToolName
+	}
+
+	// Build a compact parameter summary
+	var params strings.Builder
+	count := 0
+	for key := range event.ToolInput {
+		if count > 0 {
+			params.WriteString(", ")
+		}
+		params.WriteString(key)
+		count++
+		if count >= 3 {
+			params.WriteString(", ...")
+			break
+		}
+	}
+
+	return fmt.Sprintf("%s(%s)", event.ToolName, params.String())
+}
+
+// oversizedSource describes what produced an oversized message
+func oversizedSource(info *types.OversizedMessage) string {
+	if info.ToolName != "" {
+		return info.ToolName + " result"
+	}
+	return "user input"
+}
+
+// modelFallbackMessage describes a move to the fallback model
+func modelFallbackMessage(info *types.ModelFallback) string {
+	return fmt.Sprintf("%s is rate limited; using %s until %s",
+		info.From, info.To, info.Until.Format(time.TimeOnly))
+}
+
+// turnBudgetMessage describes a turn that used up its budget
+func turnBudgetMessage(info *types.TurnBudget) string {
+	spent := fmt.Sprintf("%d tool calls reached the per-turn limit", info.ToolCalls)
+	if info.Limit == types.TurnBudgetDuration {
+		spent = fmt.Sprintf("turn ran for %s, past the %s limit", info.Elapsed, info.MaxDuration)
+	}
+	if info.Stopped {
+		return spent + "; stopped the turn"
+	}
+	return spent + "; asking the agent to wrap up"
+}
+
 // parseLogLevel converts a string log level to LogLevel type
 func parseLogLevel(level string) LogLevel {
 	switch level {
@@ -0,0 +1,156 @@
+package headless
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func newTestLogger(level LogLevel, format LogFormat) (*Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger := NewLoggerWithFormat(level, format)
+	logger.writer = &buf
+	return logger, &buf
+}
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("line is not valid JSON: %q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestLogger_JSONFormat(t *testing.T) {
+	logger, buf := newTestLogger(LogLevelNormal, LogFormatJSON)
+
+	logger.Header("Forge Headless Execution")
+	logger.Infof("Task: %s", "fix lint")
+	logger.Warningf("disk %d%% full", 90)
+	logger.QualityGate("tests", false, "exit status 1")
+	logger.Verbosef("hidden at normal level")
+	logger.Newline()
+
+	records := decodeRecords(t, buf)
+	if len(records) != 4 {
+		t.Fatalf("expected 4 records, got %d: %s", len(records), buf.String())
+	}
+
+	for _, rec := range records {
+		if _, ok := rec["time"].(string); !ok {
+			t.Errorf("record missing time: %v", rec)
+		}
+	}
+
+	if records[1]["event"] != "info" || records[1]["level"] != "info" || records[1]["message"] != "Task: fix lint" {
+		t.Errorf("unexpected info record: %v", records[1])
+	}
+	if records[2]["level"] != "warn" || records[2]["message"] != "disk 90% full" {
+		t.Errorf("unexpected warning record: %v", records[2])
+	}
+	gate := records[3]
+	if gate["event"] != "quality_gate" || gate["gate"] != "tests" || gate["passed"] != false || gate["level"] != "error" {
+		t.Errorf("unexpected quality gate record: %v", gate)
+	}
+	if strings.Contains(buf.String(), "\033[") {
+		t.Error("JSON output should not contain ANSI escape codes")
+	}
+}
+
+func TestLogger_AgentEventJSON(t *testing.T) {
+	logger, buf := newTestLogger(LogLevelNormal, LogFormatJSON)
+
+	logger.AgentEvent(&types.AgentEvent{Type: types.EventTypeToolCall, ToolName: "read_file", ToolCallID: "call_1"})
+	logger.AgentEvent(&types.AgentEvent{Type: types.EventTypeMessageContent, Content: "streaming chunk"})
+	logger.AgentEvent(&types.AgentEvent{
+		Type:       types.EventTypeTokenUsage,
+		TokenUsage: &types.TokenUsage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120},
+	})
+	logger.AgentEvent(&types.AgentEvent{Type: types.EventTypeToolResultError, ToolName: "write_file", Error: errors.New("denied")})
+
+	records := decodeRecords(t, buf)
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records (streaming content skipped), got %d: %s", len(records), buf.String())
+	}
+
+	if records[0]["event"] != "tool_call" || records[0]["tool"] != "read_file" || records[0]["tool_call_id"] != "call_1" {
+		t.Errorf("unexpected tool call record: %v", records[0])
+	}
+
+	tokens, ok := records[1]["tokens"].(map[string]any)
+	if !ok {
+		t.Fatalf("token usage record missing tokens: %v", records[1])
+	}
+	if tokens["prompt"] != 100.0 || tokens["completion"] != 20.0 || tokens["total"] != 120.0 {
+		t.Errorf("unexpected tokens: %v", tokens)
+	}
+
+	if records[2]["level"] != "error" || records[2]["message"] != "denied" {
+		t.Errorf("unexpected tool error record: %v", records[2])
+	}
+}
+
+func TestLogger_AgentEventJSONTiming(t *testing.T) {
+	logger, buf := newTestLogger(LogLevelNormal, LogFormatJSON)
+	start := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	logger.AgentEvent(&types.AgentEvent{Type: types.EventTypeToolCall, ToolName: "run_tests", ToolCallID: "call_1", TurnID: "turn_1", Timestamp: start})
+	logger.AgentEvent(&types.AgentEvent{Type: types.EventTypeToolResult, ToolName: "run_tests", ToolCallID: "call_1", TurnID: "turn_1", Timestamp: start.Add(1500 * time.Millisecond)})
+
+	records := decodeRecords(t, buf)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0]["time"] != "2025-01-15T10:30:00Z" {
+		t.Errorf("expected record time to come from the event, got %v", records[0]["time"])
+	}
+	for _, rec := range records {
+		if rec["turn_id"] != "turn_1" {
+			t.Errorf("expected turn_id turn_1, got %v", rec["turn_id"])
+		}
+	}
+	if _, ok := records[0]["duration_ms"]; ok {
+		t.Error("tool call record should not have a duration")
+	}
+	if records[1]["duration_ms"] != 1500.0 {
+		t.Errorf("expected duration_ms 1500, got %v", records[1]["duration_ms"])
+	}
+}
+
+func TestLogger_AgentEventText(t *testing.T) {
+	logger, buf := newTestLogger(LogLevelNormal, LogFormatText)
+
+	logger.AgentEvent(&types.AgentEvent{Type: types.EventTypeToolCall, ToolName: "read_file"})
+
+	if !strings.Contains(buf.String(), "> tool: read_file") {
+		t.Errorf("expected glyph output, got %q", buf.String())
+	}
+}
+
+func TestConfig_ValidateLoggingFormat(t *testing.T) {
+	config := &Config{Task: "test", Mode: ModeWrite, WorkspaceDir: "/tmp/test"}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if config.Logging.Format != string(LogFormatText) {
+		t.Errorf("Logging.Format = %q, want %q", config.Logging.Format, LogFormatText)
+	}
+
+	config.Logging.Format = "xml"
+	if err := config.Validate(); err == nil {
+		t.Error("expected error for invalid logging format")
+	}
+}
@@ -0,0 +1,572 @@
+package headless
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// QualityGateTypeCommand runs a shell command; it is the default gate type
+	QualityGateTypeCommand = "command"
+
+	// QualityGateTypeLSP checks agent-modified files for language server diagnostics
+	QualityGateTypeLSP = "lsp"
+
+	// defaultLSPCommand is the language server used when an lsp gate sets no command
+	defaultLSPCommand = "gopls"
+
+	// lspSettleDelay is how long the server must stay quiet after reporting
+	// diagnostics for every opened file before the results are taken as final.
+	// Servers often publish syntax diagnostics first and type errors later.
+	lspSettleDelay = 2 * time.Second
+)
+
+// lspSeverities maps severity names in gate configuration to LSP DiagnosticSeverity values.
+var lspSeverities = map[string]int{
+	"error":       1,
+	"warning":     2,
+	"information": 3,
+	"hint":        4,
+}
+
+// lspLanguageIDs maps file extensions to LSP language identifiers for didOpen.
+var lspLanguageIDs = map[string]string{
+	".go":   "go",
+	".py":   "python",
+	".js":   "javascript",
+	".jsx":  "javascriptreact",
+	".ts":   "typescript",
+	".tsx":  "typescriptreact",
+	".rs":   "rust",
+	".java": "java",
+	".c":    "c",
+	".h":    "c",
+	".cpp":  "cpp",
+	".cc":   "cpp",
+	".hpp":  "cpp",
+	".rb":   "ruby",
+	".php":  "php",
+	".cs":   "csharp",
+}
+
+// LSPDiagnosticsGate starts a language server, opens the files the agent
+// modified and fails if the server reports diagnostics at the configured
+// severities. It catches compile-level mistakes in seconds, so it is best
+// placed before slower build and test gates.
+type LSPDiagnosticsGate struct {
+	name          string
+	command       string
+	required      bool
+	timeout       time.Duration
+	severities    []int
+	extensions    []string
+	modifiedFiles func() []string
+	settle        time.Duration
+	retry         RetryPolicy
+}
+
+// NewLSPDiagnosticsGate creates a diagnostics gate. modifiedFiles returns the
+// paths the agent modified, relative to the workspace or absolute. Only
+// files with one of extensions are checked; no extensions checks every file.
+// severities defaults to errors only.
+func NewLSPDiagnosticsGate(name, command string, required bool, timeout time.Duration, severities, extensions []string, modifiedFiles func() []string) *LSPDiagnosticsGate {
+	if timeout <= 0 {
+		timeout = 3 * time.Minute
+	}
+	if command == "" {
+		command = defaultLSPCommand
+		if len(extensions) == 0 {
+			extensions = []string{".go"}
+		}
+	}
+	if len(severities) == 0 {
+		severities = []string{"error"}
+	}
+
+	levels := make([]int, 0, len(severities))
+	for _, severity := range severities {
+		if level, ok := lspSeverities[strings.ToLower(severity)]; ok {
+			levels = append(levels, level)
+		}
+	}
+
+	return &LSPDiagnosticsGate{
+		name:          name,
+		command:       command,
+		required:      required,
+		timeout:       timeout,
+		severities:    levels,
+		extensions:    extensions,
+		modifiedFiles: modifiedFiles,
+		settle:        lspSettleDelay,
+	}
+}
+
+// Name returns the name of the quality gate
+func (g *LSPDiagnosticsGate) Name() string {
+	return g.name
+}
+
+// Required returns true if failure should abort execution
+func (g *LSPDiagnosticsGate) Required() bool {
+	return g.required
+}
+
+// WithRetryPolicy sets how the gate is re-run on failure and returns the gate.
+func (g *LSPDiagnosticsGate) WithRetryPolicy(policy RetryPolicy) *LSPDiagnosticsGate {
+	if policy.Flaky && policy.MaxRetries == 0 {
+		policy.MaxRetries = defaultFlakyRetries
+	}
+	g.retry = policy
+	return g
+}
+
+// RetryPolicy returns the gate's retry policy
+func (g *LSPDiagnosticsGate) RetryPolicy() RetryPolicy {
+	return g.retry
+}
+
+// Execute opens the modified files in the language server and fails if any
+// diagnostics at the configured severities are reported.
+func (g *LSPDiagnosticsGate) Execute(ctx context.Context, workspaceDir string) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("quality gate '%s' skipped: parent context canceled", g.name)
+	default:
+	}
+
+	files := g.filesToCheck(workspaceDir)
+	if len(files) == 0 {
+		return nil
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	diagnostics, err := g.collectDiagnostics(execCtx, workspaceDir, files)
+	if err != nil {
+		return &QualityGateError{
+			GateName: g.name,
+			Command:  g.command,
+			Err:      err,
+			Kind:     GateFailureInfrastructure,
+		}
+	}
+
+	var problems []string
+	for _, file := range files {
+		for _, d := range diagnostics[file] {
+			if slices.Contains(g.severities, d.severity()) {
+				problems = append(problems, d.format(file))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return &QualityGateError{
+		GateName: g.name,
+		Command:  g.command,
+		Output:   strings.Join(problems, "\n"),
+		Err:      fmt.Errorf("%d diagnostic(s) reported", len(problems)),
+		Kind:     GateFailureAssertion,
+	}
+}
+
+// filesToCheck returns the workspace-relative modified files that still
+// exist and match the gate's extensions, sorted.
+func (g *LSPDiagnosticsGate) filesToCheck(workspaceDir string) []string {
+	if g.modifiedFiles == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, path := range g.modifiedFiles() {
+		absPath := path
+		if !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(workspaceDir, path)
+		}
+		relPath, err := filepath.Rel(workspaceDir, absPath)
+		if err != nil || strings.HasPrefix(relPath, "..") || seen[relPath] {
+			continue
+		}
+		if len(g.extensions) > 0 && !slices.Contains(g.extensions, filepath.Ext(relPath)) {
+			continue
+		}
+		if info, err := os.Stat(absPath); err != nil || info.IsDir() {
+			continue // Deleted by the agent
+		}
+		seen[relPath] = true
+		files = append(files, relPath)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// collectDiagnostics runs the language server over files and returns the
+// final diagnostics reported for each, keyed by workspace-relative path.
+func (g *LSPDiagnosticsGate) collectDiagnostics(ctx context.Context, workspaceDir string, files []string) (_ map[string][]lspDiagnostic, err error) {
+	parts := strings.Fields(g.command)
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("empty language server command")
+	}
+
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
+	cmd.Dir = workspaceDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start language server: %w", err)
+	}
+	defer func() {
+		_ = stdin.Close()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		if msg := strings.TrimSpace(stderr.String()); err != nil && msg != "" {
+			err = fmt.Errorf("%w\nServer output:\n%s", err, msg)
+		}
+	}()
+
+	conn := newLSPConn(stdin, stdout)
+	go conn.readLoop()
+	defer close(conn.done)
+
+	rootURI := fileURI(workspaceDir)
+	if _, err := conn.call(ctx, "initialize", map[string]any{
+		"processId": os.Getpid(),
+		"rootUri":   rootURI,
+		"workspaceFolders": []map[string]any{
+			{"uri": rootURI, "name": filepath.Base(workspaceDir)},
+		},
+		"capabilities": map[string]any{
+			"textDocument": map[string]any{
+				"publishDiagnostics": map[string]any{},
+			},
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("language server initialize failed: %w", err)
+	}
+	if err := conn.notify("initialized", map[string]any{}); err != nil {
+		return nil, err
+	}
+
+	byURI := make(map[string]string, len(files))
+	for _, file := range files {
+		absPath := filepath.Join(workspaceDir, file)
+		content, err := os.ReadFile(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		uri := fileURI(absPath)
+		byURI[uri] = file
+		if err := conn.notify("textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{
+				"uri":        uri,
+				"languageId": languageID(file),
+				"version":    1,
+				"text":       string(content),
+			},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	diagnostics := make(map[string][]lspDiagnostic, len(files))
+	var settle <-chan time.Time
+	for {
+		select {
+		case params, ok := <-conn.diagnostics:
+			if !ok {
+				return nil, fmt.Errorf("language server exited before reporting diagnostics")
+			}
+			file, tracked := byURI[normalizeFileURI(params.URI)]
+			if !tracked {
+				continue
+			}
+			diagnostics[file] = params.Diagnostics
+			if len(diagnostics) == len(files) {
+				settle = time.After(g.settle)
+			}
+		case <-settle:
+			g.shutdown(conn)
+			return diagnostics, nil
+		case <-ctx.Done():
+			return nil, fmt.Errorf("language server reported diagnostics for %d of %d files before timing out after %v", len(diagnostics), len(files), g.timeout)
+		}
+	}
+}
+
+// shutdown asks the server to exit cleanly, giving it a moment before the
+// process is killed.
+func (g *LSPDiagnosticsGate) shutdown(conn *lspConn) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := conn.call(ctx, "shutdown", nil); err == nil {
+		_ = conn.notify("exit", nil)
+	}
+}
+
+// lspDiagnostic is a diagnostic from textDocument/publishDiagnostics.
+type lspDiagnostic struct {
+	Range struct {
+		Start struct {
+			Line      int `json:"line"`
+			Character int `json:"character"`
+		} `json:"start"`
+	} `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// severity returns the diagnostic's severity; servers may omit it, in which
+// case it is treated as an error.
+func (d lspDiagnostic) severity() int {
+	if d.Severity == 0 {
+		return lspSeverities["error"]
+	}
+	return d.Severity
+}
+
+// format renders the diagnostic as file:line:col: severity: message (source).
+func (d lspDiagnostic) format(file string) string {
+	severity := "error"
+	for name, level := range lspSeverities {
+		if level == d.severity() {
+			severity = name
+		}
+	}
+	line := fmt.Sprintf("%s:%d:%d: %s: %s", file, d.Range.Start.Line+1, d.Range.Start.Character+1, severity, d.Message)
+	if d.Source != "" {
+		line += " (" + d.Source + ")"
+	}
+	return line
+}
+
+// lspPublishDiagnostics is the payload of a textDocument/publishDiagnostics notification.
+type lspPublishDiagnostics struct {
+	URI         string          `json:"uri"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+// lspMessage is a JSON-RPC 2.0 request, response or notification.
+type lspMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// lspConn is a minimal JSON-RPC client over a language server's stdio,
+// supporting just what the diagnostics gate needs.
+type lspConn struct {
+	w io.Writer
+	r *bufio.Reader
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  int
+	pending map[string]chan *lspMessage
+
+	diagnostics chan lspPublishDiagnostics
+	done        chan struct{} // Closed when the caller stops reading diagnostics
+}
+
+func newLSPConn(w io.Writer, r io.Reader) *lspConn {
+	return &lspConn{
+		w:           w,
+		r:           bufio.NewReader(r),
+		pending:     make(map[string]chan *lspMessage),
+		diagnostics: make(chan lspPublishDiagnostics, 64),
+		done:        make(chan struct{}),
+	}
+}
+
+// call sends a request and waits for its response.
+func (c *lspConn) call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	ch := make(chan *lspMessage, 1)
+	c.pending[strconv.Itoa(id)] = ch
+	c.mu.Unlock()
+
+	if err := c.send(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return nil, fmt.Errorf("language server exited")
+		}
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s (code %d)", resp.Error.Message, resp.Error.Code)
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// notify sends a notification.
+func (c *lspConn) notify(method string, params any) error {
+	return c.send(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// send writes one message with its Content-Length header.
+func (c *lspConn) send(msg any) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		return fmt.Errorf("failed to write to language server: %w", err)
+	}
+	return nil
+}
+
+// readLoop dispatches incoming messages until the server closes its output.
+func (c *lspConn) readLoop() {
+	defer func() {
+		c.mu.Lock()
+		for id, ch := range c.pending {
+			close(ch)
+			delete(c.pending, id)
+		}
+		c.mu.Unlock()
+		close(c.diagnostics)
+	}()
+
+	for {
+		msg, err := c.read()
+		if err != nil {
+			return
+		}
+
+		switch {
+		case msg.Method == "textDocument/publishDiagnostics":
+			var params lspPublishDiagnostics
+			if json.Unmarshal(msg.Params, &params) == nil {
+				select {
+				case c.diagnostics <- params:
+				case <-c.done:
+				}
+			}
+		case msg.Method != "" && len(msg.ID) > 0:
+			// A request from the server, e.g. workspace/configuration; answer
+			// with defaults so it does not wait on us
+			c.replyDefault(msg)
+		case msg.Method == "" && len(msg.ID) > 0:
+			c.mu.Lock()
+			ch, ok := c.pending[string(msg.ID)]
+			delete(c.pending, string(msg.ID))
+			c.mu.Unlock()
+			if ok {
+				ch <- msg
+			}
+		}
+	}
+}
+
+// replyDefault answers a server-to-client request with an empty result.
+func (c *lspConn) replyDefault(msg *lspMessage) {
+	var result any
+	if msg.Method == "workspace/configuration" {
+		var params struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		_ = json.Unmarshal(msg.Params, &params)
+		result = make([]any, len(params.Items))
+	}
+	_ = c.send(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": result})
+}
+
+// read reads one message.
+func (c *lspConn) read() (*lspMessage, error) {
+	length := -1
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %w", err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message without Content-Length")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return nil, err
+	}
+	var msg lspMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// fileURI returns the file:// URI for an absolute path.
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// normalizeFileURI re-encodes a URI from the server so it compares equal to
+// the one we sent, whatever escaping the server chose.
+func normalizeFileURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return fileURI(u.Path)
+}
+
+// languageID returns the LSP language identifier for a file.
+func languageID(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if id, ok := lspLanguageIDs[ext]; ok {
+		return id
+	}
+	return strings.TrimPrefix(ext, ".")
+}
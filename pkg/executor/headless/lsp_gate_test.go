@@ -0,0 +1,215 @@
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLSPHelperServer is not a real test: it runs a fake language server when
+// the test binary is started by an lsp gate. Files containing "ERROR" or
+// "WARN" get a diagnostic of that severity on the matching line.
+func TestLSPHelperServer(t *testing.T) {
+	if os.Getenv("FORGE_LSP_HELPER") != "1" {
+		t.Skip("helper process for LSP gate tests")
+	}
+
+	conn := newLSPConn(os.Stdout, os.Stdin)
+	for {
+		msg, err := conn.read()
+		if err != nil {
+			os.Exit(0)
+		}
+		switch msg.Method {
+		case "initialize":
+			_ = conn.send(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": map[string]any{"capabilities": map[string]any{}}})
+		case "shutdown":
+			_ = conn.send(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": nil})
+		case "exit":
+			os.Exit(0)
+		case "textDocument/didOpen":
+			var params struct {
+				TextDocument struct {
+					URI  string `json:"uri"`
+					Text string `json:"text"`
+				} `json:"textDocument"`
+			}
+			_ = json.Unmarshal(msg.Params, &params)
+
+			diagnostics := []map[string]any{}
+			for i, line := range strings.Split(params.TextDocument.Text, "\n") {
+				severity := 0
+				switch {
+				case strings.Contains(line, "ERROR"):
+					severity = 1
+				case strings.Contains(line, "WARN"):
+					severity = 2
+				default:
+					continue
+				}
+				diagnostics = append(diagnostics, map[string]any{
+					"range":    map[string]any{"start": map[string]any{"line": i, "character": 2}, "end": map[string]any{"line": i, "character": 4}},
+					"severity": severity,
+					"source":   "fake",
+					"message":  strings.TrimSpace(line),
+				})
+			}
+			_ = conn.send(map[string]any{
+				"jsonrpc": "2.0",
+				"method":  "textDocument/publishDiagnostics",
+				"params":  map[string]any{"uri": params.TextDocument.URI, "diagnostics": diagnostics},
+			})
+		}
+	}
+}
+
+func newFakeLSPGate(t *testing.T, severities []string, files ...string) *LSPDiagnosticsGate {
+	t.Helper()
+	t.Setenv("FORGE_LSP_HELPER", "1")
+	command := os.Args[0] + " -test.run=^TestLSPHelperServer$"
+	gate := NewLSPDiagnosticsGate("diagnostics", command, true, 30*time.Second, severities, []string{".go"}, func() []string { return files })
+	gate.settle = 50 * time.Millisecond
+	return gate
+}
+
+func writeWorkspaceFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLSPDiagnosticsGate_ReportsErrorsOnly(t *testing.T) {
+	dir := writeWorkspaceFiles(t, map[string]string{
+		"a.go":     "package a\n\nERROR undefined: foo\n",
+		"pkg/b.go": "package b\n\nWARN unused parameter\n",
+		"notes.md": "ERROR in prose is ignored\n",
+	})
+	gate := newFakeLSPGate(t, nil, "a.go", "pkg/b.go", "notes.md", "deleted.go")
+
+	err := gate.Execute(context.Background(), dir)
+	var gateErr *QualityGateError
+	if !errors.As(err, &gateErr) {
+		t.Fatalf("expected a QualityGateError, got %v", err)
+	}
+	if gateErr.Kind != GateFailureAssertion {
+		t.Errorf("expected an assertion failure, got %s", gateErr.Kind)
+	}
+	if gateErr.Output != "a.go:3:3: error: ERROR undefined: foo (fake)" {
+		t.Errorf("unexpected output %q", gateErr.Output)
+	}
+}
+
+func TestLSPDiagnosticsGate_ConfiguredSeverities(t *testing.T) {
+	dir := writeWorkspaceFiles(t, map[string]string{
+		"a.go": "package a\n\nWARN unused parameter\n",
+	})
+	gate := newFakeLSPGate(t, []string{"error", "warning"}, "a.go")
+
+	err := gate.Execute(context.Background(), dir)
+	if err == nil || !strings.Contains(err.Error(), "a.go:3:3: warning: WARN unused parameter") {
+		t.Errorf("expected the warning to fail the gate, got %v", err)
+	}
+}
+
+func TestLSPDiagnosticsGate_CleanFilesPass(t *testing.T) {
+	dir := writeWorkspaceFiles(t, map[string]string{
+		"a.go": "package a\n",
+		"b.go": "package a\n\nfunc b() {}\n",
+	})
+	gate := newFakeLSPGate(t, nil, "a.go", filepath.Join(dir, "b.go"))
+
+	if err := gate.Execute(context.Background(), dir); err != nil {
+		t.Errorf("expected gate to pass, got %v", err)
+	}
+}
+
+func TestLSPDiagnosticsGate_NoMatchingFilesSkipsServer(t *testing.T) {
+	dir := writeWorkspaceFiles(t, map[string]string{"README.md": "docs\n"})
+	gate := NewLSPDiagnosticsGate("diagnostics", "forge-no-such-language-server", true, time.Second, nil, []string{".go"}, func() []string {
+		return []string{"README.md"}
+	})
+
+	if err := gate.Execute(context.Background(), dir); err != nil {
+		t.Errorf("expected gate to pass without starting the server, got %v", err)
+	}
+}
+
+func TestLSPDiagnosticsGate_MissingServerIsInfrastructure(t *testing.T) {
+	dir := writeWorkspaceFiles(t, map[string]string{"a.go": "package a\n"})
+	gate := NewLSPDiagnosticsGate("diagnostics", "forge-no-such-language-server", true, time.Second, nil, []string{".go"}, func() []string {
+		return []string{"a.go"}
+	})
+
+	err := gate.Execute(context.Background(), dir)
+	if failureKind(err) != GateFailureInfrastructure {
+		t.Errorf("expected an infrastructure failure, got %v", err)
+	}
+}
+
+func TestQualityGateConfig_ValidateType(t *testing.T) {
+	tests := []struct {
+		name    string
+		gate    QualityGateConfig
+		wantErr string
+	}{
+		{name: "command", gate: QualityGateConfig{Name: "test", Command: "go test ./..."}},
+		{name: "lsp defaults", gate: QualityGateConfig{Name: "diagnostics", Type: QualityGateTypeLSP}},
+		{name: "lsp severities", gate: QualityGateConfig{Name: "diagnostics", Type: QualityGateTypeLSP, Severities: []string{"error", "Warning"}}},
+		{name: "secret scan", gate: QualityGateConfig{Name: "secrets", Type: QualityGateTypeSecretScan}},
+		{name: "missing command", gate: QualityGateConfig{Name: "test"}, wantErr: "command is required"},
+		{name: "secret scan command", gate: QualityGateConfig{Name: "secrets", Type: QualityGateTypeSecretScan, Command: "gitleaks"}, wantErr: "take no command"},
+		{name: "unknown type", gate: QualityGateConfig{Name: "x", Type: "http"}, wantErr: "invalid type"},
+		{name: "unknown severity", gate: QualityGateConfig{Name: "diagnostics", Type: QualityGateTypeLSP, Severities: []string{"fatal"}}, wantErr: "invalid severity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Task: "test", Mode: ModeWrite, WorkspaceDir: "/tmp/test", QualityGates: []QualityGateConfig{tt.gate}}
+			err := config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCreateQualityGates_LSPGate(t *testing.T) {
+	gates := CreateQualityGates([]QualityGateConfig{
+		{Name: "diagnostics", Type: QualityGateTypeLSP},
+		{Name: "test", Command: "go test ./..."},
+	}, nil, func() []string { return nil })
+
+	lsp, ok := gates[0].(*LSPDiagnosticsGate)
+	if !ok {
+		t.Fatalf("expected an LSP gate, got %T", gates[0])
+	}
+	if lsp.command != defaultLSPCommand || len(lsp.extensions) != 1 || lsp.extensions[0] != ".go" {
+		t.Errorf("expected gopls defaults, got command %q extensions %v", lsp.command, lsp.extensions)
+	}
+	if len(lsp.severities) != 1 || lsp.severities[0] != lspSeverities["error"] {
+		t.Errorf("expected errors only by default, got %v", lsp.severities)
+	}
+	if _, ok := gates[1].(*CommandQualityGate); !ok {
+		t.Errorf("expected a command gate, got %T", gates[1])
+	}
+}
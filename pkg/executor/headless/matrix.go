@@ -0,0 +1,572 @@
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MatrixConfig controls how the tasks of a task matrix are run.
+//
+// Example:
+//
+//	tasks:
+//	  - name: docs
+//	    task: "Update the README for the new CLI flags"
+//	    branch: forge/docs
+//	  - name: lint
+//	    task: "Fix all golangci-lint warnings in pkg/api"
+//	    constraints:
+//	      allowed_patterns: ["pkg/api/**"]
+//	matrix:
+//	  concurrency: 2
+type MatrixConfig struct {
+	Concurrency   int  `yaml:"concurrency" json:"concurrency"`         // Tasks run at once (default: 1, one after another)
+	StopOnFailure bool `yaml:"stop_on_failure" json:"stop_on_failure"` // Skip the tasks not yet started once one fails
+	KeepWorktrees bool `yaml:"keep_worktrees" json:"keep_worktrees"`   // Keep every worktree, not just those with uncommitted changes
+}
+
+// MatrixTask is one independent task of a task matrix. Constraints set here
+// override the top-level ones field by field.
+type MatrixTask struct {
+	Name          string           `yaml:"name" json:"name"`                     // Identifies the task in logs, artifacts and default branch names
+	Task          string           `yaml:"task" json:"task"`                     // Task description
+	Branch        string           `yaml:"branch" json:"branch"`                 // Branch to commit to (default: <git.branch>-<name>)
+	Constraints   ConstraintConfig `yaml:"constraints" json:"constraints"`       // Overrides for the top-level constraints
+	CommitMessage string           `yaml:"commit_message" json:"commit_message"` // Default: git.commit_message with the task name appended
+	PRTitle       string           `yaml:"pr_title" json:"pr_title"`             // Default: git.pr_title with the task name appended
+}
+
+// matrixTaskName matches task names, which are also used as directory names
+var matrixTaskName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validateMatrix checks the task matrix, which replaces the single task
+func (c *Config) validateMatrix() error {
+	if len(c.Tasks) == 0 {
+		return nil
+	}
+	if c.Task != "" {
+		return fmt.Errorf("task and tasks cannot both be set; give each task its own entry under tasks")
+	}
+	if c.FanOut.Enabled() {
+		return fmt.Errorf("tasks cannot be combined with fan_out")
+	}
+	if c.Matrix.Concurrency < 0 {
+		return fmt.Errorf("matrix concurrency cannot be negative")
+	}
+
+	names := make(map[string]bool, len(c.Tasks))
+	branches := make(map[string]string, len(c.Tasks))
+	for i, task := range c.Tasks {
+		if !matrixTaskName.MatchString(task.Name) {
+			return fmt.Errorf("invalid name for tasks[%d]: %q (must start with a letter or digit and contain only letters, digits, '.', '_' and '-')", i, task.Name)
+		}
+		if names[task.Name] {
+			return fmt.Errorf("duplicate task name: %s", task.Name)
+		}
+		names[task.Name] = true
+
+		if strings.TrimSpace(task.Task) == "" {
+			return fmt.Errorf("task %s: task description is required", task.Name)
+		}
+		if err := task.Constraints.validate(); err != nil {
+			return fmt.Errorf("task %s: %w", task.Name, err)
+		}
+
+		branch := c.matrixBranch(task)
+		if branch == "" {
+			if c.Git.AutoCommit {
+				return fmt.Errorf("task %s: auto_commit requires a branch; set branch or git.branch", task.Name)
+			}
+			continue
+		}
+		if other, ok := branches[branch]; ok {
+			return fmt.Errorf("tasks %s and %s use the same branch: %s", other, task.Name, branch)
+		}
+		branches[branch] = task.Name
+	}
+	return nil
+}
+
+// matrixBranch returns the branch a task commits to, or "" when it has none
+func (c *Config) matrixBranch(task MatrixTask) string {
+	if task.Branch != "" {
+		return task.Branch
+	}
+	if c.Git.Branch != "" {
+		return c.Git.Branch + "-" + task.Name
+	}
+	return ""
+}
+
+// mergeConstraints returns base with the fields set in override replaced
+func mergeConstraints(base, override ConstraintConfig) ConstraintConfig {
+	merged := base
+	if override.MaxFiles != 0 {
+		merged.MaxFiles = override.MaxFiles
+	}
+	if override.MaxLinesChanged != 0 {
+		merged.MaxLinesChanged = override.MaxLinesChanged
+	}
+	if override.AllowedPatterns != nil {
+		merged.AllowedPatterns = override.AllowedPatterns
+	}
+	if override.DeniedPatterns != nil {
+		merged.DeniedPatterns = override.DeniedPatterns
+	}
+	if override.AllowedTools != nil {
+		merged.AllowedTools = override.AllowedTools
+	}
+	if override.AllowedCommands != nil {
+		merged.AllowedCommands = override.AllowedCommands
+	}
+	if override.DeniedCommands != nil {
+		merged.DeniedCommands = override.DeniedCommands
+	}
+	if override.MaxTokens != 0 {
+		merged.MaxTokens = override.MaxTokens
+	}
+	if override.Timeout != 0 {
+		merged.Timeout = override.Timeout
+	}
+	if override.StallTimeout != 0 {
+		merged.StallTimeout = override.StallTimeout
+	}
+	if override.RetryOnStall {
+		merged.RetryOnStall = true
+	}
+	if override.MaxMessageTokens != 0 {
+		merged.MaxMessageTokens = override.MaxMessageTokens
+	}
+	if override.OversizedMessages != "" {
+		merged.OversizedMessages = override.OversizedMessages
+	}
+	return merged
+}
+
+// taskConfig derives the configuration for one task's sub-execution in the
+// worktree at workspaceDir. Pull requests target base unless pr_base is set,
+// and artifacts and notifications are left to the matrix executor.
+func taskConfig(parent *Config, task MatrixTask, workspaceDir, base string) *Config {
+	config := *parent
+	config.Task = task.Task
+	config.Tasks = nil
+	config.Matrix = MatrixConfig{}
+	config.Cancel.File = parent.cancelFile() // Written to the primary checkout
+	config.WorkspaceDir = workspaceDir
+	config.Constraints = mergeConstraints(parent.Constraints, task.Constraints)
+	config.Artifacts.Enabled = false
+	config.Notifications = nil
+
+	config.Git.Branch = parent.matrixBranch(task)
+	if config.Git.PRBase == "" {
+		config.Git.PRBase = base
+	}
+
+	switch {
+	case task.CommitMessage != "":
+		config.Git.CommitMessage = task.CommitMessage
+	case parent.Git.CommitMessage != "":
+		subject, rest, _ := strings.Cut(parent.Git.CommitMessage, "\n")
+		config.Git.CommitMessage = fmt.Sprintf("%s (%s)", subject, task.Name)
+		if rest != "" {
+			config.Git.CommitMessage += "\n" + rest
+		}
+	}
+
+	switch {
+	case task.PRTitle != "":
+		config.Git.PRTitle = task.PRTitle
+	case parent.Git.PRTitle != "":
+		config.Git.PRTitle = fmt.Sprintf("%s (%s)", parent.Git.PRTitle, task.Name)
+	}
+
+	return &config
+}
+
+// MatrixTaskResult is the outcome of one task of a task matrix
+type MatrixTaskResult struct {
+	Name     string            `json:"name"`
+	Task     string            `json:"task"`
+	Status   string            `json:"status"`
+	Error    string            `json:"error,omitempty"`
+	Branch   string            `json:"branch,omitempty"`
+	Commit   string            `json:"commit,omitempty"`
+	PRURL    string            `json:"pr_url,omitempty"`
+	Worktree string            `json:"worktree,omitempty"` // Set when the task's worktree was kept
+	Summary  *ExecutionSummary `json:"summary,omitempty"`
+}
+
+// MatrixSummary aggregates the tasks of a task matrix
+type MatrixSummary struct {
+	Status      string             `json:"status"`
+	Error       string             `json:"error,omitempty"`
+	StartTime   time.Time          `json:"start_time"`
+	EndTime     time.Time          `json:"end_time"`
+	Duration    time.Duration      `json:"duration"`
+	Concurrency int                `json:"concurrency"`
+	Tasks       []MatrixTaskResult `json:"tasks"`
+	Metrics     ExecutionMetrics   `json:"metrics"`
+}
+
+// count returns how many tasks finished with status
+func (s *MatrixSummary) count(status string) int {
+	n := 0
+	for _, result := range s.Tasks {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// MatrixExecutor runs the tasks of a task matrix, each in its own git
+// worktree created from the workspace's HEAD so concurrent tasks never see
+// each other's changes, and aggregates them into one report.
+type MatrixExecutor struct {
+	config     *Config
+	newAgent   AgentFactory
+	gitManager *GitManager
+	logger     *Logger
+
+	// worktreeMu serializes adding and removing worktrees, which race on
+	// the repository's worktree metadata when run concurrently
+	worktreeMu sync.Mutex
+
+	summary *MatrixSummary
+}
+
+// NewMatrixExecutor creates a matrix executor that builds an agent per task
+// with newAgent
+func NewMatrixExecutor(config *Config, newAgent AgentFactory) (*MatrixExecutor, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if len(config.Tasks) == 0 {
+		return nil, fmt.Errorf("tasks are not configured")
+	}
+
+	summary := &MatrixSummary{
+		Status:      "running",
+		Concurrency: max(config.Matrix.Concurrency, 1),
+		Tasks:       make([]MatrixTaskResult, len(config.Tasks)),
+	}
+	for i, task := range config.Tasks {
+		summary.Tasks[i] = MatrixTaskResult{Name: task.Name, Task: task.Task, Status: statusSkipped}
+	}
+
+	return &MatrixExecutor{
+		config:     config,
+		newAgent:   newAgent,
+		gitManager: NewGitManager(config.WorkspaceDir, config.Git, config.ConfigFilePath),
+		logger:     NewLoggerWithFormat(parseLogLevel(config.Logging.Verbosity), LogFormat(config.Logging.Format)),
+		summary:    summary,
+	}, nil
+}
+
+// Run runs every task, up to the configured concurrency at once, in the
+// order they are listed
+func (m *MatrixExecutor) Run(ctx context.Context) error {
+	m.summary.StartTime = time.Now()
+	m.logger.Infof("▶ Starting task matrix: %d task(s), %d at a time", len(m.config.Tasks), m.summary.Concurrency)
+
+	head, err := m.gitManager.HeadCommit(ctx)
+	if err != nil {
+		return m.fail(fmt.Errorf("task matrix requires a git repository with a commit: %w", err))
+	}
+	prefix, err := m.gitManager.RepoPrefix(ctx)
+	if err != nil {
+		return m.fail(err)
+	}
+	// Pull requests target the branch the matrix started from
+	base, err := m.gitManager.GetCurrentBranch(ctx)
+	if err != nil {
+		return m.fail(err)
+	}
+
+	worktreeRoot, err := os.MkdirTemp("", "forge-matrix-*")
+	if err != nil {
+		return m.fail(fmt.Errorf("failed to create worktree directory: %w", err))
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		stopped bool
+	)
+	slots := make(chan struct{}, m.summary.Concurrency)
+	for i, task := range m.config.Tasks {
+		// Wait for a free slot, so tasks start in order
+		slots <- struct{}{}
+		mu.Lock()
+		skip := stopped || ctx.Err() != nil
+		mu.Unlock()
+		if skip {
+			<-slots
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, task MatrixTask) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			m.logger.Infof("▶ Task %d/%d: %s", i+1, len(m.config.Tasks), task.Name)
+			result := m.runTask(ctx, task, filepath.Join(worktreeRoot, task.Name), prefix, head, base)
+			m.logger.Infof("■ Task %s: %s", task.Name, result.Status)
+
+			mu.Lock()
+			defer mu.Unlock()
+			m.summary.Tasks[i] = result
+			switch {
+			case stopped:
+			case result.Status == statusCanceled:
+				m.logger.Warningf("! Skipping the tasks not yet started after a cancel request")
+				stopped = true
+			case failedStatus(result.Status) && m.config.Matrix.StopOnFailure:
+				m.logger.Warningf("! Skipping the tasks not yet started after a failure")
+				stopped = true
+			}
+		}(i, task)
+	}
+	wg.Wait()
+
+	// Removed only when no worktree was kept in it
+	_ = os.Remove(worktreeRoot)
+
+	return m.finalize()
+}
+
+// runTask runs one task in a new worktree at worktree and records what it
+// left behind. The worktree is removed afterwards unless it holds
+// uncommitted changes or keep_worktrees is set.
+func (m *MatrixExecutor) runTask(ctx context.Context, task MatrixTask, worktree, prefix, head, base string) MatrixTaskResult {
+	result := MatrixTaskResult{Name: task.Name, Task: task.Task, Branch: m.config.matrixBranch(task)}
+
+	m.worktreeMu.Lock()
+	err := m.gitManager.AddWorktree(ctx, worktree, result.Branch, head)
+	m.worktreeMu.Unlock()
+	if err != nil {
+		result.Status = statusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	config := taskConfig(m.config, task, filepath.Join(worktree, filepath.FromSlash(prefix)), base)
+	summary, err := runSubExecution(ctx, m.newAgent, config, generatedPaths(m.config))
+	if summary != nil {
+		result.Summary = summary
+		result.Status = summary.Status
+		result.Error = summary.Error
+		result.PRURL = summary.PRURL
+	}
+	if err != nil {
+		result.Status = statusFailed
+		result.Error = err.Error()
+	}
+
+	// Clean up even when the run was cancelled
+	cleanupCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	worktreeGit := NewGitManager(config.WorkspaceDir, config.Git, "")
+	worktreeGit.ExcludeFromCommits(generatedPaths(m.config)...)
+	if taskHead, headErr := worktreeGit.HeadCommit(cleanupCtx); headErr == nil && taskHead != head {
+		result.Commit = taskHead
+	}
+
+	dirty, dirtyErr := worktreeGit.HasUncommittedChanges(cleanupCtx)
+	if m.config.Matrix.KeepWorktrees || dirty || dirtyErr != nil {
+		result.Worktree = worktree
+		if dirty {
+			m.logger.Warningf("! Kept the worktree of %s, which has uncommitted changes: %s", task.Name, worktree)
+		}
+		return result
+	}
+	m.worktreeMu.Lock()
+	removeErr := m.gitManager.RemoveWorktree(cleanupCtx, worktree)
+	m.worktreeMu.Unlock()
+	if removeErr != nil {
+		m.logger.Warningf("! Failed to remove the worktree of %s: %v", task.Name, removeErr)
+		result.Worktree = worktree
+	}
+	return result
+}
+
+// finalize sets the overall status and writes the matrix report
+func (m *MatrixExecutor) finalize() error {
+	m.summary.EndTime = time.Now()
+	m.summary.Duration = m.summary.EndTime.Sub(m.summary.StartTime)
+
+	for _, result := range m.summary.Tasks {
+		if result.Summary != nil {
+			m.summary.Metrics.add(result.Summary.Metrics)
+		}
+	}
+
+	succeeded := m.summary.count(statusSuccess)
+	switch {
+	case succeeded == len(m.summary.Tasks):
+		m.summary.Status = statusSuccess
+	case succeeded == 0 && m.summary.count(statusPartialSuccess) == 0:
+		m.summary.Status = statusFailed
+		m.summary.Error = "no task completed successfully"
+	default:
+		m.summary.Status = statusPartialSuccess
+		m.summary.Error = fmt.Sprintf("%d of %d tasks completed successfully", succeeded, len(m.summary.Tasks))
+	}
+
+	if m.config.Artifacts.Enabled {
+		if err := m.writeArtifacts(); err != nil {
+			m.logger.Warningf("! Failed to write artifacts: %v", err)
+		} else {
+			m.logger.Successf("Artifacts written to %s", m.config.Artifacts.OutputDir)
+		}
+		uploadArtifacts(m.config, m.summary.StartTime, m.logger)
+	}
+	sendNotifications(m.config, m.outcome(), m.logger)
+
+	m.logger.Infof("■ Task matrix completed: %s (%d/%d tasks succeeded, duration: %s)", m.summary.Status, succeeded, len(m.summary.Tasks), m.summary.Duration)
+
+	if m.summary.Status == statusFailed {
+		return fmt.Errorf("task matrix failed: %s", m.summary.Error)
+	}
+	return nil
+}
+
+// writeArtifacts writes matrix.json and matrix.md, and each task's own
+// artifacts under tasks/<name>
+func (m *MatrixExecutor) writeArtifacts() error {
+	outputDir := m.config.ArtifactDir()
+	if err := os.MkdirAll(outputDir, 0750); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if m.config.Artifacts.JSON {
+		data, err := json.MarshalIndent(m.summary, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal matrix summary: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, "matrix.json"), data, 0600); err != nil {
+			return fmt.Errorf("failed to write matrix JSON: %w", err)
+		}
+	}
+
+	if m.config.Artifacts.Markdown {
+		if err := os.WriteFile(filepath.Join(outputDir, "matrix.md"), []byte(m.markdownReport()), 0600); err != nil {
+			return fmt.Errorf("failed to write matrix markdown: %w", err)
+		}
+	}
+
+	for _, result := range m.summary.Tasks {
+		if result.Summary == nil {
+			continue
+		}
+		writer := NewArtifactWriter(filepath.Join(outputDir, "tasks", result.Name), m.config.Artifacts)
+		if err := writer.WriteAll(result.Summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownReport renders the matrix summary
+func (m *MatrixExecutor) markdownReport() string {
+	s := m.summary
+	var md strings.Builder
+
+	md.WriteString("# Forge Task Matrix Summary\n\n")
+	fmt.Fprintf(&md, "**Status:** %s\n\n", s.Status)
+	fmt.Fprintf(&md, "**Tasks:** %d succeeded, %d partial, %d failed, %d skipped\n\n",
+		s.count(statusSuccess), s.count(statusPartialSuccess), s.count(statusFailed)+s.count(statusStalled)+s.count(statusCanceled), s.count(statusSkipped))
+	if !s.EndTime.IsZero() {
+		fmt.Fprintf(&md, "**Duration:** %s (%d at a time)\n\n", s.Duration, s.Concurrency)
+	}
+
+	md.WriteString("## Tasks\n\n")
+	md.WriteString("| Task | Status | Branch | Files | Details |\n")
+	md.WriteString("|------|--------|--------|-------|---------|\n")
+	for _, result := range s.Tasks {
+		icon := statusIconFail
+		if result.Status == statusSuccess {
+			icon = statusIconPass
+		}
+		branch := "-"
+		if result.Branch != "" {
+			branch = "`" + result.Branch + "`"
+		}
+		files := 0
+		if result.Summary != nil {
+			files = result.Summary.Metrics.FilesModified
+		}
+
+		var details []string
+		if result.PRURL != "" {
+			details = append(details, result.PRURL)
+		} else if result.Commit != "" {
+			details = append(details, "commit "+shortHash(result.Commit))
+		}
+		if result.Worktree != "" {
+			details = append(details, "worktree kept at `"+result.Worktree+"`")
+		}
+		if result.Error != "" {
+			details = append(details, strings.ReplaceAll(firstLine(result.Error), "|", "\\|"))
+		}
+		fmt.Fprintf(&md, "| `%s` | %s %s | %s | %d | %s |\n", result.Name, icon, result.Status, branch, files, strings.Join(details, "; "))
+	}
+	md.WriteString("\n")
+
+	md.WriteString("## Metrics\n\n")
+	fmt.Fprintf(&md, "- **Files Modified:** %d\n", s.Metrics.FilesModified)
+	fmt.Fprintf(&md, "- **Total Lines Added:** %d\n", s.Metrics.TotalLinesAdded)
+	fmt.Fprintf(&md, "- **Total Lines Removed:** %d\n", s.Metrics.TotalLinesRemoved)
+	fmt.Fprintf(&md, "- **Tokens Used:** %d\n", s.Metrics.TokensUsed)
+	if s.Metrics.CostUSD > 0 {
+		fmt.Fprintf(&md, "- **Estimated Cost:** $%.2f\n", s.Metrics.CostUSD)
+	}
+
+	return md.String()
+}
+
+// fail marks the matrix as failed before any task ran
+func (m *MatrixExecutor) fail(err error) error {
+	m.summary.Status = statusFailed
+	m.summary.Error = err.Error()
+	m.summary.EndTime = time.Now()
+	m.summary.Duration = m.summary.EndTime.Sub(m.summary.StartTime)
+
+	if m.config.Artifacts.Enabled {
+		if artifactErr := m.writeArtifacts(); artifactErr != nil {
+			m.logger.Warningf("! Failed to write failure artifacts: %v", artifactErr)
+		}
+		uploadArtifacts(m.config, m.summary.StartTime, m.logger)
+	}
+	sendNotifications(m.config, m.outcome(), m.logger)
+	return err
+}
+
+// outcome describes the matrix for notifications, with a line per task
+func (m *MatrixExecutor) outcome() runOutcome {
+	outcome := runOutcome{
+		RunID:        m.config.RunID,
+		Task:         fmt.Sprintf("task matrix of %d tasks", len(m.summary.Tasks)),
+		Status:       m.summary.Status,
+		Error:        m.summary.Error,
+		Duration:     m.summary.Duration.Round(time.Second).String(),
+		FilesChanged: m.summary.Metrics.FilesModified,
+		LinesAdded:   m.summary.Metrics.TotalLinesAdded,
+		LinesRemoved: m.summary.Metrics.TotalLinesRemoved,
+		TokensUsed:   m.summary.Metrics.TokensUsed,
+		CostUSD:      m.summary.Metrics.CostUSD,
+	}
+	for _, result := range m.summary.Tasks {
+		outcome.Tasks = append(outcome.Tasks, taskOutcome{Name: result.Name, Status: result.Status, PRURL: result.PRURL})
+	}
+	return outcome
+}
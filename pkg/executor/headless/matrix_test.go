@@ -0,0 +1,201 @@
+package headless
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+)
+
+// matrixTestConfig returns a config for a matrix of the named tasks, whose
+// agents write <name>.txt and, unless the task is named broken, the ok
+// marker the quality gate checks for
+func matrixTestConfig(workspaceDir string, names ...string) *Config {
+	config := DefaultConfig()
+	config.WorkspaceDir = workspaceDir
+	config.Logging.Verbosity = "quiet"
+	config.Git.AutoCommit = true
+	config.Git.Branch = "forge/matrix"
+	config.Git.CommitMessage = "chore: matrix change"
+	config.QualityGates = []QualityGateConfig{{Name: "marker", Command: "test -f ok", Required: true}}
+	config.QualityGateMaxRetries = 1
+	for _, name := range names {
+		config.Tasks = append(config.Tasks, MatrixTask{Name: name, Task: "Write " + name + ".txt"})
+	}
+	return config
+}
+
+// matrixAgents returns a factory whose agents write into their own workspace
+func matrixAgents(t *testing.T) AgentFactory {
+	return func(config *Config) (agent.Agent, error) {
+		return newScriptedAgent(func() error {
+			name := strings.TrimSuffix(strings.TrimPrefix(config.Task, "Write "), ".txt")
+			files := []string{name + ".txt"}
+			if name != "broken" {
+				files = append(files, "ok")
+			}
+			for _, file := range files {
+				if err := os.WriteFile(filepath.Join(config.WorkspaceDir, file), []byte(name), 0o644); err != nil {
+					t.Error(err)
+				}
+			}
+			return nil
+		}), nil
+	}
+}
+
+func TestConfig_ValidateMatrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"top-level task", func(c *Config) { c.Task = "Do it" }, "task and tasks cannot both be set"},
+		{"fan-out", func(c *Config) { c.FanOut.By = FanOutByPackage }, "cannot be combined with fan_out"},
+		{"bad name", func(c *Config) { c.Tasks[0].Name = "../a" }, "invalid name for tasks[0]"},
+		{"duplicate name", func(c *Config) { c.Tasks[1].Name = "a" }, "duplicate task name: a"},
+		{"missing task", func(c *Config) { c.Tasks[0].Task = " " }, "task a: task description is required"},
+		{"bad constraints", func(c *Config) { c.Tasks[0].Constraints.MaxFiles = -1 }, "task a: max_files cannot be negative"},
+		{"shared branch", func(c *Config) { c.Tasks[1].Branch = "forge/matrix-a" }, "tasks a and b use the same branch"},
+		{"no branch", func(c *Config) { c.Git.Branch = "" }, "task a: auto_commit requires a branch"},
+		{"negative concurrency", func(c *Config) { c.Matrix.Concurrency = -1 }, "concurrency cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := matrixTestConfig("/tmp/test", "a", "b")
+			tt.modify(config)
+			err := config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTaskConfig(t *testing.T) {
+	parent := matrixTestConfig("/repo", "docs")
+	parent.Constraints.MaxFiles = 5
+	parent.Constraints.Timeout = time.Minute
+	parent.Git.PRTitle = "Matrix"
+	task := MatrixTask{
+		Name:        "docs",
+		Task:        "Update docs",
+		Constraints: ConstraintConfig{MaxFiles: 2, AllowedPatterns: []string{"docs/**"}},
+	}
+
+	config := taskConfig(parent, task, "/worktrees/docs", "main")
+	if config.Task != "Update docs" || config.WorkspaceDir != "/worktrees/docs" || len(config.Tasks) != 0 {
+		t.Errorf("unexpected task settings: %q in %s", config.Task, config.WorkspaceDir)
+	}
+	if config.Constraints.MaxFiles != 2 || config.Constraints.Timeout != time.Minute || config.Constraints.AllowedPatterns[0] != "docs/**" {
+		t.Errorf("expected task constraints over the top-level ones, got %+v", config.Constraints)
+	}
+	if config.Git.Branch != "forge/matrix-docs" || config.Git.PRBase != "main" {
+		t.Errorf("unexpected branch %s onto %s", config.Git.Branch, config.Git.PRBase)
+	}
+	if config.Git.CommitMessage != "chore: matrix change (docs)" || config.Git.PRTitle != "Matrix (docs)" {
+		t.Errorf("unexpected commit message %q and PR title %q", config.Git.CommitMessage, config.Git.PRTitle)
+	}
+	if config.Artifacts.Enabled {
+		t.Error("expected artifacts to be left to the matrix executor")
+	}
+}
+
+func TestMatrixExecutor_Run(t *testing.T) {
+	dir := setupFanOutRepo(t)
+	config := matrixTestConfig(dir, "docs", "lint", "broken")
+	config.Matrix.Concurrency = 2
+
+	executor, err := NewMatrixExecutor(config, matrixAgents(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.Run(context.Background()); err != nil {
+		t.Fatalf("expected partial success without an error, got %v", err)
+	}
+
+	summary := executor.summary
+	if summary.Status != statusPartialSuccess {
+		t.Errorf("expected partial_success, got %s (%s)", summary.Status, summary.Error)
+	}
+	for i, want := range []string{statusSuccess, statusSuccess, statusFailed} {
+		if summary.Tasks[i].Status != want {
+			t.Errorf("task %s: expected %s, got %s (%s)", summary.Tasks[i].Name, want, summary.Tasks[i].Status, summary.Tasks[i].Error)
+		}
+	}
+
+	// Each task committed only its own change, on its own branch
+	for _, name := range []string{"docs", "lint"} {
+		branch := "forge/matrix-" + name
+		if files := gitOutput(t, dir, "show", "--name-only", "--format=", branch); files != name+".txt\nok" {
+			t.Errorf("expected %s to hold only its own files, got %q", branch, files)
+		}
+		if subject := gitOutput(t, dir, "log", "-1", "--format=%s", branch); subject != "chore: matrix change ("+name+")" {
+			t.Errorf("unexpected commit subject %q on %s", subject, branch)
+		}
+	}
+	if branch := gitOutput(t, dir, "branch", "--show-current"); branch != "main" {
+		t.Errorf("expected the workspace to stay on main, got %s", branch)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs.txt")); !os.IsNotExist(err) {
+		t.Error("expected the workspace to be untouched by the tasks")
+	}
+
+	// Only the failed task's worktree, holding its uncommitted change, is kept
+	broken := summary.Tasks[2]
+	if broken.Worktree == "" || summary.Tasks[0].Worktree != "" {
+		t.Fatalf("expected only the broken worktree to be kept, got %+v", summary.Tasks)
+	}
+	t.Cleanup(func() { os.RemoveAll(filepath.Dir(broken.Worktree)) })
+	if _, err := os.Stat(filepath.Join(broken.Worktree, "broken.txt")); err != nil {
+		t.Errorf("expected the kept worktree to hold the change: %v", err)
+	}
+	if worktrees := gitOutput(t, dir, "worktree", "list"); strings.Count(worktrees, "\n") != 1 {
+		t.Errorf("expected the workspace and one kept worktree, got:\n%s", worktrees)
+	}
+
+	report, err := os.ReadFile(filepath.Join(dir, config.Artifacts.OutputDir, "matrix.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"2 succeeded, 0 partial, 1 failed", "| `docs` | ✅ success | `forge/matrix-docs` |", "worktree kept at"} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, config.Artifacts.OutputDir, "tasks", "docs", "execution.json")); err != nil {
+		t.Errorf("expected per-task artifacts: %v", err)
+	}
+}
+
+func TestMatrixExecutor_StopOnFailure(t *testing.T) {
+	dir := setupFanOutRepo(t)
+	config := matrixTestConfig(dir, "broken", "docs")
+	config.Matrix.StopOnFailure = true
+	config.Artifacts.Enabled = false
+
+	executor, err := NewMatrixExecutor(config, matrixAgents(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.Run(context.Background()); err == nil {
+		t.Fatal("expected an error when no task succeeds")
+	}
+	t.Cleanup(func() { os.RemoveAll(filepath.Dir(executor.summary.Tasks[0].Worktree)) })
+
+	if status := executor.summary.Tasks[1].Status; status != statusSkipped {
+		t.Errorf("expected the second task to be skipped, got %s", status)
+	}
+}
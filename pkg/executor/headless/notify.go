@@ -0,0 +1,396 @@
+package headless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// notifyTimeout bounds one notification request
+const notifyTimeout = 15 * time.Second
+
+// Where a notification is posted
+const (
+	NotificationSlack   = "slack"   // Slack incoming webhook
+	NotificationTeams   = "teams"   // Microsoft Teams incoming webhook
+	NotificationWebhook = "webhook" // Any URL, posted the run outcome as JSON
+)
+
+// Which runs a notification is sent for
+const (
+	NotifyAlways  = "always"  // Every run
+	NotifyFailure = "failure" // Runs that did not succeed, including partial successes
+	NotifySuccess = "success" // Runs that succeeded
+)
+
+// NotificationConfig posts the run's outcome to a chat webhook or any URL
+// once the run has finished and its artifacts are written. A failed post is
+// logged and never changes the run's outcome.
+//
+// Example:
+//
+//	notifications:
+//	  - type: slack
+//	    url_env: SLACK_WEBHOOK_URL
+//	    when: failure
+//	  - type: webhook
+//	    url: https://ci.example.com/forge-runs
+//	    token_env: CI_TOKEN
+//	    template: '{"run": "{{run_id}}", "ok": "{{status}}", "pr": "{{pr_url}}"}'
+type NotificationConfig struct {
+	Type     string `yaml:"type" json:"type"`           // slack, teams, or webhook
+	URL      string `yaml:"url" json:"url"`             // URL posted to
+	URLEnv   string `yaml:"url_env" json:"url_env"`     // Environment variable holding the URL instead, for webhook URLs that are secrets
+	TokenEnv string `yaml:"token_env" json:"token_env"` // Environment variable holding a bearer token sent with the post
+	When     string `yaml:"when" json:"when"`           // always, failure, or success (default: always)
+	Template string `yaml:"template" json:"template"`   // JSON payload with {{name}} placeholders (default: a message for slack and teams, the outcome for webhook)
+}
+
+// notificationPlaceholders are the names a notification template can use.
+// Values are escaped for use inside JSON strings.
+var notificationPlaceholders = []string{
+	"run_id", "task", "status", "error", "branch", "commit", "pr_url",
+	"duration", "files_changed", "lines_added", "lines_removed",
+	"gates", "tokens", "cost", "tasks", "message",
+}
+
+// validate checks a notification's settings.
+func (c NotificationConfig) validate() error {
+	switch c.Type {
+	case NotificationSlack, NotificationTeams, NotificationWebhook:
+	default:
+		return fmt.Errorf("invalid notification type: %s (must be '%s', '%s', or '%s')", c.Type, NotificationSlack, NotificationTeams, NotificationWebhook)
+	}
+	switch {
+	case c.URL == "" && c.URLEnv == "":
+		return fmt.Errorf("%s notification requires a url or url_env", c.Type)
+	case c.URL != "" && c.URLEnv != "":
+		return fmt.Errorf("%s notification cannot set both url and url_env", c.Type)
+	case c.URL != "":
+		if err := validateNotificationURL(c.URL); err != nil {
+			return err
+		}
+	}
+	switch c.When {
+	case "", NotifyAlways, NotifyFailure, NotifySuccess:
+	default:
+		return fmt.Errorf("invalid notification when: %s (must be '%s', '%s', or '%s')", c.When, NotifyAlways, NotifyFailure, NotifySuccess)
+	}
+	if c.Template == "" {
+		return nil
+	}
+
+	// Render with every placeholder filled to catch unknown names and
+	// templates that are not JSON
+	var unknown []string
+	sample := taskPlaceholder.ReplaceAllStringFunc(c.Template, func(placeholder string) string {
+		name := taskPlaceholder.FindStringSubmatch(placeholder)[1]
+		if !slices.Contains(notificationPlaceholders, name) {
+			unknown = append(unknown, name)
+		}
+		return "x"
+	})
+	if len(unknown) > 0 {
+		return fmt.Errorf("%s notification template has unknown placeholders: %s (available: %s)", c.Type, strings.Join(unknown, ", "), strings.Join(notificationPlaceholders, ", "))
+	}
+	if !json.Valid([]byte(sample)) {
+		return fmt.Errorf("%s notification template is not valid JSON; put placeholders inside quoted strings", c.Type)
+	}
+	return nil
+}
+
+// validateNotificationURL checks that u is an http or https URL.
+func validateNotificationURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid notification url: must be an http or https URL")
+	}
+	return nil
+}
+
+// wants reports whether the notification is sent for a run that ended with
+// status.
+func (c NotificationConfig) wants(status string) bool {
+	switch c.When {
+	case NotifyFailure:
+		return status != statusSuccess
+	case NotifySuccess:
+		return status == statusSuccess
+	default:
+		return true
+	}
+}
+
+// runOutcome is what a notification reports about a finished run. A
+// webhook notification without a template posts it as JSON.
+type runOutcome struct {
+	RunID        string        `json:"run_id,omitempty"`
+	Task         string        `json:"task"`
+	Status       string        `json:"status"`
+	Error        string        `json:"error,omitempty"`
+	Branch       string        `json:"branch,omitempty"`
+	Commit       string        `json:"commit,omitempty"`
+	PRURL        string        `json:"pr_url,omitempty"`
+	Duration     string        `json:"duration"`
+	FilesChanged int           `json:"files_changed"`
+	LinesAdded   int           `json:"lines_added"`
+	LinesRemoved int           `json:"lines_removed"`
+	Gates        []gateOutcome `json:"gates,omitempty"`
+	Tasks        []taskOutcome `json:"tasks,omitempty"` // Matrix tasks or fan-out packages
+	TokensUsed   int           `json:"tokens_used"`
+	CostUSD      float64       `json:"estimated_cost_usd,omitempty"` // Unset when the model's price is unknown
+}
+
+// gateOutcome is the result of one quality gate.
+type gateOutcome struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Required bool   `json:"required"`
+	Skipped  bool   `json:"skipped,omitempty"`
+}
+
+// taskOutcome is the result of one matrix task or fan-out package.
+type taskOutcome struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	PRURL  string `json:"pr_url,omitempty"`
+}
+
+// newRunOutcome describes a single run from its summary.
+func newRunOutcome(summary *ExecutionSummary) runOutcome {
+	outcome := runOutcome{
+		RunID:        summary.RunID,
+		Task:         summary.Task,
+		Status:       summary.Status,
+		Error:        summary.Error,
+		PRURL:        summary.PRURL,
+		Duration:     summary.Duration.Round(time.Second).String(),
+		FilesChanged: summary.Metrics.FilesModified,
+		LinesAdded:   summary.Metrics.TotalLinesAdded,
+		LinesRemoved: summary.Metrics.TotalLinesRemoved,
+		TokensUsed:   summary.Metrics.TokensUsed,
+		CostUSD:      summary.Metrics.CostUSD,
+	}
+	if summary.GitInfo != nil {
+		outcome.Branch = summary.GitInfo.Branch
+		outcome.Commit = summary.GitInfo.CommitHash
+	}
+	if summary.QualityGateResults != nil {
+		for _, result := range summary.QualityGateResults.Results {
+			outcome.Gates = append(outcome.Gates, gateOutcome{
+				Name:     result.Name,
+				Passed:   result.Passed,
+				Required: result.Required,
+				Skipped:  result.Skipped,
+			})
+		}
+	}
+	return outcome
+}
+
+// text describes the outcome in a few lines of markdown for chat messages.
+func (o runOutcome) text() (string, string) {
+	icon, verb := "❌", "failed"
+	switch o.Status {
+	case statusSuccess:
+		icon, verb = "✅", "succeeded"
+	case statusPartialSuccess:
+		icon, verb = "⚠️", "partially succeeded"
+	case statusStalled:
+		verb = "stalled"
+	case statusCanceled:
+		icon, verb = "⏹", "was canceled"
+	}
+	title := fmt.Sprintf("%s Forge run %s: %s", icon, verb, firstLine(o.Task))
+
+	var lines []string
+	if o.Error != "" {
+		lines = append(lines, "*Error:* "+firstLine(o.Error))
+	}
+	if o.Branch != "" {
+		lines = append(lines, "*Branch:* "+o.Branch)
+	}
+	if o.PRURL != "" {
+		lines = append(lines, "*Pull request:* "+o.PRURL)
+	}
+	if gates := o.gatesText(); gates != "" {
+		lines = append(lines, "*Gates:* "+gates)
+	}
+	for _, task := range o.Tasks {
+		line := fmt.Sprintf("• %s: %s", task.Name, task.Status)
+		if task.PRURL != "" {
+			line += " " + task.PRURL
+		}
+		lines = append(lines, line)
+	}
+	if o.FilesChanged > 0 {
+		lines = append(lines, fmt.Sprintf("*Changes:* %d files, +%d/-%d", o.FilesChanged, o.LinesAdded, o.LinesRemoved))
+	}
+	lines = append(lines, fmt.Sprintf("*Cost:* %s (%s tokens) in %s", o.costText(), formatNumber(o.TokensUsed), o.Duration))
+	if o.RunID != "" {
+		lines = append(lines, "*Run:* "+o.RunID)
+	}
+	return title, strings.Join(lines, "\n")
+}
+
+// gatesText lists the quality gates with a mark for each result.
+func (o runOutcome) gatesText() string {
+	gates := make([]string, len(o.Gates))
+	for i, gate := range o.Gates {
+		mark := "✓"
+		switch {
+		case gate.Skipped:
+			mark = "–"
+		case !gate.Passed:
+			mark = "✗"
+		}
+		gates[i] = gate.Name + " " + mark
+	}
+	return strings.Join(gates, ", ")
+}
+
+// costText formats the estimated cost.
+func (o runOutcome) costText() string {
+	if o.CostUSD == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("$%.2f", o.CostUSD)
+}
+
+// values returns the outcome's template placeholder values.
+func (o runOutcome) values() map[string]string {
+	title, body := o.text()
+	tasks := make([]string, len(o.Tasks))
+	for i, task := range o.Tasks {
+		tasks[i] = task.Name + ": " + task.Status
+	}
+	return map[string]string{
+		"run_id":        o.RunID,
+		"task":          o.Task,
+		"status":        o.Status,
+		"error":         o.Error,
+		"branch":        o.Branch,
+		"commit":        o.Commit,
+		"pr_url":        o.PRURL,
+		"duration":      o.Duration,
+		"files_changed": strconv.Itoa(o.FilesChanged),
+		"lines_added":   strconv.Itoa(o.LinesAdded),
+		"lines_removed": strconv.Itoa(o.LinesRemoved),
+		"gates":         o.gatesText(),
+		"tokens":        strconv.Itoa(o.TokensUsed),
+		"cost":          o.costText(),
+		"tasks":         strings.Join(tasks, "\n"),
+		"message":       title + "\n" + body,
+	}
+}
+
+// payload builds the body posted for the notification.
+func (c NotificationConfig) payload(outcome runOutcome) ([]byte, error) {
+	if c.Template != "" {
+		values := outcome.values()
+		body := taskPlaceholder.ReplaceAllStringFunc(c.Template, func(placeholder string) string {
+			return jsonStringContent(values[taskPlaceholder.FindStringSubmatch(placeholder)[1]])
+		})
+		return []byte(body), nil
+	}
+
+	title, body := outcome.text()
+	switch c.Type {
+	case NotificationSlack:
+		return json.Marshal(map[string]string{"text": title + "\n" + body})
+	case NotificationTeams:
+		color := "D13438"
+		if outcome.Status == statusSuccess {
+			color = "2EB67D"
+		}
+		return json.Marshal(map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    title,
+			"title":      title,
+			"themeColor": color,
+			// Teams markdown needs a blank line to break a line
+			"text": strings.ReplaceAll(strings.ReplaceAll(body, "*", "**"), "\n", "\n\n"),
+		})
+	default:
+		return json.Marshal(outcome)
+	}
+}
+
+// jsonStringContent escapes s for use between the quotes of a JSON string.
+func jsonStringContent(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data[1 : len(data)-1])
+}
+
+// sendNotifications posts outcome to every configured notification that
+// wants it. Failures are logged rather than failing the run, whose own
+// outcome is already decided.
+func sendNotifications(config *Config, outcome runOutcome, logger *Logger) {
+	for _, notification := range config.Notifications {
+		if !notification.wants(outcome.Status) {
+			continue
+		}
+		if err := notification.send(outcome); err != nil {
+			logger.Warningf("! Failed to send %s notification: %v", notification.Type, err)
+			continue
+		}
+		logger.Infof("✉ Sent %s notification", notification.Type)
+	}
+}
+
+// send posts the notification for outcome.
+func (c NotificationConfig) send(outcome runOutcome) error {
+	target := c.URL
+	if c.URLEnv != "" {
+		target = os.Getenv(c.URLEnv)
+		if target == "" {
+			return fmt.Errorf("%s is not set", c.URLEnv)
+		}
+		if err := validateNotificationURL(target); err != nil {
+			return fmt.Errorf("%s: %w", c.URLEnv, err)
+		}
+	}
+	body, err := c.payload(outcome)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.TokenEnv != "" {
+		if token := os.Getenv(c.TokenEnv); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The URL may be a secret, and url.Error repeats it
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", c.Type, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package headless
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// notificationServer records the bodies posted to it
+type notificationServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	bodies []string
+	auth   []string
+}
+
+func newNotificationServer(t *testing.T) *notificationServer {
+	t.Helper()
+	s := &notificationServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.bodies = append(s.bodies, string(body))
+		s.auth = append(s.auth, r.Header.Get("Authorization"))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *notificationServer) posted() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func failedOutcome() runOutcome {
+	return newRunOutcome(&ExecutionSummary{
+		RunID:    "run-7",
+		Task:     "Fix the flaky tests\nin the api package",
+		Status:   statusPartialSuccess,
+		Error:    "Quality gates failed: test",
+		Duration: 6*time.Minute + 12*time.Second + 300*time.Millisecond,
+		PRURL:    "https://github.com/acme/api/pull/42",
+		GitInfo:  &GitInfo{Branch: "forge/nightly", CommitHash: "abc123"},
+		QualityGateResults: &QualityGateResults{Results: []QualityGateResult{
+			{Name: "lint", Required: true, Passed: true},
+			{Name: "test", Required: true, Passed: false},
+		}},
+		Metrics: ExecutionMetrics{FilesModified: 3, TotalLinesAdded: 48, TotalLinesRemoved: 12, TokensUsed: 182340, CostUSD: 0.714},
+	})
+}
+
+func TestSendNotifications_Slack(t *testing.T) {
+	server := newNotificationServer(t)
+	config := &Config{Notifications: []NotificationConfig{{Type: NotificationSlack, URL: server.URL, When: NotifyFailure}}}
+
+	sendNotifications(config, failedOutcome(), NewLogger(LogLevelQuiet))
+
+	bodies := server.posted()
+	if len(bodies) != 1 {
+		t.Fatalf("expected one post, got %d", len(bodies))
+	}
+	var payload struct{ Text string }
+	if err := json.Unmarshal([]byte(bodies[0]), &payload); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Forge run partially succeeded: Fix the flaky tests",
+		"*Branch:* forge/nightly",
+		"*Pull request:* https://github.com/acme/api/pull/42",
+		"*Gates:* lint ✓, test ✗",
+		"*Cost:* $0.71 (182,340 tokens) in 6m12s",
+	} {
+		if !strings.Contains(payload.Text, want) {
+			t.Errorf("expected message to contain %q, got:\n%s", want, payload.Text)
+		}
+	}
+}
+
+func TestSendNotifications_When(t *testing.T) {
+	server := newNotificationServer(t)
+	config := &Config{Notifications: []NotificationConfig{
+		{Type: NotificationWebhook, URL: server.URL, When: NotifyFailure},
+		{Type: NotificationWebhook, URL: server.URL, When: NotifySuccess},
+		{Type: NotificationWebhook, URL: server.URL},
+	}}
+
+	sendNotifications(config, runOutcome{Status: statusSuccess}, NewLogger(LogLevelQuiet))
+	if got := len(server.posted()); got != 2 {
+		t.Errorf("expected the success and always notifications for a successful run, got %d posts", got)
+	}
+	sendNotifications(config, runOutcome{Status: statusStalled}, NewLogger(LogLevelQuiet))
+	if got := len(server.posted()); got != 4 {
+		t.Errorf("expected the failure and always notifications for a stalled run, got %d posts in all", got-2)
+	}
+}
+
+func TestSendNotifications_WebhookJSON(t *testing.T) {
+	server := newNotificationServer(t)
+	t.Setenv("FORGE_TEST_HOOK_URL", server.URL)
+	t.Setenv("FORGE_TEST_HOOK_TOKEN", "secret")
+	config := &Config{Notifications: []NotificationConfig{{
+		Type:     NotificationWebhook,
+		URLEnv:   "FORGE_TEST_HOOK_URL",
+		TokenEnv: "FORGE_TEST_HOOK_TOKEN",
+	}}}
+
+	sendNotifications(config, failedOutcome(), NewLogger(LogLevelQuiet))
+
+	bodies := server.posted()
+	if len(bodies) != 1 || server.auth[0] != "Bearer secret" {
+		t.Fatalf("expected one authorized post, got %d (auth %v)", len(bodies), server.auth)
+	}
+	var outcome runOutcome
+	if err := json.Unmarshal([]byte(bodies[0]), &outcome); err != nil {
+		t.Fatal(err)
+	}
+	if outcome.Status != statusPartialSuccess || outcome.Branch != "forge/nightly" || len(outcome.Gates) != 2 || outcome.CostUSD != 0.714 {
+		t.Errorf("unexpected outcome: %+v", outcome)
+	}
+}
+
+func TestNotificationConfig_Template(t *testing.T) {
+	notification := NotificationConfig{
+		Type:     NotificationSlack,
+		URL:      "https://hooks.example.com/x",
+		Template: `{"channel": "#nightly", "text": "{{ status }} {{pr_url}}", "task": "{{task}}"}`,
+	}
+	if err := notification.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := notification.payload(failedOutcome())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("expected the rendered template to be JSON: %v\n%s", err, body)
+	}
+	if payload["text"] != "partial_success https://github.com/acme/api/pull/42" || payload["task"] != "Fix the flaky tests\nin the api package" {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+}
+
+func TestNotificationConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification NotificationConfig
+		wantErr      string
+	}{
+		{"slack url", NotificationConfig{Type: NotificationSlack, URL: "https://hooks.slack.com/services/x"}, ""},
+		{"url from env", NotificationConfig{Type: NotificationTeams, URLEnv: "TEAMS_URL", When: NotifyFailure}, ""},
+		{"unknown type", NotificationConfig{Type: "email", URL: "https://example.com"}, "invalid notification type"},
+		{"no url", NotificationConfig{Type: NotificationWebhook}, "requires a url or url_env"},
+		{"both urls", NotificationConfig{Type: NotificationWebhook, URL: "https://example.com", URLEnv: "URL"}, "cannot set both"},
+		{"not http", NotificationConfig{Type: NotificationWebhook, URL: "ftp://example.com"}, "invalid notification url"},
+		{"unknown when", NotificationConfig{Type: NotificationSlack, URL: "https://example.com", When: "sometimes"}, "invalid notification when"},
+		{"unknown placeholder", NotificationConfig{Type: NotificationWebhook, URL: "https://example.com", Template: `{"x": "{{cost_usd}}"}`}, "unknown placeholders: cost_usd"},
+		{"not json", NotificationConfig{Type: NotificationWebhook, URL: "https://example.com", Template: `status={{status}}`}, "not valid JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.notification.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
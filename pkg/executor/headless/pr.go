@@ -13,7 +13,7 @@ const (
 	defaultBaseBranch = "main"
 )
 
-// createPullRequest creates a GitHub pull request for the committed changes
+// createPullRequest creates a pull request (a merge request on GitLab) for the committed changes
 func (e *Executor) createPullRequest(ctx context.Context) error {
 	// Get current branch (head)
 	head, err := e.gitManager.GetCurrentBranch(ctx)
@@ -21,55 +21,21 @@ func (e *Executor) createPullRequest(ctx context.Context) error {
 		return fmt.Errorf("failed to get current branch: %w", err)
 	}
 
-	// Detect or use configured base branch
-	base := e.config.Git.PRBase
-	if base == "" {
-		// Use sourceBranch if available (we switched from it)
-		if e.sourceBranch != "" {
-			base = e.sourceBranch
-			e.logger.Debugf("Using source branch as base: %s", base)
-		} else {
-			// No source branch and no configured base, use default
-			e.logger.Debugf("No source branch tracked, using default base: %s", defaultBaseBranch)
-			base = defaultBaseBranch
-		}
-	}
-
-	// Generate PR title and description if not provided
-	title := e.config.Git.PRTitle
-	body := e.config.Git.PRBody
-
-	if title == "" || body == "" {
-		prContent, genErr := e.generatePRContent(ctx, base, head)
-		if genErr != nil {
-			e.logger.Warningf("! Failed to generate PR content, using defaults: %v", genErr)
-			if title == "" {
-				title = fmt.Sprintf("chore: automated changes in %s", head)
-			}
-			if body == "" {
-				body = fmt.Sprintf("Automated changes generated by Forge.\n\nTask: %s", e.config.Task)
-			}
-		} else {
-			if title == "" {
-				title = prContent.Title
-			}
-			if body == "" {
-				body = prContent.Description
-			}
-		}
-	}
+	base := e.prBase()
+	title, body := e.prContent(ctx, base, head)
 
 	e.logger.Debugf("Creating PR: %s -> %s", head, base)
 	e.logger.Debugf("PR Title: %s", title)
 
-	// Push branch before creating PR
+	// Push branch and open the PR on the configured git host
 	e.logger.Infof("↑ Pushing to origin/%s...", head)
-	if pushErr := e.gitManager.Push(ctx); pushErr != nil {
-		return fmt.Errorf("failed to push branch: %w", pushErr)
-	}
-
-	// Create PR using existing git.CreatePR function
-	prURL, err := git.CreatePR(e.config.WorkspaceDir, title, body, base, head)
+	prURL, err := e.gitManager.CreatePullRequest(ctx, git.PullRequest{
+		Title: title,
+		Body:  body,
+		Base:  base,
+		Head:  head,
+		Draft: e.config.Git.PRDraft,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create pull request: %w", err)
 	}
@@ -82,6 +48,52 @@ func (e *Executor) createPullRequest(ctx context.Context) error {
 	return nil
 }
 
+// prBase returns the branch pull requests target: the configured base, the
+// branch the run started from, or the default base branch
+func (e *Executor) prBase() string {
+	if e.config.Git.PRBase != "" {
+		return e.config.Git.PRBase
+	}
+	// Use sourceBranch if available (we switched from it)
+	if e.sourceBranch != "" {
+		e.logger.Debugf("Using source branch as base: %s", e.sourceBranch)
+		return e.sourceBranch
+	}
+	// No source branch and no configured base, use default
+	e.logger.Debugf("No source branch tracked, using default base: %s", defaultBaseBranch)
+	return defaultBaseBranch
+}
+
+// prContent returns the configured PR title and body, generating whichever
+// is not configured from the commits between base and head
+func (e *Executor) prContent(ctx context.Context, base, head string) (string, string) {
+	title := e.config.Git.PRTitle
+	body := e.config.Git.PRBody
+	if title != "" && body != "" {
+		return title, body
+	}
+
+	prContent, genErr := e.generatePRContent(ctx, base, head)
+	if genErr != nil {
+		e.logger.Warningf("! Failed to generate PR content, using defaults: %v", genErr)
+		if title == "" {
+			title = fmt.Sprintf("chore: automated changes in %s", head)
+		}
+		if body == "" {
+			body = fmt.Sprintf("Automated changes generated by Forge.\n\nTask: %s", e.config.Task)
+		}
+		return title, body
+	}
+
+	if title == "" {
+		title = prContent.Title
+	}
+	if body == "" {
+		body = prContent.Description
+	}
+	return title, body
+}
+
 // generatePRContent generates PR title and description using LLM
 func (e *Executor) generatePRContent(ctx context.Context, base, head string) (*git.PRContent, error) {
 	if e.llmProvider == nil {
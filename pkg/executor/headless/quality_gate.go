@@ -1,11 +1,53 @@
 package headless
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+const (
+	// defaultGateBackoff is the delay before the first re-run of a gate with a retry policy
+	defaultGateBackoff = 2 * time.Second
+
+	// maxGateBackoff caps the doubling delay between re-runs of a gate
+	maxGateBackoff = 5 * time.Minute
+
+	// defaultFlakyRetries is the number of re-runs for a flaky gate that sets no max_retries
+	defaultFlakyRetries = 2
+
+	// gateOutputLimit is the most stdout or stderr kept in a gate result; the
+	// full streams are saved as artifacts
+	gateOutputLimit = 8 * 1024
+
+	// gateWaitDelay is how long a timed-out gate's output pipes may stay open
+	// after the command is killed, e.g. held by a server it started
+	gateWaitDelay = 5 * time.Second
+)
+
+// GateFailureKind distinguishes failures the agent can fix from failures of the gate itself.
+type GateFailureKind string
+
+const (
+	// GateFailureAssertion means the gate ran to completion and reported a failure
+	GateFailureAssertion GateFailureKind = "assertion"
+
+	// GateFailureInfrastructure means the gate could not run to completion: the command
+	// was not found, timed out, was killed, or the run was canceled
+	GateFailureInfrastructure GateFailureKind = "infrastructure"
 )
 
 // QualityGate represents a validation step to run before committing changes
@@ -20,12 +62,76 @@ type QualityGate interface {
 	Execute(ctx context.Context, workspaceDir string) error
 }
 
+// RetryPolicy controls how a gate is re-run before its failure is reported.
+// Infrastructure failures are always retried up to MaxRetries; assertion
+// failures are retried only when the gate is marked flaky.
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    time.Duration // Delay before the first re-run, doubled for each one after up to 5m
+	Flaky      bool
+}
+
+// shouldRetry reports whether a failure of the given kind may be re-run.
+func (p RetryPolicy) shouldRetry(kind GateFailureKind) bool {
+	return kind == GateFailureInfrastructure || p.Flaky
+}
+
+// delay returns the backoff before the given re-run (1-based), at most
+// maxGateBackoff.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = defaultGateBackoff
+	}
+	for i := 1; i < retry && backoff < maxGateBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxGateBackoff)
+}
+
+// RetryableQualityGate is an optional interface for gates that are re-run on failure.
+type RetryableQualityGate interface {
+	QualityGate
+	RetryPolicy() RetryPolicy
+}
+
+// GateSnapshot is the complete result of one gate run, recorded so runs can
+// be compared beyond pass/fail.
+type GateSnapshot struct {
+	Passed   bool   `json:"passed"`
+	ExitCode int    `json:"exit_code"`
+	Output   string `json:"output"` // stdout and stderr, interleaved
+
+	// The separate streams, for the gate's result and artifacts
+	Stdout string `json:"-"`
+	Stderr string `json:"-"`
+}
+
+// SnapshotQualityGate is an optional interface for gates that record the
+// full result of their most recent run.
+type SnapshotQualityGate interface {
+	QualityGate
+
+	// LastSnapshot returns the result of the most recent Execute, or nil if
+	// the gate has not run
+	LastSnapshot() *GateSnapshot
+}
+
 // CommandQualityGate executes a shell command as a quality gate
 type CommandQualityGate struct {
 	name     string
 	command  string
 	required bool
 	timeout  time.Duration
+	retry    RetryPolicy
+	last     *GateSnapshot
+
+	// Scoped gates run in each modified workspace path (see WithScope)
+	scope         []string
+	modifiedFiles func() []string
+
+	workDir string            // Relative to the workspace, or to each scoped path
+	env     map[string]string // Added to the executor's environment
 }
 
 // NewCommandQualityGate creates a new command-based quality gate
@@ -56,6 +162,48 @@ func (g *CommandQualityGate) Required() bool {
 	return g.required
 }
 
+// WithRetryPolicy sets how the gate is re-run on failure and returns the gate.
+func (g *CommandQualityGate) WithRetryPolicy(policy RetryPolicy) *CommandQualityGate {
+	if policy.Flaky && policy.MaxRetries == 0 {
+		policy.MaxRetries = defaultFlakyRetries
+	}
+	g.retry = policy
+	return g
+}
+
+// RetryPolicy returns the gate's retry policy
+func (g *CommandQualityGate) RetryPolicy() RetryPolicy {
+	return g.retry
+}
+
+// LastSnapshot returns the output and exit code of the most recent run
+func (g *CommandQualityGate) LastSnapshot() *GateSnapshot {
+	return g.last
+}
+
+// WithScope makes the gate run in each of the workspace-relative paths that
+// contain a modified file instead of at the workspace root, and returns the
+// gate. The gate passes without running when no scoped path was modified.
+func (g *CommandQualityGate) WithScope(paths []string, modifiedFiles func() []string) *CommandQualityGate {
+	g.scope = paths
+	g.modifiedFiles = modifiedFiles
+	return g
+}
+
+// WithWorkDir makes the gate run its command in dir, relative to the
+// workspace or to each scoped path, and returns the gate.
+func (g *CommandQualityGate) WithWorkDir(dir string) *CommandQualityGate {
+	g.workDir = dir
+	return g
+}
+
+// WithEnv adds env to the environment the gate's command runs with, and
+// returns the gate.
+func (g *CommandQualityGate) WithEnv(env map[string]string) *CommandQualityGate {
+	g.env = env
+	return g
+}
+
 // Execute runs the quality gate command
 func (g *CommandQualityGate) Execute(ctx context.Context, workspaceDir string) error {
 	// Check if parent context is already canceled before starting
@@ -65,40 +213,139 @@ func (g *CommandQualityGate) Execute(ctx context.Context, workspaceDir string) e
 	default:
 	}
 
-	// Create context with timeout
-	execCtx, cancel := context.WithTimeout(ctx, g.timeout)
-	defer cancel()
-
 	// Parse command into parts
 	parts := strings.Fields(g.command)
 	if len(parts) == 0 {
 		return fmt.Errorf("empty command")
 	}
 
+	if len(g.scope) == 0 {
+		return g.run(ctx, parts, workspaceDir, "")
+	}
+
+	dirs := affectedScopeDirs(g.scope, g.modifiedFiles)
+	if len(dirs) == 0 {
+		g.last = &GateSnapshot{Passed: true, Output: "no modified files in the workspace paths"}
+		return nil
+	}
+	for _, dir := range dirs {
+		if err := g.run(ctx, parts, filepath.Join(workspaceDir, dir), dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run executes the gate command in dir. label names the scoped path the
+// command runs in, if any, for failure reports.
+func (g *CommandQualityGate) run(ctx context.Context, parts []string, dir, label string) error {
+	// Create context with timeout
+	execCtx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
 	// Create command
 	cmd := exec.CommandContext(execCtx, parts[0], parts[1:]...)
-	cmd.Dir = workspaceDir
+	cmd.Dir = filepath.Join(dir, g.workDir)
+	cmd.WaitDelay = gateWaitDelay
+	if len(g.env) > 0 {
+		cmd.Env = os.Environ()
+		for _, name := range slices.Sorted(maps.Keys(g.env)) {
+			cmd.Env = append(cmd.Env, name+"="+g.env[name])
+		}
+	}
 
-	// Execute command and capture output
-	output, err := cmd.CombinedOutput()
+	// Execute command, capturing the streams both separately and interleaved
+	var combined, stdout, stderr bytes.Buffer
+	var mu sync.Mutex
+	cmd.Stdout = &lockedWriter{mu: &mu, w: io.MultiWriter(&stdout, &combined)}
+	cmd.Stderr = &lockedWriter{mu: &mu, w: io.MultiWriter(&stderr, &combined)}
+	err := cmd.Run()
+	output := combined.Bytes()
+	g.last = &GateSnapshot{Passed: err == nil, Output: string(output), Stdout: stdout.String(), Stderr: stderr.String()}
 	if err != nil {
+		g.last.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			g.last.ExitCode = exitErr.ExitCode()
+		}
+		if label != "" {
+			err = fmt.Errorf("in %s: %w", label, err)
+		}
 		return &QualityGateError{
 			GateName: g.name,
 			Command:  g.command,
 			Output:   string(output),
 			Err:      err,
+			Kind:     classifyCommandFailure(execCtx, err),
 		}
 	}
 
 	return nil
 }
 
+// lockedWriter serializes writes from the goroutines copying a command's
+// stdout and stderr into shared buffers.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// affectedScopeDirs returns the scoped paths that contain at least one of the
+// modified files, in scope order.
+func affectedScopeDirs(scope []string, modifiedFiles func() []string) []string {
+	if modifiedFiles == nil {
+		return nil
+	}
+	files := modifiedFiles()
+
+	var dirs []string
+	for _, dir := range scope {
+		dir = filepath.ToSlash(filepath.Clean(dir))
+		for _, file := range files {
+			file = filepath.ToSlash(filepath.Clean(file))
+			if file == dir || strings.HasPrefix(file, dir+"/") {
+				dirs = append(dirs, dir)
+				break
+			}
+		}
+	}
+	return dirs
+}
+
+// classifyCommandFailure decides whether a failed gate command reported a real
+// failure or never ran to completion.
+func classifyCommandFailure(execCtx context.Context, err error) GateFailureKind {
+	if execCtx.Err() != nil {
+		return GateFailureInfrastructure
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		// The command could not be started at all
+		return GateFailureInfrastructure
+	}
+
+	switch exitErr.ExitCode() {
+	case -1, 126, 127: // Killed by a signal, not executable, not found
+		return GateFailureInfrastructure
+	default:
+		return GateFailureAssertion
+	}
+}
+
 // QualityGateError represents a quality gate execution failure
 type QualityGateError struct {
 	GateName string
 	Command  string
 	Output   string
 	Err      error
+	Kind     GateFailureKind
 }
 
 func (e *QualityGateError) Error() string {
@@ -113,9 +360,32 @@ func (e *QualityGateError) Unwrap() error {
 	return e.Err
 }
 
+// GateSchedule controls when a gate runs relative to the others.
+type GateSchedule struct {
+	DependsOn []string // Gates that must pass first; the gate is skipped when one does not
+	RunIf     []string // Workspace-relative globs; the gate runs only when a modified file matches one
+	Group     string   // Gates in the same group run concurrently once their dependencies have passed
+}
+
+// GateSchedules returns the schedules set in configs, keyed by gate name.
+func GateSchedules(configs []QualityGateConfig) map[string]GateSchedule {
+	schedules := make(map[string]GateSchedule)
+	for _, config := range configs {
+		if len(config.DependsOn) > 0 || len(config.RunIf) > 0 || config.Group != "" {
+			schedules[config.Name] = GateSchedule{DependsOn: config.DependsOn, RunIf: config.RunIf, Group: config.Group}
+		}
+	}
+	return schedules
+}
+
 // QualityGateRunner manages execution of multiple quality gates
 type QualityGateRunner struct {
-	gates []QualityGate
+	gates         []QualityGate
+	schedules     map[string]GateSchedule
+	modifiedFiles func() []string
+
+	artifacts *ArtifactWriter // Saves the full output of each gate run, if set
+	attempt   int             // Number of RunAll calls, numbering the output artifacts
 }
 
 // NewQualityGateRunner creates a new quality gate runner
@@ -123,40 +393,79 @@ func NewQualityGateRunner(gates []QualityGate) *QualityGateRunner {
 	return &QualityGateRunner{gates: gates}
 }
 
-// RunAll executes all quality gates and returns results
+// WithSchedules sets the dependencies, conditions and groups of the gates,
+// keyed by gate name, and returns the runner. modifiedFiles reports the files
+// run_if conditions are matched against. Gates without a schedule run one at
+// a time in order, as they do without this option.
+func (r *QualityGateRunner) WithSchedules(schedules map[string]GateSchedule, modifiedFiles func() []string) *QualityGateRunner {
+	r.schedules = schedules
+	r.modifiedFiles = modifiedFiles
+	return r
+}
+
+// WithArtifacts makes the runner save the full stdout and stderr of every
+// gate that captures them to artifacts, and returns the runner.
+func (r *QualityGateRunner) WithArtifacts(writer *ArtifactWriter) *QualityGateRunner {
+	r.artifacts = writer
+	return r
+}
+
+// RunAll executes all quality gates and returns results in gate order. Each
+// step runs the first gate whose dependencies have finished together with the
+// other ready gates in its group.
 func (r *QualityGateRunner) RunAll(ctx context.Context, workspaceDir string, logger *Logger) *QualityGateResults {
+	r.attempt++
 	results := &QualityGateResults{
-		Results:   make([]QualityGateResult, 0, len(r.gates)),
+		Results:   make([]QualityGateResult, len(r.gates)),
 		AllPassed: true, // Start optimistic
 	}
 
-	for _, gate := range r.gates {
-		result := QualityGateResult{
-			Name:     gate.Name(),
-			Required: gate.Required(),
+	index := make(map[string]int, len(r.gates))
+	for i, gate := range r.gates {
+		if _, exists := index[gate.Name()]; !exists {
+			index[gate.Name()] = i
 		}
+	}
 
-		// Log that we're starting this gate
-		if logger != nil {
-			logger.Infof("  → Running quality gate: %s", gate.Name())
+	done := make([]bool, len(r.gates))
+	for {
+		batch := r.nextBatch(done, index)
+		if len(batch) == 0 {
+			break
 		}
-
-		// Execute gate
-		err := gate.Execute(ctx, workspaceDir)
-		if err != nil {
-			result.Passed = false
-			result.Error = err.Error()
-			if logger != nil {
-				logger.QualityGate(gate.Name(), false, err.Error())
-			}
-		} else {
-			result.Passed = true
-			if logger != nil {
-				logger.QualityGate(gate.Name(), true, "")
+		if len(batch) > 1 && logger != nil {
+			names := make([]string, len(batch))
+			for i, gateIndex := range batch {
+				names[i] = r.gates[gateIndex].Name()
 			}
+			logger.Infof("  → Running quality gates in parallel: %s", strings.Join(names, ", "))
 		}
 
-		results.Results = append(results.Results, result)
+		var wg sync.WaitGroup
+		for _, gateIndex := range batch {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results.Results[gateIndex] = r.runGate(ctx, gateIndex, index, results.Results, workspaceDir, logger)
+			}()
+		}
+		wg.Wait()
+		for _, gateIndex := range batch {
+			done[gateIndex] = true
+		}
+	}
+
+	// Config validation rejects cycles; report any left rather than dropping them
+	for i, gate := range r.gates {
+		if !done[i] {
+			results.Results[i] = QualityGateResult{
+				Name:        gate.Name(),
+				Required:    gate.Required(),
+				Skipped:     true,
+				Error:       "not run: its dependencies form a cycle",
+				FailureKind: GateFailureInfrastructure,
+			}
+		}
 	}
 
 	// Calculate overall pass/fail based on required gates
@@ -171,6 +480,218 @@ func (r *QualityGateRunner) RunAll(ctx context.Context, workspaceDir string, log
 	return results
 }
 
+// nextBatch returns the indexes of the gates to run next: the first gate not
+// yet run whose dependencies have all finished, and the other such gates in
+// its group.
+func (r *QualityGateRunner) nextBatch(done []bool, index map[string]int) []int {
+	ready := func(i int) bool {
+		if done[i] {
+			return false
+		}
+		for _, dep := range r.schedules[r.gates[i].Name()].DependsOn {
+			if j, ok := index[dep]; ok && !done[j] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for i, gate := range r.gates {
+		if !ready(i) {
+			continue
+		}
+		batch := []int{i}
+		if group := r.schedules[gate.Name()].Group; group != "" {
+			for j := i + 1; j < len(r.gates); j++ {
+				if ready(j) && r.schedules[r.gates[j].Name()].Group == group {
+					batch = append(batch, j)
+				}
+			}
+		}
+		return batch
+	}
+	return nil
+}
+
+// runGate runs the gate at gateIndex unless a dependency did not pass or its
+// run_if condition matches no modified file. results holds the results of the
+// gates that have finished.
+func (r *QualityGateRunner) runGate(ctx context.Context, gateIndex int, index map[string]int, results []QualityGateResult, workspaceDir string, logger *Logger) QualityGateResult {
+	gate := r.gates[gateIndex]
+	schedule := r.schedules[gate.Name()]
+	result := QualityGateResult{
+		Name:     gate.Name(),
+		Required: gate.Required(),
+	}
+
+	for _, dep := range schedule.DependsOn {
+		if j, ok := index[dep]; ok && !results[j].Passed {
+			result.Skipped = true
+			result.Error = fmt.Sprintf("not run: depends on '%s', which did not pass", dep)
+			result.FailureKind = results[j].FailureKind
+			if logger != nil {
+				logger.QualityGate(gate.Name(), false, result.Error)
+			}
+			return result
+		}
+	}
+
+	if len(schedule.RunIf) > 0 && !anyFileMatches(schedule.RunIf, r.modifiedFiles) {
+		result.Passed = true
+		result.Skipped = true
+		if logger != nil {
+			logger.Infof("  ↷ Skipping quality gate %s: no modified file matches run_if", gate.Name())
+		}
+		return result
+	}
+
+	// Log that we're starting this gate
+	if logger != nil {
+		logger.Infof("  → Running quality gate: %s", gate.Name())
+	}
+
+	// Execute gate, re-running it as its retry policy allows
+	err := runWithRetries(ctx, gate, workspaceDir, logger, &result)
+	if findingsGate, ok := gate.(FindingsQualityGate); ok {
+		result.Findings = findingsGate.LastFindings()
+	}
+	r.captureOutput(gateIndex, gate, &result, logger)
+	if err != nil {
+		result.Passed = false
+		result.Error = err.Error()
+		result.FailureKind = failureKind(err)
+		if logger != nil {
+			logger.QualityGate(gate.Name(), false, err.Error())
+		}
+	} else {
+		result.Passed = true
+		if logger != nil {
+			logger.QualityGate(gate.Name(), true, "")
+		}
+	}
+	return result
+}
+
+// captureOutput records the stdout and stderr of the gate's last run on
+// result, truncated, and saves them in full as artifacts when the runner
+// has a writer.
+func (r *QualityGateRunner) captureOutput(gateIndex int, gate QualityGate, result *QualityGateResult, logger *Logger) {
+	snapshotGate, ok := gate.(SnapshotQualityGate)
+	if !ok {
+		return
+	}
+	snapshot := snapshotGate.LastSnapshot()
+	if snapshot == nil {
+		return
+	}
+	result.Stdout = truncateOutput(snapshot.Stdout, gateOutputLimit)
+	result.Stderr = truncateOutput(snapshot.Stderr, gateOutputLimit)
+
+	if r.artifacts == nil {
+		return
+	}
+	for _, stream := range []struct {
+		name    string
+		content string
+		path    *string
+	}{
+		{"stdout", snapshot.Stdout, &result.StdoutArtifact},
+		{"stderr", snapshot.Stderr, &result.StderrArtifact},
+	} {
+		if stream.content == "" {
+			continue
+		}
+		path, err := r.artifacts.WriteGateOutput(r.attempt, gateIndex, gate.Name(), stream.name, stream.content)
+		if err != nil {
+			if logger != nil {
+				logger.Warningf("! %v", err)
+			}
+			continue
+		}
+		*stream.path = path
+	}
+}
+
+// truncateOutput keeps the last n bytes of output, where failures are
+// usually reported, noting how much was cut.
+func truncateOutput(output string, n int) string {
+	if len(output) <= n {
+		return output
+	}
+	cut := len(output) - n
+	// Start at a line boundary when there is one close by
+	if i := strings.IndexByte(output[cut:], '\n'); i >= 0 && i < n/4 {
+		cut += i + 1
+	}
+	for cut < len(output) && !utf8.RuneStart(output[cut]) {
+		cut++
+	}
+	return fmt.Sprintf("[%d bytes truncated]\n%s", cut, output[cut:])
+}
+
+// anyFileMatches reports whether one of the modified files matches one of
+// the workspace-relative globs.
+func anyFileMatches(patterns []string, modifiedFiles func() []string) bool {
+	if modifiedFiles == nil {
+		return false
+	}
+	for _, file := range modifiedFiles() {
+		file = filepath.ToSlash(filepath.Clean(file))
+		for _, pattern := range patterns {
+			if workspace.MatchPathPattern(pattern, file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runWithRetries executes gate until it passes or its retry policy is
+// exhausted, recording the number of runs on result.
+func runWithRetries(ctx context.Context, gate QualityGate, workspaceDir string, logger *Logger, result *QualityGateResult) error {
+	var policy RetryPolicy
+	if retryable, ok := gate.(RetryableQualityGate); ok {
+		policy = retryable.RetryPolicy()
+	}
+
+	for run := 1; ; run++ {
+		result.Runs = run
+		err := gate.Execute(ctx, workspaceDir)
+		if err == nil {
+			result.Flaky = run > 1
+			return nil
+		}
+
+		kind := failureKind(err)
+		if run > policy.MaxRetries || !policy.shouldRetry(kind) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := policy.delay(run)
+		if logger != nil {
+			logger.Warningf("  ↻ %s failed (%s), re-running in %v (retry %d/%d)", gate.Name(), kind, delay, run, policy.MaxRetries)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// failureKind returns the failure kind of a gate error. Errors that did not
+// come from a gate's own command are treated as infrastructure failures.
+func failureKind(err error) GateFailureKind {
+	var gateErr *QualityGateError
+	if errors.As(err, &gateErr) && gateErr.Kind != "" {
+		return gateErr.Kind
+	}
+	return GateFailureInfrastructure
+}
+
 // QualityGateResults contains results from running quality gates
 type QualityGateResults struct {
 	AllPassed bool
@@ -187,10 +708,22 @@ type QualityGateAttempt struct {
 
 // QualityGateResult represents the result of a single quality gate
 type QualityGateResult struct {
-	Name     string
-	Required bool
-	Passed   bool
-	Error    string
+	Name        string
+	Required    bool
+	Passed      bool
+	Error       string
+	FailureKind GateFailureKind `json:",omitempty"` // Set when the gate failed
+	Runs        int             // Number of times the gate was executed, including retries
+	Flaky       bool            // Passed only after failing at least once
+	Skipped     bool            `json:",omitempty"` // Not run: run_if matched no modified file (passed), or a dependency did not pass (failed)
+	Findings    []SecretFinding `json:",omitempty"` // Masked secrets found by a secret_scan gate
+
+	// Output of the last run of a command gate, keeping the last 8 KiB of
+	// each stream; the artifacts, relative to the output directory, hold it in full
+	Stdout         string `json:",omitempty"`
+	Stderr         string `json:",omitempty"`
+	StdoutArtifact string `json:",omitempty"`
+	StderrArtifact string `json:",omitempty"`
 }
 
 // GetFailedGates returns a list of failed required gates
@@ -204,6 +737,32 @@ func (r *QualityGateResults) GetFailedGates() []QualityGateResult {
 	return failed
 }
 
+// HasSecretFindings reports whether a gate found credentials in the changes,
+// which must never be committed, even with commit_on_quality_fail.
+func (r *QualityGateResults) HasSecretFindings() bool {
+	for _, result := range r.Results {
+		if len(result.Findings) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// HasOnlyInfrastructureFailures reports whether every failed required gate
+// failed for infrastructure reasons, meaning there is nothing for the agent to fix.
+func (r *QualityGateResults) HasOnlyInfrastructureFailures() bool {
+	failed := r.GetFailedGates()
+	if len(failed) == 0 {
+		return false
+	}
+	for _, result := range failed {
+		if result.FailureKind != GateFailureInfrastructure {
+			return false
+		}
+	}
+	return true
+}
+
 // FormatErrorMessage creates a formatted error message for failed gates
 func (r *QualityGateResults) FormatErrorMessage() string {
 	failed := r.GetFailedGates()
@@ -214,7 +773,11 @@ func (r *QualityGateResults) FormatErrorMessage() string {
 	var msg strings.Builder
 	msg.WriteString("Quality gate failures:\n\n")
 	for _, result := range failed {
-		fmt.Fprintf(&msg, "❌ %s\n", result.Name)
+		fmt.Fprintf(&msg, "❌ %s", result.Name)
+		if result.FailureKind == GateFailureInfrastructure {
+			msg.WriteString(" (infrastructure error)")
+		}
+		msg.WriteString("\n")
 		if result.Error != "" {
 			fmt.Fprintf(&msg, "   Error: %s\n\n", result.Error)
 		}
@@ -234,7 +797,12 @@ func (r *QualityGateResults) FormatFeedbackMessage(retryCount, maxRetries int) s
 	fmt.Fprintf(&msg, "Your previous task completion failed quality gates (attempt %d/%d).\n\n", retryCount, maxRetries)
 	msg.WriteString("Please review and fix the following issues:\n\n")
 
+	var infra []string
 	for _, result := range failed {
+		if result.FailureKind == GateFailureInfrastructure {
+			infra = append(infra, result.Name)
+			continue
+		}
 		fmt.Fprintf(&msg, "❌ %s\n", result.Name)
 		if result.Error != "" {
 			// Extract useful error information from QualityGateError
@@ -242,20 +810,49 @@ func (r *QualityGateResults) FormatFeedbackMessage(retryCount, maxRetries int) s
 		}
 	}
 
+	if len(infra) > 0 {
+		fmt.Fprintf(&msg, "These gates could not run because of infrastructure errors unrelated to your changes; do not try to fix them: %s\n",
+			strings.Join(infra, ", "))
+	}
+
 	msg.WriteString("\nAfter fixing these issues, use task_completion again to revalidate your changes.")
 
 	return msg.String()
 }
 
-// CreateQualityGates creates quality gates from configuration
-func CreateQualityGates(configs []QualityGateConfig) []QualityGate {
+// CreateQualityGates creates quality gates from configuration. modifiedFiles
+// reports the files the agent has modified, for gates that check only those.
+// With scope set, command gates run in each scoped path with modified files.
+func CreateQualityGates(configs []QualityGateConfig, scope []string, modifiedFiles func() []string) []QualityGate {
 	gates := make([]QualityGate, 0, len(configs))
 	for _, config := range configs {
 		timeout := config.Timeout
 		if timeout <= 0 {
 			timeout = 3 * time.Minute // Default timeout if not specified
 		}
-		gate := NewCommandQualityGateWithTimeout(config.Name, config.Command, config.Required, timeout)
+		policy := RetryPolicy{
+			MaxRetries: config.MaxRetries,
+			Backoff:    config.Backoff,
+			Flaky:      config.Flaky,
+		}
+
+		var gate QualityGate
+		switch config.Type {
+		case QualityGateTypeLSP:
+			gate = NewLSPDiagnosticsGate(config.Name, config.Command, config.Required, timeout, config.Severities, config.Extensions, modifiedFiles).
+				WithRetryPolicy(policy)
+		case QualityGateTypeSecretScan:
+			gate = NewSecretScanGate(config.Name, timeout).WithRetryPolicy(policy)
+		default:
+			commandGate := NewCommandQualityGateWithTimeout(config.Name, config.Command, config.Required, timeout).
+				WithRetryPolicy(policy).
+				WithWorkDir(config.WorkDir).
+				WithEnv(config.Env)
+			if len(scope) > 0 {
+				commandGate.WithScope(scope, modifiedFiles)
+			}
+			gate = commandGate
+		}
 		gates = append(gates, gate)
 	}
 	return gates
@@ -2,9 +2,13 @@ package headless
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestQualityGateRunner_RunAll(t *testing.T) {
@@ -175,3 +179,471 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+// scriptedGate fails with the given errors in order, then passes.
+type scriptedGate struct {
+	failures []error
+	policy   RetryPolicy
+	runs     int
+}
+
+func (g *scriptedGate) Name() string             { return "scripted" }
+func (g *scriptedGate) Required() bool           { return true }
+func (g *scriptedGate) RetryPolicy() RetryPolicy { return g.policy }
+func (g *scriptedGate) Execute(ctx context.Context, workspaceDir string) error {
+	g.runs++
+	if g.runs <= len(g.failures) {
+		return g.failures[g.runs-1]
+	}
+	return nil
+}
+
+func gateErr(kind GateFailureKind) error {
+	return &QualityGateError{GateName: "scripted", Err: errors.New(string(kind)), Kind: kind}
+}
+
+func TestCommandQualityGate_FailureKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		gate    *CommandQualityGate
+		want    GateFailureKind
+		wantErr bool
+	}{
+		{
+			name: "non-zero exit is an assertion failure",
+			gate: NewCommandQualityGate("test", "false", true),
+			want: GateFailureAssertion,
+		},
+		{
+			name: "missing command is an infrastructure failure",
+			gate: NewCommandQualityGate("test", "forge-no-such-command", true),
+			want: GateFailureInfrastructure,
+		},
+		{
+			name: "timeout is an infrastructure failure",
+			gate: NewCommandQualityGateWithTimeout("test", "sleep 5", true, 50*time.Millisecond),
+			want: GateFailureInfrastructure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.gate.Execute(context.Background(), t.TempDir())
+			if err == nil {
+				t.Fatal("expected gate to fail")
+			}
+			if got := failureKind(err); got != tt.want {
+				t.Errorf("failure kind = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQualityGateRunner_Retries(t *testing.T) {
+	fast := time.Millisecond
+
+	tests := []struct {
+		name       string
+		gate       *scriptedGate
+		wantPassed bool
+		wantRuns   int
+		wantFlaky  bool
+		wantKind   GateFailureKind
+	}{
+		{
+			name:       "assertion failure is not retried without flaky",
+			gate:       &scriptedGate{failures: []error{gateErr(GateFailureAssertion)}, policy: RetryPolicy{MaxRetries: 2, Backoff: fast}},
+			wantPassed: false,
+			wantRuns:   1,
+			wantKind:   GateFailureAssertion,
+		},
+		{
+			name:       "flaky gate passes on retry",
+			gate:       &scriptedGate{failures: []error{gateErr(GateFailureAssertion)}, policy: RetryPolicy{MaxRetries: 2, Backoff: fast, Flaky: true}},
+			wantPassed: true,
+			wantRuns:   2,
+			wantFlaky:  true,
+		},
+		{
+			name:       "infrastructure failure is retried",
+			gate:       &scriptedGate{failures: []error{gateErr(GateFailureInfrastructure)}, policy: RetryPolicy{MaxRetries: 1, Backoff: fast}},
+			wantPassed: true,
+			wantRuns:   2,
+			wantFlaky:  true,
+		},
+		{
+			name: "retries are exhausted",
+			gate: &scriptedGate{
+				failures: []error{gateErr(GateFailureInfrastructure), gateErr(GateFailureInfrastructure), gateErr(GateFailureInfrastructure)},
+				policy:   RetryPolicy{MaxRetries: 2, Backoff: fast},
+			},
+			wantPassed: false,
+			wantRuns:   3,
+			wantKind:   GateFailureInfrastructure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := NewQualityGateRunner([]QualityGate{tt.gate}).RunAll(context.Background(), t.TempDir(), nil)
+			result := results.Results[0]
+
+			if result.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v", result.Passed, tt.wantPassed)
+			}
+			if result.Runs != tt.wantRuns {
+				t.Errorf("Runs = %d, want %d", result.Runs, tt.wantRuns)
+			}
+			if result.Flaky != tt.wantFlaky {
+				t.Errorf("Flaky = %v, want %v", result.Flaky, tt.wantFlaky)
+			}
+			if result.FailureKind != tt.wantKind {
+				t.Errorf("FailureKind = %q, want %q", result.FailureKind, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestQualityGateResults_InfrastructureFailures(t *testing.T) {
+	results := &QualityGateResults{
+		Results: []QualityGateResult{
+			{Name: "integration", Required: true, Error: "timed out", FailureKind: GateFailureInfrastructure},
+		},
+	}
+	if !results.HasOnlyInfrastructureFailures() {
+		t.Error("expected only infrastructure failures")
+	}
+
+	results.Results = append(results.Results, QualityGateResult{
+		Name: "lint", Required: true, Error: "unused variable", FailureKind: GateFailureAssertion,
+	})
+	if results.HasOnlyInfrastructureFailures() {
+		t.Error("expected an assertion failure to be reported")
+	}
+
+	msg := results.FormatFeedbackMessage(1, 3)
+	if !strings.Contains(msg, "❌ lint") {
+		t.Error("expected the assertion failure in the feedback")
+	}
+	if strings.Contains(msg, "❌ integration") || !strings.Contains(msg, "do not try to fix them: integration") {
+		t.Errorf("expected the infrastructure failure to be called out separately, got:\n%s", msg)
+	}
+}
+
+func TestCreateQualityGates_RetryPolicy(t *testing.T) {
+	gates := CreateQualityGates([]QualityGateConfig{
+		{Name: "integration", Command: "make integration", Flaky: true, Backoff: time.Second},
+		{Name: "lint", Command: "make lint", MaxRetries: 1},
+	}, nil, nil)
+
+	flaky := gates[0].(RetryableQualityGate).RetryPolicy()
+	if !flaky.Flaky || flaky.MaxRetries != defaultFlakyRetries || flaky.Backoff != time.Second {
+		t.Errorf("unexpected flaky policy: %+v", flaky)
+	}
+
+	lint := gates[1].(RetryableQualityGate).RetryPolicy()
+	if lint.Flaky || lint.MaxRetries != 1 {
+		t.Errorf("unexpected lint policy: %+v", lint)
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second}
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{20, maxGateBackoff},
+		{100, maxGateBackoff}, // Would overflow without the cap
+	}
+	for _, tt := range tests {
+		if got := policy.delay(tt.retry); got != tt.want {
+			t.Errorf("delay(%d) = %v, want %v", tt.retry, got, tt.want)
+		}
+	}
+
+	if got := (RetryPolicy{}).delay(1); got != defaultGateBackoff {
+		t.Errorf("expected the default backoff, got %v", got)
+	}
+}
+
+func TestCommandQualityGate_Scope(t *testing.T) {
+	dir := writeWorkspaceFiles(t, map[string]string{
+		"services/auth/ok":    "",
+		"services/billing/ok": "",
+		"libs/shared/keep":    "",
+	})
+	var modified []string
+	gate := NewCommandQualityGate("marker", "test -f ok", true).
+		WithScope([]string{"services/auth", "services/billing", "libs/shared"}, func() []string { return modified })
+
+	// Nothing modified in the workspace paths: the gate does not run
+	if err := gate.Execute(context.Background(), dir); err != nil {
+		t.Fatalf("expected no affected paths to pass, got %v", err)
+	}
+
+	// The gate runs in every affected path and nowhere else
+	modified = []string{"services/auth/handler.go", "services/billing/handler.go", "README.md"}
+	if err := gate.Execute(context.Background(), dir); err != nil {
+		t.Fatalf("expected the gate to pass in the affected paths, got %v", err)
+	}
+
+	modified = append(modified, "libs/shared/log.go")
+	err := gate.Execute(context.Background(), dir)
+	if err == nil || !strings.Contains(err.Error(), "in libs/shared") {
+		t.Errorf("expected the gate to fail in libs/shared, got %v", err)
+	}
+	if failureKind(err) != GateFailureAssertion {
+		t.Errorf("expected an assertion failure, got %s", failureKind(err))
+	}
+}
+
+// orderedGate records when it runs; gates sharing a barrier wait until all of
+// them have started, so they only pass when run concurrently.
+type orderedGate struct {
+	name    string
+	fail    bool
+	runs    *[]string
+	mu      *sync.Mutex
+	barrier *sync.WaitGroup
+}
+
+func (g *orderedGate) Name() string   { return g.name }
+func (g *orderedGate) Required() bool { return true }
+func (g *orderedGate) Execute(ctx context.Context, workspaceDir string) error {
+	g.mu.Lock()
+	*g.runs = append(*g.runs, g.name)
+	g.mu.Unlock()
+
+	if g.barrier != nil {
+		g.barrier.Done()
+		started := make(chan struct{})
+		go func() {
+			g.barrier.Wait()
+			close(started)
+		}()
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			return errors.New("the other gates in the group did not run concurrently")
+		}
+	}
+	if g.fail {
+		return gateErr(GateFailureAssertion)
+	}
+	return nil
+}
+
+func TestQualityGateRunner_Schedules(t *testing.T) {
+	var runs []string
+	var mu sync.Mutex
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	gate := func(name string, fail bool) *orderedGate {
+		return &orderedGate{name: name, fail: fail, runs: &runs, mu: &mu}
+	}
+
+	build := gate("build", false)
+	lint := gate("lint", false)
+	lint.barrier = &barrier
+	unit := gate("unit-tests", false)
+	unit.barrier = &barrier
+	e2e := gate("e2e", false)
+	docs := gate("docs", false)
+
+	configs := []QualityGateConfig{
+		{Name: "e2e", DependsOn: []string{"unit-tests"}, RunIf: []string{"web/**"}},
+		{Name: "lint", Group: "checks", DependsOn: []string{"build"}},
+		{Name: "build"},
+		{Name: "unit-tests", Group: "checks", DependsOn: []string{"build"}},
+		{Name: "docs", RunIf: []string{"docs/**", "*.md"}},
+	}
+	modified := []string{"web/app/page.ts"}
+	runner := NewQualityGateRunner([]QualityGate{e2e, lint, build, unit, docs}).
+		WithSchedules(GateSchedules(configs), func() []string { return modified })
+
+	results := runner.RunAll(context.Background(), t.TempDir(), nil)
+	if !results.AllPassed {
+		t.Fatalf("expected all gates to pass: %+v", results.Results)
+	}
+	if len(runs) != 4 || runs[0] != "build" || runs[3] != "e2e" {
+		t.Errorf("run order = %v, want build, then lint and unit-tests, then e2e", runs)
+	}
+	names := make([]string, len(results.Results))
+	for i, result := range results.Results {
+		names[i] = result.Name
+	}
+	if strings.Join(names, ",") != "e2e,lint,build,unit-tests,docs" {
+		t.Errorf("results are not in gate order: %v", names)
+	}
+	if docsResult := results.Results[4]; !docsResult.Passed || !docsResult.Skipped || docsResult.Runs != 0 {
+		t.Errorf("expected docs to be skipped by run_if, got %+v", docsResult)
+	}
+}
+
+func TestQualityGateRunner_DependencyFailure(t *testing.T) {
+	var runs []string
+	var mu sync.Mutex
+	build := &orderedGate{name: "build", fail: true, runs: &runs, mu: &mu}
+	test := &orderedGate{name: "test", runs: &runs, mu: &mu}
+	e2e := &orderedGate{name: "e2e", runs: &runs, mu: &mu}
+
+	runner := NewQualityGateRunner([]QualityGate{build, test, e2e}).WithSchedules(map[string]GateSchedule{
+		"test": {DependsOn: []string{"build"}},
+		"e2e":  {DependsOn: []string{"test"}},
+	}, nil)
+	results := runner.RunAll(context.Background(), t.TempDir(), nil)
+
+	if len(runs) != 1 {
+		t.Errorf("expected only build to run, ran %v", runs)
+	}
+	if results.AllPassed {
+		t.Error("expected the run to fail")
+	}
+	for _, result := range results.Results[1:] {
+		if result.Passed || !result.Skipped || result.FailureKind != GateFailureAssertion {
+			t.Errorf("expected %s to be skipped as failed, got %+v", result.Name, result)
+		}
+	}
+	if !strings.Contains(results.Results[2].Error, "depends on 'test'") {
+		t.Errorf("error = %q", results.Results[2].Error)
+	}
+}
+
+func TestConfig_ValidateGateSchedules(t *testing.T) {
+	tests := []struct {
+		name    string
+		gates   []QualityGateConfig
+		wantErr string
+	}{
+		{
+			name: "valid",
+			gates: []QualityGateConfig{
+				{Name: "build", Command: "go build ./..."},
+				{Name: "test", Command: "go test ./...", DependsOn: []string{"build"}, Group: "checks", RunIf: []string{"**/*.go"}},
+			},
+		},
+		{
+			name:    "unknown dependency",
+			gates:   []QualityGateConfig{{Name: "test", Command: "go test ./...", DependsOn: []string{"build"}}},
+			wantErr: "unknown gate 'build'",
+		},
+		{
+			name: "cycle",
+			gates: []QualityGateConfig{
+				{Name: "a", Command: "true", DependsOn: []string{"c"}},
+				{Name: "b", Command: "true", DependsOn: []string{"a"}},
+				{Name: "c", Command: "true", DependsOn: []string{"b"}},
+			},
+			wantErr: "cycle: a → c → b → a",
+		},
+		{
+			name:    "invalid run_if",
+			gates:   []QualityGateConfig{{Name: "e2e", Command: "true", RunIf: []string{"web/[a"}}},
+			wantErr: "run_if",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Task: "test", Mode: ModeWrite, WorkspaceDir: "/tmp/test", QualityGates: tt.gates}
+			err := config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommandQualityGate_WorkDirEnvAndOutput(t *testing.T) {
+	dir := writeWorkspaceFiles(t, map[string]string{
+		"web/e2e.sh": "echo \"token=$E2E_TOKEN\"\necho 'browser crashed' >&2\nexit 3\n",
+	})
+	outputDir := t.TempDir()
+	gates := CreateQualityGates([]QualityGateConfig{
+		{Name: "E2E tests", Command: "sh e2e.sh", Required: true, WorkDir: "web", Env: map[string]string{"E2E_TOKEN": "abc"}},
+	}, nil, nil)
+	runner := NewQualityGateRunner(gates).WithArtifacts(NewArtifactWriter(outputDir, ArtifactConfig{}))
+
+	result := runner.RunAll(context.Background(), dir, nil).Results[0]
+	if result.Passed || result.FailureKind != GateFailureAssertion {
+		t.Fatalf("expected an assertion failure, got %+v", result)
+	}
+	if result.Stdout != "token=abc\n" || result.Stderr != "browser crashed\n" {
+		t.Errorf("expected the streams captured separately, got stdout %q, stderr %q", result.Stdout, result.Stderr)
+	}
+
+	for path, want := range map[string]string{
+		result.StdoutArtifact: "token=abc\n",
+		result.StderrArtifact: "browser crashed\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(path)))
+		if err != nil || string(content) != want {
+			t.Errorf("expected %q in artifact %q, got %q (%v)", want, path, content, err)
+		}
+	}
+	if result.StderrArtifact != "quality-gates/attempt-1/01-e2e-tests.stderr.log" {
+		t.Errorf("unexpected artifact path: %s", result.StderrArtifact)
+	}
+
+	// Each run of the gates gets its own artifacts
+	result = runner.RunAll(context.Background(), dir, nil).Results[0]
+	if !strings.HasPrefix(result.StdoutArtifact, "quality-gates/attempt-2/") {
+		t.Errorf("expected the second run's artifacts under attempt-2, got %s", result.StdoutArtifact)
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	if got := truncateOutput("short", 10); got != "short" {
+		t.Errorf("expected short output unchanged, got %q", got)
+	}
+
+	output := strings.Repeat("noise\n", 100) + "FAIL: TestLogin\n"
+	got := truncateOutput(output, 40)
+	if !strings.HasSuffix(got, "FAIL: TestLogin\n") || !strings.HasPrefix(got, "[") || len(got) > 40+len("[999 bytes truncated]\n") {
+		t.Errorf("expected the tail kept with a marker, got %q", got)
+	}
+	if !strings.Contains(got, "bytes truncated]\nnoise\n") {
+		t.Errorf("expected the cut to start at a line, got %q", got)
+	}
+}
+
+func TestConfig_ValidateGateWorkDirAndEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		gate    QualityGateConfig
+		wantErr string
+	}{
+		{name: "valid", gate: QualityGateConfig{Name: "e2e", Command: "npm run e2e", WorkDir: "web", Env: map[string]string{"CI": "1"}}},
+		{name: "absolute work_dir", gate: QualityGateConfig{Name: "e2e", Command: "true", WorkDir: "/tmp"}, wantErr: "work_dir"},
+		{name: "escaping work_dir", gate: QualityGateConfig{Name: "e2e", Command: "true", WorkDir: "../other"}, wantErr: "work_dir"},
+		{name: "invalid env name", gate: QualityGateConfig{Name: "e2e", Command: "true", Env: map[string]string{"A=B": "1"}}, wantErr: "env variable"},
+		{name: "not a command gate", gate: QualityGateConfig{Name: "secrets", Type: QualityGateTypeSecretScan, WorkDir: "web"}, wantErr: "only apply to command gates"},
+		{name: "negative timeout", gate: QualityGateConfig{Name: "e2e", Command: "true", Timeout: -time.Second}, wantErr: "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Task: "test", Mode: ModeWrite, WorkspaceDir: "/tmp/test", QualityGates: []QualityGateConfig{tt.gate}}
+			err := config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
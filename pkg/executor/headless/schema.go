@@ -0,0 +1,49 @@
+package headless
+
+import (
+	"reflect"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+	"github.com/entrhq/forge/pkg/config/jsonschema"
+	"github.com/entrhq/forge/pkg/objectstore"
+	"github.com/entrhq/forge/pkg/security/sandbox"
+)
+
+// Schema returns the JSON Schema of the headless YAML config, for editors and
+// for checking a config file before a run is scheduled.
+func Schema() *jsonschema.Schema {
+	schema := jsonschema.FromType(reflect.TypeFor[Config]())
+	schema.Schema = jsonschema.Draft
+	schema.Title = "Forge headless configuration"
+
+	// Fields limited to a set of values. Empty selects the default where
+	// Validate allows it.
+	enums := []struct {
+		path   []string
+		values []string
+	}{
+		{[]string{"mode"}, []string{string(ModeReadOnly), string(ModeWrite)}},
+		{[]string{"constraints", "oversized_messages"}, []string{"", "chunk", "reject"}},
+		{[]string{"tasks", "[]", "constraints", "oversized_messages"}, []string{"", "chunk", "reject"}},
+		{[]string{"quality_gates", "[]", "type"}, []string{"", QualityGateTypeCommand, QualityGateTypeLSP, QualityGateTypeSecretScan}},
+		{[]string{"verification", "mode"}, []string{"", VerificationModeNoBehaviorChange}},
+		{[]string{"fan_out", "by"}, []string{"", FanOutByPackage}},
+		{[]string{"fan_out", "discovery"}, []string{"", DiscoveryGo, DiscoveryCommand}},
+		{[]string{"fan_out", "pr"}, []string{"", FanOutPRSingle, FanOutPRStacked}},
+		{[]string{"cancel", "rollback"}, []string{"", CancelRollbackKeep, CancelRollbackStash, CancelRollbackDiscard}},
+		{[]string{"git", "stack_group_by"}, []string{"", StackGroupByDirectory, StackGroupByUnit}},
+		{[]string{"git", "provider"}, []string{"", git.ProviderGitHub, git.ProviderGitLab, git.ProviderGitea, git.ProviderBitbucket}},
+		{[]string{"artifacts", "upload", "provider"}, []string{"", objectstore.ProviderS3, objectstore.ProviderGCS, objectstore.ProviderAzure}},
+		{[]string{"notifications", "[]", "type"}, []string{NotificationSlack, NotificationTeams, NotificationWebhook}},
+		{[]string{"notifications", "[]", "when"}, []string{"", NotifyAlways, NotifyFailure, NotifySuccess}},
+		{[]string{"sandbox", "backend"}, []string{"", sandbox.BackendNone, sandbox.BackendDocker, sandbox.BackendPodman, sandbox.BackendBubblewrap}},
+		{[]string{"logging", "verbosity"}, []string{"", "quiet", "normal", "verbose", "debug"}},
+		{[]string{"logging", "format"}, []string{"", string(LogFormatText), string(LogFormatJSON)}},
+	}
+	for _, enum := range enums {
+		if field := schema.Lookup(enum.path...); field != nil {
+			field.Enum = jsonschema.Enum(enum.values...).Enum
+		}
+	}
+	return schema
+}
@@ -0,0 +1,89 @@
+package headless
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/config/jsonschema"
+)
+
+func TestSchema_ExampleConfigs(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "..", "examples", "headless", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("no example configs found")
+	}
+
+	schema := Schema()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		issues, err := jsonschema.Validate(schema, data)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		for _, issue := range issues {
+			t.Errorf("%s: %s", path, issue)
+		}
+	}
+}
+
+func TestSchema_Issues(t *testing.T) {
+	doc := `task: Fix lint
+mode: write
+constraints:
+  max_file: 3
+  timeout: 300
+quality_gates:
+  - name: test
+    command: go test ./...
+    type: shell
+git:
+  provider: githb
+rate_limit:
+  requests_per_minute: 10
+`
+	issues, err := jsonschema.Validate(Schema(), []byte(doc))
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	want := []string{"constraints.max_file", "constraints.timeout", "quality_gates[0].type", "git.provider"}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %d: %v", len(want), len(issues), issues)
+	}
+	for i, issue := range issues {
+		if issue.Path != want[i] {
+			t.Errorf("issue %d at %s, want %s", i, issue.Path, want[i])
+		}
+	}
+}
+
+func TestSchema_Enums(t *testing.T) {
+	schema := Schema()
+	for _, path := range [][]string{
+		{"mode"},
+		{"constraints", "oversized_messages"},
+		{"tasks", "[]", "constraints", "oversized_messages"},
+		{"quality_gates", "[]", "type"},
+		{"verification", "mode"},
+		{"fan_out", "by"},
+		{"fan_out", "discovery"},
+		{"fan_out", "pr"},
+		{"git", "provider"},
+		{"artifacts", "upload", "provider"},
+		{"sandbox", "backend"},
+		{"logging", "verbosity"},
+		{"logging", "format"},
+	} {
+		field := schema.Lookup(path...)
+		if field == nil || len(field.Enum) == 0 {
+			t.Errorf("expected an enum at %v", path)
+		}
+	}
+}
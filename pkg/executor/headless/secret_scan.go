@@ -0,0 +1,287 @@
+package headless
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/security/secrets"
+)
+
+const (
+	// QualityGateTypeSecretScan scans the changes made by the run for credentials
+	QualityGateTypeSecretScan = "secret_scan"
+
+	// secretScanAllowMarker on a line suppresses findings on it, as in gitleaks
+	secretScanAllowMarker = "gitleaks:allow"
+
+	// maxSecretScanFileBytes skips untracked files too large to be source
+	maxSecretScanFileBytes = 1024 * 1024
+
+	// emptyTreeHash is git's empty tree, diffed against in repositories with no commits
+	emptyTreeHash = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+)
+
+// SecretFinding is a credential found by a secret_scan gate. The secret
+// itself is masked so the finding can be logged and stored safely.
+type SecretFinding struct {
+	Rule   string `json:"rule"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Secret string `json:"secret"`
+}
+
+// String formats the finding as file:line: rule: masked secret.
+func (f SecretFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", f.File, f.Line, f.Rule, f.Secret)
+}
+
+// FindingsQualityGate is an optional interface for gates that report
+// individual findings, recorded in the gate's result.
+type FindingsQualityGate interface {
+	QualityGate
+
+	// LastFindings returns the findings of the most recent Execute
+	LastFindings() []SecretFinding
+}
+
+// SecretScanGate scans the lines added by the run, in tracked and untracked
+// files, for credentials. It is always required, so a finding blocks the
+// auto-commit even when commit_on_quality_fail is set.
+type SecretScanGate struct {
+	name     string
+	timeout  time.Duration
+	retry    RetryPolicy
+	findings []SecretFinding
+}
+
+// NewSecretScanGate creates a secret scanning gate.
+func NewSecretScanGate(name string, timeout time.Duration) *SecretScanGate {
+	if timeout <= 0 {
+		timeout = 3 * time.Minute
+	}
+	return &SecretScanGate{name: name, timeout: timeout}
+}
+
+// Name returns the name of the quality gate
+func (g *SecretScanGate) Name() string {
+	return g.name
+}
+
+// Required returns true: committed credentials cannot be taken back
+func (g *SecretScanGate) Required() bool {
+	return true
+}
+
+// WithRetryPolicy sets how the gate is re-run on failure and returns the gate.
+func (g *SecretScanGate) WithRetryPolicy(policy RetryPolicy) *SecretScanGate {
+	g.retry = policy
+	return g
+}
+
+// RetryPolicy returns the gate's retry policy
+func (g *SecretScanGate) RetryPolicy() RetryPolicy {
+	return g.retry
+}
+
+// LastFindings returns the masked findings of the most recent run
+func (g *SecretScanGate) LastFindings() []SecretFinding {
+	return g.findings
+}
+
+// Execute scans the workspace's uncommitted changes and fails if any
+// credentials were added.
+func (g *SecretScanGate) Execute(ctx context.Context, workspaceDir string) error {
+	g.findings = nil
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("quality gate '%s' skipped: parent context canceled", g.name)
+	default:
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	findings, err := scanWorkspaceChanges(execCtx, workspaceDir)
+	if err != nil {
+		return &QualityGateError{
+			GateName: g.name,
+			Command:  QualityGateTypeSecretScan,
+			Err:      err,
+			Kind:     GateFailureInfrastructure,
+		}
+	}
+	g.findings = findings
+	if len(findings) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(findings)+1)
+	for _, finding := range findings {
+		lines = append(lines, finding.String())
+	}
+	lines = append(lines, "Remove the credentials (read them from the environment or a secret store instead), or add '"+
+		secretScanAllowMarker+"' to the line if it is a test fixture.")
+	return &QualityGateError{
+		GateName: g.name,
+		Command:  QualityGateTypeSecretScan,
+		Output:   strings.Join(lines, "\n"),
+		Err:      fmt.Errorf("%d potential secret(s) found in the changes", len(findings)),
+		Kind:     GateFailureAssertion,
+	}
+}
+
+// scanWorkspaceChanges scans the lines added since HEAD and the contents of
+// untracked files under workspaceDir.
+func scanWorkspaceChanges(ctx context.Context, workspaceDir string) ([]SecretFinding, error) {
+	base := "HEAD"
+	if _, err := runGit(ctx, workspaceDir, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		base = emptyTreeHash
+	}
+
+	diff, err := runGit(ctx, workspaceDir, "diff", base,
+		"--no-color", "--no-ext-diff", "--unified=0", "--relative", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	findings := scanDiff(diff)
+
+	untracked, err := runGit(ctx, workspaceDir, "ls-files", "--others", "--exclude-standard", "-z", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range strings.Split(strings.TrimRight(untracked, "\x00"), "\x00") {
+		if file == "" {
+			continue
+		}
+		content, err := readScannableFile(filepath.Join(workspaceDir, file))
+		if err != nil {
+			return nil, err
+		}
+		for i, line := range strings.Split(content, "\n") {
+			findings = append(findings, scanLine(filepath.ToSlash(file), i+1, line)...)
+		}
+	}
+	return findings, nil
+}
+
+// scanDiff scans the added lines of a unified diff.
+func scanDiff(diff string) []SecretFinding {
+	var findings []SecretFinding
+	var file string
+	line := 0
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 64*1024), maxSecretScanFileBytes)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(text, "+++ ")
+			if unquoted, err := strconv.Unquote(file); err == nil {
+				file = unquoted // Git quotes paths with unusual characters
+			}
+			file = strings.TrimPrefix(file, "b/")
+			if file == "/dev/null" {
+				file = ""
+			}
+		case strings.HasPrefix(text, "@@ "):
+			line = hunkStartLine(text)
+		case strings.HasPrefix(text, "+"):
+			if file != "" {
+				findings = append(findings, scanLine(file, line, text[1:])...)
+			}
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return findings
+}
+
+// hunkStartLine returns the first new-file line of a hunk header such as
+// "@@ -1,2 +3,4 @@".
+func hunkStartLine(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0
+	}
+	start, _, _ := strings.Cut(fields[2][1:], ",")
+	n, _ := strconv.Atoi(start)
+	return n
+}
+
+// scanLine applies the secrets ruleset to one line of file. A secret is
+// reported once, under the first rule that matches it.
+func scanLine(file string, lineNum int, line string) []SecretFinding {
+	if strings.Contains(line, secretScanAllowMarker) {
+		return nil
+	}
+
+	var findings []SecretFinding
+	seen := make(map[string]bool)
+	for _, match := range secrets.Find(line) {
+		if seen[match.Secret] {
+			continue
+		}
+		seen[match.Secret] = true
+		findings = append(findings, SecretFinding{
+			Rule:   match.Rule,
+			File:   file,
+			Line:   lineNum,
+			Secret: maskSecret(match.Secret),
+		})
+	}
+	return findings
+}
+
+// readScannableFile returns the content of a text file, or "" for binary
+// files, files too large to be source, and files removed since listing.
+func readScannableFile(path string) (string, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if !info.Mode().IsRegular() || info.Size() > maxSecretScanFileBytes {
+		return "", nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if bytes.IndexByte(content, 0) >= 0 {
+		return "", nil
+	}
+	return string(content), nil
+}
+
+// maskSecret keeps enough of a secret to identify it and hides the rest.
+func maskSecret(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", min(len(secret)-4, 16))
+}
+
+// runGit runs git in dir and returns its standard output.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,252 @@
+package headless
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/git"
+)
+
+// defaultStackGroupDepth is the directory depth files are grouped by when
+// stack_group_depth is not set
+const defaultStackGroupDepth = 1
+
+// StackedPR is one part of an oversized change split into dependent pull
+// requests, each based on the branch of the part before it
+type StackedPR struct {
+	Group       string   `json:"group"`
+	Description string   `json:"description,omitempty"` // Set for logical units declared by the agent
+	Branch      string   `json:"branch"`
+	Base        string   `json:"base"`
+	Commit      string   `json:"commit,omitempty"`
+	Files       []string `json:"files"`
+	Lines       int      `json:"lines"`
+	PRURL       string   `json:"pr_url,omitempty"`
+}
+
+// stackGroup returns the group a workspace-relative file belongs to: its
+// directory, cut to depth elements. Files at the root form the "." group.
+func stackGroup(file string, depth int) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return "."
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// groupStack splits changed files, with their changed line counts, into
+// stack parts. Files in one of units form a part per unit, in the units'
+// order, and a file in several belongs to the first. The remaining files are
+// grouped by directory after them, ordered by group with root files first.
+// Files within a part are sorted.
+func groupStack(lineCounts map[string]int, depth int, units []*ChangeUnit) []StackedPR {
+	if depth <= 0 {
+		depth = defaultStackGroupDepth
+	}
+
+	unitParts := make([]StackedPR, len(units))
+	byGroup := make(map[string]*StackedPR)
+	for file, lines := range lineCounts {
+		if i := slices.IndexFunc(units, func(u *ChangeUnit) bool { return u.matches(file) }); i >= 0 {
+			unitParts[i].Files = append(unitParts[i].Files, file)
+			unitParts[i].Lines += lines
+			continue
+		}
+
+		group := stackGroup(file, depth)
+		part, ok := byGroup[group]
+		if !ok {
+			part = &StackedPR{Group: group}
+			byGroup[group] = part
+		}
+		part.Files = append(part.Files, file)
+		part.Lines += lines
+	}
+
+	dirParts := make([]StackedPR, 0, len(byGroup))
+	for _, part := range byGroup {
+		sort.Strings(part.Files)
+		dirParts = append(dirParts, *part)
+	}
+	sort.Slice(dirParts, func(i, j int) bool { return dirParts[i].Group < dirParts[j].Group })
+
+	// Units that cover none of the changed files are dropped
+	parts := make([]StackedPR, 0, len(units)+len(dirParts))
+	for i, part := range unitParts {
+		if len(part.Files) == 0 {
+			continue
+		}
+		part.Group = units[i].Name
+		part.Description = units[i].Description
+		sort.Strings(part.Files)
+		parts = append(parts, part)
+	}
+	return append(parts, dirParts...)
+}
+
+// stackCommitMessage appends the part's position and group to the first line
+// of message, and puts the description of a logical unit before its body
+func stackCommitMessage(message string, part, total int, group, description string) string {
+	subject, rest, _ := strings.Cut(message, "\n")
+	message = fmt.Sprintf("%s (%d/%d: %s)", subject, part, total, group)
+	if description != "" {
+		message += "\n\n" + description
+		rest = strings.TrimLeft(rest, "\n")
+		if rest != "" {
+			rest = "\n" + rest
+		}
+	}
+	if rest != "" {
+		message += "\n" + rest
+	}
+	return message
+}
+
+// commitStack splits the working tree's changes into a stack of commits on
+// dependent branches and opens a pull request for each when the diff changes
+// more than stack_max_lines lines. It reports whether the changes were split;
+// if not, nothing has been committed.
+func (e *Executor) commitStack(ctx context.Context, message string) (bool, error) {
+	lineCounts, err := e.gitManager.StagedLineCounts(ctx)
+	if err != nil {
+		return false, err
+	}
+	total := 0
+	for _, lines := range lineCounts {
+		total += lines
+	}
+	if total <= e.config.Git.StackMaxLines {
+		return false, nil
+	}
+
+	parts := groupStack(lineCounts, e.config.Git.StackGroupDepth, e.changeUnits.Units())
+	if len(parts) < 2 {
+		e.logger.Warningf("! Diff changes %d lines, more than stack_max_lines (%d), but all files are in one group; creating a single PR",
+			total, e.config.Git.StackMaxLines)
+		return false, nil
+	}
+
+	head, err := e.gitManager.GetCurrentBranch(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	base := e.prBase()
+
+	e.logger.Infof("± Diff changes %d lines, more than stack_max_lines (%d); splitting into %d stacked PRs",
+		total, e.config.Git.StackMaxLines, len(parts))
+
+	// Each part is committed on its own branch, created from the previous one
+	for i := range parts {
+		part := &parts[i]
+		part.Branch = head
+		part.Base = base
+		if i > 0 {
+			part.Branch = fmt.Sprintf("%s-%d", head, i+1)
+			part.Base = parts[i-1].Branch
+			if err := e.gitManager.CreateBranch(ctx, part.Branch); err != nil {
+				return true, err
+			}
+		}
+
+		if err := e.gitManager.CommitPaths(ctx, stackCommitMessage(message, i+1, len(parts), part.Group, part.Description), part.Files); err != nil {
+			return true, fmt.Errorf("failed to commit stack part %s: %w", part.Group, err)
+		}
+		if part.Commit, err = e.gitManager.HeadCommit(ctx); err != nil {
+			return true, err
+		}
+		e.logger.Successf("± Committed part %d/%d (%s, %d files) on %s", i+1, len(parts), part.Group, len(part.Files), part.Branch)
+	}
+	e.summary.StackedPRs = parts
+
+	if err := e.createStackedPullRequests(ctx, total); err != nil {
+		if e.config.Git.RequirePR {
+			return true, fmt.Errorf("failed to create stacked pull requests: %w", err)
+		}
+		e.logger.Warningf("! Failed to create stacked PRs, falling back to direct push: %v", err)
+		if e.config.Git.AutoPush {
+			for _, part := range parts {
+				if pushErr := e.gitManager.PushBranch(ctx, part.Branch); pushErr != nil {
+					return true, fmt.Errorf("failed to push after PR creation failure: %w", pushErr)
+				}
+			}
+			e.logger.Successf("↑ Pushed %d stacked branches to remote", len(parts))
+		}
+	}
+
+	return true, nil
+}
+
+// createStackedPullRequests opens a pull request for each part of the stack,
+// in order, targeting the previous part's branch
+func (e *Executor) createStackedPullRequests(ctx context.Context, total int) error {
+	parts := e.summary.StackedPRs
+	title, overview := e.prContent(ctx, parts[0].Base, parts[len(parts)-1].Branch)
+
+	for i := range parts {
+		part := &parts[i]
+		e.logger.Infof("↑ Pushing to origin/%s...", part.Branch)
+		prURL, err := e.gitManager.CreatePullRequest(ctx, git.PullRequest{
+			Title: fmt.Sprintf("%s (%d/%d: %s)", title, i+1, len(parts), part.Group),
+			Body:  e.stackPRBody(i, total, overview),
+			Base:  part.Base,
+			Head:  part.Branch,
+			Draft: e.config.Git.PRDraft,
+		})
+		if err != nil {
+			return fmt.Errorf("part %d/%d (%s): %w", i+1, len(parts), part.Group, err)
+		}
+		part.PRURL = prURL
+		e.logger.Successf("⇄ Created pull request %d/%d: %s", i+1, len(parts), prURL)
+	}
+
+	// The bottom of the stack is the PR to review first
+	e.summary.PRURL = parts[0].PRURL
+	return nil
+}
+
+// stackPRBody describes part i of the stack and links every other part.
+// Parts opened earlier are linked by URL, later ones by branch.
+func (e *Executor) stackPRBody(i, total int, overview string) string {
+	parts := e.summary.StackedPRs
+	part := parts[i]
+
+	splitBy := "by directory"
+	if slices.ContainsFunc(parts, func(p StackedPR) bool { return p.Description != "" }) {
+		splitBy = "into logical units"
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Part %d of %d of a stacked change. The full diff changes %d lines, more than the %d line limit, "+
+		"so it is split %s into dependent pull requests. Review and merge them in order.\n\n",
+		i+1, len(parts), total, e.config.Git.StackMaxLines, splitBy)
+	fmt.Fprintf(&body, "This part changes `%s`: %d file(s), %d line(s).\n\n", part.Group, len(part.Files), part.Lines)
+	if part.Description != "" {
+		body.WriteString(part.Description + "\n\n")
+	}
+
+	body.WriteString("## Stack\n\n")
+	for j, other := range parts {
+		entry := fmt.Sprintf("`%s` (`%s`)", other.Branch, other.Group)
+		switch {
+		case j == i:
+			entry = fmt.Sprintf("**%s — this pull request**", entry)
+		case other.PRURL != "":
+			entry += " — " + other.PRURL
+		}
+		fmt.Fprintf(&body, "%d. %s\n", j+1, entry)
+	}
+
+	if overview != "" {
+		body.WriteString("\n---\n\n")
+		body.WriteString(overview)
+	}
+	return body.String()
+}
@@ -0,0 +1,253 @@
+package headless
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGroupStack(t *testing.T) {
+	parts := groupStack(map[string]int{
+		"README.md":              2,
+		"api/handler.go":         10,
+		"api/v2/handler.go":      5,
+		"store/db.go":            7,
+		"store/migrations/1.sql": 1,
+	}, 0, nil)
+
+	want := []struct {
+		group string
+		files int
+		lines int
+	}{
+		{".", 1, 2},
+		{"api", 2, 15},
+		{"store", 2, 8},
+	}
+	if len(parts) != len(want) {
+		t.Fatalf("expected %d parts, got %+v", len(want), parts)
+	}
+	for i, w := range want {
+		if parts[i].Group != w.group || len(parts[i].Files) != w.files || parts[i].Lines != w.lines {
+			t.Errorf("part %d: expected %s with %d files and %d lines, got %+v", i, w.group, w.files, w.lines, parts[i])
+		}
+	}
+
+	if parts := groupStack(map[string]int{"api/v2/handler.go": 1, "api/v1/handler.go": 1}, 2, nil); len(parts) != 2 || parts[0].Group != "api/v1" {
+		t.Errorf("expected grouping by two directory levels, got %+v", parts)
+	}
+}
+
+func TestGroupStack_Units(t *testing.T) {
+	units := &ChangeUnits{}
+	for _, unit := range []struct {
+		name  string
+		paths []string
+	}{
+		{"client", []string{"pkg/client/", "pkg/api/types.go"}},
+		{"unused", []string{"docs/**"}},
+		{"callers", []string{"cmd/**/*.go", "pkg/client/client.go"}},
+	} {
+		if err := units.Declare(unit.name, "About "+unit.name, unit.paths); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parts := groupStack(map[string]int{
+		"pkg/client/client.go": 40,
+		"pkg/client/retry.go":  10,
+		"pkg/api/types.go":     5,
+		"pkg/api/handler.go":   3,
+		"cmd/forge/main.go":    20,
+		"README.md":            1,
+	}, 0, units.Units())
+
+	want := []struct {
+		group string
+		files []string
+		lines int
+	}{
+		{"client", []string{"pkg/api/types.go", "pkg/client/client.go", "pkg/client/retry.go"}, 55},
+		{"callers", []string{"cmd/forge/main.go"}, 20},
+		{".", []string{"README.md"}, 1},
+		{"pkg", []string{"pkg/api/handler.go"}, 3},
+	}
+	if len(parts) != len(want) {
+		t.Fatalf("expected %d parts, got %+v", len(want), parts)
+	}
+	for i, w := range want {
+		if parts[i].Group != w.group || strings.Join(parts[i].Files, ",") != strings.Join(w.files, ",") || parts[i].Lines != w.lines {
+			t.Errorf("part %d: expected %s with %v and %d lines, got %+v", i, w.group, w.files, w.lines, parts[i])
+		}
+	}
+	if parts[0].Description != "About client" || parts[2].Description != "" {
+		t.Errorf("expected only units to carry a description, got %+v", parts)
+	}
+}
+
+func TestChangeUnits_Declare(t *testing.T) {
+	units := &ChangeUnits{}
+	for _, bad := range []string{"/etc/passwd", "../other", ".", "pkg/[a"} {
+		if err := units.Declare("bad", "", []string{bad}); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+
+	if err := units.Declare("first", "One", []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := units.Declare("second", "Two", []string{"b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := units.Declare("first", "Redone", []string{"./c/"}); err != nil {
+		t.Fatal(err)
+	}
+	got := units.Units()
+	if len(got) != 2 || got[0].Name != "first" || got[0].Description != "Redone" || !got[0].matches("c/file.go") || got[0].matches("a") {
+		t.Errorf("expected redeclaring to replace the unit in place, got %+v", got)
+	}
+}
+
+func TestGroupChangesTool_Execute(t *testing.T) {
+	units := &ChangeUnits{}
+	tool := NewGroupChangesTool(units, 400)
+
+	args := `<arguments><name>client</name><description>Extract the client.</description>` +
+		`<paths><path>pkg/client/**</path><path>go.mod</path></paths></arguments>`
+	result, metadata, err := tool.Execute(context.Background(), []byte(args))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(result, "Stack order: client") || metadata["units"] != 1 {
+		t.Errorf("unexpected result %q, metadata %v", result, metadata)
+	}
+	if got := units.Units(); len(got) != 1 || len(got[0].Paths) != 2 || !got[0].matches("pkg/client/http/do.go") {
+		t.Errorf("unexpected units %+v", got)
+	}
+
+	if _, _, err := tool.Execute(context.Background(), []byte(`<arguments><name>x</name><description>d</description></arguments>`)); err == nil {
+		t.Error("expected an error without paths")
+	}
+}
+
+func TestStackCommitMessage(t *testing.T) {
+	got := stackCommitMessage("feat: add tracing\n\nDetails", 2, 3, "store", "")
+	if got != "feat: add tracing (2/3: store)\n\nDetails" {
+		t.Errorf("unexpected message %q", got)
+	}
+
+	got = stackCommitMessage("feat: add tracing\n\nDetails", 1, 3, "client", "Extract the client.")
+	if got != "feat: add tracing (1/3: client)\n\nExtract the client.\n\nDetails" {
+		t.Errorf("unexpected message with a unit description %q", got)
+	}
+	if got := stackCommitMessage("feat: add tracing", 1, 3, "client", "Extract the client."); got != "feat: add tracing (1/3: client)\n\nExtract the client." {
+		t.Errorf("unexpected message %q", got)
+	}
+}
+
+func TestExecutor_CommitStack(t *testing.T) {
+	dir := setupGitRepo(t)
+	gitOutput(t, dir, "checkout", "-b", "forge/big")
+
+	files := map[string]string{
+		"api/handler.go": "package api\n\nfunc A() {}\n",
+		"store/db.go":    "package store\n\nfunc B() {}\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := DefaultConfig()
+	config.Task = "Add functions"
+	config.WorkspaceDir = dir
+	config.Logging.Verbosity = "quiet"
+	config.Git.AutoCommit = true
+	config.Git.CreatePR = true
+	config.Git.Branch = "forge/big"
+	config.Git.CommitMessage = "feat: add functions"
+	config.Git.PRTitle = "Add functions"
+	config.Git.PRBody = "Adds functions."
+	config.Git.StackMaxLines = 4
+
+	executor, err := NewExecutor(newScriptedAgent(func() error { return nil }), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// There is no remote, so PR creation fails and the stack stays local
+	if err := executor.commitChanges(context.Background()); err != nil {
+		t.Fatalf("commitChanges: %v", err)
+	}
+
+	parts := executor.summary.StackedPRs
+	if len(parts) != 2 || parts[0].Branch != "forge/big" || parts[1].Branch != "forge/big-2" || parts[1].Base != "forge/big" {
+		t.Fatalf("unexpected stack %+v", parts)
+	}
+	if files := gitOutput(t, dir, "show", "--name-only", "--format=", "forge/big"); files != "api/handler.go" {
+		t.Errorf("expected only api in the first part, got %q", files)
+	}
+	if files := gitOutput(t, dir, "show", "--name-only", "--format=", "forge/big-2"); files != "store/db.go" {
+		t.Errorf("expected only store in the second part, got %q", files)
+	}
+	if parent := gitOutput(t, dir, "rev-parse", "forge/big-2~1"); parent != parts[0].Commit {
+		t.Errorf("expected the second part to be stacked on %s, got %s", parts[0].Commit, parent)
+	}
+	if subject := gitOutput(t, dir, "log", "-1", "--format=%s", "forge/big-2"); subject != "feat: add functions (2/2: store)" {
+		t.Errorf("unexpected commit subject %q", subject)
+	}
+
+	body := executor.stackPRBody(1, 6, "Adds functions.")
+	for _, want := range []string{"Part 2 of 2", "`forge/big` (`api`)", "**`forge/big-2` (`store`) — this pull request**", "Adds functions."} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected PR body to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestExecutor_CommitStack_UnderLimit(t *testing.T) {
+	dir := setupGitRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "small.txt"), []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultConfig()
+	config.Task = "Small change"
+	config.WorkspaceDir = dir
+	config.Logging.Verbosity = "quiet"
+	config.Git.AutoCommit = true
+	config.Git.CreatePR = true
+	config.Git.Branch = "forge/small"
+	config.Git.StackMaxLines = 100
+
+	executor, err := NewExecutor(newScriptedAgent(func() error { return nil }), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	split, err := executor.commitStack(context.Background(), "chore: small change")
+	if err != nil || split {
+		t.Errorf("expected no split under the limit, got split=%v err=%v", split, err)
+	}
+}
+
+func TestExecutor_StackPRBody_Units(t *testing.T) {
+	config := DefaultConfig()
+	config.Git.StackMaxLines = 50
+	executor := &Executor{config: config, summary: &ExecutionSummary{StackedPRs: []StackedPR{
+		{Group: "client", Description: "Extract the client.", Branch: "forge/big", Files: []string{"pkg/client/client.go"}, Lines: 60, PRURL: "https://example.com/pr/1"},
+		{Group: "cmd", Branch: "forge/big-2", Files: []string{"cmd/main.go"}, Lines: 10},
+	}}}
+
+	body := executor.stackPRBody(0, 70, "")
+	for _, want := range []string{"split into logical units", "This part changes `client`", "Extract the client.", "`forge/big-2` (`cmd`)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected PR body to contain %q, got:\n%s", want, body)
+		}
+	}
+}
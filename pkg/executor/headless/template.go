@@ -0,0 +1,145 @@
+package headless
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TaskVarEnvPrefix prefixes the environment variables that fill task
+// template placeholders: FORGE_VAR_ISSUE_TITLE fills {{issue_title}}.
+const TaskVarEnvPrefix = "FORGE_VAR_"
+
+// taskPlaceholder matches a {{name}} placeholder in a task template
+var taskPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// TaskVars holds values for task template placeholders. It implements
+// flag.Value for repeated -var name=value flags.
+type TaskVars map[string]string
+
+// String returns the values as comma-separated name=value pairs.
+func (v TaskVars) String() string {
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + v[name]
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set adds a name=value pair.
+func (v TaskVars) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value, got %q", s)
+	}
+	v[name] = value
+	return nil
+}
+
+// LoadTaskVars reads task template values from the JSON object in the file
+// at path, or standard input when path is "-". Strings are used as they are,
+// lists are joined one item per line, and other values are written as JSON.
+func LoadTaskVars(path string) (TaskVars, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task variables: %w", err)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse task variables in %s: expected a JSON object: %w", path, err)
+	}
+
+	vars := make(TaskVars, len(payload))
+	for name, value := range payload {
+		vars[name] = taskVarString(value)
+	}
+	return vars, nil
+}
+
+// taskVarString formats a JSON value for a task template
+func taskVarString(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = taskVarString(item)
+		}
+		return strings.Join(items, "\n")
+	default:
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+}
+
+// RenderTaskTemplate sets Task to the task_template file with its
+// placeholders filled. Values come from, in increasing precedence, task_vars,
+// FORGE_VAR_<NAME> environment variables and vars, which holds the values
+// given on the command line. It does nothing without a task_template.
+func (c *Config) RenderTaskTemplate(vars TaskVars) error {
+	if c.TaskTemplate == "" || c.taskRendered {
+		return nil
+	}
+	if c.Task != "" || len(c.Tasks) > 0 {
+		return fmt.Errorf("task_template cannot be combined with task or tasks")
+	}
+
+	template, err := os.ReadFile(c.workspacePath(c.TaskTemplate))
+	if err != nil {
+		return fmt.Errorf("failed to read task_template: %w", err)
+	}
+
+	var missing []string
+	task := taskPlaceholder.ReplaceAllStringFunc(string(template), func(placeholder string) string {
+		name := taskPlaceholder.FindStringSubmatch(placeholder)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		if value, ok := os.LookupEnv(TaskVarEnvPrefix + strings.ToUpper(name)); ok {
+			return value
+		}
+		if value, ok := c.TaskVars[name]; ok {
+			return value
+		}
+		if !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+		return placeholder
+	})
+	if len(missing) > 0 {
+		return fmt.Errorf("task_template %s has no value for %s; set them with -var name=value, -vars-file, %s<NAME> environment variables or task_vars",
+			c.TaskTemplate, strings.Join(missing, ", "), TaskVarEnvPrefix)
+	}
+
+	task = strings.TrimSpace(task)
+	if task == "" {
+		return fmt.Errorf("task_template %s is empty", c.TaskTemplate)
+	}
+	c.Task = task
+	c.taskRendered = true
+	return nil
+}
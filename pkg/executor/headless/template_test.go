@@ -0,0 +1,100 @@
+package headless
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// templateConfig returns a config whose task_template holds template
+func templateConfig(t *testing.T, template string) *Config {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "task.md"), []byte(template), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := DefaultConfig()
+	config.WorkspaceDir = dir
+	config.TaskTemplate = "task.md"
+	return config
+}
+
+func TestConfig_RenderTaskTemplate(t *testing.T) {
+	config := templateConfig(t, "Fix {{issue_title}} in {{ paths }}.\n\nLog:\n{{error_log}}\n")
+	config.TaskVars = map[string]string{"issue_title": "default title", "paths": "./..."}
+	t.Setenv(TaskVarEnvPrefix+"ERROR_LOG", "panic: nil map")
+	t.Setenv(TaskVarEnvPrefix+"PATHS", "from the environment")
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("expected an unrendered template to validate, got %v", err)
+	}
+	if err := config.RenderTaskTemplate(TaskVars{"paths": "internal/auth"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Fix default title in internal/auth.\n\nLog:\npanic: nil map"
+	if config.Task != want {
+		t.Errorf("task = %q, want %q", config.Task, want)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("expected the rendered config to validate, got %v", err)
+	}
+}
+
+func TestConfig_RenderTaskTemplate_Errors(t *testing.T) {
+	config := templateConfig(t, "Fix {{issue_title}}: {{error_log}} {{issue_title}}")
+	err := config.RenderTaskTemplate(nil)
+	if err == nil || !strings.Contains(err.Error(), "no value for issue_title, error_log;") {
+		t.Errorf("expected the missing values to be listed once each, got %v", err)
+	}
+
+	config = templateConfig(t, "Fix it")
+	config.Task = "Something else"
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("expected task and task_template to be rejected, got %v", err)
+	}
+	if err := config.RenderTaskTemplate(nil); err == nil {
+		t.Error("expected task and task_template not to render")
+	}
+}
+
+func TestLoadTaskVars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "event.json")
+	payload := `{"issue_title": "Login fails", "issue_number": 42, "paths": ["internal/auth", "cmd/login"], "labels": {"bug": true}, "assignee": null}`
+	if err := os.WriteFile(path, []byte(payload), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	vars, err := LoadTaskVars(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TaskVars{
+		"issue_title":  "Login fails",
+		"issue_number": "42",
+		"paths":        "internal/auth\ncmd/login",
+		"labels":       `{"bug":true}`,
+		"assignee":     "",
+	}
+	if vars.String() != want.String() {
+		t.Errorf("vars = %v, want %v", vars, want)
+	}
+
+	if err := os.WriteFile(path, []byte(`["not", "an", "object"]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTaskVars(path); err == nil {
+		t.Error("expected a payload that is not an object to be rejected")
+	}
+}
+
+func TestTaskVars_Set(t *testing.T) {
+	vars := TaskVars{}
+	if err := vars.Set("error_log=a=b"); err != nil || vars["error_log"] != "a=b" {
+		t.Errorf("expected the value to keep its =, got %v (%v)", vars, err)
+	}
+	if err := vars.Set("novalue"); err == nil {
+		t.Error("expected a flag without = to be rejected")
+	}
+}
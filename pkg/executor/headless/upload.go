@@ -0,0 +1,223 @@
+package headless
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/objectstore"
+)
+
+const (
+	// defaultUploadURLExpiry is how long the logged signed URLs stay valid
+	defaultUploadURLExpiry = 24 * time.Hour
+
+	// uploadTimeout bounds the whole upload, including retention cleanup.
+	// Uploads run after the execution's own timeout may have passed.
+	uploadTimeout = 5 * time.Minute
+)
+
+// ArtifactUploadConfig pushes the artifacts directory to object storage once
+// the run has written it, so ephemeral CI runners keep the audit trail. Each
+// run is uploaded under <prefix>/<run id>/.
+//
+// Credentials come from the environment: AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY (and AWS_SESSION_TOKEN) for S3, GCS_HMAC_ACCESS_ID
+// and GCS_HMAC_SECRET for GCS, and AZURE_STORAGE_KEY for Azure.
+type ArtifactUploadConfig struct {
+	Provider  string        `yaml:"provider" json:"provider"`     // s3, gcs, or azure; empty disables uploads
+	Bucket    string        `yaml:"bucket" json:"bucket"`         // Bucket, or container on Azure
+	Prefix    string        `yaml:"prefix" json:"prefix"`         // Key prefix runs are uploaded under
+	Region    string        `yaml:"region" json:"region"`         // S3 region (default: AWS_REGION, or us-east-1)
+	Endpoint  string        `yaml:"endpoint" json:"endpoint"`     // Service URL for S3-compatible storage or the Azure emulator
+	Account   string        `yaml:"account" json:"account"`       // Azure storage account (default: AZURE_STORAGE_ACCOUNT)
+	Retention time.Duration `yaml:"retention" json:"retention"`   // Delete uploads under the prefix older than this (default: keep all)
+	URLExpiry time.Duration `yaml:"url_expiry" json:"url_expiry"` // Lifetime of the logged signed URLs (default: 24h, at most 7 days)
+}
+
+// Enabled reports whether artifacts are uploaded.
+func (c ArtifactUploadConfig) Enabled() bool {
+	return c.Provider != ""
+}
+
+// validate checks the upload settings.
+func (c ArtifactUploadConfig) validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	switch c.Provider {
+	case objectstore.ProviderS3, objectstore.ProviderGCS, objectstore.ProviderAzure:
+	default:
+		return fmt.Errorf("invalid artifacts upload provider: %s (must be 's3', 'gcs', or 'azure')", c.Provider)
+	}
+	if c.Bucket == "" {
+		return fmt.Errorf("artifacts upload requires a bucket")
+	}
+	if c.Retention < 0 || c.URLExpiry < 0 {
+		return fmt.Errorf("artifacts upload retention and url_expiry cannot be negative")
+	}
+	if c.URLExpiry > 7*24*time.Hour {
+		return fmt.Errorf("artifacts upload url_expiry cannot exceed 7 days")
+	}
+	// Retention deletes everything old under the prefix, so never a whole bucket
+	if c.Retention > 0 && c.prefix() == "" {
+		return fmt.Errorf("artifacts upload retention requires a prefix")
+	}
+	return nil
+}
+
+// prefix returns the key prefix without surrounding slashes.
+func (c ArtifactUploadConfig) prefix() string {
+	return strings.Trim(c.Prefix, "/")
+}
+
+// store creates the object store the artifacts are uploaded to.
+func (c ArtifactUploadConfig) store() (objectstore.Store, error) {
+	return objectstore.New(objectstore.ConfigFromEnv(objectstore.Config{
+		Provider: c.Provider,
+		Bucket:   c.Bucket,
+		Region:   c.Region,
+		Endpoint: c.Endpoint,
+		Account:  c.Account,
+	}))
+}
+
+// uploadArtifacts uploads the artifacts directory of config under a key
+// prefix unique to the run that started at startTime, logs a signed URL for
+// every file, and applies the retention policy. Failures are logged rather
+// than failing the run, whose own outcome is already decided.
+func uploadArtifacts(config *Config, startTime time.Time, logger *Logger) {
+	upload := config.Artifacts.Upload
+	if !upload.Enabled() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+
+	store, err := upload.store()
+	if err != nil {
+		logger.Warningf("! Failed to upload artifacts: %v", err)
+		return
+	}
+
+	id := config.RunID
+	if id == "" {
+		id = runID(startTime)
+	}
+	runPrefix := path.Join(upload.prefix(), id)
+	outputDir := config.ArtifactDir()
+	keys, err := putArtifacts(ctx, store, outputDir, runPrefix)
+	if err != nil {
+		logger.Warningf("! Failed to upload artifacts: %v", err)
+	}
+	if len(keys) == 0 {
+		return
+	}
+	logger.Successf("↑ Uploaded %d artifact(s) to %s://%s/%s", len(keys), upload.Provider, upload.Bucket, runPrefix)
+
+	expiry := upload.URLExpiry
+	if expiry == 0 {
+		expiry = defaultUploadURLExpiry
+	}
+	for _, key := range keys {
+		signedURL, err := store.SignedURL(key, expiry)
+		if err != nil {
+			logger.Warningf("! Failed to sign URL for %s: %v", key, err)
+			continue
+		}
+		logger.Infof("  %s: %s", strings.TrimPrefix(key, runPrefix+"/"), signedURL)
+	}
+
+	if upload.Retention > 0 {
+		deleted, err := pruneArtifacts(ctx, store, upload.prefix()+"/", time.Now().Add(-upload.Retention))
+		if err != nil {
+			logger.Warningf("! Failed to apply artifact retention: %v", err)
+		} else if deleted > 0 {
+			logger.Infof("  Deleted %d artifact(s) older than %s", deleted, upload.Retention)
+		}
+	}
+}
+
+// runID names a run's uploads: its start time, so runs list in order, and a
+// random suffix telling apart runs started in the same second.
+func runID(startTime time.Time) string {
+	if startTime.IsZero() {
+		startTime = time.Now() // The run failed before it started
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return startTime.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
+
+// putArtifacts uploads every file below dir under prefix and returns the
+// keys uploaded, in walk order.
+func putArtifacts(ctx context.Context, store objectstore.Store, dir, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		key := path.Join(prefix, filepath.ToSlash(rel))
+		if err := store.Put(ctx, key, data, artifactContentType(p)); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", rel, err)
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	return keys, err
+}
+
+// artifactContentType returns the MIME type browsers should open the
+// artifact at path with.
+func artifactContentType(p string) string {
+	ext := filepath.Ext(p)
+	if ext == ".md" {
+		return "text/markdown; charset=utf-8"
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// pruneArtifacts deletes the objects under prefix last modified before
+// cutoff and returns how many it deleted.
+func pruneArtifacts(ctx context.Context, store objectstore.Store, prefix string, cutoff time.Time) (int, error) {
+	objects, err := store.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, object := range objects {
+		if !object.LastModified.Before(cutoff) {
+			continue
+		}
+		if err := store.Delete(ctx, object.Key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,152 @@
+package headless
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/objectstore"
+)
+
+// memoryStore is an in-memory objectstore.Store
+type memoryStore struct {
+	objects map[string]objectstore.Object
+}
+
+func (s *memoryStore) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	s.objects[key] = objectstore.Object{Key: key, LastModified: time.Now()}
+	return nil
+}
+
+func (s *memoryStore) List(ctx context.Context, prefix string) ([]objectstore.Object, error) {
+	var objects []objectstore.Object
+	for key, object := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memoryStore) SignedURL(key string, expiry time.Duration) (string, error) {
+	return "https://example.com/" + key, nil
+}
+
+func TestPutArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"execution.json", "summary.md", "screenshots/home.png"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store := &memoryStore{objects: map[string]objectstore.Object{}}
+	keys, err := putArtifacts(context.Background(), store, dir, "ci/run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"ci/run-1/execution.json", "ci/run-1/screenshots/home.png", "ci/run-1/summary.md"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+}
+
+func TestPruneArtifacts(t *testing.T) {
+	now := time.Now()
+	store := &memoryStore{objects: map[string]objectstore.Object{
+		"ci/old/execution.json": {Key: "ci/old/execution.json", LastModified: now.Add(-48 * time.Hour)},
+		"ci/new/execution.json": {Key: "ci/new/execution.json", LastModified: now},
+		"other/old.json":        {Key: "other/old.json", LastModified: now.Add(-48 * time.Hour)},
+	}}
+
+	deleted, err := pruneArtifacts(context.Background(), store, "ci/", now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted %d objects, want 1", deleted)
+	}
+	if _, ok := store.objects["ci/old/execution.json"]; ok {
+		t.Error("expected the expired run to be deleted")
+	}
+	if _, ok := store.objects["other/old.json"]; !ok {
+		t.Error("expected objects outside the prefix to be kept")
+	}
+}
+
+func TestUploadArtifacts_S3(t *testing.T) {
+	var mu sync.Mutex
+	uploaded := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") == "" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		uploaded[r.URL.Path] = string(data)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "id")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	config := DefaultConfig()
+	config.WorkspaceDir = t.TempDir()
+	config.Artifacts.Upload = ArtifactUploadConfig{Provider: objectstore.ProviderS3, Bucket: "audit", Prefix: "/forge/", Endpoint: server.URL}
+	writer := NewArtifactWriter(filepath.Join(config.WorkspaceDir, config.Artifacts.OutputDir), config.Artifacts)
+	if err := writer.WriteAll(&ExecutionSummary{Task: "test", Status: statusSuccess}); err != nil {
+		t.Fatal(err)
+	}
+
+	uploadArtifacts(config, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), NewLogger(LogLevelQuiet))
+
+	var paths []string
+	for path := range uploaded {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	if len(paths) != 3 || !strings.HasPrefix(paths[0], "/audit/forge/20240102T030405Z-") || !strings.HasSuffix(paths[0], "/execution.json") {
+		t.Errorf("uploaded = %v", paths)
+	}
+}
+
+func TestArtifactUploadConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ArtifactUploadConfig
+		wantErr bool
+	}{
+		{"disabled", ArtifactUploadConfig{}, false},
+		{"valid", ArtifactUploadConfig{Provider: "gcs", Bucket: "b", Prefix: "runs", Retention: time.Hour}, false},
+		{"unknown provider", ArtifactUploadConfig{Provider: "ftp", Bucket: "b"}, true},
+		{"no bucket", ArtifactUploadConfig{Provider: "s3"}, true},
+		{"retention without prefix", ArtifactUploadConfig{Provider: "s3", Bucket: "b", Prefix: "/", Retention: time.Hour}, true},
+		{"expiry over a week", ArtifactUploadConfig{Provider: "azure", Bucket: "b", URLExpiry: 8 * 24 * time.Hour}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
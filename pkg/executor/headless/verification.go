@@ -0,0 +1,259 @@
+package headless
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// VerificationModeNoBehaviorChange records the output of every command
+	// gate before the agent starts and requires identical output afterwards
+	VerificationModeNoBehaviorChange = "no_behavior_change"
+
+	// behaviorGateName is the name of the gate that reports behavior drift
+	behaviorGateName = "no behavior change"
+
+	// maxDriftLines caps the diff lines reported per drifted gate
+	maxDriftLines = 40
+
+	// maxDiffInputLines bounds the outputs diffed line by line; larger outputs
+	// are only reported as different
+	maxDiffInputLines = 5000
+)
+
+// defaultIgnorePatterns mask output that varies between identical runs, such
+// as test timings and Go's "(cached)" marker.
+var defaultIgnorePatterns = []string{
+	`\(cached\)`,
+	`\b\d+(\.\d+)?(ns|µs|us|ms|s|m|h)\b`,
+}
+
+// VerificationConfig configures behavior verification for pure-refactor tasks
+type VerificationConfig struct {
+	Mode           string   `yaml:"mode" json:"mode"`                       // "no_behavior_change", or empty to disable
+	IgnorePatterns []string `yaml:"ignore_patterns" json:"ignore_patterns"` // Extra regexes removed from gate output before comparison
+}
+
+// Enabled reports whether behavior verification is on.
+func (c VerificationConfig) Enabled() bool {
+	return c.Mode == VerificationModeNoBehaviorChange
+}
+
+// validate checks the verification mode and ignore patterns.
+func (c VerificationConfig) validate() error {
+	switch c.Mode {
+	case "", VerificationModeNoBehaviorChange:
+	default:
+		return fmt.Errorf("invalid verification mode: %s (must be '%s')", c.Mode, VerificationModeNoBehaviorChange)
+	}
+	for _, pattern := range c.IgnorePatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid verification ignore pattern '%s': %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// BehaviorCheck compares one gate's baseline run with its run after the
+// agent's changes.
+type BehaviorCheck struct {
+	Gate     string        `json:"gate"`
+	Baseline *GateSnapshot `json:"baseline"`
+	Current  *GateSnapshot `json:"current,omitempty"`
+	Drifted  bool          `json:"drifted"`
+	Diff     string        `json:"diff,omitempty"`
+}
+
+// BehaviorVerification is the outcome of no-behavior-change verification,
+// recorded in the execution summary.
+type BehaviorVerification struct {
+	Drifted bool            `json:"drifted"`
+	Checks  []BehaviorCheck `json:"checks"`
+}
+
+// BehaviorVerifier is a quality gate that fails when any command gate's
+// output differs from the baseline recorded before the agent started. It must
+// run after the gates it compares, so it is added last.
+type BehaviorVerifier struct {
+	gates    []SnapshotQualityGate
+	ignore   []*regexp.Regexp
+	baseline map[string]*GateSnapshot
+	checks   []BehaviorCheck
+}
+
+// NewBehaviorVerifier creates a verifier over the snapshot-capable gates.
+func NewBehaviorVerifier(gates []QualityGate, config VerificationConfig) (*BehaviorVerifier, error) {
+	v := &BehaviorVerifier{baseline: make(map[string]*GateSnapshot)}
+	for _, gate := range gates {
+		if snapshotGate, ok := gate.(SnapshotQualityGate); ok {
+			v.gates = append(v.gates, snapshotGate)
+		}
+	}
+	if len(v.gates) == 0 {
+		return nil, fmt.Errorf("%s verification requires at least one command quality gate", VerificationModeNoBehaviorChange)
+	}
+
+	for _, pattern := range append(defaultIgnorePatterns, config.IgnorePatterns...) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verification ignore pattern '%s': %w", pattern, err)
+		}
+		v.ignore = append(v.ignore, re)
+	}
+	return v, nil
+}
+
+// RecordBaseline runs every compared gate on the untouched workspace.
+// Failing gates are fine, since a refactor must keep failures identical too,
+// but a gate that cannot run leaves nothing to compare against.
+func (v *BehaviorVerifier) RecordBaseline(ctx context.Context, workspaceDir string, logger *Logger) error {
+	for _, gate := range v.gates {
+		if logger != nil {
+			logger.Infof("  → Recording baseline: %s", gate.Name())
+		}
+		err := gate.Execute(ctx, workspaceDir)
+		snapshot := gate.LastSnapshot()
+		if snapshot == nil || (err != nil && failureKind(err) == GateFailureInfrastructure) {
+			return fmt.Errorf("failed to record baseline for gate '%s': %w", gate.Name(), err)
+		}
+		v.baseline[gate.Name()] = snapshot
+	}
+	return nil
+}
+
+// Name returns the name of the quality gate
+func (v *BehaviorVerifier) Name() string {
+	return behaviorGateName
+}
+
+// Required returns true: drift always blocks a no-behavior-change run
+func (v *BehaviorVerifier) Required() bool {
+	return true
+}
+
+// Execute compares the latest run of each gate with its baseline.
+func (v *BehaviorVerifier) Execute(ctx context.Context, workspaceDir string) error {
+	v.checks = v.checks[:0]
+
+	var drifted []string
+	var details strings.Builder
+	for _, gate := range v.gates {
+		current := gate.LastSnapshot()
+		if current == nil {
+			_ = gate.Execute(ctx, workspaceDir)
+			current = gate.LastSnapshot()
+		}
+
+		check := v.compare(gate.Name(), v.baseline[gate.Name()], current)
+		v.checks = append(v.checks, check)
+		if check.Drifted {
+			drifted = append(drifted, gate.Name())
+			fmt.Fprintf(&details, "%s:\n%s\n", gate.Name(), check.Diff)
+		}
+	}
+
+	if len(drifted) == 0 {
+		return nil
+	}
+	return &QualityGateError{
+		GateName: behaviorGateName,
+		Output:   strings.TrimSuffix(details.String(), "\n"),
+		Err:      fmt.Errorf("output changed from the baseline for %s; the task must not change behavior", strings.Join(drifted, ", ")),
+		Kind:     GateFailureAssertion,
+	}
+}
+
+// Report returns the comparison from the most recent verification, or nil if
+// it has not run.
+func (v *BehaviorVerifier) Report() *BehaviorVerification {
+	if len(v.checks) == 0 {
+		return nil
+	}
+	report := &BehaviorVerification{Checks: append([]BehaviorCheck(nil), v.checks...)}
+	for _, check := range report.Checks {
+		report.Drifted = report.Drifted || check.Drifted
+	}
+	return report
+}
+
+// compare reports whether current differs from baseline after normalization.
+func (v *BehaviorVerifier) compare(name string, baseline, current *GateSnapshot) BehaviorCheck {
+	check := BehaviorCheck{Gate: name, Baseline: baseline, Current: current}
+	if baseline == nil || current == nil {
+		check.Drifted = true
+		check.Diff = "gate did not produce a result"
+		return check
+	}
+
+	if baseline.ExitCode != current.ExitCode {
+		check.Drifted = true
+		check.Diff = fmt.Sprintf("exit code changed from %d to %d\n", baseline.ExitCode, current.ExitCode)
+	}
+	before, after := v.normalize(baseline.Output), v.normalize(current.Output)
+	if before != after {
+		check.Drifted = true
+		check.Diff += diffLines(before, after)
+	}
+	check.Diff = strings.TrimSuffix(check.Diff, "\n")
+	return check
+}
+
+// normalize removes volatile output and trailing whitespace so only
+// meaningful differences remain.
+func (v *BehaviorVerifier) normalize(output string) string {
+	for _, re := range v.ignore {
+		output = re.ReplaceAllString(output, "")
+	}
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// diffLines returns the lines removed from (-) and added to (+) before,
+// capped at maxDriftLines.
+func diffLines(before, after string) string {
+	a, b := strings.Split(before, "\n"), strings.Split(after, "\n")
+	if len(a) > maxDiffInputLines || len(b) > maxDiffInputLines {
+		return fmt.Sprintf("output differs (%d lines before, %d after)\n", len(a), len(b))
+	}
+
+	// Longest common subsequence table, filled from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+
+	if len(out) > maxDriftLines {
+		out = append(out[:maxDriftLines], fmt.Sprintf("... %d more changed lines", len(out)-maxDriftLines))
+	}
+	return strings.Join(out, "\n") + "\n"
+}
@@ -0,0 +1,164 @@
+package headless
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestVerifier(t *testing.T, config VerificationConfig, gates ...QualityGate) *BehaviorVerifier {
+	t.Helper()
+	config.Mode = VerificationModeNoBehaviorChange
+	verifier, err := NewBehaviorVerifier(gates, config)
+	if err != nil {
+		t.Fatalf("NewBehaviorVerifier: %v", err)
+	}
+	return verifier
+}
+
+// runGates runs the gates and then the verifier, as QualityGateRunner would.
+func runGates(t *testing.T, dir string, verifier *BehaviorVerifier, gates ...QualityGate) error {
+	t.Helper()
+	for _, gate := range gates {
+		_ = gate.Execute(context.Background(), dir)
+	}
+	return verifier.Execute(context.Background(), dir)
+}
+
+func writeOutput(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "out.txt"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBehaviorVerifier_IdenticalOutputPasses(t *testing.T) {
+	dir := t.TempDir()
+	writeOutput(t, dir, "--- PASS: TestParse (0.01s)\nok  \texample.com/pkg\t0.123s\n")
+	gate := NewCommandQualityGate("tests", "cat out.txt", true)
+	verifier := newTestVerifier(t, VerificationConfig{}, gate)
+
+	if err := verifier.RecordBaseline(context.Background(), dir, nil); err != nil {
+		t.Fatalf("RecordBaseline: %v", err)
+	}
+
+	// Timings and caching differ between runs without a behavior change
+	writeOutput(t, dir, "--- PASS: TestParse (0.35s)\nok  \texample.com/pkg\t(cached)\n")
+	if err := runGates(t, dir, verifier, gate); err != nil {
+		t.Errorf("expected no drift, got %v", err)
+	}
+
+	report := verifier.Report()
+	if report == nil || report.Drifted || len(report.Checks) != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestBehaviorVerifier_OutputDrift(t *testing.T) {
+	dir := t.TempDir()
+	writeOutput(t, dir, "result: 42\nlines: 3\n")
+	gate := NewCommandQualityGate("golden", "cat out.txt", true)
+	verifier := newTestVerifier(t, VerificationConfig{}, gate)
+
+	if err := verifier.RecordBaseline(context.Background(), dir, nil); err != nil {
+		t.Fatalf("RecordBaseline: %v", err)
+	}
+
+	writeOutput(t, dir, "result: 41\nlines: 3\n")
+	err := runGates(t, dir, verifier, gate)
+
+	var gateErr *QualityGateError
+	if !errors.As(err, &gateErr) || gateErr.Kind != GateFailureAssertion {
+		t.Fatalf("expected an assertion failure, got %v", err)
+	}
+	if !strings.Contains(gateErr.Output, "- result: 42\n+ result: 41") {
+		t.Errorf("expected a line diff, got:\n%s", gateErr.Output)
+	}
+
+	report := verifier.Report()
+	if report == nil || !report.Drifted || !report.Checks[0].Drifted {
+		t.Errorf("expected drift in report, got %+v", report)
+	}
+}
+
+func TestBehaviorVerifier_ExitCodeDrift(t *testing.T) {
+	dir := t.TempDir()
+	writeOutput(t, dir, "same\n")
+	gate := NewCommandQualityGate("check", "cat out.txt", true)
+	verifier := newTestVerifier(t, VerificationConfig{}, gate)
+
+	if err := verifier.RecordBaseline(context.Background(), dir, nil); err != nil {
+		t.Fatalf("RecordBaseline: %v", err)
+	}
+
+	// A gate that passed before must not start failing, even with no output
+	if err := os.Remove(filepath.Join(dir, "out.txt")); err != nil {
+		t.Fatal(err)
+	}
+	err := runGates(t, dir, verifier, gate)
+	if err == nil || !strings.Contains(err.Error(), "exit code changed from 0 to 1") {
+		t.Errorf("expected exit code drift, got %v", err)
+	}
+}
+
+func TestBehaviorVerifier_IgnorePatterns(t *testing.T) {
+	dir := t.TempDir()
+	writeOutput(t, dir, "build id abc123\nok\n")
+	gate := NewCommandQualityGate("build", "cat out.txt", true)
+	verifier := newTestVerifier(t, VerificationConfig{IgnorePatterns: []string{`build id \w+`}}, gate)
+
+	if err := verifier.RecordBaseline(context.Background(), dir, nil); err != nil {
+		t.Fatalf("RecordBaseline: %v", err)
+	}
+
+	writeOutput(t, dir, "build id def456\nok\n")
+	if err := runGates(t, dir, verifier, gate); err != nil {
+		t.Errorf("expected ignored output to match, got %v", err)
+	}
+}
+
+func TestBehaviorVerifier_BaselineInfrastructureFailure(t *testing.T) {
+	gate := NewCommandQualityGate("tests", "forge-no-such-command", true)
+	verifier := newTestVerifier(t, VerificationConfig{}, gate)
+
+	if err := verifier.RecordBaseline(context.Background(), t.TempDir(), nil); err == nil {
+		t.Error("expected an error when a gate cannot run for the baseline")
+	}
+}
+
+func TestNewBehaviorVerifier_RequiresCommandGate(t *testing.T) {
+	lsp := NewLSPDiagnosticsGate("diagnostics", "", true, 0, nil, nil, nil)
+	if _, err := NewBehaviorVerifier([]QualityGate{lsp}, VerificationConfig{Mode: VerificationModeNoBehaviorChange}); err == nil {
+		t.Error("expected an error without command gates to compare")
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc", "a\nc\nd")
+	want := "- b\n+ d\n"
+	if got != want {
+		t.Errorf("diffLines = %q, want %q", got, want)
+	}
+}
+
+func TestConfig_ValidateVerification(t *testing.T) {
+	config := &Config{Task: "test", Mode: ModeWrite, WorkspaceDir: "/tmp/test"}
+
+	config.Verification = VerificationConfig{Mode: VerificationModeNoBehaviorChange, IgnorePatterns: []string{`\d+ms`}}
+	if err := config.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	config.Verification = VerificationConfig{Mode: "strict"}
+	if err := config.Validate(); err == nil {
+		t.Error("expected error for invalid verification mode")
+	}
+
+	config.Verification = VerificationConfig{Mode: VerificationModeNoBehaviorChange, IgnorePatterns: []string{"("}}
+	if err := config.Validate(); err == nil {
+		t.Error("expected error for invalid ignore pattern")
+	}
+}
@@ -0,0 +1,114 @@
+package headless
+
+import (
+	"sync"
+	"time"
+)
+
+// stallRetryMessage asks the agent to carry on after its stalled turn was
+// canceled
+const stallRetryMessage = "Your previous turn stalled without any progress and was canceled. Continue working on the task from where you left off."
+
+// stallWatchdog detects an execution that stopped making progress, such as a
+// hung model request or a deadlocked tool. Every agent event is a heartbeat
+// that restarts the countdown; C fires once the timeout passes without one.
+type stallWatchdog struct {
+	timeout time.Duration
+	retries int
+	timer   *time.Timer
+
+	mu          sync.Mutex
+	paused      bool
+	stalls      int
+	interrupted bool // A stalled turn was canceled and has not ended yet
+}
+
+// newStallWatchdog creates a watchdog that lets up to retries stalled turns
+// be retried. A zero timeout disables it.
+func newStallWatchdog(timeout time.Duration, retries int) *stallWatchdog {
+	w := &stallWatchdog{timeout: timeout, retries: retries}
+	if timeout > 0 {
+		w.timer = time.NewTimer(timeout)
+	}
+	return w
+}
+
+// C returns the channel that fires on a stall, or nil when the watchdog is
+// disabled.
+func (w *stallWatchdog) C() <-chan time.Time {
+	if w.timer == nil {
+		return nil
+	}
+	return w.timer.C
+}
+
+// heartbeat restarts the countdown.
+func (w *stallWatchdog) heartbeat() {
+	if w.timer == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.paused {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+// pause stops the countdown while the executor itself is busy, for example
+// running quality gates, so the agent waiting on it is not taken for stalled.
+func (w *stallWatchdog) pause() {
+	if w.timer == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = true
+	w.timer.Stop()
+}
+
+// resume restarts the countdown after pause.
+func (w *stallWatchdog) resume() {
+	if w.timer == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = false
+	w.timer.Reset(w.timeout)
+}
+
+// stall records a stall whose turn is about to be canceled and reports
+// whether the turn may be retried. The countdown restarts, so a turn that
+// does not end after the cancellation is detected as stalled again.
+func (w *stallWatchdog) stall() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stalls++
+	w.interrupted = true
+	w.timer.Reset(w.timeout)
+	return w.stalls <= w.retries
+}
+
+// stallCount returns the number of stalls detected so far.
+func (w *stallWatchdog) stallCount() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stalls
+}
+
+// turnEnded reports whether the turn that just ended was canceled because it
+// stalled, and if so whether it is to be retried.
+func (w *stallWatchdog) turnEnded() (interrupted, retry bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	interrupted = w.interrupted
+	w.interrupted = false
+	return interrupted, interrupted && w.stalls <= w.retries
+}
+
+// stop releases the timer.
+func (w *stallWatchdog) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
@@ -0,0 +1,157 @@
+package headless
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// stallingAgent hangs on its first input until the turn is canceled, then
+// completes any later input right away. It stops on shutdown.
+type stallingAgent struct {
+	channels *types.AgentChannels
+	inputs   chan *types.Input
+}
+
+func newStallingAgent() *stallingAgent {
+	return &stallingAgent{channels: types.NewAgentChannels(10), inputs: make(chan *types.Input, 10)}
+}
+
+func (a *stallingAgent) Start(ctx context.Context) error {
+	go func() {
+		defer a.channels.Close()
+		turns := 0
+		for {
+			select {
+			case input := <-a.channels.Input:
+				a.inputs <- input
+				switch {
+				case input.IsCancel():
+					a.channels.Event <- types.NewTurnEndEvent()
+				case turns > 0:
+					a.channels.Event <- types.NewTurnEndEvent()
+				}
+				if !input.IsCancel() {
+					turns++
+				}
+			case <-a.channels.Shutdown:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (a *stallingAgent) Shutdown(ctx context.Context) error      { return nil }
+func (a *stallingAgent) GetChannels() *types.AgentChannels       { return a.channels }
+func (a *stallingAgent) GetTool(name string) any                 { return nil }
+func (a *stallingAgent) GetTools() []any                         { return nil }
+func (a *stallingAgent) GetContextInfo() *agent.ContextInfo      { return nil }
+func (a *stallingAgent) GetMessages() []*types.Message           { return nil }
+func (a *stallingAgent) GetSystemPrompt() string                 { return "" }
+func (a *stallingAgent) SetProvider(provider llm.Provider) error { return nil }
+
+// received returns the inputs the agent has read so far
+func (a *stallingAgent) received() []*types.Input {
+	var inputs []*types.Input
+	for {
+		select {
+		case input := <-a.inputs:
+			inputs = append(inputs, input)
+		default:
+			return inputs
+		}
+	}
+}
+
+func stallTestConfig(t *testing.T) *Config {
+	config := DefaultConfig()
+	config.Task = "Do something"
+	config.WorkspaceDir = t.TempDir()
+	config.Logging.Verbosity = "quiet"
+	config.Artifacts.Enabled = false
+	config.Constraints.Timeout = 10 * time.Second
+	config.Constraints.StallTimeout = 50 * time.Millisecond
+	return config
+}
+
+func TestExecutor_StallFailsExecution(t *testing.T) {
+	ag := newStallingAgent()
+	executor, err := NewExecutor(ag, stallTestConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := executor.Run(context.Background()); err == nil {
+		t.Fatal("expected a stalled execution to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("stall took %s to detect, want well under the execution timeout", elapsed)
+	}
+	if executor.summary.Status != statusStalled {
+		t.Errorf("expected status %q, got %q (%s)", statusStalled, executor.summary.Status, executor.summary.Error)
+	}
+
+	inputs := ag.received()
+	if len(inputs) != 2 || !inputs[1].IsCancel() {
+		t.Errorf("expected the task and a cancellation, got %+v", inputs)
+	}
+}
+
+func TestExecutor_StallRetriesOnce(t *testing.T) {
+	config := stallTestConfig(t)
+	config.Constraints.RetryOnStall = true
+	ag := newStallingAgent()
+	executor, err := NewExecutor(ag, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := executor.Run(context.Background()); err != nil {
+		t.Fatalf("expected the retried turn to succeed, got %v", err)
+	}
+	if executor.summary.Status != statusSuccess {
+		t.Errorf("expected status %q, got %q", statusSuccess, executor.summary.Status)
+	}
+
+	inputs := ag.received()
+	if len(inputs) != 3 || !inputs[1].IsCancel() || inputs[2].Content != stallRetryMessage {
+		t.Errorf("expected the task, a cancellation and the retry, got %+v", inputs)
+	}
+}
+
+func TestStallWatchdog_Pause(t *testing.T) {
+	w := newStallWatchdog(20*time.Millisecond, 0)
+	defer w.stop()
+
+	w.pause()
+	select {
+	case <-w.C():
+		t.Fatal("watchdog fired while paused")
+	case <-time.After(60 * time.Millisecond):
+	}
+
+	w.resume()
+	select {
+	case <-w.C():
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not fire after resuming")
+	}
+}
+
+func TestStallWatchdog_Disabled(t *testing.T) {
+	w := newStallWatchdog(0, 0)
+	w.heartbeat()
+	w.pause()
+	w.resume()
+	if w.C() != nil {
+		t.Error("expected a disabled watchdog to have no channel")
+	}
+}
@@ -0,0 +1,98 @@
+package headless
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RunWorktree is the throwaway git worktree a run with git.use_worktree
+// works in. It is a detached checkout of the workspace's HEAD, so the run's
+// edits, quality gates and commits leave the primary checkout untouched,
+// which keeps runners shared across jobs safe.
+type RunWorktree struct {
+	root        string      // Temporary directory holding the worktree
+	dir         string      // Worktree checkout
+	primaryGit  *GitManager // Repository of the primary checkout
+	worktreeGit *GitManager // Worktree, ignoring the paths Forge generates
+}
+
+// EnterWorktree creates a detached worktree of the workspace's HEAD and
+// points config at it: the workspace moves into the worktree, while the
+// artifacts directory stays in the primary checkout where CI collects it.
+// Pull requests target the branch the primary checkout is on unless pr_base
+// is set. Call Close once the run is done.
+func EnterWorktree(ctx context.Context, config *Config) (*RunWorktree, error) {
+	primaryGit := NewGitManager(config.WorkspaceDir, config.Git, "")
+	head, err := primaryGit.HeadCommit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("use_worktree requires a git repository with a commit: %w", err)
+	}
+	prefix, err := primaryGit.RepoPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	base, err := primaryGit.GetCurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Git refuses to check out a branch in two worktrees at once
+	if config.Git.Branch != "" && config.Git.Branch == base {
+		return nil, fmt.Errorf("use_worktree cannot commit to %s, which the primary checkout is on; set git.branch to a different branch", base)
+	}
+
+	root, err := os.MkdirTemp("", "forge-run-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	dir := filepath.Join(root, "worktree")
+	if err := primaryGit.AddWorktree(ctx, dir, "", head); err != nil {
+		_ = os.Remove(root)
+		return nil, err
+	}
+
+	// Resolve the artifacts, knowledge base and cancel file against the primary checkout
+	// before the workspace moves
+	config.Artifacts.OutputDir = config.ArtifactDir()
+	config.Knowledge.Dir = config.knowledgeDir()
+	config.Cancel.File = config.cancelFile()
+	config.WorkspaceDir = filepath.Join(dir, filepath.FromSlash(prefix))
+	if config.Git.PRBase == "" {
+		config.Git.PRBase = base
+	}
+
+	worktreeGit := NewGitManager(config.WorkspaceDir, config.Git, "")
+	worktreeGit.ExcludeFromCommits(generatedPaths(config)...)
+	return &RunWorktree{
+		root:        root,
+		dir:         dir,
+		primaryGit:  primaryGit,
+		worktreeGit: worktreeGit,
+	}, nil
+}
+
+// Dir returns the worktree's checkout directory.
+func (w *RunWorktree) Dir() string {
+	return w.dir
+}
+
+// Close removes the worktree. A worktree holding uncommitted changes is kept
+// so no work is lost; Close reports whether it was kept. The run's branch,
+// and any commits pushed from it, outlive the worktree.
+func (w *RunWorktree) Close() (bool, error) {
+	// Clean up even when the run was cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	dirty, err := w.worktreeGit.HasUncommittedChanges(ctx)
+	if err != nil || dirty {
+		return true, err
+	}
+	if err := w.primaryGit.RemoveWorktree(ctx, w.dir); err != nil {
+		return true, err
+	}
+	_ = os.Remove(w.root)
+	return false, nil
+}
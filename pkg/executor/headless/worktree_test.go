@@ -0,0 +1,156 @@
+package headless
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// worktreeTestConfig returns a config for a use_worktree run on
+// forge/worktree in the repository at dir
+func worktreeTestConfig(dir string) *Config {
+	config := DefaultConfig()
+	config.Task = "Write change.txt"
+	config.WorkspaceDir = dir
+	config.Logging.Verbosity = "quiet"
+	config.Git.UseWorktree = true
+	config.Git.AutoCommit = true
+	config.Git.Branch = "forge/worktree"
+	config.Git.CommitMessage = "chore: worktree change"
+	return config
+}
+
+// runInWorktree enters the worktree and runs an agent that writes file into
+// the workspace it is given
+func runInWorktree(t *testing.T, config *Config, file string) *RunWorktree {
+	t.Helper()
+	worktree, err := EnterWorktree(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ag := newScriptedAgent(func() error {
+		return os.WriteFile(filepath.Join(config.WorkspaceDir, file), []byte("change"), 0o644)
+	})
+	executor, err := NewExecutor(ag, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return worktree
+}
+
+func TestRunWorktree_LeavesPrimaryCheckoutUntouched(t *testing.T) {
+	dir := setupFanOutRepo(t)
+	config := worktreeTestConfig(dir)
+
+	worktree := runInWorktree(t, config, "change.txt")
+	if !strings.HasPrefix(config.WorkspaceDir, worktree.Dir()) {
+		t.Fatalf("expected the workspace to move into the worktree, got %s", config.WorkspaceDir)
+	}
+	if config.Git.PRBase != "main" {
+		t.Errorf("expected pull requests to target main, got %q", config.Git.PRBase)
+	}
+
+	kept, err := worktree.Close()
+	if err != nil || kept {
+		t.Fatalf("expected the worktree to be removed, kept=%v err=%v", kept, err)
+	}
+	if _, err := os.Stat(worktree.Dir()); !os.IsNotExist(err) {
+		t.Errorf("expected the worktree directory to be gone: %v", err)
+	}
+	if worktrees := gitOutput(t, dir, "worktree", "list"); strings.Contains(worktrees, "\n") {
+		t.Errorf("expected only the primary checkout, got:\n%s", worktrees)
+	}
+
+	// The change was committed on the run's branch, not in the primary checkout
+	if files := gitOutput(t, dir, "show", "--name-only", "--format=", "forge/worktree"); files != "change.txt" {
+		t.Errorf("expected forge/worktree to hold the change, got %q", files)
+	}
+	if branch := gitOutput(t, dir, "branch", "--show-current"); branch != "main" {
+		t.Errorf("expected the primary checkout to stay on main, got %s", branch)
+	}
+	if status := gitOutput(t, dir, "status", "--porcelain", "--", ".", ":(exclude)"+DefaultConfig().Artifacts.OutputDir); status != "" {
+		t.Errorf("expected the primary checkout to be clean, got:\n%s", status)
+	}
+
+	// Artifacts are written to the primary checkout
+	if _, err := os.Stat(filepath.Join(dir, DefaultConfig().Artifacts.OutputDir, "execution.json")); err != nil {
+		t.Errorf("expected artifacts in the primary checkout: %v", err)
+	}
+}
+
+func TestRunWorktree_KeepsUncommittedChanges(t *testing.T) {
+	dir := setupFanOutRepo(t)
+	config := worktreeTestConfig(dir)
+	config.Git.AutoCommit = false
+	config.Git.Branch = ""
+
+	worktree := runInWorktree(t, config, "change.txt")
+	t.Cleanup(func() { os.RemoveAll(filepath.Dir(worktree.Dir())) })
+
+	kept, err := worktree.Close()
+	if err != nil || !kept {
+		t.Fatalf("expected the worktree to be kept, kept=%v err=%v", kept, err)
+	}
+	if _, err := os.Stat(filepath.Join(worktree.Dir(), "change.txt")); err != nil {
+		t.Errorf("expected the kept worktree to hold the change: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "change.txt")); !os.IsNotExist(err) {
+		t.Error("expected the primary checkout to be untouched")
+	}
+}
+
+func TestEnterWorktree_RejectsCheckedOutBranch(t *testing.T) {
+	dir := setupFanOutRepo(t)
+	config := worktreeTestConfig(dir)
+	config.Git.Branch = "main"
+
+	if _, err := EnterWorktree(context.Background(), config); err == nil || !strings.Contains(err.Error(), "primary checkout is on") {
+		t.Fatalf("expected the primary checkout's branch to be rejected, got %v", err)
+	}
+	if config.WorkspaceDir != dir {
+		t.Errorf("expected the workspace to stay put, got %s", config.WorkspaceDir)
+	}
+}
+
+func TestConfig_ValidateUseWorktree(t *testing.T) {
+	config := worktreeTestConfig(t.TempDir())
+	config.Git.Branch = ""
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "requires a branch") {
+		t.Errorf("expected auto_commit without a branch to be rejected, got %v", err)
+	}
+
+	config = matrixTestConfig(t.TempDir(), "docs")
+	config.Git.UseWorktree = true
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "cannot be combined with tasks") {
+		t.Errorf("expected use_worktree with tasks to be rejected, got %v", err)
+	}
+}
+
+func TestConfig_ValidateRunHistory(t *testing.T) {
+	config := worktreeTestConfig(t.TempDir())
+	config.Git.SquashCommits = true
+	if err := config.Validate(); err != nil {
+		t.Errorf("expected squash_commits to be valid, got %v", err)
+	}
+
+	config.Git.AutoCommit = false
+	config.Git.CreatePR = false
+	config.Git.UseWorktree = false
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "require auto_commit") {
+		t.Errorf("expected squash_commits without auto_commit to be rejected, got %v", err)
+	}
+
+	config = worktreeTestConfig(t.TempDir())
+	config.Git.CheckpointCommits = true
+	config.Git.CreatePR = true
+	config.Git.StackMaxLines = 200
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "stack_max_lines") {
+		t.Errorf("expected checkpoint_commits with stack_max_lines to be rejected, got %v", err)
+	}
+}
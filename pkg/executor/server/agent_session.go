@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Errors returned by AgentSession when the agent cannot take an input.
+var (
+	ErrSessionBusy   = errors.New("session is busy processing a message")
+	ErrSessionClosed = errors.New("session is closed")
+	ErrInputFull     = errors.New("session input queue is full")
+)
+
+// AgentSession runs one agent on behalf of a remote client. It queues the
+// client's messages, cancellations and approval decisions, drains the agent's
+// events and shuts the agent down, and is shared by the HTTP server and the
+// ACP transport, which add their own event delivery on top.
+//
+// The agent closes its channels when it stops, so it must only be stopped
+// through Shutdown: the session is marked closed first, and no input is sent
+// after that. The context the agent was started with should therefore only be
+// canceled by the cancel function passed to NewAgentSession.
+type AgentSession struct {
+	id        string
+	createdAt time.Time
+	agent     agent.Agent
+	cancel    context.CancelFunc
+
+	mu     sync.Mutex
+	busy   bool
+	closed bool
+	done   chan struct{}
+
+	shutdownOnce sync.Once
+}
+
+// NewAgentSession wraps a started agent. cancel cancels the context the agent
+// was started with and is called by Shutdown.
+func NewAgentSession(id string, ag agent.Agent, cancel context.CancelFunc) *AgentSession {
+	return &AgentSession{
+		id:        id,
+		createdAt: time.Now(),
+		agent:     ag,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+}
+
+// ID returns the session's ID.
+func (s *AgentSession) ID() string {
+	return s.id
+}
+
+// CreatedAt returns when the session was created.
+func (s *AgentSession) CreatedAt() time.Time {
+	return s.createdAt
+}
+
+// Run passes each of the agent's events to handle until the agent stops, then
+// marks the session closed and calls finish, if set, before Shutdown returns.
+// Transports run it in its own goroutine.
+func (s *AgentSession) Run(handle func(*types.AgentEvent), finish func()) {
+	defer close(s.done)
+	for event := range s.agent.GetChannels().Event {
+		handle(event)
+	}
+
+	s.mu.Lock()
+	s.closed = true
+	s.busy = false
+	s.mu.Unlock()
+
+	if finish != nil {
+		finish()
+	}
+}
+
+// Send queues a user message. Only one turn may run at a time because the
+// agent processes inputs concurrently; the session stays busy until EndTurn.
+func (s *AgentSession) Send(content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSessionClosed
+	}
+	if s.busy {
+		return ErrSessionBusy
+	}
+
+	select {
+	case s.agent.GetChannels().Input <- types.NewUserInput(content):
+		s.busy = true
+		return nil
+	default:
+		return ErrInputFull
+	}
+}
+
+// EndTurn marks the running turn as finished so the next message is accepted.
+func (s *AgentSession) EndTurn() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.busy = false
+}
+
+// Busy reports whether a turn is running.
+func (s *AgentSession) Busy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.busy
+}
+
+// CancelTurn asks the agent to stop the running turn. It does nothing when the
+// session is idle.
+func (s *AgentSession) CancelTurn() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSessionClosed
+	}
+	if !s.busy {
+		return nil
+	}
+	select {
+	case s.agent.GetChannels().Input <- types.NewCancelInput():
+		return nil
+	default:
+		return ErrInputFull
+	}
+}
+
+// RespondApproval sends an approval decision to the agent.
+func (s *AgentSession) RespondApproval(approvalID string, approved bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Shutdown marks the session closed under the lock before the agent can
+	// close its approval channel, so the send below cannot hit a closed channel
+	if s.closed {
+		return ErrSessionClosed
+	}
+
+	decision := types.ApprovalRejected
+	if approved {
+		decision = types.ApprovalGranted
+	}
+	select {
+	case s.agent.GetChannels().Approval <- types.NewApprovalResponse(approvalID, decision):
+		return nil
+	default:
+		return ErrInputFull
+	}
+}
+
+// Shutdown stops the agent and waits for Run to finish.
+func (s *AgentSession) Shutdown(ctx context.Context) error {
+	var err error
+	s.shutdownOnce.Do(func() {
+		// Refuse further input before the agent closes its channels
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+
+		err = s.agent.Shutdown(ctx)
+		s.cancel()
+	})
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	return err
+}
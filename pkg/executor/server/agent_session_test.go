@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestAgentSession_SendWhileBusy(t *testing.T) {
+	ag := newEchoAgent()
+	ag.release = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := ag.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	sess := NewAgentSession("s1", ag, cancel)
+	turnEnded := make(chan struct{}, 1)
+	go sess.Run(func(event *types.AgentEvent) {
+		if event.Type == types.EventTypeTurnEnd {
+			sess.EndTurn()
+			turnEnded <- struct{}{}
+		}
+	}, nil)
+	defer sess.Shutdown(context.Background())
+
+	if err := sess.Send("one"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if err := sess.Send("two"); !errors.Is(err, ErrSessionBusy) {
+		t.Errorf("expected ErrSessionBusy, got %v", err)
+	}
+	close(ag.release)
+	<-turnEnded
+	if sess.Busy() {
+		t.Error("expected the session to be idle after the turn ends")
+	}
+}
+
+func TestAgentSession_RespondAfterShutdown(t *testing.T) {
+	ag := newEchoAgent()
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := ag.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	sess := NewAgentSession("s1", ag, cancel)
+	go sess.Run(func(*types.AgentEvent) {}, nil)
+
+	if err := sess.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if err := sess.RespondApproval("abc", true); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("expected ErrSessionClosed after shutdown, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+// Package server implements an HTTP executor that exposes Forge agents as an API.
+//
+// Each session owns its own agent, created on demand by a SessionFactory, so web
+// frontends and editor plugins drive exactly the same agent core as the TUI and
+// headless executors. Agent events are streamed to clients with Server-Sent Events.
+//
+// Endpoints:
+//
+//	POST   /v1/sessions                                Create a session
+//	GET    /v1/sessions                                List sessions
+//	DELETE /v1/sessions/{id}                           Shut down a session
+//	POST   /v1/sessions/{id}/messages                  Send {"content": "..."}
+//	POST   /v1/sessions/{id}/cancel                    Cancel the current turn
+//	POST   /v1/sessions/{id}/approvals/{approval_id}   Respond with {"approved": true}
+//	GET    /v1/sessions/{id}/events                    Stream events (text/event-stream)
+//	GET    /healthz                                    Liveness check
+//
+// Every SSE message carries the event's sequence number as its id, so a client
+// that reconnects with a Last-Event-ID header receives the events it missed
+// (up to the session's replay buffer).
+//
+// When a token is configured, requests must send "Authorization: Bearer <token>".
+// Browsers' EventSource cannot set headers, so the token is also accepted as a
+// "token" query parameter. forge serve always configures a token, generating
+// one if the user sets none.
+package server
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"time"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Event is the wire representation of a types.AgentEvent.
+type Event struct {
+	Seq  int64                `json:"seq"`
+	Time time.Time            `json:"time"`
+	Type types.AgentEventType `json:"type"`
+
+	Content    string         `json:"content,omitempty"`
+	ToolName   string         `json:"tool_name,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+	ToolInput  map[string]any `json:"tool_input,omitempty"`
+	ToolOutput any            `json:"tool_output,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Busy       *bool          `json:"busy,omitempty"`
+	ApprovalID string         `json:"approval_id,omitempty"`
+	Preview    any            `json:"preview,omitempty"`
+	Metadata   map[string]any `json:"metadata,omitempty"`
+
+	TokenUsage           *types.TokenUsage           `json:"token_usage,omitempty"`
+	CommandExecution     *types.CommandExecution     `json:"command_execution,omitempty"`
+	ContextSummarization *types.ContextSummarization `json:"context_summarization,omitempty"`
+	APICallInfo          *types.APICallInfo          `json:"api_call_info,omitempty"`
+	NotesData            *types.NotesData            `json:"notes_data,omitempty"`
+	OversizedMessage     *types.OversizedMessage     `json:"oversized_message,omitempty"`
+	ModelFallback        *types.ModelFallback        `json:"model_fallback,omitempty"`
+	TurnBudget           *types.TurnBudget           `json:"turn_budget,omitempty"`
+}
+
+// NewEvent converts an agent event to its wire form.
+func NewEvent(seq int64, event *types.AgentEvent) Event {
+	wire := Event{
+		Seq:                  seq,
+		Time:                 time.Now(),
+		Type:                 event.Type,
+		Content:              event.Content,
+		ToolName:             event.ToolName,
+		ToolCallID:           event.ToolCallID,
+		ToolInput:            event.ToolInput,
+		ToolOutput:           event.ToolOutput,
+		ApprovalID:           event.ApprovalID,
+		Preview:              event.Preview,
+		Metadata:             event.Metadata,
+		TokenUsage:           event.TokenUsage,
+		CommandExecution:     event.CommandExecution,
+		ContextSummarization: event.ContextSummarization,
+		APICallInfo:          event.APICallInfo,
+		NotesData:            event.NotesData,
+		OversizedMessage:     event.OversizedMessage,
+		ModelFallback:        event.ModelFallback,
+		TurnBudget:           event.TurnBudget,
+	}
+	if event.Error != nil {
+		wire.Error = event.Error.Error()
+	}
+	if event.Type == types.EventTypeUpdateBusy {
+		busy := event.IsBusy
+		wire.Busy = &busy
+	}
+	return wire
+}
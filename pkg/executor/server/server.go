@@ -0,0 +1,464 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxSessions  = 16
+	defaultEventHistory = 1000
+	shutdownTimeout     = 10 * time.Second
+
+	// maxRequestBody bounds JSON request bodies. Oversized messages are handled
+	// by the agent's own per-message guard; this only stops runaway uploads.
+	maxRequestBody = 8 << 20
+)
+
+// Server is an executor that serves agent sessions over HTTP with SSE event streams.
+type Server struct {
+	addr        string
+	factory     SessionFactory
+	token       string
+	maxSessions int
+	maxHistory  int
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	starting int // Sessions being created, which count against maxSessions
+	baseCtx  context.Context
+	httpSrv  *http.Server
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithToken requires clients to authenticate with the given bearer token.
+// An empty token disables authentication.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithMaxSessions limits the number of concurrent sessions (default 16).
+func WithMaxSessions(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.maxSessions = n
+		}
+	}
+}
+
+// WithEventHistory sets how many events each session keeps for replay to
+// reconnecting clients (default 1000).
+func WithEventHistory(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.maxHistory = n
+		}
+	}
+}
+
+// NewServer creates a server that listens on addr and creates one agent per
+// session using factory.
+func NewServer(addr string, factory SessionFactory, opts ...Option) *Server {
+	s := &Server{
+		addr:        addr,
+		factory:     factory,
+		maxSessions: defaultMaxSessions,
+		maxHistory:  defaultEventHistory,
+		sessions:    make(map[string]*session),
+		baseCtx:     context.Background(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run listens on the configured address and serves requests until ctx is
+// canceled, then shuts down every session.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves requests on listener until ctx is canceled.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	s.mu.Lock()
+	s.baseCtx = ctx
+	s.httpSrv = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	httpSrv := s.httpSrv
+	s.mu.Unlock()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpSrv.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		stopCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return s.Stop(stopCtx)
+	}
+}
+
+// Stop shuts down the HTTP server and every session.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	httpSrv := s.httpSrv
+	sessions := make([]*session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.sessions = make(map[string]*session)
+	s.mu.Unlock()
+
+	// Shut sessions down first so open event streams end and the HTTP
+	// server is not left waiting on them
+	var errs []error
+	for _, sess := range sessions {
+		if err := sess.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("session %s: %w", sess.ID(), err))
+		}
+	}
+
+	if httpSrv != nil {
+		if err := httpSrv.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Handler returns the HTTP handler serving the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("POST /v1/sessions", s.handleCreateSession)
+	mux.HandleFunc("GET /v1/sessions", s.handleListSessions)
+	mux.HandleFunc("DELETE /v1/sessions/{id}", s.handleDeleteSession)
+	mux.HandleFunc("POST /v1/sessions/{id}/messages", s.handleSendMessage)
+	mux.HandleFunc("POST /v1/sessions/{id}/cancel", s.handleCancel)
+	mux.HandleFunc("POST /v1/sessions/{id}/approvals/{approval_id}", s.handleApproval)
+	mux.HandleFunc("GET /v1/sessions/{id}/events", s.handleEvents)
+	return s.authenticate(mux)
+}
+
+// authenticate enforces the bearer token on every route except the health check.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" || r.URL.Path == "/healthz" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if provided == "" {
+			provided = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sessionInfo is the wire representation of a session.
+type sessionInfo struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Busy      bool      `json:"busy"`
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
+	// Reserve a slot up front so concurrent requests cannot overshoot the
+	// limit while their agents are being built
+	s.mu.Lock()
+	if len(s.sessions)+s.starting >= s.maxSessions {
+		s.mu.Unlock()
+		writeError(w, http.StatusTooManyRequests, fmt.Sprintf("session limit of %d reached", s.maxSessions))
+		return
+	}
+	s.starting++
+	baseCtx := s.baseCtx
+	s.mu.Unlock()
+
+	sess, err := s.startSession(baseCtx)
+
+	s.mu.Lock()
+	s.starting--
+	if err == nil {
+		s.sessions[sess.ID()] = sess
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, sessionInfo{ID: sess.ID(), CreatedAt: sess.CreatedAt()})
+}
+
+// startSession creates and starts an agent for a new session.
+func (s *Server) startSession(baseCtx context.Context) (*session, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions outlive the request that creates them. Only the session
+	// stops its agent (see session.shutdown), so the server's context is
+	// not passed on as is.
+	sessCtx, cancel := context.WithCancel(context.WithoutCancel(baseCtx))
+	ag, err := s.factory(sessCtx)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create agent: %w", err)
+	}
+	if err := ag.Start(sessCtx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start agent: %w", err)
+	}
+
+	sess := newSession(id, ag, cancel, s.maxHistory)
+	go sess.pump()
+	return sess, nil
+}
+
+func (s *Server) handleListSessions(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	infos := make([]sessionInfo, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		infos = append(infos, sessionInfo{ID: sess.ID(), CreatedAt: sess.CreatedAt(), Busy: sess.Busy()})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].CreatedAt.Before(infos[j].CreatedAt) })
+	writeJSON(w, http.StatusOK, infos)
+}
+
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	sess, ok := s.sessions[id]
+	delete(s.sessions, id)
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), shutdownTimeout)
+	defer cancel()
+	if err := sess.Shutdown(ctx); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to shut down session: %v", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		writeError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+
+	if err := sess.Send(req.Content); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	if err := sess.CancelTurn(); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) handleApproval(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Approved *bool `json:"approved"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Approved == nil {
+		writeError(w, http.StatusBadRequest, "approved is required")
+		return
+	}
+
+	if err := sess.RespondApproval(r.PathValue("approval_id"), *req.Approved); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	var afterSeq int64
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		if seq, err := strconv.ParseInt(lastID, 10, 64); err == nil {
+			afterSeq = seq
+		}
+	}
+
+	backlog, events := sess.subscribe(afterSeq)
+	defer sess.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range backlog {
+		if err := writeSSE(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			if err := writeSSE(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// lookup resolves the session named in the request path, writing a 404 if it
+// does not exist.
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (*session, bool) {
+	s.mu.Lock()
+	sess, ok := s.sessions[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+	}
+	return sess, ok
+}
+
+func writeSSE(w http.ResponseWriter, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+	return err
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrSessionBusy):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrSessionClosed):
+		writeError(w, http.StatusGone, err.Error())
+	case errors.Is(err, ErrInputFull):
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
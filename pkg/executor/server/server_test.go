@@ -0,0 +1,332 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// echoAgent replies to each message with its content and reports approval
+// decisions back as message events.
+type echoAgent struct {
+	channels *types.AgentChannels
+	release  chan struct{} // when non-nil, turns wait for it before ending
+}
+
+func newEchoAgent() *echoAgent {
+	return &echoAgent{channels: types.NewAgentChannels(16)}
+}
+
+func (a *echoAgent) Start(ctx context.Context) error {
+	go func() {
+		defer a.channels.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-a.channels.Shutdown:
+				return
+			case input := <-a.channels.Input:
+				if !input.IsUserInput() {
+					continue
+				}
+				a.channels.Event <- types.NewUpdateBusyEvent(true)
+				a.channels.Event <- types.NewMessageContentEvent("echo: " + input.Content)
+				if a.release != nil {
+					<-a.release
+				}
+				a.channels.Event <- types.NewUpdateBusyEvent(false)
+				a.channels.Event <- types.NewTurnEndEvent()
+			case approval := <-a.channels.Approval:
+				a.channels.Event <- types.NewMessageContentEvent(approval.ApprovalID + ":" + string(approval.Decision))
+			}
+		}
+	}()
+	return nil
+}
+
+func (a *echoAgent) Shutdown(ctx context.Context) error {
+	close(a.channels.Shutdown)
+	select {
+	case <-a.channels.Done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *echoAgent) GetChannels() *types.AgentChannels       { return a.channels }
+func (a *echoAgent) GetTool(string) any                      { return nil }
+func (a *echoAgent) GetTools() []any                         { return nil }
+func (a *echoAgent) GetContextInfo() *agent.ContextInfo      { return &agent.ContextInfo{} }
+func (a *echoAgent) GetMessages() []*types.Message           { return nil }
+func (a *echoAgent) GetSystemPrompt() string                 { return "" }
+func (a *echoAgent) SetProvider(provider llm.Provider) error { return nil }
+
+func newTestServer(t *testing.T, factory SessionFactory, opts ...Option) (*Server, *httptest.Server) {
+	t.Helper()
+	srv := NewServer("", factory, opts...)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Stop(ctx)
+		ts.Close()
+	})
+	return srv, ts
+}
+
+func echoFactory(context.Context) (agent.Agent, error) {
+	return newEchoAgent(), nil
+}
+
+func createSession(t *testing.T, ts *httptest.Server) string {
+	t.Helper()
+	resp, err := http.Post(ts.URL+"/v1/sessions", "application/json", nil)
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	var info sessionInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatalf("decode session: %v", err)
+	}
+	return info.ID
+}
+
+func post(t *testing.T, url, body string) int {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// readEvents reads SSE events from the stream until one of type until arrives.
+func readEvents(t *testing.T, req *http.Request, until types.AgentEventType) []Event {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(req.Context(), 5*time.Second)
+	defer cancel()
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatalf("open event stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	var events []Event
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		events = append(events, event)
+		if event.Type == until {
+			return events
+		}
+	}
+	t.Fatalf("stream ended before %s event (got %d events)", until, len(events))
+	return nil
+}
+
+func TestServer_MessageRoundTrip(t *testing.T) {
+	_, ts := newTestServer(t, echoFactory)
+	id := createSession(t, ts)
+
+	if status := post(t, ts.URL+"/v1/sessions/"+id+"/messages", `{"content":"hello"}`); status != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", status)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v1/sessions/"+id+"/events", nil)
+	events := readEvents(t, req, types.EventTypeTurnEnd)
+
+	var content string
+	for i, event := range events {
+		if event.Seq != int64(i+1) {
+			t.Errorf("expected sequence %d, got %d", i+1, event.Seq)
+		}
+		if event.Type == types.EventTypeMessageContent {
+			content = event.Content
+		}
+	}
+	if content != "echo: hello" {
+		t.Errorf("expected echoed content, got %q", content)
+	}
+	if events[0].Busy == nil || !*events[0].Busy {
+		t.Error("expected busy flag on update_busy event")
+	}
+
+	// Reconnecting with Last-Event-ID replays only newer events
+	if status := post(t, ts.URL+"/v1/sessions/"+id+"/messages", `{"content":"again"}`); status != http.StatusAccepted {
+		t.Fatalf("expected 202 for second message, got %d", status)
+	}
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/v1/sessions/"+id+"/events", nil)
+	req.Header.Set("Last-Event-ID", "4")
+	replayed := readEvents(t, req, types.EventTypeTurnEnd)
+	if replayed[0].Seq != 5 {
+		t.Errorf("expected replay to start after event 4, got %d", replayed[0].Seq)
+	}
+}
+
+func TestServer_RejectsConcurrentTurns(t *testing.T) {
+	ag := newEchoAgent()
+	ag.release = make(chan struct{})
+	_, ts := newTestServer(t, func(context.Context) (agent.Agent, error) { return ag, nil })
+	id := createSession(t, ts)
+
+	if status := post(t, ts.URL+"/v1/sessions/"+id+"/messages", `{"content":"one"}`); status != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", status)
+	}
+	if status := post(t, ts.URL+"/v1/sessions/"+id+"/messages", `{"content":"two"}`); status != http.StatusConflict {
+		t.Errorf("expected 409 while busy, got %d", status)
+	}
+	close(ag.release)
+}
+
+func TestServer_Approval(t *testing.T) {
+	_, ts := newTestServer(t, echoFactory)
+	id := createSession(t, ts)
+
+	url := ts.URL + "/v1/sessions/" + id + "/approvals/abc"
+	if status := post(t, url, `{}`); status != http.StatusBadRequest {
+		t.Errorf("expected 400 without decision, got %d", status)
+	}
+	if status := post(t, url, `{"approved":true}`); status != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", status)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v1/sessions/"+id+"/events", nil)
+	events := readEvents(t, req, types.EventTypeMessageContent)
+	if got := events[len(events)-1].Content; got != "abc:granted" {
+		t.Errorf("expected approval to reach the agent, got %q", got)
+	}
+}
+
+func TestServer_SessionLifecycle(t *testing.T) {
+	_, ts := newTestServer(t, echoFactory, WithMaxSessions(1))
+	id := createSession(t, ts)
+
+	if status := post(t, ts.URL+"/v1/sessions", ""); status != http.StatusTooManyRequests {
+		t.Errorf("expected 429 over the session limit, got %d", status)
+	}
+
+	resp, err := http.Get(ts.URL + "/v1/sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var infos []sessionInfo
+	_ = json.NewDecoder(resp.Body).Decode(&infos)
+	resp.Body.Close()
+	if len(infos) != 1 || infos[0].ID != id {
+		t.Errorf("expected session %s to be listed, got %+v", id, infos)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/v1/sessions/"+id, nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", resp.StatusCode)
+	}
+
+	if status := post(t, ts.URL+"/v1/sessions/"+id+"/messages", `{"content":"hi"}`); status != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", status)
+	}
+}
+
+func TestServer_SessionLimitCountsStartingSessions(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	factory := func(context.Context) (agent.Agent, error) {
+		close(started)
+		<-release
+		return nil, errors.New("no provider")
+	}
+	srv, ts := newTestServer(t, factory, WithMaxSessions(1))
+
+	statusCh := make(chan int, 1)
+	go func() { statusCh <- post(t, ts.URL+"/v1/sessions", "") }()
+	<-started
+
+	// The session being built holds the only slot
+	if status := post(t, ts.URL+"/v1/sessions", ""); status != http.StatusTooManyRequests {
+		t.Errorf("expected 429 while a session is starting, got %d", status)
+	}
+
+	close(release)
+	if status := <-statusCh; status != http.StatusInternalServerError {
+		t.Fatalf("expected 500 from the failing factory, got %d", status)
+	}
+
+	// The failed start gives its slot back
+	srv.mu.Lock()
+	starting, sessions := srv.starting, len(srv.sessions)
+	srv.mu.Unlock()
+	if starting != 0 || sessions != 0 {
+		t.Errorf("expected the slot to be released, got %d starting and %d sessions", starting, sessions)
+	}
+	srv.factory = echoFactory
+	createSession(t, ts)
+}
+
+func TestServer_Token(t *testing.T) {
+	_, ts := newTestServer(t, echoFactory, WithToken("secret"))
+
+	if status := post(t, ts.URL+"/v1/sessions", ""); status != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", status)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/v1/sessions", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected 201 with bearer token, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/v1/sessions?token=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 with query token, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected health check to skip auth, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// SessionFactory creates a fresh, unstarted agent for a new session.
+type SessionFactory func(ctx context.Context) (agent.Agent, error)
+
+// subscriberBuffer is the number of events a slow SSE client may fall behind
+// before it is disconnected. It can reconnect with Last-Event-ID to catch up.
+const subscriberBuffer = 256
+
+// session adds an event history and SSE subscribers to an agent session.
+type session struct {
+	*AgentSession
+
+	mu          sync.Mutex
+	closed      bool
+	seq         int64
+	history     []Event
+	maxHistory  int
+	subscribers map[chan Event]struct{}
+}
+
+func newSession(id string, ag agent.Agent, cancel context.CancelFunc, maxHistory int) *session {
+	return &session{
+		AgentSession: NewAgentSession(id, ag, cancel),
+		maxHistory:   maxHistory,
+		subscribers:  make(map[chan Event]struct{}),
+	}
+}
+
+// pump records each of the agent's events and fans it out to subscribers
+// until the agent shuts down.
+func (s *session) pump() {
+	s.Run(s.publish, s.closeSubscribers)
+}
+
+func (s *session) closeSubscribers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for ch := range s.subscribers {
+		close(ch)
+	}
+	s.subscribers = nil
+}
+
+func (s *session) publish(event *types.AgentEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if event.Type == types.EventTypeTurnEnd {
+		s.EndTurn()
+	}
+
+	s.seq++
+	wire := NewEvent(s.seq, event)
+	s.history = append(s.history, wire)
+	if len(s.history) > s.maxHistory {
+		s.history = s.history[len(s.history)-s.maxHistory:]
+	}
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- wire:
+		default:
+			// Drop clients that cannot keep up rather than stalling the agent
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe returns the buffered events after afterSeq and a channel of new
+// events. The channel is closed when the session ends or the client falls behind.
+func (s *session) subscribe(afterSeq int64) ([]Event, chan Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var backlog []Event
+	for _, event := range s.history {
+		if event.Seq > afterSeq {
+			backlog = append(backlog, event)
+		}
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	if s.closed {
+		close(ch)
+		return backlog, ch
+	}
+	s.subscribers[ch] = struct{}{}
+	return backlog, ch
+}
+
+func (s *session) unsubscribe(ch chan Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[ch]; ok {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
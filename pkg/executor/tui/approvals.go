@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"github.com/entrhq/forge/pkg/executor/tui/overlay"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+	pkgtypes "github.com/entrhq/forge/pkg/types"
+)
+
+// showApprovalQueue opens the approval queue overlay unless it is already
+// open or waiting underneath another overlay.
+func (m *model) showApprovalQueue() {
+	if m.approvals.Len() == 0 || m.approvalQueueOverlay() != nil {
+		return
+	}
+
+	queueOverlay := overlay.NewApprovalQueueOverlay(m.approvals, m.width, m.height, m.respondApproval, m.approveAllApprovals)
+	m.overlay.pushOverlay(types.OverlayModeApprovalQueue, queueOverlay)
+}
+
+// approvalQueueOverlay returns the open approval queue overlay, whether it is
+// active or saved on the overlay stack, or nil if it is not open.
+func (m *model) approvalQueueOverlay() *overlay.ApprovalQueueOverlay {
+	if m.overlay.mode == types.OverlayModeApprovalQueue {
+		if queueOverlay, ok := m.overlay.overlay.(*overlay.ApprovalQueueOverlay); ok {
+			return queueOverlay
+		}
+	}
+	for _, entry := range m.overlay.stack {
+		if entry.mode == types.OverlayModeApprovalQueue {
+			if queueOverlay, ok := entry.overlay.(*overlay.ApprovalQueueOverlay); ok {
+				return queueOverlay
+			}
+		}
+	}
+	return nil
+}
+
+// respondApproval sends the user's decision for a queued approval to the agent.
+func (m *model) respondApproval(approvalID string, granted bool) {
+	if !m.approvals.Remove(approvalID) {
+		return
+	}
+	decision := pkgtypes.ApprovalRejected
+	if granted {
+		decision = pkgtypes.ApprovalGranted
+	}
+	m.channels.Approval <- pkgtypes.NewApprovalResponse(approvalID, decision)
+}
+
+// approveAllApprovals approves every queued request. Requests that arrive
+// afterwards are queued for review as usual.
+func (m *model) approveAllApprovals() {
+	for _, item := range m.approvals.Items() {
+		m.respondApproval(item.ApprovalID, true)
+	}
+}
+
+// removeApproval drops a request the agent has resolved, e.g. on timeout, and
+// updates the queue overlay.
+func (m *model) removeApproval(approvalID string) {
+	if m.approvals.Remove(approvalID) {
+		m.refreshApprovalQueue()
+	}
+}
+
+// refreshApprovalQueue re-renders the queue overlay after the queue changed
+// outside it, closing the overlay once nothing is pending.
+func (m *model) refreshApprovalQueue() {
+	queueOverlay := m.approvalQueueOverlay()
+	if queueOverlay == nil {
+		return
+	}
+	if m.approvals.Len() > 0 {
+		queueOverlay.Refresh()
+		return
+	}
+
+	if m.overlay.overlay == types.Overlay(queueOverlay) {
+		m.ClearOverlay()
+		return
+	}
+	// Drop the queue from underneath whichever overlay covers it
+	for i, entry := range m.overlay.stack {
+		if entry.overlay == types.Overlay(queueOverlay) {
+			m.overlay.stack = append(m.overlay.stack[:i], m.overlay.stack[i+1:]...)
+			break
+		}
+	}
+}
+
+// handleApprovalsCommand reopens the approval queue after it was hidden.
+func handleApprovalsCommand(m *model, args []string) any {
+	if m.approvals.Len() == 0 {
+		m.showToast("No pending approvals", "", "✓", false)
+		return nil
+	}
+	m.showApprovalQueue()
+	return nil
+}
@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/executor/tui/overlay"
+	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func newApprovalTestModel() *model {
+	m := initialModel()
+	m.channels = types.NewAgentChannels(10)
+	m.width, m.height = 120, 40
+	return &m
+}
+
+func approvalRequest(id string) *types.AgentEvent {
+	return types.NewToolApprovalRequestEvent(id, "write_file", map[string]any{"path": id + ".go"}, &tools.ToolPreview{
+		Type:    tools.PreviewTypeDiff,
+		Title:   "Write " + id + ".go",
+		Content: "+package main\n",
+	})
+}
+
+func pressKey(t *testing.T, m *model, key string) {
+	t.Helper()
+	var msg tea.KeyMsg
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	m.Update(msg)
+}
+
+func receiveResponse(t *testing.T, m *model) *types.ApprovalResponse {
+	t.Helper()
+	select {
+	case resp := <-m.channels.Approval:
+		return resp
+	default:
+		t.Fatal("expected an approval response")
+		return nil
+	}
+}
+
+func TestApprovalQueue_QueuesRequestsInOneOverlay(t *testing.T) {
+	m := newApprovalTestModel()
+
+	m.handleAgentEvent(approvalRequest("a1"))
+	m.handleAgentEvent(approvalRequest("a2"))
+	m.handleAgentEvent(types.NewToolApprovalRequestEvent("a3", "execute_command", map[string]any{"command": "make"}, nil))
+
+	if m.overlay.mode != tuitypes.OverlayModeApprovalQueue {
+		t.Fatalf("expected approval queue overlay, got mode %v", m.overlay.mode)
+	}
+	if len(m.overlay.stack) != 0 {
+		t.Errorf("expected a single overlay, got %d stacked", len(m.overlay.stack))
+	}
+	if m.approvals.Len() != 3 {
+		t.Fatalf("expected 3 queued approvals, got %d", m.approvals.Len())
+	}
+
+	pressKey(t, m, "n")
+	if resp := receiveResponse(t, m); resp.ApprovalID != "a1" || resp.Decision != types.ApprovalRejected {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	pressKey(t, m, "y")
+	if resp := receiveResponse(t, m); resp.ApprovalID != "a2" || resp.Decision != types.ApprovalGranted {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	// The agent times out the last request; the overlay closes
+	m.handleAgentEvent(types.NewToolApprovalTimeoutEvent("a3", "execute_command"))
+	if m.approvals.Len() != 0 {
+		t.Errorf("expected empty queue, got %d", m.approvals.Len())
+	}
+	if m.overlay.isActive() {
+		t.Error("expected overlay to close when the queue empties")
+	}
+}
+
+func TestApprovalQueue_ApproveAllOnlyCoversQueued(t *testing.T) {
+	m := newApprovalTestModel()
+
+	m.handleAgentEvent(approvalRequest("a1"))
+	m.handleAgentEvent(approvalRequest("a2"))
+	pressKey(t, m, "A")
+
+	for _, id := range []string{"a1", "a2"} {
+		if resp := receiveResponse(t, m); resp.ApprovalID != id || resp.Decision != types.ApprovalGranted {
+			t.Errorf("unexpected response %+v", resp)
+		}
+	}
+	if m.overlay.isActive() {
+		t.Error("expected overlay to close after approving all")
+	}
+	if m.textarea.Value() != "" {
+		t.Errorf("approval key leaked into the input: %q", m.textarea.Value())
+	}
+
+	// Later requests in the same turn still need review
+	m.handleAgentEvent(approvalRequest("a3"))
+	select {
+	case resp := <-m.channels.Approval:
+		t.Fatalf("expected a3 to wait for review, got %+v", resp)
+	default:
+	}
+	if m.overlay.mode != tuitypes.OverlayModeApprovalQueue {
+		t.Error("expected approval queue overlay for a later request")
+	}
+}
+
+func TestApprovalQueue_HideAndReopen(t *testing.T) {
+	m := newApprovalTestModel()
+
+	m.handleAgentEvent(approvalRequest("a1"))
+	pressKey(t, m, "esc")
+	if m.overlay.isActive() {
+		t.Fatal("expected esc to hide the queue")
+	}
+	if m.approvals.Len() != 1 {
+		t.Fatalf("expected request to stay queued, got %d", m.approvals.Len())
+	}
+	select {
+	case resp := <-m.channels.Approval:
+		t.Fatalf("hiding the queue should not respond, got %+v", resp)
+	default:
+	}
+
+	handleApprovalsCommand(m, nil)
+	if _, ok := m.overlay.overlay.(*overlay.ApprovalQueueOverlay); !ok {
+		t.Fatal("expected /approvals to reopen the queue")
+	}
+	pressKey(t, m, "enter")
+	if resp := receiveResponse(t, m); resp.ApprovalID != "a1" || resp.Decision != types.ApprovalGranted {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
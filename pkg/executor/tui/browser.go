@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/tools/browser"
+)
+
+// browserManager returns the agent's browser session manager, or nil when
+// browser automation is not available.
+func (m *model) browserManager() *browser.SessionManager {
+	if defaultAgent, ok := m.agent.(*agent.DefaultAgent); ok {
+		return defaultAgent.GetBrowserManager()
+	}
+	return nil
+}
+
+// handleBrowserCommand reports whether Playwright is installed, or with the
+// install argument downloads Chromium so the browser tools replace fetch_url.
+func handleBrowserCommand(m *model, args []string) any {
+	manager := m.browserManager()
+	if manager == nil {
+		m.showToast("Browser unavailable", "Browser automation is not available in this session", "✗", true)
+		return nil
+	}
+
+	if len(args) == 0 {
+		return func() tea.Msg {
+			if err := manager.DetectInstallation(); err != nil {
+				return toastMsg{
+					message: "Playwright not installed",
+					details: "fetch_url is used instead of the browser tools. Run /browser install to install Chromium.",
+					icon:    "!",
+				}
+			}
+			return toastMsg{
+				message: "Playwright installed",
+				details: "Browser tools are available when browser automation is enabled in /settings",
+				icon:    "✓",
+			}
+		}
+	}
+
+	if args[0] != "install" {
+		m.showToast("Invalid /browser argument", "Usage: /browser [install]", "✗", true)
+		return nil
+	}
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish before installing Playwright", "⚠", true)
+		return nil
+	}
+
+	return tea.Sequence(
+		func() tea.Msg {
+			return operationStartMsg{message: "Installing Playwright Chromium..."}
+		},
+		func() tea.Msg {
+			err := manager.Install(io.Discard)
+			return operationCompleteMsg{
+				result:       "Browser tools are available from the next agent turn",
+				err:          err,
+				successTitle: "Playwright installed",
+				successIcon:  "✓",
+				errorTitle:   "Playwright install failed",
+				errorIcon:    "✗",
+			}
+		},
+	)
+}
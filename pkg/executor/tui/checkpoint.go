@@ -0,0 +1,235 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+const (
+	// checkpointInterval is how often the session is auto-saved while idle.
+	checkpointInterval = 30 * time.Second
+
+	checkpointVersion = 1
+)
+
+// sessionCheckpoint is the session state auto-saved to
+// <workspace>/.forge/session/checkpoint.json. The file is removed on a clean
+// exit, so finding one at launch means the previous session ended unexpectedly.
+type sessionCheckpoint struct {
+	Version               int               `json:"version"`
+	SavedAt               time.Time         `json:"saved_at"`
+	Agent                 *agent.Checkpoint `json:"agent"`
+	TotalPromptTokens     int               `json:"total_prompt_tokens"`
+	TotalCompletionTokens int               `json:"total_completion_tokens"`
+	TotalTokens           int               `json:"total_tokens"`
+	Usage                 *usageLedger      `json:"usage,omitempty"`
+}
+
+// checkpointTickMsg triggers a periodic auto-save.
+type checkpointTickMsg struct{}
+
+// checkpointTick schedules the next auto-save.
+func checkpointTick() tea.Cmd {
+	return tea.Tick(checkpointInterval, func(time.Time) tea.Msg {
+		return checkpointTickMsg{}
+	})
+}
+
+func checkpointPath(workspaceDir string) string {
+	return filepath.Join(workspaceDir, ".forge", "session", "checkpoint.json")
+}
+
+// loadCheckpoint reads the checkpoint left in workspaceDir. It returns nil
+// when there is none or it holds no conversation.
+func loadCheckpoint(workspaceDir string) (*sessionCheckpoint, error) {
+	data, err := os.ReadFile(checkpointPath(workspaceDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	var cp sessionCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parse checkpoint: %w", err)
+	}
+	if cp.Version != checkpointVersion || cp.Agent == nil || len(cp.Agent.Messages) == 0 {
+		return nil, nil
+	}
+	return &cp, nil
+}
+
+// writeCheckpoint atomically replaces the checkpoint in workspaceDir so a
+// crash mid-write never leaves a truncated file behind.
+func writeCheckpoint(workspaceDir string, cp *sessionCheckpoint) error {
+	path := checkpointPath(workspaceDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create checkpoint directory: %w", err)
+	}
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// removeCheckpoint deletes the checkpoint in workspaceDir, marking the
+// session as cleanly closed.
+func removeCheckpoint(workspaceDir string) error {
+	err := os.Remove(checkpointPath(workspaceDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// handleCheckpointTick saves the session if it changed since the last save
+// and the agent is idle, then schedules the next tick. Saving mid-turn is
+// avoided because the conversation may end in an unanswered tool call.
+func (m *model) handleCheckpointTick() tea.Cmd {
+	if m.checkpointDirty && !m.agentBusy {
+		m.saveCheckpoint()
+	}
+	return checkpointTick()
+}
+
+// saveCheckpoint writes the current session state. Failures are reported
+// once per run rather than on every tick.
+func (m *model) saveCheckpoint() {
+	cpAgent, ok := m.agent.(agent.Checkpointer)
+	if !ok || m.workspaceDir == "" {
+		return
+	}
+
+	cp := &sessionCheckpoint{
+		Version:               checkpointVersion,
+		SavedAt:               time.Now(),
+		Agent:                 cpAgent.Checkpoint(),
+		TotalPromptTokens:     m.totalPromptTokens,
+		TotalCompletionTokens: m.totalCompletionTokens,
+		TotalTokens:           m.totalTokens,
+		Usage:                 &m.usage,
+	}
+	if err := writeCheckpoint(m.workspaceDir, cp); err != nil {
+		if !m.checkpointFailed {
+			m.showToast("Auto-save failed", err.Error(), "!", true)
+		}
+		m.checkpointFailed = true
+		return
+	}
+	m.checkpointDirty = false
+	m.checkpointFailed = false
+}
+
+// offerRestore shows a notice about a checkpoint left by a previous session
+// that did not exit cleanly.
+func (m *model) offerRestore(cp *sessionCheckpoint) {
+	m.pendingRestore = cp
+	notice := fmt.Sprintf(
+		"The previous session ended unexpectedly. A checkpoint from %s with %d messages was found.\n"+
+			"Type /restore to continue it, or send a message to start fresh.",
+		cp.SavedAt.Format("Jan 2 15:04"), len(cp.Agent.Messages))
+	m.appendMsg(newEntryMsg("↺ ", notice, tipsStyle, "\n\n"))
+}
+
+// handleRestoreCommand restores the session offered at launch.
+func handleRestoreCommand(m *model, args []string) any {
+	if m.pendingRestore == nil {
+		m.showToast("Nothing to restore", "No previous session checkpoint is pending", "✗", true)
+		return nil
+	}
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish before restoring", "✗", true)
+		return nil
+	}
+	cpAgent, ok := m.agent.(agent.Checkpointer)
+	if !ok {
+		m.showToast("Restore unavailable", "This agent does not support session restore", "✗", true)
+		return nil
+	}
+
+	cp := m.pendingRestore
+	m.pendingRestore = nil
+	cpAgent.RestoreCheckpoint(cp.Agent)
+	m.totalPromptTokens = cp.TotalPromptTokens
+	m.totalCompletionTokens = cp.TotalCompletionTokens
+	m.totalTokens = cp.TotalTokens
+	if cp.Usage != nil {
+		m.usage = *cp.Usage
+	}
+
+	m.replayConversation(cp.Agent.Messages)
+	m.checkpointDirty = true
+	m.resumeFollowScroll()
+	m.recalculateLayout()
+
+	details := fmt.Sprintf("%d messages", len(cp.Agent.Messages))
+	if n := len(cp.Agent.Notes); n > 0 {
+		details += fmt.Sprintf(", %d notes", n)
+	}
+	m.showToast("Session restored", details, "↺", false)
+	return nil
+}
+
+// replayConversation renders a restored conversation: user prompts, agent
+// replies and the results of loop-breaking tools such as task_completion.
+// Other tool traffic and summarized history are left out to keep the
+// transcript readable; the agent still has all of it in memory.
+func (m *model) replayConversation(messages []*types.Message) {
+	for _, msg := range messages {
+		if summarized, _ := msg.Metadata["summarized"].(bool); summarized {
+			continue
+		}
+
+		switch msg.Role {
+		case types.RoleUser:
+			m.appendMsg(newUserMsg(msg.Content))
+		case types.RoleAssistant:
+			content := msg.Content
+			if i := strings.Index(content, "<tool>"); i >= 0 {
+				content = content[:i]
+			}
+			if content = strings.TrimSpace(content); content != "" {
+				m.appendMsg(newMarkdownMsg(m.mdRenderer.RenderFn(content), "\n\n"))
+			}
+		case types.RoleTool:
+			name, result, ok := parseToolResultMessage(msg.Content)
+			if ok && m.resultClassifier.IsLoopBreakingTool(name) {
+				m.appendMsg(newMarkdownMsg(m.mdRenderer.RenderFn(result), "\n\n"))
+			}
+		}
+	}
+}
+
+// parseToolResultMessage splits a tool message written by the agent as
+// "Tool '<name>' result:\n<result>".
+func parseToolResultMessage(content string) (name, result string, ok bool) {
+	rest, found := strings.CutPrefix(content, "Tool '")
+	if !found {
+		return "", "", false
+	}
+	name, result, found = strings.Cut(rest, "' result:\n")
+	if !found {
+		return "", "", false
+	}
+	return name, result, true
+}
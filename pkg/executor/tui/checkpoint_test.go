@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/agent/memory/notes"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// checkpointAgent is an agent whose session state lives in memory. Only the
+// Checkpointer methods are implemented.
+type checkpointAgent struct {
+	agent.Agent
+	state *agent.Checkpoint
+}
+
+func (a *checkpointAgent) Checkpoint() *agent.Checkpoint { return a.state }
+
+func (a *checkpointAgent) RestoreCheckpoint(cp *agent.Checkpoint) { a.state = cp }
+
+func TestCheckpoint_SaveAndLoad(t *testing.T) {
+	m := initialModel()
+	m.workspaceDir = t.TempDir()
+	m.agent = &checkpointAgent{state: &agent.Checkpoint{
+		Messages: []*types.Message{types.NewUserMessage("fix the build")},
+		Notes:    []*notes.Note{{ID: "note_1", Content: "uses make", Tags: []string{"build"}}},
+	}}
+	m.totalTokens = 1234
+	m.checkpointDirty = true
+
+	m.handleCheckpointTick()
+	if m.checkpointDirty {
+		t.Fatal("expected checkpoint to be saved")
+	}
+
+	cp, err := loadCheckpoint(m.workspaceDir)
+	if err != nil {
+		t.Fatalf("loadCheckpoint: %v", err)
+	}
+	if cp == nil || len(cp.Agent.Messages) != 1 || cp.Agent.Messages[0].Content != "fix the build" {
+		t.Fatalf("unexpected checkpoint %+v", cp)
+	}
+	if len(cp.Agent.Notes) != 1 || cp.Agent.Notes[0].ID != "note_1" {
+		t.Errorf("notes not saved: %+v", cp.Agent.Notes)
+	}
+	if cp.TotalTokens != 1234 {
+		t.Errorf("expected total tokens 1234, got %d", cp.TotalTokens)
+	}
+
+	if err := removeCheckpoint(m.workspaceDir); err != nil {
+		t.Fatalf("removeCheckpoint: %v", err)
+	}
+	if _, err := os.Stat(checkpointPath(m.workspaceDir)); !os.IsNotExist(err) {
+		t.Error("expected checkpoint file to be removed")
+	}
+}
+
+func TestCheckpoint_NotSavedWhileBusy(t *testing.T) {
+	m := initialModel()
+	m.workspaceDir = t.TempDir()
+	m.agent = &checkpointAgent{state: &agent.Checkpoint{
+		Messages: []*types.Message{types.NewUserMessage("hello")},
+	}}
+	m.checkpointDirty = true
+	m.agentBusy = true
+
+	m.handleCheckpointTick()
+	if _, err := os.Stat(checkpointPath(m.workspaceDir)); !os.IsNotExist(err) {
+		t.Error("expected no checkpoint while the agent is busy")
+	}
+}
+
+func TestLoadCheckpoint_EmptyConversationIgnored(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeCheckpoint(dir, &sessionCheckpoint{Version: checkpointVersion, Agent: &agent.Checkpoint{}}); err != nil {
+		t.Fatalf("writeCheckpoint: %v", err)
+	}
+	cp, err := loadCheckpoint(dir)
+	if err != nil || cp != nil {
+		t.Errorf("expected nil checkpoint, got %+v, %v", cp, err)
+	}
+}
+
+func TestRestoreCommand(t *testing.T) {
+	m := initialModel()
+	restored := &checkpointAgent{}
+	m.agent = restored
+	m.offerRestore(&sessionCheckpoint{
+		Version: checkpointVersion,
+		SavedAt: time.Now(),
+		Agent: &agent.Checkpoint{Messages: []*types.Message{
+			types.NewUserMessage("add tests"),
+			{Role: types.RoleAssistant, Content: "Looking at the code.<tool>{\"tool_name\":\"read_file\"}</tool>"},
+			types.NewToolMessage("Tool 'read_file' result:\npackage main"),
+			types.NewToolMessage("Tool 'task_completion' result:\nAdded tests."),
+		}},
+		TotalPromptTokens: 500,
+	})
+	before := len(m.messages)
+
+	handleRestoreCommand(&m, nil)
+
+	if restored.state == nil || len(restored.state.Messages) != 4 {
+		t.Fatal("expected agent state to be restored")
+	}
+	if m.pendingRestore != nil {
+		t.Error("expected pending restore to be cleared")
+	}
+	if m.totalPromptTokens != 500 {
+		t.Errorf("expected prompt tokens 500, got %d", m.totalPromptTokens)
+	}
+	// User prompt, assistant text and task_completion result; read_file is hidden
+	if got := len(m.messages) - before; got != 3 {
+		t.Errorf("expected 3 replayed messages, got %d", got)
+	}
+}
+
+func TestParseToolResultMessage(t *testing.T) {
+	name, result, ok := parseToolResultMessage("Tool 'task_completion' result:\nDone.\nAll good.")
+	if !ok || name != "task_completion" || result != "Done.\nAll good." {
+		t.Errorf("unexpected parse: %q %q %v", name, result, ok)
+	}
+	if _, _, ok := parseToolResultMessage("plain text"); ok {
+		t.Error("expected non-tool message to be rejected")
+	}
+}
@@ -0,0 +1,285 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+)
+
+// codeBlock is a fenced code block from an assistant message.
+type codeBlock struct {
+	Lang string // First word of the fence's info string, e.g. "go"
+	Code string
+}
+
+// codeBlockExtensions maps fence languages to the extension suggested when
+// a block is saved.
+var codeBlockExtensions = map[string]string{
+	"bash": "sh", "sh": "sh", "shell": "sh", "zsh": "sh",
+	"go": "go", "python": "py", "py": "py", "ruby": "rb", "rb": "rb",
+	"javascript": "js", "js": "js", "typescript": "ts", "ts": "ts", "tsx": "tsx", "jsx": "jsx",
+	"rust": "rs", "java": "java", "kotlin": "kt", "c": "c", "cpp": "cpp", "csharp": "cs",
+	"json": "json", "yaml": "yaml", "yml": "yaml", "toml": "toml", "xml": "xml",
+	"html": "html", "css": "css", "sql": "sql", "markdown": "md", "md": "md",
+	"diff": "diff", "dockerfile": "dockerfile", "makefile": "mk",
+}
+
+// clipboardOutput is where the OSC 52 sequence that sets the terminal's
+// clipboard is written. Bubble Tea draws on stdout, so stderr keeps the
+// sequence out of its frames.
+var clipboardOutput io.Writer = os.Stderr
+
+// writeSystemClipboard writes to the OS clipboard; replaced in tests
+var writeSystemClipboard = clipboard.WriteAll
+
+// extractCodeBlocks returns the fenced code blocks in markdown, in order. An
+// unclosed fence runs to the end of the message.
+func extractCodeBlocks(markdown string) []codeBlock {
+	var blocks []codeBlock
+	var current *codeBlock
+	var fence string
+	var indent int
+	var lines []string
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		lineIndent := len(line) - len(trimmed)
+
+		if current == nil {
+			if lineIndent > 3 {
+				continue
+			}
+			marker := fenceMarker(trimmed)
+			if marker == "" {
+				continue
+			}
+			info := strings.Fields(strings.TrimPrefix(trimmed, marker))
+			current = &codeBlock{}
+			if len(info) > 0 {
+				current.Lang = strings.ToLower(info[0])
+			}
+			fence, indent, lines = marker, lineIndent, nil
+			continue
+		}
+
+		// A closing fence is at least as long as the opening one
+		if lineIndent <= 3 && strings.HasPrefix(trimmed, fence) && strings.Trim(strings.TrimSpace(trimmed), fence[:1]) == "" {
+			current.Code = strings.Join(lines, "\n")
+			blocks = append(blocks, *current)
+			current = nil
+			continue
+		}
+		// Content loses up to the opening fence's indentation
+		lines = append(lines, line[min(indent, lineIndent):])
+	}
+
+	if current != nil {
+		current.Code = strings.Join(lines, "\n")
+		blocks = append(blocks, *current)
+	}
+	return blocks
+}
+
+// fenceMarker returns the run of three or more backticks or tildes that opens
+// a fence at the start of line, or "" when there is none.
+func fenceMarker(line string) string {
+	if !strings.HasPrefix(line, "```") && !strings.HasPrefix(line, "~~~") {
+		return ""
+	}
+	n := len(line) - len(strings.TrimLeft(line, line[:1]))
+	marker := line[:n]
+	// Backtick fences cannot have backticks in their info string
+	if marker[0] == '`' && strings.Contains(line[n:], "`") {
+		return ""
+	}
+	return marker
+}
+
+// setCodeBlocks replaces the selectable code blocks with those in the last
+// assistant message.
+func (m *model) setCodeBlocks(markdown string) {
+	m.codeBlocks = extractCodeBlocks(markdown)
+	m.selectedCodeBlock = 0
+}
+
+// handleCodeBlockKey handles the Alt keys that select, copy and save the code
+// blocks of the last assistant message. It reports whether msg was one.
+func (m *model) handleCodeBlockKey(msg tea.KeyMsg) bool {
+	if !msg.Alt || msg.Type != tea.KeyRunes || len(msg.Runes) != 1 {
+		return false
+	}
+
+	switch msg.Runes[0] {
+	case 'n':
+		m.selectCodeBlock(1)
+	case 'p':
+		m.selectCodeBlock(-1)
+	case 'y':
+		m.copyCodeBlock()
+	case 's':
+		m.startSaveCodeBlock()
+	default:
+		return false
+	}
+	return true
+}
+
+// selectCodeBlock moves the selection by step, wrapping around, and shows
+// the selected block. With nothing selected, moving forward selects the
+// first block and moving back the last.
+func (m *model) selectCodeBlock(step int) {
+	if len(m.codeBlocks) == 0 {
+		m.showToast("No code blocks", "The last response has no code blocks", "!", false)
+		return
+	}
+
+	switch {
+	case m.selectedCodeBlock == 0 && step > 0:
+		m.selectedCodeBlock = 1
+	case m.selectedCodeBlock == 0:
+		m.selectedCodeBlock = len(m.codeBlocks)
+	default:
+		n := len(m.codeBlocks)
+		m.selectedCodeBlock = ((m.selectedCodeBlock-1+step)%n+n)%n + 1
+	}
+
+	block := m.codeBlocks[m.selectedCodeBlock-1]
+	m.showToast(
+		fmt.Sprintf("Code block %d of %d", m.selectedCodeBlock, len(m.codeBlocks)),
+		describeCodeBlock(block)+" · Alt+Y copy · Alt+S save",
+		"▤", false)
+}
+
+// currentCodeBlock returns the selected code block, selecting the last one
+// when none is. It reports false when the last response has none.
+func (m *model) currentCodeBlock() (codeBlock, bool) {
+	if len(m.codeBlocks) == 0 {
+		m.showToast("No code blocks", "The last response has no code blocks", "!", false)
+		return codeBlock{}, false
+	}
+	if m.selectedCodeBlock == 0 {
+		m.selectedCodeBlock = len(m.codeBlocks)
+	}
+	return m.codeBlocks[m.selectedCodeBlock-1], true
+}
+
+// copyCodeBlock copies the selected code block to the clipboard.
+func (m *model) copyCodeBlock() {
+	block, ok := m.currentCodeBlock()
+	if !ok {
+		return
+	}
+	if !copyToClipboard(block.Code) {
+		m.showToast("Clipboard unavailable", "No clipboard manager or terminal clipboard detected", "!", true)
+		return
+	}
+	m.showToast(fmt.Sprintf("Copied code block %d of %d", m.selectedCodeBlock, len(m.codeBlocks)), describeCodeBlock(block), "✓", false)
+}
+
+// copyToClipboard writes text to the OS clipboard and, with an OSC 52 escape
+// sequence, to the terminal's clipboard, which also works over SSH. It
+// reports whether either was reached.
+func copyToClipboard(text string) bool {
+	copied := writeSystemClipboard(text) == nil
+
+	if f, ok := clipboardOutput.(*os.File); ok && !term.IsTerminal(int(f.Fd())) {
+		return copied
+	}
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	if _, err := seq.WriteTo(clipboardOutput); err == nil {
+		copied = true
+	}
+	return copied
+}
+
+// startSaveCodeBlock asks for the path to save the selected code block to,
+// in the input box.
+func (m *model) startSaveCodeBlock() {
+	block, ok := m.currentCodeBlock()
+	if !ok {
+		return
+	}
+	if m.textarea.Value() != "" && !m.savingCodeBlock {
+		m.showToast("Input not empty", "Send or clear your message before saving a code block", "!", false)
+		return
+	}
+
+	name := fmt.Sprintf("code-block-%d.txt", m.selectedCodeBlock)
+	if ext, ok := codeBlockExtensions[block.Lang]; ok {
+		name = fmt.Sprintf("code-block-%d.%s", m.selectedCodeBlock, ext)
+	}
+	m.savingCodeBlock = true
+	m.textarea.SetValue(name)
+	m.textarea.CursorEnd()
+	m.updateTextAreaHeight()
+}
+
+// cancelSaveCodeBlock abandons saving a code block.
+func (m *model) cancelSaveCodeBlock() {
+	m.savingCodeBlock = false
+	m.textarea.Reset()
+	m.updateTextAreaHeight()
+}
+
+// saveCodeBlock writes the selected code block to path, relative to the
+// workspace. Existing files are not overwritten.
+func (m *model) saveCodeBlock(path string) {
+	block, ok := m.currentCodeBlock()
+	if !ok {
+		m.cancelSaveCodeBlock()
+		return
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.workspaceDir, path)
+	}
+	if _, err := os.Stat(path); err == nil {
+		m.showToast("File exists", fmt.Sprintf("%s already exists; choose another path", path), "!", true)
+		return
+	}
+
+	content := block.Code
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		m.showToast("Save failed", err.Error(), "✗", true)
+		return
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		m.showToast("Save failed", err.Error(), "✗", true)
+		return
+	}
+
+	m.cancelSaveCodeBlock()
+	if rel, err := filepath.Rel(m.workspaceDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = rel
+	}
+	m.showToast("Saved code block", path, "✓", false)
+}
+
+// describeCodeBlock summarizes block as its language and line count.
+func describeCodeBlock(block codeBlock) string {
+	lang := block.Lang
+	if lang == "" {
+		lang = "text"
+	}
+	lines := strings.Count(block.Code, "\n") + 1
+	if block.Code == "" {
+		lines = 0
+	}
+	return fmt.Sprintf("%s · %d line(s)", lang, lines)
+}
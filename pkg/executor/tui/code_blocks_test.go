@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestExtractCodeBlocks(t *testing.T) {
+	markdown := strings.Join([]string{
+		"Run this:",
+		"```bash",
+		"go test ./...",
+		"```",
+		"",
+		"  ~~~~ Go title",
+		"  func main() {",
+		"  \tfmt.Println(\"```\")",
+		"  }",
+		"  ~~~~",
+		"Inline ``` backticks ``` are not a fence.",
+		"````",
+		"```md",
+		"nested",
+		"```",
+		"````",
+		"```python",
+		"unclosed",
+	}, "\n")
+
+	got := extractCodeBlocks(markdown)
+	want := []codeBlock{
+		{Lang: "bash", Code: "go test ./..."},
+		{Lang: "go", Code: "func main() {\n\tfmt.Println(\"```\")\n}"},
+		{Code: "```md\nnested\n```"},
+		{Lang: "python", Code: "unclosed"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d blocks, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("block %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCodeBlockKeys_Cycle(t *testing.T) {
+	m := newHeightTestModel(40)
+	alt := func(r rune) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: true} }
+
+	if !m.handleCodeBlockKey(alt('n')) || m.toast.message != "No code blocks" {
+		t.Errorf("expected a toast without code blocks, got %q", m.toast.message)
+	}
+	if m.handleCodeBlockKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}}) {
+		t.Error("expected n without Alt to reach the input")
+	}
+
+	m.setCodeBlocks("```go\na\n```\n```\nb\n```\n```sh\nc\n```")
+	m.handleCodeBlockKey(alt('p'))
+	if m.selectedCodeBlock != 3 {
+		t.Errorf("expected Alt+P to select the last block, got %d", m.selectedCodeBlock)
+	}
+	m.handleCodeBlockKey(alt('n'))
+	if m.selectedCodeBlock != 1 || m.toast.message != "Code block 1 of 3" {
+		t.Errorf("expected Alt+N to wrap to block 1, got %d (%q)", m.selectedCodeBlock, m.toast.message)
+	}
+
+	m.setCodeBlocks("no code here")
+	if m.selectedCodeBlock != 0 || len(m.codeBlocks) != 0 {
+		t.Errorf("expected a new response to reset the blocks, got %d of %d", m.selectedCodeBlock, len(m.codeBlocks))
+	}
+}
+
+func TestCopyCodeBlock(t *testing.T) {
+	var out bytes.Buffer
+	var system string
+	oldOutput, oldWrite := clipboardOutput, writeSystemClipboard
+	clipboardOutput = &out
+	writeSystemClipboard = func(text string) error { system = text; return nil }
+	t.Cleanup(func() { clipboardOutput, writeSystemClipboard = oldOutput, oldWrite })
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm-256color")
+
+	m := newHeightTestModel(40)
+	m.setCodeBlocks("```go\nfirst\n```\n```go\n\tsecond\n```")
+	m.handleCodeBlockKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}, Alt: true})
+
+	if system != "\tsecond" {
+		t.Errorf("expected the last block on the system clipboard, got %q", system)
+	}
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("\tsecond")) + "\x07"
+	if out.String() != want {
+		t.Errorf("osc52 = %q, want %q", out.String(), want)
+	}
+	if m.toast.message != "Copied code block 2 of 2" {
+		t.Errorf("unexpected toast %q", m.toast.message)
+	}
+}
+
+func TestSaveCodeBlock(t *testing.T) {
+	m := newHeightTestModel(40)
+	m.workspaceDir = t.TempDir()
+	m.setCodeBlocks("```python\nprint('hi')\n```")
+
+	m.handleCodeBlockKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}, Alt: true})
+	if !m.savingCodeBlock || m.textarea.Value() != "code-block-1.py" {
+		t.Fatalf("expected a suggested path, got %v %q", m.savingCodeBlock, m.textarea.Value())
+	}
+
+	m.textarea.SetValue("scripts/hello.py")
+	m.handleEnter(nil, nil, nil)
+	data, err := os.ReadFile(filepath.Join(m.workspaceDir, "scripts", "hello.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "print('hi')\n" {
+		t.Errorf("saved %q", data)
+	}
+	if m.savingCodeBlock || m.textarea.Value() != "" {
+		t.Errorf("expected the prompt to close after saving, got %v %q", m.savingCodeBlock, m.textarea.Value())
+	}
+
+	// Existing files are kept and the prompt stays open for another path
+	m.startSaveCodeBlock()
+	m.textarea.SetValue("scripts/hello.py")
+	m.handleEnter(nil, nil, nil)
+	if !m.savingCodeBlock || m.toast.message != "File exists" {
+		t.Errorf("expected an existing file to be refused, got %v %q", m.savingCodeBlock, m.toast.message)
+	}
+
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc}, nil, nil, nil)
+	if m.savingCodeBlock || m.textarea.Value() != "" {
+		t.Errorf("expected Esc to cancel saving, got %v %q", m.savingCodeBlock, m.textarea.Value())
+	}
+}
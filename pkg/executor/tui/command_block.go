@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	pkgtypes "github.com/entrhq/forge/pkg/types"
+)
+
+const (
+	// commandTailLines is how many of the latest output lines a collapsed
+	// block shows while its command runs.
+	commandTailLines = 8
+
+	// maxCommandBlockLines caps the output a block keeps; the oldest lines
+	// are dropped first. The tool result keeps the full output.
+	maxCommandBlockLines = 1000
+)
+
+// commandStatus is the state of a command shown in a commandBlock.
+type commandStatus int
+
+const (
+	commandRunning commandStatus = iota
+	commandCompleted
+	commandFailed
+	commandCanceled
+)
+
+// commandBlock is the live view of one execute_command run in the
+// conversation. While the command runs it shows a spinner, the elapsed time
+// and the tail of the output; once it finishes it collapses to a status line.
+// Ctrl+O expands the latest block to show all of its output.
+type commandBlock struct {
+	executionID string
+	command     string
+	start       time.Time
+
+	lines   []string // Complete output lines, capped at maxCommandBlockLines
+	partial string   // Output after the last newline
+	dropped int      // Lines dropped from the front of lines
+
+	status     commandStatus
+	exitCode   int
+	duration   time.Duration
+	expanded   bool
+	cancelSent bool
+}
+
+// write appends an output chunk, splitting it into lines.
+func (b *commandBlock) write(chunk string) {
+	chunk = strings.ReplaceAll(sanitizeOutput(chunk), "\t", "    ")
+	parts := strings.Split(b.partial+chunk, "\n")
+	b.partial = parts[len(parts)-1]
+	b.lines = append(b.lines, parts[:len(parts)-1]...)
+	if over := len(b.lines) - maxCommandBlockLines; over > 0 {
+		b.lines = b.lines[over:]
+		b.dropped += over
+	}
+}
+
+// output returns the retained output lines, including a trailing partial line.
+func (b *commandBlock) output() []string {
+	if b.partial == "" {
+		return b.lines
+	}
+	return append(b.lines[:len(b.lines):len(b.lines)], b.partial)
+}
+
+// finish records how the command ended.
+func (b *commandBlock) finish(status commandStatus, exec *pkgtypes.CommandExecution, now time.Time) {
+	b.status = status
+	b.exitCode = exec.ExitCode
+	duration, err := time.ParseDuration(exec.Duration)
+	if err != nil {
+		duration = now.Sub(b.start)
+	}
+	b.duration = duration
+	b.expanded = false
+}
+
+// render draws the block at the given width. spinnerFrame animates the
+// header while the command runs; hint is appended to the header when non-empty.
+func (b *commandBlock) render(width int, spinnerFrame string, now time.Time, hint string) string {
+	contentWidth := max(width-10, 10)
+	command := truncateRunes(strings.Join(strings.Fields(sanitizeOutput(b.command)), " "), contentWidth)
+
+	var icon, detail string
+	iconStyle := toolStyle
+	switch b.status {
+	case commandRunning:
+		icon = spinnerFrame
+		detail = formatCommandDuration(now.Sub(b.start))
+		if b.cancelSent {
+			detail += " · canceling…"
+		}
+	case commandCompleted:
+		icon = "✓"
+		detail = formatCommandDuration(b.duration)
+		if b.exitCode != 0 {
+			iconStyle = errorIconStyle
+			icon = "✗"
+			detail = fmt.Sprintf("exit %d · %s", b.exitCode, detail)
+		}
+	case commandFailed:
+		icon = "✗"
+		iconStyle = errorIconStyle
+		detail = fmt.Sprintf("exit %d · %s", b.exitCode, formatCommandDuration(b.duration))
+	case commandCanceled:
+		icon = "⊘"
+		iconStyle = tipsStyle
+		detail = "canceled after " + formatCommandDuration(b.duration)
+	}
+	if hint != "" {
+		detail += " · " + hint
+	}
+
+	var sb strings.Builder
+	sb.WriteString("  " + iconStyle.Render(icon) + " " + toolStyle.Render("$ "+command) + tipsStyle.Render(" · "+detail))
+
+	lines := b.output()
+	shown := lines
+	switch {
+	case b.expanded:
+	case b.status == commandRunning:
+		shown = lines[max(len(lines)-commandTailLines, 0):]
+	default:
+		shown = nil
+	}
+	if hidden := b.dropped + len(lines) - len(shown); hidden > 0 && len(shown) > 0 {
+		sb.WriteString("\n" + tipsStyle.Render(fmt.Sprintf("    │ … %d earlier lines", hidden)))
+	}
+	for _, line := range shown {
+		sb.WriteString("\n" + tipsStyle.Render("    │ ") + truncateRunes(line, contentWidth))
+	}
+	return sb.String()
+}
+
+// formatCommandDuration renders a command's elapsed time, to the tenth of a
+// second under a minute and to the second above.
+func formatCommandDuration(d time.Duration) string {
+	if d >= time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// truncateRunes shortens s to at most width runes, marking the cut with "…".
+func truncateRunes(s string, width int) string {
+	if runes := []rune(s); len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return s
+}
+
+// errorIconStyle colors the status icon of a failed command.
+var errorIconStyle = lipgloss.NewStyle().Foreground(salmonPink).Bold(true)
+
+// newCommandBlockMsg returns the DisplayMessage that renders a command block.
+// The block is mutable, so the message re-renders as output streams in.
+func (m *model) newCommandBlockMsg(b *commandBlock) DisplayMessage {
+	return DisplayMessage{
+		RenderFn: func(width int) string {
+			return b.render(width, m.spinner.View(), time.Now(), m.commandHint(b))
+		},
+		Trailing: "\n\n",
+	}
+}
+
+// commandHint returns the key hints shown on a block's header. Only the
+// latest block responds to the keys, so older blocks show none.
+func (m *model) commandHint(b *commandBlock) string {
+	if b != m.lastCommand {
+		return ""
+	}
+	var hints []string
+	if len(b.output()) > 0 || b.dropped > 0 {
+		if b.expanded {
+			hints = append(hints, "ctrl+o collapse")
+		} else {
+			hints = append(hints, "ctrl+o expand")
+		}
+	}
+	if b.status == commandRunning && !b.cancelSent {
+		hints = append(hints, "ctrl+x cancel")
+	}
+	return strings.Join(hints, " · ")
+}
+
+// runningCommand returns the running block with the given execution ID.
+func (m *model) runningCommand(executionID string) *commandBlock {
+	for _, b := range m.runningCommands {
+		if b.executionID == executionID {
+			return b
+		}
+	}
+	return nil
+}
+
+// handleCommandExecutionStart adds a live block for the command to the
+// conversation.
+func (m *model) handleCommandExecutionStart(event *pkgtypes.AgentEvent) {
+	if event.CommandExecution == nil {
+		return
+	}
+	b := &commandBlock{
+		executionID: event.CommandExecution.ExecutionID,
+		command:     event.CommandExecution.Command,
+		start:       eventTime(event),
+	}
+	m.runningCommands = append(m.runningCommands, b)
+	m.lastCommand = b
+	m.appendMsg(m.newCommandBlockMsg(b))
+}
+
+// handleCommandExecutionOutput streams an output chunk into its block.
+func (m *model) handleCommandExecutionOutput(event *pkgtypes.AgentEvent) {
+	if event.CommandExecution == nil {
+		return
+	}
+	if b := m.runningCommand(event.CommandExecution.ExecutionID); b != nil {
+		b.write(event.CommandExecution.Output)
+	}
+}
+
+// handleCommandExecutionEnd settles a block once its command completes, fails
+// or is canceled. handleToolResult then summarizes the full output from the
+// EventTypeToolResult that follows.
+func (m *model) handleCommandExecutionEnd(event *pkgtypes.AgentEvent, status commandStatus) {
+	if event.CommandExecution == nil {
+		return
+	}
+	for i, b := range m.runningCommands {
+		if b.executionID == event.CommandExecution.ExecutionID {
+			b.finish(status, event.CommandExecution, eventTime(event))
+			m.runningCommands = append(m.runningCommands[:i], m.runningCommands[i+1:]...)
+			return
+		}
+	}
+}
+
+// handleToggleCommandOutput expands or collapses the latest command block.
+func (m *model) handleToggleCommandOutput() {
+	if m.lastCommand == nil {
+		return
+	}
+	m.lastCommand.expanded = !m.lastCommand.expanded
+	m.recalculateLayout()
+}
+
+// handleCancelCommand asks the agent to cancel the most recently started
+// command that is still running.
+func (m *model) handleCancelCommand() {
+	if len(m.runningCommands) == 0 || m.channels == nil {
+		return
+	}
+	b := m.runningCommands[len(m.runningCommands)-1]
+	if b.cancelSent {
+		return
+	}
+	select {
+	case m.channels.Cancel <- &pkgtypes.CancellationRequest{ExecutionID: b.executionID}:
+		b.cancelSent = true
+	default:
+		m.showToast("Could not cancel command", "The cancel queue is full, try again", "!", true)
+	}
+	m.refreshCommandBlocks()
+}
+
+// refreshCommandBlocks re-renders the conversation so running blocks show
+// the current spinner frame and elapsed time. Unlike recalculateLayout it
+// leaves the scroll position alone, as the content height is unchanged.
+func (m *model) refreshCommandBlocks() {
+	m.viewport.SetContent(m.renderMessages(m.viewport.Width))
+}
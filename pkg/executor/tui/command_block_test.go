@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pkgtypes "github.com/entrhq/forge/pkg/types"
+)
+
+func TestCommandBlock_Write(t *testing.T) {
+	b := &commandBlock{}
+	b.write("one\ntw")
+	b.write("o\x1b[31m\n\tthree")
+
+	got := b.output()
+	want := []string{"one", "two", "    three"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	for i := range maxCommandBlockLines + 5 {
+		b.write(fmt.Sprintf("line %d\n", i))
+	}
+	if len(b.lines) != maxCommandBlockLines || b.dropped != 7 {
+		t.Errorf("lines = %d, dropped = %d", len(b.lines), b.dropped)
+	}
+}
+
+func TestCommandBlock_Render(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := &commandBlock{executionID: "cmd_1", command: "go test ./...", start: start}
+	for i := range 20 {
+		b.write(fmt.Sprintf("line %d\n", i))
+	}
+
+	// A running command shows its elapsed time and output tail
+	out := stripANSI(b.render(80, "*", start.Add(12*time.Second), "ctrl+x cancel"))
+	if !strings.Contains(out, "* $ go test ./... · 12s · ctrl+x cancel") {
+		t.Errorf("header = %q", out)
+	}
+	if !strings.Contains(out, "… 12 earlier lines") || strings.Contains(out, "line 11\n") || !strings.Contains(out, "line 19") {
+		t.Errorf("expected the last %d lines, got %q", commandTailLines, out)
+	}
+
+	// A finished command collapses to its status line
+	b.finish(commandFailed, &pkgtypes.CommandExecution{ExitCode: 2, Duration: "3.24s"}, start)
+	out = stripANSI(b.render(80, "*", start, ""))
+	if out != "  ✗ $ go test ./... · exit 2 · 3.2s" {
+		t.Errorf("collapsed = %q", out)
+	}
+
+	b.expanded = true
+	out = stripANSI(b.render(80, "*", start, ""))
+	if strings.Count(out, "│ line") != 20 {
+		t.Errorf("expected all output when expanded, got %q", out)
+	}
+}
+
+func TestCommandExecutionEvents(t *testing.T) {
+	m := newHeightTestModel(30)
+	m.channels = pkgtypes.NewAgentChannels(1)
+
+	m.handleAgentEvent(pkgtypes.NewCommandExecutionStartEvent("cmd_1", "make build", "/test"))
+	m.handleAgentEvent(pkgtypes.NewCommandOutputEvent("cmd_1", "compiling\n", "stdout"))
+	m.handleAgentEvent(pkgtypes.NewCommandOutputEvent("cmd_2", "unrelated\n", "stdout"))
+
+	if len(m.runningCommands) != 1 || m.lastCommand == nil {
+		t.Fatalf("expected one running command, got %d", len(m.runningCommands))
+	}
+	content := stripANSI(m.renderMessages(80))
+	if !strings.Contains(content, "$ make build") || !strings.Contains(content, "│ compiling") {
+		t.Errorf("content = %q", content)
+	}
+	if strings.Contains(content, "unrelated") {
+		t.Error("expected output for another execution ID to be ignored")
+	}
+
+	// Ctrl+X cancels the running execution, once
+	m.handleCancelCommand()
+	m.handleCancelCommand()
+	select {
+	case req := <-m.channels.Cancel:
+		if req.ExecutionID != "cmd_1" {
+			t.Errorf("canceled %q", req.ExecutionID)
+		}
+	default:
+		t.Fatal("expected a cancellation request")
+	}
+	if len(m.channels.Cancel) != 0 {
+		t.Error("expected a single cancellation request")
+	}
+
+	m.handleAgentEvent(pkgtypes.NewCommandExecutionCanceledEvent("cmd_1", "1.5s"))
+	if len(m.runningCommands) != 0 {
+		t.Error("expected the command to stop running")
+	}
+	content = stripANSI(m.renderMessages(80))
+	if !strings.Contains(content, "canceled after 1.5s · ctrl+o expand") || strings.Contains(content, "│ compiling") {
+		t.Errorf("content = %q", content)
+	}
+
+	// Ctrl+O expands the latest block
+	m.handleToggleCommandOutput()
+	if content = stripANSI(m.renderMessages(80)); !strings.Contains(content, "│ compiling") {
+		t.Errorf("expected the expanded output, got %q", content)
+	}
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	agentcontext "github.com/entrhq/forge/pkg/agent/context"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// parseCompactArgs parses /compact arguments: an optional level and an
+// optional target token count, in either order.
+func parseCompactArgs(args []string) (types.CompactRequestParams, error) {
+	var params types.CompactRequestParams
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil {
+			if n <= 0 || params.TargetTokens != 0 {
+				return params, fmt.Errorf("expected a single positive target token count, got %q", arg)
+			}
+			params.TargetTokens = n
+			continue
+		}
+		if params.Level != "" {
+			return params, fmt.Errorf("expected a single level, got %q and %q", params.Level, arg)
+		}
+		level, err := agentcontext.ParseCompactLevel(arg)
+		if err != nil {
+			return params, err
+		}
+		params.Level = string(level)
+	}
+	return params, nil
+}
+
+// handleCompactCommand asks the agent to summarize its context now. The
+// result arrives as summarization events.
+func handleCompactCommand(m *model, args []string) any {
+	if m.channels == nil {
+		m.showToast("Error", "Agent not available", "✗", true)
+		return nil
+	}
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish or /stop it before compacting", "⚠", true)
+		return nil
+	}
+
+	params, err := parseCompactArgs(args)
+	if err != nil {
+		m.showToast("Invalid /compact arguments", err.Error(), "✗", true)
+		return nil
+	}
+
+	return func() tea.Msg {
+		m.channels.Input <- types.NewCompactRequestInput(params)
+		return operationStartMsg{
+			message: "Compacting context...",
+		}
+	}
+}
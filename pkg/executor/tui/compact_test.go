@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestParseCompactArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    types.CompactRequestParams
+		wantErr bool
+	}{
+		{"defaults", nil, types.CompactRequestParams{}, false},
+		{"level", []string{"aggressive"}, types.CompactRequestParams{Level: "aggressive"}, false},
+		{"target", []string{"50000"}, types.CompactRequestParams{TargetTokens: 50000}, false},
+		{"level and target", []string{"light", "50000"}, types.CompactRequestParams{Level: "light", TargetTokens: 50000}, false},
+		{"target and level", []string{"50000", "normal"}, types.CompactRequestParams{Level: "normal", TargetTokens: 50000}, false},
+		{"unknown level", []string{"max"}, types.CompactRequestParams{}, true},
+		{"two levels", []string{"light", "normal"}, types.CompactRequestParams{}, true},
+		{"zero target", []string{"0"}, types.CompactRequestParams{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCompactArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCompactArgs(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseCompactArgs(%v) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleCompactCommand_RefusesWhileBusy(t *testing.T) {
+	m := initialModel()
+	m.channels = types.NewAgentChannels(1)
+	m.agentBusy = true
+
+	if cmd := handleCompactCommand(&m, nil); cmd != nil {
+		t.Fatal("expected no command while the agent is busy")
+	}
+	if len(m.channels.Input) != 0 {
+		t.Error("expected no compaction request to be sent")
+	}
+}
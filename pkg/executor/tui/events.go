@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	agentcontext "github.com/entrhq/forge/pkg/agent/context"
+	"github.com/entrhq/forge/pkg/agent/recording"
 	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/executor/tui/overlay"
 	"github.com/entrhq/forge/pkg/executor/tui/types"
@@ -19,14 +21,14 @@ import (
 func (m *model) handleAgentEvent(event *pkgtypes.AgentEvent) {
 	switch event.Type {
 	case pkgtypes.EventTypeThinkingStart:
-		m.handleThinkingStart()
+		m.handleThinkingStart(event)
 
 	case pkgtypes.EventTypeThinkingContent:
 		m.handleThinkingContent(event)
 		return // Exit early to preserve streaming viewport update
 
 	case pkgtypes.EventTypeThinkingEnd:
-		m.handleThinkingEnd()
+		m.handleThinkingEnd(event)
 
 	case pkgtypes.EventTypeToolCallStart:
 		m.handleToolCallStart(event)
@@ -61,13 +63,13 @@ func (m *model) handleAgentEvent(event *pkgtypes.AgentEvent) {
 		m.handleToolApprovalRequest(event)
 
 	case pkgtypes.EventTypeToolApprovalGranted:
-		m.handleToolApprovalGranted()
+		m.handleToolApprovalGranted(event)
 
 	case pkgtypes.EventTypeToolApprovalRejected:
-		m.handleToolApprovalRejected()
+		m.handleToolApprovalRejected(event)
 
 	case pkgtypes.EventTypeToolApprovalTimeout:
-		m.handleToolApprovalTimeout()
+		m.handleToolApprovalTimeout(event)
 
 	case pkgtypes.EventTypeAPICallStart:
 		m.handleApiCallStart(event)
@@ -82,7 +84,13 @@ func (m *model) handleAgentEvent(event *pkgtypes.AgentEvent) {
 		m.handleCommandExecutionOutput(event)
 
 	case pkgtypes.EventTypeCommandExecutionComplete:
-		m.handleCommandExecutionComplete(event)
+		m.handleCommandExecutionEnd(event, commandCompleted)
+
+	case pkgtypes.EventTypeCommandExecutionFailed:
+		m.handleCommandExecutionEnd(event, commandFailed)
+
+	case pkgtypes.EventTypeCommandExecutionCanceled:
+		m.handleCommandExecutionEnd(event, commandCanceled)
 
 	case pkgtypes.EventTypeContextSummarizationStart:
 		m.handleContextSummarizationStart(event)
@@ -93,8 +101,23 @@ func (m *model) handleAgentEvent(event *pkgtypes.AgentEvent) {
 	case pkgtypes.EventTypeContextSummarizationComplete:
 		m.handleContextSummarizationComplete(event)
 
+	case pkgtypes.EventTypeContextSummarizationError:
+		m.handleContextSummarizationError(event)
+
 	case pkgtypes.EventTypeNotesData:
 		m.handleNotesData(event)
+
+	case pkgtypes.EventTypeOversizedMessage:
+		m.handleOversizedMessage(event)
+
+	case pkgtypes.EventTypeModelFallback:
+		m.handleModelFallback(event)
+
+	case pkgtypes.EventTypeTurnBudgetExceeded:
+		m.handleTurnBudgetExceeded(event)
+
+	case recording.EventTypeReplayInput:
+		m.appendMsg(newUserMsg(event.Content))
 	}
 
 	m.recalculateLayout()
@@ -136,10 +159,10 @@ func (m *model) resumeFollowScroll() {
 
 // Thinking event handlers
 
-func (m *model) handleThinkingStart() {
+func (m *model) handleThinkingStart(event *pkgtypes.AgentEvent) {
 	m.isThinking = true
 	m.thinkingBuffer.Reset()
-	m.thinkingStartTime = time.Now()
+	m.thinkingStartTime = eventTime(event)
 }
 
 func (m *model) handleThinkingContent(event *pkgtypes.AgentEvent) {
@@ -165,11 +188,11 @@ func (m *model) handleThinkingContent(event *pkgtypes.AgentEvent) {
 	m.scrollToBottomOrMark() // ADR-0048
 }
 
-func (m *model) handleThinkingEnd() {
+func (m *model) handleThinkingEnd(event *pkgtypes.AgentEvent) {
 	if m.thinkingBuffer.Len() > 0 {
 		// Capture raw text so the closure can reflow at any future width.
 		thinkingText := m.thinkingBuffer.String()
-		elapsed := int(time.Since(m.thinkingStartTime).Seconds())
+		elapsed := int(eventTime(event).Sub(m.thinkingStartTime).Seconds())
 
 		if m.showThinking {
 			m.appendMsg(DisplayMessage{
@@ -212,6 +235,8 @@ func (m *model) handleToolCall(event *pkgtypes.AgentEvent) {
 	}
 	// Track tool call for result display and caching.
 	m.lastToolName = event.ToolName
+	m.usage.recordToolCall(event.ToolName)
+	m.trackFilePreviewCall(event)
 	if event.ToolCallID != "" {
 		m.lastToolCallID = event.ToolCallID
 	} else {
@@ -222,6 +247,9 @@ func (m *model) handleToolCall(event *pkgtypes.AgentEvent) {
 
 func (m *model) handleToolResult(event *pkgtypes.AgentEvent) {
 	resultStr := sanitizeOutput(fmt.Sprintf("%v", event.ToolOutput))
+	m.usage.recordToolResult(m.lastToolName, resultStr)
+	m.trackTodoList(event)
+	m.trackFilePreviewResult()
 
 	// Classify the tool result to determine display strategy.
 	tier := m.resultClassifier.ClassifyToolResult(m.lastToolName, resultStr)
@@ -286,6 +314,7 @@ func (m *model) handleMessageEnd() {
 		// terminal resizes automatically reflow the markdown output.
 		msgText := m.messageBuffer.String()
 		m.appendMsg(newMarkdownMsg(m.mdRenderer.RenderFn(msgText), "\n\n"))
+		m.setCodeBlocks(msgText)
 		m.hasMessageContentStarted = false
 	}
 	m.messageBuffer.Reset()
@@ -301,6 +330,11 @@ func (m *model) handleError(event *pkgtypes.AgentEvent) {
 
 func (m *model) handleTurnEnd() {
 	m.agentBusy = false
+	m.approvals.Clear()
+	m.refreshApprovalQueue()
+	m.appendTurnFooter()
+	m.saveUsage()
+	m.checkpointDirty = true
 	// Don't unconditionally resume scroll-following here.
 	// Per ADR-0048, scroll resume should only happen on explicit user intent:
 	// G key, PgDn at bottom, mouse wheel down at bottom, or sending a message.
@@ -317,6 +351,9 @@ func (m *model) handleUpdateBusy(event *pkgtypes.AgentEvent) {
 	if wasBusy != m.agentBusy {
 		m.recalculateLayout()
 	}
+	// The agent reports idle after the turn end, so the next queued message
+	// starts a new turn rather than racing the end of this one
+	m.sendQueuedMessage()
 }
 
 // Tool approval handlers
@@ -325,37 +362,35 @@ func (m *model) handleToolApprovalRequest(event *pkgtypes.AgentEvent) {
 	m.appendMsg(newEntryMsg("  … ", "Requesting tool approval...", toolStyle, "\n"))
 	m.recalculateLayout()
 
-	if event.Preview != nil {
-		preview, ok := event.Preview.(*tools.ToolPreview)
-		if ok {
-			responseFunc := func(response *pkgtypes.ApprovalResponse) {
-				m.channels.Approval <- response
-				m.overlay.deactivate()
-				m.recalculateLayout()
-			}
-
-			diffViewer := overlay.NewDiffViewer(
-				event.ApprovalID,
-				event.ToolName,
-				preview,
-				m.width,
-				m.height,
-				responseFunc,
-			)
-			m.overlay.pushOverlay(types.OverlayModeDiffViewer, diffViewer)
-		}
-	}
+	// Queue every request, even without a preview, so none is lost to a
+	// timeout while another approval or overlay is showing
+	preview, _ := event.Preview.(*tools.ToolPreview)
+	m.approvals.Push(overlay.QueuedApproval{
+		ApprovalID: event.ApprovalID,
+		ToolName:   event.ToolName,
+		ToolInput:  event.ToolInput,
+		Preview:    preview,
+	})
+	m.showApprovalQueue()
+	m.refreshApprovalQueue()
 }
 
-func (m *model) handleToolApprovalGranted() {
+func (m *model) handleToolApprovalGranted(event *pkgtypes.AgentEvent) {
+	m.removeApproval(event.ApprovalID)
 	m.appendMsg(newEntryMsg("  ✓ ", "Tool approved - executing...", toolStyle, "\n"))
 }
 
-func (m *model) handleToolApprovalRejected() {
+func (m *model) handleToolApprovalRejected(event *pkgtypes.AgentEvent) {
+	m.removeApproval(event.ApprovalID)
+	if reason, ok := event.Metadata["reason"].(string); ok && reason != "" {
+		m.appendMsg(newEntryMsg("  ✗ ", "Tool rejected by "+reason, warningStyle, "\n"))
+		return
+	}
 	m.appendMsg(newEntryMsg("  ✗ ", "Tool rejected by user", warningStyle, "\n"))
 }
 
-func (m *model) handleToolApprovalTimeout() {
+func (m *model) handleToolApprovalTimeout(event *pkgtypes.AgentEvent) {
+	m.removeApproval(event.ApprovalID)
 	m.appendMsg(newEntryMsg("  ⏱ ", "Tool approval timed out", warningStyle, "\n"))
 }
 
@@ -366,6 +401,7 @@ func (m *model) handleApiCallStart(event *pkgtypes.AgentEvent) {
 		m.currentContextTokens = event.APICallInfo.ContextTokens
 		m.maxContextTokens = event.APICallInfo.MaxContextTokens
 	}
+	m.turn.recordCallStart(eventTime(event))
 }
 
 func (m *model) handleTokenUsage(event *pkgtypes.AgentEvent) {
@@ -373,41 +409,16 @@ func (m *model) handleTokenUsage(event *pkgtypes.AgentEvent) {
 		m.totalPromptTokens += event.TokenUsage.PromptTokens
 		m.totalCompletionTokens += event.TokenUsage.CompletionTokens
 		m.totalTokens += event.TokenUsage.TotalTokens
+		m.turn.recordUsage(event.TokenUsage.PromptTokens, event.TokenUsage.CompletionTokens, eventTime(event))
+		m.usage.recordCall(event.TokenUsage.PromptTokens, event.TokenUsage.CompletionTokens, eventTime(event))
 	}
 }
 
-// Command execution handlers
-
-func (m *model) handleCommandExecutionStart(event *pkgtypes.AgentEvent) {
-	if event.CommandExecution != nil {
-		command := sanitizeOutput(event.CommandExecution.Command)
-		m.appendMsg(newEntryMsg("  ❯ ", fmt.Sprintf("Executing: %s", command), toolStyle, "\n"))
-		m.recalculateLayout()
-
-		ol := overlay.NewCommandExecutionOverlay(
-			event.CommandExecution.Command,
-			event.CommandExecution.WorkingDir,
-			event.CommandExecution.ExecutionID,
-			m.channels.Cancel,
-		)
-		m.overlay.pushOverlay(types.OverlayModeCommandOutput, ol)
-	}
-}
-
-func (m *model) handleCommandExecutionOutput(_ *pkgtypes.AgentEvent) {
-	// Let overlay handle streaming directly; do not stream bulk to the chat output.
-}
-
-func (m *model) handleCommandExecutionComplete(_ *pkgtypes.AgentEvent) {
-	// handleToolResult will catch the EventTypeToolResult that follows and
-	// write a clean summary using tier logic (TierSummaryWithPreview).
-}
-
 // Context summarization handlers
 
 func (m *model) handleContextSummarizationStart(event *pkgtypes.AgentEvent) {
 	m.summarization.active = true
-	m.summarization.startTime = time.Now()
+	m.summarization.startTime = eventTime(event)
 	if event.ContextSummarization != nil {
 		m.summarization.strategy = event.ContextSummarization.Strategy
 		m.summarization.currentTokens = event.ContextSummarization.CurrentTokens
@@ -425,7 +436,13 @@ func (m *model) handleContextSummarizationComplete(event *pkgtypes.AgentEvent) {
 		newTokens := event.ContextSummarization.NewTokenCount
 
 		m.summarization.active = false
-		duration := time.Since(m.summarization.startTime).Seconds()
+		duration := eventTime(event).Sub(m.summarization.startTime).Seconds()
+
+		// An on-demand /compact can find nothing to summarize
+		if event.ContextSummarization.Strategy == agentcontext.CompactStrategyName && event.ContextSummarization.ItemsProcessed == 0 {
+			m.showToast("Nothing to compact", "The context has no more messages that can be summarized", "◆", false)
+			return
+		}
 
 		m.showToast(
 			"✨ Context optimized",
@@ -438,9 +455,86 @@ func (m *model) handleContextSummarizationComplete(event *pkgtypes.AgentEvent) {
 		)
 
 		m.currentContextTokens = newTokens
+		m.usage.recordSummarization(oldTokens, newTokens)
+	}
+}
+
+func (m *model) handleContextSummarizationError(event *pkgtypes.AgentEvent) {
+	m.summarization.active = false
+	if event.ContextSummarization != nil {
+		m.showToast("Context summarization failed", event.ContextSummarization.ErrorMessage, "✗", true)
 	}
 }
 
+// Oversized message handler
+
+func (m *model) handleOversizedMessage(event *pkgtypes.AgentEvent) {
+	info := event.OversizedMessage
+	if info == nil {
+		return
+	}
+
+	source := "Your message"
+	if info.ToolName != "" {
+		source = fmt.Sprintf("The %s result", sanitizeOutput(info.ToolName))
+	}
+
+	if info.Action == pkgtypes.OversizedActionRejected {
+		m.showToast(
+			"Message too large",
+			fmt.Sprintf("%s was %s tokens, over the %s-token limit, and was not added to the conversation",
+				source, formatTokenCount(info.Tokens), formatTokenCount(info.Limit)),
+			"⚠",
+			true,
+		)
+		return
+	}
+
+	m.showToast(
+		"Message chunked",
+		fmt.Sprintf("%s was %s tokens; kept %s from its start and end to stay under the %s-token limit",
+			source, formatTokenCount(info.Tokens), formatTokenCount(info.KeptTokens), formatTokenCount(info.Limit)),
+		"✂",
+		false,
+	)
+}
+
+// Model fallback handler
+
+func (m *model) handleModelFallback(event *pkgtypes.AgentEvent) {
+	info := event.ModelFallback
+	if info == nil {
+		return
+	}
+	m.showToast(
+		"Rate limited",
+		fmt.Sprintf("%s is rate limited; using %s until %s",
+			sanitizeOutput(info.From), sanitizeOutput(info.To), info.Until.Format(time.Kitchen)),
+		"⇣",
+		false,
+	)
+}
+
+// Turn budget handler
+
+func (m *model) handleTurnBudgetExceeded(event *pkgtypes.AgentEvent) {
+	info := event.TurnBudget
+	if info == nil {
+		return
+	}
+
+	spent := fmt.Sprintf("%d tool calls made this turn", info.ToolCalls)
+	if info.Limit == pkgtypes.TurnBudgetDuration {
+		spent = fmt.Sprintf("This turn has run for %s", info.Elapsed)
+	}
+
+	if info.Stopped {
+		m.showToast("Turn stopped", spent+"; the agent did not wrap up in time", "■", true)
+		return
+	}
+	m.showToast("Turn budget reached", spent+"; asking the agent to wrap up", "⏱", false)
+}
+
 // Notes data handler
 
 func (m *model) handleNotesData(event *pkgtypes.AgentEvent) {
@@ -456,6 +550,16 @@ func (m *model) handleNotesData(event *pkgtypes.AgentEvent) {
 	}
 	m.pendingNotesRequest = false
 
-	notesOverlay := overlay.NewNotesOverlay(event.NotesData.Notes, m.width, m.height)
+	notesOverlay := overlay.NewNotesOverlay(event.NotesData.Notes, m.notesFilter, m.width, m.height)
 	m.overlay.pushOverlay(types.OverlayModeNotes, notesOverlay)
 }
+
+// eventTime returns when event occurred. Agent events are stamped when
+// emitted, so this is accurate even if the UI falls behind the event stream;
+// unstamped events fall back to the time they are handled.
+func eventTime(event *pkgtypes.AgentEvent) time.Time {
+	if event.Timestamp.IsZero() {
+		return time.Now()
+	}
+	return event.Timestamp
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/entrhq/forge/pkg/agent/slash"
 	"github.com/entrhq/forge/pkg/config"
 	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/security/workspace"
 )
 
 // Executor is a TUI-based executor that provides an interactive,
@@ -34,6 +35,8 @@ type Executor struct {
 	workspaceDir    string
 	header          string     // Custom ASCII art header (optional)
 	startupWarnings []toastMsg // Warning toasts shown once at session start
+	readOnly        string     // Explains why input is refused; empty for normal sessions
+	roots           []workspace.Root
 }
 
 // NewExecutor creates a new TUI executor for the given agent.
@@ -63,6 +66,18 @@ func (e *Executor) AddStartupWarning(message, details string, isError bool) {
 	})
 }
 
+// SetReadOnly refuses messages and shell commands for the session, showing
+// reason when the user tries to send one. Slash commands still work. Used to
+// replay recorded sessions.
+func (e *Executor) SetReadOnly(reason string) {
+	e.readOnly = reason
+}
+
+// SetWorkspaceRoots sets the additional workspace roots listed by /roots.
+func (e *Executor) SetWorkspaceRoots(roots []workspace.Root) {
+	e.roots = roots
+}
+
 // Run starts the TUI executor and blocks until the user exits.
 func (e *Executor) Run(ctx context.Context) error {
 	// Start the agent first
@@ -81,8 +96,19 @@ func (e *Executor) Run(ctx context.Context) error {
 	m.channels = e.agent.GetChannels()
 	m.provider = e.provider
 	m.workspaceDir = e.workspaceDir
+	m.roots = e.roots
 	m.header = e.header
 	m.startupWarnings = e.startupWarnings
+	m.readOnly = e.readOnly
+
+	// A checkpoint left behind means the previous session did not exit cleanly
+	if e.workspaceDir != "" {
+		if cp, err := loadCheckpoint(e.workspaceDir); err != nil {
+			log.Printf("Warning: ignoring session checkpoint: %v", err)
+		} else if cp != nil {
+			m.offerRestore(cp)
+		}
+	}
 
 	// Initialize slash handler for git operations
 	if e.provider != nil && e.workspaceDir != "" {
@@ -110,5 +136,13 @@ func (e *Executor) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to run TUI program: %w", err)
 	}
 
+	// Clean exit: drop the auto-save unless it still holds an unrestored
+	// session the user may want next time
+	if e.workspaceDir != "" && m.pendingRestore == nil {
+		if err := removeCheckpoint(e.workspaceDir); err != nil {
+			log.Printf("Warning: failed to remove session checkpoint: %v", err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// fileLinkTrim is the punctuation that surrounds paths in tool results and
+// prose, such as quotes, brackets or a trailing period.
+const fileLinkTrim = "\"'`()[]{}<>,;:."
+
+// handleConversationMouse opens a file in the preview when its path is
+// clicked in the conversation, and scrolls the preview with the wheel when
+// the pointer is over it. It reports whether it used the event.
+func (m *model) handleConversationMouse(msg tea.MouseMsg) bool {
+	if msg.Action != tea.MouseActionPress {
+		return false
+	}
+	if m.filePreviewActive() && msg.X >= m.conversationWidth() {
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.scrollFilePreview(-1)
+			return true
+		case tea.MouseButtonWheelDown:
+			m.scrollFilePreview(1)
+			return true
+		}
+		return false
+	}
+	if msg.Button != tea.MouseButtonLeft {
+		return false
+	}
+
+	row := msg.Y - headerHeight
+	lines := strings.Split(m.viewport.View(), "\n")
+	if row < 0 || row >= len(lines) {
+		return false
+	}
+	path := m.filePathAt(xansi.Strip(lines[row]), msg.X)
+	if path == "" {
+		return false
+	}
+	m.showFileInPreview(path)
+	return true
+}
+
+// filePathAt returns the absolute path of the existing file named by the
+// word covering column x of an unstyled line, or "" when the word is not a
+// file. Relative paths are resolved against the workspace, and a
+// ":line" suffix is dropped.
+func (m *model) filePathAt(line string, x int) string {
+	var word strings.Builder
+	col := 0
+	found := false
+	for _, r := range line {
+		width := xansi.StringWidth(string(r))
+		if unicode.IsSpace(r) {
+			if found {
+				break
+			}
+			word.Reset()
+		} else {
+			word.WriteRune(r)
+			found = found || (x >= col && x < col+width)
+		}
+		col += width
+	}
+	if !found {
+		return ""
+	}
+
+	path := strings.Trim(word.String(), fileLinkTrim)
+	if i := strings.IndexByte(path, ':'); i > 0 {
+		path = path[:i]
+	}
+	if path == "" {
+		return ""
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.workspaceDir, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	return filepath.Clean(path)
+}
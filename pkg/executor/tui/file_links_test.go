@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFilePathAt(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "pkg", "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := newHeightTestModel(30)
+	m.workspaceDir = dir
+
+	line := "✓ read_file (pkg/main.go:12) in pkg"
+	at := func(word string) int { return strings.Index(line, word) - len("✓") + 1 }
+	tests := []struct {
+		name string
+		x    int
+		want string
+	}{
+		{"path with a line suffix", at("main.go"), path},
+		{"first cell of the word", at("(pkg"), path},
+		{"not a file", at("read_file"), ""},
+		{"directory", at("in pkg") + 3, ""},
+		{"space", at(" in"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.filePathAt(line, tt.x); got != tt.want {
+				t.Errorf("filePathAt(x=%d) = %q, want %q", tt.x, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConversationMouse_OpensFileLink(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := newHeightTestModel(30)
+	m.width = 100
+	m.workspaceDir = dir
+	m.viewport.SetContent("You: fix it\n✓ read_file main.go")
+
+	click := tea.MouseMsg{X: strings.Index("✓ read_file main.go", "main.go") - 2, Y: headerHeight + 1, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}
+	if !m.handleConversationMouse(click) {
+		t.Fatal("expected the click on the path to be handled")
+	}
+	if !m.filePreviewActive() || m.preview.path != path || len(m.preview.lines) != 1 {
+		t.Errorf("expected the preview to open on %s, got %+v", path, m.preview)
+	}
+
+	// The wheel over the preview scrolls it instead of the conversation
+	wheel := tea.MouseMsg{X: m.conversationWidth() + 1, Y: headerHeight, Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown}
+	if !m.handleConversationMouse(wheel) {
+		t.Error("expected the wheel over the preview to be handled")
+	}
+	click.Y = headerHeight
+	if m.handleConversationMouse(click) {
+		t.Error("expected a click on plain text not to be handled")
+	}
+}
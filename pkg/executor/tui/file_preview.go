@@ -0,0 +1,369 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
+
+	pkgtypes "github.com/entrhq/forge/pkg/types"
+)
+
+const (
+	// filePreviewWidthPercent is the share of the window the preview pane
+	// takes when the split is on.
+	filePreviewWidthPercent = 45
+
+	// filePreviewMinWindowWidth is the narrowest window that gets the split;
+	// narrower windows keep the full-width conversation.
+	filePreviewMinWindowWidth = 80
+
+	// filePreviewMaxBytes caps the files the preview loads.
+	filePreviewMaxBytes = 512 * 1024
+
+	// filePreviewContextLines is how many lines above the first change stay
+	// in view when the preview follows an edit.
+	filePreviewContextLines = 3
+
+	// filePreviewMaxDiffCells caps the line comparison table; larger edits
+	// fall back to comparing lines position by position.
+	filePreviewMaxDiffCells = 4_000_000
+)
+
+// filePreviewTools are the tools whose "path" argument the preview follows.
+var filePreviewTools = map[string]bool{
+	"read_file":  true,
+	"write_file": true,
+	"apply_diff": true,
+}
+
+// filePreview is the pane beside the conversation that shows the file the
+// agent most recently read or edited, with the lines it changed marked.
+type filePreview struct {
+	enabled     bool   // The split is toggled on
+	pendingPath string // Path of the file tool call awaiting its result
+
+	path         string   // Absolute path of the file shown
+	lines        []string // Its current content
+	changed      []bool   // Lines that differ from the baseline
+	changedCount int
+	offset       int    // First line in view
+	err          string // Why the file could not be shown
+
+	// baselines holds the first content seen of each file, which changes are
+	// measured against when the workspace is not a git repository
+	baselines map[string][]string
+}
+
+// trackFilePreviewCall remembers the path of a file tool call, so its result
+// can bring the file into the preview.
+func (m *model) trackFilePreviewCall(event *pkgtypes.AgentEvent) {
+	m.preview.pendingPath = ""
+	if !filePreviewTools[event.ToolName] {
+		return
+	}
+	if path, ok := event.ToolInput["path"].(string); ok && path != "" {
+		m.preview.pendingPath = path
+	}
+}
+
+// trackFilePreviewResult shows the file of the tool call that just finished.
+func (m *model) trackFilePreviewResult() {
+	if m.preview.pendingPath == "" {
+		return
+	}
+	path := m.preview.pendingPath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.workspaceDir, path)
+	}
+	m.preview.pendingPath = ""
+	m.loadFilePreview(filepath.Clean(path))
+}
+
+// loadFilePreview reads path into the preview, marks the lines that differ
+// from its baseline and scrolls to the first of them.
+func (m *model) loadFilePreview(path string) {
+	p := &m.preview
+	if path != p.path {
+		p.offset = 0
+	}
+	p.path = path
+	p.lines, p.changed, p.changedCount, p.err = nil, nil, 0, ""
+
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		p.err = "File not found"
+		return
+	case info.IsDir():
+		p.err = "Not a file"
+		return
+	case info.Size() > filePreviewMaxBytes:
+		p.err = fmt.Sprintf("File too large to preview (%d KB)", info.Size()/1024)
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		p.err = "File could not be read"
+		return
+	}
+	if strings.IndexByte(string(data), 0) >= 0 {
+		p.err = "Binary file"
+		return
+	}
+
+	p.lines = splitPreviewLines(string(data))
+	p.changed = changedLines(m.filePreviewBaseline(path, p.lines), p.lines)
+	first := -1
+	for i, changed := range p.changed {
+		if changed {
+			p.changedCount++
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	if first >= 0 {
+		p.offset = max(first-filePreviewContextLines, 0)
+	}
+}
+
+// filePreviewBaseline returns the content changes to path are measured
+// against: its last commit in a git workspace (nothing, for files git does
+// not track), otherwise the content the preview first saw.
+func (m *model) filePreviewBaseline(path string, current []string) []string {
+	if content, ok := gitHeadContent(m.workspaceDir, path); ok {
+		return splitPreviewLines(content)
+	}
+	if m.preview.baselines == nil {
+		m.preview.baselines = make(map[string][]string)
+	}
+	baseline, ok := m.preview.baselines[path]
+	if !ok {
+		baseline = current
+		m.preview.baselines[path] = current
+	}
+	return baseline
+}
+
+// gitHeadContent returns path's content at HEAD. ok is false when dir is not
+// a git repository with a commit, or path lies outside it; an untracked file
+// has empty content.
+func gitHeadContent(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", "HEAD").Run(); err != nil {
+		return "", false
+	}
+	out, err := exec.Command("git", "-C", dir, "show", "HEAD:./"+filepath.ToSlash(rel)).Output()
+	if err != nil {
+		return "", true
+	}
+	return string(out), true
+}
+
+// splitPreviewLines splits content into lines, dropping the empty line after
+// a trailing newline.
+func splitPreviewLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// changedLines reports, for each line of current, whether it is new or
+// changed relative to baseline, using the longest common subsequence of the
+// two.
+func changedLines(baseline, current []string) []bool {
+	changed := make([]bool, len(current))
+
+	// Common leading and trailing lines are unchanged; only the middle needs
+	// comparing
+	start := 0
+	for start < len(baseline) && start < len(current) && baseline[start] == current[start] {
+		start++
+	}
+	endB, endC := len(baseline), len(current)
+	for endB > start && endC > start && baseline[endB-1] == current[endC-1] {
+		endB--
+		endC--
+	}
+	old, cur := baseline[start:endB], current[start:endC]
+	if len(cur) == 0 {
+		return changed
+	}
+
+	if len(old) == 0 || (len(old)+1)*(len(cur)+1) > filePreviewMaxDiffCells {
+		for i := range cur {
+			changed[start+i] = i >= len(old) || old[i] != cur[i]
+		}
+		return changed
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of old[i:]
+	// and cur[j:]
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(cur)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(cur) - 1; j >= 0; j-- {
+			if old[i] == cur[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for j < len(cur) {
+		switch {
+		case i < len(old) && old[i] == cur[j]:
+			i++
+			j++
+		case i < len(old) && lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			changed[start+j] = true
+			j++
+		}
+	}
+	return changed
+}
+
+// filePreviewActive reports whether the window is split; the preview is
+// hidden in windows too narrow to share.
+func (m *model) filePreviewActive() bool {
+	return m.preview.enabled && m.width >= filePreviewMinWindowWidth
+}
+
+// filePreviewWidth is the width of the preview pane, including its border.
+func (m *model) filePreviewWidth() int {
+	if !m.filePreviewActive() {
+		return 0
+	}
+	return m.width * filePreviewWidthPercent / 100
+}
+
+// conversationWidth is the width left to the conversation beside the preview.
+func (m *model) conversationWidth() int {
+	return m.width - m.filePreviewWidth()
+}
+
+// handleFilePreviewKey handles Ctrl+F, which toggles the split, and
+// Alt+Up/Alt+Down, which scroll the preview. It reports whether it used the
+// key.
+func (m *model) handleFilePreviewKey(msg tea.KeyMsg) bool {
+	switch {
+	case msg.Type == tea.KeyCtrlF:
+		m.toggleFilePreview()
+		return true
+	case msg.Alt && msg.Type == tea.KeyUp && m.filePreviewActive():
+		m.scrollFilePreview(-1)
+		return true
+	case msg.Alt && msg.Type == tea.KeyDown && m.filePreviewActive():
+		m.scrollFilePreview(1)
+		return true
+	}
+	return false
+}
+
+// toggleFilePreview turns the split on or off and reflows the conversation
+// to its new width.
+func (m *model) toggleFilePreview() {
+	m.preview.enabled = !m.preview.enabled
+	if m.preview.enabled && m.width < filePreviewMinWindowWidth {
+		m.preview.enabled = false
+		m.showToast("Window too narrow", fmt.Sprintf("The file preview needs %d columns", filePreviewMinWindowWidth), "!", true)
+		return
+	}
+	if m.preview.enabled && m.preview.path != "" {
+		m.loadFilePreview(m.preview.path)
+	}
+	m.viewport.Width = m.conversationWidth() - viewportHorizontalPadding
+	m.recalculateLayout()
+}
+
+// showFileInPreview loads path into the preview, turning the split on if
+// it is off.
+func (m *model) showFileInPreview(path string) {
+	if !m.preview.enabled {
+		m.preview.path = path
+		m.toggleFilePreview()
+		return
+	}
+	m.loadFilePreview(path)
+}
+
+// scrollFilePreview moves the preview by a few lines, keeping the last line
+// of the file at or below the bottom of the pane.
+func (m *model) scrollFilePreview(direction int) {
+	const step = 5
+	maxOffset := max(len(m.preview.lines)-(m.viewport.Height-1), 0)
+	m.preview.offset = min(max(m.preview.offset+direction*step, 0), maxOffset)
+}
+
+// renderFilePreview renders the preview pane: a title naming the file and
+// how many of its lines changed, then the lines in view, numbered, with
+// changed lines marked.
+func (m *model) renderFilePreview(width, height int) string {
+	p := &m.preview
+	contentWidth := max(width-2, 1) // Less the left border and its padding
+
+	var rows []string
+	switch {
+	case p.path == "":
+		rows = append(rows, tipsStyle.Render("No file yet"),
+			tipsStyle.Render("Files the agent reads or edits appear here"))
+	default:
+		title := m.relativePreviewPath()
+		switch {
+		case p.err != "":
+			title += " · " + p.err
+		case p.changedCount == 1:
+			title += " · 1 line changed"
+		case p.changedCount > 0:
+			title += fmt.Sprintf(" · %d lines changed", p.changedCount)
+		}
+		rows = append(rows, headerStyle.Render(xansi.Truncate(title, contentWidth, "…")))
+	}
+
+	numberWidth := len(fmt.Sprint(len(p.lines)))
+	textWidth := max(contentWidth-numberWidth-2, 1)
+	for i := p.offset; i < len(p.lines) && len(rows) < height; i++ {
+		text := xansi.Truncate(strings.ReplaceAll(sanitizeOutput(p.lines[i]), "\t", "    "), textWidth, "…")
+		number := fmt.Sprintf("%*d", numberWidth, i+1)
+		if p.changed[i] {
+			rows = append(rows, filePreviewChangedStyle.Render(number+"▎")+" "+filePreviewChangedStyle.Render(text))
+		} else {
+			rows = append(rows, tipsStyle.Render(number+"│")+" "+text)
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(contentWidth + 1).
+		Height(height).
+		MaxHeight(height).
+		PaddingLeft(1).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(dimSep).
+		Render(strings.Join(rows, "\n"))
+}
+
+// relativePreviewPath names the previewed file relative to the workspace
+// when it lies inside it.
+func (m *model) relativePreviewPath() string {
+	if rel, err := filepath.Rel(m.workspaceDir, m.preview.path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return m.preview.path
+}
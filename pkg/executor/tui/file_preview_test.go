@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	pkgtypes "github.com/entrhq/forge/pkg/types"
+)
+
+func TestChangedLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline []string
+		current  []string
+		want     []bool
+	}{
+		{"unchanged", []string{"a", "b"}, []string{"a", "b"}, []bool{false, false}},
+		{"new file", nil, []string{"a", "b"}, []bool{true, true}},
+		{"edited line", []string{"a", "b", "c"}, []string{"a", "B", "c"}, []bool{false, true, false}},
+		{"inserted lines", []string{"a", "c"}, []string{"a", "b1", "b2", "c"}, []bool{false, true, true, false}},
+		{"deleted line", []string{"a", "b", "c"}, []string{"a", "c"}, []bool{false, false}},
+		{"moved line", []string{"a", "b", "c", "d"}, []string{"b", "a", "c", "x", "d"}, []bool{false, true, false, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changedLines(tt.baseline, tt.current); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changedLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilePreview_FollowsToolActivity(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {\n}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := newHeightTestModel(30)
+	m.workspaceDir = dir
+
+	// The first read outside a git repository is the baseline
+	m.trackFilePreviewCall(pkgtypes.NewToolCallEvent("1", "read_file", map[string]any{"path": "main.go"}))
+	m.trackFilePreviewResult()
+	if m.preview.path != path || len(m.preview.lines) != 4 || m.preview.changedCount != 0 {
+		t.Fatalf("preview after read = %+v", m.preview)
+	}
+
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m.trackFilePreviewCall(pkgtypes.NewToolCallEvent("2", "apply_diff", map[string]any{"path": "main.go"}))
+	m.trackFilePreviewResult()
+	if m.preview.changedCount != 1 || !m.preview.changed[3] {
+		t.Errorf("expected line 4 to be marked changed, got %v", m.preview.changed)
+	}
+
+	// Other tools leave the preview on the last file
+	m.trackFilePreviewCall(pkgtypes.NewToolCallEvent("3", "execute_command", map[string]any{"command": "go build"}))
+	m.trackFilePreviewResult()
+	if m.preview.path != path {
+		t.Errorf("preview moved to %q", m.preview.path)
+	}
+
+	pane := stripANSI(m.renderFilePreview(40, 10))
+	if !strings.Contains(pane, "main.go · 1 line changed") || !strings.Contains(pane, `4▎     println("hi")`) {
+		t.Errorf("unexpected pane:\n%s", pane)
+	}
+}
+
+func TestFilePreview_Toggle(t *testing.T) {
+	m := newHeightTestModel(30)
+	m.width = 100
+	m.viewport.Width = m.width - viewportHorizontalPadding
+
+	m.handleFilePreviewKey(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if !m.filePreviewActive() || m.viewport.Width != 55-viewportHorizontalPadding {
+		t.Fatalf("expected the conversation to narrow beside the preview, viewport width %d", m.viewport.Width)
+	}
+	if !strings.Contains(stripANSI(m.renderFilePreview(m.filePreviewWidth(), 5)), "No file yet") {
+		t.Error("expected a placeholder before any file is read")
+	}
+
+	m.handleFilePreviewKey(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if m.filePreviewActive() || m.viewport.Width != 100-viewportHorizontalPadding {
+		t.Errorf("expected the full width back, viewport width %d", m.viewport.Width)
+	}
+
+	// Narrow windows refuse the split
+	m.width = 60
+	m.handleFilePreviewKey(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if m.preview.enabled || !m.toast.active {
+		t.Error("expected a too-narrow toast instead of the split")
+	}
+}
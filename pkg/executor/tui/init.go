@@ -53,10 +53,11 @@ func initialModel() model {
 		messages:         nil,
 		thinkingBuffer:   &strings.Builder{},
 		messageBuffer:    &strings.Builder{},
-		mdRenderer:       markdown.New(""),
+		mdRenderer:       newMarkdownRenderer(),
 		overlay:          newOverlayState(),
 		commandPalette:   overlay.NewCommandPalette(cmdItems),
 		summarization:    &summarizationStatus{},
+		approvals:        overlay.NewApprovalQueue(),
 		toast:            &toastNotification{},
 		spinner:          s,
 		agentBusy:        false,
@@ -80,11 +81,22 @@ func loadShowThinkingSetting() bool {
 	return ui.IsShowThinking()
 }
 
+// newMarkdownRenderer returns the renderer for agent messages, with code
+// highlighting as the syntax_highlighting preference says.
+func newMarkdownRenderer() *markdown.Renderer {
+	r := markdown.New("")
+	if ui := config.GetUI(); ui != nil {
+		r.SetHighlighting(ui.IsSyntaxHighlighting())
+	}
+	return r
+}
+
 // Init is the first function that will be called by Bubble Tea.
-// It returns commands to start the textarea blink animation and spinner,
-// plus any startup warning toasts queued before the session began.
+// It returns commands to start the textarea blink animation, spinner and
+// session auto-save, plus any startup warning toasts queued before the
+// session began.
 func (m *model) Init() tea.Cmd {
-	cmds := []tea.Cmd{textarea.Blink, m.spinner.Tick}
+	cmds := []tea.Cmd{textarea.Blink, m.spinner.Tick, checkpointTick()}
 	for _, w := range m.startupWarnings {
 		cmds = append(cmds, func() tea.Msg { return w })
 	}
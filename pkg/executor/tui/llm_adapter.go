@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/entrhq/forge/pkg/config"
 	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/types"
 )
@@ -31,8 +32,9 @@ func (a *llmAdapter) Generate(ctx context.Context, prompt string) (string, error
 		types.NewUserMessage(prompt),
 	}
 
-	// Use Complete to get the full response
-	response, err := a.provider.Complete(ctx, messages)
+	// Use Complete to get the full response with the commit generator's sampling settings
+	provider := llm.WithSampling(llm.WithResponseCache(a.provider, llm.SharedResponseCache()), llm.SamplingFromConfig(config.SamplingRoleCommit))
+	response, err := provider.Complete(ctx, messages)
 	if err != nil {
 		return "", fmt.Errorf("LLM generation failed: %w", err)
 	}
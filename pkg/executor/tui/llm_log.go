@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/executor/tui/overlay"
+	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// maxLLMLogEntries caps the requests /llmlog lists, newest first.
+const maxLLMLogEntries = 200
+
+// handleLLMLogCommand browses the requests logged to the workspace's
+// .forge/llm-logs.
+func handleLLMLogCommand(m *model, args []string) any {
+	dir := filepath.Join(m.workspaceDir, llm.RequestLogDir)
+	records, err := llm.ReadRequestLog(dir, maxLLMLogEntries)
+	if err != nil {
+		m.showToast("Could not read the LLM log", err.Error(), "✗", true)
+		return nil
+	}
+	if len(records) == 0 {
+		m.showToast("No LLM requests logged", "Start forge with -llm-log to log them", "◆", false)
+		return nil
+	}
+
+	entries := make([]overlay.LLMLogEntry, len(records))
+	for i := range records {
+		entries[i] = llmLogEntry(&records[i])
+	}
+	m.overlay.activate(tuitypes.OverlayModeLLMLog, overlay.NewLLMLogOverlay(entries, dir, m.width, m.height))
+	return nil
+}
+
+// llmLogEntry summarizes a logged request for the list and formats it in
+// full for the detail view.
+func llmLogEntry(record *llm.RequestLogRecord) overlay.LLMLogEntry {
+	duration := formatCommandDuration(time.Duration(record.DurationMS) * time.Millisecond)
+	title := fmt.Sprintf("%s  %s · %s", record.Time.Local().Format("15:04:05"), record.Model, record.Kind)
+
+	var description string
+	switch {
+	case record.Error != "":
+		description = fmt.Sprintf("✗ %s · %s", truncateRunes(strings.SplitN(record.Error, "\n", 2)[0], 80), duration)
+	case record.Response.PromptTokens > 0:
+		description = fmt.Sprintf("%d messages · %s in · %s out · %s", len(record.Request.Messages),
+			formatTokenCount(record.Response.PromptTokens), formatTokenCount(record.Response.CompletionTokens), duration)
+	default:
+		description = fmt.Sprintf("%d messages · %s", len(record.Request.Messages), duration)
+	}
+
+	return overlay.LLMLogEntry{
+		Title:       title,
+		Description: description,
+		Detail:      formatRequestLogRecord(record),
+	}
+}
+
+// formatRequestLogRecord renders a logged request as plain text: what was
+// sent, message by message, then what came back.
+func formatRequestLogRecord(record *llm.RequestLogRecord) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Time:     %s\n", record.Time.Local().Format("2006-01-02 15:04:05.000"))
+	fmt.Fprintf(&b, "Model:    %s\n", record.Model)
+	if record.BaseURL != "" {
+		fmt.Fprintf(&b, "Endpoint: %s\n", record.BaseURL)
+	}
+	fmt.Fprintf(&b, "Duration: %s\n", formatCommandDuration(time.Duration(record.DurationMS)*time.Millisecond))
+	if record.Response.PromptTokens > 0 || record.Response.CompletionTokens > 0 {
+		fmt.Fprintf(&b, "Tokens:   %d prompt · %d completion\n", record.Response.PromptTokens, record.Response.CompletionTokens)
+	}
+	if record.Error != "" {
+		fmt.Fprintf(&b, "Error:    %s\n", record.Error)
+	}
+	if len(record.Request.Tools) > 0 {
+		fmt.Fprintf(&b, "Tools:    %s\n", strings.Join(record.Request.Tools, ", "))
+	}
+
+	request := record.Request
+	if request.MediaType != "" {
+		fmt.Fprintf(&b, "\n── document ──\n%s, %d bytes\n", request.MediaType, request.DocumentBytes)
+		if request.Prompt != "" {
+			fmt.Fprintf(&b, "\n── prompt ──\n%s\n", request.Prompt)
+		}
+	}
+	for i, msg := range request.Messages {
+		heading := fmt.Sprintf("%d · %s", i+1, msg.Role)
+		if msg.ToolCallID != "" {
+			heading += " · result of " + msg.ToolCallID
+		}
+		if msg.Images > 0 {
+			heading += fmt.Sprintf(" · %d images", msg.Images)
+		}
+		fmt.Fprintf(&b, "\n── %s ──\n%s\n", heading, sanitizeOutput(msg.Content))
+		writeLoggedToolCalls(&b, msg.ToolCalls)
+	}
+
+	response := record.Response
+	b.WriteString("\n══ response ══\n")
+	if response.Thinking != "" {
+		fmt.Fprintf(&b, "[thinking]\n%s\n[/thinking]\n", sanitizeOutput(response.Thinking))
+	}
+	b.WriteString(sanitizeOutput(response.Content))
+	b.WriteString("\n")
+	writeLoggedToolCalls(&b, response.ToolCalls)
+	return b.String()
+}
+
+// writeLoggedToolCalls lists native tool calls as name(arguments).
+func writeLoggedToolCalls(b *strings.Builder, calls []llm.RequestLogToolCall) {
+	for _, call := range calls {
+		fmt.Fprintf(b, "→ %s(%s)\n", call.Name, call.Arguments)
+	}
+}
@@ -0,0 +1,304 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	// codePlaceholder stands in for the content of a fenced code block while
+	// glamour lays out the surrounding markdown.  applyCodeBlockBorder swaps
+	// it for the block rendered by renderCodeLines, so code is highlighted
+	// and wrapped by us rather than by glamour's word wrapper.
+	codePlaceholder = "FORGECODEBLOCK"
+
+	// codeWrapANSI marks the rows a long code line was wrapped onto, in the
+	// same muted slate blue as the border.
+	codeWrapANSI = "\x1b[38;2;98;114;164m↪\x1b[0m "
+
+	// codeTabWidth is the number of columns a tab in a code block advances to.
+	codeTabWidth = 4
+
+	// codeStyleName is the chroma style used to highlight code blocks, the
+	// same one the diff previews in the approval overlays use.
+	codeStyleName = "monokai"
+)
+
+var (
+	// fenceOpenPattern matches the opening line of a fenced code block,
+	// capturing its indentation, the fence itself and the info string.
+	fenceOpenPattern = regexp.MustCompile("^( *)(`{3,}|~{3,})(.*)$")
+
+	codeStyleOnce sync.Once
+	codeStyle     *chroma.Style
+)
+
+// codeBlock is a fenced code block taken out of the markdown before glamour
+// renders it.
+type codeBlock struct {
+	language string
+	code     string
+}
+
+// codeSpan is a run of code drawn in one style.
+type codeSpan struct {
+	sgr  string // Escape sequence for the span's style; empty for plain text
+	text string
+}
+
+// extractFencedCode replaces the content of every fenced code block in text
+// with a numbered placeholder and returns the blocks in order.  The fences
+// themselves are kept so glamour still lays the block out (including inside
+// list items), but without a language so it does not spend time
+// highlighting the placeholder.  An unclosed fence runs to the end of the
+// text, as in CommonMark.
+func extractFencedCode(text string) (string, []codeBlock) {
+	if !strings.Contains(text, "```") && !strings.Contains(text, "~~~") {
+		return text, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	var blocks []codeBlock
+	for i := 0; i < len(lines); i++ {
+		match := fenceOpenPattern.FindStringSubmatch(lines[i])
+		if match == nil || (match[2][0] == '`' && strings.Contains(match[3], "`")) {
+			out = append(out, lines[i])
+			continue
+		}
+		indent, fence := match[1], match[2]
+		language := ""
+		if fields := strings.Fields(match[3]); len(fields) > 0 {
+			language = fields[0]
+		}
+
+		var code []string
+		for i++; i < len(lines); i++ {
+			if isClosingFence(lines[i], fence) {
+				break
+			}
+			code = append(code, expandTabs(strings.TrimPrefix(lines[i], indent)))
+		}
+
+		out = append(out,
+			indent+fence,
+			indent+codePlaceholder+strconv.Itoa(len(blocks)),
+			indent+fence,
+		)
+		blocks = append(blocks, codeBlock{language: language, code: strings.Join(code, "\n")})
+	}
+	return strings.Join(out, "\n"), blocks
+}
+
+// isClosingFence reports whether line closes a block opened with fence: the
+// same character, at least as many times, and nothing else.
+func isClosingFence(line, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) < len(fence) || trimmed[0] != fence[0] {
+		return false
+	}
+	return strings.Trim(trimmed, fence[:1]) == ""
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop, so code is
+// measured and wrapped at the width the terminal actually draws it.
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range line {
+		if r == '\t' {
+			n := codeTabWidth - col%codeTabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		b.WriteRune(r)
+		col += runewidth.RuneWidth(r)
+	}
+	return b.String()
+}
+
+// placeholderIndex returns the index of the code block a rendered line stands
+// in for, and the text glamour drew before it (margins, quote bars).
+func placeholderIndex(line string) (int, string, bool) {
+	plain := xansi.Strip(line)
+	pos := strings.Index(plain, codePlaceholder)
+	if pos < 0 {
+		return 0, "", false
+	}
+	digits := plain[pos+len(codePlaceholder):]
+	end := 0
+	for end < len(digits) && digits[end] >= '0' && digits[end] <= '9' {
+		end++
+	}
+	index, err := strconv.Atoi(digits[:end])
+	if err != nil {
+		return 0, "", false
+	}
+	return index, plain[:pos], true
+}
+
+// renderCodeLines renders a code block as lines at most width cells wide,
+// highlighted with chroma unless highlight is false.
+func renderCodeLines(block codeBlock, width int, highlight bool) []string {
+	var lines [][]codeSpan
+	if highlight {
+		lines = highlightCode(block)
+	}
+	if lines == nil {
+		for _, line := range strings.Split(block.code, "\n") {
+			lines = append(lines, []codeSpan{{text: line}})
+		}
+	}
+
+	rows := make([]string, 0, len(lines))
+	for _, spans := range lines {
+		rows = append(rows, wrapCodeLine(spans, width)...)
+	}
+	return rows
+}
+
+// highlightCode splits a code block into lines of styled spans.  The lexer
+// comes from the fence's language, or is guessed from the code; nil is
+// returned when no lexer fits, so the caller renders plain text.
+func highlightCode(block codeBlock) [][]codeSpan {
+	lexer := codeLexer(block.language, block.code)
+	if lexer == nil {
+		return nil
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, block.code)
+	if err != nil {
+		return nil
+	}
+
+	style := getCodeStyle()
+	sgrs := make(map[chroma.TokenType]string)
+	lines := [][]codeSpan{nil}
+	for _, token := range iterator.Tokens() {
+		sgr, ok := sgrs[token.Type]
+		if !ok {
+			sgr = styleSGR(style.Get(token.Type))
+			sgrs[token.Type] = sgr
+		}
+		for i, part := range strings.Split(token.Value, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], codeSpan{sgr: sgr, text: part})
+			}
+		}
+	}
+
+	// Lexers end the code with a newline, which is not a line of its own.
+	if n := strings.Count(block.code, "\n") + 1; len(lines) > n {
+		lines = lines[:n]
+	}
+	return lines
+}
+
+// codeLexer returns the lexer for language, falling back to analysing code.
+func codeLexer(language, code string) chroma.Lexer {
+	if language != "" {
+		if lexer := lexers.Get(language); lexer != nil {
+			return lexer
+		}
+		if lexer := lexers.Match("file." + language); lexer != nil {
+			return lexer
+		}
+	}
+	return lexers.Analyse(code)
+}
+
+// getCodeStyle returns the chroma style for code blocks.
+func getCodeStyle() *chroma.Style {
+	codeStyleOnce.Do(func() {
+		codeStyle = styles.Get(codeStyleName)
+	})
+	return codeStyle
+}
+
+// styleSGR returns the escape sequence for a chroma style entry's foreground
+// and font.  Backgrounds are ignored so the code block's own fill shows
+// through.
+func styleSGR(entry chroma.StyleEntry) string {
+	var params []string
+	if entry.Colour.IsSet() {
+		params = append(params, fmt.Sprintf("38;2;%d;%d;%d", entry.Colour.Red(), entry.Colour.Green(), entry.Colour.Blue()))
+	}
+	if entry.Bold == chroma.Yes {
+		params = append(params, "1")
+	}
+	if entry.Italic == chroma.Yes {
+		params = append(params, "3")
+	}
+	if entry.Underline == chroma.Yes {
+		params = append(params, "4")
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+// wrapCodeLine breaks a line of code into rows at most width cells wide.
+// Rows after the first keep the line's indentation and start with ↪, so a
+// wrapped line reads as one statement rather than several, and each span's
+// style carries over onto the rows it is split across.
+func wrapCodeLine(spans []codeSpan, width int) []string {
+	var plain strings.Builder
+	for _, span := range spans {
+		plain.WriteString(span.text)
+	}
+	indent := len(plain.String()) - len(strings.TrimLeft(plain.String(), " "))
+	if indent > width/2 {
+		indent = width / 2
+	}
+	continuation := strings.Repeat(" ", indent) + codeWrapANSI
+	continuationWidth := indent + 2
+
+	var (
+		rows  []string
+		row   strings.Builder
+		col   int
+		start int // Width of the current row's continuation prefix
+	)
+	for _, span := range spans {
+		open := false
+		for _, r := range span.text {
+			w := runewidth.RuneWidth(r)
+			if col+w > width && col > start {
+				if open {
+					row.WriteString(ansiReset)
+					open = false
+				}
+				rows = append(rows, row.String())
+				row.Reset()
+				row.WriteString(continuation)
+				col, start = continuationWidth, continuationWidth
+			}
+			if !open && span.sgr != "" {
+				row.WriteString(span.sgr)
+				open = true
+			}
+			row.WriteRune(r)
+			col += w
+		}
+		if open {
+			row.WriteString(ansiReset)
+		}
+	}
+	return append(rows, row.String())
+}
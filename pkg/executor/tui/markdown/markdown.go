@@ -59,10 +59,15 @@ const (
 // The cache is invalidated implicitly: a different width key produces a new
 // renderer and the old one is kept alongside it (the set of distinct widths
 // seen in a session is tiny — typically just one or two after a resize).
+//
+// Fenced code blocks are highlighted with chroma and wrapped by the Renderer
+// itself rather than by glamour; see SetHighlighting.
 type Renderer struct {
-	style     string
-	mu        sync.Mutex
-	renderers map[int]*glamour.TermRenderer
+	style      string
+	mu         sync.Mutex
+	renderers  map[int]*glamour.TermRenderer
+	plainCode  bool // Render code blocks without syntax highlighting
+	generation int  // Bumped when a setting changes output, to invalidate RenderFn caches
 }
 
 // New returns a Renderer.  If style is empty the DefaultStyle ("dark") is used.
@@ -77,6 +82,27 @@ func New(style string) *Renderer {
 	}
 }
 
+// SetHighlighting turns syntax highlighting of fenced code blocks on or off.
+// With it off code blocks are still bordered and wrapped, but skip chroma's
+// lexer, which is the expensive part of rendering long diffs and files.
+// Closures returned by RenderFn re-render on their next call.
+func (r *Renderer) SetHighlighting(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.plainCode == !enabled {
+		return
+	}
+	r.plainCode = !enabled
+	r.generation++
+}
+
+// settings returns whether code is highlighted and the current generation.
+func (r *Renderer) settings() (bool, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.plainCode, r.generation
+}
+
 // Render converts markdown text to ANSI-styled output, word-wrapped at width.
 //
 //   - Leading/trailing whitespace is trimmed from the rendered output so callers
@@ -103,13 +129,21 @@ func (r *Renderer) Render(text string, width int) string {
 		return text
 	}
 
-	out, err := gr.Render(text)
+	// Only our own style knows how to draw the code blocks taken out here.
+	source := text
+	var blocks []codeBlock
+	if style == DefaultStyle {
+		source, blocks = extractFencedCode(text)
+	}
+
+	out, err := gr.Render(source)
 	if err != nil {
 		return text
 	}
 
 	if style == DefaultStyle {
-		out = applyCodeBlockBorder(out)
+		highlight, _ := r.settings()
+		out = applyCodeBlockBorder(out, blocks, width, highlight)
 	}
 
 	return strings.TrimRight(out, "\n")
@@ -150,10 +184,14 @@ func darkStyleConfig() ansi.StyleConfig {
 //  3. Padding each line to max_width with background-colored spaces so the
 //     right edge of the block is a solid filled rectangle.
 //
+// A block holding a placeholder from extractFencedCode is replaced with the
+// matching entry of blocks, rendered by renderCodeLines so that the whole
+// block, border included, fits in width.
+//
 // This is a post-processing step because glamour's CodeBlockElement
 // hard-codes its indent callback and the outer StyleBlock BackgroundColor
 // field is silently ignored by that render path.
-func applyCodeBlockBorder(s string) string {
+func applyCodeBlockBorder(s string, blocks []codeBlock, width int, highlight bool) string {
 	lines := strings.Split(s, "\n")
 	out := make([]string, 0, len(lines))
 	inCode := false
@@ -167,6 +205,21 @@ func applyCodeBlockBorder(s string) string {
 	}
 
 	emitBlock := func() {
+		// Swap a placeholder for its code block, drawn after the same prefix
+		// glamour gave the placeholder.  The border and the right margin
+		// take three columns.
+		for _, cl := range codeLines {
+			index, prefix, ok := placeholderIndex(cl)
+			if !ok || index >= len(blocks) {
+				continue
+			}
+			rows := renderCodeLines(blocks[index], width-3-runewidth.StringWidth(prefix), highlight)
+			codeLines = codeLines[:0]
+			for _, row := range rows {
+				codeLines = append(codeLines, prefix+row)
+			}
+			break
+		}
 		// Strip trailing blank/whitespace-only lines inside the block.
 		for len(codeLines) > 0 && strings.TrimSpace(xansi.Strip(codeLines[len(codeLines)-1])) == "" {
 			codeLines = codeLines[:len(codeLines)-1]
@@ -264,21 +317,24 @@ func (r *Renderer) getRenderer(style string, width int) (*glamour.TermRenderer,
 // supplied width before rendering so the output stays within the visible area.
 // This matches the wrapWidth = width-4 convention used throughout helpers.go.
 //
-// The closure caches its last rendered output keyed on width.  During active
+// The closure caches its last rendered output keyed on width and on the
+// Renderer's settings (see SetHighlighting).  During active
 // streaming, renderMessages is called on every token tick at a constant width,
 // so all already-committed messages return their cached string instantly rather
 // than re-invoking glamour on every tick.  On a genuine terminal resize a new
 // width is seen and the output is re-rendered once then cached again.
 func (r *Renderer) RenderFn(text string) func(int) string {
 	var (
-		mu           sync.Mutex
-		cachedWidth  int
-		cachedOutput string
+		mu               sync.Mutex
+		cachedWidth      int
+		cachedGeneration int
+		cachedOutput     string
 	)
 	return func(width int) string {
 		contentWidth := width - 4
+		_, generation := r.settings()
 		mu.Lock()
-		if contentWidth == cachedWidth && cachedOutput != "" {
+		if contentWidth == cachedWidth && generation == cachedGeneration && cachedOutput != "" {
 			out := cachedOutput
 			mu.Unlock()
 			return out
@@ -287,6 +343,7 @@ func (r *Renderer) RenderFn(text string) func(int) string {
 		out := r.Render(text, contentWidth)
 		mu.Lock()
 		cachedWidth = contentWidth
+		cachedGeneration = generation
 		cachedOutput = out
 		mu.Unlock()
 		return out
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/entrhq/forge/pkg/executor/tui/markdown"
+	"github.com/mattn/go-runewidth"
 )
 
 func TestNew_DefaultStyle(t *testing.T) {
@@ -118,6 +119,71 @@ func TestRenderFn_SubtractsViewportPadding(t *testing.T) {
 	}
 }
 
+func TestRender_CodeBlockWrapsWithinWidth(t *testing.T) {
+	r := markdown.New("dark")
+	md := "```go\nfunc main() {\n\tif someCondition && anotherCondition || yetAnotherCondition {\n\t\treturn\n\t}\n}\n```"
+	out := r.Render(md, 40)
+
+	plain := stripANSI(out)
+	for _, line := range strings.Split(plain, "\n") {
+		if w := runewidth.StringWidth(line); w > 40 {
+			t.Errorf("line is %d cells wide, wider than 40: %q", w, line)
+		}
+	}
+	if strings.Contains(plain, "\t") {
+		t.Errorf("expected tabs to be expanded: %q", plain)
+	}
+	// The wrapped row keeps the line's indentation and is marked
+	if !strings.Contains(plain, "      ↪ ") {
+		t.Errorf("expected an indented continuation row: %q", plain)
+	}
+}
+
+func TestRender_CodeBlockHighlighting(t *testing.T) {
+	md := "```go\nfunc main() {}\n```"
+	codeLine := func(out string) string {
+		for _, line := range strings.Split(out, "\n") {
+			if strings.Contains(stripANSI(line), "func main") {
+				return line
+			}
+		}
+		t.Fatalf("code missing from output: %q", out)
+		return ""
+	}
+
+	r := markdown.New("dark")
+	if n := strings.Count(codeLine(r.Render(md, 80)), "\x1b[38;2;"); n < 2 {
+		t.Errorf("expected highlighted code, got %q", codeLine(r.Render(md, 80)))
+	}
+	// Only the border is colored when code is not highlighted
+	r.SetHighlighting(false)
+	if n := strings.Count(codeLine(r.Render(md, 80)), "\x1b[38;2;"); n != 1 {
+		t.Errorf("expected plain code, got %q", codeLine(r.Render(md, 80)))
+	}
+}
+
+func TestRender_CodeBlockInList(t *testing.T) {
+	r := markdown.New("dark")
+	md := "1. Run:\n\n   ```sh\n   make test\n   ```\n\n2. Done"
+	out := stripANSI(r.Render(md, 80))
+	if !strings.Contains(out, "make test") || !strings.Contains(out, "Done") {
+		t.Errorf("list content missing from output: %q", out)
+	}
+	if strings.Contains(out, "FORGECODEBLOCK") {
+		t.Errorf("placeholder leaked into output: %q", out)
+	}
+}
+
+func TestRenderFn_RerendersWhenHighlightingChanges(t *testing.T) {
+	r := markdown.New("dark")
+	fn := r.RenderFn("```go\nfunc main() {}\n```")
+	highlighted := fn(80)
+	r.SetHighlighting(false)
+	if plain := fn(80); plain == highlighted {
+		t.Error("expected the cached output to be replaced after highlighting was turned off")
+	}
+}
+
 // stripANSI is a minimal helper for test assertions that strips common ANSI
 // escape sequences so we can check plain text content.
 func stripANSI(s string) string {
@@ -57,6 +57,24 @@ func newEntryMsg(icon, text string, style lipgloss.Style, trailing string) Displ
 	}
 }
 
+// newUserMsg returns a DisplayMessage for a message typed by the user.
+func newUserMsg(input string) DisplayMessage {
+	// Style icon and text separately for user messages
+	styledIcon := userIconStyle.Render("❯ ")
+	styledText := userTextStyle.Render(input)
+	return DisplayMessage{
+		RenderFn: func(width int) string {
+			wrapWidth := width - 4
+			if wrapWidth <= 0 {
+				wrapWidth = 80
+			}
+			wrapped := wordWrap(styledText, wrapWidth)
+			return styledIcon + wrapped
+		},
+		Trailing: "\n\n",
+	}
+}
+
 // newRawMsg returns a DisplayMessage that emits pre-rendered text verbatim,
 // ignoring width. Use only when the caller has already handled formatting
 // (e.g. bash prompt lines rendered with bashPromptStyle).
@@ -10,10 +10,12 @@ import (
 	"github.com/entrhq/forge/pkg/agent"
 	"github.com/entrhq/forge/pkg/agent/git"
 	"github.com/entrhq/forge/pkg/agent/slash"
+	"github.com/entrhq/forge/pkg/agent/todo"
 	"github.com/entrhq/forge/pkg/executor/tui/approval"
 	"github.com/entrhq/forge/pkg/executor/tui/markdown"
 	"github.com/entrhq/forge/pkg/executor/tui/overlay"
 	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/security/workspace"
 	"github.com/entrhq/forge/pkg/types"
 )
 
@@ -33,6 +35,7 @@ type model struct {
 	// Git and slash command support
 	slashHandler *slash.Handler
 	workspaceDir string
+	roots        []workspace.Root // Additional workspace roots, listed by /roots
 	commitGen    *git.CommitMessageGenerator
 	prGen        *git.PRGenerator
 
@@ -63,8 +66,33 @@ type model struct {
 	showThinking          bool      // Toggle display of extended thinking blocks
 	thinkingStartTime     time.Time // When the current thinking block began (for elapsed display)
 	currentLoadingMessage string
-	toolNameDisplayed     bool // Track if we've already displayed the tool name
-	pendingNotesRequest   bool // Track if we're waiting for notes data
+	toolNameDisplayed     bool   // Track if we've already displayed the tool name
+	pendingNotesRequest   bool   // Track if we're waiting for notes data
+	notesFilter           string // Tag the notes overlay opens filtered to
+	readOnly              string // Why the session refuses messages, e.g. a replay; empty when it takes them
+
+	// Tool approvals awaiting a decision
+	approvals *overlay.ApprovalQueue
+
+	// Large pastes saved to disk and awaiting the next message
+	pastedSnippets []pastedSnippet
+
+	// Messages sent while the agent was busy, oldest first, awaiting their turn
+	queuedMessages []queuedMessage
+
+	// Last message sent to the agent, which Up recalls for editing and /retry resends
+	lastInput   string
+	editingLast bool // The input holds the recalled message; sending it replaces the last turn
+
+	// Code blocks of the last assistant message, for Alt+N/P/Y/S
+	codeBlocks        []codeBlock
+	selectedCodeBlock int  // 1-based; 0 when none is selected
+	savingCodeBlock   bool // The input holds the path to save the selected block to
+
+	// Session auto-save and crash recovery
+	checkpointDirty  bool               // Session changed since the last auto-save
+	checkpointFailed bool               // Last auto-save failed (suppresses repeat toasts)
+	pendingRestore   *sessionCheckpoint // Checkpoint left by a crashed session, offered via /restore
 
 	// Window dimensions
 	width  int
@@ -75,11 +103,14 @@ type model struct {
 	hasMessageContentStarted bool
 
 	// Token usage tracking
-	totalPromptTokens     int // Cumulative input tokens across all API calls
-	totalCompletionTokens int // Cumulative output tokens across all API calls
-	totalTokens           int // Cumulative total tokens (input + output)
-	currentContextTokens  int // Current conversation context size
-	maxContextTokens      int // Maximum allowed context size
+	totalPromptTokens     int       // Cumulative input tokens across all API calls
+	totalCompletionTokens int       // Cumulative output tokens across all API calls
+	totalTokens           int       // Cumulative total tokens (input + output)
+	currentContextTokens  int       // Current conversation context size
+	maxContextTokens      int       // Maximum allowed context size
+	turn                  turnStats // Usage of the in-progress turn, shown as a footer when it ends
+	usage                 usageLedger
+	usageSaveFailed       bool // A usage file write failed; suppresses repeat toasts
 
 	// Tool result display
 	resultClassifier *ToolResultClassifier
@@ -89,6 +120,16 @@ type model struct {
 	lastToolCallID   string                  // Track the last tool call for 'v' shortcut
 	lastToolName     string                  // Track the last tool name
 
+	// The agent's plan, as last reported by the todo tools
+	todos []todo.Item
+
+	// The file beside the conversation when the window is split (Ctrl+F)
+	preview filePreview
+
+	// Live command output blocks
+	runningCommands []*commandBlock // Blocks of commands still running, oldest first
+	lastCommand     *commandBlock   // Most recent block, which Ctrl+O expands
+
 	// Scroll-lock state (ADR-0048)
 	followScroll  bool // true = auto-follow agent output; false = user has scrolled up
 	hasNewContent bool // true = new content arrived while scroll is locked
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/executor/tui/overlay"
+	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// summarizationModel returns the model used for context summarization,
+// which is the main model unless an override is set.
+func (m *model) summarizationModel() string {
+	if defaultAgent, ok := m.agent.(*agent.DefaultAgent); ok {
+		if model := defaultAgent.GetSummarizationModel(); model != "" {
+			return model
+		}
+	}
+	if m.provider != nil {
+		return m.provider.GetModel()
+	}
+	return ""
+}
+
+// modelChoices lists the models in use followed by the ones configured in
+// llm.models, each once, with their context size and price.
+func (m *model) modelChoices() []overlay.ModelChoice {
+	var mainModel string
+	if m.provider != nil {
+		mainModel = m.provider.GetModel()
+	}
+	summarizationModel := m.summarizationModel()
+
+	names := []string{mainModel, summarizationModel}
+	if llmCfg := config.GetLLM(); llmCfg != nil {
+		names = append(names, llmCfg.GetModel(), llmCfg.GetSummarizationModel())
+		for _, option := range llmCfg.GetModels() {
+			names = append(names, option.Name)
+		}
+	}
+
+	var choices []overlay.ModelChoice
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		choice := overlay.ModelChoice{
+			Name:          name,
+			Main:          name == mainModel,
+			Summarization: name == summarizationModel,
+		}
+		choice.ContextTokens, _ = llm.ContextWindowForModel(name)
+		if price, ok := llm.PricingForModel(name); ok {
+			choice.InputPrice = price.InputPerMillion
+			choice.OutputPrice = price.OutputPerMillion
+			choice.PriceKnown = true
+		}
+		choices = append(choices, choice)
+	}
+	return choices
+}
+
+// switchModel points the main or summarization model at name for the rest of
+// the session and records the switch in the transcript. The config file is
+// left unchanged.
+func (m *model) switchModel(name string, role overlay.ModelRole) error {
+	cloner, ok := m.provider.(llm.ModelCloner)
+	if !ok {
+		return fmt.Errorf("the current provider does not support switching models")
+	}
+
+	var notice string
+	switch role {
+	case overlay.ModelRoleMain:
+		if name == m.provider.GetModel() {
+			return nil
+		}
+		provider := cloner.CloneWithModel(name)
+		agentProvider := llm.WithSampling(llm.WithRequestLog(provider, llm.SharedRequestLog()), llm.SamplingFromConfig(config.SamplingRoleAgent))
+		agentProvider = llm.WithFallback(agentProvider, llm.FallbackModelFromConfig())
+		if err := m.agent.SetProvider(agentProvider); err != nil {
+			return fmt.Errorf("failed to update agent provider: %w", err)
+		}
+		notice = fmt.Sprintf("Switched main model from %s to %s", m.provider.GetModel(), name)
+		m.provider = provider
+
+	case overlay.ModelRoleSummarization:
+		defaultAgent, ok := m.agent.(*agent.DefaultAgent)
+		if !ok {
+			return fmt.Errorf("this agent does not support a separate summarization model")
+		}
+		if name == m.summarizationModel() {
+			return nil
+		}
+		notice = fmt.Sprintf("Switched summarization model from %s to %s", m.summarizationModel(), name)
+		defaultAgent.SetSummarizationModel(name)
+	}
+
+	m.appendMsg(newEntryMsg("⇄ ", notice, tipsStyle, "\n\n"))
+	m.recalculateLayout()
+	return nil
+}
+
+// handleModelCommand opens the model switcher, or with an argument switches
+// the main model directly.
+func handleModelCommand(m *model, args []string) any {
+	if m.provider == nil || m.agent == nil {
+		m.showToast("Error", "Agent not available", "✗", true)
+		return nil
+	}
+
+	onSelect := func(name string, role overlay.ModelRole) {
+		if err := m.switchModel(name, role); err != nil {
+			m.showToast("Model not switched", err.Error(), "✗", true)
+			return
+		}
+		m.showToast("Model switched", name+" takes effect from the next LLM call", "⇄", false)
+	}
+
+	if len(args) == 1 {
+		onSelect(args[0], overlay.ModelRoleMain)
+		return nil
+	}
+
+	modelOverlay := overlay.NewModelOverlay(m.modelChoices(), m.width, m.height, onSelect)
+	m.overlay.activate(tuitypes.OverlayModeModel, modelOverlay)
+	return nil
+}
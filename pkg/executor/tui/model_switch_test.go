@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/executor/tui/overlay"
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/llm/openai"
+)
+
+// switchAgent records the provider it is switched to. Only SetProvider is
+// implemented.
+type switchAgent struct {
+	agent.Agent
+	provider llm.Provider
+}
+
+func (a *switchAgent) SetProvider(provider llm.Provider) error {
+	a.provider = provider
+	return nil
+}
+
+func TestSwitchModel_Main(t *testing.T) {
+	provider, err := openai.NewProvider("test-key", openai.WithModel("gpt-4o"))
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	ag := &switchAgent{}
+
+	m := initialModel()
+	m.provider = provider
+	m.agent = ag
+
+	if err := m.switchModel("gpt-4.1-mini", overlay.ModelRoleMain); err != nil {
+		t.Fatalf("switchModel: %v", err)
+	}
+
+	if got := m.provider.GetModel(); got != "gpt-4.1-mini" {
+		t.Errorf("expected TUI provider model gpt-4.1-mini, got %s", got)
+	}
+	if ag.provider == nil || ag.provider.GetModel() != "gpt-4.1-mini" {
+		t.Errorf("expected agent to be switched to gpt-4.1-mini, got %v", ag.provider)
+	}
+	if transcript := m.renderMessages(120); !strings.Contains(transcript, "Switched main model from gpt-4o to gpt-4.1-mini") {
+		t.Errorf("expected the switch in the transcript, got:\n%s", transcript)
+	}
+
+	// Switching to the current model is a no-op
+	messages := len(m.messages)
+	if err := m.switchModel("gpt-4.1-mini", overlay.ModelRoleMain); err != nil {
+		t.Fatalf("switchModel: %v", err)
+	}
+	if len(m.messages) != messages {
+		t.Error("expected no transcript entry when the model is unchanged")
+	}
+}
+
+func TestModelChoices(t *testing.T) {
+	provider, err := openai.NewProvider("test-key", openai.WithModel("gpt-4o"))
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	m := initialModel()
+	m.provider = provider
+	m.agent = &switchAgent{}
+
+	choices := m.modelChoices()
+	if len(choices) == 0 || choices[0].Name != "gpt-4o" {
+		t.Fatalf("expected the main model first, got %+v", choices)
+	}
+	first := choices[0]
+	if !first.Main || !first.Summarization {
+		t.Errorf("expected gpt-4o to be the main and summarization model, got %+v", first)
+	}
+	if first.ContextTokens != 128_000 || !first.PriceKnown {
+		t.Errorf("expected context size and price for gpt-4o, got %+v", first)
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"github.com/entrhq/forge/pkg/types"
 )
 
-// requestNotes sends a notes list request to the agent
-func (m *model) requestNotes() tea.Cmd {
+// requestNotes sends a notes list request to the agent. Every note is
+// requested so the overlay can switch filters; it opens filtered to tag.
+func (m *model) requestNotes(tag string) tea.Cmd {
 	// Mark that we're waiting for notes data
 	m.pendingNotesRequest = true
+	m.notesFilter = tag
 
 	return func() tea.Msg {
 		// Create notes request input
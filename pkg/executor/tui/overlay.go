@@ -3,6 +3,7 @@ package tui
 import (
 	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/entrhq/forge/pkg/executor/tui/types"
 )
@@ -96,6 +97,15 @@ func (o *overlayState) isActive() bool {
 	return true
 }
 
+// overlayMouseMsg maps a mouse event from screen coordinates to coordinates
+// relative to the top-left corner of the overlay as renderOverlay places it.
+func overlayMouseMsg(msg tea.MouseMsg, overlay types.Overlay, width, height int) tea.MouseMsg {
+	w, h := lipgloss.Size(overlay.View())
+	msg.X -= types.CenterOffset(width, w)
+	msg.Y -= types.CenterOffset(height, h)
+	return msg
+}
+
 // renderOverlay renders an overlay centered on a clean background
 // This creates a modal appearance by not showing the base view underneath
 func renderOverlay(baseView string, overlay types.Overlay, width, height int) string {
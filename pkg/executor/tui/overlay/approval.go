@@ -77,6 +77,16 @@ func (a *GenericApprovalOverlay) Update(msg tea.Msg, state types.StateProvider,
 		// Viewport will re-wrap content automatically when dimensions change
 	}
 
+	// Clicking a button answers like pressing Enter with it selected
+	if mouseMsg, ok := msg.(tea.MouseMsg); ok {
+		if choice, clicked := a.ButtonAt(a.View(), mouseMsg); clicked {
+			if choice == ApprovalChoiceAccept {
+				return nil, a.request.OnApprove()
+			}
+			return nil, a.request.OnReject()
+		}
+	}
+
 	// Check if this is an approval/rejection key before delegating to base
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		switch keyMsg.String() {
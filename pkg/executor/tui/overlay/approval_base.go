@@ -1,10 +1,20 @@
 package overlay
 
 import (
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/entrhq/forge/pkg/executor/tui/types"
 )
 
+// ApprovalChoice is the button selected in an approval overlay.
+type ApprovalChoice int
+
+const (
+	ApprovalChoiceAccept ApprovalChoice = iota
+	ApprovalChoiceReject
+)
+
 // ApprovalOverlayBase provides common functionality for approval-style overlays.
 // This includes accept/reject button handling, selection toggling, and standard approval UI.
 type ApprovalOverlayBase struct {
@@ -157,6 +167,22 @@ func (a *ApprovalOverlayBase) RenderButtons() string {
 	return acceptBtn + spacer + rejectBtn
 }
 
+// ButtonAt returns the button a left click at msg lands on, given the
+// overlay's rendered view.
+func (a *ApprovalOverlayBase) ButtonAt(view string, msg tea.MouseMsg) (ApprovalChoice, bool) {
+	if !isLeftClick(msg) {
+		return 0, false
+	}
+	line := viewLine(view, msg.Y)
+	switch {
+	case labelAt(line, strings.TrimSpace(a.approveLabel), msg.X):
+		return ApprovalChoiceAccept, true
+	case labelAt(line, strings.TrimSpace(a.rejectLabel), msg.X):
+		return ApprovalChoiceReject, true
+	}
+	return 0, false
+}
+
 // RenderHints renders the keyboard hints
 func (a *ApprovalOverlayBase) RenderHints() string {
 	if !a.showHints {
@@ -0,0 +1,311 @@
+package overlay
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/executor/tui/syntax"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// maxQueueRows caps how many queued requests are listed above the preview.
+const maxQueueRows = 6
+
+// QueuedApproval is a tool approval request waiting for a decision.
+type QueuedApproval struct {
+	ApprovalID string
+	ToolName   string
+	ToolInput  map[string]any
+	Preview    *tools.ToolPreview // May be nil for tools without a preview
+}
+
+// title returns a one-line description of the request.
+func (q QueuedApproval) title() string {
+	if q.Preview != nil && q.Preview.Title != "" {
+		return q.Preview.Title
+	}
+	return q.ToolName
+}
+
+// content returns the detail shown for the request: its highlighted diff or
+// preview, under a notice when it reaches outside the workspace, or its
+// arguments when the tool has no preview.
+func (q QueuedApproval) content() string {
+	if q.Preview == nil {
+		if len(q.ToolInput) == 0 {
+			return types.OverlaySubtitleStyle.Render("(no arguments)")
+		}
+		keys := make([]string, 0, len(q.ToolInput))
+		for key := range q.ToolInput {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var b strings.Builder
+		for _, key := range keys {
+			fmt.Fprintf(&b, "%s: %v\n", key, q.ToolInput[key])
+		}
+		return strings.TrimSuffix(b.String(), "\n")
+	}
+
+	content := q.Preview.Content
+	if q.Preview.Type == tools.PreviewTypeDiff {
+		language, _ := q.Preview.Metadata["language"].(string)
+		if highlighted, err := syntax.HighlightDiff(q.Preview.Content, language); err == nil {
+			content = highlighted
+		}
+	}
+
+	// Changes to allowed directories outside the workspace are called out
+	if outside, _ := q.Preview.Metadata["outside_workspace"].(string); outside != "" {
+		notice := lipgloss.NewStyle().Bold(true).Foreground(types.ProgressYellow).Render("⚠ Outside the workspace: " + outside)
+		content = notice + "\n\n" + content
+	}
+	return content
+}
+
+// ApprovalQueue holds pending tool approvals in arrival order. The TUI keeps
+// one queue for the session so requests that arrive while the queue overlay
+// is hidden or covered are never dropped.
+type ApprovalQueue struct {
+	items []QueuedApproval
+}
+
+// NewApprovalQueue creates an empty approval queue.
+func NewApprovalQueue() *ApprovalQueue {
+	return &ApprovalQueue{}
+}
+
+// Push appends a request to the queue.
+func (q *ApprovalQueue) Push(item QueuedApproval) {
+	q.items = append(q.items, item)
+}
+
+// Remove drops the request with approvalID and reports whether it was queued.
+func (q *ApprovalQueue) Remove(approvalID string) bool {
+	for i, item := range q.items {
+		if item.ApprovalID == approvalID {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear drops every queued request.
+func (q *ApprovalQueue) Clear() {
+	q.items = nil
+}
+
+// Len returns the number of queued requests.
+func (q *ApprovalQueue) Len() int {
+	return len(q.items)
+}
+
+// Items returns a copy of the queued requests.
+func (q *ApprovalQueue) Items() []QueuedApproval {
+	return append([]QueuedApproval(nil), q.items...)
+}
+
+// ApprovalQueueOverlay lists pending tool approvals with a preview of the
+// selected one, so each can be approved or rejected with a single keystroke
+// instead of opening one modal per request.
+type ApprovalQueueOverlay struct {
+	*BaseOverlay
+	queue      *ApprovalQueue
+	selected   int
+	shownID    string // Approval whose content is in the viewport
+	respond    func(approvalID string, granted bool)
+	approveAll func()
+}
+
+// NewApprovalQueueOverlay creates an overlay over queue. respond is called
+// with each decision and must remove the request from the queue; approveAll
+// approves every request currently queued.
+func NewApprovalQueueOverlay(queue *ApprovalQueue, width, height int, respond func(approvalID string, granted bool), approveAll func()) *ApprovalQueueOverlay {
+	overlayWidth := types.ComputeOverlayWidth(width, 0.90, 60, 140)
+	viewportHeight := max(types.ComputeViewportHeight(height, 8)-(maxQueueRows+1), 5)
+
+	o := &ApprovalQueueOverlay{
+		queue:      queue,
+		respond:    respond,
+		approveAll: approveAll,
+	}
+
+	o.BaseOverlay = NewBaseOverlay(BaseOverlayConfig{
+		Width:          overlayWidth,
+		Height:         viewportHeight + maxQueueRows + 8,
+		ViewportWidth:  overlayWidth - 4,
+		ViewportHeight: viewportHeight,
+		RenderHeader:   o.renderHeader,
+		RenderFooter:   o.renderFooter,
+	})
+	o.Refresh()
+	return o
+}
+
+// Refresh re-reads the queue after requests were added or removed outside
+// the overlay, keeping the selection in range.
+func (o *ApprovalQueueOverlay) Refresh() {
+	items := o.queue.Items()
+	if len(items) == 0 {
+		o.selected = 0
+		o.shownID = ""
+		o.SetContent("")
+		return
+	}
+	o.selected = min(max(o.selected, 0), len(items)-1)
+
+	item := items[o.selected]
+	if item.ApprovalID != o.shownID {
+		o.shownID = item.ApprovalID
+		o.SetContent(item.content())
+		o.Viewport().GotoTop()
+	}
+}
+
+// Update handles messages for the approval queue overlay.
+func (o *ApprovalQueueOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		o.SetDimensions(types.ComputeOverlayWidth(msg.Width, 0.90, 60, 140), o.Height())
+		vp := o.Viewport()
+		vp.Width = o.Width() - 4
+		vp.Height = max(types.ComputeViewportHeight(msg.Height, 8)-(maxQueueRows+1), 5)
+		return o, nil
+
+	case tea.MouseMsg:
+		// Clicking a queued request selects it; the wheel scrolls the preview
+		if i, ok := o.requestAt(msg.Y); ok && isLeftClick(msg) {
+			o.selected = i
+			o.Refresh()
+			return o, nil
+		}
+		_, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
+		o.BaseOverlay = updatedBase
+		return o, cmd
+
+	case tea.KeyMsg:
+		items := o.queue.Items()
+		if len(items) == 0 {
+			return nil, nil
+		}
+		current := items[min(o.selected, len(items)-1)]
+
+		switch msg.String() {
+		case keyEsc, keyCtrlC:
+			// Hide the panel; requests stay queued until answered or timed out
+			if actions != nil {
+				actions.ShowToast("Approvals hidden", fmt.Sprintf("%d pending. Type /approvals to reopen", len(items)), "⏸", false)
+			}
+			return nil, nil
+		case "y", keyEnter, keyCtrlA:
+			o.respond(current.ApprovalID, true)
+		case "n", keyCtrlR:
+			o.respond(current.ApprovalID, false)
+		case "A":
+			o.approveAll()
+		case "R":
+			for _, item := range items {
+				o.respond(item.ApprovalID, false)
+			}
+		case "j", keyTab:
+			o.selected = (o.selected + 1) % len(items)
+		case "k", "shift+tab":
+			o.selected = (o.selected - 1 + len(items)) % len(items)
+		default:
+			// Arrow keys and page keys scroll the preview
+			_, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
+			o.BaseOverlay = updatedBase
+			return o, cmd
+		}
+
+		if o.queue.Len() == 0 {
+			return nil, nil
+		}
+		o.Refresh()
+		return o, nil
+	}
+
+	return o, nil
+}
+
+// visibleRows returns the range of the n queued requests listed, keeping
+// the selected one visible when the list is longer than the panel.
+func (o *ApprovalQueueOverlay) visibleRows(n int) (start, end int) {
+	if o.selected >= maxQueueRows {
+		start = o.selected - maxQueueRows + 1
+	}
+	return start, min(start+maxQueueRows, n)
+}
+
+// requestAt returns the index of the queued request listed on row y of the
+// view, below the border and the title.
+func (o *ApprovalQueueOverlay) requestAt(y int) (int, bool) {
+	start, end := o.visibleRows(o.queue.Len())
+	i := start + y - 2
+	return i, i >= start && i < end
+}
+
+// renderHeader renders the title and the list of queued requests.
+func (o *ApprovalQueueOverlay) renderHeader() string {
+	items := o.queue.Items()
+	contentWidth := o.Viewport().Width
+
+	var header strings.Builder
+	header.WriteString(types.OverlayTitleStyle.Render("Tool Approval Required"))
+	header.WriteString(types.OverlaySubtitleStyle.Render(fmt.Sprintf("  %d pending", len(items))))
+	header.WriteString("\n")
+
+	start, end := o.visibleRows(len(items))
+
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink)
+	for i := start; i < end; i++ {
+		line := fmt.Sprintf("%d. %s: %s", i+1, items[i].ToolName, items[i].title())
+		if lipgloss.Width(line) > contentWidth-2 {
+			line = truncateRunes(line, contentWidth-3) + "…"
+		}
+		if i == o.selected {
+			header.WriteString(selectedStyle.Render("▸ " + line))
+		} else {
+			header.WriteString(types.OverlaySubtitleStyle.Render("  " + line))
+		}
+		header.WriteString("\n")
+	}
+	if hidden := len(items) - end; hidden > 0 {
+		header.WriteString(types.OverlaySubtitleStyle.Render(fmt.Sprintf("  … %d more", hidden)))
+		header.WriteString("\n")
+	}
+
+	separator := lipgloss.NewStyle().Foreground(types.MutedGray).Render(strings.Repeat(sepChar, contentWidth))
+	header.WriteString(separator)
+	return header.String()
+}
+
+// renderFooter renders the key hints.
+func (o *ApprovalQueueOverlay) renderFooter() string {
+	contentWidth := o.Viewport().Width
+	separator := lipgloss.NewStyle().Foreground(types.MutedGray).Render(strings.Repeat(sepChar, contentWidth))
+	hints := types.OverlayHelpStyle.Render("y/Enter: approve • n: reject • A: approve all • R: reject all • j/k: select • ↑/↓: scroll • Esc: hide")
+	return separator + "\n" + hints
+}
+
+// View renders the approval queue overlay.
+func (o *ApprovalQueueOverlay) View() string {
+	return o.BaseOverlay.View(o.Width())
+}
+
+// truncateRunes shortens s to at most n runes.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if n <= 0 {
+		return ""
+	}
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
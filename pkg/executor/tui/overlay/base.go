@@ -64,6 +64,8 @@ func (b *BaseOverlay) Update(msg tea.Msg, actions types.ActionHandler) (bool, *B
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return b.handleKeyMsg(msg, actions)
+	case tea.MouseMsg:
+		return b.handleMouseMsg(msg)
 	case tea.WindowSizeMsg:
 		return b.handleWindowResize(msg)
 	}
@@ -94,6 +96,19 @@ func (b *BaseOverlay) handleKeyMsg(msg tea.KeyMsg, actions types.ActionHandler)
 	return false, b, nil
 }
 
+// handleMouseMsg scrolls the viewport with the mouse wheel
+func (b *BaseOverlay) handleMouseMsg(msg tea.MouseMsg) (bool, *BaseOverlay, tea.Cmd) {
+	switch wheelDelta(msg) {
+	case -1:
+		b.viewport.ScrollUp(mouseWheelLines)
+	case 1:
+		b.viewport.ScrollDown(mouseWheelLines)
+	default:
+		return false, b, nil
+	}
+	return true, b, nil
+}
+
 // handleWindowResize updates dimensions on window resize
 func (b *BaseOverlay) handleWindowResize(msg tea.WindowSizeMsg) (bool, *BaseOverlay, tea.Cmd) {
 	b.width = msg.Width
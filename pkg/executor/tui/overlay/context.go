@@ -29,6 +29,12 @@ type ContextInfo struct {
 	CurrentToolCall    string
 	HasPendingToolCall bool
 
+	// Lazy tool schemas (empty ToolGroups means every schema is sent)
+	LoadedToolCount    int
+	ToolManifestTokens int
+	DeferredToolTokens int
+	ToolGroups         []ToolGroupInfo
+
 	// Message history
 	MessageCount       int
 	ConversationTurns  int
@@ -54,6 +60,15 @@ type ContextInfo struct {
 	TotalTokens           int
 }
 
+// ToolGroupInfo describes a group of tools whose schemas are sent only once
+// the agent loads it
+type ToolGroupInfo struct {
+	Name      string
+	ToolCount int
+	Tokens    int
+	Loaded    bool
+}
+
 // NewContextOverlay creates a new context information overlay
 func NewContextOverlay(info *ContextInfo, width, height int) *ContextOverlay {
 	overlayWidth := types.ComputeOverlayWidth(width, 0.80, 56, 100)
@@ -117,6 +132,19 @@ func buildContextContent(info *ContextInfo) string {
 	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink).Render("Tool System"))
 	b.WriteString("\n")
 	fmt.Fprintf(&b, "  Available Tools:    %d (%s tokens)\n", info.ToolCount, formatTokenCount(info.ToolTokens))
+	if len(info.ToolGroups) > 0 {
+		fmt.Fprintf(&b, "  Loaded Schemas:     %d tools\n", info.LoadedToolCount)
+		fmt.Fprintf(&b, "  Tool Manifest:      %s tokens\n", formatTokenCount(info.ToolManifestTokens))
+		fmt.Fprintf(&b, "  Deferred Schemas:   %s tokens saved per call\n", formatTokenCount(info.DeferredToolTokens))
+		for _, group := range info.ToolGroups {
+			state := "deferred"
+			if group.Loaded {
+				state = "loaded"
+			}
+			fmt.Fprintf(&b, "    %-14s %2d tools  %6s tokens  %s\n",
+				group.Name, group.ToolCount, formatTokenCount(group.Tokens), state)
+		}
+	}
 	if info.HasPendingToolCall {
 		fmt.Fprintf(&b, "  Current Tool Call:  %s\n", info.CurrentToolCall)
 	}
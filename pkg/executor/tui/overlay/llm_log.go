@@ -0,0 +1,191 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// LLMLogEntry is a logged model request listed by the LLM log overlay
+type LLMLogEntry struct {
+	Title       string // When, which model and what kind of request
+	Description string // Messages, tokens and duration, or the error
+	Detail      string // The full request and response
+}
+
+// llmLogItem is an LLMLogEntry in the list
+type llmLogItem struct {
+	entry LLMLogEntry
+}
+
+func (i llmLogItem) FilterValue() string { return i.entry.Title }
+func (i llmLogItem) Title() string       { return i.entry.Title }
+func (i llmLogItem) Description() string { return i.entry.Description }
+
+// LLMLogOverlay lists the logged model requests, newest first, and shows the
+// selected one's full request and response.
+type LLMLogOverlay struct {
+	list    list.Model
+	detail  viewport.Model
+	entries []LLMLogEntry
+	viewing int // Index of the entry shown in full; -1 while listing
+	dir     string
+	width   int
+	height  int
+	active  bool
+}
+
+// NewLLMLogOverlay creates an overlay listing entries, read from dir.
+func NewLLMLogOverlay(entries []LLMLogEntry, dir string, width, height int) *LLMLogOverlay {
+	overlayWidth := types.ComputeOverlayWidth(width, 0.85, 60, 140)
+	overlayHeight := types.ComputeViewportHeight(height, 2)
+
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(types.SalmonPink).
+		BorderForeground(types.SalmonPink)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(types.MutedGray).
+		BorderForeground(types.SalmonPink)
+
+	items := make([]list.Item, len(entries))
+	for i, entry := range entries {
+		items[i] = llmLogItem{entry: entry}
+	}
+	l := list.New(items, delegate, 0, 0)
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(false)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "view request")),
+			key.NewBinding(key.WithKeys("esc", "q"), key.WithHelp("esc/q", "close")),
+		}
+	}
+
+	o := &LLMLogOverlay{
+		list:    l,
+		detail:  viewport.New(0, 0),
+		entries: entries,
+		viewing: -1,
+		dir:     dir,
+		active:  true,
+	}
+	o.resize(overlayWidth, overlayHeight)
+	return o
+}
+
+// resize fits the list and the detail view to the overlay's size.
+func (o *LLMLogOverlay) resize(width, height int) {
+	o.width = width
+	o.height = height
+	o.list.SetSize(width-4, height-4)
+	o.detail.Width = width - 4
+	o.detail.Height = height - 5
+	if o.viewing >= 0 {
+		o.showDetail(o.viewing)
+	}
+}
+
+// showDetail shows entry i in full, wrapped to the overlay's width.
+func (o *LLMLogOverlay) showDetail(i int) {
+	o.viewing = i
+	o.detail.SetContent(lipgloss.NewStyle().Width(o.detail.Width).Render(o.entries[i].Detail))
+}
+
+// Update handles messages for the LLM log overlay
+func (o *LLMLogOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	if !o.active {
+		return o, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", keyEsc:
+			// Esc goes back from a request to the list, then closes
+			if o.viewing >= 0 {
+				o.viewing = -1
+				return o, nil
+			}
+			o.active = false
+			// Return nil to signal close - caller will handle ClearOverlay()
+			return nil, nil
+		case keyEnter:
+			if o.viewing < 0 && len(o.entries) > 0 {
+				o.showDetail(o.list.Index())
+				o.detail.GotoTop()
+				return o, nil
+			}
+		}
+	case tea.MouseMsg:
+		// The list ignores the mouse, so the wheel moves its cursor instead;
+		// the request detail scrolls on its own
+		if o.viewing < 0 {
+			switch wheelDelta(msg) {
+			case -1:
+				o.list.CursorUp()
+			case 1:
+				o.list.CursorDown()
+			}
+			return o, nil
+		}
+	case tea.WindowSizeMsg:
+		o.resize(types.ComputeOverlayWidth(msg.Width, 0.85, 60, 140), types.ComputeViewportHeight(msg.Height, 2))
+		return o, nil
+	}
+
+	var cmd tea.Cmd
+	if o.viewing >= 0 {
+		o.detail, cmd = o.detail.Update(msg)
+	} else {
+		o.list, cmd = o.list.Update(msg)
+	}
+	return o, cmd
+}
+
+// View renders the LLM log overlay
+func (o *LLMLogOverlay) View() string {
+	if !o.active {
+		return ""
+	}
+
+	innerWidth := max(o.width-4, 0)
+	title := fmt.Sprintf("LLM Requests (%d)", len(o.entries))
+	subtitle := o.dir
+	body := o.list.View()
+	if o.viewing >= 0 {
+		title = o.entries[o.viewing].Title
+		subtitle = o.entries[o.viewing].Description
+		footer := types.OverlayHelpStyle.Render(fmt.Sprintf("↑/↓ scroll · esc back · %d%%", int(o.detail.ScrollPercent()*100)))
+		body = o.detail.View() + "\n" + footer
+	}
+
+	header := types.OverlayTitleStyle.Render(title)
+	pad := strings.Repeat(" ", max(0, (innerWidth-lipgloss.Width(header))/2))
+	separator := lipgloss.NewStyle().Foreground(types.MutedGray).Render(strings.Repeat(sepChar, innerWidth))
+	content := pad + header + "\n" + types.OverlaySubtitleStyle.Render(subtitle) + "\n" + separator + "\n" + body
+
+	return types.CreateOverlayContainerStyle(o.width).Height(o.height).Render(content)
+}
+
+// Focused returns whether the LLM log overlay should handle input
+func (o *LLMLogOverlay) Focused() bool {
+	return o.active
+}
+
+// Width returns the width of the LLM log overlay
+func (o *LLMLogOverlay) Width() int {
+	return o.width
+}
+
+// Height returns the height of the LLM log overlay
+func (o *LLMLogOverlay) Height() int {
+	return o.height
+}
@@ -0,0 +1,198 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// ModelRole selects which of the agent's models a switch applies to
+type ModelRole int
+
+const (
+	// ModelRoleMain is the model that runs the agent loop
+	ModelRoleMain ModelRole = iota
+	// ModelRoleSummarization is the model used to summarize context
+	ModelRoleSummarization
+)
+
+// ModelChoice is one model offered by the model switcher
+type ModelChoice struct {
+	Name          string
+	ContextTokens int // 0 when unknown
+	InputPrice    float64
+	OutputPrice   float64
+	PriceKnown    bool
+	Main          bool // Currently the main model
+	Summarization bool // Currently the summarization model
+}
+
+// ModelOverlay lists the configured models and switches the main or
+// summarization model to the selected one
+type ModelOverlay struct {
+	*BaseOverlay
+	choices  []ModelChoice
+	selected int
+	onSelect func(name string, role ModelRole)
+}
+
+// NewModelOverlay creates a model switcher over choices. onSelect is called
+// with the chosen model and role before the overlay closes.
+func NewModelOverlay(choices []ModelChoice, width, height int, onSelect func(name string, role ModelRole)) *ModelOverlay {
+	overlayWidth := types.ComputeOverlayWidth(width, 0.80, 56, 100)
+	viewportHeight := min(types.ComputeViewportHeight(height, 6), len(choices)+1)
+
+	o := &ModelOverlay{
+		choices:  choices,
+		onSelect: onSelect,
+	}
+	for i, choice := range choices {
+		if choice.Main {
+			o.selected = i
+			break
+		}
+	}
+
+	o.BaseOverlay = NewBaseOverlay(BaseOverlayConfig{
+		Width:          overlayWidth,
+		Height:         viewportHeight + 6,
+		ViewportWidth:  overlayWidth - 4,
+		ViewportHeight: viewportHeight,
+		Content:        buildModelContent(choices, o.selected),
+		RenderHeader:   o.renderHeader,
+		RenderFooter:   o.renderFooter,
+	})
+	return o
+}
+
+// formatModelPrice formats a per-million-token price pair
+func formatModelPrice(choice ModelChoice) string {
+	if !choice.PriceKnown {
+		return "-"
+	}
+	return fmt.Sprintf("$%g / $%g", choice.InputPrice, choice.OutputPrice)
+}
+
+// buildModelContent formats the model table with the selected row highlighted
+func buildModelContent(choices []ModelChoice, selected int) string {
+	var b strings.Builder
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink)
+
+	fmt.Fprintf(&b, "  %-36s %-9s %-17s %s\n", "Model", "Context", "Price (in/out)", "In use")
+	for i, choice := range choices {
+		context := "-"
+		if choice.ContextTokens > 0 {
+			context = formatTokenCount(choice.ContextTokens)
+		}
+
+		var roles []string
+		if choice.Main {
+			roles = append(roles, "main")
+		}
+		if choice.Summarization {
+			roles = append(roles, "summarization")
+		}
+
+		row := fmt.Sprintf("%-36s %-9s %-17s %s", choice.Name, context, formatModelPrice(choice), strings.Join(roles, ", "))
+		if i == selected {
+			b.WriteString(selectedStyle.Render("▸ " + row))
+		} else {
+			b.WriteString("  " + row)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Update handles messages for the model switcher
+func (o *ModelOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		o.SetDimensions(types.ComputeOverlayWidth(msg.Width, 0.80, 56, 100), o.Height())
+		o.Viewport().Width = o.Width() - 4
+		return o, nil
+
+	case tea.MouseMsg:
+		// A click selects a model and a click on the selected one switches
+		// the main model to it; the wheel scrolls the table
+		if i, ok := o.choiceAt(msg); ok {
+			if i == o.selected {
+				o.onSelect(o.choices[i].Name, ModelRoleMain)
+				return nil, nil
+			}
+			o.selected = i
+			o.SetContent(buildModelContent(o.choices, o.selected))
+			return o, nil
+		}
+		_, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
+		o.BaseOverlay = updatedBase
+		return o, cmd
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case keyEsc, keyCtrlC:
+			return nil, nil
+		case keyEnter:
+			o.onSelect(o.choices[o.selected].Name, ModelRoleMain)
+			return nil, nil
+		case "s":
+			o.onSelect(o.choices[o.selected].Name, ModelRoleSummarization)
+			return nil, nil
+		case "up", "k":
+			o.selected = (o.selected - 1 + len(o.choices)) % len(o.choices)
+		case "down", "j", keyTab:
+			o.selected = (o.selected + 1) % len(o.choices)
+		default:
+			return o, nil
+		}
+		o.SetContent(buildModelContent(o.choices, o.selected))
+
+		// Keep the selected row (after the column headings) in view
+		vp := o.Viewport()
+		if row := o.selected + 1; row < vp.YOffset {
+			vp.SetYOffset(row)
+		} else if row >= vp.YOffset+vp.Height {
+			vp.SetYOffset(row - vp.Height + 1)
+		}
+	}
+
+	return o, nil
+}
+
+// choiceAt returns the model whose row a left click at msg lands on.
+func (o *ModelOverlay) choiceAt(msg tea.MouseMsg) (int, bool) {
+	if !isLeftClick(msg) {
+		return 0, false
+	}
+	line := viewLine(o.View(), msg.Y)
+	for i, choice := range o.choices {
+		// Names are padded into a column, so " name " only matches its own row
+		if strings.Contains(line, " "+choice.Name+" ") {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// renderHeader renders the model switcher header
+func (o *ModelOverlay) renderHeader() string {
+	contentWidth := o.Viewport().Width
+	separator := lipgloss.NewStyle().Foreground(types.MutedGray).Render(strings.Repeat(sepChar, contentWidth))
+	return types.OverlayTitleStyle.Render("Switch Model") + "\n" + separator + "\n"
+}
+
+// renderFooter renders the key hints
+func (o *ModelOverlay) renderFooter() string {
+	contentWidth := o.Viewport().Width
+	separator := lipgloss.NewStyle().Foreground(types.MutedGray).Render(strings.Repeat(sepChar, contentWidth))
+	hints := types.OverlayHelpStyle.Render("Enter/click: use as main model • s: use for summarization • ↑/↓: select • Esc: close")
+	return "\n" + separator + "\n" + hints
+}
+
+// View renders the overlay
+func (o *ModelOverlay) View() string {
+	return o.BaseOverlay.View(o.Width())
+}
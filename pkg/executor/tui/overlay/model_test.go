@@ -0,0 +1,70 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBuildModelContent(t *testing.T) {
+	content := buildModelContent([]ModelChoice{
+		{Name: "gpt-4o", ContextTokens: 128_000, InputPrice: 2.5, OutputPrice: 10, PriceKnown: true, Main: true},
+		{Name: "local-coder", Summarization: true},
+	}, 0)
+
+	for _, want := range []string{"▸ gpt-4o", "128.0K", "$2.5 / $10", "main", "summarization"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in content:\n%s", want, content)
+		}
+	}
+}
+
+func TestModelOverlay_Select(t *testing.T) {
+	choices := []ModelChoice{
+		{Name: "gpt-4o", Main: true},
+		{Name: "gpt-4o-mini"},
+	}
+
+	var gotName string
+	var gotRole ModelRole
+	o := NewModelOverlay(choices, 120, 40, func(name string, role ModelRole) {
+		gotName, gotRole = name, role
+	})
+
+	o.Update(tea.KeyMsg{Type: tea.KeyDown}, nil, nil)
+	next, _ := o.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")}, nil, nil)
+	if next != nil {
+		t.Error("expected the overlay to close after a selection")
+	}
+	if gotName != "gpt-4o-mini" || gotRole != ModelRoleSummarization {
+		t.Errorf("expected gpt-4o-mini for summarization, got %s, %v", gotName, gotRole)
+	}
+
+	o = NewModelOverlay(choices, 120, 40, func(name string, role ModelRole) {
+		gotName, gotRole = name, role
+	})
+	o.Update(tea.KeyMsg{Type: tea.KeyEnter}, nil, nil)
+	if gotName != "gpt-4o" || gotRole != ModelRoleMain {
+		t.Errorf("expected gpt-4o as the main model, got %s, %v", gotName, gotRole)
+	}
+}
+
+func TestModelOverlay_Click(t *testing.T) {
+	choices := []ModelChoice{
+		{Name: "gpt-4o", Main: true},
+		{Name: "gpt-4o-mini"},
+	}
+	var gotName string
+	o := NewModelOverlay(choices, 120, 40, func(name string, role ModelRole) {
+		gotName = name
+	})
+
+	// The first click selects the row, the second switches to it
+	if next, _ := o.Update(clickOn(t, o.View(), "gpt-4o-mini"), nil, nil); next == nil || o.selected != 1 {
+		t.Fatalf("expected a click to select gpt-4o-mini, got %d", o.selected)
+	}
+	if next, _ := o.Update(clickOn(t, o.View(), "gpt-4o-mini"), nil, nil); next != nil || gotName != "gpt-4o-mini" {
+		t.Errorf("expected a second click to switch to gpt-4o-mini, got %q", gotName)
+	}
+}
@@ -0,0 +1,55 @@
+package overlay
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+)
+
+// mouseWheelLines is how far one notch of the mouse wheel scrolls.
+const mouseWheelLines = 3
+
+// Overlays receive mouse events with X and Y relative to the top-left corner
+// of their View, so clicks are hit-tested against the rendered rows.
+
+// isLeftClick reports whether msg presses the left mouse button.
+func isLeftClick(msg tea.MouseMsg) bool {
+	return msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft
+}
+
+// wheelDelta returns -1 for a wheel notch up, 1 for one down and 0 for any
+// other mouse event.
+func wheelDelta(msg tea.MouseMsg) int {
+	if msg.Action != tea.MouseActionPress {
+		return 0
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		return -1
+	case tea.MouseButtonWheelDown:
+		return 1
+	}
+	return 0
+}
+
+// viewLine returns row y of a rendered view without styling, or "" when y is
+// outside it.
+func viewLine(view string, y int) string {
+	lines := strings.Split(view, "\n")
+	if y < 0 || y >= len(lines) {
+		return ""
+	}
+	return xansi.Strip(lines[y])
+}
+
+// labelAt reports whether the first occurrence of label on an unstyled line
+// covers column x.
+func labelAt(line, label string, x int) bool {
+	i := strings.Index(line, label)
+	if label == "" || i < 0 {
+		return false
+	}
+	start := xansi.StringWidth(line[:i])
+	return x >= start && x < start+xansi.StringWidth(label)
+}
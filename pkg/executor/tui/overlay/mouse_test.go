@@ -0,0 +1,88 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	xansi "github.com/charmbracelet/x/ansi"
+	"github.com/entrhq/forge/pkg/executor/tui/approval"
+)
+
+// clickOn returns a left click on the first cell of text in view.
+func clickOn(t *testing.T, view, text string) tea.MouseMsg {
+	t.Helper()
+	for y, line := range strings.Split(xansi.Strip(view), "\n") {
+		if i := strings.Index(line, text); i >= 0 {
+			return tea.MouseMsg{X: xansi.StringWidth(line[:i]), Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}
+		}
+	}
+	t.Fatalf("%q not found in view:\n%s", text, view)
+	return tea.MouseMsg{}
+}
+
+func TestLabelAt(t *testing.T) {
+	line := "│  ✓ Accept    ✗ Reject  │"
+	start := xansi.StringWidth("│  ")
+	tests := []struct {
+		x    int
+		want bool
+	}{
+		{start - 1, false},
+		{start, true},
+		{start + xansi.StringWidth("✓ Accept") - 1, true},
+		{start + xansi.StringWidth("✓ Accept"), false},
+	}
+	for _, tt := range tests {
+		if got := labelAt(line, "✓ Accept", tt.x); got != tt.want {
+			t.Errorf("labelAt(x=%d) = %v, want %v", tt.x, got, tt.want)
+		}
+	}
+}
+
+// stubApproval is an approval request recording its answer
+type stubApproval struct {
+	answer string
+}
+
+func (r *stubApproval) Title() string   { return "Run command" }
+func (r *stubApproval) Content() string { return "go test ./..." }
+func (r *stubApproval) OnApprove() tea.Cmd {
+	r.answer = "approved"
+	return nil
+}
+func (r *stubApproval) OnReject() tea.Cmd {
+	r.answer = "rejected"
+	return nil
+}
+
+var _ approval.ApprovalRequest = (*stubApproval)(nil)
+
+func TestGenericApprovalOverlay_ClickButtons(t *testing.T) {
+	for label, want := range map[string]string{"✓ Accept": "approved", "✗ Reject": "rejected"} {
+		request := &stubApproval{}
+		o := NewGenericApprovalOverlay(request, 120, 40)
+
+		// Clicks elsewhere leave the request open
+		if next, _ := o.Update(clickOn(t, o.View(), "go test"), nil, nil); next == nil || request.answer != "" {
+			t.Fatalf("expected a click on the content not to answer, got %q", request.answer)
+		}
+		next, _ := o.Update(clickOn(t, o.View(), label), nil, nil)
+		if next != nil || request.answer != want {
+			t.Errorf("clicking %s: expected the overlay to close %s, got %q", label, want, request.answer)
+		}
+	}
+}
+
+func TestBaseOverlay_WheelScrolls(t *testing.T) {
+	o := NewBaseOverlay(BaseOverlayConfig{ViewportWidth: 40, ViewportHeight: 3, Content: strings.Repeat("line\n", 20)})
+
+	o.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown}, nil)
+	if got := o.Viewport().YOffset; got != mouseWheelLines {
+		t.Errorf("expected the wheel to scroll %d lines, got offset %d", mouseWheelLines, got)
+	}
+	o.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelUp}, nil)
+	if got := o.Viewport().YOffset; got != 0 {
+		t.Errorf("expected the wheel to scroll back to the top, got offset %d", got)
+	}
+}
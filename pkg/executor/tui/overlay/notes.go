@@ -2,12 +2,15 @@ package overlay
 
 import (
 	"fmt"
+	"io"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent/memory/notes"
 	"github.com/entrhq/forge/pkg/executor/tui/types"
 	pkgtypes "github.com/entrhq/forge/pkg/types"
 )
@@ -21,6 +24,7 @@ func convertNoteData(note *pkgtypes.NoteData) *types.NoteData {
 		ID:        note.ID,
 		Content:   note.Content,
 		Tags:      note.Tags,
+		Category:  note.Category,
 		CreatedAt: note.CreatedAt,
 		UpdatedAt: note.UpdatedAt,
 		Scratched: note.Scratched,
@@ -70,17 +74,52 @@ func newNoteListDelegate() noteListDelegate {
 	return noteListDelegate{DefaultDelegate: d}
 }
 
+// Render draws a note, its title colored by the note's category.
+func (d noteListDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if note, ok := item.(noteListItem); ok {
+		if color, ok := noteCategoryColors[note.note.Category]; ok {
+			// d is a copy, so the colors apply to this note only
+			d.Styles.NormalTitle = d.Styles.NormalTitle.Foreground(color)
+			d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(color)
+		}
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
+// noteCategoryColors colors notes by category
+var noteCategoryColors = map[string]lipgloss.Color{
+	notes.CategoryDecision: types.DiffHunkColor,
+	notes.CategoryBlocker:  types.ProgressRed,
+	notes.CategoryFollowup: types.ProgressYellow,
+}
+
+// noteFilters are the filters the notes overlay cycles through: every note,
+// then each category
+var noteFilters = append([]string{""}, notes.Categories...)
+
+// noteFilterLabels names the filters in the filter bar
+var noteFilterLabels = map[string]string{
+	"":                     "All",
+	notes.CategoryDecision: "Decisions",
+	notes.CategoryBlocker:  "Blockers",
+	notes.CategoryFollowup: "Follow-ups",
+}
+
 // NotesOverlay displays scratchpad notes in a modal dialog
 type NotesOverlay struct {
-	list   list.Model
-	notes  []pkgtypes.NoteData
-	width  int
-	height int
-	active bool
+	list    list.Model
+	notes   []pkgtypes.NoteData
+	filters []string // Tags Tab cycles through, "" for every note
+	filter  int      // Index of the active filter
+	width   int
+	height  int
+	active  bool
 }
 
-// NewNotesOverlay creates a new notes overlay
-func NewNotesOverlay(notes []pkgtypes.NoteData, width, height int) *NotesOverlay {
+// NewNotesOverlay creates a new notes overlay showing the notes tagged tag,
+// or every note when tag is "". Tab cycles between every note and each
+// category.
+func NewNotesOverlay(allNotes []pkgtypes.NoteData, tag string, width, height int) *NotesOverlay {
 	overlayWidth := types.ComputeOverlayWidth(width, 0.80, 56, 100)
 	overlayHeight := types.ComputeViewportHeight(height, 2)
 
@@ -104,6 +143,10 @@ func NewNotesOverlay(notes []pkgtypes.NoteData, width, height int) *NotesOverlay
 				key.WithKeys("enter"),
 				key.WithHelp("enter", "view note"),
 			),
+			key.NewBinding(
+				key.WithKeys("tab", "shift+tab"),
+				key.WithHelp("tab", "filter"),
+			),
 			key.NewBinding(
 				key.WithKeys("esc", "q"),
 				key.WithHelp("esc/q", "close"),
@@ -111,26 +154,88 @@ func NewNotesOverlay(notes []pkgtypes.NoteData, width, height int) *NotesOverlay
 		}
 	}
 
-	// Convert notes to list items
-	items := make([]list.Item, len(notes))
-	for i, note := range notes {
+	// A tag that is not a category gets a filter of its own
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	filters := noteFilters
+	if !slices.Contains(filters, tag) {
+		filters = append(slices.Clone(filters), tag)
+	}
+
+	o := &NotesOverlay{
+		list:    l,
+		notes:   allNotes,
+		filters: filters,
+		filter:  slices.Index(filters, tag),
+		width:   overlayWidth,
+		height:  overlayHeight,
+		active:  true,
+	}
+	o.applyFilter()
+	// The filter bar takes a line of the list's space
+	o.list.SetSize(overlayWidth-4, overlayHeight-5)
+	return o
+}
+
+// applyFilter lists the notes matching the active filter.
+func (o *NotesOverlay) applyFilter() {
+	tag := o.filters[o.filter]
+	items := make([]list.Item, 0, len(o.notes))
+	for _, note := range o.notes {
+		if tag != "" && !slices.Contains(note.Tags, tag) {
+			continue
+		}
 		noteCopy := note
-		items[i] = noteListItem{
+		items = append(items, noteListItem{
 			note:        &noteCopy,
 			tuiNoteData: convertNoteData(&noteCopy),
+		})
+	}
+	o.list.SetItems(items)
+	o.list.ResetSelected()
+}
+
+// cycleFilter moves to the next filter, or the previous one when step is -1.
+func (o *NotesOverlay) cycleFilter(step int) {
+	o.filter = (o.filter + step + len(o.filters)) % len(o.filters)
+	o.applyFilter()
+}
+
+// countTagged returns how many notes match tag.
+func (o *NotesOverlay) countTagged(tag string) int {
+	if tag == "" {
+		return len(o.notes)
+	}
+	count := 0
+	for _, note := range o.notes {
+		if slices.Contains(note.Tags, tag) {
+			count++
 		}
 	}
+	return count
+}
 
-	l.SetItems(items)
-	l.SetSize(overlayWidth-4, overlayHeight-4)
+// filterBar renders the filters with their note counts, the active one
+// highlighted.
+func (o *NotesOverlay) filterBar() string {
+	parts := make([]string, len(o.filters))
+	for i, tag := range o.filters {
+		label, ok := noteFilterLabels[tag]
+		if !ok {
+			label = "#" + tag
+		}
+		label = fmt.Sprintf("%s %d", label, o.countTagged(tag))
 
-	return &NotesOverlay{
-		list:   l,
-		notes:  notes,
-		width:  overlayWidth,
-		height: overlayHeight,
-		active: true,
+		style := lipgloss.NewStyle().Foreground(types.MutedGray)
+		if i == o.filter {
+			color, ok := noteCategoryColors[tag]
+			if !ok {
+				color = types.SalmonPink
+			}
+			style = lipgloss.NewStyle().Foreground(color).Bold(true).Underline(true)
+		}
+		parts[i] = style.Render(label)
 	}
+	return " " + strings.Join(parts, "   ")
 }
 
 // Update handles messages for the notes overlay
@@ -146,6 +251,12 @@ func (o *NotesOverlay) Update(msg tea.Msg, state types.StateProvider, actions ty
 			o.active = false
 			// Return nil to signal close - caller will handle ClearOverlay()
 			return nil, nil
+		case keyTab:
+			o.cycleFilter(1)
+			return o, nil
+		case "shift+tab":
+			o.cycleFilter(-1)
+			return o, nil
 		case keyEnter:
 			// Show full note content
 			if item, ok := o.list.SelectedItem().(noteListItem); ok {
@@ -154,10 +265,19 @@ func (o *NotesOverlay) Update(msg tea.Msg, state types.StateProvider, actions ty
 				}
 			}
 		}
+	case tea.MouseMsg:
+		// The list ignores the mouse, so the wheel moves its cursor instead
+		switch wheelDelta(msg) {
+		case -1:
+			o.list.CursorUp()
+		case 1:
+			o.list.CursorDown()
+		}
+		return o, nil
 	case tea.WindowSizeMsg:
 		o.width = types.ComputeOverlayWidth(msg.Width, 0.80, 56, 100)
 		o.height = types.ComputeViewportHeight(msg.Height, 2)
-		o.list.SetSize(o.width-4, o.height-4)
+		o.list.SetSize(o.width-4, o.height-5)
 	}
 
 	var cmd tea.Cmd
@@ -187,7 +307,7 @@ func (o *NotesOverlay) View() string {
 
 	separator := lipgloss.NewStyle().Foreground(types.MutedGray).Render(strings.Repeat(sepChar, innerWidth))
 
-	content := titlePad.String() + headerStr + "\n" + separator + "\n" + listContent
+	content := titlePad.String() + headerStr + "\n" + separator + "\n" + o.filterBar() + "\n" + listContent
 
 	return types.CreateOverlayContainerStyle(o.width).Height(o.height).Render(content)
 }
@@ -0,0 +1,67 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	pkgtypes "github.com/entrhq/forge/pkg/types"
+)
+
+func testNotes() []pkgtypes.NoteData {
+	return []pkgtypes.NoteData{
+		{ID: "1", Content: "Kept the v1 endpoint", Tags: []string{"decision", "api"}, Category: "decision"},
+		{ID: "2", Content: "CI has no network access", Tags: []string{"blocker", "build"}, Category: "blocker"},
+		{ID: "3", Content: "Flaky retry test", Tags: []string{"followup", "test"}, Category: "followup"},
+		{ID: "4", Content: "Auth reads tokens from the keychain", Tags: []string{"auth", "api"}},
+	}
+}
+
+// listedNoteIDs returns the IDs of the notes the overlay lists.
+func listedNoteIDs(o *NotesOverlay) string {
+	var ids []string
+	for _, item := range o.list.Items() {
+		ids = append(ids, item.(noteListItem).note.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestNotesOverlay_CycleFilters(t *testing.T) {
+	o := NewNotesOverlay(testNotes(), "", 100, 40)
+	if got := listedNoteIDs(o); got != "1,2,3,4" {
+		t.Fatalf("expected every note, got %s", got)
+	}
+
+	tab := tea.KeyMsg{Type: tea.KeyTab}
+	want := []string{"1", "2", "3", "1,2,3,4"}
+	for _, ids := range want {
+		o.Update(tab, nil, nil)
+		if got := listedNoteIDs(o); got != ids {
+			t.Errorf("after tab expected %s, got %s", ids, got)
+		}
+	}
+
+	o.Update(tea.KeyMsg{Type: tea.KeyShiftTab}, nil, nil)
+	if got := listedNoteIDs(o); got != "3" {
+		t.Errorf("after shift+tab expected the follow-ups, got %s", got)
+	}
+}
+
+func TestNotesOverlay_OpensFilteredToTag(t *testing.T) {
+	o := NewNotesOverlay(testNotes(), "Blocker", 100, 40)
+	if got := listedNoteIDs(o); got != "2" {
+		t.Errorf("expected the blockers, got %s", got)
+	}
+
+	// A tag that is not a category is added to the filters
+	o = NewNotesOverlay(testNotes(), "api", 100, 40)
+	if got := listedNoteIDs(o); got != "1,4" {
+		t.Errorf("expected the notes tagged api, got %s", got)
+	}
+	view := o.View()
+	for _, label := range []string{"All 4", "Decisions 1", "Blockers 1", "Follow-ups 1", "#api 2"} {
+		if !strings.Contains(view, label) {
+			t.Errorf("expected %q in the filter bar, got:\n%s", label, view)
+		}
+	}
+}
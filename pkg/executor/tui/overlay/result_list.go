@@ -2,9 +2,11 @@ package overlay
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/entrhq/forge/pkg/executor/tui/types"
@@ -12,7 +14,10 @@ import (
 
 // resultListItem represents a single item in the result list
 type resultListItem struct {
-	result *types.CachedResult
+	result  *types.CachedResult
+	line    int    // First line matching the search, or -1
+	snippet string // Text of the first matching line
+	matches int    // Number of lines matching the search
 }
 
 func (i resultListItem) FilterValue() string {
@@ -24,8 +29,16 @@ func (i resultListItem) Title() string {
 }
 
 func (i resultListItem) Description() string {
-	// Truncate summary if too long (77 chars + 3 for "..." = 80 total)
+	// Search hits show where the match is instead of the summary
 	summary := i.result.Summary
+	if i.line >= 0 && i.matches > 0 {
+		summary = fmt.Sprintf("L%d: %s", i.line+1, i.snippet)
+		if i.matches > 1 {
+			summary = fmt.Sprintf("%d matches · %s", i.matches, summary)
+		}
+	}
+
+	// Truncate summary if too long (77 chars + 3 for "..." = 80 total)
 	if len(summary) > 77 {
 		summary = summary[:77] + "..."
 	}
@@ -53,11 +66,15 @@ func newResultListDelegate() resultListDelegate {
 
 // ResultListModel represents the state of the result list overlay
 type ResultListModel struct {
-	list     list.Model
-	width    int
-	height   int
-	active   bool
-	quitting bool
+	list      list.Model
+	search    textinput.Model
+	searching bool                  // Whether keys go to the search input
+	results   []*types.CachedResult // Every cached result, newest first
+	now       func() time.Time
+	width     int
+	height    int
+	active    bool
+	quitting  bool
 }
 
 // NewResultListModel creates a new result list model
@@ -81,6 +98,10 @@ func NewResultListModel() ResultListModel {
 				key.WithKeys("enter"),
 				key.WithHelp("enter", "view result"),
 			),
+			key.NewBinding(
+				key.WithKeys("/"),
+				key.WithHelp("/", "search"),
+			),
 			key.NewBinding(
 				key.WithKeys("esc", "q"),
 				key.WithHelp("esc/q", "close"),
@@ -88,8 +109,15 @@ func NewResultListModel() ResultListModel {
 		}
 	}
 
+	search := textinput.New()
+	search.Prompt = "/ "
+	search.Placeholder = "search results (tool:<name> since:<duration>)"
+	search.PromptStyle = lipgloss.NewStyle().Foreground(types.SalmonPink)
+
 	return ResultListModel{
 		list:   l,
+		search: search,
+		now:    time.Now,
 		active: false,
 	}
 }
@@ -99,15 +127,36 @@ func (m *ResultListModel) Activate(results []*types.CachedResult, width, height
 	m.active = true
 	m.width = width
 	m.height = height
+	m.results = results
+	m.searching = false
+	m.search.Reset()
+	m.search.Blur()
 
-	// Convert cached results to list items
-	items := make([]list.Item, len(results))
-	for i, result := range results {
-		items[i] = resultListItem{result: result}
-	}
+	m.applySearch()
+	m.resize()
+}
 
+// applySearch shows the results matching the current search query
+func (m *ResultListModel) applySearch() {
+	matches := searchResults(m.results, m.search.Value(), m.now())
+	items := make([]list.Item, len(matches))
+	for i, match := range matches {
+		items[i] = match
+	}
 	m.list.SetItems(items)
-	m.list.SetSize(width-4, height-4)
+	m.list.ResetSelected()
+
+	if parseResultQuery(m.search.Value()).empty() {
+		m.list.Title = "Tool Result History"
+	} else {
+		m.list.Title = fmt.Sprintf("Tool Result History (%d of %d)", len(matches), len(m.results))
+	}
+}
+
+// resize fits the list below the search input
+func (m *ResultListModel) resize() {
+	m.search.Width = m.width - 12
+	m.list.SetSize(m.width-4, m.height-6)
 }
 
 // Deactivate hides the result list
@@ -133,24 +182,32 @@ func (m *ResultListModel) Update(msg tea.Msg, state types.StateProvider, actions
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg)
+		}
 		switch msg.String() {
-		case "q", keyEsc:
+		case "/":
+			m.searching = true
+			return m, m.search.Focus()
+		case keyEsc:
+			// Clear an active search before closing
+			if m.search.Value() != "" {
+				m.search.Reset()
+				m.applySearch()
+				return m, nil
+			}
+			m.Deactivate()
+			return m, nil
+		case "q":
 			m.Deactivate()
 			return m, nil
 		case keyEnter:
-			// Return the selected result
-			if item, ok := m.list.SelectedItem().(resultListItem); ok {
-				// Signal that we want to view this result
-				m.quitting = true
-				return m, func() tea.Msg {
-					return types.ViewResultMsg{ResultID: item.result.ID}
-				}
-			}
+			return m, m.viewSelected()
 		}
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.list.SetSize(msg.Width-4, msg.Height-4)
+		m.resize()
 	}
 
 	var cmd tea.Cmd
@@ -158,6 +215,50 @@ func (m *ResultListModel) Update(msg tea.Msg, state types.StateProvider, actions
 	return m, cmd
 }
 
+// updateSearch handles keys while the search input is focused. Enter opens
+// the selected hit, esc returns to the list, and the arrow keys move through
+// the hits without leaving the input.
+func (m *ResultListModel) updateSearch(msg tea.KeyMsg) (types.Overlay, tea.Cmd) {
+	switch msg.String() {
+	case keyEsc:
+		m.searching = false
+		m.search.Blur()
+		return m, nil
+	case keyEnter:
+		m.searching = false
+		m.search.Blur()
+		return m, m.viewSelected()
+	case "up", "down":
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
+	}
+
+	query := m.search.Value()
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	if m.search.Value() != query {
+		m.applySearch()
+	}
+	return m, cmd
+}
+
+// viewSelected returns a command opening the selected result, scrolled to
+// the first search hit when there is one
+func (m *ResultListModel) viewSelected() tea.Cmd {
+	item, ok := m.list.SelectedItem().(resultListItem)
+	if !ok {
+		return nil
+	}
+
+	// Signal that we want to view this result
+	m.quitting = true
+	line := max(item.line, 0)
+	return func() tea.Msg {
+		return types.ViewResultMsg{ResultID: item.result.ID, Line: line}
+	}
+}
+
 // View renders the result list
 func (m *ResultListModel) View() string {
 	if !m.active {
@@ -172,7 +273,7 @@ func (m *ResultListModel) View() string {
 		Width(m.width - 4).
 		Height(m.height - 4)
 
-	return boxStyle.Render(m.list.View())
+	return boxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, m.search.View(), "", m.list.View()))
 }
 
 // Focused returns whether the result list should handle input
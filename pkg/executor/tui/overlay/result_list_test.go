@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/entrhq/forge/pkg/executor/tui/types"
 )
 
@@ -150,3 +151,51 @@ func TestResultListModel_Activity(t *testing.T) {
 		t.Error("Model should not be active after Deactivate")
 	}
 }
+
+func TestResultListModel_Search(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	results := []*types.CachedResult{
+		{ID: "3", ToolName: "search_files", Result: "a.go:1: func handleAuth()\nb.go:9: handleAuth()", Timestamp: now.Add(-5 * time.Minute)},
+		{ID: "2", ToolName: "read_file", Result: "package auth\n\nfunc handleAuth() {}", Timestamp: now.Add(-10 * time.Minute)},
+		{ID: "1", ToolName: "search_files", Result: "c.go:3: handleAuth", Timestamp: now.Add(-25 * time.Minute)},
+	}
+
+	m := NewResultListModel()
+	m.now = func() time.Time { return now }
+	m.Activate(results, 100, 40)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")}, nil, nil)
+	for _, r := range "tool:search since:20m handleauth" {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}, nil, nil)
+	}
+
+	items := m.list.Items()
+	if len(items) != 1 {
+		t.Fatalf("expected one hit, got %d", len(items))
+	}
+	item := items[0].(resultListItem)
+	if item.result.ID != "3" || item.line != 0 || item.matches != 2 {
+		t.Errorf("unexpected hit %+v", item)
+	}
+	if got := item.Description(); got != "2 matches · L1: a.go:1: func handleAuth()" {
+		t.Errorf("unexpected description %q", got)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}, nil, nil)
+	if cmd == nil {
+		t.Fatal("expected enter to open the selected hit")
+	}
+	if msg, ok := cmd().(types.ViewResultMsg); !ok || msg.ResultID != "3" || msg.Line != 0 {
+		t.Errorf("unexpected view message %+v", msg)
+	}
+
+	// Esc clears the search before closing the list
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc}, nil, nil)
+	if !m.IsActive() || len(m.list.Items()) != 3 {
+		t.Errorf("expected esc to clear the search, got %d items", len(m.list.Items()))
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc}, nil, nil)
+	if m.IsActive() {
+		t.Error("expected esc to close the list without a search")
+	}
+}
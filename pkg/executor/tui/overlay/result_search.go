@@ -0,0 +1,94 @@
+package overlay
+
+import (
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// resultQuery is a parsed result history search. Plain words must all appear
+// in a result; "tool:<name>" keeps results from matching tools and
+// "since:<duration>" keeps results newer than the duration (e.g. since:20m).
+type resultQuery struct {
+	terms []string
+	tool  string
+	since time.Duration
+}
+
+// parseResultQuery parses a search query. Matching is case-insensitive, and
+// filter tokens with an invalid value are searched for as plain words.
+func parseResultQuery(query string) resultQuery {
+	var q resultQuery
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		if tool, ok := strings.CutPrefix(field, "tool:"); ok && tool != "" {
+			q.tool = tool
+			continue
+		}
+		if since, ok := strings.CutPrefix(field, "since:"); ok {
+			if d, err := time.ParseDuration(since); err == nil && d > 0 {
+				q.since = d
+				continue
+			}
+		}
+		q.terms = append(q.terms, field)
+	}
+	return q
+}
+
+// empty reports whether the query matches every result.
+func (q resultQuery) empty() bool {
+	return len(q.terms) == 0 && q.tool == "" && q.since == 0
+}
+
+// searchResults returns list items for the results matching query, in the
+// given order. Items for full-text matches point at the first matching line
+// of the result so it can be opened scrolled to the hit.
+func searchResults(results []*types.CachedResult, query string, now time.Time) []resultListItem {
+	q := parseResultQuery(query)
+	items := make([]resultListItem, 0, len(results))
+	for _, result := range results {
+		if item, ok := q.match(result, now); ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// match reports whether result matches the query and returns its list item.
+func (q resultQuery) match(result *types.CachedResult, now time.Time) (resultListItem, bool) {
+	item := resultListItem{result: result, line: -1}
+
+	if q.tool != "" && !strings.Contains(strings.ToLower(result.ToolName), q.tool) {
+		return item, false
+	}
+	if q.since > 0 && now.Sub(result.Timestamp) > q.since {
+		return item, false
+	}
+	if len(q.terms) == 0 {
+		return item, true
+	}
+
+	// Every term must appear somewhere in the result or its metadata
+	haystack := strings.ToLower(result.ToolName + "\n" + result.Summary + "\n" + result.Result)
+	for _, term := range q.terms {
+		if !strings.Contains(haystack, term) {
+			return item, false
+		}
+	}
+
+	for i, line := range strings.Split(result.Result, "\n") {
+		lower := strings.ToLower(line)
+		for _, term := range q.terms {
+			if strings.Contains(lower, term) {
+				if item.line < 0 {
+					item.line = i
+					item.snippet = strings.TrimSpace(line)
+				}
+				item.matches++
+				break
+			}
+		}
+	}
+	return item, true
+}
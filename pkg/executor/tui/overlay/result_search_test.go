@@ -0,0 +1,67 @@
+package overlay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+func TestParseResultQuery(t *testing.T) {
+	q := parseResultQuery("Tool:Grep since:20m TODO since:soon tool:")
+	if q.tool != "grep" || q.since != 20*time.Minute {
+		t.Errorf("unexpected filters %+v", q)
+	}
+	want := []string{"todo", "since:soon", "tool:"}
+	if len(q.terms) != len(want) {
+		t.Fatalf("expected terms %v, got %v", want, q.terms)
+	}
+	for i := range want {
+		if q.terms[i] != want[i] {
+			t.Errorf("term %d: expected %q, got %q", i, want[i], q.terms[i])
+		}
+	}
+
+	if !parseResultQuery("  ").empty() {
+		t.Error("expected a blank query to be empty")
+	}
+}
+
+func TestSearchResults(t *testing.T) {
+	now := time.Now()
+	results := []*types.CachedResult{
+		{ID: "2", ToolName: "execute_command", Summary: "go test", Result: "ok\nFAIL: TestLogin\nFAIL: TestLogout", Timestamp: now},
+		{ID: "1", ToolName: "read_file", Summary: "login.go", Result: "package login", Timestamp: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		ids   []string
+		line  int
+	}{
+		{"empty query keeps everything", "", []string{"2", "1"}, -1},
+		{"terms match case-insensitively", "fail testlogout", []string{"2"}, 1},
+		{"every term must match", "fail package", nil, -1},
+		{"summary matches without a line", "login.go", []string{"1"}, -1},
+		{"tool filter", "tool:read", []string{"1"}, -1},
+		{"time filter", "since:30m", []string{"2"}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := searchResults(results, tt.query, now)
+			if len(items) != len(tt.ids) {
+				t.Fatalf("expected %v, got %d items", tt.ids, len(items))
+			}
+			for i, id := range tt.ids {
+				if items[i].result.ID != id {
+					t.Errorf("item %d: expected %s, got %s", i, id, items[i].result.ID)
+				}
+			}
+			if len(items) > 0 && items[0].line != tt.line {
+				t.Errorf("expected first hit on line %d, got %d", tt.line, items[0].line)
+			}
+		})
+	}
+}
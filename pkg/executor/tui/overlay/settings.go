@@ -10,6 +10,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	xansi "github.com/charmbracelet/x/ansi"
 	"github.com/entrhq/forge/pkg/config"
 	"github.com/entrhq/forge/pkg/executor/tui/types"
 	"github.com/entrhq/forge/pkg/llm"
@@ -21,6 +22,7 @@ const (
 	modelField                = "model"
 	summarizationModelField   = "summarization_model"
 	browserAnalysisModelField = "browser_analysis_model"
+	fallbackModelField        = "fallback_model"
 	baseURLField              = "base_url"
 	apiKeyField               = "api_key"
 )
@@ -42,6 +44,10 @@ type SettingsOverlay struct {
 	hasChanges bool
 	editMode   bool
 
+	// Outcome of the last save, shown in the footer until the next change
+	saveStatus    string
+	saveStatusErr bool
+
 	// Scroll state
 	scrollOffset int
 
@@ -204,9 +210,13 @@ func (s *SettingsOverlay) loadSettings() {
 
 			for _, key := range keys {
 				value := data[key]
+				displayName := key
+				if _, managed := config.PolicyAutoApproval(key); managed {
+					displayName += " (set by policy)"
+				}
 				item := settingsItem{
 					key:         key,
-					displayName: key,
+					displayName: displayName,
 					value:       value,
 					itemType:    itemTypeToggle,
 					modified:    false,
@@ -248,6 +258,7 @@ func (s *SettingsOverlay) loadSettings() {
 				{modelField, "Model"},
 				{summarizationModelField, "Summarization Model"},
 				{browserAnalysisModelField, "Browser Analysis Model"},
+				{fallbackModelField, "Fallback Model"},
 				{baseURLField, "Base URL"},
 				{apiKeyField, "API Key"},
 			}
@@ -281,6 +292,13 @@ func (s *SettingsOverlay) loadSettings() {
 					}
 				}
 
+				// A key kept in the keyring is not part of the section data
+				if value == "" && field.key == apiKeyField {
+					if llmConfig, ok := sec.(*config.LLMSection); ok {
+						value = llmConfig.GetAPIKey()
+					}
+				}
+
 				// For summarization_model: default to the main model if not explicitly set.
 				if value == "" && field.key == summarizationModelField && s.provider != nil {
 					value = s.provider.GetModel()
@@ -309,6 +327,7 @@ func (s *SettingsOverlay) loadSettings() {
 				itemType    itemType
 			}{
 				{"show_thinking", "Show Thinking Blocks", itemTypeToggle},
+				{"syntax_highlighting", "Syntax Highlighting", itemTypeToggle},
 				{"auto_close_command_overlay", "Auto-close Command Overlay", itemTypeToggle},
 				{"keep_open_on_error", "Keep Open On Error", itemTypeToggle},
 				{"auto_close_delay", "Auto-close Delay", itemTypeText},
@@ -389,6 +408,63 @@ func (s *SettingsOverlay) loadSettings() {
 				}
 				section.items = append(section.items, item)
 			}
+
+		case config.SectionIDExperimental:
+			// Create a toggle for each experimental feature, in registry order
+			for _, feature := range config.ExperimentalFeatures() {
+				displayName := feature.Name
+				if project := config.GetProjectConfig(); project != nil {
+					if _, set := project.Experimental[feature.Name]; set {
+						displayName += " (set by project)"
+					}
+				}
+				item := settingsItem{
+					key:         feature.Name,
+					displayName: displayName,
+					value:       data[feature.Name],
+					itemType:    itemTypeToggle,
+					modified:    false,
+				}
+				section.items = append(section.items, item)
+			}
+
+		case "update":
+			// Create text and toggle items for self-update configuration
+			updateFields := []struct {
+				key         string
+				displayName string
+				itemType    itemType
+			}{
+				{"channel", "Release Channel (stable/beta)", itemTypeText},
+				{"check_for_updates", "Show Update Notices", itemTypeToggle},
+			}
+
+			for _, field := range updateFields {
+				item := settingsItem{
+					key:         field.key,
+					displayName: field.displayName,
+					value:       data[field.key],
+					itemType:    field.itemType,
+					modified:    false,
+				}
+				section.items = append(section.items, item)
+			}
+
+		case config.SectionIDSecurity:
+			// Edit the allowed hosts list as comma-separated text
+			var hosts []string
+			if list, ok := data["http_allowed_hosts"].([]any); ok {
+				for _, host := range list {
+					hosts = append(hosts, fmt.Sprintf("%v", host))
+				}
+			}
+			section.items = append(section.items, settingsItem{
+				key:         "http_allowed_hosts",
+				displayName: "HTTP Allowed Hosts (comma-separated)",
+				value:       strings.Join(hosts, ", "),
+				itemType:    itemTypeText,
+				modified:    false,
+			})
 		}
 
 		s.sections = append(s.sections, section)
@@ -432,6 +508,15 @@ func (s *SettingsOverlay) Update(msg tea.Msg, state types.StateProvider, actions
 		}
 
 		return s.handleKeyPress(msg)
+
+	case tea.MouseMsg:
+		switch {
+		case s.activeDialog != nil:
+			return s.handleDialogMouse(msg)
+		case s.confirmDialog != nil:
+			return s.handleConfirmMouse(msg)
+		}
+		return s.handleMouse(msg)
 	}
 	return s, nil
 }
@@ -488,6 +573,62 @@ func (s *SettingsOverlay) handleKeyPress(keyMsg tea.KeyMsg) (types.Overlay, tea.
 	return s, nil
 }
 
+// handleMouse scrolls the settings with the wheel and acts on a clicked
+// setting as Enter would, after selecting it.
+func (s *SettingsOverlay) handleMouse(msg tea.MouseMsg) (types.Overlay, tea.Cmd) {
+	if delta := wheelDelta(msg); delta != 0 {
+		// The View renderer clips the offset at the end of the body
+		s.scrollOffset = max(s.scrollOffset+delta*mouseWheelLines, 0)
+		return s, nil
+	}
+	if !isLeftClick(msg) {
+		return s, nil
+	}
+
+	// The body starts below the container's top border and the three header rows
+	row := msg.Y - types.CenterOffset(s.termHeight, s.height) - 4
+	if row < 0 || row >= s.getVisibleBodyHeight() {
+		return s, nil
+	}
+	section, item, ok := s.settingAt(s.scrollOffset + row)
+	if !ok {
+		return s, nil
+	}
+	s.selectedSection = section
+	s.selectedItem = max(item, 0)
+	if item < 0 {
+		return s, nil
+	}
+	return s, s.handleEnter()
+}
+
+// settingAt returns the section and item rendered on a line of the settings
+// body, with item -1 for a section's title and description.
+func (s *SettingsOverlay) settingAt(line int) (section, item int, ok bool) {
+	for i, sec := range s.sections {
+		if i > 0 {
+			// Blank line between sections
+			if line == 0 {
+				return 0, 0, false
+			}
+			line--
+		}
+		header := 1
+		if sec.description != "" {
+			header++
+		}
+		if line < header {
+			return i, -1, true
+		}
+		line -= header
+		if line < len(sec.items) {
+			return i, line, true
+		}
+		line -= len(sec.items)
+	}
+	return 0, 0, false
+}
+
 // handleEscape handles the escape key press
 func (s *SettingsOverlay) handleEscape() (types.Overlay, tea.Cmd) {
 	if s.hasChanges {
@@ -501,12 +642,15 @@ func (s *SettingsOverlay) handleEscape() (types.Overlay, tea.Cmd) {
 // handleSave handles the save command
 func (s *SettingsOverlay) handleSave() (types.Overlay, tea.Cmd) {
 	if s.hasChanges {
-		if err := s.saveSettings(); err == nil {
-			s.hasChanges = false
-			// Don't reload settings here - provider will be stale until next overlay open
-			// The onLLMSettingsChange callback reloads the provider in the agent,
-			// but this overlay instance still has the old provider reference
+		if err := s.saveSettings(); err != nil {
+			s.saveStatus = fmt.Sprintf("✗ %v", err)
+			s.saveStatusErr = true
+			return s, nil
 		}
+		s.hasChanges = false
+		// Don't reload settings here - provider will be stale until next overlay open
+		// The onLLMSettingsChange callback reloads the provider in the agent,
+		// but this overlay instance still has the old provider reference
 	}
 	return s, nil
 }
@@ -584,7 +728,7 @@ func (s *SettingsOverlay) getVisibleBodyHeight() int {
 	// header = ~3 lines (title, separator, blank space)
 	// footer = ~2 lines (separator, help) + 1 if hasChanges
 	h := s.height - 2 - 3 - 2
-	if s.hasChanges {
+	if s.hasChanges || s.saveStatus != "" {
 		h--
 	}
 	if h < 1 {
@@ -705,6 +849,8 @@ func (s *SettingsOverlay) saveSettings() error {
 	}
 
 	manager := config.Global()
+	s.saveStatus = "✓ Settings saved"
+	s.saveStatusErr = false
 
 	for _, section := range s.sections {
 		configSection, exists := manager.GetSection(section.id)
@@ -734,9 +880,19 @@ func (s *SettingsOverlay) saveSettings() error {
 		case llmSection:
 			// Save text field values for LLM settings
 			for _, item := range section.items {
-				if item.itemType == itemTypeText {
-					data[item.key] = item.value
+				if item.itemType != itemTypeText {
+					continue
 				}
+				// The API key goes to the keyring rather than the config file,
+				// and only when edited: the displayed key may have come from a
+				// flag or environment variable
+				if item.key == apiKeyField {
+					if item.modified {
+						s.storeAPIKey(configSection, item.value)
+					}
+					continue
+				}
+				data[item.key] = item.value
 			}
 
 		case "ui":
@@ -781,6 +937,23 @@ func (s *SettingsOverlay) saveSettings() error {
 				}
 				data[item.key] = item.value
 			}
+
+		case config.SectionIDExperimental:
+			for _, item := range section.items {
+				data[item.key] = item.value
+			}
+
+		case "update":
+			// Save update settings (toggle and text fields)
+			for _, item := range section.items {
+				data[item.key] = item.value
+			}
+
+		case config.SectionIDSecurity:
+			// SetData splits the comma-separated hosts text
+			for _, item := range section.items {
+				data[item.key] = item.value
+			}
 		}
 
 		// Update section
@@ -821,6 +994,23 @@ func (s *SettingsOverlay) saveSettings() error {
 	return nil
 }
 
+// storeAPIKey saves an edited API key through the LLM section, noting in the
+// save status when the keyring could not be used
+func (s *SettingsOverlay) storeAPIKey(section config.Section, value any) {
+	llmConfig, ok := section.(*config.LLMSection)
+	if !ok {
+		return
+	}
+	apiKey, _ := value.(string)
+	err := llmConfig.StoreAPIKey(apiKey)
+	switch {
+	case err == nil && llmConfig.GetAPIKeyStorage() == config.APIKeyStorageKeyring:
+		s.saveStatus = "✓ Settings saved, API key stored in the system keyring"
+	case err != nil:
+		s.saveStatus = "✓ Settings saved, API key stored in the config file (keyring unavailable)"
+	}
+}
+
 // renderViewHeader renders the title bar and top separator.
 func (s *SettingsOverlay) renderViewHeader(innerWidth int) string {
 	padLeft := (innerWidth - lipgloss.Width("Settings")) / 2
@@ -835,10 +1025,17 @@ func (s *SettingsOverlay) renderViewHeader(innerWidth int) string {
 // renderViewFooter renders the status bar, bottom separator and help text.
 func (s *SettingsOverlay) renderViewFooter(innerWidth int) string {
 	var b strings.Builder
-	if s.hasChanges {
+	switch {
+	case s.saveStatusErr:
+		b.WriteString(lipgloss.NewStyle().Foreground(types.SalmonPink).Bold(true).Render(s.saveStatus))
+		b.WriteString("\n")
+	case s.hasChanges:
 		b.WriteString(lipgloss.NewStyle().Foreground(types.SalmonPink).Bold(true).
 			Render("● Unsaved changes - Press Ctrl+S to save"))
 		b.WriteString("\n")
+	case s.saveStatus != "":
+		b.WriteString(lipgloss.NewStyle().Foreground(types.MintGreen).Render(s.saveStatus))
+		b.WriteString("\n")
 	}
 	b.WriteString(lipgloss.NewStyle().Foreground(types.MutedGray).Render(strings.Repeat(sepChar, innerWidth)))
 	b.WriteString("\n")
@@ -1554,6 +1751,74 @@ func (s *SettingsOverlay) handleConfirmInput(msg tea.Msg) (types.Overlay, tea.Cm
 	return s, nil
 }
 
+// handleDialogMouse handles clicks on the input dialog's buttons, fields
+// and radio options.
+func (s *SettingsOverlay) handleDialogMouse(msg tea.MouseMsg) (types.Overlay, tea.Cmd) {
+	if !isLeftClick(msg) {
+		return s, nil
+	}
+	view := s.renderWithDialog()
+	line := viewLine(view, msg.Y)
+	switch {
+	case labelAt(line, "[Enter to Add]", msg.X):
+		return s.handleDialogConfirm()
+	case labelAt(line, "[Esc to Cancel]", msg.X):
+		return s.handleDialogCancel()
+	}
+
+	i, ok := s.dialogFieldAt(view, msg.Y)
+	if !ok {
+		return s, nil
+	}
+	s.activeDialog.selectedField = i
+	field := &s.activeDialog.fields[i]
+	if field.fieldType == fieldTypeRadio {
+		for _, option := range field.options {
+			if labelAt(line, "○ "+option, msg.X) || labelAt(line, "● "+option, msg.X) {
+				field.value = option
+			}
+		}
+	}
+	return s, nil
+}
+
+// dialogFieldAt returns the input dialog field whose label, input or
+// options are rendered on row y of view.
+func (s *SettingsOverlay) dialogFieldAt(view string, y int) (int, bool) {
+	fields := s.activeDialog.fields
+	field := -1
+	for row, line := range strings.Split(view, "\n") {
+		if row > y {
+			break
+		}
+		// Labels stand alone on their row inside the dialog's border
+		text := strings.Trim(xansi.Strip(line), "│ ")
+		if strings.HasPrefix(text, "[Enter to Add]") {
+			return 0, false
+		}
+		if field+1 < len(fields) && text == fields[field+1].label {
+			field++
+		}
+	}
+	return field, field >= 0
+}
+
+// handleConfirmMouse answers the confirmation dialog when one of its
+// buttons is clicked.
+func (s *SettingsOverlay) handleConfirmMouse(msg tea.MouseMsg) (types.Overlay, tea.Cmd) {
+	if !isLeftClick(msg) {
+		return s, nil
+	}
+	line := viewLine(s.renderWithConfirmation(), msg.Y)
+	switch {
+	case labelAt(line, "[y] Yes, delete", msg.X):
+		return s.handleConfirmInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	case labelAt(line, "[n] No, cancel", msg.X):
+		return s.handleConfirmInput(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	}
+	return s, nil
+}
+
 // renderWithDialog renders the settings view with an input dialog overlay
 func (s *SettingsOverlay) renderWithDialog() string {
 	// Render dialog on top
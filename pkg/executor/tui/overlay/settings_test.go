@@ -2,9 +2,14 @@ package overlay
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/config"
+	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
 	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/types"
 )
@@ -792,3 +797,149 @@ func TestInputDialog_BracketedPaste(t *testing.T) {
 		}
 	})
 }
+
+// memoryKeyring is a config.Keyring backed by a map
+type memoryKeyring map[string]string
+
+func (k memoryKeyring) Get(account string) (string, error) {
+	if secret, ok := k[account]; ok {
+		return secret, nil
+	}
+	return "", config.ErrSecretNotFound
+}
+
+func (k memoryKeyring) Set(account, secret string) error {
+	k[account] = secret
+	return nil
+}
+
+func (k memoryKeyring) Delete(account string) error {
+	delete(k, account)
+	return nil
+}
+
+func TestSettingsOverlay_SaveLLMSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := config.Initialize(configPath); err != nil {
+		t.Fatalf("failed to initialize config: %v", err)
+	}
+	keyring := memoryKeyring{}
+	config.SetKeyring(keyring)
+	t.Cleanup(func() { config.SetKeyring(nil) })
+
+	provider := &mockProvider{model: "old-model", baseURL: "https://api.test.com", apiKey: "env-key"}
+	reloads := 0
+	overlay := NewSettingsOverlayWithCallback(100, 50, func() error {
+		reloads++
+		return nil
+	}, provider)
+
+	for i := range overlay.sections {
+		if overlay.sections[i].id != llmSection {
+			continue
+		}
+		for j := range overlay.sections[i].items {
+			item := &overlay.sections[i].items[j]
+			switch item.key {
+			case modelField:
+				item.value, item.modified = "new-model", true
+			case apiKeyField:
+				item.value, item.modified = "sk-new", true
+			}
+		}
+	}
+	overlay.hasChanges = true
+
+	overlay.handleSave()
+	if overlay.hasChanges || overlay.saveStatusErr {
+		t.Fatalf("expected the save to succeed, got status %q", overlay.saveStatus)
+	}
+	if reloads != 1 {
+		t.Errorf("expected the provider to be reloaded once, got %d", reloads)
+	}
+
+	llmConfig := config.GetLLM()
+	if llmConfig.GetModel() != "new-model" || llmConfig.GetAPIKey() != "sk-new" {
+		t.Errorf("unexpected LLM config: model %q, key %q", llmConfig.GetModel(), llmConfig.GetAPIKey())
+	}
+	if keyring["llm_api_key"] != "sk-new" {
+		t.Errorf("expected the API key in the keyring, got %v", keyring)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-new") || strings.Contains(string(data), "env-key") {
+		t.Errorf("API key written to the config file:\n%s", data)
+	}
+}
+
+func TestSettingsOverlay_Mouse(t *testing.T) {
+	overlay := NewSettingsOverlay(100, 50)
+	overlay.sections = []settingsSection{
+		{id: "ui", title: "Interface", items: []settingsItem{
+			{key: "a", displayName: "Option A", value: false, itemType: itemTypeToggle},
+			{key: "b", displayName: "Option B", value: false, itemType: itemTypeToggle},
+		}},
+		{id: "other", title: "Other", description: "More settings", items: []settingsItem{
+			{key: "c", displayName: "Option C", value: true, itemType: itemTypeToggle},
+		}},
+	}
+	// Body lines: Interface, Option A, Option B, blank, Other, description, Option C
+	bodyTop := tuitypes.CenterOffset(overlay.termHeight, overlay.height) + 4
+	click := func(line int) {
+		overlay.Update(tea.MouseMsg{X: 5, Y: bodyTop + line, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}, nil, nil)
+	}
+
+	click(2)
+	if overlay.selectedSection != 0 || overlay.selectedItem != 1 || overlay.sections[0].items[1].value != true {
+		t.Errorf("expected a click to select and toggle Option B, got section %d item %d", overlay.selectedSection, overlay.selectedItem)
+	}
+	click(6)
+	if overlay.selectedSection != 1 || overlay.sections[1].items[0].value != false {
+		t.Error("expected a click to select and toggle Option C")
+	}
+	click(3)
+	if overlay.selectedSection != 1 || !overlay.hasChanges {
+		t.Error("expected a click on the blank line between sections to do nothing")
+	}
+
+	overlay.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown}, nil, nil)
+	if overlay.scrollOffset != mouseWheelLines {
+		t.Errorf("expected the wheel to scroll %d lines, got %d", mouseWheelLines, overlay.scrollOffset)
+	}
+}
+
+func TestInputDialog_Mouse(t *testing.T) {
+	overlay := NewSettingsOverlay(100, 50)
+	confirmed := false
+	overlay.activeDialog = &inputDialog{
+		title: "Add Whitelist Pattern",
+		fields: []inputField{
+			{label: "Pattern", key: "pattern", fieldType: fieldTypeText},
+			{label: "Match Type", key: "type", value: "prefix", fieldType: fieldTypeRadio, options: []string{"prefix", "exact"}},
+		},
+		onConfirm: func(values map[string]string) error {
+			confirmed = values["type"] == "exact"
+			overlay.activeDialog = nil
+			return nil
+		},
+	}
+
+	overlay.Update(clickOn(t, overlay.renderWithDialog(), "○ exact"), nil, nil)
+	if overlay.activeDialog.selectedField != 1 || overlay.activeDialog.fields[1].value != "exact" {
+		t.Fatalf("expected a click to pick the exact option, got field %d value %q",
+			overlay.activeDialog.selectedField, overlay.activeDialog.fields[1].value)
+	}
+	// The first "Pattern" is the one in the title
+	overlay.Update(clickOn(t, overlay.renderWithDialog(), "Pattern"), nil, nil)
+	if overlay.activeDialog.selectedField != 1 {
+		t.Error("expected a click on the dialog title not to select a field")
+	}
+
+	overlay.Update(clickOn(t, overlay.renderWithDialog(), "[Enter to Add]"), nil, nil)
+	if !confirmed || overlay.activeDialog != nil {
+		t.Error("expected a click on the add button to confirm the dialog")
+	}
+}
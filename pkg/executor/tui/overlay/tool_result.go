@@ -59,6 +59,12 @@ func NewToolResultOverlay(toolName, result string, width, height int) *ToolResul
 	return overlay
 }
 
+// ScrollToLine scrolls the result so the zero-based line is at the top, or
+// as close to it as the content allows
+func (o *ToolResultOverlay) ScrollToLine(line int) {
+	o.Viewport().SetYOffset(line)
+}
+
 // Update handles messages
 func (o *ToolResultOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
 	handled, updatedBase, cmd := o.BaseOverlay.Update(msg, actions)
@@ -0,0 +1,259 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// sparkWidth is the maximum number of points in the context growth sparkline
+const sparkWidth = 60
+
+// sparkLevels are the bar heights used to draw sparklines, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// UsageOverlay displays the session's token usage and cost dashboard
+type UsageOverlay struct {
+	*BaseOverlay
+	title string
+}
+
+// UsageInfo contains the usage statistics to display
+type UsageInfo struct {
+	// Session totals
+	TotalPromptTokens     int
+	TotalCompletionTokens int
+	TotalCost             float64
+	UnpricedTurns         int // Turns whose model has no known price and are left out of TotalCost
+
+	Turns []TurnUsage
+	Tools []ToolUsage // Sorted heaviest first
+
+	// Summarization
+	Summarizations int
+	TokensSaved    int
+
+	// Prompt tokens of each LLM call, in order
+	ContextGrowth []int
+}
+
+// TurnUsage is the usage of one completed turn
+type TurnUsage struct {
+	Index            int
+	Model            string
+	APICalls         int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+	CostKnown        bool
+}
+
+// ToolUsage is the usage attributed to one tool
+type ToolUsage struct {
+	Name             string
+	Calls            int
+	CompletionTokens int // Output tokens spent writing the calls
+	ResultTokens     int // Approximate tokens the results added to the context
+}
+
+// NewUsageOverlay creates a new usage dashboard overlay
+func NewUsageOverlay(info *UsageInfo, width, height int) *UsageOverlay {
+	overlayWidth := types.ComputeOverlayWidth(width, 0.80, 56, 100)
+	viewportHeight := types.ComputeViewportHeight(height, 5)
+	overlayHeight := viewportHeight + 5
+
+	content := buildUsageContent(info)
+
+	overlay := &UsageOverlay{
+		title: "Token Usage",
+	}
+
+	baseConfig := BaseOverlayConfig{
+		Width:          overlayWidth,
+		Height:         overlayHeight,
+		ViewportWidth:  overlayWidth - 4,
+		ViewportHeight: viewportHeight,
+		Content:        content,
+		OnClose: func(actions types.ActionHandler) tea.Cmd {
+			return nil
+		},
+		RenderHeader: overlay.renderHeader,
+		RenderFooter: overlay.renderFooter,
+	}
+
+	overlay.BaseOverlay = NewBaseOverlay(baseConfig)
+	return overlay
+}
+
+// formatUSD formats a USD amount with enough precision for sub-cent turns
+func formatUSD(usd float64) string {
+	if usd >= 1 {
+		return fmt.Sprintf("$%.2f", usd)
+	}
+	return fmt.Sprintf("$%.4f", usd)
+}
+
+// sparkline renders values as a row of bars scaled to the largest value.
+// Longer series are bucketed down to width points, keeping each bucket's peak.
+func sparkline(values []int, width int) string {
+	if len(values) == 0 || width <= 0 {
+		return ""
+	}
+
+	points := values
+	if len(values) > width {
+		points = make([]int, width)
+		for i := range points {
+			start := i * len(values) / width
+			end := (i + 1) * len(values) / width
+			for _, v := range values[start:end] {
+				points[i] = max(points[i], v)
+			}
+		}
+	}
+
+	peak := 0
+	for _, v := range points {
+		peak = max(peak, v)
+	}
+
+	var b strings.Builder
+	for _, v := range points {
+		level := 0
+		if peak > 0 {
+			level = v * (len(sparkLevels) - 1) / peak
+		}
+		b.WriteRune(sparkLevels[level])
+	}
+	return b.String()
+}
+
+// buildUsageContent formats the usage information for display
+func buildUsageContent(info *UsageInfo) string {
+	var b strings.Builder
+	heading := lipgloss.NewStyle().Bold(true).Foreground(types.SalmonPink)
+
+	// Session totals section
+	b.WriteString(heading.Render("Session Totals"))
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "  Input Tokens:       %s\n", formatTokenCount(info.TotalPromptTokens))
+	fmt.Fprintf(&b, "  Output Tokens:      %s\n", formatTokenCount(info.TotalCompletionTokens))
+	cost := formatUSD(info.TotalCost)
+	if info.UnpricedTurns > 0 {
+		cost += fmt.Sprintf(" (excludes %d turns with unknown pricing)", info.UnpricedTurns)
+	}
+	fmt.Fprintf(&b, "  Estimated Cost:     %s\n", cost)
+	b.WriteString("\n")
+
+	// Per-turn section
+	b.WriteString(heading.Render("By Turn"))
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "  %-5s %-6s %-9s %-9s %s\n", "Turn", "Calls", "Input", "Output", "Cost")
+	for _, turn := range info.Turns {
+		cost := "-"
+		if turn.CostKnown {
+			cost = formatUSD(turn.Cost)
+		}
+		fmt.Fprintf(&b, "  %-5d %-6d %-9s %-9s %s\n",
+			turn.Index, turn.APICalls, formatTokenCount(turn.PromptTokens), formatTokenCount(turn.CompletionTokens), cost)
+	}
+	b.WriteString("\n")
+
+	// Per-tool section
+	b.WriteString(heading.Render("By Tool"))
+	b.WriteString("\n")
+	if len(info.Tools) == 0 {
+		b.WriteString("  No tool calls yet\n")
+	} else {
+		fmt.Fprintf(&b, "  %-24s %-6s %-9s %s\n", "Tool", "Calls", "Output", "Results (approx.)")
+		for _, tool := range info.Tools {
+			fmt.Fprintf(&b, "  %-24s %-6d %-9s %s\n",
+				tool.Name, tool.Calls, formatTokenCount(tool.CompletionTokens), formatTokenCount(tool.ResultTokens))
+		}
+	}
+	b.WriteString("\n")
+
+	// Summarization section
+	b.WriteString(heading.Render("Summarization"))
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "  Runs:               %d\n", info.Summarizations)
+	fmt.Fprintf(&b, "  Context Saved:      %s tokens\n", formatTokenCount(info.TokensSaved))
+	b.WriteString("\n")
+
+	// Context growth section
+	b.WriteString(heading.Render("Context Growth"))
+	b.WriteString("\n")
+	if len(info.ContextGrowth) > 0 {
+		peak := 0
+		for _, tokens := range info.ContextGrowth {
+			peak = max(peak, tokens)
+		}
+		spark := lipgloss.NewStyle().Foreground(types.ProgressGreen).Render(sparkline(info.ContextGrowth, sparkWidth))
+		fmt.Fprintf(&b, "  %s\n", spark)
+		fmt.Fprintf(&b, "  %d calls, peak %s tokens, latest %s tokens\n",
+			len(info.ContextGrowth), formatTokenCount(peak), formatTokenCount(info.ContextGrowth[len(info.ContextGrowth)-1]))
+	}
+
+	return b.String()
+}
+
+// Update handles messages for the usage overlay
+func (u *UsageOverlay) Update(msg tea.Msg, state types.StateProvider, actions types.ActionHandler) (types.Overlay, tea.Cmd) {
+	handled, updatedBase, cmd := u.BaseOverlay.Update(msg, actions)
+	u.BaseOverlay = updatedBase
+
+	if handled {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			if keyMsg.String() == keyEsc || keyMsg.String() == keyCtrlC {
+				return nil, cmd
+			}
+		}
+		return u, cmd
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyEnter {
+			return nil, u.close(actions)
+		}
+	case tea.WindowSizeMsg:
+		newOverlayWidth := types.ComputeOverlayWidth(msg.Width, 0.80, 56, 100)
+		vpHeight := types.ComputeViewportHeight(msg.Height, 5)
+
+		u.SetDimensions(newOverlayWidth, vpHeight+5)
+
+		vp := u.Viewport()
+		vp.Width = newOverlayWidth - 4
+		vp.Height = vpHeight
+	}
+
+	return u, nil
+}
+
+// renderHeader renders the usage overlay header
+func (u *UsageOverlay) renderHeader() string {
+	contentWidth := u.BaseOverlay.Viewport().Width
+
+	titlePadding := max(0, (contentWidth-len(u.title))/2)
+	separator := lipgloss.NewStyle().Foreground(types.MutedGray).Render(strings.Repeat(sepChar, contentWidth))
+
+	return strings.Repeat(" ", titlePadding) + types.OverlayTitleStyle.Render(u.title) + "\n" + separator + "\n"
+}
+
+// renderFooter renders the usage overlay footer
+func (u *UsageOverlay) renderFooter() string {
+	contentWidth := u.BaseOverlay.Viewport().Width
+
+	hint := "ESC or Enter to close • ↑/↓ to scroll"
+	hintPadding := max(0, (contentWidth-lipgloss.Width(hint))/2)
+
+	return "\n" + strings.Repeat(" ", hintPadding) + types.OverlayHelpStyle.Render(hint)
+}
+
+// View renders the overlay
+func (u *UsageOverlay) View() string {
+	return u.BaseOverlay.View(u.Width())
+}
@@ -0,0 +1,44 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]int{0, 50, 100}, 10); got != "▁▄█" {
+		t.Errorf("sparkline = %q", got)
+	}
+	if got := sparkline(nil, 10); got != "" {
+		t.Errorf("expected an empty sparkline, got %q", got)
+	}
+
+	// Long series are bucketed down to the width, keeping each bucket's peak
+	values := make([]int, 100)
+	values[99] = 1000
+	got := sparkline(values, 10)
+	if len([]rune(got)) != 10 || !strings.HasSuffix(got, "█") {
+		t.Errorf("sparkline = %q", got)
+	}
+}
+
+func TestBuildUsageContent(t *testing.T) {
+	content := buildUsageContent(&UsageInfo{
+		TotalPromptTokens:     12_000,
+		TotalCompletionTokens: 800,
+		TotalCost:             0.048,
+		UnpricedTurns:         1,
+		Turns: []TurnUsage{
+			{Index: 1, APICalls: 2, PromptTokens: 12_000, CompletionTokens: 800, Cost: 0.048, CostKnown: true},
+			{Index: 2, APICalls: 1},
+		},
+		Tools:         []ToolUsage{{Name: "read_file", Calls: 3, CompletionTokens: 300, ResultTokens: 4_000}},
+		ContextGrowth: []int{5_000, 7_000},
+	})
+
+	for _, want := range []string{"$0.0480 (excludes 1 turns with unknown pricing)", "read_file", "4.0K", "peak 7.0K tokens"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in content:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// pasteAttachmentMinLines is the size at which a bracketed paste is saved to a
+// snippet file instead of being inserted into the input verbatim.
+const pasteAttachmentMinLines = 200
+
+// pastedSnippet is a large paste that was saved to disk and replaced in the
+// input by a short placeholder.
+type pastedSnippet struct {
+	placeholder string
+	path        string // Relative to the workspace so the agent's file tools can read it
+	lines       int
+}
+
+// attachPaste saves text to <workspace>/.forge/pastes/ and inserts a
+// placeholder at the cursor. It returns false when the paste is small enough
+// to insert normally or cannot be saved.
+func (m *model) attachPaste(text string) bool {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Count(strings.TrimRight(text, "\n"), "\n") + 1
+	if lines < pasteAttachmentMinLines {
+		return false
+	}
+
+	outDir := filepath.Join(m.workspaceDir, ".forge", "pastes")
+	if err := os.MkdirAll(outDir, 0o750); err != nil {
+		m.showToast("Paste not attached", fmt.Sprintf("Could not create directory: %v", err), "!", true)
+		return false
+	}
+
+	index := len(m.pastedSnippets) + 1
+	filename := fmt.Sprintf("%s-%d.txt", time.Now().Format("20060102-150405"), index)
+	if err := os.WriteFile(filepath.Join(outDir, filename), []byte(text), 0o600); err != nil {
+		m.showToast("Paste not attached", fmt.Sprintf("Could not write file: %v", err), "!", true)
+		return false
+	}
+
+	snippet := pastedSnippet{
+		placeholder: fmt.Sprintf("[Pasted text #%d: %d lines]", index, lines),
+		path:        filepath.ToSlash(filepath.Join(".forge", "pastes", filename)),
+		lines:       lines,
+	}
+	m.pastedSnippets = append(m.pastedSnippets, snippet)
+	m.textarea.InsertString(snippet.placeholder)
+	m.updateTextAreaHeight()
+	m.showToast("Paste attached", snippet.path, "📎", false)
+	return true
+}
+
+// expandPastedSnippets appends a reference to every attached snippet whose
+// placeholder is still in input, then forgets all pending snippets. Snippets
+// whose placeholder the user deleted are not sent.
+func (m *model) expandPastedSnippets(input string) string {
+	snippets := m.pastedSnippets
+	m.pastedSnippets = nil
+	return expandSnippets(input, snippets)
+}
+
+// expandSnippets appends a reference to every snippet whose placeholder is in
+// input.
+func expandSnippets(input string, snippets []pastedSnippet) string {
+	var refs []string
+	for _, snippet := range snippets {
+		if strings.Contains(input, snippet.placeholder) {
+			refs = append(refs, fmt.Sprintf("- %s → %s (%d lines)", snippet.placeholder, snippet.path, snippet.lines))
+		}
+	}
+
+	if len(refs) == 0 {
+		return input
+	}
+	return input + "\n\nPasted text was saved to these files; use read_file to view them:\n" + strings.Join(refs, "\n")
+}
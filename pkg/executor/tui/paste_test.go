@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+)
+
+func newPasteTestModel(t *testing.T) *model {
+	t.Helper()
+	m := initialModel()
+	m.workspaceDir = t.TempDir()
+	m.textarea = textarea.New()
+	m.textarea.CharLimit = 0
+	return &m
+}
+
+func TestAttachPaste_SmallPasteIsInlined(t *testing.T) {
+	m := newPasteTestModel(t)
+
+	if m.attachPaste("one\ntwo\nthree") {
+		t.Fatal("expected small paste to be inserted normally")
+	}
+	if len(m.pastedSnippets) != 0 {
+		t.Errorf("expected no snippets, got %d", len(m.pastedSnippets))
+	}
+}
+
+func TestAttachPaste_LargePasteIsSaved(t *testing.T) {
+	m := newPasteTestModel(t)
+	text := strings.Repeat("log line\n", pasteAttachmentMinLines+50)
+
+	if !m.attachPaste(text) {
+		t.Fatal("expected large paste to be attached")
+	}
+	if got := m.textarea.Value(); got != "[Pasted text #1: 250 lines]" {
+		t.Errorf("unexpected placeholder %q", got)
+	}
+
+	snippet := m.pastedSnippets[0]
+	data, err := os.ReadFile(filepath.Join(m.workspaceDir, filepath.FromSlash(snippet.path)))
+	if err != nil {
+		t.Fatalf("snippet file not written: %v", err)
+	}
+	if string(data) != text {
+		t.Error("snippet file does not match pasted text")
+	}
+
+	sent := m.expandPastedSnippets("look at this " + m.textarea.Value())
+	if !strings.Contains(sent, snippet.path) {
+		t.Errorf("expected message to reference %s, got %q", snippet.path, sent)
+	}
+	if strings.Contains(sent, "log line") {
+		t.Error("pasted text should not be inlined into the message")
+	}
+	if len(m.pastedSnippets) != 0 {
+		t.Error("expected snippets to be cleared after sending")
+	}
+}
+
+func TestExpandPastedSnippets_DeletedPlaceholder(t *testing.T) {
+	m := newPasteTestModel(t)
+	m.attachPaste(strings.Repeat("x\n", pasteAttachmentMinLines))
+
+	if sent := m.expandPastedSnippets("never mind"); sent != "never mind" {
+		t.Errorf("expected removed placeholder to drop the attachment, got %q", sent)
+	}
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// maxPinFileBytes caps the size of a file /pin adds to the context, since
+// pinned content is never summarized
+const maxPinFileBytes = 64 * 1024
+
+// parsePinArgs turns /pin arguments into a pin request. No arguments pin the
+// last message sent, a single argument naming a file pins the file's
+// content, and anything else is pinned as text.
+func parsePinArgs(workspaceDir string, roots []workspace.Root, args []string) (types.PinRequestParams, error) {
+	if len(args) == 0 {
+		return types.PinRequestParams{}, nil
+	}
+
+	if len(args) == 1 {
+		path := resolvePinPath(workspaceDir, roots, args[0])
+		if info, err := os.Stat(path); err == nil {
+			if info.IsDir() {
+				return types.PinRequestParams{}, fmt.Errorf("%s is a directory; pin individual files", args[0])
+			}
+			if info.Size() > maxPinFileBytes {
+				return types.PinRequestParams{}, fmt.Errorf("%s is %d KB; only files up to %d KB can be pinned", args[0], info.Size()/1024, maxPinFileBytes/1024)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return types.PinRequestParams{}, err
+			}
+			if bytes.IndexByte(content, 0) >= 0 {
+				return types.PinRequestParams{}, fmt.Errorf("%s is a binary file", args[0])
+			}
+			return types.PinRequestParams{Label: args[0], Content: string(content)}, nil
+		}
+	}
+
+	return types.PinRequestParams{Label: "note", Content: strings.Join(args, " ")}, nil
+}
+
+// resolvePinPath resolves a /pin argument like tools resolve paths: relative
+// to the workspace, or to a root when it starts with @name/.
+func resolvePinPath(workspaceDir string, roots []workspace.Root, arg string) string {
+	if rest, ok := strings.CutPrefix(arg, workspace.RootPrefix); ok {
+		name, rel, _ := strings.Cut(rest, "/")
+		for _, root := range roots {
+			if root.Name == name {
+				return filepath.Join(root.Path, filepath.FromSlash(rel))
+			}
+		}
+	}
+	if filepath.IsAbs(arg) {
+		return arg
+	}
+	return filepath.Join(workspaceDir, arg)
+}
+
+// handlePinCommand pins context so the agent's summarization keeps it
+// verbatim.
+func handlePinCommand(m *model, args []string) any {
+	if m.channels == nil {
+		m.showToast("Error", "Agent not available", "✗", true)
+		return nil
+	}
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish or /stop it before pinning", "⚠", true)
+		return nil
+	}
+
+	params, err := parsePinArgs(m.workspaceDir, m.roots, args)
+	if err != nil {
+		m.showToast("Cannot pin", err.Error(), "✗", true)
+		return nil
+	}
+
+	details := "Your last message will not be summarized"
+	if params.Content != "" {
+		details = fmt.Sprintf("Added %s to the context; it will not be summarized", params.Label)
+	}
+	m.showToast("Pinned", details, "📌", false)
+
+	return func() tea.Msg {
+		m.channels.Input <- types.NewPinRequestInput(params)
+		return nil
+	}
+}
+
+// handleUnpinCommand removes every pin, letting summarization reduce pinned
+// messages again.
+func handleUnpinCommand(m *model, args []string) any {
+	if m.channels == nil {
+		m.showToast("Error", "Agent not available", "✗", true)
+		return nil
+	}
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish or /stop it before unpinning", "⚠", true)
+		return nil
+	}
+
+	m.showToast("Unpinned", "Pinned context can be summarized again", "◆", false)
+
+	return func() tea.Msg {
+		m.channels.Input <- types.NewPinRequestInput(types.PinRequestParams{Unpin: true})
+		return nil
+	}
+}
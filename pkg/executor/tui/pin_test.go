@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+func TestParsePinArgs(t *testing.T) {
+	dir := t.TempDir()
+	protoDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "SPEC.md"), []byte("# Spec"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(protoDir, "api.proto"), []byte("syntax = \"proto3\";"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "big.log"), []byte(strings.Repeat("x", maxPinFileBytes+1)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	roots := []workspace.Root{{Name: "proto", Path: protoDir}}
+
+	params, err := parsePinArgs(dir, roots, nil)
+	if err != nil || params.Content != "" {
+		t.Errorf("expected no arguments to pin the last message, got %+v, %v", params, err)
+	}
+
+	params, err = parsePinArgs(dir, roots, []string{"SPEC.md"})
+	if err != nil || params.Label != "SPEC.md" || params.Content != "# Spec" {
+		t.Errorf("expected the file to be pinned, got %+v, %v", params, err)
+	}
+
+	params, err = parsePinArgs(dir, roots, []string{"@proto/api.proto"})
+	if err != nil || !strings.Contains(params.Content, "proto3") {
+		t.Errorf("expected a file in a root to be pinned, got %+v, %v", params, err)
+	}
+
+	params, err = parsePinArgs(dir, roots, []string{"Keep", "the", "public", "API", "stable"})
+	if err != nil || params.Content != "Keep the public API stable" {
+		t.Errorf("expected text to be pinned, got %+v, %v", params, err)
+	}
+
+	if _, err := parsePinArgs(dir, roots, []string{"big.log"}); err == nil {
+		t.Error("expected oversized files to be rejected")
+	}
+	if _, err := parsePinArgs(dir, roots, []string{"@proto"}); err == nil {
+		t.Error("expected directories to be rejected")
+	}
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/executor/tui/overlay"
+	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
+)
+
+// profileOff is the /profile argument that returns to the default mode.
+const profileOff = "off"
+
+// handleProfileCommand lists the available profiles, or with an argument
+// switches to the named profile, or back to the default mode with "off".
+func handleProfileCommand(m *model, args []string) any {
+	defaultAgent, ok := m.agent.(*agent.DefaultAgent)
+	if !ok {
+		m.showToast("Error", "This agent does not support profiles", "✗", true)
+		return nil
+	}
+
+	profiles, err := config.LoadProfiles("", config.GetProjectConfig())
+	if err != nil {
+		m.showToast("Profiles not loaded", err.Error(), "✗", true)
+		return nil
+	}
+
+	if len(args) == 0 {
+		active := ""
+		if profile := defaultAgent.GetProfile(); profile != nil {
+			active = profile.Name
+		}
+		profilesOverlay := overlay.NewHelpOverlay("Profiles", buildProfilesContent(profiles, active), m.width, m.height)
+		m.overlay.activate(tuitypes.OverlayModeHelp, profilesOverlay)
+		return nil
+	}
+
+	var profile *config.Profile
+	if args[0] != profileOff {
+		if profile, err = config.LookupProfile(profiles, args[0]); err != nil {
+			m.showToast("Profile not switched", err.Error(), "✗", true)
+			return nil
+		}
+	}
+	if err := m.switchProfile(defaultAgent, profile); err != nil {
+		m.showToast("Profile not switched", err.Error(), "✗", true)
+		return nil
+	}
+
+	if profile == nil {
+		m.showToast("Profile cleared", "Back to the default instructions and tools", "⇄", false)
+	} else {
+		m.showToast("Profile switched", profile.Name+" takes effect from the next LLM call", "⇄", false)
+	}
+	return nil
+}
+
+// switchProfile makes profile the agent's working mode, or returns to the
+// default mode when profile is nil, and records the switch in the transcript.
+// A profile with a model switches the main model first; leaving a profile
+// keeps the current model.
+func (m *model) switchProfile(defaultAgent *agent.DefaultAgent, profile *config.Profile) error {
+	previous := defaultAgent.GetProfile()
+	if profile != nil && profile.Model != "" {
+		if err := m.switchModel(profile.Model, overlay.ModelRoleMain); err != nil {
+			return err
+		}
+	}
+	defaultAgent.SetProfile(profile)
+
+	var notice string
+	switch {
+	case profile == nil && previous == nil,
+		profile != nil && previous != nil && profile.Name == previous.Name:
+		return nil
+	case profile == nil:
+		notice = fmt.Sprintf("Left profile %s", previous.Name)
+	case previous == nil:
+		notice = fmt.Sprintf("Switched to profile %s", profile.Name)
+	default:
+		notice = fmt.Sprintf("Switched profile from %s to %s", previous.Name, profile.Name)
+	}
+
+	m.appendMsg(newEntryMsg("⇄ ", notice, tipsStyle, "\n\n"))
+	m.recalculateLayout()
+	return nil
+}
+
+// buildProfilesContent renders the profiles with their description and what
+// each changes, marking the active one.
+func buildProfilesContent(profiles map[string]*config.Profile, active string) string {
+	nameStyle := lipgloss.NewStyle().Foreground(tuitypes.SalmonPink)
+	textStyle := lipgloss.NewStyle().Foreground(tuitypes.BrightWhite)
+	descStyle := lipgloss.NewStyle().Foreground(tuitypes.MutedGray)
+
+	var b strings.Builder
+	if len(profiles) == 0 {
+		b.WriteString(descStyle.Render("No profiles are defined. Add them under profiles: in ~/" +
+			config.UserProfilesPath + " or " + config.ProjectConfigPath + "."))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	for _, name := range config.ProfileNames(profiles) {
+		profile := profiles[name]
+		marker := "  "
+		if name == active {
+			marker = "● "
+		}
+		b.WriteString(marker)
+		b.WriteString(nameStyle.Render(name))
+		if profile.Description != "" {
+			b.WriteString("  ")
+			b.WriteString(textStyle.Render(profile.Description))
+		}
+		b.WriteString("\n")
+		if details := profileDetails(profile); details != "" {
+			b.WriteString("    ")
+			b.WriteString(descStyle.Render(details))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(descStyle.Render("Switch with /profile <name>; /profile " + profileOff + " returns to the default mode."))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// profileDetails summarizes what a profile changes, e.g.
+// "model gpt-5 · read-only · only 4 tools".
+func profileDetails(profile *config.Profile) string {
+	var parts []string
+	if profile.Model != "" {
+		parts = append(parts, "model "+profile.Model)
+	}
+	parts = append(parts, profile.Constraints...)
+	if n := len(profile.EnabledTools); n > 0 {
+		parts = append(parts, fmt.Sprintf("only %d tools", n))
+	}
+	if n := len(profile.DisabledTools); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d tools off", n))
+	}
+	return strings.Join(parts, " · ")
+}
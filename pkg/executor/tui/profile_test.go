@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent"
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm/openai"
+)
+
+func TestSwitchProfile(t *testing.T) {
+	provider, err := openai.NewProvider("test-key", openai.WithModel("gpt-4o"))
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	ag := agent.NewDefaultAgent(provider)
+
+	m := initialModel()
+	m.provider = provider
+	m.agent = ag
+
+	reviewer := &config.Profile{Name: "reviewer", Model: "gpt-4.1", Constraints: []string{"read-only"}}
+	if err := m.switchProfile(ag, reviewer); err != nil {
+		t.Fatalf("switchProfile: %v", err)
+	}
+	if ag.GetProfile() != reviewer {
+		t.Error("expected the agent to use the profile")
+	}
+	if got := m.provider.GetModel(); got != "gpt-4.1" {
+		t.Errorf("expected the profile's model, got %s", got)
+	}
+
+	if err := m.switchProfile(ag, nil); err != nil {
+		t.Fatalf("switchProfile: %v", err)
+	}
+	if ag.GetProfile() != nil || m.provider.GetModel() != "gpt-4.1" {
+		t.Error("expected leaving the profile to clear it and keep the model")
+	}
+
+	transcript := stripANSI(m.renderMessages(120))
+	for _, want := range []string{"Switched main model from gpt-4o to gpt-4.1", "Switched to profile reviewer", "Left profile reviewer"} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("expected %q in the transcript, got:\n%s", want, transcript)
+		}
+	}
+}
+
+func TestBuildProfilesContent(t *testing.T) {
+	content := stripANSI(buildProfilesContent(map[string]*config.Profile{
+		"reviewer": {Description: "Review without editing", Model: "gpt-4.1", Constraints: []string{"read-only"}},
+		"docs":     {EnabledTools: []string{"read_file", "write_file"}},
+	}, "reviewer"))
+
+	for _, want := range []string{"● reviewer  Review without editing", "model gpt-4.1 · read-only", "  docs", "only 2 tools", "/profile off"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in profiles content:\n%s", want, content)
+		}
+	}
+
+	if content := buildProfilesContent(nil, ""); !strings.Contains(content, "No profiles are defined") {
+		t.Errorf("expected a hint for adding profiles, got:\n%s", content)
+	}
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxQueuePanelItems caps the queued messages the queue panel lists.
+const maxQueuePanelItems = 3
+
+// queuedMessage is a message typed while the agent was busy. It is sent once
+// the agent goes idle.
+type queuedMessage struct {
+	input    string          // As typed, with paste placeholders
+	snippets []pastedSnippet // Pastes attached while it was typed
+}
+
+// queueMessage holds input, and the pastes attached to it, until the agent
+// is idle.
+func (m *model) queueMessage(input string) {
+	m.queuedMessages = append(m.queuedMessages, queuedMessage{input: input, snippets: m.pastedSnippets})
+	m.pastedSnippets = nil
+	m.textarea.Reset()
+	m.updateTextAreaHeight()
+	m.recalculateLayout()
+}
+
+// recallQueuedMessage takes the most recently queued message out of the queue
+// and puts it in the empty input box, to be edited and queued again or
+// cleared to drop it. It reports whether a message was recalled.
+func (m *model) recallQueuedMessage() bool {
+	if len(m.queuedMessages) == 0 || m.bashMode || m.textarea.Value() != "" {
+		return false
+	}
+	last := m.queuedMessages[len(m.queuedMessages)-1]
+	m.queuedMessages = m.queuedMessages[:len(m.queuedMessages)-1]
+	m.pastedSnippets = append(m.pastedSnippets, last.snippets...)
+
+	m.textarea.SetValue(last.input)
+	m.textarea.CursorEnd()
+	m.updateTextAreaHeight()
+	m.recalculateLayout()
+	return true
+}
+
+// sendQueuedMessage sends the oldest queued message when the agent is idle.
+// Each queued message gets a turn of its own.
+func (m *model) sendQueuedMessage() {
+	if m.agentBusy || len(m.queuedMessages) == 0 || m.channels == nil {
+		return
+	}
+	next := m.queuedMessages[0]
+	m.queuedMessages = m.queuedMessages[1:]
+	m.sendAgentMessage(next.input, expandSnippets(next.input, next.snippets))
+}
+
+// returnQueuedMessages moves every queued message back into the input box, so
+// stopping the agent does not start the next turn behind the user's back. It
+// does nothing when the input box is in use.
+func (m *model) returnQueuedMessages() {
+	if len(m.queuedMessages) == 0 || m.textarea.Value() != "" {
+		return
+	}
+	inputs := make([]string, 0, len(m.queuedMessages))
+	for _, queued := range m.queuedMessages {
+		inputs = append(inputs, queued.input)
+		m.pastedSnippets = append(m.pastedSnippets, queued.snippets...)
+	}
+	m.queuedMessages = nil
+
+	m.textarea.SetValue(strings.Join(inputs, "\n\n"))
+	m.textarea.CursorEnd()
+	m.updateTextAreaHeight()
+	m.recalculateLayout()
+}
+
+// queuePanelLines renders the queue panel shown above the input: a header
+// and the first line of each queued message, oldest first. It returns nil
+// when nothing is queued.
+func (m *model) queuePanelLines() []string {
+	if len(m.queuedMessages) == 0 {
+		return nil
+	}
+
+	header := fmt.Sprintf("  Queued · %d · sent when the agent is done · ↑ to edit", len(m.queuedMessages))
+	lines := []string{tipsStyle.Render(header)}
+
+	shown := min(len(m.queuedMessages), maxQueuePanelItems)
+	contentWidth := max(m.width-10, 10)
+	style := lipgloss.NewStyle().Foreground(brightWhite)
+	for _, queued := range m.queuedMessages[:shown] {
+		content, _, _ := strings.Cut(queued.input, "\n")
+		if runes := []rune(content); len(runes) > contentWidth {
+			content = string(runes[:contentWidth-1]) + "…"
+		}
+		lines = append(lines, "    "+style.Render("› "+content))
+	}
+	if hidden := len(m.queuedMessages) - shown; hidden > 0 {
+		lines = append(lines, tipsStyle.Render(fmt.Sprintf("    … %d more", hidden)))
+	}
+	return lines
+}
+
+// buildQueuePanel renders the queue panel, or "" when nothing is queued.
+func (m *model) buildQueuePanel() string {
+	return strings.Join(m.queuePanelLines(), "\n")
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	pkgtypes "github.com/entrhq/forge/pkg/types"
+)
+
+func TestQueueMessagesWhileBusy(t *testing.T) {
+	m := newHeightTestModel(30)
+	m.channels = pkgtypes.NewAgentChannels(4)
+	m.agentBusy = true
+
+	m.textarea.SetValue("Also update the README")
+	m.handleEnter(nil, nil, nil)
+	m.textarea.SetValue("Then run the linter")
+	m.handleEnter(nil, nil, nil)
+
+	if len(m.channels.Input) != 0 {
+		t.Fatal("expected nothing to be sent mid-turn")
+	}
+	if len(m.queuedMessages) != 2 || m.textarea.Value() != "" {
+		t.Fatalf("expected two queued messages and an empty input, got %+v", m.queuedMessages)
+	}
+	lines := m.queuePanelLines()
+	if len(lines) != 3 || !strings.Contains(lines[0], "Queued · 2") || !strings.Contains(lines[1], "Also update the README") {
+		t.Errorf("queue panel = %q", lines)
+	}
+
+	// The end of the turn sends the oldest message as a turn of its own
+	m.handleUpdateBusy(pkgtypes.NewUpdateBusyEvent(false))
+	input := <-m.channels.Input
+	if !input.IsUserInput() || input.Content != "Also update the README" {
+		t.Fatalf("expected the first queued message, got %s %q", input.Type, input.Content)
+	}
+	if !m.agentBusy || len(m.queuedMessages) != 1 {
+		t.Errorf("expected the agent busy with one message left, busy=%v queued=%d", m.agentBusy, len(m.queuedMessages))
+	}
+	if transcript := stripANSI(m.renderMessages(120)); !strings.Contains(transcript, "Also update the README") {
+		t.Errorf("expected the sent message in the transcript, got:\n%s", transcript)
+	}
+
+	m.handleUpdateBusy(pkgtypes.NewUpdateBusyEvent(false))
+	if input := <-m.channels.Input; input.Content != "Then run the linter" {
+		t.Errorf("expected the second queued message, got %q", input.Content)
+	}
+	if m.queuePanelLines() != nil {
+		t.Error("expected the queue panel to be hidden once the queue is empty")
+	}
+}
+
+func TestRecallQueuedMessage(t *testing.T) {
+	m := newHeightTestModel(30)
+	m.channels = pkgtypes.NewAgentChannels(4)
+	m.agentBusy = true
+	m.queueMessage("first")
+	m.queueMessage("second")
+
+	// Up takes the most recent message back out for editing
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyUp}, nil, nil, nil)
+	if m.textarea.Value() != "second" || len(m.queuedMessages) != 1 {
+		t.Fatalf("expected the last queued message in the input, got %q with %d queued", m.textarea.Value(), len(m.queuedMessages))
+	}
+
+	// Up does nothing while the input is in use
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyUp}, nil, nil, nil)
+	if m.textarea.Value() != "second" || len(m.queuedMessages) != 1 {
+		t.Fatal("expected the input to be left alone")
+	}
+
+	m.textarea.SetValue("second, edited")
+	m.handleEnter(nil, nil, nil)
+	if len(m.queuedMessages) != 2 || m.queuedMessages[1].input != "second, edited" {
+		t.Errorf("expected the edited message to be queued again, got %+v", m.queuedMessages)
+	}
+}
+
+func TestStopReturnsQueuedMessages(t *testing.T) {
+	m := newHeightTestModel(30)
+	m.channels = pkgtypes.NewAgentChannels(4)
+	m.agentBusy = true
+	m.queueMessage("first")
+	m.queueMessage("second")
+
+	handleStopCommand(m, nil)
+	if input := <-m.channels.Input; !input.IsCancel() {
+		t.Fatalf("expected a cancel, got %s", input.Type)
+	}
+	if len(m.queuedMessages) != 0 || m.textarea.Value() != "first\n\nsecond" {
+		t.Fatalf("expected the queued messages back in the input, got %q", m.textarea.Value())
+	}
+
+	// Nothing is left to start a turn once the agent goes idle
+	m.handleUpdateBusy(pkgtypes.NewUpdateBusyEvent(false))
+	if len(m.channels.Input) != 0 {
+		t.Error("expected no message to be sent")
+	}
+}
+
+func TestQueuedMessageKeepsItsPastes(t *testing.T) {
+	m := newHeightTestModel(30)
+	m.channels = pkgtypes.NewAgentChannels(4)
+	m.agentBusy = true
+
+	snippet := pastedSnippet{placeholder: "[Pasted text #1: 300 lines]", path: ".forge/pastes/a.txt", lines: 300}
+	m.pastedSnippets = []pastedSnippet{snippet}
+	m.queueMessage("Summarize " + snippet.placeholder)
+	if m.pastedSnippets != nil {
+		t.Fatal("expected the paste to move to the queued message")
+	}
+
+	m.handleUpdateBusy(pkgtypes.NewUpdateBusyEvent(false))
+	input := <-m.channels.Input
+	if !strings.Contains(input.Content, ".forge/pastes/a.txt") {
+		t.Errorf("expected the paste reference in the sent message, got %q", input.Content)
+	}
+}
+
+func TestQueuePanel_ViewportHeight(t *testing.T) {
+	m := newHeightTestModel(20)
+	for _, input := range []string{"one", "two", "three", "four"} {
+		m.queueMessage(input)
+	}
+
+	// Header, three messages and a "more" line
+	if lines := m.queuePanelLines(); len(lines) != 1+maxQueuePanelItems+1 {
+		t.Fatalf("queue panel = %q", lines)
+	}
+	// 20 - 4 - 1 - 2 - 1 - 5(panel) = 7
+	if got := m.calculateViewportHeight(); got != 7 {
+		t.Errorf("calculateViewportHeight() = %d, want 7", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/entrhq/forge/pkg/agent"
 	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/llm/openai"
 )
 
@@ -22,6 +23,12 @@ func (m *model) reloadLLMProvider() error {
 	baseURL := llmConfig.GetBaseURL()
 	apiKey := llmConfig.GetAPIKey()
 
+	// The running key may have come from a flag or environment variable
+	// rather than the config
+	if apiKey == "" && m.provider != nil {
+		apiKey = m.provider.GetAPIKey()
+	}
+
 	// Validate required settings
 	if model == "" {
 		return fmt.Errorf("model cannot be empty")
@@ -31,8 +38,11 @@ func (m *model) reloadLLMProvider() error {
 	}
 
 	// Create provider options
+	limiter := llm.SharedRateLimiter()
+	limiter.SetLimits(llm.RateLimitFromConfig())
 	providerOpts := []openai.ProviderOption{
 		openai.WithModel(model),
+		openai.WithRateLimiter(limiter),
 	}
 
 	if baseURL != "" {
@@ -45,8 +55,10 @@ func (m *model) reloadLLMProvider() error {
 		return fmt.Errorf("failed to create LLM provider: %w", err)
 	}
 
-	// Update the agent's provider (thread-safe hot-reload)
-	if err := m.agent.SetProvider(provider); err != nil {
+	// Update the agent's provider (thread-safe hot-reload), keeping the agent's pinned sampling
+	agentProvider := llm.WithSampling(llm.WithRequestLog(provider, llm.SharedRequestLog()), llm.SamplingFromConfig(config.SamplingRoleAgent))
+	agentProvider = llm.WithFallback(agentProvider, llm.FallbackModelFromConfig())
+	if err := m.agent.SetProvider(agentProvider); err != nil {
 		return fmt.Errorf("failed to update agent provider: %w", err)
 	}
 
@@ -68,7 +68,7 @@ func (c *ToolResultClassifier) ClassifyToolResult(toolName string, result string
 
 	// Check for specific tools that should always be summary-only when large
 	switch toolName {
-	case "read_file", "search_files", "list_files":
+	case "read_file", "search_files", "list_files", "find_files":
 		if lineCount >= 50 {
 			return TierSummaryOnly
 		}
@@ -139,6 +139,14 @@ func (s *ToolResultSummarizer) GenerateSummary(toolName string, result string) s
 		fileCount, dirCount := s.parseListResults(result)
 		return fmt.Sprintf("Listed %d files and %d directories [Ctrl+V to view]", fileCount, dirCount)
 
+	case "find_files":
+		return fmt.Sprintf("Found %d paths [Ctrl+V to view]", s.parseFindResults(result))
+
+	case "run_tests":
+		// The first line is already a one-line summary of the run
+		summary, _, _ := strings.Cut(result, "\n")
+		return fmt.Sprintf("%s [Ctrl+V to view]", summary)
+
 	case "write_file":
 		filename := s.extractFilename(result)
 		if filename != "" {
@@ -322,6 +330,17 @@ func (s *ToolResultSummarizer) parseListResults(result string) (fileCount, dirCo
 	return fileCount, dirCount
 }
 
+// parseFindResults counts the paths listed in find_files results
+func (s *ToolResultSummarizer) parseFindResults(result string) int {
+	count := 0
+	for line := range strings.SplitSeq(result, "\n") {
+		if strings.HasPrefix(line, "·") || strings.HasPrefix(line, "▸") {
+			count++
+		}
+	}
+	return count
+}
+
 // parseApplyDiffResults extracts edit count from apply_diff results
 func (s *ToolResultSummarizer) parseApplyDiffResults(result string) int {
 	// Look for patterns like "Applied 5 edits" in the result
@@ -332,6 +332,18 @@ func TestGenerateSummary(t *testing.T) {
 			result:   "📁 dir1\n📄 file1.go\n📄 file2.py\n📁 dir2",
 			contains: []string{"Listed", "files", "directories", "Ctrl+V"},
 		},
+		{
+			name:     "find_files",
+			toolName: "find_files",
+			result:   "· main.go (12 B, modified 2024-05-01 10:00)\n▸ pkg/ (modified 2024-05-01 10:00)\n\nFound 2 paths",
+			contains: []string{"Found 2 paths", "Ctrl+V"},
+		},
+		{
+			name:     "run_tests",
+			toolName: "run_tests",
+			result:   "go tests FAILED: 12 passed, 1 failed, 0 skipped in 2.1s\nCommand: go test -json ./... (exit code 1)\n\nFailures:\n\n1. TestAdd [example] at add_test.go:9",
+			contains: []string{"go tests FAILED: 12 passed, 1 failed", "Ctrl+V"},
+		},
 		{
 			name:     "write_file with filename",
 			toolName: "write_file",
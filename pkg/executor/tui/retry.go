@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// recallLastInput puts the last message sent back in the empty input box for
+// editing, so it can be resent in place of its turn. It reports whether the
+// message was recalled.
+func (m *model) recallLastInput() bool {
+	if m.lastInput == "" || m.agentBusy || m.bashMode || m.readOnly != "" || m.textarea.Value() != "" {
+		return false
+	}
+	m.textarea.SetValue(m.lastInput)
+	m.textarea.CursorEnd()
+	m.editingLast = true
+	m.updateTextAreaHeight()
+	return true
+}
+
+// cancelEditLast abandons editing the recalled message.
+func (m *model) cancelEditLast() {
+	m.editingLast = false
+	m.textarea.Reset()
+	m.updateTextAreaHeight()
+}
+
+// retryLastTurn replaces the last turn with input: the agent drops the last
+// message and everything it did in response from its context, then handles
+// input as a new message. The transcript keeps the earlier attempt, marked as
+// replaced.
+func (m *model) retryLastTurn(input string) {
+	m.appendMsg(newEntryMsg("↻ ", "Retrying the last turn; the previous attempt was removed from the agent's context", tipsStyle, "\n\n"))
+	m.appendMsg(newUserMsg(input))
+	m.textarea.Reset()
+	m.lastInput = input
+
+	m.agentBusy = true
+	m.currentLoadingMessage = getRandomLoadingMessage()
+	m.resumeFollowScroll()
+	m.recalculateLayout()
+
+	m.channels.Input <- types.NewRetryRequestInput(types.RetryRequestParams{Content: m.expandPastedSnippets(input)})
+}
+
+// handleRetryCommand resends the last message in place of its turn, e.g.
+// after the model gave up or went the wrong way.
+func handleRetryCommand(m *model, args []string) any {
+	if m.channels == nil {
+		m.showToast("Error", "Agent not available", "✗", true)
+		return nil
+	}
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish or /stop it before retrying", "⚠", true)
+		return nil
+	}
+	if m.lastInput == "" {
+		m.showToast("Nothing to retry", "Send a message first", "⚠", true)
+		return nil
+	}
+
+	m.retryLastTurn(m.lastInput)
+	return nil
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	pkgtypes "github.com/entrhq/forge/pkg/types"
+)
+
+func TestEditAndResendLastMessage(t *testing.T) {
+	m := newHeightTestModel(30)
+	m.channels = pkgtypes.NewAgentChannels(1)
+
+	// Up does nothing before a message has been sent
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyUp}, nil, nil, nil)
+	if m.editingLast {
+		t.Fatal("expected nothing to recall")
+	}
+
+	m.textarea.SetValue("Add tests for the parser")
+	m.handleEnter(nil, nil, nil)
+	<-m.channels.Input
+	m.agentBusy = false
+
+	// Up recalls the message, and Esc abandons the edit
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyUp}, nil, nil, nil)
+	if !m.editingLast || m.textarea.Value() != "Add tests for the parser" {
+		t.Fatalf("expected the last message to be recalled, got %q", m.textarea.Value())
+	}
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc}, nil, nil, nil)
+	if m.editingLast || m.textarea.Value() != "" {
+		t.Fatal("expected Esc to cancel the edit")
+	}
+
+	// Sending the edited message retries the turn with it
+	m.handleKeyPress(tea.KeyMsg{Type: tea.KeyUp}, nil, nil, nil)
+	m.textarea.SetValue("Add table-driven tests for the parser")
+	m.handleEnter(nil, nil, nil)
+
+	input := <-m.channels.Input
+	params, _ := input.Metadata["params"].(pkgtypes.RetryRequestParams)
+	if !input.IsRetryRequest() || params.Content != "Add table-driven tests for the parser" {
+		t.Errorf("expected a retry with the edited message, got %s %+v", input.Type, params)
+	}
+	if m.editingLast || !m.agentBusy {
+		t.Error("expected the edit to end and the agent to be busy")
+	}
+	if transcript := stripANSI(m.renderMessages(120)); !strings.Contains(transcript, "Retrying the last turn") {
+		t.Errorf("expected the retry in the transcript, got:\n%s", transcript)
+	}
+}
+
+func TestRetryCommand(t *testing.T) {
+	m := newHeightTestModel(30)
+	m.channels = pkgtypes.NewAgentChannels(1)
+
+	handleRetryCommand(m, nil)
+	if len(m.channels.Input) != 0 {
+		t.Fatal("expected no retry before a message has been sent")
+	}
+
+	m.lastInput = "Fix the flaky test"
+	m.agentBusy = true
+	handleRetryCommand(m, nil)
+	if len(m.channels.Input) != 0 {
+		t.Fatal("expected no retry while the agent is busy")
+	}
+
+	m.agentBusy = false
+	handleRetryCommand(m, nil)
+	input := <-m.channels.Input
+	if params, _ := input.Metadata["params"].(pkgtypes.RetryRequestParams); params.Content != "Fix the flaky test" {
+		t.Errorf("expected the last message to be resent, got %+v", params)
+	}
+}
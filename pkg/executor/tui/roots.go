@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/executor/tui/overlay"
+	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// handleRootsCommand lists the workspace and its additional roots with the
+// access tools have to each.
+func handleRootsCommand(m *model, args []string) any {
+	rootsOverlay := overlay.NewHelpOverlay("Workspace Roots", buildRootsContent(m.workspaceDir, m.roots), m.width, m.height)
+	m.overlay.activate(tuitypes.OverlayModeHelp, rootsOverlay)
+	return nil
+}
+
+// buildRootsContent renders the roots as an aligned table: the name tools use
+// to reference the root, its access, and its directory.
+func buildRootsContent(workspaceDir string, roots []workspace.Root) string {
+	nameStyle := lipgloss.NewStyle().Foreground(tuitypes.SalmonPink)
+	accessStyle := lipgloss.NewStyle().Foreground(tuitypes.BrightWhite)
+	descStyle := lipgloss.NewStyle().Foreground(tuitypes.MutedGray)
+
+	type row struct{ name, access, dir string }
+	rows := []row{{"(workspace)", "read-write", workspaceDir}}
+	for _, root := range roots {
+		access := "read-write"
+		if root.ReadOnly {
+			access = "read-only"
+		}
+		rows = append(rows, row{workspace.RootPrefix + root.Name, access, root.Path})
+	}
+
+	nameWidth := 0
+	for _, r := range rows {
+		nameWidth = max(nameWidth, len(r.name))
+	}
+
+	var b strings.Builder
+	for _, r := range rows {
+		b.WriteString("  ")
+		b.WriteString(nameStyle.Render(r.name))
+		b.WriteString(strings.Repeat(" ", nameWidth-len(r.name)+2))
+		b.WriteString(accessStyle.Render(r.access))
+		b.WriteString(strings.Repeat(" ", len("read-write")-len(r.access)+2))
+		b.WriteString(descStyle.Render(r.dir))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if len(roots) == 0 {
+		b.WriteString(descStyle.Render("Add roots with roots: in " + config.ProjectConfigPath + " or -root name=path."))
+	} else {
+		b.WriteString(descStyle.Render("Tools reference files in a root as @name/path."))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+func TestBuildRootsContent(t *testing.T) {
+	content := buildRootsContent("/src/app", []workspace.Root{
+		{Name: "proto", Path: "/src/proto"},
+		{Name: "design", Path: "/src/design", ReadOnly: true},
+	})
+
+	for _, want := range []string{"(workspace)", "/src/app", "@proto", "/src/proto", "@design", "read-only", "@name/path"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in roots content:\n%s", want, content)
+		}
+	}
+
+	if content := buildRootsContent("/src/app", nil); !strings.Contains(content, "-root name=path") {
+		t.Errorf("expected a hint for adding roots, got:\n%s", content)
+	}
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/entrhq/forge/pkg/bootstrap"
+	"github.com/entrhq/forge/pkg/config"
+)
+
+// handleSetupCommand runs the project's setup commands, installing missing
+// dependencies and warming the build cache, while new messages wait.
+func handleSetupCommand(m *model, _ []string) any {
+	if m.workspaceDir == "" {
+		m.showToast("Setup unavailable", "This session has no workspace", "✗", true)
+		return nil
+	}
+	if m.agentBusy {
+		m.showToast("Agent busy", "Wait for the current turn to finish before running project setup", "⚠", true)
+		return nil
+	}
+
+	settings := config.GetProjectConfig().GetBootstrap()
+	steps := bootstrap.Plan(m.workspaceDir, settings.Commands)
+	if len(steps) == 0 {
+		m.showToast("Nothing to set up", "No missing dependencies were found for go.mod, package.json or requirements.txt", "✓", false)
+		return nil
+	}
+
+	workspaceDir := m.workspaceDir
+	return tea.Sequence(
+		func() tea.Msg {
+			return operationStartMsg{message: "Running project setup: " + bootstrap.Describe(steps)}
+		},
+		func() tea.Msg {
+			results := bootstrap.RunAll(context.Background(), workspaceDir, steps, settings.Timeout, nil)
+			return operationCompleteMsg{
+				result:       setupSummary(results),
+				err:          setupError(results),
+				successTitle: "Project set up",
+				successIcon:  "✓",
+				errorTitle:   "Project setup failed",
+				errorIcon:    "✗",
+			}
+		},
+	)
+}
+
+// setupSummary describes the steps that ran
+func setupSummary(results []bootstrap.Result) string {
+	lines := make([]string, len(results))
+	for i, result := range results {
+		status := "done"
+		if result.Failed() {
+			status = "failed, continuing: " + result.Error
+		}
+		lines[i] = fmt.Sprintf("%s (%s, %s)", result.Step.Command, status, result.Duration)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// setupError reports the step that stopped the setup, with the end of its
+// output
+func setupError(results []bootstrap.Result) error {
+	failure, ok := bootstrap.FirstFailure(results)
+	if !ok {
+		return nil
+	}
+	output := failure.Output
+	if lines := strings.Split(output, "\n"); len(lines) > 5 {
+		output = strings.Join(lines[len(lines)-5:], "\n")
+	}
+	return fmt.Errorf("%s: %s\n%s", failure.Step.Command, failure.Error, output)
+}
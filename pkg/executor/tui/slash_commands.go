@@ -13,6 +13,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/entrhq/forge/pkg/agent"
 	"github.com/entrhq/forge/pkg/agent/git"
 	"github.com/entrhq/forge/pkg/config"
 	"github.com/entrhq/forge/pkg/executor/tui/approval"
@@ -111,6 +112,87 @@ func init() {
 		MaxArgs:     0,
 	})
 
+	registerCommand(&SlashCommand{
+		Name:        "compact",
+		Description: "Summarize context now: [light|normal|aggressive] [target_tokens]",
+		Type:        CommandTypeAgent,
+		Handler:     handleCompactCommand,
+		MinArgs:     0,
+		MaxArgs:     2,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "pin",
+		Description: "Keep context from being summarized: [file|text] (default: your last message)",
+		Type:        CommandTypeAgent,
+		Handler:     handlePinCommand,
+		MinArgs:     0,
+		MaxArgs:     -1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "unpin",
+		Description: "Let pinned context be summarized again",
+		Type:        CommandTypeAgent,
+		Handler:     handleUnpinCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "retry",
+		Description: "Resend your last message in place of the last turn",
+		Type:        CommandTypeAgent,
+		Handler:     handleRetryCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "usage",
+		Description: "Show token usage and cost by turn and tool",
+		Type:        CommandTypeTUI,
+		Handler:     handleUsageCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "model",
+		Description: "Switch the main or summarization model",
+		Type:        CommandTypeTUI,
+		Handler:     handleModelCommand,
+		MinArgs:     0,
+		MaxArgs:     1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "profile",
+		Description: "Switch working mode: [name|off] (lists profiles without a name)",
+		Type:        CommandTypeTUI,
+		Handler:     handleProfileCommand,
+		MinArgs:     0,
+		MaxArgs:     1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "browser",
+		Description: "Check or install Playwright for browser tools: [install]",
+		Type:        CommandTypeTUI,
+		Handler:     handleBrowserCommand,
+		MinArgs:     0,
+		MaxArgs:     1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "setup",
+		Description: "Install the project's dependencies and warm its build cache",
+		Type:        CommandTypeTUI,
+		Handler:     handleSetupCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
 	registerCommand(&SlashCommand{
 		Name:        "bash",
 		Description: "Enter bash mode for running shell commands",
@@ -120,12 +202,30 @@ func init() {
 		MaxArgs:     0,
 	})
 
+	registerCommand(&SlashCommand{
+		Name:        "roots",
+		Description: "Show the workspace roots tools can access",
+		Type:        CommandTypeTUI,
+		Handler:     handleRootsCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
 	registerCommand(&SlashCommand{
 		Name:        "notes",
-		Description: "View scratchpad notes",
+		Description: "View scratchpad notes: [decision|blocker|followup|tag]",
 		Type:        CommandTypeTUI,
 		Handler:     handleNotesCommand,
 		MinArgs:     0,
+		MaxArgs:     1,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "approvals",
+		Description: "Show pending tool approvals",
+		Type:        CommandTypeTUI,
+		Handler:     handleApprovalsCommand,
+		MinArgs:     0,
 		MaxArgs:     0,
 	})
 
@@ -138,6 +238,24 @@ func init() {
 		MaxArgs:     0,
 	})
 
+	registerCommand(&SlashCommand{
+		Name:        "llmlog",
+		Description: "Browse the LLM requests and responses logged with -llm-log",
+		Type:        CommandTypeTUI,
+		Handler:     handleLLMLogCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
+	registerCommand(&SlashCommand{
+		Name:        "restore",
+		Description: "Restore the session that ended unexpectedly",
+		Type:        CommandTypeTUI,
+		Handler:     handleRestoreCommand,
+		MinArgs:     0,
+		MaxArgs:     0,
+	})
+
 	registerCommand(&SlashCommand{
 		Name:        "thinking",
 		Description: "Toggle display of extended thinking blocks",
@@ -307,10 +425,16 @@ func buildHelpContent() string {
 	keys := []keyBinding{
 		{"Enter", "Send message"},
 		{"Alt+Enter", "New line"},
+		{"Up", "Edit and resend last message (empty input)"},
 		{"Ctrl+K / Ctrl+P", "Command palette"},
 		{"Ctrl+L", "Result history"},
-		{"Cmd+V / Shift+Ins", "Paste"},
+		{"Ctrl+O", "Expand / collapse command output"},
+		{"Ctrl+X", "Cancel running command"},
+		{"Cmd+V / Shift+Ins", "Paste (large pastes attach as files)"},
 		{"Ctrl+Y", "Copy to clipboard"},
+		{"Alt+N / Alt+P", "Next / previous code block in last response"},
+		{"Alt+Y", "Copy code block"},
+		{"Alt+S", "Save code block to a file"},
 		{"PgUp", "Scroll up (lock follow)"},
 		{"PgDn", "Scroll down"},
 		{"Esc", "Cancel / dismiss overlay"},
@@ -345,6 +469,8 @@ func handleStopCommand(m *model, args []string) any {
 		// Send cancel input to agent
 		m.channels.Input <- types.NewCancelInput()
 		m.showToast("Stopping", "Sent stop signal to agent", "■", false)
+		// Queued messages go back to the input instead of starting the next turn
+		m.returnQueuedMessages()
 	}
 	return nil
 }
@@ -561,10 +687,14 @@ func handleSettingsCommand(m *model, args []string) any {
 
 	settingsOverlay := overlay.NewSettingsOverlayWithCallback(m.width, m.height, onLLMSettingsChange, m.provider)
 
-	// Sync runtime showThinking after UI settings are saved in the overlay
+	// Sync runtime showThinking and code highlighting after UI settings are
+	// saved in the overlay. Highlighting does not change line counts, so the
+	// conversation is redrawn in place.
 	settingsOverlay.SetOnUISettingsChange(func() error {
 		if ui := config.GetUI(); ui != nil {
 			m.showThinking = ui.IsShowThinking()
+			m.mdRenderer.SetHighlighting(ui.IsSyntaxHighlighting())
+			m.viewport.SetContent(m.renderMessages(m.viewport.Width))
 		}
 		return nil
 	})
@@ -600,6 +730,10 @@ func handleContextCommand(m *model, args []string) any {
 		ToolCount:               contextInfo.ToolCount,
 		ToolTokens:              contextInfo.ToolTokens,
 		ToolNames:               contextInfo.ToolNames,
+		LoadedToolCount:         contextInfo.LoadedToolCount,
+		ToolManifestTokens:      contextInfo.ToolManifestTokens,
+		DeferredToolTokens:      contextInfo.DeferredToolTokens,
+		ToolGroups:              toolGroupInfo(contextInfo.ToolGroups),
 		MessageCount:            contextInfo.MessageCount,
 		ConversationTurns:       contextInfo.ConversationTurns,
 		ConversationTokens:      contextInfo.ConversationTokens,
@@ -625,6 +759,20 @@ func handleContextCommand(m *model, args []string) any {
 	return nil
 }
 
+// toolGroupInfo converts the agent's tool group statistics for the overlay
+func toolGroupInfo(groups []agent.ToolGroupInfo) []overlay.ToolGroupInfo {
+	infos := make([]overlay.ToolGroupInfo, len(groups))
+	for i, group := range groups {
+		infos[i] = overlay.ToolGroupInfo{
+			Name:      group.Name,
+			ToolCount: group.ToolCount,
+			Tokens:    group.Tokens,
+			Loaded:    group.Loaded,
+		}
+	}
+	return infos
+}
+
 // handleBashCommand enters bash mode for running shell commands
 func handleBashCommand(m *model, args []string) any {
 	m.bashMode = true
@@ -658,8 +806,12 @@ func handleThinkingCommand(m *model, args []string) any {
 
 // handleNotesCommand requests notes data from the agent and shows notes viewer
 func handleNotesCommand(m *model, args []string) any {
+	tag := ""
+	if len(args) > 0 {
+		tag = args[0]
+	}
 	// Send notes request to agent
-	return m.requestNotes()
+	return m.requestNotes(tag)
 }
 
 // contextSnapshotTokens holds aggregate token statistics for the exported snapshot.
@@ -45,6 +45,11 @@ var (
 	toolResultStyle = lipgloss.NewStyle().
 			Foreground(brightWhite)
 
+	// filePreviewChangedStyle marks the lines the agent changed in the file
+	// preview
+	filePreviewChangedStyle = lipgloss.NewStyle().
+				Foreground(mintGreen)
+
 	warningStyle = lipgloss.NewStyle().
 			Foreground(mutedGray)
 
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/entrhq/forge/pkg/agent/todo"
+	pkgtypes "github.com/entrhq/forge/pkg/types"
+)
+
+// maxTodoPanelItems caps the steps the plan panel shows, so a long plan does
+// not crowd out the conversation.
+const maxTodoPanelItems = 6
+
+// trackTodoList picks up the todo list snapshot the todo tools attach to
+// their results.
+func (m *model) trackTodoList(event *pkgtypes.AgentEvent) {
+	if items, ok := event.Metadata[todo.MetadataKey].([]todo.Item); ok {
+		m.todos = items
+	}
+}
+
+// todoPanelLines renders the plan panel shown between the conversation and
+// the input: a progress header and a window of steps around the first
+// unfinished one. It returns nil when there is no plan, or when the plan is
+// finished and the agent has gone idle.
+func (m *model) todoPanelLines() []string {
+	if len(m.todos) == 0 {
+		return nil
+	}
+	done, complete := todo.Progress(m.todos)
+	if complete && !m.agentBusy {
+		return nil
+	}
+
+	// Start one step before the first unfinished step, so the step just
+	// completed stays in view
+	start := 0
+	for i, item := range m.todos {
+		if !item.Status.Done() {
+			start = max(i-1, 0)
+			break
+		}
+	}
+	start = max(min(start, len(m.todos)-maxTodoPanelItems), 0)
+	end := min(start+maxTodoPanelItems, len(m.todos))
+
+	header := fmt.Sprintf("  Plan · %d/%d done", done, len(m.todos))
+	if hidden := len(m.todos) - (end - start); hidden > 0 {
+		header += fmt.Sprintf(" · %d more", hidden)
+	}
+	lines := []string{tipsStyle.Render(header)}
+
+	contentWidth := max(m.width-10, 10)
+	for _, item := range m.todos[start:end] {
+		style := lipgloss.NewStyle().Foreground(brightWhite)
+		switch item.Status {
+		case todo.StatusInProgress:
+			style = lipgloss.NewStyle().Foreground(salmonPink).Bold(true)
+		case todo.StatusCompleted:
+			style = lipgloss.NewStyle().Foreground(mintGreen)
+		case todo.StatusCancelled:
+			style = lipgloss.NewStyle().Foreground(mutedGray).Strikethrough(true)
+		}
+		content := item.Content
+		if runes := []rune(content); len(runes) > contentWidth {
+			content = string(runes[:contentWidth-1]) + "…"
+		}
+		lines = append(lines, "    "+style.Render(item.Status.Icon()+" "+content))
+	}
+	return lines
+}
+
+// buildTodoPanel renders the plan panel, or "" when it is hidden.
+func (m *model) buildTodoPanel() string {
+	return strings.Join(m.todoPanelLines(), "\n")
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/todo"
+	pkgtypes "github.com/entrhq/forge/pkg/types"
+)
+
+func todoItems(statuses ...todo.Status) []todo.Item {
+	items := make([]todo.Item, len(statuses))
+	for i, status := range statuses {
+		items[i] = todo.Item{Step: i + 1, Content: fmt.Sprintf("step %d", i+1), Status: status}
+	}
+	return items
+}
+
+func TestTrackTodoList(t *testing.T) {
+	m := newHeightTestModel(20)
+	event := pkgtypes.NewToolResultEvent("1", "create_todo_list", "ok")
+	event.Metadata[todo.MetadataKey] = todoItems(todo.StatusPending, todo.StatusPending)
+
+	m.trackTodoList(event)
+	if len(m.todos) != 2 {
+		t.Fatalf("todos = %+v", m.todos)
+	}
+
+	// Results of other tools leave the plan alone
+	m.trackTodoList(pkgtypes.NewToolResultEvent("2", "read_file", "content"))
+	if len(m.todos) != 2 {
+		t.Errorf("todos = %+v", m.todos)
+	}
+}
+
+func TestTodoPanelLines(t *testing.T) {
+	m := newHeightTestModel(30)
+	if lines := m.todoPanelLines(); lines != nil {
+		t.Errorf("expected no panel without a plan, got %q", lines)
+	}
+
+	// Long plans show a window starting just before the first unfinished step
+	m.todos = todoItems(todo.StatusCompleted, todo.StatusCompleted, todo.StatusCompleted, todo.StatusInProgress,
+		todo.StatusPending, todo.StatusPending, todo.StatusPending, todo.StatusPending, todo.StatusPending)
+	lines := m.todoPanelLines()
+	if len(lines) != 1+maxTodoPanelItems {
+		t.Fatalf("lines = %q", lines)
+	}
+	if !strings.Contains(lines[0], "3/9 done · 3 more") {
+		t.Errorf("header = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "✓ step 3") || !strings.Contains(lines[2], "▸ step 4") {
+		t.Errorf("window = %q", lines[1:])
+	}
+
+	// A finished plan stays up until the turn ends
+	m.todos = todoItems(todo.StatusCompleted, todo.StatusCancelled)
+	m.agentBusy = true
+	if lines := m.todoPanelLines(); len(lines) != 3 {
+		t.Errorf("lines = %q", lines)
+	}
+	m.agentBusy = false
+	if lines := m.todoPanelLines(); lines != nil {
+		t.Errorf("expected the finished plan to be hidden, got %q", lines)
+	}
+}
+
+func TestTodoPanel_ViewportHeight(t *testing.T) {
+	m := newHeightTestModel(20)
+	m.todos = todoItems(todo.StatusInProgress, todo.StatusPending)
+
+	// 20 - 4 - 1 - 2 - 1 - 3(panel) = 9
+	if got := m.calculateViewportHeight(); got != 9 {
+		t.Errorf("calculateViewportHeight() = %d, want 9", got)
+	}
+
+	m.viewport.SetContent(strings.Repeat("content line\n", 50))
+	m.recalculateLayout()
+	if lines := strings.Count(m.View(), "\n") + 1; lines > 20 {
+		t.Errorf("View() produced %d lines for a 20-line terminal", lines)
+	}
+}
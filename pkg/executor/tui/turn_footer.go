@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// turnStats accumulates LLM usage across the API calls of a single turn so a
+// footer can be rendered when the turn ends.
+type turnStats struct {
+	apiCalls         int
+	promptTokens     int
+	completionTokens int
+	latency          time.Duration // Time spent waiting on the LLM, excluding tool execution
+	callStart        time.Time
+}
+
+// recordCallStart marks the start of an LLM call.
+func (t *turnStats) recordCallStart(now time.Time) {
+	t.apiCalls++
+	t.callStart = now
+}
+
+// recordUsage adds a completed call's token counts and latency. Token usage is
+// emitted once the response stream finishes, so it also marks the call's end.
+func (t *turnStats) recordUsage(promptTokens, completionTokens int, now time.Time) {
+	t.promptTokens += promptTokens
+	t.completionTokens += completionTokens
+	if !t.callStart.IsZero() {
+		t.latency += now.Sub(t.callStart)
+		t.callStart = time.Time{}
+	}
+}
+
+// footer renders the turn summary, e.g.
+// "claude-sonnet-4.5 · 12.3K in / 850 out · $0.0496 · 8.2s".
+// The cost is omitted when the model's price is unknown.
+func (t *turnStats) footer(model string) string {
+	var parts []string
+	if model != "" {
+		parts = append(parts, model)
+	}
+	parts = append(parts, fmt.Sprintf("%s in / %s out", formatTokenCount(t.promptTokens), formatTokenCount(t.completionTokens)))
+	if price, ok := llm.PricingForModel(model); ok {
+		parts = append(parts, formatCost(price.Cost(t.promptTokens, t.completionTokens)))
+	}
+	parts = append(parts, fmt.Sprintf("%.1fs", t.latency.Seconds()))
+	if t.apiCalls > 1 {
+		parts = append(parts, fmt.Sprintf("%d calls", t.apiCalls))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatCost formats a USD amount with enough precision for sub-cent turns.
+func formatCost(usd float64) string {
+	if usd >= 1 {
+		return fmt.Sprintf("$%.2f", usd)
+	}
+	return fmt.Sprintf("$%.4f", usd)
+}
+
+// appendTurnFooter renders the footer for the turn that just ended, if it made
+// any LLM calls, and resets the stats for the next turn.
+func (m *model) appendTurnFooter() {
+	stats := m.turn
+	m.turn = turnStats{}
+	if stats.apiCalls == 0 {
+		return
+	}
+
+	var modelName string
+	if m.provider != nil {
+		modelName = m.provider.GetModel()
+	}
+	m.usage.recordTurn(stats, modelName)
+	m.appendMsg(newEntryMsg("  ", stats.footer(modelName), tipsStyle, "\n\n"))
+}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTurnStats_Footer(t *testing.T) {
+	start := time.Now()
+	var stats turnStats
+	stats.recordCallStart(start)
+	stats.recordUsage(10_000, 500, start.Add(2*time.Second))
+	stats.recordCallStart(start.Add(5 * time.Second))
+	stats.recordUsage(12_000, 300, start.Add(6500*time.Millisecond))
+
+	got := stats.footer("anthropic/claude-sonnet-4.5")
+	want := "anthropic/claude-sonnet-4.5 · 22.0K in / 800 out · $0.0780 · 3.5s · 2 calls"
+	if got != want {
+		t.Errorf("footer = %q, want %q", got, want)
+	}
+}
+
+func TestTurnStats_FooterUnknownModel(t *testing.T) {
+	stats := turnStats{apiCalls: 1, promptTokens: 120, completionTokens: 40, latency: 900 * time.Millisecond}
+
+	got := stats.footer("local-model")
+	want := "local-model · 120 in / 40 out · 0.9s"
+	if got != want {
+		t.Errorf("footer = %q, want %q", got, want)
+	}
+}
+
+func TestAppendTurnFooter_SkipsTurnsWithoutCalls(t *testing.T) {
+	m := &model{}
+	m.appendTurnFooter()
+	if len(m.messages) != 0 {
+		t.Errorf("expected no footer for a turn without LLM calls, got %d messages", len(m.messages))
+	}
+
+	m.turn = turnStats{apiCalls: 1, promptTokens: 100}
+	m.appendTurnFooter()
+	if len(m.messages) != 1 {
+		t.Fatalf("expected one footer message, got %d", len(m.messages))
+	}
+	if m.turn.apiCalls != 0 {
+		t.Error("expected turn stats to reset after the footer")
+	}
+}
@@ -6,8 +6,8 @@ type OverlayMode int
 const (
 	// OverlayModeNone indicates no overlay is active
 	OverlayModeNone OverlayMode = iota
-	// OverlayModeDiffViewer shows the diff approval overlay
-	OverlayModeDiffViewer
+	// OverlayModeApprovalQueue shows the queue of pending tool approvals
+	OverlayModeApprovalQueue
 	// OverlayModeFileTree shows the file tree overlay
 	OverlayModeFileTree
 	// OverlayModeCommandOutput shows command output overlay
@@ -24,4 +24,10 @@ const (
 	OverlayModeToolResult
 	// OverlayModeNotes shows the scratchpad notes overlay
 	OverlayModeNotes
+	// OverlayModeUsage shows the token usage and cost dashboard
+	OverlayModeUsage
+	// OverlayModeModel shows the model switcher
+	OverlayModeModel
+	// OverlayModeLLMLog shows the logged LLM requests
+	OverlayModeLLMLog
 )
@@ -41,6 +41,7 @@ type ActionHandler interface {
 type Overlay interface {
 	// Update handles messages and returns updated overlay.
 	// Note: In the new architecture, this receives the StateProvider instead of *model
+	// Mouse messages arrive with X and Y relative to the top-left corner of View.
 	Update(msg tea.Msg, state StateProvider, actions ActionHandler) (Overlay, tea.Cmd)
 
 	// View renders the overlay
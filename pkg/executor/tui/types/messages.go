@@ -59,6 +59,7 @@ type ToastMsg struct {
 // ViewResultMsg is sent when a result is selected from the list
 type ViewResultMsg struct {
 	ResultID string
+	Line     int // Zero-based line to scroll to, such as a search hit
 }
 
 // ViewNoteMsg is sent when a note is selected from the notes list
@@ -71,6 +72,7 @@ type NoteData struct {
 	ID        string
 	Content   string
 	Tags      []string
+	Category  string
 	CreatedAt string
 	UpdatedAt string
 	Scratched bool
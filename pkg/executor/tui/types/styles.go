@@ -1,6 +1,10 @@
 package types
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"math"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 // Color Palette
 // This is the single source of truth for all TUI colors.
@@ -149,6 +153,17 @@ func ComputeViewportHeight(terminalHeight, chromeRows int) int {
 	return h
 }
 
+// CenterOffset returns where lipgloss.Place with lipgloss.Center puts a block
+// of size inner within outer, along either axis, so mouse positions can be
+// mapped back into the block.
+func CenterOffset(outer, inner int) int {
+	gap := outer - inner
+	if gap <= 0 {
+		return 0
+	}
+	return gap - int(math.Round(float64(gap)*0.5))
+}
+
 // Shared text styles for overlay content
 var (
 	// OverlayTitleStyle is used for main overlay titles
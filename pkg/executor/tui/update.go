@@ -40,14 +40,28 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Forward ALL messages to active overlay first (including custom messages like cursorBlinkMsg).
 	// This ensures overlays can handle their own custom message types.
 	if m.overlay.isActive() && m.overlay.overlay != nil {
-		updatedOverlay, overlayCmd := m.overlay.overlay.Update(msg, m, m)
+		overlayMsg := msg
+		if mouseMsg, ok := msg.(tea.MouseMsg); ok {
+			overlayMsg = overlayMouseMsg(mouseMsg, m.overlay.overlay, m.width, m.height)
+		}
+		updatedOverlay, overlayCmd := m.overlay.overlay.Update(overlayMsg, m, m)
 
 		// If overlay returns nil, it wants to close.
 		if updatedOverlay == nil {
+			closedMode := m.overlay.mode
 			m.ClearOverlay()
 			if overlayCmd != nil {
 				spinnerCmd = tea.Batch(spinnerCmd, overlayCmd)
 			}
+			// Approval keys such as y/n must not leak into the input once the
+			// queue closes.
+			if _, ok := msg.(tea.KeyMsg); ok && closedMode == tuitypes.OverlayModeApprovalQueue {
+				return m, spinnerCmd
+			}
+			// Nor may the click that closed an overlay land on what was beneath it
+			if _, ok := msg.(tea.MouseMsg); ok {
+				return m, spinnerCmd
+			}
 			// Continue processing the message in the main model.
 		} else {
 			m.overlay.overlay = updatedOverlay
@@ -73,6 +87,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				shouldFallThrough = true
 			case toastMsg, tuitypes.ToastMsg:
 				shouldFallThrough = true
+			case checkpointTickMsg:
+				// Keep auto-saving while an overlay is open
+				shouldFallThrough = true
 			}
 
 			if !shouldFallThrough {
@@ -122,6 +139,29 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
 	}
 
+	// Alt+N/P/Y/S select, copy and save the last response's code blocks
+	// before the textarea takes them as word movements.
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && !m.overlay.isActive() && !m.resultList.IsActive() &&
+		!m.commandPalette.IsActive() && m.handleCodeBlockKey(keyMsg) {
+		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
+	}
+
+	// Ctrl+F toggles the file preview and Alt+Up/Down scroll it, before the
+	// textarea takes them as cursor movements.
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && !m.overlay.isActive() && !m.resultList.IsActive() &&
+		!m.commandPalette.IsActive() && m.handleFilePreviewKey(keyMsg) {
+		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
+	}
+
+	// Large bracketed pastes become snippet file attachments instead of
+	// flooding the input, the viewport and the prompt.
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.Paste && !m.bashMode &&
+		!m.overlay.isActive() && !m.resultList.IsActive() {
+		if m.attachPaste(string(keyMsg.Runes)) {
+			return m, spinnerCmd
+		}
+	}
+
 	// Only update textarea if no overlay or result list is active.
 	// This prevents the textarea from capturing scroll events when an overlay is open.
 	if !m.overlay.isActive() && !m.resultList.IsActive() {
@@ -134,8 +174,13 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.updateTextAreaHeight()
 		}
 
-		// Handle command palette activation/deactivation based on input.
+		// Clearing the recalled message abandons the edit
 		value := m.textarea.Value()
+		if m.editingLast && value == "" {
+			m.editingLast = false
+		}
+
+		// Handle command palette activation/deactivation based on input.
 		switch {
 		case value == "/" && !m.commandPalette.IsActive():
 			m.commandPalette.Activate()
@@ -185,6 +230,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case toastMsg:
 		return m.handleToast(msg)
 
+	case checkpointTickMsg:
+		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd, m.handleCheckpointTick())
+
 	case tuitypes.ToastMsg:
 		return m.handleToast(toastMsg{
 			message: msg.Message,
@@ -213,6 +261,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.MouseMsg:
 		// Note: Mouse event forwarding to overlay is handled in the early forwarding section above.
 		if !m.overlay.isActive() {
+			if m.handleConversationMouse(msg) {
+				return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
+			}
 			// ADR-0048: track scroll direction before updating viewport.
 			if msg.Button == tea.MouseButtonWheelUp {
 				m.followScroll = false
@@ -235,6 +286,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// "unknown message type" logging from bubbles/viewport.
 		switch msg.(type) {
 		case spinner.TickMsg:
+			if len(m.runningCommands) > 0 {
+				m.refreshCommandBlocks()
+			}
 			if m.agentBusy {
 				return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
 			}
@@ -281,9 +335,12 @@ func (m *model) calculateViewportHeight() int {
 		scrollIndicatorHeight = 1
 	}
 
+	todoPanelHeight := len(m.todoPanelLines())
+	queuePanelHeight := len(m.queuePanelLines())
+
 	// Visual spacer line between header and viewport (assembleBaseView line 231 adds "")
 	const spacerHeight = 1
-	viewportHeight := max(m.height-headerHeight-spacerHeight-inputZoneHeight-statusBarHeight-loadingHeight-scrollIndicatorHeight, 1)
+	viewportHeight := max(m.height-headerHeight-spacerHeight-inputZoneHeight-statusBarHeight-loadingHeight-scrollIndicatorHeight-todoPanelHeight-queuePanelHeight, 1)
 	return viewportHeight
 }
 
@@ -306,7 +363,7 @@ func (m *model) handleWindowResize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 	m.textarea.MaxHeight = maxInputLines
 
 	m.textarea.SetWidth(m.width - textareaHorizontalPadding - promptWidth)
-	m.viewport.Width = m.width - viewportHorizontalPadding
+	m.viewport.Width = m.conversationWidth() - viewportHorizontalPadding
 	m.ready = true
 
 	// Do NOT call GotoBottom() here - let recalculateLayout handle scroll positioning
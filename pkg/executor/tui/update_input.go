@@ -16,6 +16,22 @@ func (m *model) handleEnter(tiCmd, vpCmd, spinnerCmd tea.Cmd) (tea.Model, tea.Cm
 		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
 	}
 
+	if m.savingCodeBlock {
+		m.saveCodeBlock(input)
+		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
+	}
+
+	// Read-only sessions such as replays only take slash commands
+	if m.readOnly != "" && !strings.HasPrefix(input, "/") {
+		m.textarea.Reset()
+		m.showToast("Read-only session", m.readOnly, "!", false)
+		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
+	}
+
+	// Sending the recalled message replaces the last turn
+	replaceLast := m.editingLast
+	m.editingLast = false
+
 	switch {
 	case m.bashMode:
 		return m.handleBashModeInput(input, tiCmd, vpCmd, spinnerCmd)
@@ -23,6 +39,9 @@ func (m *model) handleEnter(tiCmd, vpCmd, spinnerCmd tea.Cmd) (tea.Model, tea.Cm
 		return m.handleSlashCommand(input, tiCmd, vpCmd, spinnerCmd)
 	case strings.HasPrefix(input, "!"):
 		return m.handleSingleShotBash(input, tiCmd, vpCmd, spinnerCmd)
+	case replaceLast:
+		m.retryLastTurn(input)
+		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
 	default:
 		return m.handleAgentMessage(input, tiCmd, vpCmd, spinnerCmd)
 	}
@@ -73,23 +92,30 @@ func (m *model) handleSingleShotBash(input string, tiCmd, vpCmd, spinnerCmd tea.
 	return m, tea.Batch(tiCmd, vpCmd, spinnerCmd, m.executeBashCommand(bashCmd))
 }
 
-// handleAgentMessage sends a regular user message to the agent.
+// handleAgentMessage sends a regular user message to the agent, or queues it
+// until the current turn ends while the agent is busy.
 func (m *model) handleAgentMessage(input string, tiCmd, vpCmd, spinnerCmd tea.Cmd) (tea.Model, tea.Cmd) {
-	// Style icon and text separately for user messages
-	styledIcon := userIconStyle.Render("❯ ")
-	styledText := userTextStyle.Render(input)
-	m.appendMsg(DisplayMessage{
-		RenderFn: func(width int) string {
-			wrapWidth := width - 4
-			if wrapWidth <= 0 {
-				wrapWidth = 80
-			}
-			wrapped := wordWrap(styledText, wrapWidth)
-			return styledIcon + wrapped
-		},
-		Trailing: "\n\n",
-	})
+	// Messages already waiting go first
+	if m.agentBusy || len(m.queuedMessages) > 0 {
+		m.queueMessage(input)
+		m.sendQueuedMessage()
+		return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
+	}
+
 	m.textarea.Reset()
+	m.sendAgentMessage(input, m.expandPastedSnippets(input))
+	return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
+}
+
+// sendAgentMessage shows input in the conversation and sends content, input
+// with its pastes expanded, to the agent as a new turn.
+func (m *model) sendAgentMessage(input, content string) {
+	m.appendMsg(newUserMsg(input))
+	m.lastInput = input
+
+	// Sending a message starts a fresh session; the unrestored checkpoint is
+	// overwritten by the next auto-save
+	m.pendingRestore = nil
 
 	m.agentBusy = true
 	m.currentLoadingMessage = getRandomLoadingMessage()
@@ -98,8 +124,5 @@ func (m *model) handleAgentMessage(input string, tiCmd, vpCmd, spinnerCmd tea.Cm
 	m.resumeFollowScroll()
 	m.recalculateLayout()
 
-	userInput := types.NewUserInput(input)
-	m.channels.Input <- userInput
-
-	return m, tea.Batch(tiCmd, vpCmd, spinnerCmd)
+	m.channels.Input <- types.NewUserInput(content)
 }
@@ -25,6 +25,14 @@ func (m *model) handleKeyPress(msg tea.KeyMsg, vpCmd, tiCmd, spinnerCmd tea.Cmd)
 
 	switch msg.Type {
 	case tea.KeyEsc:
+		if m.savingCodeBlock {
+			m.cancelSaveCodeBlock()
+			return m, nil
+		}
+		if m.editingLast {
+			m.cancelEditLast()
+			return m, nil
+		}
 		if m.bashMode {
 			m.bashMode = false
 			m.textarea.Reset()
@@ -51,6 +59,19 @@ func (m *model) handleKeyPress(msg tea.KeyMsg, vpCmd, tiCmd, spinnerCmd tea.Cmd)
 	case tea.KeyCtrlY:
 		return m.handleCopyToClipboard()
 
+	case tea.KeyUp:
+		if m.recallQueuedMessage() || m.recallLastInput() {
+			return m, nil
+		}
+
+	case tea.KeyCtrlO:
+		m.handleToggleCommandOutput()
+		return m, nil
+
+	case tea.KeyCtrlX:
+		m.handleCancelCommand()
+		return m, nil
+
 	case tea.KeyEnter:
 		if msg.Alt {
 			m.textarea.InsertString("\n")
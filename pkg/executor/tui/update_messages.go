@@ -14,6 +14,7 @@ func (m *model) handleViewResult(msg tuitypes.ViewResultMsg) {
 	if result, ok := m.resultCache.get(msg.ResultID); ok {
 		m.resultList.Deactivate()
 		ol := overlay.NewToolResultOverlay(result.ToolName, result.Result, m.width, m.height)
+		ol.ScrollToLine(msg.Line)
 		m.overlay.activate(tuitypes.OverlayModeToolResult, ol)
 		return
 	}
@@ -37,6 +38,7 @@ func (m *model) handleViewNote(msg tuitypes.ViewNoteMsg) {
 func (m *model) handleSlashCommandComplete() (tea.Model, tea.Cmd) {
 	m.agentBusy = false
 	m.recalculateLayout()
+	m.sendQueuedMessage()
 	return m, nil
 }
 
@@ -64,6 +66,7 @@ func (m *model) handleOperationComplete(msg operationCompleteMsg) (tea.Model, te
 		m.recalculateLayout()
 	}
 
+	m.sendQueuedMessage()
 	return m, nil
 }
 
@@ -89,6 +92,7 @@ func (m *model) handleAgentError(msg agentErrMsg) (tea.Model, tea.Cmd) {
 	m.appendMsg(newRawMsg(rendered, "\n\n"))
 	m.agentBusy = false
 	m.recalculateLayout()
+	m.sendQueuedMessage()
 	return m, nil
 }
 
@@ -0,0 +1,207 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/entrhq/forge/pkg/executor/tui/overlay"
+	tuitypes "github.com/entrhq/forge/pkg/executor/tui/types"
+	"github.com/entrhq/forge/pkg/llm"
+)
+
+// usageLedger records where a session's tokens go: per turn, per tool, how
+// much summarization saved and how the context grew with each LLM call. It is
+// saved to <workspace>/.forge/usage/ after every turn so sessions can be
+// audited later, and carried in the checkpoint so /restore keeps it.
+type usageLedger struct {
+	StartedAt      time.Time             `json:"started_at"`
+	Turns          []turnUsage           `json:"turns"`
+	Tools          map[string]*toolUsage `json:"tools"`
+	ContextGrowth  []int                 `json:"context_growth"` // Prompt tokens of each LLM call, in order
+	Summarizations int                   `json:"summarizations"`
+	TokensSaved    int                   `json:"tokens_saved"` // Context tokens removed by summarization
+
+	// Output tokens of the last LLM call, charged to the tool it calls
+	pendingCompletion int
+}
+
+// turnUsage is the usage of a single completed turn.
+type turnUsage struct {
+	Model            string   `json:"model,omitempty"`
+	APICalls         int      `json:"api_calls"`
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	CostUSD          *float64 `json:"cost_usd,omitempty"` // Unset when the model's price is unknown
+}
+
+// toolUsage is the usage attributed to one tool across the session.
+type toolUsage struct {
+	Calls            int `json:"calls"`
+	CompletionTokens int `json:"completion_tokens"` // Output tokens spent writing the calls
+	ResultTokens     int `json:"result_tokens"`     // Approximate tokens the results added to the context
+}
+
+// recordCall adds a finished LLM call.
+func (u *usageLedger) recordCall(promptTokens, completionTokens int, now time.Time) {
+	if u.StartedAt.IsZero() {
+		u.StartedAt = now
+	}
+	u.ContextGrowth = append(u.ContextGrowth, promptTokens)
+	u.pendingCompletion = completionTokens
+}
+
+// recordToolCall charges the last call's output tokens to toolName.
+func (u *usageLedger) recordToolCall(toolName string) {
+	tool := u.tool(toolName)
+	tool.Calls++
+	tool.CompletionTokens += u.pendingCompletion
+	u.pendingCompletion = 0
+}
+
+// recordToolResult adds the approximate size of a tool result. Like the
+// context snapshot, it estimates four characters per token.
+func (u *usageLedger) recordToolResult(toolName, result string) {
+	u.tool(toolName).ResultTokens += len(result) / 4
+}
+
+// recordSummarization adds a summarization that shrank the context.
+func (u *usageLedger) recordSummarization(oldTokens, newTokens int) {
+	u.Summarizations++
+	if oldTokens > newTokens {
+		u.TokensSaved += oldTokens - newTokens
+	}
+}
+
+// recordTurn adds a completed turn's stats.
+func (u *usageLedger) recordTurn(stats turnStats, model string) {
+	turn := turnUsage{
+		Model:            model,
+		APICalls:         stats.apiCalls,
+		PromptTokens:     stats.promptTokens,
+		CompletionTokens: stats.completionTokens,
+	}
+	if price, ok := llm.PricingForModel(model); ok {
+		cost := price.Cost(stats.promptTokens, stats.completionTokens)
+		turn.CostUSD = &cost
+	}
+	u.Turns = append(u.Turns, turn)
+	u.pendingCompletion = 0
+}
+
+func (u *usageLedger) tool(name string) *toolUsage {
+	if u.Tools == nil {
+		u.Tools = make(map[string]*toolUsage)
+	}
+	tool, ok := u.Tools[name]
+	if !ok {
+		tool = &toolUsage{}
+		u.Tools[name] = tool
+	}
+	return tool
+}
+
+// overlayInfo converts the ledger for display in the usage overlay.
+func (u *usageLedger) overlayInfo() *overlay.UsageInfo {
+	info := &overlay.UsageInfo{
+		ContextGrowth:  u.ContextGrowth,
+		Summarizations: u.Summarizations,
+		TokensSaved:    u.TokensSaved,
+	}
+
+	for i, turn := range u.Turns {
+		row := overlay.TurnUsage{
+			Index:            i + 1,
+			Model:            turn.Model,
+			APICalls:         turn.APICalls,
+			PromptTokens:     turn.PromptTokens,
+			CompletionTokens: turn.CompletionTokens,
+		}
+		if turn.CostUSD != nil {
+			row.Cost = *turn.CostUSD
+			row.CostKnown = true
+			info.TotalCost += *turn.CostUSD
+		} else {
+			info.UnpricedTurns++
+		}
+		info.TotalPromptTokens += turn.PromptTokens
+		info.TotalCompletionTokens += turn.CompletionTokens
+		info.Turns = append(info.Turns, row)
+	}
+
+	for name, tool := range u.Tools {
+		info.Tools = append(info.Tools, overlay.ToolUsage{
+			Name:             name,
+			Calls:            tool.Calls,
+			CompletionTokens: tool.CompletionTokens,
+			ResultTokens:     tool.ResultTokens,
+		})
+	}
+	// Heaviest tools first
+	sort.Slice(info.Tools, func(i, j int) bool {
+		a, b := info.Tools[i], info.Tools[j]
+		if a.CompletionTokens+a.ResultTokens != b.CompletionTokens+b.ResultTokens {
+			return a.CompletionTokens+a.ResultTokens > b.CompletionTokens+b.ResultTokens
+		}
+		return a.Name < b.Name
+	})
+
+	return info
+}
+
+func usagePath(workspaceDir string, startedAt time.Time) string {
+	return filepath.Join(workspaceDir, ".forge", "usage", startedAt.Format("20060102-150405")+".json")
+}
+
+// writeUsage atomically replaces the session's usage file in workspaceDir.
+func writeUsage(workspaceDir string, u *usageLedger) error {
+	path := usagePath(workspaceDir, u.StartedAt)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create usage directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal usage: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write usage: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write usage: %w", err)
+	}
+	return nil
+}
+
+// saveUsage writes the usage file after a turn. Like checkpoint failures,
+// write failures are reported once per run.
+func (m *model) saveUsage() {
+	if m.workspaceDir == "" || len(m.usage.Turns) == 0 {
+		return
+	}
+	if err := writeUsage(m.workspaceDir, &m.usage); err != nil {
+		if !m.usageSaveFailed {
+			m.showToast("Usage not saved", err.Error(), "!", true)
+		}
+		m.usageSaveFailed = true
+		return
+	}
+	m.usageSaveFailed = false
+}
+
+// handleUsageCommand shows the token usage and cost dashboard
+func handleUsageCommand(m *model, args []string) any {
+	if len(m.usage.Turns) == 0 {
+		m.showToast("No usage yet", "Usage is recorded once a turn completes", "◆", false)
+		return nil
+	}
+
+	usageOverlay := overlay.NewUsageOverlay(m.usage.overlayInfo(), m.width, m.height)
+	m.overlay.activate(tuitypes.OverlayModeUsage, usageOverlay)
+	return nil
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestUsageLedger_AttributesTokens(t *testing.T) {
+	start := time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC)
+	var u usageLedger
+
+	u.recordCall(10_000, 400, start)
+	u.recordToolCall("read_file")
+	u.recordToolResult("read_file", string(make([]byte, 2000)))
+	u.recordCall(12_000, 900, start.Add(time.Second))
+	u.recordToolCall("apply_diff")
+	u.recordCall(13_000, 100, start.Add(2*time.Second))
+	u.recordToolCall("task_completion")
+	u.recordTurn(turnStats{apiCalls: 3, promptTokens: 35_000, completionTokens: 1_400}, "anthropic/claude-sonnet-4.5")
+	u.recordSummarization(13_000, 4_000)
+	u.recordCall(5_000, 200, start.Add(time.Minute))
+	u.recordTurn(turnStats{apiCalls: 1, promptTokens: 5_000, completionTokens: 200}, "local-model")
+
+	if !u.StartedAt.Equal(start) {
+		t.Errorf("StartedAt = %v, want %v", u.StartedAt, start)
+	}
+	if got := u.Tools["read_file"]; got.Calls != 1 || got.CompletionTokens != 400 || got.ResultTokens != 500 {
+		t.Errorf("read_file usage = %+v", got)
+	}
+	if got := u.Tools["apply_diff"]; got.CompletionTokens != 900 {
+		t.Errorf("apply_diff usage = %+v", got)
+	}
+	if u.Summarizations != 1 || u.TokensSaved != 9_000 {
+		t.Errorf("summarization = %d runs, %d saved", u.Summarizations, u.TokensSaved)
+	}
+
+	info := u.overlayInfo()
+	if info.TotalPromptTokens != 40_000 || info.TotalCompletionTokens != 1_600 {
+		t.Errorf("totals = %d in / %d out", info.TotalPromptTokens, info.TotalCompletionTokens)
+	}
+	if info.UnpricedTurns != 1 || !info.Turns[0].CostKnown || info.Turns[1].CostKnown {
+		t.Errorf("expected only the first turn to be priced, got %+v", info.Turns)
+	}
+	if info.TotalCost != info.Turns[0].Cost {
+		t.Errorf("TotalCost = %v, want %v", info.TotalCost, info.Turns[0].Cost)
+	}
+	if info.Tools[0].Name != "apply_diff" {
+		t.Errorf("expected the heaviest tool first, got %s", info.Tools[0].Name)
+	}
+	if len(info.ContextGrowth) != 4 {
+		t.Errorf("expected 4 context growth points, got %d", len(info.ContextGrowth))
+	}
+}
+
+func TestWriteUsage(t *testing.T) {
+	dir := t.TempDir()
+	u := usageLedger{StartedAt: time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC)}
+	u.recordCall(100, 10, u.StartedAt)
+	u.recordTurn(turnStats{apiCalls: 1, promptTokens: 100, completionTokens: 10}, "gpt-4o")
+
+	if err := writeUsage(dir, &u); err != nil {
+		t.Fatalf("writeUsage: %v", err)
+	}
+
+	data, err := os.ReadFile(usagePath(dir, u.StartedAt))
+	if err != nil {
+		t.Fatalf("read usage file: %v", err)
+	}
+	var loaded usageLedger
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("parse usage file: %v", err)
+	}
+	if len(loaded.Turns) != 1 || loaded.Turns[0].CostUSD == nil || loaded.Turns[0].Model != "gpt-4o" {
+		t.Errorf("unexpected saved usage %+v", loaded)
+	}
+}
@@ -0,0 +1,181 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// userCommand is a slash command defined in a YAML file, such as
+// ~/.forge/commands/review.yaml. It either sends Prompt to the agent or runs
+// Command in the shell; both are Go templates over the command's arguments.
+type userCommand struct {
+	Name        string `yaml:"name"`        // Command name without "/"; defaults to the file name
+	Description string `yaml:"description"` // Shown in the palette and /help
+	Prompt      string `yaml:"prompt"`      // Message template sent to the agent
+	Command     string `yaml:"command"`     // Shell command template run like !command
+
+	template *template.Template
+}
+
+// userCommandName matches the names user commands may take
+var userCommandName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// userCommandShellSafe matches arguments that need no quoting in a command
+var userCommandShellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// LoadUserCommands registers the slash commands defined by the *.yaml files
+// in dir. Built-in commands cannot be replaced. A missing dir is not an
+// error; invalid files are skipped and reported together in the returned
+// error.
+func LoadUserCommands(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	var errs []error
+	for _, path := range paths {
+		def, err := loadUserCommand(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(path), err))
+			continue
+		}
+		if _, exists := getCommand(def.Name); exists {
+			errs = append(errs, fmt.Errorf("%s: /%s is already defined", filepath.Base(path), def.Name))
+			continue
+		}
+		registerCommand(def.slashCommand())
+	}
+	return errors.Join(errs...)
+}
+
+// loadUserCommand reads the user command defined in the file at path.
+func loadUserCommand(path string) (*userCommand, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var def userCommand
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if def.Name == "" {
+		def.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	def.Name = strings.TrimPrefix(def.Name, "/")
+	if !userCommandName.MatchString(def.Name) {
+		return nil, fmt.Errorf("invalid name %q (use lowercase letters, digits, - and _)", def.Name)
+	}
+	if (def.Prompt == "") == (def.Command == "") {
+		return nil, fmt.Errorf("set exactly one of prompt or command")
+	}
+
+	source := def.Prompt
+	if def.Command != "" {
+		source = def.Command
+	}
+	def.template, err = template.New(def.Name).Funcs(template.FuncMap{
+		"arg": func(int) string { return "" },
+	}).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	if def.Description == "" {
+		def.Description = "Custom command from " + filepath.Base(path)
+	}
+	return &def, nil
+}
+
+// slashCommand returns the registry entry for the command.
+func (c *userCommand) slashCommand() *SlashCommand {
+	return &SlashCommand{
+		Name:        c.Name,
+		Description: c.Description,
+		Type:        CommandTypeAgent,
+		Handler:     c.handle,
+		MinArgs:     0,
+		MaxArgs:     -1,
+	}
+}
+
+// expand executes the command's template with args, shell-quoting them for
+// shell commands.
+func (c *userCommand) expand(args []string) (string, error) {
+	quote := func(s string) string { return s }
+	if c.Command != "" {
+		quote = shellQuoteArg
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quote(arg)
+	}
+
+	var b strings.Builder
+	err := template.Must(c.template.Clone()).Funcs(template.FuncMap{
+		// arg returns the nth argument, counting from 1, or "" when missing
+		"arg": func(n int) string {
+			if n < 1 || n > len(quoted) {
+				return ""
+			}
+			return quoted[n-1]
+		},
+	}).Execute(&b, struct{ Args string }{Args: strings.Join(quoted, " ")})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// handle sends the expanded prompt to the agent, queueing it while the
+// agent is busy, or runs the expanded shell command.
+func (c *userCommand) handle(m *model, args []string) any {
+	if m.readOnly != "" {
+		m.showToast("Read-only session", m.readOnly, "!", false)
+		return nil
+	}
+
+	expanded, err := c.expand(args)
+	if err != nil {
+		m.showToast("Command Error", fmt.Sprintf("/%s: %v", c.Name, err), "✗", true)
+		return nil
+	}
+	if expanded == "" {
+		m.showToast("Command Error", fmt.Sprintf("/%s expanded to nothing", c.Name), "✗", true)
+		return nil
+	}
+
+	if c.Command != "" {
+		m.appendMsg(newRawMsg(bashPromptStyle.Render(fmt.Sprintf("$ %s", expanded)), "\n"))
+		m.recalculateLayout()
+		return m.executeBashCommand(expanded)
+	}
+
+	// Messages already waiting go first
+	if m.agentBusy || len(m.queuedMessages) > 0 {
+		m.queuedMessages = append(m.queuedMessages, queuedMessage{input: expanded})
+		m.recalculateLayout()
+		m.sendQueuedMessage()
+		return nil
+	}
+	m.sendAgentMessage(expanded, expanded)
+	return nil
+}
+
+// shellQuoteArg quotes s for use as a single sh argument.
+func shellQuoteArg(s string) string {
+	if userCommandShellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
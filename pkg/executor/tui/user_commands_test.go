@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pkgtypes "github.com/entrhq/forge/pkg/types"
+)
+
+// writeUserCommands writes command files to a temporary directory, loads
+// them, and unregisters them when the test ends.
+func writeUserCommands(t *testing.T, files map[string]string) error {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	before := make(map[string]bool, len(commandRegistry))
+	for name := range commandRegistry {
+		before[name] = true
+	}
+	t.Cleanup(func() {
+		for name := range commandRegistry {
+			if !before[name] {
+				delete(commandRegistry, name)
+			}
+		}
+	})
+	return LoadUserCommands(dir)
+}
+
+func TestLoadUserCommands(t *testing.T) {
+	err := writeUserCommands(t, map[string]string{
+		"review.yaml": "description: Review a branch\nprompt: Review the changes on {{arg 1}} against main.\n",
+		"ticket.yaml": "name: ticket-note\ncommand: echo {{.Args}}\n",
+		"help.yaml":   "prompt: Not allowed\n",
+		"broken.yaml": "prompt: hi\ncommand: echo hi\n",
+		"README.md":   "not a command",
+	})
+	if err == nil || !strings.Contains(err.Error(), "help.yaml: /help is already defined") ||
+		!strings.Contains(err.Error(), "broken.yaml: set exactly one of prompt or command") {
+		t.Errorf("expected errors for help.yaml and broken.yaml, got %v", err)
+	}
+
+	review, ok := getCommand("review")
+	if !ok || review.Description != "Review a branch" {
+		t.Fatalf("expected /review to be registered, got %+v", review)
+	}
+	if ticket, ok := getCommand("ticket-note"); !ok || !strings.Contains(ticket.Description, "ticket.yaml") {
+		t.Errorf("expected /ticket-note with a default description, got %+v", ticket)
+	}
+	if help, _ := getCommand("help"); help.Handler == nil || help.Description != "Show tips and keyboard shortcuts" {
+		t.Error("expected the built-in /help to be kept")
+	}
+	if _, ok := getCommand("broken"); ok {
+		t.Error("expected the invalid command to be skipped")
+	}
+}
+
+func TestLoadUserCommands_MissingDir(t *testing.T) {
+	if err := LoadUserCommands(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("expected no error for a missing directory, got %v", err)
+	}
+}
+
+func TestUserCommand_Prompt(t *testing.T) {
+	if err := writeUserCommands(t, map[string]string{
+		"review.yaml": "prompt: |\n  Review {{arg 1}} ({{.Args}}).\n  Focus on {{arg 2}} {{arg 3}}{{arg 4}}.\n",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newHeightTestModel(30)
+	m.channels = pkgtypes.NewAgentChannels(4)
+	m.textarea.SetValue("/review feature/login error handling")
+	m.handleEnter(nil, nil, nil)
+
+	input := <-m.channels.Input
+	want := "Review feature/login (feature/login error handling).\nFocus on error handling."
+	if input.Content != want {
+		t.Errorf("prompt = %q, want %q", input.Content, want)
+	}
+
+	// While the agent is busy the prompt waits its turn
+	m.textarea.SetValue("/review main")
+	m.handleEnter(nil, nil, nil)
+	if len(m.queuedMessages) != 1 || !strings.HasPrefix(m.queuedMessages[0].input, "Review main") {
+		t.Errorf("expected the prompt to be queued, got %+v", m.queuedMessages)
+	}
+}
+
+func TestUserCommand_ShellQuotesArguments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changelog.yaml")
+	if err := os.WriteFile(path, []byte("command: git log --oneline {{arg 1}} -- {{.Args}}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	def, err := loadUserCommand(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := def.expand([]string{"v1.0", "it's; rm -rf /"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `git log --oneline v1.0 -- v1.0 'it'\''s; rm -rf /'`
+	if got != want {
+		t.Errorf("command = %q, want %q", got, want)
+	}
+}
+
+func TestUserCommand_ReadOnly(t *testing.T) {
+	if err := writeUserCommands(t, map[string]string{
+		"review.yaml": "prompt: Review it\n",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newHeightTestModel(30)
+	m.channels = pkgtypes.NewAgentChannels(4)
+	m.readOnly = "This is a replay."
+	m.textarea.SetValue("/review")
+	m.handleEnter(nil, nil, nil)
+
+	if len(m.channels.Input) != 0 {
+		t.Error("expected nothing to be sent in a read-only session")
+	}
+}
@@ -28,6 +28,11 @@ func (m *model) View() string {
 
 	// Build viewport section
 	viewportSection := m.viewport.View()
+	if m.filePreviewActive() {
+		viewportSection = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(m.conversationWidth()).Render(viewportSection),
+			m.renderFilePreview(m.filePreviewWidth(), m.viewport.Height))
+	}
 
 	// ADR-0048: scroll-lock indicator (shown only when user has scrolled up and new content arrived)
 	scrollIndicator := m.buildScrollLockIndicator()
@@ -82,7 +87,10 @@ func (m *model) buildTips() string {
 		return tipsStyle.Render("  Esc · close   Tab · next field   Enter · confirm")
 
 	case m.agentBusy:
-		hints := "  Ctrl+C · interrupt"
+		hints := "  Ctrl+C · interrupt   Enter · queue message"
+		if len(m.queuedMessages) > 0 {
+			hints += "   ↑ · edit queued"
+		}
 		if !m.followScroll {
 			hints += "   G · follow output"
 		}
@@ -144,6 +152,12 @@ func (m *model) buildBottomBar() string {
 	var left string
 	if m.bashMode {
 		left = lipgloss.NewStyle().Foreground(mintGreen).Bold(true).Render("bash mode")
+	} else if m.savingCodeBlock {
+		left = lipgloss.NewStyle().Foreground(salmonPink).Bold(true).Render(fmt.Sprintf("save code block %d/%d", m.selectedCodeBlock, len(m.codeBlocks))) +
+			lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" · enter save · esc cancel")
+	} else if m.editingLast {
+		left = lipgloss.NewStyle().Foreground(salmonPink).Bold(true).Render("editing last message") +
+			lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(" · enter resend · esc cancel")
 	}
 
 	// Thinking state indicator — always visible so the user always knows the mode
@@ -222,9 +236,15 @@ func (m *model) assembleBaseView(header, tips, viewportSection, scrollIndicator,
 	if scrollIndicator != "" {
 		middle = append(middle, scrollIndicator)
 	}
+	if todoPanel := m.buildTodoPanel(); todoPanel != "" {
+		middle = append(middle, todoPanel)
+	}
 	if m.agentBusy {
 		middle = append(middle, loadingIndicator)
 	}
+	if queuePanel := m.buildQueuePanel(); queuePanel != "" {
+		middle = append(middle, queuePanel)
+	}
 
 	// Visual spacer between header and content
 	rows := []string{header, tips, ""}
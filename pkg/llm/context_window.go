@@ -0,0 +1,27 @@
+package llm
+
+import "github.com/entrhq/forge/pkg/config"
+
+// builtinContextWindows holds the context window size in tokens of common
+// models, keyed by model name prefix. Other models set context_tokens in
+// llm.models instead.
+var builtinContextWindows = map[string]int{
+	"claude-sonnet-4":  200_000,
+	"claude-haiku-4":   200_000,
+	"claude-3-5-haiku": 200_000,
+	"gpt-5":            400_000,
+	"gpt-4.1":          1_047_576,
+	"gpt-4o":           128_000,
+}
+
+// ContextWindowForModel returns the context window size of model in tokens,
+// preferring context_tokens from the global llm.models config over the
+// built-in table. It reports false when the size is unknown.
+func ContextWindowForModel(model string) (int, bool) {
+	if llmCfg := config.GetLLM(); llmCfg != nil {
+		if option, ok := llmCfg.GetModelOption(model); ok && option.ContextTokens > 0 {
+			return option.ContextTokens, true
+		}
+	}
+	return lookupModel(builtinContextWindows, model)
+}
@@ -0,0 +1,25 @@
+package llm
+
+import "testing"
+
+func TestContextWindowForModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  int
+		found bool
+	}{
+		{"anthropic/claude-sonnet-4.5", 200_000, true},
+		{"gpt-4.1-mini", 1_047_576, true},
+		{"gpt-4o-mini", 128_000, true},
+		{"some-local-model", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, ok := ContextWindowForModel(tt.model)
+			if ok != tt.found || got != tt.want {
+				t.Errorf("ContextWindowForModel(%q) = %d, %v; want %d, %v", tt.model, got, ok, tt.want, tt.found)
+			}
+		})
+	}
+}
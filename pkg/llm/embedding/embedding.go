@@ -67,6 +67,9 @@ func NewProvider(apiKey, model string, opts ...ProviderOption) (*Provider, error
 
 func (p *Provider) Model() string { return p.model }
 
+// BaseURL returns the endpoint embeddings are requested from.
+func (p *Provider) BaseURL() string { return p.baseURL }
+
 // Embed sends a batch embedding request and returns one vector per input.
 func (p *Provider) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
 	if len(inputs) == 0 {
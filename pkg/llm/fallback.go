@@ -0,0 +1,246 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// DefaultFallbackCooldown is how long requests stay on the fallback model
+// after the primary model was rate limited, when the response did not say how
+// long to wait.
+const DefaultFallbackCooldown = time.Minute
+
+// statusOverloaded is the status some OpenAI-compatible APIs, Anthropic's
+// among them, answer with when the model is overloaded
+const statusOverloaded = 529
+
+// IsRateLimited reports whether err is a rate limit or overload response from
+// the API: status 429, 503 or 529. Provider errors expose their status with
+// an HTTPStatus() int method.
+func IsRateLimited(err error) bool {
+	var statusErr interface{ HTTPStatus() int }
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	switch statusErr.HTTPStatus() {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, statusOverloaded:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay returns how long the API asked callers to wait, when err says
+func retryDelay(err error) time.Duration {
+	var delayErr interface{ RetryDelay() time.Duration }
+	if errors.As(err, &delayErr) {
+		return delayErr.RetryDelay()
+	}
+	return 0
+}
+
+// ModelFallback describes requests moving from the primary model to the
+// fallback model after the primary was rate limited.
+type ModelFallback struct {
+	From   string    // Primary model
+	To     string    // Fallback model
+	Reason string    // The rate limit error
+	Until  time.Time // When the primary model is tried again
+}
+
+// fallbackHandlerKey is the context key for the fallback handler
+type fallbackHandlerKey struct{}
+
+// ContextWithFallbackHandler returns a copy of ctx carrying fn, which a
+// FallbackProvider calls when a request made with the context moves to the
+// fallback model.
+func ContextWithFallbackHandler(ctx context.Context, fn func(ModelFallback)) context.Context {
+	return context.WithValue(ctx, fallbackHandlerKey{}, fn)
+}
+
+// fallbackState is the cooldown shared by a FallbackProvider and its sampling
+// clones, which send to the same models
+type fallbackState struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// FallbackProvider sends requests to a primary provider and, when the primary
+// model is rate limited or overloaded, retries them on a cheaper fallback
+// model instead of failing. Requests then stay on the fallback model until the
+// API's Retry-After, or DefaultFallbackCooldown, has passed. The provider
+// reports the primary model's name and info throughout.
+type FallbackProvider struct {
+	primary  Provider
+	fallback Provider
+	state    *fallbackState
+	now      func() time.Time
+}
+
+// NewFallbackProvider creates a provider that falls back from primary to
+// fallback on rate limits.
+func NewFallbackProvider(primary, fallback Provider) *FallbackProvider {
+	return &FallbackProvider{
+		primary:  primary,
+		fallback: fallback,
+		state:    &fallbackState{},
+		now:      time.Now,
+	}
+}
+
+// WithFallback returns provider falling back to model on rate limits.
+// Provider is returned unchanged when model is empty or provider's own model,
+// or when provider does not implement ModelCloner.
+func WithFallback(provider Provider, model string) Provider {
+	if model == "" || model == provider.GetModel() {
+		return provider
+	}
+	cloner, ok := provider.(ModelCloner)
+	if !ok {
+		return provider
+	}
+	return NewFallbackProvider(provider, cloner.CloneWithModel(model))
+}
+
+// FallbackModelFromConfig returns the fallback model pinned by the workspace's
+// .forge/config.yaml, or else the one in the global LLM settings. It returns
+// "" when none is configured.
+func FallbackModelFromConfig() string {
+	if project := config.GetProjectConfig(); project != nil && project.LLM.FallbackModel != "" {
+		return project.LLM.FallbackModel
+	}
+	if llmCfg := config.GetLLM(); llmCfg != nil {
+		return llmCfg.GetFallbackModel()
+	}
+	return ""
+}
+
+// FallbackModel returns the model requests fall back to.
+func (p *FallbackProvider) FallbackModel() string {
+	return p.fallback.GetModel()
+}
+
+// callWithFallback makes call against the primary provider unless it is
+// cooling down, and against the fallback provider when the primary is rate
+// limited.
+func callWithFallback[T any](ctx context.Context, p *FallbackProvider, call func(Provider) (T, error)) (T, error) {
+	if !p.coolingDown() {
+		result, err := call(p.primary)
+		if err == nil || ctx.Err() != nil || !IsRateLimited(err) {
+			return result, err
+		}
+		p.trip(ctx, err)
+	}
+	return call(p.fallback)
+}
+
+// coolingDown reports whether requests should skip the primary model
+func (p *FallbackProvider) coolingDown() bool {
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+	return p.now().Before(p.state.until)
+}
+
+// trip starts a cooldown after the primary model returned the rate limit
+// error err, and tells the context's fallback handler.
+func (p *FallbackProvider) trip(ctx context.Context, err error) {
+	delay := retryDelay(err)
+	if delay <= 0 {
+		delay = DefaultFallbackCooldown
+	}
+	until := p.now().Add(delay)
+
+	p.state.mu.Lock()
+	if until.After(p.state.until) {
+		p.state.until = until
+	}
+	p.state.mu.Unlock()
+
+	if fn, ok := ctx.Value(fallbackHandlerKey{}).(func(ModelFallback)); ok && fn != nil {
+		fn(ModelFallback{
+			From:   p.primary.GetModel(),
+			To:     p.fallback.GetModel(),
+			Reason: err.Error(),
+			Until:  until,
+		})
+	}
+}
+
+// StreamCompletion implements Provider.
+func (p *FallbackProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *StreamChunk, error) {
+	return callWithFallback(ctx, p, func(provider Provider) (<-chan *StreamChunk, error) {
+		return provider.StreamCompletion(ctx, messages)
+	})
+}
+
+// StreamCompletionWithTools implements ToolCallingProvider when the wrapped
+// providers do.
+func (p *FallbackProvider) StreamCompletionWithTools(ctx context.Context, messages []*types.Message, tools []ToolDefinition) (<-chan *StreamChunk, error) {
+	return callWithFallback(ctx, p, func(provider Provider) (<-chan *StreamChunk, error) {
+		toolProvider, ok := provider.(ToolCallingProvider)
+		if !ok {
+			return nil, errors.New("provider does not support native tool calling")
+		}
+		return toolProvider.StreamCompletionWithTools(ctx, messages, tools)
+	})
+}
+
+// Complete implements Provider.
+func (p *FallbackProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	return callWithFallback(ctx, p, func(provider Provider) (*types.Message, error) {
+		return provider.Complete(ctx, messages)
+	})
+}
+
+// AnalyzeDocument implements Provider.
+func (p *FallbackProvider) AnalyzeDocument(ctx context.Context, fileData []byte, mediaType string, prompt string) (string, error) {
+	return callWithFallback(ctx, p, func(provider Provider) (string, error) {
+		return provider.AnalyzeDocument(ctx, fileData, mediaType, prompt)
+	})
+}
+
+// GetModelInfo returns the primary model's info.
+func (p *FallbackProvider) GetModelInfo() *types.ModelInfo {
+	return p.primary.GetModelInfo()
+}
+
+// GetModel returns the primary model's name.
+func (p *FallbackProvider) GetModel() string {
+	return p.primary.GetModel()
+}
+
+// GetBaseURL returns the primary provider's base URL.
+func (p *FallbackProvider) GetBaseURL() string {
+	return p.primary.GetBaseURL()
+}
+
+// GetAPIKey returns the primary provider's API key.
+func (p *FallbackProvider) GetAPIKey() string {
+	return p.primary.GetAPIKey()
+}
+
+// CloneWithModel returns the primary provider switched to model, still
+// falling back to the same fallback model. It implements ModelCloner.
+func (p *FallbackProvider) CloneWithModel(model string) Provider {
+	cloner, ok := p.primary.(ModelCloner)
+	if !ok {
+		return p
+	}
+	return WithFallback(cloner.CloneWithModel(model), p.fallback.GetModel())
+}
+
+// CloneWithSampling returns a copy of p whose primary and fallback providers
+// both send params. The copy shares p's cooldown. It implements
+// SamplingCloner.
+func (p *FallbackProvider) CloneWithSampling(params config.SamplingParams) Provider {
+	clone := *p
+	clone.primary = WithSampling(p.primary, params)
+	clone.fallback = WithSampling(p.fallback, params)
+	return &clone
+}
@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// statusErr is a provider error carrying an HTTP status
+type statusErr struct {
+	status int
+	delay  time.Duration
+}
+
+func (e *statusErr) Error() string             { return fmt.Sprintf("status %d", e.status) }
+func (e *statusErr) HTTPStatus() int           { return e.status }
+func (e *statusErr) RetryDelay() time.Duration { return e.delay }
+
+// scriptedProvider answers with its model name, or with err when set
+type scriptedProvider struct {
+	model string
+	err   error
+	calls int
+}
+
+func (p *scriptedProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *StreamChunk, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	ch := make(chan *StreamChunk, 1)
+	ch <- &StreamChunk{Content: p.model, Finished: true}
+	close(ch)
+	return ch, nil
+}
+
+func (p *scriptedProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return types.NewAssistantMessage(p.model), nil
+}
+
+func (p *scriptedProvider) AnalyzeDocument(ctx context.Context, fileData []byte, mediaType string, prompt string) (string, error) {
+	return p.model, p.err
+}
+
+func (p *scriptedProvider) GetModelInfo() *types.ModelInfo { return &types.ModelInfo{Name: p.model} }
+func (p *scriptedProvider) GetModel() string               { return p.model }
+func (p *scriptedProvider) GetBaseURL() string             { return "" }
+func (p *scriptedProvider) GetAPIKey() string              { return "" }
+
+func TestIsRateLimited(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&statusErr{status: 429}, true},
+		{&statusErr{status: 503}, true},
+		{&statusErr{status: 529}, true},
+		{fmt.Errorf("failed to start completion: %w", &statusErr{status: 429}), true},
+		{&statusErr{status: 400}, false},
+		{&statusErr{status: 500}, false},
+		{errors.New("connection refused"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsRateLimited(tt.err); got != tt.want {
+			t.Errorf("IsRateLimited(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFallbackProvider_CoolsDownOnPrimary(t *testing.T) {
+	primary := &scriptedProvider{model: "big", err: &statusErr{status: 429, delay: 30 * time.Second}}
+	fallback := &scriptedProvider{model: "small"}
+	provider := NewFallbackProvider(primary, fallback)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider.now = func() time.Time { return now }
+
+	var fallbacks []ModelFallback
+	ctx := ContextWithFallbackHandler(context.Background(), func(f ModelFallback) {
+		fallbacks = append(fallbacks, f)
+	})
+
+	message, err := provider.Complete(ctx, nil)
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if message.Content != "small" {
+		t.Errorf("expected the fallback model's answer, got %q", message.Content)
+	}
+	if len(fallbacks) != 1 || !fallbacks[0].Until.Equal(now.Add(30*time.Second)) {
+		t.Fatalf("expected one fallback until the Retry-After, got %+v", fallbacks)
+	}
+
+	// During the cooldown the primary model is not tried
+	if _, err := provider.Complete(ctx, nil); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if primary.calls != 1 || fallback.calls != 2 {
+		t.Errorf("expected 1 primary and 2 fallback calls, got %d and %d", primary.calls, fallback.calls)
+	}
+
+	// Once it is over the primary model is tried again
+	now = now.Add(31 * time.Second)
+	primary.err = nil
+	message, err = provider.Complete(ctx, nil)
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if message.Content != "big" || len(fallbacks) != 1 {
+		t.Errorf("expected the primary model's answer with no new fallback, got %q and %+v", message.Content, fallbacks)
+	}
+	if provider.GetModel() != "big" {
+		t.Errorf("expected the primary model's name, got %q", provider.GetModel())
+	}
+}
+
+func TestFallbackProvider_ReturnsOtherErrors(t *testing.T) {
+	primary := &scriptedProvider{model: "big", err: &statusErr{status: 400}}
+	fallback := &scriptedProvider{model: "small"}
+
+	_, err := NewFallbackProvider(primary, fallback).StreamCompletion(context.Background(), nil)
+	if err == nil {
+		t.Fatal("expected the primary model's error")
+	}
+	if fallback.calls != 0 {
+		t.Errorf("expected no fallback call, got %d", fallback.calls)
+	}
+}
+
+func TestWithFallback_WithoutModelCloner(t *testing.T) {
+	primary := &scriptedProvider{model: "big"}
+	if got := WithFallback(primary, "small"); got != Provider(primary) {
+		t.Error("expected a provider that cannot clone models to be returned unchanged")
+	}
+}
@@ -0,0 +1,34 @@
+package llm
+
+import "github.com/entrhq/forge/pkg/config"
+
+// builtinImageModels lists, by model name prefix, common models that accept
+// image input. Other models set images in llm.models instead.
+var builtinImageModels = map[string]bool{
+	"claude-3":        true,
+	"claude-sonnet-4": true,
+	"claude-opus-4":   true,
+	"claude-haiku-4":  true,
+	"gpt-5":           true,
+	"gpt-4.1":         true,
+	"gpt-4o":          true,
+	"gemini":          true,
+}
+
+// ModelAcceptsImages reports whether model accepts images in its messages,
+// from images in the global llm.models config or the built-in table.
+func ModelAcceptsImages(model string) bool {
+	if llmCfg := config.GetLLM(); llmCfg != nil {
+		if option, ok := llmCfg.GetModelOption(model); ok && option.Images {
+			return true
+		}
+	}
+	accepts, _ := lookupModel(builtinImageModels, model)
+	return accepts
+}
+
+// AcceptsImages reports whether provider's model accepts images in its
+// messages.
+func AcceptsImages(provider Provider) bool {
+	return provider != nil && ModelAcceptsImages(provider.GetModel())
+}
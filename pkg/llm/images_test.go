@@ -0,0 +1,25 @@
+package llm
+
+import "testing"
+
+func TestModelAcceptsImages(t *testing.T) {
+	tests := []struct {
+		model string
+		want  bool
+	}{
+		{"anthropic/claude-sonnet-4.5", true},
+		{"claude-3-5-haiku-latest", true},
+		{"gpt-4o-mini", true},
+		{"gpt-5", true},
+		{"gpt-3.5-turbo", false},
+		{"some-local-model", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := ModelAcceptsImages(tt.model); got != tt.want {
+				t.Errorf("ModelAcceptsImages(%q) = %v; want %v", tt.model, got, tt.want)
+			}
+		})
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"os"
 
 	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm"
 )
 
 // BuildProvider creates an LLM provider based on configuration precedence:
-// CLI flags > Environment variables > Config file > Defaults
+// CLI flags > Environment variables > Project config (model only) > Global config file > Defaults
 func BuildProvider(cliModel, cliBaseURL, cliAPIKey, defaultModel string) (*Provider, error) {
 	// Start with CLI values (empty strings if not provided)
 	finalModel := cliModel
@@ -23,13 +24,22 @@ func BuildProvider(cliModel, cliBaseURL, cliAPIKey, defaultModel string) (*Provi
 		finalBaseURL = os.Getenv("OPENAI_BASE_URL")
 	}
 
+	// A model pinned by the workspace's .forge/config.yaml beats the global config
+	modelPinned := cliModel != "" && cliModel != defaultModel
+	if !modelPinned {
+		if project := config.GetProjectConfig(); project != nil && project.LLM.Model != "" {
+			finalModel = project.LLM.Model
+			modelPinned = true
+		}
+	}
+
 	// Get config file settings
 	llmConfigFromFile := config.GetLLM()
 
 	// Fall back to config file if still empty
 	if llmConfigFromFile != nil {
-		// Model: Use config file only if CLI didn't set a non-default value
-		if cliModel == "" || cliModel == defaultModel {
+		// Model: Use config file only if neither CLI nor project pinned one
+		if !modelPinned {
 			if configFileModel := llmConfigFromFile.GetModel(); configFileModel != "" {
 				finalModel = configFileModel
 			}
@@ -55,12 +65,17 @@ func BuildProvider(cliModel, cliBaseURL, cliAPIKey, defaultModel string) (*Provi
 
 	// Validate that API key was resolved
 	if finalAPIKey == "" {
-		return nil, fmt.Errorf("API key is required. Set OPENAI_API_KEY environment variable, use -api-key flag, or configure in ~/.config/forge/config.yaml")
+		return nil, fmt.Errorf("API key is required. Set OPENAI_API_KEY environment variable, use -api-key flag, or run forge auth login llm")
 	}
 
+	// Every provider built from configuration shares one rate limit budget
+	limiter := llm.SharedRateLimiter()
+	limiter.SetLimits(llm.RateLimitFromConfig())
+
 	// Create OpenAI provider with the final, resolved configuration
 	providerOpts := []ProviderOption{
 		WithModel(finalModel),
+		WithRateLimiter(limiter),
 	}
 	if finalBaseURL != "" {
 		providerOpts = append(providerOpts, WithBaseURL(finalBaseURL))
@@ -49,8 +49,11 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/entrhq/forge/pkg/config"
 	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/llm/parser"
 	"github.com/entrhq/forge/pkg/types"
@@ -69,6 +72,8 @@ type Provider struct {
 	baseURL    string
 	model      string
 	modelInfo  *types.ModelInfo
+	sampling   config.SamplingParams
+	limiter    *llm.RateLimiter // Shared with clones; nil sends requests unlimited
 }
 
 // ProviderOption is a function that configures a Provider.
@@ -89,6 +94,14 @@ func WithBaseURL(baseURL string) ProviderOption {
 	}
 }
 
+// WithRateLimiter holds requests back until they fit within limiter's
+// limits. Clones of the provider share the limiter.
+func WithRateLimiter(limiter *llm.RateLimiter) ProviderOption {
+	return func(p *Provider) {
+		p.limiter = limiter
+	}
+}
+
 // NewProvider creates a new OpenAI provider with the given API key.
 //
 // If apiKey is empty, it will attempt to read from the OPENAI_API_KEY environment variable.
@@ -149,6 +162,7 @@ func NewProvider(apiKey string, opts ...ProviderOption) (*Provider, error) {
 	p.modelInfo.Provider = "openai"
 	p.modelInfo.Name = p.model
 	p.modelInfo.SupportsStreaming = true
+	p.modelInfo.SupportsToolCalling = true
 	p.modelInfo.MaxTokens = 8192 // Default, varies by model
 
 	// Store base URL in metadata if not default
@@ -173,6 +187,32 @@ func (p *Provider) CloneWithModel(model string) llm.Provider {
 	return &clone
 }
 
+// CloneWithSampling returns a shallow copy of p that sends the given sampling
+// parameters with every request. It implements llm.SamplingCloner.
+func (p *Provider) CloneWithSampling(params config.SamplingParams) llm.Provider {
+	clone := *p
+	clone.sampling = params
+	return &clone
+}
+
+// GetSampling returns the sampling parameters sent with each request.
+func (p *Provider) GetSampling() config.SamplingParams {
+	return p.sampling
+}
+
+// applySampling adds any configured sampling parameters to a request body.
+func (p *Provider) applySampling(reqBody map[string]any) {
+	if p.sampling.Temperature != nil {
+		reqBody["temperature"] = *p.sampling.Temperature
+	}
+	if p.sampling.TopP != nil {
+		reqBody["top_p"] = *p.sampling.TopP
+	}
+	if p.sampling.Seed != nil {
+		reqBody["seed"] = *p.sampling.Seed
+	}
+}
+
 // StreamCompletion sends messages to the OpenAI API and streams back response chunks.
 //
 // The returned channel emits StreamChunk instances as the response is generated.
@@ -182,66 +222,88 @@ func (p *Provider) CloneWithModel(model string) llm.Provider {
 // which provides better compatibility with OpenAI-compatible APIs that may
 // include SSE comments or have slight format variations.
 func (p *Provider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *llm.StreamChunk, error) {
-	resp, err := p.sendStreamRequest(ctx, messages)
+	resp, reservation, err := p.sendStreamRequest(ctx, messages, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	chunks := make(chan *llm.StreamChunk, 10)
-	go p.processStreamResponse(ctx, resp, chunks)
+	go p.processStreamResponse(ctx, resp, reservation, chunks)
 	return chunks, nil
 }
 
-// sendStreamRequest creates and sends the HTTP request for streaming
-func (p *Provider) sendStreamRequest(ctx context.Context, messages []*types.Message) (*http.Response, error) {
+// sendStreamRequest creates and sends the HTTP request for streaming, offering
+// tools through function calling when any are given. The returned rate limit
+// reservation is held until the stream has been read.
+func (p *Provider) sendStreamRequest(ctx context.Context, messages []*types.Message, tools []llm.ToolDefinition) (*http.Response, *llm.RateLimitReservation, error) {
 	openaiMessages := convertToOpenAIMessages(messages)
 
 	reqBody := map[string]any{
 		"model":    p.model,
 		"messages": openaiMessages,
 		"stream":   true,
+		// Report billed token usage in a final chunk, so the agent can
+		// reconcile its own estimates with it
+		"stream_options": map[string]any{"include_usage": true},
 	}
+	if len(tools) > 0 {
+		reqBody["tools"] = convertToOpenAITools(tools)
+	}
+	p.applySampling(reqBody)
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := p.baseURL + "/chat/completions"
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+p.apiKey)
 	req.Header.Set("Accept", "text/event-stream")
 
+	reservation, err := p.limiter.Acquire(ctx, llm.EstimateTokens(len(bodyBytes)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("waiting for the client-side rate limit: %w", err)
+	}
+
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		reservation.Done(0)
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		body, readErr := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if readErr != nil {
-			return nil, fmt.Errorf("API request failed with status %d (failed to read error body: %w)", resp.StatusCode, readErr)
-		}
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		defer resp.Body.Close()
+		reservation.Done(0)
+		return nil, nil, newStatusError(resp)
 	}
 
-	return resp, nil
+	return resp, reservation, nil
 }
 
-// processStreamResponse processes the SSE stream and sends chunks to the channel
-func (p *Provider) processStreamResponse(ctx context.Context, resp *http.Response, chunks chan<- *llm.StreamChunk) {
+// processStreamResponse processes the SSE stream and sends chunks to the
+// channel, then settles reservation with the reported token usage
+func (p *Provider) processStreamResponse(ctx context.Context, resp *http.Response, reservation *llm.RateLimitReservation, chunks chan<- *llm.StreamChunk) {
 	defer close(chunks)
 	defer resp.Body.Close()
 
 	scanner := bufio.NewScanner(resp.Body)
 	firstChunk := true
 	thinkingParser := parser.NewThinkingParser()
+	toolCalls := &toolCallAccumulator{}
+	var usage *llm.UsageInfo
+	defer func() {
+		if usage != nil {
+			reservation.Done(usage.TotalTokens)
+		} else {
+			reservation.Done(0)
+		}
+	}()
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -253,20 +315,23 @@ func (p *Provider) processStreamResponse(ctx context.Context, resp *http.Respons
 		data := strings.TrimPrefix(line, "data: ")
 
 		if data == "[DONE]" {
-			p.handleStreamEnd(ctx, thinkingParser, chunks)
+			p.handleStreamEnd(ctx, thinkingParser, toolCalls, usage, chunks)
 			return
 		}
 
-		if !p.processSSEChunk(ctx, data, &firstChunk, thinkingParser, chunks) {
+		if !p.processSSEChunk(ctx, data, &firstChunk, thinkingParser, toolCalls, &usage, chunks) {
 			return
 		}
 	}
 
-	p.flushRemainingContent(ctx, thinkingParser, chunks)
-
 	if err := scanner.Err(); err != nil {
+		p.flushRemainingContent(ctx, thinkingParser, chunks)
 		chunks <- &llm.StreamChunk{Error: fmt.Errorf("stream read error: %w", err)}
+		return
 	}
+
+	// The stream ended without a [DONE] marker
+	p.handleStreamEnd(ctx, thinkingParser, toolCalls, usage, chunks)
 }
 
 // isValidSSELine checks if a line is a valid SSE data line
@@ -274,10 +339,12 @@ func (p *Provider) isValidSSELine(line string) bool {
 	return line != "" && !strings.HasPrefix(line, ":") && strings.HasPrefix(line, "data: ")
 }
 
-// handleStreamEnd handles the [DONE] marker and flushes remaining content
-func (p *Provider) handleStreamEnd(ctx context.Context, thinkingParser *parser.ThinkingParser, chunks chan<- *llm.StreamChunk) {
+// handleStreamEnd handles the [DONE] marker and flushes remaining content,
+// including tool calls not yet reported by a finish reason. The final chunk
+// carries the usage reported after the finish reason, if any.
+func (p *Provider) handleStreamEnd(ctx context.Context, thinkingParser *parser.ThinkingParser, toolCalls *toolCallAccumulator, usage *llm.UsageInfo, chunks chan<- *llm.StreamChunk) {
 	p.flushRemainingContent(ctx, thinkingParser, chunks)
-	chunks <- &llm.StreamChunk{Finished: true}
+	chunks <- &llm.StreamChunk{Finished: true, ToolCalls: toolCalls.take(), Usage: usage}
 }
 
 // flushRemainingContent flushes any buffered content from the thinking parser
@@ -302,21 +369,36 @@ func (p *Provider) sendChunkIfPresent(ctx context.Context, chunk *llm.StreamChun
 }
 
 // processSSEChunk processes a single SSE data chunk
-func (p *Provider) processSSEChunk(ctx context.Context, data string, firstChunk *bool, thinkingParser *parser.ThinkingParser, chunks chan<- *llm.StreamChunk) bool {
+func (p *Provider) processSSEChunk(ctx context.Context, data string, firstChunk *bool, thinkingParser *parser.ThinkingParser, toolCalls *toolCallAccumulator, usage **llm.UsageInfo, chunks chan<- *llm.StreamChunk) bool {
 	var chunk struct {
 		Choices []struct {
 			Delta struct {
-				Role    string `json:"role"`
-				Content string `json:"content"`
+				Role      string          `json:"role"`
+				Content   string          `json:"content"`
+				ToolCalls []toolCallDelta `json:"tool_calls"`
 			} `json:"delta"`
 			FinishReason *string `json:"finish_reason"`
 		} `json:"choices"`
+		Usage *struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
 	}
 
 	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
 		return true // Skip malformed chunks silently
 	}
 
+	// Usage arrives in its own chunk after the finish reason, with no choices
+	if chunk.Usage != nil {
+		*usage = &llm.UsageInfo{
+			PromptTokens:     chunk.Usage.PromptTokens,
+			CompletionTokens: chunk.Usage.CompletionTokens,
+			TotalTokens:      chunk.Usage.TotalTokens,
+		}
+	}
+
 	if len(chunk.Choices) == 0 {
 		return true
 	}
@@ -335,7 +417,11 @@ func (p *Provider) processSSEChunk(ctx context.Context, data string, firstChunk
 		}
 	}
 
-	return p.handleFinishReason(ctx, chunk.Choices[0].FinishReason, streamChunk, chunks)
+	for _, toolCall := range delta.ToolCalls {
+		toolCalls.add(toolCall)
+	}
+
+	return p.handleFinishReason(ctx, chunk.Choices[0].FinishReason, toolCalls, streamChunk, chunks)
 }
 
 // processContent parses and sends content chunks
@@ -359,11 +445,15 @@ func (p *Provider) processContent(ctx context.Context, content, role string, thi
 	return true
 }
 
-// handleFinishReason handles the finish_reason field
-func (p *Provider) handleFinishReason(ctx context.Context, finishReason *string, streamChunk *llm.StreamChunk, chunks chan<- *llm.StreamChunk) bool {
-	if finishReason != nil && *finishReason == "stop" {
-		streamChunk.Finished = true
-		return p.sendChunkIfPresent(ctx, streamChunk, chunks)
+// handleFinishReason handles the finish_reason field. The stream is only
+// finished at [DONE], since the usage chunk follows the finish reason.
+func (p *Provider) handleFinishReason(ctx context.Context, finishReason *string, toolCalls *toolCallAccumulator, streamChunk *llm.StreamChunk, chunks chan<- *llm.StreamChunk) bool {
+	if finishReason != nil && (*finishReason == "stop" || *finishReason == "tool_calls") {
+		streamChunk.ToolCalls = toolCalls.take()
+		if streamChunk.Role != "" || len(streamChunk.ToolCalls) > 0 {
+			return p.sendChunkIfPresent(ctx, streamChunk, chunks)
+		}
+		return true
 	}
 
 	if streamChunk.Role != "" {
@@ -409,6 +499,97 @@ func (p *Provider) Complete(ctx context.Context, messages []*types.Message) (*ty
 	}, nil
 }
 
+// StatusError is returned when the API answers a request with a non-2xx
+// status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // From the Retry-After header; 0 when absent
+}
+
+// newStatusError reads the error response resp into a *StatusError.
+func newStatusError(resp *http.Response) *StatusError {
+	statusErr := &StatusError{StatusCode: resp.StatusCode, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		statusErr.Body = fmt.Sprintf("(failed to read error body: %v)", err)
+	} else {
+		statusErr.Body = string(body)
+	}
+	return statusErr
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// HTTPStatus returns the response status. It lets llm.IsRateLimited recognize
+// rate limit responses.
+func (e *StatusError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// RetryDelay returns how long the API asked callers to wait before retrying.
+func (e *StatusError) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// ListModels returns the IDs of the models the API serves, from its /models
+// endpoint. A non-2xx response is returned as a *StatusError.
+func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode model list: %w", err)
+	}
+	models := make([]string, 0, len(list.Data))
+	for _, model := range list.Data {
+		models = append(models, model.ID)
+	}
+	return models, nil
+}
+
 // GetModelInfo returns information about the OpenAI model being used.
 func (p *Provider) GetModelInfo() *types.ModelInfo {
 	return p.modelInfo
@@ -491,6 +672,13 @@ func (p *Provider) AnalyzeDocument(ctx context.Context, fileData []byte, mediaTy
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+p.apiKey)
 
+	reservation, err := p.limiter.Acquire(ctx, llm.EstimateTokens(len(bodyBytes)))
+	if err != nil {
+		return "", fmt.Errorf("waiting for the client-side rate limit: %w", err)
+	}
+	usedTokens := 0
+	defer func() { reservation.Done(usedTokens) }()
+
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
@@ -498,11 +686,7 @@ func (p *Provider) AnalyzeDocument(ctx context.Context, fileData []byte, mediaTy
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, readErr := io.ReadAll(resp.Body)
-		if readErr != nil {
-			return "", fmt.Errorf("API request failed with status %d (failed to read error body: %w)", resp.StatusCode, readErr)
-		}
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return "", newStatusError(resp)
 	}
 
 	// Parse the response
@@ -512,11 +696,15 @@ func (p *Provider) AnalyzeDocument(ctx context.Context, fileData []byte, mediaTy
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
+	usedTokens = result.Usage.TotalTokens
 
 	if len(result.Choices) == 0 {
 		return "", fmt.Errorf("no response from API")
@@ -529,12 +717,22 @@ func (p *Provider) AnalyzeDocument(ctx context.Context, fileData []byte, mediaTy
 func convertToOpenAIMessages(messages []*types.Message) []openai.ChatCompletionMessageParamUnion {
 	openaiMessages := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages))
 
-	for _, msg := range messages {
+	for i := 0; i < len(messages); i++ {
+		msg := messages[i]
+
+		// Native tool calls are sent together with their results
+		if msg.Role == types.RoleAssistant && len(msg.ToolCalls) > 0 {
+			converted, consumed := convertToolCallMessages(messages, i)
+			openaiMessages = append(openaiMessages, converted...)
+			i += consumed - 1
+			continue
+		}
+
 		switch msg.Role {
 		case types.RoleSystem:
 			openaiMessages = append(openaiMessages, openai.SystemMessage(msg.Content))
 		case types.RoleUser:
-			openaiMessages = append(openaiMessages, openai.UserMessage(msg.Content))
+			openaiMessages = append(openaiMessages, userMessage(msg.Content, msg.Images))
 		case types.RoleAssistant:
 			openaiMessages = append(openaiMessages, openai.AssistantMessage(msg.Content))
 		case types.RoleTool:
@@ -543,7 +741,7 @@ func convertToOpenAIMessages(messages []*types.Message) []openai.ChatCompletionM
 			// normalizeRoleForLLM in prompts/builder.go. This case handles it
 			// defensively in case the provider is called directly without going
 			// through BuildMessages.
-			openaiMessages = append(openaiMessages, openai.UserMessage(msg.Content))
+			openaiMessages = append(openaiMessages, userMessage(msg.Content, msg.Images))
 		default:
 			// Default to user message for unknown roles.
 			openaiMessages = append(openaiMessages, openai.UserMessage(msg.Content))
@@ -552,3 +750,21 @@ func convertToOpenAIMessages(messages []*types.Message) []openai.ChatCompletionM
 
 	return openaiMessages
 }
+
+// userMessage converts a user message, sending any images as image content
+// parts after the text.
+func userMessage(content string, images []types.Image) openai.ChatCompletionMessageParamUnion {
+	if len(images) == 0 {
+		return openai.UserMessage(content)
+	}
+	parts := make([]openai.ChatCompletionContentPartUnionParam, 0, len(images)+1)
+	if content != "" {
+		parts = append(parts, openai.TextContentPart(content))
+	}
+	for _, image := range images {
+		parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+			URL: fmt.Sprintf("data:%s;base64,%s", image.MediaType, base64.StdEncoding.EncodeToString(image.Data)),
+		}))
+	}
+	return openai.UserMessage(parts)
+}
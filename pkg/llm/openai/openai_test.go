@@ -1,9 +1,18 @@
 package openai
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/llm"
 	"github.com/entrhq/forge/pkg/types"
 )
 
@@ -289,6 +298,27 @@ func TestConvertToOpenAIMessages_PreservesOrder(t *testing.T) {
 	}
 }
 
+func TestConvertToOpenAIMessages_Images(t *testing.T) {
+	msg := types.NewUserMessage("Tool 'view_image' result:\nImage: shot.png")
+	msg.Images = []types.Image{{MediaType: "image/png", Data: []byte("png")}}
+
+	result := convertToOpenAIMessages([]*types.Message{msg})
+	if len(result) != 1 || result[0].OfUser == nil {
+		t.Fatalf("expected 1 user message, got %+v", result)
+	}
+
+	parts := result[0].OfUser.Content.OfArrayOfContentParts
+	if len(parts) != 2 {
+		t.Fatalf("expected text and image parts, got %d parts", len(parts))
+	}
+	if parts[0].OfText == nil || parts[0].OfText.Text != msg.Content {
+		t.Errorf("expected the text first, got %+v", parts[0])
+	}
+	if parts[1].OfImageURL == nil || parts[1].OfImageURL.ImageURL.URL != "data:image/png;base64,cG5n" {
+		t.Errorf("expected the image as a data URL, got %+v", parts[1])
+	}
+}
+
 func TestProvider_ModelInfoMetadata(t *testing.T) {
 	oldKey := os.Getenv("OPENAI_API_KEY")
 	oldBaseURL := os.Getenv("OPENAI_BASE_URL")
@@ -330,3 +360,245 @@ func TestProvider_HTTPClientInitialized(t *testing.T) {
 		t.Error("Expected HTTP client to be initialized")
 	}
 }
+
+func TestProvider_CloneWithSampling(t *testing.T) {
+	var captured map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"ok\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider("test-key", WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	temp := 0.0
+	seed := int64(7)
+	sampled := llm.WithSampling(provider, config.SamplingParams{Temperature: &temp, Seed: &seed})
+
+	if _, err := sampled.Complete(context.Background(), []*types.Message{types.NewUserMessage("hi")}); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if captured["temperature"] != 0.0 {
+		t.Errorf("expected temperature 0 in request, got %v", captured["temperature"])
+	}
+	if captured["seed"] != 7.0 {
+		t.Errorf("expected seed 7 in request, got %v", captured["seed"])
+	}
+	if _, ok := captured["top_p"]; ok {
+		t.Errorf("top_p should be omitted when unset")
+	}
+
+	// The original provider must be unaffected by the clone
+	captured = nil
+	if _, err := provider.Complete(context.Background(), []*types.Message{types.NewUserMessage("hi")}); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if _, ok := captured["temperature"]; ok {
+		t.Errorf("original provider should not send temperature")
+	}
+}
+
+func TestProvider_StreamCompletionReportsUsage(t *testing.T) {
+	var captured map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"ok\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":1200,\"completion_tokens\":3,\"total_tokens\":1203}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider("test-key", WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	stream, err := provider.StreamCompletion(context.Background(), []*types.Message{types.NewUserMessage("hi")})
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+
+	var last *llm.StreamChunk
+	var content string
+	for chunk := range stream {
+		if chunk.Error != nil {
+			t.Fatalf("unexpected stream error: %v", chunk.Error)
+		}
+		if last != nil && last.Finished {
+			t.Fatal("received a chunk after the finished chunk")
+		}
+		content += chunk.Content
+		last = chunk
+	}
+
+	if content != "ok" {
+		t.Errorf("expected content %q, got %q", "ok", content)
+	}
+	if last == nil || !last.Finished || last.Usage == nil {
+		t.Fatalf("expected the finished chunk to carry usage, got %+v", last)
+	}
+	if last.Usage.PromptTokens != 1200 || last.Usage.CompletionTokens != 3 || last.Usage.TotalTokens != 1203 {
+		t.Errorf("unexpected usage %+v", last.Usage)
+	}
+
+	options, ok := captured["stream_options"].(map[string]any)
+	if !ok || options["include_usage"] != true {
+		t.Errorf("expected stream_options.include_usage in request, got %v", captured["stream_options"])
+	}
+}
+
+func TestProvider_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid key"}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"gpt-4o"},{"id":"anthropic/claude-sonnet-4.5"}]}`)
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider("test-key", WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	models, err := provider.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(models) != 2 || models[1] != "anthropic/claude-sonnet-4.5" {
+		t.Errorf("unexpected models %v", models)
+	}
+
+	rejected, err := NewProvider("wrong-key", WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	_, err = rejected.ListModels(context.Background())
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a 401 StatusError, got %v", err)
+	}
+}
+
+func TestProvider_FallsBackOnRateLimit(t *testing.T) {
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		model, _ := req["model"].(string)
+		models = append(models, model)
+		if model == "big-model" {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":"rate limited"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"ok\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider("test-key", WithBaseURL(server.URL), WithModel("big-model"))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	// Without a fallback the rate limit is returned with its status
+	_, err = provider.StreamCompletion(context.Background(), []*types.Message{types.NewUserMessage("hi")})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests || statusErr.RetryAfter.Seconds() != 30 {
+		t.Fatalf("expected a 429 StatusError with a 30s Retry-After, got %v", err)
+	}
+	if !llm.IsRateLimited(err) {
+		t.Error("expected the 429 to count as rate limited")
+	}
+
+	var fallbacks []llm.ModelFallback
+	ctx := llm.ContextWithFallbackHandler(context.Background(), func(f llm.ModelFallback) {
+		fallbacks = append(fallbacks, f)
+	})
+	message, err := llm.WithFallback(provider, "small-model").Complete(ctx, []*types.Message{types.NewUserMessage("hi")})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if message.Content != "ok" {
+		t.Errorf("expected the fallback model's answer, got %q", message.Content)
+	}
+	if len(fallbacks) != 1 || fallbacks[0].From != "big-model" || fallbacks[0].To != "small-model" {
+		t.Errorf("expected one fallback from big-model to small-model, got %+v", fallbacks)
+	}
+	if want := []string{"big-model", "big-model", "small-model"}; fmt.Sprint(models) != fmt.Sprint(want) {
+		t.Errorf("expected requests to %v, got %v", want, models)
+	}
+}
+
+func TestProvider_RateLimiterSharedWithClones(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"ok\"},\"finish_reason\":\"stop\"}]}\n\n")
+		if req["model"] == "held" {
+			// Keep the stream open until the test lets it finish
+			w.(http.Flusher).Flush()
+			<-release
+		}
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":7,\"completion_tokens\":3,\"total_tokens\":10}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	limiter := llm.NewRateLimiter(config.RateLimit{MaxConcurrentRequests: 1})
+	provider, err := NewProvider("test-key", WithBaseURL(server.URL), WithRateLimiter(limiter))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	messages := []*types.Message{types.NewUserMessage("hi")}
+
+	// Each request releases its slot once its stream is read, so requests
+	// from the provider and its clones run one after the other
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, p := range []llm.Provider{provider, provider.CloneWithModel("other"), provider.CloneWithSampling(config.SamplingParams{})} {
+		if _, err := p.Complete(ctx, messages); err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+	}
+
+	// A request still streaming holds back the others
+	held, err := provider.CloneWithModel("held").StreamCompletion(ctx, messages)
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+	blockedCtx, cancelBlocked := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancelBlocked()
+	if _, err := provider.Complete(blockedCtx, messages); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request to wait for the held one, got %v", err)
+	}
+
+	close(release)
+	for range held {
+	}
+	if _, err := provider.Complete(ctx, messages); err != nil {
+		t.Fatalf("Complete after the held request finished failed: %v", err)
+	}
+}
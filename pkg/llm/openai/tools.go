@@ -0,0 +1,159 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/shared"
+)
+
+// missingToolResult answers a native tool call that has no recorded result,
+// such as a loop-breaking tool or a call the user rejected. The API rejects
+// histories where a tool call is not followed by its result.
+const missingToolResult = "No result was recorded for this tool call."
+
+// toolImagesContent introduces the images returned by native tool calls.
+const toolImagesContent = "Images returned by the tool calls above:"
+
+// StreamCompletionWithTools streams a completion that offers tools through the
+// function-calling API. Tool calls are accumulated from the streamed deltas and
+// reported on the final chunk. It implements llm.ToolCallingProvider.
+func (p *Provider) StreamCompletionWithTools(ctx context.Context, messages []*types.Message, tools []llm.ToolDefinition) (<-chan *llm.StreamChunk, error) {
+	resp, reservation, err := p.sendStreamRequest(ctx, messages, tools)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make(chan *llm.StreamChunk, 10)
+	go p.processStreamResponse(ctx, resp, reservation, chunks)
+	return chunks, nil
+}
+
+// toolCallDelta is one streamed fragment of a tool call. The first fragment
+// for an index carries the ID and name; the arguments arrive in pieces.
+type toolCallDelta struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// toolCallAccumulator assembles streamed tool call deltas by index.
+type toolCallAccumulator struct {
+	calls map[int]*types.ToolCall
+}
+
+// add merges a delta into the call at its index.
+func (a *toolCallAccumulator) add(delta toolCallDelta) {
+	if a.calls == nil {
+		a.calls = make(map[int]*types.ToolCall)
+	}
+	call, ok := a.calls[delta.Index]
+	if !ok {
+		call = &types.ToolCall{}
+		a.calls[delta.Index] = call
+	}
+	if delta.ID != "" {
+		call.ID = delta.ID
+	}
+	if delta.Function.Name != "" {
+		call.Name = delta.Function.Name
+	}
+	call.Arguments += delta.Function.Arguments
+}
+
+// take returns the accumulated calls in index order and resets the accumulator.
+func (a *toolCallAccumulator) take() []types.ToolCall {
+	if len(a.calls) == 0 {
+		return nil
+	}
+	indexes := make([]int, 0, len(a.calls))
+	for index := range a.calls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	calls := make([]types.ToolCall, 0, len(indexes))
+	for _, index := range indexes {
+		call := *a.calls[index]
+		// Some OpenAI-compatible servers omit call IDs, which results must reference
+		if call.ID == "" {
+			call.ID = fmt.Sprintf("call_%d", index)
+		}
+		calls = append(calls, call)
+	}
+	a.calls = nil
+	return calls
+}
+
+// convertToOpenAITools converts tool definitions to function tool parameters.
+func convertToOpenAITools(tools []llm.ToolDefinition) []openai.ChatCompletionToolParam {
+	params := make([]openai.ChatCompletionToolParam, 0, len(tools))
+	for _, tool := range tools {
+		function := shared.FunctionDefinitionParam{
+			Name:       tool.Name,
+			Parameters: shared.FunctionParameters(tool.Parameters),
+		}
+		if tool.Description != "" {
+			function.Description = openai.String(tool.Description)
+		}
+		params = append(params, openai.ChatCompletionToolParam{Function: function})
+	}
+	return params
+}
+
+// convertToolCallMessages converts an assistant message with native tool calls,
+// and the tool results that answer it, starting at messages[i]. Calls without
+// a result get a placeholder answer. It returns the converted messages and the
+// number of input messages consumed.
+func convertToolCallMessages(messages []*types.Message, i int) ([]openai.ChatCompletionMessageParamUnion, int) {
+	msg := messages[i]
+
+	var assistant openai.ChatCompletionAssistantMessageParam
+	if msg.Content != "" {
+		assistant.Content.OfString = openai.String(msg.Content)
+	}
+	pending := make(map[string]bool, len(msg.ToolCalls))
+	for _, call := range msg.ToolCalls {
+		assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallParam{
+			ID: call.ID,
+			Function: openai.ChatCompletionMessageToolCallFunctionParam{
+				Name:      call.Name,
+				Arguments: call.Arguments,
+			},
+		})
+		pending[call.ID] = true
+	}
+	converted := []openai.ChatCompletionMessageParamUnion{{OfAssistant: &assistant}}
+
+	// Results must directly follow the call they answer
+	consumed := 1
+	var images []types.Image
+	for _, next := range messages[i+1:] {
+		if next.Role != types.RoleTool || !pending[next.ToolCallID] {
+			break
+		}
+		converted = append(converted, openai.ToolMessage(next.Content, next.ToolCallID))
+		images = append(images, next.Images...)
+		delete(pending, next.ToolCallID)
+		consumed++
+	}
+	for _, call := range msg.ToolCalls {
+		if pending[call.ID] {
+			converted = append(converted, openai.ToolMessage(missingToolResult, call.ID))
+		}
+	}
+
+	// Tool messages only carry text, so images the tools returned follow the
+	// results in a user message
+	if len(images) > 0 {
+		converted = append(converted, userMessage(toolImagesContent, images))
+	}
+	return converted, consumed
+}
@@ -0,0 +1,192 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/llm"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func TestProvider_StreamCompletionWithTools(t *testing.T) {
+	var captured map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"Reading it.\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"function\":{\"name\":\"read_file\",\"arguments\":\"{\\\"path\\\":\"}}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":\"\\\"main.go\\\"}\"}}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"tool_calls\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider("test-key", WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	if !llm.SupportsNativeToolCalls(provider) {
+		t.Fatal("expected the provider to support native tool calls")
+	}
+
+	tools := []llm.ToolDefinition{{
+		Name:        "read_file",
+		Description: "Read a file",
+		Parameters:  map[string]any{"type": "object", "properties": map[string]any{"path": map[string]any{"type": "string"}}},
+	}}
+	stream, err := provider.StreamCompletionWithTools(context.Background(), []*types.Message{types.NewUserMessage("read main.go")}, tools)
+	if err != nil {
+		t.Fatalf("StreamCompletionWithTools failed: %v", err)
+	}
+
+	var content string
+	var calls []types.ToolCall
+	for chunk := range stream {
+		if chunk.Error != nil {
+			t.Fatalf("unexpected stream error: %v", chunk.Error)
+		}
+		content += chunk.Content
+		calls = append(calls, chunk.ToolCalls...)
+	}
+
+	if content != "Reading it." {
+		t.Errorf("expected content %q, got %q", "Reading it.", content)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(calls))
+	}
+	if calls[0].ID != "call_1" || calls[0].Name != "read_file" || calls[0].Arguments != `{"path":"main.go"}` {
+		t.Errorf("unexpected tool call %+v", calls[0])
+	}
+
+	requestTools, ok := captured["tools"].([]any)
+	if !ok || len(requestTools) != 1 {
+		t.Fatalf("expected 1 tool in request, got %v", captured["tools"])
+	}
+	function := requestTools[0].(map[string]any)["function"].(map[string]any)
+	if function["name"] != "read_file" || function["description"] != "Read a file" {
+		t.Errorf("unexpected function definition %v", function)
+	}
+}
+
+func TestProvider_StreamCompletionOmitsTools(t *testing.T) {
+	var captured map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"ok\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider("test-key", WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	if _, err := provider.Complete(context.Background(), []*types.Message{types.NewUserMessage("hi")}); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if _, ok := captured["tools"]; ok {
+		t.Error("tools should be omitted from plain completions")
+	}
+}
+
+func TestToolCallAccumulator_OrdersAndResets(t *testing.T) {
+	acc := &toolCallAccumulator{}
+	second := toolCallDelta{Index: 1}
+	second.Function.Name = "list_files"
+	first := toolCallDelta{Index: 0, ID: "call_a"}
+	first.Function.Name = "read_file"
+
+	acc.add(second)
+	acc.add(first)
+
+	calls := acc.take()
+	if len(calls) != 2 || calls[0].Name != "read_file" || calls[1].Name != "list_files" {
+		t.Fatalf("expected calls in index order, got %+v", calls)
+	}
+	if calls[1].ID != "call_1" {
+		t.Errorf("expected a generated ID for a call without one, got %q", calls[1].ID)
+	}
+	if acc.take() != nil {
+		t.Error("expected take to reset the accumulator")
+	}
+}
+
+func TestConvertToOpenAIMessages_PairsToolCalls(t *testing.T) {
+	result := types.NewToolMessage("Tool 'read_file' result:\npackage main")
+	result.ToolCallID = "call_1"
+	orphan := types.NewToolMessage("Tool 'list_files' result:\nmain.go")
+	orphan.ToolCallID = "call_gone"
+
+	messages := []*types.Message{
+		types.NewUserMessage("read main.go"),
+		{Role: types.RoleAssistant, Content: "Reading it.", ToolCalls: []types.ToolCall{{ID: "call_1", Name: "read_file", Arguments: `{"path":"main.go"}`}}},
+		result,
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "call_2", Name: "task_completion", Arguments: `{"result":"done"}`}}},
+		types.NewUserMessage("thanks"),
+		orphan,
+	}
+
+	converted := convertToOpenAIMessages(messages)
+	if len(converted) != 7 {
+		t.Fatalf("expected 7 messages, got %d", len(converted))
+	}
+
+	assistant := converted[1].OfAssistant
+	if assistant == nil || len(assistant.ToolCalls) != 1 || assistant.ToolCalls[0].ID != "call_1" {
+		t.Fatalf("expected assistant message with call_1, got %+v", converted[1])
+	}
+	if tool := converted[2].OfTool; tool == nil || tool.ToolCallID != "call_1" {
+		t.Errorf("expected tool result for call_1, got %+v", converted[2])
+	}
+
+	// A call without a recorded result is answered with a placeholder
+	if tool := converted[4].OfTool; tool == nil || tool.ToolCallID != "call_2" || tool.Content.OfString.Value != missingToolResult {
+		t.Errorf("expected placeholder result for call_2, got %+v", converted[4])
+	}
+	if converted[5].OfUser == nil {
+		t.Errorf("expected user message after placeholder, got %+v", converted[5])
+	}
+
+	// A result whose call is no longer in the history falls back to a user message
+	if converted[6].OfUser == nil {
+		t.Errorf("expected orphaned tool result as user message, got %+v", converted[6])
+	}
+}
+
+func TestConvertToOpenAIMessages_ToolImages(t *testing.T) {
+	result := types.NewToolMessage("Tool 'view_image' result:\nImage: shot.png")
+	result.ToolCallID = "call_1"
+	result.Images = []types.Image{{MediaType: "image/png", Data: []byte("png")}}
+
+	messages := []*types.Message{
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "call_1", Name: "view_image", Arguments: `{"path":"shot.png"}`}}},
+		result,
+	}
+
+	converted := convertToOpenAIMessages(messages)
+	if len(converted) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(converted))
+	}
+	if tool := converted[1].OfTool; tool == nil || tool.ToolCallID != "call_1" {
+		t.Errorf("expected tool result for call_1, got %+v", converted[1])
+	}
+
+	// Tool messages cannot hold images, so they follow in a user message
+	user := converted[2].OfUser
+	if user == nil {
+		t.Fatalf("expected user message with the image, got %+v", converted[2])
+	}
+	parts := user.Content.OfArrayOfContentParts
+	if len(parts) != 2 || parts[1].OfImageURL == nil {
+		t.Errorf("expected text and image parts, got %+v", parts)
+	}
+}
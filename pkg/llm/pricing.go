@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"strings"
+
+	"github.com/entrhq/forge/pkg/config"
+)
+
+// builtinPricing holds list prices for common models, keyed by model name
+// prefix. Users on other models or negotiated rates set llm.pricing instead.
+var builtinPricing = map[string]config.ModelPricing{
+	"claude-sonnet-4":  {InputPerMillion: 3, OutputPerMillion: 15},
+	"claude-haiku-4":   {InputPerMillion: 1, OutputPerMillion: 5},
+	"claude-3-5-haiku": {InputPerMillion: 0.8, OutputPerMillion: 4},
+	"gpt-5":            {InputPerMillion: 1.25, OutputPerMillion: 10},
+	"gpt-5-mini":       {InputPerMillion: 0.25, OutputPerMillion: 2},
+	"gpt-5-nano":       {InputPerMillion: 0.05, OutputPerMillion: 0.4},
+	"gpt-4.1":          {InputPerMillion: 2, OutputPerMillion: 8},
+	"gpt-4.1-mini":     {InputPerMillion: 0.4, OutputPerMillion: 1.6},
+	"gpt-4.1-nano":     {InputPerMillion: 0.1, OutputPerMillion: 0.4},
+	"gpt-4o":           {InputPerMillion: 2.5, OutputPerMillion: 10},
+	"gpt-4o-mini":      {InputPerMillion: 0.15, OutputPerMillion: 0.6},
+}
+
+// PricingForModel returns the price of model, preferring an exact entry in
+// the global llm.pricing config over the built-in table. Router-style names
+// such as "anthropic/claude-sonnet-4.5" match on the part after the slash.
+// It reports false when the price is unknown.
+func PricingForModel(model string) (config.ModelPricing, bool) {
+	if llmCfg := config.GetLLM(); llmCfg != nil {
+		if price, ok := llmCfg.GetPricing(model); ok {
+			return price, true
+		}
+	}
+
+	return lookupModel(builtinPricing, model)
+}
+
+// lookupModel finds model in a table keyed by model name prefix. Router-style
+// names match on the part after the slash, and the longest prefix wins so
+// "gpt-4o-mini" does not match "gpt-4o".
+func lookupModel[T any](table map[string]T, model string) (T, bool) {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	var best string
+	for prefix := range table {
+		if strings.HasPrefix(name, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		var zero T
+		return zero, false
+	}
+	return table[best], true
+}
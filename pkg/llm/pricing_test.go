@@ -0,0 +1,29 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/entrhq/forge/pkg/config"
+)
+
+func TestPricingForModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  config.ModelPricing
+		found bool
+	}{
+		{"anthropic/claude-sonnet-4.5", config.ModelPricing{InputPerMillion: 3, OutputPerMillion: 15}, true},
+		{"gpt-4o-mini-2024-07-18", config.ModelPricing{InputPerMillion: 0.15, OutputPerMillion: 0.6}, true},
+		{"GPT-4o", config.ModelPricing{InputPerMillion: 2.5, OutputPerMillion: 10}, true},
+		{"some-local-model", config.ModelPricing{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, ok := PricingForModel(tt.model)
+			if ok != tt.found || got != tt.want {
+				t.Errorf("PricingForModel(%q) = %+v, %v; want %+v, %v", tt.model, got, ok, tt.want, tt.found)
+			}
+		})
+	}
+}
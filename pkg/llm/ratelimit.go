@@ -0,0 +1,206 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config"
+)
+
+// rateLimitWindow is the window requests and tokens per minute are counted over
+const rateLimitWindow = time.Minute
+
+// RateLimitFromConfig returns the rate limits in the global LLM settings. It
+// returns the zero value, no limits, when configuration has not been
+// initialized.
+func RateLimitFromConfig() config.RateLimit {
+	llmCfg := config.GetLLM()
+	if llmCfg == nil {
+		return config.RateLimit{}
+	}
+	return llmCfg.GetRateLimit()
+}
+
+// rateLimitEntry is a request counted against the per-minute limits
+type rateLimitEntry struct {
+	start  time.Time
+	tokens int // Estimated until the request finishes, then as reported
+}
+
+// RateLimiter holds requests back on the client until they fit within its
+// config.RateLimit, so the agent, the summarizer and the commit and PR
+// generators share one budget instead of each tripping the provider's limits. A nil
+// *RateLimiter does not limit anything. It is safe for concurrent use.
+type RateLimiter struct {
+	mu       sync.Mutex
+	limits   config.RateLimit
+	window   []*rateLimitEntry // Requests started within the last minute, oldest first
+	inFlight int
+	changed  chan struct{} // Closed when a request finishes or the limits change
+	now      func() time.Time
+}
+
+// NewRateLimiter creates a rate limiter enforcing limits.
+func NewRateLimiter(limits config.RateLimit) *RateLimiter {
+	return &RateLimiter{
+		limits:  limits,
+		changed: make(chan struct{}),
+		now:     time.Now,
+	}
+}
+
+var (
+	sharedRateLimiter     *RateLimiter
+	sharedRateLimiterOnce sync.Once
+)
+
+// SharedRateLimiter returns the process-wide rate limiter that providers
+// built from configuration share. It does not limit anything until limits
+// are set on it.
+func SharedRateLimiter() *RateLimiter {
+	sharedRateLimiterOnce.Do(func() {
+		sharedRateLimiter = NewRateLimiter(config.RateLimit{})
+	})
+	return sharedRateLimiter
+}
+
+// SetLimits replaces the limits. Requests already waiting are checked
+// against the new ones.
+func (l *RateLimiter) SetLimits(limits config.RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits = limits
+	l.notifyLocked()
+}
+
+// Limits returns the limits being enforced.
+func (l *RateLimiter) Limits() config.RateLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limits
+}
+
+// Acquire waits until a request estimated to use tokens fits within the
+// limits, or ctx is done. Call Done on the returned reservation once the
+// request has finished. A nil limiter returns a nil reservation straight
+// away.
+func (l *RateLimiter) Acquire(ctx context.Context, tokens int) (*RateLimitReservation, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	for {
+		l.mu.Lock()
+		entry, wait := l.tryAcquireLocked(tokens)
+		changed := l.changed
+		l.mu.Unlock()
+		if entry != nil {
+			return &RateLimitReservation{limiter: l, entry: entry}, nil
+		}
+
+		// Wait for a request to finish, or for the oldest one counted
+		// against the per-minute limits to leave the window
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-changed:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tryAcquireLocked counts a request against the limits when it fits. When
+// it does not, it returns how long until a request leaves the per-minute
+// window, or 0 when only a finishing request can make room.
+func (l *RateLimiter) tryAcquireLocked(tokens int) (*rateLimitEntry, time.Duration) {
+	now := l.now()
+	l.pruneLocked(now)
+
+	if l.limits.MaxConcurrentRequests > 0 && l.inFlight >= l.limits.MaxConcurrentRequests {
+		return nil, 0
+	}
+	if l.limits.RequestsPerMinute > 0 && len(l.window) >= l.limits.RequestsPerMinute {
+		return nil, l.window[len(l.window)-l.limits.RequestsPerMinute].start.Add(rateLimitWindow).Sub(now)
+	}
+	if limit := l.limits.TokensPerMinute; limit > 0 && len(l.window) > 0 {
+		// A request larger than the whole budget goes once the window is empty
+		needed := min(tokens, limit)
+		used := 0
+		for _, entry := range l.window {
+			used += entry.tokens
+		}
+		if used+needed > limit {
+			for _, entry := range l.window {
+				used -= entry.tokens
+				if used+needed <= limit {
+					return nil, entry.start.Add(rateLimitWindow).Sub(now)
+				}
+			}
+		}
+	}
+
+	entry := &rateLimitEntry{start: now, tokens: tokens}
+	l.window = append(l.window, entry)
+	l.inFlight++
+	return entry, 0
+}
+
+// pruneLocked forgets requests that started more than a minute ago.
+func (l *RateLimiter) pruneLocked(now time.Time) {
+	cutoff := now.Add(-rateLimitWindow)
+	i := 0
+	for i < len(l.window) && !l.window[i].start.After(cutoff) {
+		i++
+	}
+	l.window = l.window[i:]
+}
+
+// notifyLocked wakes the requests waiting for room.
+func (l *RateLimiter) notifyLocked() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// RateLimitReservation is a request counted against a RateLimiter's limits.
+type RateLimitReservation struct {
+	limiter *RateLimiter
+	entry   *rateLimitEntry
+	once    sync.Once
+}
+
+// Done releases the request's concurrency slot. usedTokens, when above 0,
+// replaces the estimate the request was admitted with. Done may be called on
+// a nil reservation, and more than once.
+func (r *RateLimitReservation) Done(usedTokens int) {
+	if r == nil {
+		return
+	}
+	r.once.Do(func() {
+		l := r.limiter
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if usedTokens > 0 {
+			r.entry.tokens = usedTokens
+		}
+		l.inFlight--
+		l.notifyLocked()
+	})
+}
+
+// EstimateTokens approximates the tokens in a request body of n bytes, at
+// four bytes per token.
+func EstimateTokens(n int) int {
+	return (n + 3) / 4
+}
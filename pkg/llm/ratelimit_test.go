@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config"
+)
+
+// newTestRateLimiter returns a limiter on a clock the test moves by hand
+func newTestRateLimiter(limits config.RateLimit) (*RateLimiter, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(limits)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+// acquireNow acquires without waiting, reporting whether the request fit
+func acquireNow(t *testing.T, limiter *RateLimiter, tokens int) (*RateLimitReservation, bool) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	reservation, err := limiter.Acquire(ctx, tokens)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, false
+	}
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	return reservation, true
+}
+
+func TestRateLimiter_RequestsPerMinute(t *testing.T) {
+	limiter, now := newTestRateLimiter(config.RateLimit{RequestsPerMinute: 2})
+
+	for i := 0; i < 2; i++ {
+		reservation, ok := acquireNow(t, limiter, 10)
+		if !ok {
+			t.Fatalf("request %d was held back", i+1)
+		}
+		reservation.Done(0)
+	}
+	if _, ok := acquireNow(t, limiter, 10); ok {
+		t.Fatal("a third request within the minute was let through")
+	}
+
+	*now = now.Add(time.Minute)
+	if _, ok := acquireNow(t, limiter, 10); !ok {
+		t.Error("request was held back after the window moved on")
+	}
+}
+
+func TestRateLimiter_TokensPerMinute(t *testing.T) {
+	limiter, now := newTestRateLimiter(config.RateLimit{TokensPerMinute: 100})
+
+	first, ok := acquireNow(t, limiter, 50)
+	if !ok {
+		t.Fatal("first request was held back")
+	}
+	// The reported usage replaces the estimate
+	first.Done(90)
+	if _, ok := acquireNow(t, limiter, 20); ok {
+		t.Fatal("request over the token budget was let through")
+	}
+	if reservation, ok := acquireNow(t, limiter, 10); !ok {
+		t.Fatal("request within the token budget was held back")
+	} else {
+		reservation.Done(0)
+	}
+
+	// A request larger than the whole budget waits for an empty window
+	*now = now.Add(time.Minute)
+	if _, ok := acquireNow(t, limiter, 500); !ok {
+		t.Error("oversized request was held back with nothing else in the window")
+	}
+}
+
+func TestRateLimiter_MaxConcurrentRequests(t *testing.T) {
+	limiter, _ := newTestRateLimiter(config.RateLimit{MaxConcurrentRequests: 1})
+
+	first, ok := acquireNow(t, limiter, 1)
+	if !ok {
+		t.Fatal("first request was held back")
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		reservation, err := limiter.Acquire(ctx, 1)
+		reservation.Done(0)
+		acquired <- err
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second request ran while the first was in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	first.Done(0)
+	first.Done(0) // Done twice must not free a second slot
+	if err := <-acquired; err != nil {
+		t.Fatalf("second request failed once the first finished: %v", err)
+	}
+}
+
+func TestRateLimiter_Nil(t *testing.T) {
+	var limiter *RateLimiter
+	reservation, err := limiter.Acquire(context.Background(), 1000)
+	if err != nil || reservation != nil {
+		t.Fatalf("nil limiter Acquire() = %v, %v, want nil, nil", reservation, err)
+	}
+	reservation.Done(10)
+}
@@ -0,0 +1,407 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/security/secrets"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// RequestLogDir is where request logs are written, relative to the workspace.
+const RequestLogDir = ".forge/llm-logs"
+
+// Request kinds recorded in RequestLogRecord.Kind
+const (
+	RequestKindStream          = "stream"
+	RequestKindStreamWithTools = "stream_with_tools"
+	RequestKindComplete        = "complete"
+	RequestKindAnalyzeDocument = "analyze_document"
+)
+
+// redactedText replaces secrets in request logs
+const redactedText = "[REDACTED]"
+
+// RequestLogRecord is one request to the model and its response, as written
+// to a file in the request log directory.
+type RequestLogRecord struct {
+	ID         string             `json:"id"`
+	Time       time.Time          `json:"time"`
+	Kind       string             `json:"kind"`
+	Model      string             `json:"model"`
+	BaseURL    string             `json:"base_url,omitempty"`
+	DurationMS int64              `json:"duration_ms"`
+	Request    RequestLogRequest  `json:"request"`
+	Response   RequestLogResponse `json:"response"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// RequestLogRequest is what was sent to the model.
+type RequestLogRequest struct {
+	Messages []RequestLogMessage `json:"messages,omitempty"`
+	Tools    []string            `json:"tools,omitempty"` // Names of the tools offered natively
+
+	// Set for document analysis
+	MediaType     string `json:"media_type,omitempty"`
+	DocumentBytes int    `json:"document_bytes,omitempty"`
+	Prompt        string `json:"prompt,omitempty"`
+}
+
+// RequestLogMessage is a message of the conversation sent to the model.
+// Images are counted rather than stored.
+type RequestLogMessage struct {
+	Role       string               `json:"role"`
+	Content    string               `json:"content"`
+	ToolCalls  []RequestLogToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string               `json:"tool_call_id,omitempty"`
+	Images     int                  `json:"images,omitempty"`
+}
+
+// RequestLogToolCall is a native tool call made by the model.
+type RequestLogToolCall struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// RequestLogResponse is what the model streamed back.
+type RequestLogResponse struct {
+	Content          string               `json:"content"`
+	Thinking         string               `json:"thinking,omitempty"`
+	ToolCalls        []RequestLogToolCall `json:"tool_calls,omitempty"`
+	Chunks           int                  `json:"chunks,omitempty"`
+	PromptTokens     int                  `json:"prompt_tokens,omitempty"`
+	CompletionTokens int                  `json:"completion_tokens,omitempty"`
+}
+
+// RequestLog writes every request a RequestLogProvider sends, and the
+// response streamed back, to a JSON file per request in a directory. Known
+// secrets and credential-shaped strings are redacted before anything is
+// written. A nil *RequestLog does not log anything. It is safe for
+// concurrent use.
+type RequestLog struct {
+	dir     string
+	seq     atomic.Int64
+	mu      sync.Mutex
+	secrets []string
+	now     func() time.Time
+}
+
+// NewRequestLog creates a request log writing to dir, creating it if
+// needed. secrets, such as the API key, are redacted wherever they appear.
+func NewRequestLog(dir string, secrets ...string) (*RequestLog, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create request log directory: %w", err)
+	}
+	l := &RequestLog{dir: dir, now: time.Now}
+	l.AddSecrets(secrets...)
+	return l, nil
+}
+
+// Dir returns the directory the log writes to.
+func (l *RequestLog) Dir() string {
+	return l.dir
+}
+
+// AddSecrets adds values to redact, such as an API key read after the log
+// was created. Values shorter than 8 characters are ignored, since redacting
+// them would mangle ordinary text.
+func (l *RequestLog) AddSecrets(secrets ...string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, secret := range secrets {
+		if len(secret) >= 8 {
+			l.secrets = append(l.secrets, secret)
+		}
+	}
+}
+
+var (
+	sharedRequestLogMu sync.Mutex
+	sharedRequestLog   *RequestLog
+)
+
+// SharedRequestLog returns the process-wide request log that providers
+// built for the agent are wrapped with, or nil when logging is off.
+func SharedRequestLog() *RequestLog {
+	sharedRequestLogMu.Lock()
+	defer sharedRequestLogMu.Unlock()
+	return sharedRequestLog
+}
+
+// SetSharedRequestLog turns request logging on for providers built from now
+// on, or off when l is nil.
+func SetSharedRequestLog(l *RequestLog) {
+	sharedRequestLogMu.Lock()
+	defer sharedRequestLogMu.Unlock()
+	sharedRequestLog = l
+}
+
+// redact replaces the log's secrets and credential-shaped strings in data.
+func (l *RequestLog) redact(data string) string {
+	l.mu.Lock()
+	known := l.secrets
+	l.mu.Unlock()
+	for _, secret := range known {
+		data = strings.ReplaceAll(data, secret, redactedText)
+	}
+	return secrets.Redact(data, redactedText)
+}
+
+// write redacts record and saves it to its own file. Failures are ignored:
+// the log is a debugging aid and must never fail a request.
+func (l *RequestLog) write(record *RequestLogRecord) {
+	record.ID = fmt.Sprintf("%s-%04d", record.Time.Format("20060102-150405.000"), l.seq.Add(1))
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return
+	}
+	path := filepath.Join(l.dir, record.ID+".json")
+	_ = os.WriteFile(path, []byte(l.redact(string(data))), 0o600) //nolint:errcheck // best effort
+}
+
+// ReadRequestLog returns the records in dir, newest first, at most limit of
+// them when limit is above 0. A missing directory has no records.
+func ReadRequestLog(dir string, limit int) ([]RequestLogRecord, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	// IDs start with the request's time, so names sort oldest first
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	if limit > 0 && len(names) > limit {
+		names = names[:limit]
+	}
+
+	records := make([]RequestLogRecord, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		var record RequestLogRecord
+		if json.Unmarshal(data, &record) == nil {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// RequestLogProvider is a Provider that writes the requests it sends to the
+// provider it wraps, and their responses, to a RequestLog.
+type RequestLogProvider struct {
+	provider Provider
+	log      *RequestLog
+}
+
+// WithRequestLog returns provider writing its requests to log. Provider is
+// returned unchanged when log is nil.
+func WithRequestLog(provider Provider, log *RequestLog) Provider {
+	if log == nil {
+		return provider
+	}
+	log.AddSecrets(provider.GetAPIKey())
+	return &RequestLogProvider{provider: provider, log: log}
+}
+
+// newRecord starts the record of a request of kind.
+func (p *RequestLogProvider) newRecord(kind string, messages []*types.Message) *RequestLogRecord {
+	record := &RequestLogRecord{
+		Time:    p.log.now(),
+		Kind:    kind,
+		Model:   p.provider.GetModel(),
+		BaseURL: p.provider.GetBaseURL(),
+	}
+	for _, msg := range messages {
+		if msg == nil {
+			continue
+		}
+		record.Request.Messages = append(record.Request.Messages, RequestLogMessage{
+			Role:       string(msg.Role),
+			Content:    msg.Content,
+			ToolCalls:  requestLogToolCalls(msg.ToolCalls),
+			ToolCallID: msg.ToolCallID,
+			Images:     len(msg.Images),
+		})
+	}
+	return record
+}
+
+// finish records how long the request took and the error it ended with,
+// then writes the record.
+func (p *RequestLogProvider) finish(record *RequestLogRecord, err error) {
+	record.DurationMS = p.log.now().Sub(record.Time).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+	}
+	p.log.write(record)
+}
+
+// requestLogToolCalls converts native tool calls for the log.
+func requestLogToolCalls(calls []types.ToolCall) []RequestLogToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	logged := make([]RequestLogToolCall, len(calls))
+	for i, call := range calls {
+		logged[i] = RequestLogToolCall{ID: call.ID, Name: call.Name, Arguments: call.Arguments}
+	}
+	return logged
+}
+
+// logStream relays stream to the caller, accumulating the response into
+// record, and writes the record once the stream ends.
+func (p *RequestLogProvider) logStream(ctx context.Context, record *RequestLogRecord, stream <-chan *StreamChunk, err error) (<-chan *StreamChunk, error) {
+	if err != nil {
+		p.finish(record, err)
+		return nil, err
+	}
+
+	out := make(chan *StreamChunk)
+	go func() {
+		defer close(out)
+		var content, thinking strings.Builder
+		var streamErr error
+		relaying := true
+		for chunk := range stream {
+			record.Response.Chunks++
+			if chunk.Type == ContentTypeThinking {
+				thinking.WriteString(chunk.Content)
+			} else {
+				content.WriteString(chunk.Content)
+			}
+			if chunk.Error != nil {
+				streamErr = chunk.Error
+			}
+			if chunk.Usage != nil {
+				record.Response.PromptTokens = chunk.Usage.PromptTokens
+				record.Response.CompletionTokens = chunk.Usage.CompletionTokens
+			}
+			if len(chunk.ToolCalls) > 0 {
+				record.Response.ToolCalls = requestLogToolCalls(chunk.ToolCalls)
+			}
+
+			// Keep draining once the caller has gone, so the wrapped
+			// provider is not left blocked on its send
+			if relaying {
+				select {
+				case out <- chunk:
+				case <-ctx.Done():
+					relaying = false
+				}
+			}
+		}
+		record.Response.Content = content.String()
+		record.Response.Thinking = thinking.String()
+		if streamErr == nil && ctx.Err() != nil {
+			streamErr = ctx.Err()
+		}
+		p.finish(record, streamErr)
+	}()
+	return out, nil
+}
+
+// StreamCompletion implements Provider.
+func (p *RequestLogProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *StreamChunk, error) {
+	record := p.newRecord(RequestKindStream, messages)
+	stream, err := p.provider.StreamCompletion(ctx, messages)
+	return p.logStream(ctx, record, stream, err)
+}
+
+// StreamCompletionWithTools implements ToolCallingProvider when the wrapped
+// provider does.
+func (p *RequestLogProvider) StreamCompletionWithTools(ctx context.Context, messages []*types.Message, tools []ToolDefinition) (<-chan *StreamChunk, error) {
+	toolProvider, ok := p.provider.(ToolCallingProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support native tool calling")
+	}
+	record := p.newRecord(RequestKindStreamWithTools, messages)
+	for _, tool := range tools {
+		record.Request.Tools = append(record.Request.Tools, tool.Name)
+	}
+	stream, err := toolProvider.StreamCompletionWithTools(ctx, messages, tools)
+	return p.logStream(ctx, record, stream, err)
+}
+
+// Complete implements Provider.
+func (p *RequestLogProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	record := p.newRecord(RequestKindComplete, messages)
+	response, err := p.provider.Complete(ctx, messages)
+	if response != nil {
+		record.Response.Content = response.Content
+		record.Response.ToolCalls = requestLogToolCalls(response.ToolCalls)
+	}
+	p.finish(record, err)
+	return response, err
+}
+
+// AnalyzeDocument implements Provider. The document itself is not logged,
+// only its type and size.
+func (p *RequestLogProvider) AnalyzeDocument(ctx context.Context, fileData []byte, mediaType string, prompt string) (string, error) {
+	record := p.newRecord(RequestKindAnalyzeDocument, nil)
+	record.Request.MediaType = mediaType
+	record.Request.DocumentBytes = len(fileData)
+	record.Request.Prompt = prompt
+	analysis, err := p.provider.AnalyzeDocument(ctx, fileData, mediaType, prompt)
+	record.Response.Content = analysis
+	p.finish(record, err)
+	return analysis, err
+}
+
+// GetModelInfo returns the wrapped provider's model info.
+func (p *RequestLogProvider) GetModelInfo() *types.ModelInfo {
+	return p.provider.GetModelInfo()
+}
+
+// GetModel returns the wrapped provider's model name.
+func (p *RequestLogProvider) GetModel() string {
+	return p.provider.GetModel()
+}
+
+// GetBaseURL returns the wrapped provider's base URL.
+func (p *RequestLogProvider) GetBaseURL() string {
+	return p.provider.GetBaseURL()
+}
+
+// GetAPIKey returns the wrapped provider's API key.
+func (p *RequestLogProvider) GetAPIKey() string {
+	return p.provider.GetAPIKey()
+}
+
+// CloneWithModel returns the wrapped provider switched to model, still
+// logging to the same log. It implements ModelCloner.
+func (p *RequestLogProvider) CloneWithModel(model string) Provider {
+	cloner, ok := p.provider.(ModelCloner)
+	if !ok {
+		return p
+	}
+	return &RequestLogProvider{provider: cloner.CloneWithModel(model), log: p.log}
+}
+
+// CloneWithSampling returns the wrapped provider sending params, still
+// logging to the same log. It implements SamplingCloner.
+func (p *RequestLogProvider) CloneWithSampling(params config.SamplingParams) Provider {
+	return &RequestLogProvider{provider: WithSampling(p.provider, params), log: p.log}
+}
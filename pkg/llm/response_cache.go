@@ -0,0 +1,344 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// Defaults for the response cache fields left unset
+const (
+	DefaultResponseCacheTTL       = 7 * 24 * time.Hour
+	DefaultResponseCacheMaxSizeMB = 256
+)
+
+// ResponseCacheFromConfig returns the response cache settings from the global
+// LLM settings. It returns the zero value, which leaves the cache off, when
+// configuration has not been initialized.
+func ResponseCacheFromConfig() config.ResponseCache {
+	llmCfg := config.GetLLM()
+	if llmCfg == nil {
+		return config.ResponseCache{}
+	}
+	return llmCfg.GetResponseCache()
+}
+
+// ResponseCache stores the responses to completion requests on disk, one
+// file per request keyed by a hash of the model, endpoint, sampling
+// parameters and messages. Identical requests within the TTL are answered
+// from the cache, which is trimmed to its size limit by dropping the least
+// recently written entries. It is safe for concurrent use, and processes
+// sharing the directory see each other's entries.
+type ResponseCache struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+	mu       sync.Mutex // Serializes trimming
+	now      func() time.Time
+	hits     atomic.Int64
+	misses   atomic.Int64
+}
+
+// NewResponseCache creates a cache as configured by cfg, creating its
+// directory if needed. Enabled is not checked.
+func NewResponseCache(cfg config.ResponseCache) (*ResponseCache, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = "~/.forge/cache/llm"
+	}
+	dir, err := workspace.ExpandHome(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create response cache directory: %w", err)
+	}
+
+	c := &ResponseCache{
+		dir:      dir,
+		ttl:      cfg.TTL,
+		maxBytes: int64(cfg.MaxSizeMB) << 20,
+		now:      time.Now,
+	}
+	if c.ttl == 0 {
+		c.ttl = DefaultResponseCacheTTL
+	}
+	if c.maxBytes == 0 {
+		c.maxBytes = DefaultResponseCacheMaxSizeMB << 20
+	}
+	return c, nil
+}
+
+// Dir returns the directory the cache is stored in.
+func (c *ResponseCache) Dir() string {
+	return c.dir
+}
+
+// Stats returns how many requests were answered from the cache and how many
+// were sent to the model.
+func (c *ResponseCache) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+var (
+	sharedResponseCacheMu sync.Mutex
+	sharedResponseCache   *ResponseCache
+)
+
+// SharedResponseCache returns the process-wide response cache used for
+// summarization and commit and PR generation, or nil when caching is off.
+func SharedResponseCache() *ResponseCache {
+	sharedResponseCacheMu.Lock()
+	defer sharedResponseCacheMu.Unlock()
+	return sharedResponseCache
+}
+
+// SetSharedResponseCache turns response caching on for the deterministic
+// calls made from now on, or off when c is nil.
+func SetSharedResponseCache(c *ResponseCache) {
+	sharedResponseCacheMu.Lock()
+	defer sharedResponseCacheMu.Unlock()
+	sharedResponseCache = c
+}
+
+// responseCacheEntry is a cached response as written to its file.
+type responseCacheEntry struct {
+	Time    time.Time `json:"time"`
+	Model   string    `json:"model"`
+	Role    string    `json:"role"`
+	Content string    `json:"content"`
+}
+
+// responseCacheKey is what a cached response is keyed by.
+type responseCacheKey struct {
+	Model    string                `json:"model"`
+	BaseURL  string                `json:"base_url"`
+	Sampling config.SamplingParams `json:"sampling"`
+	Messages []RequestLogMessage   `json:"messages"`
+}
+
+// key hashes a request. Requests with images are not cached, since only
+// their count would be hashed.
+func (c *ResponseCache) key(provider Provider, sampling config.SamplingParams, messages []*types.Message) (string, bool) {
+	key := responseCacheKey{Model: provider.GetModel(), BaseURL: provider.GetBaseURL(), Sampling: sampling}
+	for _, msg := range messages {
+		if msg == nil {
+			continue
+		}
+		if len(msg.Images) > 0 {
+			return "", false
+		}
+		key.Messages = append(key.Messages, RequestLogMessage{
+			Role:       string(msg.Role),
+			Content:    msg.Content,
+			ToolCalls:  requestLogToolCalls(msg.ToolCalls),
+			ToolCallID: msg.ToolCallID,
+		})
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// get returns the cached response for key, if there is one within the TTL.
+func (c *ResponseCache) get(key string) (*types.Message, bool) {
+	data, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var entry responseCacheEntry
+	if json.Unmarshal(data, &entry) != nil || c.now().Sub(entry.Time) > c.ttl {
+		return nil, false
+	}
+	return &types.Message{Role: types.MessageRole(entry.Role), Content: entry.Content}, true
+}
+
+// put caches response under key, then trims the cache. Failures are ignored:
+// the cache only saves cost and must never fail a request.
+func (c *ResponseCache) put(key, model string, response *types.Message) {
+	data, err := json.Marshal(responseCacheEntry{
+		Time:    c.now(),
+		Model:   model,
+		Role:    string(response.Role),
+		Content: response.Content,
+	})
+	if err != nil {
+		return
+	}
+
+	// Write through a temporary file so readers never see a partial entry
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(tmp.Name(), filepath.Join(c.dir, key+".json")) != nil {
+		_ = os.Remove(tmp.Name()) //nolint:errcheck // best effort
+		return
+	}
+	c.trim()
+}
+
+// trim removes expired entries, then the oldest ones until the cache fits
+// its size limit.
+func (c *ResponseCache) trim() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	type cached struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []cached
+	var total int64
+	now := c.now()
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(c.dir, entry.Name())
+		if now.Sub(info.ModTime()) > c.ttl {
+			_ = os.Remove(path) //nolint:errcheck // best effort
+			continue
+		}
+		files = append(files, cached{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+	if total <= c.maxBytes {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, file := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		if os.Remove(file.path) == nil {
+			total -= file.size
+		}
+	}
+}
+
+// ResponseCacheProvider is a Provider that answers Complete from a
+// ResponseCache when it can. Streaming requests, which drive the agent loop,
+// are passed through uncached.
+type ResponseCacheProvider struct {
+	provider Provider
+	cache    *ResponseCache
+	sampling config.SamplingParams // Sent with every request, so part of the key
+}
+
+// WithResponseCache returns provider answering completions from cache.
+// Provider is returned unchanged when cache is nil. Apply sampling
+// parameters to the result, not to provider, so they are part of the key.
+func WithResponseCache(provider Provider, cache *ResponseCache) Provider {
+	if cache == nil {
+		return provider
+	}
+	return &ResponseCacheProvider{provider: provider, cache: cache}
+}
+
+// Complete implements Provider. Successful text responses are cached;
+// responses with tool calls and empty ones are not.
+func (p *ResponseCacheProvider) Complete(ctx context.Context, messages []*types.Message) (*types.Message, error) {
+	key, ok := p.cache.key(p.provider, p.sampling, messages)
+	if !ok {
+		return p.provider.Complete(ctx, messages)
+	}
+	if response, hit := p.cache.get(key); hit {
+		p.cache.hits.Add(1)
+		return response, nil
+	}
+
+	p.cache.misses.Add(1)
+	response, err := p.provider.Complete(ctx, messages)
+	if err == nil && response != nil && response.Content != "" && len(response.ToolCalls) == 0 {
+		p.cache.put(key, p.provider.GetModel(), response)
+	}
+	return response, err
+}
+
+// StreamCompletion implements Provider.
+func (p *ResponseCacheProvider) StreamCompletion(ctx context.Context, messages []*types.Message) (<-chan *StreamChunk, error) {
+	return p.provider.StreamCompletion(ctx, messages)
+}
+
+// StreamCompletionWithTools implements ToolCallingProvider when the wrapped
+// provider does.
+func (p *ResponseCacheProvider) StreamCompletionWithTools(ctx context.Context, messages []*types.Message, tools []ToolDefinition) (<-chan *StreamChunk, error) {
+	toolProvider, ok := p.provider.(ToolCallingProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider does not support native tool calling")
+	}
+	return toolProvider.StreamCompletionWithTools(ctx, messages, tools)
+}
+
+// AnalyzeDocument implements Provider.
+func (p *ResponseCacheProvider) AnalyzeDocument(ctx context.Context, fileData []byte, mediaType string, prompt string) (string, error) {
+	return p.provider.AnalyzeDocument(ctx, fileData, mediaType, prompt)
+}
+
+// GetModelInfo returns the wrapped provider's model info.
+func (p *ResponseCacheProvider) GetModelInfo() *types.ModelInfo {
+	return p.provider.GetModelInfo()
+}
+
+// GetModel returns the wrapped provider's model name.
+func (p *ResponseCacheProvider) GetModel() string {
+	return p.provider.GetModel()
+}
+
+// GetBaseURL returns the wrapped provider's base URL.
+func (p *ResponseCacheProvider) GetBaseURL() string {
+	return p.provider.GetBaseURL()
+}
+
+// GetAPIKey returns the wrapped provider's API key.
+func (p *ResponseCacheProvider) GetAPIKey() string {
+	return p.provider.GetAPIKey()
+}
+
+// CloneWithModel returns the wrapped provider switched to model, still
+// caching in the same cache. It implements ModelCloner.
+func (p *ResponseCacheProvider) CloneWithModel(model string) Provider {
+	cloner, ok := p.provider.(ModelCloner)
+	if !ok {
+		return p
+	}
+	return &ResponseCacheProvider{provider: cloner.CloneWithModel(model), cache: p.cache, sampling: p.sampling}
+}
+
+// CloneWithSampling returns the wrapped provider sending params, still
+// caching in the same cache. It implements SamplingCloner.
+func (p *ResponseCacheProvider) CloneWithSampling(params config.SamplingParams) Provider {
+	return &ResponseCacheProvider{provider: WithSampling(p.provider, params), cache: p.cache, sampling: params}
+}
@@ -0,0 +1,119 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+func newTestResponseCache(t *testing.T) *ResponseCache {
+	t.Helper()
+	cache, err := NewResponseCache(config.ResponseCache{Enabled: true, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewResponseCache failed: %v", err)
+	}
+	return cache
+}
+
+func TestResponseCacheProvider_ReusesResponses(t *testing.T) {
+	cache := newTestResponseCache(t)
+	inner := &scriptedProvider{model: "small"}
+	provider := WithResponseCache(inner, cache)
+	messages := []*types.Message{types.NewUserMessage("summarize this")}
+
+	for i := 0; i < 2; i++ {
+		message, err := provider.Complete(context.Background(), messages)
+		if err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+		if message.Content != "small" || message.Role != types.RoleAssistant {
+			t.Errorf("unexpected response %+v", message)
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected the second request to be answered from the cache, got %d calls", inner.calls)
+	}
+	if hits, misses := cache.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %d and %d", hits, misses)
+	}
+
+	// A different prompt, model or sampling is a different request
+	_, _ = provider.Complete(context.Background(), []*types.Message{types.NewUserMessage("something else")})
+	other := &scriptedProvider{model: "big"}
+	_, _ = WithResponseCache(other, cache).Complete(context.Background(), messages)
+	temperature := 0.5
+	_, _ = WithSampling(provider, config.SamplingParams{Temperature: &temperature}).Complete(context.Background(), messages)
+	if inner.calls != 3 || other.calls != 1 {
+		t.Errorf("expected every distinct request to reach the model, got %d and %d calls", inner.calls, other.calls)
+	}
+}
+
+func TestResponseCacheProvider_SkipsFailures(t *testing.T) {
+	cache := newTestResponseCache(t)
+	inner := &scriptedProvider{model: "small", err: &statusErr{status: 500}}
+	provider := WithResponseCache(inner, cache)
+	messages := []*types.Message{types.NewUserMessage("summarize this")}
+
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Complete(context.Background(), messages); err == nil {
+			t.Fatal("expected the provider error to be returned")
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("expected failures not to be cached, got %d calls", inner.calls)
+	}
+}
+
+func TestResponseCache_Expires(t *testing.T) {
+	cache := newTestResponseCache(t)
+	inner := &scriptedProvider{model: "small"}
+	provider := WithResponseCache(inner, cache)
+	messages := []*types.Message{types.NewUserMessage("summarize this")}
+
+	_, _ = provider.Complete(context.Background(), messages)
+	later := time.Now().Add(DefaultResponseCacheTTL + time.Hour)
+	cache.now = func() time.Time { return later }
+	_, _ = provider.Complete(context.Background(), messages)
+	if inner.calls != 2 {
+		t.Errorf("expected an expired response to be requested again, got %d calls", inner.calls)
+	}
+}
+
+func TestResponseCache_TrimsOldestEntries(t *testing.T) {
+	cache := newTestResponseCache(t)
+	message := types.NewAssistantMessage("summary")
+	cache.put("a", "small", message)
+	cache.put("b", "small", message)
+
+	info, err := os.Stat(filepath.Join(cache.Dir(), "a.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for key, age := range map[string]time.Duration{"a": 2 * time.Hour, "b": time.Hour} {
+		if err := os.Chtimes(filepath.Join(cache.Dir(), key+".json"), now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Room for two entries: the oldest goes when a third is written
+	cache.maxBytes = 2*info.Size() + info.Size()/2
+	cache.put("c", "small", message)
+	for key, want := range map[string]bool{"a": false, "b": true, "c": true} {
+		if _, ok := cache.get(key); ok != want {
+			t.Errorf("entry %s cached = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestWithResponseCache_Nil(t *testing.T) {
+	inner := &scriptedProvider{model: "small"}
+	if provider := WithResponseCache(inner, nil); provider != inner {
+		t.Error("expected the provider to be returned unchanged without a cache")
+	}
+}
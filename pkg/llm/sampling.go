@@ -0,0 +1,32 @@
+package llm
+
+import (
+	"github.com/entrhq/forge/pkg/config"
+)
+
+// SamplingCloner is an optional interface for providers that can return a
+// lightweight copy of themselves which sends the given sampling parameters with
+// every request. The params replace any previously configured on the provider.
+type SamplingCloner interface {
+	CloneWithSampling(params config.SamplingParams) Provider
+}
+
+// WithSampling returns provider configured to use params. Providers that do not
+// implement SamplingCloner are returned unchanged.
+func WithSampling(provider Provider, params config.SamplingParams) Provider {
+	if cloner, ok := provider.(SamplingCloner); ok {
+		return cloner.CloneWithSampling(params)
+	}
+	return provider
+}
+
+// SamplingFromConfig returns the sampling parameters configured for role in the
+// global LLM settings (see config.SamplingRoleAgent and friends). It returns the
+// zero value when configuration has not been initialized.
+func SamplingFromConfig(role string) config.SamplingParams {
+	llmCfg := config.GetLLM()
+	if llmCfg == nil {
+		return config.SamplingParams{}
+	}
+	return llmCfg.GetSampling(role)
+}
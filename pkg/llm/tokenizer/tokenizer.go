@@ -5,31 +5,129 @@ package tokenizer
 
 import (
 	"fmt"
+	"math"
+	"strings"
 	"sync"
 
 	"github.com/entrhq/forge/pkg/types"
 	tiktoken "github.com/pkoukk/tiktoken-go"
 )
 
+// Encodings used to count tokens.
+const (
+	EncodingCL100K = "cl100k_base" // GPT-4 and GPT-3.5
+	EncodingO200K  = "o200k_base"  // GPT-4o, GPT-4.1, GPT-5 and the o-series
+)
+
 // Tokenizer provides token counting functionality
 type Tokenizer struct {
 	encoding *tiktoken.Tiktoken
+	name     string  // Name of the encoding
+	scale    float64 // Multiplier applied to counts, for models whose tokenizer is not public
 	mu       sync.Mutex
 }
 
-// defaultEncoding is the encoding used for most modern models (GPT-4, Claude, etc.)
-const defaultEncoding = "cl100k_base"
+// defaultEncoding is the encoding used for models without a known tokenizer
+const defaultEncoding = EncodingCL100K
+
+// claudeScale approximates Anthropic's tokenizer, which is not public, from
+// cl100k counts: Claude models produce roughly 15% more tokens for the same
+// text. Provider-reported usage corrects what remains of the drift.
+const claudeScale = 1.15
+
+// tokensPerImage estimates what an attached image costs. Providers bill
+// images by resolution, roughly 800 to 1600 tokens for a screenshot.
+const tokensPerImage = 1000
+
+// modelEncoding is the tokenizer used for a family of models
+type modelEncoding struct {
+	name  string
+	scale float64
+}
+
+// modelEncodings maps model name prefixes to their tokenizer. The longest
+// matching prefix wins.
+var modelEncodings = map[string]modelEncoding{
+	"gpt-3.5":    {EncodingCL100K, 1},
+	"gpt-4":      {EncodingCL100K, 1},
+	"gpt-4o":     {EncodingO200K, 1},
+	"gpt-4.1":    {EncodingO200K, 1},
+	"gpt-4.5":    {EncodingO200K, 1},
+	"gpt-5":      {EncodingO200K, 1},
+	"chatgpt-4o": {EncodingO200K, 1},
+	"o1":         {EncodingO200K, 1},
+	"o3":         {EncodingO200K, 1},
+	"o4":         {EncodingO200K, 1},
+	"claude":     {EncodingCL100K, claudeScale},
+}
 
-// New creates a new Tokenizer instance
+// New creates a new Tokenizer instance using the default encoding
 func New() (*Tokenizer, error) {
-	encoding, err := tiktoken.GetEncoding(defaultEncoding)
+	return NewForModel("")
+}
+
+// NewForModel creates a Tokenizer that counts tokens the way model does, or
+// as closely as a public encoding allows. Unknown models use cl100k_base.
+func NewForModel(model string) (*Tokenizer, error) {
+	t := &Tokenizer{}
+	if err := t.SetModel(model); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SetModel switches the tokenizer to model's encoding. On error the previous
+// encoding is kept.
+func (t *Tokenizer) SetModel(model string) error {
+	enc := encodingForModel(model)
+	encoding, err := tiktoken.GetEncoding(enc.name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tiktoken encoding: %w", err)
+		return fmt.Errorf("failed to get tiktoken encoding: %w", err)
 	}
 
-	return &Tokenizer{
-		encoding: encoding,
-	}, nil
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.encoding = encoding
+	t.name = enc.name
+	t.scale = enc.scale
+	return nil
+}
+
+// Encoding returns the name of the encoding in use, with the scale applied to
+// its counts when there is one, e.g. "cl100k_base×1.15".
+func (t *Tokenizer) Encoding() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.scale != 1 {
+		return fmt.Sprintf("%s×%g", t.name, t.scale)
+	}
+	return t.name
+}
+
+// EncodingForModel returns the name of the encoding used to count model's
+// tokens.
+func EncodingForModel(model string) string {
+	return encodingForModel(model).name
+}
+
+// encodingForModel looks up model's tokenizer, ignoring any provider prefix
+// such as "openai/" or "anthropic/"
+func encodingForModel(model string) modelEncoding {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	var best string
+	for prefix := range modelEncodings {
+		if strings.HasPrefix(name, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return modelEncoding{defaultEncoding, 1}
+	}
+	return modelEncodings[best]
 }
 
 // CountTokens counts the number of tokens in the given text
@@ -41,8 +139,11 @@ func (t *Tokenizer) CountTokens(text string) int {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	tokens := t.encoding.Encode(text, nil, nil)
-	return len(tokens)
+	tokens := len(t.encoding.Encode(text, nil, nil))
+	if t.scale != 1 {
+		return int(math.Ceil(float64(tokens) * t.scale))
+	}
+	return tokens
 }
 
 // CountMessageTokens counts tokens for a message with role overhead
@@ -59,6 +160,7 @@ func (t *Tokenizer) CountMessageTokens(message *types.Message) int {
 	count := tokensPerMessage
 	count += t.CountTokens(string(message.Role))
 	count += t.CountTokens(message.Content)
+	count += len(message.Images) * tokensPerImage
 
 	return count
 }
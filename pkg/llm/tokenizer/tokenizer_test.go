@@ -0,0 +1,35 @@
+package tokenizer
+
+import "testing"
+
+func TestEncodingForModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"gpt-4o", EncodingO200K},
+		{"gpt-4o-mini", EncodingO200K},
+		{"openai/gpt-4.1-nano", EncodingO200K},
+		{"gpt-5", EncodingO200K},
+		{"o3-mini", EncodingO200K},
+		{"gpt-4", EncodingCL100K},
+		{"gpt-4-turbo", EncodingCL100K},
+		{"gpt-3.5-turbo", EncodingCL100K},
+		{"anthropic/claude-sonnet-4", EncodingCL100K},
+		{"llama-3.1-70b", EncodingCL100K},
+		{"", EncodingCL100K},
+	}
+
+	for _, tt := range tests {
+		if got := EncodingForModel(tt.model); got != tt.want {
+			t.Errorf("EncodingForModel(%q) = %s, want %s", tt.model, got, tt.want)
+		}
+	}
+
+	if enc := encodingForModel("Claude-Haiku-4"); enc.scale != claudeScale {
+		t.Errorf("expected Claude counts to be scaled by %g, got %g", claudeScale, enc.scale)
+	}
+	if enc := encodingForModel("gpt-4o"); enc.scale != 1 {
+		t.Errorf("expected exact counts for gpt-4o, got scale %g", enc.scale)
+	}
+}
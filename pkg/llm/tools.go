@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/entrhq/forge/pkg/config"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+const (
+	// ToolCallModeXML has the model write tool calls as XML in its response
+	// text. It works with every provider and is the default.
+	ToolCallModeXML = config.ToolCallingXML
+
+	// ToolCallModeNative sends tool definitions through the provider's
+	// function-calling API and reads tool calls from its structured response.
+	ToolCallModeNative = config.ToolCallingNative
+)
+
+// ToolDefinition describes a tool offered to the model through a native
+// function-calling API.
+type ToolDefinition struct {
+	Name        string
+	Description string
+
+	// Parameters is the JSON Schema object for the tool's arguments.
+	Parameters map[string]any
+}
+
+// ToolCallingProvider is an optional interface for providers whose API
+// supports native function calling. Providers that implement it should also
+// set ModelInfo.SupportsToolCalling.
+type ToolCallingProvider interface {
+	// StreamCompletionWithTools behaves like StreamCompletion but offers tools
+	// to the model. Tool calls are reported in StreamChunk.ToolCalls; message
+	// content is streamed as usual.
+	StreamCompletionWithTools(ctx context.Context, messages []*types.Message, tools []ToolDefinition) (<-chan *StreamChunk, error)
+}
+
+// SupportsNativeToolCalls reports whether provider can be used in native tool
+// calling mode.
+func SupportsNativeToolCalls(provider Provider) bool {
+	if _, ok := provider.(ToolCallingProvider); !ok {
+		return false
+	}
+	info := provider.GetModelInfo()
+	return info != nil && info.SupportsToolCalling
+}
+
+// ToolCallModeFromConfig returns the tool calling mode configured in the global
+// LLM settings, defaulting to ToolCallModeXML.
+func ToolCallModeFromConfig() string {
+	llmCfg := config.GetLLM()
+	if llmCfg == nil || llmCfg.GetToolCalling() == "" {
+		return ToolCallModeXML
+	}
+	return llmCfg.GetToolCalling()
+}
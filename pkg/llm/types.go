@@ -1,5 +1,7 @@
 package llm
 
+import "github.com/entrhq/forge/pkg/types"
+
 // ContentType indicates the type of content in a StreamChunk.
 type ContentType string
 
@@ -54,6 +56,11 @@ type StreamChunk struct {
 	// This is typically only present in the final chunk (when Finished=true).
 	// May be nil if the provider doesn't support usage tracking or if it's not the final chunk.
 	Usage *UsageInfo
+
+	// ToolCalls contains the native tool calls the model made.
+	// Only set by StreamCompletionWithTools, typically on the final chunk once
+	// the streamed call arguments are complete.
+	ToolCalls []types.ToolCall
 }
 
 // IsError returns true if this chunk contains an error.
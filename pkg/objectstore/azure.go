@@ -0,0 +1,236 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// azureVersion is the Blob service REST API version requests and SAS tokens
+// use.
+const azureVersion = "2021-08-06"
+
+// AzureStore stores objects as block blobs in an Azure Blob Storage
+// container, signing requests with the storage account's shared key.
+type AzureStore struct {
+	client    *http.Client
+	account   string
+	key       []byte
+	container string
+	base      *url.URL // The container's URL; blob names are paths below it
+	now       func() time.Time
+}
+
+func newAzureStore(cfg Config) (*AzureStore, error) {
+	key, err := base64.StdEncoding.DecodeString(cfg.AccountKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure storage key: %w", err)
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", cfg.Account)
+	}
+	base, err := url.Parse(strings.TrimSuffix(endpoint, "/") + "/" + cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	return &AzureStore{
+		client:    newHTTPClient(),
+		account:   cfg.Account,
+		key:       key,
+		container: cfg.Bucket,
+		base:      base,
+		now:       time.Now,
+	}, nil
+}
+
+// Put uploads body as the blob key.
+func (s *AzureStore) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.blobURL(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, len(body))
+
+	_, err = do(s.client, req)
+	return err
+}
+
+// enumerationResults is the response of List Blobs
+type enumerationResults struct {
+	Blobs []struct {
+		Name         string `xml:"Name"`
+		LastModified string `xml:"Properties>Last-Modified"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// List returns the blobs whose names start with prefix.
+func (s *AzureStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	marker := ""
+	for {
+		u := *s.base
+		query := url.Values{"restype": {"container"}, "comp": {"list"}, "prefix": {prefix}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		u.RawQuery = query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		s.sign(req, 0)
+
+		data, err := do(s.client, req)
+		if err != nil {
+			return nil, err
+		}
+		var result enumerationResults
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse blob listing: %w", err)
+		}
+		for _, blob := range result.Blobs {
+			modified, err := http.ParseTime(blob.LastModified)
+			if err != nil {
+				return nil, fmt.Errorf("invalid modification time of %s: %w", blob.Name, err)
+			}
+			objects = append(objects, Object{Key: blob.Name, LastModified: modified})
+		}
+
+		if result.NextMarker == "" {
+			return objects, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// Delete removes the blob key.
+func (s *AzureStore) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.blobURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	s.sign(req, 0)
+
+	_, err = do(s.client, req)
+	return err
+}
+
+// SignedURL returns a URL with a read-only service SAS for the blob key that
+// is valid until expiry has passed.
+func (s *AzureStore) SignedURL(key string, expiry time.Duration) (string, error) {
+	if err := checkExpiry(expiry); err != nil {
+		return "", err
+	}
+
+	signedExpiry := s.now().UTC().Add(expiry).Format(time.RFC3339)
+	resource := "/blob/" + s.account + "/" + s.container + "/" + key
+	stringToSign := strings.Join([]string{
+		"r",                // signedPermissions
+		"",                 // signedStart
+		signedExpiry,       // signedExpiry
+		resource,           // canonicalizedResource
+		"",                 // signedIdentifier
+		"",                 // signedIP
+		"",                 // signedProtocol
+		azureVersion,       // signedVersion
+		"b",                // signedResource
+		"",                 // signedSnapshotTime
+		"",                 // signedEncryptionScope
+		"", "", "", "", "", // Response header overrides
+	}, "\n")
+
+	query := url.Values{
+		"sv":  {azureVersion},
+		"sr":  {"b"},
+		"sp":  {"r"},
+		"se":  {signedExpiry},
+		"sig": {s.signature(stringToSign)},
+	}
+	u := s.blobURL(key)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// blobURL returns the URL of the blob key.
+func (s *AzureStore) blobURL(key string) *url.URL {
+	u := *s.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	u.RawPath = escapePath(u.Path)
+	return &u
+}
+
+// sign adds the date, version and Shared Key Authorization headers to req,
+// whose body is contentLength bytes long.
+func (s *AzureStore) sign(req *http.Request, contentLength int) {
+	req.Header.Set("x-ms-date", s.now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureVersion)
+
+	length := ""
+	if contentLength > 0 {
+		length = strconv.Itoa(contentLength)
+	}
+
+	var msHeaders []string
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower)
+		}
+	}
+	sort.Strings(msHeaders)
+	var canonicalHeaders strings.Builder
+	for _, name := range msHeaders {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+
+	resource := "/" + s.account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		"", // Content-Encoding
+		"", // Content-Language
+		length,
+		"", // Content-MD5
+		req.Header.Get("Content-Type"),
+		"", // Date, sent as x-ms-date
+		"", // If-Modified-Since
+		"", // If-Match
+		"", // If-None-Match
+		"", // If-Unmodified-Since
+		"", // Range
+		canonicalHeaders.String() + resource,
+	}, "\n")
+
+	req.Header.Set("Authorization", "SharedKey "+s.account+":"+s.signature(stringToSign))
+}
+
+func (s *AzureStore) signature(stringToSign string) string {
+	return base64.StdEncoding.EncodeToString(hmacSHA256(s.key, stringToSign))
+}
@@ -0,0 +1,105 @@
+package objectstore
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeContainer is a minimal Blob Storage container named "container"
+type fakeContainer struct {
+	mu    sync.Mutex
+	blobs map[string]string
+}
+
+func (c *fakeContainer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey acct:") || r.Header.Get("x-ms-version") != azureVersion {
+		http.Error(w, "unsigned request", http.StatusForbidden)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := strings.TrimPrefix(r.URL.Path, "/container/")
+	switch {
+	case r.Method == http.MethodPut:
+		if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+			http.Error(w, "missing blob type", http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		c.blobs[name] = string(data)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete:
+		delete(c.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	case r.URL.Query().Get("comp") == "list":
+		var listing strings.Builder
+		listing.WriteString("<EnumerationResults><Blobs>")
+		for n := range c.blobs {
+			if strings.HasPrefix(n, r.URL.Query().Get("prefix")) {
+				listing.WriteString("<Blob><Name>" + n + "</Name><Properties><Last-Modified>Tue, 02 Jan 2024 03:04:05 GMT</Last-Modified></Properties></Blob>")
+			}
+		}
+		listing.WriteString("</Blobs><NextMarker /></EnumerationResults>")
+		_, _ = io.WriteString(w, listing.String())
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestAzureStore_RoundTrip(t *testing.T) {
+	container := &fakeContainer{blobs: map[string]string{}}
+	server := httptest.NewServer(container)
+	defer server.Close()
+
+	key := base64.StdEncoding.EncodeToString([]byte("account key"))
+	store, err := New(Config{Provider: ProviderAzure, Bucket: "container", Endpoint: server.URL, Account: "acct", AccountKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "runs/1/execution.json", []byte("{}"), "application/json"); err != nil {
+		t.Fatal(err)
+	}
+	objects, err := store.List(ctx, "runs/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Key != "runs/1/execution.json" || objects[0].LastModified.Year() != 2024 {
+		t.Errorf("objects = %+v", objects)
+	}
+	if err := store.Delete(ctx, "runs/1/execution.json"); err != nil {
+		t.Fatal(err)
+	}
+	if len(container.blobs) != 0 {
+		t.Errorf("blobs = %v", container.blobs)
+	}
+
+	signed, err := store.SignedURL("runs/1/execution.json", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if u.Path != "/container/runs/1/execution.json" || query.Get("sp") != "r" || query.Get("sr") != "b" || query.Get("sig") == "" {
+		t.Errorf("signed URL = %s", signed)
+	}
+}
+
+func TestAzureStore_InvalidKey(t *testing.T) {
+	if _, err := New(Config{Provider: ProviderAzure, Bucket: "c", Account: "acct", AccountKey: "not base64!"}); err == nil {
+		t.Error("expected an invalid account key to be rejected")
+	}
+}
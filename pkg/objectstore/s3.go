@@ -0,0 +1,318 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4DateFormat = "20060102T150405Z"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// S3Store stores objects in an S3 bucket, or in a GCS bucket through the
+// XML API's S3 interoperability, signing requests with AWS Signature V4.
+type S3Store struct {
+	client *http.Client
+	signer sigV4
+	base   *url.URL // The bucket's URL; object keys are paths below it
+	now    func() time.Time
+}
+
+func newS3Store(cfg Config) (*S3Store, error) {
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+		if cfg.Provider == ProviderGCS {
+			region = "auto"
+		}
+	}
+
+	// Custom endpoints and GCS take the bucket in the path; AWS in the host
+	var rawURL string
+	switch {
+	case cfg.Endpoint != "":
+		rawURL = strings.TrimSuffix(cfg.Endpoint, "/") + "/" + cfg.Bucket
+	case cfg.Provider == ProviderGCS:
+		rawURL = "https://storage.googleapis.com/" + cfg.Bucket
+	default:
+		rawURL = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", cfg.Bucket, region)
+	}
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	return &S3Store{
+		client: newHTTPClient(),
+		signer: sigV4{
+			accessKeyID:     cfg.AccessKeyID,
+			secretAccessKey: cfg.SecretAccessKey,
+			sessionToken:    cfg.SessionToken,
+			region:          region,
+		},
+		base: base,
+		now:  time.Now,
+	}, nil
+}
+
+// Put uploads body as the object key.
+func (s *S3Store) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	sum := sha256.Sum256(body)
+	s.signer.sign(req, hex.EncodeToString(sum[:]), s.now())
+
+	_, err = do(s.client, req)
+	return err
+}
+
+// listBucketResult is the response of ListObjectsV2
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the objects whose keys start with prefix.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		u := *s.base
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u.RawQuery = canonicalQuery(query)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		s.signer.sign(req, emptyPayloadHash, s.now())
+
+		data, err := do(s.client, req)
+		if err != nil {
+			return nil, err
+		}
+		var result listBucketResult
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse object listing: %w", err)
+		}
+		for _, content := range result.Contents {
+			objects = append(objects, Object{Key: content.Key, LastModified: content.LastModified})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Delete removes the object key.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	s.signer.sign(req, emptyPayloadHash, s.now())
+
+	_, err = do(s.client, req)
+	return err
+}
+
+// SignedURL returns a presigned URL that downloads key until expiry has
+// passed.
+func (s *S3Store) SignedURL(key string, expiry time.Duration) (string, error) {
+	if err := checkExpiry(expiry); err != nil {
+		return "", err
+	}
+	return s.signer.presign(s.objectURL(key), expiry, s.now()), nil
+}
+
+// objectURL returns the URL of the object key.
+func (s *S3Store) objectURL(key string) *url.URL {
+	u := *s.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	u.RawPath = escapePath(u.Path)
+	return &u
+}
+
+// emptyPayloadHash is the SHA-256 of an empty body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// sigV4 signs requests with AWS Signature Version 4 for the s3 service.
+type sigV4 struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	region          string
+}
+
+// sign adds the date, payload hash and Authorization headers to req. Every
+// header already on req is signed, along with the host.
+func (s sigV4) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(sigV4DateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(now)
+	signature := s.signature(now, s.stringToSign(amzDate, scope, canonicalRequest))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.accessKeyID, scope, signedHeaders, signature))
+}
+
+// presign returns u with query parameters authorizing a GET of it until
+// expiry has passed.
+func (s sigV4) presign(u *url.URL, expiry time.Duration, now time.Time) string {
+	amzDate := now.UTC().Format(sigV4DateFormat)
+	scope := s.scope(now)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", s.accessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if s.sessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		canonicalURI(u),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	signed := *u
+	signed.RawQuery = canonicalQuery(query) + "&X-Amz-Signature=" + s.signature(now, s.stringToSign(amzDate, scope, canonicalRequest))
+	return signed.String()
+}
+
+func (s sigV4) scope(now time.Time) string {
+	return now.UTC().Format("20060102") + "/" + s.region + "/s3/aws4_request"
+}
+
+func (s sigV4) stringToSign(amzDate, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	return sigV4Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+}
+
+func (s sigV4) signature(now time.Time, stringToSign string) string {
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), now.UTC().Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalURI returns the escaped path of u, "/" when it is empty.
+func canonicalURI(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	return escapePath(u.Path)
+}
+
+// canonicalQuery encodes query sorted by key, escaping as SigV4 requires.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, escape(key)+"="+escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escapePath percent-encodes everything in path but unreserved characters
+// and slashes.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escape percent-encodes everything in s but the RFC 3986 unreserved
+// characters.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
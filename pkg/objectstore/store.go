@@ -0,0 +1,151 @@
+// Package objectstore uploads files to cloud object storage: Amazon S3 and
+// S3-compatible services, Google Cloud Storage through its XML API with HMAC
+// keys, and Azure Blob Storage. Requests are signed with the providers'
+// shared-key schemes, so no cloud SDK is needed.
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Supported object storage providers
+const (
+	ProviderS3    = "s3"
+	ProviderGCS   = "gcs"
+	ProviderAzure = "azure"
+)
+
+// maxURLExpiry is the longest lifetime a signed URL can have; SigV4
+// presigned URLs are capped at a week.
+const maxURLExpiry = 7 * 24 * time.Hour
+
+// Object is a stored object
+type Object struct {
+	Key          string
+	LastModified time.Time
+}
+
+// Store reads and writes the objects of a bucket (a container on Azure)
+type Store interface {
+	// Put uploads body as the object key
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+
+	// List returns the objects whose keys start with prefix
+	List(ctx context.Context, prefix string) ([]Object, error)
+
+	// Delete removes the object key
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL returns a URL that downloads key without credentials until
+	// expiry has passed
+	SignedURL(key string, expiry time.Duration) (string, error)
+}
+
+// Config selects and configures a Store
+type Config struct {
+	Provider string // s3, gcs, or azure
+	Bucket   string // Bucket, or container on Azure
+	Region   string // S3 region (default: us-east-1)
+	Endpoint string // Service URL for S3-compatible services or the Azure emulator
+
+	// Credentials: an access key pair for S3 and GCS (HMAC keys), or the
+	// storage account and its key for Azure
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Account         string
+	AccountKey      string
+}
+
+// ConfigFromEnv fills in the credentials of cfg from the environment
+// variables conventionally holding them for its provider: AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN for S3, GCS_HMAC_ACCESS_ID and
+// GCS_HMAC_SECRET for GCS, and AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY
+// for Azure. Values already set are kept.
+func ConfigFromEnv(cfg Config) Config {
+	setFromEnv := func(value *string, envVar string) {
+		if *value == "" {
+			*value = os.Getenv(envVar)
+		}
+	}
+
+	switch cfg.Provider {
+	case ProviderS3:
+		setFromEnv(&cfg.AccessKeyID, "AWS_ACCESS_KEY_ID")
+		setFromEnv(&cfg.SecretAccessKey, "AWS_SECRET_ACCESS_KEY")
+		setFromEnv(&cfg.SessionToken, "AWS_SESSION_TOKEN")
+		setFromEnv(&cfg.Region, "AWS_REGION")
+	case ProviderGCS:
+		setFromEnv(&cfg.AccessKeyID, "GCS_HMAC_ACCESS_ID")
+		setFromEnv(&cfg.SecretAccessKey, "GCS_HMAC_SECRET")
+	case ProviderAzure:
+		setFromEnv(&cfg.Account, "AZURE_STORAGE_ACCOUNT")
+		setFromEnv(&cfg.AccountKey, "AZURE_STORAGE_KEY")
+	}
+	return cfg
+}
+
+// New creates the Store for cfg.Provider.
+func New(cfg Config) (Store, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("object storage requires a bucket")
+	}
+
+	switch cfg.Provider {
+	case ProviderS3, ProviderGCS:
+		if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+			return nil, fmt.Errorf("%s requires an access key ID and secret", cfg.Provider)
+		}
+		return newS3Store(cfg)
+	case ProviderAzure:
+		if cfg.Account == "" || cfg.AccountKey == "" {
+			return nil, fmt.Errorf("azure requires a storage account and key")
+		}
+		return newAzureStore(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported object storage provider: %s (must be 's3', 'gcs', or 'azure')", cfg.Provider)
+	}
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: 2 * time.Minute}
+}
+
+// do sends req and returns the response body, or an error for a response
+// that is not a success.
+func do(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, truncate(string(data), 300))
+	}
+	return data, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// checkExpiry rejects signed URL lifetimes the providers do not accept.
+func checkExpiry(expiry time.Duration) error {
+	if expiry <= 0 || expiry > maxURLExpiry {
+		return fmt.Errorf("signed URL expiry must be between 1s and %s", maxURLExpiry)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Package offline implements offline mode, in which Forge runs without
+// network access for air-gapped environments using local models. Providers
+// must be served from this machine or a private network, network-touching
+// tools are not registered, and anything a run needs the network for fails
+// before the run starts instead of partway through it.
+package offline
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Instructions tell the agent it has no network access, so it reports tasks
+// that need the network instead of attempting them.
+const Instructions = `# Offline Mode
+
+Forge is running offline: there is no network access. Browser tools are unavailable, and shell commands that download packages, clone repositories, or call remote APIs will fail. Work only with the files and tools already available in the workspace. If the task cannot be completed without network access, stop and report which step needs the network instead of attempting it.`
+
+// IsLocalURL reports whether rawURL points at this machine or a private
+// network: localhost, a loopback, private, or link-local IP address, or a
+// host name ending in .local, .localhost, or .internal.
+func IsLocalURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return isLocalHost(u.Hostname())
+}
+
+func isLocalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" {
+		return true
+	}
+	for _, suffix := range []string{".local", ".localhost", ".internal"} {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
+}
+
+// CheckEndpoint returns an actionable error when the endpoint used for what
+// (e.g. "LLM provider") is not local. hint says where the URL is configured.
+func CheckEndpoint(what, rawURL, hint string) error {
+	if IsLocalURL(rawURL) {
+		return nil
+	}
+	return fmt.Errorf("offline mode: the %s at %s is not on this machine or a private network; "+
+		"point %s at a local server (for example http://localhost:11434/v1 for Ollama) or run without -offline",
+		what, rawURL, hint)
+}
+
+// Report records the capabilities offline mode turned off, for the startup
+// banner.
+type Report struct {
+	disabled []string
+}
+
+// Disable records that capability is unavailable, with the reason why.
+func (r *Report) Disable(capability, reason string) {
+	r.disabled = append(r.disabled, fmt.Sprintf("%s (%s)", capability, reason))
+}
+
+// Disabled returns the recorded capabilities and reasons.
+func (r *Report) Disabled() []string {
+	return r.disabled
+}
+
+// Banner renders the capability banner shown when a run starts offline.
+func (r *Report) Banner() string {
+	var b strings.Builder
+	b.WriteString("Offline mode: network access disabled\n")
+	for _, item := range r.disabled {
+		fmt.Fprintf(&b, "  ✗ %s\n", item)
+	}
+	b.WriteString("  ! Shell commands are not sandboxed and will fail if they need the network\n")
+	return b.String()
+}
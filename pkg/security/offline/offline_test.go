@@ -0,0 +1,59 @@
+package offline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsLocalURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://localhost:11434/v1", true},
+		{"http://127.0.0.1:8080/v1", true},
+		{"http://[::1]:8080/v1", true},
+		{"http://192.168.1.20:8000/v1", true},
+		{"http://10.0.0.5/v1", true},
+		{"http://gpu-box.local:8000/v1", true},
+		{"http://models.internal/v1", true},
+		{"https://api.openai.com/v1", false},
+		{"https://openrouter.ai/api/v1", false},
+		{"http://8.8.8.8/v1", false},
+		{"not a url", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsLocalURL(tt.url); got != tt.want {
+			t.Errorf("IsLocalURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	if err := CheckEndpoint("LLM provider", "http://localhost:11434/v1", "-base-url"); err != nil {
+		t.Errorf("CheckEndpoint() local error = %v", err)
+	}
+
+	err := CheckEndpoint("LLM provider", "https://api.openai.com/v1", "-base-url")
+	if err == nil {
+		t.Fatal("expected error for remote endpoint")
+	}
+	if !strings.Contains(err.Error(), "api.openai.com") || !strings.Contains(err.Error(), "-base-url") {
+		t.Errorf("error should name the endpoint and where to change it: %v", err)
+	}
+}
+
+func TestReport_Banner(t *testing.T) {
+	report := &Report{}
+	report.Disable("Browser tools", "they load remote pages")
+
+	banner := report.Banner()
+	if !strings.Contains(banner, "Offline mode") || !strings.Contains(banner, "Browser tools (they load remote pages)") {
+		t.Errorf("unexpected banner:\n%s", banner)
+	}
+	if len(report.Disabled()) != 1 {
+		t.Errorf("Disabled() = %v, want one entry", report.Disabled())
+	}
+}
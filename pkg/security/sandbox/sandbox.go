@@ -0,0 +1,210 @@
+// Package sandbox runs model-chosen shell commands isolated from the host:
+// inside a Docker or Podman container, or under bubblewrap on Linux. The
+// workspace and any directories added with Mount are bind-mounted at their
+// host paths, so paths in command output match the rest of the session, and
+// the network is off unless enabled.
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Supported sandbox backends
+const (
+	BackendNone       = "none"
+	BackendDocker     = "docker"
+	BackendPodman     = "podman"
+	BackendBubblewrap = "bubblewrap"
+)
+
+// DefaultImage is the container image used when none is configured
+const DefaultImage = "debian:stable-slim"
+
+// Config selects and configures the sandbox for execute_command.
+//
+// Example headless YAML:
+//
+//	sandbox:
+//	  backend: docker
+//	  image: golang:1.24
+//	  network: false
+type Config struct {
+	Backend string `yaml:"backend" json:"backend"` // none (default), docker, podman, or bubblewrap
+	Image   string `yaml:"image" json:"image"`     // Container image (default: DefaultImage)
+	Network bool   `yaml:"network" json:"network"` // Allow network access (default: false)
+}
+
+// Enabled reports whether commands run in a sandbox.
+func (c Config) Enabled() bool {
+	return c.Backend != "" && c.Backend != BackendNone
+}
+
+// Validate checks the backend name and backend-specific settings.
+func (c Config) Validate() error {
+	switch c.Backend {
+	case "", BackendNone, BackendDocker, BackendPodman:
+	case BackendBubblewrap:
+		if c.Image != "" {
+			return fmt.Errorf("sandbox.image is not used by the bubblewrap backend, which runs commands on the host's filesystem")
+		}
+	default:
+		return fmt.Errorf("invalid sandbox backend: %s (must be 'none', 'docker', 'podman', or 'bubblewrap')", c.Backend)
+	}
+	return nil
+}
+
+// Sandbox wraps shell commands so they run isolated from the host
+type Sandbox struct {
+	config       Config
+	runtime      string // Path of the docker, podman, or bwrap binary
+	workspaceDir string
+	mounts       []mount // Directories besides the workspace, such as workspace roots
+}
+
+// mount is a host directory bind-mounted into the sandbox at the same path
+type mount struct {
+	dir      string
+	readOnly bool
+}
+
+// New creates the sandbox described by cfg for workspaceDir. It returns
+// (nil, nil) when cfg does not enable a sandbox, and an error when the
+// backend's binary is not installed, so a run fails before any command
+// would fall back to the host.
+func New(cfg Config, workspaceDir string) (*Sandbox, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	binary := cfg.Backend
+	if cfg.Backend == BackendBubblewrap {
+		if runtime.GOOS != "linux" {
+			return nil, fmt.Errorf("the bubblewrap sandbox is only available on Linux")
+		}
+		binary = "bwrap"
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("sandbox backend %s requires %s, which was not found in PATH", cfg.Backend, binary)
+	}
+
+	if cfg.Image == "" && cfg.Backend != BackendBubblewrap {
+		cfg.Image = DefaultImage
+	}
+
+	return &Sandbox{config: cfg, runtime: path, workspaceDir: workspaceDir}, nil
+}
+
+// Mount makes dir, such as a workspace root or allowed directory, available
+// in the sandbox at its host path, read-only if readOnly is set. Directories
+// that do not exist when a command starts are left out, so a container
+// runtime does not create them.
+func (s *Sandbox) Mount(dir string, readOnly bool) {
+	s.mounts = append(s.mounts, mount{dir: dir, readOnly: readOnly})
+}
+
+// existingMounts returns the mounts whose directories exist
+func (s *Sandbox) existingMounts() []mount {
+	var existing []mount
+	for _, m := range s.mounts {
+		if info, err := os.Stat(m.dir); err == nil && info.IsDir() {
+			existing = append(existing, m)
+		}
+	}
+	return existing
+}
+
+// Command returns a command that runs shell in the sandbox with workDir as
+// its working directory. Call cleanup once the command has finished or been
+// canceled; for containers it removes one that outlived its CLI process.
+func (s *Sandbox) Command(ctx context.Context, workDir, shell string) (cmd *exec.Cmd, cleanup func()) {
+	if s.config.Backend == BackendBubblewrap {
+		return exec.CommandContext(ctx, s.runtime, s.bubblewrapArgs(workDir, shell)...), func() {}
+	}
+
+	name := "forge-" + uuid.NewString()[:8]
+	cmd = exec.CommandContext(ctx, s.runtime, s.containerArgs(name, workDir, shell)...)
+	cleanup = func() {
+		// Killing the CLI on cancel or timeout does not stop the container
+		rmCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = exec.CommandContext(rmCtx, s.runtime, "rm", "-f", name).Run()
+	}
+	return cmd, cleanup
+}
+
+func (s *Sandbox) containerArgs(name, workDir, shell string) []string {
+	args := []string{"run", "--rm", "-i", "--name", name,
+		"-v", s.workspaceDir + ":" + s.workspaceDir,
+		"-w", workDir,
+		// The image's user may not be able to write to HOME
+		"-e", "HOME=/tmp",
+	}
+	for _, m := range s.existingMounts() {
+		volume := m.dir + ":" + m.dir
+		if m.readOnly {
+			volume += ":ro"
+		}
+		args = append(args, "-v", volume)
+	}
+	if !s.config.Network {
+		args = append(args, "--network", "none")
+	}
+	// Run as the host user so files written to the workspace keep their owner
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 && gid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, gid))
+	}
+	return append(args, s.config.Image, "sh", "-c", shell)
+}
+
+func (s *Sandbox) bubblewrapArgs(workDir, shell string) []string {
+	args := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+		"--bind", s.workspaceDir, s.workspaceDir,
+	}
+	// The host's filesystem is already readable, so only writable mounts
+	// need binding
+	for _, m := range s.existingMounts() {
+		if !m.readOnly {
+			args = append(args, "--bind", m.dir, m.dir)
+		}
+	}
+	args = append(args,
+		"--chdir", workDir,
+		"--unshare-all",
+		"--die-with-parent",
+	)
+	if s.config.Network {
+		args = append(args, "--share-net")
+	}
+	return append(args, "--", "sh", "-c", shell)
+}
+
+// Describe summarizes the sandbox for logs, e.g.
+// "docker (golang:1.24, no network)".
+func (s *Sandbox) Describe() string {
+	var details []string
+	if s.config.Image != "" {
+		details = append(details, s.config.Image)
+	}
+	if s.config.Network {
+		details = append(details, "network enabled")
+	} else {
+		details = append(details, "no network")
+	}
+	return fmt.Sprintf("%s (%s)", s.config.Backend, strings.Join(details, ", "))
+}
@@ -0,0 +1,161 @@
+package sandbox
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"empty", Config{}, false},
+		{"none", Config{Backend: BackendNone}, false},
+		{"docker", Config{Backend: BackendDocker, Image: "golang:1.24"}, false},
+		{"podman", Config{Backend: BackendPodman}, false},
+		{"bubblewrap", Config{Backend: BackendBubblewrap}, false},
+		{"bubblewrap with image", Config{Backend: BackendBubblewrap, Image: "golang:1.24"}, true},
+		{"unknown", Config{Backend: "firejail"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewDisabled(t *testing.T) {
+	sb, err := New(Config{Backend: BackendNone}, "/work")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if sb != nil {
+		t.Errorf("New() = %v, want nil for the none backend", sb)
+	}
+}
+
+func TestNewMissingRuntime(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	if _, err := New(Config{Backend: BackendDocker}, "/work"); err == nil {
+		t.Fatal("New() should fail when docker is not installed")
+	}
+}
+
+func TestContainerCommand(t *testing.T) {
+	sb := &Sandbox{
+		config:       Config{Backend: BackendDocker, Image: DefaultImage},
+		runtime:      "/usr/bin/docker",
+		workspaceDir: "/work",
+	}
+
+	cmd, cleanup := sb.Command(context.Background(), "/work/sub", "go test ./...")
+	defer cleanup()
+
+	args := cmd.Args[1:]
+	if args[0] != "run" || !slices.Contains(args, "--rm") {
+		t.Errorf("args = %v, want a removed-on-exit container run", args)
+	}
+	assertFlag(t, args, "-v", "/work:/work")
+	assertFlag(t, args, "-w", "/work/sub")
+	assertFlag(t, args, "--network", "none")
+	if tail := args[len(args)-4:]; !slices.Equal(tail, []string{DefaultImage, "sh", "-c", "go test ./..."}) {
+		t.Errorf("args end with %v, want the image and shell command", tail)
+	}
+
+	i := slices.Index(args, "--name")
+	if i < 0 || !strings.HasPrefix(args[i+1], "forge-") {
+		t.Errorf("args = %v, want a forge- container name", args)
+	}
+}
+
+func TestContainerCommandWithNetwork(t *testing.T) {
+	sb := &Sandbox{
+		config:       Config{Backend: BackendPodman, Image: DefaultImage, Network: true},
+		runtime:      "/usr/bin/podman",
+		workspaceDir: "/work",
+	}
+
+	cmd, cleanup := sb.Command(context.Background(), "/work", "true")
+	defer cleanup()
+
+	if slices.Contains(cmd.Args, "--network") {
+		t.Errorf("args = %v, want no --network flag when network is enabled", cmd.Args)
+	}
+}
+
+func TestBubblewrapCommand(t *testing.T) {
+	sb := &Sandbox{
+		config:       Config{Backend: BackendBubblewrap},
+		runtime:      "/usr/bin/bwrap",
+		workspaceDir: "/work",
+	}
+
+	cmd, cleanup := sb.Command(context.Background(), "/work/sub", "make")
+	defer cleanup()
+
+	args := cmd.Args[1:]
+	if !slices.Contains(args, "--unshare-all") || slices.Contains(args, "--share-net") {
+		t.Errorf("args = %v, want all namespaces unshared", args)
+	}
+	assertFlag(t, args, "--chdir", "/work/sub")
+	i := slices.Index(args, "--bind")
+	if i < 0 || args[i+1] != "/work" || args[i+2] != "/work" {
+		t.Errorf("args = %v, want the workspace bound read-write", args)
+	}
+	if tail := args[len(args)-4:]; !slices.Equal(tail, []string{"--", "sh", "-c", "make"}) {
+		t.Errorf("args end with %v, want the shell command", tail)
+	}
+}
+
+func TestCommandMounts(t *testing.T) {
+	shared, cache := t.TempDir(), t.TempDir()
+	sb := &Sandbox{
+		config:       Config{Backend: BackendDocker, Image: DefaultImage},
+		runtime:      "/usr/bin/docker",
+		workspaceDir: "/work",
+	}
+	sb.Mount(shared, true)
+	sb.Mount(cache, false)
+	sb.Mount("/does/not/exist", false)
+
+	cmd, cleanup := sb.Command(context.Background(), shared, "ls")
+	defer cleanup()
+	if !slices.Contains(cmd.Args, shared+":"+shared+":ro") || !slices.Contains(cmd.Args, cache+":"+cache) {
+		t.Errorf("args = %v, want the shared directory mounted read-only and the cache read-write", cmd.Args)
+	}
+	if strings.Contains(strings.Join(cmd.Args, " "), "/does/not/exist") {
+		t.Errorf("args = %v, want the missing directory left out", cmd.Args)
+	}
+
+	sb.config = Config{Backend: BackendBubblewrap}
+	cmd, cleanup = sb.Command(context.Background(), cache, "ls")
+	defer cleanup()
+	args := strings.Join(cmd.Args, " ")
+	if !strings.Contains(args, "--bind "+cache+" "+cache) || strings.Contains(args, "--bind "+shared) {
+		t.Errorf("args = %v, want only the writable cache bound", cmd.Args)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	sb := &Sandbox{config: Config{Backend: BackendDocker, Image: "golang:1.24"}}
+	if got, want := sb.Describe(), "docker (golang:1.24, no network)"; got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+}
+
+func assertFlag(t *testing.T, args []string, flag, value string) {
+	t.Helper()
+	i := slices.Index(args, flag)
+	if i < 0 || i+1 >= len(args) || args[i+1] != value {
+		t.Errorf("args = %v, want %s %s", args, flag, value)
+	}
+}
@@ -0,0 +1,116 @@
+// Package secrets detects credentials in text. The same ruleset backs the
+// headless secret_scan quality gate, which blocks commits that add
+// credentials, and the LLM request log, which redacts them before writing
+// prompts to disk.
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"sort"
+)
+
+// Rule detects one kind of credential. The secret is the first capture group
+// of Pattern, or the whole match when it has none.
+type Rule struct {
+	ID      string
+	Pattern *regexp.Regexp
+	// MinEntropy rejects low-entropy matches such as placeholders; 0 disables it
+	MinEntropy float64
+}
+
+// Rules is the built-in ruleset, modeled on the gitleaks defaults. Specific
+// rules come before generic ones, so callers reporting a secret once can keep
+// the first rule that matched it.
+var Rules = []Rule{
+	{ID: "aws-access-key-id", Pattern: regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16})\b`)},
+	{ID: "aws-secret-access-key", Pattern: regexp.MustCompile(`(?i)aws.{0,20}secret.{0,20}[=:]\s*["']?([A-Za-z0-9/+]{40})\b`), MinEntropy: 3.5},
+	{ID: "github-token", Pattern: regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36})\b`)},
+	{ID: "github-fine-grained-token", Pattern: regexp.MustCompile(`\b(github_pat_[A-Za-z0-9_]{82})\b`)},
+	{ID: "gitlab-token", Pattern: regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20})\b`)},
+	{ID: "slack-token", Pattern: regexp.MustCompile(`\b(xox[abposr]-[A-Za-z0-9-]{10,})`)},
+	{ID: "slack-webhook", Pattern: regexp.MustCompile(`(https://hooks\.slack\.com/services/T[A-Z0-9]+/B[A-Z0-9]+/[A-Za-z0-9]+)`)},
+	{ID: "stripe-secret-key", Pattern: regexp.MustCompile(`\b((?:sk|rk)_live_[A-Za-z0-9]{24,})\b`)},
+	{ID: "google-api-key", Pattern: regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})\b`)},
+	{ID: "anthropic-api-key", Pattern: regexp.MustCompile(`\b(sk-ant-(?:api|admin)[0-9]{2}-[A-Za-z0-9_-]{80,})`)},
+	{ID: "openai-api-key", Pattern: regexp.MustCompile(`\b(sk-(?:proj-|svcacct-|admin-)?[A-Za-z0-9_-]{20,}T3BlbkFJ[A-Za-z0-9_-]{20,})`)},
+	{ID: "openai-project-key", Pattern: regexp.MustCompile(`\b(sk-(?:proj|svcacct|admin)-[A-Za-z0-9_-]{40,})`), MinEntropy: 3.5},
+	{ID: "npm-token", Pattern: regexp.MustCompile(`\b(npm_[A-Za-z0-9]{36})\b`)},
+	// A whole key block is matched when the text has one, so redaction hides
+	// the key and not just its header; a single line matches the header
+	{ID: "private-key", Pattern: regexp.MustCompile(`-----BEGIN[ A-Z0-9_-]{0,100}PRIVATE KEY(?: BLOCK)?-----(?:[\s\S]*?-----END[ A-Z0-9_-]{0,100}PRIVATE KEY(?: BLOCK)?-----)?`)},
+	{ID: "jwt", Pattern: regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`)},
+	{ID: "bearer-token", Pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]{16,}=*)`), MinEntropy: 3.5},
+	{ID: "generic-credential", Pattern: regexp.MustCompile(`(?i)(?:api_?key|secret|token|passw(?:or)?d|credentials?|auth)["']?\s*(?::=|[:=]|=>)\s*["']([^"'\s$]{12,})["']`), MinEntropy: 3.5},
+}
+
+// Match is a secret found in a text.
+type Match struct {
+	Rule   string
+	Secret string
+	Start  int // Byte offset of Secret in the text
+	End    int
+}
+
+// Find applies every rule to text and returns the secrets found, grouped by
+// rule in the order of Rules. A secret matched by several rules is returned
+// once per rule.
+func Find(text string) []Match {
+	var matches []Match
+	for _, rule := range Rules {
+		for _, loc := range rule.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) > 3 && loc[2] >= 0 && loc[3] > loc[2] {
+				start, end = loc[2], loc[3]
+			}
+			secret := text[start:end]
+			if rule.MinEntropy > 0 && Entropy(secret) < rule.MinEntropy {
+				continue
+			}
+			matches = append(matches, Match{Rule: rule.ID, Secret: secret, Start: start, End: end})
+		}
+	}
+	return matches
+}
+
+// Redact replaces every secret Find reports in text with replacement.
+func Redact(text, replacement string) string {
+	matches := Find(text)
+	if len(matches) == 0 {
+		return text
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+
+	redacted := make([]byte, 0, len(text))
+	pos := 0
+	for _, match := range matches {
+		if match.Start < pos {
+			// Overlaps the secret redacted last
+			pos = max(pos, match.End)
+			continue
+		}
+		redacted = append(redacted, text[pos:match.Start]...)
+		redacted = append(redacted, replacement...)
+		pos = match.End
+	}
+	redacted = append(redacted, text[pos:]...)
+	return string(redacted)
+}
+
+// Entropy returns the Shannon entropy of s in bits per character.
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var entropy float64
+	total := float64(len([]rune(s)))
+	for _, count := range counts {
+		p := float64(count) / total
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,126 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AllowedDir is a directory outside the workspace that tools may use by its
+// absolute path, such as the Go module cache or a schemas directory shared
+// between projects. Unlike a Root it has no name and no ignore rules of its
+// own, and it need not exist yet.
+type AllowedDir struct {
+	Path     string // Directory; "~" is expanded and relative paths are resolved against the workspace
+	ReadOnly bool   // Whether tools may only read the directory
+}
+
+// SetAllowedDirs replaces the guard's allow-listed directories. Each must be
+// outside the workspace and must not contain it. Neither the filesystem root
+// nor the home directory may be allowed as a whole, and nothing in a hidden
+// directory of the home directory, such as ~/.ssh or ~/.config, may be
+// allowed at all since those hold credentials. Directories that do not exist
+// are allowed, for caches created later.
+func (g *Guard) SetAllowedDirs(dirs []AllowedDir) error {
+	resolved := make([]AllowedDir, 0, len(dirs))
+	for _, d := range dirs {
+		dir, err := g.resolveAllowedDir(d.Path)
+		if err != nil {
+			return fmt.Errorf("allowed directory '%s': %w", d.Path, err)
+		}
+		for _, other := range resolved {
+			if other.Path == dir {
+				return fmt.Errorf("allowed directory '%s' is listed twice", d.Path)
+			}
+		}
+		resolved = append(resolved, AllowedDir{Path: dir, ReadOnly: d.ReadOnly})
+	}
+
+	g.allowedDirs = resolved
+	return nil
+}
+
+// AllowedDirs returns the guard's allow-listed directories with their
+// resolved absolute paths, in the order they were set.
+func (g *Guard) AllowedDirs() []AllowedDir {
+	return append([]AllowedDir(nil), g.allowedDirs...)
+}
+
+// resolveAllowedDir expands, resolves and checks an allowed directory.
+func (g *Guard) resolveAllowedDir(dir string) (string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return "", fmt.Errorf("directory cannot be empty")
+	}
+	dir, err := ExpandHome(dir)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(g.workspaceDir, dir)
+	}
+	evalPath := resolveWhitelistPath(filepath.Clean(dir))
+
+	if filepath.Dir(evalPath) == evalPath {
+		return "", fmt.Errorf("the filesystem root cannot be allowed")
+	}
+	if homeDir, homeErr := os.UserHomeDir(); homeErr == nil {
+		evalHome := resolveWhitelistPath(homeDir)
+		if evalPath == evalHome {
+			return "", fmt.Errorf("the home directory cannot be allowed as a whole")
+		}
+		if rel, err := filepath.Rel(evalHome, evalPath); err == nil && isWithinDir(evalPath, evalHome) && strings.HasPrefix(rel, ".") {
+			return "", fmt.Errorf("hidden directories in the home directory cannot be allowed")
+		}
+	}
+	if isWithinDir(evalPath, g.workspaceDir) {
+		return "", fmt.Errorf("directory is inside the workspace")
+	}
+	if isWithinDir(g.workspaceDir, evalPath) {
+		return "", fmt.Errorf("directory contains the workspace")
+	}
+	if info, err := os.Stat(evalPath); err == nil && !info.IsDir() {
+		return "", fmt.Errorf("not a directory")
+	}
+	return evalPath, nil
+}
+
+// AllowedDirFor returns the allow-listed directory containing absPath, if
+// absPath is outside the workspace and its roots. Whitelisted directories
+// (see AddWhitelist) are reported as read-write allowed directories.
+func (g *Guard) AllowedDirFor(absPath string) (AllowedDir, bool) {
+	return g.allowedDirFor(g.resolveSymlinks(absPath))
+}
+
+// allowedDirFor is AllowedDirFor for an evaluated absolute path.
+func (g *Guard) allowedDirFor(evalPath string) (AllowedDir, bool) {
+	if isWithinDir(evalPath, g.workspaceDir) || g.rootFor(evalPath) != nil {
+		return AllowedDir{}, false
+	}
+	for _, dir := range g.allowedDirs {
+		if isWithinDir(evalPath, dir.Path) {
+			return dir, true
+		}
+	}
+	for _, dir := range g.whitelistedDirs {
+		if isWithinDir(evalPath, dir) {
+			return AllowedDir{Path: dir}, true
+		}
+	}
+	return AllowedDir{}, false
+}
+
+// DescribeExternalAccess describes a path in an allow-listed directory for
+// approval previews, such as "/home/me/schemas (allowed directory,
+// read-write)". It returns "" for paths in the workspace or its roots.
+func (g *Guard) DescribeExternalAccess(absPath string) string {
+	dir, ok := g.AllowedDirFor(absPath)
+	if !ok {
+		return ""
+	}
+	access := "read-write"
+	if dir.ReadOnly {
+		access = "read-only"
+	}
+	return fmt.Sprintf("%s (allowed directory, %s)", dir.Path, access)
+}
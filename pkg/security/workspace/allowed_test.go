@@ -0,0 +1,139 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newAllowedGuard creates a guard for an app workspace with a read-only
+// "modcache" and a writable "schemas" directory allowed next to it.
+func newAllowedGuard(t *testing.T) (*Guard, string, string) {
+	t.Helper()
+	base := t.TempDir()
+	for _, dir := range []string{"app", "modcache", "schemas"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	guard, err := NewGuard(filepath.Join(base, "app"))
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	err = guard.SetAllowedDirs([]AllowedDir{
+		{Path: filepath.Join(base, "modcache"), ReadOnly: true},
+		{Path: "../schemas"},
+	})
+	if err != nil {
+		t.Fatalf("SetAllowedDirs failed: %v", err)
+	}
+	dirs := guard.AllowedDirs()
+	return guard, dirs[0].Path, dirs[1].Path
+}
+
+func TestGuard_SetAllowedDirs_Validation(t *testing.T) {
+	base := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(base, "app", "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "file.txt"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	guard, err := NewGuard(filepath.Join(base, "app"))
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		dirs []AllowedDir
+	}{
+		{"empty path", []AllowedDir{{Path: " "}}},
+		{"filesystem root", []AllowedDir{{Path: "/"}}},
+		{"home directory", []AllowedDir{{Path: "~"}}},
+		{"hidden home directory", []AllowedDir{{Path: "~/.ssh", ReadOnly: true}}},
+		{"inside hidden home directory", []AllowedDir{{Path: "~/.config/gh"}}},
+		{"inside workspace", []AllowedDir{{Path: "sub"}}},
+		{"contains workspace", []AllowedDir{{Path: base}}},
+		{"file", []AllowedDir{{Path: "../file.txt"}}},
+		{"duplicate", []AllowedDir{{Path: "../shared"}, {Path: filepath.Join(base, "shared")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := guard.SetAllowedDirs(tt.dirs); err == nil {
+				t.Errorf("expected SetAllowedDirs(%v) to fail", tt.dirs)
+			}
+		})
+	}
+
+	// Directories created later, such as a cache, may be allowed up front
+	if err := guard.SetAllowedDirs([]AllowedDir{{Path: "../cache"}}); err != nil {
+		t.Errorf("expected a missing directory to be allowed: %v", err)
+	}
+}
+
+func TestGuard_AllowedDirs_Access(t *testing.T) {
+	guard, modcache, schemas := newAllowedGuard(t)
+
+	for _, p := range []string{filepath.Join(modcache, "mod.go"), filepath.Join(schemas, "user.json")} {
+		if err := guard.ValidatePath(p); err != nil {
+			t.Errorf("expected %s to be accessible: %v", p, err)
+		}
+		if guard.ShouldIgnore(p) {
+			t.Errorf("expected %s not to be ignored", p)
+		}
+	}
+	if err := guard.ValidatePath(filepath.Join(filepath.Dir(schemas), "other", "x.go")); err == nil {
+		t.Error("expected a directory that is not allowed to stay outside the workspace")
+	}
+
+	var ruleErr *PathRuleError
+	if err := guard.CheckWrite(filepath.Join(modcache, "mod.go"), 1); !errors.As(err, &ruleErr) || ruleErr.Rule != RuleReadOnly {
+		t.Errorf("expected a read_only violation writing to the read-only directory, got %v", err)
+	}
+	if err := guard.CheckWorkingDir(modcache); err == nil {
+		t.Error("expected the read-only directory to be rejected as a working directory")
+	}
+	if err := guard.CheckWrite(filepath.Join(schemas, "user.json"), 1); err != nil {
+		t.Errorf("expected writes to the read-write directory to be allowed: %v", err)
+	}
+}
+
+func TestGuard_DescribeExternalAccess(t *testing.T) {
+	guard, modcache, schemas := newAllowedGuard(t)
+	if err := guard.AddWhitelist(filepath.Join(filepath.Dir(schemas), "tools")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join(modcache, "a", "b.go"), modcache + " (allowed directory, read-only)"},
+		{filepath.Join(schemas, "user.json"), schemas + " (allowed directory, read-write)"},
+		{filepath.Join(filepath.Dir(schemas), "tools", "t.yaml"), filepath.Join(filepath.Dir(schemas), "tools") + " (allowed directory, read-write)"},
+		{filepath.Join(guard.WorkspaceDir(), "main.go"), ""},
+	}
+	for _, tt := range tests {
+		if got := guard.DescribeExternalAccess(tt.path); got != tt.want {
+			t.Errorf("DescribeExternalAccess(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGuard_AllowedDirsInstructions(t *testing.T) {
+	guard, modcache, schemas := newAllowedGuard(t)
+
+	instructions := guard.RootsInstructions()
+	for _, want := range []string{"# Allowed Directories", modcache + " (read-only)", schemas + " (read-write)"} {
+		if !strings.Contains(instructions, want) {
+			t.Errorf("expected instructions to contain %q, got:\n%s", want, instructions)
+		}
+	}
+	if strings.Contains(instructions, "# Workspace Roots") {
+		t.Errorf("expected no roots section without roots, got:\n%s", instructions)
+	}
+}
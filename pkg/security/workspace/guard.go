@@ -17,6 +17,11 @@ type Guard struct {
 	workspaceDir    string         // Absolute path to workspace root
 	ignoreMatcher   *IgnoreMatcher // Pattern matcher for ignore rules
 	whitelistedDirs []string       // Additional allowed directories outside workspace
+	allowedDirs     []AllowedDir   // Resolved allow-listed directories (see SetAllowedDirs)
+	pathRules       PathRules      // Write restrictions within allowed directories
+	readOnlyDirs    []string       // Resolved PathRules.ReadOnly directories
+	scopeDirs       []string       // Subtrees the guard is restricted to (see SetScope)
+	roots           []*root        // Additional workspace roots (see SetRoots)
 }
 
 // NewGuard creates a new workspace guard for the given directory.
@@ -59,6 +64,7 @@ func NewGuard(workspaceDir string) (*Guard, error) {
 // - The path is empty
 // - The path contains invalid characters or patterns
 // - The resolved path is outside the workspace
+// - The resolved path is outside the guard's scope
 // - The path attempts directory traversal
 func (g *Guard) ValidatePath(path string) error {
 	if path == "" {
@@ -76,31 +82,29 @@ func (g *Guard) ValidatePath(path string) error {
 		return fmt.Errorf("path '%s' is outside workspace boundaries", path)
 	}
 
+	if !g.inScope(g.resolveSymlinks(resolvedPath)) {
+		return fmt.Errorf("path '%s' is outside the workspace scope (%s)", path, strings.Join(g.Scope(), ", "))
+	}
+
 	return nil
 }
 
 // ResolvePath converts a relative or absolute path to an absolute path
 // within the workspace context. It cleans the path and resolves any
-// symbolic links. Supports tilde expansion for paths starting with ~/.
+// symbolic links. Supports tilde expansion for paths starting with ~/ and
+// "@name/path" references to additional workspace roots.
 func (g *Guard) ResolvePath(path string) (string, error) {
 	if path == "" {
 		return "", fmt.Errorf("path cannot be empty")
 	}
 
 	// Expand tilde to home directory if present
-	expandedPath := path
-	if strings.HasPrefix(path, "~/") {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to expand ~: %w", err)
-		}
-		expandedPath = filepath.Join(homeDir, path[2:])
-	} else if path == "~" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to expand ~: %w", err)
-		}
-		expandedPath = homeDir
+	expandedPath, err := ExpandHome(path)
+	if err != nil {
+		return "", err
+	}
+	if expandedPath == path {
+		expandedPath = g.expandRootPath(path)
 	}
 
 	// Clean the path to remove any .. or . components
@@ -153,9 +157,9 @@ func (g *Guard) ResolvePath(path string) (string, error) {
 }
 
 // IsWithinWorkspace checks if an absolute path is within the workspace boundaries
-// or within any whitelisted directory. This is the core security check - it ensures
-// a path is either the workspace itself, a child directory of the workspace, or
-// within an explicitly whitelisted directory.
+// or within any workspace root, whitelisted, allowed or read-only directory. This is the core
+// security check - it ensures a path is either the workspace itself, a child directory
+// of the workspace, or within an explicitly configured directory.
 func (g *Guard) IsWithinWorkspace(absPath string) bool {
 	// Evaluate symlinks to ensure consistent path comparison
 	// This is important on systems like macOS where /var -> /private/var
@@ -173,7 +177,20 @@ func (g *Guard) IsWithinWorkspace(absPath string) bool {
 		}
 	}
 
-	return false
+	for _, allowed := range g.allowedDirs {
+		if isWithinDir(evalPath, allowed.Path) {
+			return true
+		}
+	}
+
+	// Read-only directories are readable; CheckWrite rejects writes to them
+	for _, readOnly := range g.readOnlyDirs {
+		if isWithinDir(evalPath, readOnly) {
+			return true
+		}
+	}
+
+	return g.rootFor(evalPath) != nil
 }
 
 // resolveSymlinks resolves symlinks in a path, handling non-existent paths
@@ -217,12 +234,25 @@ func (g *Guard) WorkspaceDir() string {
 }
 
 // MakeRelative converts an absolute path to a path relative to the workspace.
-// Returns an error if the path is not within the workspace.
+// Paths in an additional workspace root are returned as "@name/path", the form
+// ResolvePath accepts. Returns an error if the path is not within the workspace.
 func (g *Guard) MakeRelative(absPath string) (string, error) {
 	if !g.IsWithinWorkspace(absPath) {
 		return "", fmt.Errorf("path '%s' is not within workspace", absPath)
 	}
 
+	evalPath := g.resolveSymlinks(absPath)
+	if r := g.rootFor(evalPath); r != nil {
+		relPath, err := filepath.Rel(r.dir, evalPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to make path relative: %w", err)
+		}
+		if relPath == "." {
+			return RootPrefix + r.Name, nil
+		}
+		return RootPrefix + r.Name + "/" + filepath.ToSlash(relPath), nil
+	}
+
 	relPath, err := filepath.Rel(g.workspaceDir, absPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to make path relative: %w", err)
@@ -234,12 +264,14 @@ func (g *Guard) MakeRelative(absPath string) (string, error) {
 // ShouldIgnore checks if a path should be ignored based on loaded ignore patterns.
 // The path can be either absolute or relative - it will be converted to relative for matching.
 // Returns true if the path matches any ignore pattern (considering precedence and negation).
-// Whitelisted paths are never ignored, regardless of ignore patterns.
+// Whitelisted and allowed paths are never ignored, regardless of ignore patterns. Paths
+// outside the guard's scope are always ignored, and paths in an additional
+// workspace root are matched against that root's own ignore files.
 func (g *Guard) ShouldIgnore(path string) bool {
 	// Get absolute path for whitelist checking
 	var absPath string
-	if filepath.IsAbs(path) {
-		absPath = path
+	if expanded := g.expandRootPath(path); filepath.IsAbs(expanded) {
+		absPath = expanded
 	} else {
 		absPath = filepath.Join(g.workspaceDir, path)
 	}
@@ -253,6 +285,27 @@ func (g *Guard) ShouldIgnore(path string) bool {
 			return false
 		}
 	}
+	if _, ok := g.allowedDirFor(evalPath); ok {
+		return false
+	}
+
+	if !g.inScope(evalPath) {
+		return true
+	}
+
+	// Check if path is a directory by attempting to stat it
+	isDir := false
+	if info, err := os.Lstat(absPath); err == nil {
+		isDir = info.IsDir()
+	}
+
+	if r := g.rootFor(evalPath); r != nil {
+		relPath, err := filepath.Rel(r.dir, evalPath)
+		if err != nil {
+			return false
+		}
+		return r.ignoreMatcher.ShouldIgnore(relPath, isDir)
+	}
 
 	// Convert to relative path for pattern matching
 	var relPath string
@@ -268,11 +321,19 @@ func (g *Guard) ShouldIgnore(path string) bool {
 		relPath = path
 	}
 
-	// Check if path is a directory by attempting to stat it
-	isDir := false
-	if info, err := os.Lstat(absPath); err == nil {
-		isDir = info.IsDir()
-	}
-
 	return g.ignoreMatcher.ShouldIgnore(relPath, isDir)
 }
+
+// ExpandHome replaces a leading "~" in path with the user's home directory.
+// Paths that do not start with "~" or "~/", including "~user/...", are
+// returned unchanged.
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to expand ~: %w", err)
+	}
+	return filepath.Join(homeDir, path[1:]), nil
+}
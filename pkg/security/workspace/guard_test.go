@@ -409,3 +409,28 @@ func TestGuard_SymlinkSecurity(t *testing.T) {
 		t.Error("ValidatePath() should reject symlink pointing outside workspace")
 	}
 }
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"~", home},
+		{"~/go/pkg/mod", filepath.Join(home, "go", "pkg", "mod")},
+		{"~other/dir", "~other/dir"},
+		{"relative/~/dir", "relative/~/dir"},
+		{"/abs/dir", "/abs/dir"},
+	}
+	for _, tt := range tests {
+		got, err := ExpandHome(tt.path)
+		if err != nil {
+			t.Fatalf("ExpandHome(%q) failed: %v", tt.path, err)
+		}
+		if got != tt.want {
+			t.Errorf("ExpandHome(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
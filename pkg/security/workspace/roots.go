@@ -0,0 +1,179 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RootPrefix starts a path that names a workspace root, as in "@proto/api.proto".
+const RootPrefix = "@"
+
+// rootNamePattern matches usable root names.
+var rootNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Root is a directory the guard treats as part of the workspace besides the
+// workspace directory itself, such as a shared proto repository next to an
+// application repository. Tools address it as "@<name>/<path>" and may also
+// use its absolute path.
+type Root struct {
+	Name     string // Name used in "@name/path" references
+	Path     string // Directory; relative paths are resolved against the workspace
+	ReadOnly bool   // Whether tools may only read the root
+}
+
+// root is a configured Root with its directory resolved.
+type root struct {
+	Root
+	dir           string         // Absolute path with symlinks evaluated
+	ignoreMatcher *IgnoreMatcher // The root's own ignore rules
+}
+
+// SetRoots replaces the guard's additional workspace roots. Each root must
+// exist, have a unique name, and neither contain nor be contained by the
+// workspace or another root, so every path belongs to at most one root.
+func (g *Guard) SetRoots(roots []Root) error {
+	resolved := make([]*root, 0, len(roots))
+	for _, r := range roots {
+		if err := ValidateRootName(r.Name); err != nil {
+			return err
+		}
+		dir, err := g.resolveRootDir(r.Path)
+		if err != nil {
+			return fmt.Errorf("workspace root '%s': %w", r.Name, err)
+		}
+		if isWithinDir(dir, g.workspaceDir) || isWithinDir(g.workspaceDir, dir) {
+			return fmt.Errorf("workspace root '%s' overlaps the workspace directory", r.Name)
+		}
+		for _, other := range resolved {
+			if other.Name == r.Name {
+				return fmt.Errorf("duplicate workspace root name '%s'", r.Name)
+			}
+			if isWithinDir(dir, other.dir) || isWithinDir(other.dir, dir) {
+				return fmt.Errorf("workspace roots '%s' and '%s' overlap", other.Name, r.Name)
+			}
+		}
+
+		ignoreMatcher, err := NewIgnoreMatcher(dir)
+		if err != nil {
+			return fmt.Errorf("workspace root '%s': failed to initialize ignore matcher: %w", r.Name, err)
+		}
+		resolved = append(resolved, &root{Root: Root{Name: r.Name, Path: dir, ReadOnly: r.ReadOnly}, dir: dir, ignoreMatcher: ignoreMatcher})
+	}
+
+	g.roots = resolved
+	return nil
+}
+
+// Roots returns the guard's additional workspace roots with their resolved
+// absolute paths, in the order they were set.
+func (g *Guard) Roots() []Root {
+	roots := make([]Root, len(g.roots))
+	for i, r := range g.roots {
+		roots[i] = r.Root
+	}
+	return roots
+}
+
+// ValidateRootName reports whether name is usable as a workspace root name.
+func ValidateRootName(name string) error {
+	if name == "" {
+		return fmt.Errorf("workspace root name cannot be empty")
+	}
+	if !rootNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid workspace root name '%s' (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// resolveRootDir resolves a root directory the way SetPathRules resolves
+// read-only directories, and checks that it exists.
+func (g *Guard) resolveRootDir(dir string) (string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return "", fmt.Errorf("directory cannot be empty")
+	}
+	dir, err := ExpandHome(dir)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(g.workspaceDir, dir)
+	}
+
+	evalPath, err := filepath.EvalSymlinks(filepath.Clean(dir))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	info, err := os.Stat(evalPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("'%s' is not a directory", dir)
+	}
+	return evalPath, nil
+}
+
+// expandRootPath rewrites an "@name/path" reference to the root's absolute
+// path. Paths that do not name a configured root are returned unchanged, so
+// workspace directories that happen to start with "@" stay reachable.
+func (g *Guard) expandRootPath(p string) string {
+	if !strings.HasPrefix(p, RootPrefix) {
+		return p
+	}
+	name, rest, _ := strings.Cut(strings.TrimPrefix(filepath.ToSlash(p), RootPrefix), "/")
+	for _, r := range g.roots {
+		if r.Name == name {
+			return filepath.Join(r.dir, filepath.FromSlash(rest))
+		}
+	}
+	return p
+}
+
+// rootFor returns the root containing an evaluated absolute path, or nil.
+func (g *Guard) rootFor(evalPath string) *root {
+	for _, r := range g.roots {
+		if isWithinDir(evalPath, r.dir) {
+			return r
+		}
+	}
+	return nil
+}
+
+// RootsInstructions describes the additional workspace roots and allowed
+// directories for the system prompt, or returns "" when there are none.
+func (g *Guard) RootsInstructions() string {
+	if len(g.roots) == 0 && len(g.allowedDirs) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	if len(g.roots) > 0 {
+		b.WriteString("# Workspace Roots\n\n")
+		fmt.Fprintf(&b, "Besides the workspace (%s), this session spans these directories. Refer to files in them as @<root>/<path>, for example @%s/README.md, in every tool that takes a path, including execute_command's working_dir. Plain relative paths always refer to the workspace.\n\n", g.workspaceDir, g.roots[0].Name)
+		for _, r := range g.roots {
+			access := "read-write"
+			if r.ReadOnly {
+				access = "read-only"
+			}
+			fmt.Fprintf(&b, "- @%s: %s (%s)\n", r.Name, r.dir, access)
+		}
+	}
+	if len(g.allowedDirs) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("# Allowed Directories\n\n")
+		b.WriteString("Tools may also use these directories outside the workspace by absolute path, for example to read dependency sources.\n\n")
+		for _, d := range g.allowedDirs {
+			access := "read-write"
+			if d.ReadOnly {
+				access = "read-only"
+			}
+			fmt.Fprintf(&b, "- %s (%s)\n", d.Path, access)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
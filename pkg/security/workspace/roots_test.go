@@ -0,0 +1,181 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newRootedGuard creates a guard for an app workspace with a writable "proto"
+// root and a read-only "design" root next to it.
+func newRootedGuard(t *testing.T) (*Guard, string, string) {
+	t.Helper()
+	base := t.TempDir()
+	for _, dir := range []string{"app", "proto/api", "design"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(base, "proto", ".gitignore"), []byte("gen/\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	guard, err := NewGuard(filepath.Join(base, "app"))
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	err = guard.SetRoots([]Root{
+		{Name: "proto", Path: "../proto"},
+		{Name: "design", Path: filepath.Join(base, "design"), ReadOnly: true},
+	})
+	if err != nil {
+		t.Fatalf("SetRoots failed: %v", err)
+	}
+	roots := guard.Roots()
+	return guard, roots[0].Path, roots[1].Path
+}
+
+func TestGuard_SetRoots_Validation(t *testing.T) {
+	base := t.TempDir()
+	for _, dir := range []string{"app/sub", "proto/api"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	guard, err := NewGuard(filepath.Join(base, "app"))
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		roots []Root
+	}{
+		{"empty name", []Root{{Path: "../proto"}}},
+		{"invalid name", []Root{{Name: "my/proto", Path: "../proto"}}},
+		{"missing directory", []Root{{Name: "proto", Path: "../missing"}}},
+		{"inside workspace", []Root{{Name: "sub", Path: "sub"}}},
+		{"contains workspace", []Root{{Name: "base", Path: base}}},
+		{"duplicate name", []Root{{Name: "proto", Path: "../proto"}, {Name: "proto", Path: "../proto/api"}}},
+		{"overlapping roots", []Root{{Name: "proto", Path: "../proto"}, {Name: "api", Path: "../proto/api"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := guard.SetRoots(tt.roots); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestGuard_Roots_ResolvePath(t *testing.T) {
+	guard, protoDir, _ := newRootedGuard(t)
+
+	resolved, err := guard.ResolvePath("@proto/api/user.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(protoDir, "api", "user.proto"); resolved != want {
+		t.Errorf("ResolvePath = %s, want %s", resolved, want)
+	}
+
+	for _, path := range []string{"@proto", "@proto/api/user.proto", filepath.Join(protoDir, "api")} {
+		if err := guard.ValidatePath(path); err != nil {
+			t.Errorf("ValidatePath(%q) = %v, want nil", path, err)
+		}
+	}
+
+	// Unknown roots are ordinary workspace paths
+	resolved, err = guard.ResolvePath("@types/node")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(guard.WorkspaceDir(), "@types", "node"); resolved != want {
+		t.Errorf("ResolvePath = %s, want %s", resolved, want)
+	}
+
+	if err := guard.ValidatePath("@proto/../../outside"); err == nil {
+		t.Error("expected traversal out of a root to be rejected")
+	}
+}
+
+func TestGuard_Roots_MakeRelative(t *testing.T) {
+	guard, protoDir, _ := newRootedGuard(t)
+
+	tests := map[string]string{
+		protoDir: "@proto",
+		filepath.Join(protoDir, "api", "user.proto"):          "@proto/api/user.proto",
+		filepath.Join(guard.WorkspaceDir(), "cmd", "main.go"): filepath.Join("cmd", "main.go"),
+	}
+	for absPath, want := range tests {
+		got, err := guard.MakeRelative(absPath)
+		if err != nil {
+			t.Fatalf("MakeRelative(%q) failed: %v", absPath, err)
+		}
+		if got != want {
+			t.Errorf("MakeRelative(%q) = %q, want %q", absPath, got, want)
+		}
+	}
+}
+
+func TestGuard_Roots_CheckWrite(t *testing.T) {
+	guard, _, designDir := newRootedGuard(t)
+	if err := guard.SetPathRules(PathRules{DenyWrite: []string{"*.lock"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := guard.CheckWrite("@proto/api/user.proto", 10); err != nil {
+		t.Errorf("expected writes to a writable root to be allowed, got %v", err)
+	}
+
+	var ruleErr *PathRuleError
+	for _, path := range []string{"@design/mock.fig", filepath.Join(designDir, "mock.fig")} {
+		err := guard.CheckWrite(path, 10)
+		if !errors.As(err, &ruleErr) || ruleErr.Rule != RuleReadOnly || ruleErr.Pattern != "@design" {
+			t.Errorf("CheckWrite(%q) = %v, want a read-only root error", path, err)
+		}
+	}
+	if err := guard.CheckWorkingDir("@design"); !errors.As(err, &ruleErr) || ruleErr.Rule != RuleReadOnly {
+		t.Errorf("expected a read-only root to be rejected as a working directory, got %v", err)
+	}
+
+	err := guard.CheckWrite("@proto/buf.lock", 10)
+	if !errors.As(err, &ruleErr) || ruleErr.Rule != RuleDenyWrite {
+		t.Errorf("expected deny_write patterns to apply within roots, got %v", err)
+	}
+}
+
+func TestGuard_Roots_ShouldIgnore(t *testing.T) {
+	guard, protoDir, _ := newRootedGuard(t)
+	if err := os.MkdirAll(filepath.Join(protoDir, "gen"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if !guard.ShouldIgnore(filepath.Join(protoDir, "gen")) {
+		t.Error("expected the root's .gitignore to apply")
+	}
+	if !guard.ShouldIgnore("@proto/gen") {
+		t.Error("expected root references to be matched against the root's .gitignore")
+	}
+	if guard.ShouldIgnore("@proto/api") {
+		t.Error("expected unignored root paths to be kept")
+	}
+}
+
+func TestGuard_RootsInstructions(t *testing.T) {
+	guard, err := NewGuard(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := guard.RootsInstructions(); got != "" {
+		t.Errorf("expected no instructions without roots, got %q", got)
+	}
+
+	guard, protoDir, _ := newRootedGuard(t)
+	got := guard.RootsInstructions()
+	if !strings.Contains(got, "- @proto: "+protoDir+" (read-write)") || !strings.Contains(got, "(read-only)") {
+		t.Errorf("unexpected instructions:\n%s", got)
+	}
+}
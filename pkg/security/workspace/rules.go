@@ -0,0 +1,235 @@
+package workspace
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PathRules restricts which paths tools may modify. Reads are unaffected.
+type PathRules struct {
+	// DenyWrite lists workspace-relative glob patterns that may never be
+	// written, e.g. "vendor/**", "*.lock" or ".github/workflows/**". "**"
+	// matches any number of directories. Patterns without a slash match the
+	// file name at any depth.
+	DenyWrite []string
+
+	// ReadOnly lists directories that may be read but never written or used as
+	// a command working directory. Relative paths are resolved against the
+	// workspace; directories outside it become readable, like a read-only mount.
+	ReadOnly []string
+
+	// MaxFileSize is the largest file in bytes a tool may write. Zero means
+	// no limit.
+	MaxFileSize int64
+}
+
+// Path rule names reported in PathRuleError.
+const (
+	RuleDenyWrite   = "deny_write"
+	RuleReadOnly    = "read_only"
+	RuleMaxFileSize = "max_file_size"
+	RuleScope       = "scope"
+)
+
+// Operations checked against path rules.
+const (
+	OpWrite      = "write"
+	OpWorkingDir = "working_dir"
+)
+
+// PathRuleError reports an operation rejected by a path rule.
+type PathRuleError struct {
+	Op      string // OpWrite or OpWorkingDir
+	Path    string // Path as given by the caller
+	Rule    string // RuleDenyWrite, RuleReadOnly, RuleMaxFileSize or RuleScope
+	Pattern string // Matching deny_write pattern, read_only directory or scope
+	Size    int64  // Size of the rejected write (max_file_size only)
+	Limit   int64  // Configured limit (max_file_size only)
+}
+
+// Error implements the error interface.
+func (e *PathRuleError) Error() string {
+	target := "write to"
+	if e.Op == OpWorkingDir {
+		target = "run commands in"
+	}
+
+	switch e.Rule {
+	case RuleMaxFileSize:
+		return fmt.Sprintf("cannot write '%s': %d bytes exceeds the %d byte limit set by path rule %s", e.Path, e.Size, e.Limit, e.Rule)
+	case RuleReadOnly:
+		return fmt.Sprintf("cannot %s '%s': directory '%s' is read-only (path rule %s)", target, e.Path, e.Pattern, e.Rule)
+	case RuleScope:
+		return fmt.Sprintf("cannot %s '%s': path is outside the workspace scope '%s' (path rule %s)", target, e.Path, e.Pattern, e.Rule)
+	default:
+		return fmt.Sprintf("cannot %s '%s': path matches protected pattern '%s' (path rule %s)", target, e.Path, e.Pattern, e.Rule)
+	}
+}
+
+// SetPathRules replaces the guard's path rules. Read-only directories outside
+// the workspace become readable.
+func (g *Guard) SetPathRules(rules PathRules) error {
+	if rules.MaxFileSize < 0 {
+		return fmt.Errorf("max file size cannot be negative")
+	}
+	for _, pattern := range rules.DenyWrite {
+		if err := ValidatePathPattern(pattern); err != nil {
+			return err
+		}
+	}
+
+	readOnlyDirs := make([]string, 0, len(rules.ReadOnly))
+	for _, dir := range rules.ReadOnly {
+		if strings.TrimSpace(dir) == "" {
+			return fmt.Errorf("read-only directory cannot be empty")
+		}
+		dir, err := ExpandHome(dir)
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(g.workspaceDir, dir)
+		}
+		readOnlyDirs = append(readOnlyDirs, resolveWhitelistPath(filepath.Clean(dir)))
+	}
+
+	g.pathRules = rules
+	g.readOnlyDirs = readOnlyDirs
+	return nil
+}
+
+// ValidatePathPattern reports whether pattern is a usable deny_write glob.
+func ValidatePathPattern(pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return fmt.Errorf("path pattern cannot be empty")
+	}
+	for _, segment := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid path pattern '%s': %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// CheckWrite reports whether a file of size bytes may be written at path.
+// Callers must validate path with ValidatePath first. Violations are returned
+// as *PathRuleError.
+func (g *Guard) CheckWrite(p string, size int64) error {
+	if err := g.checkProtected(OpWrite, p); err != nil {
+		return err
+	}
+	if absPath, err := g.ResolvePath(p); err == nil && !g.inWriteScope(g.resolveSymlinks(absPath)) {
+		return &PathRuleError{Op: OpWrite, Path: p, Rule: RuleScope, Pattern: strings.Join(g.Scope(), ", ")}
+	}
+	if g.pathRules.MaxFileSize > 0 && size > g.pathRules.MaxFileSize {
+		return &PathRuleError{Op: OpWrite, Path: p, Rule: RuleMaxFileSize, Size: size, Limit: g.pathRules.MaxFileSize}
+	}
+	return nil
+}
+
+// CheckWorkingDir reports whether commands may run in dir. A protected
+// directory cannot be a working directory, since commands can write to it.
+func (g *Guard) CheckWorkingDir(dir string) error {
+	return g.checkProtected(OpWorkingDir, dir)
+}
+
+// checkProtected rejects paths inside a read-only directory, allowed directory
+// or workspace root, or matching a deny_write pattern. Patterns are matched relative to the
+// workspace or root containing the path.
+func (g *Guard) checkProtected(op, p string) error {
+	absPath, err := g.ResolvePath(p)
+	if err != nil {
+		return err
+	}
+	evalPath := g.resolveSymlinks(absPath)
+
+	for _, dir := range g.readOnlyDirs {
+		if isWithinDir(evalPath, dir) {
+			return &PathRuleError{Op: op, Path: p, Rule: RuleReadOnly, Pattern: dir}
+		}
+	}
+
+	if dir, ok := g.allowedDirFor(evalPath); ok && dir.ReadOnly {
+		return &PathRuleError{Op: op, Path: p, Rule: RuleReadOnly, Pattern: dir.Path}
+	}
+
+	baseDir := g.workspaceDir
+	if r := g.rootFor(evalPath); r != nil {
+		if r.ReadOnly {
+			return &PathRuleError{Op: op, Path: p, Rule: RuleReadOnly, Pattern: RootPrefix + r.Name}
+		}
+		baseDir = r.dir
+	}
+	if !isWithinDir(evalPath, baseDir) {
+		return nil
+	}
+	relPath, err := filepath.Rel(baseDir, evalPath)
+	if err != nil {
+		return nil
+	}
+	relPath = filepath.ToSlash(relPath)
+	for _, pattern := range g.pathRules.DenyWrite {
+		if MatchPathPattern(pattern, relPath) {
+			return &PathRuleError{Op: op, Path: p, Rule: RuleDenyWrite, Pattern: pattern}
+		}
+	}
+	return nil
+}
+
+// isWithinDir reports whether p is dir or one of its descendants.
+func isWithinDir(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p+string(filepath.Separator), dir+string(filepath.Separator))
+}
+
+// MatchPathPattern matches a slash-separated relative path against a glob
+// pattern in which "**" matches zero or more directories. A pattern without a
+// slash is matched against every path element, so "*.lock" protects lock
+// files at any depth.
+func MatchPathPattern(pattern, relPath string) bool {
+	pattern = strings.Trim(filepath.ToSlash(strings.TrimSpace(pattern)), "/")
+	if relPath == "." {
+		relPath = ""
+	}
+	pathSegments := strings.Split(relPath, "/")
+
+	if !strings.Contains(pattern, "/") && pattern != "**" {
+		for _, segment := range pathSegments {
+			if matched, _ := path.Match(pattern, segment); matched {
+				return true
+			}
+		}
+		return false
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), pathSegments)
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(rest, segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// GetPathRules returns the guard's path rules.
+func (g *Guard) GetPathRules() PathRules {
+	return g.pathRules
+}
@@ -0,0 +1,126 @@
+package workspace
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"vendor/**", "vendor/github.com/pkg/errors/errors.go", true},
+		{"vendor/**", "vendor", true},
+		{"vendor/**", "internal/vendor/x.go", false},
+		{"*.lock", "Cargo.lock", true},
+		{"*.lock", "web/yarn.lock", true},
+		{"*.lock", "lockfile.go", false},
+		{".github/workflows/**", ".github/workflows/ci.yml", true},
+		{".github/workflows/**", ".github/CODEOWNERS", false},
+		{"**/testdata/*.golden", "pkg/a/testdata/out.golden", true},
+		{"**/testdata/*.golden", "testdata/out.golden", true},
+		{"docs/*.md", "docs/adr/0001.md", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchPathPattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchPathPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGuard_CheckWrite(t *testing.T) {
+	workspaceDir := t.TempDir()
+	guard, err := NewGuard(workspaceDir)
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	if err := guard.SetPathRules(PathRules{
+		DenyWrite:   []string{"vendor/**", "*.lock"},
+		ReadOnly:    []string{"docs/adr"},
+		MaxFileSize: 100,
+	}); err != nil {
+		t.Fatalf("SetPathRules failed: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		size int64
+		rule string
+	}{
+		{"main.go", 10, ""},
+		{"vendor/modules.txt", 10, RuleDenyWrite},
+		{"web/package.lock", 10, RuleDenyWrite},
+		{"docs/adr/0001-record.md", 10, RuleReadOnly},
+		{"docs/guide.md", 10, ""},
+		{"big.txt", 101, RuleMaxFileSize},
+	}
+
+	for _, tt := range tests {
+		err := guard.CheckWrite(tt.path, tt.size)
+		if tt.rule == "" {
+			if err != nil {
+				t.Errorf("CheckWrite(%q) unexpected error: %v", tt.path, err)
+			}
+			continue
+		}
+
+		var ruleErr *PathRuleError
+		if !errors.As(err, &ruleErr) {
+			t.Errorf("CheckWrite(%q) = %v, want PathRuleError", tt.path, err)
+			continue
+		}
+		if ruleErr.Rule != tt.rule {
+			t.Errorf("CheckWrite(%q) rule = %s, want %s", tt.path, ruleErr.Rule, tt.rule)
+		}
+	}
+}
+
+func TestGuard_ReadOnlyMountOutsideWorkspace(t *testing.T) {
+	workspaceDir := t.TempDir()
+	mountDir := t.TempDir()
+	guard, err := NewGuard(workspaceDir)
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	target := filepath.Join(mountDir, "shared.go")
+	if err := guard.ValidatePath(target); err == nil {
+		t.Fatal("expected path outside workspace to be rejected before mounting")
+	}
+
+	if err := guard.SetPathRules(PathRules{ReadOnly: []string{mountDir}}); err != nil {
+		t.Fatalf("SetPathRules failed: %v", err)
+	}
+
+	if err := guard.ValidatePath(target); err != nil {
+		t.Errorf("expected read-only mount to be readable: %v", err)
+	}
+	if err := guard.CheckWrite(target, 1); err == nil {
+		t.Error("expected write to read-only mount to be rejected")
+	}
+	if err := guard.CheckWorkingDir(mountDir); err == nil {
+		t.Error("expected read-only mount to be rejected as working directory")
+	}
+	if err := guard.CheckWorkingDir("."); err != nil {
+		t.Errorf("expected workspace root to be a valid working directory: %v", err)
+	}
+}
+
+func TestGuard_SetPathRulesInvalid(t *testing.T) {
+	guard, err := NewGuard(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	if err := guard.SetPathRules(PathRules{DenyWrite: []string{"src/[a-"}}); err == nil {
+		t.Error("expected malformed pattern to be rejected")
+	}
+	if err := guard.SetPathRules(PathRules{MaxFileSize: -1}); err == nil {
+		t.Error("expected negative size limit to be rejected")
+	}
+}
@@ -0,0 +1,105 @@
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SetScope restricts the guard to the given workspace-relative subtrees, for
+// running against a large monorepo. Paths outside every subtree are rejected
+// by ValidatePath and CheckWrite and ignored by ShouldIgnore, so listing and
+// search tools never descend into them. Directories that contain a subtree
+// stay accessible so the tree can be navigated down to it, and whitelisted and
+// read-only directories are unaffected. An empty list removes the scope.
+func (g *Guard) SetScope(paths []string) error {
+	scopeDirs := make([]string, 0, len(paths))
+	for _, p := range paths {
+		clean, err := cleanScopePath(p)
+		if err != nil {
+			return err
+		}
+		scopeDirs = append(scopeDirs, resolveWhitelistPath(filepath.Join(g.workspaceDir, clean)))
+	}
+
+	g.scopeDirs = scopeDirs
+	return nil
+}
+
+// Scope returns the workspace-relative subtrees the guard is restricted to,
+// or nil if it is not scoped.
+func (g *Guard) Scope() []string {
+	if len(g.scopeDirs) == 0 {
+		return nil
+	}
+	scope := make([]string, 0, len(g.scopeDirs))
+	for _, dir := range g.scopeDirs {
+		relPath, err := filepath.Rel(g.workspaceDir, dir)
+		if err != nil {
+			relPath = dir
+		}
+		scope = append(scope, filepath.ToSlash(relPath))
+	}
+	return scope
+}
+
+// ValidateScopePath reports whether p is usable as a scope path: a relative
+// path to a subdirectory of the workspace.
+func ValidateScopePath(p string) error {
+	_, err := cleanScopePath(p)
+	return err
+}
+
+// cleanScopePath validates a scope path and returns it cleaned.
+func cleanScopePath(p string) (string, error) {
+	if strings.TrimSpace(p) == "" {
+		return "", fmt.Errorf("scope path cannot be empty")
+	}
+	if filepath.IsAbs(p) {
+		return "", fmt.Errorf("scope path '%s' must be relative to the workspace", p)
+	}
+	clean := filepath.Clean(p)
+	if clean == "." {
+		return "", fmt.Errorf("scope path '%s' must be a subdirectory of the workspace", p)
+	}
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("scope path '%s' is outside the workspace", p)
+	}
+	return clean, nil
+}
+
+// inScope reports whether an evaluated absolute path may be accessed under
+// the guard's scope. Paths outside the workspace are left to the boundary
+// checks.
+func (g *Guard) inScope(evalPath string) bool {
+	if len(g.scopeDirs) == 0 || !isWithinDir(evalPath, g.workspaceDir) {
+		return true
+	}
+	for _, dir := range g.scopeDirs {
+		// Ancestors of a scoped subtree are needed to navigate down to it
+		if isWithinDir(evalPath, dir) || isWithinDir(dir, evalPath) {
+			return true
+		}
+	}
+	for _, dir := range g.readOnlyDirs {
+		if isWithinDir(evalPath, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// inWriteScope reports whether an evaluated absolute path may be written
+// under the guard's scope. Unlike inScope, ancestors of a scoped subtree are
+// excluded, so files next to it cannot be written.
+func (g *Guard) inWriteScope(evalPath string) bool {
+	if len(g.scopeDirs) == 0 || !isWithinDir(evalPath, g.workspaceDir) {
+		return true
+	}
+	for _, dir := range g.scopeDirs {
+		if isWithinDir(evalPath, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newScopedGuard(t *testing.T) (*Guard, string) {
+	t.Helper()
+	workspaceDir := t.TempDir()
+	for _, dir := range []string{"services/auth", "services/billing", "libs/shared", "docs"} {
+		if err := os.MkdirAll(filepath.Join(workspaceDir, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	guard, err := NewGuard(workspaceDir)
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	if err := guard.SetPathRules(PathRules{ReadOnly: []string{"docs"}}); err != nil {
+		t.Fatalf("SetPathRules failed: %v", err)
+	}
+	if err := guard.SetScope([]string{"services/auth", "libs/shared/"}); err != nil {
+		t.Fatalf("SetScope failed: %v", err)
+	}
+	return guard, guard.WorkspaceDir()
+}
+
+func TestGuard_SetScope_Validation(t *testing.T) {
+	guard, err := NewGuard(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	for _, path := range []string{"", ".", "../other", "/abs/path"} {
+		if err := guard.SetScope([]string{path}); err == nil {
+			t.Errorf("expected an error for scope path %q", path)
+		}
+	}
+}
+
+func TestGuard_Scope(t *testing.T) {
+	guard, _ := newScopedGuard(t)
+
+	scope := guard.Scope()
+	if len(scope) != 2 || scope[0] != "services/auth" || scope[1] != "libs/shared" {
+		t.Errorf("unexpected scope %v", scope)
+	}
+
+	if err := guard.SetScope(nil); err != nil {
+		t.Fatalf("SetScope failed: %v", err)
+	}
+	if scope := guard.Scope(); scope != nil {
+		t.Errorf("expected no scope after clearing, got %v", scope)
+	}
+	if err := guard.ValidatePath("services/billing/main.go"); err != nil {
+		t.Errorf("expected every path to be valid without a scope, got %v", err)
+	}
+}
+
+func TestGuard_ValidatePath_Scope(t *testing.T) {
+	guard, _ := newScopedGuard(t)
+
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{"services/auth/main.go", true},
+		{"services/auth", true},
+		{"libs/shared/log/log.go", true},
+		{".", true},        // Ancestors stay navigable
+		{"services", true}, // Ancestors stay navigable
+		{"docs/guide.md", true},
+		{"services/billing/main.go", false},
+		{"services/authz/main.go", false},
+		{"go.mod", false},
+	}
+
+	for _, tt := range tests {
+		err := guard.ValidatePath(tt.path)
+		if tt.valid && err != nil {
+			t.Errorf("ValidatePath(%q) unexpected error: %v", tt.path, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("ValidatePath(%q) expected an error", tt.path)
+		}
+	}
+}
+
+func TestGuard_ShouldIgnore_Scope(t *testing.T) {
+	guard, workspaceDir := newScopedGuard(t)
+
+	tests := []struct {
+		path   string
+		ignore bool
+	}{
+		{filepath.Join(workspaceDir, "services"), false},
+		{filepath.Join(workspaceDir, "services", "auth", "main.go"), false},
+		{filepath.Join(workspaceDir, "services", "billing"), true},
+		{filepath.Join(workspaceDir, "README.md"), true},
+		{"libs/shared/log.go", false},
+		{"libs/other", true},
+	}
+
+	for _, tt := range tests {
+		if got := guard.ShouldIgnore(tt.path); got != tt.ignore {
+			t.Errorf("ShouldIgnore(%q) = %v, want %v", tt.path, got, tt.ignore)
+		}
+	}
+}
+
+func TestGuard_CheckWrite_Scope(t *testing.T) {
+	guard, _ := newScopedGuard(t)
+
+	if err := guard.CheckWrite("services/auth/main.go", 10); err != nil {
+		t.Errorf("expected a write inside the scope to be allowed, got %v", err)
+	}
+
+	for _, path := range []string{"services/billing/main.go", "services/main.go", "main.go"} {
+		var ruleErr *PathRuleError
+		if err := guard.CheckWrite(path, 10); !errors.As(err, &ruleErr) || ruleErr.Rule != RuleScope {
+			t.Errorf("CheckWrite(%q) expected a %s violation, got %v", path, RuleScope, err)
+		}
+	}
+}
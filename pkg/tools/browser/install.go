@@ -0,0 +1,83 @@
+package browser
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// ErrNotInstalled is returned when the Playwright driver or its Chromium
+// build is missing.
+var ErrNotInstalled = errors.New("playwright is not installed")
+
+// InstallHint tells users how to install Playwright's Chromium.
+const InstallHint = "Run /browser install in the TUI, or `go run github.com/playwright-community/playwright-go/cmd/playwright install chromium`."
+
+// CheckInstallation reports whether the Playwright driver and Chromium are
+// installed, without downloading anything. The error wraps ErrNotInstalled
+// and names the missing part.
+func CheckInstallation() error {
+	driver, err := playwright.NewDriver(quietRunOptions(io.Discard))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotInstalled, err)
+	}
+
+	out, err := driver.Command("--version").Output()
+	if err != nil || !bytes.Contains(out, []byte(driver.Version)) {
+		return fmt.Errorf("%w: driver v%s not found", ErrNotInstalled, driver.Version)
+	}
+
+	// The dry run lists where each required browser build would be installed
+	out, err = driver.Command("install", "--dry-run", "chromium").Output()
+	if err != nil {
+		return fmt.Errorf("%w: could not query browser builds: %w", ErrNotInstalled, err)
+	}
+	locations := installLocations(out)
+	if len(locations) == 0 {
+		return fmt.Errorf("%w: could not determine the Chromium install location", ErrNotInstalled)
+	}
+	for _, location := range locations {
+		if _, statErr := os.Stat(location); statErr != nil {
+			return fmt.Errorf("%w: Chromium not found at %s", ErrNotInstalled, location)
+		}
+	}
+	return nil
+}
+
+// installLocations parses the "Install location:" lines of
+// `playwright install --dry-run` output.
+func installLocations(dryRunOutput []byte) []string {
+	var locations []string
+	scanner := bufio.NewScanner(bytes.NewReader(dryRunOutput))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if location, ok := strings.CutPrefix(line, "Install location:"); ok {
+			locations = append(locations, strings.TrimSpace(location))
+		}
+	}
+	return locations
+}
+
+// installChromium downloads the Playwright driver and Chromium, writing
+// progress to out.
+func installChromium(out io.Writer) error {
+	opts := quietRunOptions(out)
+	opts.Browsers = []string{"chromium"}
+	return playwright.Install(opts)
+}
+
+// quietRunOptions returns driver options that send output to out, so that
+// nothing is written over the TUI.
+func quietRunOptions(out io.Writer) *playwright.RunOptions {
+	return &playwright.RunOptions{
+		Verbose: false,
+		Stdout:  out,
+		Stderr:  out,
+	}
+}
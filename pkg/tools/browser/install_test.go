@@ -0,0 +1,40 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallLocations(t *testing.T) {
+	output := []byte(`browser: chromium version 136.0.7103.25
+  Install location:    /home/user/.cache/ms-playwright/chromium-1169
+  Download url:        https://cdn.playwright.dev/chromium-linux.zip
+
+browser: chromium-headless-shell version 136.0.7103.25
+  Install location:    /home/user/.cache/ms-playwright/chromium_headless_shell-1169
+`)
+
+	assert.Equal(t, []string{
+		"/home/user/.cache/ms-playwright/chromium-1169",
+		"/home/user/.cache/ms-playwright/chromium_headless_shell-1169",
+	}, installLocations(output))
+	assert.Empty(t, installLocations([]byte("unexpected output")))
+}
+
+func TestSessionManager_UnavailableFailsWithGuidance(t *testing.T) {
+	manager := NewSessionManager()
+	assert.True(t, manager.Available(), "available until found missing")
+
+	manager.installErr = fmt.Errorf("%w: driver not found", ErrNotInstalled)
+	assert.False(t, manager.Available())
+
+	err := manager.Initialize()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotInstalled))
+	assert.Contains(t, err.Error(), "fetch_url")
+	assert.Contains(t, err.Error(), "/browser install")
+}
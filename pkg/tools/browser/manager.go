@@ -18,6 +18,7 @@ type SessionManager struct {
 	maxSessions int
 	idleTimeout time.Duration
 	initialized bool
+	installErr  error // Set when Playwright was found to be missing
 }
 
 // NewSessionManager creates a new session manager.
@@ -40,19 +41,19 @@ func (m *SessionManager) Initialize() error {
 		return nil
 	}
 
-	// Install and run Playwright with verbose=false and discard output to avoid interfering with TUI
-	opts := &playwright.RunOptions{
-		Verbose: false,
-		Stdout:  io.Discard,
-		Stderr:  io.Discard,
+	// Fail with guidance instead of a driver error once Playwright is known
+	// to be missing
+	if m.installErr != nil {
+		return unavailableError(m.installErr)
 	}
 
-	err := playwright.Install(opts)
-	if err != nil {
-		return fmt.Errorf("failed to install playwright: %w", err)
+	// Install and run Playwright, discarding output to avoid interfering with TUI
+	if err := installChromium(io.Discard); err != nil {
+		m.installErr = fmt.Errorf("%w: %w", ErrNotInstalled, err)
+		return unavailableError(m.installErr)
 	}
 
-	pw, err := playwright.Run(opts)
+	pw, err := playwright.Run(quietRunOptions(io.Discard))
 	if err != nil {
 		return fmt.Errorf("failed to start playwright: %w", err)
 	}
@@ -62,6 +63,40 @@ func (m *SessionManager) Initialize() error {
 	return nil
 }
 
+// DetectInstallation checks whether Playwright's Chromium is installed and
+// records the result. While it is missing, start_browser_session is hidden
+// in favor of fetch_url.
+func (m *SessionManager) DetectInstallation() error {
+	err := CheckInstallation()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.installErr = err
+	return err
+}
+
+// Available reports whether browser sessions can be started. It is true
+// until DetectInstallation or Initialize finds Playwright missing.
+func (m *SessionManager) Available() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.installErr == nil
+}
+
+// Install downloads Playwright's Chromium, writing progress to out, and
+// makes browser sessions available again.
+func (m *SessionManager) Install(out io.Writer) error {
+	if err := installChromium(out); err != nil {
+		return fmt.Errorf("failed to install playwright: %w", err)
+	}
+	return m.DetectInstallation()
+}
+
+// unavailableError explains a missing Playwright installation to the agent.
+func unavailableError(err error) error {
+	return fmt.Errorf("browser automation is unavailable (%w). Use fetch_url to read web pages instead. %s", err, InstallHint)
+}
+
 // StartSession creates a new browser session with the given name and options.
 func (m *SessionManager) StartSession(name string, opts SessionOptions) (*Session, error) {
 	m.mu.Lock()
@@ -8,9 +8,10 @@ import (
 
 // ToolRegistry manages dynamic browser tool registration.
 type ToolRegistry struct {
-	manager  *SessionManager
-	provider llm.Provider
-	tools    []tools.Tool
+	manager       *SessionManager
+	provider      llm.Provider
+	screenshotDir string
+	tools         []tools.Tool
 }
 
 // NewToolRegistry creates a new browser tool registry.
@@ -26,6 +27,12 @@ func (r *ToolRegistry) SetLLMProvider(provider llm.Provider) {
 	r.provider = provider
 }
 
+// SetScreenshotDir sets the directory browser screenshots are saved to.
+// Defaults to DefaultScreenshotDir when not set.
+func (r *ToolRegistry) SetScreenshotDir(dir string) {
+	r.screenshotDir = dir
+}
+
 // RegisterTools creates and returns all browser tools.
 // This should be called by the main tool registry to get the browser tools.
 func (r *ToolRegistry) RegisterTools() []tools.Tool {
@@ -49,6 +56,7 @@ func (r *ToolRegistry) RegisterTools() []tools.Tool {
 		NewWaitTool(r.manager),
 		NewSearchTool(r.manager),
 		NewEvaluateTool(r.manager),
+		NewScreenshotTool(r.manager, r.screenshotDir),
 	)
 
 	// AI-powered tools (only if LLM provider is available)
@@ -0,0 +1,215 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// ScreenshotTool captures screenshots of the browser session to disk.
+type ScreenshotTool struct {
+	manager *SessionManager
+	dir     string
+}
+
+// NewScreenshotTool creates a new screenshot tool that saves screenshots to dir.
+// An empty dir saves them to DefaultScreenshotDir.
+func NewScreenshotTool(manager *SessionManager, dir string) *ScreenshotTool {
+	if dir == "" {
+		dir = DefaultScreenshotDir
+	}
+	return &ScreenshotTool{
+		manager: manager,
+		dir:     dir,
+	}
+}
+
+// Name returns the tool name.
+func (t *ScreenshotTool) Name() string {
+	return "browser_screenshot"
+}
+
+// Description returns the tool description.
+func (t *ScreenshotTool) Description() string {
+	return "Capture a PNG screenshot of the full page, the viewport, or a single element in the browser session. Saves the image to the screenshots directory and returns its path and dimensions, for visual verification of UI changes."
+}
+
+// Schema returns the tool's JSON schema.
+func (t *ScreenshotTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"session": map[string]any{
+				"type":        "string",
+				"description": "Name of the browser session to use",
+			},
+			"selector": map[string]any{
+				"type":        "string",
+				"description": "Optional CSS selector of an element to capture instead of the page. The first matching element is captured.",
+			},
+			"full_page": map[string]any{
+				"type":        "boolean",
+				"description": "Capture the whole scrollable page (true, default) or only the visible viewport (false). Ignored when selector is set.",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Optional file name for the screenshot (e.g., 'login-form.png'). Defaults to the session name and a timestamp. An existing file with the same name is overwritten.",
+			},
+		},
+		[]string{"session"},
+	)
+}
+
+// ScreenshotInput represents the parameters for capturing a screenshot.
+type ScreenshotInput struct {
+	XMLName  xml.Name `xml:"arguments"`
+	Session  string   `xml:"session"`
+	Selector string   `xml:"selector"`
+	FullPage *bool    `xml:"full_page"`
+	Name     string   `xml:"name"`
+}
+
+// Execute captures a screenshot.
+func (t *ScreenshotTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	// Parse parameters
+	var input ScreenshotInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	// Validate parameters
+	if input.Session == "" {
+		return "", nil, fmt.Errorf("session name is required")
+	}
+	fileName, err := screenshotFileName(input.Name, input.Session, time.Now())
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Get session
+	session, err := t.manager.GetSession(input.Session)
+	if err != nil {
+		return "", nil, err
+	}
+
+	opts := ScreenshotOptions{
+		Selector: input.Selector,
+		FullPage: true, // Default
+	}
+	if input.FullPage != nil {
+		opts.FullPage = *input.FullPage
+	}
+
+	data, err := session.Screenshot(opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	path := filepath.Join(t.dir, fileName)
+	width, height, err := saveScreenshot(path, data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	target := "viewport"
+	switch {
+	case opts.Selector != "":
+		target = fmt.Sprintf("element '%s'", opts.Selector)
+	case opts.FullPage:
+		target = "full page"
+	}
+
+	result := fmt.Sprintf(`Screenshot saved
+
+Screenshot Details:
+- Session: %s
+- Captured: %s
+- URL: %s
+- Path: %s
+- Dimensions: %dx%d pixels
+- Size: %d bytes
+
+Reference the path in your summary or capture again after changes to compare the page visually.`,
+		input.Session,
+		target,
+		session.CurrentURL,
+		path,
+		width,
+		height,
+		len(data),
+	)
+
+	metadata := map[string]any{
+		"path":   path,
+		"width":  width,
+		"height": height,
+		"bytes":  len(data),
+	}
+
+	return result, metadata, nil
+}
+
+// IsLoopBreaking returns whether this tool breaks the agent loop.
+func (t *ScreenshotTool) IsLoopBreaking() bool {
+	return false
+}
+
+// ShouldShow returns whether this tool should be visible.
+// Screenshot tools are only shown when there are active sessions.
+func (t *ScreenshotTool) ShouldShow() bool {
+	return t.manager.HasSessions()
+}
+
+// screenshotFileName returns the file name to save a screenshot as. A
+// requested name must be a plain file name and gets a .png extension if it
+// has none; without one, the name is derived from the session and time.
+func screenshotFileName(name, session string, now time.Time) (string, error) {
+	if name == "" {
+		return fmt.Sprintf("%s-%s.png", sanitizeFileName(session), now.Format("20060102-150405.000")), nil
+	}
+
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("name must be a file name, not a path: %s", name)
+	}
+	if !strings.EqualFold(filepath.Ext(name), ".png") {
+		name += ".png"
+	}
+	return name, nil
+}
+
+// sanitizeFileName replaces characters that are unsafe in file names.
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, s)
+}
+
+// saveScreenshot writes PNG data to path, creating its directory, and
+// returns the image dimensions.
+func saveScreenshot(path string, data []byte) (int, int, error) {
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid screenshot data: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return 0, 0, fmt.Errorf("failed to create screenshot directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return 0, 0, fmt.Errorf("failed to save screenshot: %w", err)
+	}
+
+	return cfg.Width, cfg.Height, nil
+}
@@ -0,0 +1,92 @@
+package browser
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreenshotTool_Schema(t *testing.T) {
+	tool := NewScreenshotTool(NewSessionManager(), "")
+	assert.Equal(t, "browser_screenshot", tool.Name())
+	assert.Equal(t, DefaultScreenshotDir, tool.dir)
+
+	props := tool.Schema()["properties"].(map[string]any)
+	for _, name := range []string{"session", "selector", "full_page", "name"} {
+		assert.Contains(t, props, name)
+	}
+	assert.Equal(t, []string{"session"}, tool.Schema()["required"])
+}
+
+func TestScreenshotTool_Execute_ValidationErrors(t *testing.T) {
+	tool := NewScreenshotTool(NewSessionManager(), t.TempDir())
+
+	tests := []struct {
+		name        string
+		input       ScreenshotInput
+		expectError string
+	}{
+		{"missing session", ScreenshotInput{}, "session name is required"},
+		{"path as name", ScreenshotInput{Session: "test", Name: "../escape.png"}, "name must be a file name"},
+		{"session not found", ScreenshotInput{Session: "nonexistent"}, "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argsXML, err := xml.Marshal(tt.input)
+			require.NoError(t, err)
+
+			_, _, err = tool.Execute(context.Background(), argsXML)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
+
+func TestScreenshotFileName(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 6_000_000, time.UTC)
+
+	name, err := screenshotFileName("", "my session", now)
+	require.NoError(t, err)
+	assert.Equal(t, "my-session-20260102-030405.006.png", name)
+
+	name, err = screenshotFileName("login-form", "s", now)
+	require.NoError(t, err)
+	assert.Equal(t, "login-form.png", name)
+
+	name, err = screenshotFileName("Home.PNG", "s", now)
+	require.NoError(t, err)
+	assert.Equal(t, "Home.PNG", name)
+
+	for _, bad := range []string{"a/b.png", `a\b.png`, ".."} {
+		_, err = screenshotFileName(bad, "s", now)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSaveScreenshot(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 30))))
+
+	path := filepath.Join(t.TempDir(), "nested", "shot.png")
+	width, height, err := saveScreenshot(path, buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 40, width)
+	assert.Equal(t, 30, height)
+
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), saved)
+
+	_, _, err = saveScreenshot(path, []byte("not a png"))
+	assert.Error(t, err)
+}
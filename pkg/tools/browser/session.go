@@ -374,6 +374,36 @@ func (s *Session) Search(opts SearchOptions) ([]SearchResult, error) {
 	return results, nil
 }
 
+// Screenshot captures the page, or the first element matching the selector,
+// as a PNG.
+func (s *Session) Screenshot(opts ScreenshotOptions) ([]byte, error) {
+	s.UpdateLastUsed()
+
+	var timeout *float64
+	if opts.Timeout > 0 {
+		timeout = &opts.Timeout
+	}
+
+	if opts.Selector != "" {
+		data, err := s.Page.Locator(opts.Selector).First().Screenshot(playwright.LocatorScreenshotOptions{
+			Timeout: timeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("element screenshot failed: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := s.Page.Screenshot(playwright.PageScreenshotOptions{
+		FullPage: &opts.FullPage,
+		Timeout:  timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("screenshot failed: %w", err)
+	}
+	return data, nil
+}
+
 // GetMetadata returns current page metadata.
 func (s *Session) GetMetadata() (map[string]string, error) {
 	s.UpdateLastUsed()
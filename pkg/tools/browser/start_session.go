@@ -218,7 +218,8 @@ func (t *StartSessionTool) IsLoopBreaking() bool {
 }
 
 // ShouldShow returns whether this tool should be visible.
-// Session management tools are only shown when browser automation is enabled in settings.
+// Session management tools are only shown when browser automation is enabled in settings
+// and Playwright is installed; fetch_url remains available either way.
 func (t *StartSessionTool) ShouldShow() bool {
 	if !config.IsInitialized() {
 		return false
@@ -227,5 +228,5 @@ func (t *StartSessionTool) ShouldShow() bool {
 	if ui == nil {
 		return false
 	}
-	return ui.IsBrowserEnabled()
+	return ui.IsBrowserEnabled() && t.manager.Available()
 }
@@ -143,6 +143,19 @@ type WaitOptions struct {
 	Timeout float64
 }
 
+// ScreenshotOptions configures screenshot capture.
+type ScreenshotOptions struct {
+	// Selector optionally limits the screenshot to the first matching element
+	Selector string
+
+	// FullPage captures the whole scrollable page instead of the viewport
+	// (ignored when Selector is set)
+	FullPage bool
+
+	// Timeout in milliseconds
+	Timeout float64
+}
+
 // SearchOptions configures page search.
 type SearchOptions struct {
 	// Pattern is the text or regex pattern to search for
@@ -169,4 +182,5 @@ const (
 	DefaultViewportHeight = 720
 	DefaultMaxSessions    = 5
 	DefaultIdleTimeout    = 300 // 5 minutes in seconds
+	DefaultScreenshotDir  = ".forge/screenshots"
 )
@@ -49,7 +49,7 @@ func (t *ApplyDiffTool) Schema() map[string]any {
 					"properties": map[string]any{
 						"search": map[string]any{
 							"type":        "string",
-							"description": "Exact text to search for (must match exactly including whitespace)",
+							"description": "Exact text to search for, including whitespace. Near misses from whitespace or small differences are matched when unambiguous",
 						},
 						"replace": map[string]any{
 							"type":        "string",
@@ -65,16 +65,11 @@ func (t *ApplyDiffTool) Schema() map[string]any {
 }
 
 // Execute performs the search/replace operations and returns metadata about the changes.
-//
-//nolint:gocyclo // TODO: refactor to reduce complexity
 func (t *ApplyDiffTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
 	var input struct {
-		XMLName xml.Name `xml:"arguments"`
-		Path    string   `xml:"path"`
-		Edits   []struct {
-			Search  string `xml:"search"`
-			Replace string `xml:"replace"`
-		} `xml:"edits>edit"`
+		XMLName xml.Name   `xml:"arguments"`
+		Path    string     `xml:"path"`
+		Edits   []DiffEdit `xml:"edits>edit"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
@@ -106,43 +101,11 @@ func (t *ApplyDiffTool) Execute(ctx context.Context, argsXML []byte) (string, ma
 		return "", nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	fileContent := string(content)
-	originalContent := fileContent
-
-	// Apply each edit in sequence
-	appliedEdits := 0
-	totalLinesAdded := 0
-	totalLinesRemoved := 0
-
-	for i, edit := range input.Edits {
-		if edit.Search == "" {
-			return "", nil, fmt.Errorf("edit %d: search text cannot be empty", i+1)
-		}
-
-		// Check if search text exists
-		if !strings.Contains(fileContent, edit.Search) {
-			return "", nil, fmt.Errorf("edit %d: search text not found in file. The file content may have changed or the search pattern doesn't match exactly.\n\nRecovery steps:\n1. Use read_file to view the current file content (consider reading more context lines to understand the structure)\n2. Verify the exact text including whitespace, indentation, and line breaks\n3. Try a smaller, more focused edit targeting a unique code pattern\n4. Ensure your search text matches the actual file content character-for-character\n\nSearch text that failed:\n%s", i+1, edit.Search)
-		}
-
-		// Count occurrences to warn about multiple matches
-		count := strings.Count(fileContent, edit.Search)
-		if count > 1 {
-			return "", nil, fmt.Errorf("edit %d: search text appears %d times in file, must be unique. When multiple matches exist, the diff cannot determine which occurrence to modify.\n\nRecovery steps:\n1. Use read_file with appropriate line ranges to examine each occurrence\n2. Include more surrounding context in your search text to make it unique\n3. Make the search pattern more specific by including nearby code (function signature, variable declarations, etc.)\n4. Consider splitting into multiple smaller, targeted edits with unique search patterns\n5. Avoid overly generic patterns that match multiple locations\n\nExample: Instead of searching for 'return err', include the surrounding function context to make it unique", i+1, count)
-		}
-
-		// Track line changes for this edit
-		searchLines := strings.Count(edit.Search, "\n") + 1
-		replaceLines := strings.Count(edit.Replace, "\n") + 1
-
-		if replaceLines > searchLines {
-			totalLinesAdded += replaceLines - searchLines
-		} else if searchLines > replaceLines {
-			totalLinesRemoved += searchLines - replaceLines
-		}
+	originalContent := string(content)
 
-		// Apply the replacement
-		fileContent = strings.Replace(fileContent, edit.Search, edit.Replace, 1)
-		appliedEdits++
+	fileContent, changes, matches, err := ApplyEdits(originalContent, input.Edits)
+	if err != nil {
+		return "", nil, err
 	}
 
 	// Only write if changes were made
@@ -150,15 +113,14 @@ func (t *ApplyDiffTool) Execute(ctx context.Context, argsXML []byte) (string, ma
 		return "No changes made to file", nil, nil
 	}
 
-	// Write the modified content atomically
-	tmpPath := absPath + ".tmp"
-	if writeErr := os.WriteFile(tmpPath, []byte(fileContent), 0600); writeErr != nil { //nolint:gosec
-		return "", nil, fmt.Errorf("failed to write temporary file: %w", writeErr)
+	// Enforce write-protection and size rules
+	if ruleErr := t.guard.CheckWrite(input.Path, int64(len(fileContent))); ruleErr != nil {
+		return "", nil, ruleErr
 	}
 
-	if renameErr := os.Rename(tmpPath, absPath); renameErr != nil {
-		os.Remove(tmpPath)
-		return "", nil, fmt.Errorf("failed to rename temporary file: %w", renameErr)
+	// Write the modified content atomically
+	if writeErr := atomicWriteFile(ctx, absPath, fileContent); writeErr != nil {
+		return "", nil, writeErr
 	}
 
 	// Get relative path for response
@@ -169,13 +131,14 @@ func (t *ApplyDiffTool) Execute(ctx context.Context, argsXML []byte) (string, ma
 
 	// Build metadata about the changes
 	metadata := map[string]any{
-		"edits_applied": appliedEdits,
-		"lines_added":   totalLinesAdded,
-		"lines_removed": totalLinesRemoved,
+		"edits_applied": len(input.Edits),
+		"lines_added":   changes.LinesAdded,
+		"lines_removed": changes.LinesRemoved,
 		"file_path":     relPath,
 	}
+	AddMatchMetadata(metadata, matches)
 
-	return fmt.Sprintf("Successfully applied %d edit(s) to %s", appliedEdits, relPath), metadata, nil
+	return fmt.Sprintf("Successfully applied %d edit(s) to %s%s", len(input.Edits), relPath, DescribeMatches(matches)), metadata, nil
 }
 
 // IsLoopBreaking returns whether this tool should break the agent loop.
@@ -211,12 +174,9 @@ func (t *ApplyDiffTool) XMLExample() string {
 // GeneratePreview implements the Previewable interface to show a diff preview.
 func (t *ApplyDiffTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
 	var input struct {
-		XMLName xml.Name `xml:"arguments"`
-		Path    string   `xml:"path"`
-		Edits   []struct {
-			Search  string `xml:"search"`
-			Replace string `xml:"replace"`
-		} `xml:"edits>edit"`
+		XMLName xml.Name   `xml:"arguments"`
+		Path    string     `xml:"path"`
+		Edits   []DiffEdit `xml:"edits>edit"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
@@ -248,24 +208,15 @@ func (t *ApplyDiffTool) GeneratePreview(ctx context.Context, argsXML []byte) (*t
 	}
 
 	originalContent := string(content)
-	modifiedContent := originalContent
-
-	// Apply edits to generate modified version
-	for i, edit := range input.Edits {
-		if edit.Search == "" {
-			return nil, fmt.Errorf("edit %d: search text cannot be empty", i+1)
-		}
 
-		if !strings.Contains(modifiedContent, edit.Search) {
-			return nil, fmt.Errorf("edit %d: search text not found in file. The file content may have changed or the search pattern doesn't match exactly.\n\nRecovery steps:\n1. Use read_file to view the current file content (consider reading more context lines to understand the structure)\n2. Verify the exact text including whitespace, indentation, and line breaks\n3. Try a smaller, more focused edit targeting a unique code pattern\n4. Ensure your search text matches the actual file content character-for-character\n\nSearch text that failed:\n%s", i+1, edit.Search)
-		}
-
-		count := strings.Count(modifiedContent, edit.Search)
-		if count > 1 {
-			return nil, fmt.Errorf("edit %d: search text appears %d times in file, must be unique. When multiple matches exist, the diff cannot determine which occurrence to modify.\n\nRecovery steps:\n1. Use read_file with appropriate line ranges to examine each occurrence\n2. Include more surrounding context in your search text to make it unique\n3. Make the search pattern more specific by including nearby code (function signature, variable declarations, etc.)\n4. Consider splitting into multiple smaller, targeted edits with unique search patterns\n5. Avoid overly generic patterns that match multiple locations\n\nExample: Instead of searching for 'return err', include the surrounding function context to make it unique", i+1, count)
-		}
+	modifiedContent, _, _, err := ApplyEdits(originalContent, input.Edits)
+	if err != nil {
+		return nil, err
+	}
 
-		modifiedContent = strings.Replace(modifiedContent, edit.Search, edit.Replace, 1)
+	// Don't ask for approval of a write the path rules will reject
+	if ruleErr := t.guard.CheckWrite(input.Path, int64(len(modifiedContent))); ruleErr != nil {
+		return nil, ruleErr
 	}
 
 	// Generate diff
@@ -279,7 +230,7 @@ func (t *ApplyDiffTool) GeneratePreview(ctx context.Context, argsXML []byte) (*t
 	// Detect file language from extension for syntax highlighting metadata
 	language := detectLanguage(relPath)
 
-	return &tools.ToolPreview{
+	preview := &tools.ToolPreview{
 		Type:        tools.PreviewTypeDiff,
 		Title:       fmt.Sprintf("Apply %d edit(s) to %s", len(input.Edits), relPath),
 		Description: fmt.Sprintf("This will modify %s with %d search/replace operation(s)", relPath, len(input.Edits)),
@@ -289,7 +240,89 @@ func (t *ApplyDiffTool) GeneratePreview(ctx context.Context, argsXML []byte) (*t
 			"language":   language,
 			"edit_count": len(input.Edits),
 		},
-	}, nil
+	}
+	markOutsideWorkspace(preview, t.guard, absPath)
+	return preview, nil
+}
+
+// DiffEdit is a single search/replace operation understood by apply_diff.
+type DiffEdit struct {
+	Search  string `xml:"search"`
+	Replace string `xml:"replace"`
+}
+
+// ApplyEdits applies edits to content in order and returns the modified content
+// along with the number of lines added and removed and how each edit's search
+// text was matched. Each search string must match exactly once in the content
+// as it stands when that edit is applied; when it does not appear at all, it
+// may instead match one block of lines ignoring whitespace or, failing that,
+// one block at least FuzzyMatchThreshold similar to it.
+func ApplyEdits(content string, edits []DiffEdit) (string, LineChanges, []EditMatch, error) {
+	var changes LineChanges
+	matches := make([]EditMatch, 0, len(edits))
+
+	for i, edit := range edits {
+		if edit.Search == "" {
+			return "", changes, nil, fmt.Errorf("edit %d: search text cannot be empty", i+1)
+		}
+
+		start, end, replace, match, err := locateEdit(content, edit, i+1)
+		if err != nil {
+			return "", changes, nil, err
+		}
+		matches = append(matches, match)
+
+		// Track line changes for this edit
+		searchLines := strings.Count(content[start:end], "\n") + 1
+		replaceLines := strings.Count(replace, "\n") + 1
+
+		if replaceLines > searchLines {
+			changes.LinesAdded += replaceLines - searchLines
+		} else if searchLines > replaceLines {
+			changes.LinesRemoved += searchLines - replaceLines
+		}
+
+		content = content[:start] + replace + content[end:]
+	}
+
+	return content, changes, matches, nil
+}
+
+// AddMatchMetadata records how edits were matched in an apply_diff result's
+// metadata: match_ratio is the lowest similarity any edit was applied at, and
+// fuzzy_matches lists the edits that were not found exactly.
+func AddMatchMetadata(metadata map[string]any, matches []EditMatch) {
+	ratio := 1.0
+	var fuzzy []EditMatch
+	for _, match := range matches {
+		ratio = min(ratio, match.Ratio)
+		if match.Strategy != MatchExact {
+			fuzzy = append(fuzzy, match)
+		}
+	}
+	metadata["match_ratio"] = ratio
+	if len(fuzzy) > 0 {
+		metadata["fuzzy_matches"] = fuzzy
+	}
+}
+
+// DescribeMatches explains the edits that were not found exactly, for the
+// result message, so the model knows to check them. It is empty when every
+// edit matched exactly.
+func DescribeMatches(matches []EditMatch) string {
+	var notes []string
+	for _, match := range matches {
+		switch match.Strategy {
+		case MatchWhitespace:
+			notes = append(notes, fmt.Sprintf("edit %d matched line %d ignoring whitespace", match.Edit, match.StartLine))
+		case MatchSimilar:
+			notes = append(notes, fmt.Sprintf("edit %d matched line %d at %.0f%% similarity", match.Edit, match.StartLine, match.Ratio*100))
+		}
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(notes, "; ") + ")"
 }
 
 // detectLanguage returns a language identifier based on file extension
@@ -0,0 +1,134 @@
+package coding
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// maxWriteFileSize is the largest content write_file accepts, whatever
+	// the workspace's path rules allow. Larger files should be generated by a
+	// command or split up.
+	maxWriteFileSize = 10 << 20
+
+	// writeChunkSize is how much content is written between checks for
+	// cancellation.
+	writeChunkSize = 64 << 10
+)
+
+const (
+	// BackupDir is where previous versions of overwritten files are kept,
+	// relative to the workspace.
+	BackupDir = ".forge/backups"
+
+	// TrashDir is where delete_file moves deleted files and directories,
+	// relative to the workspace.
+	TrashDir = ".forge/trash"
+)
+
+// atomicWriteFile replaces the file at path with content. The content is
+// streamed to a temporary file in the same directory, synced, and renamed over
+// path, so an interrupted write (a timeout, SIGTERM or a full disk) leaves
+// the previous file untouched rather than half written. An existing file
+// keeps its permissions; a new one is created with mode 0600.
+func atomicWriteFile(ctx context.Context, path, content string) error {
+	perm := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	renamed := false
+	defer func() {
+		if !renamed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err := copyWithContext(ctx, tmp, strings.NewReader(content)); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to flush temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+	renamed = true
+
+	// Persist the rename itself; not every platform can sync a directory
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		_ = dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// copyWithContext copies src to dst in chunks, stopping when ctx is done.
+func copyWithContext(ctx context.Context, dst io.Writer, src io.Reader) error {
+	buf := make([]byte, writeChunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, readErr := src.Read(buf)
+		if n > 0 {
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// backupFile saves original, the previous content of the file at absPath,
+// into the workspace's backup directory under relPath, the file's path
+// relative to the workspace, stamped with now. It returns the backup's path
+// relative to the workspace.
+func backupFile(workspaceDir, absPath, relPath, original string, now time.Time) (string, error) {
+	backupRel := stampedPath(BackupDir, absPath, relPath, now)
+	backupPath := filepath.Join(workspaceDir, backupRel)
+	if err := os.MkdirAll(filepath.Dir(backupPath), 0750); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	if err := os.WriteFile(backupPath, []byte(original), 0600); err != nil {
+		return "", fmt.Errorf("failed to write backup: %w", err)
+	}
+	return filepath.ToSlash(backupRel), nil
+}
+
+// stampedPath returns where a copy of the file at absPath is kept under dir,
+// a workspace-relative directory such as BackupDir: relPath, the file's path
+// relative to the workspace, under a directory named for now.
+func stampedPath(dir, absPath, relPath string, now time.Time) string {
+	// A file in another root is kept under the root's name, and one outside
+	// the workspace under its base name
+	name := filepath.ToSlash(filepath.Clean(relPath))
+	name = strings.TrimPrefix(name, "@")
+	if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		name = filepath.Base(absPath)
+	}
+	return filepath.Join(filepath.FromSlash(dir), now.Format("20060102-150405"), filepath.FromSlash(name))
+}
@@ -0,0 +1,94 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// CreateDirectoryTool creates directories, with any missing parents, in the
+// workspace.
+type CreateDirectoryTool struct {
+	guard *workspace.Guard
+}
+
+// NewCreateDirectoryTool creates a new CreateDirectoryTool with workspace security.
+func NewCreateDirectoryTool(guard *workspace.Guard) *CreateDirectoryTool {
+	return &CreateDirectoryTool{
+		guard: guard,
+	}
+}
+
+// Name returns the tool name.
+func (t *CreateDirectoryTool) Name() string {
+	return "create_directory"
+}
+
+// Description returns the tool description.
+func (t *CreateDirectoryTool) Description() string {
+	return "Create a directory, along with any missing parent directories. Succeeds if the directory already exists. " +
+		"write_file and move_file create parent directories themselves, so this is only needed for directories that should exist empty."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *CreateDirectoryTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path of the directory to create (relative to workspace)",
+			},
+		},
+		[]string{"path"},
+	)
+}
+
+// Execute creates the directory.
+func (t *CreateDirectoryTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Path    string   `xml:"path"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if input.Path == "" {
+		return "", nil, fmt.Errorf("missing required parameter: path")
+	}
+
+	absPath, relPath, err := resolveModifiedPath(t.guard, input.Path)
+	if err != nil {
+		return "", nil, err
+	}
+	if ruleErr := t.guard.CheckWrite(input.Path, 0); ruleErr != nil {
+		return "", nil, ruleErr
+	}
+
+	metadata := map[string]any{
+		"dir_path": relPath,
+	}
+	if info, statErr := os.Stat(absPath); statErr == nil {
+		if !info.IsDir() {
+			return "", nil, fmt.Errorf("'%s' already exists and is not a directory", relPath)
+		}
+		metadata["created"] = false
+		return fmt.Sprintf("Directory '%s' already exists", relPath), metadata, nil
+	}
+
+	if mkdirErr := os.MkdirAll(absPath, 0750); mkdirErr != nil {
+		return "", nil, fmt.Errorf("failed to create directory: %w", mkdirErr)
+	}
+	metadata["created"] = true
+	return fmt.Sprintf("Directory '%s' created", relPath), metadata, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *CreateDirectoryTool) IsLoopBreaking() bool {
+	return false
+}
@@ -0,0 +1,52 @@
+package coding
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+func TestCreateDirectoryTool(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	tool := NewCreateDirectoryTool(guard)
+	xmlInput := `<arguments><path>internal/text/testdata</path></arguments>`
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if info, statErr := os.Stat(filepath.Join(tmpDir, "internal", "text", "testdata")); statErr != nil || !info.IsDir() {
+		t.Fatalf("Expected the directory to be created, got %v", statErr)
+	}
+	if metadata["created"] != true || !strings.Contains(result, "created") {
+		t.Errorf("Expected created, got %v: %s", metadata, result)
+	}
+
+	// An existing directory is fine
+	result, metadata, err = tool.Execute(context.Background(), []byte(xmlInput))
+	if err != nil || metadata["created"] != false || !strings.Contains(result, "already exists") {
+		t.Errorf("Expected an existing directory to succeed, got %v: %s (%v)", metadata, result, err)
+	}
+
+	writeTestFile(t, filepath.Join(tmpDir, "file.txt"), "x")
+	if _, _, err := tool.Execute(context.Background(), []byte(`<arguments><path>file.txt</path></arguments>`)); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("Expected a file in the way to be rejected, got %v", err)
+	}
+
+	if err := guard.SetPathRules(workspace.PathRules{DenyWrite: []string{"vendor/**"}}); err != nil {
+		t.Fatalf("SetPathRules failed: %v", err)
+	}
+	_, _, err = tool.Execute(context.Background(), []byte(`<arguments><path>vendor/x</path></arguments>`))
+	var ruleErr *workspace.PathRuleError
+	if !errors.As(err, &ruleErr) {
+		t.Errorf("Expected a PathRuleError, got %v", err)
+	}
+}
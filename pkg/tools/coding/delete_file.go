@@ -0,0 +1,302 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// DeleteFileTool deletes files and directories by moving them to the
+// workspace's trash, so a deletion can be undone by moving them back.
+type DeleteFileTool struct {
+	guard *workspace.Guard
+}
+
+// NewDeleteFileTool creates a new DeleteFileTool with workspace security.
+func NewDeleteFileTool(guard *workspace.Guard) *DeleteFileTool {
+	return &DeleteFileTool{
+		guard: guard,
+	}
+}
+
+// Name returns the tool name.
+func (t *DeleteFileTool) Name() string {
+	return "delete_file"
+}
+
+// Description returns the tool description.
+func (t *DeleteFileTool) Description() string {
+	return "Delete a file, or a directory and everything in it when recursive is true. " +
+		"The deleted path is moved to " + TrashDir + "/ rather than erased, so it can be restored. " +
+		"Use this instead of rm in execute_command: deletions are checked against the workspace's write rules and tracked like other file changes."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *DeleteFileTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path to the file or directory to delete (relative to workspace)",
+			},
+			"recursive": map[string]any{
+				"type":        "boolean",
+				"description": "Required to delete a directory, with everything in it (default: false)",
+			},
+		},
+		[]string{"path"},
+	)
+}
+
+// deleteRequest is a validated delete_file invocation.
+type deleteRequest struct {
+	absPath string
+	relPath string
+	isDir   bool
+	files   []string // Workspace-relative files a directory deletion removes
+}
+
+// parseRequest validates the tool arguments against the workspace and its
+// write rules.
+func (t *DeleteFileTool) parseRequest(argsXML []byte) (*deleteRequest, error) {
+	var input struct {
+		XMLName   xml.Name `xml:"arguments"`
+		Path      string   `xml:"path"`
+		Recursive bool     `xml:"recursive"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if input.Path == "" {
+		return nil, fmt.Errorf("missing required parameter: path")
+	}
+
+	absPath, relPath, err := resolveModifiedPath(t.guard, input.Path)
+	if err != nil {
+		return nil, err
+	}
+	if isTrashPath(relPath) {
+		return nil, fmt.Errorf("'%s' is already in the trash", relPath)
+	}
+
+	info, err := os.Lstat(absPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("'%s' does not exist", relPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat '%s': %w", relPath, err)
+	}
+	if ruleErr := t.guard.CheckWrite(input.Path, 0); ruleErr != nil {
+		return nil, ruleErr
+	}
+
+	req := &deleteRequest{absPath: absPath, relPath: relPath, isDir: info.IsDir()}
+	if !req.isDir {
+		return req, nil
+	}
+	if !input.Recursive {
+		return nil, fmt.Errorf("'%s' is a directory; set recursive to true to delete it and everything in it", relPath)
+	}
+
+	// Every file in the directory must be writable, not just the directory
+	req.files, err = filesUnder(t.guard, input.Path, absPath)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Execute moves the file or directory to the trash.
+func (t *DeleteFileTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	req, err := t.parseRequest(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Count the lines a file deletion removes before it is gone
+	var linesRemoved int
+	if !req.isDir && !isBinaryFile(req.absPath) {
+		if content, readErr := os.ReadFile(req.absPath); readErr == nil {
+			linesRemoved = CalculateLineChanges(string(content), "").LinesRemoved
+		}
+	}
+
+	trashPath, err := moveToTrash(t.guard.WorkspaceDir(), req.absPath, req.relPath, time.Now())
+	if err != nil {
+		return "", nil, err
+	}
+
+	metadata := map[string]any{
+		"trash_path": trashPath,
+	}
+	if req.isDir {
+		metadata["modified_files"] = req.files
+		return fmt.Sprintf("Directory '%s' deleted (%d files); moved to '%s'", req.relPath, len(req.files), trashPath), metadata, nil
+	}
+
+	metadata["file_path"] = req.relPath
+	metadata["lines_added"] = 0
+	metadata["lines_removed"] = linesRemoved
+	return fmt.Sprintf("File '%s' deleted (-%d lines); moved to '%s'", req.relPath, linesRemoved, trashPath), metadata, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *DeleteFileTool) IsLoopBreaking() bool {
+	return false
+}
+
+// GeneratePreview implements the Previewable interface, showing the removed
+// content of a file or the files in a directory.
+func (t *DeleteFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	req, err := t.parseRequest(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.isDir {
+		preview := &tools.ToolPreview{
+			Type:        tools.PreviewTypeCommand,
+			Title:       fmt.Sprintf("Delete directory %s", req.relPath),
+			Description: fmt.Sprintf("This will move %s and its %d files to %s", req.relPath, len(req.files), TrashDir),
+			Content:     strings.Join(req.files, "\n"),
+			Metadata: map[string]any{
+				"file_path":  req.relPath,
+				"file_count": len(req.files),
+			},
+		}
+		markOutsideWorkspace(preview, t.guard, req.absPath)
+		return preview, nil
+	}
+
+	preview := &tools.ToolPreview{
+		Type:        tools.PreviewTypeCommand,
+		Title:       fmt.Sprintf("Delete %s", req.relPath),
+		Description: fmt.Sprintf("This will move %s to %s", req.relPath, TrashDir),
+		Content:     fmt.Sprintf("Binary file %s", req.relPath),
+		Metadata: map[string]any{
+			"file_path": req.relPath,
+			"language":  detectLanguage(req.relPath),
+		},
+	}
+	if !isBinaryFile(req.absPath) {
+		content, err := os.ReadFile(req.absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		preview.Type = tools.PreviewTypeDiff
+		preview.Content = GenerateUnifiedDiff(string(content), "", req.relPath)
+	}
+	markOutsideWorkspace(preview, t.guard, req.absPath)
+	return preview, nil
+}
+
+// markOutsideWorkspace notes in a preview's metadata, as "outside_workspace",
+// which of absPaths are in an allowed directory outside the workspace, so the
+// approval prompt makes clear the change reaches beyond the project.
+func markOutsideWorkspace(preview *tools.ToolPreview, guard *workspace.Guard, absPaths ...string) {
+	var outside []string
+	for _, absPath := range absPaths {
+		if desc := guard.DescribeExternalAccess(absPath); desc != "" && !slices.Contains(outside, desc) {
+			outside = append(outside, desc)
+		}
+	}
+	if len(outside) > 0 {
+		preview.Metadata["outside_workspace"] = strings.Join(outside, "; ")
+	}
+}
+
+// resolveModifiedPath validates a path a tool will delete, move or create and
+// returns its absolute and workspace-relative forms. Workspace roots cannot
+// be modified themselves.
+func resolveModifiedPath(guard *workspace.Guard, path string) (absPath, relPath string, err error) {
+	if validateErr := guard.ValidatePath(path); validateErr != nil {
+		return "", "", fmt.Errorf("invalid path: %w", validateErr)
+	}
+	absPath, err = guard.ResolvePath(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	relPath, err = guard.MakeRelative(absPath)
+	if err != nil || relPath == "" {
+		relPath = path
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	if relPath == "." || (strings.HasPrefix(relPath, workspace.RootPrefix) && !strings.Contains(relPath, "/")) {
+		return "", "", fmt.Errorf("cannot modify the workspace root itself")
+	}
+	return absPath, relPath, nil
+}
+
+// filesUnder returns the workspace-relative files in the directory at
+// absDir, which the guard knows as path, checking each against the
+// workspace's write rules.
+func filesUnder(guard *workspace.Guard, path, absDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(absDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, relErr := filepath.Rel(absDir, p)
+		if relErr != nil {
+			return relErr
+		}
+		guardPath := filepath.ToSlash(filepath.Join(path, rel))
+		if ruleErr := guard.CheckWrite(guardPath, 0); ruleErr != nil {
+			return ruleErr
+		}
+		if fileRel, relErr := guard.MakeRelative(p); relErr == nil {
+			guardPath = filepath.ToSlash(fileRel)
+		}
+		files = append(files, guardPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// moveToTrash moves the file or directory at absPath into the workspace's
+// trash, under relPath stamped with now, and returns where it went relative
+// to the workspace. An earlier deletion of the same path in the same second
+// is kept by numbering the later one.
+func moveToTrash(workspaceDir, absPath, relPath string, now time.Time) (string, error) {
+	trashRel := stampedPath(TrashDir, absPath, relPath, now)
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(filepath.Join(workspaceDir, trashRel)); os.IsNotExist(err) {
+			break
+		}
+		trashRel = stampedPath(TrashDir, absPath, relPath, now) + fmt.Sprintf(".%d", i)
+	}
+
+	trashPath := filepath.Join(workspaceDir, trashRel)
+	if err := os.MkdirAll(filepath.Dir(trashPath), 0750); err != nil {
+		return "", fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	if err := os.Rename(absPath, trashPath); err != nil {
+		return "", fmt.Errorf("failed to move '%s' to the trash: %w", relPath, err)
+	}
+	return filepath.ToSlash(trashRel), nil
+}
+
+// isTrashPath reports whether the workspace-relative relPath is in the trash.
+func isTrashPath(relPath string) bool {
+	return relPath == TrashDir || strings.HasPrefix(relPath, TrashDir+"/")
+}
@@ -0,0 +1,129 @@
+package coding
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+func TestDeleteFileTool_File(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	writeTestFile(t, filepath.Join(tmpDir, "old.go"), "package old\n\nfunc Old() {}\n")
+	tool := NewDeleteFileTool(createWorkspaceGuard(t, tmpDir))
+	xmlInput := `<arguments><path>old.go</path></arguments>`
+
+	preview, err := tool.GeneratePreview(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("GeneratePreview failed: %v", err)
+	}
+	if preview.Type != tools.PreviewTypeDiff || !strings.Contains(preview.Content, "-func Old() {}") {
+		t.Errorf("Expected a diff removing the content, got %s: %s", preview.Type, preview.Content)
+	}
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(tmpDir, "old.go")); !os.IsNotExist(statErr) {
+		t.Error("Expected old.go to be gone")
+	}
+	if metadata["file_path"] != "old.go" || metadata["lines_removed"] != 3 {
+		t.Errorf("Expected old.go with 3 lines removed, got %v", metadata)
+	}
+
+	trashPath, _ := metadata["trash_path"].(string)
+	if !strings.HasPrefix(trashPath, TrashDir+"/") || !strings.HasSuffix(trashPath, "/old.go") || !strings.Contains(result, trashPath) {
+		t.Fatalf("Expected old.go in the trash, got %q (%s)", trashPath, result)
+	}
+	content, err := os.ReadFile(filepath.Join(tmpDir, filepath.FromSlash(trashPath)))
+	if err != nil || string(content) != "package old\n\nfunc Old() {}\n" {
+		t.Errorf("Expected the trash to hold the deleted content, got %q (%v)", content, err)
+	}
+
+	// Deleting the same path again in the same second keeps both copies
+	writeTestFile(t, filepath.Join(tmpDir, "old.go"), "second")
+	_, metadata, err = tool.Execute(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if second, _ := metadata["trash_path"].(string); second == trashPath {
+		t.Errorf("Expected the second deletion in its own place, got %q twice", second)
+	}
+}
+
+func TestDeleteFileTool_Directory(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, "legacy", "sub"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(tmpDir, "legacy", "a.go"), "a")
+	writeTestFile(t, filepath.Join(tmpDir, "legacy", "sub", "b.go"), "b")
+	tool := NewDeleteFileTool(createWorkspaceGuard(t, tmpDir))
+
+	_, _, err := tool.Execute(context.Background(), []byte(`<arguments><path>legacy</path></arguments>`))
+	if err == nil || !strings.Contains(err.Error(), "set recursive") {
+		t.Fatalf("Expected a directory to need recursive, got %v", err)
+	}
+
+	_, metadata, err := tool.Execute(context.Background(), []byte(`<arguments><path>legacy</path><recursive>true</recursive></arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	files, _ := metadata["modified_files"].([]string)
+	if len(files) != 2 || files[0] != "legacy/a.go" || files[1] != "legacy/sub/b.go" {
+		t.Errorf("Expected both files reported, got %v", metadata["modified_files"])
+	}
+	if _, statErr := os.Stat(filepath.Join(tmpDir, "legacy")); !os.IsNotExist(statErr) {
+		t.Error("Expected legacy/ to be gone")
+	}
+}
+
+func TestDeleteFileTool_Rejected(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, "deps"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(tmpDir, "deps", "yarn.lock"), "pinned")
+	guard := createWorkspaceGuard(t, tmpDir)
+	if err := guard.SetPathRules(workspace.PathRules{DenyWrite: []string{"*.lock"}}); err != nil {
+		t.Fatalf("SetPathRules failed: %v", err)
+	}
+	tool := NewDeleteFileTool(guard)
+
+	// A protected file cannot be deleted, directly or with its directory
+	for _, xmlInput := range []string{
+		`<arguments><path>deps/yarn.lock</path></arguments>`,
+		`<arguments><path>deps</path><recursive>true</recursive></arguments>`,
+	} {
+		_, _, err := tool.Execute(context.Background(), []byte(xmlInput))
+		var ruleErr *workspace.PathRuleError
+		if !errors.As(err, &ruleErr) || ruleErr.Rule != workspace.RuleDenyWrite {
+			t.Errorf("Expected deny_write PathRuleError, got: %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "deps", "yarn.lock")); err != nil {
+		t.Errorf("Expected yarn.lock to be kept, got %v", err)
+	}
+
+	for xmlInput, want := range map[string]string{
+		`<arguments><path>.</path><recursive>true</recursive></arguments>`: "workspace root",
+		`<arguments><path>missing.go</path></arguments>`:                   "does not exist",
+		`<arguments><path>../outside.go</path></arguments>`:                "outside workspace",
+	} {
+		if _, _, err := tool.Execute(context.Background(), []byte(xmlInput)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q for %s, got %v", want, xmlInput, err)
+		}
+	}
+}
@@ -5,8 +5,14 @@
 //   - WriteFileTool: Create or overwrite files with validation
 //   - ListFilesTool: List directory contents with optional recursion
 //   - SearchFilesTool: Search files using regex patterns
+//   - FindFilesTool: Find files by glob, modification time and size
 //   - ApplyDiffTool: Apply targeted edits using search/replace
+//   - RenameSymbolTool: Rename Go identifiers workspace-wide via gopls
+//   - DeleteFileTool: Delete files and directories into the workspace trash
+//   - MoveFileTool: Move or rename files and directories
+//   - CreateDirectoryTool: Create directories with missing parents
 //   - ExecuteCommandTool: Execute terminal commands with approval
+//   - RunTestsTool: Run go test, pytest or jest and parse the failures
 //
 // All tools enforce workspace-level security through the WorkspaceGuard,
 // preventing access to files outside the designated workspace directory.
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/sandbox"
 	"github.com/entrhq/forge/pkg/security/workspace"
 	"github.com/entrhq/forge/pkg/types"
 )
@@ -20,6 +21,7 @@ import (
 type ExecuteCommandTool struct {
 	guard          *workspace.Guard
 	defaultTimeout time.Duration
+	sandbox        *sandbox.Sandbox // Runs commands isolated from the host when set
 }
 
 // NewExecuteCommandTool creates a new command execution tool
@@ -30,6 +32,12 @@ func NewExecuteCommandTool(guard *workspace.Guard) *ExecuteCommandTool {
 	}
 }
 
+// SetSandbox makes the tool run commands inside sb instead of directly on
+// the host. A nil sandbox restores host execution.
+func (t *ExecuteCommandTool) SetSandbox(sb *sandbox.Sandbox) {
+	t.sandbox = sb
+}
+
 // Name returns the tool name
 func (t *ExecuteCommandTool) Name() string {
 	return "execute_command"
@@ -102,6 +110,12 @@ func (t *ExecuteCommandTool) Execute(ctx context.Context, argsXML []byte) (strin
 		workDir = absWorkDir
 	}
 
+	// Commands may write anywhere below their working directory, so protected
+	// directories cannot be used as one
+	if ruleErr := t.guard.CheckWorkingDir(workDir); ruleErr != nil {
+		return "", nil, ruleErr
+	}
+
 	// Create context with timeout from parent context
 	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
@@ -125,7 +139,14 @@ func (t *ExecuteCommandTool) Execute(ctx context.Context, argsXML []byte) (strin
 
 	// Execute command with streaming
 	start := time.Now()
-	cmd := exec.CommandContext(execCtx, "sh", "-c", input.Command)
+	var cmd *exec.Cmd
+	if t.sandbox != nil {
+		var cleanup func()
+		cmd, cleanup = t.sandbox.Command(execCtx, workDir, input.Command)
+		defer cleanup()
+	} else {
+		cmd = exec.CommandContext(execCtx, "sh", "-c", input.Command)
+	}
 	cmd.Dir = workDir
 
 	var stdout, stderr string
@@ -191,6 +212,9 @@ func (t *ExecuteCommandTool) Execute(ctx context.Context, argsXML []byte) (strin
 		"duration_ms": duration.Milliseconds(),
 		"working_dir": workDir,
 	}
+	if t.sandbox != nil {
+		metadata["sandbox"] = t.sandbox.Describe()
+	}
 
 	return result, metadata, nil
 }
@@ -265,6 +289,10 @@ func (t *ExecuteCommandTool) GeneratePreview(ctx context.Context, argsXML []byte
 		workDir = absWorkDir
 	}
 
+	if ruleErr := t.guard.CheckWorkingDir(workDir); ruleErr != nil {
+		return nil, ruleErr
+	}
+
 	// Determine timeout
 	timeout := t.defaultTimeout
 	if input.Timeout > 0 {
@@ -281,7 +309,7 @@ func (t *ExecuteCommandTool) GeneratePreview(ctx context.Context, argsXML []byte
 	preview.WriteString("\n\n")
 	fmt.Fprintf(&preview, "Timeout: %s\n", timeout)
 
-	return &tools.ToolPreview{
+	toolPreview := &tools.ToolPreview{
 		Type:        tools.PreviewTypeCommand,
 		Title:       "Execute Command",
 		Description: fmt.Sprintf("This will execute the command: %s", input.Command),
@@ -291,7 +319,9 @@ func (t *ExecuteCommandTool) GeneratePreview(ctx context.Context, argsXML []byte
 			"working_dir": workDir,
 			"timeout":     timeout.Seconds(),
 		},
-	}, nil
+	}
+	markOutsideWorkspace(toolPreview, t.guard, workDir)
+	return toolPreview, nil
 }
 
 // EventEmitter is a function type for emitting agent events
@@ -0,0 +1,384 @@
+package fileindex
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+const (
+	// maxEntries caps the files and directories the index holds. Larger
+	// workspaces are searched by walking.
+	maxEntries = 200000
+
+	// maxIndexedFileSize is the largest file whose trigrams are indexed.
+	// Larger text files are kept as candidates for every search.
+	maxIndexedFileSize = 1 << 20
+
+	// binarySniffLen is how much of a file is checked for NUL bytes.
+	binarySniffLen = 512
+)
+
+// Entry is an indexed file or directory.
+type Entry struct {
+	Path    string // Absolute path
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
+}
+
+// indexedFile is an entry with what the index knows about its content.
+type indexedFile struct {
+	Entry
+	binary   bool     // Content has NUL bytes or could not be read
+	trigrams []uint32 // Sorted; nil when the file is too large to index
+}
+
+// Index is an in-memory trigram index of the workspace's files. It is built
+// in the background and kept current by a file watcher; until it is ready,
+// and after it gave up on an oversized workspace, queries report that the
+// caller has to walk the filesystem itself.
+type Index struct {
+	guard   *workspace.Guard
+	root    string
+	watcher *fsnotify.Watcher
+
+	mu       sync.RWMutex
+	entries  map[string]*indexedFile
+	ready    bool
+	disabled bool
+
+	done chan struct{}
+}
+
+// New starts indexing the workspace of guard. Paths the guard ignores are
+// neither indexed nor watched.
+func New(guard *workspace.Guard) (*Index, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	ix := &Index{
+		guard:   guard,
+		root:    guard.WorkspaceDir(),
+		watcher: watcher,
+		entries: make(map[string]*indexedFile),
+		done:    make(chan struct{}),
+	}
+	go ix.run()
+	return ix, nil
+}
+
+// Close stops watching the workspace. Later queries walk the filesystem.
+func (ix *Index) Close() error {
+	err := ix.watcher.Close()
+	<-ix.done
+
+	ix.mu.Lock()
+	ix.ready = false
+	ix.entries = nil
+	ix.mu.Unlock()
+	return err
+}
+
+// Ready reports whether the index answers queries.
+func (ix *Index) Ready() bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.ready && !ix.disabled
+}
+
+// Entries returns the indexed files and directories below dir. It reports
+// false when the index cannot answer for dir.
+func (ix *Index) Entries(dir string) ([]Entry, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if !ix.covers(dir) {
+		return nil, false
+	}
+
+	var entries []Entry
+	for path, file := range ix.entries {
+		if isBelow(path, dir) {
+			entries = append(entries, file.Entry)
+		}
+	}
+	return entries, true
+}
+
+// Candidates returns the text files at or below path that may contain a
+// match of the regular expression pattern, in the order a filepath.Walk of
+// path visits them. It reports false when the index cannot answer for path.
+func (ix *Index) Candidates(path, pattern string) ([]string, bool) {
+	q := buildQuery(pattern)
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if !ix.covers(path) {
+		return nil, false
+	}
+
+	var candidates []string
+	for filePath, file := range ix.entries {
+		if file.IsDir || file.binary || (filePath != path && !isBelow(filePath, path)) {
+			continue
+		}
+		if file.trigrams != nil && !q.matches(file.trigrams) {
+			continue
+		}
+		candidates = append(candidates, filePath)
+	}
+	slices.SortFunc(candidates, compareWalkOrder)
+	return candidates, true
+}
+
+// covers reports whether the index is ready and holds path. The caller
+// holds the lock.
+func (ix *Index) covers(path string) bool {
+	if !ix.ready || ix.disabled {
+		return false
+	}
+	_, ok := ix.entries[path]
+	return ok
+}
+
+// run builds the index, then applies file system events until the watcher
+// is closed. Events that arrive while building queue up in the watcher and
+// are applied once the build is done.
+func (ix *Index) run() {
+	defer close(ix.done)
+
+	ix.build()
+	for {
+		select {
+		case event, ok := <-ix.watcher.Events:
+			if !ok {
+				return
+			}
+			ix.apply(event)
+		case err, ok := <-ix.watcher.Errors:
+			if !ok {
+				return
+			}
+			// Dropped events leave the index stale, so start over
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				ix.build()
+			}
+		}
+	}
+}
+
+// build indexes the whole workspace from scratch.
+func (ix *Index) build() {
+	ix.mu.Lock()
+	ix.ready = false
+	ix.mu.Unlock()
+
+	entries := make(map[string]*indexedFile)
+	ok := ix.addTree(ix.root, entries)
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.entries = entries
+	ix.disabled = !ok
+	ix.ready = true
+}
+
+// addTree indexes root and everything below it into entries, watching each
+// directory. It reports false when the tree cannot be indexed completely.
+func (ix *Index) addTree(root string, entries map[string]*indexedFile) bool {
+	complete := true
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip entries with errors, as a walk would
+		}
+		if !ix.guard.IsWithinWorkspace(path) || ix.guard.ShouldIgnore(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(entries) >= maxEntries {
+			complete = false
+			return filepath.SkipAll
+		}
+
+		if d.IsDir() {
+			if err := ix.watcher.Add(path); err != nil {
+				// Typically the inotify watch limit: changes here would be missed
+				complete = false
+				return filepath.SkipAll
+			}
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		entries[path] = indexFile(path, info)
+		return nil
+	})
+	return complete
+}
+
+// apply updates the index for a file system event.
+func (ix *Index) apply(event fsnotify.Event) {
+	path := event.Name
+	switch {
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		ix.mu.Lock()
+		ix.remove(path)
+		ix.mu.Unlock()
+
+	case event.Has(fsnotify.Create), event.Has(fsnotify.Write), event.Has(fsnotify.Chmod):
+		info, err := os.Lstat(path)
+		if err != nil {
+			ix.mu.Lock()
+			ix.remove(path)
+			ix.mu.Unlock()
+			return
+		}
+		if !ix.guard.IsWithinWorkspace(path) || ix.guard.ShouldIgnore(path) {
+			return
+		}
+
+		if info.IsDir() {
+			if !event.Has(fsnotify.Create) {
+				ix.mu.Lock()
+				if file, ok := ix.entries[path]; ok {
+					file.ModTime = info.ModTime()
+				}
+				ix.mu.Unlock()
+				return
+			}
+			// A new directory may already hold files created before it was watched
+			added := make(map[string]*indexedFile)
+			ok := ix.addTree(path, added)
+			ix.mu.Lock()
+			for p, file := range added {
+				ix.entries[p] = file
+			}
+			if !ok || len(ix.entries) > maxEntries {
+				ix.disabled = true
+			}
+			ix.mu.Unlock()
+			return
+		}
+
+		file := indexFile(path, info)
+		ix.mu.Lock()
+		if _, exists := ix.entries[path]; !exists && len(ix.entries) >= maxEntries {
+			ix.disabled = true
+		}
+		ix.entries[path] = file
+		ix.mu.Unlock()
+	}
+}
+
+// remove drops path and everything below it. The caller holds the lock.
+func (ix *Index) remove(path string) {
+	file, ok := ix.entries[path]
+	if !ok {
+		return
+	}
+	delete(ix.entries, path)
+	if !file.IsDir {
+		return
+	}
+	for p := range ix.entries {
+		if isBelow(p, path) {
+			delete(ix.entries, p)
+		}
+	}
+}
+
+// indexFile describes the file at path and indexes its content.
+func indexFile(path string, info fs.FileInfo) *indexedFile {
+	file := &indexedFile{Entry: Entry{
+		Path:    path,
+		IsDir:   info.IsDir(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}}
+	if file.IsDir {
+		return file
+	}
+
+	// Follows symlinks, like the tools reading the file
+	f, err := os.Open(path)
+	if err != nil {
+		file.binary = true
+		return file
+	}
+	defer f.Close()
+
+	head := make([]byte, binarySniffLen)
+	n, err := f.Read(head)
+	if n == 0 {
+		// Empty files have no text to match; unreadable ones are skipped
+		file.binary = !errors.Is(err, io.EOF)
+		file.trigrams = []uint32{}
+		return file
+	}
+	if bytes.IndexByte(head[:n], 0) >= 0 {
+		file.binary = true
+		return file
+	}
+	if info.Size() > maxIndexedFileSize {
+		return file
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		file.binary = true
+		return file
+	}
+	file.trigrams = trigramsOf(data)
+	if file.trigrams == nil {
+		file.trigrams = []uint32{}
+	}
+	return file
+}
+
+// isBelow reports whether path is inside dir.
+func isBelow(path, dir string) bool {
+	return strings.HasPrefix(path, dir) && len(path) > len(dir) &&
+		(path[len(dir)] == filepath.Separator || strings.HasSuffix(dir, string(filepath.Separator)))
+}
+
+// compareWalkOrder orders paths as filepath.Walk visits them: by path
+// component, so a directory's files come before a sibling that sorts
+// between them as a string ("a/b" before "a-c").
+func compareWalkOrder(a, b string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		ca, cb := a[i], b[i]
+		if ca == cb {
+			continue
+		}
+		if ca == filepath.Separator {
+			return -1
+		}
+		if cb == filepath.Separator {
+			return 1
+		}
+		if ca < cb {
+			return -1
+		}
+		return 1
+	}
+	return len(a) - len(b)
+}
@@ -0,0 +1,163 @@
+package fileindex
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// newTestIndex indexes a workspace holding files and waits until it is ready
+func newTestIndex(t *testing.T, files map[string]string) (*Index, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for path, content := range files {
+		writeFile(t, filepath.Join(dir, filepath.FromSlash(path)), content)
+	}
+
+	guard, err := workspace.NewGuard(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ix, err := New(guard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ix.Close() })
+
+	eventually(t, "index to be ready", ix.Ready)
+	return ix, guard.WorkspaceDir()
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// eventually waits for cond, which the watcher makes true asynchronously
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// relCandidates returns the candidates for pattern relative to root
+func relCandidates(t *testing.T, ix *Index, root, pattern string) []string {
+	t.Helper()
+	paths, ok := ix.Candidates(root, pattern)
+	if !ok {
+		t.Fatalf("index could not answer for %s", root)
+	}
+	rel := make([]string, len(paths))
+	for i, path := range paths {
+		r, _ := filepath.Rel(root, path)
+		rel[i] = filepath.ToSlash(r)
+	}
+	return rel
+}
+
+func TestIndexCandidates(t *testing.T) {
+	ix, root := newTestIndex(t, map[string]string{
+		"main.go":        "package main\n\nfunc main() {}\n",
+		"a/handler.go":   "package a\n\nfunc Handle() {}\n",
+		"a-b/notes.txt":  "func is mentioned here\n",
+		"big.txt":        strings.Repeat("x", maxIndexedFileSize+1),
+		"image.bin":      "\x00\x01func",
+		"empty.txt":      "",
+		".git/config":    "[core] func",
+		"a/nested/x.txt": "nothing to see",
+	})
+
+	if got := relCandidates(t, ix, root, `func (main|Handle)\(`); !slices.Equal(got, []string{"a/handler.go", "big.txt", "main.go"}) {
+		t.Errorf("candidates = %v", got)
+	}
+	// Walk order: a directory's files come before a sibling like "a-b"
+	if got := relCandidates(t, ix, root, `func`); !slices.Equal(got, []string{"a/handler.go", "a-b/notes.txt", "big.txt", "main.go"}) {
+		t.Errorf("candidates = %v", got)
+	}
+	// Patterns without literals rule nothing out
+	if got := relCandidates(t, ix, root, `.`); len(got) != 6 {
+		t.Errorf("expected every text file, got %v", got)
+	}
+
+	sub, ok := ix.Candidates(filepath.Join(root, "a"), `(?i)HANDLE`)
+	if !ok || len(sub) != 1 || filepath.Base(sub[0]) != "handler.go" {
+		t.Errorf("candidates under a = %v, %v", sub, ok)
+	}
+	if _, ok := ix.Candidates(filepath.Join(root, ".git"), `core`); ok {
+		t.Error("expected ignored paths not to be covered")
+	}
+}
+
+func TestIndexFollowsChanges(t *testing.T) {
+	ix, root := newTestIndex(t, map[string]string{"main.go": "package main\n"})
+
+	has := func(pattern, rel string) func() bool {
+		return func() bool {
+			paths, _ := ix.Candidates(root, pattern)
+			return slices.Contains(paths, filepath.Join(root, filepath.FromSlash(rel)))
+		}
+	}
+
+	writeFile(t, filepath.Join(root, "main.go"), "package main\n\nfunc added() {}\n")
+	eventually(t, "the edit to be indexed", has(`added`, "main.go"))
+
+	writeFile(t, filepath.Join(root, "pkg", "deep", "new.go"), "package deep // marker\n")
+	eventually(t, "the new directory to be indexed", has(`marker`, "pkg/deep/new.go"))
+
+	if err := os.RemoveAll(filepath.Join(root, "pkg")); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the removal to be indexed", func() bool {
+		entries, _ := ix.Entries(root)
+		return len(entries) == 1
+	})
+}
+
+func TestIndexEntries(t *testing.T) {
+	ix, root := newTestIndex(t, map[string]string{"a/b.go": "package a", "c.md": "# c"})
+
+	entries, ok := ix.Entries(root)
+	if !ok {
+		t.Fatal("index could not answer for the root")
+	}
+	var got []string
+	for _, entry := range entries {
+		rel, _ := filepath.Rel(root, entry.Path)
+		if entry.IsDir {
+			rel += "/"
+		}
+		got = append(got, filepath.ToSlash(rel))
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"a/", "a/b.go", "c.md"}) {
+		t.Errorf("entries = %v", got)
+	}
+
+	if _, ok := ix.Entries(filepath.Join(root, "missing")); ok {
+		t.Error("expected paths outside the index not to be covered")
+	}
+}
+
+func TestCompareWalkOrder(t *testing.T) {
+	paths := []string{"a-c", "a/b", "a", "a/b/c", "ab"}
+	slices.SortFunc(paths, compareWalkOrder)
+	if want := []string{"a", "a/b", "a/b/c", "a-c", "ab"}; !slices.Equal(paths, want) {
+		t.Errorf("sorted = %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,172 @@
+package fileindex
+
+import (
+	"regexp/syntax"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// query is a conjunction of clauses that every file containing a match of a
+// regular expression satisfies. An empty query matches every file.
+type query []clause
+
+// clause is satisfied by a file that contains all trigrams of at least one
+// of its alternatives.
+type clause [][]uint32
+
+// buildQuery derives the literal text that any match of pattern contains
+// and turns it into a query over trigrams. Patterns it cannot analyze give
+// an empty query, so the index never rules out a file that could match.
+func buildQuery(pattern string) query {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+
+	var q query
+	for _, alternatives := range requiredLiterals(re.Simplify()) {
+		c := make(clause, 0, len(alternatives))
+		for _, literal := range alternatives {
+			c = append(c, trigramsOf([]byte(literal)))
+		}
+		q = append(q, c)
+	}
+	return q
+}
+
+// requiredLiterals returns sets of alternative literals: every match of re
+// contains at least one literal of each set. Literals are lower-cased and at
+// least three bytes long.
+func requiredLiterals(re *syntax.Regexp) [][]string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return literalSets(re)
+
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return requiredLiterals(re.Sub[0])
+		}
+
+	case syntax.OpConcat:
+		var sets [][]string
+		for _, sub := range re.Sub {
+			sets = append(sets, requiredLiterals(sub)...)
+		}
+		return sets
+
+	case syntax.OpAlternate:
+		// A match contains a literal of one branch; each branch contributes
+		// its most selective set
+		var alternatives []string
+		for _, sub := range re.Sub {
+			best := mostSelective(requiredLiterals(sub))
+			if best == nil {
+				return nil
+			}
+			alternatives = append(alternatives, best...)
+		}
+		return [][]string{alternatives}
+	}
+	return nil
+}
+
+// literalSets turns a literal node into single-literal sets. The index only
+// folds ASCII case, so case-folded literals are split at characters that
+// also match non-ASCII text: non-ASCII characters, and k and s, which fold
+// to the Kelvin sign and the long s.
+func literalSets(re *syntax.Regexp) [][]string {
+	literal := string(re.Rune)
+	pieces := []string{literal}
+	if re.Flags&syntax.FoldCase != 0 {
+		pieces = strings.FieldsFunc(literal, func(r rune) bool {
+			return r >= utf8.RuneSelf || strings.ContainsRune("kKsS", r)
+		})
+	}
+
+	var sets [][]string
+	for _, piece := range pieces {
+		if len(piece) >= 3 {
+			sets = append(sets, []string{toLowerASCII(piece)})
+		}
+	}
+	return sets
+}
+
+// mostSelective picks the set whose shortest literal is longest.
+func mostSelective(sets [][]string) []string {
+	var best []string
+	bestLen := 0
+	for _, set := range sets {
+		shortest := len(set[0])
+		for _, literal := range set[1:] {
+			shortest = min(shortest, len(literal))
+		}
+		if shortest > bestLen {
+			best, bestLen = set, shortest
+		}
+	}
+	return best
+}
+
+// matches reports whether a file with the given sorted trigrams may contain
+// a match.
+func (q query) matches(trigrams []uint32) bool {
+	for _, c := range q {
+		if !c.matches(trigrams) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c clause) matches(trigrams []uint32) bool {
+	for _, alternative := range c {
+		if containsAll(trigrams, alternative) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAll reports whether the sorted trigrams hold every trigram of want.
+func containsAll(trigrams, want []uint32) bool {
+	for _, t := range want {
+		if _, found := slices.BinarySearch(trigrams, t); !found {
+			return false
+		}
+	}
+	return true
+}
+
+// trigramsOf returns the distinct trigrams of data, ASCII lower-cased, in
+// ascending order.
+func trigramsOf(data []byte) []uint32 {
+	if len(data) < 3 {
+		return nil
+	}
+	trigrams := make([]uint32, 0, len(data)-2)
+	for i := 0; i+2 < len(data); i++ {
+		trigrams = append(trigrams, uint32(lowerASCII(data[i]))<<16|uint32(lowerASCII(data[i+1]))<<8|uint32(lowerASCII(data[i+2])))
+	}
+	slices.Sort(trigrams)
+	return slices.Clip(slices.Compact(trigrams))
+}
+
+func lowerASCII(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+func toLowerASCII(s string) string {
+	b := []byte(s)
+	for i := range b {
+		b[i] = lowerASCII(b[i])
+	}
+	return string(b)
+}
@@ -0,0 +1,61 @@
+package fileindex
+
+import (
+	"regexp/syntax"
+	"slices"
+	"testing"
+)
+
+func TestRequiredLiterals(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    [][]string
+	}{
+		{`hello`, [][]string{{"hello"}}},
+		{`Hello`, [][]string{{"hello"}}},
+		{`func\s+main\(`, [][]string{{"func"}, {"main("}}},
+		{`(foo|barbaz)qux`, [][]string{{"foo", "barbaz"}, {"qux"}}},
+		{`foo|b`, nil},
+		{`(abc)+x*`, [][]string{{"abc"}}},
+		{`(abc)*`, nil},
+		{`a.c`, nil},
+		{`(?i)class`, [][]string{{"cla"}}},
+		{`(?i)überall`, [][]string{{"berall"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			re, err := syntax.Parse(tt.pattern, syntax.Perl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := requiredLiterals(re.Simplify())
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("requiredLiterals(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	content := trigramsOf([]byte("package main\n\nfunc Main() {}\n"))
+
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{`func\s+Main`, true},
+		{`(?i)FUNC main`, true},
+		{`missing`, false},
+		{`packa(ge|xx)`, true},
+		{`(nothere|notthere)`, false},
+		{`.*`, true},
+		{`[`, true}, // Unparsable patterns rule nothing out
+	}
+
+	for _, tt := range tests {
+		if got := buildQuery(tt.pattern).matches(content); got != tt.want {
+			t.Errorf("query %q matches = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
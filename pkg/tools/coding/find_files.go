@@ -0,0 +1,529 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/coding/fileindex"
+)
+
+// defaultFindMaxResults caps the paths returned when max_results is not set.
+const defaultFindMaxResults = 200
+
+// FindFilesTool finds files by name or path glob, modification time and size.
+type FindFilesTool struct {
+	guard *workspace.Guard
+	index *fileindex.Index // Lists the workspace without walking when set and ready
+	now   func() time.Time
+}
+
+// NewFindFilesTool creates a new FindFilesTool with workspace security.
+func NewFindFilesTool(guard *workspace.Guard) *FindFilesTool {
+	return &FindFilesTool{
+		guard: guard,
+		now:   time.Now,
+	}
+}
+
+// SetIndex makes the tool list files from the index, walking the directory
+// while the index cannot answer. A nil index restores walking for every
+// call.
+func (t *FindFilesTool) SetIndex(index *fileindex.Index) {
+	t.index = index
+}
+
+// Name returns the tool name.
+func (t *FindFilesTool) Name() string {
+	return "find_files"
+}
+
+// Description returns the tool description.
+func (t *FindFilesTool) Description() string {
+	return "Find files by name or path glob (e.g., '**/*_test.go', 'cmd/*/main.go'), optionally filtered by modification time and size. Returns matching paths with size and modification time. Use this to locate files by name; use search_files to search file contents."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *FindFilesTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"pattern": map[string]any{
+				"type":        "string",
+				"description": "Glob pattern to match. Patterns without '/' match file names at any depth (e.g., '*.go', 'Dockerfile*'); patterns with '/' match paths relative to the search path, where '*' stays within one directory and '**' spans directories (e.g., 'pkg/**/*_test.go'). Braces list alternatives (e.g., '*.{yaml,yml}').",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Directory to search in (relative to workspace, defaults to workspace root)",
+			},
+			"exclude": map[string]any{
+				"type":        "string",
+				"description": "Optional glob pattern of paths to leave out, matched the same way as pattern (e.g., '**/testdata/**')",
+			},
+			"modified_since": map[string]any{
+				"type":        "string",
+				"description": "Only return files modified within a duration (e.g., '30m', '24h', '7d') or since a date ('2024-05-01' or RFC 3339)",
+			},
+			"min_size": map[string]any{
+				"type":        "string",
+				"description": "Only return files at least this large (e.g., '500', '10KB', '1.5MB')",
+			},
+			"max_size": map[string]any{
+				"type":        "string",
+				"description": "Only return files at most this large (e.g., '100KB')",
+			},
+			"type": map[string]any{
+				"type":        "string",
+				"description": "What to return: 'file' (default), 'directory' or 'any'",
+			},
+			"sort": map[string]any{
+				"type":        "string",
+				"description": "Result order: 'path' (default), 'modified' (newest first) or 'size' (largest first)",
+			},
+			"max_results": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of paths to return (default: %d)", defaultFindMaxResults),
+			},
+		},
+		[]string{"pattern"},
+	)
+}
+
+// findInput holds the parsed find_files arguments.
+type findInput struct {
+	XMLName       xml.Name `xml:"arguments"`
+	Pattern       string   `xml:"pattern"`
+	Path          string   `xml:"path"`
+	Exclude       string   `xml:"exclude"`
+	ModifiedSince string   `xml:"modified_since"`
+	MinSize       string   `xml:"min_size"`
+	MaxSize       string   `xml:"max_size"`
+	Type          string   `xml:"type"`
+	Sort          string   `xml:"sort"`
+	MaxResults    int      `xml:"max_results"`
+}
+
+// findFilter holds the compiled filters of a find_files call.
+type findFilter struct {
+	include  pathGlob
+	exclude  *pathGlob
+	since    time.Time
+	minSize  int64
+	maxSize  int64
+	fileType string
+}
+
+// findMatch is a path found by find_files.
+type findMatch struct {
+	Path    string
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
+}
+
+// Execute finds the files matching the filters.
+func (t *FindFilesTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input findInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if strings.TrimSpace(input.Pattern) == "" {
+		return "", nil, fmt.Errorf("missing required parameter: pattern")
+	}
+
+	// Default to workspace root if no path provided
+	if input.Path == "" {
+		input.Path = "."
+	}
+	if input.MaxResults <= 0 {
+		input.MaxResults = defaultFindMaxResults
+	}
+
+	filter, err := t.parseFilter(input)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Validate path with workspace guard
+	if err := t.guard.ValidatePath(input.Path); err != nil {
+		return "", nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	absPath, err := t.guard.ResolvePath(input.Path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	matches, err := t.find(ctx, absPath, filter)
+	if err != nil {
+		return "", nil, fmt.Errorf("find failed: %w", err)
+	}
+
+	sortFindMatches(matches, input.Sort)
+	total := len(matches)
+	if total > input.MaxResults {
+		matches = matches[:input.MaxResults]
+	}
+
+	result := t.formatMatches(matches, total)
+
+	metadata := map[string]any{
+		"path":        input.Path,
+		"pattern":     input.Pattern,
+		"match_count": total,
+		"truncated":   total > len(matches),
+	}
+	if input.Exclude != "" {
+		metadata["exclude"] = input.Exclude
+	}
+	if input.ModifiedSince != "" {
+		metadata["modified_since"] = input.ModifiedSince
+	}
+
+	return result, metadata, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *FindFilesTool) IsLoopBreaking() bool {
+	return false
+}
+
+// parseFilter validates the arguments and compiles them into a filter.
+func (t *FindFilesTool) parseFilter(input findInput) (findFilter, error) {
+	filter := findFilter{maxSize: -1, fileType: strings.ToLower(input.Type)}
+
+	include, err := compilePathGlob(input.Pattern)
+	if err != nil {
+		return filter, fmt.Errorf("invalid pattern: %w", err)
+	}
+	filter.include = include
+
+	if input.Exclude != "" {
+		exclude, err := compilePathGlob(input.Exclude)
+		if err != nil {
+			return filter, fmt.Errorf("invalid exclude pattern: %w", err)
+		}
+		filter.exclude = &exclude
+	}
+
+	if input.ModifiedSince != "" {
+		if filter.since, err = parseModifiedSince(input.ModifiedSince, t.now()); err != nil {
+			return filter, err
+		}
+	}
+
+	if input.MinSize != "" {
+		if filter.minSize, err = parseFileSize(input.MinSize); err != nil {
+			return filter, fmt.Errorf("invalid min_size: %w", err)
+		}
+	}
+	if input.MaxSize != "" {
+		if filter.maxSize, err = parseFileSize(input.MaxSize); err != nil {
+			return filter, fmt.Errorf("invalid max_size: %w", err)
+		}
+	}
+
+	switch filter.fileType {
+	case "":
+		filter.fileType = "file"
+	case "file", "directory", "any":
+	default:
+		return filter, fmt.Errorf("invalid type: %s (must be 'file', 'directory' or 'any')", input.Type)
+	}
+
+	switch input.Sort {
+	case "", "path", "modified", "size":
+	default:
+		return filter, fmt.Errorf("invalid sort: %s (must be 'path', 'modified' or 'size')", input.Sort)
+	}
+
+	return filter, nil
+}
+
+// find returns the entries below rootPath that pass the filter, from the
+// index when it can answer and by walking otherwise.
+func (t *FindFilesTool) find(ctx context.Context, rootPath string, filter findFilter) ([]findMatch, error) {
+	if t.index != nil {
+		if entries, ok := t.index.Entries(rootPath); ok {
+			return t.findIndexed(ctx, rootPath, entries, filter)
+		}
+	}
+
+	var matches []findMatch
+
+	err := filepath.WalkDir(rootPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip entries with errors
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		// Skip the root directory itself
+		if path == rootPath {
+			return nil
+		}
+
+		// Check if path is within workspace (security check)
+		if !t.guard.IsWithinWorkspace(path) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Skip ignored paths
+		if t.guard.ShouldIgnore(path) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relPath, err := filepath.Rel(rootPath, path)
+		if err != nil {
+			return nil
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if filter.exclude != nil && filter.exclude.Match(relPath) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !filter.wantsType(entry.IsDir()) || !filter.include.Match(relPath) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil // Skip entries we can't stat
+		}
+		if !filter.since.IsZero() && info.ModTime().Before(filter.since) {
+			return nil
+		}
+		if !entry.IsDir() && (info.Size() < filter.minSize || (filter.maxSize >= 0 && info.Size() > filter.maxSize)) {
+			return nil
+		}
+
+		matches = append(matches, findMatch{
+			Path:    path,
+			IsDir:   entry.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+
+	return matches, err
+}
+
+// findIndexed filters the indexed entries below rootPath the way find's walk
+// does: an excluded directory excludes everything below it.
+func (t *FindFilesTool) findIndexed(ctx context.Context, rootPath string, entries []fileindex.Entry, filter findFilter) ([]findMatch, error) {
+	excludedDirs := make(map[string]bool)
+	excluded := func(relPath string) bool {
+		if filter.exclude == nil {
+			return false
+		}
+		for i := range len(relPath) {
+			if relPath[i] != '/' {
+				continue
+			}
+			dir := relPath[:i]
+			isExcluded, seen := excludedDirs[dir]
+			if !seen {
+				isExcluded = filter.exclude.Match(dir)
+				excludedDirs[dir] = isExcluded
+			}
+			if isExcluded {
+				return true
+			}
+		}
+		return filter.exclude.Match(relPath)
+	}
+
+	var matches []findMatch
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		relPath, err := filepath.Rel(rootPath, entry.Path)
+		if err != nil {
+			continue
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if excluded(relPath) || !filter.wantsType(entry.IsDir) || !filter.include.Match(relPath) {
+			continue
+		}
+		if !filter.since.IsZero() && entry.ModTime.Before(filter.since) {
+			continue
+		}
+		if !entry.IsDir && (entry.Size < filter.minSize || (filter.maxSize >= 0 && entry.Size > filter.maxSize)) {
+			continue
+		}
+
+		matches = append(matches, findMatch{
+			Path:    entry.Path,
+			IsDir:   entry.IsDir,
+			Size:    entry.Size,
+			ModTime: entry.ModTime,
+		})
+	}
+	return matches, nil
+}
+
+// wantsType reports whether entries of the given kind are returned.
+func (f findFilter) wantsType(isDir bool) bool {
+	switch f.fileType {
+	case "directory":
+		return isDir
+	case "any":
+		return true
+	default:
+		return !isDir
+	}
+}
+
+// sortFindMatches orders matches by path, newest first or largest first.
+func sortFindMatches(matches []findMatch, order string) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		switch order {
+		case "modified":
+			if !matches[i].ModTime.Equal(matches[j].ModTime) {
+				return matches[i].ModTime.After(matches[j].ModTime)
+			}
+		case "size":
+			if matches[i].Size != matches[j].Size {
+				return matches[i].Size > matches[j].Size
+			}
+		}
+		return matches[i].Path < matches[j].Path
+	})
+}
+
+// formatMatches formats found paths into a readable string.
+func (t *FindFilesTool) formatMatches(matches []findMatch, total int) string {
+	if len(matches) == 0 {
+		return "No files found"
+	}
+
+	var builder strings.Builder
+	for _, match := range matches {
+		relPath, err := t.guard.MakeRelative(match.Path)
+		if err != nil {
+			relPath = match.Path
+		}
+
+		modified := match.ModTime.Format("2006-01-02 15:04")
+		if match.IsDir {
+			fmt.Fprintf(&builder, "▸ %s/ (modified %s)\n", relPath, modified)
+		} else {
+			fmt.Fprintf(&builder, "· %s (%s, modified %s)\n", relPath, formatFileSize(match.Size), modified)
+		}
+	}
+
+	if total > len(matches) {
+		fmt.Fprintf(&builder, "\nFound %d paths, showing the first %d. Narrow the pattern or raise max_results to see more.", total, len(matches))
+	} else {
+		fmt.Fprintf(&builder, "\nFound %d paths", total)
+	}
+
+	return builder.String()
+}
+
+// pathGlob matches slash-separated relative paths. Patterns without a slash
+// match the base name; a leading "**/" also matches at the top level.
+type pathGlob struct {
+	globs    []glob.Glob
+	baseName bool
+}
+
+// compilePathGlob compiles a find_files glob pattern.
+func compilePathGlob(pattern string) (pathGlob, error) {
+	pattern = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(pattern)), "./")
+	patterns := []string{pattern}
+	if rest, ok := strings.CutPrefix(pattern, "**/"); ok {
+		patterns = append(patterns, rest)
+	}
+
+	pg := pathGlob{baseName: !strings.Contains(pattern, "/")}
+	for _, p := range patterns {
+		g, err := glob.Compile(p, '/')
+		if err != nil {
+			return pathGlob{}, err
+		}
+		pg.globs = append(pg.globs, g)
+	}
+	return pg, nil
+}
+
+// Match reports whether the relative path matches the pattern.
+func (pg pathGlob) Match(relPath string) bool {
+	if pg.baseName {
+		relPath = relPath[strings.LastIndex(relPath, "/")+1:]
+	}
+	for _, g := range pg.globs {
+		if g.Match(relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseModifiedSince parses a duration before now ("24h", "7d") or a date
+// ("2006-01-02" or RFC 3339) into the earliest modification time to return.
+func parseModifiedSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.ParseFloat(days, 64); err == nil && n >= 0 {
+			return now.Add(-time.Duration(n * float64(24*time.Hour))), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid modified_since: %s (use a duration like '24h' or '7d', or a date like '2024-05-01')", value)
+}
+
+// parseFileSize parses a size in bytes with an optional B, KB, MB or GB
+// suffix, using the same 1024-based units as formatFileSize.
+func parseFileSize(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := float64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   float64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if rest, ok := strings.CutSuffix(s, unit.suffix); ok {
+			s, multiplier = strings.TrimSpace(rest), unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a size (e.g., '500', '10KB', '1.5MB')", value)
+	}
+	return int64(n * multiplier), nil
+}
@@ -0,0 +1,206 @@
+package coding
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/coding/fileindex"
+)
+
+// setupFindFilesDir creates a small tree with files of known sizes and
+// modification times
+func setupFindFilesDir(t *testing.T) string {
+	tmpDir, cleanup := setupTestDir(t)
+	t.Cleanup(cleanup)
+
+	files := map[string]string{
+		"main.go":                   "package main",
+		"README.md":                 strings.Repeat("x", 2048),
+		"pkg/api/handler.go":        "package api",
+		"pkg/api/handler_test.go":   "package api",
+		"pkg/api/testdata/fixture":  "data",
+		"cmd/tool/main.go":          "package main",
+		"config/app.yaml":           "a: 1",
+		"config/db.yml":             "b: 2",
+		"config/nested/old_test.go": "package nested",
+	}
+	for path, content := range files {
+		full := filepath.Join(tmpDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, full, content)
+	}
+
+	old := time.Now().Add(-72 * time.Hour)
+	for _, path := range []string{"README.md", "config/nested/old_test.go"} {
+		if err := os.Chtimes(filepath.Join(tmpDir, filepath.FromSlash(path)), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tmpDir
+}
+
+// findPaths runs find_files and returns the listed paths
+func findPaths(t *testing.T, tool *FindFilesTool, args string) []string {
+	t.Helper()
+	result, _, err := tool.Execute(context.Background(), []byte("<arguments>"+args+"</arguments>"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(result, "\n") {
+		if rest, ok := strings.CutPrefix(line, "· "); ok {
+			paths = append(paths, rest[:strings.Index(rest, " (")])
+		} else if rest, ok := strings.CutPrefix(line, "▸ "); ok {
+			paths = append(paths, rest[:strings.Index(rest, " (")])
+		}
+	}
+	return paths
+}
+
+func TestFindFilesTool_Patterns(t *testing.T) {
+	tmpDir := setupFindFilesDir(t)
+	tool := NewFindFilesTool(createWorkspaceGuard(t, tmpDir))
+
+	tests := []struct {
+		name string
+		args string
+		want []string
+	}{
+		{"base name at any depth", "<pattern>main.go</pattern>", []string{"cmd/tool/main.go", "main.go"}},
+		{"base name glob", "<pattern>*_test.go</pattern>", []string{"config/nested/old_test.go", "pkg/api/handler_test.go"}},
+		{"double star", "<pattern>**/*.go</pattern><path>pkg</path>", []string{"pkg/api/handler.go", "pkg/api/handler_test.go"}},
+		{"single star stays in one directory", "<pattern>*/*.go</pattern>", nil},
+		{"path glob", "<pattern>cmd/*/main.go</pattern>", []string{"cmd/tool/main.go"}},
+		{"alternatives", "<pattern>*.{yaml,yml}</pattern>", []string{"config/app.yaml", "config/db.yml"}},
+		{"exclude", "<pattern>*</pattern><path>pkg</path><exclude>**/testdata/**</exclude>", []string{"pkg/api/handler.go", "pkg/api/handler_test.go"}},
+		{"directories", "<pattern>api</pattern><type>directory</type>", []string{"pkg/api/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findPaths(t, tool, tt.args)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFindFilesTool_Filters(t *testing.T) {
+	tmpDir := setupFindFilesDir(t)
+	tool := NewFindFilesTool(createWorkspaceGuard(t, tmpDir))
+
+	if got := findPaths(t, tool, "<pattern>*</pattern><modified_since>1d</modified_since><path>config</path>"); len(got) != 2 {
+		t.Errorf("expected the two recent config files, got %v", got)
+	}
+	if got := findPaths(t, tool, "<pattern>*</pattern><min_size>1KB</min_size>"); len(got) != 1 || got[0] != "README.md" {
+		t.Errorf("expected only README.md to be at least 1KB, got %v", got)
+	}
+	if got := findPaths(t, tool, "<pattern>*.md</pattern><max_size>1KB</max_size>"); len(got) != 0 {
+		t.Errorf("expected no markdown files under 1KB, got %v", got)
+	}
+	if got := findPaths(t, tool, "<pattern>*</pattern><sort>size</sort><max_results>1</max_results>"); len(got) != 1 || got[0] != "README.md" {
+		t.Errorf("expected the largest file first, got %v", got)
+	}
+}
+
+func TestFindFilesTool_Metadata(t *testing.T) {
+	tmpDir := setupFindFilesDir(t)
+	tool := NewFindFilesTool(createWorkspaceGuard(t, tmpDir))
+
+	result, metadata, err := tool.Execute(context.Background(), []byte("<arguments><pattern>*.go</pattern><max_results>2</max_results></arguments>"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if metadata["match_count"] != 5 || metadata["truncated"] != true {
+		t.Errorf("expected 5 truncated matches, got %v", metadata)
+	}
+	if !strings.Contains(result, "Found 5 paths, showing the first 2") {
+		t.Errorf("expected truncation notice, got: %s", result)
+	}
+}
+
+func TestFindFilesTool_InvalidArguments(t *testing.T) {
+	tmpDir := setupFindFilesDir(t)
+	tool := NewFindFilesTool(createWorkspaceGuard(t, tmpDir))
+
+	tests := []struct {
+		name    string
+		args    string
+		wantErr string
+	}{
+		{"missing pattern", "", "missing required parameter: pattern"},
+		{"bad pattern", "<pattern>[a-</pattern>", "invalid pattern"},
+		{"bad modified_since", "<pattern>*</pattern><modified_since>yesterday</modified_since>", "invalid modified_since"},
+		{"bad size", "<pattern>*</pattern><min_size>big</min_size>", "invalid min_size"},
+		{"bad type", "<pattern>*</pattern><type>link</type>", "invalid type"},
+		{"outside workspace", "<pattern>*</pattern><path>../</path>", "invalid path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tool.Execute(context.Background(), []byte("<arguments>"+tt.args+"</arguments>"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseFileSize(t *testing.T) {
+	tests := map[string]int64{"500": 500, "10KB": 10240, "1.5mb": 1572864, "2 GB": 2 << 30, "100B": 100}
+	for input, want := range tests {
+		if got, err := parseFileSize(input); err != nil || got != want {
+			t.Errorf("parseFileSize(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+}
+
+// newReadyIndex indexes the guard's workspace and waits until it can answer
+func newReadyIndex(t *testing.T, guard *workspace.Guard) *fileindex.Index {
+	t.Helper()
+	index, err := fileindex.New(guard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = index.Close() })
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !index.Ready() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the file index")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return index
+}
+
+func TestFindFilesTool_IndexMatchesWalk(t *testing.T) {
+	tmpDir := setupFindFilesDir(t)
+	guard := createWorkspaceGuard(t, tmpDir)
+	walking := NewFindFilesTool(guard)
+	indexed := NewFindFilesTool(guard)
+	indexed.SetIndex(newReadyIndex(t, guard))
+
+	for _, args := range []string{
+		"<pattern>*.go</pattern>",
+		"<pattern>**/*.go</pattern><path>pkg</path>",
+		"<pattern>*</pattern><exclude>testdata</exclude>",
+		"<pattern>*</pattern><exclude>config/*</exclude><type>any</type>",
+		"<pattern>*</pattern><modified_since>1d</modified_since><sort>size</sort>",
+		"<pattern>*</pattern><min_size>1KB</min_size>",
+	} {
+		want := findPaths(t, walking, args)
+		if got := findPaths(t, indexed, args); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: indexed %v, walked %v", args, got, want)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package coding
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Strategies apply_diff uses to locate an edit's search text, tried in order
+const (
+	MatchExact      = "exact"      // The search text appears verbatim
+	MatchWhitespace = "whitespace" // The lines match once whitespace is normalized
+	MatchSimilar    = "similar"    // The lines are similar enough by edit distance
+)
+
+// FuzzyMatchThreshold is the similarity a block of lines must reach for a
+// search text that was not found exactly to be matched to it.
+const FuzzyMatchThreshold = 0.9
+
+// fuzzyMatchMargin is how much better than every other candidate the best
+// similar block must be; closer runners-up make the match ambiguous.
+const fuzzyMatchMargin = 0.05
+
+// EditMatch describes how an edit's search text was located in the file.
+type EditMatch struct {
+	Edit      int     `json:"edit"` // 1-based
+	Strategy  string  `json:"strategy"`
+	Ratio     float64 `json:"ratio"`      // Similarity of the matched text, 1 for exact and whitespace matches
+	StartLine int     `json:"start_line"` // 1-based first line of the match
+}
+
+// fileLine is a line of the file being edited, by byte offsets into it.
+// end excludes the newline.
+type fileLine struct {
+	start, end int
+}
+
+// splitFileLines indexes the lines of content.
+func splitFileLines(content string) []fileLine {
+	var lines []fileLine
+	start := 0
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			lines = append(lines, fileLine{start, i})
+			start = i + 1
+		}
+	}
+	return append(lines, fileLine{start, len(content)})
+}
+
+// normalizeWhitespace collapses runs of whitespace to a single space and
+// trims both ends, so indentation, tab/space and trailing whitespace drift
+// compare equal.
+func normalizeWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// lineMatch is a block of file lines matched to a search text.
+type lineMatch struct {
+	first, count int // Lines of the block
+	ratio        float64
+}
+
+// locateEdit finds where search applies in content: verbatim if it appears
+// there, otherwise the one block of lines it matches ignoring whitespace, or
+// failing that the one block similar enough to it. It returns the byte range
+// to replace and, for fuzzy matches, replace reindented to the file's
+// indentation.
+func locateEdit(content string, edit DiffEdit, index int) (start, end int, replace string, match EditMatch, err error) {
+	match = EditMatch{Edit: index, Strategy: MatchExact, Ratio: 1}
+	switch count := strings.Count(content, edit.Search); {
+	case count == 1:
+		start = strings.Index(content, edit.Search)
+		match.StartLine = strings.Count(content[:start], "\n") + 1
+		return start, start + len(edit.Search), edit.Replace, match, nil
+	case count > 1:
+		return 0, 0, "", match, fmt.Errorf("edit %d: search text appears %d times in file, must be unique. When multiple matches exist, the diff cannot determine which occurrence to modify.\n\nRecovery steps:\n1. Use read_file with appropriate line ranges to examine each occurrence\n2. Include more surrounding context in your search text to make it unique\n3. Make the search pattern more specific by including nearby code (function signature, variable declarations, etc.)\n4. Consider splitting into multiple smaller, targeted edits with unique search patterns\n5. Avoid overly generic patterns that match multiple locations\n\nExample: Instead of searching for 'return err', include the surrounding function context to make it unique", index, count)
+	}
+
+	// A search text ending in a newline replaces the newline after its
+	// last line too
+	search := edit.Search
+	trailingNewline := strings.HasSuffix(search, "\n")
+	if trailingNewline {
+		search = strings.TrimSuffix(search, "\n")
+	}
+	searchLines := strings.Split(search, "\n")
+	lines := splitFileLines(content)
+
+	matches, best := findLineMatches(content, lines, searchLines)
+	switch {
+	case len(matches) > 1 && matches[0].ratio == 1:
+		return 0, 0, "", match, fmt.Errorf("edit %d: search text was not found exactly and matches %d places once whitespace is ignored, so it is ambiguous. Include more surrounding context to make it unique, and copy the text from read_file output character-for-character", index, len(matches))
+	case len(matches) > 1:
+		return 0, 0, "", match, fmt.Errorf("edit %d: search text was not found exactly and is similar to %d places (lines %d and %d are the closest), so it is ambiguous. Use read_file to view the current content and copy the text to replace exactly", index, len(matches), matches[0].first+1, matches[1].first+1)
+	case len(matches) == 0:
+		msg := fmt.Sprintf("edit %d: search text not found in file. The file content may have changed or the search pattern doesn't match exactly.\n\nRecovery steps:\n1. Use read_file to view the current file content (consider reading more context lines to understand the structure)\n2. Verify the exact text including whitespace, indentation, and line breaks\n3. Try a smaller, more focused edit targeting a unique code pattern\n4. Ensure your search text matches the actual file content character-for-character", index)
+		if best.ratio >= 0.5 {
+			msg += fmt.Sprintf("\n\nClosest match: lines %d-%d, %.0f%% similar (%.0f%% needed)", best.first+1, best.first+best.count, best.ratio*100, FuzzyMatchThreshold*100)
+		}
+		return 0, 0, "", match, fmt.Errorf("%s\n\nSearch text that failed:\n%s", msg, edit.Search)
+	}
+
+	found := matches[0]
+	first, last := lines[found.first], lines[found.first+found.count-1]
+	start, end = first.start, last.end
+	if trailingNewline && end < len(content) {
+		end++
+	}
+	if found.ratio < 1 {
+		match.Strategy = MatchSimilar
+	} else {
+		match.Strategy = MatchWhitespace
+	}
+	match.Ratio = found.ratio
+	match.StartLine = found.first + 1
+
+	fileLines := make([]string, found.count)
+	for i := range fileLines {
+		line := lines[found.first+i]
+		fileLines[i] = content[line.start:line.end]
+	}
+	return start, end, reindent(edit.Replace, searchLines, fileLines), match, nil
+}
+
+// findLineMatches returns the blocks of lines matching searchLines once
+// whitespace is normalized or, when there are none, the blocks at least
+// FuzzyMatchThreshold similar to them, best first. A similar block is only
+// returned alone when it beats every other by fuzzyMatchMargin. best is the
+// most similar block, for reporting a near miss.
+func findLineMatches(content string, lines []fileLine, searchLines []string) (matches []lineMatch, best lineMatch) {
+	n := len(searchLines)
+	if n > len(lines) {
+		return nil, best
+	}
+	want := make([]string, n)
+	for i, line := range searchLines {
+		want[i] = normalizeWhitespace(line)
+	}
+	have := make([]string, len(lines))
+	for i, line := range lines {
+		have[i] = normalizeWhitespace(content[line.start:line.end])
+	}
+
+	var similar []lineMatch
+	for first := 0; first+n <= len(have); first++ {
+		ratio := blockSimilarity(have[first:first+n], want, best.ratio)
+		if ratio == 1 {
+			matches = append(matches, lineMatch{first, n, 1})
+		} else if ratio >= FuzzyMatchThreshold-fuzzyMatchMargin {
+			similar = append(similar, lineMatch{first, n, ratio})
+		}
+		if ratio > best.ratio {
+			best = lineMatch{first, n, ratio}
+		}
+	}
+	if len(matches) > 0 {
+		return matches, best
+	}
+	if best.ratio < FuzzyMatchThreshold {
+		return nil, best
+	}
+
+	// Runners-up close to the best block make it ambiguous
+	matches = []lineMatch{best}
+	for _, candidate := range similar {
+		if candidate.first != best.first && best.ratio-candidate.ratio < fuzzyMatchMargin {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches, best
+}
+
+// blockSimilarity compares blocks of normalized lines by edit distance,
+// returning 1 minus the distance over the length of the longer block. It
+// gives up, returning 0, once the block cannot beat floor, the best
+// similarity found so far, or come within fuzzyMatchMargin of the threshold.
+func blockSimilarity(have, want []string, floor float64) float64 {
+	total := 0
+	for i := range want {
+		total += max(utf8.RuneCountInString(have[i]), utf8.RuneCountInString(want[i]))
+	}
+	if total == 0 {
+		return 1
+	}
+
+	floor = min(floor, FuzzyMatchThreshold-fuzzyMatchMargin)
+	budget := int((1 - floor) * float64(total))
+	distance := 0
+	for i := range want {
+		if have[i] == want[i] {
+			continue
+		}
+		distance += levenshtein(have[i], want[i])
+		if distance > budget {
+			return 0
+		}
+	}
+	return 1 - float64(distance)/float64(total)
+}
+
+// levenshtein returns the edit distance between a and b in runes.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current := row[j]
+			row[j] = min(row[j]+1, row[j-1]+1, prev+cost)
+			prev = current
+		}
+	}
+	return row[len(rb)]
+}
+
+// reindent converts replace from the indentation of the search text to the
+// file's, judged by the first non-blank search line and the file line it
+// matched: spaces become tabs (or tabs spaces) at the same ratio, and any
+// other difference swaps the leading indentation.
+func reindent(replace string, searchLines, fileLines []string) string {
+	var from, to string
+	for i, line := range searchLines {
+		if strings.TrimSpace(line) != "" {
+			from, to = leadingWhitespace(line), leadingWhitespace(fileLines[i])
+			break
+		}
+	}
+	if from == to {
+		return replace
+	}
+
+	lines := strings.Split(replace, "\n")
+	for i, line := range lines {
+		indent := leadingWhitespace(line)
+		if indent == "" {
+			continue
+		}
+		lines[i] = convertIndent(indent, from, to) + line[len(indent):]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// convertIndent maps indent from the search text's indentation style, seen
+// as from, to the file's, seen as to.
+func convertIndent(indent, from, to string) string {
+	spaces, tabs := strings.Trim(from, " ") == "", strings.Trim(to, "\t") == ""
+	switch {
+	case spaces && tabs && len(from)%len(to) == 0 && strings.Trim(indent, " ") == "":
+		unit := len(from) / len(to)
+		return strings.Repeat("\t", len(indent)/unit) + strings.Repeat(" ", len(indent)%unit)
+	case strings.Trim(from, "\t") == "" && strings.Trim(to, " ") == "" && len(to)%len(from) == 0 && strings.Trim(indent, "\t") == "":
+		return strings.Repeat(" ", len(indent)*(len(to)/len(from)))
+	case strings.HasPrefix(indent, from):
+		return to + indent[len(from):]
+	default:
+		return indent
+	}
+}
+
+// leadingWhitespace returns the spaces and tabs s starts with.
+func leadingWhitespace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
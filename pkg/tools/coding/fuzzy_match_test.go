@@ -0,0 +1,160 @@
+package coding
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyEdits_WhitespaceMatch(t *testing.T) {
+	content := "func main() {\n\tif ok {  \n\t\trun()\n\t}\n}\n"
+	edits := []DiffEdit{{
+		Search:  "    if ok {\n        run()\n    }",
+		Replace: "    if ok {\n        run()\n        stop()\n    }",
+	}}
+
+	modified, changes, matches, err := ApplyEdits(content, edits)
+	if err != nil {
+		t.Fatalf("ApplyEdits failed: %v", err)
+	}
+
+	// The replacement takes on the file's tab indentation
+	want := "func main() {\n\tif ok {\n\t\trun()\n\t\tstop()\n\t}\n}\n"
+	if modified != want {
+		t.Errorf("Expected %q, got %q", want, modified)
+	}
+	if changes.LinesAdded != 1 || changes.LinesRemoved != 0 {
+		t.Errorf("Expected 1 line added, got %+v", changes)
+	}
+	if len(matches) != 1 || matches[0].Strategy != MatchWhitespace || matches[0].Ratio != 1 || matches[0].StartLine != 2 {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+}
+
+func TestApplyEdits_SimilarMatch(t *testing.T) {
+	content := "func total(items []Item) int {\n\tsum := 0\n\tfor _, item := range items {\n\t\tsum += item.Price\n\t}\n\treturn sum\n}\n"
+	edits := []DiffEdit{{
+		Search:  "\tsum := 0\n\tfor _, item := range items {\n\t\tsum += item.price\n\t}",
+		Replace: "\tsum := 0\n\tfor _, item := range items {\n\t\tsum += item.Price * item.Quantity\n\t}",
+	}}
+
+	modified, _, matches, err := ApplyEdits(content, edits)
+	if err != nil {
+		t.Fatalf("ApplyEdits failed: %v", err)
+	}
+	if !strings.Contains(modified, "sum += item.Price * item.Quantity\n") || strings.Contains(modified, "sum += item.Price\n") {
+		t.Errorf("Expected the similar block to be replaced, got %q", modified)
+	}
+	if len(matches) != 1 || matches[0].Strategy != MatchSimilar || matches[0].Ratio < FuzzyMatchThreshold || matches[0].Ratio >= 1 {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+}
+
+func TestApplyEdits_BelowThreshold(t *testing.T) {
+	content := "func greet() {\n\tfmt.Println(\"hello\")\n}\n"
+	edits := []DiffEdit{{
+		Search:  "func greet() {\n\tlog.Printf(\"hi %s\", name)\n}",
+		Replace: "func greet() {}",
+	}}
+
+	_, _, _, err := ApplyEdits(content, edits)
+	if err == nil {
+		t.Fatal("Expected an error for a search text below the similarity threshold")
+	}
+	if !strings.Contains(err.Error(), "search text not found") || !strings.Contains(err.Error(), "Closest match: lines 1-3") {
+		t.Errorf("Expected a not found error with the closest match, got: %v", err)
+	}
+}
+
+func TestApplyEdits_AmbiguousWhitespaceMatch(t *testing.T) {
+	content := "if err != nil {\n\treturn err\n}\nif err != nil {\n\treturn err\n}\n"
+	edits := []DiffEdit{{
+		Search:  "if err != nil {\n  return err\n}",
+		Replace: "if err != nil {\n  return nil\n}",
+	}}
+
+	_, _, _, err := ApplyEdits(content, edits)
+	if err == nil || !strings.Contains(err.Error(), "matches 2 places") {
+		t.Errorf("Expected an ambiguous match error, got: %v", err)
+	}
+}
+
+func TestApplyDiffTool_FuzzyMatchMetadata(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	testFile := filepath.Join(tmpDir, "test.go")
+	writeTestFile(t, testFile, "package main\n\nconst name = \"forge\"   \n")
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	tool := NewApplyDiffTool(guard)
+
+	xmlInput := `<arguments>
+	<path>test.go</path>
+	<edits>
+		<edit>
+			<search>package main</search>
+			<replace>package app</replace>
+		</edit>
+		<edit>
+			<search>const name = "forge"</search>
+			<replace>const name = "anvil"</replace>
+		</edit>
+	</edits>
+</arguments>`
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// The exact search matches within the line, so the trailing whitespace stays
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "package app\n\nconst name = \"anvil\"   \n" {
+		t.Errorf("Unexpected content: %q", string(content))
+	}
+	if metadata["match_ratio"].(float64) != 1 {
+		t.Errorf("Expected match_ratio=1, got %v", metadata["match_ratio"])
+	}
+	if _, ok := metadata["fuzzy_matches"]; ok {
+		t.Errorf("Expected no fuzzy_matches for exact edits, got %v", metadata["fuzzy_matches"])
+	}
+	if strings.Contains(result, "matched") {
+		t.Errorf("Expected no match notes for exact edits, got: %s", result)
+	}
+
+	xmlInput = `<arguments>
+	<path>test.go</path>
+	<edits>
+		<edit>
+			<search>package app
+
+const name = "anvl"</search>
+			<replace>package app
+
+const name = "hammer"</replace>
+		</edit>
+	</edits>
+</arguments>`
+
+	result, metadata, err = tool.Execute(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	ratio := metadata["match_ratio"].(float64)
+	if ratio < FuzzyMatchThreshold || ratio >= 1 {
+		t.Errorf("Expected a fuzzy match_ratio, got %v", ratio)
+	}
+	fuzzy := metadata["fuzzy_matches"].([]EditMatch)
+	if len(fuzzy) != 1 || fuzzy[0].Edit != 1 || fuzzy[0].Strategy != MatchSimilar {
+		t.Errorf("Unexpected fuzzy_matches: %+v", fuzzy)
+	}
+	if !strings.Contains(result, "edit 1 matched line 1 at") {
+		t.Errorf("Expected the fuzzy match to be noted, got: %s", result)
+	}
+}
@@ -0,0 +1,254 @@
+package coding
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// MoveFileTool moves or renames files and directories within the workspace.
+type MoveFileTool struct {
+	guard *workspace.Guard
+}
+
+// NewMoveFileTool creates a new MoveFileTool with workspace security.
+func NewMoveFileTool(guard *workspace.Guard) *MoveFileTool {
+	return &MoveFileTool{
+		guard: guard,
+	}
+}
+
+// Name returns the tool name.
+func (t *MoveFileTool) Name() string {
+	return "move_file"
+}
+
+// Description returns the tool description.
+func (t *MoveFileTool) Description() string {
+	return "Move or rename a file or directory. Parent directories of the destination are created as needed. " +
+		"An existing destination file is only replaced when overwrite is true, and its previous version is kept under " + BackupDir + "/. " +
+		"Use this instead of mv in execute_command: moves are checked against the workspace's write rules and tracked like other file changes. " +
+		"It does not update references to the moved file; use rename_symbol or apply_diff for those."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *MoveFileTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path to the file or directory to move (relative to workspace)",
+			},
+			"destination": map[string]any{
+				"type":        "string",
+				"description": "New path for the file or directory, including its name (relative to workspace)",
+			},
+			"overwrite": map[string]any{
+				"type":        "boolean",
+				"description": "Replace an existing destination file, keeping a backup of it (default: false)",
+			},
+		},
+		[]string{"path", "destination"},
+	)
+}
+
+// moveRequest is a validated move_file invocation.
+type moveRequest struct {
+	absPath     string
+	relPath     string
+	absDest     string
+	relDest     string
+	isDir       bool
+	overwrites  bool     // The destination is an existing file that will be replaced
+	sourceFiles []string // Workspace-relative files a directory move takes away
+	destFiles   []string // and where they end up
+}
+
+// parseRequest validates the tool arguments against the workspace and its
+// write rules, for both the source and the destination.
+func (t *MoveFileTool) parseRequest(argsXML []byte) (*moveRequest, error) {
+	var input struct {
+		XMLName     xml.Name `xml:"arguments"`
+		Path        string   `xml:"path"`
+		Destination string   `xml:"destination"`
+		Overwrite   bool     `xml:"overwrite"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if input.Path == "" {
+		return nil, fmt.Errorf("missing required parameter: path")
+	}
+	if input.Destination == "" {
+		return nil, fmt.Errorf("missing required parameter: destination")
+	}
+
+	req := &moveRequest{}
+	var err error
+	if req.absPath, req.relPath, err = resolveModifiedPath(t.guard, input.Path); err != nil {
+		return nil, err
+	}
+	if req.absDest, req.relDest, err = resolveModifiedPath(t.guard, input.Destination); err != nil {
+		return nil, err
+	}
+	if req.absPath == req.absDest {
+		return nil, fmt.Errorf("path and destination are the same")
+	}
+
+	info, err := os.Lstat(req.absPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("'%s' does not exist", req.relPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat '%s': %w", req.relPath, err)
+	}
+	req.isDir = info.IsDir()
+	if req.isDir && strings.HasPrefix(req.absDest, req.absPath+string(filepath.Separator)) {
+		return nil, fmt.Errorf("cannot move '%s' into itself", req.relPath)
+	}
+
+	if destInfo, statErr := os.Lstat(req.absDest); statErr == nil {
+		switch {
+		case destInfo.IsDir():
+			return nil, fmt.Errorf("destination '%s' is an existing directory; give the full new path, including the name", req.relDest)
+		case req.isDir:
+			return nil, fmt.Errorf("destination '%s' is an existing file", req.relDest)
+		case !input.Overwrite:
+			return nil, fmt.Errorf("destination '%s' already exists; set overwrite to true to replace it", req.relDest)
+		}
+		req.overwrites = true
+	}
+
+	if ruleErr := t.guard.CheckWrite(input.Path, 0); ruleErr != nil {
+		return nil, ruleErr
+	}
+	if ruleErr := t.guard.CheckWrite(input.Destination, info.Size()); ruleErr != nil {
+		return nil, ruleErr
+	}
+	if !req.isDir {
+		return req, nil
+	}
+
+	// Every file in the directory must be writable where it is and where it
+	// is going
+	if req.sourceFiles, err = filesUnder(t.guard, input.Path, req.absPath); err != nil {
+		return nil, err
+	}
+	for _, file := range req.sourceFiles {
+		dest := req.relDest + strings.TrimPrefix(file, req.relPath)
+		if ruleErr := t.guard.CheckWrite(dest, 0); ruleErr != nil {
+			return nil, ruleErr
+		}
+		req.destFiles = append(req.destFiles, dest)
+	}
+	return req, nil
+}
+
+// Execute moves the file or directory.
+func (t *MoveFileTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	req, err := t.parseRequest(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var backupPath string
+	if req.overwrites {
+		original, readErr := os.ReadFile(req.absDest)
+		if readErr != nil {
+			return "", nil, fmt.Errorf("failed to read destination: %w", readErr)
+		}
+		backupPath, err = backupFile(t.guard.WorkspaceDir(), req.absDest, req.relDest, string(original), time.Now())
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	if mkdirErr := os.MkdirAll(filepath.Dir(req.absDest), 0750); mkdirErr != nil {
+		return "", nil, fmt.Errorf("failed to create directories: %w", mkdirErr)
+	}
+	if renameErr := os.Rename(req.absPath, req.absDest); renameErr != nil {
+		return "", nil, fmt.Errorf("failed to move '%s': %w", req.relPath, renameErr)
+	}
+
+	modified := []string{req.relPath, req.relDest}
+	message := fmt.Sprintf("File '%s' moved to '%s'", req.relPath, req.relDest)
+	if req.isDir {
+		modified = append(append([]string{}, req.sourceFiles...), req.destFiles...)
+		message = fmt.Sprintf("Directory '%s' moved to '%s' (%d files)", req.relPath, req.relDest, len(req.sourceFiles))
+	}
+
+	metadata := map[string]any{
+		"source":         req.relPath,
+		"destination":    req.relDest,
+		"modified_files": modified,
+	}
+	if backupPath != "" {
+		message += fmt.Sprintf("; previous '%s' saved to '%s'", req.relDest, backupPath)
+		metadata["backup_path"] = backupPath
+	}
+	return message, metadata, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *MoveFileTool) IsLoopBreaking() bool {
+	return false
+}
+
+// GeneratePreview implements the Previewable interface, listing what moves
+// where and, when a file is replaced, how its content changes.
+func (t *MoveFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	req, err := t.parseRequest(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &tools.ToolPreview{
+		Type:        tools.PreviewTypeCommand,
+		Title:       fmt.Sprintf("Move %s to %s", req.relPath, req.relDest),
+		Description: fmt.Sprintf("This will move %s to %s", req.relPath, req.relDest),
+		Content:     fmt.Sprintf("%s → %s", req.relPath, req.relDest),
+		Metadata: map[string]any{
+			"file_path":   req.relPath,
+			"destination": req.relDest,
+		},
+	}
+	markOutsideWorkspace(preview, t.guard, req.absPath, req.absDest)
+
+	if req.isDir {
+		lines := make([]string, len(req.sourceFiles))
+		for i, file := range req.sourceFiles {
+			lines[i] = fmt.Sprintf("%s → %s", file, req.destFiles[i])
+		}
+		preview.Title = fmt.Sprintf("Move directory %s to %s", req.relPath, req.relDest)
+		preview.Description = fmt.Sprintf("This will move %s and its %d files to %s", req.relPath, len(req.sourceFiles), req.relDest)
+		preview.Content = strings.Join(lines, "\n")
+		return preview, nil
+	}
+
+	if req.overwrites {
+		preview.Title = fmt.Sprintf("Move %s over %s", req.relPath, req.relDest)
+		preview.Description = fmt.Sprintf("This will replace %s with %s, keeping a backup under %s", req.relDest, req.relPath, BackupDir)
+		if !isBinaryFile(req.absPath) && !isBinaryFile(req.absDest) {
+			original, readErr := os.ReadFile(req.absDest)
+			if readErr != nil {
+				return nil, fmt.Errorf("failed to read destination: %w", readErr)
+			}
+			content, readErr := os.ReadFile(req.absPath)
+			if readErr != nil {
+				return nil, fmt.Errorf("failed to read file: %w", readErr)
+			}
+			preview.Type = tools.PreviewTypeDiff
+			preview.Content = GenerateUnifiedDiff(string(original), string(content), req.relDest)
+		}
+	}
+	return preview, nil
+}
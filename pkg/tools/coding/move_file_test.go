@@ -0,0 +1,117 @@
+package coding
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+func TestMoveFileTool_File(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	writeTestFile(t, filepath.Join(tmpDir, "util.go"), "package util\n")
+	tool := NewMoveFileTool(createWorkspaceGuard(t, tmpDir))
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(`<arguments>
+	<path>util.go</path>
+	<destination>internal/text/util.go</destination>
+</arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(result, "moved to 'internal/text/util.go'") {
+		t.Errorf("Unexpected result: %s", result)
+	}
+	content, err := os.ReadFile(filepath.Join(tmpDir, "internal", "text", "util.go"))
+	if err != nil || string(content) != "package util\n" {
+		t.Errorf("Expected the file at its destination, got %q (%v)", content, err)
+	}
+	if _, statErr := os.Stat(filepath.Join(tmpDir, "util.go")); !os.IsNotExist(statErr) {
+		t.Error("Expected util.go to be gone")
+	}
+	files, _ := metadata["modified_files"].([]string)
+	if len(files) != 2 || files[0] != "util.go" || files[1] != "internal/text/util.go" {
+		t.Errorf("Expected both paths reported, got %v", metadata["modified_files"])
+	}
+}
+
+func TestMoveFileTool_Overwrite(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	writeTestFile(t, filepath.Join(tmpDir, "new.txt"), "new\n")
+	writeTestFile(t, filepath.Join(tmpDir, "old.txt"), "old\n")
+	tool := NewMoveFileTool(createWorkspaceGuard(t, tmpDir))
+
+	xmlInput := `<arguments><path>new.txt</path><destination>old.txt</destination></arguments>`
+	if _, _, err := tool.Execute(context.Background(), []byte(xmlInput)); err == nil || !strings.Contains(err.Error(), "set overwrite") {
+		t.Fatalf("Expected an existing destination to need overwrite, got %v", err)
+	}
+
+	xmlInput = `<arguments><path>new.txt</path><destination>old.txt</destination><overwrite>true</overwrite></arguments>`
+	preview, err := tool.GeneratePreview(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("GeneratePreview failed: %v", err)
+	}
+	if preview.Type != tools.PreviewTypeDiff || !strings.Contains(preview.Content, "-old") || !strings.Contains(preview.Content, "+new") {
+		t.Errorf("Expected a diff of the replaced file, got %s: %s", preview.Type, preview.Content)
+	}
+
+	_, metadata, err := tool.Execute(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	backupPath, _ := metadata["backup_path"].(string)
+	backup, err := os.ReadFile(filepath.Join(tmpDir, filepath.FromSlash(backupPath)))
+	if !strings.HasPrefix(backupPath, BackupDir+"/") || err != nil || string(backup) != "old\n" {
+		t.Errorf("Expected the replaced file in %s, got %q: %q (%v)", BackupDir, backupPath, backup, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(tmpDir, "old.txt")); string(content) != "new\n" {
+		t.Errorf("Expected old.txt replaced, got %q", content)
+	}
+}
+
+func TestMoveFileTool_Directory(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, "pkg", "util"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(tmpDir, "pkg", "util", "a.go"), "a")
+	guard := createWorkspaceGuard(t, tmpDir)
+	tool := NewMoveFileTool(guard)
+
+	if _, _, err := tool.Execute(context.Background(), []byte(`<arguments><path>pkg</path><destination>pkg/util/pkg</destination></arguments>`)); err == nil || !strings.Contains(err.Error(), "into itself") {
+		t.Errorf("Expected a move into itself to be rejected, got %v", err)
+	}
+
+	// A directory cannot be moved where its files would be protected
+	if err := guard.SetPathRules(workspace.PathRules{DenyWrite: []string{"vendor/**"}}); err != nil {
+		t.Fatalf("SetPathRules failed: %v", err)
+	}
+	_, _, err := tool.Execute(context.Background(), []byte(`<arguments><path>pkg/util</path><destination>vendor/util</destination></arguments>`))
+	var ruleErr *workspace.PathRuleError
+	if !errors.As(err, &ruleErr) {
+		t.Fatalf("Expected a PathRuleError, got %v", err)
+	}
+
+	_, metadata, err := tool.Execute(context.Background(), []byte(`<arguments><path>pkg/util</path><destination>internal/util</destination></arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(tmpDir, "internal", "util", "a.go")); statErr != nil {
+		t.Errorf("Expected a.go under internal/util, got %v", statErr)
+	}
+	files, _ := metadata["modified_files"].([]string)
+	if len(files) != 2 || files[0] != "pkg/util/a.go" || files[1] != "internal/util/a.go" {
+		t.Errorf("Expected the file on both sides reported, got %v", metadata["modified_files"])
+	}
+}
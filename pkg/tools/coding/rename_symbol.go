@@ -0,0 +1,351 @@
+package coding
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// RenameSymbolTool performs type-aware renames of Go identifiers across the
+// workspace by driving gopls, so every reference is updated and textually
+// similar identifiers are left alone.
+type RenameSymbolTool struct {
+	guard   *workspace.Guard
+	timeout time.Duration
+}
+
+// NewRenameSymbolTool creates a new RenameSymbolTool with workspace security.
+func NewRenameSymbolTool(guard *workspace.Guard) *RenameSymbolTool {
+	return &RenameSymbolTool{
+		guard:   guard,
+		timeout: 2 * time.Minute, // gopls type-checks the whole workspace before renaming
+	}
+}
+
+// Name returns the tool name.
+func (t *RenameSymbolTool) Name() string {
+	return "rename_symbol"
+}
+
+// Description returns the tool description.
+func (t *RenameSymbolTool) Description() string {
+	return "Rename a Go identifier (variable, function, type, method, field, package-level constant, etc.) everywhere it is referenced in the workspace, using gopls for type-aware resolution. " +
+		"Prefer this over apply_diff for renames: it updates every reference, including in other packages, and never touches unrelated identifiers that happen to share the name. Returns the list of files changed."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *RenameSymbolTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path to a Go file containing the symbol, at its declaration or any reference (relative to workspace)",
+			},
+			"line": map[string]any{
+				"type":        "integer",
+				"description": "Line number (1-based) in that file where the symbol appears",
+			},
+			"symbol": map[string]any{
+				"type":        "string",
+				"description": "Current name of the symbol. If it appears more than once on the line, the first occurrence is used",
+			},
+			"new_name": map[string]any{
+				"type":        "string",
+				"description": "New name for the symbol; must be a valid Go identifier",
+			},
+		},
+		[]string{"path", "line", "symbol", "new_name"},
+	)
+}
+
+// renameRequest is a validated rename_symbol invocation.
+type renameRequest struct {
+	relPath  string
+	oldName  string
+	newName  string
+	position string // gopls position: <abs path>:#<byte offset>
+}
+
+// parseRequest validates the tool arguments and locates the symbol.
+func (t *RenameSymbolTool) parseRequest(argsXML []byte) (*renameRequest, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Path    string   `xml:"path"`
+		Line    int      `xml:"line"`
+		Symbol  string   `xml:"symbol"`
+		NewName string   `xml:"new_name"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	input.Symbol = strings.TrimSpace(input.Symbol)
+	input.NewName = strings.TrimSpace(input.NewName)
+
+	if input.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	if filepath.Ext(input.Path) != ".go" {
+		return nil, fmt.Errorf("path must be a Go source file")
+	}
+	if input.Line < 1 {
+		return nil, fmt.Errorf("line must be a positive line number")
+	}
+	if input.Symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	if !token.IsIdentifier(input.NewName) {
+		return nil, fmt.Errorf("new_name %q is not a valid Go identifier", input.NewName)
+	}
+	if input.NewName == input.Symbol {
+		return nil, fmt.Errorf("new_name is the same as the current name")
+	}
+
+	if err := t.guard.ValidatePath(input.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	absPath, err := t.guard.ResolvePath(input.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	offset, err := findSymbolOffset(content, input.Line, input.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	relPath, err := t.guard.MakeRelative(absPath)
+	if err != nil || relPath == "" {
+		relPath = input.Path
+	}
+
+	return &renameRequest{
+		relPath:  relPath,
+		oldName:  input.Symbol,
+		newName:  input.NewName,
+		position: fmt.Sprintf("%s:#%d", absPath, offset),
+	}, nil
+}
+
+// findSymbolOffset returns the byte offset of the first whole-identifier
+// occurrence of symbol on the given 1-based line.
+func findSymbolOffset(content []byte, line int, symbol string) (int, error) {
+	lineStart := 0
+	for i := 1; i < line; i++ {
+		next := bytes.IndexByte(content[lineStart:], '\n')
+		if next < 0 {
+			return 0, fmt.Errorf("line %d is past the end of the file", line)
+		}
+		lineStart += next + 1
+	}
+	lineText := content[lineStart:]
+	if end := bytes.IndexByte(lineText, '\n'); end >= 0 {
+		lineText = lineText[:end]
+	}
+
+	for from := 0; ; {
+		i := bytes.Index(lineText[from:], []byte(symbol))
+		if i < 0 {
+			return 0, fmt.Errorf("symbol %q not found on line %d. Use read_file to check the exact line", symbol, line)
+		}
+		start := from + i
+		end := start + len(symbol)
+		if (start == 0 || !isIdentByte(lineText[start-1])) && (end == len(lineText) || !isIdentByte(lineText[end])) {
+			return lineStart + start, nil
+		}
+		from = start + 1
+	}
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || b >= 0x80 || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
+
+// runGopls runs gopls in the workspace and returns its standard output.
+func (t *RenameSymbolTool) runGopls(ctx context.Context, args ...string) (string, error) {
+	goplsPath, err := exec.LookPath("gopls")
+	if err != nil {
+		return "", fmt.Errorf("gopls not found in PATH. Install it with 'go install golang.org/x/tools/gopls@latest', or rename with apply_diff instead")
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(runCtx, goplsPath, args...)
+	cmd.Dir = t.guard.WorkspaceDir()
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if runCtx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("gopls timed out after %s", t.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("gopls rename failed: %s", msg)
+		}
+		return "", fmt.Errorf("gopls rename failed: %w", err)
+	}
+	return stdout.String(), nil
+}
+
+// changedFiles asks gopls which files the rename would modify and checks
+// each against the workspace guard. Paths are returned workspace-relative
+// and sorted.
+func (t *RenameSymbolTool) changedFiles(ctx context.Context, req *renameRequest) ([]string, error) {
+	out, err := t.runGopls(ctx, "rename", "-l", req.position, req.newName)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(out, "\n") {
+		absPath := strings.TrimSpace(line)
+		if absPath == "" {
+			continue
+		}
+		if !t.guard.IsWithinWorkspace(absPath) {
+			return nil, fmt.Errorf("rename would modify %s, which is outside the workspace", absPath)
+		}
+		relPath, relErr := t.guard.MakeRelative(absPath)
+		if relErr != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", absPath, relErr)
+		}
+
+		var size int64
+		if info, statErr := os.Stat(absPath); statErr == nil {
+			size = info.Size()
+		}
+		if ruleErr := t.guard.CheckWrite(relPath, size); ruleErr != nil {
+			return nil, ruleErr
+		}
+		files = append(files, relPath)
+	}
+	sort.Strings(files)
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("gopls found nothing to rename for %q", req.oldName)
+	}
+	return files, nil
+}
+
+// ChangedFiles returns the workspace-relative files a rename would modify,
+// without modifying them.
+func (t *RenameSymbolTool) ChangedFiles(ctx context.Context, argsXML []byte) (oldName, newName string, files []string, err error) {
+	req, err := t.parseRequest(argsXML)
+	if err != nil {
+		return "", "", nil, err
+	}
+	files, err = t.changedFiles(ctx, req)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return req.oldName, req.newName, files, nil
+}
+
+// Execute performs the rename and returns the files that changed.
+func (t *RenameSymbolTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	req, err := t.parseRequest(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Check every file before writing any, so path rules never leave a
+	// rename half applied
+	files, err := t.changedFiles(ctx, req)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if _, err := t.runGopls(ctx, "rename", "-w", req.position, req.newName); err != nil {
+		return "", nil, err
+	}
+
+	metadata := map[string]any{
+		"old_name":       req.oldName,
+		"new_name":       req.newName,
+		"files_changed":  len(files),
+		"modified_files": files,
+	}
+
+	return FormatRenameResult(req.oldName, req.newName, files), metadata, nil
+}
+
+// FormatRenameResult renders the rename_symbol result message.
+func FormatRenameResult(oldName, newName string, files []string) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "Renamed %s to %s in %d file(s):\n", oldName, newName, len(files))
+	for _, file := range files {
+		fmt.Fprintf(&result, "- %s\n", file)
+	}
+	return strings.TrimSuffix(result.String(), "\n")
+}
+
+// IsLoopBreaking returns whether this tool should break the agent loop.
+func (t *RenameSymbolTool) IsLoopBreaking() bool {
+	return false
+}
+
+// XMLExample provides a concrete XML usage example for this tool.
+func (t *RenameSymbolTool) XMLExample() string {
+	return `<tool>
+<server_name>local</server_name>
+<tool_name>rename_symbol</tool_name>
+<arguments>
+  <path>pkg/server/handler.go</path>
+  <line>42</line>
+  <symbol>handleReq</symbol>
+  <new_name>handleRequest</new_name>
+</arguments>
+</tool>`
+}
+
+// GeneratePreview implements the Previewable interface to show the rename as
+// a unified diff across every affected file.
+func (t *RenameSymbolTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	req, err := t.parseRequest(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	// Don't ask for approval of a rename the path rules will reject
+	files, err := t.changedFiles(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := t.runGopls(ctx, "rename", "-d", req.position, req.newName)
+	if err != nil {
+		return nil, err
+	}
+	// Show workspace-relative paths in the diff headers
+	diff = strings.ReplaceAll(diff, t.guard.WorkspaceDir()+string(filepath.Separator), "")
+
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeDiff,
+		Title:       fmt.Sprintf("Rename %s to %s", req.oldName, req.newName),
+		Description: fmt.Sprintf("This will rename %s to %s in %d file(s)", req.oldName, req.newName, len(files)),
+		Content:     diff,
+		Metadata: map[string]any{
+			"file_path":  req.relPath,
+			"language":   "go",
+			"file_count": len(files),
+		},
+	}, nil
+}
@@ -0,0 +1,134 @@
+package coding
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindSymbolOffset(t *testing.T) {
+	content := []byte("package main\n\nfunc handle(handler string) { handle2(handler) }\n")
+
+	tests := []struct {
+		name    string
+		line    int
+		symbol  string
+		want    int
+		wantErr bool
+	}{
+		{name: "first whole identifier", line: 3, symbol: "handle", want: 19},
+		{name: "skips longer identifiers", line: 3, symbol: "handler", want: 26},
+		{name: "identifier with digits", line: 3, symbol: "handle2", want: 44},
+		{name: "missing symbol", line: 1, symbol: "handle", wantErr: true},
+		{name: "line past end", line: 10, symbol: "handle", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findSymbolOffset(content, tt.line, tt.symbol)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got offset %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("offset = %d, want %d", got, tt.want)
+			}
+			if string(content[got:got+len(tt.symbol)]) != tt.symbol {
+				t.Errorf("offset %d does not point at %q", got, tt.symbol)
+			}
+		})
+	}
+}
+
+func TestRenameSymbolTool_InvalidArguments(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	writeTestFile(t, filepath.Join(tmpDir, "main.go"), "package main\n\nfunc oldName() {}\n")
+
+	tool := NewRenameSymbolTool(createWorkspaceGuard(t, tmpDir))
+
+	tests := []struct {
+		name    string
+		args    string
+		wantErr string
+	}{
+		{
+			name:    "not a Go file",
+			args:    `<arguments><path>README.md</path><line>1</line><symbol>x</symbol><new_name>y</new_name></arguments>`,
+			wantErr: "Go source file",
+		},
+		{
+			name:    "invalid identifier",
+			args:    `<arguments><path>main.go</path><line>3</line><symbol>oldName</symbol><new_name>new-name</new_name></arguments>`,
+			wantErr: "not a valid Go identifier",
+		},
+		{
+			name:    "keyword",
+			args:    `<arguments><path>main.go</path><line>3</line><symbol>oldName</symbol><new_name>func</new_name></arguments>`,
+			wantErr: "not a valid Go identifier",
+		},
+		{
+			name:    "same name",
+			args:    `<arguments><path>main.go</path><line>3</line><symbol>oldName</symbol><new_name>oldName</new_name></arguments>`,
+			wantErr: "same as the current name",
+		},
+		{
+			name:    "symbol not on line",
+			args:    `<arguments><path>main.go</path><line>1</line><symbol>oldName</symbol><new_name>newName</new_name></arguments>`,
+			wantErr: "not found on line 1",
+		},
+		{
+			name:    "outside workspace",
+			args:    `<arguments><path>../other.go</path><line>1</line><symbol>x</symbol><new_name>y</new_name></arguments>`,
+			wantErr: "invalid path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tool.Execute(context.Background(), []byte(tt.args))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRenameSymbolTool_Execute(t *testing.T) {
+	if _, err := exec.LookPath("gopls"); err != nil {
+		t.Skip("gopls not installed")
+	}
+
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	writeTestFile(t, filepath.Join(tmpDir, "go.mod"), "module example.com/rename\n\ngo 1.21\n")
+	writeTestFile(t, filepath.Join(tmpDir, "a.go"), "package rename\n\nfunc count() int { return 1 }\n\nvar counter = count()\n")
+	writeTestFile(t, filepath.Join(tmpDir, "b.go"), "package rename\n\nfunc total() int { return count() + counter }\n")
+
+	tool := NewRenameSymbolTool(createWorkspaceGuard(t, tmpDir))
+	args := `<arguments><path>a.go</path><line>3</line><symbol>count</symbol><new_name>tally</new_name></arguments>`
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(args))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if metadata["files_changed"] != 2 {
+		t.Errorf("files_changed = %v, want 2\n%s", metadata["files_changed"], result)
+	}
+
+	b, err := os.ReadFile(filepath.Join(tmpDir, "b.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "tally() + counter") {
+		t.Errorf("expected reference renamed and counter untouched, got:\n%s", b)
+	}
+}
@@ -0,0 +1,400 @@
+package coding
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/sandbox"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// Test frameworks supported by run_tests
+const (
+	FrameworkGo     = "go"
+	FrameworkPytest = "pytest"
+	FrameworkJest   = "jest"
+)
+
+const (
+	defaultMaxFailures = 20
+	// rawOutputTailLines is how much raw output is returned when the results
+	// cannot be parsed
+	rawOutputTailLines = 60
+)
+
+// frameworkMarkers lists the files that identify a project's test framework,
+// in the order they are checked
+var frameworkMarkers = []struct {
+	framework string
+	files     []string
+}{
+	{FrameworkGo, []string{"go.mod"}},
+	{FrameworkJest, []string{"jest.config.js", "jest.config.ts", "jest.config.mjs", "jest.config.cjs", "jest.config.json"}},
+	{FrameworkPytest, []string{"pytest.ini", "conftest.py", "tox.ini", "pyproject.toml", "setup.cfg"}},
+}
+
+// RunTestsTool runs a project's test suite and returns a compact summary with
+// structured failures instead of the raw test output
+type RunTestsTool struct {
+	guard          *workspace.Guard
+	defaultTimeout time.Duration
+	sandbox        *sandbox.Sandbox // Runs tests isolated from the host when set
+}
+
+// NewRunTestsTool creates a new test runner tool
+func NewRunTestsTool(guard *workspace.Guard) *RunTestsTool {
+	return &RunTestsTool{
+		guard:          guard,
+		defaultTimeout: 5 * time.Minute,
+	}
+}
+
+// SetSandbox makes the tool run tests inside sb instead of directly on the
+// host. A nil sandbox restores host execution.
+func (t *RunTestsTool) SetSandbox(sb *sandbox.Sandbox) {
+	t.sandbox = sb
+}
+
+// Name returns the tool name
+func (t *RunTestsTool) Name() string {
+	return "run_tests"
+}
+
+// Description returns the tool description
+func (t *RunTestsTool) Description() string {
+	return "Run the project's tests (go test, pytest or jest, detected from the project files) and return a summary with each failure's test name, file, line and message. Prefer this over execute_command for running tests: the output is much shorter."
+}
+
+// Schema returns the tool's JSON schema
+func (t *RunTestsTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Directory to run the tests from, relative to the workspace (default: workspace root)",
+			},
+			"framework": map[string]any{
+				"type":        "string",
+				"description": "Test framework to use: go, pytest or jest (default: detected from the project files)",
+			},
+			"target": map[string]any{
+				"type":        "string",
+				"description": "What to test: a Go package pattern (default ./...), a pytest path or node ID, or a jest path pattern",
+			},
+			"filter": map[string]any{
+				"type":        "string",
+				"description": "Only run tests matching this name pattern (go test -run, pytest -k, jest -t)",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Timeout in seconds (default: 300)",
+			},
+			"max_failures": map[string]any{
+				"type":        "integer",
+				"description": "Maximum number of failures to report in detail (default: 20)",
+			},
+		},
+		[]string{},
+	)
+}
+
+// runTestsInput holds the arguments of a run_tests call
+type runTestsInput struct {
+	XMLName     xml.Name `xml:"arguments"`
+	Path        string   `xml:"path"`
+	Framework   string   `xml:"framework"`
+	Target      string   `xml:"target"`
+	Filter      string   `xml:"filter"`
+	Timeout     float64  `xml:"timeout"`
+	MaxFailures int      `xml:"max_failures"`
+}
+
+// testRun is a resolved run_tests call
+type testRun struct {
+	framework string
+	command   string
+	workDir   string
+	timeout   time.Duration
+	input     runTestsInput
+}
+
+// Command returns the shell command the call would run and the directory it
+// would run in, without running it.
+func (t *RunTestsTool) Command(argsXML []byte) (command, workDir string, err error) {
+	run, err := t.resolve(argsXML)
+	if err != nil {
+		return "", "", err
+	}
+	return run.command, run.workDir, nil
+}
+
+// resolve parses the arguments, validates the directory and builds the test
+// command
+func (t *RunTestsTool) resolve(argsXML []byte) (*testRun, error) {
+	var input runTestsInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	workDir := t.guard.WorkspaceDir()
+	if input.Path != "" {
+		if validateErr := t.guard.ValidatePath(input.Path); validateErr != nil {
+			return nil, fmt.Errorf("invalid path: %w", validateErr)
+		}
+		absPath, resolveErr := t.guard.ResolvePath(input.Path)
+		if resolveErr != nil {
+			return nil, fmt.Errorf("failed to resolve path: %w", resolveErr)
+		}
+		workDir = absPath
+	}
+
+	// Test runs write caches and build output below their working directory
+	if ruleErr := t.guard.CheckWorkingDir(workDir); ruleErr != nil {
+		return nil, ruleErr
+	}
+
+	framework := strings.ToLower(strings.TrimSpace(input.Framework))
+	switch framework {
+	case "", "auto":
+		framework = t.detectFramework(workDir)
+		if framework == "" {
+			return nil, fmt.Errorf("could not detect a test framework in %s: specify framework as go, pytest or jest", workDir)
+		}
+	case FrameworkGo, FrameworkPytest, FrameworkJest:
+	default:
+		return nil, fmt.Errorf("unsupported framework %q: must be go, pytest or jest", input.Framework)
+	}
+
+	timeout := t.defaultTimeout
+	if input.Timeout > 0 {
+		timeout = time.Duration(input.Timeout * float64(time.Second))
+	}
+
+	return &testRun{
+		framework: framework,
+		command:   testCommand(framework, input.Target, input.Filter),
+		workDir:   workDir,
+		timeout:   timeout,
+		input:     input,
+	}, nil
+}
+
+// detectFramework looks for project files in dir and its parents, up to the
+// workspace root
+func (t *RunTestsTool) detectFramework(dir string) string {
+	root := t.guard.WorkspaceDir()
+	for {
+		if framework := frameworkIn(dir); framework != "" {
+			return framework
+		}
+		if dir == root || !strings.HasPrefix(dir, root) {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// frameworkIn returns the test framework dir's project files point to
+func frameworkIn(dir string) string {
+	for _, marker := range frameworkMarkers {
+		for _, name := range marker.files {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return marker.framework
+			}
+		}
+	}
+
+	// A package.json only counts when it uses jest
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil && bytes.Contains(data, []byte(`"jest`)) {
+		return FrameworkJest
+	}
+	return ""
+}
+
+// testCommand builds the shell command that runs framework's tests with
+// machine-readable output
+func testCommand(framework, target, filter string) string {
+	var args []string
+	switch framework {
+	case FrameworkGo:
+		if target == "" {
+			target = "./..."
+		}
+		args = []string{"go", "test", "-json"}
+		if filter != "" {
+			args = append(args, "-run", filter)
+		}
+		args = append(args, strings.Fields(target)...)
+	case FrameworkPytest:
+		args = []string{"python", "-m", "pytest", "-q", "-rfE", "--tb=short", "-p", "no:cacheprovider"}
+		if filter != "" {
+			args = append(args, "-k", filter)
+		}
+		args = append(args, strings.Fields(target)...)
+	case FrameworkJest:
+		args = []string{"npx", "--no-install", "jest", "--ci", "--json", "--testLocationInResults"}
+		if filter != "" {
+			args = append(args, "-t", filter)
+		}
+		args = append(args, strings.Fields(target)...)
+	}
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellSafe matches arguments that need no quoting
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes s for use as a single sh argument
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Execute runs the tests and returns the parsed results
+func (t *RunTestsTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	run, err := t.resolve(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, run.timeout)
+	defer cancel()
+
+	// Let the user cancel a long test run like any other command
+	if registry, ok := ctx.Value(CommandRegistryKey).(*sync.Map); ok {
+		execID := fmt.Sprintf("tests_%d", time.Now().UnixNano())
+		registry.Store(execID, cancel)
+		defer registry.Delete(execID)
+	}
+
+	start := time.Now()
+	var cmd *exec.Cmd
+	if t.sandbox != nil {
+		var cleanup func()
+		cmd, cleanup = t.sandbox.Command(execCtx, run.workDir, run.command)
+		defer cleanup()
+	} else {
+		cmd = exec.CommandContext(execCtx, "sh", "-c", run.command)
+	}
+	cmd.Dir = run.workDir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+	duration := time.Since(start)
+
+	exitCode := 0
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(runErr, &exitErr) {
+			return "", nil, fmt.Errorf("failed to run tests: %w", runErr)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	report := ParseTestOutput(run.framework, stdout.String(), stderr.String(), run.workDir)
+
+	maxFailures := run.input.MaxFailures
+	if maxFailures <= 0 {
+		maxFailures = defaultMaxFailures
+	}
+
+	var result strings.Builder
+	switch execCtx.Err() {
+	case context.Canceled:
+		fmt.Fprintf(&result, "Tests were canceled by user after %s\n\n", duration.Round(time.Millisecond))
+	case context.DeadlineExceeded:
+		fmt.Fprintf(&result, "Tests timed out after %s\n\n", duration.Round(time.Millisecond))
+	}
+	result.WriteString(report.Format(run.command, exitCode, duration, maxFailures))
+
+	// Without parsed results the raw output is the only clue to what went wrong
+	if exitCode != 0 && len(report.Failures) == 0 {
+		fmt.Fprintf(&result, "\n\nNo test failures could be parsed. Last lines of output:\n%s",
+			tailLines(stdout.String()+stderr.String(), rawOutputTailLines))
+	}
+
+	metadata := map[string]any{
+		"framework":   run.framework,
+		"command":     run.command,
+		"working_dir": run.workDir,
+		"passed":      report.Passed,
+		"failed":      report.Failed,
+		"skipped":     report.Skipped,
+		"exit_code":   exitCode,
+		"duration_ms": duration.Milliseconds(),
+	}
+	if t.sandbox != nil {
+		metadata["sandbox"] = t.sandbox.Describe()
+	}
+
+	return result.String(), metadata, nil
+}
+
+// tailLines returns the last n lines of s
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = append([]string{fmt.Sprintf("... (%d earlier lines omitted)", len(lines)-n)}, lines[len(lines)-n:]...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// IsLoopBreaking indicates this tool should not break the agent loop
+func (t *RunTestsTool) IsLoopBreaking() bool {
+	return false
+}
+
+// GeneratePreview implements the Previewable interface to show the test
+// command before it runs.
+func (t *RunTestsTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	run, err := t.resolve(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	var preview strings.Builder
+	preview.WriteString("Command: ")
+	preview.WriteString(run.command)
+	preview.WriteString("\n\n")
+	preview.WriteString("Working Directory: ")
+	preview.WriteString(run.workDir)
+	preview.WriteString("\n\n")
+	fmt.Fprintf(&preview, "Timeout: %s\n", run.timeout)
+
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeCommand,
+		Title:       "Run Tests",
+		Description: fmt.Sprintf("This will run the %s tests: %s", run.framework, run.command),
+		Content:     preview.String(),
+		Metadata: map[string]any{
+			"command":     run.command,
+			"framework":   run.framework,
+			"working_dir": run.workDir,
+			"timeout":     run.timeout.Seconds(),
+		},
+	}, nil
+}
@@ -0,0 +1,254 @@
+package coding
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunTestsTool_DetectFramework(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		path  string
+		want  string
+	}{
+		{"go module", map[string]string{"go.mod": "module example"}, "", FrameworkGo},
+		{"go module from a subdirectory", map[string]string{"go.mod": "module example", "pkg/a.go": "package pkg"}, "pkg", FrameworkGo},
+		{"jest dependency", map[string]string{"package.json": `{"devDependencies": {"jest": "^29.0.0"}}`}, "", FrameworkJest},
+		{"package.json without jest", map[string]string{"package.json": `{"name": "app"}`}, "", ""},
+		{"pytest config", map[string]string{"pyproject.toml": "[tool.pytest.ini_options]"}, "", FrameworkPytest},
+		{"nothing", map[string]string{"README.md": "hi"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, cleanup := setupTestDir(t)
+			defer cleanup()
+			for path, content := range tt.files {
+				full := filepath.Join(tmpDir, filepath.FromSlash(path))
+				if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+					t.Fatal(err)
+				}
+				writeTestFile(t, full, content)
+			}
+
+			tool := NewRunTestsTool(createWorkspaceGuard(t, tmpDir))
+			args := "<arguments></arguments>"
+			if tt.path != "" {
+				args = "<arguments><path>" + tt.path + "</path></arguments>"
+			}
+
+			_, _, err := tool.Command([]byte(args))
+			if tt.want == "" {
+				if err == nil || !strings.Contains(err.Error(), "could not detect") {
+					t.Errorf("expected a detection error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Command failed: %v", err)
+			}
+			run, _ := tool.resolve([]byte(args))
+			if run.framework != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, run.framework)
+			}
+		})
+	}
+}
+
+func TestTestCommand(t *testing.T) {
+	tests := []struct {
+		framework, target, filter string
+		want                      string
+	}{
+		{FrameworkGo, "", "", "go test -json ./..."},
+		{FrameworkGo, "./pkg/... ./cmd/...", "TestFoo$", "go test -json -run 'TestFoo$' ./pkg/... ./cmd/..."},
+		{FrameworkPytest, "tests/test_api.py", "not slow", "python -m pytest -q -rfE --tb=short -p no:cacheprovider -k 'not slow' tests/test_api.py"},
+		{FrameworkJest, "src/api", "it's ok", `npx --no-install jest --ci --json --testLocationInResults -t 'it'\''s ok' src/api`},
+	}
+
+	for _, tt := range tests {
+		if got := testCommand(tt.framework, tt.target, tt.filter); got != tt.want {
+			t.Errorf("testCommand(%s, %q, %q) = %s, want %s", tt.framework, tt.target, tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestParseGoTestOutput(t *testing.T) {
+	stdout := strings.Join([]string{
+		`{"Action":"run","Package":"example/calc","Test":"TestAdd"}`,
+		`{"Action":"output","Package":"example/calc","Test":"TestAdd","Output":"=== RUN   TestAdd\n"}`,
+		`{"Action":"output","Package":"example/calc","Test":"TestAdd","Output":"--- PASS: TestAdd (0.00s)\n"}`,
+		`{"Action":"pass","Package":"example/calc","Test":"TestAdd"}`,
+		`{"Action":"output","Package":"example/calc","Test":"TestDiv/by_zero","Output":"=== RUN   TestDiv/by_zero\n"}`,
+		`{"Action":"output","Package":"example/calc","Test":"TestDiv/by_zero","Output":"    calc_test.go:21: expected error, got 0\n"}`,
+		`{"Action":"output","Package":"example/calc","Test":"TestDiv/by_zero","Output":"    --- FAIL: TestDiv/by_zero (0.00s)\n"}`,
+		`{"Action":"fail","Package":"example/calc","Test":"TestDiv/by_zero"}`,
+		`{"Action":"fail","Package":"example/calc","Test":"TestDiv"}`,
+		`{"Action":"skip","Package":"example/calc","Test":"TestSlow"}`,
+		`{"Action":"fail","Package":"example/calc"}`,
+		`{"ImportPath":"example/broken [example/broken.test]","Action":"build-output","Output":"# example/broken [example/broken.test]\n"}`,
+		`{"ImportPath":"example/broken [example/broken.test]","Action":"build-output","Output":"broken/broken_test.go:7:2: undefined: missing\n"}`,
+		`{"Action":"fail","Package":"example/broken","FailedBuild":"example/broken [example/broken.test]"}`,
+	}, "\n")
+
+	report := ParseTestOutput(FrameworkGo, stdout, "", "")
+	if report.Passed != 1 || report.Failed != 2 || report.Skipped != 1 {
+		t.Errorf("expected 1 passed, 2 failed, 1 skipped, got %+v", report)
+	}
+	if len(report.Failures) != 2 {
+		t.Fatalf("expected the subtest and the build failure, got %+v", report.Failures)
+	}
+
+	got := report.Failures[0]
+	want := TestFailure{Name: "TestDiv/by_zero", Package: "example/calc", File: "calc_test.go", Line: 21, Message: "expected error, got 0"}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	build := report.Failures[1]
+	if build.Name != "package example/broken" || build.Location() != "broken/broken_test.go:7" || !strings.Contains(build.Message, "undefined: missing") {
+		t.Errorf("unexpected build failure: %+v", build)
+	}
+}
+
+func TestParsePytestOutput(t *testing.T) {
+	output := `..F.s
+=================================== FAILURES ===================================
+_________________________ TestMath.test_divide __________________________
+tests/test_math.py:14: in test_divide
+    assert divide(1, 0) == 0
+src/math.py:3: in divide
+    return a / b
+E   ZeroDivisionError: division by zero
+___________________________ test_parse[bad] ____________________________
+tests/test_parse.py:9: in test_parse
+    assert parse(value) == 1
+E   assert None == 1
+E    +  where None = parse('bad')
+=========================== short test summary info ============================
+FAILED tests/test_math.py::TestMath::test_divide - ZeroDivisionError: division by zero
+FAILED tests/test_parse.py::test_parse[bad]
+2 failed, 2 passed, 1 skipped in 0.12s
+`
+
+	report := ParseTestOutput(FrameworkPytest, output, "", "")
+	if report.Passed != 2 || report.Failed != 2 || report.Skipped != 1 {
+		t.Errorf("expected 2 passed, 2 failed, 1 skipped, got %+v", report)
+	}
+	if len(report.Failures) != 2 {
+		t.Fatalf("expected 2 failures, got %+v", report.Failures)
+	}
+
+	// The traceback's last frame is in the code under test, so the line
+	// comes from the test file's frame
+	divide := report.Failures[0]
+	if divide.Name != "tests/test_math.py::TestMath::test_divide" || divide.Location() != "tests/test_math.py:14" || divide.Message != "ZeroDivisionError: division by zero" {
+		t.Errorf("unexpected failure: %+v", divide)
+	}
+
+	parse := report.Failures[1]
+	if parse.Location() != "tests/test_parse.py:9" || parse.Message != "assert None == 1\n+  where None = parse('bad')" {
+		t.Errorf("unexpected failure: %+v", parse)
+	}
+}
+
+func TestParseJestOutput(t *testing.T) {
+	stdout := `{"numPassedTests":3,"numFailedTests":1,"numPendingTests":1,"numTodoTests":0,"testResults":[` +
+		`{"name":"/work/src/sum.test.js","status":"failed","message":"","assertionResults":[` +
+		`{"fullName":"sum adds numbers","status":"failed","location":{"line":5,"column":3},"failureMessages":["Error: \u001b[2mexpect(\u001b[22mreceived\u001b[2m).toBe(\u001b[22mexpected\u001b[2m)\u001b[22m\n\nExpected: 4\nReceived: 5\n    at Object.<anonymous> (/work/src/sum.test.js:6:20)"]},` +
+		`{"fullName":"sum handles zero","status":"passed","failureMessages":[]}]},` +
+		`{"name":"/work/src/broken.test.js","status":"failed","message":"SyntaxError: Unexpected token (3:1)","assertionResults":[]}]}`
+
+	report := ParseTestOutput(FrameworkJest, stdout, "", "/work")
+	if report.Passed != 3 || report.Failed != 2 || report.Skipped != 1 {
+		t.Errorf("expected 3 passed, 2 failed, 1 skipped, got %+v", report)
+	}
+	if len(report.Failures) != 2 {
+		t.Fatalf("expected 2 failures, got %+v", report.Failures)
+	}
+
+	sum := report.Failures[0]
+	if sum.Name != "sum adds numbers" || sum.Location() != "src/sum.test.js:5" {
+		t.Errorf("unexpected failure: %+v", sum)
+	}
+	if sum.Message != "Error: expect(received).toBe(expected)\n\nExpected: 4\nReceived: 5" {
+		t.Errorf("expected colors and stack frames to be stripped, got %q", sum.Message)
+	}
+
+	if suite := report.Failures[1]; suite.Name != "suite src/broken.test.js" || !strings.Contains(suite.Message, "SyntaxError") {
+		t.Errorf("unexpected suite failure: %+v", suite)
+	}
+}
+
+func TestTestReport_FormatLimitsFailures(t *testing.T) {
+	report := &TestReport{Framework: FrameworkGo, Passed: 5, Failed: 3, Failures: []TestFailure{
+		{Name: "TestA", File: "a_test.go", Line: 3, Message: "boom"},
+		{Name: "TestB"},
+		{Name: "TestC"},
+	}}
+
+	out := report.Format("go test -json ./...", 1, 1500*time.Millisecond, 2)
+	if !strings.HasPrefix(out, "go tests FAILED: 5 passed, 3 failed, 0 skipped in 1.5s") {
+		t.Errorf("unexpected summary: %s", out)
+	}
+	if !strings.Contains(out, "1. TestA at a_test.go:3\n\n   boom") || strings.Contains(out, "TestC") || !strings.Contains(out, "... and 1 more failures") {
+		t.Errorf("unexpected failure listing:\n%s", out)
+	}
+}
+
+func TestTrimMessage(t *testing.T) {
+	lines := make([]string, 30)
+	for i := range lines {
+		lines[i] = "    line"
+	}
+	msg := trimMessage(lines)
+	if got := strings.Count(msg, "\n") + 1; got != maxFailureMessageLines+1 {
+		t.Errorf("expected %d lines including the ellipsis, got %d", maxFailureMessageLines+1, got)
+	}
+	if !strings.HasPrefix(msg, "line\n") || !strings.HasSuffix(msg, "\n...") {
+		t.Errorf("expected a dedented, truncated message, got %q", msg)
+	}
+}
+
+func TestRunTestsTool_ExecuteGo(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	writeTestFile(t, filepath.Join(tmpDir, "go.mod"), "module example\n\ngo 1.21\n")
+	writeTestFile(t, filepath.Join(tmpDir, "calc_test.go"), `package example
+
+import "testing"
+
+func TestPass(t *testing.T) {}
+
+func TestFail(t *testing.T) {
+	t.Errorf("want %d, got %d", 2, 3)
+}
+`)
+
+	tool := NewRunTestsTool(createWorkspaceGuard(t, tmpDir))
+
+	result, metadata, err := tool.Execute(context.Background(), []byte("<arguments></arguments>"))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if metadata["framework"] != FrameworkGo || metadata["passed"] != 1 || metadata["failed"] != 1 || metadata["exit_code"] == 0 {
+		t.Errorf("unexpected metadata: %v", metadata)
+	}
+	if !strings.Contains(result, "1. TestFail [example] at calc_test.go:8") || !strings.Contains(result, "want 2, got 3") {
+		t.Errorf("expected a structured failure, got:\n%s", result)
+	}
+	if strings.Contains(result, "=== RUN") {
+		t.Errorf("expected raw go test output to be left out, got:\n%s", result)
+	}
+}
@@ -12,11 +12,16 @@ import (
 
 	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/coding/fileindex"
 )
 
+// defaultSearchMaxResults caps the matches returned when max_results is not set.
+const defaultSearchMaxResults = 100
+
 // SearchFilesTool searches for patterns in files using regular expressions.
 type SearchFilesTool struct {
 	guard *workspace.Guard
+	index *fileindex.Index // Narrows the files to read when set and ready
 }
 
 // NewSearchFilesTool creates a new SearchFilesTool with workspace security.
@@ -26,6 +31,13 @@ func NewSearchFilesTool(guard *workspace.Guard) *SearchFilesTool {
 	}
 }
 
+// SetIndex makes the tool read only the files the index says may match,
+// walking the directory while the index cannot answer. A nil index restores
+// walking for every search.
+func (t *SearchFilesTool) SetIndex(index *fileindex.Index) {
+	t.index = index
+}
+
 // Name returns the tool name.
 func (t *SearchFilesTool) Name() string {
 	return "search_files"
@@ -33,7 +45,7 @@ func (t *SearchFilesTool) Name() string {
 
 // Description returns the tool description.
 func (t *SearchFilesTool) Description() string {
-	return "Search for patterns in files using regular expressions. Returns matches with surrounding context lines."
+	return "Search for patterns in files using regular expressions or fixed strings. Returns matches with surrounding context lines, or per-file match counts with count_only."
 }
 
 // Schema returns the JSON schema for the tool's input parameters.
@@ -56,6 +68,29 @@ func (t *SearchFilesTool) Schema() map[string]any {
 				"type":        "integer",
 				"description": "Number of context lines to show before and after match (default: 2)",
 			},
+			"case_insensitive": map[string]any{
+				"type":        "boolean",
+				"description": "Match letters regardless of case (default: false)",
+			},
+			"fixed_strings": map[string]any{
+				"type":        "boolean",
+				"description": "Treat pattern as a literal string rather than a regular expression (default: false)",
+			},
+			"exclude": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "string",
+				},
+				"description": "Optional glob patterns of paths to leave out, relative to the search path. Patterns without '/' match names at any depth; an excluded directory excludes everything below it (e.g., 'vendor', '*_test.go', 'docs/**')",
+			},
+			"max_results": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of matches to show, or of files with count_only (default: %d). The total is always reported.", defaultSearchMaxResults),
+			},
+			"count_only": map[string]any{
+				"type":        "boolean",
+				"description": "Return only the number of matches in each file instead of the matching lines (default: false)",
+			},
 		},
 		[]string{"pattern"}, // pattern is required
 	)
@@ -65,11 +100,16 @@ func (t *SearchFilesTool) Schema() map[string]any {
 func (t *SearchFilesTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
 	// Parse arguments
 	var input struct {
-		XMLName      xml.Name `xml:"arguments"`
-		Path         string   `xml:"path"`
-		Pattern      string   `xml:"pattern"`
-		FilePattern  string   `xml:"file_pattern"`
-		ContextLines int      `xml:"context_lines"`
+		XMLName         xml.Name `xml:"arguments"`
+		Path            string   `xml:"path"`
+		Pattern         string   `xml:"pattern"`
+		FilePattern     string   `xml:"file_pattern"`
+		ContextLines    int      `xml:"context_lines"`
+		CaseInsensitive bool     `xml:"case_insensitive"`
+		FixedStrings    bool     `xml:"fixed_strings"`
+		Exclude         []string `xml:"exclude>glob"`
+		MaxResults      int      `xml:"max_results"`
+		CountOnly       bool     `xml:"count_only"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
@@ -89,6 +129,14 @@ func (t *SearchFilesTool) Execute(ctx context.Context, argsXML []byte) (string,
 	if input.ContextLines == 0 {
 		input.ContextLines = 2
 	}
+	if input.MaxResults <= 0 {
+		input.MaxResults = defaultSearchMaxResults
+	}
+
+	exclude, err := compileSearchExclusions(input.Exclude)
+	if err != nil {
+		return "", nil, err
+	}
 
 	// Validate path with workspace guard
 	if err := t.guard.ValidatePath(input.Path); err != nil {
@@ -102,37 +150,87 @@ func (t *SearchFilesTool) Execute(ctx context.Context, argsXML []byte) (string,
 	}
 
 	// Compile regex pattern
-	regex, err := regexp.Compile(input.Pattern)
+	pattern := input.Pattern
+	if input.FixedStrings {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if input.CaseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	regex, err := regexp.Compile(pattern)
 	if err != nil {
 		return "", nil, fmt.Errorf("invalid regex pattern: %w", err)
 	}
 
-	// Search files
-	matches, err := t.searchDirectory(absPath, regex, input.FilePattern, input.ContextLines)
+	query := searchQuery{
+		root:         absPath,
+		regex:        regex,
+		filePattern:  input.FilePattern,
+		exclude:      exclude,
+		contextLines: input.ContextLines,
+		countOnly:    input.CountOnly,
+	}
+
+	// Search the files the index selects, or every file under the path
+	var matches []searchMatch
+	if candidates, ok := t.indexCandidates(absPath, pattern); ok {
+		matches, err = t.searchCandidates(candidates, query)
+	} else {
+		matches, err = t.searchDirectory(absPath, query)
+	}
 	if err != nil {
 		return "", nil, fmt.Errorf("search failed: %w", err)
 	}
 
-	// Format output
-	result := t.formatMatches(matches)
+	// Count matches per file, in the order the files were searched
+	var files []string
+	counts := make(map[string]int)
+	for _, match := range matches {
+		if counts[match.FilePath] == 0 {
+			files = append(files, match.FilePath)
+		}
+		counts[match.FilePath]++
+	}
+
+	// Format output, showing at most max_results matches, or files when
+	// counting
+	total := len(matches)
+	if input.CountOnly {
+		total = len(files)
+	}
+	shown := min(total, input.MaxResults)
+	var result string
+	if input.CountOnly {
+		result = t.formatCounts(files[:shown], counts, len(matches))
+	} else {
+		result = t.formatMatches(matches[:shown], len(matches))
+	}
 
 	// Build metadata
 	metadata := map[string]any{
-		"path":          input.Path,
-		"pattern":       input.Pattern,
-		"match_count":   len(matches),
-		"context_lines": input.ContextLines,
+		"path":               input.Path,
+		"pattern":            input.Pattern,
+		"match_count":        len(matches),
+		"files_with_matches": len(files),
+		"context_lines":      input.ContextLines,
+		"shown":              shown,
+		"truncated":          shown < total,
 	}
 	if input.FilePattern != "" {
 		metadata["file_pattern"] = input.FilePattern
 	}
-
-	// Count unique files
-	fileSet := make(map[string]bool)
-	for _, match := range matches {
-		fileSet[match.FilePath] = true
+	if input.CaseInsensitive {
+		metadata["case_insensitive"] = true
+	}
+	if input.FixedStrings {
+		metadata["fixed_strings"] = true
+	}
+	if len(input.Exclude) > 0 {
+		metadata["exclude"] = input.Exclude
+	}
+	if input.CountOnly {
+		metadata["count_only"] = true
 	}
-	metadata["files_with_matches"] = len(fileSet)
 
 	return result, metadata, nil
 }
@@ -151,8 +249,18 @@ type searchMatch struct {
 	ContextFrom int      // Starting line number of context
 }
 
+// searchQuery holds what a search looks for and where.
+type searchQuery struct {
+	root         string // Absolute search path that exclusions are relative to
+	regex        *regexp.Regexp
+	filePattern  string
+	exclude      searchExclusions
+	contextLines int
+	countOnly    bool // Skip collecting context lines
+}
+
 // searchDirectory searches all files in a directory recursively.
-func (t *SearchFilesTool) searchDirectory(dirPath string, regex *regexp.Regexp, filePattern string, contextLines int) ([]searchMatch, error) {
+func (t *SearchFilesTool) searchDirectory(dirPath string, query searchQuery) ([]searchMatch, error) {
 	var matches []searchMatch
 
 	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
@@ -170,6 +278,10 @@ func (t *SearchFilesTool) searchDirectory(dirPath string, regex *regexp.Regexp,
 			if t.guard.ShouldIgnore(path) {
 				return filepath.SkipDir
 			}
+			// Skip excluded directories
+			if path != dirPath && query.excludes(path) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -183,37 +295,123 @@ func (t *SearchFilesTool) searchDirectory(dirPath string, regex *regexp.Regexp,
 			return nil
 		}
 
-		// Apply file pattern filter if specified
-		if filePattern != "" {
-			matched, matchErr := filepath.Match(filePattern, filepath.Base(path))
-			if matchErr != nil {
-				return fmt.Errorf("invalid file pattern: %w", matchErr)
-			}
-			if !matched {
-				return nil
-			}
+		fileMatches, err := t.searchPath(path, query)
+		if err != nil {
+			return err
 		}
+		matches = append(matches, fileMatches...)
+		return nil
+	})
 
-		// Skip binary files (simple heuristic)
-		if isBinaryFile(path) {
-			return nil
+	return matches, err
+}
+
+// indexCandidates returns the files under path that may match pattern, and
+// false when there is no index or it cannot answer for path.
+func (t *SearchFilesTool) indexCandidates(path, pattern string) ([]string, bool) {
+	if t.index == nil {
+		return nil, false
+	}
+	return t.index.Candidates(path, pattern)
+}
+
+// searchCandidates searches the files the index selected.
+func (t *SearchFilesTool) searchCandidates(paths []string, query searchQuery) ([]searchMatch, error) {
+	var matches []searchMatch
+	for _, path := range paths {
+		fileMatches, err := t.searchPath(path, query)
+		if err != nil {
+			return nil, err
 		}
+		matches = append(matches, fileMatches...)
+	}
+	return matches, nil
+}
 
-		// Search file
-		fileMatches, err := t.searchFile(path, regex, contextLines)
+// searchPath searches one file that passed the workspace checks, unless the
+// file pattern, exclusions or binary check leave it out.
+func (t *SearchFilesTool) searchPath(path string, query searchQuery) ([]searchMatch, error) {
+	// Apply file pattern filter if specified
+	if query.filePattern != "" {
+		matched, err := filepath.Match(query.filePattern, filepath.Base(path))
 		if err != nil {
-			return nil // Skip files we can't read
+			return nil, fmt.Errorf("invalid file pattern: %w", err)
+		}
+		if !matched {
+			return nil, nil
 		}
+	}
 
-		matches = append(matches, fileMatches...)
-		return nil
-	})
+	if query.excludes(path) {
+		return nil, nil
+	}
 
-	return matches, err
+	// Skip binary files (simple heuristic)
+	if isBinaryFile(path) {
+		return nil, nil
+	}
+
+	// Search file
+	fileMatches, err := t.searchFile(path, query.regex, query.contextLines, query.countOnly)
+	if err != nil {
+		return nil, nil // Skip files we can't read
+	}
+	return fileMatches, nil
+}
+
+// excludes reports whether path, or a directory between the search root and
+// path, matches an exclusion.
+func (q searchQuery) excludes(path string) bool {
+	if len(q.exclude) == 0 {
+		return false
+	}
+	relPath, err := filepath.Rel(q.root, path)
+	if err != nil || relPath == "." {
+		return false
+	}
+	return q.exclude.Match(filepath.ToSlash(relPath))
+}
+
+// searchExclusions are the exclude globs of a search.
+type searchExclusions []pathGlob
+
+// compileSearchExclusions compiles exclude globs the way find_files does,
+// ignoring blank entries.
+func compileSearchExclusions(patterns []string) (searchExclusions, error) {
+	var exclude searchExclusions
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		g, err := compilePathGlob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+		exclude = append(exclude, g)
+	}
+	return exclude, nil
+}
+
+// Match reports whether the relative path, or one of its parent
+// directories, matches any of the globs.
+func (e searchExclusions) Match(relPath string) bool {
+	for {
+		for _, g := range e {
+			if g.Match(relPath) {
+				return true
+			}
+		}
+		i := strings.LastIndex(relPath, "/")
+		if i < 0 {
+			return false
+		}
+		relPath = relPath[:i]
+	}
 }
 
 // searchFile searches for pattern in a single file.
-func (t *SearchFilesTool) searchFile(filePath string, regex *regexp.Regexp, contextLines int) ([]searchMatch, error) {
+// When countOnly is set, matches carry no line text or context.
+func (t *SearchFilesTool) searchFile(filePath string, regex *regexp.Regexp, contextLines int, countOnly bool) ([]searchMatch, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
@@ -229,6 +427,12 @@ func (t *SearchFilesTool) searchFile(filePath string, regex *regexp.Regexp, cont
 	for scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
+		if countOnly {
+			if regex.MatchString(line) {
+				matches = append(matches, searchMatch{FilePath: filePath, LineNumber: lineNum})
+			}
+			continue
+		}
 		lines = append(lines, line)
 
 		// Check if line matches
@@ -258,6 +462,9 @@ func (t *SearchFilesTool) searchFile(filePath string, regex *regexp.Regexp, cont
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
+	if countOnly {
+		return matches, nil
+	}
 
 	// Now add context to matches
 	for i := range matches {
@@ -278,9 +485,10 @@ func (t *SearchFilesTool) searchFile(filePath string, regex *regexp.Regexp, cont
 	return matches, nil
 }
 
-// formatMatches formats search matches into a readable string.
-func (t *SearchFilesTool) formatMatches(matches []searchMatch) string {
-	if len(matches) == 0 {
+// formatMatches formats search matches into a readable string, noting how
+// many of the total matches were left out.
+func (t *SearchFilesTool) formatMatches(matches []searchMatch, total int) string {
+	if total == 0 {
 		return "No matches found"
 	}
 
@@ -329,7 +537,37 @@ func (t *SearchFilesTool) formatMatches(matches []searchMatch) string {
 	}
 
 	// Add summary
-	fmt.Fprintf(&builder, "Found %d matches", len(matches))
+	if total > len(matches) {
+		fmt.Fprintf(&builder, "Found %d matches, showing the first %d. Narrow the search, use count_only to see which files match, or raise max_results to see more.", total, len(matches))
+	} else {
+		fmt.Fprintf(&builder, "Found %d matches", total)
+	}
+
+	return builder.String()
+}
+
+// formatCounts formats the number of matches in each file, noting how many
+// of the matching files were left out.
+func (t *SearchFilesTool) formatCounts(files []string, counts map[string]int, total int) string {
+	if total == 0 {
+		return "No matches found"
+	}
+
+	var builder strings.Builder
+	for _, file := range files {
+		relPath, err := t.guard.MakeRelative(file)
+		if err != nil {
+			relPath = file
+		}
+		fmt.Fprintf(&builder, "%6d  %s\n", counts[file], relPath)
+	}
+
+	fileCount := len(counts)
+	if fileCount > len(files) {
+		fmt.Fprintf(&builder, "\nFound %d matches in %d files, showing the first %d files. Narrow the search or raise max_results to see more.", total, fileCount, len(files))
+	} else {
+		fmt.Fprintf(&builder, "\nFound %d matches in %d files", total, fileCount)
+	}
 
 	return builder.String()
 }
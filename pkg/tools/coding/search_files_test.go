@@ -575,3 +575,212 @@ func TestSearchFilesTool_SpecificDirectory(t *testing.T) {
 		t.Errorf("Expected match_count=1, got %v", metadata["match_count"])
 	}
 }
+
+func TestSearchFilesTool_IndexMatchesWalk(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	writeTestFile(t, filepath.Join(tmpDir, ".gitignore"), "ignored_dir/")
+	os.MkdirAll(filepath.Join(tmpDir, "a", "b"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "a-b"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "ignored_dir"), 0755)
+	writeTestFile(t, filepath.Join(tmpDir, "main.go"), "package main\n\nfunc main() {\n\tHandle()\n}")
+	writeTestFile(t, filepath.Join(tmpDir, "a", "b", "handler.go"), "package b\n\nfunc Handle() {}")
+	writeTestFile(t, filepath.Join(tmpDir, "a-b", "notes.md"), "Call handle() first")
+	writeTestFile(t, filepath.Join(tmpDir, "ignored_dir", "handle.go"), "func Handle() {}")
+	writeTestFile(t, filepath.Join(tmpDir, "data.bin"), "Handle\x00")
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	walking := NewSearchFilesTool(guard)
+	indexed := NewSearchFilesTool(guard)
+	indexed.SetIndex(newReadyIndex(t, guard))
+
+	for _, args := range []string{
+		"<pattern>Handle</pattern>",
+		"<pattern>(?i)handle\\(</pattern>",
+		"<pattern>func \\w+</pattern><file_pattern>*.go</file_pattern>",
+		"<pattern>Handle</pattern><path>a</path>",
+		"<pattern>missing</pattern>",
+		"<pattern>^}</pattern>",
+	} {
+		input := []byte("<arguments>" + args + "</arguments>")
+		want, _, err := walking.Execute(context.Background(), input)
+		if err != nil {
+			t.Fatalf("%s: walking search failed: %v", args, err)
+		}
+		got, _, err := indexed.Execute(context.Background(), input)
+		if err != nil {
+			t.Fatalf("%s: indexed search failed: %v", args, err)
+		}
+		if got != want {
+			t.Errorf("%s: indexed result differs from walking\nindexed:\n%s\nwalked:\n%s", args, got, want)
+		}
+	}
+}
+
+func TestSearchFilesTool_CaseInsensitiveAndFixedStrings(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	writeTestFile(t, filepath.Join(tmpDir, "file.txt"), "Config.Load()\nconfig.load()\nConfigXLoad()")
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	tool := NewSearchFilesTool(guard)
+	indexed := NewSearchFilesTool(guard)
+	indexed.SetIndex(newReadyIndex(t, guard))
+
+	tests := []struct {
+		args string
+		want int
+	}{
+		{"<pattern>Config.Load()</pattern>", 2},
+		{"<pattern>Config.Load()</pattern><fixed_strings>true</fixed_strings>", 1},
+		{"<pattern>config.load()</pattern><fixed_strings>true</fixed_strings><case_insensitive>true</case_insensitive>", 2},
+		{"<pattern>CONFIG</pattern><case_insensitive>true</case_insensitive>", 3},
+	}
+	for _, tt := range tests {
+		for _, tool := range []*SearchFilesTool{tool, indexed} {
+			_, metadata, err := tool.Execute(context.Background(), []byte("<arguments>"+tt.args+"</arguments>"))
+			if err != nil {
+				t.Fatalf("%s: Execute failed: %v", tt.args, err)
+			}
+			if metadata["match_count"].(int) != tt.want {
+				t.Errorf("%s: match_count = %v, want %d", tt.args, metadata["match_count"], tt.want)
+			}
+		}
+	}
+}
+
+func TestSearchFilesTool_Exclude(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	os.MkdirAll(filepath.Join(tmpDir, "vendor", "lib"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "pkg", "api"), 0755)
+	writeTestFile(t, filepath.Join(tmpDir, "main.go"), "TODO: main")
+	writeTestFile(t, filepath.Join(tmpDir, "vendor", "lib", "lib.go"), "TODO: vendored")
+	writeTestFile(t, filepath.Join(tmpDir, "pkg", "api", "api.go"), "TODO: api")
+	writeTestFile(t, filepath.Join(tmpDir, "pkg", "api", "api_test.go"), "TODO: test")
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	tool := NewSearchFilesTool(guard)
+	indexed := NewSearchFilesTool(guard)
+	indexed.SetIndex(newReadyIndex(t, guard))
+
+	xmlInput := `<arguments>
+	<pattern>TODO</pattern>
+	<exclude><glob>vendor</glob><glob>*_test.go</glob></exclude>
+</arguments>`
+
+	for _, tool := range []*SearchFilesTool{tool, indexed} {
+		result, metadata, err := tool.Execute(context.Background(), []byte(xmlInput))
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if strings.Contains(result, "vendored") || strings.Contains(result, "TODO: test") {
+			t.Errorf("Expected excluded files to be left out, got: %s", result)
+		}
+		if metadata["match_count"].(int) != 2 {
+			t.Errorf("Expected match_count=2, got %v", metadata["match_count"])
+		}
+	}
+
+	// Exclusions are relative to the search path
+	_, metadata, err := tool.Execute(context.Background(), []byte(`<arguments>
+	<pattern>TODO</pattern>
+	<path>pkg</path>
+	<exclude><glob>api/*_test.go</glob></exclude>
+</arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if metadata["match_count"].(int) != 1 {
+		t.Errorf("Expected match_count=1, got %v", metadata["match_count"])
+	}
+
+	_, _, err = tool.Execute(context.Background(), []byte(`<arguments>
+	<pattern>TODO</pattern>
+	<exclude><glob>[</glob></exclude>
+</arguments>`))
+	if err == nil {
+		t.Error("Expected error for invalid exclude pattern")
+	}
+}
+
+func TestSearchFilesTool_MaxResults(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	writeTestFile(t, filepath.Join(tmpDir, "a.txt"), "match 1\nmatch 2\nmatch 3")
+	writeTestFile(t, filepath.Join(tmpDir, "b.txt"), "match 4\nmatch 5")
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	tool := NewSearchFilesTool(guard)
+
+	xmlInput := `<arguments>
+	<pattern>match</pattern>
+	<max_results>2</max_results>
+	<context_lines>1</context_lines>
+</arguments>`
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if strings.Contains(result, "▶ 3 |") || strings.Contains(result, "b.txt") {
+		t.Errorf("Expected only the first 2 matches, got: %s", result)
+	}
+	if !strings.Contains(result, "Found 5 matches, showing the first 2") {
+		t.Errorf("Expected the summary to note the truncation, got: %s", result)
+	}
+	if metadata["match_count"].(int) != 5 || metadata["shown"].(int) != 2 || metadata["truncated"] != true {
+		t.Errorf("Unexpected metadata: %v", metadata)
+	}
+}
+
+func TestSearchFilesTool_CountOnly(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	os.Mkdir(filepath.Join(tmpDir, "sub"), 0755)
+	writeTestFile(t, filepath.Join(tmpDir, "a.txt"), "match\nmatch\nother\nmatch")
+	writeTestFile(t, filepath.Join(tmpDir, "sub", "b.txt"), "match")
+	writeTestFile(t, filepath.Join(tmpDir, "c.txt"), "nothing here")
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	tool := NewSearchFilesTool(guard)
+
+	xmlInput := `<arguments>
+	<pattern>match</pattern>
+	<count_only>true</count_only>
+</arguments>`
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	want := fmt.Sprintf("%6d  a.txt\n%6d  %s\n\nFound 4 matches in 2 files", 3, 1, filepath.Join("sub", "b.txt"))
+	if result != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, result)
+	}
+	if metadata["match_count"].(int) != 4 || metadata["files_with_matches"].(int) != 2 {
+		t.Errorf("Unexpected metadata: %v", metadata)
+	}
+
+	// max_results caps the files listed
+	result, metadata, err = tool.Execute(context.Background(), []byte(`<arguments>
+	<pattern>match</pattern>
+	<count_only>true</count_only>
+	<max_results>1</max_results>
+</arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if strings.Contains(result, "b.txt") || !strings.Contains(result, "showing the first 1 files") {
+		t.Errorf("Expected only the first file, got: %s", result)
+	}
+	if metadata["truncated"] != true {
+		t.Errorf("Expected truncated=true, got %v", metadata["truncated"])
+	}
+}
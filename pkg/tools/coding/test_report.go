@@ -0,0 +1,498 @@
+package coding
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxFailureMessageLines and maxFailureMessageLen bound each reported
+	// failure message
+	maxFailureMessageLines = 12
+	maxFailureMessageLen   = 1200
+)
+
+// TestFailure is a single failed test, or a package or file that failed to
+// build or load
+type TestFailure struct {
+	Name    string `json:"name"`
+	Package string `json:"package,omitempty"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Location returns "file:line", "file" or "" for the failure.
+func (f TestFailure) Location() string {
+	switch {
+	case f.File == "":
+		return ""
+	case f.Line > 0:
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	default:
+		return f.File
+	}
+}
+
+// TestReport is the parsed outcome of a test run
+type TestReport struct {
+	Framework string        `json:"framework"`
+	Passed    int           `json:"passed"`
+	Failed    int           `json:"failed"`
+	Skipped   int           `json:"skipped"`
+	Failures  []TestFailure `json:"failures,omitempty"`
+}
+
+// ParseTestOutput parses the output of a test run for framework. workDir is
+// used to shorten absolute file paths.
+func ParseTestOutput(framework, stdout, stderr, workDir string) *TestReport {
+	var report *TestReport
+	switch framework {
+	case FrameworkGo:
+		report = parseGoTestOutput(stdout, stderr)
+	case FrameworkPytest:
+		report = parsePytestOutput(stdout + stderr)
+	case FrameworkJest:
+		report = parseJestOutput(stdout, workDir)
+	default:
+		report = &TestReport{}
+	}
+	report.Framework = framework
+	return report
+}
+
+// Format renders the report as a compact summary followed by the first
+// maxFailures failures.
+func (r *TestReport) Format(command string, exitCode int, duration time.Duration, maxFailures int) string {
+	var b strings.Builder
+
+	status := "PASSED"
+	if exitCode != 0 || r.Failed > 0 {
+		status = "FAILED"
+	}
+	fmt.Fprintf(&b, "%s tests %s: %d passed, %d failed, %d skipped in %s\n",
+		r.Framework, status, r.Passed, r.Failed, r.Skipped, duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "Command: %s (exit code %d)", command, exitCode)
+
+	if len(r.Failures) == 0 {
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\n\nFailures:")
+	for i, f := range r.Failures {
+		if i == maxFailures {
+			fmt.Fprintf(&b, "\n\n... and %d more failures", len(r.Failures)-maxFailures)
+			break
+		}
+
+		fmt.Fprintf(&b, "\n\n%d. %s", i+1, f.Name)
+		if f.Package != "" {
+			fmt.Fprintf(&b, " [%s]", f.Package)
+		}
+		if loc := f.Location(); loc != "" {
+			fmt.Fprintf(&b, " at %s", loc)
+		}
+		if f.Message != "" {
+			b.WriteString("\n")
+			for _, line := range strings.Split(f.Message, "\n") {
+				b.WriteString("\n   ")
+				b.WriteString(line)
+			}
+		}
+	}
+	return b.String()
+}
+
+// trimMessage bounds a failure message to a few lines
+func trimMessage(lines []string) string {
+	var kept []string
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" && (len(kept) == 0 || kept[len(kept)-1] == "") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	for len(kept) > 0 && kept[len(kept)-1] == "" {
+		kept = kept[:len(kept)-1]
+	}
+
+	truncated := false
+	if len(kept) > maxFailureMessageLines {
+		kept = kept[:maxFailureMessageLines]
+		truncated = true
+	}
+
+	msg := dedent(kept)
+	if len(msg) > maxFailureMessageLen {
+		msg = msg[:maxFailureMessageLen]
+		truncated = true
+	}
+	if truncated {
+		msg += "\n..."
+	}
+	return msg
+}
+
+// dedent removes the indentation common to all non-empty lines
+func dedent(lines []string) string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i, line := range lines {
+			if len(line) >= indent {
+				lines[i] = line[indent:]
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ansiEscape matches terminal color codes
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// goTestEvent is one line of go test -json output
+type goTestEvent struct {
+	Action      string `json:"Action"`
+	Package     string `json:"Package"`
+	Test        string `json:"Test"`
+	Output      string `json:"Output"`
+	ImportPath  string `json:"ImportPath"`
+	FailedBuild string `json:"FailedBuild"`
+}
+
+// goFileLine matches the "file_test.go:12: message" prefix of a test log line
+var goFileLine = regexp.MustCompile(`^\s*([\w./-]+\.go):(\d+):\s?(.*)$`)
+
+// parseGoTestOutput parses go test -json events. Build errors are reported
+// as build-output events on Go 1.24 and later, and on stderr before that.
+func parseGoTestOutput(stdout, stderr string) *TestReport {
+	report := &TestReport{}
+	output := make(map[string][]string) // package and test -> output lines
+	buildOutput := make(map[string][]string)
+	failedPackages := make(map[string]string) // package -> failed build
+	var packageOrder []string
+	var failures []TestFailure
+
+	scanner := bufio.NewScanner(strings.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var ev goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil || ev.Action == "" {
+			continue
+		}
+		key := ev.Package + "\x00" + ev.Test
+
+		switch ev.Action {
+		case "output":
+			output[key] = append(output[key], strings.TrimRight(ev.Output, "\n"))
+		case "build-output":
+			buildOutput[ev.ImportPath] = append(buildOutput[ev.ImportPath], strings.TrimRight(ev.Output, "\n"))
+		case "pass":
+			if ev.Test != "" {
+				report.Passed++
+			}
+		case "skip":
+			if ev.Test != "" {
+				report.Skipped++
+			}
+		case "fail":
+			if ev.Test == "" {
+				failedPackages[ev.Package] = ev.FailedBuild
+				packageOrder = append(packageOrder, ev.Package)
+				continue
+			}
+			failures = append(failures, goTestFailure(ev.Package, ev.Test, output[key]))
+		}
+	}
+
+	// A parent test fails whenever one of its subtests does, so only the
+	// subtests are worth reporting
+	for _, f := range failures {
+		if !hasFailedSubtest(failures, f) {
+			report.Failures = append(report.Failures, f)
+		}
+	}
+	report.Failed = len(report.Failures)
+
+	// Packages that failed without a failing test did not build or crashed
+	// outside a test
+	for _, pkg := range packageOrder {
+		if packageHasFailure(report.Failures, pkg) {
+			continue
+		}
+		lines := buildOutput[failedPackages[pkg]]
+		if len(lines) == 0 {
+			lines = packageOutput(output[pkg+"\x00"])
+		}
+		if len(lines) == 0 {
+			lines = strings.Split(strings.TrimSpace(stderr), "\n")
+		}
+		failure := TestFailure{Name: "package " + pkg, Package: pkg}
+		for _, line := range lines {
+			if m := goFileLine.FindStringSubmatch(line); m != nil {
+				failure.File = m[1]
+				failure.Line, _ = strconv.Atoi(m[2])
+				break
+			}
+		}
+		failure.Message = trimMessage(lines)
+		report.Failures = append(report.Failures, failure)
+		report.Failed++
+	}
+
+	return report
+}
+
+// goTestFailure builds a failure from a test's output, dropping go test's
+// own status lines
+func goTestFailure(pkg, test string, lines []string) TestFailure {
+	failure := TestFailure{Name: test, Package: pkg}
+	var message []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- ") {
+			continue
+		}
+		if failure.File == "" {
+			if m := goFileLine.FindStringSubmatch(line); m != nil {
+				failure.File = m[1]
+				failure.Line, _ = strconv.Atoi(m[2])
+				line = m[3]
+			}
+		}
+		message = append(message, line)
+	}
+	failure.Message = trimMessage(message)
+	return failure
+}
+
+// packageOutput drops go test's status lines from a package's output
+func packageOutput(lines []string) []string {
+	var kept []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "FAIL" || trimmed == "PASS" || strings.HasPrefix(trimmed, "FAIL\t") ||
+			strings.HasPrefix(trimmed, "ok ") || strings.HasPrefix(trimmed, "exit status") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return kept
+}
+
+func hasFailedSubtest(failures []TestFailure, parent TestFailure) bool {
+	for _, f := range failures {
+		if f.Package == parent.Package && strings.HasPrefix(f.Name, parent.Name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func packageHasFailure(failures []TestFailure, pkg string) bool {
+	for _, f := range failures {
+		if f.Package == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// pytestSummary matches the final "2 failed, 10 passed in 1.23s" line
+	pytestSummary = regexp.MustCompile(`^=*\s*(\d+ \w+.*) in [\d.]+s`)
+	pytestCount   = regexp.MustCompile(`(\d+) (passed|failed|skipped|error|errors|xfailed|xpassed)`)
+	// pytestShort matches a "FAILED path::test - message" line of the short
+	// test summary
+	pytestShort = regexp.MustCompile(`^(FAILED|ERROR) (\S+)(?: - (.*))?$`)
+	// pytestSection matches the "____ test_name ____" header of a failure's
+	// traceback
+	pytestSection  = regexp.MustCompile(`^_{3,} (?:ERROR at \w+ of |ERROR collecting )?(.+?) _{3,}$`)
+	pytestFileLine = regexp.MustCompile(`^(\S+\.py):(\d+): `)
+)
+
+// pytestTrace is the traceback printed for one failure
+type pytestTrace struct {
+	lines map[string]int // File -> line of the last frame in that file
+	error []string       // The "E   ..." lines
+}
+
+// parsePytestOutput parses the output of pytest -rfE --tb=short
+func parsePytestOutput(output string) *TestReport {
+	report := &TestReport{}
+	traces := make(map[string]*pytestTrace)
+	var current *pytestTrace
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if m := pytestSection.FindStringSubmatch(line); m != nil {
+			current = &pytestTrace{lines: make(map[string]int)}
+			traces[m[1]] = current
+			continue
+		}
+		if strings.HasPrefix(line, "====") {
+			current = nil
+		}
+
+		if current != nil {
+			if m := pytestFileLine.FindStringSubmatch(line); m != nil {
+				current.lines[m[1]], _ = strconv.Atoi(m[2])
+			} else if rest, ok := strings.CutPrefix(line, "E "); ok {
+				current.error = append(current.error, strings.TrimSpace(rest))
+			}
+			continue
+		}
+
+		if m := pytestShort.FindStringSubmatch(line); m != nil {
+			report.Failures = append(report.Failures, pytestFailure(m[2], m[3], traces))
+			continue
+		}
+
+		if m := pytestSummary.FindStringSubmatch(line); m != nil {
+			for _, count := range pytestCount.FindAllStringSubmatch(m[1], -1) {
+				n, _ := strconv.Atoi(count[1])
+				switch count[2] {
+				case "passed", "xpassed":
+					report.Passed += n
+				case "failed", "error", "errors":
+					report.Failed += n
+				case "skipped", "xfailed":
+					report.Skipped += n
+				}
+			}
+		}
+	}
+
+	if report.Failed < len(report.Failures) {
+		report.Failed = len(report.Failures)
+	}
+	return report
+}
+
+// pytestFailure builds a failure from a short summary line and the matching
+// traceback
+func pytestFailure(nodeID, message string, traces map[string]*pytestTrace) TestFailure {
+	failure := TestFailure{Name: nodeID}
+	file, name, hasName := strings.Cut(nodeID, "::")
+	failure.File = file
+
+	// Tracebacks are headed by the test name with "::" written as "."
+	header := file
+	if hasName {
+		header = strings.ReplaceAll(name, "::", ".")
+	}
+	if trace, ok := traces[header]; ok {
+		// The last frames are often in the code under test, so the line is
+		// taken from the test file's own frame
+		failure.Line = trace.lines[file]
+		if message == "" {
+			message = strings.Join(trace.error, "\n")
+		}
+	}
+	failure.Message = trimMessage(strings.Split(message, "\n"))
+	return failure
+}
+
+// jestResults is the part of jest --json output run_tests uses
+type jestResults struct {
+	NumPassedTests  int `json:"numPassedTests"`
+	NumFailedTests  int `json:"numFailedTests"`
+	NumPendingTests int `json:"numPendingTests"`
+	NumTodoTests    int `json:"numTodoTests"`
+	TestResults     []struct {
+		Name             string `json:"name"`
+		Status           string `json:"status"`
+		Message          string `json:"message"`
+		AssertionResults []struct {
+			FullName        string   `json:"fullName"`
+			Status          string   `json:"status"`
+			FailureMessages []string `json:"failureMessages"`
+			Location        *struct {
+				Line int `json:"line"`
+			} `json:"location"`
+		} `json:"assertionResults"`
+	} `json:"testResults"`
+}
+
+// jestStackLine matches a stack frame in a failure message
+var jestStackLine = regexp.MustCompile(`^\s+at `)
+
+// parseJestOutput parses jest --json output
+func parseJestOutput(stdout, workDir string) *TestReport {
+	report := &TestReport{}
+
+	// Anything printed before the JSON document is not part of the results
+	start := strings.Index(stdout, "{")
+	if start < 0 {
+		return report
+	}
+	var results jestResults
+	if err := json.NewDecoder(strings.NewReader(stdout[start:])).Decode(&results); err != nil {
+		return report
+	}
+
+	report.Passed = results.NumPassedTests
+	report.Failed = results.NumFailedTests
+	report.Skipped = results.NumPendingTests + results.NumTodoTests
+
+	for _, suite := range results.TestResults {
+		file := suite.Name
+		if rel, err := filepath.Rel(workDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+
+		suiteFailures := 0
+		for _, test := range suite.AssertionResults {
+			if test.Status != "failed" {
+				continue
+			}
+			suiteFailures++
+			failure := TestFailure{Name: test.FullName, File: file}
+			if test.Location != nil {
+				failure.Line = test.Location.Line
+			}
+			failure.Message = jestMessage(strings.Join(test.FailureMessages, "\n"))
+			report.Failures = append(report.Failures, failure)
+		}
+
+		// A suite that fails without failing tests did not compile or load
+		if suite.Status == "failed" && suiteFailures == 0 {
+			report.Failures = append(report.Failures, TestFailure{
+				Name:    "suite " + file,
+				File:    file,
+				Message: jestMessage(suite.Message),
+			})
+			report.Failed++
+		}
+	}
+
+	return report
+}
+
+// jestMessage strips colors and stack frames from a jest failure message
+func jestMessage(msg string) string {
+	var lines []string
+	for _, line := range strings.Split(ansiEscape.ReplaceAllString(msg, ""), "\n") {
+		if jestStackLine.MatchString(line) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return trimMessage(lines)
+}
@@ -0,0 +1,157 @@
+package coding
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/jpeg" // Register the JPEG decoder for image.DecodeConfig
+	_ "image/png"  // Register the PNG decoder for image.DecodeConfig
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/types"
+)
+
+// maxImageSize is the largest image view_image attaches. Providers reject
+// larger images, and each one costs context on every later turn.
+const maxImageSize = 5 << 20
+
+// ViewImageTool shows the model a PNG or JPEG image, such as a screenshot of
+// a UI bug, by attaching it to the conversation. Models that do not accept
+// image input get a note to use analyze_document instead.
+type ViewImageTool struct {
+	guard         *workspace.Guard
+	screenshotDir string
+}
+
+// NewViewImageTool creates a new ViewImageTool with workspace security.
+func NewViewImageTool(guard *workspace.Guard) *ViewImageTool {
+	return &ViewImageTool{
+		guard: guard,
+	}
+}
+
+// SetScreenshotDir sets the directory browser screenshots are saved to.
+// Images in it can be viewed even when it is outside the workspace or
+// ignored.
+func (t *ViewImageTool) SetScreenshotDir(dir string) {
+	t.screenshotDir = dir
+}
+
+// Name returns the tool name.
+func (t *ViewImageTool) Name() string {
+	return "view_image"
+}
+
+// Description returns the tool description.
+func (t *ViewImageTool) Description() string {
+	return "Look at a PNG or JPEG image from the workspace, such as a screenshot of a UI bug or one taken with browser_screenshot. The image is attached to the conversation so you can see it directly."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *ViewImageTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path to the image (relative to workspace), or the path browser_screenshot returned. Supported formats: .png, .jpg, .jpeg",
+			},
+		},
+		[]string{"path"},
+	)
+}
+
+// Execute loads the image and returns it in the result metadata, for the
+// agent to attach to the conversation.
+func (t *ViewImageTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Path    string   `xml:"path"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if input.Path == "" {
+		return "", nil, fmt.Errorf("missing required parameter: path")
+	}
+
+	absPath, err := t.resolveImagePath(input.Path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("file not found: %s", input.Path)
+	}
+	if info.IsDir() {
+		return "", nil, fmt.Errorf("path is a directory, not an image: %s", input.Path)
+	}
+	if info.Size() > maxImageSize {
+		return "", nil, fmt.Errorf("image is %d bytes, larger than the %d byte limit; crop or downscale it first", info.Size(), maxImageSize)
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	// Trust the content over the extension
+	mediaType := http.DetectContentType(data)
+	if mediaType != "image/png" && mediaType != "image/jpeg" {
+		return "", nil, fmt.Errorf("unsupported image format: %s (supported: PNG, JPEG)", mediaType)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	result := fmt.Sprintf("Image: %s\nFormat: %s\nDimensions: %dx%d pixels\nSize: %d bytes",
+		input.Path, mediaType, config.Width, config.Height, len(data))
+
+	metadata := map[string]any{
+		"path":              input.Path,
+		"width":             config.Width,
+		"height":            config.Height,
+		"bytes":             len(data),
+		types.MetadataImage: types.Image{MediaType: mediaType, Data: data},
+	}
+
+	return result, metadata, nil
+}
+
+// resolveImagePath returns the absolute path of the image at path. Images in
+// the screenshot directory are allowed as they are; others go through the
+// workspace guard.
+func (t *ViewImageTool) resolveImagePath(path string) (string, error) {
+	if t.screenshotDir != "" && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(t.screenshotDir, filepath.Clean(path)); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.Clean(path), nil
+		}
+	}
+
+	if err := t.guard.ValidatePath(path); err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+	absPath, err := t.guard.ResolvePath(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if t.guard.ShouldIgnore(absPath) {
+		return "", fmt.Errorf("file '%s' is ignored by .gitignore, .forgeignore, or default patterns", path)
+	}
+	return absPath, nil
+}
+
+// IsLoopBreaking returns false as this is an operational tool.
+func (t *ViewImageTool) IsLoopBreaking() bool {
+	return false
+}
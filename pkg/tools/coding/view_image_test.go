@@ -0,0 +1,91 @@
+package coding
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestPNG writes a width x height PNG to path.
+func writeTestPNG(t *testing.T, path string, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0600))
+	return buf.Bytes()
+}
+
+func TestViewImageTool_Execute(t *testing.T) {
+	tmpDir := t.TempDir()
+	guard, err := workspace.NewGuard(tmpDir)
+	require.NoError(t, err)
+	data := writeTestPNG(t, filepath.Join(tmpDir, "bug.png"), 4, 3)
+
+	tool := NewViewImageTool(guard)
+	result, metadata, err := tool.Execute(context.Background(), []byte(`<arguments><path>bug.png</path></arguments>`))
+	require.NoError(t, err)
+
+	assert.Contains(t, result, "Dimensions: 4x3 pixels")
+	assert.Equal(t, 4, metadata["width"])
+	assert.Equal(t, 3, metadata["height"])
+	assert.Equal(t, types.Image{MediaType: "image/png", Data: data}, metadata[types.MetadataImage])
+}
+
+func TestViewImageTool_Execute_ScreenshotDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	guard, err := workspace.NewGuard(tmpDir)
+	require.NoError(t, err)
+
+	// Screenshots may be saved outside the workspace, e.g. to CI artifacts
+	screenshotDir := t.TempDir()
+	path := filepath.Join(screenshotDir, "page.png")
+	writeTestPNG(t, path, 2, 2)
+
+	tool := NewViewImageTool(guard)
+	_, _, err = tool.Execute(context.Background(), []byte(`<arguments><path>`+path+`</path></arguments>`))
+	assert.Error(t, err)
+
+	tool.SetScreenshotDir(screenshotDir)
+	_, metadata, err := tool.Execute(context.Background(), []byte(`<arguments><path>`+path+`</path></arguments>`))
+	require.NoError(t, err)
+	assert.Contains(t, metadata, types.MetadataImage)
+}
+
+func TestViewImageTool_Execute_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+	guard, err := workspace.NewGuard(tmpDir)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "notes.png"), []byte("not an image"), 0600))
+	big := make([]byte, maxImageSize+1)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "big.png"), big, 0600))
+
+	tool := NewViewImageTool(guard)
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"missing path", "", "missing required parameter"},
+		{"not found", "missing.png", "file not found"},
+		{"outside workspace", "../outside.png", "invalid path"},
+		{"not an image", "notes.png", "unsupported image format"},
+		{"too large", "big.png", "larger than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tool.Execute(context.Background(), []byte(`<arguments><path>`+tt.path+`</path></arguments>`))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
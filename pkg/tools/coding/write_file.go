@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/entrhq/forge/pkg/agent/tools"
 	"github.com/entrhq/forge/pkg/security/workspace"
@@ -30,7 +31,7 @@ func (t *WriteFileTool) Name() string {
 
 // Description returns the tool description.
 func (t *WriteFileTool) Description() string {
-	return "Write content to a file, creating it if it doesn't exist or overwriting if it does. Automatically creates parent directories as needed."
+	return "Write content to a file, creating it if it doesn't exist or overwriting if it does. Automatically creates parent directories as needed. The file is replaced atomically, so an interrupted write leaves the previous version intact."
 }
 
 // Schema returns the JSON schema for the tool's input parameters.
@@ -43,7 +44,11 @@ func (t *WriteFileTool) Schema() map[string]any {
 			},
 			"content": map[string]any{
 				"type":        "string",
-				"description": "Content to write to the file",
+				"description": "Content to write to the file (at most 10 MiB)",
+			},
+			"backup": map[string]any{
+				"type":        "boolean",
+				"description": "Keep a copy of the file being overwritten under .forge/backups/ (default: false)",
 			},
 		},
 		[]string{"path", "content"},
@@ -56,6 +61,7 @@ func (t *WriteFileTool) Execute(ctx context.Context, argsXML []byte) (string, ma
 		XMLName xml.Name `xml:"arguments"`
 		Path    string   `xml:"path"`
 		Content string   `xml:"content"`
+		Backup  bool     `xml:"backup"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
@@ -77,6 +83,14 @@ func (t *WriteFileTool) Execute(ctx context.Context, argsXML []byte) (string, ma
 		return "", nil, fmt.Errorf("failed to resolve path: %w", err)
 	}
 
+	// Enforce write-protection and size rules before touching the file system
+	if sizeErr := checkWriteSize(input.Content); sizeErr != nil {
+		return "", nil, sizeErr
+	}
+	if ruleErr := t.guard.CheckWrite(input.Path, int64(len(input.Content))); ruleErr != nil {
+		return "", nil, ruleErr
+	}
+
 	// Create parent directories if they don't exist
 	dir := filepath.Dir(absPath)
 	if mkdirErr := os.MkdirAll(dir, 0750); mkdirErr != nil {
@@ -91,17 +105,23 @@ func (t *WriteFileTool) Execute(ctx context.Context, argsXML []byte) (string, ma
 		originalContent = string(existingContent)
 	}
 
-	// Write file atomically using a temporary file
-	tmpPath := absPath + ".tmp"
-	if writeErr := os.WriteFile(tmpPath, []byte(input.Content), 0600); writeErr != nil {
-		return "", nil, fmt.Errorf("failed to write temporary file: %w", writeErr)
+	// Get relative path for output message
+	relPath, err := t.guard.MakeRelative(absPath)
+	if err != nil {
+		relPath = input.Path // Fallback to original path
+	}
+
+	// Keep the previous version before replacing it
+	var backupPath string
+	if input.Backup && fileExists {
+		backupPath, err = backupFile(t.guard.WorkspaceDir(), absPath, relPath, originalContent, time.Now())
+		if err != nil {
+			return "", nil, err
+		}
 	}
 
-	// Rename temporary file to target file (atomic operation)
-	if renameErr := os.Rename(tmpPath, absPath); renameErr != nil {
-		// Clean up temporary file on error
-		os.Remove(tmpPath)
-		return "", nil, fmt.Errorf("failed to rename temporary file: %w", renameErr)
+	if writeErr := atomicWriteFile(ctx, absPath, input.Content); writeErr != nil {
+		return "", nil, writeErr
 	}
 
 	// Calculate line changes using the original content read before writing
@@ -113,12 +133,6 @@ func (t *WriteFileTool) Execute(ctx context.Context, argsXML []byte) (string, ma
 		return "", nil, fmt.Errorf("failed to get file info: %w", err)
 	}
 
-	// Get relative path for output message
-	relPath, err := t.guard.MakeRelative(absPath)
-	if err != nil {
-		relPath = input.Path // Fallback to original path
-	}
-
 	var message string
 	if fileExists {
 		message = fmt.Sprintf("File '%s' overwritten successfully (+%d/-%d lines)",
@@ -136,10 +150,23 @@ func (t *WriteFileTool) Execute(ctx context.Context, argsXML []byte) (string, ma
 		"lines_removed": lineChanges.LinesRemoved,
 		"size_bytes":    fileInfo.Size(),
 	}
+	if backupPath != "" {
+		message += fmt.Sprintf("; previous version saved to '%s'", backupPath)
+		metadata["backup_path"] = backupPath
+	}
 
 	return message, metadata, nil
 }
 
+// checkWriteSize rejects content larger than write_file accepts.
+func checkWriteSize(content string) error {
+	if len(content) > maxWriteFileSize {
+		return fmt.Errorf("content is %d bytes, over the %d byte write_file limit; generate the file with a command or split it into smaller files",
+			len(content), maxWriteFileSize)
+	}
+	return nil
+}
+
 // IsLoopBreaking returns false as this tool doesn't break the agent loop.
 func (t *WriteFileTool) IsLoopBreaking() bool {
 	return false
@@ -172,6 +199,14 @@ func (t *WriteFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*t
 		return nil, fmt.Errorf("failed to resolve path: %w", err)
 	}
 
+	// Don't ask for approval of a write the size guard or path rules will reject
+	if sizeErr := checkWriteSize(input.Content); sizeErr != nil {
+		return nil, sizeErr
+	}
+	if ruleErr := t.guard.CheckWrite(input.Path, int64(len(input.Content))); ruleErr != nil {
+		return nil, ruleErr
+	}
+
 	// Check if file exists
 	var previewContent string
 	var title, description string
@@ -213,7 +248,7 @@ func (t *WriteFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*t
 
 	language := detectLanguage(relPath)
 
-	return &tools.ToolPreview{
+	preview := &tools.ToolPreview{
 		Type:        previewType,
 		Title:       title,
 		Description: description,
@@ -223,5 +258,7 @@ func (t *WriteFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*t
 			"language":  language,
 			"size":      len(input.Content),
 		},
-	}, nil
+	}
+	markOutsideWorkspace(preview, t.guard, absPath)
+	return preview, nil
 }
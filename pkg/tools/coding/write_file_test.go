@@ -2,11 +2,14 @@ package coding
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/entrhq/forge/pkg/security/workspace"
 )
 
 func TestWriteFileTool_CreateNewFile(t *testing.T) {
@@ -436,3 +439,213 @@ func TestWriteFileTool_LineChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteFileTool_PathRules(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	if err := guard.SetPathRules(workspace.PathRules{DenyWrite: []string{"*.lock"}}); err != nil {
+		t.Fatalf("SetPathRules failed: %v", err)
+	}
+	tool := NewWriteFileTool(guard)
+
+	xmlInput := `<arguments>
+	<path>deps/yarn.lock</path>
+	<content>pinned</content>
+</arguments>`
+
+	_, _, err := tool.Execute(context.Background(), []byte(xmlInput))
+	var ruleErr *workspace.PathRuleError
+	if !errors.As(err, &ruleErr) || ruleErr.Rule != workspace.RuleDenyWrite {
+		t.Fatalf("Expected deny_write PathRuleError, got: %v", err)
+	}
+
+	if _, statErr := os.Stat(filepath.Join(tmpDir, "deps")); !os.IsNotExist(statErr) {
+		t.Error("Expected no directories to be created for a rejected write")
+	}
+}
+
+func TestWriteFileTool_CancelledWriteKeepsOriginal(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	testFile := filepath.Join(tmpDir, "main.go")
+	writeTestFile(t, testFile, "package main\n")
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	tool := NewWriteFileTool(guard)
+
+	// A run interrupted mid-write must not leave the file half written
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	xmlInput := fmt.Sprintf(`<arguments>
+	<path>main.go</path>
+	<content>%s</content>
+</arguments>`, strings.Repeat("// filler\n", 20000))
+
+	if _, _, err := tool.Execute(ctx, []byte(xmlInput)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "package main\n" {
+		t.Errorf("Expected the original content, got %d bytes", len(content))
+	}
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the original file, found %d entries", len(entries))
+	}
+}
+
+func TestWriteFileTool_KeepsPermissions(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	testFile := filepath.Join(tmpDir, "run.sh")
+	writeTestFile(t, testFile, "#!/bin/sh\n")
+	if err := os.Chmod(testFile, 0755); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	tool := NewWriteFileTool(guard)
+
+	xmlInput := `<arguments>
+	<path>run.sh</path>
+	<content>#!/bin/sh
+echo hello</content>
+</arguments>`
+
+	if _, _, err := tool.Execute(context.Background(), []byte(xmlInput)); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	info, err := os.Stat(testFile)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("Expected mode 0755 to be kept, got %o", info.Mode().Perm())
+	}
+}
+
+func TestWriteFileTool_Backup(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	testFile := filepath.Join(tmpDir, "pkg", "config.go")
+	if err := os.MkdirAll(filepath.Dir(testFile), 0750); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeTestFile(t, testFile, "old version")
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	tool := NewWriteFileTool(guard)
+
+	xmlInput := `<arguments>
+	<path>pkg/config.go</path>
+	<content>new version</content>
+	<backup>true</backup>
+</arguments>`
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	backupPath, ok := metadata["backup_path"].(string)
+	if !ok || !strings.HasPrefix(backupPath, ".forge/backups/") || !strings.HasSuffix(backupPath, "/pkg/config.go") {
+		t.Fatalf("Expected a backup under .forge/backups/, got %v", metadata["backup_path"])
+	}
+	if !strings.Contains(result, backupPath) {
+		t.Errorf("Expected the backup path in the result, got: %s", result)
+	}
+
+	backup, err := os.ReadFile(filepath.Join(tmpDir, filepath.FromSlash(backupPath)))
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	if string(backup) != "old version" {
+		t.Errorf("Expected the previous version in the backup, got: %s", string(backup))
+	}
+
+	// A new file has nothing to back up
+	xmlInput = `<arguments>
+	<path>fresh.go</path>
+	<content>package fresh</content>
+	<backup>true</backup>
+</arguments>`
+	_, metadata, err = tool.Execute(context.Background(), []byte(xmlInput))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, ok := metadata["backup_path"]; ok {
+		t.Errorf("Expected no backup for a new file, got %v", metadata["backup_path"])
+	}
+}
+
+func TestWriteFileTool_SizeGuard(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	tool := NewWriteFileTool(guard)
+
+	xmlInput := fmt.Sprintf(`<arguments>
+	<path>huge.txt</path>
+	<content>%s</content>
+</arguments>`, strings.Repeat("a", maxWriteFileSize+1))
+
+	_, _, err := tool.Execute(context.Background(), []byte(xmlInput))
+	if err == nil || !strings.Contains(err.Error(), "write_file limit") {
+		t.Fatalf("Expected the size guard to reject the write, got: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(tmpDir, "huge.txt")); !os.IsNotExist(statErr) {
+		t.Error("Expected no file to be written")
+	}
+	if _, err := tool.GeneratePreview(context.Background(), []byte(xmlInput)); err == nil {
+		t.Error("Expected the preview to reject the write too")
+	}
+}
+
+func TestWriteFileTool_PreviewOutsideWorkspace(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	schemasDir := t.TempDir()
+
+	guard := createWorkspaceGuard(t, tmpDir)
+	if err := guard.SetAllowedDirs([]workspace.AllowedDir{{Path: schemasDir}}); err != nil {
+		t.Fatalf("SetAllowedDirs failed: %v", err)
+	}
+	tool := NewWriteFileTool(guard)
+
+	preview, err := tool.GeneratePreview(context.Background(), []byte(`<arguments>
+	<path>`+filepath.Join(schemasDir, "user.json")+`</path>
+	<content>{}</content>
+</arguments>`))
+	if err != nil {
+		t.Fatalf("GeneratePreview failed: %v", err)
+	}
+	outside, _ := preview.Metadata["outside_workspace"].(string)
+	if !strings.Contains(outside, "allowed directory, read-write") {
+		t.Errorf("Expected the preview to flag the allowed directory, got %q", outside)
+	}
+
+	preview, err = tool.GeneratePreview(context.Background(), []byte(`<arguments>
+	<path>main.go</path>
+	<content>package main</content>
+</arguments>`))
+	if err != nil {
+		t.Fatalf("GeneratePreview failed: %v", err)
+	}
+	if _, ok := preview.Metadata["outside_workspace"]; ok {
+		t.Errorf("Expected no outside_workspace note for a workspace file, got %v", preview.Metadata["outside_workspace"])
+	}
+}
@@ -33,7 +33,7 @@ func (t *CreateCustomToolTool) Name() string {
 
 // Description returns the tool description.
 func (t *CreateCustomToolTool) Description() string {
-	return "Create a new custom tool with Go boilerplate code in ~/.forge/tools/. Generates tool.yaml metadata and a Go source file template that the agent can then edit and compile."
+	return "Create a new custom tool in ~/.forge/tools/. Generates tool.yaml metadata and a Go, Python or shell source template that the agent can then edit (and, for Go, compile). Python and shell tools need no Go toolchain."
 }
 
 // Schema returns the JSON schema for the tool's input parameters.
@@ -48,6 +48,11 @@ func (t *CreateCustomToolTool) Schema() map[string]any {
 				"type":        "string",
 				"description": "Tool description (what the tool does)",
 			},
+			"language": map[string]any{
+				"type":        "string",
+				"enum":        []string{LanguageGo, LanguagePython, LanguageShell},
+				"description": "Language to write the tool in (default: go). Python and shell tools run with an interpreter and need no compilation",
+			},
 		},
 		[]string{"name", "description"},
 	)
@@ -59,6 +64,7 @@ func (t *CreateCustomToolTool) Execute(ctx context.Context, argsXML []byte) (str
 		XMLName     xml.Name `xml:"arguments"`
 		Name        string   `xml:"name"`
 		Description string   `xml:"description"`
+		Language    string   `xml:"language"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
@@ -73,11 +79,17 @@ func (t *CreateCustomToolTool) Execute(ctx context.Context, argsXML []byte) (str
 		return "", nil, fmt.Errorf("missing required parameter: description")
 	}
 
+	language := input.Language
+	if language == "" {
+		language = LanguageGo
+	}
+
 	// Create scaffold options with default version
 	opts := ScaffoldOptions{
 		Name:        input.Name,
 		Description: input.Description,
-		Version:     "1.0.0",    // Always use 1.0.0 as default
+		Version:     "1.0.0", // Always use 1.0.0 as default
+		Language:    language,
 		ToolsDir:    t.toolsDir, // Use override if set (for testing)
 	}
 
@@ -100,12 +112,16 @@ func (t *CreateCustomToolTool) Execute(ctx context.Context, argsXML []byte) (str
 	}
 
 	message := buildToolCreationGuide(input.Name, toolDir)
+	if language != LanguageGo {
+		message = buildScriptToolGuide(input.Name, toolDir, language)
+	}
 
 	metadata := map[string]any{
 		"tool_name":   input.Name,
 		"tool_dir":    toolDir,
 		"version":     "1.0.0",
-		"source_file": input.Name + ".go",
+		"language":    language,
+		"source_file": SourceFileName(input.Name, language),
 	}
 
 	return message, metadata, nil
@@ -122,6 +138,7 @@ func (t *CreateCustomToolTool) GeneratePreview(ctx context.Context, argsXML []by
 		XMLName     xml.Name `xml:"arguments"`
 		Name        string   `xml:"name"`
 		Description string   `xml:"description"`
+		Language    string   `xml:"language"`
 	}
 
 	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
@@ -138,11 +155,17 @@ func (t *CreateCustomToolTool) GeneratePreview(ctx context.Context, argsXML []by
 		}
 	}
 
+	language := input.Language
+	if language == "" {
+		language = LanguageGo
+	}
+	sourceFile := SourceFileName(input.Name, language)
+
 	content := fmt.Sprintf(`Files to be created:
   - %s/%s/tool.yaml (metadata)
-  - %s/%s/%s.go (source template)`,
+  - %s/%s/%s (%s source template)`,
 		toolDir, input.Name,
-		toolDir, input.Name, input.Name)
+		toolDir, input.Name, sourceFile, language)
 
 	return &tools.ToolPreview{
 		Type:        tools.PreviewTypeFileWrite,
@@ -153,7 +176,8 @@ func (t *CreateCustomToolTool) GeneratePreview(ctx context.Context, argsXML []by
 			"tool_name":   input.Name,
 			"tool_dir":    filepath.Join(toolDir, input.Name),
 			"version":     "1.0.0",
-			"source_file": input.Name + ".go",
+			"language":    language,
+			"source_file": sourceFile,
 		},
 	}, nil
 }
@@ -222,3 +246,64 @@ Test it by calling it directly.
 		toolDir, toolName, toolName,
 		toolName, toolName)
 }
+
+// buildScriptToolGuide returns the workflow guide for a python or shell tool,
+// which runs from its script without a build step.
+func buildScriptToolGuide(toolName, toolDir, language string) string {
+	sourceFile := SourceFileName(toolName, language)
+
+	parsing := `Loop over "$@" and match --name=value with a case statement
+  --count=*) count="${arg#*=}" ;;`
+	dependencies := `Shell tools can only use programs installed on the machine. Check for
+them with command -v and report a clear error when one is missing.`
+	if language == LanguagePython {
+		parsing = `Add an argparse argument per parameter
+  parser.add_argument("--count", type=int, default=10, help="description")`
+		dependencies = fmt.Sprintf(`Use the standard library where you can. For third-party packages, list
+pip requirements under dependencies in tool.yaml:
+  dependencies:
+    - requests==2.32.3
+They are installed into a virtualenv at %s/%s the first time the
+tool runs, and reinstalled whenever the list changes.`, toolDir, venvDirName)
+	}
+
+	return fmt.Sprintf(`Custom tool '%[1]s' created successfully at %[2]s
+
+## Tool Creation Workflow
+
+### 1. Implement the Tool Logic
+Edit %[2]s/%[3]s to add your implementation:
+- Parse the parameters run_custom_tool passes as --name=value flags
+- Read environment variables as needed
+- Write results to stdout, errors to stderr
+- Use appropriate exit codes (0 for success, non-zero for errors)
+
+### 2. Update Tool Metadata
+Edit %[2]s/tool.yaml to match your implementation:
+- Update parameters to reflect actual CLI flags or inputs
+- Add descriptions for each parameter
+- Set interpreter if the tool needs a specific one (default: %[4]s)
+- Document any security considerations
+
+### 3. Verify Auto-Discovery
+There is nothing to compile: the entrypoint is %[3]s, run by the interpreter.
+The tool is available on the next turn once its interpreter is installed.
+Test it by calling it directly.
+
+## Input Handling
+
+%[5]s
+
+## Dependencies
+
+%[6]s
+
+## Best Practices
+
+- Validate inputs to prevent injection attacks
+- Use clear, descriptive parameter names
+- Provide helpful error messages
+- Document security considerations in tool.yaml
+- Keep tools focused on a single responsibility`,
+		toolName, toolDir, sourceFile, (&ToolMetadata{Language: language}).GetInterpreter(), parsing, dependencies)
+}
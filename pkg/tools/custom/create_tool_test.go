@@ -78,6 +78,40 @@ func TestCreateCustomToolTool_Execute(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "creates python tool",
+			argsXML: `<arguments>
+				<name>py_tool</name>
+				<description>A python tool</description>
+				<language>python</language>
+			</arguments>`,
+			wantErr: false,
+			validate: func(t *testing.T, toolsDir string) {
+				toolDir := filepath.Join(toolsDir, "py_tool")
+				if _, err := os.Stat(filepath.Join(toolDir, "py_tool.py")); err != nil {
+					t.Errorf("Python source file not created: %v", err)
+				}
+
+				// The script itself is the entrypoint; there is nothing to compile
+				metadata, err := LoadMetadata(filepath.Join(toolDir, "tool.yaml"))
+				if err != nil {
+					t.Fatalf("failed to load metadata: %v", err)
+				}
+				if metadata.Language != LanguagePython || metadata.Entrypoint != "py_tool.py" {
+					t.Errorf("expected a python tool running py_tool.py, got %q running %q", metadata.Language, metadata.Entrypoint)
+				}
+			},
+		},
+		{
+			name: "fails with unknown language",
+			argsXML: `<arguments>
+				<name>rb_tool</name>
+				<description>A ruby tool</description>
+				<language>ruby</language>
+			</arguments>`,
+			wantErr:     true,
+			errContains: "language must be go, python, or shell",
+		},
 		{
 			name: "fails with missing name",
 			argsXML: `<arguments>
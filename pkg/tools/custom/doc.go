@@ -1,18 +1,22 @@
 // Package custom provides the custom tools system, enabling agents to create,
-// manage, and execute persistent Go-based tools that extend Forge capabilities.
+// manage, and execute persistent tools that extend Forge capabilities.
 //
-// Custom tools are standalone executable programs with YAML metadata files that
-// define their interface. They live in ~/.forge/tools/ and persist across sessions.
+// Custom tools are standalone programs with YAML metadata files that define
+// their interface. They live in ~/.forge/tools/ and persist across sessions.
+// A tool is written in Go and compiled to a binary, or is a Python or shell
+// script run by the interpreter its metadata declares, so writing one does
+// not require a Go toolchain.
 //
 // Architecture:
-//   - Registry: Scans ~/.forge/tools/ and loads tool metadata on each agent turn
-//   - Scaffolder: Generates boilerplate Go code and YAML templates
+//   - Registry: Scans ~/.forge/tools/ and loads tool metadata on each agent turn,
+//     and sets up a virtualenv for Python tools that declare dependencies
+//   - Scaffolder: Generates boilerplate Go, Python or shell code and YAML templates
 //   - Executor: Wraps execute_command to run tools with argument conversion
 //
 // Example workflow:
 //  1. Agent calls create_custom_tool to scaffold a new tool
-//  2. Agent edits the generated .go file to implement logic
-//  3. Agent compiles the tool with go build
+//  2. Agent edits the generated source file to implement logic
+//  3. For a Go tool, agent compiles the tool with go build
 //  4. Tool becomes available immediately via run_custom_tool
 //
 // Security:
@@ -4,18 +4,35 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Languages a custom tool can be written in
+const (
+	LanguageGo     = "go"     // Compiled to a binary that runs directly
+	LanguagePython = "python" // Script run by a Python interpreter
+	LanguageShell  = "shell"  // Script run by a shell
+)
+
+// Interpreters used for script tools that do not declare one
+const (
+	defaultPythonInterpreter = "python3"
+	defaultShellInterpreter  = "bash"
+)
+
 // ToolMetadata represents the YAML metadata for a custom tool
 type ToolMetadata struct {
-	Name        string      `yaml:"name"`        // Tool identifier (matches directory name)
-	Description string      `yaml:"description"` // What the tool does
-	Version     string      `yaml:"version"`     // Semantic version (e.g., "1.0.0")
-	Entrypoint  string      `yaml:"entrypoint"`  // Compiled binary name (not .go file)
-	Usage       string      `yaml:"usage"`       // Multi-line usage instructions for agent
-	Parameters  []Parameter `yaml:"parameters"`  // List of parameters
+	Name         string      `yaml:"name"`                   // Tool identifier (matches directory name)
+	Description  string      `yaml:"description"`            // What the tool does
+	Version      string      `yaml:"version"`                // Semantic version (e.g., "1.0.0")
+	Language     string      `yaml:"language,omitempty"`     // go (default), python, or shell
+	Interpreter  string      `yaml:"interpreter,omitempty"`  // Runs a python or shell entrypoint (default: python3 or bash)
+	Dependencies []string    `yaml:"dependencies,omitempty"` // pip requirements installed into the tool's virtualenv (python only)
+	Entrypoint   string      `yaml:"entrypoint"`             // Compiled binary name for Go tools, script name otherwise
+	Usage        string      `yaml:"usage"`                  // Multi-line usage instructions for agent
+	Parameters   []Parameter `yaml:"parameters"`             // List of parameters
 }
 
 // GetName returns the tool name (implements prompts.ToolMetadata interface)
@@ -28,6 +45,30 @@ func (m *ToolMetadata) GetDescription() string {
 	return m.Description
 }
 
+// GetLanguage returns the language the tool is written in, Go unless the
+// metadata says otherwise.
+func (m *ToolMetadata) GetLanguage() string {
+	if m.Language == "" {
+		return LanguageGo
+	}
+	return m.Language
+}
+
+// GetInterpreter returns the program that runs the tool's entrypoint, or ""
+// for a Go tool, whose entrypoint runs directly.
+func (m *ToolMetadata) GetInterpreter() string {
+	switch {
+	case m.Interpreter != "":
+		return m.Interpreter
+	case m.GetLanguage() == LanguagePython:
+		return defaultPythonInterpreter
+	case m.GetLanguage() == LanguageShell:
+		return defaultShellInterpreter
+	default:
+		return ""
+	}
+}
+
 // Parameter represents a tool parameter definition
 type Parameter struct {
 	Name        string `yaml:"name"`        // Parameter identifier
@@ -50,6 +91,9 @@ func (m *ToolMetadata) Validate() error {
 	if m.Entrypoint == "" {
 		return fmt.Errorf("tool entrypoint cannot be empty")
 	}
+	if err := m.validateRuntime(); err != nil {
+		return err
+	}
 
 	// Validate parameter types
 	for i, param := range m.Parameters {
@@ -69,6 +113,37 @@ func (m *ToolMetadata) Validate() error {
 	return nil
 }
 
+// validateRuntime checks the language, interpreter and dependencies.
+func (m *ToolMetadata) validateRuntime() error {
+	switch m.GetLanguage() {
+	case LanguageGo:
+		if m.Interpreter != "" {
+			return fmt.Errorf("interpreter is only used by python and shell tools")
+		}
+	case LanguagePython, LanguageShell:
+	default:
+		return fmt.Errorf("language must be go, python, or shell, got %q", m.Language)
+	}
+
+	// The interpreter is run directly, never through a shell
+	if strings.ContainsAny(m.Interpreter, " \t\n") {
+		return fmt.Errorf("interpreter must be a program name or path without arguments")
+	}
+
+	if len(m.Dependencies) > 0 && m.GetLanguage() != LanguagePython {
+		return fmt.Errorf("dependencies are only supported for python tools")
+	}
+	for _, dependency := range m.Dependencies {
+		// Each dependency is passed to pip as one argument, so an option
+		// such as --index-url could redirect the install
+		if dependency == "" || strings.HasPrefix(dependency, "-") || strings.ContainsAny(dependency, " \t\n") {
+			return fmt.Errorf("dependency %q must be a pip requirement such as requests==2.32.3", dependency)
+		}
+	}
+
+	return nil
+}
+
 // LoadMetadata reads and parses a tool.yaml file
 func LoadMetadata(path string) (*ToolMetadata, error) {
 	data, err := os.ReadFile(path)
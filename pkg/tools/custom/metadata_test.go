@@ -82,6 +82,66 @@ func TestToolMetadata_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "python tool with dependencies",
+			meta: ToolMetadata{
+				Name:         "test-tool",
+				Description:  "A test tool",
+				Version:      "1.0.0",
+				Language:     LanguagePython,
+				Interpreter:  "python3.12",
+				Dependencies: []string{"requests==2.32.3"},
+				Entrypoint:   "test-tool.py",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown language",
+			meta: ToolMetadata{
+				Name:        "test-tool",
+				Description: "A test tool",
+				Version:     "1.0.0",
+				Language:    "ruby",
+				Entrypoint:  "test-tool.rb",
+			},
+			wantErr: true,
+		},
+		{
+			name: "interpreter with arguments",
+			meta: ToolMetadata{
+				Name:        "test-tool",
+				Description: "A test tool",
+				Version:     "1.0.0",
+				Language:    LanguageShell,
+				Interpreter: "bash -c",
+				Entrypoint:  "test-tool.sh",
+			},
+			wantErr: true,
+		},
+		{
+			name: "dependencies on a shell tool",
+			meta: ToolMetadata{
+				Name:         "test-tool",
+				Description:  "A test tool",
+				Version:      "1.0.0",
+				Language:     LanguageShell,
+				Dependencies: []string{"jq"},
+				Entrypoint:   "test-tool.sh",
+			},
+			wantErr: true,
+		},
+		{
+			name: "dependency that is a pip option",
+			meta: ToolMetadata{
+				Name:         "test-tool",
+				Description:  "A test tool",
+				Version:      "1.0.0",
+				Language:     LanguagePython,
+				Dependencies: []string{"--index-url=https://example.com/simple"},
+				Entrypoint:   "test-tool.py",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
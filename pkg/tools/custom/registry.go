@@ -3,6 +3,7 @@ package custom
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"sync"
@@ -10,9 +11,10 @@ import (
 
 // Registry manages the discovery and loading of custom tools
 type Registry struct {
-	mu       sync.RWMutex
-	tools    map[string]*ToolMetadata // tool name -> metadata
-	toolsDir string                   // Override for testing, empty means use default
+	mu          sync.RWMutex
+	tools       map[string]*ToolMetadata // tool name -> metadata
+	toolsDir    string                   // Override for testing, empty means use default
+	bootstrapMu sync.Mutex               // Serializes virtualenv setup
 }
 
 // NewRegistry creates a new custom tool registry
@@ -93,8 +95,13 @@ func (r *Registry) Refresh() error {
 			continue
 		}
 
-		// Check if file is executable (skip on Windows where permission bits don't work)
-		if runtime.GOOS != "windows" {
+		if interpreter := metadata.GetInterpreter(); interpreter != "" {
+			// Scripts are run by their interpreter, which must be installed
+			if _, err := exec.LookPath(interpreter); err != nil {
+				continue
+			}
+		} else if runtime.GOOS != "windows" {
+			// Check if file is executable (skip on Windows where permission bits don't work)
 			if info.Mode()&0111 == 0 {
 				// Not executable, skip this tool
 				continue
@@ -148,7 +155,8 @@ func (r *Registry) Has(toolName string) bool {
 	return ok
 }
 
-// GetBinaryPath returns the absolute path to a tool's binary
+// GetBinaryPath returns the absolute path to a tool's binary, or to its
+// script for python and shell tools
 func (r *Registry) GetBinaryPath(toolName string) (string, error) {
 	metadata, ok := r.Get(toolName)
 	if !ok {
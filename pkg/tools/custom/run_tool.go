@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/xml"
 	"fmt"
-	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -40,7 +40,7 @@ func (t *RunCustomToolTool) Name() string {
 }
 
 func (t *RunCustomToolTool) Description() string {
-	return "Execute a custom tool from ~/.forge/tools/. Discovers available tools, converts arguments to CLI flags, and executes the tool binary."
+	return "Execute a custom tool from ~/.forge/tools/. Discovers available tools, converts arguments to CLI flags, and executes the tool binary or script."
 }
 
 func (t *RunCustomToolTool) Schema() map[string]any {
@@ -112,16 +112,25 @@ func (t *RunCustomToolTool) Execute(ctx context.Context, argsXML []byte) (string
 		flags = append(flags, fmt.Sprintf("--%s=%v", key, value))
 	}
 
-	// Execute tool with timeout
-	execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
-	defer cancel()
-
 	// Validate binary path is within workspace before execution
 	if err = t.guard.ValidatePath(binaryPath); err != nil {
 		return "", nil, fmt.Errorf("tool binary path validation failed: %w", err)
 	}
 
-	cmd := exec.CommandContext(execCtx, binaryPath, flags...)
+	// Set up a python tool's dependencies outside the timeout, which is
+	// meant for the tool itself
+	if err = t.registry.Prepare(ctx, input.ToolName); err != nil {
+		return "", nil, err
+	}
+
+	// Execute tool with timeout
+	execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	cmd, err := t.registry.Command(execCtx, input.ToolName, flags)
+	if err != nil {
+		return "", nil, err
+	}
 
 	// Set working directory to workspace (so tools can access workspace files)
 	workspaceDir := t.guard.WorkspaceDir()
@@ -168,7 +177,17 @@ func (t *RunCustomToolTool) GeneratePreview(ctx context.Context, argsXML []byte)
 
 	// Build preview content
 	var content strings.Builder
-	fmt.Fprintf(&content, "Binary: %s\n", binaryPath)
+	metadata, _ := t.registry.Get(input.ToolName)
+	if interpreter := metadata.GetInterpreter(); interpreter != "" {
+		fmt.Fprintf(&content, "Script: %s\n", binaryPath)
+		fmt.Fprintf(&content, "Interpreter: %s\n", interpreter)
+		if len(metadata.Dependencies) > 0 {
+			fmt.Fprintf(&content, "Dependencies: %s (installed into %s/%s on first run)\n",
+				strings.Join(metadata.Dependencies, ", "), filepath.Dir(binaryPath), venvDirName)
+		}
+	} else {
+		fmt.Fprintf(&content, "Binary: %s\n", binaryPath)
+	}
 	if len(args) > 0 {
 		content.WriteString("Arguments:\n")
 		for key, value := range args {
@@ -185,7 +204,7 @@ func (t *RunCustomToolTool) GeneratePreview(ctx context.Context, argsXML []byte)
 	return &tools.ToolPreview{
 		Type:        tools.PreviewTypeCommand,
 		Title:       fmt.Sprintf("Execute custom tool: %s", input.ToolName),
-		Description: fmt.Sprintf("Run custom tool binary from ~/.forge/tools/%s/", input.ToolName),
+		Description: fmt.Sprintf("Run custom tool from ~/.forge/tools/%s/", input.ToolName),
 		Content:     content.String(),
 		Metadata: map[string]any{
 			"tool_name":   input.ToolName,
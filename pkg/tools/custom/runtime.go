@@ -0,0 +1,104 @@
+package custom
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// venvDirName is the virtualenv created in a python tool's directory for
+	// its dependencies
+	venvDirName = ".venv"
+
+	// dependencyStampName records, inside the virtualenv, the dependencies
+	// last installed into it
+	dependencyStampName = "forge-dependencies"
+)
+
+// Prepare gets a tool ready to run. A python tool with dependencies gets a
+// virtualenv in its directory, created on first use and reinstalled
+// whenever the dependencies in tool.yaml change. Other tools need nothing.
+func (r *Registry) Prepare(ctx context.Context, toolName string) error {
+	metadata, ok := r.Get(toolName)
+	if !ok {
+		return fmt.Errorf("tool %s not found", toolName)
+	}
+	if metadata.GetLanguage() != LanguagePython || len(metadata.Dependencies) == 0 {
+		return nil
+	}
+
+	toolsDir, err := r.getToolsDir()
+	if err != nil {
+		return err
+	}
+	return r.ensureVenv(ctx, filepath.Join(toolsDir, toolName), metadata)
+}
+
+// Command returns the command that runs a tool with args: the binary itself
+// for Go tools, the interpreter with the script for python and shell tools.
+// Call Prepare first so a python tool's virtualenv exists.
+func (r *Registry) Command(ctx context.Context, toolName string, args []string) (*exec.Cmd, error) {
+	metadata, ok := r.Get(toolName)
+	if !ok {
+		return nil, fmt.Errorf("tool %s not found", toolName)
+	}
+	entrypoint, err := r.GetBinaryPath(toolName)
+	if err != nil {
+		return nil, err
+	}
+
+	interpreter := metadata.GetInterpreter()
+	if interpreter == "" {
+		return exec.CommandContext(ctx, entrypoint, args...), nil
+	}
+	if metadata.GetLanguage() == LanguagePython && len(metadata.Dependencies) > 0 {
+		interpreter = venvPython(filepath.Join(filepath.Dir(entrypoint), venvDirName))
+	}
+	return exec.CommandContext(ctx, interpreter, append([]string{entrypoint}, args...)...), nil
+}
+
+// ensureVenv creates the tool's virtualenv if needed and installs its
+// dependencies unless they are already installed.
+func (r *Registry) ensureVenv(ctx context.Context, toolDir string, metadata *ToolMetadata) error {
+	r.bootstrapMu.Lock()
+	defer r.bootstrapMu.Unlock()
+
+	venv := filepath.Join(toolDir, venvDirName)
+	stamp := filepath.Join(venv, dependencyStampName)
+	want := strings.Join(metadata.Dependencies, "\n") + "\n"
+	if data, err := os.ReadFile(stamp); err == nil && string(data) == want {
+		return nil
+	}
+
+	python := venvPython(venv)
+	if _, err := os.Stat(python); err != nil {
+		output, err := exec.CommandContext(ctx, metadata.GetInterpreter(), "-m", "venv", venv).CombinedOutput() //nolint:gosec // the interpreter is validated to take no arguments
+		if err != nil {
+			return fmt.Errorf("failed to create virtualenv for %s: %w\nOutput: %s", metadata.Name, err, string(output))
+		}
+	}
+
+	args := append([]string{"-m", "pip", "install", "--disable-pip-version-check", "--quiet"}, metadata.Dependencies...)
+	output, err := exec.CommandContext(ctx, python, args...).CombinedOutput() //nolint:gosec // dependencies are validated not to be pip options
+	if err != nil {
+		return fmt.Errorf("failed to install dependencies for %s: %w\nOutput: %s", metadata.Name, err, string(output))
+	}
+
+	if err := os.WriteFile(stamp, []byte(want), 0600); err != nil {
+		return fmt.Errorf("failed to record installed dependencies: %w", err)
+	}
+	return nil
+}
+
+// venvPython returns the path of a virtualenv's python executable.
+func venvPython(venv string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(venv, "Scripts", "python.exe")
+	}
+	return filepath.Join(venv, "bin", "python")
+}
@@ -0,0 +1,103 @@
+package custom
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffold_ScriptToolsRun(t *testing.T) {
+	for _, language := range []string{LanguagePython, LanguageShell} {
+		t.Run(language, func(t *testing.T) {
+			interpreter := (&ToolMetadata{Language: language}).GetInterpreter()
+			if _, err := exec.LookPath(interpreter); err != nil {
+				t.Skipf("%s is not installed", interpreter)
+			}
+
+			tmpDir := t.TempDir()
+			if err := Scaffold(ScaffoldOptions{
+				Name:        "script-tool",
+				Description: "A script tool",
+				Language:    language,
+				ToolsDir:    tmpDir,
+			}); err != nil {
+				t.Fatalf("Scaffold() error = %v", err)
+			}
+
+			// Scripts are discovered without a build step or executable bit
+			registry := NewRegistryWithDir(tmpDir)
+			if err := registry.Refresh(); err != nil {
+				t.Fatalf("Refresh() error = %v", err)
+			}
+			if !registry.Has("script-tool") {
+				t.Fatal("expected the scaffolded script tool to be registered")
+			}
+
+			if err := registry.Prepare(context.Background(), "script-tool"); err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			cmd, err := registry.Command(context.Background(), "script-tool", nil)
+			if err != nil {
+				t.Fatalf("Command() error = %v", err)
+			}
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("running the tool failed: %v\n%s", err, output)
+			}
+			if !strings.Contains(string(output), "TODO: Implement tool logic") {
+				t.Errorf("unexpected output %q", output)
+			}
+		})
+	}
+}
+
+func TestRegistry_PrepareReusesVirtualenv(t *testing.T) {
+	tmpDir := t.TempDir()
+	toolDir := filepath.Join(tmpDir, "py-tool")
+	metadata := &ToolMetadata{
+		Name:         "py-tool",
+		Description:  "A python tool",
+		Version:      "1.0.0",
+		Language:     LanguagePython,
+		Interpreter:  "sh", // Present everywhere; never run when the virtualenv is current
+		Dependencies: []string{"requests==2.32.3"},
+		Entrypoint:   "py-tool.py",
+	}
+	if err := SaveMetadata(filepath.Join(toolDir, "tool.yaml"), metadata); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(toolDir, "py-tool.py"), []byte("print('hi')\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A virtualenv that already has the declared dependencies installed
+	venv := filepath.Join(toolDir, venvDirName)
+	if err := os.MkdirAll(filepath.Dir(venvPython(venv)), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(venv, dependencyStampName), []byte("requests==2.32.3\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewRegistryWithDir(tmpDir)
+	if err := registry.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Prepare(context.Background(), "py-tool"); err != nil {
+		t.Fatalf("expected an up-to-date virtualenv to be reused, got %v", err)
+	}
+
+	cmd, err := registry.Command(context.Background(), "py-tool", []string{"--count=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.Path != venvPython(venv) {
+		t.Errorf("expected the virtualenv's python, got %s", cmd.Path)
+	}
+	if want := []string{venvPython(venv), filepath.Join(toolDir, "py-tool.py"), "--count=2"}; strings.Join(cmd.Args, " ") != strings.Join(want, " ") {
+		t.Errorf("Args = %v, want %v", cmd.Args, want)
+	}
+}
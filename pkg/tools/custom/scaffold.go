@@ -13,9 +13,23 @@ type ScaffoldOptions struct {
 	Name        string // Tool name (will be directory and binary name)
 	Description string // Tool description
 	Version     string // Tool version (default: "1.0.0")
+	Language    string // go (default), python, or shell
 	ToolsDir    string // Optional: Custom tools directory (for testing)
 }
 
+// SourceFileName returns the name of the source file scaffolded for a tool
+// written in language.
+func SourceFileName(toolName, language string) string {
+	switch language {
+	case LanguagePython:
+		return toolName + ".py"
+	case LanguageShell:
+		return toolName + ".sh"
+	default:
+		return toolName + ".go"
+	}
+}
+
 // Scaffold creates a new custom tool directory structure with boilerplate code
 func Scaffold(opts ScaffoldOptions) error {
 	// Validate options
@@ -34,6 +48,13 @@ func Scaffold(opts ScaffoldOptions) error {
 	if opts.Version == "" {
 		opts.Version = "1.0.0"
 	}
+	if opts.Language == "" {
+		opts.Language = LanguageGo
+	}
+	boilerplate, ok := boilerplateTemplates[opts.Language]
+	if !ok {
+		return fmt.Errorf("language must be go, python, or shell, got %q", opts.Language)
+	}
 
 	// Get tool directory
 	var toolDir string
@@ -57,12 +78,20 @@ func Scaffold(opts ScaffoldOptions) error {
 		return fmt.Errorf("failed to create tool directory: %w", err)
 	}
 
+	// Go tools run their compiled binary, script tools the script itself
+	sourceFile := SourceFileName(opts.Name, opts.Language)
+	entrypoint := sourceFile
+	if opts.Language == LanguageGo {
+		entrypoint = opts.Name
+	}
+
 	// Create tool.yaml metadata
 	metadata := &ToolMetadata{
 		Name:        opts.Name,
 		Description: opts.Description,
 		Version:     opts.Version,
-		Entrypoint:  opts.Name, // Binary name; Go source is opts.Name + ".go"
+		Language:    opts.Language,
+		Entrypoint:  entrypoint,
 		Usage:       fmt.Sprintf("Usage instructions for %s", opts.Name),
 		Parameters:  []Parameter{}, // Empty initially, agent will add parameters
 	}
@@ -72,10 +101,9 @@ func Scaffold(opts ScaffoldOptions) error {
 		return fmt.Errorf("failed to save metadata: %w", err)
 	}
 
-	// Create Go source file with boilerplate
-	goFilePath := filepath.Join(toolDir, opts.Name+".go")
-	if err := writeGoBoilerplate(goFilePath, opts.Name); err != nil {
-		return fmt.Errorf("failed to write Go boilerplate: %w", err)
+	// Create source file with boilerplate
+	if err := writeBoilerplate(filepath.Join(toolDir, sourceFile), boilerplate, opts.Name); err != nil {
+		return fmt.Errorf("failed to write %s boilerplate: %w", opts.Language, err)
 	}
 
 	return nil
@@ -107,9 +135,16 @@ func validateToolName(name string) error {
 	return nil
 }
 
-// writeGoBoilerplate writes the Go boilerplate template to a file
-func writeGoBoilerplate(path, toolName string) error {
-	tmpl, err := template.New("boilerplate").Parse(goBoilerplateTemplate)
+// boilerplateTemplates holds the source template for each language
+var boilerplateTemplates = map[string]string{
+	LanguageGo:     goBoilerplateTemplate,
+	LanguagePython: pythonBoilerplateTemplate,
+	LanguageShell:  shellBoilerplateTemplate,
+}
+
+// writeBoilerplate writes a boilerplate template to a file
+func writeBoilerplate(path, boilerplate, toolName string) error {
+	tmpl, err := template.New("boilerplate").Parse(boilerplate)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -180,3 +215,98 @@ func writeError(err error) {
 	os.Exit(1)
 }
 `
+
+// pythonBoilerplateTemplate is the template for new custom tool Python scripts
+const pythonBoilerplateTemplate = `#!/usr/bin/env python3
+"""{{.ToolName}} custom tool for Forge."""
+
+import argparse
+import os
+import sys
+
+
+def main():
+    parser = argparse.ArgumentParser(prog="{{.ToolName}}")
+
+    # Define arguments for your tool parameters
+    # run_custom_tool passes each parameter as --name=value
+    # TODO: Add your parameters here
+    # Example:
+    # parser.add_argument("--example", default="", help="Example parameter")
+    # parser.add_argument("--count", type=int, default=10, help="Number of items")
+
+    args = parser.parse_args()
+
+    # Access environment variables if needed (optional - remove if not used)
+    # Example:
+    # api_key = os.environ.get("MY_API_KEY")
+    # if not api_key:
+    #     write_error("MY_API_KEY environment variable not set")
+
+    # TODO: Implement your tool logic here
+    # Use args to access parameters
+    # Example: if not args.example: ...
+    # Third-party packages go under dependencies in tool.yaml
+
+    # Output results to stdout
+    # The agent will see this output
+    result = "TODO: Implement tool logic"
+    write_output(result)
+
+
+def write_output(result):
+    """Write the tool result to stdout. This is what the agent will see."""
+    print(result)
+
+
+def write_error(message):
+    """Write an error message to stderr and exit with code 1."""
+    print(f"Error: {message}", file=sys.stderr)
+    sys.exit(1)
+
+
+if __name__ == "__main__":
+    main()
+`
+
+// shellBoilerplateTemplate is the template for new custom tool shell scripts
+const shellBoilerplateTemplate = `#!/usr/bin/env bash
+# {{.ToolName}} custom tool for Forge.
+set -euo pipefail
+
+# write_output writes the tool result to stdout
+# This is what the agent will see as the tool execution result
+write_output() {
+  printf '%s\n' "$1"
+}
+
+# write_error writes an error message to stderr and exits with code 1
+write_error() {
+  printf 'Error: %s\n' "$1" >&2
+  exit 1
+}
+
+# Define variables for your tool parameters
+# run_custom_tool passes each parameter as --name=value
+# TODO: Add your parameters here
+# Example:
+# example=""
+for arg in "$@"; do
+  case "$arg" in
+    # --example=*) example="${arg#*=}" ;;
+    *) write_error "unknown argument: $arg" ;;
+  esac
+done
+
+# Access environment variables if needed (optional - remove if not used)
+# Example:
+# if [ -z "${MY_API_KEY:-}" ]; then
+#   write_error "MY_API_KEY environment variable not set"
+# fi
+
+# TODO: Implement your tool logic here
+
+# Output results to stdout
+result="TODO: Implement tool logic"
+write_output "$result"
+`
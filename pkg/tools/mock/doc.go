@@ -0,0 +1,20 @@
+// Package mock provides simulated versions of Forge's side-effecting tools.
+//
+// In mock mode (the -mock-tools flag), write_file, apply_diff, delete_file,
+// move_file, create_directory, execute_command and run_tests never touch the
+// real workspace. File writes, deletions and moves land in an in-memory
+// Overlay layered on top of the workspace, and commands
+// and test runs are recorded rather than executed. read_file consults the overlay first so the
+// agent observes its own simulated edits, which keeps multi-step flows
+// realistic. rename_symbol reports the files a rename would change but does
+// not apply it.
+// Simulated writes obey the guard's path rules and write scope like real ones,
+// so a write the real tool would refuse is refused here too.
+//
+// Tools that only inspect the workspace (list_files, search_files,
+// find_files) continue to read the real filesystem and will not see files
+// that exist only in the overlay.
+//
+// Tools whose side effects cannot be simulated, such as the terminal, web,
+// browser and custom tools, are not registered in mock mode at all.
+package mock
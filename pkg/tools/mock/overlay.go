@@ -0,0 +1,204 @@
+package mock
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Change describes a file that was written to or deleted from the overlay.
+type Change struct {
+	// Path is the file path relative to the overlay root.
+	Path string
+	// Created is true when the file did not exist on disk before the session.
+	Created bool
+	// Original is the on-disk content captured before the first simulated write.
+	Original string
+	// Content is the current simulated content.
+	Content string
+	// Deleted is true when the file was deleted; Content is then empty.
+	Deleted bool
+}
+
+// overlayFile tracks a single file shadowed by the overlay.
+type overlayFile struct {
+	original []byte
+	existed  bool
+	content  []byte
+	deleted  bool
+}
+
+// Overlay is an in-memory filesystem layered over a workspace directory.
+// Reads fall through to disk for files that have not been written.
+// It is safe for concurrent use.
+type Overlay struct {
+	root string
+
+	mu       sync.RWMutex
+	files    map[string]*overlayFile
+	commands []string
+}
+
+// NewOverlay creates an empty overlay rooted at the given workspace directory.
+func NewOverlay(root string) *Overlay {
+	return &Overlay{
+		root:  root,
+		files: make(map[string]*overlayFile),
+	}
+}
+
+// ReadFile returns the simulated content of absPath if it has been written,
+// otherwise the content on disk.
+func (o *Overlay) ReadFile(absPath string) ([]byte, error) {
+	o.mu.RLock()
+	f, ok := o.files[absPath]
+	o.mu.RUnlock()
+
+	if ok {
+		if f.deleted {
+			return nil, &fs.PathError{Op: "open", Path: absPath, Err: fs.ErrNotExist}
+		}
+		return append([]byte(nil), f.content...), nil
+	}
+	return os.ReadFile(absPath)
+}
+
+// Exists reports whether absPath exists in the overlay or on disk.
+func (o *Overlay) Exists(absPath string) bool {
+	o.mu.RLock()
+	f, ok := o.files[absPath]
+	o.mu.RUnlock()
+
+	if ok {
+		return !f.deleted
+	}
+	_, err := os.Stat(absPath)
+	return err == nil
+}
+
+// WriteFile records data as the new content of absPath without touching disk.
+// It returns whether the file existed (in the overlay or on disk) beforehand.
+func (o *Overlay) WriteFile(absPath string, data []byte) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	f := o.file(absPath)
+	existed := !f.deleted
+	f.content = append([]byte(nil), data...)
+	f.deleted = false
+	return existed
+}
+
+// DeleteFile records the deletion of absPath without touching disk. It
+// returns whether the file existed (in the overlay or on disk) beforehand.
+func (o *Overlay) DeleteFile(absPath string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	f := o.file(absPath)
+	existed := !f.deleted
+	f.content = nil
+	f.deleted = true
+	return existed
+}
+
+// file returns the overlay's entry for absPath, shadowing the file on disk
+// the first time it is touched. A file that is on neither starts deleted.
+// Must be called with the lock held.
+func (o *Overlay) file(absPath string) *overlayFile {
+	if f, ok := o.files[absPath]; ok {
+		return f
+	}
+
+	f := &overlayFile{deleted: true}
+	if original, err := os.ReadFile(absPath); err == nil {
+		f.original = original
+		f.content = original
+		f.existed = true
+		f.deleted = false
+	}
+	o.files[absPath] = f
+	return f
+}
+
+// FilesUnder returns the files in the directory absDir as the overlay sees
+// them: those on disk that have not been deleted, and those written to the
+// overlay, sorted.
+func (o *Overlay) FilesUnder(absDir string) []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	_ = filepath.WalkDir(absDir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			seen[p] = true
+		}
+		return nil
+	})
+	prefix := absDir + string(filepath.Separator)
+	for absPath, f := range o.files {
+		if strings.HasPrefix(absPath, prefix) {
+			seen[absPath] = !f.deleted
+		}
+	}
+
+	files := make([]string, 0, len(seen))
+	for absPath, exists := range seen {
+		if exists {
+			files = append(files, absPath)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// RecordCommand appends a command that would have been executed.
+func (o *Overlay) RecordCommand(command string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.commands = append(o.commands, command)
+}
+
+// Commands returns the recorded commands in execution order.
+func (o *Overlay) Commands() []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return append([]string(nil), o.commands...)
+}
+
+// Changes returns every file written to or deleted from the overlay, sorted
+// by path. Files whose simulated content matches the original, and files
+// created and then deleted, are omitted.
+func (o *Overlay) Changes() []Change {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	changes := make([]Change, 0, len(o.files))
+	for absPath, f := range o.files {
+		if f.existed && !f.deleted && string(f.original) == string(f.content) {
+			continue
+		}
+		if !f.existed && f.deleted {
+			continue
+		}
+		relPath, err := filepath.Rel(o.root, absPath)
+		if err != nil {
+			relPath = absPath
+		}
+		changes = append(changes, Change{
+			Path:     relPath,
+			Created:  !f.existed,
+			Original: string(f.original),
+			Content:  string(f.content),
+			Deleted:  f.deleted,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
@@ -0,0 +1,884 @@
+package mock
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/coding"
+)
+
+// mockNotice is appended to simulated results so the model knows nothing
+// reached the real workspace.
+const mockNotice = "[mock mode: no changes were made to the real workspace]"
+
+// WriteFileTool simulates write_file by recording content in an Overlay.
+type WriteFileTool struct {
+	*coding.WriteFileTool
+	guard   *workspace.Guard
+	overlay *Overlay
+}
+
+// NewWriteFileTool creates a simulated write_file tool backed by overlay.
+func NewWriteFileTool(guard *workspace.Guard, overlay *Overlay) *WriteFileTool {
+	return &WriteFileTool{
+		WriteFileTool: coding.NewWriteFileTool(guard),
+		guard:         guard,
+		overlay:       overlay,
+	}
+}
+
+// Execute records the file content in the overlay and reports the simulated change.
+func (t *WriteFileTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Path    string   `xml:"path"`
+		Content string   `xml:"content"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if input.Path == "" {
+		return "", nil, fmt.Errorf("missing required parameter: path")
+	}
+
+	absPath, relPath, err := resolve(t.guard, input.Path)
+	if err != nil {
+		return "", nil, err
+	}
+	if ruleErr := t.guard.CheckWrite(input.Path, int64(len(input.Content))); ruleErr != nil {
+		return "", nil, ruleErr
+	}
+
+	var originalContent string
+	if existing, readErr := t.overlay.ReadFile(absPath); readErr == nil {
+		originalContent = string(existing)
+	}
+
+	fileExists := t.overlay.WriteFile(absPath, []byte(input.Content))
+	lineChanges := coding.CalculateLineChanges(originalContent, input.Content)
+
+	var message string
+	if fileExists {
+		message = fmt.Sprintf("File '%s' overwritten successfully (+%d/-%d lines)",
+			relPath, lineChanges.LinesAdded, lineChanges.LinesRemoved)
+	} else {
+		message = fmt.Sprintf("File '%s' created successfully (+%d lines)",
+			relPath, lineChanges.LinesAdded)
+	}
+
+	metadata := map[string]any{
+		"file_path":     input.Path,
+		"file_exists":   fileExists,
+		"lines_added":   lineChanges.LinesAdded,
+		"lines_removed": lineChanges.LinesRemoved,
+		"size_bytes":    int64(len(input.Content)),
+		"mocked":        true,
+	}
+
+	return message + "\n" + mockNotice, metadata, nil
+}
+
+// GeneratePreview shows the change relative to the simulated file state.
+func (t *WriteFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Path    string   `xml:"path"`
+		Content string   `xml:"content"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if input.Path == "" {
+		return nil, fmt.Errorf("missing required parameter: path")
+	}
+
+	absPath, relPath, err := resolve(t.guard, input.Path)
+	if err != nil {
+		return nil, err
+	}
+	if ruleErr := t.guard.CheckWrite(input.Path, int64(len(input.Content))); ruleErr != nil {
+		return nil, ruleErr
+	}
+
+	preview := &tools.ToolPreview{
+		Type:        tools.PreviewTypeFileWrite,
+		Title:       fmt.Sprintf("Create new file %s (simulated)", relPath),
+		Description: fmt.Sprintf("This will create %s in the mock overlay", relPath),
+		Content:     input.Content,
+		Metadata: map[string]any{
+			"file_path": relPath,
+			"size":      len(input.Content),
+		},
+	}
+
+	if existing, readErr := t.overlay.ReadFile(absPath); readErr == nil {
+		preview.Type = tools.PreviewTypeDiff
+		preview.Title = fmt.Sprintf("Overwrite %s (simulated)", relPath)
+		preview.Description = fmt.Sprintf("This will overwrite %s in the mock overlay", relPath)
+		preview.Content = coding.GenerateUnifiedDiff(string(existing), input.Content, relPath)
+	}
+
+	return preview, nil
+}
+
+// ApplyDiffTool simulates apply_diff against the overlay's view of a file.
+type ApplyDiffTool struct {
+	*coding.ApplyDiffTool
+	guard   *workspace.Guard
+	overlay *Overlay
+}
+
+// NewApplyDiffTool creates a simulated apply_diff tool backed by overlay.
+func NewApplyDiffTool(guard *workspace.Guard, overlay *Overlay) *ApplyDiffTool {
+	return &ApplyDiffTool{
+		ApplyDiffTool: coding.NewApplyDiffTool(guard),
+		guard:         guard,
+		overlay:       overlay,
+	}
+}
+
+// Execute applies the edits to the simulated file content.
+func (t *ApplyDiffTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	result, err := t.apply(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if result.modified == result.original {
+		return "No changes made to file", nil, nil
+	}
+
+	t.overlay.WriteFile(result.absPath, []byte(result.modified))
+
+	metadata := map[string]any{
+		"edits_applied": result.editCount,
+		"lines_added":   result.changes.LinesAdded,
+		"lines_removed": result.changes.LinesRemoved,
+		"file_path":     result.relPath,
+		"mocked":        true,
+	}
+	coding.AddMatchMetadata(metadata, result.matches)
+
+	return fmt.Sprintf("Successfully applied %d edit(s) to %s%s\n%s", result.editCount, result.relPath, coding.DescribeMatches(result.matches), mockNotice), metadata, nil
+}
+
+// GeneratePreview shows the diff relative to the simulated file state.
+func (t *ApplyDiffTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	result, err := t.apply(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeDiff,
+		Title:       fmt.Sprintf("Apply %d edit(s) to %s (simulated)", result.editCount, result.relPath),
+		Description: fmt.Sprintf("This will modify %s in the mock overlay", result.relPath),
+		Content:     coding.GenerateUnifiedDiff(result.original, result.modified, result.relPath),
+		Metadata: map[string]any{
+			"file_path":  result.relPath,
+			"edit_count": result.editCount,
+		},
+	}, nil
+}
+
+// diffResult is the outcome of applying edits to a file's simulated content.
+type diffResult struct {
+	absPath   string
+	relPath   string
+	original  string
+	modified  string
+	changes   coding.LineChanges
+	matches   []coding.EditMatch
+	editCount int
+}
+
+// apply parses the arguments and computes the edited content without recording it.
+func (t *ApplyDiffTool) apply(argsXML []byte) (*diffResult, error) {
+	var input struct {
+		XMLName xml.Name          `xml:"arguments"`
+		Path    string            `xml:"path"`
+		Edits   []coding.DiffEdit `xml:"edits>edit"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if input.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	if len(input.Edits) == 0 {
+		return nil, fmt.Errorf("at least one edit is required")
+	}
+
+	absPath, relPath, err := resolve(t.guard, input.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := t.overlay.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	modified, changes, matches, err := coding.ApplyEdits(string(content), input.Edits)
+	if err != nil {
+		return nil, err
+	}
+	if ruleErr := t.guard.CheckWrite(input.Path, int64(len(modified))); ruleErr != nil {
+		return nil, ruleErr
+	}
+
+	return &diffResult{
+		absPath:   absPath,
+		relPath:   relPath,
+		original:  string(content),
+		modified:  modified,
+		changes:   changes,
+		matches:   matches,
+		editCount: len(input.Edits),
+	}, nil
+}
+
+// ExecuteCommandTool simulates execute_command by recording the command
+// instead of running it.
+type ExecuteCommandTool struct {
+	*coding.ExecuteCommandTool
+	guard   *workspace.Guard
+	overlay *Overlay
+}
+
+// NewExecuteCommandTool creates a simulated execute_command tool backed by overlay.
+func NewExecuteCommandTool(guard *workspace.Guard, overlay *Overlay) *ExecuteCommandTool {
+	return &ExecuteCommandTool{
+		ExecuteCommandTool: coding.NewExecuteCommandTool(guard),
+		guard:              guard,
+		overlay:            overlay,
+	}
+}
+
+// Execute records the command and returns a successful, empty result in the
+// same shape as a real execution.
+func (t *ExecuteCommandTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName    xml.Name `xml:"arguments"`
+		Command    string   `xml:"command"`
+		Timeout    float64  `xml:"timeout"`
+		WorkingDir string   `xml:"working_dir"`
+	}
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	if input.Command == "" {
+		return "", nil, fmt.Errorf("command cannot be empty")
+	}
+
+	workDir := t.guard.WorkspaceDir()
+	if input.WorkingDir != "" {
+		if validateErr := t.guard.ValidatePath(input.WorkingDir); validateErr != nil {
+			return "", nil, fmt.Errorf("invalid working directory: %w", validateErr)
+		}
+		absWorkDir, resolveErr := t.guard.ResolvePath(input.WorkingDir)
+		if resolveErr != nil {
+			return "", nil, fmt.Errorf("failed to resolve working directory: %w", resolveErr)
+		}
+		workDir = absWorkDir
+	}
+
+	t.overlay.RecordCommand(input.Command)
+
+	result := fmt.Sprintf("Command completed successfully in 0s\n\nStdout:\n%s\n\nExit code: 0",
+		"[mock mode: command was recorded but not executed]")
+
+	metadata := map[string]any{
+		"command":     input.Command,
+		"exit_code":   0,
+		"duration_ms": int64(0),
+		"working_dir": workDir,
+		"mocked":      true,
+	}
+
+	return result, metadata, nil
+}
+
+// RunTestsTool simulates run_tests by recording the test command instead of
+// running it. The real tests would run against the files on disk, not the
+// overlay, so their results would not reflect the simulated edits anyway.
+type RunTestsTool struct {
+	*coding.RunTestsTool
+	overlay *Overlay
+}
+
+// NewRunTestsTool creates a simulated run_tests tool backed by overlay.
+func NewRunTestsTool(guard *workspace.Guard, overlay *Overlay) *RunTestsTool {
+	return &RunTestsTool{
+		RunTestsTool: coding.NewRunTestsTool(guard),
+		overlay:      overlay,
+	}
+}
+
+// Execute records the test command and returns an empty, passing result in
+// the same shape as a real run.
+func (t *RunTestsTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	command, workDir, err := t.Command(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	t.overlay.RecordCommand(command)
+
+	result := fmt.Sprintf("Tests PASSED: 0 passed, 0 failed, 0 skipped in 0s\nCommand: %s (exit code 0)\n\n%s",
+		command, "[mock mode: tests were recorded but not run]")
+
+	metadata := map[string]any{
+		"command":     command,
+		"working_dir": workDir,
+		"passed":      0,
+		"failed":      0,
+		"skipped":     0,
+		"exit_code":   0,
+		"duration_ms": int64(0),
+		"mocked":      true,
+	}
+
+	return result, metadata, nil
+}
+
+// RenameSymbolTool simulates rename_symbol by reporting which files gopls
+// would change without applying the rename. gopls works on the files on disk,
+// so the rename cannot be layered onto the overlay.
+type RenameSymbolTool struct {
+	*coding.RenameSymbolTool
+}
+
+// NewRenameSymbolTool creates a simulated rename_symbol tool.
+func NewRenameSymbolTool(guard *workspace.Guard) *RenameSymbolTool {
+	return &RenameSymbolTool{
+		RenameSymbolTool: coding.NewRenameSymbolTool(guard),
+	}
+}
+
+// Execute lists the files the rename would touch and returns a result in the
+// same shape as a real rename.
+func (t *RenameSymbolTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	oldName, newName, files, err := t.ChangedFiles(ctx, argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	metadata := map[string]any{
+		"old_name":      oldName,
+		"new_name":      newName,
+		"files_changed": len(files),
+		"mocked":        true,
+	}
+
+	return coding.FormatRenameResult(oldName, newName, files) + "\n\n" + mockNotice, metadata, nil
+}
+
+// DeleteFileTool simulates delete_file by deleting files in the overlay.
+type DeleteFileTool struct {
+	*coding.DeleteFileTool
+	guard   *workspace.Guard
+	overlay *Overlay
+}
+
+// NewDeleteFileTool creates a simulated delete_file tool backed by overlay.
+func NewDeleteFileTool(guard *workspace.Guard, overlay *Overlay) *DeleteFileTool {
+	return &DeleteFileTool{
+		DeleteFileTool: coding.NewDeleteFileTool(guard),
+		guard:          guard,
+		overlay:        overlay,
+	}
+}
+
+// deleteInput holds delete_file's arguments.
+type deleteInput struct {
+	XMLName   xml.Name `xml:"arguments"`
+	Path      string   `xml:"path"`
+	Recursive bool     `xml:"recursive"`
+}
+
+// target resolves the path to delete and the files deleting it removes.
+func (t *DeleteFileTool) target(argsXML []byte) (relPath string, files []string, isDir bool, err error) {
+	var input deleteInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, false, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if input.Path == "" {
+		return "", nil, false, fmt.Errorf("missing required parameter: path")
+	}
+
+	absPath, relPath, err := resolve(t.guard, input.Path)
+	if err != nil {
+		return "", nil, false, err
+	}
+	files, isDir, err = overlayTarget(t.overlay, absPath, relPath)
+	if err != nil {
+		return "", nil, false, err
+	}
+	if isDir && !input.Recursive {
+		return "", nil, false, fmt.Errorf("'%s' is a directory; set recursive to true to delete it and everything in it", relPath)
+	}
+	if ruleErr := t.guard.CheckWrite(input.Path, 0); ruleErr != nil {
+		return "", nil, false, ruleErr
+	}
+	for _, file := range files {
+		if ruleErr := t.guard.CheckWrite(relativeTo(t.guard, file), 0); ruleErr != nil {
+			return "", nil, false, ruleErr
+		}
+	}
+	return relPath, files, isDir, nil
+}
+
+// Execute deletes the file, or every file in the directory, in the overlay.
+func (t *DeleteFileTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	relPath, files, isDir, err := t.target(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if isDir {
+		relFiles := make([]string, len(files))
+		for i, absPath := range files {
+			t.overlay.DeleteFile(absPath)
+			relFiles[i] = relativeTo(t.guard, absPath)
+		}
+		metadata := map[string]any{
+			"modified_files": relFiles,
+			"mocked":         true,
+		}
+		return fmt.Sprintf("Directory '%s' deleted (%d files)\n%s", relPath, len(files), mockNotice), metadata, nil
+	}
+
+	var linesRemoved int
+	if content, readErr := t.overlay.ReadFile(files[0]); readErr == nil {
+		linesRemoved = coding.CalculateLineChanges(string(content), "").LinesRemoved
+	}
+	t.overlay.DeleteFile(files[0])
+
+	metadata := map[string]any{
+		"file_path":     relPath,
+		"lines_added":   0,
+		"lines_removed": linesRemoved,
+		"mocked":        true,
+	}
+	return fmt.Sprintf("File '%s' deleted (-%d lines)\n%s", relPath, linesRemoved, mockNotice), metadata, nil
+}
+
+// GeneratePreview lists what would be deleted from the simulated workspace.
+func (t *DeleteFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	relPath, files, isDir, err := t.target(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	if isDir {
+		relFiles := make([]string, len(files))
+		for i, absPath := range files {
+			relFiles[i] = relativeTo(t.guard, absPath)
+		}
+		return &tools.ToolPreview{
+			Type:        tools.PreviewTypeCommand,
+			Title:       fmt.Sprintf("Delete directory %s (simulated)", relPath),
+			Description: fmt.Sprintf("This will delete %s and its %d files in the mock overlay", relPath, len(files)),
+			Content:     strings.Join(relFiles, "\n"),
+			Metadata:    map[string]any{"file_path": relPath},
+		}, nil
+	}
+
+	content, err := t.overlay.ReadFile(files[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeDiff,
+		Title:       fmt.Sprintf("Delete %s (simulated)", relPath),
+		Description: fmt.Sprintf("This will delete %s in the mock overlay", relPath),
+		Content:     coding.GenerateUnifiedDiff(string(content), "", relPath),
+		Metadata:    map[string]any{"file_path": relPath},
+	}, nil
+}
+
+// MoveFileTool simulates move_file by moving files within the overlay.
+type MoveFileTool struct {
+	*coding.MoveFileTool
+	guard   *workspace.Guard
+	overlay *Overlay
+}
+
+// NewMoveFileTool creates a simulated move_file tool backed by overlay.
+func NewMoveFileTool(guard *workspace.Guard, overlay *Overlay) *MoveFileTool {
+	return &MoveFileTool{
+		MoveFileTool: coding.NewMoveFileTool(guard),
+		guard:        guard,
+		overlay:      overlay,
+	}
+}
+
+// simulatedMove is a move_file call resolved against the overlay.
+type simulatedMove struct {
+	relPath, relDest string
+	isDir            bool
+	moves            [][2]string // Absolute source and destination of each file
+}
+
+// plan resolves the move against the overlay.
+func (t *MoveFileTool) plan(argsXML []byte) (*simulatedMove, error) {
+	var input struct {
+		XMLName     xml.Name `xml:"arguments"`
+		Path        string   `xml:"path"`
+		Destination string   `xml:"destination"`
+		Overwrite   bool     `xml:"overwrite"`
+	}
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if input.Path == "" {
+		return nil, fmt.Errorf("missing required parameter: path")
+	}
+	if input.Destination == "" {
+		return nil, fmt.Errorf("missing required parameter: destination")
+	}
+
+	absPath, relPath, err := resolve(t.guard, input.Path)
+	if err != nil {
+		return nil, err
+	}
+	absDest, relDest, err := resolve(t.guard, input.Destination)
+	if err != nil {
+		return nil, err
+	}
+	if absPath == absDest {
+		return nil, fmt.Errorf("path and destination are the same")
+	}
+
+	files, isDir, err := overlayTarget(t.overlay, absPath, relPath)
+	if err != nil {
+		return nil, err
+	}
+	if ruleErr := t.guard.CheckWrite(input.Path, 0); ruleErr != nil {
+		return nil, ruleErr
+	}
+	var size int64
+	if !isDir {
+		if content, readErr := t.overlay.ReadFile(absPath); readErr == nil {
+			size = int64(len(content))
+		}
+	}
+	if ruleErr := t.guard.CheckWrite(input.Destination, size); ruleErr != nil {
+		return nil, ruleErr
+	}
+
+	move := &simulatedMove{relPath: relPath, relDest: relDest, isDir: isDir}
+	for _, file := range files {
+		dest := absDest + strings.TrimPrefix(file, absPath)
+		if t.overlay.Exists(dest) && (isDir || !input.Overwrite) {
+			return nil, fmt.Errorf("destination '%s' already exists; set overwrite to true to replace it", relativeTo(t.guard, dest))
+		}
+		// Every file in a directory must be writable where it is and where it
+		// is going
+		if isDir {
+			if ruleErr := t.guard.CheckWrite(relativeTo(t.guard, file), 0); ruleErr != nil {
+				return nil, ruleErr
+			}
+			if ruleErr := t.guard.CheckWrite(relativeTo(t.guard, dest), 0); ruleErr != nil {
+				return nil, ruleErr
+			}
+		}
+		move.moves = append(move.moves, [2]string{file, dest})
+	}
+	return move, nil
+}
+
+// Execute moves the file, or every file in the directory, in the overlay.
+func (t *MoveFileTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	move, err := t.plan(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sources, destinations []string
+	for _, m := range move.moves {
+		content, readErr := t.overlay.ReadFile(m[0])
+		if readErr != nil {
+			return "", nil, fmt.Errorf("failed to read file: %w", readErr)
+		}
+		t.overlay.WriteFile(m[1], content)
+		t.overlay.DeleteFile(m[0])
+		sources = append(sources, relativeTo(t.guard, m[0]))
+		destinations = append(destinations, relativeTo(t.guard, m[1]))
+	}
+
+	message := fmt.Sprintf("File '%s' moved to '%s'", move.relPath, move.relDest)
+	if move.isDir {
+		message = fmt.Sprintf("Directory '%s' moved to '%s' (%d files)", move.relPath, move.relDest, len(move.moves))
+	}
+	metadata := map[string]any{
+		"source":         move.relPath,
+		"destination":    move.relDest,
+		"modified_files": append(sources, destinations...),
+		"mocked":         true,
+	}
+	return message + "\n" + mockNotice, metadata, nil
+}
+
+// GeneratePreview lists what would move where in the simulated workspace.
+func (t *MoveFileTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	move, err := t.plan(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, len(move.moves))
+	for i, m := range move.moves {
+		lines[i] = fmt.Sprintf("%s → %s", relativeTo(t.guard, m[0]), relativeTo(t.guard, m[1]))
+	}
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeCommand,
+		Title:       fmt.Sprintf("Move %s to %s (simulated)", move.relPath, move.relDest),
+		Description: fmt.Sprintf("This will move %s to %s in the mock overlay", move.relPath, move.relDest),
+		Content:     strings.Join(lines, "\n"),
+		Metadata: map[string]any{
+			"file_path":   move.relPath,
+			"destination": move.relDest,
+		},
+	}, nil
+}
+
+// CreateDirectoryTool simulates create_directory. Directories hold no
+// content of their own, so nothing is recorded in the overlay.
+type CreateDirectoryTool struct {
+	*coding.CreateDirectoryTool
+	guard *workspace.Guard
+}
+
+// NewCreateDirectoryTool creates a simulated create_directory tool.
+func NewCreateDirectoryTool(guard *workspace.Guard) *CreateDirectoryTool {
+	return &CreateDirectoryTool{
+		CreateDirectoryTool: coding.NewCreateDirectoryTool(guard),
+		guard:               guard,
+	}
+}
+
+// Execute reports the directory as created without creating it.
+func (t *CreateDirectoryTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Path    string   `xml:"path"`
+	}
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if input.Path == "" {
+		return "", nil, fmt.Errorf("missing required parameter: path")
+	}
+
+	_, relPath, err := resolve(t.guard, input.Path)
+	if err != nil {
+		return "", nil, err
+	}
+	if ruleErr := t.guard.CheckWrite(input.Path, 0); ruleErr != nil {
+		return "", nil, ruleErr
+	}
+	metadata := map[string]any{
+		"dir_path": relPath,
+		"mocked":   true,
+	}
+	return fmt.Sprintf("Directory '%s' created\n%s", relPath, mockNotice), metadata, nil
+}
+
+// ReadFileTool reads files through the overlay so simulated edits are visible.
+type ReadFileTool struct {
+	*coding.ReadFileTool
+	guard   *workspace.Guard
+	overlay *Overlay
+}
+
+// NewReadFileTool creates a read_file tool that sees the overlay's content.
+func NewReadFileTool(guard *workspace.Guard, overlay *Overlay) *ReadFileTool {
+	return &ReadFileTool{
+		ReadFileTool: coding.NewReadFileTool(guard),
+		guard:        guard,
+		overlay:      overlay,
+	}
+}
+
+// Execute returns line-numbered content from the overlay, falling back to disk.
+func (t *ReadFileTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName   xml.Name `xml:"arguments"`
+		Path      string   `xml:"path"`
+		StartLine int      `xml:"start_line"`
+		EndLine   int      `xml:"end_line"`
+	}
+
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if input.Path == "" {
+		return "", nil, fmt.Errorf("missing required parameter: path")
+	}
+
+	absPath, _, err := resolve(t.guard, input.Path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if t.guard.ShouldIgnore(absPath) {
+		return "", nil, fmt.Errorf("file '%s' is ignored by .gitignore, .forgeignore, or default patterns", input.Path)
+	}
+
+	data, err := t.overlay.ReadFile(absPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	content, err := formatLines(string(data), input.StartLine, input.EndLine)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	metadata := map[string]any{
+		"path":       input.Path,
+		"size_bytes": int64(len(data)),
+	}
+	if input.StartLine > 0 {
+		metadata["start_line"] = input.StartLine
+	}
+	if input.EndLine > 0 {
+		metadata["end_line"] = input.EndLine
+	}
+
+	return content, metadata, nil
+}
+
+// formatLines renders content in read_file's "N | line" format for the
+// inclusive 1-based range [startLine, endLine]. Zero values select everything.
+func formatLines(content string, startLine, endLine int) (string, error) {
+	readAll := startLine == 0 && endLine == 0
+	if !readAll {
+		if startLine < 1 {
+			return "", fmt.Errorf("start_line must be >= 1, got %d", startLine)
+		}
+		if endLine < startLine && endLine != 0 {
+			return "", fmt.Errorf("end_line (%d) must be >= start_line (%d)", endLine, startLine)
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	var builder strings.Builder
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		if !readAll && lineNum < startLine {
+			continue
+		}
+		if !readAll && endLine > 0 && lineNum > endLine {
+			break
+		}
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+		fmt.Fprintf(&builder, "%d | %s", lineNum, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	if builder.Len() == 0 && !readAll && startLine > lineNum {
+		return "", fmt.Errorf("start_line %d exceeds file length (%d lines)", startLine, lineNum)
+	}
+
+	return builder.String(), nil
+}
+
+// resolve validates path against the guard and returns its absolute and
+// workspace-relative forms.
+func resolve(guard *workspace.Guard, path string) (absPath, relPath string, err error) {
+	if validateErr := guard.ValidatePath(path); validateErr != nil {
+		return "", "", fmt.Errorf("invalid path: %w", validateErr)
+	}
+
+	absPath, err = guard.ResolvePath(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	relPath, err = guard.MakeRelative(absPath)
+	if err != nil || relPath == "" {
+		relPath = path
+	}
+
+	return absPath, relPath, nil
+}
+
+// overlayTarget returns the files at absPath as the overlay sees them: the
+// file itself, or every file in the directory, which isDir reports.
+func overlayTarget(overlay *Overlay, absPath, relPath string) (files []string, isDir bool, err error) {
+	if info, statErr := os.Stat(absPath); statErr == nil && info.IsDir() {
+		return overlay.FilesUnder(absPath), true, nil
+	}
+	if overlay.Exists(absPath) {
+		return []string{absPath}, false, nil
+	}
+	if files := overlay.FilesUnder(absPath); len(files) > 0 {
+		return files, true, nil
+	}
+	return nil, false, fmt.Errorf("'%s' does not exist", relPath)
+}
+
+// relativeTo returns absPath relative to the guard's workspace.
+func relativeTo(guard *workspace.Guard, absPath string) string {
+	if relPath, err := guard.MakeRelative(absPath); err == nil && relPath != "" {
+		return filepath.ToSlash(relPath)
+	}
+	return absPath
+}
+
+// Wrap replaces the side-effecting tools in list with overlay-backed
+// simulations, leaving every other tool untouched.
+func Wrap(list []tools.Tool, guard *workspace.Guard, overlay *Overlay) []tools.Tool {
+	wrapped := make([]tools.Tool, len(list))
+	for i, tool := range list {
+		switch tool.Name() {
+		case "write_file":
+			wrapped[i] = NewWriteFileTool(guard, overlay)
+		case "apply_diff":
+			wrapped[i] = NewApplyDiffTool(guard, overlay)
+		case "execute_command":
+			wrapped[i] = NewExecuteCommandTool(guard, overlay)
+		case "run_tests":
+			wrapped[i] = NewRunTestsTool(guard, overlay)
+		case "read_file":
+			wrapped[i] = NewReadFileTool(guard, overlay)
+		case "rename_symbol":
+			wrapped[i] = NewRenameSymbolTool(guard)
+		case "delete_file":
+			wrapped[i] = NewDeleteFileTool(guard, overlay)
+		case "move_file":
+			wrapped[i] = NewMoveFileTool(guard, overlay)
+		case "create_directory":
+			wrapped[i] = NewCreateDirectoryTool(guard)
+		default:
+			wrapped[i] = tool
+		}
+	}
+	return wrapped
+}
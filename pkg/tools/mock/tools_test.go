@@ -0,0 +1,291 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+	"github.com/entrhq/forge/pkg/tools/coding"
+)
+
+func newTestOverlay(t *testing.T) (string, *workspace.Guard, *Overlay) {
+	t.Helper()
+
+	dir := t.TempDir()
+	guard, err := workspace.NewGuard(dir)
+	if err != nil {
+		t.Fatalf("failed to create guard: %v", err)
+	}
+	return dir, guard, NewOverlay(guard.WorkspaceDir())
+}
+
+func TestWriteFileTool_DoesNotTouchDisk(t *testing.T) {
+	dir, guard, overlay := newTestOverlay(t)
+	tool := NewWriteFileTool(guard, overlay)
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(`<arguments>
+	<path>new.txt</path>
+	<content>hello</content>
+</arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if !strings.Contains(result, "created successfully") {
+		t.Errorf("expected realistic creation message, got: %s", result)
+	}
+	if metadata["mocked"] != true {
+		t.Errorf("expected mocked=true in metadata")
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "new.txt")); !os.IsNotExist(statErr) {
+		t.Errorf("expected file to be absent on disk, stat err: %v", statErr)
+	}
+
+	changes := overlay.Changes()
+	if len(changes) != 1 || changes[0].Path != "new.txt" || !changes[0].Created || changes[0].Content != "hello" {
+		t.Errorf("unexpected overlay changes: %+v", changes)
+	}
+}
+
+func TestApplyDiffTool_SeesPriorSimulatedWrites(t *testing.T) {
+	dir, guard, overlay := newTestOverlay(t)
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nconst x = 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	write := NewWriteFileTool(guard, overlay)
+	if _, _, err := write.Execute(context.Background(), []byte(`<arguments><path>main.go</path><content>package main
+
+const y = 2
+</content></arguments>`)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	diff := NewApplyDiffTool(guard, overlay)
+	args := []byte(`<arguments><path>main.go</path><edits><edit><search>const y = 2</search><replace>const y = 3</replace></edit></edits></arguments>`)
+
+	preview, err := diff.GeneratePreview(context.Background(), args)
+	if err != nil {
+		t.Fatalf("GeneratePreview failed: %v", err)
+	}
+	if preview.Type != tools.PreviewTypeDiff || !strings.Contains(preview.Content, "+const y = 3") {
+		t.Errorf("unexpected preview: %+v", preview)
+	}
+
+	if _, _, err := diff.Execute(context.Background(), args); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	onDisk, _ := os.ReadFile(path)
+	if string(onDisk) != "package main\n\nconst x = 1\n" {
+		t.Errorf("real file was modified: %q", onDisk)
+	}
+
+	read := NewReadFileTool(guard, overlay)
+	content, _, err := read.Execute(context.Background(), []byte(`<arguments><path>main.go</path><start_line>3</start_line></arguments>`))
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if content != "3 | const y = 3" {
+		t.Errorf("expected simulated content, got: %q", content)
+	}
+
+	changes := overlay.Changes()
+	if len(changes) != 1 || changes[0].Created || changes[0].Original != "package main\n\nconst x = 1\n" {
+		t.Errorf("unexpected overlay changes: %+v", changes)
+	}
+}
+
+func TestMoveAndDeleteFileTools_DoNotTouchDisk(t *testing.T) {
+	dir, guard, overlay := newTestOverlay(t)
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"pkg/a.go": "package pkg\n", "old.txt": "old\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	overlay.WriteFile(filepath.Join(dir, "pkg", "b.go"), []byte("package pkg\n\nvar b = 1\n"))
+
+	move := NewMoveFileTool(guard, overlay)
+	if _, _, err := move.Execute(context.Background(), []byte(`<arguments><path>pkg</path><destination>internal/pkg</destination></arguments>`)); err != nil {
+		t.Fatalf("move_file failed: %v", err)
+	}
+	del := NewDeleteFileTool(guard, overlay)
+	_, metadata, err := del.Execute(context.Background(), []byte(`<arguments><path>old.txt</path></arguments>`))
+	if err != nil {
+		t.Fatalf("delete_file failed: %v", err)
+	}
+	if metadata["lines_removed"] != 1 || metadata["mocked"] != true {
+		t.Errorf("unexpected metadata: %v", metadata)
+	}
+
+	// Nothing changed on disk
+	for _, name := range []string{"pkg/a.go", "old.txt"} {
+		if _, statErr := os.Stat(filepath.Join(dir, name)); statErr != nil {
+			t.Errorf("expected %s to stay on disk, got %v", name, statErr)
+		}
+	}
+	if overlay.Exists(filepath.Join(dir, "old.txt")) {
+		t.Error("expected old.txt to be deleted in the overlay")
+	}
+
+	// b.go was only ever in the overlay, so it is simply created at its new path
+	var got []string
+	for _, change := range overlay.Changes() {
+		got = append(got, fmt.Sprintf("%s created=%v deleted=%v", change.Path, change.Created, change.Deleted))
+	}
+	want := []string{
+		"internal/pkg/a.go created=true deleted=false",
+		"internal/pkg/b.go created=true deleted=false",
+		"old.txt created=false deleted=true",
+		"pkg/a.go created=false deleted=true",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTools_RefuseDeniedWrites(t *testing.T) {
+	dir, guard, overlay := newTestOverlay(t)
+	if err := guard.SetPathRules(workspace.PathRules{DenyWrite: []string{"secrets/**"}, MaxFileSize: 16}); err != nil {
+		t.Fatalf("SetPathRules failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "secrets"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"secrets/key.txt": "key\n", "notes.txt": "notes\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		tool tools.Tool
+		args string
+	}{
+		{"write_file", NewWriteFileTool(guard, overlay), `<arguments><path>secrets/new.txt</path><content>x</content></arguments>`},
+		{"write_file too large", NewWriteFileTool(guard, overlay), `<arguments><path>big.txt</path><content>more than sixteen bytes</content></arguments>`},
+		{"apply_diff", NewApplyDiffTool(guard, overlay), `<arguments><path>secrets/key.txt</path><edits><edit><search>key</search><replace>leaked</replace></edit></edits></arguments>`},
+		{"delete_file", NewDeleteFileTool(guard, overlay), `<arguments><path>secrets/key.txt</path></arguments>`},
+		{"delete_file directory", NewDeleteFileTool(guard, overlay), `<arguments><path>secrets</path><recursive>true</recursive></arguments>`},
+		{"move_file source", NewMoveFileTool(guard, overlay), `<arguments><path>secrets/key.txt</path><destination>key.txt</destination></arguments>`},
+		{"move_file destination", NewMoveFileTool(guard, overlay), `<arguments><path>notes.txt</path><destination>secrets/notes.txt</destination></arguments>`},
+		{"create_directory", NewCreateDirectoryTool(guard), `<arguments><path>secrets/sub</path></arguments>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.tool.Execute(context.Background(), []byte(tt.args))
+			var ruleErr *workspace.PathRuleError
+			if !errors.As(err, &ruleErr) {
+				t.Fatalf("expected a path rule error, got %v", err)
+			}
+		})
+	}
+
+	if changes := overlay.Changes(); len(changes) != 0 {
+		t.Errorf("expected refused writes to leave the overlay empty, got %+v", changes)
+	}
+}
+
+func TestExecuteCommandTool_RecordsWithoutRunning(t *testing.T) {
+	dir, guard, overlay := newTestOverlay(t)
+	tool := NewExecuteCommandTool(guard, overlay)
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(`<arguments><command>touch created.txt</command></arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if !strings.Contains(result, "Exit code: 0") {
+		t.Errorf("expected exit code in result, got: %s", result)
+	}
+	if metadata["exit_code"] != 0 {
+		t.Errorf("expected exit_code=0, got %v", metadata["exit_code"])
+	}
+	if _, statErr := os.Stat(filepath.Join(dir, "created.txt")); !os.IsNotExist(statErr) {
+		t.Errorf("command should not have run")
+	}
+	if cmds := overlay.Commands(); len(cmds) != 1 || cmds[0] != "touch created.txt" {
+		t.Errorf("unexpected recorded commands: %v", cmds)
+	}
+}
+
+func TestRunTestsTool_RecordsWithoutRunning(t *testing.T) {
+	dir, guard, overlay := newTestOverlay(t)
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewRunTestsTool(guard, overlay)
+
+	result, metadata, err := tool.Execute(context.Background(), []byte(`<arguments><filter>TestAdd</filter></arguments>`))
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if !strings.Contains(result, "exit code 0") || metadata["mocked"] != true {
+		t.Errorf("expected a mocked passing result, got: %s (%v)", result, metadata)
+	}
+	if cmds := overlay.Commands(); len(cmds) != 1 || cmds[0] != "go test -json -run TestAdd ./..." {
+		t.Errorf("unexpected recorded commands: %v", cmds)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	_, guard, overlay := newTestOverlay(t)
+
+	list := []tools.Tool{
+		coding.NewReadFileTool(guard),
+		coding.NewWriteFileTool(guard),
+		coding.NewListFilesTool(guard),
+		coding.NewApplyDiffTool(guard),
+		coding.NewExecuteCommandTool(guard),
+		coding.NewRunTestsTool(guard),
+		coding.NewDeleteFileTool(guard),
+		coding.NewMoveFileTool(guard),
+		coding.NewCreateDirectoryTool(guard),
+	}
+	wrapped := Wrap(list, guard, overlay)
+
+	if _, ok := wrapped[0].(*ReadFileTool); !ok {
+		t.Errorf("read_file not wrapped")
+	}
+	if _, ok := wrapped[1].(*WriteFileTool); !ok {
+		t.Errorf("write_file not wrapped")
+	}
+	if wrapped[2] != list[2] {
+		t.Errorf("list_files should be left untouched")
+	}
+	if _, ok := wrapped[3].(*ApplyDiffTool); !ok {
+		t.Errorf("apply_diff not wrapped")
+	}
+	if _, ok := wrapped[4].(*ExecuteCommandTool); !ok {
+		t.Errorf("execute_command not wrapped")
+	}
+	if _, ok := wrapped[5].(*RunTestsTool); !ok {
+		t.Errorf("run_tests not wrapped")
+	}
+	if _, ok := wrapped[6].(*DeleteFileTool); !ok {
+		t.Errorf("delete_file not wrapped")
+	}
+	if _, ok := wrapped[7].(*MoveFileTool); !ok {
+		t.Errorf("move_file not wrapped")
+	}
+	if _, ok := wrapped[8].(*CreateDirectoryTool); !ok {
+		t.Errorf("create_directory not wrapped")
+	}
+	for i := range list {
+		if wrapped[i].Name() != list[i].Name() {
+			t.Errorf("wrapped tool %d changed name: %s != %s", i, wrapped[i].Name(), list[i].Name())
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/xml"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/entrhq/forge/pkg/agent/memory/notes"
 	"github.com/entrhq/forge/pkg/agent/tools"
@@ -48,6 +50,11 @@ func (t *AddNoteTool) Schema() map[string]any {
 				"minItems":    1,
 				"maxItems":    5,
 			},
+			"category": map[string]any{
+				"type":        "string",
+				"enum":        notes.Categories,
+				"description": "Optional kind of note, added as a tag: decision (a choice and its rationale), blocker (a constraint that stops or limits the work) or followup (work deferred for later)",
+			},
 		},
 		[]string{"content", "tags"},
 	)
@@ -56,9 +63,10 @@ func (t *AddNoteTool) Schema() map[string]any {
 // Execute creates a new note.
 func (t *AddNoteTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
 	var input struct {
-		XMLName xml.Name `xml:"arguments"`
-		Content string   `xml:"content"`
-		Tags    []string `xml:"tags>tag"`
+		XMLName  xml.Name `xml:"arguments"`
+		Content  string   `xml:"content"`
+		Tags     []string `xml:"tags>tag"`
+		Category string   `xml:"category"`
 	}
 
 	if err := xml.Unmarshal(argsXML, &input); err != nil {
@@ -73,8 +81,20 @@ func (t *AddNoteTool) Execute(ctx context.Context, argsXML []byte) (string, map[
 		return "", nil, fmt.Errorf("missing required parameter: tags (at least 1 tag required)")
 	}
 
+	// The category is stored as a tag, first so it reads as the note's kind
+	tags := input.Tags
+	if category := strings.ToLower(strings.TrimSpace(input.Category)); category != "" {
+		if !notes.IsCategory(category) {
+			return "", nil, fmt.Errorf("invalid category %q: must be one of %s", input.Category, strings.Join(notes.Categories, ", "))
+		}
+		tags = slices.DeleteFunc(slices.Clone(tags), func(tag string) bool {
+			return strings.EqualFold(strings.TrimSpace(tag), category)
+		})
+		tags = append([]string{category}, tags...)
+	}
+
 	// Add the note
-	note, err := t.manager.Add(input.Content, input.Tags)
+	note, err := t.manager.Add(input.Content, tags)
 	if err != nil {
 		return "", nil, err
 	}
@@ -88,6 +108,9 @@ func (t *AddNoteTool) Execute(ctx context.Context, argsXML []byte) (string, map[
 		"total_notes": t.manager.Count(),
 		"tags":        note.Tags,
 	}
+	if category := note.Category(); category != "" {
+		metadata["category"] = category
+	}
 
 	return message, metadata, nil
 }
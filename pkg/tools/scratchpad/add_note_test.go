@@ -298,3 +298,58 @@ func TestAddNoteTool_Execute_WithHelper(t *testing.T) {
 		t.Error("Expected 1 note after creation")
 	}
 }
+
+func TestAddNoteTool_Execute_Category(t *testing.T) {
+	manager := notes.NewManager()
+	tool := NewAddNoteTool(manager)
+
+	argsXML := []byte(`<arguments>
+		<content>Chose JWT over sessions so the API stays stateless</content>
+		<tags>
+			<tag>auth</tag>
+			<tag>Decision</tag>
+		</tags>
+		<category>decision</category>
+	</arguments>`)
+
+	_, metadata, err := tool.Execute(context.Background(), argsXML)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if metadata["category"] != notes.CategoryDecision {
+		t.Errorf("Expected category metadata %q, got %v", notes.CategoryDecision, metadata["category"])
+	}
+
+	note, err := manager.Get(metadata["note_id"].(string))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	// The category leads the tags and is not repeated
+	if strings.Join(note.Tags, ",") != "decision,auth" {
+		t.Errorf("Expected tags [decision auth], got %v", note.Tags)
+	}
+}
+
+func TestAddNoteTool_Execute_InvalidCategory(t *testing.T) {
+	manager := notes.NewManager()
+	tool := NewAddNoteTool(manager)
+
+	argsXML := []byte(`<arguments>
+		<content>Test note</content>
+		<tags>
+			<tag>test</tag>
+		</tags>
+		<category>idea</category>
+	</arguments>`)
+
+	_, _, err := tool.Execute(context.Background(), argsXML)
+	if err == nil {
+		t.Fatal("Execute() should fail with an unknown category")
+	}
+	if !strings.Contains(err.Error(), "category") {
+		t.Errorf("Error should mention the category: %v", err)
+	}
+	if manager.Count() != 0 {
+		t.Errorf("Expected no note to be created, got %d", manager.Count())
+	}
+}
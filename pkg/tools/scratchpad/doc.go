@@ -8,7 +8,8 @@
 //
 // Tool Overview:
 //
-// add_note: Create a new note with content (max 800 chars) and 1-5 tags
+// add_note: Create a new note with content (max 800 chars), 1-5 tags and an
+// optional category (decision, blocker or followup)
 //
 // search_notes: Search notes by content query and/or tags with relevance ranking
 //
@@ -0,0 +1,82 @@
+package terminal
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// CloseTerminalTool closes a terminal session.
+type CloseTerminalTool struct {
+	manager *SessionManager
+}
+
+// NewCloseTerminalTool creates a new close terminal tool.
+func NewCloseTerminalTool(manager *SessionManager) *CloseTerminalTool {
+	return &CloseTerminalTool{
+		manager: manager,
+	}
+}
+
+// Name returns the tool name.
+func (t *CloseTerminalTool) Name() string {
+	return "close_terminal"
+}
+
+// Description returns the tool description.
+func (t *CloseTerminalTool) Description() string {
+	return "Close a terminal session, killing its program and any processes it started. Close sessions once you are done with them."
+}
+
+// Schema returns the tool's JSON schema.
+func (t *CloseTerminalTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"session": map[string]any{
+				"type":        "string",
+				"description": "Name of the terminal session to close",
+			},
+		},
+		[]string{"session"},
+	)
+}
+
+// closeTerminalInput represents the parameters for closing a terminal.
+type closeTerminalInput struct {
+	XMLName xml.Name `xml:"arguments"`
+	Session string   `xml:"session"`
+}
+
+// Execute closes the session and returns its last screen.
+func (t *CloseTerminalTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input closeTerminalInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	if input.Session == "" {
+		return "", nil, fmt.Errorf("session name is required")
+	}
+
+	session, err := t.manager.GetSession(input.Session)
+	if err != nil {
+		return "", nil, err
+	}
+	status := session.Status()
+	if err := t.manager.CloseSession(input.Session); err != nil {
+		return "", nil, fmt.Errorf("failed to close terminal: %w", err)
+	}
+
+	return fmt.Sprintf("Terminal %q closed (the program was %s).", input.Session, status), nil, nil
+}
+
+// IsLoopBreaking returns whether this tool breaks the agent loop.
+func (t *CloseTerminalTool) IsLoopBreaking() bool {
+	return false
+}
+
+// ShouldShow shows the tool while terminal sessions are open.
+func (t *CloseTerminalTool) ShouldShow() bool {
+	return t.manager.HasSessions()
+}
@@ -0,0 +1,31 @@
+// Package terminal lets the agent drive interactive programs through a
+// pseudo-terminal.
+//
+// execute_command runs a command to completion and returns its output, which
+// rules out programs that wait for input: database shells, debuggers, REPLs
+// and anything that prompts. A terminal session keeps such a program running
+// behind a PTY between agent iterations, so the agent can type into it and
+// read what it printed, the way a person at a terminal would.
+//
+// # Session Lifecycle
+//
+//  1. Start: start_terminal runs a command in a new named session
+//  2. Use: terminal_send_keys types text and named keys, terminal_read_screen
+//     reads the latest output
+//  3. Close: close_terminal kills the program and everything it started
+//  4. Timeout: sessions idle for longer than the idle timeout are closed,
+//     and every session is closed when the agent shuts down
+//
+// # Screen
+//
+// Output is kept as a line-oriented transcript rather than a full terminal
+// emulation: carriage returns, backspaces and line erases are applied and
+// other escape sequences are dropped. Sessions run with TERM=dumb so
+// programs keep to plain output; full-screen programs such as editors are
+// not a good fit.
+//
+// # Platforms
+//
+// Sessions are supported on Linux and macOS. Elsewhere the tools are hidden
+// and starting a session fails with ErrUnsupported.
+package terminal
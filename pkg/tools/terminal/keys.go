@@ -0,0 +1,58 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// namedKeys maps key names, lowercased, to the bytes a terminal sends for them
+var namedKeys = map[string]string{
+	"enter":     "\r",
+	"return":    "\r",
+	"tab":       "\t",
+	"escape":    "\x1b",
+	"esc":       "\x1b",
+	"backspace": "\x7f",
+	"space":     " ",
+	"up":        "\x1b[A",
+	"down":      "\x1b[B",
+	"right":     "\x1b[C",
+	"left":      "\x1b[D",
+	"home":      "\x1b[H",
+	"end":       "\x1b[F",
+	"pageup":    "\x1b[5~",
+	"pagedown":  "\x1b[6~",
+	"delete":    "\x1b[3~",
+}
+
+// keyNames lists the key names parseKeys understands, for tool descriptions
+const keyNames = "Enter, Tab, Escape, Backspace, Space, Up, Down, Left, Right, Home, End, PageUp, PageDown, Delete and Ctrl-A to Ctrl-Z (e.g. Ctrl-C, Ctrl-D)"
+
+// parseKeys turns a space- or comma-separated list of key names into the
+// bytes to send.
+func parseKeys(keys string) (string, error) {
+	var input strings.Builder
+	fields := strings.FieldsFunc(keys, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+	for _, field := range fields {
+		name := strings.ToLower(field)
+		if seq, ok := namedKeys[name]; ok {
+			input.WriteString(seq)
+			continue
+		}
+
+		// Ctrl-C, Ctrl+C, C-c
+		for _, prefix := range []string{"ctrl-", "ctrl+", "c-"} {
+			if rest, found := strings.CutPrefix(name, prefix); found && len(rest) == 1 && rest[0] >= 'a' && rest[0] <= 'z' {
+				input.WriteByte(rest[0] - 'a' + 1)
+				name = ""
+				break
+			}
+		}
+		if name != "" {
+			return "", fmt.Errorf("unknown key %q (keys are %s)", field, keyNames)
+		}
+	}
+	return input.String(), nil
+}
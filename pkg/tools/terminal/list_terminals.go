@@ -0,0 +1,68 @@
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// ListTerminalsTool lists the open terminal sessions.
+type ListTerminalsTool struct {
+	manager *SessionManager
+}
+
+// NewListTerminalsTool creates a new list terminals tool.
+func NewListTerminalsTool(manager *SessionManager) *ListTerminalsTool {
+	return &ListTerminalsTool{
+		manager: manager,
+	}
+}
+
+// Name returns the tool name.
+func (t *ListTerminalsTool) Name() string {
+	return "list_terminals"
+}
+
+// Description returns the tool description.
+func (t *ListTerminalsTool) Description() string {
+	return "List the open terminal sessions with their programs and whether they are still running."
+}
+
+// Schema returns the tool's JSON schema.
+func (t *ListTerminalsTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{},
+		[]string{},
+	)
+}
+
+// Execute lists the sessions.
+func (t *ListTerminalsTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	sessions := t.manager.ListSessions()
+	if len(sessions) == 0 {
+		return "No terminal sessions are open.\n\nUse start_terminal to start one.", nil, nil
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "Open terminal sessions: %d\n", len(sessions))
+	for _, info := range sessions {
+		fmt.Fprintf(&result, "\n%s (%s)\n", info.Name, info.Status)
+		fmt.Fprintf(&result, "  Command: %s\n", info.Command)
+		fmt.Fprintf(&result, "  Working directory: %s\n", info.WorkDir)
+		fmt.Fprintf(&result, "  Last used: %s ago\n", time.Since(info.LastUsed).Round(time.Second))
+	}
+	return result.String(), nil, nil
+}
+
+// IsLoopBreaking returns whether this tool breaks the agent loop.
+func (t *ListTerminalsTool) IsLoopBreaking() bool {
+	return false
+}
+
+// ShouldShow shows the tool while terminal sessions are open.
+func (t *ListTerminalsTool) ShouldShow() bool {
+	return t.manager.HasSessions()
+}
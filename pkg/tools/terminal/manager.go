@@ -0,0 +1,244 @@
+package terminal
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default values for sessions
+const (
+	DefaultMaxSessions = 5
+	DefaultIdleTimeout = 30 * time.Minute
+	DefaultRows        = 24
+	DefaultCols        = 120
+	DefaultScrollback  = 2000 // Lines of output kept per session
+)
+
+// cleanupInterval is how often idle sessions are looked for
+var cleanupInterval = time.Minute
+
+// Supported reports whether interactive terminals work on this platform.
+func Supported() bool {
+	return supported
+}
+
+// SessionOptions configures a new session.
+type SessionOptions struct {
+	Command    string   // Shell command to run
+	WorkDir    string   // Absolute working directory
+	Rows       int      // Terminal height (default DefaultRows)
+	Cols       int      // Terminal width (default DefaultCols)
+	Scrollback int      // Lines of output kept (default DefaultScrollback)
+	Env        []string // Extra environment variables, as KEY=value
+}
+
+// SessionManager manages the agent's terminal sessions and closes the ones
+// left idle.
+type SessionManager struct {
+	mu          sync.RWMutex
+	sessions    map[string]*Session
+	maxSessions int
+	idleTimeout time.Duration
+	cleaning    bool // Whether the idle cleanup loop is running
+}
+
+// NewSessionManager creates a new session manager.
+func NewSessionManager() *SessionManager {
+	return &SessionManager{
+		sessions:    make(map[string]*Session),
+		maxSessions: DefaultMaxSessions,
+		idleTimeout: DefaultIdleTimeout,
+	}
+}
+
+// StartSession runs a command in a new terminal session with the given name.
+func (m *SessionManager) StartSession(name string, opts SessionOptions) (*Session, error) {
+	if !supported {
+		return nil, ErrUnsupported
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.sessions[name]; exists {
+		return nil, fmt.Errorf("terminal %q already exists", name)
+	}
+	if len(m.sessions) >= m.maxSessions {
+		return nil, fmt.Errorf("maximum number of terminals (%d) reached; close one with close_terminal first", m.maxSessions)
+	}
+
+	if opts.Rows <= 0 {
+		opts.Rows = DefaultRows
+	}
+	if opts.Cols <= 0 {
+		opts.Cols = DefaultCols
+	}
+	if opts.Scrollback <= 0 {
+		opts.Scrollback = DefaultScrollback
+	}
+
+	session, err := startSession(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	m.sessions[name] = session
+
+	if !m.cleaning {
+		m.cleaning = true
+		go m.cleanupLoop()
+	}
+	return session, nil
+}
+
+// GetSession retrieves an open session by name.
+func (m *SessionManager) GetSession(name string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, exists := m.sessions[name]
+	if !exists {
+		return nil, m.notFoundLocked(name)
+	}
+	return session, nil
+}
+
+// notFoundLocked explains that a session does not exist, naming the ones that do.
+func (m *SessionManager) notFoundLocked(name string) error {
+	if len(m.sessions) == 0 {
+		return fmt.Errorf("terminal %q not found; no terminals are open", name)
+	}
+	names := make([]string, 0, len(m.sessions))
+	for open := range m.sessions {
+		names = append(names, open)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("terminal %q not found; open terminals: %v", name, names)
+}
+
+// CloseSession closes and removes a session.
+func (m *SessionManager) CloseSession(name string) error {
+	m.mu.Lock()
+	session, exists := m.sessions[name]
+	if !exists {
+		err := m.notFoundLocked(name)
+		m.mu.Unlock()
+		return err
+	}
+	delete(m.sessions, name)
+	m.mu.Unlock()
+
+	return session.Close()
+}
+
+// ListSessions returns information about all open sessions, oldest first.
+func (m *SessionManager) ListSessions() []SessionInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	infos := make([]SessionInfo, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		infos = append(infos, SessionInfo{
+			Name:      session.Name,
+			Command:   session.Command,
+			WorkDir:   session.WorkDir,
+			Status:    session.Status(),
+			CreatedAt: session.CreatedAt,
+			LastUsed:  session.LastUsed(),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+// HasSessions returns true if there are any open sessions.
+func (m *SessionManager) HasSessions() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.sessions) > 0
+}
+
+// CloseAll closes all open sessions.
+func (m *SessionManager) CloseAll() error {
+	m.mu.Lock()
+	sessions := m.sessions
+	m.sessions = make(map[string]*Session)
+	m.mu.Unlock()
+
+	return closeSessions(sessions)
+}
+
+// CleanupIdleSessions closes sessions that have not been used for longer
+// than the idle timeout.
+func (m *SessionManager) CleanupIdleSessions() error {
+	m.mu.Lock()
+	idle := make(map[string]*Session)
+	for name, session := range m.sessions {
+		if time.Since(session.LastUsed()) > m.idleTimeout {
+			idle[name] = session
+			delete(m.sessions, name)
+		}
+	}
+	m.mu.Unlock()
+
+	return closeSessions(idle)
+}
+
+// cleanupLoop closes idle sessions until none are left open.
+func (m *SessionManager) cleanupLoop() {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		_ = m.CleanupIdleSessions() // A program that will not die is not worth keeping the session for
+
+		m.mu.Lock()
+		if len(m.sessions) == 0 {
+			m.cleaning = false
+			m.mu.Unlock()
+			return
+		}
+		m.mu.Unlock()
+	}
+}
+
+// closeSessions closes sessions, collecting the errors.
+func closeSessions(sessions map[string]*Session) error {
+	var errs []error
+	for _, session := range sessions {
+		if err := session.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("errors closing terminals: %v", errs)
+	}
+	return nil
+}
+
+// SetMaxSessions sets the maximum number of concurrent sessions.
+func (m *SessionManager) SetMaxSessions(maxSessions int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxSessions = maxSessions
+}
+
+// SetIdleTimeout sets how long a session may go unused before it is closed.
+func (m *SessionManager) SetIdleTimeout(timeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.idleTimeout = timeout
+}
+
+// SessionInfo contains metadata about a terminal session.
+type SessionInfo struct {
+	Name      string
+	Command   string
+	WorkDir   string
+	Status    string
+	CreatedAt time.Time
+	LastUsed  time.Time
+}
@@ -0,0 +1,72 @@
+package terminal
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionInteractive(t *testing.T) {
+	if !Supported() {
+		t.Skip("pseudo-terminals are not supported on this platform")
+	}
+
+	manager := NewSessionManager()
+	defer manager.CloseAll()
+
+	// read waits for input, which execute_command could not give it
+	session, err := manager.StartSession("shell", SessionOptions{
+		Command: `printf 'name? '; read name; echo "hello, $name"; sleep 60`,
+		WorkDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if !session.WaitFor(ctx, "name?", 5*time.Second) {
+		t.Fatalf("prompt did not appear, screen:\n%s", session.Screen(10))
+	}
+	if err := session.Send("forge\r"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if !session.WaitFor(ctx, "hello, forge", 5*time.Second) {
+		t.Fatalf("reply did not appear, screen:\n%s", session.Screen(10))
+	}
+	if !strings.Contains(session.Screen(10), "name? forge") {
+		t.Errorf("screen does not show the echoed input:\n%s", session.Screen(10))
+	}
+
+	if err := manager.CloseSession("shell"); err != nil {
+		t.Fatalf("CloseSession() error = %v", err)
+	}
+	if exited, _ := session.Exited(); !exited {
+		t.Error("program still running after CloseSession")
+	}
+	if manager.HasSessions() {
+		t.Error("HasSessions() = true after closing the only session")
+	}
+}
+
+func TestCleanupIdleSessions(t *testing.T) {
+	if !Supported() {
+		t.Skip("pseudo-terminals are not supported on this platform")
+	}
+
+	manager := NewSessionManager()
+	defer manager.CloseAll()
+	manager.SetIdleTimeout(time.Millisecond)
+
+	if _, err := manager.StartSession("idle", SessionOptions{Command: "sleep 60", WorkDir: t.TempDir()}); err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if err := manager.CleanupIdleSessions(); err != nil {
+		t.Fatalf("CleanupIdleSessions() error = %v", err)
+	}
+	if manager.HasSessions() {
+		t.Error("idle session was not closed")
+	}
+}
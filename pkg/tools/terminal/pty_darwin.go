@@ -0,0 +1,48 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a new pseudo-terminal pair.
+func openPTY() (ptmx, tty *os.File, err error) {
+	ptmx, err = os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open pseudo-terminal: %w", err)
+	}
+
+	// TIOCPTYGNAME fills a buffer of 128 bytes
+	buf := make([]byte, 128)
+	err = control(ptmx, func(fd int) error {
+		if err := unix.IoctlSetInt(fd, unix.TIOCPTYGRANT, 0); err != nil {
+			return err
+		}
+		if err := unix.IoctlSetInt(fd, unix.TIOCPTYUNLK, 0); err != nil {
+			return err
+		}
+		if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), uintptr(unix.TIOCPTYGNAME), uintptr(unsafe.Pointer(&buf[0]))); errno != 0 {
+			return errno
+		}
+		return nil
+	})
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+
+	if i := bytes.IndexByte(buf, 0); i >= 0 {
+		buf = buf[:i]
+	}
+	name := string(buf)
+	tty, err = os.OpenFile(name, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	return ptmx, tty, nil
+}
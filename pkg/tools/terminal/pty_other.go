@@ -0,0 +1,21 @@
+//go:build !linux && !darwin
+
+package terminal
+
+import (
+	"os"
+	"os/exec"
+)
+
+// supported reports whether this platform can open pseudo-terminals
+const supported = false
+
+func startPTY(cmd *exec.Cmd, rows, cols int) (*os.File, error) {
+	return nil, ErrUnsupported
+}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = cmd.Process.Kill()
+	}
+}
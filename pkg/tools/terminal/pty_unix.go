@@ -0,0 +1,79 @@
+//go:build linux || darwin
+
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// supported reports whether this platform can open pseudo-terminals
+const supported = true
+
+// startPTY starts cmd with a new pseudo-terminal of the given size as its
+// controlling terminal and stdio, and returns the terminal's master side.
+func startPTY(cmd *exec.Cmd, rows, cols int) (*os.File, error) {
+	ptmx, tty, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+
+	if err := setSize(ptmx, rows, cols); err != nil {
+		ptmx.Close()
+		return nil, err
+	}
+
+	cmd.Stdin = tty
+	cmd.Stdout = tty
+	cmd.Stderr = tty
+	// A session of its own makes the terminal the program's controlling
+	// terminal, so Ctrl-C reaches it, and lets close kill its whole group
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if err := cmd.Start(); err != nil {
+		ptmx.Close()
+		return nil, err
+	}
+	return ptmx, nil
+}
+
+// setSize sets the terminal's window size.
+func setSize(ptmx *os.File, rows, cols int) error {
+	return control(ptmx, func(fd int) error {
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: uint16(rows), Col: uint16(cols)}) //nolint:gosec // sizes are validated
+	})
+}
+
+// killProcessGroup kills the session's program and every process it started.
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	// The program leads its own process group, see startPTY
+	if err := unix.Kill(-cmd.Process.Pid, unix.SIGKILL); err != nil {
+		_ = cmd.Process.Kill()
+	}
+}
+
+// control runs fn with f's descriptor without taking it out of the runtime
+// poller, so closing f still interrupts a pending read.
+func control(f *os.File, fn func(fd int) error) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err := conn.Control(func(fd uintptr) {
+		fnErr = fn(int(fd)) //nolint:gosec // descriptors fit in an int
+	}); err != nil {
+		return err
+	}
+	if fnErr != nil {
+		return fmt.Errorf("terminal ioctl failed: %w", fnErr)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package terminal
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// ReadScreenTool reads a terminal session's output.
+type ReadScreenTool struct {
+	manager *SessionManager
+}
+
+// NewReadScreenTool creates a new read screen tool.
+func NewReadScreenTool(manager *SessionManager) *ReadScreenTool {
+	return &ReadScreenTool{
+		manager: manager,
+	}
+}
+
+// Name returns the tool name.
+func (t *ReadScreenTool) Name() string {
+	return "terminal_read_screen"
+}
+
+// Description returns the tool description.
+func (t *ReadScreenTool) Description() string {
+	return "Read the latest output of a terminal session without typing anything, optionally waiting for text to appear. Use it to check on a program that is still working."
+}
+
+// Schema returns the tool's JSON schema.
+func (t *ReadScreenTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"session": map[string]any{
+				"type":        "string",
+				"description": "Name of the terminal session",
+			},
+			"lines": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Lines of output to return, up to %d of scrollback (default: the terminal height)", DefaultScrollback),
+			},
+			"wait_for": map[string]any{
+				"type":        "string",
+				"description": "Text to wait for in the output since keys were last sent before returning (default: return immediately)",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Seconds to wait for wait_for (default: 10)",
+			},
+		},
+		[]string{"session"},
+	)
+}
+
+// readScreenInput represents the parameters for reading the screen.
+type readScreenInput struct {
+	XMLName xml.Name `xml:"arguments"`
+	Session string   `xml:"session"`
+	Lines   int      `xml:"lines"`
+	WaitFor string   `xml:"wait_for"`
+	Timeout float64  `xml:"timeout"`
+}
+
+// Execute returns the session's screen.
+func (t *ReadScreenTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input readScreenInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	if input.Session == "" {
+		return "", nil, fmt.Errorf("session name is required")
+	}
+
+	session, err := t.manager.GetSession(input.Session)
+	if err != nil {
+		return "", nil, err
+	}
+
+	note := ""
+	if input.WaitFor != "" {
+		matched := session.WaitFor(ctx, input.WaitFor, waitTimeout(input.Timeout))
+		note = waitNote(input.WaitFor, matched, input.Timeout)
+	}
+	return formatScreen(session, screenLines(session, input.Lines), note), nil, nil
+}
+
+// IsLoopBreaking returns whether this tool breaks the agent loop.
+func (t *ReadScreenTool) IsLoopBreaking() bool {
+	return false
+}
+
+// ShouldShow shows the tool while terminal sessions are open.
+func (t *ReadScreenTool) ShouldShow() bool {
+	return t.manager.HasSessions()
+}
@@ -0,0 +1,18 @@
+package terminal
+
+import (
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// NewTools returns the terminal tools, sharing manager. Only start_terminal
+// is shown to the agent until a session is open.
+func NewTools(manager *SessionManager, guard *workspace.Guard) []tools.Tool {
+	return []tools.Tool{
+		NewStartTerminalTool(manager, guard),
+		NewSendKeysTool(manager),
+		NewReadScreenTool(manager),
+		NewListTerminalsTool(manager),
+		NewCloseTerminalTool(manager),
+	}
+}
@@ -0,0 +1,241 @@
+package terminal
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseState is where the screen's escape sequence parser is
+type parseState int
+
+const (
+	stateGround     parseState = iota
+	stateEscape                // After ESC
+	stateEscapeNext            // After ESC and a charset designator, which take one more byte
+	stateCSI                   // Inside ESC [ ... final
+	stateOSC                   // Inside ESC ] ... BEL or ST
+	stateOSCEscape             // After ESC inside an OSC
+)
+
+// screen turns terminal output into a line-oriented transcript. It applies
+// carriage returns, backspaces, tabs, horizontal cursor movement and erases,
+// and drops every other escape sequence. Lines are not wrapped at the
+// terminal width.
+type screen struct {
+	lines    [][]rune
+	col      int
+	maxLines int
+	state    parseState
+	params   []byte // Parameters of the CSI sequence being parsed
+	partial  []byte // Incomplete UTF-8 sequence at the end of the last write
+}
+
+// newScreen creates a screen keeping at most maxLines lines.
+func newScreen(maxLines int) *screen {
+	return &screen{lines: [][]rune{nil}, maxLines: maxLines}
+}
+
+// Write feeds output to the screen and returns the printable text it
+// contained, line breaks included.
+func (s *screen) Write(p []byte) string {
+	var printed strings.Builder
+	for _, b := range p {
+		switch s.state {
+		case stateGround:
+			s.ground(b, &printed)
+		case stateEscape:
+			s.escape(b)
+		case stateEscapeNext:
+			s.state = stateGround
+		case stateCSI:
+			switch {
+			case b >= 0x40 && b <= 0x7e:
+				s.csi(b)
+				s.state = stateGround
+			case b >= 0x30 && b <= 0x3f:
+				s.params = append(s.params, b)
+			}
+		case stateOSC:
+			switch b {
+			case 0x07:
+				s.state = stateGround
+			case 0x1b:
+				s.state = stateOSCEscape
+			}
+		case stateOSCEscape:
+			s.state = stateGround
+		}
+	}
+	return printed.String()
+}
+
+// ground handles a byte outside escape sequences.
+func (s *screen) ground(b byte, printed *strings.Builder) {
+	if len(s.partial) > 0 || b >= utf8.RuneSelf {
+		s.partial = append(s.partial, b)
+		if !utf8.FullRune(s.partial) {
+			return
+		}
+		r, _ := utf8.DecodeRune(s.partial)
+		s.partial = s.partial[:0]
+		s.put(r)
+		printed.WriteRune(r)
+		return
+	}
+
+	switch b {
+	case 0x1b:
+		s.state = stateEscape
+	case '\r':
+		s.col = 0
+	case '\n':
+		s.newline()
+		printed.WriteByte('\n')
+	case '\b':
+		if s.col > 0 {
+			s.col--
+		}
+	case '\t':
+		for next := (s.col/8 + 1) * 8; s.col < next; {
+			s.put(' ')
+		}
+		printed.WriteByte('\t')
+	default:
+		if b >= 0x20 && b != 0x7f {
+			s.put(rune(b))
+			printed.WriteByte(b)
+		}
+	}
+}
+
+// escape handles the byte after an ESC.
+func (s *screen) escape(b byte) {
+	switch b {
+	case '[':
+		s.params = s.params[:0]
+		s.state = stateCSI
+	case ']':
+		s.state = stateOSC
+	case '(', ')', '*', '+', '#', '%':
+		s.state = stateEscapeNext
+	case 'c':
+		s.clear()
+		s.state = stateGround
+	default:
+		s.state = stateGround
+	}
+}
+
+// csi applies the CSI sequence ending in final.
+func (s *screen) csi(final byte) {
+	params := strings.TrimLeft(string(s.params), "?<=>")
+	arg := func(i, def int) int {
+		fields := strings.Split(params, ";")
+		if i >= len(fields) {
+			return def
+		}
+		n, err := strconv.Atoi(fields[i])
+		if err != nil || n == 0 {
+			return def
+		}
+		return n
+	}
+
+	line := s.current()
+	switch final {
+	case 'K': // Erase in line
+		switch arg(0, 0) {
+		case 0:
+			if s.col < len(line) {
+				s.setCurrent(line[:s.col])
+			}
+		case 1:
+			for i := 0; i <= s.col && i < len(line); i++ {
+				line[i] = ' '
+			}
+		case 2:
+			s.setCurrent(nil)
+		}
+	case 'J': // Erase in display
+		switch arg(0, 0) {
+		case 0:
+			if s.col < len(line) {
+				s.setCurrent(line[:s.col])
+			}
+		case 2, 3:
+			s.clear()
+		}
+	case 'C': // Cursor forward
+		s.col += arg(0, 1)
+	case 'D': // Cursor back
+		s.col = max(s.col-arg(0, 1), 0)
+	case 'G': // Cursor to column
+		s.col = arg(0, 1) - 1
+	case 'H', 'f': // Cursor position; only the column is followed
+		s.col = arg(1, 1) - 1
+	case 'P': // Delete characters
+		if s.col < len(line) {
+			end := min(s.col+arg(0, 1), len(line))
+			s.setCurrent(append(line[:s.col:s.col], line[end:]...))
+		}
+	case '@': // Insert blanks
+		if s.col < len(line) {
+			blanks := []rune(strings.Repeat(" ", arg(0, 1)))
+			s.setCurrent(append(append(append([]rune(nil), line[:s.col]...), blanks...), line[s.col:]...))
+		}
+	case 'X': // Erase characters
+		for i := s.col; i < s.col+arg(0, 1) && i < len(line); i++ {
+			line[i] = ' '
+		}
+	}
+}
+
+// put writes r at the cursor.
+func (s *screen) put(r rune) {
+	line := s.current()
+	for len(line) < s.col {
+		line = append(line, ' ')
+	}
+	if s.col < len(line) {
+		line[s.col] = r
+	} else {
+		line = append(line, r)
+	}
+	s.setCurrent(line)
+	s.col++
+}
+
+// newline starts a new line, dropping the oldest beyond maxLines.
+func (s *screen) newline() {
+	s.lines = append(s.lines, nil)
+	if len(s.lines) > s.maxLines {
+		s.lines = s.lines[len(s.lines)-s.maxLines:]
+	}
+	s.col = 0
+}
+
+// clear empties the screen.
+func (s *screen) clear() {
+	s.lines = [][]rune{nil}
+	s.col = 0
+}
+
+func (s *screen) current() []rune {
+	return s.lines[len(s.lines)-1]
+}
+
+func (s *screen) setCurrent(line []rune) {
+	s.lines[len(s.lines)-1] = line
+}
+
+// Tail returns the last n lines, the cursor's line last, without trailing
+// spaces.
+func (s *screen) Tail(n int) string {
+	start := max(len(s.lines)-n, 0)
+	lines := make([]string, 0, len(s.lines)-start)
+	for _, line := range s.lines[start:] {
+		lines = append(lines, strings.TrimRight(string(line), " "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,85 @@
+package terminal
+
+import "testing"
+
+func TestScreenWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			name:   "lines",
+			output: "first\r\nsecond\r\n> ",
+			want:   "first\nsecond\n>",
+		},
+		{
+			name:   "carriage return overwrites",
+			output: "progress 10%\rprogress 100%",
+			want:   "progress 100%",
+		},
+		{
+			name:   "backspace and erase to end of line",
+			output: "SELECT 2\b1\x1b[K",
+			want:   "SELECT 1",
+		},
+		{
+			name:   "colors and titles are dropped",
+			output: "\x1b]0;title\x07\x1b[1;32mok\x1b[0m done",
+			want:   "ok done",
+		},
+		{
+			name:   "clear screen",
+			output: "old\r\nstuff\x1b[H\x1b[2Jnew",
+			want:   "new",
+		},
+		{
+			name:   "delete characters",
+			output: "abcdef\x1b[4D\x1b[2P",
+			want:   "abef",
+		},
+		{
+			name:   "utf-8 split across writes",
+			output: "caf\xc3\xa9",
+			want:   "café",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScreen(100)
+			// Feed one byte at a time to cover sequences split across reads
+			for i := 0; i < len(tt.output); i++ {
+				s.Write([]byte{tt.output[i]})
+			}
+			if got := s.Tail(100); got != tt.want {
+				t.Errorf("Tail() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenScrollback(t *testing.T) {
+	s := newScreen(3)
+	s.Write([]byte("1\n2\n3\n4\n5"))
+	if got := s.Tail(10); got != "3\n4\n5" {
+		t.Errorf("Tail(10) = %q, want the last 3 lines", got)
+	}
+	if got := s.Tail(2); got != "4\n5" {
+		t.Errorf("Tail(2) = %q, want %q", got, "4\n5")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	got, err := parseKeys("Enter, ctrl-c Up C-d")
+	if err != nil {
+		t.Fatalf("parseKeys() error = %v", err)
+	}
+	if want := "\r\x03\x1b[A\x04"; got != want {
+		t.Errorf("parseKeys() = %q, want %q", got, want)
+	}
+
+	if _, err := parseKeys("Hyper"); err == nil {
+		t.Error("parseKeys() accepted an unknown key")
+	}
+}
@@ -0,0 +1,169 @@
+package terminal
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// SendKeysTool types into a terminal session.
+type SendKeysTool struct {
+	manager *SessionManager
+}
+
+// NewSendKeysTool creates a new send keys tool.
+func NewSendKeysTool(manager *SessionManager) *SendKeysTool {
+	return &SendKeysTool{
+		manager: manager,
+	}
+}
+
+// Name returns the tool name.
+func (t *SendKeysTool) Name() string {
+	return "terminal_send_keys"
+}
+
+// Description returns the tool description.
+func (t *SendKeysTool) Description() string {
+	return "Type text and keys into a terminal session started with start_terminal, then return the screen once the program has responded. " +
+		"Text is typed as is; add keys: Enter to submit a line."
+}
+
+// Schema returns the tool's JSON schema.
+func (t *SendKeysTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"session": map[string]any{
+				"type":        "string",
+				"description": "Name of the terminal session",
+			},
+			"text": map[string]any{
+				"type":        "string",
+				"description": "Text to type, sent before keys",
+			},
+			"keys": map[string]any{
+				"type":        "string",
+				"description": "Space-separated special keys to press after the text: " + keyNames,
+			},
+			"wait_for": map[string]any{
+				"type":        "string",
+				"description": "Text to wait for in the output before returning, such as the prompt (default: wait until output pauses). Typed text is echoed, so choose text the program prints",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Seconds to wait for output before returning (default: 10)",
+			},
+			"lines": map[string]any{
+				"type":        "integer",
+				"description": "Lines of output to return (default: the terminal height)",
+			},
+		},
+		[]string{"session"},
+	)
+}
+
+// sendKeysInput represents the parameters for sending keys.
+type sendKeysInput struct {
+	XMLName xml.Name `xml:"arguments"`
+	Session string   `xml:"session"`
+	Text    string   `xml:"text"`
+	Keys    string   `xml:"keys"`
+	WaitFor string   `xml:"wait_for"`
+	Timeout float64  `xml:"timeout"`
+	Lines   int      `xml:"lines"`
+}
+
+// parseInput parses and validates the tool arguments, returning the bytes
+// to send.
+func (t *SendKeysTool) parseInput(argsXML []byte) (*sendKeysInput, string, error) {
+	var input sendKeysInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, "", fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	if input.Session == "" {
+		return nil, "", fmt.Errorf("session name is required")
+	}
+	keys, err := parseKeys(input.Keys)
+	if err != nil {
+		return nil, "", err
+	}
+	if input.Text == "" && keys == "" {
+		return nil, "", fmt.Errorf("text or keys is required")
+	}
+	return &input, input.Text + keys, nil
+}
+
+// Execute sends the input and returns the screen.
+func (t *SendKeysTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	input, keys, err := t.parseInput(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	session, err := t.manager.GetSession(input.Session)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := session.Send(keys); err != nil {
+		return "", nil, err
+	}
+
+	matched := session.WaitFor(ctx, input.WaitFor, waitTimeout(input.Timeout))
+	return formatScreen(session, screenLines(session, input.Lines), waitNote(input.WaitFor, matched, input.Timeout)), nil, nil
+}
+
+// IsLoopBreaking returns whether this tool breaks the agent loop.
+func (t *SendKeysTool) IsLoopBreaking() bool {
+	return false
+}
+
+// ShouldShow shows the tool while terminal sessions are open.
+func (t *SendKeysTool) ShouldShow() bool {
+	return t.manager.HasSessions()
+}
+
+// GeneratePreview implements the Previewable interface: what is typed into a
+// shell runs like a command, so it is approved like one.
+func (t *SendKeysTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	input, _, err := t.parseInput(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "Session: %s\n", input.Session)
+	if session, err := t.manager.GetSession(input.Session); err == nil {
+		fmt.Fprintf(&content, "Program: %s\n", session.Command)
+	}
+	if input.Text != "" {
+		fmt.Fprintf(&content, "\nText: %s\n", input.Text)
+	}
+	if input.Keys != "" {
+		fmt.Fprintf(&content, "\nKeys: %s\n", input.Keys)
+	}
+
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeCommand,
+		Title:       "Send Keys to Terminal",
+		Description: fmt.Sprintf("This will type into terminal session '%s'", input.Session),
+		Content:     content.String(),
+		Metadata: map[string]any{
+			"session": input.Session,
+			"text":    input.Text,
+			"keys":    input.Keys,
+		},
+	}, nil
+}
+
+// screenLines returns the number of lines to show, defaulting to the
+// terminal height.
+func screenLines(session *Session, lines int) int {
+	if lines <= 0 {
+		return session.Rows
+	}
+	return min(lines, DefaultScrollback)
+}
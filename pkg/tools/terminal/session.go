@@ -0,0 +1,229 @@
+package terminal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnsupported is returned when this platform cannot open pseudo-terminals.
+var ErrUnsupported = errors.New("interactive terminals are not supported on this platform")
+
+// Timing of waits for output
+const (
+	// settleDelay is how long output has to pause before a program is taken
+	// to be waiting for input
+	settleDelay = 500 * time.Millisecond
+	// pollInterval is how often waits look at the output
+	pollInterval = 50 * time.Millisecond
+	// closeTimeout bounds how long Close waits for the program to die
+	closeTimeout = 5 * time.Second
+	// maxRecentBytes caps the output kept for wait_for matching
+	maxRecentBytes = 64 * 1024
+)
+
+// Session is an interactive program running behind a pseudo-terminal.
+type Session struct {
+	Name      string
+	Command   string
+	WorkDir   string
+	Rows      int
+	Cols      int
+	CreatedAt time.Time
+
+	cmd  *exec.Cmd
+	ptmx *os.File
+	done chan struct{} // Closed when the program exits
+
+	mu         sync.Mutex
+	screen     *screen
+	recent     strings.Builder // Printable output since the last input
+	lastOutput time.Time
+	lastUsed   time.Time
+	exitCode   int
+}
+
+// startSession runs opts.Command under a new pseudo-terminal.
+func startSession(name string, opts SessionOptions) (*Session, error) {
+	cmd := exec.Command("sh", "-c", opts.Command) //nolint:gosec // the command is approved by the user
+	cmd.Dir = opts.WorkDir
+	// Keep programs to plain, line-oriented output the screen can follow
+	cmd.Env = append(os.Environ(), "TERM=dumb", "NO_COLOR=1", "PAGER=cat")
+	cmd.Env = append(cmd.Env, opts.Env...)
+
+	ptmx, err := startPTY(cmd, opts.Rows, opts.Cols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %q: %w", opts.Command, err)
+	}
+
+	now := time.Now()
+	s := &Session{
+		Name:       name,
+		Command:    opts.Command,
+		WorkDir:    opts.WorkDir,
+		Rows:       opts.Rows,
+		Cols:       opts.Cols,
+		CreatedAt:  now,
+		cmd:        cmd,
+		ptmx:       ptmx,
+		done:       make(chan struct{}),
+		screen:     newScreen(opts.Scrollback),
+		lastOutput: now,
+		lastUsed:   now,
+	}
+	go s.readOutput()
+	go s.wait()
+	return s, nil
+}
+
+// readOutput feeds the program's output to the screen until the terminal is
+// closed.
+func (s *Session) readOutput() {
+	buf := make([]byte, 4096)
+	for {
+		n, err := s.ptmx.Read(buf)
+		if n > 0 {
+			s.mu.Lock()
+			printed := s.screen.Write(buf[:n])
+			if s.recent.Len()+len(printed) > maxRecentBytes {
+				// Keep the newest half so a match spanning the cut survives
+				kept := s.recent.String()
+				kept = kept[len(kept)/2:]
+				s.recent.Reset()
+				s.recent.WriteString(kept)
+			}
+			s.recent.WriteString(printed)
+			s.lastOutput = time.Now()
+			s.mu.Unlock()
+		}
+		if err != nil {
+			// Linux reports EIO once the program has closed the terminal
+			return
+		}
+	}
+}
+
+// wait records the program's exit.
+func (s *Session) wait() {
+	_ = s.cmd.Wait()
+	s.mu.Lock()
+	s.exitCode = s.cmd.ProcessState.ExitCode()
+	s.mu.Unlock()
+	close(s.done)
+}
+
+// Exited reports whether the program has exited, and its exit code when it
+// has.
+func (s *Session) Exited() (bool, int) {
+	select {
+	case <-s.done:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return true, s.exitCode
+	default:
+		return false, 0
+	}
+}
+
+// Status describes whether the program is still running.
+func (s *Session) Status() string {
+	if exited, code := s.Exited(); exited {
+		return fmt.Sprintf("exited with code %d", code)
+	}
+	return fmt.Sprintf("running, pid %d", s.cmd.Process.Pid)
+}
+
+// Send types input into the terminal. Output from before it no longer
+// counts for WaitFor.
+func (s *Session) Send(input string) error {
+	if exited, code := s.Exited(); exited {
+		return fmt.Errorf("the program in terminal %q has exited with code %d", s.Name, code)
+	}
+
+	s.mu.Lock()
+	s.recent.Reset()
+	s.lastUsed = time.Now()
+	s.mu.Unlock()
+
+	if _, err := s.ptmx.WriteString(input); err != nil {
+		return fmt.Errorf("failed to write to terminal %q: %w", s.Name, err)
+	}
+	return nil
+}
+
+// WaitFor waits until the program prints text, or until its output settles
+// when text is empty, for at most timeout. It reports whether that happened
+// before the timeout, the program's exit or ctx's cancellation.
+func (s *Session) WaitFor(ctx context.Context, text string, timeout time.Duration) bool {
+	start := time.Now()
+	deadline := start.Add(timeout)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.mu.Lock()
+		found := text != "" && strings.Contains(s.recent.String(), text)
+		quiet := time.Since(s.lastOutput) >= settleDelay
+		s.mu.Unlock()
+
+		switch {
+		case found:
+			return true
+		case text == "" && quiet && time.Since(start) >= settleDelay:
+			return true
+		case quiet && s.hasExited():
+			// Give up once the program is gone and its last output is read
+			return text == ""
+		case time.Now().After(deadline):
+			return false
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// Screen returns the last lines lines of output.
+func (s *Session) Screen(lines int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastUsed = time.Now()
+	return s.screen.Tail(lines)
+}
+
+// LastUsed returns when the agent last sent input to or read the session.
+func (s *Session) LastUsed() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastUsed
+}
+
+// Close kills the program and every process it started, and closes the
+// terminal.
+func (s *Session) Close() error {
+	killProcessGroup(s.cmd)
+	closeErr := s.ptmx.Close()
+
+	select {
+	case <-s.done:
+	case <-time.After(closeTimeout):
+		return fmt.Errorf("terminal %q: program did not exit after being killed", s.Name)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to close terminal %q: %w", s.Name, closeErr)
+	}
+	return nil
+}
+
+func (s *Session) hasExited() bool {
+	exited, _ := s.Exited()
+	return exited
+}
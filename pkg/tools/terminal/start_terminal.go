@@ -0,0 +1,214 @@
+package terminal
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/security/workspace"
+)
+
+// DefaultWaitTimeout bounds how long the tools wait for output
+const DefaultWaitTimeout = 10 * time.Second
+
+// StartTerminalTool runs an interactive program in a new terminal session.
+type StartTerminalTool struct {
+	manager *SessionManager
+	guard   *workspace.Guard
+}
+
+// NewStartTerminalTool creates a new start terminal tool.
+func NewStartTerminalTool(manager *SessionManager, guard *workspace.Guard) *StartTerminalTool {
+	return &StartTerminalTool{
+		manager: manager,
+		guard:   guard,
+	}
+}
+
+// Name returns the tool name.
+func (t *StartTerminalTool) Name() string {
+	return "start_terminal"
+}
+
+// Description returns the tool description.
+func (t *StartTerminalTool) Description() string {
+	return "Start an interactive program (database shell, debugger, REPL, or any command that prompts for input) in a pseudo-terminal session that persists across agent loop iterations. " +
+		"Drive it with terminal_send_keys and terminal_read_screen, and close it with close_terminal when done. Use execute_command instead for commands that run to completion on their own."
+}
+
+// Schema returns the tool's JSON schema.
+func (t *StartTerminalTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"session": map[string]any{
+				"type":        "string",
+				"description": "Unique name for the terminal session (e.g., 'db', 'debugger')",
+			},
+			"command": map[string]any{
+				"type":        "string",
+				"description": "Shell command that starts the program (e.g., 'psql mydb', 'dlv debug ./cmd/app', 'python3')",
+			},
+			"working_dir": map[string]any{
+				"type":        "string",
+				"description": "Working directory relative to workspace (default: workspace root)",
+			},
+			"wait_for": map[string]any{
+				"type":        "string",
+				"description": "Text to wait for in the program's output before returning, such as its prompt (default: wait until output pauses)",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Seconds to wait for output before returning (default: 10)",
+			},
+			"rows": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Terminal height in lines (default: %d)", DefaultRows),
+			},
+			"cols": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Terminal width in columns (default: %d)", DefaultCols),
+			},
+		},
+		[]string{"session", "command"},
+	)
+}
+
+// startTerminalInput represents the parameters for starting a terminal.
+type startTerminalInput struct {
+	XMLName    xml.Name `xml:"arguments"`
+	Session    string   `xml:"session"`
+	Command    string   `xml:"command"`
+	WorkingDir string   `xml:"working_dir"`
+	WaitFor    string   `xml:"wait_for"`
+	Timeout    float64  `xml:"timeout"`
+	Rows       int      `xml:"rows"`
+	Cols       int      `xml:"cols"`
+}
+
+// parseInput parses and validates the tool arguments, resolving the working
+// directory.
+func (t *StartTerminalTool) parseInput(argsXML []byte) (*startTerminalInput, string, error) {
+	var input startTerminalInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, "", fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	if input.Session == "" {
+		return nil, "", fmt.Errorf("session name is required")
+	}
+	if strings.TrimSpace(input.Command) == "" {
+		return nil, "", fmt.Errorf("command cannot be empty")
+	}
+	if input.Rows < 0 || input.Rows > 500 || input.Cols < 0 || input.Cols > 1000 {
+		return nil, "", fmt.Errorf("terminal size must be at most 500 rows by 1000 columns")
+	}
+
+	workDir := t.guard.WorkspaceDir()
+	if input.WorkingDir != "" {
+		if err := t.guard.ValidatePath(input.WorkingDir); err != nil {
+			return nil, "", fmt.Errorf("invalid working directory: %w", err)
+		}
+		absWorkDir, err := t.guard.ResolvePath(input.WorkingDir)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to resolve working directory: %w", err)
+		}
+		workDir = absWorkDir
+	}
+
+	// Programs may write anywhere below their working directory, as with
+	// execute_command
+	if err := t.guard.CheckWorkingDir(workDir); err != nil {
+		return nil, "", err
+	}
+	return &input, workDir, nil
+}
+
+// Execute starts the program and returns its first screen of output.
+func (t *StartTerminalTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	input, workDir, err := t.parseInput(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	session, err := t.manager.StartSession(input.Session, SessionOptions{
+		Command: input.Command,
+		WorkDir: workDir,
+		Rows:    input.Rows,
+		Cols:    input.Cols,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start terminal: %w", err)
+	}
+
+	matched := session.WaitFor(ctx, input.WaitFor, waitTimeout(input.Timeout))
+	metadata := map[string]any{
+		"session":     session.Name,
+		"command":     session.Command,
+		"working_dir": workDir,
+	}
+	return formatScreen(session, session.Rows, waitNote(input.WaitFor, matched, input.Timeout)), metadata, nil
+}
+
+// IsLoopBreaking returns whether this tool breaks the agent loop.
+func (t *StartTerminalTool) IsLoopBreaking() bool {
+	return false
+}
+
+// ShouldShow hides the tool where pseudo-terminals are not supported.
+func (t *StartTerminalTool) ShouldShow() bool {
+	return Supported()
+}
+
+// GeneratePreview implements the Previewable interface so starting a
+// program is approved like execute_command.
+func (t *StartTerminalTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	input, workDir, err := t.parseInput(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeCommand,
+		Title:       "Start Terminal",
+		Description: fmt.Sprintf("This will start the interactive program: %s", input.Command),
+		Content:     fmt.Sprintf("Command: %s\n\nWorking Directory: %s\n\nSession: %s\n", input.Command, workDir, input.Session),
+		Metadata: map[string]any{
+			"session":     input.Session,
+			"command":     input.Command,
+			"working_dir": workDir,
+		},
+	}, nil
+}
+
+// waitTimeout converts a timeout argument in seconds, defaulting to
+// DefaultWaitTimeout.
+func waitTimeout(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return DefaultWaitTimeout
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// waitNote explains a wait_for that did not match.
+func waitNote(waitFor string, matched bool, seconds float64) string {
+	if waitFor == "" || matched {
+		return ""
+	}
+	return fmt.Sprintf("%q did not appear within %s; the program may still be working, so read the screen again or send more keys.", waitFor, waitTimeout(seconds))
+}
+
+// formatScreen renders the last lines of a session's screen for the agent.
+func formatScreen(session *Session, lines int, note string) string {
+	var result strings.Builder
+	fmt.Fprintf(&result, "Terminal %q (%s): %s\n", session.Name, session.Status(), session.Command)
+	if note != "" {
+		result.WriteString(note)
+		result.WriteString("\n")
+	}
+	fmt.Fprintf(&result, "\nScreen (last %d lines):\n", lines)
+	result.WriteString(session.Screen(lines))
+	return result.String()
+}
@@ -0,0 +1,81 @@
+package todo
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/entrhq/forge/pkg/agent/todo"
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// CreateTodoListTool lays out the agent's plan as an ordered checklist.
+type CreateTodoListTool struct {
+	list *todo.List
+}
+
+// NewCreateTodoListTool creates a new CreateTodoListTool.
+func NewCreateTodoListTool(list *todo.List) *CreateTodoListTool {
+	return &CreateTodoListTool{
+		list: list,
+	}
+}
+
+// Name returns the tool name.
+func (t *CreateTodoListTool) Name() string {
+	return "create_todo_list"
+}
+
+// Description returns the tool description.
+func (t *CreateTodoListTool) Description() string {
+	return "Create a todo list of the ordered steps you plan to take for a multi-step task, replacing any existing list. The user sees it as a live checklist. Mark progress with update_todo."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *CreateTodoListTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"steps": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "string",
+				},
+				"description": fmt.Sprintf("Ordered steps, each a short imperative sentence (max %d characters)", todo.MaxContentLength),
+				"minItems":    1,
+				"maxItems":    todo.MaxItems,
+			},
+		},
+		[]string{"steps"},
+	)
+}
+
+// Execute replaces the todo list.
+func (t *CreateTodoListTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Steps   []string `xml:"steps>step"`
+	}
+
+	if err := xml.Unmarshal(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if len(input.Steps) == 0 {
+		return "", nil, fmt.Errorf("missing required parameter: steps (at least 1 step required)")
+	}
+
+	if err := t.list.Set(input.Steps); err != nil {
+		return "", nil, err
+	}
+
+	items := t.list.Items()
+	metadata := map[string]any{
+		todo.MetadataKey: items,
+	}
+	return todo.Format(items), metadata, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *CreateTodoListTool) IsLoopBreaking() bool {
+	return false
+}
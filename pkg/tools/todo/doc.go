@@ -0,0 +1,21 @@
+// Package todo provides the tools the agent plans a task with.
+//
+// The agent lays out the steps of a multi-step task with create_todo_list and
+// marks its progress with update_todo. Both tools return the whole checklist,
+// and put a snapshot of it in their result metadata under todo.MetadataKey so
+// executors can show the plan as it evolves: the TUI renders a live checklist
+// panel and headless runs include the final list in summary.md.
+//
+// Tool Overview:
+//
+// create_todo_list: Replace the plan with an ordered list of pending steps
+//
+// update_todo: Set a step's status (pending, in_progress, completed, or
+// cancelled) and optionally reword it
+//
+// Usage Example:
+//
+//	list := todo.NewList()
+//	registry.Register(todotools.NewCreateTodoListTool(list))
+//	registry.Register(todotools.NewUpdateTodoTool(list))
+package todo
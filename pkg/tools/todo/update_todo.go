@@ -0,0 +1,101 @@
+package todo
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/todo"
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+// UpdateTodoTool marks progress on a step of the todo list.
+type UpdateTodoTool struct {
+	list *todo.List
+}
+
+// NewUpdateTodoTool creates a new UpdateTodoTool.
+func NewUpdateTodoTool(list *todo.List) *UpdateTodoTool {
+	return &UpdateTodoTool{
+		list: list,
+	}
+}
+
+// Name returns the tool name.
+func (t *UpdateTodoTool) Name() string {
+	return "update_todo"
+}
+
+// Description returns the tool description.
+func (t *UpdateTodoTool) Description() string {
+	return "Update the status of a step on the todo list: mark it in_progress when you start it and completed when it is done, or cancelled when it is no longer needed. Optionally reword the step."
+}
+
+// Schema returns the JSON schema for the tool's input parameters.
+func (t *UpdateTodoTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"step": map[string]any{
+				"type":        "integer",
+				"description": "Number of the step to update, starting at 1",
+			},
+			"status": map[string]any{
+				"type":        "string",
+				"enum":        []string{string(todo.StatusPending), string(todo.StatusInProgress), string(todo.StatusCompleted), string(todo.StatusCancelled)},
+				"description": "New status of the step",
+			},
+			"content": map[string]any{
+				"type":        "string",
+				"description": "New wording for the step (optional)",
+			},
+		},
+		[]string{"step", "status"},
+	)
+}
+
+// Execute updates a step.
+func (t *UpdateTodoTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	var input struct {
+		XMLName xml.Name `xml:"arguments"`
+		Step    string   `xml:"step"`
+		Status  string   `xml:"status"`
+		Content string   `xml:"content"`
+	}
+
+	if err := xml.Unmarshal(argsXML, &input); err != nil {
+		return "", nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if strings.TrimSpace(input.Step) == "" {
+		return "", nil, fmt.Errorf("missing required parameter: step")
+	}
+	step, err := strconv.Atoi(strings.TrimSpace(input.Step))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid step: %s (must be a step number)", input.Step)
+	}
+
+	if input.Status == "" {
+		return "", nil, fmt.Errorf("missing required parameter: status")
+	}
+	status, err := todo.ParseStatus(input.Status)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if _, err := t.list.Update(step, status, input.Content); err != nil {
+		return "", nil, err
+	}
+
+	items := t.list.Items()
+	metadata := map[string]any{
+		todo.MetadataKey: items,
+	}
+	return todo.Format(items), metadata, nil
+}
+
+// IsLoopBreaking returns false as this tool doesn't break the agent loop.
+func (t *UpdateTodoTool) IsLoopBreaking() bool {
+	return false
+}
@@ -0,0 +1,59 @@
+package todo
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/todo"
+)
+
+func TestTodoTools(t *testing.T) {
+	list := todo.NewList()
+	create := NewCreateTodoListTool(list)
+	update := NewUpdateTodoTool(list)
+	ctx := context.Background()
+
+	result, metadata, err := create.Execute(ctx, []byte(`<arguments><steps><step>Read the handler</step><step>Fix the bug</step></steps></arguments>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, "○ 2. Fix the bug") {
+		t.Errorf("result = %s", result)
+	}
+	if items, ok := metadata[todo.MetadataKey].([]todo.Item); !ok || len(items) != 2 {
+		t.Errorf("metadata = %+v", metadata)
+	}
+
+	result, metadata, err = update.Execute(ctx, []byte(`<arguments><step>1</step><status>completed</status></arguments>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, "(1/2 done)") {
+		t.Errorf("result = %s", result)
+	}
+	items := metadata[todo.MetadataKey].([]todo.Item)
+	if items[0].Status != todo.StatusCompleted {
+		t.Errorf("items = %+v", items)
+	}
+}
+
+func TestUpdateTodoTool_InvalidArguments(t *testing.T) {
+	list := todo.NewList()
+	if err := list.Set([]string{"step"}); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewUpdateTodoTool(list)
+
+	for _, args := range []string{
+		`<arguments><status>completed</status></arguments>`,
+		`<arguments><step>one</step><status>completed</status></arguments>`,
+		`<arguments><step>1</step></arguments>`,
+		`<arguments><step>1</step><status>finished</status></arguments>`,
+		`<arguments><step>5</step><status>completed</status></arguments>`,
+	} {
+		if _, _, err := tool.Execute(context.Background(), []byte(args)); err == nil {
+			t.Errorf("expected an error for %s", args)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// Package web provides tools that read the web over plain HTTP, without
+// browser automation.
+package web
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/config"
+	"golang.org/x/net/html"
+)
+
+const (
+	// fetchTimeout bounds a single fetch_url request, including redirects
+	fetchTimeout = 30 * time.Second
+	// maxFetchBytes caps how much of a response body is read
+	maxFetchBytes = 5 * 1024 * 1024
+	// maxRedirects caps how many redirects a fetch follows
+	maxRedirects = 10
+
+	defaultMaxLength = 10000
+	minMaxLength     = 100
+	maxMaxLength     = 100000
+)
+
+// Output formats for HTML pages
+const (
+	formatMarkdown = "markdown"
+	formatText     = "text"
+)
+
+// FetchURLTool reads a web page with a plain GET request and returns its main
+// content as markdown or text. It needs neither Playwright nor
+// browser_enabled; JavaScript is not run, so pages rendered client-side may
+// be incomplete. It cannot reach loopback, private or link-local addresses,
+// such as a cloud metadata service, unless their host is one http_request is
+// allowed to reach.
+type FetchURLTool struct {
+	client *http.Client
+}
+
+// NewFetchURLTool creates a new fetch URL tool.
+func NewFetchURLTool() *FetchURLTool {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialPublic
+	return &FetchURLTool{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   fetchTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// dialPublic connects to addr unless it resolves to an internal address and
+// its host is not in the http_request allow-list. The check is made on the
+// address actually dialed, so redirects and DNS answers cannot get around it.
+func dialPublic(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	host, port, err := net.SplitHostPort(addr)
+	if err == nil && config.IsHTTPHostAllowed(&url.URL{Scheme: "http", Host: net.JoinHostPort(host, port)}) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	dialer.Control = func(network, address string, _ syscall.RawConn) error {
+		ipHost, _, splitErr := net.SplitHostPort(address)
+		if splitErr != nil {
+			return splitErr
+		}
+		if ip, parseErr := netip.ParseAddr(ipHost); parseErr == nil && isInternalAddr(ip) {
+			return fmt.Errorf("%s resolves to the internal address %s; add it to http_allowed_hosts in the security settings to allow it", host, ip)
+		}
+		return nil
+	}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// isInternalAddr reports whether ip is on this machine, a private network or
+// a link-local network
+func isInternalAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// Name returns the tool name.
+func (t *FetchURLTool) Name() string {
+	return "fetch_url"
+}
+
+// Description returns the tool description.
+func (t *FetchURLTool) Description() string {
+	return "Fetch a web page with a plain HTTP GET and return its main content as markdown (default) or plain text. " +
+		"Navigation, headers, footers and scripts are stripped. Does not run JavaScript, so pages rendered client-side may be incomplete. " +
+		"Prefer this over a browser session for reading documentation, articles and API responses. " +
+		"Private and link-local network addresses cannot be fetched."
+}
+
+// Schema returns the tool's JSON schema.
+func (t *FetchURLTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "The http or https URL to fetch",
+			},
+			"format": map[string]any{
+				"type":        "string",
+				"description": "Output format for HTML pages: 'markdown' (default) or 'text'",
+			},
+			"max_length": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum content length in characters (%d-%d). Default: %d", minMaxLength, maxMaxLength, defaultMaxLength),
+			},
+		},
+		[]string{"url"},
+	)
+}
+
+// FetchURLInput represents the parameters for fetching a URL.
+type FetchURLInput struct {
+	XMLName   xml.Name `xml:"arguments"`
+	URL       string   `xml:"url"`
+	Format    string   `xml:"format"`
+	MaxLength *int     `xml:"max_length"`
+}
+
+// Execute fetches the URL.
+func (t *FetchURLTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	input, err := t.parseInput(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	maxLength := defaultMaxLength
+	if input.MaxLength != nil {
+		maxLength = *input.MaxLength
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, input.URL, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Forge/1.0 (+https://github.com/entrhq/forge)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain,application/json;q=0.9,*/*;q=0.5")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && urlErr.Timeout() {
+			return "", nil, fmt.Errorf("failed to fetch %s: timed out after %s", input.URL, fetchTimeout)
+		}
+		return "", nil, fmt.Errorf("failed to fetch %s: %w", input.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", nil, fmt.Errorf("failed to fetch %s: %s", input.URL, resp.Status)
+	}
+
+	// Read one byte past the cap to tell whether the body was cut off
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes+1))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	bodyTruncated := len(body) > maxFetchBytes
+	if bodyTruncated {
+		body = body[:maxFetchBytes]
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+	}
+
+	var content, title string
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		doc, parseErr := html.Parse(strings.NewReader(string(body)))
+		if parseErr != nil {
+			return "", nil, fmt.Errorf("failed to parse HTML: %w", parseErr)
+		}
+		title = documentTitle(doc)
+		content = renderDocument(doc, resp.Request.URL, input.Format == formatText)
+	case strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml"):
+		content = string(body)
+	default:
+		return "", nil, fmt.Errorf("unsupported content type %s; fetch_url only returns text content", mediaType)
+	}
+	totalLength := len(content)
+	content = truncateContent(content, maxLength)
+
+	var details strings.Builder
+	if title != "" {
+		fmt.Fprintf(&details, "- Title: %s\n", title)
+	}
+	fmt.Fprintf(&details, "- Content-Type: %s\n", mediaType)
+	fmt.Fprintf(&details, "- Format: %s\n", input.Format)
+	fmt.Fprintf(&details, "- Length: %d characters", totalLength)
+	if bodyTruncated {
+		fmt.Fprintf(&details, "\n- Note: response exceeded %d MB; only the start was read", maxFetchBytes/(1024*1024))
+	}
+
+	result := fmt.Sprintf("Fetched %s\n\nFetch Details:\n%s\n\n---\n\n%s", resp.Request.URL, details.String(), content)
+
+	metadata := map[string]any{
+		"url":          resp.Request.URL.String(),
+		"status":       resp.StatusCode,
+		"content_type": mediaType,
+		"title":        title,
+		"truncated":    bodyTruncated || totalLength > maxLength,
+	}
+	return result, metadata, nil
+}
+
+// parseInput parses and validates the XML input parameters.
+func (t *FetchURLTool) parseInput(argsXML []byte) (*FetchURLInput, error) {
+	var input FetchURLInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	input.URL = strings.TrimSpace(input.URL)
+	if input.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	parsed, err := url.Parse(input.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("url must be an absolute http or https URL")
+	}
+
+	switch input.Format {
+	case "":
+		input.Format = formatMarkdown
+	case formatMarkdown, formatText:
+	default:
+		return nil, fmt.Errorf("invalid format: %s (must be 'markdown' or 'text')", input.Format)
+	}
+
+	if input.MaxLength != nil && (*input.MaxLength < minMaxLength || *input.MaxLength > maxMaxLength) {
+		return nil, fmt.Errorf("max_length must be between %d and %d", minMaxLength, maxMaxLength)
+	}
+
+	return &input, nil
+}
+
+// truncateContent limits content to maxLength bytes with a note, without
+// splitting a UTF-8 sequence.
+func truncateContent(content string, maxLength int) string {
+	if len(content) <= maxLength {
+		return content
+	}
+	cut := maxLength
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return content[:cut] + fmt.Sprintf("\n\n[Content truncated: %d of %d characters shown]", cut, len(content))
+}
+
+// GeneratePreview generates a concise one-line preview of the fetch operation.
+func (t *FetchURLTool) GeneratePreview(argsXML []byte) (string, error) {
+	input, err := t.parseInput(argsXML)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Fetch %s", input.URL), nil
+}
+
+// IsLoopBreaking returns whether this tool breaks the agent loop.
+func (t *FetchURLTool) IsLoopBreaking() bool {
+	return false
+}
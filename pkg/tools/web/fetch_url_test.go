@@ -0,0 +1,148 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPage = `<html>
+<head><title>Test Page</title><script>var hidden = 1;</script></head>
+<body>
+  <nav><a href="/">Home</a> <a href="/docs">Docs</a></nav>
+  <header><p>Site banner</p></header>
+  <main>
+    <h1>Heading</h1>
+    <p>First   paragraph with <a href="/link">a link</a> and <code>inline()</code>.</p>
+    <div><p>Nested <strong>bold</strong> paragraph</p></div>
+  </main>
+  <footer>Copyright</footer>
+</body>
+</html>`
+
+func fetchArgs(url, extra string) []byte {
+	return fmt.Appendf(nil, "<arguments><url>%s</url>%s</arguments>", url, extra)
+}
+
+func TestFetchURLTool_Markdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, testPage)
+	}))
+	defer server.Close()
+
+	tool := NewFetchURLTool()
+	result, metadata, err := tool.Execute(context.Background(), fetchArgs(server.URL, ""))
+	require.NoError(t, err)
+
+	assert.Contains(t, result, "- Title: Test Page")
+	assert.Contains(t, result, "# Heading\n\nFirst paragraph with ["+"a link]("+server.URL+"/link) and `inline()`.\n\nNested **bold** paragraph")
+	for _, boilerplate := range []string{"hidden", "Home", "Site banner", "Copyright"} {
+		assert.NotContains(t, result, boilerplate)
+	}
+	assert.Equal(t, "text/html", metadata["content_type"])
+	assert.Equal(t, http.StatusOK, metadata["status"])
+	assert.Equal(t, false, metadata["truncated"])
+}
+
+func TestFetchURLTool_TextFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, testPage)
+	}))
+	defer server.Close()
+
+	tool := NewFetchURLTool()
+	result, _, err := tool.Execute(context.Background(), fetchArgs(server.URL, "<format>text</format>"))
+	require.NoError(t, err)
+
+	assert.Contains(t, result, "Heading\n\nFirst paragraph with a link and inline().\n\nNested bold paragraph")
+	assert.NotContains(t, result, "#")
+}
+
+func TestFetchURLTool_PlainTextTruncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, strings.Repeat("x", 500))
+	}))
+	defer server.Close()
+
+	tool := NewFetchURLTool()
+	result, metadata, err := tool.Execute(context.Background(), fetchArgs(server.URL, "<max_length>100</max_length>"))
+	require.NoError(t, err)
+
+	assert.Contains(t, result, "[Content truncated: 100 of 500 characters shown]")
+	assert.Equal(t, true, metadata["truncated"])
+}
+
+func TestFetchURLTool_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte{0x89, 'P', 'N', 'G'})
+		}
+	}))
+	defer server.Close()
+
+	tool := NewFetchURLTool()
+	tests := []struct {
+		name string
+		args []byte
+		want string
+	}{
+		{"missing url", []byte("<arguments></arguments>"), "url is required"},
+		{"relative url", fetchArgs("/docs", ""), "absolute http or https"},
+		{"unsupported scheme", fetchArgs("file:///etc/passwd", ""), "absolute http or https"},
+		{"invalid format", fetchArgs(server.URL, "<format>html</format>"), "invalid format"},
+		{"max length out of range", fetchArgs(server.URL, "<max_length>10</max_length>"), "max_length must be between"},
+		{"not found", fetchArgs(server.URL+"/missing", ""), "404"},
+		{"binary content", fetchArgs(server.URL+"/image", ""), "unsupported content type image/png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tool.Execute(context.Background(), tt.args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestFetchURLTool_RefusesInternalAddresses(t *testing.T) {
+	// The test server is on 127.0.0.1, which the default allow-list permits,
+	// so it can redirect to addresses that are not
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	tool := NewFetchURLTool()
+	for _, target := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/",
+		"http://[fd00::1]/",
+		server.URL + "/redirect",
+	} {
+		t.Run(target, func(t *testing.T) {
+			_, _, err := tool.Execute(context.Background(), fetchArgs(target, ""))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "internal address")
+		})
+	}
+}
+
+func TestTruncateContent_KeepsRunesWhole(t *testing.T) {
+	content := strings.Repeat("é", 100) // two bytes each
+	truncated := truncateContent(content, 101)
+	assert.True(t, strings.HasPrefix(truncated, strings.Repeat("é", 50)+"\n\n"))
+	assert.Contains(t, truncated, "100 of 200")
+}
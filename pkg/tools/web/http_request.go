@@ -0,0 +1,321 @@
+package web
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/entrhq/forge/pkg/config"
+)
+
+const (
+	// defaultRequestTimeout bounds an http_request call without a timeout
+	defaultRequestTimeout = 30 * time.Second
+	// maxRequestTimeout is the longest timeout an http_request call may set
+	maxRequestTimeout = 5 * time.Minute
+	// maxRequestBodyBytes caps the request body the model may send
+	maxRequestBodyBytes = 1024 * 1024
+)
+
+// httpMethods are the methods http_request accepts
+var httpMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// HTTPRequestTool sends an arbitrary HTTP request and returns the raw
+// response, for exercising APIs. Requests may only go to the hosts allowed in
+// the security settings, which default to this machine. Redirects are
+// returned rather than followed, so a redirect cannot leave the allow-list.
+type HTTPRequestTool struct {
+	client *http.Client
+}
+
+// NewHTTPRequestTool creates a new HTTP request tool.
+func NewHTTPRequestTool() *HTTPRequestTool {
+	return &HTTPRequestTool{
+		client: &http.Client{
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Name returns the tool name.
+func (t *HTTPRequestTool) Name() string {
+	return "http_request"
+}
+
+// Description returns the tool description.
+func (t *HTTPRequestTool) Description() string {
+	return "Send an HTTP request and return the response status, headers and body. Use it to exercise an API you are working on instead of running curl. " +
+		"Only hosts allowed in the security settings can be reached (by default localhost). Redirects are returned, not followed."
+}
+
+// Schema returns the tool's JSON schema.
+func (t *HTTPRequestTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"url": map[string]any{
+				"type":        "string",
+				"description": "The http or https URL to send the request to, including any query string",
+			},
+			"method": map[string]any{
+				"type":        "string",
+				"description": "HTTP method. Default: GET",
+				"enum":        httpMethods,
+			},
+			"headers": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "string",
+				},
+				"description": "Request headers, each as 'Name: value'",
+			},
+			"body": map[string]any{
+				"type":        "string",
+				"description": "Request body, sent verbatim. Set a Content-Type header to match it",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": fmt.Sprintf("Timeout in seconds (max %d). Default: %d", int(maxRequestTimeout.Seconds()), int(defaultRequestTimeout.Seconds())),
+			},
+			"max_length": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum response body length in characters (%d-%d). Default: %d", minMaxLength, maxMaxLength, defaultMaxLength),
+			},
+		},
+		[]string{"url"},
+	)
+}
+
+// HTTPRequestInput represents the parameters for an HTTP request.
+type HTTPRequestInput struct {
+	XMLName   xml.Name `xml:"arguments"`
+	URL       string   `xml:"url"`
+	Method    string   `xml:"method"`
+	Headers   []string `xml:"headers>header"`
+	Body      string   `xml:"body"`
+	Timeout   float64  `xml:"timeout"`
+	MaxLength *int     `xml:"max_length"`
+
+	header http.Header
+	target *url.URL
+}
+
+// Execute sends the request.
+func (t *HTTPRequestTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	input, err := t.parseInput(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+	if !config.IsHTTPHostAllowed(input.target) {
+		return "", nil, fmt.Errorf("host %s is not in the allowed hosts (http_allowed_hosts in the security settings); ask the user to add it", input.target.Host)
+	}
+
+	timeout := defaultRequestTimeout
+	if input.Timeout > 0 {
+		timeout = time.Duration(input.Timeout * float64(time.Second))
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if input.Body != "" {
+		body = strings.NewReader(input.Body)
+	}
+	req, err := http.NewRequestWithContext(reqCtx, input.Method, input.target.String(), body)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid request: %w", err)
+	}
+	req.Header = input.header
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", "Forge/1.0 (+https://github.com/entrhq/forge)")
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	start := time.Now()
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if errors.Is(reqCtx.Err(), context.DeadlineExceeded) {
+			return "", nil, fmt.Errorf("%s %s timed out after %s", input.Method, input.target, timeout)
+		}
+		return "", nil, fmt.Errorf("%s %s failed: %w", input.Method, input.target, err)
+	}
+	defer resp.Body.Close()
+
+	// Read one byte past the cap to tell whether the body was cut off
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes+1))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	duration := time.Since(start)
+	bodyTruncated := len(respBody) > maxFetchBytes
+	if bodyTruncated {
+		respBody = respBody[:maxFetchBytes]
+	}
+
+	maxLength := defaultMaxLength
+	if input.MaxLength != nil {
+		maxLength = *input.MaxLength
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "%s %s\n\n%s %s (%d ms)\n", input.Method, input.target, resp.Proto, resp.Status, duration.Milliseconds())
+	result.WriteString(formatHeaders(resp.Header))
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "" && len(respBody) > 0 {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(respBody))
+	}
+	contentTruncated := false
+	switch {
+	case len(respBody) == 0:
+		result.WriteString("\n(empty body)")
+	case isTextMediaType(mediaType):
+		content := string(respBody)
+		contentTruncated = len(content) > maxLength
+		result.WriteString("\n")
+		result.WriteString(truncateContent(content, maxLength))
+		if bodyTruncated {
+			fmt.Fprintf(&result, "\n\n[Response exceeded %d MB; only the start was read]", maxFetchBytes/(1024*1024))
+		}
+	default:
+		fmt.Fprintf(&result, "\n(%d bytes of %s not shown)", len(respBody), mediaType)
+	}
+
+	metadata := map[string]any{
+		"method":       input.Method,
+		"url":          input.target.String(),
+		"status":       resp.StatusCode,
+		"content_type": mediaType,
+		"duration_ms":  duration.Milliseconds(),
+		"truncated":    bodyTruncated || contentTruncated,
+	}
+	return result.String(), metadata, nil
+}
+
+// parseInput parses and validates the XML input parameters.
+func (t *HTTPRequestTool) parseInput(argsXML []byte) (*HTTPRequestInput, error) {
+	var input HTTPRequestInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	input.URL = strings.TrimSpace(input.URL)
+	if input.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	target, err := url.Parse(input.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("url must be an absolute http or https URL")
+	}
+	input.target = target
+
+	input.Method = strings.ToUpper(strings.TrimSpace(input.Method))
+	if input.Method == "" {
+		input.Method = http.MethodGet
+	}
+	if !slices.Contains(httpMethods, input.Method) {
+		return nil, fmt.Errorf("invalid method: %s (must be one of %s)", input.Method, strings.Join(httpMethods, ", "))
+	}
+
+	input.header = make(http.Header)
+	for _, line := range input.Headers {
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header %q: expected 'Name: value'", line)
+		}
+		input.header.Add(name, strings.TrimSpace(value))
+	}
+
+	if len(input.Body) > maxRequestBodyBytes {
+		return nil, fmt.Errorf("body exceeds %d bytes", maxRequestBodyBytes)
+	}
+	if input.Timeout < 0 || time.Duration(input.Timeout*float64(time.Second)) > maxRequestTimeout {
+		return nil, fmt.Errorf("timeout must be between 0 and %d seconds", int(maxRequestTimeout.Seconds()))
+	}
+	if input.MaxLength != nil && (*input.MaxLength < minMaxLength || *input.MaxLength > maxMaxLength) {
+		return nil, fmt.Errorf("max_length must be between %d and %d", minMaxLength, maxMaxLength)
+	}
+
+	return &input, nil
+}
+
+// GeneratePreview implements tools.Previewable, so requests, which may change
+// state on the server, go through approval.
+func (t *HTTPRequestTool) GeneratePreview(ctx context.Context, argsXML []byte) (*tools.ToolPreview, error) {
+	input, err := t.parseInput(argsXML)
+	if err != nil {
+		return nil, err
+	}
+
+	// Don't ask for approval of a request the allow-list will reject
+	if !config.IsHTTPHostAllowed(input.target) {
+		return nil, fmt.Errorf("host %s is not in the allowed hosts", input.target.Host)
+	}
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "%s %s\n", input.Method, input.target)
+	content.WriteString(formatHeaders(input.header))
+	if input.Body != "" {
+		content.WriteString("\n")
+		content.WriteString(truncateContent(input.Body, defaultMaxLength))
+	}
+
+	return &tools.ToolPreview{
+		Type:        tools.PreviewTypeCommand,
+		Title:       fmt.Sprintf("%s %s", input.Method, input.target.Host),
+		Description: fmt.Sprintf("Send an HTTP %s request to %s", input.Method, input.target),
+		Content:     strings.TrimSuffix(content.String(), "\n"),
+		Metadata: map[string]any{
+			"method": input.Method,
+			"url":    input.target.String(),
+		},
+	}, nil
+}
+
+// IsLoopBreaking returns whether this tool breaks the agent loop.
+func (t *HTTPRequestTool) IsLoopBreaking() bool {
+	return false
+}
+
+// formatHeaders renders headers one per line, sorted by name.
+func formatHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+	return b.String()
+}
+
+// isTextMediaType reports whether a response of mediaType is shown as text.
+func isTextMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "json") ||
+		strings.HasSuffix(mediaType, "xml") ||
+		mediaType == "application/x-www-form-urlencoded" ||
+		mediaType == "application/javascript"
+}
@@ -0,0 +1,127 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requestArgs(url, extra string) []byte {
+	return fmt.Appendf(nil, "<arguments><url>%s</url>%s</arguments>", url, extra)
+}
+
+func TestHTTPRequestTool_Post(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "42")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"method":%q,"auth":%q,"body":%q}`, r.Method, r.Header.Get("Authorization"), body)
+	}))
+	defer server.Close()
+
+	tool := NewHTTPRequestTool()
+	args := requestArgs(server.URL+"/users", `<method>post</method><headers><header>Authorization: Bearer token</header><header>Content-Type: application/json</header></headers><body>{"name":"a &amp; b"}</body>`)
+	result, metadata, err := tool.Execute(context.Background(), args)
+	require.NoError(t, err)
+
+	assert.Contains(t, result, "POST "+server.URL+"/users")
+	assert.Contains(t, result, "201 Created")
+	assert.Contains(t, result, "X-Request-Id: 42")
+	assert.Contains(t, result, `{"method":"POST","auth":"Bearer token","body":"{\"name\":\"a & b\"}"}`)
+	assert.Equal(t, http.StatusCreated, metadata["status"])
+	assert.Equal(t, "application/json", metadata["content_type"])
+	assert.Equal(t, false, metadata["truncated"])
+}
+
+func TestHTTPRequestTool_ErrorStatusAndRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "https://example.com/", http.StatusFound)
+			return
+		}
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	tool := NewHTTPRequestTool()
+
+	// Error statuses are results, not failures, when testing an API
+	result, metadata, err := tool.Execute(context.Background(), requestArgs(server.URL+"/missing", ""))
+	require.NoError(t, err)
+	assert.Contains(t, result, "404 Not Found")
+	assert.Contains(t, result, "not found")
+	assert.Equal(t, http.StatusNotFound, metadata["status"])
+
+	result, _, err = tool.Execute(context.Background(), requestArgs(server.URL+"/old", ""))
+	require.NoError(t, err)
+	assert.Contains(t, result, "302 Found")
+	assert.Contains(t, result, "Location: https://example.com/")
+}
+
+func TestHTTPRequestTool_Truncation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, strings.Repeat("x", 500))
+	}))
+	defer server.Close()
+
+	result, metadata, err := NewHTTPRequestTool().Execute(context.Background(), requestArgs(server.URL, "<max_length>100</max_length>"))
+	require.NoError(t, err)
+	assert.Contains(t, result, "[Content truncated: 100 of 500 characters shown]")
+	assert.Equal(t, true, metadata["truncated"])
+}
+
+func TestHTTPRequestTool_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	_, _, err := NewHTTPRequestTool().Execute(context.Background(), requestArgs(server.URL, "<timeout>0.1</timeout>"))
+	assert.ErrorContains(t, err, "timed out")
+}
+
+func TestHTTPRequestTool_HostNotAllowed(t *testing.T) {
+	tool := NewHTTPRequestTool()
+	_, _, err := tool.Execute(context.Background(), requestArgs("https://example.com/api", ""))
+	assert.ErrorContains(t, err, "not in the allowed hosts")
+
+	_, err = tool.GeneratePreview(context.Background(), requestArgs("https://example.com/api", ""))
+	assert.Error(t, err)
+}
+
+func TestHTTPRequestTool_InvalidInput(t *testing.T) {
+	tool := NewHTTPRequestTool()
+	for name, args := range map[string][]byte{
+		"missing url":  []byte("<arguments></arguments>"),
+		"relative url": requestArgs("/api", ""),
+		"bad method":   requestArgs("http://localhost/", "<method>TRACE</method>"),
+		"bad header":   requestArgs("http://localhost/", "<headers><header>no colon</header></headers>"),
+		"long timeout": requestArgs("http://localhost/", "<timeout>3600</timeout>"),
+	} {
+		_, _, err := tool.Execute(context.Background(), args)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestHTTPRequestTool_Preview(t *testing.T) {
+	var tool tools.Tool = NewHTTPRequestTool()
+	previewable, ok := tool.(tools.Previewable)
+	require.True(t, ok)
+
+	preview, err := previewable.GeneratePreview(context.Background(), requestArgs("http://localhost:8080/users/1", `<method>DELETE</method><headers><header>Authorization: Bearer token</header></headers>`))
+	require.NoError(t, err)
+	assert.Equal(t, "DELETE localhost:8080", preview.Title)
+	assert.Equal(t, "DELETE http://localhost:8080/users/1\nAuthorization: Bearer token", preview.Content)
+}
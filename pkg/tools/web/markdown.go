@@ -0,0 +1,442 @@
+package web
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// skippedElements never contribute content: scripts, media, forms and page
+// chrome such as navigation and footers.
+var skippedElements = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true, "template": true,
+	"iframe": true, "object": true, "embed": true, "svg": true, "canvas": true,
+	"video": true, "audio": true, "nav": true, "footer": true, "aside": true,
+	"form": true, "button": true, "select": true, "input": true, "textarea": true,
+	"dialog": true,
+}
+
+// skippedRoles are ARIA landmark roles for page chrome
+var skippedRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true,
+	"search": true, "dialog": true, "alert": true,
+}
+
+// Placeholders survive whitespace cleanup and are expanded at the end:
+// codePlaceholder marks a preformatted block by index and indentMarker one
+// level of list nesting.
+const (
+	codePlaceholder = "\x00%d\x00"
+	indentMarker    = "\x01"
+)
+
+var (
+	spaceRun        = regexp.MustCompile(`\s+`)
+	spaceAroundLine = regexp.MustCompile(` *\n *`)
+	blankLines      = regexp.MustCompile(`\n{3,}`)
+	codeRef         = regexp.MustCompile(`\x00(\d+)\x00`)
+)
+
+// documentTitle returns the text of the document's <title>
+func documentTitle(doc *html.Node) string {
+	if n := findElement(doc, func(n *html.Node) bool { return n.Data == "title" }); n != nil {
+		return strings.TrimSpace(spaceRun.ReplaceAllString(textContent(n), " "))
+	}
+	return ""
+}
+
+// renderDocument converts the main content of an HTML document to markdown,
+// or to plain text when plain is set. Boilerplate is dropped, and the page's
+// <main> element (or its only <article>) is used when it has one. Links are
+// resolved against base.
+func renderDocument(doc *html.Node, base *url.URL, plain bool) string {
+	c := &converter{base: base, plain: plain}
+	out := c.render(mainContent(doc))
+
+	out = spaceAroundLine.ReplaceAllString(out, "\n")
+	out = blankLines.ReplaceAllString(out, "\n\n")
+	out = strings.TrimSpace(out)
+	out = strings.ReplaceAll(out, indentMarker, "  ")
+	return codeRef.ReplaceAllStringFunc(out, func(ref string) string {
+		i, _ := strconv.Atoi(strings.Trim(ref, "\x00"))
+		return c.code[i]
+	})
+}
+
+// mainContent picks the element holding the page's primary content
+func mainContent(doc *html.Node) *html.Node {
+	if n := findElement(doc, func(n *html.Node) bool {
+		return n.Data == "main" || attr(n, "role") == "main"
+	}); n != nil {
+		return n
+	}
+
+	var articles []*html.Node
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "article" {
+			articles = append(articles, n)
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(doc)
+	if len(articles) == 1 {
+		return articles[0]
+	}
+
+	if body := findElement(doc, func(n *html.Node) bool { return n.Data == "body" }); body != nil {
+		return body
+	}
+	return doc
+}
+
+// converter renders an HTML tree to markdown. Block elements are separated
+// by blank lines that are normalized once the whole tree is rendered;
+// preformatted blocks are stashed in code so that normalization leaves them
+// intact.
+type converter struct {
+	base  *url.URL
+	plain bool
+	code  []string
+}
+
+// render converts n and its descendants
+func (c *converter) render(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return spaceRun.ReplaceAllString(n.Data, " ")
+	case html.ElementNode:
+	default:
+		return c.children(n)
+	}
+
+	if isBoilerplate(n) {
+		return ""
+	}
+
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := strings.TrimSpace(c.children(n))
+		if text == "" {
+			return ""
+		}
+		if c.plain {
+			return block(text)
+		}
+		return block(strings.Repeat("#", int(n.Data[1]-'0')) + " " + text)
+	case "p", "div", "section", "article", "main", "header", "figure", "figcaption",
+		"details", "summary", "dl", "dt", "dd", "address", "li":
+		return block(c.children(n))
+	case "br":
+		return "\n"
+	case "hr":
+		if c.plain {
+			return "\n\n"
+		}
+		return block("---")
+	case "pre":
+		return c.preformatted(n)
+	case "code", "kbd", "samp":
+		text := textContent(n)
+		if c.plain || strings.TrimSpace(text) == "" {
+			return text
+		}
+		return "`" + text + "`"
+	case "strong", "b":
+		return c.emphasis(n, "**")
+	case "em", "i":
+		return c.emphasis(n, "*")
+	case "a":
+		return c.link(n)
+	case "img":
+		alt := strings.TrimSpace(attr(n, "alt"))
+		if alt == "" || c.plain {
+			return alt
+		}
+		return fmt.Sprintf("![%s](%s)", alt, c.resolve(attr(n, "src")))
+	case "ul", "ol":
+		return c.list(n)
+	case "blockquote":
+		return c.blockquote(n)
+	case "table":
+		return c.table(n)
+	}
+	return c.children(n)
+}
+
+// children renders the children of n in order
+func (c *converter) children(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(c.render(child))
+	}
+	return b.String()
+}
+
+// emphasis wraps inline content in a markdown marker, keeping surrounding
+// spaces outside it
+func (c *converter) emphasis(n *html.Node, marker string) string {
+	text := c.children(n)
+	trimmed := strings.TrimSpace(text)
+	if c.plain || trimmed == "" {
+		return text
+	}
+	leading := text[:len(text)-len(strings.TrimLeft(text, " "))]
+	trailing := text[len(strings.TrimRight(text, " ")):]
+	return leading + marker + trimmed + marker + trailing
+}
+
+// link renders an anchor with its href resolved against the page URL.
+// Fragment-only and javascript: links are reduced to their text.
+func (c *converter) link(n *html.Node) string {
+	text := strings.TrimSpace(c.children(n))
+	href := strings.TrimSpace(attr(n, "href"))
+	if c.plain || text == "" || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return text
+	}
+	return fmt.Sprintf("[%s](%s)", text, c.resolve(href))
+}
+
+// resolve makes ref absolute relative to the page URL
+func (c *converter) resolve(ref string) string {
+	if c.base == nil {
+		return ref
+	}
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return c.base.ResolveReference(parsed).String()
+}
+
+// preformatted stashes a <pre> block as a fenced code block, tagged with the
+// language from a "language-*" class when there is one
+func (c *converter) preformatted(n *html.Node) string {
+	code := strings.Trim(textContent(n), "\n")
+	if code == "" {
+		return ""
+	}
+
+	if !c.plain {
+		lang := codeLanguage(n)
+		if codeChild := findElement(n, func(e *html.Node) bool { return e.Data == "code" }); lang == "" && codeChild != nil {
+			lang = codeLanguage(codeChild)
+		}
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		code = fence + lang + "\n" + code + "\n" + fence
+	}
+
+	c.code = append(c.code, code)
+	return block(fmt.Sprintf(codePlaceholder, len(c.code)-1))
+}
+
+// list renders ul/ol items, indenting nested content under each marker
+func (c *converter) list(n *html.Node) string {
+	var items []string
+	number := 1
+	if start := attr(n, "start"); start != "" {
+		_, _ = fmt.Sscanf(start, "%d", &number)
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.Data != "li" {
+			continue
+		}
+		content := strings.TrimSpace(spaceAroundLine.ReplaceAllString(c.children(child), "\n"))
+		if content == "" {
+			continue
+		}
+		// Keep items tight; nested blocks follow on the next line
+		content = blankLines.ReplaceAllString(strings.ReplaceAll(content, "\n\n", "\n"), "\n")
+
+		marker := "- "
+		if n.Data == "ol" {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+		lines := strings.Split(content, "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = indentMarker + lines[i]
+			}
+		}
+		items = append(items, marker+strings.Join(lines, "\n"))
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	return block(strings.Join(items, "\n"))
+}
+
+// blockquote prefixes each line of the quoted content with "> "
+func (c *converter) blockquote(n *html.Node) string {
+	content := strings.TrimSpace(spaceAroundLine.ReplaceAllString(c.children(n), "\n"))
+	if content == "" || c.plain {
+		return block(content)
+	}
+	content = blankLines.ReplaceAllString(content, "\n\n")
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return block(strings.Join(lines, "\n"))
+}
+
+// table renders a table as a markdown pipe table, taking the first row as
+// the header
+func (c *converter) table(n *html.Node) string {
+	var rows [][]string
+	var collect func(*html.Node)
+	collect = func(e *html.Node) {
+		for child := e.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.Data {
+			case "thead", "tbody", "tfoot":
+				collect(child)
+			case "tr":
+				var cells []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+						text := strings.TrimSpace(spaceRun.ReplaceAllString(c.children(cell), " "))
+						cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+					}
+				}
+				if len(cells) > 0 {
+					rows = append(rows, cells)
+				}
+			}
+		}
+	}
+	collect(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	if c.plain {
+		lines := make([]string, len(rows))
+		for i, row := range rows {
+			lines[i] = strings.Join(row, "\t")
+		}
+		return block(strings.Join(lines, "\n"))
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	formatRow := func(row []string) string {
+		cells := make([]string, columns)
+		copy(cells, row)
+		return "| " + strings.Join(cells, " | ") + " |"
+	}
+
+	lines := []string{formatRow(rows[0]), "|" + strings.Repeat(" --- |", columns)}
+	for _, row := range rows[1:] {
+		lines = append(lines, formatRow(row))
+	}
+	return block(strings.Join(lines, "\n"))
+}
+
+// block separates content from its neighbours with blank lines
+func block(content string) string {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return ""
+	}
+	return "\n\n" + content + "\n\n"
+}
+
+// isBoilerplate reports whether an element is page chrome or hidden. A
+// <header> is kept inside an <article> or <main>, where it usually holds the
+// title.
+func isBoilerplate(n *html.Node) bool {
+	if skippedElements[n.Data] || skippedRoles[attr(n, "role")] {
+		return true
+	}
+	if hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	if n.Data == "header" {
+		for p := n.Parent; p != nil; p = p.Parent {
+			if p.Type == html.ElementNode && (p.Data == "article" || p.Data == "main") {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// codeLanguage returns the language named by a "language-*" or "lang-*" class
+func codeLanguage(n *html.Node) string {
+	for _, class := range strings.Fields(attr(n, "class")) {
+		if lang, ok := strings.CutPrefix(class, "language-"); ok {
+			return lang
+		}
+		if lang, ok := strings.CutPrefix(class, "lang-"); ok {
+			return lang
+		}
+	}
+	return ""
+}
+
+// textContent returns the raw text beneath n
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == "br" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+// findElement returns the first element beneath n matching match, depth first
+func findElement(n *html.Node, match func(*html.Node) bool) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && match(child) {
+			return child
+		}
+		if found := findElement(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// attr returns the value of an attribute, or "" when it is absent
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasAttr reports whether n has the attribute, whatever its value
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
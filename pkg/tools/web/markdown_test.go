@@ -0,0 +1,86 @@
+package web
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func renderHTML(t *testing.T, page string, plain bool) string {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(page))
+	require.NoError(t, err)
+	base, err := url.Parse("https://example.com/docs/guide")
+	require.NoError(t, err)
+	return renderDocument(doc, base, plain)
+}
+
+func TestRenderDocument_Markdown(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want string
+	}{
+		{
+			name: "headings and emphasis",
+			page: `<h2>Install</h2><p>Run it <em>now</em>, <b>twice</b>.</p>`,
+			want: "## Install\n\nRun it *now*, **twice**.",
+		},
+		{
+			name: "links resolved against the page",
+			page: `<p><a href="../api">API</a>, <a href="#top">top</a> and <a href="https://go.dev">Go</a></p>`,
+			want: "[API](https://example.com/api), top and [Go](https://go.dev)",
+		},
+		{
+			name: "code block keeps indentation and language",
+			page: "<pre><code class=\"language-go\">func main() {\n\tfmt.Println(\"hi\")\n}</code></pre>",
+			want: "```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```",
+		},
+		{
+			name: "nested lists",
+			page: `<ul><li>One<ul><li>Inner</li></ul></li><li>Two</li></ul><ol start="3"><li>Three</li></ol>`,
+			want: "- One\n  - Inner\n- Two\n\n3. Three",
+		},
+		{
+			name: "blockquote",
+			page: `<blockquote><p>Quoted</p><p>Again</p></blockquote>`,
+			want: "> Quoted\n>\n> Again",
+		},
+		{
+			name: "table",
+			page: `<table><thead><tr><th>Name</th><th>Type</th></tr></thead><tbody><tr><td>a|b</td><td>string</td></tr></tbody></table>`,
+			want: "| Name | Type |\n| --- | --- |\n| a\\|b | string |",
+		},
+		{
+			name: "only article is used",
+			page: `<div class="menu">Menu</div><article><header><h1>Title</h1></header><p>Body</p></article>`,
+			want: "# Title\n\nBody",
+		},
+		{
+			name: "hidden elements dropped",
+			page: `<p>Shown</p><div hidden>Hidden</div><div aria-hidden="true">Icon</div><div role="navigation">Nav</div>`,
+			want: "Shown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderHTML(t, tt.page, false))
+		})
+	}
+}
+
+func TestRenderDocument_Plain(t *testing.T) {
+	page := `<h1>Title</h1><p>See <a href="/x">the docs</a> for <code>x</code>.</p><pre>  indented</pre>`
+	assert.Equal(t, "Title\n\nSee the docs for x.\n\n  indented", renderHTML(t, page, true))
+}
+
+func TestDocumentTitle(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader("<title>\n  Page  Title\n</title><p>Body</p>"))
+	require.NoError(t, err)
+	assert.Equal(t, "Page Title", documentTitle(doc))
+}
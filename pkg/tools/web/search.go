@@ -0,0 +1,304 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Search providers web_search can use
+const (
+	SearchProviderBrave   = "brave"
+	SearchProviderSearXNG = "searxng"
+	SearchProviderBing    = "bing"
+)
+
+// Safe search levels, from least to most filtered
+const (
+	SafeSearchOff      = "off"
+	SafeSearchModerate = "moderate"
+	SafeSearchStrict   = "strict"
+)
+
+const (
+	braveSearchURL = "https://api.search.brave.com/res/v1/web/search"
+	bingSearchURL  = "https://api.bing.microsoft.com/v7.0/search"
+
+	// searchTimeout bounds a single search request
+	searchTimeout = 20 * time.Second
+	// maxSearchResponseBytes caps how much of a search response is read
+	maxSearchResponseBytes = 2 * 1024 * 1024
+)
+
+// SearchQuery is a web search request.
+type SearchQuery struct {
+	Query      string
+	Count      int    // Results wanted; providers may return fewer
+	SafeSearch string // SafeSearchOff, SafeSearchModerate or SafeSearchStrict
+}
+
+// SearchResult is a single web search hit.
+type SearchResult struct {
+	Title   string
+	URL     string
+	Snippet string
+}
+
+// SearchProvider runs web searches against a search API.
+type SearchProvider interface {
+	// Name returns the provider name, e.g. "brave".
+	Name() string
+	// Search returns the results for query, best first.
+	Search(ctx context.Context, query SearchQuery) ([]SearchResult, error)
+}
+
+// SearchProviderFromEnv returns the search provider configured in the
+// environment. FORGE_SEARCH_PROVIDER selects one explicitly; otherwise the
+// first of Brave (BRAVE_API_KEY), SearXNG (SEARXNG_URL) and Bing
+// (BING_API_KEY) with credentials is used. It returns (nil, nil) when no
+// provider is configured.
+func SearchProviderFromEnv() (SearchProvider, error) {
+	braveKey := os.Getenv("BRAVE_API_KEY")
+	searxngURL := os.Getenv("SEARXNG_URL")
+	bingKey := os.Getenv("BING_API_KEY")
+
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("FORGE_SEARCH_PROVIDER")))
+	if name == "" {
+		switch {
+		case braveKey != "":
+			name = SearchProviderBrave
+		case searxngURL != "":
+			name = SearchProviderSearXNG
+		case bingKey != "":
+			name = SearchProviderBing
+		default:
+			return nil, nil
+		}
+	}
+
+	switch name {
+	case SearchProviderBrave:
+		if braveKey == "" {
+			return nil, fmt.Errorf("search provider brave needs BRAVE_API_KEY")
+		}
+		return NewBraveSearch(braveKey), nil
+	case SearchProviderSearXNG:
+		if searxngURL == "" {
+			return nil, fmt.Errorf("search provider searxng needs SEARXNG_URL")
+		}
+		return NewSearXNGSearch(searxngURL)
+	case SearchProviderBing:
+		if bingKey == "" {
+			return nil, fmt.Errorf("search provider bing needs BING_API_KEY")
+		}
+		return NewBingSearch(bingKey), nil
+	default:
+		return nil, fmt.Errorf("unknown search provider %q (must be %s, %s or %s)",
+			name, SearchProviderBrave, SearchProviderSearXNG, SearchProviderBing)
+	}
+}
+
+// BraveSearch searches with the Brave Search API.
+type BraveSearch struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewBraveSearch creates a Brave Search provider using apiKey.
+func NewBraveSearch(apiKey string) *BraveSearch {
+	return &BraveSearch{apiKey: apiKey, baseURL: braveSearchURL, client: &http.Client{Timeout: searchTimeout}}
+}
+
+// Name returns the provider name.
+func (b *BraveSearch) Name() string {
+	return SearchProviderBrave
+}
+
+// Search runs the query.
+func (b *BraveSearch) Search(ctx context.Context, query SearchQuery) ([]SearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query.Query)
+	params.Set("count", strconv.Itoa(min(query.Count, 20)))
+	params.Set("safesearch", query.SafeSearch)
+
+	header := http.Header{}
+	header.Set("X-Subscription-Token", b.apiKey)
+
+	var body struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := getSearchJSON(ctx, b.client, b.baseURL+"?"+params.Encode(), header, &body); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(body.Web.Results))
+	for _, r := range body.Web.Results {
+		results = append(results, SearchResult{Title: plainText(r.Title), URL: r.URL, Snippet: plainText(r.Description)})
+	}
+	return results, nil
+}
+
+// SearXNGSearch searches a SearXNG instance, which must have the JSON output
+// format enabled.
+type SearXNGSearch struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewSearXNGSearch creates a provider for the SearXNG instance at baseURL.
+func NewSearXNGSearch(baseURL string) (*SearXNGSearch, error) {
+	parsed, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("SEARXNG_URL must be an absolute http or https URL, got %q", baseURL)
+	}
+	return &SearXNGSearch{baseURL: strings.TrimSuffix(parsed.String(), "/"), client: &http.Client{Timeout: searchTimeout}}, nil
+}
+
+// Name returns the provider name.
+func (s *SearXNGSearch) Name() string {
+	return SearchProviderSearXNG
+}
+
+// Search runs the query. SearXNG does not take a result count, so the first
+// page is trimmed to the count asked for.
+func (s *SearXNGSearch) Search(ctx context.Context, query SearchQuery) ([]SearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query.Query)
+	params.Set("format", "json")
+	params.Set("safesearch", map[string]string{SafeSearchOff: "0", SafeSearchModerate: "1", SafeSearchStrict: "2"}[query.SafeSearch])
+
+	var body struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := getSearchJSON(ctx, s.client, s.baseURL+"/search?"+params.Encode(), nil, &body); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, min(len(body.Results), query.Count))
+	for _, r := range body.Results[:min(len(body.Results), query.Count)] {
+		results = append(results, SearchResult{Title: plainText(r.Title), URL: r.URL, Snippet: plainText(r.Content)})
+	}
+	return results, nil
+}
+
+// BingSearch searches with the Bing Web Search API.
+type BingSearch struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewBingSearch creates a Bing Web Search provider using apiKey.
+func NewBingSearch(apiKey string) *BingSearch {
+	return &BingSearch{apiKey: apiKey, baseURL: bingSearchURL, client: &http.Client{Timeout: searchTimeout}}
+}
+
+// Name returns the provider name.
+func (b *BingSearch) Name() string {
+	return SearchProviderBing
+}
+
+// Search runs the query.
+func (b *BingSearch) Search(ctx context.Context, query SearchQuery) ([]SearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query.Query)
+	params.Set("count", strconv.Itoa(query.Count))
+	params.Set("safeSearch", map[string]string{SafeSearchOff: "Off", SafeSearchModerate: "Moderate", SafeSearchStrict: "Strict"}[query.SafeSearch])
+	params.Set("responseFilter", "Webpages")
+
+	header := http.Header{}
+	header.Set("Ocp-Apim-Subscription-Key", b.apiKey)
+
+	var body struct {
+		WebPages struct {
+			Value []struct {
+				Name    string `json:"name"`
+				URL     string `json:"url"`
+				Snippet string `json:"snippet"`
+			} `json:"value"`
+		} `json:"webPages"`
+	}
+	if err := getSearchJSON(ctx, b.client, b.baseURL+"?"+params.Encode(), header, &body); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(body.WebPages.Value))
+	for _, r := range body.WebPages.Value {
+		results = append(results, SearchResult{Title: plainText(r.Name), URL: r.URL, Snippet: plainText(r.Snippet)})
+	}
+	return results, nil
+}
+
+// getSearchJSON sends a GET request to a search API and decodes its JSON
+// response into out.
+func getSearchJSON(ctx context.Context, client *http.Client, target string, header http.Header, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("invalid search request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Forge/1.0 (+https://github.com/entrhq/forge)")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("search request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSearchResponseBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read search response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail := strings.TrimSpace(truncateContent(string(data), 200))
+		if detail == "" {
+			return fmt.Errorf("search failed: %s", resp.Status)
+		}
+		return fmt.Errorf("search failed: %s: %s", resp.Status, detail)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse search response: %w", err)
+	}
+	return nil
+}
+
+// plainText strips the markup some providers use to highlight matches, such
+// as <strong>, and decodes entities.
+func plainText(s string) string {
+	if !strings.ContainsAny(s, "<&") {
+		return strings.TrimSpace(s)
+	}
+	var b strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case html.TextToken:
+			b.Write(tokenizer.Text())
+		}
+	}
+}
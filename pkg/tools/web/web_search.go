@@ -0,0 +1,147 @@
+package web
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/entrhq/forge/pkg/agent/tools"
+)
+
+const (
+	defaultSearchCount = 5
+	maxSearchCount     = 20
+)
+
+// WebSearchTool searches the web through a SearchProvider and returns the
+// title, URL and snippet of each result, so the agent can find pages to read
+// with fetch_url or a browser session.
+type WebSearchTool struct {
+	provider SearchProvider
+}
+
+// NewWebSearchTool creates a web search tool backed by provider.
+func NewWebSearchTool(provider SearchProvider) *WebSearchTool {
+	return &WebSearchTool{provider: provider}
+}
+
+// Name returns the tool name.
+func (t *WebSearchTool) Name() string {
+	return "web_search"
+}
+
+// Description returns the tool description.
+func (t *WebSearchTool) Description() string {
+	return "Search the web and return the title, URL and snippet of each result. " +
+		"Use it to find documentation, error messages, release notes or libraries, then read the relevant pages with fetch_url. " +
+		"Snippets are short excerpts; do not rely on them without reading the page."
+}
+
+// Schema returns the tool's JSON schema.
+func (t *WebSearchTool) Schema() map[string]any {
+	return tools.BaseToolSchema(
+		map[string]any{
+			"query": map[string]any{
+				"type":        "string",
+				"description": "The search query, as you would type it into a search engine",
+			},
+			"count": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Number of results (1-%d). Default: %d", maxSearchCount, defaultSearchCount),
+			},
+			"safe_search": map[string]any{
+				"type":        "string",
+				"description": "Filtering of adult content. Default: moderate",
+				"enum":        []string{SafeSearchOff, SafeSearchModerate, SafeSearchStrict},
+			},
+		},
+		[]string{"query"},
+	)
+}
+
+// WebSearchInput represents the parameters for a web search.
+type WebSearchInput struct {
+	XMLName    xml.Name `xml:"arguments"`
+	Query      string   `xml:"query"`
+	Count      *int     `xml:"count"`
+	SafeSearch string   `xml:"safe_search"`
+}
+
+// Execute runs the search.
+func (t *WebSearchTool) Execute(ctx context.Context, argsXML []byte) (string, map[string]any, error) {
+	query, err := t.parseInput(argsXML)
+	if err != nil {
+		return "", nil, err
+	}
+
+	results, err := t.provider.Search(ctx, query)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s search for %q failed: %w", t.provider.Name(), query.Query, err)
+	}
+
+	metadata := map[string]any{
+		"provider": t.provider.Name(),
+		"query":    query.Query,
+		"count":    len(results),
+	}
+	if len(results) == 0 {
+		return fmt.Sprintf("No results for %q. Try fewer or different keywords.", query.Query), metadata, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Search results for %q (%s, %d results)\n", query.Query, t.provider.Name(), len(results))
+	for i, r := range results {
+		fmt.Fprintf(&b, "\n%d. %s\n   %s\n", i+1, r.Title, r.URL)
+		if r.Snippet != "" {
+			fmt.Fprintf(&b, "   %s\n", r.Snippet)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n"), metadata, nil
+}
+
+// parseInput parses and validates the XML input parameters.
+func (t *WebSearchTool) parseInput(argsXML []byte) (SearchQuery, error) {
+	var input WebSearchInput
+	if err := tools.UnmarshalXMLWithFallback(argsXML, &input); err != nil {
+		return SearchQuery{}, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	query := SearchQuery{
+		Query:      strings.TrimSpace(input.Query),
+		Count:      defaultSearchCount,
+		SafeSearch: strings.ToLower(strings.TrimSpace(input.SafeSearch)),
+	}
+	if query.Query == "" {
+		return SearchQuery{}, fmt.Errorf("query is required")
+	}
+	if input.Count != nil {
+		if *input.Count < 1 || *input.Count > maxSearchCount {
+			return SearchQuery{}, fmt.Errorf("count must be between 1 and %d", maxSearchCount)
+		}
+		query.Count = *input.Count
+	}
+	switch query.SafeSearch {
+	case "":
+		query.SafeSearch = SafeSearchModerate
+	case SafeSearchOff, SafeSearchModerate, SafeSearchStrict:
+	default:
+		return SearchQuery{}, fmt.Errorf("invalid safe_search: %s (must be '%s', '%s' or '%s')",
+			query.SafeSearch, SafeSearchOff, SafeSearchModerate, SafeSearchStrict)
+	}
+	return query, nil
+}
+
+// GeneratePreview generates a concise one-line preview of the search.
+func (t *WebSearchTool) GeneratePreview(argsXML []byte) (string, error) {
+	query, err := t.parseInput(argsXML)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Search the web for %q", query.Query), nil
+}
+
+// IsLoopBreaking returns whether this tool breaks the agent loop.
+func (t *WebSearchTool) IsLoopBreaking() bool {
+	return false
+}
@@ -0,0 +1,170 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSearchProvider returns fixed results and records the last query.
+type fakeSearchProvider struct {
+	results []SearchResult
+	query   SearchQuery
+}
+
+func (f *fakeSearchProvider) Name() string { return "fake" }
+
+func (f *fakeSearchProvider) Search(ctx context.Context, query SearchQuery) ([]SearchResult, error) {
+	f.query = query
+	return f.results, nil
+}
+
+func TestWebSearchTool_Execute(t *testing.T) {
+	provider := &fakeSearchProvider{results: []SearchResult{
+		{Title: "Effective Go", URL: "https://go.dev/doc/effective_go", Snippet: "Tips for writing clear, idiomatic Go code."},
+		{Title: "Go FAQ", URL: "https://go.dev/doc/faq"},
+	}}
+	tool := NewWebSearchTool(provider)
+
+	result, metadata, err := tool.Execute(context.Background(), []byte("<arguments><query> idiomatic go </query><count>2</count><safe_search>strict</safe_search></arguments>"))
+	require.NoError(t, err)
+
+	assert.Equal(t, SearchQuery{Query: "idiomatic go", Count: 2, SafeSearch: SafeSearchStrict}, provider.query)
+	assert.Equal(t, `Search results for "idiomatic go" (fake, 2 results)
+
+1. Effective Go
+   https://go.dev/doc/effective_go
+   Tips for writing clear, idiomatic Go code.
+
+2. Go FAQ
+   https://go.dev/doc/faq`, result)
+	assert.Equal(t, "fake", metadata["provider"])
+	assert.Equal(t, 2, metadata["count"])
+
+	provider.results = nil
+	result, _, err = tool.Execute(context.Background(), []byte("<arguments><query>zzzz</query></arguments>"))
+	require.NoError(t, err)
+	assert.Contains(t, result, `No results for "zzzz"`)
+	assert.Equal(t, SearchQuery{Query: "zzzz", Count: defaultSearchCount, SafeSearch: SafeSearchModerate}, provider.query)
+}
+
+func TestWebSearchTool_InvalidInput(t *testing.T) {
+	tool := NewWebSearchTool(&fakeSearchProvider{})
+	for args, want := range map[string]string{
+		"<arguments><query> </query></arguments>":                                    "query is required",
+		"<arguments><query>go</query><count>21</count></arguments>":                  "count must be between 1 and 20",
+		"<arguments><query>go</query><safe_search>maximum</safe_search></arguments>": "invalid safe_search",
+	} {
+		_, _, err := tool.Execute(context.Background(), []byte(args))
+		if assert.Error(t, err, args) {
+			assert.Contains(t, err.Error(), want)
+		}
+	}
+}
+
+func TestBraveSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "brave-key", r.Header.Get("X-Subscription-Token"))
+		assert.Equal(t, "golang generics", r.URL.Query().Get("q"))
+		assert.Equal(t, "3", r.URL.Query().Get("count"))
+		assert.Equal(t, "off", r.URL.Query().Get("safesearch"))
+		fmt.Fprint(w, `{"web":{"results":[{"title":"Tutorial: Getting started with generics","url":"https://go.dev/doc/tutorial/generics","description":"Introduces the basics of <strong>generics</strong> in Go &amp; more."}]}}`)
+	}))
+	defer server.Close()
+
+	brave := NewBraveSearch("brave-key")
+	brave.baseURL = server.URL
+	results, err := brave.Search(context.Background(), SearchQuery{Query: "golang generics", Count: 3, SafeSearch: SafeSearchOff})
+	require.NoError(t, err)
+	assert.Equal(t, []SearchResult{{
+		Title:   "Tutorial: Getting started with generics",
+		URL:     "https://go.dev/doc/tutorial/generics",
+		Snippet: "Introduces the basics of generics in Go & more.",
+	}}, results)
+}
+
+func TestSearXNGSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/searx/search", r.URL.Path)
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		assert.Equal(t, "2", r.URL.Query().Get("safesearch"))
+		fmt.Fprint(w, `{"results":[
+			{"title":"One","url":"https://example.com/1","content":"first"},
+			{"title":"Two","url":"https://example.com/2","content":"second"},
+			{"title":"Three","url":"https://example.com/3","content":"third"}]}`)
+	}))
+	defer server.Close()
+
+	searxng, err := NewSearXNGSearch(server.URL + "/searx/")
+	require.NoError(t, err)
+	results, err := searxng.Search(context.Background(), SearchQuery{Query: "example", Count: 2, SafeSearch: SafeSearchStrict})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, SearchResult{Title: "Two", URL: "https://example.com/2", Snippet: "second"}, results[1])
+
+	_, err = NewSearXNGSearch("searx.local")
+	assert.Error(t, err)
+}
+
+func TestBingSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "bing-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"code":"401","message":"Access denied"}}`)
+			return
+		}
+		assert.Equal(t, "Moderate", r.URL.Query().Get("safeSearch"))
+		fmt.Fprint(w, `{"webPages":{"value":[{"name":"Go","url":"https://go.dev","snippet":"Build simple, secure, scalable systems with Go."}]}}`)
+	}))
+	defer server.Close()
+
+	bing := NewBingSearch("bing-key")
+	bing.baseURL = server.URL
+	results, err := bing.Search(context.Background(), SearchQuery{Query: "go", Count: 5, SafeSearch: SafeSearchModerate})
+	require.NoError(t, err)
+	assert.Equal(t, []SearchResult{{Title: "Go", URL: "https://go.dev", Snippet: "Build simple, secure, scalable systems with Go."}}, results)
+
+	bing = NewBingSearch("wrong-key")
+	bing.baseURL = server.URL
+	_, err = bing.Search(context.Background(), SearchQuery{Query: "go", Count: 5, SafeSearch: SafeSearchModerate})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401 Unauthorized")
+	assert.Contains(t, err.Error(), "Access denied")
+}
+
+func TestSearchProviderFromEnv(t *testing.T) {
+	setEnv := func(provider, brave, searxng, bing string) {
+		t.Setenv("FORGE_SEARCH_PROVIDER", provider)
+		t.Setenv("BRAVE_API_KEY", brave)
+		t.Setenv("SEARXNG_URL", searxng)
+		t.Setenv("BING_API_KEY", bing)
+	}
+
+	setEnv("", "", "", "")
+	provider, err := SearchProviderFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, provider)
+
+	setEnv("", "", "http://localhost:8888", "bing-key")
+	provider, err = SearchProviderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, SearchProviderSearXNG, provider.Name())
+
+	setEnv("Bing", "brave-key", "", "bing-key")
+	provider, err = SearchProviderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, SearchProviderBing, provider.Name())
+
+	setEnv("brave", "", "", "bing-key")
+	_, err = SearchProviderFromEnv()
+	assert.ErrorContains(t, err, "BRAVE_API_KEY")
+
+	setEnv("google", "", "", "")
+	_, err = SearchProviderFromEnv()
+	assert.ErrorContains(t, err, "unknown search provider")
+}
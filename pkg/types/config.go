@@ -18,6 +18,10 @@ type ModelInfo struct {
 
 	// SupportsStreaming indicates if the model supports streaming responses.
 	SupportsStreaming bool
+
+	// SupportsToolCalling indicates if the provider accepts tool definitions
+	// and returns tool calls through a native function-calling API.
+	SupportsToolCalling bool
 }
 
 // AgentConfig holds configuration for an agent instance.
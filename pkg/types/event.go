@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // AgentEventType defines the type of event emitted by the agent.
 type AgentEventType string
 
@@ -38,6 +40,9 @@ const (
 	EventTypeContextSummarizationComplete AgentEventType = "context_summarization_complete" // EventTypeContextSummarizationComplete indicates context summarization finished successfully.
 	EventTypeContextSummarizationError    AgentEventType = "context_summarization_error"    // EventTypeContextSummarizationError indicates an error occurred during context summarization.
 	EventTypeNotesData                    AgentEventType = "notes_data"                     // EventTypeNotesData indicates notes data response from agent.
+	EventTypeOversizedMessage             AgentEventType = "oversized_message"              // EventTypeOversizedMessage indicates a user input or tool result exceeded the per-message token ceiling.
+	EventTypeModelFallback                AgentEventType = "model_fallback"                 // EventTypeModelFallback indicates the model was rate limited and requests moved to the fallback model.
+	EventTypeTurnBudgetExceeded           AgentEventType = "turn_budget_exceeded"           // EventTypeTurnBudgetExceeded indicates a turn used up its tool call or time budget.
 )
 
 // AgentEvent represents an event emitted by the agent during execution.
@@ -90,6 +95,27 @@ type AgentEvent struct {
 
 	// NotesData contains notes data (for notes data events).
 	NotesData *NotesData
+
+	// OversizedMessage contains details of a message that exceeded the
+	// per-message token ceiling (for oversized message events).
+	OversizedMessage *OversizedMessage
+
+	// ModelFallback contains details of a move to the fallback model (for
+	// model fallback events).
+	ModelFallback *ModelFallback
+
+	// TurnBudget contains details of the budget a turn used up (for turn
+	// budget exceeded events).
+	TurnBudget *TurnBudget
+
+	// Timestamp is when the event occurred. The agent stamps events as they
+	// are emitted, so consumers can time operations independently of when
+	// they read the event off the channel.
+	Timestamp time.Time
+
+	// TurnID identifies the user turn the event belongs to. It is empty for
+	// events emitted outside a turn, such as notes data responses.
+	TurnID string
 }
 
 // TokenUsage contains token usage statistics from an LLM API call.
@@ -591,6 +617,7 @@ type NoteData struct {
 	ID        string
 	Content   string
 	Tags      []string
+	Category  string // decision, blocker or followup when the note has a category tag
 	CreatedAt string
 	UpdatedAt string
 	Scratched bool
@@ -612,3 +639,110 @@ func NewNotesDataEvent(notes []NoteData, tag string) *AgentEvent {
 		},
 	}
 }
+
+// Sources of an oversized message.
+const (
+	OversizedSourceUserInput  = "user_input"
+	OversizedSourceToolResult = "tool_result"
+)
+
+// Actions taken on an oversized message.
+const (
+	OversizedActionRejected = "rejected"
+	OversizedActionChunked  = "chunked"
+)
+
+// OversizedMessage describes a single message that exceeded the per-message
+// token ceiling and what the agent did about it.
+type OversizedMessage struct {
+	// Source is OversizedSourceUserInput or OversizedSourceToolResult.
+	Source string
+
+	// ToolName is the tool that produced the result (tool results only).
+	ToolName string
+
+	// Tokens is the size of the original message.
+	Tokens int
+
+	// Limit is the configured per-message token ceiling.
+	Limit int
+
+	// Action is OversizedActionRejected or OversizedActionChunked.
+	Action string
+
+	// KeptTokens is the size of the content that was added to the conversation.
+	KeptTokens int
+}
+
+// NewOversizedMessageEvent creates an oversized message event.
+func NewOversizedMessageEvent(info OversizedMessage) *AgentEvent {
+	return &AgentEvent{
+		Type:             EventTypeOversizedMessage,
+		ToolName:         info.ToolName,
+		OversizedMessage: &info,
+		Metadata:         make(map[string]any),
+	}
+}
+
+// ModelFallback describes requests moving to the fallback model after the
+// main model was rate limited or overloaded.
+type ModelFallback struct {
+	// From is the model that was rate limited.
+	From string
+
+	// To is the fallback model requests now go to.
+	To string
+
+	// Reason is the rate limit error the API returned.
+	Reason string
+
+	// Until is when the main model is tried again.
+	Until time.Time
+}
+
+// NewModelFallbackEvent creates a model fallback event.
+func NewModelFallbackEvent(info ModelFallback) *AgentEvent {
+	return &AgentEvent{
+		Type:          EventTypeModelFallback,
+		ModelFallback: &info,
+		Metadata:      make(map[string]any),
+	}
+}
+
+// Turn budgets a turn can use up.
+const (
+	TurnBudgetToolCalls = "tool_calls"
+	TurnBudgetDuration  = "duration"
+)
+
+// TurnBudget describes a turn that used up its tool call or time budget. The
+// agent is told to wrap up, and the turn is stopped if it keeps going.
+type TurnBudget struct {
+	// Limit is TurnBudgetToolCalls or TurnBudgetDuration.
+	Limit string
+
+	// ToolCalls is the number of tool calls made in the turn so far.
+	ToolCalls int
+
+	// MaxToolCalls is the configured tool call budget (0 when unlimited).
+	MaxToolCalls int
+
+	// Elapsed is how long the turn has been running.
+	Elapsed time.Duration
+
+	// MaxDuration is the configured time budget (0 when unlimited).
+	MaxDuration time.Duration
+
+	// Stopped is true when the agent kept going after being told to wrap up
+	// and the turn was ended.
+	Stopped bool
+}
+
+// NewTurnBudgetExceededEvent creates a turn budget exceeded event.
+func NewTurnBudgetExceededEvent(info TurnBudget) *AgentEvent {
+	return &AgentEvent{
+		Type:       EventTypeTurnBudgetExceeded,
+		TurnBudget: &info,
+		Metadata:   make(map[string]any),
+	}
+}
@@ -4,10 +4,13 @@ package types
 type InputType string
 
 const (
-	InputTypeCancel       InputType = "cancel"        // InputTypeCancel indicates a cancellation request.
-	InputTypeUserInput    InputType = "user_input"    // InputTypeUserInput indicates a simple text input from the user.
-	InputTypeFormInput    InputType = "form_input"    // InputTypeFormInput indicates structured form data with multiple key-value pairs.
-	InputTypeNotesRequest InputType = "notes_request" // InputTypeNotesRequest indicates a request for notes data.
+	InputTypeCancel         InputType = "cancel"          // InputTypeCancel indicates a cancellation request.
+	InputTypeUserInput      InputType = "user_input"      // InputTypeUserInput indicates a simple text input from the user.
+	InputTypeFormInput      InputType = "form_input"      // InputTypeFormInput indicates structured form data with multiple key-value pairs.
+	InputTypeNotesRequest   InputType = "notes_request"   // InputTypeNotesRequest indicates a request for notes data.
+	InputTypeCompactRequest InputType = "compact_request" // InputTypeCompactRequest asks the agent to compact its context now.
+	InputTypePinRequest     InputType = "pin_request"     // InputTypePinRequest asks the agent to pin or unpin context.
+	InputTypeRetryRequest   InputType = "retry_request"   // InputTypeRetryRequest asks the agent to redo its last turn.
 )
 
 // Input represents various types of input that can be sent to an agent.
@@ -101,3 +104,62 @@ func NewNotesRequestInput(params NotesRequestParams) *Input {
 		Metadata: map[string]any{"params": params},
 	}
 }
+
+// IsCompactRequest returns true if this is a context compaction request input.
+func (i *Input) IsCompactRequest() bool {
+	return i.Type == InputTypeCompactRequest
+}
+
+// CompactRequestParams contains parameters for an on-demand context compaction.
+type CompactRequestParams struct {
+	Level        string // light, normal (default) or aggressive
+	TargetTokens int    // Stop once the context is this small (0 for no target)
+}
+
+// NewCompactRequestInput creates a new context compaction request input.
+func NewCompactRequestInput(params CompactRequestParams) *Input {
+	return &Input{
+		Type:     InputTypeCompactRequest,
+		Metadata: map[string]any{"params": params},
+	}
+}
+
+// IsPinRequest returns true if this is a context pinning request input.
+func (i *Input) IsPinRequest() bool {
+	return i.Type == InputTypePinRequest
+}
+
+// PinRequestParams contains parameters for pinning context so it survives
+// summarization.
+type PinRequestParams struct {
+	Label   string // Short description of the pinned content, e.g. a file path
+	Content string // Text to pin; empty pins the user's most recent message
+	Unpin   bool   // Remove every pin instead
+}
+
+// NewPinRequestInput creates a new context pinning request input.
+func NewPinRequestInput(params PinRequestParams) *Input {
+	return &Input{
+		Type:     InputTypePinRequest,
+		Metadata: map[string]any{"params": params},
+	}
+}
+
+// IsRetryRequest returns true if this is a request to redo the last turn.
+func (i *Input) IsRetryRequest() bool {
+	return i.Type == InputTypeRetryRequest
+}
+
+// RetryRequestParams contains the message to redo the last turn with.
+type RetryRequestParams struct {
+	Content string // Message that replaces the last one the user sent, edited or not
+}
+
+// NewRetryRequestInput creates a request to remove the last turn from the
+// context and run it again with params.Content.
+func NewRetryRequestInput(params RetryRequestParams) *Input {
+	return &Input{
+		Type:     InputTypeRetryRequest,
+		Metadata: map[string]any{"params": params},
+	}
+}
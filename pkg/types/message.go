@@ -13,6 +13,23 @@ const (
 	RoleTool      MessageRole = "tool"      // RoleTool represents a message generated by a tool or external system.
 )
 
+// MetadataPinned is the message metadata key that pins a message: context
+// summarization keeps pinned messages verbatim and pruning never evicts them.
+const MetadataPinned = "pinned"
+
+// MetadataImage is the tool result metadata key holding an Image the tool
+// wants the model to see.
+const MetadataImage = "image"
+
+// Image is an image attached to a message, for models that accept image input.
+type Image struct {
+	// MediaType is the image's MIME type, e.g. image/png.
+	MediaType string
+
+	// Data is the encoded image file.
+	Data []byte
+}
+
 // Message represents a single message in a conversation.
 type Message struct {
 	// Metadata holds optional additional information about the message.
@@ -27,6 +44,29 @@ type Message struct {
 
 	// Role indicates who sent the message (system, user, or assistant).
 	Role MessageRole
+
+	// ToolCalls holds the native tool calls made by an assistant message.
+	// It is only set when the agent uses provider function calling.
+	ToolCalls []ToolCall
+
+	// ToolCallID links a tool result message to the native tool call it answers.
+	ToolCallID string
+
+	// Images holds images sent to the model along with Content.
+	Images []Image
+}
+
+// ToolCall is a tool invocation returned through a provider's native
+// function-calling API.
+type ToolCall struct {
+	// ID is the provider-assigned identifier for this call.
+	ID string
+
+	// Name is the name of the tool being called.
+	Name string
+
+	// Arguments is the JSON-encoded argument object.
+	Arguments string
 }
 
 // NewMessage creates a new Message with the given role and content.
@@ -67,3 +107,9 @@ func (m *Message) WithMetadata(key string, value any) *Message {
 	m.Metadata[key] = value
 	return m
 }
+
+// IsPinned reports whether the message is pinned.
+func (m *Message) IsPinned() bool {
+	pinned, _ := m.Metadata[MetadataPinned].(bool)
+	return pinned
+}
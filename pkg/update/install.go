@@ -0,0 +1,138 @@
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// publicKey is the base64-encoded Ed25519 key that release checksums are
+// signed with. Release builds set it with
+//
+//	-ldflags "-X github.com/entrhq/forge/pkg/update.publicKey=<key>"
+var publicKey string
+
+// ErrNoPublicKey is returned when this build has no release signing key, so
+// downloads cannot be verified.
+var ErrNoPublicKey = errors.New("this build has no release signing key, so updates cannot be verified; download the release manually")
+
+// PublicKey returns the release signing key compiled into this build.
+func PublicKey() (ed25519.PublicKey, error) {
+	if publicKey == "" {
+		return nil, ErrNoPublicKey
+	}
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release signing key in this build")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// AssetName returns the name of the release binary for a platform.
+func AssetName(goos, goarch string) string {
+	name := fmt.Sprintf("forge_%s_%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Download fetches the binary for the current platform from release and
+// verifies it against the signed checksums before returning it.
+func (c *Client) Download(ctx context.Context, release *Release, key ed25519.PublicKey) ([]byte, error) {
+	asset := AssetName(runtime.GOOS, runtime.GOARCH)
+	for _, name := range []string{asset, ChecksumsAsset, SignatureAsset} {
+		if release.Assets[name] == "" {
+			return nil, fmt.Errorf("release %s has no %s asset", release.Version, name)
+		}
+	}
+
+	checksums, err := c.get(ctx, release.Assets[ChecksumsAsset])
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", ChecksumsAsset, err)
+	}
+	signature, err := c.get(ctx, release.Assets[SignatureAsset])
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", SignatureAsset, err)
+	}
+	if !ed25519.Verify(key, checksums, signature) {
+		return nil, fmt.Errorf("signature of %s does not match the release signing key", ChecksumsAsset)
+	}
+
+	binary, err := c.get(ctx, release.Assets[asset])
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset, err)
+	}
+	if err := VerifyChecksum(checksums, asset, binary); err != nil {
+		return nil, err
+	}
+
+	return binary, nil
+}
+
+// VerifyChecksum checks data against the SHA-256 digest listed for name in a
+// checksums file of "<hex digest>  <name>" lines.
+func VerifyChecksum(checksums []byte, name string, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s is not listed in %s", name, ChecksumsAsset)
+}
+
+// Install replaces the executable at path with binary. The new binary is
+// written next to it and renamed into place, so a failed update leaves the
+// old binary intact.
+func Install(path string, binary []byte) error {
+	if strings.Contains(filepath.ToSlash(path), "/Cellar/") {
+		return fmt.Errorf("forge was installed with Homebrew; run 'brew upgrade forge' instead")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	tmp := path + ".new"
+	if err := os.WriteFile(tmp, binary, info.Mode().Perm()|0o100); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("no permission to write to %s; re-run with the permissions used to install forge", filepath.Dir(path))
+		}
+		return fmt.Errorf("failed to write update: %w", err)
+	}
+
+	// Windows cannot replace a running executable, but it can rename it
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("failed to move old binary aside: %w", err)
+		}
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+	return nil
+}
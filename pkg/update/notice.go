@@ -0,0 +1,76 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CheckInterval is how often the release feed is checked for the
+// update-available notice.
+const CheckInterval = 24 * time.Hour
+
+// checkCache records the last release feed check, so starting Forge does not
+// wait on the network.
+type checkCache struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Channel   string    `json:"channel"`
+	Latest    string    `json:"latest"`
+}
+
+// CachePath returns the location of the update check cache.
+func CachePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".forge", "update-check.json"), nil
+}
+
+// Notice returns an update-available message when the last recorded check
+// for channel found a version newer than current, or "" otherwise. It only
+// reads the cache; call Refresh to update it.
+func Notice(cachePath, current, channel string) string {
+	cache, err := readCache(cachePath)
+	if err != nil || cache.Channel != channel || !IsNewer(cache.Latest, current) {
+		return ""
+	}
+	return fmt.Sprintf("Forge %s is available (you have %s). Run 'forge update' to install it.", cache.Latest, current)
+}
+
+// Refresh checks the release feed and records the latest version for channel,
+// unless the cache is younger than CheckInterval.
+func (c *Client) Refresh(ctx context.Context, cachePath, channel string, now time.Time) error {
+	if cache, err := readCache(cachePath); err == nil && cache.Channel == channel && now.Sub(cache.CheckedAt) < CheckInterval {
+		return nil
+	}
+
+	release, err := c.Latest(ctx, channel)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(checkCache{CheckedAt: now, Channel: channel, Latest: release.Version})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(cachePath, data, 0o600)
+}
+
+func readCache(path string) (*checkCache, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is under the user's home directory
+	if err != nil {
+		return nil, err
+	}
+	var cache checkCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
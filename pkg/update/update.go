@@ -0,0 +1,197 @@
+// Package update checks the Forge release feed for newer versions and replaces
+// the running binary with a verified download.
+//
+// Every release publishes one binary per platform (forge_<os>_<arch>), a
+// checksums.txt listing their SHA-256 digests, and checksums.txt.sig, an
+// Ed25519 signature of checksums.txt. A download is installed only if the
+// signature verifies against the key compiled into this build and the binary
+// matches its checksum.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ChannelStable receives full releases only
+	ChannelStable = "stable"
+	// ChannelBeta also receives pre-releases
+	ChannelBeta = "beta"
+
+	// DefaultFeedURL lists Forge releases, newest first
+	DefaultFeedURL = "https://api.github.com/repos/entrhq/forge/releases"
+
+	// ChecksumsAsset lists the SHA-256 digest of every release binary
+	ChecksumsAsset = "checksums.txt"
+	// SignatureAsset is the Ed25519 signature of ChecksumsAsset
+	SignatureAsset = "checksums.txt.sig"
+)
+
+// Release is a published Forge version
+type Release struct {
+	Version    string            // Without the leading "v"
+	Prerelease bool              // Published to the beta channel only
+	URL        string            // Release notes page
+	Assets     map[string]string // Download URL by asset name
+}
+
+// Client reads the release feed and downloads release assets
+type Client struct {
+	FeedURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for the default release feed
+func NewClient() *Client {
+	return &Client{
+		FeedURL:    DefaultFeedURL,
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// ValidateChannel checks that channel is a known release channel
+func ValidateChannel(channel string) error {
+	if channel != ChannelStable && channel != ChannelBeta {
+		return fmt.Errorf("invalid update channel: %s (must be '%s' or '%s')", channel, ChannelStable, ChannelBeta)
+	}
+	return nil
+}
+
+// Latest returns the newest release published to channel.
+func (c *Client) Latest(ctx context.Context, channel string) (*Release, error) {
+	if err := ValidateChannel(channel); err != nil {
+		return nil, err
+	}
+
+	body, err := c.get(ctx, c.FeedURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release feed: %w", err)
+	}
+
+	var feed []struct {
+		TagName    string `json:"tag_name"`
+		HTMLURL    string `json:"html_url"`
+		Draft      bool   `json:"draft"`
+		Prerelease bool   `json:"prerelease"`
+		Assets     []struct {
+			Name string `json:"name"`
+			URL  string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+	if err := json.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse release feed: %w", err)
+	}
+
+	var latest *Release
+	for _, entry := range feed {
+		if entry.Draft || (entry.Prerelease && channel != ChannelBeta) {
+			continue
+		}
+		release := &Release{
+			Version:    strings.TrimPrefix(entry.TagName, "v"),
+			Prerelease: entry.Prerelease,
+			URL:        entry.HTMLURL,
+			Assets:     make(map[string]string, len(entry.Assets)),
+		}
+		for _, asset := range entry.Assets {
+			release.Assets[asset.Name] = asset.URL
+		}
+		if latest == nil || IsNewer(release.Version, latest.Version) {
+			latest = release
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("no %s releases found", channel)
+	}
+	return latest, nil
+}
+
+// get fetches url and returns the response body
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// IsNewer reports whether version a is newer than version b. Versions are
+// compared as semantic versions, with or without a leading "v"; a
+// pre-release (1.2.0-beta.1) is older than its release (1.2.0).
+func IsNewer(a, b string) bool {
+	return compareVersions(a, b) > 0
+}
+
+func compareVersions(a, b string) int {
+	aCore, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	bCore, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+
+	if c := compareDotted(aCore, bCore); c != 0 {
+		return c
+	}
+
+	// A release is newer than any of its pre-releases
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareDotted(aPre, bPre)
+}
+
+// compareDotted compares dot-separated identifiers, numerically where both
+// are numbers.
+func compareDotted(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		aPart, bPart := "0", "0" // Missing parts count as zero
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+
+		aNum, aErr := strconv.Atoi(aPart)
+		bNum, bErr := strconv.Atoi(bPart)
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum != bNum {
+				return compareInts(aNum, bNum)
+			}
+		case aPart != bPart:
+			return strings.Compare(aPart, bPart)
+		}
+	}
+	return 0
+}
+
+func compareInts(a, b int) int {
+	if a > b {
+		return 1
+	}
+	return -1
+}
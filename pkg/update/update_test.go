@@ -0,0 +1,194 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"0.2.0", "0.1.0", true},
+		{"v0.10.0", "0.9.0", true},
+		{"0.1.0", "0.1.0", false},
+		{"0.1.0", "0.2.0", false},
+		{"1.0.0", "1.0.0-beta.2", true},
+		{"1.0.0-beta.2", "1.0.0", false},
+		{"1.0.0-beta.10", "1.0.0-beta.2", true},
+		{"1.1.0-beta.1", "1.0.0", true},
+		{"1.2", "1.2.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsNewer(tt.a, tt.b); got != tt.want {
+			t.Errorf("IsNewer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+const testFeed = `[
+	{"tag_name": "v0.4.0-beta.1", "prerelease": true, "html_url": "https://example.com/v0.4.0-beta.1"},
+	{"tag_name": "v0.5.0", "draft": true},
+	{"tag_name": "v0.3.0", "html_url": "https://example.com/v0.3.0"},
+	{"tag_name": "v0.2.0"}
+]`
+
+func newFeedServer(t *testing.T, feed string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(feed))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Latest(t *testing.T) {
+	server := newFeedServer(t, testFeed)
+	client := &Client{FeedURL: server.URL, HTTPClient: server.Client()}
+
+	stable, err := client.Latest(context.Background(), ChannelStable)
+	if err != nil {
+		t.Fatalf("Latest(stable) error = %v", err)
+	}
+	if stable.Version != "0.3.0" {
+		t.Errorf("stable version = %q, want 0.3.0 (pre-releases and drafts skipped)", stable.Version)
+	}
+
+	beta, err := client.Latest(context.Background(), ChannelBeta)
+	if err != nil {
+		t.Fatalf("Latest(beta) error = %v", err)
+	}
+	if beta.Version != "0.4.0-beta.1" || !beta.Prerelease {
+		t.Errorf("beta release = %+v, want 0.4.0-beta.1", beta)
+	}
+
+	if _, err := client.Latest(context.Background(), "nightly"); err == nil {
+		t.Error("expected error for unknown channel")
+	}
+}
+
+func TestClient_Download(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asset := AssetName(runtime.GOOS, runtime.GOARCH)
+	binary := []byte("new forge binary")
+	sum := sha256.Sum256(binary)
+	checksums := []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), asset))
+	signature := ed25519.Sign(priv, checksums)
+
+	files := map[string][]byte{
+		"/" + asset:          binary,
+		"/" + ChecksumsAsset: checksums,
+		"/" + SignatureAsset: signature,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(files[r.URL.Path])
+	}))
+	defer server.Close()
+
+	release := &Release{Version: "9.9.9", Assets: map[string]string{
+		asset:          server.URL + "/" + asset,
+		ChecksumsAsset: server.URL + "/" + ChecksumsAsset,
+		SignatureAsset: server.URL + "/" + SignatureAsset,
+	}}
+	client := &Client{HTTPClient: server.Client()}
+
+	got, err := client.Download(context.Background(), release, pub)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if string(got) != string(binary) {
+		t.Errorf("Download() = %q, want %q", got, binary)
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err := client.Download(context.Background(), release, otherPub); err == nil {
+		t.Error("expected error for a signature from another key")
+	}
+
+	files["/"+asset] = []byte("tampered binary")
+	if _, err := client.Download(context.Background(), release, pub); err == nil {
+		t.Error("expected error for a binary that does not match its checksum")
+	}
+}
+
+func TestPublicKey_Missing(t *testing.T) {
+	if publicKey != "" {
+		t.Skip("build has a release signing key")
+	}
+	if _, err := PublicKey(); err != ErrNoPublicKey {
+		t.Errorf("PublicKey() error = %v, want ErrNoPublicKey", err)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "forge")
+	if err := os.WriteFile(path, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Install(path, []byte("new")); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("installed binary = %q, want %q", data, "new")
+	}
+	if _, err := os.Stat(path + ".new"); !os.IsNotExist(err) {
+		t.Error("temporary file should not be left behind")
+	}
+
+	if err := Install("/opt/homebrew/Cellar/forge/0.1.0/bin/forge", []byte("new")); err == nil {
+		t.Error("expected Homebrew installs to be refused")
+	}
+}
+
+func TestNoticeAndRefresh(t *testing.T) {
+	server := newFeedServer(t, testFeed)
+	client := &Client{FeedURL: server.URL, HTTPClient: server.Client()}
+	cachePath := filepath.Join(t.TempDir(), "update-check.json")
+	now := time.Now()
+
+	if notice := Notice(cachePath, "0.1.0", ChannelStable); notice != "" {
+		t.Errorf("expected no notice before the first check, got %q", notice)
+	}
+
+	if err := client.Refresh(context.Background(), cachePath, ChannelStable, now); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if notice := Notice(cachePath, "0.1.0", ChannelStable); notice == "" {
+		t.Error("expected a notice when a newer release is recorded")
+	}
+	if notice := Notice(cachePath, "0.3.0", ChannelStable); notice != "" {
+		t.Errorf("expected no notice when up to date, got %q", notice)
+	}
+	if notice := Notice(cachePath, "0.1.0", ChannelBeta); notice != "" {
+		t.Errorf("expected no notice for a different channel, got %q", notice)
+	}
+
+	// A fresh cache is not refreshed, even if the feed is unreachable
+	client.FeedURL = "http://127.0.0.1:0"
+	if err := client.Refresh(context.Background(), cachePath, ChannelStable, now.Add(time.Hour)); err != nil {
+		t.Errorf("Refresh() with fresh cache error = %v", err)
+	}
+}